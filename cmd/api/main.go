@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/jofosuware/go/shopit/config"
 	"github.com/jofosuware/go/shopit/internal/server"
 	"github.com/jofosuware/go/shopit/pkg/driver"
 	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/tracing"
 )
 
 func main() {
@@ -29,6 +32,11 @@ func main() {
 	appLogger.InitLogger()
 	appLogger.Infof("AppVersion: %s, LogLevel: %s, Mode: %s, SSL: %t", cfg.Server.AppVersion, cfg.Logger.Level, cfg.Server.Mode, cfg.Server.SSL)
 
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		appLogger.Fatal(err)
+	}
+
 	// connect to database
 	appLogger.Info("Connecting to database...")
 
@@ -38,7 +46,13 @@ func main() {
 		connectionString = fmt.Sprintf("host=%s port=%d dbname=%s user=%s password=%s sslmode=%s", cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.Dbname, cfg.Postgres.User, cfg.Postgres.Password, cfg.Postgres.SSLMode)
 	}
 
-	db, err := driver.ConnectSQL(connectionString)
+	db, err := driver.ConnectSQL(connectionString, driver.Options{
+		StatementCacheCapacity: cfg.Postgres.StatementCacheCapacity,
+		StatementCacheMode:     cfg.Postgres.StatementCacheMode,
+		Logger:                 appLogger.With("module", "sql"),
+		SlowQueryThreshold:     cfg.Postgres.SlowQueryThreshold,
+		LogQueries:             cfg.Postgres.LogQueries,
+	})
 	if err != nil {
 		appLogger.Fatal(err)
 	}
@@ -53,7 +67,29 @@ func main() {
 	s := server.NewServer(cfg, appLogger, d)
 	s.Setup()
 
-	if err = s.Run(); err != nil {
+	if cfg.Server.HotReload {
+		config.Watch(cfgFile, func(c *config.Config) {
+			if err := appLogger.SetLevel(c.Logger.Level); err != nil {
+				appLogger.Errorf("error reloading log level: %v", err)
+			}
+			s.Reload(c)
+		})
+	}
+
+	err = s.Run()
+
+	// flush the spans of the last requests
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if terr := shutdownTracing(ctx); terr != nil {
+		appLogger.Errorf("error flushing traces: %v", terr)
+	}
+
+	if serr := appLogger.Sync(); serr != nil {
+		fmt.Fprintf(os.Stderr, "error flushing logs: %v\n", serr)
+	}
+
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,123 @@
+// Command seed fills the database of a staging or demo environment, or of a local
+// development server, with fake users, categories, products with placeholder images,
+// reviews and orders. It runs with the config of the api server and refuses to unless
+// seed.Enabled is set or the server runs in Development mode. With -admin, the first
+// generated user is an admin to sign in as.
+//
+//	go run ./cmd/seed -users 200 -products 500 -images 3 -reviews 10 -orders 4
+//	go run ./cmd/seed -admin
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
+	authRepository "github.com/jofosuware/go/shopit/internal/auth/repository"
+	categoryRepository "github.com/jofosuware/go/shopit/internal/categories/repository"
+	"github.com/jofosuware/go/shopit/internal/models"
+	ordRepository "github.com/jofosuware/go/shopit/internal/orders/repository"
+	prodRepository "github.com/jofosuware/go/shopit/internal/products/repository"
+	seedUC "github.com/jofosuware/go/shopit/internal/seed/usecase"
+	"github.com/jofosuware/go/shopit/pkg/bcrypt"
+	"github.com/jofosuware/go/shopit/pkg/driver"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+func main() {
+	var opts models.SeedOptions
+	flag.IntVar(&opts.Users, "users", 50, fmt.Sprintf("users to generate, at most %d", models.MaxSeedUsers))
+	flag.IntVar(&opts.Products, "products", 100, fmt.Sprintf("products to generate, at most %d", models.MaxSeedProducts))
+	flag.IntVar(&opts.ImagesPerProduct, "images", 2, fmt.Sprintf("images per product, at most %d", models.MaxSeedImagesPerProduct))
+	flag.IntVar(&opts.ReviewsPerProduct, "reviews", 5, fmt.Sprintf("most reviews per product, at most %d", models.MaxSeedReviewsPerProduct))
+	flag.IntVar(&opts.OrdersPerUser, "orders", 3, fmt.Sprintf("orders per user, at most %d", models.MaxSeedOrdersPerUser))
+	flag.Int64Var(&opts.Seed, "seed", 0, "seed of the generated data, 0 for a random one")
+	owner := flag.String("owner", "", "id of the user the products are listed by, the first generated user by default")
+	flag.BoolVar(&opts.Admin, "admin", false, "make the first generated user an admin")
+	flag.Parse()
+
+	if err := checkVolumes(opts); err != nil {
+		log.Fatal(err)
+	}
+	if *owner != "" {
+		id, err := uuid.Parse(*owner)
+		if err != nil {
+			log.Fatalf("invalid owner: %v", err)
+		}
+		opts.Owner = id
+	}
+
+	cfgFile, err := config.LoadConfig("./config/config-local")
+	if err != nil {
+		log.Fatalf("LoadConfig: %v", err)
+	}
+
+	cfg, err := config.ParseConfig(cfgFile)
+	if err != nil {
+		log.Fatalf("ParseConfig: %v", err)
+	}
+
+	if !cfg.Seed.Enabled && cfg.Server.Mode != "Development" {
+		log.Fatal("seed data generator is disabled: set seed.enabled (SEED_ENABLED) in staging or demo environments only")
+	}
+	if err := money.SetCurrency(cfg.Server.Currency); err != nil {
+		log.Fatal(err)
+	}
+
+	var connectionString = cfg.Postgres.Url
+
+	if cfg.Server.Mode == "Development" {
+		connectionString = fmt.Sprintf("host=%s port=%d dbname=%s user=%s password=%s sslmode=%s", cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.Dbname, cfg.Postgres.User, cfg.Postgres.Password, cfg.Postgres.SSLMode)
+	}
+
+	db, err := driver.ConnectSQL(connectionString, driver.Options{
+		StatementCacheCapacity: cfg.Postgres.StatementCacheCapacity,
+		StatementCacheMode:     cfg.Postgres.StatementCacheMode,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	d := db.SQL
+	defer d.Close()
+
+	uc := seedUC.NewSeedUC(authRepository.NewAuthRepository(d), categoryRepository.NewCategoriesRepository(d),
+		prodRepository.NewProdRepository(d), ordRepository.NewOrdersRepository(d), bcrypt.NewEncrypt())
+
+	report, err := uc.Generate(opts)
+	if report != nil {
+		log.Printf("Generated %d users, %d categories, %d products, %d images, %d reviews and %d orders",
+			report.Users, report.Categories, report.Products, report.Images, report.Reviews, report.Orders)
+		if report.Users > 0 {
+			log.Printf("Generated users sign in with the password %q", report.Password)
+		}
+		if report.Admin != "" {
+			log.Printf("The admin signs in with the email %q", report.Admin)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// checkVolumes returns an error when a volume of opts is negative or over its cap.
+func checkVolumes(opts models.SeedOptions) error {
+	volumes := []struct {
+		flag   string
+		n, max int
+	}{
+		{"users", opts.Users, models.MaxSeedUsers},
+		{"products", opts.Products, models.MaxSeedProducts},
+		{"images", opts.ImagesPerProduct, models.MaxSeedImagesPerProduct},
+		{"reviews", opts.ReviewsPerProduct, models.MaxSeedReviewsPerProduct},
+		{"orders", opts.OrdersPerUser, models.MaxSeedOrdersPerUser},
+	}
+	for _, v := range volumes {
+		if v.n < 0 || v.n > v.max {
+			return fmt.Errorf("-%s must be between 0 and %d", v.flag, v.max)
+		}
+	}
+
+	return nil
+}
@@ -4,23 +4,53 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
+	"os"
+	"reflect"
 	"strings"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/google/uuid"
 	"github.com/spf13/viper"
 )
 
 // Config is App config struct
 type Config struct {
-	Server     ServerConfig
-	Postgres   PostgresConfig
-	Cookie     Cookie
-	Logger     Logger
-	Stripe     Stripe
-	SMTP       SMTP
-	Cloudinary Cloudinary
-	SecretKey  string
-	Frontend   string
+	Server         ServerConfig
+	Postgres       PostgresConfig
+	Cookie         Cookie
+	Logger         Logger
+	Payments       Payments
+	Stripe         Stripe
+	PayPal         PayPal
+	SMTP           SMTP
+	Cloudinary     Cloudinary
+	Storage        Storage
+	RateLimit      RateLimit
+	LoadShedding   LoadShedding
+	BodyLimit      BodyLimit
+	ErrorReporting ErrorReporting
+	Tracing        Tracing
+	Checkout       Checkout
+	Delivery       Delivery
+	PasswordReset  PasswordReset
+	MagicLink      MagicLink
+	Avatar         Avatar
+	Notifications  Notifications
+	Currencies     Currencies
+	Invoices       Invoices
+	Analytics      Analytics
+	Outbox         Outbox
+	Webhooks       Webhooks
+	CatalogSync    CatalogSync
+	Related        Related
+	Storefront     Storefront
+	GRPC           GRPC
+	Seed           Seed
+	Features       map[string]FeatureFlag
+	SecretKey      string
+	Frontend       string
 }
 
 // ServerConfig Server config struct
@@ -36,27 +66,91 @@ type ServerConfig struct {
 	CtxDefaultTimeout time.Duration
 	CSRF              bool
 	Debug             bool
+	// TokenCleanupInterval is how often expired tokens are deleted (0 disables the job)
+	TokenCleanupInterval time.Duration
+	// AccountDeletionGrace is how long a user can restore an account they deleted
+	AccountDeletionGrace time.Duration
+	// AccountPurgeInterval is how often accounts past their grace period are deleted (0 disables the job)
+	AccountPurgeInterval time.Duration
+	// PublishInterval is how often drafts whose publish time has come are published (0 disables the job)
+	PublishInterval time.Duration
+	// HotReload watches the config file and applies changes to the log level and rate limits without a
+	// restart; other settings are read at startup only
+	HotReload bool
+	// CertFile and KeyFile are the PEM certificate chain and private key the server listens with over
+	// TLS when SSL is set
+	CertFile string
+	KeyFile  string
+	// AutocertDomains are the hosts the server gets Let's Encrypt certificates for when SSL is set
+	// without a CertFile, cached in AutocertCacheDir; AutocertEmail is told of problems with them
+	AutocertDomains  []string
+	AutocertCacheDir string
+	AutocertEmail    string
+	// RedirectPort is the port of a plain HTTP listener redirecting to HTTPS when SSL is set, which
+	// also answers the HTTP challenges of Let's Encrypt (empty for none)
+	RedirectPort string
+	// HSTSMaxAge is how long browsers are told to use HTTPS only, with a Strict-Transport-Security
+	// header on the responses of a server with SSL (0 sends none)
+	HSTSMaxAge time.Duration
+	// CompressionLevel is the brotli or gzip level, 1 to 9, of the JSON and CSV responses of clients
+	// accepting it (0 disables compression)
+	CompressionLevel int
+	// PrettyJSON indents every JSON response; otherwise only those to requests with a pretty query
+	// parameter are
+	PrettyJSON bool
+	// Currency is the ISO 4217 code of the currency the shop sells in
+	Currency string
+	// Locale is the language of emails and invoices for users without a preferred one, and of
+	// API responses to clients without an Accept-Language header
+	Locale string
+	// Messages is a directory of <locale>.json catalogs rewording or translating the messages of
+	// the shop, such as the validation errors of API responses (empty keeps the built-in ones)
+	Messages string
+	// TrustedProxies are the IP addresses or CIDR ranges of the proxies in front of the API, such as
+	// the Render or Heroku router, whose X-Forwarded-For and X-Real-IP headers give the client address
+	// (empty trusts none, the peer of the connection being the client)
+	TrustedProxies []string
 }
 
-// Logger config
+// Logger config. Encoding is "json" or "console"; when it is empty, logs are written
+// as JSON, or as console lines with Development or in Development mode. Sampling
+// thins out the messages of high-volume paths, such as the request log.
 type Logger struct {
 	Development       bool
 	DisableCaller     bool
 	DisableStacktrace bool
 	Encoding          string
 	Level             string
+	Sampling          LogSampling
 }
 
-// PostgresConfig Postgresql config
+// LogSampling config. Of the messages repeated by a sampled logger within a second,
+// the first Initial are written and then every Thereafter-th. An Initial of 0 turns
+// sampling off.
+type LogSampling struct {
+	Initial    int
+	Thereafter int
+}
+
+// PostgresConfig Postgresql config. Each connection keeps the statements of its last
+// StatementCacheCapacity distinct queries prepared, 512 when it is 0 and none when it
+// is negative, in StatementCacheMode: "prepare", or "describe" behind a pooler such as
+// PgBouncer. Queries taking SlowQueryThreshold or longer are logged with their
+// duration and redacted arguments, 0 logging none, and with LogQueries every query is
+// logged at debug level.
 type PostgresConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Dbname   string
-	SSLMode  string
-	PgDriver string
-	Url      string
+	Host                   string
+	Port                   int
+	User                   string
+	Password               string
+	Dbname                 string
+	SSLMode                string
+	PgDriver               string
+	Url                    string
+	StatementCacheCapacity int
+	StatementCacheMode     string
+	SlowQueryThreshold     time.Duration
+	LogQueries             bool
 }
 
 // Cookie config
@@ -67,10 +161,36 @@ type Cookie struct {
 	HTTPOnly bool
 }
 
-// Stripe config
+// Payments config. Provider is the provider new payments are made with: stripe
+// (default) or paypal. Payments already made are captured, voided and updated by
+// webhooks through the provider they were made with.
+type Payments struct {
+	Provider string
+}
+
+// Stripe config. With ManualCapture payments are only authorized at checkout and
+// captured when the order ships; VoidInterval is how often the payments of orders not
+// shipped within CaptureWindow, whatever their provider, are voided (0 disables the
+// job). WebhookSecret verifies the events posted to the Stripe webhook.
 type Stripe struct {
-	Secret string
-	Key    string
+	Secret        string
+	Key           string
+	WebhookSecret string
+	ManualCapture bool
+	CaptureWindow time.Duration
+	VoidInterval  time.Duration
+}
+
+// PayPal config. URL is the API to use, the sandbox by default; set it to
+// https://api-m.paypal.com in production. With ManualCapture orders are only
+// authorized at checkout and captured when the order ships. WebhookID identifies the
+// webhook whose notifications are verified.
+type PayPal struct {
+	ClientID      string
+	Secret        string
+	URL           string
+	WebhookID     string
+	ManualCapture bool
 }
 
 // SMTP config
@@ -88,15 +208,284 @@ type Cloudinary struct {
 	Secret string
 }
 
+// RateLimit config for the per-client request limiter.
+// Rate is requests per second, Burst the bucket size.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// LoadShedding config. Low priority requests, such as searches and analytics, are
+// answered with 503 while MaxInFlight other requests are served or DBSaturation
+// percent of the database connections are in use. 0 disables a threshold.
+type LoadShedding struct {
+	MaxInFlight  int
+	DBSaturation int
+}
+
+// BodyLimit config, in bytes. JSON is the largest JSON or URL encoded body and Multipart the largest
+// form, except on the routes uploading product images, which take forms up to Upload.
+// Memory is how much of a form is held in memory; its files beyond that are written
+// to temporary files while the request is served. Larger bodies are answered with 413.
+type BodyLimit struct {
+	JSON      int64
+	Multipart int64
+	Upload    int64
+	Memory    int64
+}
+
+// ErrorReporting config. Panics in request handlers are reported to Provider, "sentry"
+// or none when empty, at the project of DSN. Environment tags reports, server.Mode by
+// default, and Timeout bounds a report.
+type ErrorReporting struct {
+	Provider    string
+	DSN         string
+	Environment string
+	Timeout     time.Duration
+}
+
+// Tracing config. With Enabled, the spans of requests, use cases and SQL queries are
+// exported over OTLP/HTTP to Endpoint, the base URL of a collector such as
+// http://localhost:4318, as ServiceName. SampleRatio is the share, 0 to 1, of the
+// traces started by the server that are kept; traces of callers follow their choice.
+type Tracing struct {
+	Enabled     bool
+	Endpoint    string
+	ServiceName string
+	SampleRatio float64
+}
+
+// Checkout config. LockDuration is how long a checkout session keeps its prices
+// and ExpiryInterval how often sessions past it are expired (0 disables the job).
+type Checkout struct {
+	LockDuration   time.Duration
+	ExpiryInterval time.Duration
+}
+
+// Delivery config for delivery estimates. Orders ship from the Warehouse country and
+// leave it after HandlingDays business days. Each of Methods gives its transit time
+// in business days as "min-max"; DefaultMethod is used when none is chosen.
+type Delivery struct {
+	Warehouse     string
+	HandlingDays  int
+	DefaultMethod string
+	Methods       map[string]DeliveryMethod
+}
+
+// DeliveryMethod transit times for domestic and international destinations, with
+// overrides per destination Countries (matched case-insensitively).
+type DeliveryMethod struct {
+	Domestic      string
+	International string
+	Countries     map[string]string
+}
+
+// PasswordReset config for password reset links. Expiry is how long a link stays
+// valid. URL is the link sent by email, with {token} standing for the reset token;
+// a URL starting with / is relative to MagicLink.BaseURL.
+type PasswordReset struct {
+	Expiry time.Duration
+	URL    string
+}
+
+// MagicLink config for passwordless sign-in links. Expiry is how long a link stays
+// valid; links are signed with the JWT secret key. BaseURL is the public scheme and
+// host of the API the links point at, such as https://api.shopit.example; it is not
+// taken from the request, whose Host header the client chooses. Every other link in
+// emails, such as password reset and account restore links, is built on it too.
+type MagicLink struct {
+	Expiry  time.Duration
+	BaseURL string
+}
+
+// Avatar config for avatar uploads. MaxSize is the largest accepted image in bytes
+// and MaxAspectRatio the largest ratio of the longer side to the shorter one.
+// AllowedTypes lists the content types accepted, among image/jpeg, image/png and
+// image/gif. When ModerationURL is set, every avatar is posted there for review
+// before it is stored.
+type Avatar struct {
+	MaxSize           int
+	MaxAspectRatio    float64
+	AllowedTypes      []string
+	ModerationURL     string
+	ModerationTimeout time.Duration
+}
+
+// Notifications config. Defaults gives, per event, the channels (email, sms, push)
+// users are notified on until they set their own preferences. DigestInterval is how
+// often the admin digests due are sent (0 disables the job), and products with at
+// most DigestLowStock units left are listed in them.
+type Notifications struct {
+	Defaults       map[string][]string
+	DigestInterval time.Duration
+	DigestLowStock int
+}
+
+// Currencies config for paying in currencies other than the shop one. Accepted lists
+// the currency codes customers can pay in besides Server.Currency. Exchange rates are
+// fetched from RatesURL ({base} stands for the shop currency) and cached for RatesTTL;
+// without a RatesURL the fixed Rates are used, one unit of the shop currency buying
+// that many units of each accepted currency.
+type Currencies struct {
+	Accepted []string
+	RatesURL string
+	RatesTTL time.Duration
+	Rates    map[string]float64
+}
+
+// Invoices config for order invoices. Seller (name, address, tax number, one per
+// line) is printed at the top of every invoice. With Archive the invoice of a paid
+// order is uploaded to the configured storage the first time it is downloaded.
+type Invoices struct {
+	Seller  string
+	Archive bool
+}
+
+// Analytics config for the data shared with analysts. PseudonymKey keys the stable
+// pseudonyms that replace user and order ids in anonymized exports; they are only
+// available once it is set, and changing it changes every pseudonym.
+type Analytics struct {
+	PseudonymKey string
+}
+
+// Outbox config for the events written to the outbox with the changes they describe,
+// such as the order emails. They are dispatched every DispatchInterval (0 disables
+// the job), BatchSize at a time; an event is attempted MaxAttempts times before it is
+// given up on, and processed events are kept for Retention.
+type Outbox struct {
+	DispatchInterval time.Duration
+	BatchSize        int
+	MaxAttempts      int
+	Retention        time.Duration
+}
+
+// Webhooks config for the deliveries of the events posted to the webhooks of external
+// systems. Due deliveries are posted every DeliveryInterval (0 disables the job),
+// BatchSize at a time, and a webhook has Timeout to answer; a delivery is attempted
+// MaxAttempts times before it is given up on, and finished deliveries are kept in the
+// log for Retention.
+type Webhooks struct {
+	DeliveryInterval time.Duration
+	Timeout          time.Duration
+	BatchSize        int
+	MaxAttempts      int
+	Retention        time.Duration
+}
+
+// CatalogSync config for pulling the product catalog from a Google Sheet shared by
+// link, in the product import format. SheetID is the id in the URL of the sheet and
+// GID the tab read, the first one when empty; the sheet has Timeout to answer. It is
+// pulled every Interval (0 only syncs on demand) and the products it adds are owned
+// by the admin whose id is Owner.
+type CatalogSync struct {
+	SheetID  string
+	GID      string
+	Owner    string
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// Related config for the related products of a product page and of an order
+// confirmation. They are cached for CacheTTL, so changes to the catalog show in them
+// once it passes; 0 disables the cache.
+type Related struct {
+	CacheTTL time.Duration
+}
+
+// Storefront config for what shoppers see of products. With HideStock, public
+// product responses tell whether a product is in stock and low on stock, at or
+// under its low-stock threshold, instead of how many units are left; admin
+// endpoints always show the exact stock.
+type Storefront struct {
+	HideStock bool
+}
+
+// GRPC config for the gRPC API internal services read products and orders with. It
+// listens on Port when set, over TLS with the certificate and key in CertFile and
+// KeyFile; with ClientCAFile, clients must present a certificate signed by it. It
+// only runs without TLS in Development mode.
+type GRPC struct {
+	Port         string
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// Seed config for the fake data generator of staging and demo environments. With
+// Enabled, admins can generate fake users, products, reviews and orders, and so can
+// the seed command, which also runs in Development mode; never enable it in production.
+type Seed struct {
+	Enabled bool
+}
+
+// FeatureFlag config for a preview cohort. The flag is enabled for subjects
+// (user ids, or anonymous cohort ids) on the Allowlist and for Percentage (0-100)
+// of everyone else.
+type FeatureFlag struct {
+	Percentage int
+	Allowlist  []string
+}
+
+// Storage config selects the CloudUploader implementation.
+// Provider is one of "cloudinary" (default), "s3" or "local".
+// ReconcileInterval is how often orphaned assets are cleaned up (0 disables the job)
+// and OrphanGracePeriod how old an unreferenced asset must be before it is destroyed.
+// PresignExpiry is how long the parameters of a direct upload stay valid (1m to 1h)
+// and PresignMaxSize the largest file they accept in bytes, on S3.
+// Retry bounds and retries every call to the provider.
+type Storage struct {
+	Provider          string
+	S3                S3Storage
+	Local             LocalStorage
+	Retry             StorageRetry
+	ReconcileInterval time.Duration
+	OrphanGracePeriod time.Duration
+	PresignExpiry     time.Duration
+	PresignMaxSize    int64
+}
+
+// S3Storage config for any S3-compatible object store
+type S3Storage struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	PublicURL string
+}
+
+// StorageRetry config for calls to the storage provider. Timeout bounds each attempt
+// of a call and MaxAttempts how many are made, with a jittered backoff growing from
+// BaseDelay to MaxDelay between them. After FailureThreshold calls in a row fail, the
+// circuit breaker rejects calls for OpenTimeout.
+type StorageRetry struct {
+	Timeout          time.Duration
+	MaxAttempts      int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+// LocalStorage config for storing uploads on disk, served under /static
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
 // LoadConfig Load config file from given path
 func LoadConfig(filename string) (*viper.Viper, error) {
 	v := viper.New()
 
 	v.SetConfigName(filename)
 	v.AddConfigPath(".")
-	// allow env vars to override config using _ for nested keys
+	// allow env vars to override config using _ for nested keys, SHOPIT_POSTGRES_URL for
+	// postgres.url first, then POSTGRES_URL and the explicit bindings below
+	v.SetEnvPrefix(EnvPrefix)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
+	bindEnvKeys(v, reflect.TypeOf(Config{}), "")
 
 	// explicit bindings for commonly overridden keys (single list, no duplicates)
 	v.BindEnv("server.port", "PORT")
@@ -109,6 +498,17 @@ func LoadConfig(filename string) (*viper.Viper, error) {
 
 	v.BindEnv("stripe.secret", "STRIPE_SECRET")
 	v.BindEnv("stripe.key", "STRIPE_KEY")
+	v.BindEnv("stripe.manualcapture", "STRIPE_MANUAL_CAPTURE")
+	v.BindEnv("stripe.capturewindow", "STRIPE_CAPTURE_WINDOW")
+	v.BindEnv("stripe.voidinterval", "STRIPE_VOID_INTERVAL")
+	v.BindEnv("stripe.webhooksecret", "STRIPE_WEBHOOK_SECRET")
+
+	v.BindEnv("payments.provider", "PAYMENT_PROVIDER")
+	v.BindEnv("paypal.clientid", "PAYPAL_CLIENT_ID")
+	v.BindEnv("paypal.secret", "PAYPAL_SECRET")
+	v.BindEnv("paypal.url", "PAYPAL_URL")
+	v.BindEnv("paypal.webhookid", "PAYPAL_WEBHOOK_ID")
+	v.BindEnv("paypal.manualcapture", "PAYPAL_MANUAL_CAPTURE")
 
 	v.BindEnv("smtp.host", "SMTP_HOST")
 	v.BindEnv("smtp.port", "SMTP_PORT")
@@ -119,6 +519,130 @@ func LoadConfig(filename string) (*viper.Viper, error) {
 	v.BindEnv("cloudinary.key", "CLOUDINARY_KEY")
 	v.BindEnv("cloudinary.secret", "CLOUDINARY_SECRET")
 
+	v.BindEnv("ratelimit.rate", "RATE_LIMIT_RATE")
+	v.BindEnv("ratelimit.burst", "RATE_LIMIT_BURST")
+
+	v.BindEnv("storage.provider", "STORAGE_PROVIDER")
+	v.BindEnv("storage.s3.endpoint", "S3_ENDPOINT")
+	v.BindEnv("storage.s3.region", "S3_REGION")
+	v.BindEnv("storage.s3.bucket", "S3_BUCKET")
+	v.BindEnv("storage.s3.accesskey", "S3_ACCESS_KEY")
+	v.BindEnv("storage.s3.secretkey", "S3_SECRET_KEY")
+	v.BindEnv("storage.s3.usessl", "S3_USE_SSL")
+	v.BindEnv("storage.s3.publicurl", "S3_PUBLIC_URL")
+	v.BindEnv("storage.local.dir", "STORAGE_LOCAL_DIR")
+	v.BindEnv("storage.local.baseurl", "STORAGE_LOCAL_BASE_URL")
+	v.BindEnv("storage.reconcileinterval", "STORAGE_RECONCILE_INTERVAL")
+	v.BindEnv("storage.orphangraceperiod", "STORAGE_ORPHAN_GRACE_PERIOD")
+	v.BindEnv("storage.presignexpiry", "STORAGE_PRESIGN_EXPIRY")
+	v.BindEnv("storage.presignmaxsize", "STORAGE_PRESIGN_MAX_SIZE")
+	v.BindEnv("server.tokencleanupinterval", "TOKEN_CLEANUP_INTERVAL")
+	v.BindEnv("server.accountdeletiongrace", "ACCOUNT_DELETION_GRACE")
+	v.BindEnv("server.accountpurgeinterval", "ACCOUNT_PURGE_INTERVAL")
+	v.BindEnv("server.publishinterval", "PUBLISH_INTERVAL")
+	v.BindEnv("server.hotreload", "CONFIG_HOT_RELOAD")
+	v.BindEnv("server.certfile", "TLS_CERT_FILE")
+	v.BindEnv("server.keyfile", "TLS_KEY_FILE")
+	v.BindEnv("server.autocertdomains", "AUTOCERT_DOMAINS")
+	v.BindEnv("server.autocertcachedir", "AUTOCERT_CACHE_DIR")
+	v.BindEnv("server.autocertemail", "AUTOCERT_EMAIL")
+	v.BindEnv("server.redirectport", "HTTP_REDIRECT_PORT")
+	v.BindEnv("server.hstsmaxage", "HSTS_MAX_AGE")
+	v.BindEnv("server.compressionlevel", "COMPRESSION_LEVEL")
+	v.BindEnv("server.prettyjson", "PRETTY_JSON")
+	v.BindEnv("server.trustedproxies", "TRUSTED_PROXIES")
+	v.BindEnv("checkout.lockduration", "CHECKOUT_LOCK_DURATION")
+	v.BindEnv("checkout.expiryinterval", "CHECKOUT_EXPIRY_INTERVAL")
+	v.BindEnv("delivery.warehouse", "DELIVERY_WAREHOUSE")
+	v.BindEnv("delivery.handlingdays", "DELIVERY_HANDLING_DAYS")
+	v.BindEnv("passwordreset.expiry", "PASSWORD_RESET_EXPIRY")
+	v.BindEnv("passwordreset.url", "PASSWORD_RESET_URL")
+	v.BindEnv("magiclink.expiry", "MAGIC_LINK_EXPIRY")
+	v.BindEnv("magiclink.baseurl", "MAGIC_LINK_BASE_URL")
+	v.BindEnv("avatar.maxsize", "AVATAR_MAX_SIZE")
+	v.BindEnv("avatar.maxaspectratio", "AVATAR_MAX_ASPECT_RATIO")
+	v.BindEnv("avatar.allowedtypes", "AVATAR_ALLOWED_TYPES")
+	v.BindEnv("avatar.moderationurl", "AVATAR_MODERATION_URL")
+	v.BindEnv("avatar.moderationtimeout", "AVATAR_MODERATION_TIMEOUT")
+	v.BindEnv("currencies.accepted", "CURRENCIES_ACCEPTED")
+	v.BindEnv("currencies.ratesurl", "CURRENCIES_RATES_URL")
+	v.BindEnv("currencies.ratesttl", "CURRENCIES_RATES_TTL")
+	v.BindEnv("invoices.seller", "INVOICES_SELLER")
+	v.BindEnv("invoices.archive", "INVOICES_ARCHIVE")
+	v.BindEnv("analytics.pseudonymkey", "ANALYTICS_PSEUDONYM_KEY")
+	v.BindEnv("grpc.port", "GRPC_PORT")
+	v.BindEnv("grpc.certfile", "GRPC_CERT_FILE")
+	v.BindEnv("grpc.keyfile", "GRPC_KEY_FILE")
+	v.BindEnv("grpc.clientcafile", "GRPC_CLIENT_CA_FILE")
+	v.BindEnv("seed.enabled", "SEED_ENABLED")
+	v.BindEnv("catalogsync.sheetid", "CATALOG_SHEET_ID")
+	v.BindEnv("catalogsync.owner", "CATALOG_SYNC_OWNER")
+	v.BindEnv("errorreporting.dsn", "SENTRY_DSN")
+	v.BindEnv("tracing.endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT")
+	v.BindEnv("tracing.servicename", "OTEL_SERVICE_NAME")
+	v.SetDefault("logger.sampling.initial", 100)
+	v.SetDefault("logger.sampling.thereafter", 100)
+	v.SetDefault("server.tokencleanupinterval", "1h")
+	v.SetDefault("server.accountdeletiongrace", "720h")
+	v.SetDefault("server.accountpurgeinterval", "1h")
+	v.SetDefault("server.publishinterval", "1m")
+	v.SetDefault("server.autocertcachedir", "./certs")
+	v.SetDefault("server.hstsmaxage", "8760h")
+	v.SetDefault("server.compressionlevel", 5)
+	v.SetDefault("server.currency", "USD")
+	v.SetDefault("server.locale", "en")
+	v.SetDefault("payments.provider", "stripe")
+	v.SetDefault("stripe.capturewindow", "144h")
+	v.SetDefault("stripe.voidinterval", "1h")
+	v.SetDefault("storage.reconcileinterval", "24h")
+	v.SetDefault("storage.orphangraceperiod", "1h")
+	v.SetDefault("storage.presignexpiry", "15m")
+	v.SetDefault("storage.presignmaxsize", 10<<20)
+	v.SetDefault("storage.retry.timeout", "30s")
+	v.SetDefault("storage.retry.maxattempts", 3)
+	v.SetDefault("storage.retry.basedelay", "200ms")
+	v.SetDefault("storage.retry.maxdelay", "2s")
+	v.SetDefault("storage.retry.failurethreshold", 5)
+	v.SetDefault("storage.retry.opentimeout", "30s")
+	v.SetDefault("bodylimit.json", 1<<20)
+	v.SetDefault("bodylimit.multipart", 10<<20)
+	v.SetDefault("bodylimit.upload", 32<<20)
+	v.SetDefault("bodylimit.memory", 1<<20)
+	v.SetDefault("errorreporting.timeout", "5s")
+	v.SetDefault("tracing.endpoint", "http://localhost:4318")
+	v.SetDefault("tracing.servicename", "shopit-api")
+	v.SetDefault("tracing.sampleratio", 1.0)
+	v.SetDefault("checkout.lockduration", "15m")
+	v.SetDefault("checkout.expiryinterval", "1m")
+	v.SetDefault("delivery.handlingdays", 1)
+	v.SetDefault("delivery.defaultmethod", "standard")
+	v.SetDefault("passwordreset.expiry", "60m")
+	v.SetDefault("passwordreset.url", "/password/reset/{token}")
+	v.SetDefault("magiclink.expiry", "15m")
+	v.SetDefault("avatar.maxsize", 2<<20)
+	v.SetDefault("avatar.maxaspectratio", 2.0)
+	v.SetDefault("avatar.allowedtypes", []string{"image/jpeg", "image/png", "image/gif"})
+	v.SetDefault("avatar.moderationtimeout", "5s")
+	v.SetDefault("currencies.ratesttl", "1h")
+	v.SetDefault("outbox.dispatchinterval", "5s")
+	v.SetDefault("outbox.batchsize", 50)
+	v.SetDefault("outbox.maxattempts", 10)
+	v.SetDefault("outbox.retention", "168h")
+	v.SetDefault("webhooks.deliveryinterval", "10s")
+	v.SetDefault("webhooks.timeout", "10s")
+	v.SetDefault("webhooks.batchsize", 50)
+	v.SetDefault("webhooks.maxattempts", 10)
+	v.SetDefault("webhooks.retention", "720h")
+	v.SetDefault("catalogsync.timeout", "30s")
+	v.SetDefault("notifications.digestinterval", "1h")
+	v.SetDefault("notifications.digestlowstock", 5)
+	v.SetDefault("notifications.defaults", map[string][]string{
+		"order_placed":  {"email"},
+		"order_status":  {"email"},
+		"price_drop":    {"email"},
+		"ticket_update": {"email"},
+	})
+
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			// file not present — continue, we'll rely on env vars
@@ -128,9 +652,96 @@ func LoadConfig(filename string) (*viper.Viper, error) {
 		}
 	}
 
+	if err := readSecretFiles(v); err != nil {
+		return nil, err
+	}
+
 	return v, nil
 }
 
+// secretKeys are the sensitive keys that can be read from files, such as Docker
+// secrets, by their older environment variable names if any.
+var secretKeys = map[string][]string{
+	"server.jwtsecretkey":    {"JWT_SECRET_KEY"},
+	"postgres.url":           {"DATABASE_URL"},
+	"postgres.password":      nil,
+	"stripe.secret":          {"STRIPE_SECRET"},
+	"stripe.webhooksecret":   {"STRIPE_WEBHOOK_SECRET"},
+	"paypal.secret":          {"PAYPAL_SECRET"},
+	"smtp.password":          {"SMTP_PASSWORD"},
+	"cloudinary.secret":      {"CLOUDINARY_SECRET"},
+	"storage.s3.secretkey":   {"S3_SECRET_KEY"},
+	"analytics.pseudonymkey": {"ANALYTICS_PSEUDONYM_KEY"},
+	"secretkey":              nil,
+}
+
+// readSecretFiles sets each of the secretKeys whose environment variable is named
+// with _FILE, as STRIPE_SECRET_FILE, to the content of that file without its trailing
+// newline. The file wins over the variable itself and the config file.
+func readSecretFiles(v *viper.Viper) error {
+	for key, names := range secretKeys {
+		name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		names = append([]string{EnvPrefix + "_" + name, name}, names...)
+		for _, n := range names {
+			path := os.Getenv(n + "_FILE")
+			if path == "" {
+				continue
+			}
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("reading %s_FILE: %w", n, err)
+			}
+			v.Set(key, strings.TrimRight(string(b), "\r\n"))
+			break
+		}
+	}
+
+	return nil
+}
+
+// Watch calls onChange with the config each time the config file of v changes, when
+// it parses and is valid; a change that does not is logged and ignored. It does
+// nothing without a config file.
+func Watch(v *viper.Viper, onChange func(*Config)) {
+	if v.ConfigFileUsed() == "" {
+		log.Println("no config file to watch")
+		return
+	}
+
+	v.OnConfigChange(func(fsnotify.Event) {
+		c, err := ParseConfig(v)
+		if err != nil {
+			log.Printf("config change ignored: %v", err)
+			return
+		}
+		onChange(c)
+	})
+	v.WatchConfig()
+}
+
+// EnvPrefix prefixes the environment variables every config key can be set with: the
+// key path in upper case with _ between its parts, SHOPIT_STRIPE_SECRET for
+// stripe.secret.
+const EnvPrefix = "SHOPIT"
+
+// bindEnvKeys binds the keys of the fields of t under prefix to their environment
+// variables without EnvPrefix, so that a config set by environment variables alone is
+// unmarshalled. Maps, such as features, are set in the config file only.
+func bindEnvKeys(v *viper.Viper, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := prefix + strings.ToLower(f.Name)
+		switch {
+		case f.Type.Kind() == reflect.Map:
+		case f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeOf(time.Duration(0)):
+			bindEnvKeys(v, f.Type, key+".")
+		default:
+			_ = v.BindEnv(key, strings.ToUpper(strings.ReplaceAll(key, ".", "_")))
+		}
+	}
+}
+
 // ParseConfig Parse config file
 func ParseConfig(v *viper.Viper) (*Config, error) {
 	var c Config
@@ -138,7 +749,14 @@ func ParseConfig(v *viper.Viper) (*Config, error) {
 	// Normalize numeric timeout values (seconds) into duration strings so
 	// they unmarshal properly into time.Duration fields. Accept either
 	// integer seconds or duration strings like "5s" in config.
-	durationKeys := []string{"server.readtimeout", "server.writetimeout", "server.ctxdefaulttimeout"}
+	durationKeys := []string{"postgres.slowquerythreshold", "server.readtimeout", "server.writetimeout", "server.ctxdefaulttimeout",
+		"server.tokencleanupinterval", "server.accountdeletiongrace", "server.accountpurgeinterval",
+		"server.publishinterval", "server.hstsmaxage", "stripe.capturewindow", "stripe.voidinterval", "storage.reconcileinterval", "storage.orphangraceperiod",
+		"storage.presignexpiry", "storage.retry.timeout", "storage.retry.basedelay", "storage.retry.maxdelay",
+		"storage.retry.opentimeout", "checkout.lockduration", "checkout.expiryinterval", "passwordreset.expiry",
+		"magiclink.expiry", "avatar.moderationtimeout", "currencies.ratesttl", "outbox.dispatchinterval",
+		"outbox.retention", "notifications.digestinterval", "webhooks.deliveryinterval", "webhooks.timeout", "webhooks.retention",
+		"catalogsync.interval", "catalogsync.timeout", "errorreporting.timeout"}
 	for _, k := range durationKeys {
 		if v.IsSet(k) {
 			val := v.Get(k)
@@ -166,37 +784,266 @@ func ParseConfig(v *viper.Viper) (*Config, error) {
 	return &c, nil
 }
 
-// Validate performs basic sanity checks on required configuration values.
+// ValidationError lists every problem Validate found with a config.
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p.Error())
+	}
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+// Validate performs basic sanity checks on required configuration values. It returns
+// a *ValidationError reporting every missing or invalid setting, not just the first.
 func (c *Config) Validate() error {
+	var errs []error
+
 	// JWT and DB (existing)
 	if c.Server.JwtSecretKey == "" {
-		return errors.New("missing required secret: set JWT_SECRET_KEY (server.jwtSecretKey)")
+		errs = append(errs, errors.New("missing required secret: set JWT_SECRET_KEY (server.jwtSecretKey)"))
 	}
 	if c.Postgres.Url == "" {
 		if c.Postgres.Host == "" || c.Postgres.User == "" || c.Postgres.Dbname == "" {
-			return errors.New("missing postgres configuration: set DATABASE_URL or POSTGRES_HOST/POSTGRES_USER/POSTGRES_DB")
+			errs = append(errs, errors.New("missing postgres configuration: set DATABASE_URL or POSTGRES_HOST/POSTGRES_USER/POSTGRES_DBNAME"))
 		}
 	}
+	switch c.Postgres.StatementCacheMode {
+	case "", "prepare", "describe":
+	default:
+		errs = append(errs, fmt.Errorf("unknown statement cache mode %q: use prepare or describe (postgres.statementCacheMode)", c.Postgres.StatementCacheMode))
+	}
+	if c.Postgres.SlowQueryThreshold < 0 {
+		errs = append(errs, errors.New("slow query threshold must not be negative (postgres.slowQueryThreshold)"))
+	}
+
+	// TLS
+	if c.Server.SSL {
+		if (c.Server.CertFile == "") != (c.Server.KeyFile == "") {
+			errs = append(errs, errors.New("tls needs both a certificate and a key: set TLS_CERT_FILE/TLS_KEY_FILE"))
+		}
+		if c.Server.CertFile == "" && len(c.Server.AutocertDomains) == 0 {
+			errs = append(errs, errors.New("ssl needs a certificate: set TLS_CERT_FILE/TLS_KEY_FILE or AUTOCERT_DOMAINS"))
+		}
+		if c.Server.RedirectPort != "" && c.Server.RedirectPort == c.Server.Port {
+			errs = append(errs, errors.New("the https redirect must listen on a port of its own (server.redirectPort)"))
+		}
+	}
+	if c.Server.HSTSMaxAge < 0 {
+		errs = append(errs, errors.New("hsts max age must not be negative (server.hstsMaxAge)"))
+	}
+	if c.Server.CompressionLevel < 0 || c.Server.CompressionLevel > 9 {
+		errs = append(errs, errors.New("compression level must be between 0 and 9 (server.compressionLevel)"))
+	}
+
+	// Logger
+	switch strings.ToLower(c.Logger.Encoding) {
+	case "", "json", "console":
+	default:
+		errs = append(errs, fmt.Errorf("unknown log encoding %q: use json or console (logger.encoding)", c.Logger.Encoding))
+	}
+	if c.Logger.Sampling.Initial < 0 || c.Logger.Sampling.Thereafter < 0 {
+		errs = append(errs, errors.New("log sampling must not be negative (logger.sampling)"))
+	}
 
 	// Payment (required in prod or when enabled)
 	if c.Server.Mode != "Development" {
 		if c.Stripe.Secret == "" {
-			return errors.New("missing STRIPE_SECRET (stripe.secret)")
+			errs = append(errs, errors.New("missing STRIPE_SECRET (stripe.secret)"))
 		}
 		if c.Stripe.Key == "" {
-			return errors.New("missing STRIPE_KEY (stripe.key)")
+			errs = append(errs, errors.New("missing STRIPE_KEY (stripe.key)"))
 		}
 	}
 
-	// Cloudinary
-	if c.Cloudinary.Name == "" || c.Cloudinary.Key == "" || c.Cloudinary.Secret == "" {
-		return errors.New("missing cloudinary credentials: set CLOUDINARY_NAME/CLOUDINARY_KEY/CLOUDINARY_SECRET")
+	switch strings.ToLower(c.Payments.Provider) {
+	case "", "stripe":
+	case "paypal":
+		if c.PayPal.ClientID == "" || c.PayPal.Secret == "" {
+			errs = append(errs, errors.New("missing paypal credentials: set PAYPAL_CLIENT_ID/PAYPAL_SECRET"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown payment provider %q: use stripe or paypal", c.Payments.Provider))
+	}
+	if c.PayPal.URL != "" {
+		u, err := url.Parse(c.PayPal.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("paypal url %q must be an http(s) url (paypal.url)", c.PayPal.URL))
+		}
+	}
+
+	// Stripe releases card authorizations after seven days
+	if c.Stripe.ManualCapture && c.Stripe.CaptureWindow > 7*24*time.Hour {
+		errs = append(errs, errors.New("stripe capture window must not be more than 168h (stripe.captureWindow)"))
+	}
+
+	// Body limits
+	if c.BodyLimit.JSON <= 0 || c.BodyLimit.Multipart <= 0 || c.BodyLimit.Upload <= 0 || c.BodyLimit.Memory <= 0 {
+		errs = append(errs, errors.New("body limits must be positive (bodylimit)"))
+	}
+	if c.BodyLimit.Upload < c.BodyLimit.Multipart {
+		errs = append(errs, errors.New("upload body limit must not be below the multipart one (bodylimit.upload)"))
+	}
+
+	// Error reporting
+	switch strings.ToLower(c.ErrorReporting.Provider) {
+	case "":
+	case "sentry":
+		if c.ErrorReporting.DSN == "" {
+			errs = append(errs, errors.New("missing SENTRY_DSN (errorReporting.dsn)"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown error reporting provider %q: use sentry or leave it empty", c.ErrorReporting.Provider))
+	}
+
+	// Tracing
+	if c.Tracing.Enabled {
+		u, err := url.Parse(c.Tracing.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("tracing endpoint %q must be an http(s) url (tracing.endpoint)", c.Tracing.Endpoint))
+		}
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("tracing sample ratio must be between 0 and 1 (tracing.sampleRatio)"))
+	}
+
+	// Storage
+	switch strings.ToLower(c.Storage.Provider) {
+	case "", "cloudinary":
+		if c.Cloudinary.Name == "" || c.Cloudinary.Key == "" || c.Cloudinary.Secret == "" {
+			errs = append(errs, errors.New("missing cloudinary credentials: set CLOUDINARY_NAME/CLOUDINARY_KEY/CLOUDINARY_SECRET"))
+		}
+	case "s3":
+		s3 := c.Storage.S3
+		if s3.Endpoint == "" || s3.Bucket == "" || s3.AccessKey == "" || s3.SecretKey == "" {
+			errs = append(errs, errors.New("incomplete S3 storage configuration: set S3_ENDPOINT/S3_BUCKET/S3_ACCESS_KEY/S3_SECRET_KEY"))
+		}
+	case "local":
+		if c.Storage.Local.Dir == "" {
+			errs = append(errs, errors.New("missing local storage directory: set STORAGE_LOCAL_DIR (storage.local.dir)"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown storage provider %q: use cloudinary, s3 or local", c.Storage.Provider))
+	}
+	if c.Storage.PresignExpiry < time.Minute || c.Storage.PresignExpiry > time.Hour {
+		errs = append(errs, errors.New("presigned upload expiry must be between 1m and 1h (storage.presignExpiry)"))
+	}
+	if c.Storage.PresignMaxSize <= 0 {
+		errs = append(errs, errors.New("presigned upload max size must be positive (storage.presignMaxSize)"))
+	}
+	if r := c.Storage.Retry; r.Timeout <= 0 || r.MaxAttempts <= 0 || r.BaseDelay <= 0 || r.MaxDelay <= 0 ||
+		r.FailureThreshold <= 0 || r.OpenTimeout <= 0 {
+		errs = append(errs, errors.New("storage retry settings must be positive (storage.retry)"))
+	}
+
+	// Feature flags
+	for name, f := range c.Features {
+		if f.Percentage < 0 || f.Percentage > 100 {
+			errs = append(errs, fmt.Errorf("feature flag %q: percentage must be between 0 and 100", name))
+		}
+	}
+
+	// Password reset
+	if c.PasswordReset.Expiry < 5*time.Minute || c.PasswordReset.Expiry > 24*time.Hour {
+		errs = append(errs, errors.New("password reset expiry must be between 5m and 24h (passwordReset.expiry)"))
+	}
+	if !strings.Contains(c.PasswordReset.URL, "{token}") {
+		errs = append(errs, errors.New("password reset url must contain {token} (passwordReset.url)"))
+	}
+	if !strings.HasPrefix(c.PasswordReset.URL, "/") {
+		u, err := url.Parse(c.PasswordReset.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("password reset url %q must be a path or an http(s) url (passwordReset.url)", c.PasswordReset.URL))
+		}
+	}
+
+	// Magic link
+	if c.MagicLink.Expiry < time.Minute || c.MagicLink.Expiry > time.Hour {
+		errs = append(errs, errors.New("magic link expiry must be between 1m and 1h (magicLink.expiry)"))
+	}
+	if u, err := url.Parse(c.MagicLink.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("magic link base url %q must be an http(s) url: set MAGIC_LINK_BASE_URL (magicLink.baseUrl)", c.MagicLink.BaseURL))
+	}
+
+	// Avatar
+	if c.Avatar.MaxSize <= 0 {
+		errs = append(errs, errors.New("avatar max size must be positive (avatar.maxSize)"))
+	}
+	if c.Avatar.MaxAspectRatio < 1 {
+		errs = append(errs, errors.New("avatar max aspect ratio must be at least 1 (avatar.maxAspectRatio)"))
+	}
+	if len(c.Avatar.AllowedTypes) == 0 {
+		errs = append(errs, errors.New("avatar allowed types must not be empty (avatar.allowedTypes)"))
+	}
+	for _, t := range c.Avatar.AllowedTypes {
+		if t != "image/jpeg" && t != "image/png" && t != "image/gif" {
+			errs = append(errs, fmt.Errorf("avatar type %q must be image/jpeg, image/png or image/gif (avatar.allowedTypes)", t))
+		}
+	}
+	if c.Avatar.ModerationURL != "" {
+		u, err := url.Parse(c.Avatar.ModerationURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("avatar moderation url %q must be an http(s) url (avatar.moderationUrl)", c.Avatar.ModerationURL))
+		}
+	}
+
+	// Currencies
+	if c.Currencies.RatesURL != "" {
+		u, err := url.Parse(c.Currencies.RatesURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("exchange rates url %q must be an http(s) url (currencies.ratesUrl)", c.Currencies.RatesURL))
+		}
+		if c.Currencies.RatesTTL <= 0 {
+			errs = append(errs, errors.New("exchange rates ttl must be positive (currencies.ratesTtl)"))
+		}
+	} else {
+		// viper lower cases map keys
+		for _, code := range c.Currencies.Accepted {
+			if c.Currencies.Rates[strings.ToLower(code)] <= 0 && c.Currencies.Rates[strings.ToUpper(code)] <= 0 {
+				errs = append(errs, fmt.Errorf("missing exchange rate for %s: set currencies.rates or currencies.ratesUrl", code))
+			}
+		}
+	}
+
+	// Catalog sync
+	if c.CatalogSync.SheetID != "" {
+		if c.CatalogSync.Timeout <= 0 {
+			errs = append(errs, errors.New("catalog sync timeout must be positive (catalogSync.timeout)"))
+		}
+		if _, err := uuid.Parse(c.CatalogSync.Owner); c.CatalogSync.Interval > 0 && err != nil {
+			errs = append(errs, errors.New("scheduled catalog sync needs the id of the admin owning the products it adds (catalogSync.owner)"))
+		}
+	}
+
+	// gRPC
+	if c.GRPC.Port != "" {
+		if (c.GRPC.CertFile == "") != (c.GRPC.KeyFile == "") {
+			errs = append(errs, errors.New("grpc tls needs both a certificate and a key: set GRPC_CERT_FILE/GRPC_KEY_FILE"))
+		}
+		if c.GRPC.CertFile == "" && c.Server.Mode != "Development" {
+			errs = append(errs, errors.New("grpc runs without tls in Development mode only: set GRPC_CERT_FILE/GRPC_KEY_FILE"))
+		}
+		if c.GRPC.ClientCAFile != "" && c.GRPC.CertFile == "" {
+			errs = append(errs, errors.New("grpc client certificates need tls: set GRPC_CERT_FILE/GRPC_KEY_FILE"))
+		}
 	}
 
 	// SMTP
 	if c.SMTP.Host == "" || c.SMTP.Port == 0 || c.SMTP.Username == "" || c.SMTP.Password == "" {
-		return errors.New("incomplete SMTP configuration: set SMTP_HOST/SMTP_PORT/SMTP_USERNAME/SMTP_PASSWORD")
+		errs = append(errs, errors.New("incomplete SMTP configuration: set SMTP_HOST/SMTP_PORT/SMTP_USERNAME/SMTP_PASSWORD"))
 	}
 
+	if len(errs) > 0 {
+		return &ValidationError{Problems: errs}
+	}
 	return nil
 }
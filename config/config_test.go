@@ -0,0 +1,83 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Run("Config from environment variables alone", func(t *testing.T) {
+		t.Setenv("SHOPIT_SERVER_MODE", "Development")
+		t.Setenv("SHOPIT_SERVER_JWTSECRETKEY", "secret")
+		t.Setenv("SHOPIT_SERVER_READTIMEOUT", "5s")
+		t.Setenv("SHOPIT_POSTGRES_URL", "postgres://shopit@db/shopit")
+		t.Setenv("DATABASE_URL", "postgres://ignored@db/shopit")
+		t.Setenv("SHOPIT_STORAGE_PROVIDER", "local")
+		t.Setenv("SHOPIT_STORAGE_LOCAL_DIR", "/uploads")
+		t.Setenv("SMTP_HOST", "smtp.example.com")
+		t.Setenv("SHOPIT_SMTP_PORT", "587")
+		t.Setenv("SHOPIT_SMTP_USERNAME", "shopit")
+		t.Setenv("SHOPIT_SMTP_PASSWORD", "password")
+		t.Setenv("SHOPIT_SERVER_TRUSTEDPROXIES", "10.0.0.0/8")
+		t.Setenv("MAGIC_LINK_BASE_URL", "https://api.shopit.example")
+
+		v, err := config.LoadConfig("no-such-config")
+		require.NoError(t, err)
+		c, err := config.ParseConfig(v)
+		require.NoError(t, err)
+
+		assert.Equal(t, "secret", c.Server.JwtSecretKey)
+		assert.Equal(t, 5*time.Second, c.Server.ReadTimeout)
+		assert.Equal(t, "postgres://shopit@db/shopit", c.Postgres.Url)
+		assert.Equal(t, "/uploads", c.Storage.Local.Dir)
+		assert.Equal(t, 30*time.Second, c.Storage.Retry.Timeout)
+		assert.Equal(t, "smtp.example.com", c.SMTP.Host)
+		assert.Equal(t, 587, c.SMTP.Port)
+		assert.Equal(t, []string{"10.0.0.0/8"}, c.Server.TrustedProxies)
+		assert.Equal(t, "https://api.shopit.example", c.MagicLink.BaseURL)
+	})
+
+	t.Run("Every missing setting is reported", func(t *testing.T) {
+		v, err := config.LoadConfig("no-such-config")
+		require.NoError(t, err)
+		_, err = config.ParseConfig(v)
+
+		var verr *config.ValidationError
+		require.ErrorAs(t, err, &verr)
+		assert.GreaterOrEqual(t, len(verr.Problems), 4)
+		assert.Contains(t, err.Error(), "JWT_SECRET_KEY")
+		assert.Contains(t, err.Error(), "STRIPE_SECRET")
+		assert.Contains(t, err.Error(), "SMTP_HOST")
+		assert.Contains(t, err.Error(), "MAGIC_LINK_BASE_URL")
+		assert.True(t, errors.Is(err, verr.Problems[0]))
+	})
+	t.Run("Secrets read from files", func(t *testing.T) {
+		dir := t.TempDir()
+		secret := filepath.Join(dir, "stripe_secret")
+		require.NoError(t, os.WriteFile(secret, []byte("sk_test_file\n"), 0o600))
+		password := filepath.Join(dir, "smtp_password")
+		require.NoError(t, os.WriteFile(password, []byte("from-file"), 0o600))
+		t.Setenv("STRIPE_SECRET", "sk_test_env")
+		t.Setenv("STRIPE_SECRET_FILE", secret)
+		t.Setenv("SHOPIT_SMTP_PASSWORD_FILE", password)
+
+		v, err := config.LoadConfig("no-such-config")
+		require.NoError(t, err)
+		assert.Equal(t, "sk_test_file", v.GetString("stripe.secret"))
+		assert.Equal(t, "from-file", v.GetString("smtp.password"))
+	})
+
+	t.Run("Missing secret file", func(t *testing.T) {
+		t.Setenv("JWT_SECRET_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		_, err := config.LoadConfig("no-such-config")
+		assert.ErrorContains(t, err, "JWT_SECRET_KEY_FILE")
+	})
+}
@@ -0,0 +1,224 @@
+// Package delivery provides HTTP handlers for the address books of users.
+//
+// Users save the addresses they ship to and place orders with the id of one of
+// them instead of entering the shipping info again.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/addresses"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// AddressHandlers provides HTTP handler methods for address book endpoints.
+type AddressHandlers struct {
+	logger    logger.Logger
+	addressUC addresses.AddressUC
+}
+
+// NewAddressHandlers returns a new AddressHandlers.
+func NewAddressHandlers(logger logger.Logger, addressUC addresses.AddressUC) *AddressHandlers {
+	return &AddressHandlers{
+		logger:    logger,
+		addressUC: addressUC,
+	}
+}
+
+type addressResponse struct {
+	Success bool            `json:"success"`
+	Address *models.Address `json:"address"`
+}
+
+// GetAddresses returns the addresses of the current user, the default first.
+// Endpoint: GET /api/v1/addresses
+func (h *AddressHandlers) GetAddresses(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.addressUC.GetAddresses(user.ID)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting addresses: %w", err))
+		return
+	}
+
+	jr := struct {
+		Success   bool             `json:"success"`
+		Addresses []models.Address `json:"addresses"`
+	}{
+		Success:   true,
+		Addresses: list,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// GetAddress returns an address of the current user.
+// Endpoint: GET /api/v1/addresses/{id}
+func (h *AddressHandlers) GetAddress(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.id(w, r)
+	if !ok {
+		return
+	}
+
+	addr, err := h.addressUC.GetAddress(id, user.ID)
+	if err != nil {
+		h.writeError(w, r, "error getting address", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, addressResponse{Success: true, Address: addr})
+}
+
+// CreateAddress saves an address to the address book of the current user. The first
+// address saved is the default.
+// Endpoint: POST /api/v1/addresses
+// Expects JSON body: {"label": <string>, "address": <string>, "city": <string>, "phoneNo": <string>,
+// "postalCode": <string>, "country": <string>, "default": <bool>}.
+func (h *AddressHandlers) CreateAddress(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	var req addressRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	addr := req.address()
+	addr.UserID = user.ID
+
+	created, err := h.addressUC.CreateAddress(addr)
+	if err != nil {
+		h.writeError(w, r, "error creating address", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusCreated, addressResponse{Success: true, Address: created})
+}
+
+// UpdateAddress replaces an address of the current user. Setting "default" makes it
+// the default; clearing it does not, make another address the default instead.
+// Endpoint: PUT /api/v1/addresses/{id}
+// Expects the JSON body of CreateAddress.
+func (h *AddressHandlers) UpdateAddress(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.id(w, r)
+	if !ok {
+		return
+	}
+
+	var req addressRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	addr := req.address()
+	addr.ID = id
+	addr.UserID = user.ID
+
+	updated, err := h.addressUC.UpdateAddress(addr)
+	if err != nil {
+		h.writeError(w, r, "error updating address", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, addressResponse{Success: true, Address: updated})
+}
+
+// SetDefault makes an address the default of the current user.
+// Endpoint: PUT /api/v1/addresses/{id}/default
+func (h *AddressHandlers) SetDefault(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.id(w, r)
+	if !ok {
+		return
+	}
+
+	addr, err := h.addressUC.SetDefault(id, user.ID)
+	if err != nil {
+		h.writeError(w, r, "error setting default address", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, addressResponse{Success: true, Address: addr})
+}
+
+// DeleteAddress deletes an address of the current user. When it was the default,
+// the newest address left becomes the default.
+// Endpoint: DELETE /api/v1/addresses/{id}
+func (h *AddressHandlers) DeleteAddress(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.id(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.addressUC.DeleteAddress(id, user.ID); err != nil {
+		h.writeError(w, r, "error deleting address", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, models.Response{Success: true, Message: "address deleted"})
+}
+
+// user returns the signed in user. It writes the response and returns false when
+// there is none.
+func (h *AddressHandlers) user(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return nil, false
+	}
+
+	return user, true
+}
+
+// id returns the address id of the URL. It writes the response and returns false
+// when it is not an id.
+func (h *AddressHandlers) id(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+func (h *AddressHandlers) writeError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if addresses.IsClientError(err) {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("%s: %v", msg, err)
+		return
+	}
+	_ = utils.ServerError(w, r, h.logger, fmt.Errorf("%s: %w", msg, err))
+}
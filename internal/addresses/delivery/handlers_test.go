@@ -0,0 +1,142 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/addresses"
+	"github.com/jofosuware/go/shopit/internal/addresses/delivery"
+	"github.com/jofosuware/go/shopit/internal/addresses/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func request(user *models.User, method, id, body string) *http.Request {
+	req := httptest.NewRequest(method, "/addresses/"+id, bytes.NewBufferString(body))
+	rCtx := chi.NewRouteContext()
+	rCtx.URLParams.Add("id", id)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+	return req.WithContext(context.WithValue(ctx, utils.UserContextKey, user))
+}
+
+func TestCreateAddress(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	addressUC := mocks.NewAddressUC(t)
+	h := delivery.NewAddressHandlers(logger, addressUC)
+	user := &models.User{ID: uuid.New()}
+
+	call := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.CreateAddress(rr, request(user, http.MethodPost, "", body))
+		return rr
+	}
+
+	t.Run("Address is saved", func(t *testing.T) {
+		addressUC.On("CreateAddress", models.Address{UserID: user.ID, Label: "Home", Address: "1 Main St", City: "Accra",
+			PhoneNo: "0200000000", PostalCode: "00233", Country: "Ghana"}).
+			Return(func(a models.Address) (*models.Address, error) {
+				a.ID = uuid.New()
+				a.Default = true
+				return &a, nil
+			}).Once()
+
+		rr := call(`{"label": " Home ", "address": "1 Main St", "city": "Accra", "phoneNo": "0200000000",
+			"postalCode": "00233", "country": "Ghana"}`)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		var res struct {
+			Address models.Address `json:"address"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, "Home", res.Address.Label)
+		assert.True(t, res.Address.Default)
+	})
+
+	t.Run("Missing fields", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call(`{"address": "1 Main St", "city": " "}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Address book full", func(t *testing.T) {
+		addressUC.On("CreateAddress", mock.Anything).Return(nil, addresses.ErrTooManyAddresses).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := call(`{"address": "1 Main St", "city": "Accra", "phoneNo": "0200000000", "postalCode": "00233",
+			"country": "Ghana"}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestSetDefault(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	addressUC := mocks.NewAddressUC(t)
+	h := delivery.NewAddressHandlers(logger, addressUC)
+	user := &models.User{ID: uuid.New()}
+
+	t.Run("Address becomes the default", func(t *testing.T) {
+		id := uuid.New()
+		addressUC.On("SetDefault", id, user.ID).Return(&models.Address{ID: id, Default: true}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.SetDefault(rr, request(user, http.MethodPut, id.String(), ""))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Address of another user", func(t *testing.T) {
+		id := uuid.New()
+		addressUC.On("SetDefault", id, user.ID).Return(nil, addresses.ErrAddressNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.SetDefault(rr, request(user, http.MethodPut, id.String(), ""))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.SetDefault(rr, request(user, http.MethodPut, "home", ""))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestDeleteAddress(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	addressUC := mocks.NewAddressUC(t)
+	h := delivery.NewAddressHandlers(logger, addressUC)
+	user := &models.User{ID: uuid.New()}
+
+	t.Run("Address is deleted", func(t *testing.T) {
+		id := uuid.New()
+		addressUC.On("DeleteAddress", id, user.ID).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.DeleteAddress(rr, request(user, http.MethodDelete, id.String(), ""))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		id := uuid.New()
+		addressUC.On("DeleteAddress", id, user.ID).Return(errors.New("db down")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.DeleteAddress(rr, request(user, http.MethodDelete, id.String(), ""))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
@@ -0,0 +1,32 @@
+package delivery
+
+import (
+	"strings"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// addressRequest is the body of the endpoints saving an address. The lengths are the
+// ones of the shipping info of orders, which an address is copied to.
+type addressRequest struct {
+	Label      string `json:"label" validate:"max=50"`
+	Address    string `json:"address" validate:"notblank,max=100"`
+	City       string `json:"city" validate:"notblank,max=100"`
+	PhoneNo    string `json:"phoneNo" validate:"notblank,max=100"`
+	PostalCode string `json:"postalCode" validate:"notblank,max=100"`
+	Country    string `json:"country" validate:"notblank,max=100"`
+	Default    bool   `json:"default"`
+}
+
+// address returns the address of the request, trimmed.
+func (req *addressRequest) address() models.Address {
+	return models.Address{
+		Label:      strings.TrimSpace(req.Label),
+		Address:    strings.TrimSpace(req.Address),
+		City:       strings.TrimSpace(req.City),
+		PhoneNo:    strings.TrimSpace(req.PhoneNo),
+		PostalCode: strings.TrimSpace(req.PostalCode),
+		Country:    strings.TrimSpace(req.Country),
+		Default:    req.Default,
+	}
+}
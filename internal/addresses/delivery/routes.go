@@ -0,0 +1,31 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// AddressRouter returns a chi.Router with the address book routes of the signed in user.
+//
+//   - GET    /              → List the user's addresses, the default first
+//   - POST   /              → Save an address
+//   - GET    /{id}          → Get an address
+//   - PUT    /{id}          → Update an address
+//   - PUT    /{id}/default  → Make an address the default
+//   - DELETE /{id}          → Delete an address
+func (h *AddressHandlers) AddressRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+
+	mux.Get("/", h.GetAddresses)
+	mux.Post("/", h.CreateAddress)
+	mux.Get("/{id}", h.GetAddress)
+	mux.Put("/{id}", h.UpdateAddress)
+	mux.Put("/{id}/default", h.SetDefault)
+	mux.Delete("/{id}", h.DeleteAddress)
+
+	return mux
+}
@@ -0,0 +1,21 @@
+package addresses
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+var (
+	// ErrAddressNotFound is returned when an address does not exist or belongs to another user.
+	ErrAddressNotFound = errors.New("address not found")
+
+	// ErrTooManyAddresses is returned when a user with models.MaxAddresses addresses saves another.
+	ErrTooManyAddresses = fmt.Errorf("an address book holds at most %d addresses", models.MaxAddresses)
+)
+
+// IsClientError reports whether err is caused by the request rather than the server.
+func IsClientError(err error) bool {
+	return errors.Is(err, ErrAddressNotFound) || errors.Is(err, ErrTooManyAddresses)
+}
@@ -0,0 +1,196 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	uuid "github.com/google/uuid"
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AddressUC is an autogenerated mock type for the AddressUC type
+type AddressUC struct {
+	mock.Mock
+}
+
+// CreateAddress provides a mock function with given fields: a
+func (_m *AddressUC) CreateAddress(a models.Address) (*models.Address, error) {
+	ret := _m.Called(a)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAddress")
+	}
+
+	var r0 *models.Address
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Address) (*models.Address, error)); ok {
+		return rf(a)
+	}
+	if rf, ok := ret.Get(0).(func(models.Address) *models.Address); ok {
+		r0 = rf(a)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Address)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Address) error); ok {
+		r1 = rf(a)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteAddress provides a mock function with given fields: id, userID
+func (_m *AddressUC) DeleteAddress(id uuid.UUID, userID uuid.UUID) error {
+	ret := _m.Called(id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAddress")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(id, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAddress provides a mock function with given fields: id, userID
+func (_m *AddressUC) GetAddress(id uuid.UUID, userID uuid.UUID) (*models.Address, error) {
+	ret := _m.Called(id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAddress")
+	}
+
+	var r0 *models.Address
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) (*models.Address, error)); ok {
+		return rf(id, userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) *models.Address); ok {
+		r0 = rf(id, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Address)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAddresses provides a mock function with given fields: userID
+func (_m *AddressUC) GetAddresses(userID uuid.UUID) ([]models.Address, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAddresses")
+	}
+
+	var r0 []models.Address
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.Address, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.Address); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Address)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetDefault provides a mock function with given fields: id, userID
+func (_m *AddressUC) SetDefault(id uuid.UUID, userID uuid.UUID) (*models.Address, error) {
+	ret := _m.Called(id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDefault")
+	}
+
+	var r0 *models.Address
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) (*models.Address, error)); ok {
+		return rf(id, userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) *models.Address); ok {
+		r0 = rf(id, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Address)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateAddress provides a mock function with given fields: a
+func (_m *AddressUC) UpdateAddress(a models.Address) (*models.Address, error) {
+	ret := _m.Called(a)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAddress")
+	}
+
+	var r0 *models.Address
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Address) (*models.Address, error)); ok {
+		return rf(a)
+	}
+	if rf, ok := ret.Get(0).(func(models.Address) *models.Address); ok {
+		r0 = rf(a)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Address)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Address) error); ok {
+		r1 = rf(a)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAddressUC creates a new instance of AddressUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAddressUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AddressUC {
+	mock := &AddressUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,29 @@
+package addresses
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// FetchAddresses fetches the addresses of a user, the default first, then newest first, returns an error on failure
+	FetchAddresses(userID uuid.UUID) ([]models.Address, error)
+
+	// FetchAddress fetches an address of a user, returns sql.ErrNoRows when the user has no such address
+	FetchAddress(id, userID uuid.UUID) (*models.Address, error)
+
+	// CountAddresses counts the addresses of a user, returns an error on failure
+	CountAddresses(userID uuid.UUID) (int, error)
+
+	// InsertAddress inserts an address, taking the default from the other addresses of its user when it is the default, returns the saved address
+	InsertAddress(a models.Address) (*models.Address, error)
+
+	// UpdateAddress updates an address of a user but not whether it is the default, returns sql.ErrNoRows when the user has no such address
+	UpdateAddress(a models.Address) (*models.Address, error)
+
+	// SetDefaultAddress makes an address the default of its user, returns sql.ErrNoRows when the user has no such address
+	SetDefaultAddress(id, userID uuid.UUID) error
+
+	// DeleteAddress deletes an address of a user, the newest left becoming the default when it was, returns sql.ErrNoRows when the user has no such address
+	DeleteAddress(id, userID uuid.UUID) error
+}
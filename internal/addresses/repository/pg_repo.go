@@ -0,0 +1,222 @@
+// Package repository provides persistence for the address books of users.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// AddressesRepository handles address database operations.
+type AddressesRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewAddressesRepository returns a new AddressesRepository.
+func NewAddressesRepository(db *sql.DB) *AddressesRepository {
+	return &AddressesRepository{
+		DB: db,
+	}
+}
+
+const addressColumns = `address_id, user_id, label, address, city, phone, postal, country, is_default, created_at,
+				updated_at`
+
+// FetchAddresses fetches the addresses of a user, the default first, then newest first.
+func (r *AddressesRepository) FetchAddresses(userID uuid.UUID) ([]models.Address, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "select " + addressColumns + " from addresses where user_id = $1 order by is_default desc, created_at desc"
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := []models.Address{}
+	for rows.Next() {
+		a, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses = append(addresses, *a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+// FetchAddress fetches an address of a user. It returns sql.ErrNoRows when the user
+// has no such address.
+func (r *AddressesRepository) FetchAddress(id, userID uuid.UUID) (*models.Address, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	row := r.DB.QueryRowContext(ctx, "select "+addressColumns+" from addresses where address_id = $1 and user_id = $2",
+		id, userID)
+
+	return scanAddress(row)
+}
+
+// CountAddresses counts the addresses of a user.
+func (r *AddressesRepository) CountAddresses(userID uuid.UUID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var n int
+	if err := r.DB.QueryRowContext(ctx, "select count(*) from addresses where user_id = $1", userID).Scan(&n); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// InsertAddress inserts an address. A default address takes the default from the
+// other addresses of its user in the same transaction.
+func (r *AddressesRepository) InsertAddress(a models.Address) (*models.Address, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	if a.Default {
+		if err := clearDefault(ctx, tx, a.UserID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `insert into addresses (user_id, label, address, city, phone, postal, country, is_default, created_at,
+				updated_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9) returning ` + addressColumns
+
+	saved, err := scanAddress(tx.QueryRowContext(ctx, query, a.UserID, a.Label, a.Address, a.City, a.PhoneNo,
+		a.PostalCode, a.Country, a.Default, now))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// UpdateAddress updates an address of a user, leaving whether it is the default
+// alone. It returns sql.ErrNoRows when the user has no such address.
+func (r *AddressesRepository) UpdateAddress(a models.Address) (*models.Address, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update addresses set label = $1, address = $2, city = $3, phone = $4, postal = $5, country = $6,
+				updated_at = $7 where address_id = $8 and user_id = $9 returning ` + addressColumns
+
+	row := r.DB.QueryRowContext(ctx, query, a.Label, a.Address, a.City, a.PhoneNo, a.PostalCode, a.Country,
+		time.Now(), a.ID, a.UserID)
+
+	return scanAddress(row)
+}
+
+// SetDefaultAddress makes an address the default of its user and the others not. It
+// returns sql.ErrNoRows when the user has no such address.
+func (r *AddressesRepository) SetDefaultAddress(id, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	if err := clearDefault(ctx, tx, userID, now); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, "update addresses set is_default = true, updated_at = $1 where address_id = $2 and user_id = $3",
+		now, id, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+// DeleteAddress deletes an address of a user. When it was the default, the newest
+// address left becomes the default. It returns sql.ErrNoRows when the user has no
+// such address.
+func (r *AddressesRepository) DeleteAddress(id, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var wasDefault bool
+	err = tx.QueryRowContext(ctx, "delete from addresses where address_id = $1 and user_id = $2 returning is_default",
+		id, userID).Scan(&wasDefault)
+	if err != nil {
+		return err
+	}
+
+	if wasDefault {
+		query := `update addresses set is_default = true, updated_at = $2 where address_id =
+					(select address_id from addresses where user_id = $1 order by created_at desc limit 1)`
+
+		if _, err := tx.ExecContext(ctx, query, userID, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// clearDefault makes no address of the user the default.
+func clearDefault(ctx context.Context, tx *sql.Tx, userID uuid.UUID, now time.Time) error {
+	_, err := tx.ExecContext(ctx, "update addresses set is_default = false, updated_at = $2 where user_id = $1 and is_default",
+		userID, now)
+
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAddress(row scanner) (*models.Address, error) {
+	var a models.Address
+
+	err := row.Scan(&a.ID, &a.UserID, &a.Label, &a.Address, &a.City, &a.PhoneNo, &a.PostalCode, &a.Country,
+		&a.Default, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
@@ -0,0 +1,167 @@
+package repository_test
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/addresses/repository"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var addressColumns = []string{"address_id", "user_id", "label", "address", "city", "phone", "postal", "country",
+	"is_default", "created_at", "updated_at"}
+
+func TestFetchAddresses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAddressesRepository(db)
+	userID := uuid.New()
+	now := time.Now()
+
+	t.Run("Default first", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("from addresses where user_id = $1 order by is_default desc")).WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows(addressColumns).
+				AddRow(uuid.New(), userID, "Home", "1 Main St", "Accra", "0200000000", "00233", "Ghana", true, now, now).
+				AddRow(uuid.New(), userID, "Work", "2 High St", "Kumasi", "0200000001", "00233", "Ghana", false, now, now))
+
+		list, err := repo.FetchAddresses(userID)
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.True(t, list[0].Default)
+		assert.Equal(t, "Work", list[1].Label)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Empty address book", func(t *testing.T) {
+		mock.ExpectQuery("from addresses where user_id").WithArgs(userID).WillReturnRows(sqlmock.NewRows(addressColumns))
+
+		list, err := repo.FetchAddresses(userID)
+		require.NoError(t, err)
+		assert.NotNil(t, list)
+		assert.Empty(t, list)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInsertAddress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAddressesRepository(db)
+	now := time.Now()
+	clear := regexp.QuoteMeta("update addresses set is_default = false")
+
+	t.Run("Default address takes the default", func(t *testing.T) {
+		a := models.Address{UserID: uuid.New(), Label: "Home", Address: "1 Main St", City: "Accra", PhoneNo: "0200000000",
+			PostalCode: "00233", Country: "Ghana", Default: true}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(clear).WithArgs(a.UserID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("insert into addresses").
+			WithArgs(a.UserID, "Home", "1 Main St", "Accra", "0200000000", "00233", "Ghana", true, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(addressColumns).
+				AddRow(uuid.New(), a.UserID, "Home", "1 Main St", "Accra", "0200000000", "00233", "Ghana", true, now, now))
+		mock.ExpectCommit()
+
+		saved, err := repo.InsertAddress(a)
+		require.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, saved.ID)
+		assert.True(t, saved.Default)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Other address leaves the default", func(t *testing.T) {
+		a := models.Address{UserID: uuid.New(), Address: "2 High St", City: "Kumasi", PhoneNo: "0200000001",
+			PostalCode: "00233", Country: "Ghana"}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into addresses").
+			WillReturnRows(sqlmock.NewRows(addressColumns).
+				AddRow(uuid.New(), a.UserID, "", "2 High St", "Kumasi", "0200000001", "00233", "Ghana", false, now, now))
+		mock.ExpectCommit()
+
+		saved, err := repo.InsertAddress(a)
+		require.NoError(t, err)
+		assert.False(t, saved.Default)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetDefaultAddress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAddressesRepository(db)
+	id, userID := uuid.New(), uuid.New()
+	clear := regexp.QuoteMeta("update addresses set is_default = false")
+	set := regexp.QuoteMeta("update addresses set is_default = true")
+
+	t.Run("Address becomes the default", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(clear).WithArgs(userID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(set).WithArgs(sqlmock.AnyArg(), id, userID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.SetDefaultAddress(id, userID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Address of another user", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(clear).WithArgs(userID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(set).WithArgs(sqlmock.AnyArg(), id, userID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, repo.SetDefaultAddress(id, userID), sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteAddress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAddressesRepository(db)
+	id, userID := uuid.New(), uuid.New()
+	del := regexp.QuoteMeta("delete from addresses where address_id = $1 and user_id = $2 returning is_default")
+	promote := regexp.QuoteMeta("update addresses set is_default = true")
+
+	t.Run("Default is handed over", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(del).WithArgs(id, userID).WillReturnRows(sqlmock.NewRows([]string{"is_default"}).AddRow(true))
+		mock.ExpectExec(promote).WithArgs(userID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.DeleteAddress(id, userID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Other address", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(del).WithArgs(id, userID).WillReturnRows(sqlmock.NewRows([]string{"is_default"}).AddRow(false))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.DeleteAddress(id, userID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Missing address", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(del).WithArgs(id, userID).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, repo.DeleteAddress(id, userID), sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,26 @@
+package addresses
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type AddressUC interface {
+	// GetAddresses returns the address book of a user
+	GetAddresses(userID uuid.UUID) ([]models.Address, error)
+
+	// GetAddress returns an address of a user
+	GetAddress(id, userID uuid.UUID) (*models.Address, error)
+
+	// CreateAddress saves an address to the address book of its user
+	CreateAddress(a models.Address) (*models.Address, error)
+
+	// UpdateAddress changes an address of a user
+	UpdateAddress(a models.Address) (*models.Address, error)
+
+	// SetDefault makes an address the default of its user
+	SetDefault(id, userID uuid.UUID) (*models.Address, error)
+
+	// DeleteAddress removes an address from the address book of a user
+	DeleteAddress(id, userID uuid.UUID) error
+}
@@ -0,0 +1,124 @@
+// Package usecase implements the address books of users.
+//
+// A user saves up to models.MaxAddresses shipping addresses and orders are shipped
+// to one of them by id. One address is the default: the first address saved is, and
+// when the default is deleted the newest address left takes its place.
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/addresses"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// AddressesUC provides address book use cases.
+type AddressesUC struct {
+	repo addresses.Repo
+}
+
+// NewAddressesUC returns a new AddressesUC.
+func NewAddressesUC(repo addresses.Repo) *AddressesUC {
+	return &AddressesUC{
+		repo: repo,
+	}
+}
+
+// GetAddresses returns the addresses of a user, the default first.
+func (a *AddressesUC) GetAddresses(userID uuid.UUID) ([]models.Address, error) {
+	list, err := a.repo.FetchAddresses(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching addresses: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetAddress returns an address of a user, addresses.ErrAddressNotFound when the
+// user has no such address.
+func (a *AddressesUC) GetAddress(id, userID uuid.UUID) (*models.Address, error) {
+	addr, err := a.repo.FetchAddress(id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, addresses.ErrAddressNotFound
+		}
+		return nil, fmt.Errorf("error fetching address: %w", err)
+	}
+
+	return addr, nil
+}
+
+// CreateAddress saves addr to the address book of its user. The first address of a
+// user is the default, whether or not it is marked so. It returns
+// addresses.ErrTooManyAddresses when the address book is full.
+func (a *AddressesUC) CreateAddress(addr models.Address) (*models.Address, error) {
+	n, err := a.repo.CountAddresses(addr.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error counting addresses: %w", err)
+	}
+	if n >= models.MaxAddresses {
+		return nil, addresses.ErrTooManyAddresses
+	}
+
+	addr.Default = addr.Default || n == 0
+
+	saved, err := a.repo.InsertAddress(addr)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting address: %w", err)
+	}
+
+	return saved, nil
+}
+
+// UpdateAddress changes the fields of an address of its user and makes it the default
+// when it is marked so; unmarking the default leaves it the default, another address
+// has to be made the default instead. It returns addresses.ErrAddressNotFound when the
+// user has no such address.
+func (a *AddressesUC) UpdateAddress(addr models.Address) (*models.Address, error) {
+	saved, err := a.repo.UpdateAddress(addr)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, addresses.ErrAddressNotFound
+		}
+		return nil, fmt.Errorf("error updating address: %w", err)
+	}
+
+	if addr.Default && !saved.Default {
+		if err := a.repo.SetDefaultAddress(saved.ID, saved.UserID); err != nil {
+			return nil, fmt.Errorf("error setting default address: %w", err)
+		}
+		saved.Default = true
+	}
+
+	return saved, nil
+}
+
+// SetDefault makes an address the default of its user and returns it. It returns
+// addresses.ErrAddressNotFound when the user has no such address.
+func (a *AddressesUC) SetDefault(id, userID uuid.UUID) (*models.Address, error) {
+	if err := a.repo.SetDefaultAddress(id, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, addresses.ErrAddressNotFound
+		}
+		return nil, fmt.Errorf("error setting default address: %w", err)
+	}
+
+	return a.GetAddress(id, userID)
+}
+
+// DeleteAddress deletes an address of a user. Orders shipped to it keep their
+// shipping info. It returns addresses.ErrAddressNotFound when the user has no such
+// address.
+func (a *AddressesUC) DeleteAddress(id, userID uuid.UUID) error {
+	if err := a.repo.DeleteAddress(id, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return addresses.ErrAddressNotFound
+		}
+		return fmt.Errorf("error deleting address: %w", err)
+	}
+
+	return nil
+}
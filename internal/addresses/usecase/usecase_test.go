@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/addresses"
+	"github.com/jofosuware/go/shopit/internal/addresses/mocks"
+	"github.com/jofosuware/go/shopit/internal/addresses/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAddress(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	a := usecase.NewAddressesUC(repo)
+	userID := uuid.New()
+
+	inserted := func(addr models.Address) (*models.Address, error) {
+		addr.ID = uuid.New()
+		return &addr, nil
+	}
+
+	t.Run("First address is the default", func(t *testing.T) {
+		repo.On("CountAddresses", userID).Return(0, nil).Once()
+		repo.On("InsertAddress", mock.MatchedBy(func(addr models.Address) bool { return addr.Default })).
+			Return(inserted).Once()
+
+		saved, err := a.CreateAddress(models.Address{UserID: userID, Address: "1 Main St"})
+		require.NoError(t, err)
+		assert.True(t, saved.Default)
+	})
+
+	t.Run("Other addresses keep their flag", func(t *testing.T) {
+		repo.On("CountAddresses", userID).Return(1, nil).Once()
+		repo.On("InsertAddress", mock.MatchedBy(func(addr models.Address) bool { return !addr.Default })).
+			Return(inserted).Once()
+
+		saved, err := a.CreateAddress(models.Address{UserID: userID, Address: "2 High St"})
+		require.NoError(t, err)
+		assert.False(t, saved.Default)
+	})
+
+	t.Run("Address book full", func(t *testing.T) {
+		repo.On("CountAddresses", userID).Return(models.MaxAddresses, nil).Once()
+
+		_, err := a.CreateAddress(models.Address{UserID: userID, Address: "3 Low St"})
+		assert.ErrorIs(t, err, addresses.ErrTooManyAddresses)
+	})
+}
+
+func TestUpdateAddress(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	a := usecase.NewAddressesUC(repo)
+	addr := models.Address{ID: uuid.New(), UserID: uuid.New(), Address: "1 Main St", Default: true}
+
+	t.Run("Marked default becomes the default", func(t *testing.T) {
+		repo.On("UpdateAddress", addr).Return(&models.Address{ID: addr.ID, UserID: addr.UserID}, nil).Once()
+		repo.On("SetDefaultAddress", addr.ID, addr.UserID).Return(nil).Once()
+
+		saved, err := a.UpdateAddress(addr)
+		require.NoError(t, err)
+		assert.True(t, saved.Default)
+	})
+
+	t.Run("Already the default", func(t *testing.T) {
+		repo.On("UpdateAddress", addr).Return(&models.Address{ID: addr.ID, UserID: addr.UserID, Default: true}, nil).Once()
+
+		saved, err := a.UpdateAddress(addr)
+		require.NoError(t, err)
+		assert.True(t, saved.Default)
+	})
+
+	t.Run("Missing address", func(t *testing.T) {
+		repo.On("UpdateAddress", addr).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := a.UpdateAddress(addr)
+		assert.ErrorIs(t, err, addresses.ErrAddressNotFound)
+	})
+}
+
+func TestDeleteAddress(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	a := usecase.NewAddressesUC(repo)
+	id, userID := uuid.New(), uuid.New()
+
+	t.Run("Missing address", func(t *testing.T) {
+		repo.On("DeleteAddress", id, userID).Return(sql.ErrNoRows).Once()
+		assert.ErrorIs(t, a.DeleteAddress(id, userID), addresses.ErrAddressNotFound)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		repo.On("DeleteAddress", id, userID).Return(errors.New("db down")).Once()
+
+		err := a.DeleteAddress(id, userID)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, addresses.ErrAddressNotFound)
+	})
+}
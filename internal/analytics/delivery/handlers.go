@@ -0,0 +1,81 @@
+// Package delivery provides HTTP handlers for the shop analytics.
+//
+// It lets admins see what customers are worth to the shop and how many of them come
+// back to order again.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jofosuware/go/shopit/internal/analytics"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// AnalyticsHandlers provides HTTP handler methods for analytics endpoints.
+type AnalyticsHandlers struct {
+	logger      logger.Logger
+	analyticsUC analytics.AnalyticsUC
+}
+
+// NewAnalyticsHandlers returns a new AnalyticsHandlers.
+func NewAnalyticsHandlers(logger logger.Logger, analyticsUC analytics.AnalyticsUC) *AnalyticsHandlers {
+	return &AnalyticsHandlers{
+		logger:      logger,
+		analyticsUC: analyticsUC,
+	}
+}
+
+// GetCustomerValues returns the lifetime value and repeat purchases of the customers
+// who ordered in a currency, and a page of them with the count, sum, average and
+// dates of their orders (admin).
+// Endpoint: GET /api/v1/admin/analytics/customers?currency=<code>&sort=<[-]orders|spent|average|last_order>&page=<int>&limit=<int>
+// The shop currency and the biggest spenders first by default; limit defaults to 20
+// and is capped at 100.
+func (h *AnalyticsHandlers) GetCustomerValues(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.CustomerFilter{Currency: q.Get("currency"), Sort: q.Get("sort"), Page: 1}
+
+	for name, n := range map[string]*int{"page": &filter.Page, "limit": &filter.PerPage} {
+		if s := q.Get(name); s != "" {
+			v, err := strconv.Atoi(s)
+			if err != nil || v < 1 {
+				_ = utils.BadRequest(w, r, fmt.Errorf("%s must be a positive number", name))
+				h.logger.Errorf("error parsing %s: %v", name, s)
+				return
+			}
+			*n = v
+		}
+	}
+
+	summary, customers, err := h.analyticsUC.GetCustomerValues(filter)
+	if err != nil {
+		if errors.Is(err, analytics.ErrInvalidCurrency) || errors.Is(err, analytics.ErrInvalidSort) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error getting customer values: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting customer values: %w", err))
+		return
+	}
+
+	if customers == nil {
+		customers = []models.CustomerValue{}
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success   bool                    `json:"success"`
+		Summary   *models.CustomerSummary `json:"summary"`
+		Page      int                     `json:"page"`
+		Customers []models.CustomerValue  `json:"customers"`
+	}{
+		Success:   true,
+		Summary:   summary,
+		Page:      filter.Page,
+		Customers: customers,
+	})
+}
@@ -0,0 +1,75 @@
+package delivery_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/analytics"
+	"github.com/jofosuware/go/shopit/internal/analytics/delivery"
+	"github.com/jofosuware/go/shopit/internal/analytics/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCustomerValues(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	analyticsUC := mocks.NewAnalyticsUC(t)
+	h := delivery.NewAnalyticsHandlers(logger, analyticsUC)
+
+	call := func(query string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.GetCustomerValues(rr, httptest.NewRequest(http.MethodGet, "/admin/analytics/customers"+query, nil))
+		return rr
+	}
+
+	t.Run("Page of customers", func(t *testing.T) {
+		summary := &models.CustomerSummary{Currency: money.DefaultCurrency, Customers: 40, RepeatCustomers: 10, RepeatRate: 0.25}
+		list := []models.CustomerValue{{UserID: uuid.New(), Email: "ama@example.com", Orders: 3, Spent: money.New(15000, money.DefaultCurrency)}}
+		analyticsUC.On("GetCustomerValues", models.CustomerFilter{Currency: "usd", Sort: "-orders", Page: 2, PerPage: 10}).
+			Return(summary, list, nil).Once()
+
+		rr := call("?currency=usd&sort=-orders&page=2&limit=10")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Summary   models.CustomerSummary `json:"summary"`
+			Page      int                    `json:"page"`
+			Customers []models.CustomerValue `json:"customers"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, 10, resp.Summary.RepeatCustomers)
+		assert.Equal(t, 2, resp.Page)
+		require.Len(t, resp.Customers, 1)
+		assert.Equal(t, "ama@example.com", resp.Customers[0].Email)
+	})
+
+	t.Run("Invalid sort", func(t *testing.T) {
+		analyticsUC.On("GetCustomerValues", models.CustomerFilter{Sort: "name", Page: 1}).
+			Return(nil, nil, analytics.ErrInvalidSort).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("?sort=name").Code)
+	})
+
+	t.Run("Invalid page", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("?page=0").Code)
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		analyticsUC.On("GetCustomerValues", models.CustomerFilter{Page: 1}).Return(nil, nil, errors.New("db down")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusInternalServerError, call("").Code)
+	})
+}
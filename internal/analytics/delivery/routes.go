@@ -0,0 +1,23 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/loadshed"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// AnalyticsRouter returns a chi.Router with admin-only analytics routes.
+//
+//   - GET /customers → Lifetime value and repeat purchases of customers
+func (h *AnalyticsHandlers) AnalyticsRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+	mux.Use(utils.IsAdmin)
+
+	mux.With(loadshed.LowPriority).Get("/customers", h.GetCustomerValues)
+
+	return mux
+}
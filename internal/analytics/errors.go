@@ -0,0 +1,10 @@
+package analytics
+
+import "errors"
+
+var (
+	// ErrInvalidSort is returned when listing customers in an order that is not supported.
+	ErrInvalidSort = errors.New("sort must be orders, spent, average or last_order, optionally prefixed with -")
+	// ErrInvalidCurrency is returned when aggregating customers in a currency the shop does not accept.
+	ErrInvalidCurrency = errors.New("currency is not accepted by the shop")
+)
@@ -0,0 +1,66 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AnalyticsUC is an autogenerated mock type for the AnalyticsUC type
+type AnalyticsUC struct {
+	mock.Mock
+}
+
+// GetCustomerValues provides a mock function with given fields: filter
+func (_m *AnalyticsUC) GetCustomerValues(filter models.CustomerFilter) (*models.CustomerSummary, []models.CustomerValue, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCustomerValues")
+	}
+
+	var r0 *models.CustomerSummary
+	var r1 []models.CustomerValue
+	var r2 error
+	if rf, ok := ret.Get(0).(func(models.CustomerFilter) (*models.CustomerSummary, []models.CustomerValue, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(models.CustomerFilter) *models.CustomerSummary); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CustomerSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.CustomerFilter) []models.CustomerValue); ok {
+		r1 = rf(filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]models.CustomerValue)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(models.CustomerFilter) error); ok {
+		r2 = rf(filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewAnalyticsUC creates a new instance of AnalyticsUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAnalyticsUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AnalyticsUC {
+	mock := &AnalyticsUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,87 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+// FetchCustomerSummary provides a mock function with given fields: currency
+func (_m *Repo) FetchCustomerSummary(currency string) (*models.CustomerSummary, error) {
+	ret := _m.Called(currency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchCustomerSummary")
+	}
+
+	var r0 *models.CustomerSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.CustomerSummary, error)); ok {
+		return rf(currency)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.CustomerSummary); ok {
+		r0 = rf(currency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CustomerSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(currency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchCustomerValues provides a mock function with given fields: filter
+func (_m *Repo) FetchCustomerValues(filter models.CustomerFilter) ([]models.CustomerValue, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchCustomerValues")
+	}
+
+	var r0 []models.CustomerValue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.CustomerFilter) ([]models.CustomerValue, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(models.CustomerFilter) []models.CustomerValue); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.CustomerValue)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.CustomerFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
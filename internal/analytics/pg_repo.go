@@ -0,0 +1,11 @@
+package analytics
+
+import "github.com/jofosuware/go/shopit/internal/models"
+
+type Repo interface {
+	// FetchCustomerValues fetches the page of customers selected by filter with their order aggregates
+	FetchCustomerValues(filter models.CustomerFilter) ([]models.CustomerValue, error)
+
+	// FetchCustomerSummary counts the customers who ordered in currency, those who ordered again and their spend
+	FetchCustomerSummary(currency string) (*models.CustomerSummary, error)
+}
@@ -0,0 +1,124 @@
+// Package repository aggregates the orders of customers for the shop analytics.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/sqlb"
+)
+
+// AnalyticsRepository handles analytics database operations.
+type AnalyticsRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewAnalyticsRepository returns a new AnalyticsRepository.
+func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{
+		DB: db,
+	}
+}
+
+// countedOrders selects the orders counted towards the value of a customer. The status
+// is written out rather than bound, for the predicate of orders_customer_value_idx to
+// match it.
+const countedOrders = "order_status <> '" + models.OrderCancelled + "'"
+
+// customerOrders are the orders of models.CustomerSorts.
+var customerOrders = sqlb.Sort{
+	models.CustomersOrders:    "count(*)",
+	models.CustomersSpent:     "sum(o.total_price)",
+	models.CustomersAverage:   "round(avg(o.total_price))::bigint",
+	models.CustomersLastOrder: "max(o.created_at)",
+}
+
+// FetchCustomerValues returns the page of the customers who ordered in the currency of
+// filter, with the count, sum, average and dates of their orders that are not
+// cancelled. A Sort that is not one of models.CustomerSorts lists the customers who
+// spent most first.
+func (r *AnalyticsRepository) FetchCustomerValues(filter models.CustomerFilter) ([]models.CustomerValue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	limit := filter.PerPage
+	if limit <= 0 {
+		limit = 20
+	}
+	page := filter.Page
+	if page < 1 {
+		page = 1
+	}
+
+	q := sqlb.Select("u.user_id, u.name, u.email, count(*), sum(o.total_price), "+
+		customerOrders[models.CustomersAverage]+", min(o.created_at), max(o.created_at)").
+		From("orders o join users u on u.user_id = o.user_id").
+		Where(sqlb.Expr("o.currency = ?", filter.Currency), sqlb.Expr("o."+countedOrders)).
+		GroupBy("u.user_id")
+	order, ok := customerOrders.Order(filter.Sort)
+	if !ok {
+		order = customerOrders[models.CustomersSpent] + " desc"
+	}
+	query, args := q.OrderBy(order, "u.user_id").Page(limit, (page-1)*limit).Build()
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var customers []models.CustomerValue
+	for rows.Next() {
+		var c models.CustomerValue
+		err := rows.Scan(
+			&c.UserID,
+			&c.Name,
+			&c.Email,
+			&c.Orders,
+			&c.Spent,
+			&c.AverageOrder,
+			&c.FirstOrderAt,
+			&c.LastOrderAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		c.Spent = c.Spent.WithCurrency(filter.Currency)
+		c.AverageOrder = c.AverageOrder.WithCurrency(filter.Currency)
+
+		customers = append(customers, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return customers, nil
+}
+
+// FetchCustomerSummary returns how many customers ordered in currency, how many of
+// them more than once and what they spent, cancelled orders left out.
+func (r *AnalyticsRepository) FetchCustomerSummary(currency string) (*models.CustomerSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := `select count(*), count(*) filter (where c.orders > 1), coalesce(sum(c.spent), 0)
+		from (
+			select count(*) as orders, sum(total_price) as spent
+			from orders
+			where currency = $1 and ` + countedOrders + `
+			group by user_id
+		) c`
+
+	s := models.CustomerSummary{Currency: currency}
+	err := r.DB.QueryRowContext(ctx, query, currency).Scan(&s.Customers, &s.RepeatCustomers, &s.Revenue)
+	if err != nil {
+		return nil, err
+	}
+	s.Revenue = s.Revenue.WithCurrency(currency)
+
+	return &s, nil
+}
@@ -0,0 +1,86 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/analytics/repository"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchCustomerValues(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAnalyticsRepository(db)
+	columns := []string{"user_id", "name", "email", "orders", "spent", "average", "first_order", "last_order"}
+	first, last := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Page of customers in the sort asked for", func(t *testing.T) {
+		userID := uuid.New()
+		mock.ExpectQuery(regexp.QuoteMeta(`select u.user_id, u.name, u.email, count(*), sum(o.total_price), `+
+			`round(avg(o.total_price))::bigint, min(o.created_at), max(o.created_at) from orders o join users u on `+
+			`u.user_id = o.user_id where o.currency = $1 and o.order_status <> 'Cancelled' group by u.user_id `+
+			`order by max(o.created_at) desc, u.user_id limit $2 offset $3`)).
+			WithArgs("EUR", 10, 20).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(userID, "Ama", "ama@example.com", 3, "15000", "5000", first, last))
+
+		customers, err := repo.FetchCustomerValues(models.CustomerFilter{Currency: "EUR", Sort: "-last_order", Page: 3, PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, customers, 1)
+		assert.Equal(t, userID, customers[0].UserID)
+		assert.Equal(t, 3, customers[0].Orders)
+		assert.Equal(t, money.New(15000, "EUR"), customers[0].Spent)
+		assert.Equal(t, money.New(5000, "EUR"), customers[0].AverageOrder)
+		assert.Equal(t, last, customers[0].LastOrderAt)
+	})
+
+	t.Run("Biggest spenders first by default", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`order by sum(o.total_price) desc, u.user_id limit $2 offset $3`)).
+			WithArgs("USD", 20, 0).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		customers, err := repo.FetchCustomerValues(models.CustomerFilter{Currency: "USD"})
+		require.NoError(t, err)
+		assert.Empty(t, customers)
+	})
+
+	t.Run("Average order rounded to whole units", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`order by round(avg(o.total_price))::bigint desc, u.user_id`)).
+			WithArgs("USD", 20, 0).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(uuid.New(), "Kofi", "kofi@example.com", 2, "3001", "1501",
+				first, last))
+
+		customers, err := repo.FetchCustomerValues(models.CustomerFilter{Currency: "USD", Sort: "-average"})
+		require.NoError(t, err)
+		require.Len(t, customers, 1)
+		assert.Equal(t, money.New(1501, "USD"), customers[0].AverageOrder)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchCustomerSummary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAnalyticsRepository(db)
+
+	mock.ExpectQuery(`select count\(\*\), count\(\*\) filter \(where c.orders > 1\), .* from orders where currency = \$1 and order_status <> 'Cancelled' group by user_id`).
+		WithArgs("USD").
+		WillReturnRows(sqlmock.NewRows([]string{"customers", "repeat", "spent"}).AddRow(40, 10, 200000))
+
+	s, err := repo.FetchCustomerSummary("USD")
+	require.NoError(t, err)
+	assert.Equal(t, &models.CustomerSummary{Currency: "USD", Customers: 40, RepeatCustomers: 10,
+		Revenue: money.New(200000, "USD")}, s)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,8 @@
+package analytics
+
+import "github.com/jofosuware/go/shopit/internal/models"
+
+type AnalyticsUC interface {
+	// GetCustomerValues returns the summary of the customers who ordered in the currency of filter and a page of them
+	GetCustomerValues(filter models.CustomerFilter) (*models.CustomerSummary, []models.CustomerValue, error)
+}
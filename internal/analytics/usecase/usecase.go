@@ -0,0 +1,82 @@
+// Package usecase computes the lifetime value and repeat purchases of customers.
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jofosuware/go/shopit/internal/analytics"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// Customer page sizes
+const (
+	// DefaultCustomerLimit is how many customers are listed a page without a limit
+	DefaultCustomerLimit = 20
+	// MaxCustomerLimit caps the customers listed a page
+	MaxCustomerLimit = 100
+)
+
+// AnalyticsUC provides analytics use cases.
+type AnalyticsUC struct {
+	repo analytics.Repo
+}
+
+// NewAnalyticsUC returns a new AnalyticsUC.
+func NewAnalyticsUC(repo analytics.Repo) *AnalyticsUC {
+	return &AnalyticsUC{
+		repo: repo,
+	}
+}
+
+// GetCustomerValues returns the summary of the customers who ordered in the currency
+// of filter, the shop currency when it is empty, and the page of them filter selects.
+// Customers are listed biggest spenders first without a Sort; a non-positive PerPage
+// stands for DefaultCustomerLimit, and PerPage is capped at MaxCustomerLimit. It
+// returns analytics.ErrInvalidCurrency or analytics.ErrInvalidSort for a filter it
+// cannot apply.
+func (u *AnalyticsUC) GetCustomerValues(filter models.CustomerFilter) (*models.CustomerSummary, []models.CustomerValue, error) {
+	filter.Currency = strings.ToUpper(filter.Currency)
+	if filter.Currency == "" {
+		filter.Currency = money.DefaultCurrency
+	}
+	if !money.Supported(filter.Currency) {
+		return nil, nil, analytics.ErrInvalidCurrency
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = "-" + models.CustomersSpent
+	}
+	if !slices.Contains(models.CustomerSorts, strings.TrimPrefix(filter.Sort, "-")) {
+		return nil, nil, analytics.ErrInvalidSort
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PerPage <= 0 {
+		filter.PerPage = DefaultCustomerLimit
+	}
+	if filter.PerPage > MaxCustomerLimit {
+		filter.PerPage = MaxCustomerLimit
+	}
+
+	summary, err := u.repo.FetchCustomerSummary(filter.Currency)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching customer summary: %v", err)
+	}
+	summary.AverageValue = money.New(0, filter.Currency)
+	if summary.Customers > 0 {
+		summary.RepeatRate = float64(summary.RepeatCustomers) / float64(summary.Customers)
+		summary.AverageValue = money.New(summary.Revenue.Amount/int64(summary.Customers), filter.Currency)
+	}
+
+	customers, err := u.repo.FetchCustomerValues(filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching customer values: %v", err)
+	}
+
+	return summary, customers, nil
+}
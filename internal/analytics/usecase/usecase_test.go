@@ -0,0 +1,63 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/analytics"
+	"github.com/jofosuware/go/shopit/internal/analytics/mocks"
+	"github.com/jofosuware/go/shopit/internal/analytics/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCustomerValues(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewAnalyticsUC(repo)
+
+	t.Run("Summary and page of customers", func(t *testing.T) {
+		filter := models.CustomerFilter{Currency: money.DefaultCurrency, Sort: "-" + models.CustomersSpent, Page: 1,
+			PerPage: usecase.DefaultCustomerLimit}
+		list := []models.CustomerValue{{UserID: uuid.New(), Orders: 3, Spent: money.New(15000, money.DefaultCurrency)}}
+		repo.On("FetchCustomerSummary", money.DefaultCurrency).Return(&models.CustomerSummary{
+			Currency: money.DefaultCurrency, Customers: 40, RepeatCustomers: 10, Revenue: money.New(200000, money.DefaultCurrency),
+		}, nil).Once()
+		repo.On("FetchCustomerValues", filter).Return(list, nil).Once()
+
+		summary, customers, err := u.GetCustomerValues(models.CustomerFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, list, customers)
+		assert.Equal(t, 0.25, summary.RepeatRate)
+		assert.Equal(t, money.New(5000, money.DefaultCurrency), summary.AverageValue)
+	})
+
+	t.Run("Page size is capped", func(t *testing.T) {
+		filter := models.CustomerFilter{Currency: money.DefaultCurrency, Sort: models.CustomersOrders, Page: 2,
+			PerPage: usecase.MaxCustomerLimit}
+		repo.On("FetchCustomerSummary", money.DefaultCurrency).Return(&models.CustomerSummary{}, nil).Once()
+		repo.On("FetchCustomerValues", filter).Return(nil, nil).Once()
+
+		summary, _, err := u.GetCustomerValues(models.CustomerFilter{Currency: "usd", Sort: "orders", Page: 2, PerPage: 1000})
+		require.NoError(t, err)
+		assert.Zero(t, summary.RepeatRate)
+		assert.True(t, summary.AverageValue.IsZero())
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		_, _, err := u.GetCustomerValues(models.CustomerFilter{Currency: "XYZ"})
+		assert.ErrorIs(t, err, analytics.ErrInvalidCurrency)
+
+		_, _, err = u.GetCustomerValues(models.CustomerFilter{Sort: "-name"})
+		assert.ErrorIs(t, err, analytics.ErrInvalidSort)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		repo.On("FetchCustomerSummary", money.DefaultCurrency).Return(nil, errors.New("db down")).Once()
+
+		_, _, err := u.GetCustomerValues(models.CustomerFilter{})
+		assert.ErrorContains(t, err, "db down")
+	})
+}
@@ -0,0 +1,59 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AssetsUC is an autogenerated mock type for the AssetsUC type
+type AssetsUC struct {
+	mock.Mock
+}
+
+// ReconcileOrphans provides a mock function with given fields: ctx
+func (_m *AssetsUC) ReconcileOrphans(ctx context.Context) (*models.ReconcileReport, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReconcileOrphans")
+	}
+
+	var r0 *models.ReconcileReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*models.ReconcileReport, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *models.ReconcileReport); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ReconcileReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAssetsUC creates a new instance of AssetsUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAssetsUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AssetsUC {
+	mock := &AssetsUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,9 @@
+package assets
+
+import "context"
+
+type Repo interface {
+	// FetchPublicIds returns the public ids of every stored asset referenced by the avatar and images tables
+	// or pending in the uploads table
+	FetchPublicIds(ctx context.Context) (map[string]bool, error)
+}
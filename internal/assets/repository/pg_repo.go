@@ -0,0 +1,59 @@
+// Package repository provides persistence lookups for stored assets.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// AssetsRepository handles asset-related database operations.
+type AssetsRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewAssetsRepository returns a new AssetsRepository.
+func NewAssetsRepository(db *sql.DB) *AssetsRepository {
+	return &AssetsRepository{
+		DB: db,
+	}
+}
+
+// FetchPublicIds returns the public ids referenced by the avatar, images and
+// review_images tables, and those of uploads that can still be attached.
+func (r *AssetsRepository) FetchPublicIds(ctx context.Context) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := `
+			select public_id from avatar
+			union
+			select public_id from images
+			union
+			select public_id from review_images
+			union
+			select public_id from uploads where claimed_at is null and expires_at > current_timestamp
+	`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
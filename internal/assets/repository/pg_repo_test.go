@@ -0,0 +1,51 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jofosuware/go/shopit/internal/assets/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchPublicIds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	repo := repository.NewAssetsRepository(db)
+
+	query := `
+			select public_id from avatar
+			union
+			select public_id from images
+			union
+			select public_id from review_images
+			union
+			select public_id from uploads where claimed_at is null and expires_at > current_timestamp
+	`
+
+	t.Run("public ids are fetched", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"public_id"}).AddRow("avatar/a").AddRow("products/b")
+		mock.ExpectQuery(query).WillReturnRows(rows)
+
+		ids, err := repo.FetchPublicIds(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]bool{"avatar/a": true, "products/b": true}, ids)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(errors.New("database error"))
+
+		ids, err := repo.FetchPublicIds(context.Background())
+		assert.Error(t, err)
+		assert.Nil(t, ids)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,12 @@
+package assets
+
+import (
+	"context"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type AssetsUC interface {
+	// ReconcileOrphans destroys stored assets that no database record refers to
+	ReconcileOrphans(ctx context.Context) (*models.ReconcileReport, error)
+}
@@ -0,0 +1,85 @@
+// Package usecase reconciles stored assets with the database.
+//
+// Uploads happen before their database records are written, so a failed insert or a
+// crash in between leaves an asset that nothing refers to. ReconcileOrphans lists the
+// avatar, product and review folders, cross-checks them against the avatar, images and
+// review_images tables and destroys the leftovers.
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/assets"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+)
+
+// DefaultGracePeriod is the minimum age of an unreferenced asset before it is destroyed.
+// It keeps the job away from uploads whose records are still being written.
+const DefaultGracePeriod = time.Hour
+
+// AssetsUC provides asset maintenance use cases.
+type AssetsUC struct {
+	store cloudinary.AssetStore
+	repo  assets.Repo
+	grace time.Duration
+	now   func() time.Time
+}
+
+// NewAssetsUC returns a new AssetsUC. A non-positive grace falls back to DefaultGracePeriod.
+func NewAssetsUC(store cloudinary.AssetStore, repo assets.Repo, grace time.Duration) *AssetsUC {
+	if grace <= 0 {
+		grace = DefaultGracePeriod
+	}
+
+	return &AssetsUC{
+		store: store,
+		repo:  repo,
+		grace: grace,
+		now:   time.Now,
+	}
+}
+
+// ReconcileOrphans destroys avatars, product images and review images that are not referenced by
+// the database. It stops destroying them once ctx is done.
+func (a *AssetsUC) ReconcileOrphans(ctx context.Context) (*models.ReconcileReport, error) {
+	var stored []cloudinary.Asset
+	for _, folder := range []string{cloudinary.FolderAvatars, cloudinary.FolderProducts, cloudinary.FolderReviews} {
+		list, err := a.store.ListAssets(ctx, folder)
+		if err != nil {
+			return nil, fmt.Errorf("error listing %s assets: %w", folder, err)
+		}
+		stored = append(stored, list...)
+	}
+
+	// Fetch the references after listing so an asset saved in between is never seen as orphaned.
+	refs, err := a.repo.FetchPublicIds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching asset references: %v", err)
+	}
+
+	report := &models.ReconcileReport{Scanned: len(stored)}
+	cutoff := a.now().Add(-a.grace)
+
+	for _, asset := range stored {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if refs[asset.PublicID] || asset.CreatedAt.After(cutoff) {
+			continue
+		}
+
+		report.Orphaned++
+
+		if _, err := a.store.Destroy(ctx, asset.PublicID); err != nil {
+			report.Failed = append(report.Failed, asset.PublicID)
+			continue
+		}
+
+		report.Destroyed++
+	}
+
+	return report, nil
+}
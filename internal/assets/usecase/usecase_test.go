@@ -0,0 +1,62 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/api/uploader"
+	"github.com/jofosuware/go/shopit/internal/assets/mocks"
+	"github.com/jofosuware/go/shopit/internal/assets/usecase"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	mockCloudinary "github.com/jofosuware/go/shopit/pkg/cloudinary/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileOrphans(t *testing.T) {
+	old := time.Now().Add(-2 * time.Hour)
+
+	t.Run("Unreferenced assets are destroyed", func(t *testing.T) {
+		store := mockCloudinary.NewAssetStore(t)
+		repo := mocks.NewRepo(t)
+		a := usecase.NewAssetsUC(store, repo, time.Hour)
+
+		store.On("ListAssets", mock.Anything, cloudinary.FolderAvatars).Return([]cloudinary.Asset{
+			{PublicID: "avatar/kept", CreatedAt: old},
+			{PublicID: "avatar/orphan", CreatedAt: old},
+		}, nil)
+		store.On("ListAssets", mock.Anything, cloudinary.FolderProducts).Return([]cloudinary.Asset{
+			{PublicID: "products/fresh", CreatedAt: time.Now()},
+			{PublicID: "products/orphan", CreatedAt: old},
+		}, nil)
+		store.On("ListAssets", mock.Anything, cloudinary.FolderReviews).Return([]cloudinary.Asset{
+			{PublicID: "reviews/kept", CreatedAt: old},
+		}, nil)
+		repo.On("FetchPublicIds", mock.Anything).Return(map[string]bool{"avatar/kept": true, "reviews/kept": true}, nil)
+		store.On("Destroy", mock.Anything, "avatar/orphan").Return(&uploader.DestroyResult{Result: "ok"}, nil)
+		store.On("Destroy", mock.Anything, "products/orphan").Return(nil, errors.New("provider error"))
+
+		report, err := a.ReconcileOrphans(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, 5, report.Scanned)
+		assert.Equal(t, 2, report.Orphaned)
+		assert.Equal(t, 1, report.Destroyed)
+		assert.Equal(t, []string{"products/orphan"}, report.Failed)
+	})
+
+	t.Run("Listing failure aborts the run", func(t *testing.T) {
+		store := mockCloudinary.NewAssetStore(t)
+		repo := mocks.NewRepo(t)
+		a := usecase.NewAssetsUC(store, repo, time.Hour)
+
+		store.On("ListAssets", mock.Anything, cloudinary.FolderAvatars).Return(nil, cloudinary.ErrListingUnsupported)
+
+		report, err := a.ReconcileOrphans(context.Background())
+		assert.ErrorIs(t, err, cloudinary.ErrListingUnsupported)
+		assert.Nil(t, report)
+	})
+}
@@ -0,0 +1,97 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// PlanCleanup dry runs a bulk cleanup: nothing is deleted, the response tells how many
+// records would be, lists the ids of the first of them and carries the token that
+// confirms the cleanup with RunCleanup. The cleanups are inactive-users, customers
+// created before the cutoff who never ordered, a year ago by default, and
+// expired-sessions, sign-in tokens expired before the cutoff, now by default.
+// Endpoint: POST /api/v1/auth/admin/cleanup/{target}/dry-run
+// Expects JSON: {"before": <RFC 3339 time>}, before optional.
+func (h *AuthHandlers) PlanCleanup(w http.ResponseWriter, r *http.Request) {
+	var req cleanupPlanRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	admin := r.Context().Value(utils.UserContextKey).(*models.User)
+
+	plan, err := h.authUC.PlanCleanup(chi.URLParam(r, "target"), req.Before, admin.ID)
+	if err != nil {
+		if errors.Is(err, auth.ErrUnknownCleanup) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error planning cleanup: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error planning cleanup: %w", err))
+		return
+	}
+
+	jr := struct {
+		Success bool                `json:"success"`
+		Plan    *models.CleanupPlan `json:"plan"`
+	}{
+		Success: true,
+		Plan:    plan,
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// RunCleanup runs a bulk cleanup confirmed by the token of its dry run, which only the
+// admin who ran the dry run can use, once, within 10 minutes. If the records to delete
+// changed since the dry run, nothing is deleted and the response is 409: dry run again.
+// Endpoint: POST /api/v1/auth/admin/cleanup/{target}
+// Expects JSON: {"token": <token of the dry run>}.
+func (h *AuthHandlers) RunCleanup(w http.ResponseWriter, r *http.Request) {
+	var req cleanupRunRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	admin := r.Context().Value(utils.UserContextKey).(*models.User)
+	target := chi.URLParam(r, "target")
+
+	res, err := h.authUC.RunCleanup(r.Context(), target, req.Token, admin.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrCleanupChanged):
+			_ = utils.WriteJSON(w, http.StatusConflict, models.Response{Message: err.Error()})
+			h.logger.Errorf("error running cleanup: %v", err)
+		case errors.Is(err, auth.ErrUnknownCleanup), errors.Is(err, auth.ErrInvalidCleanupToken):
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error running cleanup: %v", err)
+		default:
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error running cleanup: %w", err))
+		}
+		return
+	}
+
+	h.logger.Infof("cleanup %s run by %s: %d deleted", target, admin.ID, res.Deleted)
+
+	jr := struct {
+		Success bool                  `json:"success"`
+		Cleanup *models.CleanupResult `json:"cleanup"`
+	}{
+		Success: true,
+		Cleanup: res,
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
@@ -0,0 +1,111 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/auth/delivery"
+	"github.com/jofosuware/go/shopit/internal/auth/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanup(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	authUC := mocks.NewAuthenticateUC(t)
+	h := delivery.NewAuthHandlers(logger, authUC)
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
+	call := func(handler http.HandlerFunc, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/cleanup/"+target, bytes.NewBufferString(body))
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("target", target)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+		req = req.WithContext(context.WithValue(ctx, UserContextKey, admin))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	t.Run("Dry run", func(t *testing.T) {
+		expires := time.Now().Add(10 * time.Minute)
+		authUC.On("PlanCleanup", models.CleanupInactiveUsers, time.Time{}, admin.ID).Return(&models.CleanupPlan{
+			Target: models.CleanupInactiveUsers, Matched: 2, Sample: []uuid.UUID{uuid.New(), uuid.New()},
+			Token: "TOKEN", ExpiresAt: &expires,
+		}, nil).Once()
+
+		rr := call(h.PlanCleanup, models.CleanupInactiveUsers, `{}`)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Plan models.CleanupPlan `json:"plan"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, 2, res.Plan.Matched)
+		assert.Equal(t, "TOKEN", res.Plan.Token)
+	})
+
+	t.Run("Cutoff in the future", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call(h.PlanCleanup, models.CleanupInactiveUsers, `{"before": "2999-01-01T00:00:00Z"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Unknown cleanup", func(t *testing.T) {
+		authUC.On("PlanCleanup", "orders", time.Time{}, admin.ID).Return(nil, auth.ErrUnknownCleanup).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call(h.PlanCleanup, "orders", `{}`).Code)
+	})
+
+	t.Run("Cleanup is run", func(t *testing.T) {
+		authUC.On("RunCleanup", mock.Anything, models.CleanupInactiveUsers, "TOKEN", admin.ID).
+			Return(&models.CleanupResult{Target: models.CleanupInactiveUsers, Deleted: 2}, nil).Once()
+		logger.On("Infof", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := call(h.RunCleanup, models.CleanupInactiveUsers, `{"token": "TOKEN"}`)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Cleanup models.CleanupResult `json:"cleanup"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, int64(2), res.Cleanup.Deleted)
+	})
+
+	t.Run("Token is required", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, call(h.RunCleanup, models.CleanupInactiveUsers, `{}`).Code)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		authUC.On("RunCleanup", mock.Anything, models.CleanupInactiveUsers, "TOKEN", admin.ID).
+			Return(nil, auth.ErrInvalidCleanupToken).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call(h.RunCleanup, models.CleanupInactiveUsers, `{"token": "TOKEN"}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Records changed since the dry run", func(t *testing.T) {
+		authUC.On("RunCleanup", mock.Anything, models.CleanupInactiveUsers, "TOKEN", admin.ID).
+			Return(nil, auth.ErrCleanupChanged).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call(h.RunCleanup, models.CleanupInactiveUsers, `{"token": "TOKEN"}`)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
@@ -4,6 +4,7 @@
 package delivery
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,7 +13,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/internal/auth"
 	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
 	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/moderation"
 	"github.com/jofosuware/go/shopit/pkg/utils"
 	"github.com/jofosuware/go/shopit/pkg/validator"
 )
@@ -40,50 +43,47 @@ func NewAuthHandlers(
 
 // Register registers a new user.
 // Endpoint: POST /api/v1/auth/register
-// Expects multipart form data: name, email, password, avatar.
+// Expects multipart form data: name, email, password, avatar (optional).
 func (h *AuthHandlers) Register(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseMultipartForm(100000)
-	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("parsing multipart form error: %v", err)
-		return
-	}
-
-	name := r.FormValue("name")
-	email := r.FormValue("email")
-	password := r.FormValue("password")
-	avatar := r.FormValue("avatar")
-
-	// validate data
-	v := validator.New()
-	v.Check(name != "", "name", "user name must be provided")
-	v.Check(email != "", "email", "user email must be provided")
-	v.Check(len(password) > 7, "password", "password must be at least 8 characters")
-	v.Check(avatar != "", "avatar", "user avatar must be provided")
-
-	if !v.Valid() {
-		utils.FailedValidation(w, r, v.Errors)
-		h.logger.Errorf("Failed validation: %v", v.Errors)
+	var req registerRequest
+	if !utils.Bind(w, r, h.logger, &req) {
 		return
 	}
 
 	u := models.User{
-		Name:     name,
-		Email:    email,
-		Password: password,
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: req.Password,
 		Role:     "user",
 	}
 
-	res, err := h.authUC.Register(u, avatar)
+	res, err := h.authUC.Register(r.Context(), u, req.Avatar)
 	if err != nil {
+		var avatarErr *auth.AvatarError
+		if errors.As(err, &avatarErr) {
+			v := validator.New()
+			v.AddError("avatar", avatarErr.Reason)
+			utils.FailedValidation(w, r, v)
+			h.logger.Errorf("Error registering user: %v", err)
+			return
+		}
+		if errors.Is(err, moderation.ErrUnavailable) {
+			_ = utils.ServiceUnavailable(w, r, errors.New("avatar moderation is temporarily unavailable, try again later"))
+			h.logger.Errorf("Error registering user: %v", err)
+			return
+		}
+		if errors.Is(err, auth.ErrDuplicateEmail) {
+			_ = utils.WriteJSON(w, http.StatusConflict, models.Response{Message: auth.ErrDuplicateEmail.Error()})
+			h.logger.Errorf("Error registering user: %v", err)
+			return
+		}
 		_ = utils.BadRequest(w, r, errors.New("error registering user"))
 		h.logger.Errorf("Error registering user: %v", err)
 		return
 	}
 
 	if err := utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("writing json error: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 
@@ -93,37 +93,25 @@ func (h *AuthHandlers) Register(w http.ResponseWriter, r *http.Request) {
 // Endpoint: POST /api/v1/auth/login
 // Expects JSON body: email, password.
 func (h *AuthHandlers) Login(w http.ResponseWriter, r *http.Request) {
-	var u *models.User
-
-	// Parse json into user struct
-	err := utils.ReadJSON(w, r, &u)
-	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("reading json error: %v", err)
-		return
-	}
-
-	// validate data
-	v := validator.New()
-	v.Check(u.Email != "", "email", "user email must be provided")
-	v.Check(len(u.Password) > 7, "password", "password must be at least 8 characters")
-
-	if !v.Valid() {
-		utils.FailedValidation(w, r, v.Errors)
-		h.logger.Errorf("Failed validation: %v", v.Errors)
+	var req loginRequest
+	if !utils.Bind(w, r, h.logger, &req) {
 		return
 	}
 
-	res, err := h.authUC.Login(u.Email, u.Password)
+	res, err := h.authUC.Login(req.Email, req.Password, r)
 	if err != nil {
+		if errors.Is(err, auth.ErrPendingDeletion) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("Error logging in user: %v", err)
+			return
+		}
 		_ = utils.BadRequest(w, r, errors.New("error logging in user, invalid user or user does not exists"))
 		h.logger.Errorf("Error logging in user: %v", err)
 		return
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 
@@ -133,26 +121,12 @@ func (h *AuthHandlers) Login(w http.ResponseWriter, r *http.Request) {
 // Endpoint: POST /api/v1/auth/password/forgot
 // Expects form data: email.
 func (h *AuthHandlers) SendPasswordResetEmail(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseMultipartForm(10000)
-	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("parsing form error: %v", err)
-		return
-	}
-
-	email := r.Form.Get("email")
-
-	// validate data
-	v := validator.New()
-	v.Check(email != "", "email", "user email must be provided")
-
-	if !v.Valid() {
-		utils.FailedValidation(w, r, v.Errors)
-		h.logger.Errorf("Failed validation: %v", v.Errors)
+	var req emailRequest
+	if !utils.Bind(w, r, h.logger, &req) {
 		return
 	}
 
-	res, err := h.authUC.SendPasswordResetEmail(email, r)
+	res, err := h.authUC.SendPasswordResetEmail(req.Email)
 	if err != nil {
 		_ = utils.BadRequest(w, r, err)
 		h.logger.Errorf("Error sending password reset email: %v", err)
@@ -160,8 +134,7 @@ func (h *AuthHandlers) SendPasswordResetEmail(w http.ResponseWriter, r *http.Req
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 
@@ -173,34 +146,18 @@ func (h *AuthHandlers) SendPasswordResetEmail(w http.ResponseWriter, r *http.Req
 func (h *AuthHandlers) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	t := chi.URLParam(r, "token")
 
-	err := r.ParseMultipartForm(10000)
-	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("parsing form error: %v", err)
+	var req resetPasswordRequest
+	if !utils.Bind(w, r, h.logger, &req) {
 		return
 	}
 
-	password := r.Form.Get("password")
-	confirm := r.Form.Get("confirmPassword")
-
-	// validate data
-	v := validator.New()
-	v.Check(password != "", "password", "password must be provided")
-	v.Check(confirm != "", "confirmPassword", "confirm password must be provided")
-
-	if !v.Valid() {
-		utils.FailedValidation(w, r, v.Errors)
-		h.logger.Errorf("Failed validation: %v", v.Errors)
-		return
-	}
-
-	if password != confirm {
+	if req.Password != req.ConfirmPassword {
 		_ = utils.BadRequest(w, r, errors.New("passwors mismatch"))
 		h.logger.Info("Passwords mismatch")
 		return
 	}
 
-	res, err := h.authUC.ResetPassword(t, password)
+	res, err := h.authUC.ResetPassword(t, req.Password)
 	if err != nil {
 		_ = utils.BadRequest(w, r, errors.New("password reset unsuccessful, try again later"))
 		h.logger.Errorf("Error resetting password: %v", err)
@@ -208,8 +165,7 @@ func (h *AuthHandlers) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("writing json error: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
@@ -238,47 +194,78 @@ func (h *AuthHandlers) GetUserProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := utils.WriteJSON(w, http.StatusOK, jr); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
 
-// UpdatePassword updates the authenticated user's password.
-// Endpoint: POST /api/v1/auth/password/update
-// Expects form data: oldPassword, password.
-func (h *AuthHandlers) UpdatePassword(w http.ResponseWriter, r *http.Request) {
+// DeleteAccount schedules the deletion of the authenticated user's account. The user
+// is signed out and emailed a link to restore the account during the grace period.
+// Endpoint: DELETE /api/v1/auth/me
+func (h *AuthHandlers) DeleteAccount(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(UserContextKey).(*models.User)
 	if !ok {
-		_ = utils.BadRequest(w, r, errors.New(""))
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
 		h.logger.Error("unable to retrieve user from session")
 		return
 	}
 
-	err := r.ParseMultipartForm(10000)
+	res, err := h.authUC.ScheduleDeletion(user.ID)
 	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("parsing form error: %v", err)
+		if errors.Is(err, auth.ErrPendingDeletion) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error scheduling account deletion: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error scheduling account deletion: %w", err))
+		return
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
+}
 
-	password := r.Form.Get("password")
-	oldPassword := r.Form.Get("oldPassword")
+// RestoreAccount cancels the scheduled deletion of an account using the emailed link.
+// Endpoint: PUT /api/v1/auth/account/restore/{token}
+func (h *AuthHandlers) RestoreAccount(w http.ResponseWriter, r *http.Request) {
+	res, err := h.authUC.RestoreAccount(chi.URLParam(r, "token"))
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidRestoreLink) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error restoring account: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error restoring account: %w", err))
+		return
+	}
 
-	// validate data
-	v := validator.New()
-	v.Check(password != "", "password", "password must be provided")
-	v.Check(oldPassword != "", "oldPassword", "old password must be provided")
+	if err := utils.WriteJSON(w, http.StatusOK, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// UpdatePassword updates the authenticated user's password.
+// Endpoint: POST /api/v1/auth/password/update
+// Expects form data: oldPassword, password.
+func (h *AuthHandlers) UpdatePassword(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New(""))
+		h.logger.Error("unable to retrieve user from session")
+		return
+	}
 
-	if !v.Valid() {
-		utils.FailedValidation(w, r, v.Errors)
-		h.logger.Errorf("Failed validation: %v", v.Errors)
+	var req updatePasswordRequest
+	if !utils.Bind(w, r, h.logger, &req) {
 		return
 	}
 
 	passwords := models.Passwords{
-		Password:    password,
-		OldPassword: oldPassword,
+		Password:    req.Password,
+		OldPassword: req.OldPassword,
 	}
 
 	res, err := h.authUC.UpdatePassword(user.ID, passwords)
@@ -289,15 +276,15 @@ func (h *AuthHandlers) UpdatePassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
 
 // UpdateProfile updates the authenticated user's profile and avatar.
 // Endpoint: POST /api/v1/auth/me/update
-// Expects form data: name, email, avatar.
+// Expects form data: name, email, avatar, and optionally phone (E.164), dateOfBirth
+// (YYYY-MM-DD) and newsletter (bool); left out, they keep their value.
 func (h *AuthHandlers) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(UserContextKey).(*models.User)
 	if !ok {
@@ -306,35 +293,34 @@ func (h *AuthHandlers) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := r.ParseMultipartForm(10000)
-	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("parsing form error: %v", err)
+	var req updateProfileRequest
+	if !utils.Bind(w, r, h.logger, &req) {
 		return
 	}
 
-	name := r.Form.Get("name")
-	email := r.Form.Get("email")
-	avatar := r.FormValue("avatar")
-
-	// validate data
-	v := validator.New()
-
-	v.Check(name != "", "name", "name must be provided")
-	v.Check(email != "", "email", "email must be provided")
-	v.IsEmailValid(email, "email", "email must be valid")
+	user.Name = req.Name
+	user.Email = req.Email
 
-	if !v.Valid() {
-		utils.FailedValidation(w, r, v.Errors)
-		h.logger.Errorf("Failed validation: %v", v.Errors)
-		return
-	}
-
-	user.Name = name
-	user.Email = email
-
-	err = h.authUC.UpdateProfile(*user, avatar)
+	err := h.authUC.UpdateProfile(r.Context(), *user, req.profile(), req.Avatar)
 	if err != nil {
+		var avatarErr *auth.AvatarError
+		if errors.As(err, &avatarErr) {
+			v := validator.New()
+			v.AddError("avatar", avatarErr.Reason)
+			utils.FailedValidation(w, r, v)
+			h.logger.Errorf("Error updating profile: %v", err)
+			return
+		}
+		if errors.Is(err, moderation.ErrUnavailable) {
+			_ = utils.ServiceUnavailable(w, r, errors.New("avatar moderation is temporarily unavailable, try again later"))
+			h.logger.Errorf("Error updating profile: %v", err)
+			return
+		}
+		if errors.Is(err, cloudinary.ErrUnavailable) {
+			_ = utils.ServiceUnavailable(w, r, errors.New("image storage is temporarily unavailable, try again later"))
+			h.logger.Errorf("Error updating profile: %v", err)
+			return
+		}
 		_ = utils.BadRequest(w, r, err)
 		h.logger.Errorf("Error updating profile: %v", err)
 		return
@@ -347,8 +333,7 @@ func (h *AuthHandlers) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
@@ -381,8 +366,7 @@ func (h *AuthHandlers) Logout(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
@@ -406,8 +390,7 @@ func (h *AuthHandlers) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
@@ -443,15 +426,15 @@ func (h *AuthHandlers) GetUserDetails(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
 
-// UpdateUser updates a user's profile (admin).
+// UpdateUser updates a user's profile (admin). Their role is changed with UpdateUserRole.
 // Endpoint: PUT /api/v1/auth/admin/user/{id}
-// Expects URL param: id (UUID) and form data: name, email, role.
+// Expects URL param: id (UUID) and form data: name, email, and optionally phone,
+// dateOfBirth and newsletter as in UpdateProfile.
 func (h *AuthHandlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
@@ -467,37 +450,17 @@ func (h *AuthHandlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = r.ParseMultipartForm(100000)
-	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error parsing multipart form: %v", err)
-		return
-	}
-
-	name := r.Form.Get("name")
-	email := r.Form.Get("email")
-	role := r.Form.Get("role")
-
-	fmt.Printf("Name: %s and email: %s", name, email)
-
-	v := validator.New()
-
-	v.Check(name != "", "name", "user name must be provided")
-	v.Check(email != "", "email", "user email must be provided")
-
-	if !v.Valid() {
-		_ = utils.BadRequest(w, r, errors.New("invalid input"))
-		h.logger.Error("invalid input")
+	var req updateUserRequest
+	if !utils.Bind(w, r, h.logger, &req) {
 		return
 	}
 
 	user := models.User{
-		Name:  name,
-		Email: email,
-		Role:  role,
+		Name:  req.Name,
+		Email: req.Email,
 	}
 
-	res, err := h.authUC.UpdateUser(userID, user)
+	res, err := h.authUC.UpdateUser(userID, user, req.profile())
 	if err != nil {
 		_ = utils.BadRequest(w, r, err)
 		h.logger.Errorf("error updating user: %v", err)
@@ -505,8 +468,7 @@ func (h *AuthHandlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
@@ -529,8 +491,13 @@ func (h *AuthHandlers) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.authUC.DeleteUser(userID)
+	err = h.authUC.DeleteUser(r.Context(), userID)
 	if err != nil {
+		if errors.Is(err, cloudinary.ErrUnavailable) {
+			_ = utils.ServiceUnavailable(w, r, errors.New("image storage is temporarily unavailable, try again later"))
+			h.logger.Errorf("error deleting user: %v", err)
+			return
+		}
 		_ = utils.BadRequest(w, r, err)
 		h.logger.Errorf("error deleting user: %v", err)
 		return
@@ -541,8 +508,129 @@ func (h *AuthHandlers) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// CreateUser creates a user with a temporary password emailed to them (admin).
+// Endpoint: POST /api/v1/auth/admin/user
+// Form fields: name, email, role (optional, defaults to user).
+func (h *AuthHandlers) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req userRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	user := models.User{
+		Name:  req.Name,
+		Email: req.Email,
+		Role:  req.Role,
+	}
+
+	res, err := h.authUC.CreateUser(user)
+	if err != nil {
+		if errors.Is(err, auth.ErrDuplicateEmail) {
+			_ = utils.WriteJSON(w, http.StatusConflict, models.Response{Message: auth.ErrDuplicateEmail.Error()})
+			h.logger.Errorf("error creating user: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error creating user: %w", err))
+		return
+	}
+
+	if err = utils.WriteJSON(w, http.StatusCreated, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// UpdateCurrency sets the currency the current user prefers to shop in. Requests
+// without an X-Currency header are served in it.
+// Endpoint: PUT /api/v1/auth/me/currency
+// Form fields: currency (an accepted currency code, empty for the shop currency).
+func (h *AuthHandlers) UpdateCurrency(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	var req currencyRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	res, err := h.authUC.SetCurrency(user.ID, req.Currency)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error updating currency: %w", err))
+		return
+	}
+
+	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// UpdateLocale sets the locale the current user reads emails and invoices in.
+// Endpoint: PUT /api/v1/auth/me/locale
+// Form fields: locale (a supported locale code, empty for the shop locale).
+func (h *AuthHandlers) UpdateLocale(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	var req localeRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	res, err := h.authUC.SetLocale(user.ID, req.Locale)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error updating locale: %w", err))
+		return
+	}
+
+	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// UpdateUserRole changes the role of a user (admin).
+// Endpoint: PATCH /api/v1/auth/admin/user/{id}/role
+// Form fields: role (one of models.Roles).
+func (h *AuthHandlers) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, errors.New("invalid user id"))
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	var req roleRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	res, err := h.authUC.UpdateUserRole(userID, req.Role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = utils.BadRequest(w, r, errors.New("user not found"))
+			h.logger.Errorf("error updating user role: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error updating user role: %w", err))
+		return
+	}
+
+	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
@@ -8,18 +8,26 @@ package delivery_test
 import (
 	"bytes"
 	"context"
+	"database/sql"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
 	"github.com/jofosuware/go/shopit/internal/auth/delivery"
 	mockAuth "github.com/jofosuware/go/shopit/internal/auth/mocks"
 	"github.com/jofosuware/go/shopit/internal/models"
 	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/moderation"
+	"github.com/jofosuware/go/shopit/pkg/money"
 	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/jofosuware/go/shopit/pkg/validator"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -95,6 +103,43 @@ func TestRegister(t *testing.T) {
 			mockError:  assert.AnError,
 			wantCode:   http.StatusBadRequest,
 		},
+		{
+			name: "authUC.Register avatar rejected",
+			formData: url.Values{
+				"name":     {"John Doe"},
+				"email":    {"user@gmail.com"},
+				"password": {"veryStrongPassword"},
+				"avatar":   {"someImage.jpg"},
+			},
+			avatar:     "someImage.jpg",
+			mockReturn: nil,
+			mockError:  &auth.AvatarError{Reason: "avatar aspect ratio must not exceed 2:1"},
+			wantCode:   http.StatusUnprocessableEntity,
+		},
+		{
+			name: "authUC.Register moderation unavailable",
+			formData: url.Values{
+				"name":     {"John Doe"},
+				"email":    {"user@gmail.com"},
+				"password": {"veryStrongPassword"},
+				"avatar":   {"someImage.jpg"},
+			},
+			avatar:     "someImage.jpg",
+			mockReturn: nil,
+			mockError:  fmt.Errorf("error moderating avatar: %w", moderation.ErrUnavailable),
+			wantCode:   http.StatusServiceUnavailable,
+		},
+		{
+			name: "authUC.Register email taken",
+			formData: url.Values{
+				"name":     {"John Doe"},
+				"email":    {"user@gmail.com"},
+				"password": {"veryStrongPassword"},
+			},
+			mockReturn: nil,
+			mockError:  fmt.Errorf("%w: user@gmail.com", auth.ErrDuplicateEmail),
+			wantCode:   http.StatusConflict,
+		},
 	}
 
 	for _, tt := range tests {
@@ -122,8 +167,8 @@ func TestRegister(t *testing.T) {
 				Role:     "user",
 			}
 
-			if tt.name == "authUC.Register error" {
-				authUC.On("Register", u, tt.avatar).Return(nil, tt.mockError).Once()
+			if strings.HasPrefix(tt.name, "authUC.Register") {
+				authUC.On("Register", mock.Anything, u, tt.avatar).Return(nil, tt.mockError).Once()
 				logger.On("Errorf", mock.Anything, mock.Anything).Once()
 				h.Register(rr, req)
 				assert.Equal(t, tt.wantCode, rr.Code)
@@ -133,10 +178,10 @@ func TestRegister(t *testing.T) {
 			}
 
 			if tt.mockError == nil {
-				authUC.On("Register", u, tt.avatar).Return(tt.mockReturn, nil).Once()
+				authUC.On("Register", mock.Anything, u, tt.avatar).Return(tt.mockReturn, nil).Once()
 			} else {
 				logger.On("Errorf", mock.Anything, mock.Anything).Once()
-				authUC.On("Register", u, tt.avatar).Return(nil, tt.mockError).Maybe()
+				authUC.On("Register", mock.Anything, u, tt.avatar).Return(nil, tt.mockError).Maybe()
 			}
 
 			h.Register(rr, req)
@@ -224,10 +269,10 @@ func TestLogin(t *testing.T) {
 			req, _ := http.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(tt.jsonData))
 			rr := httptest.NewRecorder()
 			if tt.mockError == nil {
-				authUC.On("Login", tt.mockUser.Email, tt.mockUser.Password).Return(tt.mockResp, nil).Once()
+				authUC.On("Login", tt.mockUser.Email, tt.mockUser.Password, mock.Anything).Return(tt.mockResp, nil).Once()
 			} else {
 				logger.On("Errorf", mock.Anything, mock.Anything).Once()
-				authUC.On("Login", tt.mockUser.Email, tt.mockUser.Password).Return(nil, tt.mockError).Once()
+				authUC.On("Login", tt.mockUser.Email, tt.mockUser.Password, mock.Anything).Return(nil, tt.mockError).Once()
 			}
 			h.Login(rr, req)
 			assert.Equal(t, tt.wantCode, rr.Code)
@@ -246,7 +291,7 @@ func TestSendPasswordResetEmail(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, "/send-password-reset-email", formData)
 		require.NoError(t, err)
 		rr := httptest.NewRecorder()
-		authUC.On("SendPasswordResetEmail", "user@gmail.com", req).Return(&models.Response{}, nil).Once()
+		authUC.On("SendPasswordResetEmail", "user@gmail.com").Return(&models.Response{}, nil).Once()
 		req.Header.Set("Content-Type", ct)
 		h.SendPasswordResetEmail(rr, req)
 		assert.Equal(t, http.StatusOK, rr.Code)
@@ -282,7 +327,7 @@ func TestSendPasswordResetEmail(t *testing.T) {
 		require.NoError(t, err)
 		rr := httptest.NewRecorder()
 		req.Header.Set("Content-Type", ct)
-		authUC.On("SendPasswordResetEmail", "user@gmail.com", req).Return(nil, assert.AnError).Once()
+		authUC.On("SendPasswordResetEmail", "user@gmail.com").Return(nil, assert.AnError).Once()
 		logger.On("Errorf", mock.Anything, mock.Anything).Once()
 		h.SendPasswordResetEmail(rr, req)
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
@@ -587,12 +632,69 @@ func TestUpdateProfile(t *testing.T) {
 		ctx := context.WithValue(req.Context(), UserContextKey, &u)
 		req = req.WithContext(ctx)
 
-		authUC.On("UpdateProfile", u, "newAvatar.jpg").Return(nil).Once()
+		authUC.On("UpdateProfile", mock.Anything, u, models.ProfileUpdate{}, "newAvatar.jpg").Return(nil).Once()
+		h.UpdateProfile(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		authUC.AssertExpectations(t)
+	})
+
+	t.Run("Successful update of profile details", func(t *testing.T) {
+		formData := url.Values{}
+		formData.Set("name", "John Doe")
+		formData.Set("email", "john.doe@example.com")
+		formData.Set("phone", " +14155552671 ")
+		formData.Set("dateOfBirth", "1990-05-01")
+		formData.Set("newsletter", "true")
+		body, contentType, err := utils.CreateMultipartForm(formData)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/update-profile", body)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+
+		u := models.User{
+			Name:  "John Doe",
+			Email: "john.doe@example.com",
+		}
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, &u))
+
+		phone, newsletter := "+14155552671", true
+		dob := time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)
+		profile := models.ProfileUpdate{Phone: &phone, DateOfBirth: &dob, Newsletter: &newsletter}
+		authUC.On("UpdateProfile", mock.Anything, u, profile, "").Return(nil).Once()
 		h.UpdateProfile(rr, req)
 		assert.Equal(t, http.StatusOK, rr.Code)
 		authUC.AssertExpectations(t)
 	})
 
+	for name, field := range map[string][2]string{
+		"Validation error - phone not in E.164":      {"phone", "0201234567"},
+		"Validation error - invalid date of birth":   {"dateOfBirth", "01/05/1990"},
+		"Validation error - date of birth in future": {"dateOfBirth", time.Now().AddDate(1, 0, 0).Format("2006-01-02")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			formData := url.Values{}
+			formData.Set("name", "John Doe")
+			formData.Set("email", "john.doe@example.com")
+			formData.Set(field[0], field[1])
+			body, contentType, err := utils.CreateMultipartForm(formData)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/update-profile", body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", contentType)
+			req = req.WithContext(context.WithValue(req.Context(), UserContextKey, &models.User{}))
+			rr := httptest.NewRecorder()
+
+			logger.On("Errorf", mock.Anything, mock.Anything).Once()
+			h.UpdateProfile(rr, req)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), field[0])
+			logger.AssertExpectations(t)
+		})
+	}
+
 	t.Run("Missing user in context", func(t *testing.T) {
 		formData := url.Values{}
 		formData.Set("name", "John Doe")
@@ -716,7 +818,7 @@ func TestUpdateProfile(t *testing.T) {
 
 		rr := httptest.NewRecorder()
 
-		authUC.On("UpdateProfile", mock.Anything, mock.Anything).Return(assert.AnError).Once()
+		authUC.On("UpdateProfile", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError).Once()
 		logger.On("Errorf", mock.Anything, mock.Anything).Once()
 
 		h.UpdateProfile(rr, req)
@@ -726,3 +828,208 @@ func TestUpdateProfile(t *testing.T) {
 		logger.AssertExpectations(t)
 	})
 }
+
+// TestCreateUser tests the CreateUser admin handler, covering success, validation errors and an existing email.
+func TestCreateUser(t *testing.T) {
+	h, logger, authUC := newTestHandler(t)
+
+	t.Run("Successful create user", func(t *testing.T) {
+		formData, ct, _ := utils.CreateMultipartForm(url.Values{"name": {"Jane"}, "email": {"jane@gmail.com"}, "role": {"admin"}})
+		req, err := http.NewRequest(http.MethodPost, "/admin/user", formData)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+		rr := httptest.NewRecorder()
+		authUC.On("CreateUser", models.User{Name: "Jane", Email: "jane@gmail.com", Role: "admin"}).
+			Return(&models.UserResponse{Success: true}, nil).Once()
+		h.CreateUser(rr, req)
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Invalid role", func(t *testing.T) {
+		formData, ct, _ := utils.CreateMultipartForm(url.Values{"name": {"Jane"}, "email": {"jane@gmail.com"}, "role": {"superuser"}})
+		req, err := http.NewRequest(http.MethodPost, "/admin/user", formData)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		h.CreateUser(rr, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Invalid email", func(t *testing.T) {
+		formData, ct, _ := utils.CreateMultipartForm(url.Values{"name": {"Jane"}, "email": {"jane"}})
+		req, err := http.NewRequest(http.MethodPost, "/admin/user", formData)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		h.CreateUser(rr, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), validator.CodeEmail)
+	})
+
+	t.Run("Email already taken", func(t *testing.T) {
+		formData, ct, _ := utils.CreateMultipartForm(url.Values{"name": {"Jane"}, "email": {"taken@gmail.com"}})
+		req, err := http.NewRequest(http.MethodPost, "/admin/user", formData)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+		rr := httptest.NewRecorder()
+		authUC.On("CreateUser", models.User{Name: "Jane", Email: "taken@gmail.com"}).
+			Return(nil, fmt.Errorf("%w: taken@gmail.com", auth.ErrDuplicateEmail)).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		h.CreateUser(rr, req)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
+
+// TestUpdateUserRole tests the UpdateUserRole admin handler, covering success, an invalid role and a missing user.
+func TestUpdateUserRole(t *testing.T) {
+	h, logger, authUC := newTestHandler(t)
+
+	newRequest := func(t *testing.T, id, role string) *http.Request {
+		formData, ct, _ := utils.CreateMultipartForm(url.Values{"role": {role}})
+		req, err := http.NewRequest(http.MethodPatch, "/admin/user/id/role", formData)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+	}
+
+	t.Run("Successful role change", func(t *testing.T) {
+		id := uuid.New()
+		rr := httptest.NewRecorder()
+		authUC.On("UpdateUserRole", id, models.RoleAdmin).Return(&models.UserResponse{Success: true}, nil).Once()
+		h.UpdateUserRole(rr, newRequest(t, id.String(), models.RoleAdmin))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Invalid role", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		h.UpdateUserRole(rr, newRequest(t, uuid.NewString(), "root"))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		id := uuid.New()
+		rr := httptest.NewRecorder()
+		authUC.On("UpdateUserRole", id, models.RoleUser).Return(nil, sql.ErrNoRows).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		h.UpdateUserRole(rr, newRequest(t, id.String(), models.RoleUser))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+// TestUpdateCurrency tests the UpdateCurrency handler, covering an accepted and an unknown currency.
+func TestUpdateCurrency(t *testing.T) {
+	h, logger, authUC := newTestHandler(t)
+	u := models.User{ID: uuid.New()}
+	require.NoError(t, money.Accept("EUR"))
+	t.Cleanup(func() { money.Accept() })
+
+	newRequest := func(t *testing.T, currency string) *http.Request {
+		formData, ct, _ := utils.CreateMultipartForm(url.Values{"currency": {currency}})
+		req, err := http.NewRequest(http.MethodPut, "/me/currency", formData)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+		return req.WithContext(context.WithValue(req.Context(), UserContextKey, &u))
+	}
+
+	t.Run("Currency set", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		authUC.On("SetCurrency", u.ID, "EUR").Return(&models.UserResponse{Success: true}, nil).Once()
+		h.UpdateCurrency(rr, newRequest(t, "EUR"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Currency not accepted", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		h.UpdateCurrency(rr, newRequest(t, "GBP"))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+// TestUpdateLocale tests the UpdateLocale handler, covering a supported and an unknown locale.
+func TestUpdateLocale(t *testing.T) {
+	h, logger, authUC := newTestHandler(t)
+	u := models.User{ID: uuid.New()}
+
+	newRequest := func(t *testing.T, locale string) *http.Request {
+		formData, ct, _ := utils.CreateMultipartForm(url.Values{"locale": {locale}})
+		req, err := http.NewRequest(http.MethodPut, "/me/locale", formData)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+		return req.WithContext(context.WithValue(req.Context(), UserContextKey, &u))
+	}
+
+	t.Run("Locale set", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		authUC.On("SetLocale", u.ID, "fr").Return(&models.UserResponse{Success: true}, nil).Once()
+		h.UpdateLocale(rr, newRequest(t, "fr"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Locale not supported", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		h.UpdateLocale(rr, newRequest(t, "xx"))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+// TestDeleteAccount tests the DeleteAccount handler, covering success and an account already pending deletion.
+func TestDeleteAccount(t *testing.T) {
+	h, logger, authUC := newTestHandler(t)
+	u := models.User{ID: uuid.New(), Email: "jane@gmail.com"}
+
+	newRequest := func(t *testing.T) *http.Request {
+		req, err := http.NewRequest(http.MethodDelete, "/me", nil)
+		require.NoError(t, err)
+		return req.WithContext(context.WithValue(req.Context(), UserContextKey, &u))
+	}
+
+	t.Run("Successful deletion request", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		authUC.On("ScheduleDeletion", u.ID).Return(&models.Response{Success: true}, nil).Once()
+		h.DeleteAccount(rr, newRequest(t))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Already pending deletion", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		authUC.On("ScheduleDeletion", u.ID).Return(nil, auth.ErrPendingDeletion).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		h.DeleteAccount(rr, newRequest(t))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+// TestRestoreAccount tests the RestoreAccount handler, covering success and an invalid link.
+func TestRestoreAccount(t *testing.T) {
+	h, logger, authUC := newTestHandler(t)
+
+	newRequest := func(t *testing.T, token string) *http.Request {
+		req, err := http.NewRequest(http.MethodPut, "/account/restore/"+token, nil)
+		require.NoError(t, err)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("token", token)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+	}
+
+	t.Run("Successful restore", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		authUC.On("RestoreAccount", "tok").Return(&models.Response{Success: true}, nil).Once()
+		h.RestoreAccount(rr, newRequest(t, "tok"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Invalid link", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		authUC.On("RestoreAccount", "bad").Return(nil, auth.ErrInvalidRestoreLink).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		h.RestoreAccount(rr, newRequest(t, "bad"))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,104 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/pkg/realip"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// MagicLinkCookie names the cookie holding the device secret the magic links a client
+// requests are bound to.
+const MagicLinkCookie = "shopit_magic_device"
+
+// magicLinkDeviceAge is how long a client keeps its magic link device secret.
+const magicLinkDeviceAge = 30 * 24 * 60 * 60
+
+// SendMagicLink emails a passwordless sign-in link to a user. The client is given
+// the device cookie the link is bound to, or keeps the one it has.
+// Endpoint: POST /api/v1/auth/magic-link
+// Expects JSON: {"email": <email>}.
+func (h *AuthHandlers) SendMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req emailRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	device := uuid.NewString()
+	if c, err := r.Cookie(MagicLinkCookie); err == nil && c.Value != "" {
+		device = c.Value
+	}
+
+	res, err := h.authUC.SendMagicLink(req.Email, device, r)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error sending magic link: %w", err))
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     MagicLinkCookie,
+		Value:    device,
+		Path:     r.URL.Path,
+		MaxAge:   magicLinkDeviceAge,
+		HttpOnly: true,
+		Secure:   realip.Secure(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if err := utils.WriteJSON(w, http.StatusOK, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// MagicLinkLogin signs in with the magic link opened, on the device that requested
+// it. Opened on another device, it answers 409 until the sign-in is confirmed.
+// Endpoint: GET /api/v1/auth/magic-link?token={token}&hash={signature}
+func (h *AuthHandlers) MagicLinkLogin(w http.ResponseWriter, r *http.Request) {
+	h.magicLinkLogin(w, r, false)
+}
+
+// ConfirmMagicLink signs in with a magic link opened on another device than the one
+// that requested it.
+// Endpoint: PUT /api/v1/auth/magic-link?token={token}&hash={signature}
+func (h *AuthHandlers) ConfirmMagicLink(w http.ResponseWriter, r *http.Request) {
+	h.magicLinkLogin(w, r, true)
+}
+
+func (h *AuthHandlers) magicLinkLogin(w http.ResponseWriter, r *http.Request, confirmed bool) {
+	var device string
+	if c, err := r.Cookie(MagicLinkCookie); err == nil {
+		device = c.Value
+	}
+
+	res, err := h.authUC.MagicLinkLogin(device, confirmed, r)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrMagicLinkUnconfirmed):
+			jr := struct {
+				Success              bool   `json:"success"`
+				Message              string `json:"message"`
+				ConfirmationRequired bool   `json:"confirmationRequired"`
+			}{
+				Message:              err.Error(),
+				ConfirmationRequired: true,
+			}
+			_ = utils.WriteJSON(w, http.StatusConflict, jr)
+		case errors.Is(err, auth.ErrInvalidMagicLink), errors.Is(err, auth.ErrPendingDeletion):
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error signing in with magic link: %v", err)
+		default:
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error signing in with magic link: %w", err))
+		}
+		return
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
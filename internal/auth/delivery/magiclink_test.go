@@ -0,0 +1,135 @@
+package delivery_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/auth/delivery"
+	"github.com/jofosuware/go/shopit/internal/auth/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendMagicLink(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	authUC := mocks.NewAuthenticateUC(t)
+	router := delivery.NewAuthHandlers(logger, authUC).AuthRouter()
+
+	send := func(body string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/magic-link", bytes.NewBufferString(body))
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Link is sent and the device cookie set", func(t *testing.T) {
+		var device string
+		authUC.On("SendMagicLink", "ama@example.com", mock.AnythingOfType("string"), mock.Anything).
+			Run(func(args mock.Arguments) { device = args.String(1) }).
+			Return(&models.Response{Success: true}, nil).Once()
+
+		rr := send(`{"email": "ama@example.com"}`, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		cookies := rr.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, delivery.MagicLinkCookie, cookies[0].Name)
+		assert.Equal(t, device, cookies[0].Value)
+		assert.NotEmpty(t, device)
+		assert.True(t, cookies[0].HttpOnly)
+	})
+
+	t.Run("Device cookie is kept", func(t *testing.T) {
+		authUC.On("SendMagicLink", "ama@example.com", "known-device", mock.Anything).
+			Return(&models.Response{Success: true}, nil).Once()
+
+		rr := send(`{"email": "ama@example.com"}`, &http.Cookie{Name: delivery.MagicLinkCookie, Value: "known-device"})
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Forwarded scheme of an untrusted client is ignored", func(t *testing.T) {
+		authUC.On("SendMagicLink", "ama@example.com", mock.AnythingOfType("string"), mock.Anything).
+			Return(&models.Response{Success: true}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/magic-link", bytes.NewBufferString(`{"email": "ama@example.com"}`))
+		req.Header.Set("X-Forwarded-Proto", "https")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		cookies := rr.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.False(t, cookies[0].Secure)
+	})
+
+	t.Run("Missing email", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, send(`{}`, nil).Code)
+	})
+}
+
+func TestMagicLinkLogin(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	authUC := mocks.NewAuthenticateUC(t)
+	router := delivery.NewAuthHandlers(logger, authUC).AuthRouter()
+
+	open := func(method string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/magic-link?token=TOKEN&hash=.sig", nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Same device signs in", func(t *testing.T) {
+		authUC.On("MagicLinkLogin", "device", false, mock.Anything).
+			Return(&models.UserResponse{Success: true, Token: "session"}, nil).Once()
+
+		rr := open(http.MethodGet, &http.Cookie{Name: delivery.MagicLinkCookie, Value: "device"})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res models.UserResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, "session", res.Token)
+	})
+
+	t.Run("Another device is asked to confirm", func(t *testing.T) {
+		authUC.On("MagicLinkLogin", "", false, mock.Anything).Return(nil, auth.ErrMagicLinkUnconfirmed).Once()
+
+		rr := open(http.MethodGet, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+
+		var res struct {
+			ConfirmationRequired bool `json:"confirmationRequired"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.True(t, res.ConfirmationRequired)
+	})
+
+	t.Run("Confirmed on another device", func(t *testing.T) {
+		authUC.On("MagicLinkLogin", "", true, mock.Anything).
+			Return(&models.UserResponse{Success: true, Token: "session"}, nil).Once()
+
+		assert.Equal(t, http.StatusOK, open(http.MethodPut, nil).Code)
+	})
+
+	t.Run("Used link", func(t *testing.T) {
+		authUC.On("MagicLinkLogin", "", false, mock.Anything).Return(nil, auth.ErrInvalidMagicLink).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, open(http.MethodGet, nil).Code)
+	})
+}
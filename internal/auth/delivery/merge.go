@@ -0,0 +1,54 @@
+package delivery
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// MergeUsers merges two accounts of the same customer. The newest is kept; the data
+// of the other is moved to it and the other is deleted. Both accounts are signed out.
+// Responds with the audit record of the merge.
+// Endpoint: POST /api/v1/auth/admin/user/merge
+// Expects JSON: {"userIds": [<id>, <id>]}.
+func (h *AuthHandlers) MergeUsers(w http.ResponseWriter, r *http.Request) {
+	var req mergeUsersRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	admin := r.Context().Value(utils.UserContextKey).(*models.User)
+
+	m, err := h.authUC.MergeUsers(req.UserIDs[0], req.UserIDs[1], admin.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			_ = utils.BadRequest(w, r, errors.New("user not found"))
+			h.logger.Errorf("error merging users: %v", err)
+		case errors.Is(err, auth.ErrSameUser), errors.Is(err, auth.ErrMergeRoles), errors.Is(err, auth.ErrPendingDeletion):
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error merging users: %v", err)
+		default:
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error merging users: %w", err))
+		}
+		return
+	}
+
+	jr := struct {
+		Success bool                 `json:"success"`
+		Merge   *models.AccountMerge `json:"merge"`
+	}{
+		Success: true,
+		Merge:   m,
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
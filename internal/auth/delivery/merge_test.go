@@ -0,0 +1,83 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/auth/delivery"
+	"github.com/jofosuware/go/shopit/internal/auth/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeUsers(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	authUC := mocks.NewAuthenticateUC(t)
+	h := delivery.NewAuthHandlers(logger, authUC)
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	first, second := uuid.New(), uuid.New()
+
+	merge := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/user/merge", bytes.NewBufferString(body))
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, admin))
+		rr := httptest.NewRecorder()
+		h.MergeUsers(rr, req)
+		return rr
+	}
+	body := fmt.Sprintf(`{"userIds": ["%s", "%s"]}`, first, second)
+
+	t.Run("Accounts are merged", func(t *testing.T) {
+		authUC.On("MergeUsers", first, second, admin.ID).
+			Return(&models.AccountMerge{KeptUserID: second, MergedUserID: first, Orders: 3}, nil).Once()
+
+		rr := merge(body)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Merge models.AccountMerge `json:"merge"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, second, res.Merge.KeptUserID)
+		assert.Equal(t, 3, res.Merge.Orders)
+	})
+
+	t.Run("Two distinct ids are required", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Twice()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, merge(fmt.Sprintf(`{"userIds": ["%s"]}`, first)).Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, merge(fmt.Sprintf(`{"userIds": ["%s", "%s"]}`, first, first)).Code)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		authUC.On("MergeUsers", first, second, admin.ID).Return(nil, sql.ErrNoRows).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, merge(body).Code)
+	})
+
+	t.Run("Different roles", func(t *testing.T) {
+		authUC.On("MergeUsers", first, second, admin.ID).Return(nil, auth.ErrMergeRoles).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, merge(body).Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		authUC.On("MergeUsers", first, second, admin.ID).Return(nil, errors.New("connection reset")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusInternalServerError, merge(body).Code)
+	})
+}
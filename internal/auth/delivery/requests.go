@@ -0,0 +1,174 @@
+package delivery
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// registerRequest is the body of Register.
+type registerRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"min=8"`
+	Avatar   string `json:"avatar"`
+}
+
+// loginRequest is the body of Login.
+type loginRequest struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"min=8"`
+}
+
+// emailRequest is the body of the endpoints sending an email to a user.
+type emailRequest struct {
+	Email string `json:"email" validate:"required"`
+}
+
+// resetPasswordRequest is the body of ResetPassword.
+type resetPasswordRequest struct {
+	Password        string `json:"password" validate:"required"`
+	ConfirmPassword string `json:"confirmPassword" validate:"required"`
+}
+
+// updatePasswordRequest is the body of UpdatePassword.
+type updatePasswordRequest struct {
+	Password    string `json:"password" validate:"required"`
+	OldPassword string `json:"oldPassword" validate:"required"`
+}
+
+// updateProfileRequest is the body of UpdateProfile.
+type updateProfileRequest struct {
+	Name   string `json:"name" validate:"required"`
+	Email  string `json:"email" validate:"required,email"`
+	Avatar string `json:"avatar"`
+	profileFields
+}
+
+// userRequest is the body of the admin endpoint creating users. An empty role leaves
+// the user a customer.
+type userRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required"`
+	Role  string `json:"role"`
+}
+
+func (req *userRequest) Validate(v *validator.Validator) {
+	v.IsEmailValid(req.Email, "email", "email must be a valid email address")
+	v.CheckCode(req.Role == "" || models.ValidRole(req.Role), "role", validator.CodeOneOf, auth.ErrInvalidRole.Error())
+}
+
+// updateUserRequest is the body of UpdateUser: the name and email of a userRequest, with
+// the profile details. Roles only change with UpdateUserRole.
+type updateUserRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required"`
+	profileFields
+}
+
+func (req *updateUserRequest) Validate(v *validator.Validator) {
+	req.profileFields.Validate(v)
+}
+
+// e164 matches phone numbers in the E.164 format: a plus sign, then up to 15 digits
+// starting with the country code.
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// dateOfBirthLayout is the layout of dateOfBirth.
+const dateOfBirthLayout = "2006-01-02"
+
+// profileFields are the optional profile details of the endpoints updating users.
+// Fields left out keep their value; an empty phone or dateOfBirth clears it.
+type profileFields struct {
+	Phone       *string `json:"phone"`
+	DateOfBirth *string `json:"dateOfBirth"`
+	Newsletter  *bool   `json:"newsletter"`
+
+	dateOfBirth time.Time
+}
+
+func (req *profileFields) Validate(v *validator.Validator) {
+	if req.Phone != nil {
+		*req.Phone = strings.TrimSpace(*req.Phone)
+		v.Check(*req.Phone == "" || e164.MatchString(*req.Phone), "phone",
+			"phone must be in the E.164 format, such as +14155552671")
+	}
+
+	if req.DateOfBirth != nil && strings.TrimSpace(*req.DateOfBirth) != "" {
+		dob, err := time.Parse(dateOfBirthLayout, strings.TrimSpace(*req.DateOfBirth))
+		if err != nil {
+			v.AddError("dateOfBirth", "dateOfBirth must be a date (YYYY-MM-DD)")
+			return
+		}
+		v.Check(dob.Before(time.Now()), "dateOfBirth", "dateOfBirth must be in the past")
+		req.dateOfBirth = dob
+	}
+}
+
+// profile returns the update of the details sent.
+func (req *profileFields) profile() models.ProfileUpdate {
+	p := models.ProfileUpdate{Phone: req.Phone, Newsletter: req.Newsletter}
+	if req.DateOfBirth != nil {
+		p.DateOfBirth = &req.dateOfBirth
+	}
+
+	return p
+}
+
+// roleRequest is the body of UpdateUserRole.
+type roleRequest struct {
+	Role string `json:"role"`
+}
+
+func (req *roleRequest) Validate(v *validator.Validator) {
+	v.CheckCode(models.ValidRole(req.Role), "role", validator.CodeOneOf, auth.ErrInvalidRole.Error())
+}
+
+// currencyRequest is the body of UpdateCurrency. An empty currency clears the preference.
+type currencyRequest struct {
+	Currency string `json:"currency"`
+}
+
+func (req *currencyRequest) Validate(v *validator.Validator) {
+	req.Currency = strings.TrimSpace(req.Currency)
+	v.CheckCode(req.Currency == "" || money.Supported(req.Currency), "currency", validator.CodeOneOf,
+		fmt.Sprintf("currency must be one of: %s", strings.Join(money.Currencies(), ", ")))
+}
+
+// localeRequest is the body of UpdateLocale. An empty locale clears the preference.
+type localeRequest struct {
+	Locale string `json:"locale"`
+}
+
+func (req *localeRequest) Validate(v *validator.Validator) {
+	req.Locale = strings.ToLower(strings.TrimSpace(req.Locale))
+	v.CheckCode(req.Locale == "" || i18n.Supported(req.Locale), "locale", validator.CodeOneOf,
+		fmt.Sprintf("locale must be one of: %s", strings.Join(i18n.Locales(), ", ")))
+}
+
+// mergeUsersRequest is the body of MergeUsers.
+type mergeUsersRequest struct {
+	UserIDs []uuid.UUID `json:"userIds" validate:"len=2,unique"`
+}
+
+// cleanupPlanRequest is the body of PlanCleanup. A zero cutoff leaves it to the cleanup.
+type cleanupPlanRequest struct {
+	Before time.Time `json:"before"`
+}
+
+func (req *cleanupPlanRequest) Validate(v *validator.Validator) {
+	v.Check(!req.Before.After(time.Now()), "before", "before must not be in the future")
+}
+
+// cleanupRunRequest is the body of RunCleanup.
+type cleanupRunRequest struct {
+	Token string `json:"token" validate:"required"`
+}
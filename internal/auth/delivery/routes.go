@@ -1,8 +1,8 @@
 // Package delivery provides HTTP routes for authentication endpoints.
 //
-// It wires handler methods for registration, login, password management,
-// profile management, and admin user management, applying authentication
-// middleware where appropriate.
+// It wires handler methods for registration, login, magic link sign-in,
+// password management, profile management, and admin user management,
+// applying authentication middleware where appropriate.
 package delivery
 
 import (
@@ -22,15 +22,31 @@ import (
 //   - POST   /password/forgot         → Send password reset email
 //   - PUT    /password/reset/{token}  → Reset password with token
 //   - GET    /logout/{token}          → Logout user (delete token)
+//   - PUT    /account/restore/{token} → Cancel a scheduled account deletion
+//   - POST   /magic-link              → Email a passwordless sign-in link
+//   - GET    /magic-link              → Sign in with a magic link, on the device that requested it
+//   - PUT    /magic-link              → Confirm a magic link sign-in on another device
 //
 // Authenticated routes (require IsAuthenticated middleware):
 //   - GET    /me                      → Get current user profile
+//   - DELETE /me                      → Schedule deletion of the current user's account
 //   - PUT    /password/update         → Update current user password
 //   - PUT    /me/update               → Update current user profile
-//   - GET    /admin/users             → Get all users (admin)
-//   - GET    /admin/user/{id}         → Get user details by ID (admin)
-//   - PUT    /admin/user/{id}         → Update user by ID (admin)
-//   - DELETE /admin/user/{id}         → Delete user by ID (admin)
+//   - PUT    /me/currency             → Set the currency the current user shops in
+//   - GET    /me/sessions             → List the current user's active sessions
+//   - DELETE /me/sessions             → Sign out of every other session
+//   - DELETE /me/sessions/{id}        → Sign out of one session
+//
+// Admin-only routes (require IsAuthenticated and IsAdmin middleware):
+//   - GET    /admin/users             → Get all users
+//   - GET    /admin/user/{id}         → Get user details by ID
+//   - PUT    /admin/user/{id}         → Update user by ID, but for their role
+//   - DELETE /admin/user/{id}         → Delete user by ID
+//   - POST   /admin/user              → Create a user with an emailed temporary password
+//   - PATCH  /admin/user/{id}/role    → Change a user's role
+//   - POST   /admin/user/merge        → Merge two duplicate accounts into the newest
+//   - POST   /admin/cleanup/{target}/dry-run → Show what a bulk cleanup would delete, with a confirmation token
+//   - POST   /admin/cleanup/{target} → Run a bulk cleanup confirmed by the token of its dry run
 func (h *AuthHandlers) AuthRouter() http.Handler {
 	mux := chi.NewRouter()
 
@@ -40,17 +56,39 @@ func (h *AuthHandlers) AuthRouter() http.Handler {
 	mux.Put("/password/reset/{token}", h.ResetPassword)
 
 	mux.Get("/logout/{token}", h.Logout)
+	mux.Put("/account/restore/{token}", h.RestoreAccount)
+
+	mux.Post("/magic-link", h.SendMagicLink)
+	mux.Get("/magic-link", h.MagicLinkLogin)
+	mux.Put("/magic-link", h.ConfirmMagicLink)
 
 	mux.Group(func(r chi.Router) {
 		r.Use(utils.IsAuthenticated)
 
 		r.Get("/me", h.GetUserProfile)
+		r.Delete("/me", h.DeleteAccount)
 		r.Put("/password/update", h.UpdatePassword)
 		r.Put("/me/update", h.UpdateProfile)
+		r.Put("/me/currency", h.UpdateCurrency)
+		r.Put("/me/locale", h.UpdateLocale)
+		r.Get("/me/sessions", h.GetSessions)
+		r.Delete("/me/sessions", h.RevokeOtherSessions)
+		r.Delete("/me/sessions/{id}", h.RevokeSession)
+	})
+
+	mux.Group(func(r chi.Router) {
+		r.Use(utils.IsAuthenticated)
+		r.Use(utils.IsAdmin)
+
 		r.Get("/admin/users", h.GetAllUsers)
 		r.Get("/admin/user/{id}", h.GetUserDetails)
 		r.Put("/admin/user/{id}", h.UpdateUser)
 		r.Delete("/admin/user/{id}", h.DeleteUser)
+		r.Post("/admin/user", h.CreateUser)
+		r.Patch("/admin/user/{id}/role", h.UpdateUserRole)
+		r.Post("/admin/user/merge", h.MergeUsers)
+		r.Post("/admin/cleanup/{target}/dry-run", h.PlanCleanup)
+		r.Post("/admin/cleanup/{target}", h.RunCleanup)
 	})
 
 	return mux
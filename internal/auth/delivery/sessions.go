@@ -0,0 +1,96 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// GetSessions lists the active sessions of the current user, newest first, with the
+// device each was signed in from. The session of the request is marked current.
+// Endpoint: GET /api/v1/auth/me/sessions
+func (h *AuthHandlers) GetSessions(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(utils.UserContextKey).(*models.User)
+	token, _ := r.Context().Value(utils.TokenContextKey).(string)
+
+	sessions, err := h.authUC.Sessions(user.ID, token)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error listing sessions: %w", err))
+		return
+	}
+
+	res := struct {
+		Success  bool             `json:"success"`
+		Sessions []models.Session `json:"sessions"`
+	}{
+		Success:  true,
+		Sessions: sessions,
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// RevokeSession signs the current user out of one of their sessions, which may be
+// the one of the request.
+// Endpoint: DELETE /api/v1/auth/me/sessions/{id}
+func (h *AuthHandlers) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(utils.UserContextKey).(*models.User)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, errors.New("invalid session id"))
+		h.logger.Errorf("error parsing session id: %v", err)
+		return
+	}
+
+	if err := h.authUC.RevokeSession(user.ID, id); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error revoking session: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error revoking session: %w", err))
+		return
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, models.Response{Success: true, Message: "session revoked"}); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// RevokeOtherSessions signs the current user out of every session but the one of the
+// request, and responds with how many were revoked.
+// Endpoint: DELETE /api/v1/auth/me/sessions
+func (h *AuthHandlers) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(utils.UserContextKey).(*models.User)
+	token, _ := r.Context().Value(utils.TokenContextKey).(string)
+
+	n, err := h.authUC.RevokeOtherSessions(user.ID, token)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error revoking sessions: %w", err))
+		return
+	}
+
+	res := struct {
+		Success bool  `json:"success"`
+		Revoked int64 `json:"revoked"`
+	}{
+		Success: true,
+		Revoked: n,
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
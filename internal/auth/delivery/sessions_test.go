@@ -0,0 +1,111 @@
+package delivery_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/auth/delivery"
+	"github.com/jofosuware/go/shopit/internal/auth/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessions(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	authUC := mocks.NewAuthenticateUC(t)
+	h := delivery.NewAuthHandlers(logger, authUC)
+	user := &models.User{ID: uuid.New()}
+
+	newRequest := func(method, target, id string) *http.Request {
+		req := httptest.NewRequest(method, target, nil)
+		ctx := context.WithValue(req.Context(), UserContextKey, user)
+		ctx = context.WithValue(ctx, utils.TokenContextKey, "MQUYLLXB2PHU5PE6PG3HGG2AXI")
+		if id != "" {
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", id)
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		}
+		return req.WithContext(ctx)
+	}
+
+	t.Run("Sessions are listed", func(t *testing.T) {
+		current := models.Session{ID: uuid.New(), UserAgent: "Firefox", IP: "203.0.113.7",
+			CreatedAt: time.Now(), Expiry: time.Now().Add(time.Hour), Current: true}
+		authUC.On("Sessions", user.ID, "MQUYLLXB2PHU5PE6PG3HGG2AXI").Return([]models.Session{current}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.GetSessions(rr, newRequest(http.MethodGet, "/me/sessions", ""))
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Sessions []models.Session `json:"sessions"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		require.Len(t, res.Sessions, 1)
+		assert.Equal(t, current.ID, res.Sessions[0].ID)
+		assert.True(t, res.Sessions[0].Current)
+	})
+
+	t.Run("A session is revoked", func(t *testing.T) {
+		id := uuid.New()
+		authUC.On("RevokeSession", user.ID, id).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.RevokeSession(rr, newRequest(http.MethodDelete, "/me/sessions/"+id.String(), id.String()))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Unknown session", func(t *testing.T) {
+		id := uuid.New()
+		authUC.On("RevokeSession", user.ID, id).Return(auth.ErrSessionNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.RevokeSession(rr, newRequest(http.MethodDelete, "/me/sessions/"+id.String(), id.String()))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid session id", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.RevokeSession(rr, newRequest(http.MethodDelete, "/me/sessions/abc", "abc"))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Other sessions are revoked", func(t *testing.T) {
+		authUC.On("RevokeOtherSessions", user.ID, "MQUYLLXB2PHU5PE6PG3HGG2AXI").Return(int64(2), nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.RevokeOtherSessions(rr, newRequest(http.MethodDelete, "/me/sessions", ""))
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Revoked int64 `json:"revoked"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, int64(2), res.Revoked)
+	})
+
+	t.Run("Database failure", func(t *testing.T) {
+		authUC.On("RevokeOtherSessions", user.ID, mock.Anything).Return(int64(0), errors.New("db down")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.RevokeOtherSessions(rr, newRequest(http.MethodDelete, "/me/sessions", ""))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
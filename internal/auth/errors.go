@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// ErrDuplicateEmail is returned when registering or creating a user whose email is taken,
+// regardless of case.
+var ErrDuplicateEmail = errors.New("an account with this email already exists")
+
+// ErrInvalidRole is returned when a role is not one of models.Roles.
+var ErrInvalidRole = fmt.Errorf("role must be one of: %s", strings.Join(models.Roles, ", "))
+
+// ErrPendingDeletion is returned when an account is already scheduled for deletion.
+var ErrPendingDeletion = errors.New("account is scheduled for deletion, use the link in the email to restore it")
+
+// ErrInvalidRestoreLink is returned when an account restore link is unknown or expired.
+var ErrInvalidRestoreLink = errors.New("restore link is invalid or has expired")
+
+// ErrInvalidMagicLink is returned when a magic sign-in link is forged, expired or already used.
+var ErrInvalidMagicLink = errors.New("sign-in link is invalid, has expired or was already used")
+
+// ErrMagicLinkUnconfirmed is returned when a magic sign-in link is opened on another device
+// than the one that requested it, until the sign-in is confirmed on that device.
+var ErrMagicLinkUnconfirmed = errors.New("sign-in link was requested from another device, confirm to sign in on this one")
+
+// ErrSessionNotFound is returned when revoking a session the user does not have.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrSameUser is returned when merging an account into itself.
+var ErrSameUser = errors.New("an account cannot be merged into itself")
+
+// ErrMergeRoles is returned when merging accounts that do not have the same role.
+var ErrMergeRoles = errors.New("accounts with different roles cannot be merged")
+
+// ErrUnknownCleanup is returned when a bulk cleanup is not one of models.CleanupTargets.
+var ErrUnknownCleanup = fmt.Errorf("cleanup must be one of: %s", strings.Join(models.CleanupTargets, ", "))
+
+// ErrInvalidCleanupToken is returned when a cleanup is confirmed with a token that is not
+// from a dry run of the same cleanup by the same admin, has expired or was already used.
+var ErrInvalidCleanupToken = errors.New("confirmation token is invalid, has expired or was already used")
+
+// ErrCleanupChanged is returned when the records a cleanup would delete are no longer
+// those of its dry run.
+var ErrCleanupChanged = errors.New("the records to clean up changed since the dry run, run it again")
+
+// AvatarError is returned when an avatar is rejected: it is malformed, too large,
+// too elongated or refused by moderation. Reason is shown to the client.
+type AvatarError struct {
+	Reason string
+}
+
+func (e *AvatarError) Error() string {
+	return e.Reason
+}
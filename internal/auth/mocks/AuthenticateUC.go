@@ -3,11 +3,15 @@
 package mocks
 
 import (
+	context "context"
 	http "net/http"
 
-	models "github.com/jofosuware/go/shopit/internal/models"
 	mock "github.com/stretchr/testify/mock"
 
+	models "github.com/jofosuware/go/shopit/internal/models"
+
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -16,17 +20,75 @@ type AuthenticateUC struct {
 	mock.Mock
 }
 
-// DeleteUser provides a mock function with given fields: userID
-func (_m *AuthenticateUC) DeleteUser(userID uuid.UUID) error {
-	ret := _m.Called(userID)
+// CreateUser provides a mock function with given fields: user
+func (_m *AuthenticateUC) CreateUser(user models.User) (*models.UserResponse, error) {
+	ret := _m.Called(user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUser")
+	}
+
+	var r0 *models.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.User) (*models.UserResponse, error)); ok {
+		return rf(user)
+	}
+	if rf, ok := ret.Get(0).(func(models.User) *models.UserResponse); ok {
+		r0 = rf(user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.User) error); ok {
+		r1 = rf(user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteExpiredTokens provides a mock function with given fields: ctx
+func (_m *AuthenticateUC) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredTokens")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteUser provides a mock function with given fields: ctx, userID
+func (_m *AuthenticateUC) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteUser")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -34,7 +96,7 @@ func (_m *AuthenticateUC) DeleteUser(userID uuid.UUID) error {
 	return r0
 }
 
-// DeleteUserToken provides a mock function with given fields: userID
+// DeleteUserToken provides a mock function with given fields: token
 func (_m *AuthenticateUC) DeleteUserToken(token string) error {
 	ret := _m.Called(token)
 
@@ -112,9 +174,9 @@ func (_m *AuthenticateUC) GetUserDetails(userID uuid.UUID) (*models.User, error)
 	return r0, r1
 }
 
-// Login provides a mock function with given fields: email, password
-func (_m *AuthenticateUC) Login(email string, password string) (*models.UserResponse, error) {
-	ret := _m.Called(email, password)
+// Login provides a mock function with given fields: email, password, r
+func (_m *AuthenticateUC) Login(email string, password string, r *http.Request) (*models.UserResponse, error) {
+	ret := _m.Called(email, password, r)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Login")
@@ -122,19 +184,79 @@ func (_m *AuthenticateUC) Login(email string, password string) (*models.UserResp
 
 	var r0 *models.UserResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (*models.UserResponse, error)); ok {
-		return rf(email, password)
+	if rf, ok := ret.Get(0).(func(string, string, *http.Request) (*models.UserResponse, error)); ok {
+		return rf(email, password, r)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) *models.UserResponse); ok {
-		r0 = rf(email, password)
+	if rf, ok := ret.Get(0).(func(string, string, *http.Request) *models.UserResponse); ok {
+		r0 = rf(email, password, r)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.UserResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(email, password)
+	if rf, ok := ret.Get(1).(func(string, string, *http.Request) error); ok {
+		r1 = rf(email, password, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MagicLinkLogin provides a mock function with given fields: device, confirmed, r
+func (_m *AuthenticateUC) MagicLinkLogin(device string, confirmed bool, r *http.Request) (*models.UserResponse, error) {
+	ret := _m.Called(device, confirmed, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MagicLinkLogin")
+	}
+
+	var r0 *models.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, bool, *http.Request) (*models.UserResponse, error)); ok {
+		return rf(device, confirmed, r)
+	}
+	if rf, ok := ret.Get(0).(func(string, bool, *http.Request) *models.UserResponse); ok {
+		r0 = rf(device, confirmed, r)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, bool, *http.Request) error); ok {
+		r1 = rf(device, confirmed, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MergeUsers provides a mock function with given fields: firstID, secondID, adminID
+func (_m *AuthenticateUC) MergeUsers(firstID uuid.UUID, secondID uuid.UUID, adminID uuid.UUID) (*models.AccountMerge, error) {
+	ret := _m.Called(firstID, secondID, adminID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeUsers")
+	}
+
+	var r0 *models.AccountMerge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID) (*models.AccountMerge, error)); ok {
+		return rf(firstID, secondID, adminID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, uuid.UUID) *models.AccountMerge); ok {
+		r0 = rf(firstID, secondID, adminID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccountMerge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(firstID, secondID, adminID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -142,9 +264,67 @@ func (_m *AuthenticateUC) Login(email string, password string) (*models.UserResp
 	return r0, r1
 }
 
-// Register provides a mock function with given fields: user, avatar
-func (_m *AuthenticateUC) Register(user models.User, avatar string) (*models.UserResponse, error) {
-	ret := _m.Called(user, avatar)
+// PlanCleanup provides a mock function with given fields: target, before, adminID
+func (_m *AuthenticateUC) PlanCleanup(target string, before time.Time, adminID uuid.UUID) (*models.CleanupPlan, error) {
+	ret := _m.Called(target, before, adminID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PlanCleanup")
+	}
+
+	var r0 *models.CleanupPlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, time.Time, uuid.UUID) (*models.CleanupPlan, error)); ok {
+		return rf(target, before, adminID)
+	}
+	if rf, ok := ret.Get(0).(func(string, time.Time, uuid.UUID) *models.CleanupPlan); ok {
+		r0 = rf(target, before, adminID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CleanupPlan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, time.Time, uuid.UUID) error); ok {
+		r1 = rf(target, before, adminID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeDeletedAccounts provides a mock function with given fields: ctx
+func (_m *AuthenticateUC) PurgeDeletedAccounts(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeDeletedAccounts")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Register provides a mock function with given fields: ctx, user, avatar
+func (_m *AuthenticateUC) Register(ctx context.Context, user models.User, avatar string) (*models.UserResponse, error) {
+	ret := _m.Called(ctx, user, avatar)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Register")
@@ -152,19 +332,19 @@ func (_m *AuthenticateUC) Register(user models.User, avatar string) (*models.Use
 
 	var r0 *models.UserResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(models.User, string) (*models.UserResponse, error)); ok {
-		return rf(user, avatar)
+	if rf, ok := ret.Get(0).(func(context.Context, models.User, string) (*models.UserResponse, error)); ok {
+		return rf(ctx, user, avatar)
 	}
-	if rf, ok := ret.Get(0).(func(models.User, string) *models.UserResponse); ok {
-		r0 = rf(user, avatar)
+	if rf, ok := ret.Get(0).(func(context.Context, models.User, string) *models.UserResponse); ok {
+		r0 = rf(ctx, user, avatar)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.UserResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(models.User, string) error); ok {
-		r1 = rf(user, avatar)
+	if rf, ok := ret.Get(1).(func(context.Context, models.User, string) error); ok {
+		r1 = rf(ctx, user, avatar)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -202,9 +382,175 @@ func (_m *AuthenticateUC) ResetPassword(token string, password string) (*models.
 	return r0, r1
 }
 
-// SendPasswordResetEmail provides a mock function with given fields: email, r
-func (_m *AuthenticateUC) SendPasswordResetEmail(email string, r *http.Request) (*models.Response, error) {
-	ret := _m.Called(email, r)
+// RestoreAccount provides a mock function with given fields: token
+func (_m *AuthenticateUC) RestoreAccount(token string) (*models.Response, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreAccount")
+	}
+
+	var r0 *models.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.Response, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.Response); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeOtherSessions provides a mock function with given fields: userID, token
+func (_m *AuthenticateUC) RevokeOtherSessions(userID uuid.UUID, token string) (int64, error) {
+	ret := _m.Called(userID, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeOtherSessions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) (int64, error)); ok {
+		return rf(userID, token)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) int64); ok {
+		r0 = rf(userID, token)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(userID, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeSession provides a mock function with given fields: userID, id
+func (_m *AuthenticateUC) RevokeSession(userID uuid.UUID, id uuid.UUID) error {
+	ret := _m.Called(userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RunCleanup provides a mock function with given fields: ctx, target, token, adminID
+func (_m *AuthenticateUC) RunCleanup(ctx context.Context, target string, token string, adminID uuid.UUID) (*models.CleanupResult, error) {
+	ret := _m.Called(ctx, target, token, adminID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RunCleanup")
+	}
+
+	var r0 *models.CleanupResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, uuid.UUID) (*models.CleanupResult, error)); ok {
+		return rf(ctx, target, token, adminID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, uuid.UUID) *models.CleanupResult); ok {
+		r0 = rf(ctx, target, token, adminID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CleanupResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, uuid.UUID) error); ok {
+		r1 = rf(ctx, target, token, adminID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ScheduleDeletion provides a mock function with given fields: userID
+func (_m *AuthenticateUC) ScheduleDeletion(userID uuid.UUID) (*models.Response, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScheduleDeletion")
+	}
+
+	var r0 *models.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Response, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Response); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendMagicLink provides a mock function with given fields: email, device, r
+func (_m *AuthenticateUC) SendMagicLink(email string, device string, r *http.Request) (*models.Response, error) {
+	ret := _m.Called(email, device, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendMagicLink")
+	}
+
+	var r0 *models.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, *http.Request) (*models.Response, error)); ok {
+		return rf(email, device, r)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, *http.Request) *models.Response); ok {
+		r0 = rf(email, device, r)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, *http.Request) error); ok {
+		r1 = rf(email, device, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendPasswordResetEmail provides a mock function with given fields: email
+func (_m *AuthenticateUC) SendPasswordResetEmail(email string) (*models.Response, error) {
+	ret := _m.Called(email)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendPasswordResetEmail")
@@ -212,19 +558,109 @@ func (_m *AuthenticateUC) SendPasswordResetEmail(email string, r *http.Request)
 
 	var r0 *models.Response
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, *http.Request) (*models.Response, error)); ok {
-		return rf(email, r)
+	if rf, ok := ret.Get(0).(func(string) (*models.Response, error)); ok {
+		return rf(email)
 	}
-	if rf, ok := ret.Get(0).(func(string, *http.Request) *models.Response); ok {
-		r0 = rf(email, r)
+	if rf, ok := ret.Get(0).(func(string) *models.Response); ok {
+		r0 = rf(email)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Response)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, *http.Request) error); ok {
-		r1 = rf(email, r)
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Sessions provides a mock function with given fields: userID, token
+func (_m *AuthenticateUC) Sessions(userID uuid.UUID, token string) ([]models.Session, error) {
+	ret := _m.Called(userID, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sessions")
+	}
+
+	var r0 []models.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) ([]models.Session, error)); ok {
+		return rf(userID, token)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) []models.Session); ok {
+		r0 = rf(userID, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(userID, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetCurrency provides a mock function with given fields: userID, currency
+func (_m *AuthenticateUC) SetCurrency(userID uuid.UUID, currency string) (*models.UserResponse, error) {
+	ret := _m.Called(userID, currency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCurrency")
+	}
+
+	var r0 *models.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) (*models.UserResponse, error)); ok {
+		return rf(userID, currency)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) *models.UserResponse); ok {
+		r0 = rf(userID, currency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(userID, currency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetLocale provides a mock function with given fields: userID, locale
+func (_m *AuthenticateUC) SetLocale(userID uuid.UUID, locale string) (*models.UserResponse, error) {
+	ret := _m.Called(userID, locale)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLocale")
+	}
+
+	var r0 *models.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) (*models.UserResponse, error)); ok {
+		return rf(userID, locale)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) *models.UserResponse); ok {
+		r0 = rf(userID, locale)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(userID, locale)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -262,17 +698,17 @@ func (_m *AuthenticateUC) UpdatePassword(userId uuid.UUID, passwords models.Pass
 	return r0, r1
 }
 
-// UpdateProfile provides a mock function with given fields: user, avatar
-func (_m *AuthenticateUC) UpdateProfile(user models.User, avatar string) error {
-	ret := _m.Called(user, avatar)
+// UpdateProfile provides a mock function with given fields: ctx, user, profile, avatar
+func (_m *AuthenticateUC) UpdateProfile(ctx context.Context, user models.User, profile models.ProfileUpdate, avatar string) error {
+	ret := _m.Called(ctx, user, profile, avatar)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateProfile")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(models.User, string) error); ok {
-		r0 = rf(user, avatar)
+	if rf, ok := ret.Get(0).(func(context.Context, models.User, models.ProfileUpdate, string) error); ok {
+		r0 = rf(ctx, user, profile, avatar)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -280,9 +716,9 @@ func (_m *AuthenticateUC) UpdateProfile(user models.User, avatar string) error {
 	return r0
 }
 
-// UpdateUser provides a mock function with given fields: userID, user
-func (_m *AuthenticateUC) UpdateUser(userID uuid.UUID, user models.User) (*models.UserResponse, error) {
-	ret := _m.Called(userID, user)
+// UpdateUser provides a mock function with given fields: userID, user, profile
+func (_m *AuthenticateUC) UpdateUser(userID uuid.UUID, user models.User, profile models.ProfileUpdate) (*models.UserResponse, error) {
+	ret := _m.Called(userID, user, profile)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateUser")
@@ -290,19 +726,49 @@ func (_m *AuthenticateUC) UpdateUser(userID uuid.UUID, user models.User) (*model
 
 	var r0 *models.UserResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID, models.User) (*models.UserResponse, error)); ok {
-		return rf(userID, user)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.User, models.ProfileUpdate) (*models.UserResponse, error)); ok {
+		return rf(userID, user, profile)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.User, models.ProfileUpdate) *models.UserResponse); ok {
+		r0 = rf(userID, user, profile)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, models.User, models.ProfileUpdate) error); ok {
+		r1 = rf(userID, user, profile)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateUserRole provides a mock function with given fields: userID, role
+func (_m *AuthenticateUC) UpdateUserRole(userID uuid.UUID, role string) (*models.UserResponse, error) {
+	ret := _m.Called(userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserRole")
+	}
+
+	var r0 *models.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) (*models.UserResponse, error)); ok {
+		return rf(userID, role)
 	}
-	if rf, ok := ret.Get(0).(func(uuid.UUID, models.User) *models.UserResponse); ok {
-		r0 = rf(userID, user)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) *models.UserResponse); ok {
+		r0 = rf(userID, role)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.UserResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(uuid.UUID, models.User) error); ok {
-		r1 = rf(userID, user)
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(userID, role)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -3,9 +3,14 @@
 package mocks
 
 import (
-	uuid "github.com/google/uuid"
+	context "context"
+
 	models "github.com/jofosuware/go/shopit/internal/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
 )
 
 // Repo is an autogenerated mock type for the Repo type
@@ -13,17 +18,17 @@ type Repo struct {
 	mock.Mock
 }
 
-// DeleteAvatar provides a mock function with given fields:
-func (_m *Repo) DeleteAvatar() error {
-	ret := _m.Called()
+// CancelUserDeletion provides a mock function with given fields: id
+func (_m *Repo) CancelUserDeletion(id uuid.UUID) error {
+	ret := _m.Called(id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteAvatar")
+		panic("no return value specified for CancelUserDeletion")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -49,6 +54,154 @@ func (_m *Repo) DeleteAvatarById(id string) error {
 	return r0
 }
 
+// DeleteExpiredMagicLinks provides a mock function with given fields: ctx
+func (_m *Repo) DeleteExpiredMagicLinks(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredMagicLinks")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteExpiredTokens provides a mock function with given fields: ctx
+func (_m *Repo) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredTokens")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteInactiveUser provides a mock function with given fields: id, before
+func (_m *Repo) DeleteInactiveUser(id uuid.UUID, before time.Time) (bool, error) {
+	ret := _m.Called(id, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteInactiveUser")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) (bool, error)); ok {
+		return rf(id, before)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) bool); ok {
+		r0 = rf(id, before)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, time.Time) error); ok {
+		r1 = rf(id, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteOtherSessions provides a mock function with given fields: userID, token
+func (_m *Repo) DeleteOtherSessions(userID uuid.UUID, token string) (int64, error) {
+	ret := _m.Called(userID, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOtherSessions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) (int64, error)); ok {
+		return rf(userID, token)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) int64); ok {
+		r0 = rf(userID, token)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(userID, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteSession provides a mock function with given fields: userID, id
+func (_m *Repo) DeleteSession(userID uuid.UUID, id uuid.UUID) error {
+	ret := _m.Called(userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteToken provides a mock function with given fields: token
+func (_m *Repo) DeleteToken(token string) error {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteTokenById provides a mock function with given fields: userId
 func (_m *Repo) DeleteTokenById(userId uuid.UUID) error {
 	ret := _m.Called(userId)
@@ -67,35 +220,45 @@ func (_m *Repo) DeleteTokenById(userId uuid.UUID) error {
 	return r0
 }
 
-// DeleteUserById provides a mock function with given fields: id
-func (_m *Repo) DeleteUserById(id uuid.UUID) error {
-	ret := _m.Called(id)
+// DeleteTokensExpiredBefore provides a mock function with given fields: before
+func (_m *Repo) DeleteTokensExpiredBefore(before time.Time) (int64, error) {
+	ret := _m.Called(before)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteUserById")
+		panic("no return value specified for DeleteTokensExpiredBefore")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
-		r0 = rf(id)
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int64, error)); ok {
+		return rf(before)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(before)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(int64)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// DeleteUsers provides a mock function with given fields:
-func (_m *Repo) DeleteUsers() error {
-	ret := _m.Called()
+// DeleteUserById provides a mock function with given fields: id
+func (_m *Repo) DeleteUserById(id uuid.UUID) error {
+	ret := _m.Called(id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteUsers")
+		panic("no return value specified for DeleteUserById")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -161,6 +324,96 @@ func (_m *Repo) FetchAvatarById(userId uuid.UUID) (models.Avatar, error) {
 	return r0, r1
 }
 
+// FetchCleanupCandidates provides a mock function with given fields: target, before
+func (_m *Repo) FetchCleanupCandidates(target string, before time.Time) ([]uuid.UUID, error) {
+	ret := _m.Called(target, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchCleanupCandidates")
+	}
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) ([]uuid.UUID, error)); ok {
+		return rf(target, before)
+	}
+	if rf, ok := ret.Get(0).(func(string, time.Time) []uuid.UUID); ok {
+		r0 = rf(target, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, time.Time) error); ok {
+		r1 = rf(target, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchMagicLink provides a mock function with given fields: tokenHash
+func (_m *Repo) FetchMagicLink(tokenHash []byte) (*models.MagicLink, error) {
+	ret := _m.Called(tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchMagicLink")
+	}
+
+	var r0 *models.MagicLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]byte) (*models.MagicLink, error)); ok {
+		return rf(tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func([]byte) *models.MagicLink); ok {
+		r0 = rf(tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.MagicLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]byte) error); ok {
+		r1 = rf(tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchSessions provides a mock function with given fields: userID, token
+func (_m *Repo) FetchSessions(userID uuid.UUID, token string) ([]models.Session, error) {
+	ret := _m.Called(userID, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchSessions")
+	}
+
+	var r0 []models.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) ([]models.Session, error)); ok {
+		return rf(userID, token)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) []models.Session); ok {
+		r0 = rf(userID, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(userID, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FetchTokenById provides a mock function with given fields: id
 func (_m *Repo) FetchTokenById(id uuid.UUID) (*models.Token, error) {
 	ret := _m.Called(id)
@@ -281,6 +534,36 @@ func (_m *Repo) FetchUserByToken(token string) (*models.User, error) {
 	return r0, r1
 }
 
+// FetchUsersDueForDeletion provides a mock function with given fields: ctx, now
+func (_m *Repo) FetchUsersDueForDeletion(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchUsersDueForDeletion")
+	}
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]uuid.UUID, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []uuid.UUID); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // InsertAvatar provides a mock function with given fields: avatar
 func (_m *Repo) InsertAvatar(avatar *models.Avatar) (models.Avatar, error) {
 	ret := _m.Called(avatar)
@@ -309,6 +592,60 @@ func (_m *Repo) InsertAvatar(avatar *models.Avatar) (models.Avatar, error) {
 	return r0, r1
 }
 
+// InsertCleanupConfirmation provides a mock function with given fields: c
+func (_m *Repo) InsertCleanupConfirmation(c models.CleanupConfirmation) error {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertCleanupConfirmation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.CleanupConfirmation) error); ok {
+		r0 = rf(c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertMagicLink provides a mock function with given fields: l
+func (_m *Repo) InsertMagicLink(l models.MagicLink) error {
+	ret := _m.Called(l)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertMagicLink")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.MagicLink) error); ok {
+		r0 = rf(l)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertSession provides a mock function with given fields: t
+func (_m *Repo) InsertSession(t *models.Token) error {
+	ret := _m.Called(t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Token) error); ok {
+		r0 = rf(t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertToken provides a mock function with given fields: t, userID
 func (_m *Repo) InsertToken(t *models.Token, userID uuid.UUID) error {
 	ret := _m.Called(t, userID)
@@ -357,6 +694,144 @@ func (_m *Repo) InsertUser(user models.User) (*models.User, error) {
 	return r0, r1
 }
 
+// MergeUsers provides a mock function with given fields: m
+func (_m *Repo) MergeUsers(m models.AccountMerge) (*models.AccountMerge, error) {
+	ret := _m.Called(m)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeUsers")
+	}
+
+	var r0 *models.AccountMerge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.AccountMerge) (*models.AccountMerge, error)); ok {
+		return rf(m)
+	}
+	if rf, ok := ret.Get(0).(func(models.AccountMerge) *models.AccountMerge); ok {
+		r0 = rf(m)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccountMerge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.AccountMerge) error); ok {
+		r1 = rf(m)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueueAvatar provides a mock function with given fields: p
+func (_m *Repo) QueueAvatar(p models.PendingAvatar) error {
+	ret := _m.Called(p)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueueAvatar")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.PendingAvatar) error); ok {
+		r0 = rf(p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreUser provides a mock function with given fields: token
+func (_m *Repo) RestoreUser(token string) error {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ScheduleUserDeletion provides a mock function with given fields: id, deleteAfter, restoreHash
+func (_m *Repo) ScheduleUserDeletion(id uuid.UUID, deleteAfter time.Time, restoreHash []byte) error {
+	ret := _m.Called(id, deleteAfter, restoreHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScheduleUserDeletion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time, []byte) error); ok {
+		r0 = rf(id, deleteAfter, restoreHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCurrency provides a mock function with given fields: id, currency
+func (_m *Repo) UpdateCurrency(id uuid.UUID, currency string) error {
+	ret := _m.Called(id, currency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCurrency")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = rf(id, currency)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateLocale provides a mock function with given fields: id, locale
+func (_m *Repo) UpdateLocale(id uuid.UUID, locale string) error {
+	ret := _m.Called(id, locale)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateLocale")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = rf(id, locale)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePassword provides a mock function with given fields: c, hash
+func (_m *Repo) UpdatePassword(c models.PasswordChange, hash string) error {
+	ret := _m.Called(c, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.PasswordChange, string) error); ok {
+		r0 = rf(c, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateUser provides a mock function with given fields: user
 func (_m *Repo) UpdateUser(user models.User) error {
 	ret := _m.Called(user)
@@ -375,6 +850,72 @@ func (_m *Repo) UpdateUser(user models.User) error {
 	return r0
 }
 
+// UpdateUserRole provides a mock function with given fields: id, role
+func (_m *Repo) UpdateUserRole(id uuid.UUID, role string) error {
+	ret := _m.Called(id, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserRole")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = rf(id, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UseCleanupConfirmation provides a mock function with given fields: tokenHash, adminID, at
+func (_m *Repo) UseCleanupConfirmation(tokenHash []byte, adminID uuid.UUID, at time.Time) (*models.CleanupConfirmation, error) {
+	ret := _m.Called(tokenHash, adminID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseCleanupConfirmation")
+	}
+
+	var r0 *models.CleanupConfirmation
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]byte, uuid.UUID, time.Time) (*models.CleanupConfirmation, error)); ok {
+		return rf(tokenHash, adminID, at)
+	}
+	if rf, ok := ret.Get(0).(func([]byte, uuid.UUID, time.Time) *models.CleanupConfirmation); ok {
+		r0 = rf(tokenHash, adminID, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CleanupConfirmation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]byte, uuid.UUID, time.Time) error); ok {
+		r1 = rf(tokenHash, adminID, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UseMagicLink provides a mock function with given fields: id, at
+func (_m *Repo) UseMagicLink(id uuid.UUID, at time.Time) error {
+	ret := _m.Called(id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseMagicLink")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) error); ok {
+		r0 = rf(id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRepo(t interface {
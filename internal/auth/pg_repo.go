@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/internal/models"
 )
@@ -21,12 +24,6 @@ type Repo interface {
 	// FetchAvatarById fetches avatar data using user id from the avatar table
 	FetchAvatarById(userId uuid.UUID) (models.Avatar, error)
 
-	// DeleteUsers deletes the users from the database
-	DeleteUsers() error
-
-	// DeleteAvatar deletes the avatar from the database
-	DeleteAvatar() error
-
 	// FetchUserByEmail fetches a user by email from the database
 	FetchUserByEmail(email string) (*models.User, error)
 
@@ -36,12 +33,19 @@ type Repo interface {
 	// UpdateUser updates the users table with new changes
 	UpdateUser(user models.User) error
 
+	// UpdatePassword sets the password hash of the user of a change, leaving the other details as they are,
+	// and writes the change to the outbox in the same transaction, for the user to be notified of it
+	UpdatePassword(c models.PasswordChange, hash string) error
+
 	// FetchUserById returns a user by id and error if any error occurs
 	FetchUserById(id uuid.UUID) (*models.User, error)
 
 	// DeleteAvatarById deletes an avatar by id
 	DeleteAvatarById(id string) error
 
+	// QueueAvatar writes an avatar to upload again to the outbox
+	QueueAvatar(p models.PendingAvatar) error
+
 	// FetchAllUsers returns all users and error if any error occurs
 	FetchAllUsers() ([]*models.User, error)
 
@@ -50,4 +54,75 @@ type Repo interface {
 
 	// DeleteTokenById deletes a token by user id and error if any error occurs
 	DeleteTokenById(userId uuid.UUID) error
+
+	// UpdateUserRole sets the role of a user
+	UpdateUserRole(id uuid.UUID, role string) error
+
+	// UpdateCurrency sets the preferred currency of a user, empty for the shop currency
+	UpdateCurrency(id uuid.UUID, currency string) error
+
+	// UpdateLocale sets the preferred locale of a user, empty for the shop locale
+	UpdateLocale(id uuid.UUID, locale string) error
+
+	// DeleteExpiredTokens deletes every token past its expiry and returns how many were removed
+	DeleteExpiredTokens(ctx context.Context) (int64, error)
+
+	// ScheduleUserDeletion marks a user for deletion after deleteAfter with the hash of its restore token
+	ScheduleUserDeletion(id uuid.UUID, deleteAfter time.Time, restoreHash []byte) error
+
+	// CancelUserDeletion clears the deletion schedule of a user
+	CancelUserDeletion(id uuid.UUID) error
+
+	// RestoreUser clears the deletion schedule of the user holding a restore token, returns sql.ErrNoRows if none
+	RestoreUser(token string) error
+
+	// FetchUsersDueForDeletion returns the ids of users whose deletion is due
+	FetchUsersDueForDeletion(ctx context.Context, now time.Time) ([]uuid.UUID, error)
+
+	// InsertMagicLink saves a magic sign-in link
+	InsertMagicLink(l models.MagicLink) error
+
+	// FetchMagicLink returns the magic link with the token hash, sql.ErrNoRows if none
+	FetchMagicLink(tokenHash []byte) (*models.MagicLink, error)
+
+	// UseMagicLink marks a magic link used at, returns sql.ErrNoRows if it was already used or has expired
+	UseMagicLink(id uuid.UUID, at time.Time) error
+
+	// DeleteExpiredMagicLinks deletes every magic link past its expiry and returns how many were removed
+	DeleteExpiredMagicLinks(ctx context.Context) (int64, error)
+
+	// MergeUsers moves the data of a user to another, revokes their tokens, records the merge and deletes the
+	// merged user in one transaction, returns sql.ErrNoRows if either user does not exist
+	MergeUsers(m models.AccountMerge) (*models.AccountMerge, error)
+
+	// FetchCleanupCandidates returns the ids of the records a bulk cleanup would delete with a cutoff
+	FetchCleanupCandidates(target string, before time.Time) ([]uuid.UUID, error)
+
+	// InsertCleanupConfirmation saves the confirmation of the dry run of a bulk cleanup
+	InsertCleanupConfirmation(c models.CleanupConfirmation) error
+
+	// UseCleanupConfirmation marks the confirmation with the token hash used at, returns sql.ErrNoRows if it is not
+	// the admin's, was already used or has expired
+	UseCleanupConfirmation(tokenHash []byte, adminID uuid.UUID, at time.Time) (*models.CleanupConfirmation, error)
+
+	// DeleteInactiveUser deletes a customer created before the cutoff who never ordered, reports whether it was deleted
+	DeleteInactiveUser(id uuid.UUID, before time.Time) (bool, error)
+
+	// DeleteTokensExpiredBefore deletes every token that expired before the cutoff and returns how many were removed
+	DeleteTokensExpiredBefore(before time.Time) (int64, error)
+
+	// InsertSession saves the token of a sign-in with its device, keeping the other tokens of its user
+	InsertSession(t *models.Token) error
+
+	// FetchSessions returns the unexpired tokens of a user as sessions, newest first, the one of token marked current
+	FetchSessions(userID uuid.UUID, token string) ([]models.Session, error)
+
+	// DeleteSession revokes a session of a user, returns sql.ErrNoRows if the user has no such session
+	DeleteSession(userID, id uuid.UUID) error
+
+	// DeleteOtherSessions revokes every session of a user but the one of token and returns how many were revoked
+	DeleteOtherSessions(userID uuid.UUID, token string) (int64, error)
+
+	// DeleteToken revokes a token, returns sql.ErrNoRows if it does not exist
+	DeleteToken(token string) error
 }
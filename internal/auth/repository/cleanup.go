@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// cleanupCandidates selects, for each bulk cleanup, the ids of the records it deletes
+// given the cutoff as $1, in a stable order so a dry run can be compared to the run.
+var cleanupCandidates = map[string]string{
+	models.CleanupInactiveUsers: `select u.user_id from users u
+		where u.role = 'user' and u.created_at < $1
+		and not exists (select 1 from orders o where o.user_id = u.user_id)
+		order by u.user_id`,
+	models.CleanupExpiredSessions: `select token_id from tokens where expiry < $1 order by token_id`,
+}
+
+// FetchCleanupCandidates returns the ids of the records the bulk cleanup target would
+// delete with the cutoff before.
+func (r *AuthRepository) FetchCleanupCandidates(target string, before time.Time) ([]uuid.UUID, error) {
+	query, ok := cleanupCandidates[target]
+	if !ok {
+		return nil, fmt.Errorf("unknown cleanup target %q", target)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// InsertCleanupConfirmation saves the confirmation of the dry run of a bulk cleanup.
+func (r *AuthRepository) InsertCleanupConfirmation(c models.CleanupConfirmation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into cleanup_confirmations (admin_id, target, before, token_hash, digest, matched, expiry, created_at)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := r.DB.ExecContext(ctx, query, c.AdminID, c.Target, c.Before, c.TokenHash, c.Digest, c.Matched, c.Expiry,
+		time.Now())
+
+	return err
+}
+
+// UseCleanupConfirmation marks the confirmation with the token hash used at and returns
+// it. Only an unused and unexpired confirmation of the admin is marked, in the same
+// statement, so a token runs its cleanup once. It returns sql.ErrNoRows otherwise.
+func (r *AuthRepository) UseCleanupConfirmation(tokenHash []byte, adminID uuid.UUID, at time.Time) (*models.CleanupConfirmation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update cleanup_confirmations set used_at = $3
+		where token_hash = $1 and admin_id = $2 and used_at is null and expiry > $3
+		returning cleanup_confirmation_id, admin_id, target, before, token_hash, digest, matched, expiry, used_at, created_at`
+
+	var (
+		c      models.CleanupConfirmation
+		usedAt sql.NullTime
+	)
+	err := r.DB.QueryRowContext(ctx, query, tokenHash, adminID, at).Scan(
+		&c.ID,
+		&c.AdminID,
+		&c.Target,
+		&c.Before,
+		&c.TokenHash,
+		&c.Digest,
+		&c.Matched,
+		&c.Expiry,
+		&usedAt,
+		&c.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		c.UsedAt = &usedAt.Time
+	}
+
+	return &c, nil
+}
+
+// DeleteInactiveUser deletes the user with id if it is still a customer created before
+// the cutoff that never placed an order, and reports whether it was deleted. A user
+// who ordered since the dry run is kept, as deleting them would delete their orders.
+func (r *AuthRepository) DeleteInactiveUser(id uuid.UUID, before time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `delete from users u
+		where u.user_id = $1 and u.role = 'user' and u.created_at < $2
+		and not exists (select 1 from orders o where o.user_id = u.user_id)`
+
+	res, err := r.DB.ExecContext(ctx, query, id, before)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// DeleteTokensExpiredBefore deletes every token that expired before the cutoff and
+// returns how many were removed.
+func (r *AuthRepository) DeleteTokensExpiredBefore(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := `delete from tokens where expiry < $1`
+
+	res, err := r.DB.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
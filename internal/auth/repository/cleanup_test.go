@@ -0,0 +1,114 @@
+package repository_test
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuthRepository_FetchCleanupCandidates verifies each cleanup selects its own records.
+func TestAuthRepository_FetchCleanupCandidates(t *testing.T) {
+	before := time.Now()
+
+	t.Run("inactive users", func(t *testing.T) {
+		repo, mock, db := newTestRepo(t)
+		defer db.Close()
+		id := uuid.New()
+
+		mock.ExpectQuery(`select u.user_id from users u\s+where u.role = 'user' and u.created_at < \$1\s+and not exists`).
+			WithArgs(before).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(id))
+
+		ids, err := repo.FetchCleanupCandidates(models.CleanupInactiveUsers, before)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{id}, ids)
+	})
+
+	t.Run("expired sessions", func(t *testing.T) {
+		repo, mock, db := newTestRepo(t)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`select token_id from tokens where expiry < $1 order by token_id`)).
+			WithArgs(before).WillReturnRows(sqlmock.NewRows([]string{"token_id"}))
+
+		ids, err := repo.FetchCleanupCandidates(models.CleanupExpiredSessions, before)
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("unknown target", func(t *testing.T) {
+		repo, _, db := newTestRepo(t)
+		defer db.Close()
+
+		_, err := repo.FetchCleanupCandidates("orders", before)
+		assert.Error(t, err)
+	})
+}
+
+// TestAuthRepository_UseCleanupConfirmation verifies a confirmation is used once, by its admin.
+func TestAuthRepository_UseCleanupConfirmation(t *testing.T) {
+	query := regexp.QuoteMeta(`update cleanup_confirmations set used_at = $3
+		where token_hash = $1 and admin_id = $2 and used_at is null and expiry > $3`)
+	hash, adminID, at := []byte("hash"), uuid.New(), time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		repo, mock, db := newTestRepo(t)
+		defer db.Close()
+		id := uuid.New()
+
+		mock.ExpectQuery(query).WithArgs(hash, adminID, at).WillReturnRows(sqlmock.NewRows([]string{
+			"cleanup_confirmation_id", "admin_id", "target", "before", "token_hash", "digest", "matched", "expiry",
+			"used_at", "created_at",
+		}).AddRow(id, adminID, models.CleanupInactiveUsers, at, hash, []byte("digest"), 3, at, at, at))
+
+		c, err := repo.UseCleanupConfirmation(hash, adminID, at)
+		require.NoError(t, err)
+		assert.Equal(t, id, c.ID)
+		assert.Equal(t, 3, c.Matched)
+		assert.NotNil(t, c.UsedAt)
+	})
+
+	t.Run("used, expired or of another admin", func(t *testing.T) {
+		repo, mock, db := newTestRepo(t)
+		defer db.Close()
+
+		mock.ExpectQuery(query).WithArgs(hash, adminID, at).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.UseCleanupConfirmation(hash, adminID, at)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+// TestAuthRepository_DeleteInactiveUser verifies a user is only deleted while inactive.
+func TestAuthRepository_DeleteInactiveUser(t *testing.T) {
+	query := `delete from users u\s+where u.user_id = \$1 and u.role = 'user' and u.created_at < \$2\s+and not exists`
+	id, before := uuid.New(), time.Now()
+
+	t.Run("deleted", func(t *testing.T) {
+		repo, mock, db := newTestRepo(t)
+		defer db.Close()
+
+		mock.ExpectExec(query).WithArgs(id, before).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		deleted, err := repo.DeleteInactiveUser(id, before)
+		require.NoError(t, err)
+		assert.True(t, deleted)
+	})
+
+	t.Run("no longer inactive", func(t *testing.T) {
+		repo, mock, db := newTestRepo(t)
+		defer db.Close()
+
+		mock.ExpectExec(query).WithArgs(id, before).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		deleted, err := repo.DeleteInactiveUser(id, before)
+		require.NoError(t, err)
+		assert.False(t, deleted)
+	})
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// The methods below empty the tables of users and avatars. They only exist in test
+// builds, so no code path of the running shop can wipe its customers.
+
+// DeleteUsers deletes all users from the database.
+func (r *AuthRepository) DeleteUsers() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `delete from users`
+	_, err := r.DB.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAvatar deletes all avatars from the database.
+func (r *AuthRepository) DeleteAvatar() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `delete from avatar`
+	_, err := r.DB.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// InsertMagicLink saves a magic sign-in link.
+func (r *AuthRepository) InsertMagicLink(l models.MagicLink) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into magic_links (user_id, token_hash, device_hash, ip, user_agent, expiry, created_at)
+		values ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.DB.ExecContext(ctx, query, l.UserID, l.TokenHash, l.DeviceHash, l.IP, l.UserAgent, l.Expiry, time.Now())
+
+	return err
+}
+
+// FetchMagicLink returns the magic link with the token hash, used or not. It returns
+// sql.ErrNoRows when there is none.
+func (r *AuthRepository) FetchMagicLink(tokenHash []byte) (*models.MagicLink, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select magic_link_id, user_id, token_hash, device_hash, ip, user_agent, expiry, used_at, created_at
+		from magic_links where token_hash = $1`
+
+	var (
+		l      models.MagicLink
+		usedAt sql.NullTime
+	)
+	err := r.DB.QueryRowContext(ctx, query, tokenHash).Scan(
+		&l.ID,
+		&l.UserID,
+		&l.TokenHash,
+		&l.DeviceHash,
+		&l.IP,
+		&l.UserAgent,
+		&l.Expiry,
+		&usedAt,
+		&l.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		l.UsedAt = &usedAt.Time
+	}
+
+	return &l, nil
+}
+
+// UseMagicLink marks a magic link used at. The link is only marked if it is unused
+// and unexpired, in the same statement, so of two requests racing with one link only
+// one signs in. It returns sql.ErrNoRows when the link was already used or has expired.
+func (r *AuthRepository) UseMagicLink(id uuid.UUID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update magic_links set used_at = $2 where magic_link_id = $1 and used_at is null and expiry > $2`
+
+	res, err := r.DB.ExecContext(ctx, query, id, at)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteExpiredMagicLinks deletes every magic link whose expiry has passed, used or
+// not.
+func (r *AuthRepository) DeleteExpiredMagicLinks(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := `delete from magic_links where expiry < $1`
+
+	res, err := r.DB.ExecContext(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
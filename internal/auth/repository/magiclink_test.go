@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuthRepository_InsertMagicLink verifies saving a magic link with its hashes and device.
+func TestAuthRepository_InsertMagicLink(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	l := models.MagicLink{
+		UserID:     uuid.New(),
+		TokenHash:  []byte("token"),
+		DeviceHash: []byte("device"),
+		IP:         "203.0.113.7",
+		UserAgent:  "Firefox",
+		Expiry:     time.Now().Add(15 * time.Minute),
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`insert into magic_links`)).
+		WithArgs(l.UserID, l.TokenHash, l.DeviceHash, l.IP, l.UserAgent, l.Expiry, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.InsertMagicLink(l))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAuthRepository_FetchMagicLink verifies reading a magic link by its token hash, used or not.
+func TestAuthRepository_FetchMagicLink(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	query := regexp.QuoteMeta(`from magic_links where token_hash = $1`)
+	columns := []string{"magic_link_id", "user_id", "token_hash", "device_hash", "ip", "user_agent", "expiry", "used_at", "created_at"}
+	id, userID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	t.Run("unused", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs([]byte("token")).WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(id, userID, []byte("token"), []byte("device"), "203.0.113.7", "Firefox", now, nil, now))
+
+		l, err := repo.FetchMagicLink([]byte("token"))
+		require.NoError(t, err)
+		assert.Equal(t, id, l.ID)
+		assert.Equal(t, userID, l.UserID)
+		assert.Equal(t, []byte("device"), l.DeviceHash)
+		assert.Nil(t, l.UsedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("used", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs([]byte("token")).WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(id, userID, []byte("token"), []byte("device"), "", "", now, now, now))
+
+		l, err := repo.FetchMagicLink([]byte("token"))
+		require.NoError(t, err)
+		require.NotNil(t, l.UsedAt)
+		assert.Equal(t, now, *l.UsedAt)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs([]byte("token")).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.FetchMagicLink([]byte("token"))
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+// TestAuthRepository_UseMagicLink verifies a magic link is only used once.
+func TestAuthRepository_UseMagicLink(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	id := uuid.New()
+	at := time.Now()
+	query := regexp.QuoteMeta(`update magic_links set used_at = $2 where magic_link_id = $1 and used_at is null and expiry > $2`)
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(id, at).WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, repo.UseMagicLink(id, at))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already used", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(id, at).WillReturnResult(sqlmock.NewResult(0, 0))
+		assert.ErrorIs(t, repo.UseMagicLink(id, at), sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestAuthRepository_DeleteExpiredMagicLinks verifies removing the magic links past their expiry.
+func TestAuthRepository_DeleteExpiredMagicLinks(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(`delete from magic_links where expiry < $1`)).
+		WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := repo.DeleteExpiredMagicLinks(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// MergeUsers moves the orders (with their shipping, payments and cancellations),
+// reviews, store credit, coupon redemptions, checkout sessions, products, support
+// tickets and their messages, notifications, addresses and wishlist of
+// m.MergedUserID to m.KeptUserID, revokes the tokens of both, records m with the
+// counts moved and deletes the merged user, in one transaction. Moved addresses are
+// not the default, which stays the kept user's, and products both users wishlisted
+// are kept once. Both users are locked first; it returns sql.ErrNoRows when either
+// does not exist.
+func (r *AuthRepository) MergeUsers(m models.AccountMerge) (*models.AccountMerge, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	lock := `select user_id from users where user_id = $1 for update`
+	if err := tx.QueryRowContext(ctx, lock, m.KeptUserID).Scan(&m.KeptUserID); err != nil {
+		return nil, err
+	}
+	if err := tx.QueryRowContext(ctx, lock, m.MergedUserID).Scan(&m.MergedUserID); err != nil {
+		return nil, err
+	}
+
+	moves := []struct {
+		table string
+		count *int
+	}{
+		{"orders", &m.Orders},
+		{"reviews", &m.Reviews},
+		{"store_credits", &m.StoreCredits},
+		{"coupon_redemptions", nil},
+		{"checkout_sessions", nil},
+		{"products", nil},
+		{"order_cancellations", nil},
+		{"tickets", nil},
+		{"ticket_messages", nil},
+		{"notifications", nil},
+	}
+	for _, mv := range moves {
+		res, err := tx.ExecContext(ctx, `update `+mv.table+` set user_id = $1 where user_id = $2`,
+			m.KeptUserID, m.MergedUserID)
+		if err != nil {
+			return nil, err
+		}
+
+		if mv.count != nil {
+			n, err := res.RowsAffected()
+			if err != nil {
+				return nil, err
+			}
+			*mv.count = int(n)
+		}
+	}
+
+	// a user has one default address at most
+	_, err = tx.ExecContext(ctx, `update addresses set user_id = $1, is_default = false where user_id = $2`,
+		m.KeptUserID, m.MergedUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// products the kept user wishlisted too go with the merged user
+	query := `update wishlist_items w set user_id = $1 where w.user_id = $2
+				and not exists (select 1 from wishlist_items k where k.user_id = $1 and k.product_id = w.product_id)`
+	if _, err := tx.ExecContext(ctx, query, m.KeptUserID, m.MergedUserID); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `delete from tokens where user_id in ($1, $2)`, m.KeptUserID, m.MergedUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `insert into account_merges (kept_user_id, merged_user_id, merged_name, merged_email, orders, reviews,
+				store_credits, merged_by, created_at)
+				values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning merge_id, created_at`
+
+	err = tx.QueryRowContext(ctx, query, m.KeptUserID, m.MergedUserID, m.MergedName, m.MergedEmail, m.Orders,
+		m.Reviews, m.StoreCredits, m.MergedBy, time.Now()).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// the avatar, preferences and sign-in links of the merged user go with it
+	if _, err := tx.ExecContext(ctx, `delete from users where user_id = $1`, m.MergedUserID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuthRepository_MergeUsers verifies a merge moves the data of the merged user,
+// its addresses as non-default ones, revokes tokens, is recorded and deletes the
+// merged user in one transaction.
+func TestAuthRepository_MergeUsers(t *testing.T) {
+	m := models.AccountMerge{
+		KeptUserID:   uuid.New(),
+		MergedUserID: uuid.New(),
+		MergedName:   "Ama",
+		MergedEmail:  "ama.old@example.com",
+		MergedBy:     uuid.New(),
+	}
+	lock := regexp.QuoteMeta(`select user_id from users where user_id = $1 for update`)
+
+	t.Run("success", func(t *testing.T) {
+		repo, mock, db := newTestRepo(t)
+		defer db.Close()
+		id := uuid.New()
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(m.KeptUserID).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(m.KeptUserID))
+		mock.ExpectQuery(lock).WithArgs(m.MergedUserID).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(m.MergedUserID))
+		tables := []struct {
+			name string
+			rows int64
+		}{{"orders", 3}, {"reviews", 2}, {"store_credits", 1}, {"coupon_redemptions", 1},
+			{"checkout_sessions", 0}, {"products", 0}, {"order_cancellations", 1}, {"tickets", 1}, {"ticket_messages", 2},
+			{"notifications", 5}}
+		for _, table := range tables {
+			mock.ExpectExec(regexp.QuoteMeta(`update `+table.name+` set user_id = $1 where user_id = $2`)).
+				WithArgs(m.KeptUserID, m.MergedUserID).WillReturnResult(sqlmock.NewResult(0, table.rows))
+		}
+		mock.ExpectExec(regexp.QuoteMeta(`update addresses set user_id = $1, is_default = false where user_id = $2`)).
+			WithArgs(m.KeptUserID, m.MergedUserID).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(`update wishlist_items w set user_id = \$1 where w.user_id = \$2\s+and not exists \(select 1 `+
+			`from wishlist_items k where k.user_id = \$1 and k.product_id = w.product_id\)`).
+			WithArgs(m.KeptUserID, m.MergedUserID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`delete from tokens where user_id in ($1, $2)`)).
+			WithArgs(m.KeptUserID, m.MergedUserID).WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectQuery(regexp.QuoteMeta(`insert into account_merges`)).
+			WithArgs(m.KeptUserID, m.MergedUserID, "Ama", "ama.old@example.com", 3, 2, 1, m.MergedBy, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"merge_id", "created_at"}).AddRow(id, now))
+		mock.ExpectExec(regexp.QuoteMeta(`delete from users where user_id = $1`)).
+			WithArgs(m.MergedUserID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		merge, err := repo.MergeUsers(m)
+		require.NoError(t, err)
+		assert.Equal(t, id, merge.ID)
+		assert.Equal(t, 3, merge.Orders)
+		assert.Equal(t, 2, merge.Reviews)
+		assert.Equal(t, 1, merge.StoreCredits)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing user", func(t *testing.T) {
+		repo, mock, db := newTestRepo(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(m.KeptUserID).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(m.KeptUserID))
+		mock.ExpectQuery(lock).WithArgs(m.MergedUserID).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := repo.MergeUsers(m)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -9,9 +9,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
 	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/driver"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/outbox"
 )
 
+// emailIndex is the unique index keeping emails unique regardless of case.
+const emailIndex = "users_email_lower_idx"
+
 // AuthRepository provides methods for interacting with the authentication-related tables in the database.
 // It should be constructed with a *sql.DB connection.
 type AuthRepository struct {
@@ -26,7 +33,8 @@ func NewAuthRepository(db *sql.DB) *AuthRepository {
 	}
 }
 
-// InsertUser inserts a new user into the users table.
+// InsertUser inserts a new user into the users table. It returns auth.ErrDuplicateEmail
+// when another user has the email, regardless of case.
 func (r *AuthRepository) InsertUser(u models.User) (*models.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -50,6 +58,9 @@ func (r *AuthRepository) InsertUser(u models.User) (*models.User, error) {
 		&user.CreatedAt,
 	)
 
+	if driver.IsUniqueViolation(err, emailIndex) {
+		return nil, auth.ErrDuplicateEmail
+	}
 	if err != nil {
 		return &user, err
 	}
@@ -62,13 +73,17 @@ func (r *AuthRepository) UpdateUser(u models.User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := `update users set name = $1, email = $2, password = $3, role = $4 where user_id = $5`
+	query := `update users set name = $1, email = $2, password = $3, role = $4, phone = nullif($5, ''),
+				date_of_birth = $6, newsletter = $7 where user_id = $8`
 
 	_, err := r.DB.ExecContext(ctx, query,
 		u.Name,
 		u.Email,
 		u.Password,
 		u.Role,
+		u.Phone,
+		u.DateOfBirth,
+		u.Newsletter,
 		u.ID,
 	)
 
@@ -79,6 +94,42 @@ func (r *AuthRepository) UpdateUser(u models.User) error {
 	return nil
 }
 
+// UpdatePassword sets the password hash of the user of the change c, writing c to the
+// outbox as events.PasswordChanged in the same transaction, for the user to be notified
+// of it. It returns sql.ErrNoRows when there is no such user.
+func (r *AuthRepository) UpdatePassword(c models.PasswordChange, hash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `update users set password = $1 where user_id = $2`
+
+	res, err := tx.ExecContext(ctx, query, hash, c.UserID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := outbox.Write(ctx, tx, events.PasswordChanged, c); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 // InsertAvatar inserts a new avatar record for a user.
 func (r *AuthRepository) InsertAvatar(a *models.Avatar) (models.Avatar, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -133,35 +184,7 @@ func (r *AuthRepository) FetchAvatarById(userId uuid.UUID) (models.Avatar, error
 	return a, nil
 }
 
-// DeleteUsers deletes all users from the database.
-func (r *AuthRepository) DeleteUsers() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	query := `delete from users`
-	_, err := r.DB.ExecContext(ctx, query)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// DeleteAvatar deletes all avatars from the database.
-func (r *AuthRepository) DeleteAvatar() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	query := `delete from avatar`
-	_, err := r.DB.ExecContext(ctx, query)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// FetchUserByEmail fetches a user by email.
+// FetchUserByEmail fetches a user by email, regardless of case.
 func (r *AuthRepository) FetchUserByEmail(email string) (*models.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -169,9 +192,9 @@ func (r *AuthRepository) FetchUserByEmail(email string) (*models.User, error) {
 	var user models.User
 
 	query := `
-		select user_id, name, email, password, role, created_at
+		select user_id, name, email, password, role, created_at, delete_after, locale
 		from users
-		where email = $1
+		where LOWER(email) = LOWER($1)
 	`
 
 	err := r.DB.QueryRowContext(ctx, query, email).Scan(
@@ -181,6 +204,8 @@ func (r *AuthRepository) FetchUserByEmail(email string) (*models.User, error) {
 		&user.Password,
 		&user.Role,
 		&user.CreatedAt,
+		&user.DeleteAfter,
+		&user.Locale,
 	)
 
 	if err != nil {
@@ -190,7 +215,8 @@ func (r *AuthRepository) FetchUserByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-// InsertToken inserts a token for a user, deleting any existing tokens for that user.
+// InsertToken inserts a token for a user, deleting any existing tokens for that user,
+// which signs them out of every session.
 func (r *AuthRepository) InsertToken(t *models.Token, userID uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -227,13 +253,16 @@ func (r *AuthRepository) FetchTokenById(id uuid.UUID) (*models.Token, error) {
 
 	var token models.Token
 
-	query := `select * from tokens where user_id = $1`
+	query := `select token_id, token_hash, expiry, user_id, user_agent, ip, created_at, updated_at
+		from tokens where user_id = $1`
 
 	err := r.DB.QueryRowContext(ctx, query, id).Scan(
 		&token.ID,
 		&token.Hash,
 		&token.Expiry,
 		&token.UserID,
+		&token.UserAgent,
+		&token.IP,
 		&token.CreatedAt,
 		&token.UpdatedAt,
 	)
@@ -255,13 +284,14 @@ func (r *AuthRepository) FetchUserByToken(token string) (*models.User, error) {
 
 	query := `
 		select
-			u.user_id, u.name, u.email, u.role
+			u.user_id, u.name, u.email, u.role, u.currency, u.locale
 		from
 			users u
 			inner join tokens t on (u.user_id = t.user_id)
 		where
 			t.token_hash = $1
 			and t.expiry > $2
+			and u.delete_after is null
 	`
 
 	err := r.DB.QueryRowContext(ctx, query, tokenHash[:], time.Now()).Scan(
@@ -269,6 +299,8 @@ func (r *AuthRepository) FetchUserByToken(token string) (*models.User, error) {
 		&user.Name,
 		&user.Email,
 		&user.Role,
+		&user.Currency,
+		&user.Locale,
 	)
 
 	if err != nil {
@@ -285,7 +317,10 @@ func (r *AuthRepository) FetchUserById(id uuid.UUID) (*models.User, error) {
 
 	var user models.User
 
-	query := `select * from users where user_id = $1`
+	query := `select user_id, name, email, password, role, created_at, delete_after, currency, locale,
+				coalesce(phone, ''), date_of_birth, newsletter,
+				coalesce((select sum(amount) from store_credits c where c.user_id = u.user_id), 0)
+				from users u where user_id = $1`
 
 	err := r.DB.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
@@ -294,6 +329,13 @@ func (r *AuthRepository) FetchUserById(id uuid.UUID) (*models.User, error) {
 		&user.Password,
 		&user.Role,
 		&user.CreatedAt,
+		&user.DeleteAfter,
+		&user.Currency,
+		&user.Locale,
+		&user.Phone,
+		&user.DateOfBirth,
+		&user.Newsletter,
+		&user.StoreCredit,
 	)
 
 	if err != nil {
@@ -318,6 +360,15 @@ func (r *AuthRepository) DeleteAvatarById(id string) error {
 	return nil
 }
 
+// QueueAvatar writes the avatar of a user to the outbox as events.AvatarPending, to
+// be uploaded again in the background.
+func (r *AuthRepository) QueueAvatar(p models.PendingAvatar) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return outbox.Write(ctx, r.DB, events.AvatarPending, p)
+}
+
 // FetchAllUsers returns all users in the database.
 func (r *AuthRepository) FetchAllUsers() ([]*models.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -325,7 +376,8 @@ func (r *AuthRepository) FetchAllUsers() ([]*models.User, error) {
 
 	var users []*models.User
 
-	query := `select * from users`
+	query := `select user_id, name, email, password, role, created_at, delete_after, currency, locale,
+				coalesce(phone, ''), date_of_birth, newsletter from users`
 
 	rows, err := r.DB.QueryContext(ctx, query)
 	if err != nil {
@@ -341,6 +393,12 @@ func (r *AuthRepository) FetchAllUsers() ([]*models.User, error) {
 			&user.Password,
 			&user.Role,
 			&user.CreatedAt,
+			&user.DeleteAfter,
+			&user.Currency,
+			&user.Locale,
+			&user.Phone,
+			&user.DateOfBirth,
+			&user.Newsletter,
 		)
 		if err != nil {
 			return nil, err
@@ -381,3 +439,187 @@ func (r *AuthRepository) DeleteTokenById(userId uuid.UUID) error {
 
 	return nil
 }
+
+// UpdateUserRole sets the role of the user with the given id.
+func (r *AuthRepository) UpdateUserRole(id uuid.UUID, role string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update users set role = $1 where user_id = $2`
+
+	res, err := r.DB.ExecContext(ctx, query, role, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateCurrency sets the preferred currency of the user with the given id, empty for
+// the shop currency.
+func (r *AuthRepository) UpdateCurrency(id uuid.UUID, currency string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update users set currency = $1 where user_id = $2`
+
+	res, err := r.DB.ExecContext(ctx, query, currency, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateLocale sets the preferred locale of the user with the given id, empty for the
+// shop locale.
+func (r *AuthRepository) UpdateLocale(id uuid.UUID, locale string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update users set locale = $1 where user_id = $2`
+
+	res, err := r.DB.ExecContext(ctx, query, locale, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteExpiredTokens deletes every token whose expiry has passed.
+func (r *AuthRepository) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := `delete from tokens where expiry < $1`
+
+	res, err := r.DB.ExecContext(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// ScheduleUserDeletion marks a user for deletion after deleteAfter, storing the hash of the
+// token that restores the account.
+func (r *AuthRepository) ScheduleUserDeletion(id uuid.UUID, deleteAfter time.Time, restoreHash []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update users set delete_after = $1, restore_token_hash = $2 where user_id = $3`
+
+	res, err := r.DB.ExecContext(ctx, query, deleteAfter, restoreHash, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CancelUserDeletion clears the deletion schedule of a user.
+func (r *AuthRepository) CancelUserDeletion(id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update users set delete_after = null, restore_token_hash = null where user_id = $1`
+
+	_, err := r.DB.ExecContext(ctx, query, id)
+
+	return err
+}
+
+// RestoreUser clears the deletion schedule of the user holding the restore token, as long
+// as the account has not been purged yet. It returns sql.ErrNoRows when no user matches.
+func (r *AuthRepository) RestoreUser(token string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tokenHash := sha256.Sum256([]byte(token))
+
+	query := `update users set delete_after = null, restore_token_hash = null
+				where restore_token_hash = $1 and delete_after > $2`
+
+	res, err := r.DB.ExecContext(ctx, query, tokenHash[:], time.Now())
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// FetchUsersDueForDeletion returns the ids of users whose deletion is due at now.
+func (r *AuthRepository) FetchUsersDueForDeletion(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := `select user_id from users where delete_after <= $1`
+
+	rows, err := r.DB.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -8,6 +8,7 @@
 package repository_test
 
 import (
+	"context"
 	"crypto/sha256"
 	"database/sql"
 	"errors"
@@ -17,8 +18,12 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jofosuware/go/shopit/internal/auth"
 	"github.com/jofosuware/go/shopit/internal/auth/repository"
 	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -64,22 +69,42 @@ func TestAuthRepository_InsertUser(t *testing.T) {
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+	t.Run("email taken", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(user.Name, user.Email, user.Password, user.Role, sqlmock.AnyArg()).
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_lower_idx"})
+		_, err := repo.InsertUser(user)
+		assert.ErrorIs(t, err, auth.ErrDuplicateEmail)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("other unique violation", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(user.Name, user.Email, user.Password, user.Role, sqlmock.AnyArg()).
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_pkey"})
+		_, err := repo.InsertUser(user)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, auth.ErrDuplicateEmail)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 // TestAuthRepository_UpdateUser verifies updating a user's information, covering both success and database error cases.
 func TestAuthRepository_UpdateUser(t *testing.T) {
 	repo, mock, db := newTestRepo(t)
 	defer db.Close()
-	u := models.User{ID: uuid.New(), Name: "Test User", Email: "user@example.com", Password: "verySecret", Role: "admin"}
-	query := regexp.QuoteMeta(`update users set name = $1, email = $2, password = $3, role = $4 where user_id = $5`)
+	dob := time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)
+	u := models.User{ID: uuid.New(), Name: "Test User", Email: "user@example.com", Password: "verySecret", Role: "admin",
+		Phone: "+14155552671", DateOfBirth: &dob, Newsletter: true}
+	query := regexp.QuoteMeta(`update users set name = $1, email = $2, password = $3, role = $4, phone = nullif($5, ''),
+				date_of_birth = $6, newsletter = $7 where user_id = $8`)
 	t.Run("success", func(t *testing.T) {
-		mock.ExpectExec(query).WithArgs(u.Name, u.Email, u.Password, u.Role, u.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(query).WithArgs(u.Name, u.Email, u.Password, u.Role, u.Phone, u.DateOfBirth, u.Newsletter, u.ID).WillReturnResult(sqlmock.NewResult(1, 1))
 		err := repo.UpdateUser(u)
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 	t.Run("exec error", func(t *testing.T) {
-		mock.ExpectExec(query).WithArgs(u.Name, u.Email, u.Password, u.Role, u.ID).WillReturnError(errors.New("update error"))
+		mock.ExpectExec(query).WithArgs(u.Name, u.Email, u.Password, u.Role, u.Phone, u.DateOfBirth, u.Newsletter, u.ID).WillReturnError(errors.New("update error"))
 		err := repo.UpdateUser(u)
 		assert.Error(t, err)
 		assert.Equal(t, "update error", err.Error())
@@ -176,10 +201,10 @@ func TestAuthRepository_FetchUserByEmail(t *testing.T) {
 	defer db.Close()
 	email := "test@example.com"
 	user := models.User{ID: uuid.New(), Name: "Test User", Email: email, Password: "password", Role: "admin", CreatedAt: time.Now()}
-	query := regexp.QuoteMeta(`select user_id, name, email, password, role, created_at from users where email = $1`)
+	query := `select user_id, name, email, password, role, created_at, delete_after, locale\s+from users\s+where LOWER\(email\) = LOWER\(\$1\)`
 	t.Run("success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"user_id", "name", "email", "password", "role", "created_at"}).
-			AddRow(user.ID, user.Name, user.Email, user.Password, user.Role, user.CreatedAt)
+		rows := sqlmock.NewRows([]string{"user_id", "name", "email", "password", "role", "created_at", "delete_after", "locale"}).
+			AddRow(user.ID, user.Name, user.Email, user.Password, user.Role, user.CreatedAt, nil, "fr")
 		mock.ExpectQuery(query).WithArgs(email).WillReturnRows(rows)
 		result, err := repo.FetchUserByEmail(email)
 		assert.NoError(t, err)
@@ -229,10 +254,11 @@ func TestAuthRepository_FetchTokenById(t *testing.T) {
 	repo, mock, db := newTestRepo(t)
 	defer db.Close()
 	id := uuid.New()
-	query := regexp.QuoteMeta(`select * from tokens where user_id = $1`)
+	query := regexp.QuoteMeta(`select token_id, token_hash, expiry, user_id, user_agent, ip, created_at, updated_at
+		from tokens where user_id = $1`)
 	t.Run("success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "token_hash", "expiry", "user_id", "created_at", "updated_at"}).
-			AddRow(uuid.New(), []byte("hash"), time.Now().Add(time.Hour), id, time.Now(), time.Now())
+		rows := sqlmock.NewRows([]string{"id", "token_hash", "expiry", "user_id", "user_agent", "ip", "created_at", "updated_at"}).
+			AddRow(uuid.New(), []byte("hash"), time.Now().Add(time.Hour), id, "curl/8.0", "203.0.113.7", time.Now(), time.Now())
 		mock.ExpectQuery(query).WithArgs(id).WillReturnRows(rows)
 		tok, err := repo.FetchTokenById(id)
 		assert.NoError(t, err)
@@ -254,19 +280,21 @@ func TestAuthRepository_FetchUserByToken(t *testing.T) {
 	token := "sometoken"
 	hash := sha256.Sum256([]byte(token))
 	query := regexp.QuoteMeta(`select
-			u.user_id, u.name, u.email, u.role
+			u.user_id, u.name, u.email, u.role, u.currency, u.locale
 		from
 			users u
 			inner join tokens t on (u.user_id = t.user_id)
 		where
 			t.token_hash = $1
-			and t.expiry > $2`)
+			and t.expiry > $2
+			and u.delete_after is null`)
 	t.Run("success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"user_id", "name", "email", "role"}).AddRow(uuid.New(), "User", "user@example.com", "admin")
+		rows := sqlmock.NewRows([]string{"user_id", "name", "email", "role", "currency", "locale"}).AddRow(uuid.New(), "User", "user@example.com", "admin", "EUR", "fr")
 		mock.ExpectQuery(query).WithArgs(hash[:], sqlmock.AnyArg()).WillReturnRows(rows)
 		user, err := repo.FetchUserByToken(token)
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
+		assert.Equal(t, "EUR", user.Currency)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 	t.Run("not found", func(t *testing.T) {
@@ -282,15 +310,22 @@ func TestAuthRepository_FetchUserById(t *testing.T) {
 	repo, mock, db := newTestRepo(t)
 	defer db.Close()
 	id := uuid.New()
-	query := regexp.QuoteMeta(`select * from users where user_id = $1`)
+	query := regexp.QuoteMeta(`select user_id, name, email, password, role, created_at, delete_after, currency, locale,
+				coalesce(phone, ''), date_of_birth, newsletter,
+				coalesce((select sum(amount) from store_credits c where c.user_id = u.user_id), 0)
+				from users u where user_id = $1`)
 	t.Run("success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"user_id", "name", "email", "password", "role", "created_at"}).
-			AddRow(id, "User", "user@example.com", "password", "admin", time.Now())
+		rows := sqlmock.NewRows([]string{"user_id", "name", "email", "password", "role", "created_at", "delete_after", "currency", "locale", "phone", "date_of_birth", "newsletter", "store_credit"}).
+			AddRow(id, "User", "user@example.com", "password", "admin", time.Now(), nil, "", "", "+14155552671", nil, true, 25)
 		mock.ExpectQuery(query).WithArgs(id).WillReturnRows(rows)
 		user, err := repo.FetchUserById(id)
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
 		assert.Equal(t, id, user.ID)
+		assert.Equal(t, money.Of(25), user.StoreCredit)
+		assert.Equal(t, "+14155552671", user.Phone)
+		assert.Nil(t, user.DateOfBirth)
+		assert.True(t, user.Newsletter)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 	t.Run("not found", func(t *testing.T) {
@@ -326,12 +361,13 @@ func TestAuthRepository_FetchAllUsers(t *testing.T) {
 	repo, mock, db := newTestRepo(t)
 	defer db.Close()
 
-	query := regexp.QuoteMeta(`select * from users`)
+	query := regexp.QuoteMeta(`select user_id, name, email, password, role, created_at, delete_after, currency, locale,
+				coalesce(phone, ''), date_of_birth, newsletter from users`)
 
 	t.Run("success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"user_id", "name", "email", "password", "role", "created_at"}).
-			AddRow(uuid.New(), "User1", "user1@example.com", "password1", "admin", time.Now()).
-			AddRow(uuid.New(), "User2", "user2@example.com", "password2", "user", time.Now())
+		rows := sqlmock.NewRows([]string{"user_id", "name", "email", "password", "role", "created_at", "delete_after", "currency", "locale", "phone", "date_of_birth", "newsletter"}).
+			AddRow(uuid.New(), "User1", "user1@example.com", "password1", "admin", time.Now(), nil, "", "", "", nil, false).
+			AddRow(uuid.New(), "User2", "user2@example.com", "password2", "user", time.Now(), nil, "EUR", "fr", "+14155552671", time.Now(), true)
 
 		mock.ExpectQuery(query).WillReturnRows(rows)
 
@@ -351,8 +387,8 @@ func TestAuthRepository_FetchAllUsers(t *testing.T) {
 	})
 	// Scan error
 	t.Run("scan error", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"user_id", "name", "email", "password", "role", "created_at"}).
-			AddRow("bad-uuid", "User1", "user1@example.com", "password1", "admin", time.Now())
+		rows := sqlmock.NewRows([]string{"user_id", "name", "email", "password", "role", "created_at", "delete_after", "currency", "locale", "phone", "date_of_birth", "newsletter"}).
+			AddRow("bad-uuid", "User1", "user1@example.com", "password1", "admin", time.Now(), nil, "", "", "", nil, false)
 		mock.ExpectQuery(query).WillReturnRows(rows)
 		_, err := repo.FetchAllUsers()
 		assert.Error(t, err)
@@ -399,3 +435,191 @@ func TestAuthRepository_DeleteTokenById(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+// TestAuthRepository_DeleteExpiredTokens verifies deleting expired tokens, covering both success and error cases.
+func TestAuthRepository_DeleteExpiredTokens(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	query := regexp.QuoteMeta(`delete from tokens where expiry < $1`)
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 3))
+		n, err := repo.DeleteExpiredTokens(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("exec error", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(sqlmock.AnyArg()).WillReturnError(errors.New("delete error"))
+		_, err := repo.DeleteExpiredTokens(context.Background())
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestAuthRepository_UpdateUserRole verifies updating a user's role, covering success, a missing user and errors.
+func TestAuthRepository_UpdateUserRole(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	id := uuid.New()
+	query := regexp.QuoteMeta(`update users set role = $1 where user_id = $2`)
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("admin", id).WillReturnResult(sqlmock.NewResult(0, 1))
+		err := repo.UpdateUserRole(id, "admin")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("admin", id).WillReturnResult(sqlmock.NewResult(0, 0))
+		err := repo.UpdateUserRole(id, "admin")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("exec error", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("admin", id).WillReturnError(errors.New("update error"))
+		err := repo.UpdateUserRole(id, "admin")
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestAuthRepository_UpdatePassword verifies setting a user's password alone with its outbox event, covering success, a missing user and errors.
+func TestAuthRepository_UpdatePassword(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	c := models.PasswordChange{UserID: uuid.New(), ChangedAt: time.Now()}
+	query := regexp.QuoteMeta(`update users set password = $1 where user_id = $2`)
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).WithArgs("hash", c.UserID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("insert into outbox").WithArgs(events.PasswordChanged, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		err := repo.UpdatePassword(c, "hash")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).WithArgs("hash", c.UserID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+		err := repo.UpdatePassword(c, "hash")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("outbox error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).WithArgs("hash", c.UserID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("insert into outbox").WillReturnError(errors.New("outbox error"))
+		mock.ExpectRollback()
+		err := repo.UpdatePassword(c, "hash")
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestAuthRepository_UpdateCurrency verifies setting the preferred currency, covering success and a missing user.
+func TestAuthRepository_UpdateCurrency(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	id := uuid.New()
+	query := regexp.QuoteMeta(`update users set currency = $1 where user_id = $2`)
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("EUR", id).WillReturnResult(sqlmock.NewResult(0, 1))
+		err := repo.UpdateCurrency(id, "EUR")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("EUR", id).WillReturnResult(sqlmock.NewResult(0, 0))
+		err := repo.UpdateCurrency(id, "EUR")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestAuthRepository_UpdateLocale verifies setting the preferred locale, covering success and a missing user.
+func TestAuthRepository_UpdateLocale(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	id := uuid.New()
+	query := regexp.QuoteMeta(`update users set locale = $1 where user_id = $2`)
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("fr", id).WillReturnResult(sqlmock.NewResult(0, 1))
+		err := repo.UpdateLocale(id, "fr")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("fr", id).WillReturnResult(sqlmock.NewResult(0, 0))
+		err := repo.UpdateLocale(id, "fr")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestAuthRepository_ScheduleUserDeletion verifies scheduling a deletion, covering success and a missing user.
+func TestAuthRepository_ScheduleUserDeletion(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	id := uuid.New()
+	deleteAfter := time.Now().Add(time.Hour)
+	query := regexp.QuoteMeta(`update users set delete_after = $1, restore_token_hash = $2 where user_id = $3`)
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(deleteAfter, []byte("hash"), id).WillReturnResult(sqlmock.NewResult(0, 1))
+		err := repo.ScheduleUserDeletion(id, deleteAfter, []byte("hash"))
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(deleteAfter, []byte("hash"), id).WillReturnResult(sqlmock.NewResult(0, 0))
+		err := repo.ScheduleUserDeletion(id, deleteAfter, []byte("hash"))
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestAuthRepository_RestoreUser verifies restoring an account by its token, covering success and an unknown token.
+func TestAuthRepository_RestoreUser(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	token := "restoretoken"
+	hash := sha256.Sum256([]byte(token))
+	query := `update users set delete_after = null, restore_token_hash = null\s+where restore_token_hash = \$1 and delete_after > \$2`
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(hash[:], sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		err := repo.RestoreUser(token)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("unknown token", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(hash[:], sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+		err := repo.RestoreUser(token)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestAuthRepository_FetchUsersDueForDeletion verifies listing the users past their grace period.
+func TestAuthRepository_FetchUsersDueForDeletion(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	now := time.Now()
+	id := uuid.New()
+	query := regexp.QuoteMeta(`select user_id from users where delete_after <= $1`)
+	mock.ExpectQuery(query).WithArgs(now).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(id))
+	ids, err := repo.FetchUsersDueForDeletion(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{id}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAuthRepository_QueueAvatar verifies that avatars to upload again are written to the outbox.
+func TestAuthRepository_QueueAvatar(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	p := models.PendingAvatar{UserID: uuid.New(), Avatar: "data:image/png;base64,AAAA"}
+	mock.ExpectExec("insert into outbox").WithArgs(events.AvatarPending, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	err := repo.QueueAvatar(p)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// InsertSession saves the authentication token of a sign-in with the device it was
+// made from. Unlike InsertToken, the other tokens of its user are kept, so the user
+// stays signed in on their other devices.
+func (r *AuthRepository) InsertSession(t *models.Token) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into tokens (token_hash, expiry, user_id, user_agent, ip, created_at, updated_at)
+		values ($1, $2, $3, $4, $5, $6, $7)`
+
+	now := time.Now()
+	_, err := r.DB.ExecContext(ctx, query, t.Hash, t.Expiry, t.UserID, t.UserAgent, t.IP, now, now)
+
+	return err
+}
+
+// FetchSessions returns the unexpired tokens of a user as sessions, newest first. The
+// session of token is marked current.
+func (r *AuthRepository) FetchSessions(userID uuid.UUID, token string) ([]models.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tokenHash := sha256.Sum256([]byte(token))
+	query := `select token_id, user_agent, ip, created_at, expiry, token_hash = $2
+		from tokens where user_id = $1 and expiry > $3 order by created_at desc`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID, tokenHash[:], time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.Expiry, &s.Current); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// DeleteSession revokes the session id of a user. It returns sql.ErrNoRows when the
+// user has no such session.
+func (r *AuthRepository) DeleteSession(userID, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.DB.ExecContext(ctx, `delete from tokens where token_id = $1 and user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteOtherSessions revokes every session of a user but the one of token, and
+// returns how many were revoked.
+func (r *AuthRepository) DeleteOtherSessions(userID uuid.UUID, token string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tokenHash := sha256.Sum256([]byte(token))
+	res, err := r.DB.ExecContext(ctx, `delete from tokens where user_id = $1 and token_hash <> $2`, userID, tokenHash[:])
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// DeleteToken revokes token, signing its device out. It returns sql.ErrNoRows when
+// the token does not exist.
+func (r *AuthRepository) DeleteToken(token string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tokenHash := sha256.Sum256([]byte(token))
+	res, err := r.DB.ExecContext(ctx, `delete from tokens where token_hash = $1`, tokenHash[:])
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package repository_test
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuthRepository_InsertSession verifies saving a token with its device, keeping the other tokens of the user.
+func TestAuthRepository_InsertSession(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	tok := &models.Token{Hash: []byte("hash"), UserID: uuid.New(), Expiry: time.Now().Add(time.Hour),
+		UserAgent: "Firefox", IP: "203.0.113.7"}
+
+	mock.ExpectExec(regexp.QuoteMeta(`insert into tokens (token_hash, expiry, user_id, user_agent, ip, created_at, updated_at)`)).
+		WithArgs(tok.Hash, tok.Expiry, tok.UserID, tok.UserAgent, tok.IP, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.InsertSession(tok))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAuthRepository_FetchSessions verifies listing the unexpired tokens of a user, the current one marked.
+func TestAuthRepository_FetchSessions(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	userID, current, other := uuid.New(), uuid.New(), uuid.New()
+	hash := sha256.Sum256([]byte("token"))
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`from tokens where user_id = $1 and expiry > $3 order by created_at desc`)).
+		WithArgs(userID, hash[:], sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"token_id", "user_agent", "ip", "created_at", "expiry", "current"}).
+			AddRow(current, "Firefox", "203.0.113.7", now, now.Add(time.Hour), true).
+			AddRow(other, "", "", now.Add(-time.Hour), now.Add(time.Minute), false))
+
+	sessions, err := repo.FetchSessions(userID, "token")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, models.Session{ID: current, UserAgent: "Firefox", IP: "203.0.113.7", CreatedAt: now,
+		Expiry: now.Add(time.Hour), Current: true}, sessions[0])
+	assert.False(t, sessions[1].Current)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAuthRepository_DeleteSession verifies revoking a session of the user only.
+func TestAuthRepository_DeleteSession(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	userID, id := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`delete from tokens where token_id = $1 and user_id = $2`)
+
+	t.Run("revoked", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(id, userID).WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, repo.DeleteSession(userID, id))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("not the user's", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(id, userID).WillReturnResult(sqlmock.NewResult(0, 0))
+		assert.ErrorIs(t, repo.DeleteSession(userID, id), sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestAuthRepository_DeleteOtherSessions verifies revoking every session of a user but the current one.
+func TestAuthRepository_DeleteOtherSessions(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	userID := uuid.New()
+	hash := sha256.Sum256([]byte("token"))
+
+	mock.ExpectExec(regexp.QuoteMeta(`delete from tokens where user_id = $1 and token_hash <> $2`)).
+		WithArgs(userID, hash[:]).WillReturnResult(sqlmock.NewResult(0, 2))
+
+	n, err := repo.DeleteOtherSessions(userID, "token")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAuthRepository_DeleteToken verifies revoking a single token, and that an unknown one is reported.
+func TestAuthRepository_DeleteToken(t *testing.T) {
+	repo, mock, db := newTestRepo(t)
+	defer db.Close()
+	hash := sha256.Sum256([]byte("token"))
+	query := regexp.QuoteMeta(`delete from tokens where token_hash = $1`)
+
+	t.Run("revoked", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(hash[:]).WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, repo.DeleteToken("token"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("unknown", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(hash[:]).WillReturnResult(sqlmock.NewResult(0, 0))
+		assert.ErrorIs(t, repo.DeleteToken("token"), sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/internal/models"
@@ -9,13 +11,13 @@ import (
 
 type AuthenticateUC interface {
 	// Register signup a user
-	Register(user models.User, avatar string) (*models.UserResponse, error)
+	Register(ctx context.Context, user models.User, avatar string) (*models.UserResponse, error)
 
-	// Login login a user
-	Login(email, password string) (*models.UserResponse, error)
+	// Login login a user, starting a session for the device of r
+	Login(email, password string, r *http.Request) (*models.UserResponse, error)
 
 	// SendPasswordResetEmail process password and email reset
-	SendPasswordResetEmail(email string, r *http.Request) (*models.Response, error)
+	SendPasswordResetEmail(email string) (*models.Response, error)
 
 	// ResetPassword reset password
 	ResetPassword(token, password string) (*models.UserResponse, error)
@@ -23,8 +25,9 @@ type AuthenticateUC interface {
 	// UpdatePassword update password for a user by id
 	UpdatePassword(userId uuid.UUID, passwords models.Passwords) (*models.UserResponse, error)
 
-	// UpdateProfile update a user profile, returns error on failure
-	UpdateProfile(user models.User, avatar string) error
+	// UpdateProfile update a user profile, the name and email of user and the details of profile, returns
+	// error on failure
+	UpdateProfile(ctx context.Context, user models.User, profile models.ProfileUpdate, avatar string) error
 
 	// GetAllUsers fetches all users from the database and return a pointer to a slice of User structs
 	// or an error if any occurs during the process.
@@ -36,13 +39,62 @@ type AuthenticateUC interface {
 
 	// UpdateUser updates the user data in the database based on the provided userID and returns
 	// a pointer to the updated UserResponse struct or an error if any occurs during the process.
-	UpdateUser(userID uuid.UUID, user models.User) (*models.UserResponse, error)
+	UpdateUser(userID uuid.UUID, user models.User, profile models.ProfileUpdate) (*models.UserResponse, error)
 
 	// DeleteUser deletes the user data from the database based on the provided userID and returns
 	// an error if any occurs during the process.
-	DeleteUser(userID uuid.UUID) error
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
 
-	// DeleteUserToken deletes the user token from the database based on the provided userID and returns an error
+	// DeleteUserToken deletes the user token from the database, ending its session only, and returns an error
 	// if any occurs during the process.
 	DeleteUserToken(token string) error
+
+	// Sessions lists the active sessions of a user, the one of token marked current.
+	Sessions(userID uuid.UUID, token string) ([]models.Session, error)
+
+	// RevokeSession signs a user out of one of their sessions.
+	RevokeSession(userID, id uuid.UUID) error
+
+	// RevokeOtherSessions signs a user out of every session but the one of token and returns how many were revoked.
+	RevokeOtherSessions(userID uuid.UUID, token string) (int64, error)
+
+	// CreateUser creates a user with a generated temporary password and emails it to them (admin).
+	CreateUser(user models.User) (*models.UserResponse, error)
+
+	// UpdateUserRole changes the role of a user (admin). The role must be one of models.Roles.
+	UpdateUserRole(userID uuid.UUID, role string) (*models.UserResponse, error)
+
+	// SetCurrency sets the currency a user prefers to shop in, empty for the shop currency.
+	SetCurrency(userID uuid.UUID, currency string) (*models.UserResponse, error)
+
+	// SetLocale sets the locale a user reads emails and invoices in, empty for the shop locale.
+	SetLocale(userID uuid.UUID, locale string) (*models.UserResponse, error)
+
+	// DeleteExpiredTokens removes expired tokens and magic links from the database and returns how many were removed.
+	DeleteExpiredTokens(ctx context.Context) (int64, error)
+
+	// ScheduleDeletion schedules the deletion of a user's own account, signs them out and emails an undo link.
+	ScheduleDeletion(userID uuid.UUID) (*models.Response, error)
+
+	// RestoreAccount cancels a scheduled deletion using the token from the undo link.
+	RestoreAccount(token string) (*models.Response, error)
+
+	// PurgeDeletedAccounts deletes the accounts whose grace period is over and returns how many were deleted.
+	PurgeDeletedAccounts(ctx context.Context) (int64, error)
+
+	// SendMagicLink emails a signed single-use sign-in link bound to the device asking for it.
+	SendMagicLink(email, device string, r *http.Request) (*models.Response, error)
+
+	// MagicLinkLogin signs in with the magic link of r, on another device only once confirmed.
+	MagicLinkLogin(device string, confirmed bool, r *http.Request) (*models.UserResponse, error)
+
+	// MergeUsers merges the older of two duplicate accounts into the newer one, on behalf of an admin.
+	MergeUsers(firstID, secondID, adminID uuid.UUID) (*models.AccountMerge, error)
+
+	// PlanCleanup dry runs a bulk cleanup with a cutoff and returns what it would delete, with a single-use token
+	// that confirms it on behalf of the admin.
+	PlanCleanup(target string, before time.Time, adminID uuid.UUID) (*models.CleanupPlan, error)
+
+	// RunCleanup runs the bulk cleanup confirmed by the token of its dry run.
+	RunCleanup(ctx context.Context, target, token string, adminID uuid.UUID) (*models.CleanupResult, error)
 }
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"image"
+	_ "image/gif"  // register GIF decoding for avatars
+	_ "image/jpeg" // register JPEG decoding for avatars
+	_ "image/png"  // register PNG decoding for avatars
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/moderation"
+)
+
+// DefaultAvatarMaxSize is the largest accepted avatar in bytes by default.
+const DefaultAvatarMaxSize = 2 << 20
+
+// DefaultAvatarMaxAspectRatio is the largest ratio of an avatar's longer side to
+// its shorter one by default.
+const DefaultAvatarMaxAspectRatio = 2.0
+
+// AvatarPolicy constrains avatar uploads. MaxSize is the largest image in bytes and
+// MaxAspectRatio the largest ratio of the longer side to the shorter one; zero
+// fields fall back to DefaultAvatarMaxSize and DefaultAvatarMaxAspectRatio.
+// AllowedTypes lists the content types accepted, every one of avatarFormats when
+// empty. A nil Moderator accepts every avatar.
+type AvatarPolicy struct {
+	MaxSize        int
+	MaxAspectRatio float64
+	AllowedTypes   []string
+	Moderator      moderation.Moderator
+}
+
+// avatarFormats maps the image formats accepted for avatars to their content type.
+var avatarFormats = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+}
+
+// checkAvatar validates a base64 data URI avatar before it is uploaded: its size,
+// format and aspect ratio, then the verdict of the moderator. A rejected avatar is
+// reported as an *auth.AvatarError.
+func (a *AuthUC) checkAvatar(avatar string) error {
+	data, err := decodeAvatar(avatar, a.avatar.MaxSize)
+	if err != nil {
+		return err
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return a.avatarTypeError()
+	}
+	contentType, ok := avatarFormats[format]
+	if !ok || !a.avatarTypeAllowed(contentType) || cfg.Width == 0 || cfg.Height == 0 {
+		return a.avatarTypeError()
+	}
+
+	long, short := max(cfg.Width, cfg.Height), min(cfg.Width, cfg.Height)
+	if float64(long)/float64(short) > a.avatar.MaxAspectRatio {
+		return &auth.AvatarError{Reason: fmt.Sprintf("avatar aspect ratio must not exceed %g:1", a.avatar.MaxAspectRatio)}
+	}
+
+	if a.avatar.Moderator == nil {
+		return nil
+	}
+
+	verdict, err := a.avatar.Moderator.Review(context.Background(), data, contentType)
+	if err != nil {
+		return fmt.Errorf("error moderating avatar: %w", err)
+	}
+	if !verdict.Allowed {
+		reason := "avatar was rejected by moderation"
+		if verdict.Reason != "" {
+			reason += ": " + verdict.Reason
+		}
+		return &auth.AvatarError{Reason: reason}
+	}
+
+	return nil
+}
+
+// avatarTypeAllowed reports whether the policy accepts avatars of contentType.
+func (a *AuthUC) avatarTypeAllowed(contentType string) bool {
+	if len(a.avatar.AllowedTypes) == 0 {
+		return true
+	}
+	for _, t := range a.avatar.AllowedTypes {
+		if t == contentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// avatarTypeError rejects an avatar that is not an image of an allowed type.
+func (a *AuthUC) avatarTypeError() error {
+	var names []string
+	for _, f := range []string{"jpeg", "png", "gif"} {
+		if a.avatarTypeAllowed(avatarFormats[f]) {
+			names = append(names, strings.ToUpper(f))
+		}
+	}
+
+	if len(names) == 0 {
+		return &auth.AvatarError{Reason: "avatars are not accepted"}
+	}
+
+	list := names[len(names)-1]
+	if len(names) > 1 {
+		list = strings.Join(names[:len(names)-1], ", ") + " or " + list
+	}
+
+	return &auth.AvatarError{Reason: "avatar must be a " + list + " image"}
+}
+
+// decodeAvatar returns the bytes of a base64 data URI, rejecting images larger
+// than maxSize.
+func decodeAvatar(avatar string, maxSize int) ([]byte, error) {
+	i := strings.Index(avatar, ",")
+	if !strings.HasPrefix(avatar, "data:") || i < 0 || !strings.HasSuffix(avatar[:i], ";base64") {
+		return nil, &auth.AvatarError{Reason: "avatar must be a base64 encoded data uri"}
+	}
+
+	payload := avatar[i+1:]
+	if base64.StdEncoding.DecodedLen(len(payload)) > maxSize+2 {
+		return nil, &auth.AvatarError{Reason: fmt.Sprintf("avatar must not exceed %d bytes", maxSize)}
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, &auth.AvatarError{Reason: "avatar must be a base64 encoded data uri"}
+	}
+	if len(data) > maxSize {
+		return nil, &auth.AvatarError{Reason: fmt.Sprintf("avatar must not exceed %d bytes", maxSize)}
+	}
+
+	return data, nil
+}
+
+// storeAvatar uploads the avatar of a user and records it. The upload is discarded
+// when it cannot be recorded.
+func (a *AuthUC) storeAvatar(ctx context.Context, userID uuid.UUID, avatar string) (models.Avatar, error) {
+	res, err := a.cld.UploadToCloud(ctx, cloudinary.FolderAvatars, avatar)
+	if err != nil {
+		return models.Avatar{}, fmt.Errorf("error uploading to cloud: %w", err)
+	}
+
+	saved, err := a.repo.InsertAvatar(&models.Avatar{
+		PublicId: res.PublicID,
+		Url:      res.URL,
+		UserId:   userID,
+	})
+	if err != nil {
+		a.discardAsset(ctx, res.PublicID)
+		return models.Avatar{}, fmt.Errorf("error saving avatar: %v", err)
+	}
+
+	return saved, nil
+}
+
+// HandleAvatarPending stores the avatar a user signed up with, which could not be
+// stored then. An avatar the user replaced since, or of a user deleted since, is
+// dropped. It handles events.AvatarPending; a failed upload is retried by the outbox.
+func (a *AuthUC) HandleAvatarPending(e events.Event) error {
+	p, ok := e.Data.(models.PendingAvatar)
+	if !ok {
+		return fmt.Errorf("unexpected %s event data %T", e.Name, e.Data)
+	}
+
+	if _, err := a.repo.FetchAvatarById(p.UserID); !errors.Is(err, sql.ErrNoRows) {
+		if err != nil {
+			return fmt.Errorf("error fetching avatar by id: %v", err)
+		}
+		return nil
+	}
+
+	if _, err := a.repo.FetchUserById(p.UserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("error fetching user: %v", err)
+	}
+
+	_, err := a.storeAvatar(context.Background(), p.UserID, p.Avatar)
+	return err
+}
+
+// avatarColors are the backgrounds of the default avatars.
+var avatarColors = []string{"#1abc9c", "#2e86c1", "#8e44ad", "#d35400", "#c0392b", "#16a085", "#2c3e50", "#b7950b"}
+
+// defaultAvatar returns the avatar of a user who has none: their initials on a
+// background picked by their id, as an SVG data URI. It is not stored and has no
+// public id.
+func defaultAvatar(u *models.User) models.Avatar {
+	var initials []rune
+	for _, word := range strings.Fields(u.Name) {
+		r := []rune(word)[0]
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			initials = append(initials, unicode.ToUpper(r))
+		}
+		if len(initials) == 2 {
+			break
+		}
+	}
+	if len(initials) == 0 {
+		initials = []rune{'?'}
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write(u.ID[:])
+	color := avatarColors[h.Sum32()%uint32(len(avatarColors))]
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="128" height="128" viewBox="0 0 128 128">`+
+		`<rect width="128" height="128" fill="%s"/>`+
+		`<text x="50%%" y="50%%" dy=".35em" text-anchor="middle" fill="#fff" font-family="sans-serif" font-size="52">%s</text>`+
+		`</svg>`, color, string(initials))
+
+	return models.Avatar{
+		Url:    "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg)),
+		UserId: u.ID,
+	}
+}
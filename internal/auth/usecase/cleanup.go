@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// DefaultInactiveUserAge is how old a customer account without orders has to be for
+// the inactive users cleanup when no cutoff is given.
+const DefaultInactiveUserAge = 365 * 24 * time.Hour
+
+// CleanupConfirmationExpiry is how long the token of a cleanup dry run confirms it.
+const CleanupConfirmationExpiry = 10 * time.Minute
+
+// scopeCleanup is the scope of the token confirming a bulk cleanup.
+const scopeCleanup = "cleanup"
+
+// cleanupSample caps the ids listed by a dry run.
+const cleanupSample = 20
+
+// PlanCleanup dry runs the bulk cleanup target: it finds what it would delete with the
+// cutoff before and returns how many records, the ids of the first of them and a token
+// confirming it. The token is single use, for adminID only, and expires after
+// CleanupConfirmationExpiry. A zero cutoff is DefaultInactiveUserAge ago for inactive
+// users and now for expired sessions. It returns auth.ErrUnknownCleanup for an unknown
+// target.
+func (a *AuthUC) PlanCleanup(target string, before time.Time, adminID uuid.UUID) (*models.CleanupPlan, error) {
+	if !models.ValidCleanupTarget(target) {
+		return nil, auth.ErrUnknownCleanup
+	}
+
+	if before.IsZero() {
+		before = time.Now()
+		if target == models.CleanupInactiveUsers {
+			before = before.Add(-DefaultInactiveUserAge)
+		}
+	}
+
+	ids, err := a.repo.FetchCleanupCandidates(target, before)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching cleanup candidates: %w", err)
+	}
+
+	plan := &models.CleanupPlan{
+		Target:  target,
+		Before:  before,
+		Matched: len(ids),
+		Sample:  ids[:min(len(ids), cleanupSample)],
+	}
+	if len(ids) == 0 {
+		plan.Sample = []uuid.UUID{}
+		return plan, nil
+	}
+
+	t, err := a.token.GenerateToken(adminID, CleanupConfirmationExpiry, scopeCleanup)
+	if err != nil {
+		return nil, fmt.Errorf("error generating cleanup token: %v", err)
+	}
+
+	err = a.repo.InsertCleanupConfirmation(models.CleanupConfirmation{
+		AdminID:   adminID,
+		Target:    target,
+		Before:    before,
+		TokenHash: t.Hash,
+		Digest:    digestIDs(ids),
+		Matched:   len(ids),
+		Expiry:    t.Expiry,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error saving cleanup confirmation: %w", err)
+	}
+
+	plan.Token = t.PlainText
+	plan.ExpiresAt = &t.Expiry
+
+	return plan, nil
+}
+
+// RunCleanup runs the bulk cleanup target confirmed by token, the token of its dry run
+// by adminID, and uses the token up. The cleanup only runs if it still matches the
+// records of the dry run; if any appeared or went away since, it returns
+// auth.ErrCleanupChanged and a new dry run is needed. It returns
+// auth.ErrInvalidCleanupToken for a token that does not confirm this cleanup.
+//
+// Inactive users are deleted one by one, each only if it still has no orders; an
+// avatar that cannot be removed from storage is reported with the other errors once
+// the rest are deleted.
+func (a *AuthUC) RunCleanup(ctx context.Context, target, token string, adminID uuid.UUID) (*models.CleanupResult, error) {
+	if !models.ValidCleanupTarget(target) {
+		return nil, auth.ErrUnknownCleanup
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	c, err := a.repo.UseCleanupConfirmation(hash[:], adminID, time.Now())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, auth.ErrInvalidCleanupToken
+		}
+		return nil, fmt.Errorf("error using cleanup confirmation: %w", err)
+	}
+	if c.Target != target {
+		return nil, auth.ErrInvalidCleanupToken
+	}
+
+	ids, err := a.repo.FetchCleanupCandidates(target, c.Before)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching cleanup candidates: %w", err)
+	}
+	if subtle.ConstantTimeCompare(digestIDs(ids), c.Digest) != 1 {
+		return nil, auth.ErrCleanupChanged
+	}
+
+	res := &models.CleanupResult{Target: target}
+
+	switch target {
+	case models.CleanupExpiredSessions:
+		res.Deleted, err = a.repo.DeleteTokensExpiredBefore(c.Before)
+		if err != nil {
+			return nil, fmt.Errorf("error deleting expired sessions: %w", err)
+		}
+		return res, nil
+	default:
+		var errs []error
+		for _, id := range ids {
+			deleted, err := a.deleteInactiveUser(ctx, id, c.Before)
+			if deleted {
+				res.Deleted++
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("user %s: %w", id, err))
+			}
+		}
+		return res, errors.Join(errs...)
+	}
+}
+
+// deleteInactiveUser deletes the user with id if it is still inactive with the cutoff
+// before, then its avatar from storage; the avatar record goes with the user.
+func (a *AuthUC) deleteInactiveUser(ctx context.Context, id uuid.UUID, before time.Time) (bool, error) {
+	avatar, err := a.repo.FetchAvatarById(id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+	hasAvatar := err == nil
+
+	deleted, err := a.repo.DeleteInactiveUser(id, before)
+	if err != nil || !deleted {
+		return false, err
+	}
+
+	if hasAvatar {
+		if _, err := a.cld.Destroy(ctx, avatar.PublicId); err != nil {
+			return true, fmt.Errorf("error removing avatar: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// digestIDs hashes ids, in order, to tell whether a cleanup still matches its dry run.
+func digestIDs(ids []uuid.UUID) []byte {
+	h := sha256.New()
+	for _, id := range ids {
+		h.Write(id[:])
+	}
+	return h.Sum(nil)
+}
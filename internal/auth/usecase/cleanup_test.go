@@ -0,0 +1,143 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/api/uploader"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	mockRepo "github.com/jofosuware/go/shopit/internal/auth/mocks"
+	"github.com/jofosuware/go/shopit/internal/auth/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockBcrypt "github.com/jofosuware/go/shopit/pkg/bcrypt/mocks"
+	mockCloudinary "github.com/jofosuware/go/shopit/pkg/cloudinary/mocks"
+	mockMail "github.com/jofosuware/go/shopit/pkg/mailer/mocks"
+	mockToken "github.com/jofosuware/go/shopit/pkg/token/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanup(t *testing.T) {
+	repo := mockRepo.NewRepo(t)
+	mToken := mockToken.NewTokener(t)
+	cld := mockCloudinary.NewCloudUploader(t)
+	a := usecase.NewAuthUC(cld, repo, mToken, mockBcrypt.NewEncryptor(t), mockMail.NewMailer(t), 0,
+		usecase.PasswordReset{}, usecase.MagicLinks{}, usecase.AvatarPolicy{}, nil)
+
+	adminID := uuid.New()
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	before := time.Now().Add(-time.Hour)
+
+	// plan dry runs the inactive users cleanup matching ids and returns its token and
+	// the confirmation stored.
+	plan := func(t *testing.T) (string, models.CleanupConfirmation) {
+		t.Helper()
+
+		hash := sha256.Sum256([]byte("PLAINTEXT"))
+		tok := &models.Token{PlainText: "PLAINTEXT", Hash: hash[:], Expiry: time.Now().Add(usecase.CleanupConfirmationExpiry)}
+		var stored models.CleanupConfirmation
+		repo.On("FetchCleanupCandidates", models.CleanupInactiveUsers, before).Return(ids, nil).Once()
+		mToken.On("GenerateToken", adminID, usecase.CleanupConfirmationExpiry, "cleanup").Return(tok, nil).Once()
+		repo.On("InsertCleanupConfirmation", mock.AnythingOfType("models.CleanupConfirmation")).
+			Run(func(args mock.Arguments) { stored = args.Get(0).(models.CleanupConfirmation) }).Return(nil).Once()
+
+		p, err := a.PlanCleanup(models.CleanupInactiveUsers, before, adminID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, p.Matched)
+		assert.Equal(t, ids, p.Sample)
+		assert.Equal(t, stored.TokenHash, hash[:])
+		assert.Equal(t, adminID, stored.AdminID)
+
+		return p.Token, stored
+	}
+
+	t.Run("Dry run then cleanup", func(t *testing.T) {
+		token, c := plan(t)
+		hash := sha256.Sum256([]byte(token))
+		repo.On("UseCleanupConfirmation", hash[:], adminID, mock.AnythingOfType("time.Time")).Return(&c, nil).Once()
+		repo.On("FetchCleanupCandidates", models.CleanupInactiveUsers, before).Return(ids, nil).Once()
+		repo.On("FetchAvatarById", ids[0]).Return(models.Avatar{PublicId: "pid"}, nil).Once()
+		repo.On("DeleteInactiveUser", ids[0], before).Return(true, nil).Once()
+		cld.On("Destroy", mock.Anything, "pid").Return(&uploader.DestroyResult{Result: "ok"}, nil).Once()
+		repo.On("FetchAvatarById", ids[1]).Return(models.Avatar{}, sql.ErrNoRows).Once()
+		// ordered since the dry run
+		repo.On("DeleteInactiveUser", ids[1], before).Return(false, nil).Once()
+
+		res, err := a.RunCleanup(context.Background(), models.CleanupInactiveUsers, token, adminID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), res.Deleted)
+	})
+
+	t.Run("Nothing to clean up", func(t *testing.T) {
+		repo.On("FetchCleanupCandidates", models.CleanupExpiredSessions, mock.AnythingOfType("time.Time")).
+			Return([]uuid.UUID(nil), nil).Once()
+
+		p, err := a.PlanCleanup(models.CleanupExpiredSessions, time.Time{}, adminID)
+		require.NoError(t, err)
+		assert.Zero(t, p.Matched)
+		assert.Empty(t, p.Token)
+	})
+
+	t.Run("Records changed since the dry run", func(t *testing.T) {
+		token, c := plan(t)
+		repo.On("UseCleanupConfirmation", mock.Anything, adminID, mock.Anything).Return(&c, nil).Once()
+		repo.On("FetchCleanupCandidates", models.CleanupInactiveUsers, before).Return(ids[:1], nil).Once()
+
+		_, err := a.RunCleanup(context.Background(), models.CleanupInactiveUsers, token, adminID)
+		assert.ErrorIs(t, err, auth.ErrCleanupChanged)
+	})
+
+	t.Run("Token of another cleanup", func(t *testing.T) {
+		token, c := plan(t)
+		repo.On("UseCleanupConfirmation", mock.Anything, adminID, mock.Anything).Return(&c, nil).Once()
+
+		_, err := a.RunCleanup(context.Background(), models.CleanupExpiredSessions, token, adminID)
+		assert.ErrorIs(t, err, auth.ErrInvalidCleanupToken)
+	})
+
+	t.Run("Used or expired token", func(t *testing.T) {
+		repo.On("UseCleanupConfirmation", mock.Anything, adminID, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := a.RunCleanup(context.Background(), models.CleanupInactiveUsers, "PLAINTEXT", adminID)
+		assert.ErrorIs(t, err, auth.ErrInvalidCleanupToken)
+	})
+
+	t.Run("Expired sessions", func(t *testing.T) {
+		c := models.CleanupConfirmation{Target: models.CleanupExpiredSessions, Before: before,
+			Digest: sha256.New().Sum(nil)}
+		repo.On("UseCleanupConfirmation", mock.Anything, adminID, mock.Anything).Return(&c, nil).Once()
+		repo.On("FetchCleanupCandidates", models.CleanupExpiredSessions, before).Return([]uuid.UUID(nil), nil).Once()
+		repo.On("DeleteTokensExpiredBefore", before).Return(int64(0), nil).Once()
+
+		res, err := a.RunCleanup(context.Background(), models.CleanupExpiredSessions, "PLAINTEXT", adminID)
+		require.NoError(t, err)
+		assert.Zero(t, res.Deleted)
+	})
+
+	t.Run("Unknown cleanup", func(t *testing.T) {
+		_, err := a.PlanCleanup("orders", before, adminID)
+		assert.ErrorIs(t, err, auth.ErrUnknownCleanup)
+	})
+
+	t.Run("Avatar left in storage", func(t *testing.T) {
+		token, c := plan(t)
+		repo.On("UseCleanupConfirmation", mock.Anything, adminID, mock.Anything).Return(&c, nil).Once()
+		repo.On("FetchCleanupCandidates", models.CleanupInactiveUsers, before).Return(ids, nil).Once()
+		for _, id := range ids {
+			repo.On("FetchAvatarById", id).Return(models.Avatar{PublicId: id.String()}, nil).Once()
+			repo.On("DeleteInactiveUser", id, before).Return(true, nil).Once()
+		}
+		cld.On("Destroy", mock.Anything, ids[0].String()).Return(nil, errors.New("cloudinary error")).Once()
+		cld.On("Destroy", mock.Anything, ids[1].String()).Return(&uploader.DestroyResult{Result: "ok"}, nil).Once()
+
+		res, err := a.RunCleanup(context.Background(), models.CleanupInactiveUsers, token, adminID)
+		assert.Error(t, err)
+		assert.Equal(t, int64(2), res.Deleted)
+	})
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	"github.com/jofosuware/go/shopit/pkg/realip"
+	"github.com/jofosuware/go/shopit/pkg/urlsigner"
+)
+
+// DefaultMagicLinkExpiry is how long a magic sign-in link stays valid by default.
+const DefaultMagicLinkExpiry = 15 * time.Minute
+
+// scopeMagicLink is the scope of the token in a magic sign-in link.
+const scopeMagicLink = "magic-link"
+
+// MagicLinks configures passwordless sign-in links. Signer signs the links, so a
+// forged or stale link is turned down before it is looked up; no links are sent
+// without one. BaseURL is the public scheme and host of the API the links point at,
+// never taken from the request; the other links emailed, such as password reset and
+// account restore links, point at it too. A zero Expiry falls back to DefaultMagicLinkExpiry.
+type MagicLinks struct {
+	Expiry  time.Duration
+	BaseURL string
+	Signer  *urlsigner.Signer
+}
+
+// errMagicLinksDisabled is returned when magic links are used without a signer.
+var errMagicLinksDisabled = errors.New("magic links are not configured")
+
+// SendMagicLink emails the user with the email a signed, single-use link that signs
+// them in, pointing at the path of r on the configured BaseURL. The link is bound to device, a secret kept by
+// the client that asked for it: opened anywhere else, the sign-in has to be confirmed.
+// The email names the device, so users can tell a link they did not ask for. The
+// response is the same whether the email has an account or not, so it cannot be used
+// to find out who shops here.
+func (a *AuthUC) SendMagicLink(email, device string, r *http.Request) (*models.Response, error) {
+	if a.magicLinks.Signer == nil {
+		return nil, errMagicLinksDisabled
+	}
+	if email == "" {
+		return nil, errors.New("user must provide an email")
+	}
+	if device == "" {
+		return nil, errors.New("device must be provided")
+	}
+
+	res := &models.Response{
+		Success: true,
+		Message: fmt.Sprintf("If %s has an account, a sign-in link was sent to it.", email),
+	}
+
+	user, err := a.repo.FetchUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return res, nil
+		}
+		return nil, fmt.Errorf("error fetching user: %w", err)
+	}
+
+	// an account scheduled for deletion is restored with the link it was sent
+	if user.DeleteAfter != nil {
+		return res, nil
+	}
+
+	t, err := a.token.GenerateToken(user.ID, a.magicLinks.Expiry, scopeMagicLink)
+	if err != nil {
+		return nil, fmt.Errorf("error generating magic link token: %v", err)
+	}
+
+	l := models.MagicLink{
+		UserID:     user.ID,
+		TokenHash:  t.Hash,
+		DeviceHash: hashDevice(device),
+		IP:         realip.FromRequest(r),
+		UserAgent:  userAgent(r),
+		Expiry:     t.Expiry,
+	}
+	if err := a.repo.InsertMagicLink(l); err != nil {
+		return nil, fmt.Errorf("error saving magic link: %v", err)
+	}
+
+	link := a.magicLinks.Signer.GenerateTokenFromString(fmt.Sprintf("%s%s?token=%s", a.magicLinks.BaseURL, r.URL.Path, t.PlainText))
+
+	data := struct {
+		Name    string
+		Link    string
+		Expires string
+		Device  string
+		IP      string
+	}{
+		Name:    user.Name,
+		Link:    link,
+		Expires: humanDuration(a.magicLinks.Expiry, user.Locale),
+		Device:  l.UserAgent,
+		IP:      l.IP,
+	}
+	if data.Device == "" {
+		data.Device = i18n.T(user.Locale, "an unknown device")
+	}
+
+	subject := i18n.T(user.Locale, "Your ShopIT sign-in link")
+	if err := a.mail.SendMail(mailFrom, user.Email, subject, "magic-link", user.Locale, data); err != nil {
+		return nil, fmt.Errorf("error sending mail: %v", err)
+	}
+
+	return res, nil
+}
+
+// MagicLinkLogin signs in the user of the magic link r was made with. The link must
+// carry a valid signature, not have expired and not have been used. Opened on another
+// device than the one that asked for it, it returns auth.ErrMagicLinkUnconfirmed
+// without using the link, unless confirmed; so a link fetched by a mail scanner is
+// still good for the user. A link signs in once: of two requests racing with it, only
+// one gets through.
+func (a *AuthUC) MagicLinkLogin(device string, confirmed bool, r *http.Request) (*models.UserResponse, error) {
+	if a.magicLinks.Signer == nil {
+		return nil, errMagicLinksDisabled
+	}
+
+	plainText := r.URL.Query().Get("token")
+	link := a.magicLinks.BaseURL + r.URL.RequestURI()
+	minutes := int(math.Ceil(a.magicLinks.Expiry.Minutes()))
+
+	if plainText == "" || !a.magicLinks.Signer.VerifyToken(link) || a.magicLinks.Signer.Expired(link, minutes) {
+		return nil, auth.ErrInvalidMagicLink
+	}
+
+	hash := sha256.Sum256([]byte(plainText))
+	l, err := a.repo.FetchMagicLink(hash[:])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, auth.ErrInvalidMagicLink
+		}
+		return nil, fmt.Errorf("error fetching magic link: %w", err)
+	}
+
+	now := time.Now()
+	if l.UsedAt != nil || !now.Before(l.Expiry) {
+		return nil, auth.ErrInvalidMagicLink
+	}
+
+	if !confirmed && subtle.ConstantTimeCompare(hashDevice(device), l.DeviceHash) != 1 {
+		return nil, auth.ErrMagicLinkUnconfirmed
+	}
+
+	if err := a.repo.UseMagicLink(l.ID, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, auth.ErrInvalidMagicLink
+		}
+		return nil, fmt.Errorf("error using magic link: %w", err)
+	}
+
+	user, err := a.repo.FetchUserById(l.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %w", err)
+	}
+
+	if user.DeleteAfter != nil {
+		return nil, auth.ErrPendingDeletion
+	}
+
+	return a.signIn(user, r)
+}
+
+// hashDevice returns the hash of the device secret of a magic link, as it is stored.
+func hashDevice(device string) []byte {
+	hash := sha256.Sum256([]byte(device))
+	return hash[:]
+}
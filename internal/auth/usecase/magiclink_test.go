@@ -0,0 +1,191 @@
+package usecase_test
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	mockRepo "github.com/jofosuware/go/shopit/internal/auth/mocks"
+	"github.com/jofosuware/go/shopit/internal/auth/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockBcrypt "github.com/jofosuware/go/shopit/pkg/bcrypt/mocks"
+	mockCloudinary "github.com/jofosuware/go/shopit/pkg/cloudinary/mocks"
+	mockMail "github.com/jofosuware/go/shopit/pkg/mailer/mocks"
+	"github.com/jofosuware/go/shopit/pkg/token"
+	mockToken "github.com/jofosuware/go/shopit/pkg/token/mocks"
+	"github.com/jofosuware/go/shopit/pkg/urlsigner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	magicLinkBase = "https://api.shop.example.com"
+	magicLinkURL  = magicLinkBase + "/api/v1/auth/magic-link"
+)
+
+// newMagicLinkUC returns an AuthUC signing magic links, with its mocked dependencies.
+func newMagicLinkUC(t *testing.T) (*usecase.AuthUC, *mockRepo.Repo, *mockToken.Tokener, *mockMail.Mailer) {
+	repo := mockRepo.NewRepo(t)
+	mToken := mockToken.NewTokener(t)
+	mail := mockMail.NewMailer(t)
+	a := usecase.NewAuthUC(mockCloudinary.NewCloudUploader(t), repo, mToken, mockBcrypt.NewEncryptor(t), mail, 0,
+		usecase.PasswordReset{}, usecase.MagicLinks{BaseURL: magicLinkBase + "/", Signer: &urlsigner.Signer{Secret: []byte("secret")}}, usecase.AvatarPolicy{}, nil)
+
+	return a, repo, mToken, mail
+}
+
+// sendMagicLink sends a magic link to u from device and returns the link emailed
+// and the magic link stored.
+func sendMagicLink(t *testing.T, a *usecase.AuthUC, repo *mockRepo.Repo, mToken *mockToken.Tokener, mail *mockMail.Mailer, u *models.User, device string) (string, models.MagicLink) {
+	t.Helper()
+
+	plainText := "PLAINTEXT" + strings.ToUpper(uuid.NewString()[:8])
+	hash := sha256.Sum256([]byte(plainText))
+	tok := &models.Token{PlainText: plainText, Hash: hash[:], Expiry: time.Now().Add(usecase.DefaultMagicLinkExpiry)}
+
+	var (
+		stored models.MagicLink
+		link   string
+	)
+	repo.On("FetchUserByEmail", u.Email).Return(u, nil).Once()
+	mToken.On("GenerateToken", u.ID, usecase.DefaultMagicLinkExpiry, "magic-link").Return(tok, nil).Once()
+	repo.On("InsertMagicLink", mock.AnythingOfType("models.MagicLink")).
+		Run(func(args mock.Arguments) { stored = args.Get(0).(models.MagicLink) }).Return(nil).Once()
+	mail.On("SendMail", mock.Anything, u.Email, mock.Anything, "magic-link", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { link = reflect.ValueOf(args.Get(5)).FieldByName("Link").String() }).Return(nil).Once()
+
+	// the link points at the base url whatever host the request names
+	req := httptest.NewRequest(http.MethodPost, "http://evil.example/api/v1/auth/magic-link", nil)
+	req.Header.Set("User-Agent", "Firefox")
+	req.Header.Set("X-Forwarded-Proto", "http")
+	res, err := a.SendMagicLink(u.Email, device, req)
+	require.NoError(t, err)
+	require.True(t, res.Success)
+
+	stored.ID = uuid.New()
+	return link, stored
+}
+
+func TestSendMagicLink(t *testing.T) {
+	a, repo, mToken, mail := newMagicLinkUC(t)
+	u := &models.User{ID: uuid.New(), Name: "Ama", Email: "ama@example.com"}
+
+	t.Run("Link is signed, stored hashed and emailed", func(t *testing.T) {
+		link, stored := sendMagicLink(t, a, repo, mToken, mail, u, "device")
+
+		assert.True(t, strings.HasPrefix(link, magicLinkURL+"?token=PLAINTEXT"))
+		assert.Contains(t, link, "&hash=")
+		assert.Equal(t, u.ID, stored.UserID)
+		assert.NotContains(t, string(stored.TokenHash), "PLAINTEXT")
+		assert.NotEqual(t, []byte("device"), stored.DeviceHash)
+		assert.Equal(t, "192.0.2.1", stored.IP)
+		assert.Equal(t, "Firefox", stored.UserAgent)
+	})
+
+	t.Run("Unknown email gets the same answer", func(t *testing.T) {
+		repo.On("FetchUserByEmail", "nobody@example.com").Return(nil, sql.ErrNoRows).Once()
+
+		res, err := a.SendMagicLink("nobody@example.com", "device", httptest.NewRequest(http.MethodPost, magicLinkURL, nil))
+		require.NoError(t, err)
+		assert.True(t, res.Success)
+	})
+
+	t.Run("Account pending deletion gets no link", func(t *testing.T) {
+		deleteAfter := time.Now().Add(time.Hour)
+		pending := &models.User{ID: uuid.New(), Email: "gone@example.com", DeleteAfter: &deleteAfter}
+		repo.On("FetchUserByEmail", pending.Email).Return(pending, nil).Once()
+
+		res, err := a.SendMagicLink(pending.Email, "device", httptest.NewRequest(http.MethodPost, magicLinkURL, nil))
+		require.NoError(t, err)
+		assert.True(t, res.Success)
+	})
+
+	t.Run("Not configured", func(t *testing.T) {
+		a, _, _, _, _, _ := newTestAuthUC(t)
+
+		_, err := a.SendMagicLink(u.Email, "device", httptest.NewRequest(http.MethodPost, magicLinkURL, nil))
+		assert.Error(t, err)
+	})
+}
+
+func TestMagicLinkLogin(t *testing.T) {
+	a, repo, mToken, mail := newMagicLinkUC(t)
+	u := &models.User{ID: uuid.New(), Name: "Ama", Email: "ama@example.com"}
+
+	expectSignIn := func() {
+		repo.On("FetchUserById", u.ID).Return(u, nil).Once()
+		mToken.On("GenerateToken", u.ID, 24*time.Hour, token.ScopeAuthentication).Return(&models.Token{PlainText: "session"}, nil).Once()
+		repo.On("InsertSession", mock.MatchedBy(func(t *models.Token) bool { return t.PlainText == "session" })).Return(nil).Once()
+		repo.On("FetchAvatarById", u.ID).Return(models.Avatar{}, nil).Once()
+	}
+
+	t.Run("Same device signs in once", func(t *testing.T) {
+		link, stored := sendMagicLink(t, a, repo, mToken, mail, u, "device")
+		repo.On("FetchMagicLink", stored.TokenHash).Return(&stored, nil).Once()
+		repo.On("UseMagicLink", stored.ID, mock.AnythingOfType("time.Time")).Return(nil).Once()
+		expectSignIn()
+
+		res, err := a.MagicLinkLogin("device", false, httptest.NewRequest(http.MethodGet, link, nil))
+		require.NoError(t, err)
+		assert.Equal(t, "session", res.Token)
+
+		usedAt := time.Now()
+		stored.UsedAt = &usedAt
+		repo.On("FetchMagicLink", stored.TokenHash).Return(&stored, nil).Once()
+
+		_, err = a.MagicLinkLogin("device", false, httptest.NewRequest(http.MethodGet, link, nil))
+		assert.ErrorIs(t, err, auth.ErrInvalidMagicLink)
+	})
+
+	t.Run("Another device must confirm", func(t *testing.T) {
+		link, stored := sendMagicLink(t, a, repo, mToken, mail, u, "device")
+		repo.On("FetchMagicLink", stored.TokenHash).Return(&stored, nil).Twice()
+
+		_, err := a.MagicLinkLogin("", false, httptest.NewRequest(http.MethodGet, link, nil))
+		assert.ErrorIs(t, err, auth.ErrMagicLinkUnconfirmed)
+
+		repo.On("UseMagicLink", stored.ID, mock.AnythingOfType("time.Time")).Return(nil).Once()
+		expectSignIn()
+
+		res, err := a.MagicLinkLogin("other", true, httptest.NewRequest(http.MethodPut, link, nil))
+		require.NoError(t, err)
+		assert.Equal(t, "session", res.Token)
+	})
+
+	t.Run("Replay racing the first use", func(t *testing.T) {
+		link, stored := sendMagicLink(t, a, repo, mToken, mail, u, "device")
+		repo.On("FetchMagicLink", stored.TokenHash).Return(&stored, nil).Once()
+		repo.On("UseMagicLink", stored.ID, mock.AnythingOfType("time.Time")).Return(sql.ErrNoRows).Once()
+
+		_, err := a.MagicLinkLogin("device", false, httptest.NewRequest(http.MethodGet, link, nil))
+		assert.ErrorIs(t, err, auth.ErrInvalidMagicLink)
+	})
+
+	t.Run("Tampered link", func(t *testing.T) {
+		link, _ := sendMagicLink(t, a, repo, mToken, mail, u, "device")
+		forged := strings.Replace(link, "token=PLAINTEXT", "token=FORGEDXXX", 1)
+
+		_, err := a.MagicLinkLogin("device", false, httptest.NewRequest(http.MethodGet, forged, nil))
+		assert.ErrorIs(t, err, auth.ErrInvalidMagicLink)
+
+		_, err = a.MagicLinkLogin("device", false, httptest.NewRequest(http.MethodGet, magicLinkURL+"?token=PLAINTEXT", nil))
+		assert.ErrorIs(t, err, auth.ErrInvalidMagicLink)
+	})
+
+	t.Run("Expired link", func(t *testing.T) {
+		link, stored := sendMagicLink(t, a, repo, mToken, mail, u, "device")
+		stored.Expiry = time.Now().Add(-time.Second)
+		repo.On("FetchMagicLink", stored.TokenHash).Return(&stored, nil).Once()
+
+		_, err := a.MagicLinkLogin("device", false, httptest.NewRequest(http.MethodGet, link, nil))
+		assert.ErrorIs(t, err, auth.ErrInvalidMagicLink)
+	})
+}
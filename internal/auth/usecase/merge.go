@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// MergeUsers merges two accounts of the same customer, as support does for duplicate
+// registrations. The newest account is kept with its profile; the orders, reviews and
+// store credit of the other are moved to it and the other is deleted. Both are signed
+// out. adminID is recorded as having made the merge. It returns sql.ErrNoRows if either
+// user does not exist, auth.ErrSameUser, auth.ErrMergeRoles if the accounts do not have
+// the same role, or auth.ErrPendingDeletion if either is scheduled for deletion.
+func (a *AuthUC) MergeUsers(firstID, secondID, adminID uuid.UUID) (*models.AccountMerge, error) {
+	if firstID == secondID {
+		return nil, auth.ErrSameUser
+	}
+
+	first, err := a.repo.FetchUserById(firstID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %w", err)
+	}
+
+	second, err := a.repo.FetchUserById(secondID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %w", err)
+	}
+
+	if first.Role != second.Role {
+		return nil, auth.ErrMergeRoles
+	}
+	if first.DeleteAfter != nil || second.DeleteAfter != nil {
+		return nil, auth.ErrPendingDeletion
+	}
+
+	kept, merged := first, second
+	if merged.CreatedAt.After(kept.CreatedAt) {
+		kept, merged = merged, kept
+	}
+
+	m, err := a.repo.MergeUsers(models.AccountMerge{
+		KeptUserID:   kept.ID,
+		MergedUserID: merged.ID,
+		MergedName:   merged.Name,
+		MergedEmail:  merged.Email,
+		MergedBy:     adminID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error merging users: %w", err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,84 @@
+package usecase_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeUsers(t *testing.T) {
+	a, repo, _, _ := newMagicLinkUC(t)
+	adminID := uuid.New()
+	older := &models.User{ID: uuid.New(), Name: "Ama", Email: "ama.old@example.com", Role: models.RoleUser,
+		CreatedAt: time.Now().Add(-48 * time.Hour)}
+	newer := &models.User{ID: uuid.New(), Name: "Ama Mensah", Email: "ama@example.com", Role: models.RoleUser,
+		CreatedAt: time.Now()}
+
+	t.Run("Newest account is kept", func(t *testing.T) {
+		repo.On("FetchUserById", newer.ID).Return(newer, nil).Once()
+		repo.On("FetchUserById", older.ID).Return(older, nil).Once()
+		repo.On("MergeUsers", models.AccountMerge{
+			KeptUserID:   newer.ID,
+			MergedUserID: older.ID,
+			MergedName:   older.Name,
+			MergedEmail:  older.Email,
+			MergedBy:     adminID,
+		}).Return(&models.AccountMerge{KeptUserID: newer.ID, MergedUserID: older.ID, Orders: 2}, nil).Once()
+
+		m, err := a.MergeUsers(newer.ID, older.ID, adminID)
+		require.NoError(t, err)
+		assert.Equal(t, newer.ID, m.KeptUserID)
+		assert.Equal(t, 2, m.Orders)
+	})
+
+	t.Run("Order of the ids does not matter", func(t *testing.T) {
+		repo.On("FetchUserById", older.ID).Return(older, nil).Once()
+		repo.On("FetchUserById", newer.ID).Return(newer, nil).Once()
+		repo.On("MergeUsers", mock.MatchedBy(func(m models.AccountMerge) bool {
+			return m.KeptUserID == newer.ID && m.MergedUserID == older.ID
+		})).Return(&models.AccountMerge{KeptUserID: newer.ID}, nil).Once()
+
+		_, err := a.MergeUsers(older.ID, newer.ID, adminID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Same account", func(t *testing.T) {
+		_, err := a.MergeUsers(older.ID, older.ID, adminID)
+		assert.ErrorIs(t, err, auth.ErrSameUser)
+	})
+
+	t.Run("Different roles", func(t *testing.T) {
+		admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+		repo.On("FetchUserById", older.ID).Return(older, nil).Once()
+		repo.On("FetchUserById", admin.ID).Return(admin, nil).Once()
+
+		_, err := a.MergeUsers(older.ID, admin.ID, adminID)
+		assert.ErrorIs(t, err, auth.ErrMergeRoles)
+	})
+
+	t.Run("Account pending deletion", func(t *testing.T) {
+		deleteAfter := time.Now().Add(time.Hour)
+		pending := &models.User{ID: uuid.New(), Role: models.RoleUser, DeleteAfter: &deleteAfter}
+		repo.On("FetchUserById", older.ID).Return(older, nil).Once()
+		repo.On("FetchUserById", pending.ID).Return(pending, nil).Once()
+
+		_, err := a.MergeUsers(older.ID, pending.ID, adminID)
+		assert.ErrorIs(t, err, auth.ErrPendingDeletion)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		unknown := uuid.New()
+		repo.On("FetchUserById", older.ID).Return(older, nil).Once()
+		repo.On("FetchUserById", unknown).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := a.MergeUsers(older.ID, unknown, adminID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
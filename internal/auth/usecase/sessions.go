@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// maxUserAgent caps the user agent kept to describe a device that signed in or
+// requested a magic link.
+const maxUserAgent = 512
+
+// userAgent returns the user agent of r, capped to maxUserAgent bytes.
+func userAgent(r *http.Request) string {
+	ua := r.UserAgent()
+	if len(ua) > maxUserAgent {
+		ua = ua[:maxUserAgent]
+	}
+
+	return ua
+}
+
+// Sessions lists the unexpired sessions of a user, newest first. The session of token,
+// the one of the request, is marked current.
+func (a *AuthUC) Sessions(userID uuid.UUID, token string) ([]models.Session, error) {
+	sessions, err := a.repo.FetchSessions(userID, token)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching sessions: %v", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs a user out of session id, which may be the current one. It
+// returns auth.ErrSessionNotFound when the user has no such session.
+func (a *AuthUC) RevokeSession(userID, id uuid.UUID) error {
+	if err := a.repo.DeleteSession(userID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return auth.ErrSessionNotFound
+		}
+		return fmt.Errorf("error revoking session: %v", err)
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions signs a user out of every session but the one of token, and
+// returns how many sessions were revoked.
+func (a *AuthUC) RevokeOtherSessions(userID uuid.UUID, token string) (int64, error) {
+	n, err := a.repo.DeleteOtherSessions(userID, token)
+	if err != nil {
+		return 0, fmt.Errorf("error revoking sessions: %v", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,56 @@
+package usecase_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessions(t *testing.T) {
+	a, _, repo, _, _, _ := newTestAuthUC(t)
+	userID := uuid.New()
+
+	t.Run("Sessions are listed", func(t *testing.T) {
+		sessions := []models.Session{{ID: uuid.New(), Current: true}, {ID: uuid.New()}}
+		repo.On("FetchSessions", userID, "token").Return(sessions, nil).Once()
+
+		got, err := a.Sessions(userID, "token")
+		require.NoError(t, err)
+		assert.Equal(t, sessions, got)
+	})
+
+	t.Run("A session is revoked", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("DeleteSession", userID, id).Return(nil).Once()
+
+		assert.NoError(t, a.RevokeSession(userID, id))
+	})
+
+	t.Run("Session of another user", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("DeleteSession", userID, id).Return(sql.ErrNoRows).Once()
+
+		assert.ErrorIs(t, a.RevokeSession(userID, id), auth.ErrSessionNotFound)
+	})
+
+	t.Run("Other sessions are revoked", func(t *testing.T) {
+		repo.On("DeleteOtherSessions", userID, "token").Return(int64(3), nil).Once()
+
+		n, err := a.RevokeOtherSessions(userID, "token")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+	})
+
+	t.Run("Database failure", func(t *testing.T) {
+		repo.On("DeleteOtherSessions", userID, "token").Return(int64(0), errors.New("db down")).Once()
+
+		_, err := a.RevokeOtherSessions(userID, "token")
+		assert.ErrorContains(t, err, "db down")
+	})
+}
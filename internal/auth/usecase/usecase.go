@@ -6,9 +6,14 @@
 package usecase
 
 import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -17,46 +22,122 @@ import (
 	"github.com/jofosuware/go/shopit/internal/models"
 	"github.com/jofosuware/go/shopit/pkg/bcrypt"
 	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
 	"github.com/jofosuware/go/shopit/pkg/mailer"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/realip"
 	"github.com/jofosuware/go/shopit/pkg/token"
 )
 
+// mailFrom is the sender of account emails.
+const mailFrom = mailer.DefaultFrom
+
+// DefaultDeletionGrace is how long an account scheduled for deletion can still be restored.
+const DefaultDeletionGrace = 30 * 24 * time.Hour
+
+// DefaultPasswordResetExpiry is how long a password reset link stays valid by default.
+const DefaultPasswordResetExpiry = 60 * time.Minute
+
+// DefaultPasswordResetURL is the default password reset link, relative to the base url of the links in emails.
+const DefaultPasswordResetURL = "/password/reset/{token}"
+
+// PasswordReset configures password reset links. Expiry is how long a link stays
+// valid. URL is the link, with {token} standing for the reset token; a URL starting
+// with / is relative to the BaseURL of MagicLinks. Zero fields fall back to
+// DefaultPasswordResetExpiry and DefaultPasswordResetURL.
+type PasswordReset struct {
+	Expiry time.Duration
+	URL    string
+}
+
+// scopeAccountRestore is the scope of the token in the account restore link.
+const scopeAccountRestore = "account-restore"
+
 // AuthUC provides authentication and user management use cases.
 // It should be constructed with all required dependencies.
 type AuthUC struct {
-	cld    cloudinary.CloudUploader
-	repo   auth.Repo
-	token  token.Tokener
-	bcrypt bcrypt.Encryptor
-	mail   mailer.Mailer
+	cld           cloudinary.CloudUploader
+	repo          auth.Repo
+	token         token.Tokener
+	bcrypt        bcrypt.Encryptor
+	mail          mailer.Mailer
+	deletionGrace time.Duration
+	passwordReset PasswordReset
+	magicLinks    MagicLinks
+	avatar        AvatarPolicy
+	events        *events.Bus
 }
 
-// NewAuthUC returns a new AuthUC with the provided dependencies.
+// NewAuthUC returns a new AuthUC with the provided dependencies. A non-positive
+// deletionGrace falls back to DefaultDeletionGrace. Sign-ups are published on bus.
 func NewAuthUC(
 	cld cloudinary.CloudUploader,
 	repo auth.Repo,
 	token token.Tokener,
 	b bcrypt.Encryptor,
 	mail mailer.Mailer,
+	deletionGrace time.Duration,
+	passwordReset PasswordReset,
+	magicLinks MagicLinks,
+	avatar AvatarPolicy,
+	bus *events.Bus,
 ) *AuthUC {
+	if deletionGrace <= 0 {
+		deletionGrace = DefaultDeletionGrace
+	}
+	if passwordReset.Expiry <= 0 {
+		passwordReset.Expiry = DefaultPasswordResetExpiry
+	}
+	if passwordReset.URL == "" {
+		passwordReset.URL = DefaultPasswordResetURL
+	}
+	if magicLinks.Expiry <= 0 {
+		magicLinks.Expiry = DefaultMagicLinkExpiry
+	}
+	magicLinks.BaseURL = strings.TrimSuffix(magicLinks.BaseURL, "/")
+	if avatar.MaxSize <= 0 {
+		avatar.MaxSize = DefaultAvatarMaxSize
+	}
+	if avatar.MaxAspectRatio <= 0 {
+		avatar.MaxAspectRatio = DefaultAvatarMaxAspectRatio
+	}
+
 	return &AuthUC{
-		cld:    cld,
-		repo:   repo,
-		token:  token,
-		bcrypt: b,
-		mail:   mail,
+		cld:           cld,
+		repo:          repo,
+		token:         token,
+		bcrypt:        b,
+		mail:          mail,
+		deletionGrace: deletionGrace,
+		passwordReset: passwordReset,
+		magicLinks:    magicLinks,
+		avatar:        avatar,
+		events:        bus,
 	}
 }
 
 // Register creates a new user, uploads avatar, and returns a user response with token.
-func (a *AuthUC) Register(user models.User, avatar string) (*models.UserResponse, error) {
+// The avatar is optional; without one, the user gets a default avatar of their
+// initials. An avatar that fails the avatar policy fails the registration, but one
+// that cannot be stored does not: it is queued in the outbox to be uploaded again,
+// and the user has the default avatar meanwhile. The new user is published as
+// events.UserRegistered.
+// The avatar is checked against the avatar policy before the user is saved.
+func (a *AuthUC) Register(ctx context.Context, user models.User, avatar string) (*models.UserResponse, error) {
 	u, err := a.repo.FetchUserByEmail(user.Email)
 	if err != nil && err.Error() != "sql: no rows in result set" {
 		return nil, fmt.Errorf("error fetching user: %v", err)
 	}
 
-	if err == nil && u.Email == user.Email {
-		return nil, fmt.Errorf("user %s already exists", u.Name)
+	if err == nil {
+		return nil, fmt.Errorf("%w: %s", auth.ErrDuplicateEmail, user.Email)
+	}
+
+	if avatar != "" {
+		if err := a.checkAvatar(avatar); err != nil {
+			return nil, err
+		}
 	}
 
 	hashPassword, err := a.bcrypt.GenerateFromPassword([]byte(user.Password))
@@ -68,14 +149,13 @@ func (a *AuthUC) Register(user models.User, avatar string) (*models.UserResponse
 
 	u, err = a.repo.InsertUser(user)
 	if err != nil {
+		// the email may have been taken since it was checked
+		if errors.Is(err, auth.ErrDuplicateEmail) {
+			return nil, fmt.Errorf("%w: %s", auth.ErrDuplicateEmail, user.Email)
+		}
 		return nil, fmt.Errorf("error saving user: %v", err)
 	}
 
-	res, err := a.cld.UploadToCloud("avatar", avatar)
-	if err != nil {
-		return nil, fmt.Errorf("error uploading to cloud: %v", err)
-	}
-
 	t, err := a.token.GenerateToken(u.ID, 24*time.Hour, token.ScopeAuthentication)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %v", err)
@@ -86,18 +166,19 @@ func (a *AuthUC) Register(user models.User, avatar string) (*models.UserResponse
 		return nil, fmt.Errorf("error saving token: %v", err)
 	}
 
-	avtar := models.Avatar{
-		PublicId: res.PublicID,
-		Url:      res.URL,
-		UserId:   u.ID,
-	}
-
-	avtar, err = a.repo.InsertAvatar(&avtar)
-	if err != nil {
-		return nil, fmt.Errorf("error saving avatar: %v", err)
+	u.Avatar = defaultAvatar(u)
+	if avatar != "" {
+		saved, err := a.storeAvatar(ctx, u.ID, avatar)
+		if err == nil {
+			u.Avatar = saved
+		} else if err := a.repo.QueueAvatar(models.PendingAvatar{UserID: u.ID, Avatar: avatar}); err != nil {
+			return nil, fmt.Errorf("error queueing avatar: %v", err)
+		}
 	}
 
-	u.Avatar = avtar
+	registered := *u
+	registered.Password = ""
+	a.events.Publish(events.UserRegistered, registered)
 
 	ur := &models.UserResponse{
 		Success: true,
@@ -108,8 +189,9 @@ func (a *AuthUC) Register(user models.User, avatar string) (*models.UserResponse
 	return ur, nil
 }
 
-// Login authenticates a user and returns a user response with token.
-func (a *AuthUC) Login(email, password string) (*models.UserResponse, error) {
+// Login authenticates a user and returns a user response with the token of a new
+// session for the device of r.
+func (a *AuthUC) Login(email, password string, r *http.Request) (*models.UserResponse, error) {
 	u, err := a.repo.FetchUserByEmail(email)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching user by email: %v", err)
@@ -119,19 +201,35 @@ func (a *AuthUC) Login(email, password string) (*models.UserResponse, error) {
 		return nil, fmt.Errorf("error comparing password: %v", err)
 	}
 
-	t, err := a.token.GenerateToken(u.ID, 24*time.Hour, "authentication")
+	if u.DeleteAfter != nil {
+		return nil, auth.ErrPendingDeletion
+	}
+
+	return a.signIn(u, r)
+}
+
+// signIn issues a new authentication token to u for the device of r, starting a
+// session next to their others, and returns it with the user and their avatar.
+func (a *AuthUC) signIn(u *models.User, r *http.Request) (*models.UserResponse, error) {
+	t, err := a.token.GenerateToken(u.ID, 24*time.Hour, token.ScopeAuthentication)
 	if err != nil {
 		return nil, fmt.Errorf("error generating token: %v", err)
 	}
 
-	if err = a.repo.InsertToken(t, u.ID); err != nil {
+	t.UserAgent = userAgent(r)
+	t.IP = realip.FromRequest(r)
+	if err = a.repo.InsertSession(t); err != nil {
 		return nil, fmt.Errorf("error saving token: %v", err)
 	}
 
+	// users without an avatar get the default one until they upload theirs
 	avatar, err := a.repo.FetchAvatarById(u.ID)
-	if err != nil {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("error fetching avatar by id: %v", err)
 	}
+	if err != nil {
+		avatar = defaultAvatar(u)
+	}
 
 	u.Avatar = avatar
 
@@ -145,15 +243,7 @@ func (a *AuthUC) Login(email, password string) (*models.UserResponse, error) {
 }
 
 // SendPasswordResetEmail sends a password reset email to the given address.
-func (a *AuthUC) SendPasswordResetEmail(email string, r *http.Request) (*models.Response, error) {
-	var protocol string
-	if forwarded := r.Header.Get("X-Forwarded-Proto"); forwarded != "" {
-		protocol = forwarded
-	} else if r.TLS != nil {
-		protocol = "https"
-	} else {
-		protocol = "http"
-	}
+func (a *AuthUC) SendPasswordResetEmail(email string) (*models.Response, error) {
 	if email == "" {
 		return nil, errors.New("user must provide an email")
 	}
@@ -164,21 +254,26 @@ func (a *AuthUC) SendPasswordResetEmail(email string, r *http.Request) (*models.
 	}
 
 	// generate token
-	t, err := a.token.GenerateToken(user.ID, 60*time.Minute, token.ScopeAuthentication)
+	t, err := a.token.GenerateToken(user.ID, a.passwordReset.Expiry, token.ScopeAuthentication)
 	if err != nil {
 		return nil, err
 	}
 
-	resetUrl := fmt.Sprintf("%s://%s/password/reset/%s", protocol, strings.Split(r.Host, ":")[0], t.PlainText)
+	resetUrl := strings.ReplaceAll(a.passwordReset.URL, "{token}", url.PathEscape(t.PlainText))
+	if strings.HasPrefix(resetUrl, "/") {
+		resetUrl = a.siteURL() + resetUrl
+	}
 
 	var data struct {
-		Link string
+		Link    string
+		Expires string
 	}
 
 	data.Link = resetUrl
+	data.Expires = humanDuration(a.passwordReset.Expiry, user.Locale)
 
 	//send mail
-	err = a.mail.SendMail("DePeridot <postmaster@sandboxa7a6fd0db7744e4f8917325ae3ce1a04.mailgun.org>", email, "ShopIT Password Recovery", "password-reset", data)
+	err = a.mail.SendMail(mailFrom, email, i18n.T(user.Locale, "ShopIT Password Recovery"), "password-reset", user.Locale, data)
 	if err != nil {
 		return nil, fmt.Errorf("error sending mail: %v", err)
 	}
@@ -228,9 +323,8 @@ func (a *AuthUC) ResetPassword(newToken, password string) (*models.UserResponse,
 		return nil, err
 	}
 
-	// update password
-	user.Password = string(hashedPassword)
-	err = a.repo.UpdateUser(*user)
+	// update password, the user of the token only has some of the details
+	err = a.repo.UpdatePassword(models.PasswordChange{UserID: user.ID, ChangedAt: time.Now()}, string(hashedPassword))
 	if err != nil {
 		return nil, err
 	}
@@ -279,8 +373,7 @@ func (a *AuthUC) UpdatePassword(userId uuid.UUID, passwords models.Passwords) (*
 	}
 
 	// update password
-	user.Password = string(hashedPassword)
-	err = a.repo.UpdateUser(*user)
+	err = a.repo.UpdatePassword(models.PasswordChange{UserID: user.ID, ChangedAt: time.Now()}, string(hashedPassword))
 	if err != nil {
 		return nil, err
 	}
@@ -294,45 +387,46 @@ func (a *AuthUC) UpdatePassword(userId uuid.UUID, passwords models.Passwords) (*
 	return res, nil
 }
 
-// UpdateProfile updates the profile and avatar of a user.
-func (a *AuthUC) UpdateProfile(user models.User, avatar string) error {
+// UpdateProfile sets the name and email of user and the details of profile on the
+// stored user, and replaces their avatar when one is given. A new avatar is checked
+// against the avatar policy before the old one is removed.
+func (a *AuthUC) UpdateProfile(ctx context.Context, user models.User, profile models.ProfileUpdate, avatar string) error {
 	if avatar != "" {
-		at, err := a.repo.FetchAvatarById(user.ID)
-		if err != nil {
+		if err := a.checkAvatar(avatar); err != nil {
 			return err
 		}
 
-		_, err = a.cld.Destroy(at.PublicId)
-		if err != nil {
+		at, err := a.repo.FetchAvatarById(user.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
 			return err
 		}
 
-		err = a.repo.DeleteAvatarById(at.PublicId)
-		if err != nil {
-			return err
-		}
+		if err == nil {
+			_, err = a.cld.Destroy(ctx, at.PublicId)
+			if err != nil {
+				return err
+			}
 
-		res, err := a.cld.UploadToCloud("avatar", avatar)
-		if err != nil {
-			return err
+			err = a.repo.DeleteAvatarById(at.PublicId)
+			if err != nil {
+				return err
+			}
 		}
 
-		at.PublicId = res.PublicID
-		at.Url = res.URL
-		at.UserId = user.ID
-
-		_, err = a.repo.InsertAvatar(&at)
-		if err != nil {
+		if _, err := a.storeAvatar(ctx, user.ID, avatar); err != nil {
 			return err
 		}
 	}
 
-	err := a.repo.UpdateUser(user)
+	u, err := a.repo.FetchUserById(user.ID)
 	if err != nil {
 		return err
 	}
+	u.Name = user.Name
+	u.Email = user.Email
+	profile.Apply(u)
 
-	return nil
+	return a.repo.UpdateUser(*u)
 }
 
 // GetAllUsers returns all users.
@@ -353,16 +447,20 @@ func (a *AuthUC) GetUserDetails(userID uuid.UUID) (*models.User, error) {
 	}
 
 	avatar, err := a.repo.FetchAvatarById(userID)
-	if err != nil {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, err
 	}
+	if err != nil {
+		avatar = defaultAvatar(user)
+	}
 	user.Avatar = avatar
 
 	return user, nil
 }
 
-// UpdateUser updates the details of a user by ID.
-func (a *AuthUC) UpdateUser(userID uuid.UUID, user models.User) (*models.UserResponse, error) {
+// UpdateUser updates the name and email of a user by ID, and the details of profile.
+// The role of the user is kept; it changes with UpdateUserRole.
+func (a *AuthUC) UpdateUser(userID uuid.UUID, user models.User, profile models.ProfileUpdate) (*models.UserResponse, error) {
 	// get user
 	u, err := a.repo.FetchUserById(userID)
 	if err != nil {
@@ -370,7 +468,7 @@ func (a *AuthUC) UpdateUser(userID uuid.UUID, user models.User) (*models.UserRes
 	}
 	u.Name = user.Name
 	u.Email = user.Email
-	u.Role = user.Role
+	profile.Apply(u)
 
 	err = a.repo.UpdateUser(*u)
 	if err != nil {
@@ -385,20 +483,22 @@ func (a *AuthUC) UpdateUser(userID uuid.UUID, user models.User) (*models.UserRes
 }
 
 // DeleteUser deletes a user
-func (a *AuthUC) DeleteUser(userID uuid.UUID) error {
+func (a *AuthUC) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 	avatar, err := a.repo.FetchAvatarById(userID)
-	if err != nil {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return err
 	}
 
-	_, err = a.cld.Destroy(avatar.PublicId)
-	if err != nil {
-		return err
-	}
+	if err == nil {
+		_, err = a.cld.Destroy(ctx, avatar.PublicId)
+		if err != nil {
+			return err
+		}
 
-	err = a.repo.DeleteAvatarById(avatar.PublicId)
-	if err != nil {
-		return err
+		err = a.repo.DeleteAvatarById(avatar.PublicId)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = a.repo.DeleteUserById(userID)
@@ -409,17 +509,278 @@ func (a *AuthUC) DeleteUser(userID uuid.UUID) error {
 	return nil
 }
 
-// DeleteUserToken deletes user token from the database
+// DeleteUserToken deletes user token from the database, ending its session only
 func (a *AuthUC) DeleteUserToken(token string) error {
-	user, err := a.repo.FetchUserByToken(token)
+	return a.repo.DeleteToken(token)
+}
+
+// CreateUser creates a user with a generated temporary password, which is emailed to them.
+// The user is removed again when the email cannot be sent, since nobody would know the password.
+func (a *AuthUC) CreateUser(user models.User) (*models.UserResponse, error) {
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	if !models.ValidRole(user.Role) {
+		return nil, auth.ErrInvalidRole
+	}
+
+	u, err := a.repo.FetchUserByEmail(user.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+
+	if err == nil {
+		return nil, fmt.Errorf("%w: %s", auth.ErrDuplicateEmail, user.Email)
+	}
+
+	password, err := generatePassword()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("error generating password: %v", err)
 	}
 
-	err = a.repo.DeleteTokenById(user.ID)
+	hashPassword, err := a.bcrypt.GenerateFromPassword([]byte(password))
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("error hashing password: %v", err)
 	}
 
-	return nil
+	user.Password = string(hashPassword)
+
+	u, err = a.repo.InsertUser(user)
+	if err != nil {
+		// the email may have been taken since it was checked
+		if errors.Is(err, auth.ErrDuplicateEmail) {
+			return nil, fmt.Errorf("%w: %s", auth.ErrDuplicateEmail, user.Email)
+		}
+		return nil, fmt.Errorf("error saving user: %v", err)
+	}
+
+	data := struct {
+		Name     string
+		Email    string
+		Password string
+	}{
+		Name:     u.Name,
+		Email:    u.Email,
+		Password: password,
+	}
+
+	err = a.mail.SendMail(mailFrom, u.Email, i18n.T(u.Locale, "Your ShopIT account"), "account-created", u.Locale, data)
+	if err != nil {
+		_ = a.repo.DeleteUserById(u.ID)
+		return nil, fmt.Errorf("error sending mail: %v", err)
+	}
+
+	u.Password = ""
+
+	return &models.UserResponse{
+		Success: true,
+		User:    *u,
+	}, nil
+}
+
+// UpdateUserRole changes the role of a user to one of models.Roles.
+func (a *AuthUC) UpdateUserRole(userID uuid.UUID, role string) (*models.UserResponse, error) {
+	if !models.ValidRole(role) {
+		return nil, auth.ErrInvalidRole
+	}
+
+	if err := a.repo.UpdateUserRole(userID, role); err != nil {
+		return nil, err
+	}
+
+	return &models.UserResponse{
+		Success: true,
+	}, nil
+}
+
+// SetCurrency sets the preferred currency of a user. It must be accepted by the shop;
+// an empty currency clears the preference.
+func (a *AuthUC) SetCurrency(userID uuid.UUID, currency string) (*models.UserResponse, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency != "" && !money.Supported(currency) {
+		return nil, fmt.Errorf("%w: %s", money.ErrUnsupportedCurrency, currency)
+	}
+
+	if err := a.repo.UpdateCurrency(userID, currency); err != nil {
+		return nil, err
+	}
+
+	return &models.UserResponse{
+		Success: true,
+	}, nil
+}
+
+// SetLocale sets the locale a user reads emails and invoices in. It must be supported;
+// an empty locale clears the preference.
+func (a *AuthUC) SetLocale(userID uuid.UUID, locale string) (*models.UserResponse, error) {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale != "" && !i18n.Supported(locale) {
+		return nil, fmt.Errorf("%w: %s", i18n.ErrUnsupportedLocale, locale)
+	}
+
+	if err := a.repo.UpdateLocale(userID, locale); err != nil {
+		return nil, err
+	}
+
+	return &models.UserResponse{
+		Success: true,
+	}, nil
+}
+
+// generatePassword returns a random temporary password.
+func generatePassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DeleteExpiredTokens removes expired tokens and magic links, and returns how many
+// were removed.
+func (a *AuthUC) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	n, err := a.repo.DeleteExpiredTokens(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired tokens: %v", err)
+	}
+
+	links, err := a.repo.DeleteExpiredMagicLinks(ctx)
+	if err != nil {
+		return n, fmt.Errorf("error deleting expired magic links: %v", err)
+	}
+
+	return n + links, nil
+}
+
+// ScheduleDeletion schedules the deletion of a user's own account after the grace period,
+// signs them out everywhere and emails them a link that restores the account.
+func (a *AuthUC) ScheduleDeletion(userID uuid.UUID) (*models.Response, error) {
+	user, err := a.repo.FetchUserById(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %w", err)
+	}
+
+	if user.DeleteAfter != nil {
+		return nil, auth.ErrPendingDeletion
+	}
+
+	t, err := a.token.GenerateToken(userID, a.deletionGrace, scopeAccountRestore)
+	if err != nil {
+		return nil, fmt.Errorf("error generating restore token: %v", err)
+	}
+
+	if err := a.repo.ScheduleUserDeletion(userID, t.Expiry, t.Hash); err != nil {
+		return nil, fmt.Errorf("error scheduling deletion: %w", err)
+	}
+
+	data := struct {
+		Name        string
+		Link        string
+		DeleteAfter string
+	}{
+		Name:        user.Name,
+		Link:        fmt.Sprintf("%s/account/restore/%s", a.siteURL(), t.PlainText),
+		DeleteAfter: i18n.FormatLongDate(user.Locale, t.Expiry),
+	}
+
+	// without the email the user could not undo the deletion, so don't schedule it
+	subject := i18n.T(user.Locale, "ShopIT Account Deletion")
+	if err := a.mail.SendMail(mailFrom, user.Email, subject, "account-deletion", user.Locale, data); err != nil {
+		if cancelErr := a.repo.CancelUserDeletion(userID); cancelErr != nil {
+			return nil, fmt.Errorf("error cancelling deletion after failing to send mail: %v", cancelErr)
+		}
+		return nil, fmt.Errorf("error sending mail: %v", err)
+	}
+
+	if err := a.repo.DeleteTokenById(userID); err != nil {
+		return nil, fmt.Errorf("error revoking tokens: %v", err)
+	}
+
+	return &models.Response{
+		Success: true,
+		Message: fmt.Sprintf("Your account will be deleted on %s. Check %s to undo it.", data.DeleteAfter, user.Email),
+	}, nil
+}
+
+// RestoreAccount cancels the scheduled deletion of the account holding the restore token.
+func (a *AuthUC) RestoreAccount(restoreToken string) (*models.Response, error) {
+	if restoreToken == "" {
+		return nil, auth.ErrInvalidRestoreLink
+	}
+
+	if err := a.repo.RestoreUser(restoreToken); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, auth.ErrInvalidRestoreLink
+		}
+		return nil, fmt.Errorf("error restoring account: %v", err)
+	}
+
+	return &models.Response{
+		Success: true,
+		Message: "Your account has been restored, you can log in again.",
+	}, nil
+}
+
+// PurgeDeletedAccounts deletes the accounts whose deletion grace period is over and returns
+// how many were deleted. An account that fails is retried on the next run, as are those
+// left once ctx is done.
+func (a *AuthUC) PurgeDeletedAccounts(ctx context.Context) (int64, error) {
+	ids, err := a.repo.FetchUsersDueForDeletion(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("error fetching accounts due for deletion: %v", err)
+	}
+
+	var (
+		n    int64
+		errs []error
+	)
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := a.DeleteUser(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", id, err))
+			continue
+		}
+		n++
+	}
+
+	return n, errors.Join(errs...)
+}
+
+// siteURL returns the public scheme and host of the API, for links in emails. It is
+// the configured base url of magic links, never the host of the request, which the
+// client chooses.
+func (a *AuthUC) siteURL() string {
+	return a.magicLinks.BaseURL
+}
+
+// humanDuration spells out d in hours and minutes in the locale code, e.g. "1 hour
+// 30 minutes".
+func humanDuration(d time.Duration, code string) string {
+	unit := func(n int, name string) string {
+		if n == 1 {
+			return i18n.T(code, "1 "+name)
+		}
+		return i18n.T(code, "%d "+name+"s", n)
+	}
+
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h == 0:
+		return unit(m, "minute")
+	case m == 0:
+		return unit(h, "hour")
+	default:
+		return unit(h, "hour") + " " + unit(m, "minute")
+	}
+}
+
+// discardAsset removes an uploaded asset whose database record could not be saved.
+// It outlives a cancelled ctx, and failures are ignored: the orphan reconciliation job
+// removes whatever is left behind.
+func (a *AuthUC) discardAsset(ctx context.Context, publicID string) {
+	_, _ = a.cld.Destroy(context.WithoutCancel(ctx), publicID)
 }
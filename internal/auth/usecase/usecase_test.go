@@ -6,19 +6,35 @@
 package usecase_test
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"image"
+	"image/png"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/cloudinary/cloudinary-go/api/uploader"
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
 	mockRepo "github.com/jofosuware/go/shopit/internal/auth/mocks"
 	"github.com/jofosuware/go/shopit/internal/auth/usecase"
 	"github.com/jofosuware/go/shopit/internal/models"
 	mockBcrypt "github.com/jofosuware/go/shopit/pkg/bcrypt/mocks"
 	mockCloudinary "github.com/jofosuware/go/shopit/pkg/cloudinary/mocks"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
 	mockMail "github.com/jofosuware/go/shopit/pkg/mailer/mocks"
+	"github.com/jofosuware/go/shopit/pkg/moderation"
+	mockModeration "github.com/jofosuware/go/shopit/pkg/moderation/mocks"
+	"github.com/jofosuware/go/shopit/pkg/money"
 	"github.com/jofosuware/go/shopit/pkg/token"
 	mockToken "github.com/jofosuware/go/shopit/pkg/token/mocks"
 	"github.com/stretchr/testify/assert"
@@ -26,6 +42,18 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// avatarURI is a valid square PNG avatar.
+var avatarURI = pngAvatar(4, 4)
+
+// pngAvatar returns a w by h PNG image as a base64 data URI.
+func pngAvatar(w, h int) string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		panic(err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
 // newTestAuthUC returns a new AuthUC instance and its mocked dependencies for testing.
 func newTestAuthUC(t *testing.T) (*usecase.AuthUC, *mockCloudinary.CloudUploader, *mockRepo.Repo, *mockToken.Tokener, *mockBcrypt.Encryptor, *mockMail.Mailer) {
 	cld := mockCloudinary.NewCloudUploader(t)
@@ -33,7 +61,7 @@ func newTestAuthUC(t *testing.T) (*usecase.AuthUC, *mockCloudinary.CloudUploader
 	mToken := mockToken.NewTokener(t)
 	mBcrypt := mockBcrypt.NewEncryptor(t)
 	mail := mockMail.NewMailer(t)
-	return usecase.NewAuthUC(cld, repo, mToken, mBcrypt, mail), cld, repo, mToken, mBcrypt, mail
+	return usecase.NewAuthUC(cld, repo, mToken, mBcrypt, mail, 0, usecase.PasswordReset{}, usecase.MagicLinks{}, usecase.AvatarPolicy{}, nil), cld, repo, mToken, mBcrypt, mail
 }
 
 // TestAuthUC_Register tests the Register use case for all success and error scenarios.
@@ -42,14 +70,14 @@ func TestAuthUC_Register(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		u := models.User{ID: uuid.New(), Name: "test", Email: "user@gmail.com", Password: "userPassword", Role: "user"}
-		cld.On("UploadToCloud", "avatar", "test").Return(&uploader.UploadResult{PublicID: "pid", URL: "url"}, nil)
+		cld.On("UploadToCloud", mock.Anything, "avatar", avatarURI).Return(&uploader.UploadResult{PublicID: "pid", URL: "url"}, nil)
 		repo.On("FetchUserByEmail", u.Email).Return(&models.User{}, errors.New("sql: no rows in result set")).Once()
 		mBcrypt.On("GenerateFromPassword", []byte(u.Password)).Return([]byte(u.Password), nil).Once()
 		repo.On("InsertUser", u).Return(&u, nil).Once()
 		mToken.On("GenerateToken", u.ID, 24*time.Hour, token.ScopeAuthentication).Return(&models.Token{PlainText: "tok"}, nil).Once()
 		repo.On("InsertToken", &models.Token{PlainText: "tok"}, u.ID).Return(nil).Once()
 		repo.On("InsertAvatar", &models.Avatar{PublicId: "pid", Url: "url", UserId: u.ID}).Return(models.Avatar{PublicId: "pid", Url: "url", UserId: u.ID}, nil).Once()
-		res, err := a.Register(u, "test")
+		res, err := a.Register(context.Background(), u, avatarURI)
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 	})
@@ -57,8 +85,26 @@ func TestAuthUC_Register(t *testing.T) {
 	t.Run("User already exists", func(t *testing.T) {
 		u := models.User{ID: uuid.New(), Name: "test", Email: "user@gmail.com", Password: "userPassword", Role: "user"}
 		repo.On("FetchUserByEmail", u.Email).Return(&u, nil).Once()
-		res, err := a.Register(u, "test")
-		assert.Error(t, err)
+		res, err := a.Register(context.Background(), u, avatarURI)
+		assert.ErrorIs(t, err, auth.ErrDuplicateEmail)
+		assert.Nil(t, res)
+	})
+
+	t.Run("Email taken in another case", func(t *testing.T) {
+		u := models.User{ID: uuid.New(), Name: "test", Email: "User@Gmail.com", Password: "userPassword", Role: "user"}
+		repo.On("FetchUserByEmail", u.Email).Return(&models.User{ID: uuid.New(), Email: "user@gmail.com"}, nil).Once()
+		res, err := a.Register(context.Background(), u, avatarURI)
+		assert.ErrorIs(t, err, auth.ErrDuplicateEmail)
+		assert.Nil(t, res)
+	})
+
+	t.Run("Email taken since it was checked", func(t *testing.T) {
+		u := models.User{Name: "test", Email: "User@gmail.com", Password: "userPassword", Role: "user"}
+		repo.On("FetchUserByEmail", u.Email).Return(nil, sql.ErrNoRows).Once()
+		mBcrypt.On("GenerateFromPassword", []byte(u.Password)).Return([]byte("hash"), nil).Once()
+		repo.On("InsertUser", mock.Anything).Return(nil, auth.ErrDuplicateEmail).Once()
+		res, err := a.Register(context.Background(), u, "")
+		assert.ErrorIs(t, err, auth.ErrDuplicateEmail)
 		assert.Nil(t, res)
 	})
 
@@ -66,10 +112,187 @@ func TestAuthUC_Register(t *testing.T) {
 		u := models.User{ID: uuid.New(), Name: "test", Email: "user@gmail.com", Password: "userPassword", Role: "user"}
 		repo.On("FetchUserByEmail", u.Email).Return(&models.User{}, errors.New("sql: no rows in result set")).Once()
 		mBcrypt.On("GenerateFromPassword", []byte(u.Password)).Return(nil, errors.New("hash error")).Once()
-		res, err := a.Register(u, "test")
+		res, err := a.Register(context.Background(), u, avatarURI)
 		assert.Error(t, err)
 		assert.Nil(t, res)
 	})
+
+	t.Run("Error saving avatar destroys the upload and queues the avatar", func(t *testing.T) {
+		u := models.User{ID: uuid.New(), Name: "test", Email: "user@gmail.com", Password: "userPassword", Role: "user"}
+		repo.On("FetchUserByEmail", u.Email).Return(&models.User{}, errors.New("sql: no rows in result set")).Once()
+		mBcrypt.On("GenerateFromPassword", []byte(u.Password)).Return([]byte(u.Password), nil).Once()
+		repo.On("InsertUser", u).Return(&u, nil).Once()
+		mToken.On("GenerateToken", u.ID, 24*time.Hour, token.ScopeAuthentication).Return(&models.Token{PlainText: "tok"}, nil).Once()
+		repo.On("InsertToken", &models.Token{PlainText: "tok"}, u.ID).Return(nil).Once()
+		repo.On("InsertAvatar", &models.Avatar{PublicId: "pid", Url: "url", UserId: u.ID}).Return(models.Avatar{}, errors.New("db error")).Once()
+		cld.On("Destroy", mock.Anything, "pid").Return(&uploader.DestroyResult{Result: "ok"}, nil).Once()
+		repo.On("QueueAvatar", models.PendingAvatar{UserID: u.ID, Avatar: avatarURI}).Return(nil).Once()
+		res, err := a.Register(context.Background(), u, avatarURI)
+		require.NoError(t, err)
+		assert.Empty(t, res.User.Avatar.PublicId)
+		assert.True(t, strings.HasPrefix(res.User.Avatar.Url, "data:image/svg+xml;base64,"))
+	})
+}
+
+// TestAuthUC_RegisterAvatarFallback tests that registering does not depend on the avatar being stored.
+func TestAuthUC_RegisterAvatarFallback(t *testing.T) {
+	u := models.User{ID: uuid.New(), Name: "Ama Mensah", Email: "user@gmail.com", Password: "userPassword", Role: "user"}
+
+	register := func(t *testing.T, avatar string, setup func(cld *mockCloudinary.CloudUploader, repo *mockRepo.Repo)) *models.UserResponse {
+		a, cld, repo, mToken, mBcrypt, _ := newTestAuthUC(t)
+		repo.On("FetchUserByEmail", u.Email).Return(&models.User{}, sql.ErrNoRows).Once()
+		mBcrypt.On("GenerateFromPassword", []byte(u.Password)).Return([]byte("hash"), nil).Once()
+		repo.On("InsertUser", mock.Anything).Return(&u, nil).Once()
+		mToken.On("GenerateToken", u.ID, 24*time.Hour, token.ScopeAuthentication).Return(&models.Token{PlainText: "tok"}, nil).Once()
+		repo.On("InsertToken", &models.Token{PlainText: "tok"}, u.ID).Return(nil).Once()
+		setup(cld, repo)
+
+		res, err := a.Register(context.Background(), u, avatar)
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("Without an avatar the user gets their initials", func(t *testing.T) {
+		res := register(t, "", func(*mockCloudinary.CloudUploader, *mockRepo.Repo) {})
+
+		require.True(t, strings.HasPrefix(res.User.Avatar.Url, "data:image/svg+xml;base64,"))
+		svg, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(res.User.Avatar.Url, "data:image/svg+xml;base64,"))
+		require.NoError(t, err)
+		assert.Contains(t, string(svg), ">AM</text>")
+	})
+
+	t.Run("Upload failure queues the avatar", func(t *testing.T) {
+		res := register(t, avatarURI, func(cld *mockCloudinary.CloudUploader, repo *mockRepo.Repo) {
+			cld.On("UploadToCloud", mock.Anything, "avatar", avatarURI).Return(nil, errors.New("timeout")).Once()
+			repo.On("QueueAvatar", models.PendingAvatar{UserID: u.ID, Avatar: avatarURI}).Return(nil).Once()
+		})
+
+		assert.Empty(t, res.User.Avatar.PublicId)
+		assert.NotEmpty(t, res.User.Avatar.Url)
+	})
+}
+
+// TestAuthUC_HandleAvatarPending tests that avatars queued at sign up are stored once.
+func TestAuthUC_HandleAvatarPending(t *testing.T) {
+	a, cld, repo, _, _, _ := newTestAuthUC(t)
+	p := models.PendingAvatar{UserID: uuid.New(), Avatar: avatarURI}
+	e := events.Event{Name: events.AvatarPending, Data: p}
+
+	t.Run("Avatar is stored", func(t *testing.T) {
+		repo.On("FetchAvatarById", p.UserID).Return(models.Avatar{}, sql.ErrNoRows).Once()
+		repo.On("FetchUserById", p.UserID).Return(&models.User{ID: p.UserID}, nil).Once()
+		cld.On("UploadToCloud", mock.Anything, "avatar", avatarURI).Return(&uploader.UploadResult{PublicID: "pid", URL: "url"}, nil).Once()
+		repo.On("InsertAvatar", &models.Avatar{PublicId: "pid", Url: "url", UserId: p.UserID}).
+			Return(models.Avatar{PublicId: "pid", Url: "url", UserId: p.UserID}, nil).Once()
+
+		require.NoError(t, a.HandleAvatarPending(e))
+	})
+
+	t.Run("Upload failure is retried", func(t *testing.T) {
+		repo.On("FetchAvatarById", p.UserID).Return(models.Avatar{}, sql.ErrNoRows).Once()
+		repo.On("FetchUserById", p.UserID).Return(&models.User{ID: p.UserID}, nil).Once()
+		cld.On("UploadToCloud", mock.Anything, "avatar", avatarURI).Return(nil, errors.New("timeout")).Once()
+
+		assert.Error(t, a.HandleAvatarPending(e))
+	})
+
+	t.Run("Avatar replaced since is dropped", func(t *testing.T) {
+		repo.On("FetchAvatarById", p.UserID).Return(models.Avatar{PublicId: "other"}, nil).Once()
+
+		require.NoError(t, a.HandleAvatarPending(e))
+	})
+
+	t.Run("User deleted since", func(t *testing.T) {
+		repo.On("FetchAvatarById", p.UserID).Return(models.Avatar{}, sql.ErrNoRows).Once()
+		repo.On("FetchUserById", p.UserID).Return(nil, sql.ErrNoRows).Once()
+
+		require.NoError(t, a.HandleAvatarPending(e))
+	})
+}
+
+// TestAuthUC_RegisterPublishesSignUp tests that Register publishes the new user without its password.
+func TestAuthUC_RegisterPublishesSignUp(t *testing.T) {
+	cld, repo, mToken, mBcrypt := mockCloudinary.NewCloudUploader(t), mockRepo.NewRepo(t), mockToken.NewTokener(t), mockBcrypt.NewEncryptor(t)
+	bus := events.New(mockLogger.NewLogger(t))
+	var published []events.Event
+	bus.Subscribe(func(e events.Event) error {
+		published = append(published, e)
+		return nil
+	}, events.UserRegistered)
+	a := usecase.NewAuthUC(cld, repo, mToken, mBcrypt, mockMail.NewMailer(t), 0, usecase.PasswordReset{}, usecase.MagicLinks{}, usecase.AvatarPolicy{}, bus)
+
+	u := models.User{ID: uuid.New(), Name: "test", Email: "user@gmail.com", Password: "userPassword", Role: "user"}
+	cld.On("UploadToCloud", mock.Anything, "avatar", avatarURI).Return(&uploader.UploadResult{PublicID: "pid", URL: "url"}, nil).Once()
+	repo.On("FetchUserByEmail", u.Email).Return(&models.User{}, errors.New("sql: no rows in result set")).Once()
+	mBcrypt.On("GenerateFromPassword", []byte(u.Password)).Return([]byte("hash"), nil).Once()
+	repo.On("InsertUser", mock.Anything).Return(&u, nil).Once()
+	mToken.On("GenerateToken", u.ID, 24*time.Hour, token.ScopeAuthentication).Return(&models.Token{PlainText: "tok"}, nil).Once()
+	repo.On("InsertToken", &models.Token{PlainText: "tok"}, u.ID).Return(nil).Once()
+	repo.On("InsertAvatar", mock.Anything).Return(models.Avatar{PublicId: "pid", Url: "url", UserId: u.ID}, nil).Once()
+
+	_, err := a.Register(context.Background(), u, avatarURI)
+	require.NoError(t, err)
+	bus.Close()
+
+	require.Len(t, published, 1)
+	user := published[0].Data.(models.User)
+	assert.Equal(t, u.ID, user.ID)
+	assert.Empty(t, user.Password)
+	assert.Equal(t, "url", user.Avatar.Url)
+}
+
+// TestAuthUC_RegisterAvatarPolicy tests that avatars are checked before anything is saved or uploaded.
+func TestAuthUC_RegisterAvatarPolicy(t *testing.T) {
+	u := models.User{ID: uuid.New(), Name: "test", Email: "user@gmail.com", Password: "userPassword", Role: "user"}
+
+	newUC := func(t *testing.T, policy usecase.AvatarPolicy) *usecase.AuthUC {
+		repo := mockRepo.NewRepo(t)
+		repo.On("FetchUserByEmail", u.Email).Return(&models.User{}, sql.ErrNoRows).Once()
+		return usecase.NewAuthUC(mockCloudinary.NewCloudUploader(t), repo, mockToken.NewTokener(t),
+			mockBcrypt.NewEncryptor(t), mockMail.NewMailer(t), 0, usecase.PasswordReset{}, usecase.MagicLinks{}, policy, nil)
+	}
+
+	tests := []struct {
+		name   string
+		avatar string
+		policy usecase.AvatarPolicy
+		reason string
+	}{
+		{"Not a data uri", "https://example.com/me.png", usecase.AvatarPolicy{}, "avatar must be a base64 encoded data uri"},
+		{"Not base64", "data:image/png;base64,%%%", usecase.AvatarPolicy{}, "avatar must be a base64 encoded data uri"},
+		{"Not an image", "data:text/plain;base64," + base64.StdEncoding.EncodeToString([]byte("hello")), usecase.AvatarPolicy{}, "avatar must be a JPEG, PNG or GIF image"},
+		{"Too large", avatarURI, usecase.AvatarPolicy{MaxSize: 10}, "avatar must not exceed 10 bytes"},
+		{"Too elongated", pngAvatar(30, 10), usecase.AvatarPolicy{}, "avatar aspect ratio must not exceed 2:1"},
+		{"Type not allowed", avatarURI, usecase.AvatarPolicy{AllowedTypes: []string{"image/jpeg", "image/gif"}}, "avatar must be a JPEG or GIF image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := newUC(t, tt.policy).Register(context.Background(), u, tt.avatar)
+			var avatarErr *auth.AvatarError
+			require.ErrorAs(t, err, &avatarErr)
+			assert.Equal(t, tt.reason, avatarErr.Reason)
+			assert.Nil(t, res)
+		})
+	}
+
+	t.Run("Rejected by moderation", func(t *testing.T) {
+		mod := mockModeration.NewModerator(t)
+		mod.On("Review", mock.Anything, mock.Anything, "image/png").Return(&moderation.Verdict{Reason: "nudity"}, nil).Once()
+		res, err := newUC(t, usecase.AvatarPolicy{Moderator: mod}).Register(context.Background(), u, avatarURI)
+		var avatarErr *auth.AvatarError
+		require.ErrorAs(t, err, &avatarErr)
+		assert.Equal(t, "avatar was rejected by moderation: nudity", avatarErr.Reason)
+		assert.Nil(t, res)
+	})
+
+	t.Run("Moderation unavailable", func(t *testing.T) {
+		mod := mockModeration.NewModerator(t)
+		mod.On("Review", mock.Anything, mock.Anything, "image/png").Return(nil, moderation.ErrUnavailable).Once()
+		res, err := newUC(t, usecase.AvatarPolicy{Moderator: mod}).Register(context.Background(), u, avatarURI)
+		assert.ErrorIs(t, err, moderation.ErrUnavailable)
+		assert.Nil(t, res)
+	})
 }
 
 // TestAuthUC_Login tests the Login use case for all success and error scenarios.
@@ -80,17 +303,20 @@ func TestAuthUC_Login(t *testing.T) {
 		u := models.User{ID: uuid.New(), Email: "user@gmail.com", Password: "userPassword"}
 		repo.On("FetchUserByEmail", u.Email).Return(&u, nil).Once()
 		mBcrypt.On("CompareHashAndPassword", []byte(u.Password), []byte(u.Password)).Return(nil).Once()
-		mToken.On("GenerateToken", u.ID, 24*time.Hour, "authentication").Return(&models.Token{}, nil).Once()
-		repo.On("InsertToken", &models.Token{}, u.ID).Return(nil).Once()
+		mToken.On("GenerateToken", u.ID, 24*time.Hour, "authentication").Return(&models.Token{UserID: u.ID}, nil).Once()
+		repo.On("InsertSession", &models.Token{UserID: u.ID, UserAgent: "Firefox", IP: "203.0.113.7"}).Return(nil).Once()
 		repo.On("FetchAvatarById", u.ID).Return(models.Avatar{}, nil).Once()
-		res, err := a.Login(u.Email, u.Password)
+		r := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+		r.Header.Set("User-Agent", "Firefox")
+		r.RemoteAddr = "203.0.113.7:4242"
+		res, err := a.Login(u.Email, u.Password, r)
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 	})
 
 	t.Run("Failed Login - User not found", func(t *testing.T) {
 		repo.On("FetchUserByEmail", "").Return(nil, errors.New("error"))
-		ur, err := a.Login("", "")
+		ur, err := a.Login("", "", httptest.NewRequest(http.MethodPost, "/auth/login", nil))
 		assert.Error(t, err)
 		assert.Nil(t, ur)
 	})
@@ -99,10 +325,20 @@ func TestAuthUC_Login(t *testing.T) {
 		u := models.User{ID: uuid.New(), Email: "user@gmail.com", Password: "userPassword"}
 		repo.On("FetchUserByEmail", u.Email).Return(&u, nil).Once()
 		mBcrypt.On("CompareHashAndPassword", []byte(u.Password), []byte(u.Password)).Return(errors.New("wrong password")).Once()
-		ur, err := a.Login(u.Email, u.Password)
+		ur, err := a.Login(u.Email, u.Password, httptest.NewRequest(http.MethodPost, "/auth/login", nil))
 		assert.Error(t, err)
 		assert.Nil(t, ur)
 	})
+
+	t.Run("Failed Login - Pending deletion", func(t *testing.T) {
+		deleteAfter := time.Now().Add(time.Hour)
+		u := models.User{ID: uuid.New(), Email: "user@gmail.com", Password: "userPassword", DeleteAfter: &deleteAfter}
+		repo.On("FetchUserByEmail", u.Email).Return(&u, nil).Once()
+		mBcrypt.On("CompareHashAndPassword", []byte(u.Password), []byte(u.Password)).Return(nil).Once()
+		ur, err := a.Login(u.Email, u.Password, httptest.NewRequest(http.MethodPost, "/auth/login", nil))
+		assert.ErrorIs(t, err, auth.ErrPendingDeletion)
+		assert.Nil(t, ur)
+	})
 }
 
 // TestAuthUC_SendPasswordResetEmail tests SendPasswordResetEmail for all scenarios.
@@ -111,29 +347,79 @@ func TestAuthUC_SendPasswordResetEmail(t *testing.T) {
 	u := models.User{ID: uuid.New(), Email: "user@gmail.com", Password: "userPassword"}
 
 	t.Run("Success", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodPost, "/forget-password", nil)
-		require.NoError(t, err)
 		repo.On("FetchUserByEmail", u.Email).Return(&u, nil).Once()
 		tok := &models.Token{PlainText: "tok"}
 		mToken.On("GenerateToken", u.ID, 60*time.Minute, token.ScopeAuthentication).Return(tok, nil).Once()
-		mail.On("SendMail", mock.Anything, u.Email, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		mail.On("SendMail", mock.Anything, u.Email, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
 		repo.On("InsertToken", tok, u.ID).Return(nil).Once()
-		res, err := a.SendPasswordResetEmail(u.Email, req)
+		res, err := a.SendPasswordResetEmail(u.Email)
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 	})
 
 	t.Run("Failed to send email", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodPost, "/forget-password", nil)
-		require.NoError(t, err)
 		repo.On("FetchUserByEmail", u.Email).Return(&u, nil).Once()
 		tok := &models.Token{PlainText: "tok"}
 		mToken.On("GenerateToken", u.ID, 60*time.Minute, token.ScopeAuthentication).Return(tok, nil).Once()
-		mail.On("SendMail", mock.Anything, u.Email, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("mail error")).Once()
-		res, err := a.SendPasswordResetEmail(u.Email, req)
+		mail.On("SendMail", mock.Anything, u.Email, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("mail error")).Once()
+		res, err := a.SendPasswordResetEmail(u.Email)
 		assert.Error(t, err)
 		assert.Nil(t, res)
 	})
+
+	t.Run("Configured lifetime and link", func(t *testing.T) {
+		repo := mockRepo.NewRepo(t)
+		mToken := mockToken.NewTokener(t)
+		mail := mockMail.NewMailer(t)
+		a := usecase.NewAuthUC(mockCloudinary.NewCloudUploader(t), repo, mToken, mockBcrypt.NewEncryptor(t), mail, 0,
+			usecase.PasswordReset{Expiry: 90 * time.Minute, URL: "https://shop.example.com/reset?token={token}"}, usecase.MagicLinks{}, usecase.AvatarPolicy{}, nil)
+
+		repo.On("FetchUserByEmail", u.Email).Return(&u, nil).Once()
+		tok := &models.Token{PlainText: "tok"}
+		mToken.On("GenerateToken", u.ID, 90*time.Minute, token.ScopeAuthentication).Return(tok, nil).Once()
+		mail.On("SendMail", mock.Anything, u.Email, mock.Anything, "password-reset", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			data := fmt.Sprintf("%+v", args.Get(5))
+			assert.Contains(t, data, "Link:https://shop.example.com/reset?token=tok")
+			assert.Contains(t, data, "Expires:1 hour 30 minutes")
+		}).Return(nil).Once()
+		repo.On("InsertToken", tok, u.ID).Return(nil).Once()
+
+		_, err := a.SendPasswordResetEmail(u.Email)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Relative link on the configured base url", func(t *testing.T) {
+		repo := mockRepo.NewRepo(t)
+		mToken := mockToken.NewTokener(t)
+		mail := mockMail.NewMailer(t)
+		a := usecase.NewAuthUC(mockCloudinary.NewCloudUploader(t), repo, mToken, mockBcrypt.NewEncryptor(t), mail, 0,
+			usecase.PasswordReset{}, usecase.MagicLinks{BaseURL: "https://api.shopit.example/"}, usecase.AvatarPolicy{}, nil)
+
+		repo.On("FetchUserByEmail", u.Email).Return(&u, nil).Once()
+		tok := &models.Token{PlainText: "tok"}
+		mToken.On("GenerateToken", u.ID, 60*time.Minute, token.ScopeAuthentication).Return(tok, nil).Once()
+		mail.On("SendMail", mock.Anything, u.Email, mock.Anything, "password-reset", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			assert.Contains(t, fmt.Sprintf("%+v", args.Get(5)), "Link:https://api.shopit.example/password/reset/tok")
+		}).Return(nil).Once()
+		repo.On("InsertToken", tok, u.ID).Return(nil).Once()
+
+		_, err := a.SendPasswordResetEmail(u.Email)
+		assert.NoError(t, err)
+	})
+
+	t.Run("In the locale of the user", func(t *testing.T) {
+		fr := models.User{ID: uuid.New(), Email: "marie@gmail.com", Locale: i18n.French}
+		repo.On("FetchUserByEmail", fr.Email).Return(&fr, nil).Once()
+		tok := &models.Token{PlainText: "tok"}
+		mToken.On("GenerateToken", fr.ID, 60*time.Minute, token.ScopeAuthentication).Return(tok, nil).Once()
+		mail.On("SendMail", mock.Anything, fr.Email, "Réinitialisation de votre mot de passe ShopIT", "password-reset", i18n.French, mock.Anything).Run(func(args mock.Arguments) {
+			assert.Contains(t, fmt.Sprintf("%+v", args.Get(5)), "Expires:1 heure")
+		}).Return(nil).Once()
+		repo.On("InsertToken", tok, fr.ID).Return(nil).Once()
+
+		_, err := a.SendPasswordResetEmail(fr.Email)
+		assert.NoError(t, err)
+	})
 }
 
 // TestAuthUC_ResetPassword tests the ResetPassword use case for all success and error scenarios.
@@ -151,7 +437,9 @@ func TestAuthUC_ResetPassword(t *testing.T) {
 		mBcrypt.On("GenerateFromPassword", []byte(u.Password)).Return([]byte("verySecret"), nil).Once()
 		mToken.On("GenerateToken", u.ID, 24*time.Hour, token.ScopeAuthentication).Return(&models.Token{}, nil).Once()
 		repo.On("InsertToken", &models.Token{}, u.ID).Return(nil).Once()
-		repo.On("UpdateUser", u).Return(nil).Once()
+		repo.On("UpdatePassword", mock.MatchedBy(func(c models.PasswordChange) bool {
+			return c.UserID == u.ID && !c.ChangedAt.IsZero()
+		}), "verySecret").Return(nil).Once()
 		res, err := a.ResetPassword("token", u.Password)
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
@@ -169,7 +457,7 @@ func TestAuthUC_ResetPassword(t *testing.T) {
 		mBcrypt.On("GenerateFromPassword", []byte(u.Password)).Return([]byte("verySecret"), nil).Once()
 		mToken.On("GenerateToken", u.ID, 24*time.Hour, token.ScopeAuthentication).Return(&models.Token{}, nil).Once()
 		repo.On("InsertToken", &models.Token{}, u.ID).Return(nil).Once()
-		repo.On("UpdateUser", u).Return(errors.New("update error")).Once()
+		repo.On("UpdatePassword", mock.Anything, "verySecret").Return(errors.New("update error")).Once()
 		res, err := a.ResetPassword("token", u.Password)
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -192,7 +480,9 @@ func TestUpdatedPassword(t *testing.T) {
 		repo.On("FetchUserById", u.ID).Return(&u, nil)
 		mBcrypt.On("CompareHashAndPassword", []byte(u.Password), []byte(passwords.OldPassword)).Return(nil)
 		mBcrypt.On("GenerateFromPassword", []byte(passwords.Password)).Return([]byte(passwords.Password), nil)
-		repo.On("UpdateUser", models.User{ID: u.ID, Password: "newPassword"}).Return(nil)
+		repo.On("UpdatePassword", mock.MatchedBy(func(c models.PasswordChange) bool {
+			return c.UserID == u.ID
+		}), "newPassword").Return(nil)
 		mToken.On("GenerateToken", u.ID, 24*time.Hour, token.ScopeAuthentication).Return(&models.Token{}, nil)
 		repo.On("InsertToken", &models.Token{}, u.ID).Return(nil)
 		res, err := a.UpdatePassword(u.ID, passwords)
@@ -242,7 +532,7 @@ func TestUpdatedPassword(t *testing.T) {
 		repo.On("FetchUserById", u.ID).Return(&u, nil)
 		mBcrypt.On("CompareHashAndPassword", []byte(u.Password), []byte(passwords.OldPassword)).Return(nil)
 		mBcrypt.On("GenerateFromPassword", []byte(passwords.Password)).Return([]byte(passwords.Password), nil)
-		repo.On("UpdateUser", models.User{ID: u.ID, Password: "newPassword"}).Return(errors.New("update error"))
+		repo.On("UpdatePassword", mock.Anything, "newPassword").Return(errors.New("update error"))
 		mToken.On("GenerateToken", u.ID, 24*time.Hour, token.ScopeAuthentication).Return(&models.Token{}, nil)
 		repo.On("InsertToken", &models.Token{}, u.ID).Return(nil)
 		res, err := a.UpdatePassword(u.ID, passwords)
@@ -273,25 +563,46 @@ func TestUpdateProfile(t *testing.T) {
 			URL:      "url",
 		}
 		repo.On("FetchAvatarById", u.ID).Return(avatar, nil).Once()
-		cld.On("Destroy", avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
+		cld.On("Destroy", mock.Anything, avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
 		repo.On("DeleteAvatarById", avatar.PublicId).Return(nil).Once()
-		cld.On("UploadToCloud", "avatar", "user.jpg").Return(&res, nil).Once()
+		cld.On("UploadToCloud", mock.Anything, "avatar", avatarURI).Return(&res, nil).Once()
 		repo.On("InsertAvatar", mock.AnythingOfType("*models.Avatar")).Return(avatar, nil).Once()
-		repo.On("UpdateUser", mock.Anything).Return(nil)
-		err := a.UpdateProfile(u, "user.jpg")
+		repo.On("FetchUserById", u.ID).Return(&u, nil).Once()
+		repo.On("UpdateUser", mock.Anything).Return(nil).Once()
+		err := a.UpdateProfile(context.Background(), u, models.ProfileUpdate{}, avatarURI)
 		assert.NoError(t, err)
 	})
 
+	t.Run("Success - Profile details are set on the stored user", func(t *testing.T) {
+		phone, newsletter := "+233201234567", true
+		dob := time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)
+		stored := models.User{ID: u.ID, Email: "old@gmail.com", Password: "hash", Name: "John", Phone: "+14155552671"}
+
+		repo.On("FetchUserById", u.ID).Return(&stored, nil).Once()
+		repo.On("UpdateUser", models.User{ID: u.ID, Email: u.Email, Password: "hash", Name: u.Name, Phone: phone,
+			DateOfBirth: &dob, Newsletter: true}).Return(nil).Once()
+
+		session := models.User{ID: u.ID, Email: u.Email, Name: u.Name}
+		err := a.UpdateProfile(context.Background(), session, models.ProfileUpdate{Phone: &phone, DateOfBirth: &dob, Newsletter: &newsletter}, "")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Failed Update - Avatar rejected before the old one is removed", func(t *testing.T) {
+		err := a.UpdateProfile(context.Background(), u, models.ProfileUpdate{}, pngAvatar(1, 5))
+		var avatarErr *auth.AvatarError
+		assert.ErrorAs(t, err, &avatarErr)
+	})
+
 	t.Run("Failed Update - User not found", func(t *testing.T) {
 		repo.On("FetchAvatarById", u.ID).Return(models.Avatar{}, errors.New("user not found")).Once()
-		err := a.UpdateProfile(u, "user.jpg")
+		err := a.UpdateProfile(context.Background(), u, models.ProfileUpdate{}, avatarURI)
 		assert.Error(t, err)
 	})
 
 	t.Run("Failed Update - Error deleting old avatar", func(t *testing.T) {
 		repo.On("FetchAvatarById", u.ID).Return(avatar, nil).Once()
-		cld.On("Destroy", avatar.PublicId).Return(&uploader.DestroyResult{}, errors.New("cloudinary error")).Once()
-		err := a.UpdateProfile(u, "user.jpg")
+		cld.On("Destroy", mock.Anything, avatar.PublicId).Return(&uploader.DestroyResult{}, errors.New("cloudinary error")).Once()
+		err := a.UpdateProfile(context.Background(), u, models.ProfileUpdate{}, avatarURI)
 		assert.Error(t, err)
 	})
 
@@ -312,10 +623,10 @@ func TestUpdateProfile(t *testing.T) {
 			URL:      "url",
 		}
 		repo.On("FetchAvatarById", u.ID).Return(avatar, nil).Once()
-		cld.On("Destroy", avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
+		cld.On("Destroy", mock.Anything, avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
 		repo.On("DeleteAvatarById", avatar.PublicId).Return(nil).Once()
-		cld.On("UploadToCloud", "avatar", "user.jpg").Return(&res, errors.New("upload error")).Once()
-		err := a.UpdateProfile(u, "user.jpg")
+		cld.On("UploadToCloud", mock.Anything, "avatar", avatarURI).Return(&res, errors.New("upload error")).Once()
+		err := a.UpdateProfile(context.Background(), u, models.ProfileUpdate{}, avatarURI)
 		assert.Error(t, err)
 	})
 
@@ -336,11 +647,12 @@ func TestUpdateProfile(t *testing.T) {
 			URL:      "url",
 		}
 		repo.On("FetchAvatarById", u.ID).Return(avatar, nil).Once()
-		cld.On("Destroy", avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
+		cld.On("Destroy", mock.Anything, avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
 		repo.On("DeleteAvatarById", avatar.PublicId).Return(nil).Once()
-		cld.On("UploadToCloud", "avatar", "user.jpg").Return(&res, nil).Once()
+		cld.On("UploadToCloud", mock.Anything, "avatar", avatarURI).Return(&res, nil).Once()
 		repo.On("InsertAvatar", &avatar).Return(avatar, errors.New("insert error")).Once()
-		err := a.UpdateProfile(u, "user.jpg")
+		cld.On("Destroy", mock.Anything, res.PublicID).Return(&uploader.DestroyResult{}, nil).Once()
+		err := a.UpdateProfile(context.Background(), u, models.ProfileUpdate{}, avatarURI)
 		assert.Error(t, err)
 	})
 }
@@ -409,11 +721,27 @@ func TestUpdateUser(t *testing.T) {
 		}
 		repo.On("FetchUserById", id).Return(&u, nil)
 		repo.On("UpdateUser", u).Return(nil)
-		res, err := a.UpdateUser(id, u)
+		res, err := a.UpdateUser(id, u, models.ProfileUpdate{})
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 	})
 
+	t.Run("Success - Role and profile details left out are kept", func(t *testing.T) {
+		id := uuid.New()
+		dob := time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)
+		stored := models.User{ID: id, Name: "John Doe", Role: models.RoleUser, Phone: "+14155552671", DateOfBirth: &dob,
+			Newsletter: true}
+		clear := ""
+
+		repo.On("FetchUserById", id).Return(&stored, nil).Once()
+		repo.On("UpdateUser", models.User{ID: id, Name: "Jane Doe", Email: "jane@gmail.com", Role: models.RoleUser,
+			DateOfBirth: &dob, Newsletter: true}).Return(nil).Once()
+
+		_, err := a.UpdateUser(id, models.User{Name: "Jane Doe", Email: "jane@gmail.com", Role: models.RoleAdmin},
+			models.ProfileUpdate{Phone: &clear})
+		assert.NoError(t, err)
+	})
+
 	t.Run("Failed Update - User not found", func(t *testing.T) {
 		id := uuid.New()
 		u := models.User{
@@ -423,7 +751,7 @@ func TestUpdateUser(t *testing.T) {
 			Name:     "John Doe",
 		}
 		repo.On("FetchUserById", id).Return(nil, errors.New("user not found"))
-		res, err := a.UpdateUser(id, u)
+		res, err := a.UpdateUser(id, u, models.ProfileUpdate{})
 		assert.Error(t, err)
 		assert.Nil(t, res)
 	})
@@ -438,7 +766,7 @@ func TestUpdateUser(t *testing.T) {
 		}
 		repo.On("FetchUserById", id).Return(&u, nil)
 		repo.On("UpdateUser", u).Return(errors.New("update error"))
-		res, err := a.UpdateUser(id, u)
+		res, err := a.UpdateUser(id, u, models.ProfileUpdate{})
 		assert.Error(t, err)
 		assert.Nil(t, res)
 	})
@@ -457,16 +785,16 @@ func TestDeleteUser(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		repo.On("FetchAvatarById", id).Return(avatar, nil).Once()
-		cld.On("Destroy", avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
+		cld.On("Destroy", mock.Anything, avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
 		repo.On("DeleteAvatarById", avatar.PublicId).Return(nil).Once()
 		repo.On("DeleteUserById", id).Return(nil).Once()
-		err := a.DeleteUser(id)
+		err := a.DeleteUser(context.Background(), id)
 		assert.NoError(t, err)
 	})
 
 	t.Run("Failed Delete - User not found", func(t *testing.T) {
 		repo.On("FetchAvatarById", id).Return(models.Avatar{}, errors.New("user not found")).Once()
-		err := a.DeleteUser(id)
+		err := a.DeleteUser(context.Background(), id)
 		assert.Error(t, err)
 	})
 
@@ -477,18 +805,18 @@ func TestDeleteUser(t *testing.T) {
 			UserId:   id,
 		}
 		repo.On("FetchAvatarById", id).Return(avatar, nil).Once()
-		cld.On("Destroy", avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
+		cld.On("Destroy", mock.Anything, avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
 		repo.On("DeleteAvatarById", avatar.PublicId).Return(errors.New("delete avatar error")).Once()
-		err := a.DeleteUser(id)
+		err := a.DeleteUser(context.Background(), id)
 		assert.Error(t, err)
 	})
 
 	t.Run("Failed Delete - Error deleting user", func(t *testing.T) {
 		repo.On("FetchAvatarById", id).Return(avatar, nil).Once()
-		cld.On("Destroy", avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
+		cld.On("Destroy", mock.Anything, avatar.PublicId).Return(&uploader.DestroyResult{}, nil).Once()
 		repo.On("DeleteAvatarById", avatar.PublicId).Return(nil).Once()
 		repo.On("DeleteUserById", id).Return(errors.New("delete error")).Once()
-		err := a.DeleteUser(id)
+		err := a.DeleteUser(context.Background(), id)
 		assert.Error(t, err)
 	})
 }
@@ -498,26 +826,242 @@ func TestLogout(t *testing.T) {
 	a, _, repo, _, _, _ := newTestAuthUC(t)
 	t.Run("Success", func(t *testing.T) {
 		tok := "MQUYLLXB2PHU5PE6PG3HGG2AXI"
-		id := uuid.New()
-		repo.On("FetchUserByToken", tok).Return(&models.User{ID: id}, nil).Once()
-		repo.On("DeleteTokenById", id).Return(nil).Once()
+		repo.On("DeleteToken", tok).Return(nil).Once()
 		err := a.DeleteUserToken(tok)
 		assert.NoError(t, err)
 	})
 
 	t.Run("Failed Logout - Token not found", func(t *testing.T) {
 		tok := "INVALIDTOKEN"
-		repo.On("FetchUserByToken", tok).Return(nil, errors.New("token not found")).Once()
+		repo.On("DeleteToken", tok).Return(sql.ErrNoRows).Once()
 		err := a.DeleteUserToken(tok)
 		assert.Error(t, err)
 	})
 
 	t.Run("Failed Logout - Error deleting token", func(t *testing.T) {
 		tok := "MQUYLLXB2PHU5PE6PG3HGG2AXI"
-		id := uuid.New()
-		repo.On("FetchUserByToken", tok).Return(&models.User{ID: id}, nil).Once()
-		repo.On("DeleteTokenById", id).Return(errors.New("delete error")).Once()
+		repo.On("DeleteToken", tok).Return(errors.New("delete error")).Once()
 		err := a.DeleteUserToken(tok)
 		assert.Error(t, err)
 	})
 }
+
+// TestDeleteExpiredTokens tests the DeleteExpiredTokens use case for success and error scenarios.
+func TestDeleteExpiredTokens(t *testing.T) {
+	a, _, repo, _, _, _ := newTestAuthUC(t)
+	t.Run("Success", func(t *testing.T) {
+		repo.On("DeleteExpiredTokens", mock.Anything).Return(int64(4), nil).Once()
+		repo.On("DeleteExpiredMagicLinks", mock.Anything).Return(int64(2), nil).Once()
+		n, err := a.DeleteExpiredTokens(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(6), n)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		repo.On("DeleteExpiredTokens", mock.Anything).Return(int64(0), errors.New("delete error")).Once()
+		_, err := a.DeleteExpiredTokens(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+// TestCreateUser tests the CreateUser use case for success and error scenarios.
+func TestCreateUser(t *testing.T) {
+	a, _, repo, _, mBcrypt, mail := newTestAuthUC(t)
+
+	t.Run("Success", func(t *testing.T) {
+		u := models.User{Name: "Jane", Email: "jane@gmail.com"}
+		created := models.User{ID: uuid.New(), Name: u.Name, Email: u.Email, Role: models.RoleUser, Password: "hash"}
+		repo.On("FetchUserByEmail", u.Email).Return(nil, sql.ErrNoRows).Once()
+		mBcrypt.On("GenerateFromPassword", mock.Anything).Return([]byte("hash"), nil).Once()
+		repo.On("InsertUser", mock.MatchedBy(func(in models.User) bool {
+			return in.Role == models.RoleUser && in.Password == "hash"
+		})).Return(&created, nil).Once()
+		mail.On("SendMail", mock.Anything, u.Email, mock.Anything, "account-created", mock.Anything, mock.Anything).Return(nil).Once()
+		res, err := a.CreateUser(u)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, res.User.ID)
+		assert.Empty(t, res.User.Password)
+	})
+
+	t.Run("Invalid role", func(t *testing.T) {
+		res, err := a.CreateUser(models.User{Name: "Jane", Email: "jane@gmail.com", Role: "root"})
+		assert.ErrorIs(t, err, auth.ErrInvalidRole)
+		assert.Nil(t, res)
+	})
+
+	t.Run("User already exists", func(t *testing.T) {
+		u := models.User{Name: "Jane", Email: "jane@gmail.com"}
+		repo.On("FetchUserByEmail", u.Email).Return(&models.User{Email: u.Email}, nil).Once()
+		res, err := a.CreateUser(u)
+		assert.ErrorIs(t, err, auth.ErrDuplicateEmail)
+		assert.Nil(t, res)
+	})
+
+	t.Run("Email taken since it was checked", func(t *testing.T) {
+		u := models.User{Name: "Jane", Email: "jane@gmail.com"}
+		repo.On("FetchUserByEmail", u.Email).Return(nil, sql.ErrNoRows).Once()
+		mBcrypt.On("GenerateFromPassword", mock.Anything).Return([]byte("hash"), nil).Once()
+		repo.On("InsertUser", mock.Anything).Return(nil, auth.ErrDuplicateEmail).Once()
+		res, err := a.CreateUser(u)
+		assert.ErrorIs(t, err, auth.ErrDuplicateEmail)
+		assert.Nil(t, res)
+	})
+
+	t.Run("Mail failure removes the user", func(t *testing.T) {
+		u := models.User{Name: "Jane", Email: "jane@gmail.com"}
+		created := models.User{ID: uuid.New(), Name: u.Name, Email: u.Email, Role: models.RoleUser}
+		repo.On("FetchUserByEmail", u.Email).Return(nil, sql.ErrNoRows).Once()
+		mBcrypt.On("GenerateFromPassword", mock.Anything).Return([]byte("hash"), nil).Once()
+		repo.On("InsertUser", mock.Anything).Return(&created, nil).Once()
+		mail.On("SendMail", mock.Anything, u.Email, mock.Anything, "account-created", mock.Anything, mock.Anything).Return(errors.New("smtp down")).Once()
+		repo.On("DeleteUserById", created.ID).Return(nil).Once()
+		res, err := a.CreateUser(u)
+		assert.Error(t, err)
+		assert.Nil(t, res)
+	})
+}
+
+// TestUpdateUserRole tests the UpdateUserRole use case for success and error scenarios.
+func TestUpdateUserRole(t *testing.T) {
+	a, _, repo, _, _, _ := newTestAuthUC(t)
+
+	t.Run("Success", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("UpdateUserRole", id, models.RoleAdmin).Return(nil).Once()
+		res, err := a.UpdateUserRole(id, models.RoleAdmin)
+		require.NoError(t, err)
+		assert.True(t, res.Success)
+	})
+
+	t.Run("Invalid role", func(t *testing.T) {
+		res, err := a.UpdateUserRole(uuid.New(), "root")
+		assert.ErrorIs(t, err, auth.ErrInvalidRole)
+		assert.Nil(t, res)
+	})
+}
+
+// TestSetCurrency tests the SetCurrency use case for accepted, cleared and unknown currencies.
+func TestSetCurrency(t *testing.T) {
+	a, _, repo, _, _, _ := newTestAuthUC(t)
+	require.NoError(t, money.Accept("EUR"))
+	t.Cleanup(func() { money.Accept() })
+
+	t.Run("Success", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("UpdateCurrency", id, "EUR").Return(nil).Once()
+		res, err := a.SetCurrency(id, "eur")
+		require.NoError(t, err)
+		assert.True(t, res.Success)
+	})
+
+	t.Run("Preference cleared", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("UpdateCurrency", id, "").Return(nil).Once()
+		_, err := a.SetCurrency(id, "")
+		require.NoError(t, err)
+	})
+
+	t.Run("Currency not accepted", func(t *testing.T) {
+		res, err := a.SetCurrency(uuid.New(), "GBP")
+		assert.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+		assert.Nil(t, res)
+	})
+}
+
+// TestSetLocale tests the SetLocale use case for supported, cleared and unknown locales.
+func TestSetLocale(t *testing.T) {
+	a, _, repo, _, _, _ := newTestAuthUC(t)
+
+	t.Run("Success", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("UpdateLocale", id, "fr").Return(nil).Once()
+		res, err := a.SetLocale(id, "FR")
+		require.NoError(t, err)
+		assert.True(t, res.Success)
+	})
+
+	t.Run("Preference cleared", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("UpdateLocale", id, "").Return(nil).Once()
+		_, err := a.SetLocale(id, "")
+		require.NoError(t, err)
+	})
+
+	t.Run("Locale not supported", func(t *testing.T) {
+		res, err := a.SetLocale(uuid.New(), "xx")
+		assert.ErrorIs(t, err, i18n.ErrUnsupportedLocale)
+		assert.Nil(t, res)
+	})
+}
+
+// TestScheduleDeletion tests the ScheduleDeletion use case for success and error scenarios.
+func TestScheduleDeletion(t *testing.T) {
+	a, _, repo, mToken, _, mail := newTestAuthUC(t)
+	u := models.User{ID: uuid.New(), Name: "Jane", Email: "jane@gmail.com"}
+	tok := &models.Token{PlainText: "tok", Hash: []byte("hash"), Expiry: time.Now().Add(time.Hour)}
+
+	t.Run("Success", func(t *testing.T) {
+		repo.On("FetchUserById", u.ID).Return(&u, nil).Once()
+		mToken.On("GenerateToken", u.ID, mock.Anything, mock.Anything).Return(tok, nil).Once()
+		repo.On("ScheduleUserDeletion", u.ID, tok.Expiry, tok.Hash).Return(nil).Once()
+		mail.On("SendMail", mock.Anything, u.Email, mock.Anything, "account-deletion", mock.Anything, mock.Anything).Return(nil).Once()
+		repo.On("DeleteTokenById", u.ID).Return(nil).Once()
+		res, err := a.ScheduleDeletion(u.ID)
+		require.NoError(t, err)
+		assert.True(t, res.Success)
+	})
+
+	t.Run("Already pending", func(t *testing.T) {
+		pending := u
+		pending.DeleteAfter = &tok.Expiry
+		repo.On("FetchUserById", u.ID).Return(&pending, nil).Once()
+		res, err := a.ScheduleDeletion(u.ID)
+		assert.ErrorIs(t, err, auth.ErrPendingDeletion)
+		assert.Nil(t, res)
+	})
+
+	t.Run("Mail failure cancels the deletion", func(t *testing.T) {
+		repo.On("FetchUserById", u.ID).Return(&u, nil).Once()
+		mToken.On("GenerateToken", u.ID, mock.Anything, mock.Anything).Return(tok, nil).Once()
+		repo.On("ScheduleUserDeletion", u.ID, tok.Expiry, tok.Hash).Return(nil).Once()
+		mail.On("SendMail", mock.Anything, u.Email, mock.Anything, "account-deletion", mock.Anything, mock.Anything).Return(errors.New("smtp down")).Once()
+		repo.On("CancelUserDeletion", u.ID).Return(nil).Once()
+		res, err := a.ScheduleDeletion(u.ID)
+		assert.Error(t, err)
+		assert.Nil(t, res)
+	})
+}
+
+// TestRestoreAccount tests the RestoreAccount use case for success and error scenarios.
+func TestRestoreAccount(t *testing.T) {
+	a, _, repo, _, _, _ := newTestAuthUC(t)
+
+	t.Run("Success", func(t *testing.T) {
+		repo.On("RestoreUser", "tok").Return(nil).Once()
+		res, err := a.RestoreAccount("tok")
+		require.NoError(t, err)
+		assert.True(t, res.Success)
+	})
+
+	t.Run("Unknown or expired link", func(t *testing.T) {
+		repo.On("RestoreUser", "bad").Return(sql.ErrNoRows).Once()
+		res, err := a.RestoreAccount("bad")
+		assert.ErrorIs(t, err, auth.ErrInvalidRestoreLink)
+		assert.Nil(t, res)
+	})
+}
+
+// TestPurgeDeletedAccounts tests that due accounts are deleted and failures are reported.
+func TestPurgeDeletedAccounts(t *testing.T) {
+	a, _, repo, _, _, _ := newTestAuthUC(t)
+	ok, failing := uuid.New(), uuid.New()
+
+	repo.On("FetchUsersDueForDeletion", mock.Anything, mock.Anything).Return([]uuid.UUID{ok, failing}, nil).Once()
+	repo.On("FetchAvatarById", mock.Anything).Return(models.Avatar{}, sql.ErrNoRows).Twice()
+	repo.On("DeleteUserById", ok).Return(nil).Once()
+	repo.On("DeleteUserById", failing).Return(errors.New("delete error")).Once()
+
+	n, err := a.PurgeDeletedAccounts(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), n)
+}
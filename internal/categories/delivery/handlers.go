@@ -0,0 +1,187 @@
+// Package delivery provides HTTP handlers for product categories.
+//
+// Anyone can browse the category tree; admins create, rename, move and delete
+// categories, and set the tax class, shipping class and visibility of many at once.
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/categories"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// CategoryHandlers provides HTTP handler methods for category endpoints.
+type CategoryHandlers struct {
+	logger     logger.Logger
+	categoryUC categories.CategoryUC
+}
+
+// NewCategoryHandlers returns a new CategoryHandlers.
+func NewCategoryHandlers(logger logger.Logger, categoryUC categories.CategoryUC) *CategoryHandlers {
+	return &CategoryHandlers{
+		logger:     logger,
+		categoryUC: categoryUC,
+	}
+}
+
+type categoriesResponse struct {
+	Success    bool               `json:"success"`
+	Categories []*models.Category `json:"categories"`
+}
+
+type categoryResponse struct {
+	Success  bool             `json:"success"`
+	Category *models.Category `json:"category"`
+}
+
+// GetCategories returns the category tree.
+// Endpoint: GET /api/v1/categories
+func (h *CategoryHandlers) GetCategories(w http.ResponseWriter, r *http.Request) {
+	cats, err := h.categoryUC.GetCategories()
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting categories: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, categoriesResponse{Success: true, Categories: cats})
+}
+
+// GetCategory returns a category with its subcategories.
+// Endpoint: GET /api/v1/categories/{id}
+func (h *CategoryHandlers) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	cat, err := h.categoryUC.GetCategory(id)
+	if err != nil {
+		h.writeError(w, r, "error getting category", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, categoryResponse{Success: true, Category: cat})
+}
+
+// CreateCategory creates a category (admin).
+// Endpoint: POST /api/v1/categories/admin/category
+// Expects JSON body: {"name": <string>, "parentId": <uuid or null>}.
+func (h *CategoryHandlers) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	cat, ok := h.readCategory(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.categoryUC.CreateCategory(cat)
+	if err != nil {
+		h.writeError(w, r, "error creating category", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusCreated, categoryResponse{Success: true, Category: created})
+}
+
+// UpdateCategory renames a category or moves it under another parent (admin).
+// Endpoint: PUT /api/v1/categories/admin/category/{id}
+// Expects JSON body: {"name": <string>, "parentId": <uuid or null>}; a null parent makes it a root.
+func (h *CategoryHandlers) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	cat, ok := h.readCategory(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.categoryUC.UpdateCategory(id, cat)
+	if err != nil {
+		h.writeError(w, r, "error updating category", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, categoryResponse{Success: true, Category: updated})
+}
+
+// BulkUpdateCategories sets the tax class, shipping class or visibility of categories
+// and their products in one transaction, returning how many of each changed (admin).
+// Endpoint: PUT /api/v1/categories/admin/bulk
+// Expects JSON body: {"categories": [<uuid>], "includeSubcategories": <bool>,
+// "taxClass": <string>, "shippingClass": <string>, "hidden": <bool>}; omitted classes
+// and visibility are left as they are.
+func (h *CategoryHandlers) BulkUpdateCategories(w http.ResponseWriter, r *http.Request) {
+	var req bulkCategoriesRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	summary, err := h.categoryUC.BulkUpdateCategories(models.CategoryBulkUpdate(req))
+	if err != nil {
+		h.writeError(w, r, "error updating categories", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success bool                        `json:"success"`
+		Updated *models.CategoryBulkSummary `json:"updated"`
+	}{Success: true, Updated: summary})
+}
+
+// DeleteCategory deletes a category without subcategories or products (admin).
+// Endpoint: DELETE /api/v1/categories/admin/category/{id}
+func (h *CategoryHandlers) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	if err := h.categoryUC.DeleteCategory(id); err != nil {
+		h.writeError(w, r, "error deleting category", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, models.Response{Success: true, Message: "category deleted"})
+}
+
+// readCategory reads and validates the category in the request body. It writes the
+// response and returns false when the body is not a valid category.
+func (h *CategoryHandlers) readCategory(w http.ResponseWriter, r *http.Request) (models.Category, bool) {
+	var req categoryRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return models.Category{}, false
+	}
+
+	return models.Category{Name: strings.TrimSpace(req.Name), ParentId: req.ParentId}, true
+}
+
+func (h *CategoryHandlers) writeError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if categories.IsClientError(err) {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("%s: %v", msg, err)
+		return
+	}
+	_ = utils.ServerError(w, r, h.logger, fmt.Errorf("%s: %w", msg, err))
+}
+
+func hasClass(classes []string, class string) bool {
+	for _, c := range classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
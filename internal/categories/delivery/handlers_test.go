@@ -0,0 +1,184 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/categories"
+	"github.com/jofosuware/go/shopit/internal/categories/delivery"
+	"github.com/jofosuware/go/shopit/internal/categories/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCategories(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	categoryUC := mocks.NewCategoryUC(t)
+
+	h := delivery.NewCategoryHandlers(logger, categoryUC)
+
+	root := &models.Category{CategoryId: uuid.New(), Name: "Electronics"}
+	root.Children = []*models.Category{{CategoryId: uuid.New(), Name: "Cameras"}}
+	categoryUC.On("GetCategories").Return([]*models.Category{root}, nil).Once()
+
+	rr := httptest.NewRecorder()
+	h.GetCategories(rr, httptest.NewRequest(http.MethodGet, "/categories", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp struct {
+		Categories []*models.Category `json:"categories"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Categories, 1)
+	require.Len(t, resp.Categories[0].Children, 1)
+	assert.Equal(t, "Cameras", resp.Categories[0].Children[0].Name)
+}
+
+func TestCreateCategory(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	categoryUC := mocks.NewCategoryUC(t)
+
+	h := delivery.NewCategoryHandlers(logger, categoryUC)
+	parent := uuid.New()
+
+	t.Run("Category is created", func(t *testing.T) {
+		cat := models.Category{Name: "Drones", ParentId: uuid.NullUUID{UUID: parent, Valid: true}}
+		categoryUC.On("CreateCategory", cat).Return(&models.Category{CategoryId: uuid.New(), Name: "Drones"}, nil).Once()
+
+		body := `{"name":"  Drones ","parentId":"` + parent.String() + `"}`
+		rr := httptest.NewRecorder()
+		h.CreateCategory(rr, httptest.NewRequest(http.MethodPost, "/categories/admin/category", bytes.NewBufferString(body)))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Missing name", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.CreateCategory(rr, httptest.NewRequest(http.MethodPost, "/categories/admin/category", bytes.NewBufferString(`{"name":" "}`)))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Name taken", func(t *testing.T) {
+		categoryUC.On("CreateCategory", models.Category{Name: "Books"}).Return(nil, categories.ErrCategoryExists).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.CreateCategory(rr, httptest.NewRequest(http.MethodPost, "/categories/admin/category", bytes.NewBufferString(`{"name":"Books"}`)))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestUpdateCategory(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	categoryUC := mocks.NewCategoryUC(t)
+
+	h := delivery.NewCategoryHandlers(logger, categoryUC)
+	id := uuid.New()
+
+	newRequest := func(method, body string) *http.Request {
+		req := httptest.NewRequest(method, "/categories/admin/category/id", bytes.NewBufferString(body))
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+	}
+
+	t.Run("Category moved to root", func(t *testing.T) {
+		categoryUC.On("UpdateCategory", id, models.Category{Name: "Cameras"}).
+			Return(&models.Category{CategoryId: id, Name: "Cameras"}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.UpdateCategory(rr, newRequest(http.MethodPut, `{"name":"Cameras","parentId":null}`))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		categoryUC.On("UpdateCategory", id, mock.Anything).Return(nil, categories.ErrCategoryCycle).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.UpdateCategory(rr, newRequest(http.MethodPut, `{"name":"Cameras","parentId":"`+uuid.NewString()+`"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Delete category in use", func(t *testing.T) {
+		categoryUC.On("DeleteCategory", id).Return(categories.ErrCategoryInUse).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.DeleteCategory(rr, newRequest(http.MethodDelete, ""))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestBulkUpdateCategories(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	categoryUC := mocks.NewCategoryUC(t)
+
+	h := delivery.NewCategoryHandlers(logger, categoryUC)
+	id := uuid.New()
+
+	newRequest := func(body string) *http.Request {
+		return httptest.NewRequest(http.MethodPut, "/categories/admin/bulk", bytes.NewBufferString(body))
+	}
+
+	t.Run("Summary of the changes", func(t *testing.T) {
+		categoryUC.On("BulkUpdateCategories", mock.MatchedBy(func(u models.CategoryBulkUpdate) bool {
+			return len(u.Categories) == 1 && u.Categories[0] == id && u.IncludeSubcategories &&
+				*u.TaxClass == models.TaxReduced && u.ShippingClass == nil && u.Hidden == nil
+		})).Return(&models.CategoryBulkSummary{Categories: 3, Products: 12}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.BulkUpdateCategories(rr, newRequest(`{"categories":["`+id.String()+`"],"includeSubcategories":true,"taxClass":"reduced"}`))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Updated models.CategoryBulkSummary `json:"updated"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.CategoryBulkSummary{Categories: 3, Products: 12}, resp.Updated)
+	})
+
+	t.Run("Invalid updates", func(t *testing.T) {
+		for _, body := range []string{
+			`{"categories":[],"hidden":true}`,
+			`{"categories":["` + id.String() + `"]}`,
+			`{"categories":["` + id.String() + `"],"taxClass":"luxury"}`,
+			`{"categories":["` + id.String() + `"],"shippingClass":"drone"}`,
+		} {
+			logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+			rr := httptest.NewRecorder()
+			h.BulkUpdateCategories(rr, newRequest(body))
+
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, body)
+		}
+	})
+
+	t.Run("Missing category", func(t *testing.T) {
+		categoryUC.On("BulkUpdateCategories", mock.Anything).Return(nil, categories.ErrCategoryNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.BulkUpdateCategories(rr, newRequest(`{"categories":["`+id.String()+`"],"hidden":false}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
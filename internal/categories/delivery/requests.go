@@ -0,0 +1,43 @@
+package delivery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// categoryRequest is the body of the endpoints creating and updating a category.
+type categoryRequest struct {
+	Name     string        `json:"name" validate:"notblank"`
+	ParentId uuid.NullUUID `json:"parentId"`
+}
+
+func (req *categoryRequest) Validate(v *validator.Validator) {
+	v.CheckCode(len([]rune(strings.TrimSpace(req.Name))) <= models.MaxCategoryNameLength, "name", validator.CodeTooLong,
+		fmt.Sprintf("name must be at most %d characters", models.MaxCategoryNameLength))
+}
+
+// bulkCategoriesRequest is the body of BulkUpdateCategories.
+type bulkCategoriesRequest struct {
+	Categories           []uuid.UUID `json:"categories" validate:"min=1"`
+	IncludeSubcategories bool        `json:"includeSubcategories"`
+	TaxClass             *string     `json:"taxClass" validate:"required_without_all=ShippingClass Hidden"`
+	ShippingClass        *string     `json:"shippingClass"`
+	Hidden               *bool       `json:"hidden"`
+}
+
+func (req *bulkCategoriesRequest) Validate(v *validator.Validator) {
+	v.CheckCode(len(req.Categories) <= models.MaxBulkCategories, "categories", validator.CodeTooMany,
+		fmt.Sprintf("categories must have at most %d items", models.MaxBulkCategories))
+	if req.TaxClass != nil {
+		v.CheckCode(hasClass(models.TaxClasses, *req.TaxClass), "taxClass", validator.CodeOneOf,
+			fmt.Sprintf("tax class must be one of: %s", strings.Join(models.TaxClasses, ", ")))
+	}
+	if req.ShippingClass != nil {
+		v.CheckCode(hasClass(models.ShippingClasses, *req.ShippingClass), "shippingClass", validator.CodeOneOf,
+			fmt.Sprintf("shipping class must be one of: %s", strings.Join(models.ShippingClasses, ", ")))
+	}
+}
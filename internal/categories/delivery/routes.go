@@ -0,0 +1,35 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// CategoryRouter returns a chi.Router with the category routes.
+//
+//   - GET    /                     → Category tree
+//   - GET    /{id}                 → Category with its subcategories
+//   - POST   /admin/category       → Create a category (admin)
+//   - PUT    /admin/category/{id}  → Rename or move a category (admin)
+//   - DELETE /admin/category/{id}  → Delete a category (admin)
+//   - PUT    /admin/bulk           → Set classes or visibility of categories and their products (admin)
+func (h *CategoryHandlers) CategoryRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Get("/", h.GetCategories)
+	mux.Get("/{id}", h.GetCategory)
+
+	mux.Group(func(r chi.Router) {
+		r.Use(utils.IsAuthenticated)
+		r.Use(utils.IsAdmin)
+
+		r.Post("/admin/category", h.CreateCategory)
+		r.Put("/admin/category/{id}", h.UpdateCategory)
+		r.Delete("/admin/category/{id}", h.DeleteCategory)
+		r.Put("/admin/bulk", h.BulkUpdateCategories)
+	})
+
+	return mux
+}
@@ -0,0 +1,31 @@
+package categories
+
+import "errors"
+
+var (
+	// ErrCategoryNotFound is returned when a category does not exist.
+	ErrCategoryNotFound = errors.New("category not found")
+
+	// ErrParentNotFound is returned when the parent of a category does not exist.
+	ErrParentNotFound = errors.New("parent category not found")
+
+	// ErrCategoryExists is returned when another category already has the name.
+	ErrCategoryExists = errors.New("a category with this name already exists")
+
+	// ErrCategoryCycle is returned when a category would be moved below itself.
+	ErrCategoryCycle = errors.New("a category cannot be moved below itself")
+
+	// ErrCategoryInUse is returned when deleting a category that has subcategories or products.
+	ErrCategoryInUse = errors.New("category has subcategories or products")
+)
+
+// IsClientError reports whether err is caused by the request rather than the server.
+func IsClientError(err error) bool {
+	for _, e := range []error{ErrCategoryNotFound, ErrParentNotFound, ErrCategoryExists, ErrCategoryCycle, ErrCategoryInUse} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,196 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	uuid "github.com/google/uuid"
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CategoryUC is an autogenerated mock type for the CategoryUC type
+type CategoryUC struct {
+	mock.Mock
+}
+
+// BulkUpdateCategories provides a mock function with given fields: u
+func (_m *CategoryUC) BulkUpdateCategories(u models.CategoryBulkUpdate) (*models.CategoryBulkSummary, error) {
+	ret := _m.Called(u)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkUpdateCategories")
+	}
+
+	var r0 *models.CategoryBulkSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.CategoryBulkUpdate) (*models.CategoryBulkSummary, error)); ok {
+		return rf(u)
+	}
+	if rf, ok := ret.Get(0).(func(models.CategoryBulkUpdate) *models.CategoryBulkSummary); ok {
+		r0 = rf(u)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CategoryBulkSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.CategoryBulkUpdate) error); ok {
+		r1 = rf(u)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateCategory provides a mock function with given fields: c
+func (_m *CategoryUC) CreateCategory(c models.Category) (*models.Category, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCategory")
+	}
+
+	var r0 *models.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Category) (*models.Category, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(models.Category) *models.Category); ok {
+		r0 = rf(c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Category) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteCategory provides a mock function with given fields: id
+func (_m *CategoryUC) DeleteCategory(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCategory")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCategories provides a mock function with given fields:
+func (_m *CategoryUC) GetCategories() ([]*models.Category, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCategories")
+	}
+
+	var r0 []*models.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.Category, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.Category); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCategory provides a mock function with given fields: id
+func (_m *CategoryUC) GetCategory(id uuid.UUID) (*models.Category, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCategory")
+	}
+
+	var r0 *models.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Category, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Category); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCategory provides a mock function with given fields: id, c
+func (_m *CategoryUC) UpdateCategory(id uuid.UUID, c models.Category) (*models.Category, error) {
+	ret := _m.Called(id, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCategory")
+	}
+
+	var r0 *models.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.Category) (*models.Category, error)); ok {
+		return rf(id, c)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.Category) *models.Category); ok {
+		r0 = rf(id, c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, models.Category) error); ok {
+		r1 = rf(id, c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCategoryUC creates a new instance of CategoryUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCategoryUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CategoryUC {
+	mock := &CategoryUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,252 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	uuid "github.com/google/uuid"
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+// BulkUpdateCategories provides a mock function with given fields: ids, u
+func (_m *Repo) BulkUpdateCategories(ids []uuid.UUID, u models.CategoryBulkUpdate) (*models.CategoryBulkSummary, error) {
+	ret := _m.Called(ids, u)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkUpdateCategories")
+	}
+
+	var r0 *models.CategoryBulkSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID, models.CategoryBulkUpdate) (*models.CategoryBulkSummary, error)); ok {
+		return rf(ids, u)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID, models.CategoryBulkUpdate) *models.CategoryBulkSummary); ok {
+		r0 = rf(ids, u)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CategoryBulkSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID, models.CategoryBulkUpdate) error); ok {
+		r1 = rf(ids, u)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CategoryInUse provides a mock function with given fields: id
+func (_m *Repo) CategoryInUse(id uuid.UUID) (bool, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CategoryInUse")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (bool, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) bool); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CategoryNameExists provides a mock function with given fields: name, exclude
+func (_m *Repo) CategoryNameExists(name string, exclude uuid.UUID) (bool, error) {
+	ret := _m.Called(name, exclude)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CategoryNameExists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, uuid.UUID) (bool, error)); ok {
+		return rf(name, exclude)
+	}
+	if rf, ok := ret.Get(0).(func(string, uuid.UUID) bool); ok {
+		r0 = rf(name, exclude)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, uuid.UUID) error); ok {
+		r1 = rf(name, exclude)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteCategory provides a mock function with given fields: id
+func (_m *Repo) DeleteCategory(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCategory")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FetchCategories provides a mock function with given fields:
+func (_m *Repo) FetchCategories() ([]models.Category, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchCategories")
+	}
+
+	var r0 []models.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]models.Category, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []models.Category); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchCategoryById provides a mock function with given fields: id
+func (_m *Repo) FetchCategoryById(id uuid.UUID) (*models.Category, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchCategoryById")
+	}
+
+	var r0 *models.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Category, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Category); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertCategory provides a mock function with given fields: c
+func (_m *Repo) InsertCategory(c *models.Category) (*models.Category, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertCategory")
+	}
+
+	var r0 *models.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*models.Category) (*models.Category, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(*models.Category) *models.Category); ok {
+		r0 = rf(c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*models.Category) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCategory provides a mock function with given fields: c
+func (_m *Repo) UpdateCategory(c *models.Category) (*models.Category, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCategory")
+	}
+
+	var r0 *models.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*models.Category) (*models.Category, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(*models.Category) *models.Category); ok {
+		r0 = rf(c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*models.Category) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
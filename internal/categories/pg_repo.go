@@ -0,0 +1,33 @@
+package categories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// FetchCategories fetches every category ordered by name, returns an error on failure
+	FetchCategories() ([]models.Category, error)
+
+	// FetchCategoryById fetches a category by id, returns sql.ErrNoRows when there is no such category
+	FetchCategoryById(id uuid.UUID) (*models.Category, error)
+
+	// CategoryNameExists reports whether a category other than exclude already has the name, regardless of case
+	CategoryNameExists(name string, exclude uuid.UUID) (bool, error)
+
+	// InsertCategory inserts a category, returns an error on failure
+	InsertCategory(c *models.Category) (*models.Category, error)
+
+	// UpdateCategory updates the name and parent of a category and renames its products, returns sql.ErrNoRows when there is no such category
+	UpdateCategory(c *models.Category) (*models.Category, error)
+
+	// BulkUpdateCategories sets the classes and hidden flag of u that are not nil on the categories ids and their
+	// products in one transaction, returns how many of each were changed
+	BulkUpdateCategories(ids []uuid.UUID, u models.CategoryBulkUpdate) (*models.CategoryBulkSummary, error)
+
+	// CategoryInUse reports whether a category has subcategories or products
+	CategoryInUse(id uuid.UUID) (bool, error)
+
+	// DeleteCategory deletes a category, returns sql.ErrNoRows when there is no such category
+	DeleteCategory(id uuid.UUID) error
+}
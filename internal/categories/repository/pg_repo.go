@@ -0,0 +1,257 @@
+// Package repository provides persistence for the product category tree.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// categoryColumns lists the categories columns in the order scanned by scanCategory.
+const categoryColumns = "category_id, name, parent_id, tax_class, shipping_class, hidden, created_at"
+
+// CategoriesRepository handles category database operations.
+type CategoriesRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewCategoriesRepository returns a new CategoriesRepository.
+func NewCategoriesRepository(db *sql.DB) *CategoriesRepository {
+	return &CategoriesRepository{
+		DB: db,
+	}
+}
+
+// FetchCategories fetches every category ordered by name.
+func (r *CategoriesRepository) FetchCategories() ([]models.Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, "select "+categoryColumns+" from categories order by name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cats []models.Category
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		cats = append(cats, *c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cats, nil
+}
+
+// FetchCategoryById fetches a category by id. It returns sql.ErrNoRows when there
+// is no such category.
+func (r *CategoriesRepository) FetchCategoryById(id uuid.UUID) (*models.Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "select " + categoryColumns + " from categories where category_id = $1"
+
+	return scanCategory(r.DB.QueryRowContext(ctx, query, id))
+}
+
+// CategoryNameExists reports whether a category other than exclude already has
+// name, regardless of case.
+func (r *CategoriesRepository) CategoryNameExists(name string, exclude uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var exists bool
+
+	query := "select exists(select 1 from categories where lower(name) = lower($1) and category_id <> $2)"
+
+	if err := r.DB.QueryRowContext(ctx, query, name, exclude).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// InsertCategory inserts a category.
+func (r *CategoriesRepository) InsertCategory(c *models.Category) (*models.Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into categories (name, parent_id) values ($1, $2)
+				returning ` + categoryColumns
+
+	return scanCategory(r.DB.QueryRowContext(ctx, query, c.Name, c.ParentId))
+}
+
+// UpdateCategory updates the name and parent of a category and, in the same
+// transaction, the category name shown on its products. It returns sql.ErrNoRows
+// when there is no such category.
+func (r *CategoriesRepository) UpdateCategory(c *models.Category) (*models.Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `update categories set name = $1, parent_id = $2 where category_id = $3
+				returning ` + categoryColumns
+
+	cat, err := scanCategory(tx.QueryRowContext(ctx, query, c.Name, c.ParentId, c.CategoryId))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "update products set category = $1 where category_id = $2", cat.Name, cat.CategoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return cat, nil
+}
+
+// BulkUpdateCategories sets the tax class, shipping class and hidden flag of
+// u that are not nil on the categories ids and on their products, within one
+// transaction. It returns how many categories and products were changed.
+func (r *CategoriesRepository) BulkUpdateCategories(ids []uuid.UUID, u models.CategoryBulkUpdate) (*models.CategoryBulkSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	args := []interface{}{nullString(u.TaxClass), nullString(u.ShippingClass), nullBool(u.Hidden)}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	in := placeholders(3, len(ids))
+
+	// rows already set as requested are left alone, so the summary counts real changes
+	set := `set tax_class = coalesce($1, tax_class), shipping_class = coalesce($2, shipping_class),
+				hidden = coalesce($3, hidden)
+				where category_id in ` + in + ` and (tax_class <> coalesce($1, tax_class)
+				or shipping_class <> coalesce($2, shipping_class) or hidden <> coalesce($3, hidden))`
+
+	var summary models.CategoryBulkSummary
+
+	res, err := tx.ExecContext(ctx, "update categories "+set, args...)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Categories, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	res, err = tx.ExecContext(ctx, "update products "+set, args...)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Products, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// CategoryInUse reports whether a category has subcategories or products.
+func (r *CategoriesRepository) CategoryInUse(id uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var used bool
+
+	query := `select exists(select 1 from categories where parent_id = $1)
+				or exists(select 1 from products where category_id = $1)`
+
+	if err := r.DB.QueryRowContext(ctx, query, id).Scan(&used); err != nil {
+		return false, err
+	}
+
+	return used, nil
+}
+
+// DeleteCategory deletes a category. It returns sql.ErrNoRows when there is no such
+// category.
+func (r *CategoriesRepository) DeleteCategory(id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.DB.ExecContext(ctx, "delete from categories where category_id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCategory(row scanner) (*models.Category, error) {
+	var c models.Category
+	err := row.Scan(&c.CategoryId, &c.Name, &c.ParentId, &c.TaxClass, &c.ShippingClass, &c.Hidden, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// placeholders returns "($from+1, ..., $from+n)".
+func placeholders(from, n int) string {
+	ph := make([]string, n)
+	for i := range ph {
+		ph[i] = fmt.Sprintf("$%d", from+i+1)
+	}
+	return "(" + strings.Join(ph, ", ") + ")"
+}
+
+// nullString stores a nil string as NULL.
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+// nullBool stores a nil bool as NULL.
+func nullBool(b *bool) sql.NullBool {
+	if b == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *b, Valid: true}
+}
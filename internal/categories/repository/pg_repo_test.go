@@ -0,0 +1,162 @@
+package repository_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/categories/repository"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var columns = []string{"category_id", "name", "parent_id", "tax_class", "shipping_class", "hidden", "created_at"}
+
+func TestFetchCategories(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCategoriesRepository(db)
+	root := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("select category_id, name, parent_id, tax_class, shipping_class, hidden, created_at from categories order by name")).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(uuid.New(), "Cameras", root, "standard", "fragile", false, time.Now()).
+			AddRow(root, "Electronics", nil, "standard", "standard", true, time.Now()))
+
+	cats, err := repo.FetchCategories()
+	require.NoError(t, err)
+	require.Len(t, cats, 2)
+	assert.Equal(t, uuid.NullUUID{UUID: root, Valid: true}, cats[0].ParentId)
+	assert.Equal(t, "fragile", cats[0].ShippingClass)
+	assert.False(t, cats[1].ParentId.Valid)
+	assert.True(t, cats[1].Hidden)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCategoriesRepository(db)
+	c := models.Category{CategoryId: uuid.New(), Name: "Photo"}
+	update := regexp.QuoteMeta("update categories set name = $1, parent_id = $2 where category_id = $3")
+	rename := regexp.QuoteMeta("update products set category = $1 where category_id = $2")
+
+	t.Run("Category and its products are renamed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(update).WithArgs("Photo", c.ParentId, c.CategoryId).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(c.CategoryId, "Photo", nil, "standard", "standard", false, time.Now()))
+		mock.ExpectExec(rename).WithArgs("Photo", c.CategoryId).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+
+		got, err := repo.UpdateCategory(&c)
+		require.NoError(t, err)
+		assert.Equal(t, "Photo", got.Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Missing category", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(update).WithArgs("Photo", c.ParentId, c.CategoryId).WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+
+		_, err := repo.UpdateCategory(&c)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rename error rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(update).WithArgs("Photo", c.ParentId, c.CategoryId).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(c.CategoryId, "Photo", nil, "standard", "standard", false, time.Now()))
+		mock.ExpectExec(rename).WillReturnError(errors.New("db error"))
+		mock.ExpectRollback()
+
+		_, err := repo.UpdateCategory(&c)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBulkUpdateCategories(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCategoriesRepository(db)
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	reduced := "reduced"
+	hidden := true
+	u := models.CategoryBulkUpdate{TaxClass: &reduced, Hidden: &hidden}
+	args := []driver.Value{sql.NullString{String: "reduced", Valid: true}, sql.NullString{}, sql.NullBool{Bool: true, Valid: true},
+		ids[0], ids[1]}
+	set := regexp.QuoteMeta("set tax_class = coalesce($1, tax_class), shipping_class = coalesce($2, shipping_class),") +
+		`\s+hidden = coalesce\(\$3, hidden\)\s+where category_id in \(\$4, \$5\)`
+
+	t.Run("Categories and their products are updated", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("update categories " + set).WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("update products " + set).WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 14))
+		mock.ExpectCommit()
+
+		summary, err := repo.BulkUpdateCategories(ids, u)
+		require.NoError(t, err)
+		assert.Equal(t, &models.CategoryBulkSummary{Categories: 2, Products: 14}, summary)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Product error rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("update categories " + set).WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("update products " + set).WithArgs(args...).WillReturnError(errors.New("db error"))
+		mock.ExpectRollback()
+
+		_, err := repo.BulkUpdateCategories(ids, u)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCategoryInUse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCategoriesRepository(db)
+	id := uuid.New()
+
+	mock.ExpectQuery(`select exists\(select 1 from categories where parent_id = \$1\)\s+or exists\(select 1 from products where category_id = \$1\)`).
+		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(true))
+
+	used, err := repo.CategoryInUse(id)
+	require.NoError(t, err)
+	assert.True(t, used)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCategoriesRepository(db)
+	id := uuid.New()
+	query := regexp.QuoteMeta("delete from categories where category_id = $1")
+
+	mock.ExpectExec(query).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.DeleteCategory(id))
+
+	mock.ExpectExec(query).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.DeleteCategory(id), sql.ErrNoRows)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,26 @@
+package categories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type CategoryUC interface {
+	// GetCategories returns the category tree, as its roots
+	GetCategories() ([]*models.Category, error)
+
+	// GetCategory returns a category with its subcategories
+	GetCategory(id uuid.UUID) (*models.Category, error)
+
+	// CreateCategory creates a category, under its parent when it has one
+	CreateCategory(c models.Category) (*models.Category, error)
+
+	// UpdateCategory renames a category or moves it under another parent
+	UpdateCategory(id uuid.UUID, c models.Category) (*models.Category, error)
+
+	// BulkUpdateCategories sets the tax class, shipping class or visibility of categories and their products
+	BulkUpdateCategories(u models.CategoryBulkUpdate) (*models.CategoryBulkSummary, error)
+
+	// DeleteCategory deletes a category without subcategories or products
+	DeleteCategory(id uuid.UUID) error
+}
@@ -0,0 +1,212 @@
+// Package usecase implements the product category tree.
+//
+// Categories form a forest: a category without a parent is a root, and a category
+// can be renamed or moved under another parent as long as it is not moved below
+// itself. Only categories without subcategories or products can be deleted. Bulk
+// updates set the tax class, shipping class or visibility of categories and
+// cascade to their products.
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/categories"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/driver"
+)
+
+// CategoriesUC provides category use cases.
+type CategoriesUC struct {
+	repo categories.Repo
+}
+
+// NewCategoriesUC returns a new CategoriesUC.
+func NewCategoriesUC(repo categories.Repo) *CategoriesUC {
+	return &CategoriesUC{
+		repo: repo,
+	}
+}
+
+// GetCategories returns the category tree as its roots, siblings ordered by name.
+func (c *CategoriesUC) GetCategories() ([]*models.Category, error) {
+	cats, err := c.repo.FetchCategories()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching categories: %v", err)
+	}
+
+	return models.CategoryTree(cats), nil
+}
+
+// GetCategory returns a category with its subcategories.
+func (c *CategoriesUC) GetCategory(id uuid.UUID) (*models.Category, error) {
+	cats, err := c.repo.FetchCategories()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching categories: %v", err)
+	}
+
+	if cat := findCategory(models.CategoryTree(cats), id); cat != nil {
+		return cat, nil
+	}
+
+	return nil, categories.ErrCategoryNotFound
+}
+
+// CreateCategory creates a category. Its name must not be taken and its parent,
+// when set, must exist.
+func (c *CategoriesUC) CreateCategory(cat models.Category) (*models.Category, error) {
+	if err := c.checkName(cat.Name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	if cat.ParentId.Valid {
+		if _, err := c.repo.FetchCategoryById(cat.ParentId.UUID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, categories.ErrParentNotFound
+			}
+			return nil, fmt.Errorf("error fetching parent category: %v", err)
+		}
+	}
+
+	created, err := c.repo.InsertCategory(&cat)
+	if err != nil {
+		if driver.IsUniqueViolation(err, categoryNameIndex) {
+			return nil, categories.ErrCategoryExists
+		}
+		return nil, fmt.Errorf("error saving category: %v", err)
+	}
+
+	return created, nil
+}
+
+// UpdateCategory renames category id or moves it under another parent; a null
+// parent makes it a root. It cannot be moved below itself.
+func (c *CategoriesUC) UpdateCategory(id uuid.UUID, cat models.Category) (*models.Category, error) {
+	if err := c.checkName(cat.Name, id); err != nil {
+		return nil, err
+	}
+
+	if cat.ParentId.Valid {
+		cats, err := c.repo.FetchCategories()
+		if err != nil {
+			return nil, fmt.Errorf("error fetching categories: %v", err)
+		}
+
+		if !hasCategory(cats, cat.ParentId.UUID) {
+			return nil, categories.ErrParentNotFound
+		}
+		if models.IsDescendant(cats, cat.ParentId.UUID, id) {
+			return nil, categories.ErrCategoryCycle
+		}
+	}
+
+	cat.CategoryId = id
+	updated, err := c.repo.UpdateCategory(&cat)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, categories.ErrCategoryNotFound
+		}
+		if driver.IsUniqueViolation(err, categoryNameIndex) {
+			return nil, categories.ErrCategoryExists
+		}
+		return nil, fmt.Errorf("error updating category: %v", err)
+	}
+
+	return updated, nil
+}
+
+// BulkUpdateCategories sets the tax class, shipping class or visibility of the
+// categories of u, and of every category below them with IncludeSubcategories, on
+// the categories and their products. Every category named must exist.
+func (c *CategoriesUC) BulkUpdateCategories(u models.CategoryBulkUpdate) (*models.CategoryBulkSummary, error) {
+	cats, err := c.repo.FetchCategories()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching categories: %v", err)
+	}
+
+	for _, id := range u.Categories {
+		if !hasCategory(cats, id) {
+			return nil, fmt.Errorf("%w: %s", categories.ErrCategoryNotFound, id)
+		}
+	}
+
+	var ids []uuid.UUID
+	for _, cat := range cats {
+		for _, id := range u.Categories {
+			if cat.CategoryId == id || u.IncludeSubcategories && models.IsDescendant(cats, cat.CategoryId, id) {
+				ids = append(ids, cat.CategoryId)
+				break
+			}
+		}
+	}
+
+	summary, err := c.repo.BulkUpdateCategories(ids, u)
+	if err != nil {
+		return nil, fmt.Errorf("error updating categories: %v", err)
+	}
+
+	return summary, nil
+}
+
+// DeleteCategory deletes category id. A category with subcategories or products
+// cannot be deleted.
+func (c *CategoriesUC) DeleteCategory(id uuid.UUID) error {
+	used, err := c.repo.CategoryInUse(id)
+	if err != nil {
+		return fmt.Errorf("error checking category: %v", err)
+	}
+	if used {
+		return categories.ErrCategoryInUse
+	}
+
+	if err := c.repo.DeleteCategory(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return categories.ErrCategoryNotFound
+		}
+		return fmt.Errorf("error deleting category: %v", err)
+	}
+
+	return nil
+}
+
+// categoryNameIndex is the unique index on the names of categories, which a category
+// saved with the name of another one created since checkName breaks.
+const categoryNameIndex = "categories_name_idx"
+
+// checkName fails with categories.ErrCategoryExists when a category other than
+// exclude already has name.
+func (c *CategoriesUC) checkName(name string, exclude uuid.UUID) error {
+	exists, err := c.repo.CategoryNameExists(name, exclude)
+	if err != nil {
+		return fmt.Errorf("error checking category name: %v", err)
+	}
+	if exists {
+		return categories.ErrCategoryExists
+	}
+
+	return nil
+}
+
+// findCategory returns the node with id in the trees rooted at nodes, or nil.
+func findCategory(nodes []*models.Category, id uuid.UUID) *models.Category {
+	for _, n := range nodes {
+		if n.CategoryId == id {
+			return n
+		}
+		if found := findCategory(n.Children, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func hasCategory(cats []models.Category, id uuid.UUID) bool {
+	for _, c := range cats {
+		if c.CategoryId == id {
+			return true
+		}
+	}
+	return false
+}
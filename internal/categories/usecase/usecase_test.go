@@ -0,0 +1,192 @@
+package usecase_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/categories"
+	"github.com/jofosuware/go/shopit/internal/categories/mocks"
+	"github.com/jofosuware/go/shopit/internal/categories/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	electronics = models.Category{CategoryId: uuid.New(), Name: "Electronics"}
+	cameras     = models.Category{CategoryId: uuid.New(), Name: "Cameras", ParentId: uuid.NullUUID{UUID: electronics.CategoryId, Valid: true}}
+	lenses      = models.Category{CategoryId: uuid.New(), Name: "Lenses", ParentId: uuid.NullUUID{UUID: cameras.CategoryId, Valid: true}}
+	books       = models.Category{CategoryId: uuid.New(), Name: "Books"}
+
+	// all is ordered by name, like the repository returns them
+	all = []models.Category{books, cameras, electronics, lenses}
+)
+
+func TestGetCategories(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	c := usecase.NewCategoriesUC(repo)
+
+	repo.On("FetchCategories").Return(all, nil).Once()
+
+	roots, err := c.GetCategories()
+	require.NoError(t, err)
+
+	require.Len(t, roots, 2)
+	assert.Equal(t, "Books", roots[0].Name)
+	assert.Equal(t, "Electronics", roots[1].Name)
+	require.Len(t, roots[1].Children, 1)
+	assert.Equal(t, "Cameras", roots[1].Children[0].Name)
+	require.Len(t, roots[1].Children[0].Children, 1)
+	assert.Equal(t, "Lenses", roots[1].Children[0].Children[0].Name)
+}
+
+func TestGetCategory(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	c := usecase.NewCategoriesUC(repo)
+
+	t.Run("Category with its subtree", func(t *testing.T) {
+		repo.On("FetchCategories").Return(all, nil).Once()
+
+		cat, err := c.GetCategory(cameras.CategoryId)
+		require.NoError(t, err)
+		assert.Equal(t, "Cameras", cat.Name)
+		require.Len(t, cat.Children, 1)
+		assert.Equal(t, "Lenses", cat.Children[0].Name)
+	})
+
+	t.Run("Missing category", func(t *testing.T) {
+		repo.On("FetchCategories").Return(all, nil).Once()
+
+		_, err := c.GetCategory(uuid.New())
+		assert.ErrorIs(t, err, categories.ErrCategoryNotFound)
+	})
+}
+
+func TestCreateCategory(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	c := usecase.NewCategoriesUC(repo)
+
+	t.Run("Subcategory is created", func(t *testing.T) {
+		cat := models.Category{Name: "Drones", ParentId: electronics.ParentId}
+		cat.ParentId = uuid.NullUUID{UUID: electronics.CategoryId, Valid: true}
+		repo.On("CategoryNameExists", "Drones", uuid.Nil).Return(false, nil).Once()
+		repo.On("FetchCategoryById", electronics.CategoryId).Return(&electronics, nil).Once()
+		repo.On("InsertCategory", &cat).Return(&models.Category{CategoryId: uuid.New(), Name: "Drones"}, nil).Once()
+
+		created, err := c.CreateCategory(cat)
+		require.NoError(t, err)
+		assert.Equal(t, "Drones", created.Name)
+	})
+
+	t.Run("Name taken", func(t *testing.T) {
+		repo.On("CategoryNameExists", "cameras", uuid.Nil).Return(true, nil).Once()
+
+		_, err := c.CreateCategory(models.Category{Name: "cameras"})
+		assert.ErrorIs(t, err, categories.ErrCategoryExists)
+	})
+
+	t.Run("Missing parent", func(t *testing.T) {
+		parent := uuid.New()
+		repo.On("CategoryNameExists", "Drones", uuid.Nil).Return(false, nil).Once()
+		repo.On("FetchCategoryById", parent).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := c.CreateCategory(models.Category{Name: "Drones", ParentId: uuid.NullUUID{UUID: parent, Valid: true}})
+		assert.ErrorIs(t, err, categories.ErrParentNotFound)
+	})
+}
+
+func TestUpdateCategory(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	c := usecase.NewCategoriesUC(repo)
+
+	t.Run("Category is moved", func(t *testing.T) {
+		cat := models.Category{Name: "Lenses", ParentId: uuid.NullUUID{UUID: electronics.CategoryId, Valid: true}}
+		repo.On("CategoryNameExists", "Lenses", lenses.CategoryId).Return(false, nil).Once()
+		repo.On("FetchCategories").Return(all, nil).Once()
+		repo.On("UpdateCategory", mock.MatchedBy(func(c *models.Category) bool {
+			return c.CategoryId == lenses.CategoryId && c.ParentId == cat.ParentId
+		})).Return(&lenses, nil).Once()
+
+		_, err := c.UpdateCategory(lenses.CategoryId, cat)
+		require.NoError(t, err)
+	})
+
+	t.Run("Category cannot move below itself", func(t *testing.T) {
+		cat := models.Category{Name: "Electronics", ParentId: uuid.NullUUID{UUID: lenses.CategoryId, Valid: true}}
+		repo.On("CategoryNameExists", "Electronics", electronics.CategoryId).Return(false, nil).Once()
+		repo.On("FetchCategories").Return(all, nil).Once()
+
+		_, err := c.UpdateCategory(electronics.CategoryId, cat)
+		assert.ErrorIs(t, err, categories.ErrCategoryCycle)
+
+		cat.ParentId.UUID = electronics.CategoryId
+		repo.On("CategoryNameExists", "Electronics", electronics.CategoryId).Return(false, nil).Once()
+		repo.On("FetchCategories").Return(all, nil).Once()
+
+		_, err = c.UpdateCategory(electronics.CategoryId, cat)
+		assert.ErrorIs(t, err, categories.ErrCategoryCycle)
+	})
+
+	t.Run("Missing category", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("CategoryNameExists", "Toys", id).Return(false, nil).Once()
+		repo.On("UpdateCategory", mock.Anything).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := c.UpdateCategory(id, models.Category{Name: "Toys"})
+		assert.ErrorIs(t, err, categories.ErrCategoryNotFound)
+	})
+}
+
+func TestDeleteCategory(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	c := usecase.NewCategoriesUC(repo)
+
+	t.Run("Unused category is deleted", func(t *testing.T) {
+		repo.On("CategoryInUse", books.CategoryId).Return(false, nil).Once()
+		repo.On("DeleteCategory", books.CategoryId).Return(nil).Once()
+
+		assert.NoError(t, c.DeleteCategory(books.CategoryId))
+	})
+
+	t.Run("Category in use", func(t *testing.T) {
+		repo.On("CategoryInUse", electronics.CategoryId).Return(true, nil).Once()
+
+		assert.ErrorIs(t, c.DeleteCategory(electronics.CategoryId), categories.ErrCategoryInUse)
+	})
+}
+
+func TestBulkUpdateCategories(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	c := usecase.NewCategoriesUC(repo)
+	hidden := true
+	summary := &models.CategoryBulkSummary{Categories: 1, Products: 4}
+
+	t.Run("Named categories only", func(t *testing.T) {
+		u := models.CategoryBulkUpdate{Categories: []uuid.UUID{cameras.CategoryId, books.CategoryId}, Hidden: &hidden}
+		repo.On("FetchCategories").Return(all, nil).Once()
+		repo.On("BulkUpdateCategories", []uuid.UUID{books.CategoryId, cameras.CategoryId}, u).Return(summary, nil).Once()
+
+		got, err := c.BulkUpdateCategories(u)
+		require.NoError(t, err)
+		assert.Equal(t, summary, got)
+	})
+
+	t.Run("Subcategories are included", func(t *testing.T) {
+		u := models.CategoryBulkUpdate{Categories: []uuid.UUID{electronics.CategoryId}, IncludeSubcategories: true, Hidden: &hidden}
+		repo.On("FetchCategories").Return(all, nil).Once()
+		repo.On("BulkUpdateCategories", []uuid.UUID{cameras.CategoryId, electronics.CategoryId, lenses.CategoryId}, u).
+			Return(summary, nil).Once()
+
+		_, err := c.BulkUpdateCategories(u)
+		require.NoError(t, err)
+	})
+
+	t.Run("Missing category", func(t *testing.T) {
+		repo.On("FetchCategories").Return(all, nil).Once()
+
+		_, err := c.BulkUpdateCategories(models.CategoryBulkUpdate{Categories: []uuid.UUID{books.CategoryId, uuid.New()}, Hidden: &hidden})
+		assert.ErrorIs(t, err, categories.ErrCategoryNotFound)
+	})
+}
@@ -0,0 +1,172 @@
+// Package delivery provides HTTP handlers for checkout sessions.
+//
+// A session locks the prices of a cart while the customer pays; the payment and
+// order endpoints accept its id to charge the locked totals.
+package delivery
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/checkout"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/eta"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// CheckoutHandlers provides HTTP handler methods for checkout endpoints.
+type CheckoutHandlers struct {
+	logger     logger.Logger
+	checkoutUC checkout.CheckoutUC
+	estimator  *eta.Estimator
+}
+
+// NewCheckoutHandlers returns a new CheckoutHandlers.
+func NewCheckoutHandlers(logger logger.Logger, checkoutUC checkout.CheckoutUC, estimator *eta.Estimator) *CheckoutHandlers {
+	return &CheckoutHandlers{
+		logger:     logger,
+		checkoutUC: checkoutUC,
+		estimator:  estimator,
+	}
+}
+
+type sessionResponse struct {
+	Success bool                    `json:"success"`
+	Session *models.CheckoutSession `json:"session"`
+}
+
+// CreateSession prices the cart and locks its prices.
+// Endpoint: POST /api/v1/checkout/session
+// Expects JSON body: {"orderItems": [{"product": <id>, "variant": <id>, "quantity": <int>}], "shippingPrice": <money>, "taxPrice": <money>};
+// variant is required for products with variants. The session is priced in the currency of the
+// X-Currency header, else of the user preference, else of the shop.
+func (h *CheckoutHandlers) CreateSession(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	var req sessionRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	currency, err := exchange.Currency(r)
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error creating checkout session: %v", err)
+		return
+	}
+
+	session := models.CheckoutSession{
+		UserID:        user.ID,
+		Currency:      currency,
+		ShippingPrice: req.ShippingPrice,
+		TaxPrice:      req.TaxPrice,
+	}
+
+	for _, i := range req.OrderItems {
+		var variant uuid.NullUUID
+		if i.Variant != "" {
+			variant = uuid.NullUUID{UUID: uuid.MustParse(i.Variant), Valid: true}
+		}
+
+		session.Items = append(session.Items, &models.CheckoutItem{
+			ProductID: uuid.MustParse(i.Product),
+			VariantID: variant,
+			Quantity:  i.Quantity,
+		})
+	}
+
+	s, err := h.checkoutUC.CreateSession(r.Context(), session)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = utils.BadRequest(w, r, errors.New("product not found"))
+			h.logger.Errorf("error creating checkout session: %v", err)
+			return
+		}
+		if checkout.IsClientError(err) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error creating checkout session: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error creating checkout session: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusCreated, sessionResponse{Success: true, Session: s})
+}
+
+// GetSession returns a checkout session of the current user.
+// Endpoint: GET /api/v1/checkout/session/{id}
+func (h *CheckoutHandlers) GetSession(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	s, err := h.checkoutUC.GetSession(r.Context(), id, user.ID)
+	if err != nil {
+		if checkout.IsClientError(err) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error fetching checkout session: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error fetching checkout session: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, sessionResponse{Success: true, Session: s})
+}
+
+// GetDeliveryEstimate returns the estimated delivery window for a destination,
+// for one shipping method or, without one, for every method offered.
+// Endpoint: GET /api/v1/checkout/eta?country=<country>&method=<method>
+func (h *CheckoutHandlers) GetDeliveryEstimate(w http.ResponseWriter, r *http.Request) {
+	country := r.URL.Query().Get("country")
+	method := r.URL.Query().Get("method")
+
+	v := validator.New()
+	v.CheckCode(country != "", "country", validator.CodeRequired, "must be provided")
+	if !v.Valid() {
+		utils.FailedValidation(w, r, v)
+		return
+	}
+
+	var estimates []models.DeliveryEstimate
+	if method == "" {
+		estimates = h.estimator.EstimateAll(country, time.Now())
+	} else {
+		est, err := h.estimator.Estimate(method, country, time.Now())
+		if err != nil {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error estimating delivery: %v", err)
+			return
+		}
+		estimates = append(estimates, *est)
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success   bool                      `json:"success"`
+		Estimates []models.DeliveryEstimate `json:"estimates"`
+	}{Success: true, Estimates: estimates})
+}
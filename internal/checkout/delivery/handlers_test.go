@@ -0,0 +1,167 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/checkout"
+	"github.com/jofosuware/go/shopit/internal/checkout/delivery"
+	"github.com/jofosuware/go/shopit/internal/checkout/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/eta"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newEstimator(t *testing.T) *eta.Estimator {
+	e, err := eta.New(config.Delivery{
+		Warehouse:     "GH",
+		HandlingDays:  1,
+		DefaultMethod: "standard",
+		Methods: map[string]config.DeliveryMethod{
+			"standard": {Domestic: "2-4", International: "7-14"},
+			"express":  {Domestic: "1", International: "3-5"},
+		},
+	})
+	require.NoError(t, err)
+	return e
+}
+
+func TestCreateSession(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	checkoutUC := mocks.NewCheckoutUC(t)
+
+	h := delivery.NewCheckoutHandlers(logger, checkoutUC, newEstimator(t))
+
+	user := models.User{ID: uuid.New()}
+	prodID := uuid.New()
+	newRequest := func(t *testing.T, body string) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, "/checkout/session", bytes.NewBufferString(body))
+		require.NoError(t, err)
+
+		return req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, &user))
+	}
+	cart := fmt.Sprintf(`{"orderItems":[{"product":%q,"quantity":2}],"shippingPrice":"10.00","taxPrice":{"amount":500,"currency":"USD"}}`, prodID)
+
+	t.Run("Session is created", func(t *testing.T) {
+		checkoutUC.On("CreateSession", mock.Anything, mock.MatchedBy(func(s models.CheckoutSession) bool {
+			return s.UserID == user.ID && s.Currency == "USD" && s.ShippingPrice == money.Of(1000) && s.TaxPrice == money.Of(500) &&
+				len(s.Items) == 1 && s.Items[0].ProductID == prodID && s.Items[0].Quantity == 2
+		})).Return(&models.CheckoutSession{ID: uuid.New(), TotalPrice: money.Of(21500)}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.CreateSession(rr, newRequest(t, cart))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Session in the currency of the header", func(t *testing.T) {
+		require.NoError(t, money.Accept("EUR"))
+		t.Cleanup(func() { money.Accept() })
+
+		checkoutUC.On("CreateSession", mock.Anything, mock.MatchedBy(func(s models.CheckoutSession) bool {
+			return s.Currency == "EUR"
+		})).Return(&models.CheckoutSession{ID: uuid.New()}, nil).Once()
+
+		req := newRequest(t, cart)
+		req.Header.Set("X-Currency", "eur")
+		rr := httptest.NewRecorder()
+		h.CreateSession(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Currency not accepted", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		req := newRequest(t, cart)
+		req.Header.Set("X-Currency", "GBP")
+		rr := httptest.NewRecorder()
+		h.CreateSession(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid cart", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.CreateSession(rr, newRequest(t, `{"orderItems":[{"product":"abc","quantity":0}]}`))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Out of stock", func(t *testing.T) {
+		checkoutUC.On("CreateSession", mock.Anything, mock.Anything).Return(nil, checkout.ErrOutOfStock).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.CreateSession(rr, newRequest(t, cart))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestGetDeliveryEstimate(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	checkoutUC := mocks.NewCheckoutUC(t)
+
+	h := delivery.NewCheckoutHandlers(logger, checkoutUC, newEstimator(t))
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, "/checkout/eta?"+query, nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		h.GetDeliveryEstimate(rr, req)
+		return rr
+	}
+
+	t.Run("All methods", func(t *testing.T) {
+		rr := get("country=GH")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Estimates []models.DeliveryEstimate `json:"estimates"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Estimates, 2)
+		assert.Equal(t, "express", resp.Estimates[0].Method)
+		assert.Equal(t, 2, resp.Estimates[0].MaxDays)
+	})
+
+	t.Run("One method", func(t *testing.T) {
+		rr := get("country=US&method=standard")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Estimates []models.DeliveryEstimate `json:"estimates"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Estimates, 1)
+		assert.Equal(t, 8, resp.Estimates[0].MinDays)
+	})
+
+	t.Run("Missing country", func(t *testing.T) {
+		rr := get("")
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Unknown method", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := get("country=GH&method=drone")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
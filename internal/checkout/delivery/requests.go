@@ -0,0 +1,27 @@
+package delivery
+
+import (
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// sessionRequest is the body of CreateSession.
+type sessionRequest struct {
+	OrderItems    []sessionItemRequest `json:"orderItems" validate:"min=1,dive"`
+	ShippingPrice money.Money          `json:"shippingPrice"`
+	TaxPrice      money.Money          `json:"taxPrice"`
+}
+
+// sessionItemRequest is an item of a sessionRequest. The variant is required for
+// products with variants.
+type sessionItemRequest struct {
+	Product  string `json:"product" validate:"uuid"`
+	Variant  string `json:"variant" validate:"omitempty,uuid"`
+	Quantity int    `json:"quantity" validate:"min=1"`
+}
+
+func (req *sessionRequest) Validate(v *validator.Validator) {
+	v.CheckCode(!req.ShippingPrice.IsNegative(), "shippingPrice", validator.CodeTooSmall,
+		"shipping price must not be negative")
+	v.CheckCode(!req.TaxPrice.IsNegative(), "taxPrice", validator.CodeTooSmall, "tax price must not be negative")
+}
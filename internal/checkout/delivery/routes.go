@@ -0,0 +1,25 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// CheckoutRouter returns a chi.Router with the checkout session routes.
+//
+//   - POST /session      → Lock the prices of a cart
+//   - GET  /session/{id} → Get a checkout session
+//   - GET  /eta          → Estimate delivery windows for a destination
+func (h *CheckoutHandlers) CheckoutRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+
+	mux.Post("/session", h.CreateSession)
+	mux.Get("/session/{id}", h.GetSession)
+	mux.Get("/eta", h.GetDeliveryEstimate)
+
+	return mux
+}
@@ -0,0 +1,44 @@
+package checkout
+
+import "errors"
+
+var (
+	// ErrEmptyCart is returned when a session is created without items.
+	ErrEmptyCart = errors.New("cart is empty")
+
+	// ErrOutOfStock is returned when a cart asks for more than a product's stock.
+	ErrOutOfStock = errors.New("product is out of stock")
+
+	// ErrVariantNotFound is returned when a cart line names a variant its product does not have.
+	ErrVariantNotFound = errors.New("product variant not found")
+
+	// ErrVariantRequired is returned when a product with variants is added to a cart without one.
+	ErrVariantRequired = errors.New("product must be ordered as one of its variants")
+
+	// ErrSessionNotFound is returned when a session does not exist or belongs to another user.
+	ErrSessionNotFound = errors.New("checkout session not found")
+
+	// ErrSessionExpired is returned when the price lock of a session has run out.
+	ErrSessionExpired = errors.New("checkout session expired")
+
+	// ErrSessionClosed is returned when a session was already used to place an order.
+	ErrSessionClosed = errors.New("checkout session already completed")
+
+	// ErrPaymentMismatch is returned when an order is paid with another payment intent than its session's.
+	ErrPaymentMismatch = errors.New("payment does not belong to the checkout session")
+
+	// ErrCreditSpent is returned when the store credit applied to a session was spent elsewhere.
+	ErrCreditSpent = errors.New("store credit of the checkout session is no longer available")
+)
+
+// IsClientError reports whether err is caused by the request rather than the server.
+func IsClientError(err error) bool {
+	for _, e := range []error{ErrEmptyCart, ErrOutOfStock, ErrVariantNotFound, ErrVariantRequired, ErrSessionNotFound, ErrSessionExpired,
+		ErrSessionClosed, ErrPaymentMismatch, ErrCreditSpent} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+
+	return false
+}
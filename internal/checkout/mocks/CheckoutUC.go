@@ -0,0 +1,233 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	money "github.com/jofosuware/go/shopit/pkg/money"
+
+	uuid "github.com/google/uuid"
+)
+
+// CheckoutUC is an autogenerated mock type for the CheckoutUC type
+type CheckoutUC struct {
+	mock.Mock
+}
+
+// AttachPayment provides a mock function with given fields: ctx, id, intentID
+func (_m *CheckoutUC) AttachPayment(ctx context.Context, id uuid.UUID, intentID string) error {
+	ret := _m.Called(ctx, id, intentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AttachPayment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, id, intentID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Claim provides a mock function with given fields: ctx, id, userID, paymentID
+func (_m *CheckoutUC) Claim(ctx context.Context, id uuid.UUID, userID uuid.UUID, paymentID string) (*models.CheckoutSession, error) {
+	ret := _m.Called(ctx, id, userID, paymentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Claim")
+	}
+
+	var r0 *models.CheckoutSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) (*models.CheckoutSession, error)); ok {
+		return rf(ctx, id, userID, paymentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) *models.CheckoutSession); ok {
+		r0 = rf(ctx, id, userID, paymentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CheckoutSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = rf(ctx, id, userID, paymentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Complete provides a mock function with given fields: ctx, id, orderID
+func (_m *CheckoutUC) Complete(ctx context.Context, id uuid.UUID, orderID uuid.UUID) error {
+	ret := _m.Called(ctx, id, orderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Complete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, id, orderID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateSession provides a mock function with given fields: ctx, session
+func (_m *CheckoutUC) CreateSession(ctx context.Context, session models.CheckoutSession) (*models.CheckoutSession, error) {
+	ret := _m.Called(ctx, session)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSession")
+	}
+
+	var r0 *models.CheckoutSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.CheckoutSession) (*models.CheckoutSession, error)); ok {
+		return rf(ctx, session)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.CheckoutSession) *models.CheckoutSession); ok {
+		r0 = rf(ctx, session)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CheckoutSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.CheckoutSession) error); ok {
+		r1 = rf(ctx, session)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExpireSessions provides a mock function with given fields: ctx
+func (_m *CheckoutUC) ExpireSessions(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpireSessions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSession provides a mock function with given fields: ctx, id, userID
+func (_m *CheckoutUC) GetSession(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.CheckoutSession, error) {
+	ret := _m.Called(ctx, id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSession")
+	}
+
+	var r0 *models.CheckoutSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (*models.CheckoutSession, error)); ok {
+		return rf(ctx, id, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) *models.CheckoutSession); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CheckoutSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PriceItems provides a mock function with given fields: ctx, currency, items
+func (_m *CheckoutUC) PriceItems(ctx context.Context, currency string, items []*models.CheckoutItem) (money.Money, error) {
+	ret := _m.Called(ctx, currency, items)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PriceItems")
+	}
+
+	var r0 money.Money
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*models.CheckoutItem) (money.Money, error)); ok {
+		return rf(ctx, currency, items)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*models.CheckoutItem) money.Money); ok {
+		r0 = rf(ctx, currency, items)
+	} else {
+		r0 = ret.Get(0).(money.Money)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []*models.CheckoutItem) error); ok {
+		r1 = rf(ctx, currency, items)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: ctx, id
+func (_m *CheckoutUC) Release(ctx context.Context, id uuid.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCheckoutUC creates a new instance of CheckoutUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCheckoutUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CheckoutUC {
+	mock := &CheckoutUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,47 @@
+package checkout
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// FetchProduct fetches the name, price and stock of a product, returns an error on failure
+	FetchProduct(ctx context.Context, productID uuid.UUID) (*models.Product, error)
+
+	// FetchVariants fetches the price delta and stock of the variants of a product, returns an error on failure
+	FetchVariants(ctx context.Context, productID uuid.UUID) ([]models.Variant, error)
+
+	// InsertSession inserts a checkout session, returns the session and an error on failure
+	InsertSession(ctx context.Context, s models.CheckoutSession) (*models.CheckoutSession, error)
+
+	// InsertSessionItem inserts a locked cart line of a session, returns an error on failure
+	InsertSessionItem(ctx context.Context, sessionID uuid.UUID, item models.CheckoutItem) error
+
+	// FetchSessionById fetches a checkout session without its items, returns an error on failure
+	FetchSessionById(ctx context.Context, id uuid.UUID) (*models.CheckoutSession, error)
+
+	// FetchSessionItems fetches the locked cart lines of a session, returns an error on failure
+	FetchSessionItems(ctx context.Context, id uuid.UUID) ([]*models.CheckoutItem, error)
+
+	// DeleteSessionById deletes a session and its items, returns an error on failure
+	DeleteSessionById(ctx context.Context, id uuid.UUID) error
+
+	// UpdatePaymentIntent records the payment intent created for a session, returns an error on failure
+	UpdatePaymentIntent(ctx context.Context, id uuid.UUID, intentID string) error
+
+	// ClaimSession marks an open, unexpired session completed, returns sql.ErrNoRows when it was not
+	ClaimSession(ctx context.Context, id uuid.UUID, now time.Time) error
+
+	// ReleaseSession reopens a claimed session that has no order, returns an error on failure
+	ReleaseSession(ctx context.Context, id uuid.UUID) error
+
+	// UpdateSessionOrder records the order placed with a session, returns an error on failure
+	UpdateSessionOrder(ctx context.Context, id, orderID uuid.UUID) error
+
+	// ExpireSessions marks open sessions past their expiry expired, returns how many were
+	ExpireSessions(ctx context.Context, now time.Time) (int64, error)
+}
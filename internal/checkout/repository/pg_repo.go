@@ -0,0 +1,281 @@
+// Package repository provides persistence for checkout sessions.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// CheckoutRepository handles checkout session database operations.
+type CheckoutRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewCheckoutRepository returns a new CheckoutRepository.
+func NewCheckoutRepository(db *sql.DB) *CheckoutRepository {
+	return &CheckoutRepository{
+		DB: db,
+	}
+}
+
+// FetchProduct fetches the name, price and stock of a product, the price in the
+// product currency.
+func (r *CheckoutRepository) FetchProduct(ctx context.Context, productID uuid.UUID) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select product_id, name, price, currency, stock from products where product_id = $1`
+
+	var p models.Product
+	err := r.DB.QueryRowContext(ctx, query, productID).Scan(
+		&p.ProductId,
+		&p.Name,
+		&p.Price,
+		&p.Price.Currency,
+		&p.Stock,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// FetchVariants fetches the attributes, price delta and stock of the variants of a
+// product, the price delta in the product currency.
+func (r *CheckoutRepository) FetchVariants(ctx context.Context, productID uuid.UUID) ([]models.Variant, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select v.variant_id, v.product_id, v.attributes, v.price_delta, p.currency, v.stock
+				from product_variants v join products p on p.product_id = v.product_id where v.product_id = $1`
+
+	rows, err := r.DB.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []models.Variant
+	for rows.Next() {
+		var (
+			v     models.Variant
+			attrs []byte
+		)
+		if err := rows.Scan(&v.VariantId, &v.ProductId, &attrs, &v.PriceDelta, &v.PriceDelta.Currency, &v.Stock); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(attrs, &v.Attributes); err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+// InsertSession inserts a checkout session.
+func (r *CheckoutRepository) InsertSession(ctx context.Context, s models.CheckoutSession) (*models.CheckoutSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `insert into checkout_sessions (user_id, currency, item_price, tax_price, shipping_price,
+				credit_applied, total_price, status, expires_at, created_at)
+				values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+				returning session_id, created_at`
+
+	err := r.DB.QueryRowContext(ctx, query,
+		s.UserID,
+		s.Currency,
+		s.ItemsPrice,
+		s.TaxPrice,
+		s.ShippingPrice,
+		s.CreditApplied,
+		s.TotalPrice,
+		s.Status,
+		s.ExpiresAt,
+		time.Now(),
+	).Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// InsertSessionItem inserts a locked cart line of a session.
+func (r *CheckoutRepository) InsertSessionItem(ctx context.Context, sessionID uuid.UUID, item models.CheckoutItem) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `insert into checkout_session_items (session_id, product_id, variant_id, name, price, quantity)
+				values ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.DB.ExecContext(ctx, query, sessionID, item.ProductID, item.VariantID, item.Name, item.Price, item.Quantity)
+
+	return err
+}
+
+// FetchSessionById fetches a checkout session without its items.
+func (r *CheckoutRepository) FetchSessionById(ctx context.Context, id uuid.UUID) (*models.CheckoutSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select session_id, user_id, currency, item_price, tax_price, shipping_price, credit_applied,
+				total_price, status, payment_intent_id, order_id, expires_at, created_at
+				from checkout_sessions where session_id = $1`
+
+	var s models.CheckoutSession
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(
+		&s.ID,
+		&s.UserID,
+		&s.Currency,
+		&s.ItemsPrice,
+		&s.TaxPrice,
+		&s.ShippingPrice,
+		&s.CreditApplied,
+		&s.TotalPrice,
+		&s.Status,
+		&s.PaymentIntentID,
+		&s.OrderID,
+		&s.ExpiresAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.UseCurrency(s.Currency)
+
+	return &s, nil
+}
+
+// FetchSessionItems fetches the locked cart lines of a session, priced in the session
+// currency.
+func (r *CheckoutRepository) FetchSessionItems(ctx context.Context, id uuid.UUID) ([]*models.CheckoutItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select i.product_id, i.variant_id, i.name, i.price, s.currency, i.quantity
+				from checkout_session_items i join checkout_sessions s on s.session_id = i.session_id
+				where i.session_id = $1`
+
+	rows, err := r.DB.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.CheckoutItem
+	for rows.Next() {
+		var i models.CheckoutItem
+		if err := rows.Scan(&i.ProductID, &i.VariantID, &i.Name, &i.Price, &i.Price.Currency, &i.Quantity); err != nil {
+			return nil, err
+		}
+
+		items = append(items, &i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// DeleteSessionById deletes a session; its items are removed by the foreign key cascade.
+func (r *CheckoutRepository) DeleteSessionById(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, `delete from checkout_sessions where session_id = $1`, id)
+
+	return err
+}
+
+// UpdatePaymentIntent records the payment intent created for a session.
+func (r *CheckoutRepository) UpdatePaymentIntent(ctx context.Context, id uuid.UUID, intentID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update checkout_sessions set payment_intent_id = $1 where session_id = $2`
+
+	_, err := r.DB.ExecContext(ctx, query, intentID, id)
+
+	return err
+}
+
+// ClaimSession marks an open session completed unless it expired before now.
+// It returns sql.ErrNoRows when no session was claimed.
+func (r *CheckoutRepository) ClaimSession(ctx context.Context, id uuid.UUID, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update checkout_sessions set status = $1 where session_id = $2 and status = $3 and expires_at > $4`
+
+	res, err := r.DB.ExecContext(ctx, query, models.CheckoutCompleted, id, models.CheckoutOpen, now)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReleaseSession reopens a claimed session that has no order.
+func (r *CheckoutRepository) ReleaseSession(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update checkout_sessions set status = $1 where session_id = $2 and status = $3 and order_id is null`
+
+	_, err := r.DB.ExecContext(ctx, query, models.CheckoutOpen, id, models.CheckoutCompleted)
+
+	return err
+}
+
+// UpdateSessionOrder records the order placed with a session.
+func (r *CheckoutRepository) UpdateSessionOrder(ctx context.Context, id, orderID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update checkout_sessions set order_id = $1 where session_id = $2`
+
+	_, err := r.DB.ExecContext(ctx, query, orderID, id)
+
+	return err
+}
+
+// ExpireSessions marks open sessions that expired before now and returns how many were.
+func (r *CheckoutRepository) ExpireSessions(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := `update checkout_sessions set status = $1 where status = $2 and expires_at <= $3`
+
+	res, err := r.DB.ExecContext(ctx, query, models.CheckoutExpired, models.CheckoutOpen, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,169 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/checkout/repository"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCheckoutRepository(db)
+
+	s := models.CheckoutSession{
+		UserID:        uuid.New(),
+		Currency:      "USD",
+		ItemsPrice:    money.Of(200),
+		TaxPrice:      money.Of(5),
+		ShippingPrice: money.Of(10),
+		TotalPrice:    money.Of(215),
+		Status:        models.CheckoutOpen,
+		ExpiresAt:     time.Now().Add(15 * time.Minute),
+	}
+	id := uuid.New()
+
+	mock.ExpectQuery(`insert into checkout_sessions`).
+		WithArgs(s.UserID, "USD", 200, 5, 10, 0, 215, models.CheckoutOpen, s.ExpiresAt, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "created_at"}).AddRow(id, time.Now()))
+
+	got, err := repo.InsertSession(context.Background(), s)
+	require.NoError(t, err)
+	assert.Equal(t, id, got.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchSessionById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCheckoutRepository(db)
+	query := regexp.QuoteMeta(`select session_id, user_id, currency, item_price, tax_price, shipping_price, credit_applied,
+				total_price, status, payment_intent_id, order_id, expires_at, created_at
+				from checkout_sessions where session_id = $1`)
+
+	t.Run("Session without an order", func(t *testing.T) {
+		id, userID := uuid.New(), uuid.New()
+		rows := sqlmock.NewRows([]string{"session_id", "user_id", "currency", "item_price", "tax_price", "shipping_price",
+			"credit_applied", "total_price", "status", "payment_intent_id", "order_id", "expires_at", "created_at"}).
+			AddRow(id, userID, "EUR", 200, 5, 10, 0, 215, models.CheckoutOpen, "", nil, time.Now(), time.Now())
+		mock.ExpectQuery(query).WithArgs(id).WillReturnRows(rows)
+
+		s, err := repo.FetchSessionById(context.Background(), id)
+		require.NoError(t, err)
+
+		assert.Equal(t, userID, s.UserID)
+		assert.Equal(t, "EUR", s.Currency)
+		assert.Equal(t, money.New(215, "EUR"), s.TotalPrice)
+		assert.False(t, s.OrderID.Valid)
+	})
+
+	t.Run("Missing session", func(t *testing.T) {
+		id := uuid.New()
+		mock.ExpectQuery(query).WithArgs(id).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.FetchSessionById(context.Background(), id)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestClaimSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCheckoutRepository(db)
+	query := regexp.QuoteMeta(`update checkout_sessions set status = $1 where session_id = $2 and status = $3 and expires_at > $4`)
+	id, now := uuid.New(), time.Now()
+
+	t.Run("Open session is claimed", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(models.CheckoutCompleted, id, models.CheckoutOpen, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.ClaimSession(context.Background(), id, now))
+	})
+
+	t.Run("Session already claimed", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(models.CheckoutCompleted, id, models.CheckoutOpen, now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.ClaimSession(context.Background(), id, now), sql.ErrNoRows)
+	})
+}
+
+func TestExpireSessions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCheckoutRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec(regexp.QuoteMeta(`update checkout_sessions set status = $1 where status = $2 and expires_at <= $3`)).
+		WithArgs(models.CheckoutExpired, models.CheckoutOpen, now).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	n, err := repo.ExpireSessions(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+}
+
+func TestFetchVariants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCheckoutRepository(db)
+
+	productID, variantID := uuid.New(), uuid.New()
+	mock.ExpectQuery(regexp.QuoteMeta(`select v.variant_id, v.product_id, v.attributes, v.price_delta, p.currency, v.stock
+				from product_variants v join products p on p.product_id = v.product_id where v.product_id = $1`)).
+		WithArgs(productID).
+		WillReturnRows(sqlmock.NewRows([]string{"variant_id", "product_id", "attributes", "price_delta", "currency", "stock"}).
+			AddRow(variantID, productID, []byte(`{"size": "M"}`), 5, "EUR", 2))
+
+	variants, err := repo.FetchVariants(context.Background(), productID)
+	require.NoError(t, err)
+	require.Len(t, variants, 1)
+	assert.Equal(t, variantID, variants[0].VariantId)
+	assert.Equal(t, "M", variants[0].Label())
+	assert.Equal(t, money.New(5, "EUR"), variants[0].PriceDelta)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchSessionItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCheckoutRepository(db)
+
+	sessionID, productID, variantID := uuid.New(), uuid.New(), uuid.New()
+	mock.ExpectQuery(regexp.QuoteMeta(`select i.product_id, i.variant_id, i.name, i.price, s.currency, i.quantity
+				from checkout_session_items i join checkout_sessions s on s.session_id = i.session_id
+				where i.session_id = $1`)).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "variant_id", "name", "price", "currency", "quantity"}).
+			AddRow(productID, variantID, "Shirt (M)", 25, "USD", 1).
+			AddRow(productID, nil, "Shirt", 20, "USD", 2))
+
+	items, err := repo.FetchSessionItems(context.Background(), sessionID)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, uuid.NullUUID{UUID: variantID, Valid: true}, items[0].VariantID)
+	assert.False(t, items[1].VariantID.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
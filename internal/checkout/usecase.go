@@ -0,0 +1,34 @@
+package checkout
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+type CheckoutUC interface {
+	// CreateSession locks the current prices of the session's items, returns the priced session
+	CreateSession(ctx context.Context, session models.CheckoutSession) (*models.CheckoutSession, error)
+
+	// PriceItems prices items from the catalog in currency, returns their total
+	PriceItems(ctx context.Context, currency string, items []*models.CheckoutItem) (money.Money, error)
+
+	// GetSession returns a session of a user with its items
+	GetSession(ctx context.Context, id, userID uuid.UUID) (*models.CheckoutSession, error)
+
+	// AttachPayment records the payment intent charging a session, returns an error on failure
+	AttachPayment(ctx context.Context, id uuid.UUID, intentID string) error
+
+	// Claim reserves an open session of a user for an order paid with paymentID
+	Claim(ctx context.Context, id, userID uuid.UUID, paymentID string) (*models.CheckoutSession, error)
+
+	// Release reopens a claimed session after placing its order failed
+	Release(ctx context.Context, id uuid.UUID) error
+
+	// Complete records the order placed with a claimed session
+	Complete(ctx context.Context, id, orderID uuid.UUID) error
+
+	// ExpireSessions expires open sessions whose price lock ran out, returns how many were
+	ExpireSessions(ctx context.Context) (int64, error)
+}
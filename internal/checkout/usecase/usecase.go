@@ -0,0 +1,371 @@
+// Package usecase implements checkout sessions.
+//
+// A session prices a cart from the catalog and locks those prices for a while, so a
+// product price change between paying and placing the order is not charged to the
+// customer. The payment intent is created for the locked total and the order is
+// placed with the locked prices; a session can only be used for one order.
+//
+// The store credit of the customer is taken off the total when the session is
+// created and spent when the order is placed, so a session that expires unused
+// leaves the credit untouched.
+//
+// A session is priced in the currency the customer pays in, converting catalog prices
+// at the exchange rates of the moment; the locked prices keep those rates. Store
+// credit is kept in the shop currency, so it only pays sessions in that currency.
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/checkout"
+	"github.com/jofosuware/go/shopit/internal/credit"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultLockDuration is how long a session keeps its prices.
+const DefaultLockDuration = 15 * time.Minute
+
+// CheckoutUC provides checkout session use cases.
+type CheckoutUC struct {
+	repo    checkout.Repo
+	credits credit.Repo
+	rates   *exchange.Converter
+	lock    time.Duration
+	now     func() time.Time
+}
+
+// NewCheckoutUC returns a new CheckoutUC. A non-positive lock falls back to DefaultLockDuration.
+func NewCheckoutUC(repo checkout.Repo, credits credit.Repo, rates *exchange.Converter, lock time.Duration) *CheckoutUC {
+	if lock <= 0 {
+		lock = DefaultLockDuration
+	}
+
+	return &CheckoutUC{
+		repo:    repo,
+		credits: credits,
+		rates:   rates,
+		lock:    lock,
+		now:     time.Now,
+	}
+}
+
+// CreateSession prices the items of session from the catalog in the session currency,
+// the shop currency when it has none, adds the shipping and tax prices, takes the store
+// credit of the user off the total and saves the session with its prices locked until
+// the lock duration has passed.
+func (c *CheckoutUC) CreateSession(ctx context.Context, session models.CheckoutSession) (_ *models.CheckoutSession, err error) {
+	ctx, span := tracing.Start(ctx, "CheckoutUC.CreateSession")
+	defer tracing.End(span, &err)
+
+	if len(session.Items) == 0 {
+		return nil, checkout.ErrEmptyCart
+	}
+
+	if session.Currency == "" {
+		session.Currency = money.DefaultCurrency
+	}
+	if !money.Supported(session.Currency) {
+		return nil, fmt.Errorf("%w: %s", money.ErrUnsupportedCurrency, session.Currency)
+	}
+
+	// merge lines of the same product and variant
+	type lineKey struct {
+		product uuid.UUID
+		variant uuid.NullUUID
+	}
+	var items []*models.CheckoutItem
+	lines := make(map[lineKey]*models.CheckoutItem)
+	for _, i := range session.Items {
+		key := lineKey{i.ProductID, i.VariantID}
+		if line, ok := lines[key]; ok {
+			line.Quantity += i.Quantity
+			continue
+		}
+		line := &models.CheckoutItem{ProductID: i.ProductID, VariantID: i.VariantID, Quantity: i.Quantity}
+		lines[key] = line
+		items = append(items, line)
+	}
+
+	if session.ItemsPrice, err = c.PriceItems(ctx, session.Currency, items); err != nil {
+		return nil, err
+	}
+
+	if session.ShippingPrice, err = c.convert(session.ShippingPrice, session.Currency); err != nil {
+		return nil, err
+	}
+	if session.TaxPrice, err = c.convert(session.TaxPrice, session.Currency); err != nil {
+		return nil, err
+	}
+
+	session.Items = nil
+	session.TotalPrice = session.ItemsPrice.Add(session.ShippingPrice).Add(session.TaxPrice)
+	session.CreditApplied = money.New(0, session.Currency)
+	if session.Currency == money.DefaultCurrency {
+		balance, err := c.credits.FetchBalance(session.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching store credit: %v", err)
+		}
+		session.CreditApplied = money.Min(money.Max(balance, money.Of(0)), session.TotalPrice)
+	}
+	session.TotalPrice = session.TotalPrice.Sub(session.CreditApplied)
+	session.Status = models.CheckoutOpen
+	session.ExpiresAt = c.now().Add(c.lock)
+
+	s, err := c.repo.InsertSession(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("error saving checkout session: %v", err)
+	}
+
+	for _, i := range items {
+		if err := c.repo.InsertSessionItem(ctx, s.ID, *i); err != nil {
+			if delErr := c.repo.DeleteSessionById(ctx, s.ID); delErr != nil {
+				return nil, fmt.Errorf("error deleting checkout session: %v", delErr)
+			}
+			return nil, fmt.Errorf("error saving checkout item: %v", err)
+		}
+	}
+
+	s.Items = items
+
+	return s, nil
+}
+
+// PriceItems sets the name and price of each of items from the catalog, converting
+// prices to currency, and returns their total. It returns checkout.ErrVariantRequired,
+// checkout.ErrVariantNotFound or checkout.ErrOutOfStock when a line cannot be sold as
+// asked, and a wrapped sql.ErrNoRows when its product does not exist.
+func (c *CheckoutUC) PriceItems(ctx context.Context, currency string, items []*models.CheckoutItem) (_ money.Money, err error) {
+	ctx, span := tracing.Start(ctx, "CheckoutUC.PriceItems")
+	defer tracing.End(span, &err)
+
+	total := money.New(0, currency)
+	for _, i := range items {
+		p, err := c.repo.FetchProduct(ctx, i.ProductID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return money.Money{}, fmt.Errorf("product %s: %w", i.ProductID, sql.ErrNoRows)
+			}
+			return money.Money{}, fmt.Errorf("error fetching product: %v", err)
+		}
+
+		variants, err := c.repo.FetchVariants(ctx, i.ProductID)
+		if err != nil {
+			return money.Money{}, fmt.Errorf("error fetching variants: %v", err)
+		}
+
+		name, price, stock := p.Name, p.Price, p.Stock
+		if i.VariantID.Valid || len(variants) > 0 {
+			v := findVariant(variants, i.VariantID)
+			if v == nil {
+				if !i.VariantID.Valid {
+					return money.Money{}, fmt.Errorf("%s: %w", p.Name, checkout.ErrVariantRequired)
+				}
+				return money.Money{}, fmt.Errorf("%s: %w", p.Name, checkout.ErrVariantNotFound)
+			}
+			name = fmt.Sprintf("%s (%s)", p.Name, v.Label())
+			price = price.Add(v.PriceDelta)
+			stock = v.Stock
+		}
+
+		if i.Quantity > stock {
+			return money.Money{}, fmt.Errorf("%s: %w", name, checkout.ErrOutOfStock)
+		}
+
+		price, err = c.convert(price, currency)
+		if err != nil {
+			return money.Money{}, err
+		}
+
+		i.Name = name
+		i.Price = price
+		total = total.Add(i.Price.Mul(i.Quantity))
+	}
+
+	return total, nil
+}
+
+// convert returns m in currency.
+func (c *CheckoutUC) convert(m money.Money, currency string) (money.Money, error) {
+	v, err := c.rates.Convert(m, currency)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("error converting prices to %s: %v", currency, err)
+	}
+
+	return v, nil
+}
+
+// findVariant returns the variant of variants with id, or nil when there is none.
+func findVariant(variants []models.Variant, id uuid.NullUUID) *models.Variant {
+	if !id.Valid {
+		return nil
+	}
+
+	for i := range variants {
+		if variants[i].VariantId == id.UUID {
+			return &variants[i]
+		}
+	}
+
+	return nil
+}
+
+// GetSession returns a session of userID with its items. An open session past its
+// expiry is reported expired even before the expiry job has run.
+func (c *CheckoutUC) GetSession(ctx context.Context, id, userID uuid.UUID) (_ *models.CheckoutSession, err error) {
+	ctx, span := tracing.Start(ctx, "CheckoutUC.GetSession", attribute.String("checkout.session.id", id.String()))
+	defer tracing.End(span, &err)
+
+	s, err := c.repo.FetchSessionById(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, checkout.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("error fetching checkout session: %v", err)
+	}
+
+	if s.UserID != userID {
+		return nil, checkout.ErrSessionNotFound
+	}
+
+	if s.Status == models.CheckoutOpen && !c.now().Before(s.ExpiresAt) {
+		s.Status = models.CheckoutExpired
+	}
+
+	items, err := c.repo.FetchSessionItems(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching checkout items: %v", err)
+	}
+	s.Items = items
+
+	return s, nil
+}
+
+// AttachPayment records the payment intent charging the session.
+func (c *CheckoutUC) AttachPayment(ctx context.Context, id uuid.UUID, intentID string) (err error) {
+	ctx, span := tracing.Start(ctx, "CheckoutUC.AttachPayment", attribute.String("checkout.session.id", id.String()))
+	defer tracing.End(span, &err)
+
+	if err := c.repo.UpdatePaymentIntent(ctx, id, intentID); err != nil {
+		return fmt.Errorf("error saving payment intent: %v", err)
+	}
+
+	return nil
+}
+
+// Claim reserves an open session of userID for the order paid with paymentID, so the
+// session cannot be used twice. Release it if the order cannot be placed.
+func (c *CheckoutUC) Claim(ctx context.Context, id, userID uuid.UUID, paymentID string) (_ *models.CheckoutSession, err error) {
+	ctx, span := tracing.Start(ctx, "CheckoutUC.Claim", attribute.String("checkout.session.id", id.String()))
+	defer tracing.End(span, &err)
+
+	s, err := c.GetSession(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch s.Status {
+	case models.CheckoutExpired:
+		return nil, checkout.ErrSessionExpired
+	case models.CheckoutCompleted:
+		return nil, checkout.ErrSessionClosed
+	}
+
+	if s.PaymentIntentID != "" && s.PaymentIntentID != paymentID {
+		return nil, checkout.ErrPaymentMismatch
+	}
+
+	if err := c.repo.ClaimSession(ctx, id, c.now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// another request claimed it, or it expired, since it was fetched
+			return nil, checkout.ErrSessionClosed
+		}
+		return nil, fmt.Errorf("error claiming checkout session: %v", err)
+	}
+
+	if s.CreditApplied.IsPositive() {
+		_, err := c.credits.InsertDebit(models.CreditEntry{
+			UserID:    userID,
+			Amount:    s.CreditApplied.Neg(),
+			Reason:    models.CreditCheckout,
+			SessionID: uuid.NullUUID{UUID: id, Valid: true},
+		})
+		if err != nil {
+			if relErr := c.repo.ReleaseSession(ctx, id); relErr != nil {
+				return nil, fmt.Errorf("error releasing checkout session: %v", relErr)
+			}
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, checkout.ErrCreditSpent
+			}
+			return nil, fmt.Errorf("error spending store credit: %v", err)
+		}
+	}
+
+	s.Status = models.CheckoutCompleted
+
+	return s, nil
+}
+
+// Release reopens a claimed session whose order could not be placed and gives back
+// the store credit it spent.
+func (c *CheckoutUC) Release(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "CheckoutUC.Release", attribute.String("checkout.session.id", id.String()))
+	defer tracing.End(span, &err)
+
+	s, err := c.repo.FetchSessionById(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error fetching checkout session: %v", err)
+	}
+
+	if err := c.repo.ReleaseSession(ctx, id); err != nil {
+		return fmt.Errorf("error releasing checkout session: %v", err)
+	}
+
+	// only a claimed session without an order has spent its credit
+	if s.CreditApplied.IsPositive() && s.Status == models.CheckoutCompleted && !s.OrderID.Valid {
+		_, err := c.credits.InsertCredit(models.CreditEntry{
+			UserID:    s.UserID,
+			Amount:    s.CreditApplied,
+			Reason:    models.CreditCheckoutReleased,
+			SessionID: uuid.NullUUID{UUID: id, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("error giving back store credit: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Complete records the order placed with a claimed session.
+func (c *CheckoutUC) Complete(ctx context.Context, id, orderID uuid.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "CheckoutUC.Complete", attribute.String("checkout.session.id", id.String()))
+	defer tracing.End(span, &err)
+
+	if err := c.repo.UpdateSessionOrder(ctx, id, orderID); err != nil {
+		return fmt.Errorf("error saving checkout order: %v", err)
+	}
+
+	return nil
+}
+
+// ExpireSessions expires the open sessions whose price lock ran out.
+func (c *CheckoutUC) ExpireSessions(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "CheckoutUC.ExpireSessions")
+	defer tracing.End(span, &err)
+
+	n, err := c.repo.ExpireSessions(ctx, c.now())
+	if err != nil {
+		return 0, fmt.Errorf("error expiring checkout sessions: %v", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,352 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/checkout"
+	"github.com/jofosuware/go/shopit/internal/checkout/mocks"
+	"github.com/jofosuware/go/shopit/internal/checkout/usecase"
+	mockCredit "github.com/jofosuware/go/shopit/internal/credit/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSession(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	credits := mockCredit.NewRepo(t)
+	rates := exchange.New(exchange.NewStatic(map[string]float64{"EUR": 0.5}))
+	c := usecase.NewCheckoutUC(repo, credits, rates, 10*time.Minute)
+	ctx := context.Background()
+
+	userID, prodID := uuid.New(), uuid.New()
+	product := &models.Product{ProductId: prodID, Name: "Laptop", Price: money.Of(500), Stock: 3}
+
+	t.Run("Prices are locked from the catalog", func(t *testing.T) {
+		sessionID := uuid.New()
+		repo.On("FetchProduct", mock.Anything, prodID).Return(product, nil).Once()
+		repo.On("FetchVariants", mock.Anything, prodID).Return(nil, nil).Once()
+		credits.On("FetchBalance", userID).Return(money.Of(0), nil).Once()
+		repo.On("InsertSession", mock.Anything, mock.MatchedBy(func(s models.CheckoutSession) bool {
+			return s.ItemsPrice == money.Of(1000) && s.TotalPrice == money.Of(1035) && s.Status == models.CheckoutOpen &&
+				time.Until(s.ExpiresAt) > 9*time.Minute
+		})).Return(func(_ context.Context, s models.CheckoutSession) *models.CheckoutSession {
+			s.ID = sessionID
+			return &s
+		}, nil).Once()
+		repo.On("InsertSessionItem", mock.Anything, sessionID, models.CheckoutItem{ProductID: prodID, Name: "Laptop", Price: money.Of(500), Quantity: 2}).
+			Return(nil).Once()
+
+		s, err := c.CreateSession(ctx, models.CheckoutSession{
+			UserID: userID,
+			// lines of the same product are merged
+			Items:         []*models.CheckoutItem{{ProductID: prodID, Quantity: 1, Price: money.Of(1)}, {ProductID: prodID, Quantity: 1}},
+			ShippingPrice: money.Of(25),
+			TaxPrice:      money.Of(10),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, sessionID, s.ID)
+		require.Len(t, s.Items, 1)
+		assert.Equal(t, money.Of(500), s.Items[0].Price)
+	})
+
+	t.Run("Store credit is taken off the total", func(t *testing.T) {
+		repo.On("FetchProduct", mock.Anything, prodID).Return(product, nil).Once()
+		repo.On("FetchVariants", mock.Anything, prodID).Return(nil, nil).Once()
+		credits.On("FetchBalance", userID).Return(money.Of(200), nil).Once()
+		repo.On("InsertSession", mock.Anything, mock.MatchedBy(func(s models.CheckoutSession) bool {
+			return s.CreditApplied == money.Of(200) && s.TotalPrice == money.Of(300)
+		})).Return(&models.CheckoutSession{ID: uuid.New()}, nil).Once()
+		repo.On("InsertSessionItem", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := c.CreateSession(ctx, models.CheckoutSession{
+			UserID: userID,
+			Items:  []*models.CheckoutItem{{ProductID: prodID, Quantity: 1}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Store credit above the total", func(t *testing.T) {
+		repo.On("FetchProduct", mock.Anything, prodID).Return(product, nil).Once()
+		repo.On("FetchVariants", mock.Anything, prodID).Return(nil, nil).Once()
+		credits.On("FetchBalance", userID).Return(money.Of(800), nil).Once()
+		repo.On("InsertSession", mock.Anything, mock.MatchedBy(func(s models.CheckoutSession) bool {
+			return s.CreditApplied == money.Of(500) && s.TotalPrice == money.Of(0)
+		})).Return(&models.CheckoutSession{ID: uuid.New()}, nil).Once()
+		repo.On("InsertSessionItem", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := c.CreateSession(ctx, models.CheckoutSession{
+			UserID: userID,
+			Items:  []*models.CheckoutItem{{ProductID: prodID, Quantity: 1}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Empty cart", func(t *testing.T) {
+		_, err := c.CreateSession(ctx, models.CheckoutSession{UserID: userID})
+		assert.ErrorIs(t, err, checkout.ErrEmptyCart)
+	})
+
+	t.Run("Quantity above stock", func(t *testing.T) {
+		repo.On("FetchProduct", mock.Anything, prodID).Return(product, nil).Once()
+		repo.On("FetchVariants", mock.Anything, prodID).Return(nil, nil).Once()
+
+		_, err := c.CreateSession(ctx, models.CheckoutSession{
+			UserID: userID,
+			Items:  []*models.CheckoutItem{{ProductID: prodID, Quantity: 4}},
+		})
+		assert.ErrorIs(t, err, checkout.ErrOutOfStock)
+	})
+
+	t.Run("Variants are priced and stocked on their own", func(t *testing.T) {
+		variantID := uuid.New()
+		variants := []models.Variant{{VariantId: variantID, ProductId: prodID, Attributes: map[string]string{"ram": "32GB"},
+			PriceDelta: money.Of(150), Stock: 5}}
+		repo.On("FetchProduct", mock.Anything, prodID).Return(product, nil).Once()
+		repo.On("FetchVariants", mock.Anything, prodID).Return(variants, nil).Once()
+		credits.On("FetchBalance", userID).Return(money.Of(0), nil).Once()
+		repo.On("InsertSession", mock.Anything, mock.MatchedBy(func(s models.CheckoutSession) bool {
+			return s.ItemsPrice == money.Of(2600)
+		})).Return(&models.CheckoutSession{ID: uuid.New()}, nil).Once()
+		repo.On("InsertSessionItem", mock.Anything, mock.Anything, models.CheckoutItem{ProductID: prodID,
+			VariantID: uuid.NullUUID{UUID: variantID, Valid: true}, Name: "Laptop (32GB)", Price: money.Of(650), Quantity: 4}).
+			Return(nil).Once()
+
+		_, err := c.CreateSession(ctx, models.CheckoutSession{
+			UserID: userID,
+			// four is above the product stock but not the variant's
+			Items: []*models.CheckoutItem{{ProductID: prodID, VariantID: uuid.NullUUID{UUID: variantID, Valid: true}, Quantity: 4}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Variant is required", func(t *testing.T) {
+		repo.On("FetchProduct", mock.Anything, prodID).Return(product, nil).Once()
+		repo.On("FetchVariants", mock.Anything, prodID).Return([]models.Variant{{VariantId: uuid.New(), ProductId: prodID}}, nil).Once()
+
+		_, err := c.CreateSession(ctx, models.CheckoutSession{
+			UserID: userID,
+			Items:  []*models.CheckoutItem{{ProductID: prodID, Quantity: 1}},
+		})
+		assert.ErrorIs(t, err, checkout.ErrVariantRequired)
+	})
+
+	t.Run("Session in another currency has no store credit", func(t *testing.T) {
+		require.NoError(t, money.Accept("EUR"))
+		t.Cleanup(func() { money.Accept() })
+
+		repo.On("FetchProduct", mock.Anything, prodID).Return(product, nil).Once()
+		repo.On("FetchVariants", mock.Anything, prodID).Return(nil, nil).Once()
+		repo.On("InsertSession", mock.Anything, mock.MatchedBy(func(s models.CheckoutSession) bool {
+			return s.Currency == "EUR" && s.ItemsPrice == money.New(500, "EUR") && s.ShippingPrice == money.New(5, "EUR") &&
+				s.CreditApplied == money.New(0, "EUR") && s.TotalPrice == money.New(505, "EUR")
+		})).Return(&models.CheckoutSession{ID: uuid.New()}, nil).Once()
+		repo.On("InsertSessionItem", mock.Anything, mock.Anything, mock.MatchedBy(func(i models.CheckoutItem) bool {
+			return i.Price == money.New(250, "EUR")
+		})).Return(nil).Once()
+
+		_, err := c.CreateSession(ctx, models.CheckoutSession{
+			UserID:        userID,
+			Currency:      "EUR",
+			Items:         []*models.CheckoutItem{{ProductID: prodID, Quantity: 2}},
+			ShippingPrice: money.Of(10),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Currency not accepted", func(t *testing.T) {
+		_, err := c.CreateSession(ctx, models.CheckoutSession{
+			UserID:   userID,
+			Currency: "GBP",
+			Items:    []*models.CheckoutItem{{ProductID: prodID, Quantity: 1}},
+		})
+		assert.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+	})
+
+	t.Run("Unknown variant", func(t *testing.T) {
+		repo.On("FetchProduct", mock.Anything, prodID).Return(product, nil).Once()
+		repo.On("FetchVariants", mock.Anything, prodID).Return(nil, nil).Once()
+
+		_, err := c.CreateSession(ctx, models.CheckoutSession{
+			UserID: userID,
+			Items:  []*models.CheckoutItem{{ProductID: prodID, VariantID: uuid.NullUUID{UUID: uuid.New(), Valid: true}, Quantity: 1}},
+		})
+		assert.ErrorIs(t, err, checkout.ErrVariantNotFound)
+	})
+
+	t.Run("Session is deleted when an item cannot be saved", func(t *testing.T) {
+		sessionID := uuid.New()
+		repo.On("FetchProduct", mock.Anything, prodID).Return(product, nil).Once()
+		repo.On("FetchVariants", mock.Anything, prodID).Return(nil, nil).Once()
+		credits.On("FetchBalance", userID).Return(money.Of(0), nil).Once()
+		repo.On("InsertSession", mock.Anything, mock.Anything).Return(&models.CheckoutSession{ID: sessionID}, nil).Once()
+		repo.On("InsertSessionItem", mock.Anything, sessionID, mock.Anything).Return(errors.New("db error")).Once()
+		repo.On("DeleteSessionById", mock.Anything, sessionID).Return(nil).Once()
+
+		_, err := c.CreateSession(ctx, models.CheckoutSession{
+			UserID: userID,
+			Items:  []*models.CheckoutItem{{ProductID: prodID, Quantity: 1}},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestClaim(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	credits := mockCredit.NewRepo(t)
+	c := usecase.NewCheckoutUC(repo, credits, nil, 0)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	open := func(id uuid.UUID) *models.CheckoutSession {
+		return &models.CheckoutSession{
+			ID:              id,
+			UserID:          userID,
+			Status:          models.CheckoutOpen,
+			PaymentIntentID: "pi_1",
+			ExpiresAt:       time.Now().Add(time.Minute),
+		}
+	}
+
+	t.Run("Open session is claimed", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchSessionById", mock.Anything, id).Return(open(id), nil).Once()
+		repo.On("FetchSessionItems", mock.Anything, id).Return([]*models.CheckoutItem{{Price: money.Of(100), Quantity: 1}}, nil).Once()
+		repo.On("ClaimSession", mock.Anything, id, mock.Anything).Return(nil).Once()
+
+		s, err := c.Claim(ctx, id, userID, "pi_1")
+		require.NoError(t, err)
+
+		assert.Equal(t, models.CheckoutCompleted, s.Status)
+		assert.Len(t, s.Items, 1)
+	})
+
+	t.Run("Session of another user", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchSessionById", mock.Anything, id).Return(open(id), nil).Once()
+
+		_, err := c.Claim(ctx, id, uuid.New(), "pi_1")
+		assert.ErrorIs(t, err, checkout.ErrSessionNotFound)
+	})
+
+	t.Run("Missing session", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchSessionById", mock.Anything, id).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := c.Claim(ctx, id, userID, "pi_1")
+		assert.ErrorIs(t, err, checkout.ErrSessionNotFound)
+	})
+
+	t.Run("Session past its expiry", func(t *testing.T) {
+		id := uuid.New()
+		s := open(id)
+		s.ExpiresAt = time.Now().Add(-time.Second)
+		repo.On("FetchSessionById", mock.Anything, id).Return(s, nil).Once()
+		repo.On("FetchSessionItems", mock.Anything, id).Return(nil, nil).Once()
+
+		_, err := c.Claim(ctx, id, userID, "pi_1")
+		assert.ErrorIs(t, err, checkout.ErrSessionExpired)
+	})
+
+	t.Run("Paid with another payment intent", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchSessionById", mock.Anything, id).Return(open(id), nil).Once()
+		repo.On("FetchSessionItems", mock.Anything, id).Return(nil, nil).Once()
+
+		_, err := c.Claim(ctx, id, userID, "pi_2")
+		assert.ErrorIs(t, err, checkout.ErrPaymentMismatch)
+	})
+
+	t.Run("Session claimed concurrently", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchSessionById", mock.Anything, id).Return(open(id), nil).Once()
+		repo.On("FetchSessionItems", mock.Anything, id).Return(nil, nil).Once()
+		repo.On("ClaimSession", mock.Anything, id, mock.Anything).Return(sql.ErrNoRows).Once()
+
+		_, err := c.Claim(ctx, id, userID, "pi_1")
+		assert.ErrorIs(t, err, checkout.ErrSessionClosed)
+	})
+
+	t.Run("Store credit is spent", func(t *testing.T) {
+		id := uuid.New()
+		s := open(id)
+		s.CreditApplied = money.Of(50)
+		repo.On("FetchSessionById", mock.Anything, id).Return(s, nil).Once()
+		repo.On("FetchSessionItems", mock.Anything, id).Return(nil, nil).Once()
+		repo.On("ClaimSession", mock.Anything, id, mock.Anything).Return(nil).Once()
+		credits.On("InsertDebit", mock.MatchedBy(func(e models.CreditEntry) bool {
+			return e.UserID == userID && e.Amount == money.Of(-50) && e.Reason == models.CreditCheckout && e.SessionID.UUID == id
+		})).Return(&models.CreditEntry{}, nil).Once()
+
+		_, err := c.Claim(ctx, id, userID, "pi_1")
+		require.NoError(t, err)
+	})
+
+	t.Run("Store credit spent elsewhere", func(t *testing.T) {
+		id := uuid.New()
+		s := open(id)
+		s.CreditApplied = money.Of(50)
+		repo.On("FetchSessionById", mock.Anything, id).Return(s, nil).Once()
+		repo.On("FetchSessionItems", mock.Anything, id).Return(nil, nil).Once()
+		repo.On("ClaimSession", mock.Anything, id, mock.Anything).Return(nil).Once()
+		credits.On("InsertDebit", mock.Anything).Return(nil, sql.ErrNoRows).Once()
+		repo.On("ReleaseSession", mock.Anything, id).Return(nil).Once()
+
+		_, err := c.Claim(ctx, id, userID, "pi_1")
+		assert.ErrorIs(t, err, checkout.ErrCreditSpent)
+	})
+}
+
+func TestRelease(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	credits := mockCredit.NewRepo(t)
+	c := usecase.NewCheckoutUC(repo, credits, nil, 0)
+	ctx := context.Background()
+
+	userID := uuid.New()
+
+	t.Run("Spent store credit is given back", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchSessionById", mock.Anything, id).
+			Return(&models.CheckoutSession{ID: id, UserID: userID, Status: models.CheckoutCompleted, CreditApplied: money.Of(50)}, nil).Once()
+		repo.On("ReleaseSession", mock.Anything, id).Return(nil).Once()
+		credits.On("InsertCredit", mock.MatchedBy(func(e models.CreditEntry) bool {
+			return e.UserID == userID && e.Amount == money.Of(50) && e.Reason == models.CreditCheckoutReleased
+		})).Return(&models.CreditEntry{}, nil).Once()
+
+		require.NoError(t, c.Release(ctx, id))
+	})
+
+	t.Run("Session with an order keeps its credit", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchSessionById", mock.Anything, id).Return(&models.CheckoutSession{
+			ID: id, UserID: userID, Status: models.CheckoutCompleted, CreditApplied: money.Of(50),
+			OrderID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		}, nil).Once()
+		repo.On("ReleaseSession", mock.Anything, id).Return(nil).Once()
+
+		require.NoError(t, c.Release(ctx, id))
+	})
+}
+
+func TestExpireSessions(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	credits := mockCredit.NewRepo(t)
+	c := usecase.NewCheckoutUC(repo, credits, nil, 0)
+	ctx := context.Background()
+
+	repo.On("ExpireSessions", mock.Anything, mock.AnythingOfType("time.Time")).Return(int64(3), nil).Once()
+
+	n, err := c.ExpireSessions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+}
@@ -0,0 +1,134 @@
+// Package delivery provides HTTP handlers for store credit.
+//
+// Users see their balance and ledger; admins grant credit for refunds or as goodwill
+// and deduct it. Checkout applies the balance on its own.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/credit"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// CreditHandlers provides HTTP handler methods for store credit endpoints.
+type CreditHandlers struct {
+	logger   logger.Logger
+	creditUC credit.CreditUC
+}
+
+// NewCreditHandlers returns a new CreditHandlers.
+func NewCreditHandlers(logger logger.Logger, creditUC credit.CreditUC) *CreditHandlers {
+	return &CreditHandlers{
+		logger:   logger,
+		creditUC: creditUC,
+	}
+}
+
+type balanceResponse struct {
+	Success bool `json:"success"`
+	*models.CreditBalance
+}
+
+type entryResponse struct {
+	Success bool                `json:"success"`
+	Entry   *models.CreditEntry `json:"entry"`
+}
+
+// GetMyBalance returns the store credit of the current user with the latest ledger entries.
+// Endpoint: GET /api/v1/credit/me
+func (h *CreditHandlers) GetMyBalance(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	h.writeBalance(w, r, user.ID)
+}
+
+// GetUserBalance returns the store credit of a user with the latest ledger entries (admin).
+// Endpoint: GET /api/v1/credit/admin/user/{id}
+func (h *CreditHandlers) GetUserBalance(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	h.writeBalance(w, r, id)
+}
+
+func (h *CreditHandlers) writeBalance(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	balance, err := h.creditUC.GetBalance(userID)
+	if err != nil {
+		if credit.IsClientError(err) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error fetching store credit: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error fetching store credit: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, balanceResponse{Success: true, CreditBalance: balance})
+}
+
+// GrantCredit credits a user (admin).
+// Endpoint: POST /api/v1/credit/admin/user/{id}/grant
+// Expects JSON body: {"amount": <money>, "reason": "refund|goodwill|adjustment", "note": <string>}.
+func (h *CreditHandlers) GrantCredit(w http.ResponseWriter, r *http.Request) {
+	h.changeCredit(w, r, h.creditUC.Grant)
+}
+
+// DeductCredit takes credit off a user (admin). The balance cannot go below zero.
+// Endpoint: POST /api/v1/credit/admin/user/{id}/deduct
+// Expects JSON body: {"amount": <money>, "reason": "refund|goodwill|adjustment", "note": <string>}.
+func (h *CreditHandlers) DeductCredit(w http.ResponseWriter, r *http.Request) {
+	h.changeCredit(w, r, h.creditUC.Deduct)
+}
+
+type changeFunc func(userID uuid.UUID, amount money.Money, reason, note string, adminID uuid.UUID) (*models.CreditEntry, error)
+
+func (h *CreditHandlers) changeCredit(w http.ResponseWriter, r *http.Request, change changeFunc) {
+	admin, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	var req changeRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	entry, err := change(id, req.Amount, req.Reason, req.Note, admin.ID)
+	if err != nil {
+		if credit.IsClientError(err) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error changing store credit: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error changing store credit: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusCreated, entryResponse{Success: true, Entry: entry})
+}
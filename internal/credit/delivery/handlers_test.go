@@ -0,0 +1,100 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/credit"
+	"github.com/jofosuware/go/shopit/internal/credit/delivery"
+	"github.com/jofosuware/go/shopit/internal/credit/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMyBalance(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	creditUC := mocks.NewCreditUC(t)
+
+	h := delivery.NewCreditHandlers(logger, creditUC)
+	user := models.User{ID: uuid.New()}
+
+	creditUC.On("GetBalance", user.ID).Return(&models.CreditBalance{UserID: user.ID, Balance: money.Of(40)}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/credit/me", nil)
+	req = req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, &user))
+	rr := httptest.NewRecorder()
+	h.GetMyBalance(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp models.CreditBalance
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, money.Of(40), resp.Balance)
+}
+
+func TestChangeCredit(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	creditUC := mocks.NewCreditUC(t)
+
+	h := delivery.NewCreditHandlers(logger, creditUC)
+	admin := models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	userID := uuid.New()
+
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/credit/admin/user/id", bytes.NewBufferString(body))
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", userID.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+		return req.WithContext(context.WithValue(ctx, utils.UserContextKey, &admin))
+	}
+
+	t.Run("Credit is granted", func(t *testing.T) {
+		creditUC.On("Grant", userID, money.Of(2500), models.CreditRefund, "order 42", admin.ID).
+			Return(&models.CreditEntry{UserID: userID, Amount: money.Of(2500)}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.GrantCredit(rr, newRequest(`{"amount":"25.00","reason":"refund","note":"order 42"}`))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.GrantCredit(rr, newRequest(`{"amount":{"amount":-500,"currency":"USD"},"reason":"gift"}`))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Deducting more than the balance", func(t *testing.T) {
+		creditUC.On("Deduct", userID, money.Of(10000), models.CreditAdjustment, "", admin.ID).
+			Return(nil, credit.ErrInsufficientCredit).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.DeductCredit(rr, newRequest(`{"amount":"100","reason":"adjustment"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Amount as a bare number", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.GrantCredit(rr, newRequest(`{"amount":25,"reason":"refund"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,22 @@
+package delivery
+
+import (
+	"github.com/jofosuware/go/shopit/internal/credit"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// changeRequest is the body of the endpoints granting and deducting credit.
+type changeRequest struct {
+	Amount money.Money `json:"amount"`
+	Reason string      `json:"reason"`
+	Note   string      `json:"note" validate:"max=255"`
+}
+
+func (req *changeRequest) Validate(v *validator.Validator) {
+	v.CheckCode(req.Amount.IsPositive(), "amount", validator.CodeTooSmall, credit.ErrInvalidAmount.Error())
+	v.Check(req.Amount.Currency == "" || req.Amount.Currency == money.DefaultCurrency, "amount",
+		"amount must be in the shop currency")
+	v.CheckCode(models.ValidCreditReason(req.Reason), "reason", validator.CodeOneOf, credit.ErrInvalidReason.Error())
+}
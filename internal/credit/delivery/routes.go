@@ -0,0 +1,32 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// CreditRouter returns a chi.Router with the store credit routes.
+//
+//   - GET  /me                     → Store credit of the current user
+//   - GET  /admin/user/{id}        → Store credit of a user (admin)
+//   - POST /admin/user/{id}/grant  → Credit a user (admin)
+//   - POST /admin/user/{id}/deduct → Deduct credit of a user (admin)
+func (h *CreditHandlers) CreditRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+
+	mux.Get("/me", h.GetMyBalance)
+
+	mux.Group(func(r chi.Router) {
+		r.Use(utils.IsAdmin)
+
+		r.Get("/admin/user/{id}", h.GetUserBalance)
+		r.Post("/admin/user/{id}/grant", h.GrantCredit)
+		r.Post("/admin/user/{id}/deduct", h.DeductCredit)
+	})
+
+	return mux
+}
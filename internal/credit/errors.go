@@ -0,0 +1,34 @@
+package credit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+var (
+	// ErrUserNotFound is returned when crediting or debiting a user that does not exist.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrInvalidAmount is returned when an amount is not positive.
+	ErrInvalidAmount = errors.New("amount must be positive")
+
+	// ErrInvalidReason is returned when a reason is not one of models.CreditReasons.
+	ErrInvalidReason = fmt.Errorf("reason must be one of: %s", strings.Join(models.CreditReasons, ", "))
+
+	// ErrInsufficientCredit is returned when deducting more than the balance.
+	ErrInsufficientCredit = errors.New("insufficient store credit")
+)
+
+// IsClientError reports whether err is caused by the request rather than the server.
+func IsClientError(err error) bool {
+	for _, e := range []error{ErrUserNotFound, ErrInvalidAmount, ErrInvalidReason, ErrInsufficientCredit} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	money "github.com/jofosuware/go/shopit/pkg/money"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// CreditUC is an autogenerated mock type for the CreditUC type
+type CreditUC struct {
+	mock.Mock
+}
+
+// Deduct provides a mock function with given fields: userID, amount, reason, note, adminID
+func (_m *CreditUC) Deduct(userID uuid.UUID, amount money.Money, reason string, note string, adminID uuid.UUID) (*models.CreditEntry, error) {
+	ret := _m.Called(userID, amount, reason, note, adminID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Deduct")
+	}
+
+	var r0 *models.CreditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, money.Money, string, string, uuid.UUID) (*models.CreditEntry, error)); ok {
+		return rf(userID, amount, reason, note, adminID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, money.Money, string, string, uuid.UUID) *models.CreditEntry); ok {
+		r0 = rf(userID, amount, reason, note, adminID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CreditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, money.Money, string, string, uuid.UUID) error); ok {
+		r1 = rf(userID, amount, reason, note, adminID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBalance provides a mock function with given fields: userID
+func (_m *CreditUC) GetBalance(userID uuid.UUID) (*models.CreditBalance, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBalance")
+	}
+
+	var r0 *models.CreditBalance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.CreditBalance, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.CreditBalance); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CreditBalance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Grant provides a mock function with given fields: userID, amount, reason, note, adminID
+func (_m *CreditUC) Grant(userID uuid.UUID, amount money.Money, reason string, note string, adminID uuid.UUID) (*models.CreditEntry, error) {
+	ret := _m.Called(userID, amount, reason, note, adminID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Grant")
+	}
+
+	var r0 *models.CreditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, money.Money, string, string, uuid.UUID) (*models.CreditEntry, error)); ok {
+		return rf(userID, amount, reason, note, adminID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, money.Money, string, string, uuid.UUID) *models.CreditEntry); ok {
+		r0 = rf(userID, amount, reason, note, adminID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CreditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, money.Money, string, string, uuid.UUID) error); ok {
+		r1 = rf(userID, amount, reason, note, adminID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCreditUC creates a new instance of CreditUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCreditUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CreditUC {
+	mock := &CreditUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,148 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	money "github.com/jofosuware/go/shopit/pkg/money"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+// FetchBalance provides a mock function with given fields: userID
+func (_m *Repo) FetchBalance(userID uuid.UUID) (money.Money, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchBalance")
+	}
+
+	var r0 money.Money
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (money.Money, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) money.Money); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Get(0).(money.Money)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchEntries provides a mock function with given fields: userID, limit
+func (_m *Repo) FetchEntries(userID uuid.UUID, limit int) ([]*models.CreditEntry, error) {
+	ret := _m.Called(userID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchEntries")
+	}
+
+	var r0 []*models.CreditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) ([]*models.CreditEntry, error)); ok {
+		return rf(userID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) []*models.CreditEntry); ok {
+		r0 = rf(userID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.CreditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(userID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertCredit provides a mock function with given fields: entry
+func (_m *Repo) InsertCredit(entry models.CreditEntry) (*models.CreditEntry, error) {
+	ret := _m.Called(entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertCredit")
+	}
+
+	var r0 *models.CreditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.CreditEntry) (*models.CreditEntry, error)); ok {
+		return rf(entry)
+	}
+	if rf, ok := ret.Get(0).(func(models.CreditEntry) *models.CreditEntry); ok {
+		r0 = rf(entry)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CreditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.CreditEntry) error); ok {
+		r1 = rf(entry)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertDebit provides a mock function with given fields: entry
+func (_m *Repo) InsertDebit(entry models.CreditEntry) (*models.CreditEntry, error) {
+	ret := _m.Called(entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertDebit")
+	}
+
+	var r0 *models.CreditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.CreditEntry) (*models.CreditEntry, error)); ok {
+		return rf(entry)
+	}
+	if rf, ok := ret.Get(0).(func(models.CreditEntry) *models.CreditEntry); ok {
+		r0 = rf(entry)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CreditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.CreditEntry) error); ok {
+		r1 = rf(entry)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,21 @@
+package credit
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+type Repo interface {
+	// FetchBalance fetches the store credit balance of a user, returns sql.ErrNoRows when there is no such user
+	FetchBalance(userID uuid.UUID) (money.Money, error)
+
+	// FetchEntries fetches up to limit ledger entries of a user, newest first, returns an error on failure
+	FetchEntries(userID uuid.UUID, limit int) ([]*models.CreditEntry, error)
+
+	// InsertCredit inserts a positive ledger entry, returns sql.ErrNoRows when there is no such user
+	InsertCredit(entry models.CreditEntry) (*models.CreditEntry, error)
+
+	// InsertDebit inserts a negative ledger entry, returns sql.ErrNoRows when the balance does not cover it
+	InsertDebit(entry models.CreditEntry) (*models.CreditEntry, error)
+}
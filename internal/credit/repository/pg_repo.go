@@ -0,0 +1,140 @@
+// Package repository provides persistence for the store credit ledger.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// CreditRepository handles store credit database operations.
+type CreditRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewCreditRepository returns a new CreditRepository.
+func NewCreditRepository(db *sql.DB) *CreditRepository {
+	return &CreditRepository{
+		DB: db,
+	}
+}
+
+// FetchBalance fetches the store credit balance of a user, the sum of the ledger.
+// It returns sql.ErrNoRows when there is no such user.
+func (r *CreditRepository) FetchBalance(userID uuid.UUID) (money.Money, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select coalesce((select sum(amount) from store_credits where user_id = u.user_id), 0)
+				from users u where u.user_id = $1`
+
+	var balance money.Money
+	if err := r.DB.QueryRowContext(ctx, query, userID).Scan(&balance); err != nil {
+		return money.Money{}, err
+	}
+
+	return balance, nil
+}
+
+// FetchEntries fetches up to limit ledger entries of a user, newest first.
+func (r *CreditRepository) FetchEntries(userID uuid.UUID, limit int) ([]*models.CreditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select entry_id, user_id, amount, reason, note, session_id, created_by, created_at
+				from store_credits where user_id = $1 order by created_at desc limit $2`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.CreditEntry
+	for rows.Next() {
+		var e models.CreditEntry
+		err := rows.Scan(&e.ID, &e.UserID, &e.Amount, &e.Reason, &e.Note, &e.SessionID, &e.CreatedBy, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// InsertCredit inserts a positive ledger entry. It returns sql.ErrNoRows when there
+// is no such user.
+func (r *CreditRepository) InsertCredit(entry models.CreditEntry) (*models.CreditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into store_credits (user_id, amount, reason, note, session_id, created_by, created_at)
+				select $1, $2, $3, $4, $5, $6, $7 where exists (select 1 from users where user_id = $1)
+				returning entry_id, created_at`
+
+	err := r.DB.QueryRowContext(ctx, query, entry.UserID, entry.Amount, entry.Reason, entry.Note,
+		entry.SessionID, entry.CreatedBy, time.Now()).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// InsertDebit inserts a negative ledger entry unless it would take the balance below
+// zero. The user row is locked while the balance is checked, so concurrent debits
+// cannot spend the same credit. It returns sql.ErrNoRows when there is no such user
+// or the balance does not cover the entry.
+func (r *CreditRepository) InsertDebit(entry models.CreditEntry) (*models.CreditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var balance money.Money
+	err = tx.QueryRowContext(ctx, `select user_id from users where user_id = $1 for update`, entry.UserID).
+		Scan(&entry.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, `select coalesce(sum(amount), 0) from store_credits where user_id = $1`,
+		entry.UserID).Scan(&balance)
+	if err != nil {
+		return nil, err
+	}
+
+	if balance.Add(entry.Amount).IsNegative() {
+		return nil, sql.ErrNoRows
+	}
+
+	query := `insert into store_credits (user_id, amount, reason, note, session_id, created_by, created_at)
+				values ($1, $2, $3, $4, $5, $6, $7) returning entry_id, created_at`
+
+	err = tx.QueryRowContext(ctx, query, entry.UserID, entry.Amount, entry.Reason, entry.Note,
+		entry.SessionID, entry.CreatedBy, time.Now()).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
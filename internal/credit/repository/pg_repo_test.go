@@ -0,0 +1,92 @@
+package repository_test
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/credit/repository"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchBalance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCreditRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectQuery(`select coalesce\(\(select sum\(amount\) from store_credits`).WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("4000"))
+
+	balance, err := repo.FetchBalance(userID)
+	require.NoError(t, err)
+	assert.Equal(t, money.Of(4000), balance)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertCredit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCreditRepository(db)
+	entry := models.CreditEntry{UserID: uuid.New(), Amount: money.Of(25), Reason: models.CreditRefund}
+	id := uuid.New()
+
+	mock.ExpectQuery(`insert into store_credits`).
+		WithArgs(entry.UserID, 25, models.CreditRefund, "", entry.SessionID, entry.CreatedBy, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"entry_id", "created_at"}).AddRow(id, time.Now()))
+
+	got, err := repo.InsertCredit(entry)
+	require.NoError(t, err)
+	assert.Equal(t, id, got.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertDebit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCreditRepository(db)
+	lock := regexp.QuoteMeta(`select user_id from users where user_id = $1 for update`)
+	sum := regexp.QuoteMeta(`select coalesce(sum(amount), 0) from store_credits where user_id = $1`)
+
+	t.Run("Balance covers the debit", func(t *testing.T) {
+		entry := models.CreditEntry{UserID: uuid.New(), Amount: money.Of(-30), Reason: models.CreditCheckout}
+		id := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(entry.UserID).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(entry.UserID))
+		mock.ExpectQuery(sum).WithArgs(entry.UserID).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(30))
+		mock.ExpectQuery(`insert into store_credits`).
+			WillReturnRows(sqlmock.NewRows([]string{"entry_id", "created_at"}).AddRow(id, time.Now()))
+		mock.ExpectCommit()
+
+		got, err := repo.InsertDebit(entry)
+		require.NoError(t, err)
+		assert.Equal(t, id, got.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Balance too low", func(t *testing.T) {
+		entry := models.CreditEntry{UserID: uuid.New(), Amount: money.Of(-30), Reason: models.CreditCheckout}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(entry.UserID).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(entry.UserID))
+		mock.ExpectQuery(sum).WithArgs(entry.UserID).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(20))
+		mock.ExpectRollback()
+
+		_, err := repo.InsertDebit(entry)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
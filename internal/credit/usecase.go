@@ -0,0 +1,18 @@
+package credit
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+type CreditUC interface {
+	// GetBalance returns the store credit balance of a user with the latest ledger entries
+	GetBalance(userID uuid.UUID) (*models.CreditBalance, error)
+
+	// Grant credits a user amount for reason on behalf of an admin, returns the ledger entry
+	Grant(userID uuid.UUID, amount money.Money, reason, note string, adminID uuid.UUID) (*models.CreditEntry, error)
+
+	// Deduct takes amount off the credit of a user on behalf of an admin, returns the ledger entry
+	Deduct(userID uuid.UUID, amount money.Money, reason, note string, adminID uuid.UUID) (*models.CreditEntry, error)
+}
@@ -0,0 +1,119 @@
+// Package usecase implements the store credit ledger.
+//
+// Credit is never stored as a balance: every grant, deduction and checkout spend is a
+// ledger entry and the balance is their sum, so each change of a balance can be
+// traced back to its reason and to the admin or checkout session behind it.
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/credit"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// EntriesLimit is how many ledger entries GetBalance returns.
+const EntriesLimit = 50
+
+// CreditUC provides store credit use cases.
+type CreditUC struct {
+	repo credit.Repo
+}
+
+// NewCreditUC returns a new CreditUC.
+func NewCreditUC(repo credit.Repo) *CreditUC {
+	return &CreditUC{
+		repo: repo,
+	}
+}
+
+// GetBalance returns the store credit balance of userID with its latest ledger entries.
+func (c *CreditUC) GetBalance(userID uuid.UUID) (*models.CreditBalance, error) {
+	balance, err := c.repo.FetchBalance(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, credit.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error fetching store credit balance: %v", err)
+	}
+
+	entries, err := c.repo.FetchEntries(userID, EntriesLimit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching store credit entries: %v", err)
+	}
+	if entries == nil {
+		entries = []*models.CreditEntry{}
+	}
+
+	return &models.CreditBalance{UserID: userID, Balance: balance, Entries: entries}, nil
+}
+
+// Grant credits userID amount, for a refund or as goodwill, on behalf of adminID.
+func (c *CreditUC) Grant(userID uuid.UUID, amount money.Money, reason, note string, adminID uuid.UUID) (*models.CreditEntry, error) {
+	if err := validate(amount, reason); err != nil {
+		return nil, err
+	}
+
+	e, err := c.repo.InsertCredit(models.CreditEntry{
+		UserID:    userID,
+		Amount:    amount,
+		Reason:    reason,
+		Note:      note,
+		CreatedBy: uuid.NullUUID{UUID: adminID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, credit.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error granting store credit: %v", err)
+	}
+
+	return e, nil
+}
+
+// Deduct takes amount off the credit of userID on behalf of adminID. The balance
+// cannot go below zero.
+func (c *CreditUC) Deduct(userID uuid.UUID, amount money.Money, reason, note string, adminID uuid.UUID) (*models.CreditEntry, error) {
+	if err := validate(amount, reason); err != nil {
+		return nil, err
+	}
+
+	// tell a missing user from a short balance, which the debit reports alike
+	if _, err := c.repo.FetchBalance(userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, credit.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error fetching store credit balance: %v", err)
+	}
+
+	e, err := c.repo.InsertDebit(models.CreditEntry{
+		UserID:    userID,
+		Amount:    amount.Neg(),
+		Reason:    reason,
+		Note:      note,
+		CreatedBy: uuid.NullUUID{UUID: adminID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, credit.ErrInsufficientCredit
+		}
+		return nil, fmt.Errorf("error deducting store credit: %v", err)
+	}
+
+	return e, nil
+}
+
+func validate(amount money.Money, reason string) error {
+	if !amount.IsPositive() {
+		return credit.ErrInvalidAmount
+	}
+	if !models.ValidCreditReason(reason) {
+		return credit.ErrInvalidReason
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package usecase_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/credit"
+	"github.com/jofosuware/go/shopit/internal/credit/mocks"
+	"github.com/jofosuware/go/shopit/internal/credit/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBalance(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	c := usecase.NewCreditUC(repo)
+
+	t.Run("Balance with entries", func(t *testing.T) {
+		userID := uuid.New()
+		repo.On("FetchBalance", userID).Return(money.Of(40), nil).Once()
+		repo.On("FetchEntries", userID, usecase.EntriesLimit).Return([]*models.CreditEntry{{Amount: money.Of(40)}}, nil).Once()
+
+		b, err := c.GetBalance(userID)
+		require.NoError(t, err)
+		assert.Equal(t, money.Of(40), b.Balance)
+		assert.Len(t, b.Entries, 1)
+	})
+
+	t.Run("Missing user", func(t *testing.T) {
+		userID := uuid.New()
+		repo.On("FetchBalance", userID).Return(money.Of(0), sql.ErrNoRows).Once()
+
+		_, err := c.GetBalance(userID)
+		assert.ErrorIs(t, err, credit.ErrUserNotFound)
+	})
+}
+
+func TestGrant(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	c := usecase.NewCreditUC(repo)
+	userID, adminID := uuid.New(), uuid.New()
+
+	t.Run("Credit is granted", func(t *testing.T) {
+		repo.On("InsertCredit", mock.MatchedBy(func(e models.CreditEntry) bool {
+			return e.UserID == userID && e.Amount == money.Of(25) && e.Reason == models.CreditGoodwill && e.CreatedBy.UUID == adminID
+		})).Return(&models.CreditEntry{Amount: money.Of(25)}, nil).Once()
+
+		e, err := c.Grant(userID, money.Of(25), models.CreditGoodwill, "late delivery", adminID)
+		require.NoError(t, err)
+		assert.Equal(t, money.Of(25), e.Amount)
+	})
+
+	t.Run("Invalid amount", func(t *testing.T) {
+		_, err := c.Grant(userID, money.Of(0), models.CreditGoodwill, "", adminID)
+		assert.ErrorIs(t, err, credit.ErrInvalidAmount)
+	})
+
+	t.Run("Invalid reason", func(t *testing.T) {
+		_, err := c.Grant(userID, money.Of(5), models.CreditCheckout, "", adminID)
+		assert.ErrorIs(t, err, credit.ErrInvalidReason)
+	})
+}
+
+func TestDeduct(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	c := usecase.NewCreditUC(repo)
+	userID, adminID := uuid.New(), uuid.New()
+
+	t.Run("Credit is deducted", func(t *testing.T) {
+		repo.On("FetchBalance", userID).Return(money.Of(40), nil).Once()
+		repo.On("InsertDebit", mock.MatchedBy(func(e models.CreditEntry) bool {
+			return e.Amount == money.Of(-15) && e.Reason == models.CreditAdjustment
+		})).Return(&models.CreditEntry{Amount: money.Of(-15)}, nil).Once()
+
+		e, err := c.Deduct(userID, money.Of(15), models.CreditAdjustment, "", adminID)
+		require.NoError(t, err)
+		assert.Equal(t, money.Of(-15), e.Amount)
+	})
+
+	t.Run("Balance too low", func(t *testing.T) {
+		repo.On("FetchBalance", userID).Return(money.Of(10), nil).Once()
+		repo.On("InsertDebit", mock.Anything).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := c.Deduct(userID, money.Of(15), models.CreditAdjustment, "", adminID)
+		assert.ErrorIs(t, err, credit.ErrInsufficientCredit)
+	})
+
+	t.Run("Missing user", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchBalance", id).Return(money.Of(0), sql.ErrNoRows).Once()
+
+		_, err := c.Deduct(id, money.Of(15), models.CreditAdjustment, "", adminID)
+		assert.ErrorIs(t, err, credit.ErrUserNotFound)
+	})
+}
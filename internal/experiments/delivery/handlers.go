@@ -0,0 +1,55 @@
+// Package delivery provides HTTP handlers for experiment metrics.
+//
+// It lets admins compare how the variants of feature flags convert.
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jofosuware/go/shopit/internal/experiments"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// ExperimentHandlers provides HTTP handler methods for experiment endpoints.
+type ExperimentHandlers struct {
+	logger        logger.Logger
+	experimentsUC experiments.ExperimentsUC
+}
+
+// NewExperimentHandlers returns a new ExperimentHandlers.
+func NewExperimentHandlers(logger logger.Logger, experimentsUC experiments.ExperimentsUC) *ExperimentHandlers {
+	return &ExperimentHandlers{
+		logger:        logger,
+		experimentsUC: experimentsUC,
+	}
+}
+
+// GetSummary returns the conversion per variant of every feature flag (admin).
+// Endpoint: GET /api/v1/admin/experiments
+func (h *ExperimentHandlers) GetSummary(w http.ResponseWriter, r *http.Request) {
+	summaries, err := h.experimentsUC.GetSummary()
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error fetching experiment summary: %w", err))
+		return
+	}
+
+	if summaries == nil {
+		summaries = []*models.VariantSummary{}
+	}
+
+	jr := struct {
+		Success  bool                     `json:"success"`
+		Variants []*models.VariantSummary `json:"variants"`
+	}{
+		Success:  true,
+		Variants: summaries,
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
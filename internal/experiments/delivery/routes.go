@@ -0,0 +1,23 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/loadshed"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// ExperimentRouter returns a chi.Router with admin-only experiment routes.
+//
+//   - GET / → Conversion per feature flag variant
+func (h *ExperimentHandlers) ExperimentRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+	mux.Use(utils.IsAdmin)
+
+	mux.With(loadshed.LowPriority).Get("/", h.GetSummary)
+
+	return mux
+}
@@ -0,0 +1,93 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ExperimentsUC is an autogenerated mock type for the ExperimentsUC type
+type ExperimentsUC struct {
+	mock.Mock
+}
+
+// GetSummary provides a mock function with given fields:
+func (_m *ExperimentsUC) GetSummary() ([]*models.VariantSummary, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSummary")
+	}
+
+	var r0 []*models.VariantSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.VariantSummary, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.VariantSummary); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.VariantSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LinkSubject provides a mock function with given fields: subject, userID
+func (_m *ExperimentsUC) LinkSubject(subject string, userID string) error {
+	ret := _m.Called(subject, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LinkSubject")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(subject, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RecordExposure provides a mock function with given fields: flag, variant, subject
+func (_m *ExperimentsUC) RecordExposure(flag string, variant string, subject string) error {
+	ret := _m.Called(flag, variant, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordExposure")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(flag, variant, subject)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewExperimentsUC creates a new instance of ExperimentsUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewExperimentsUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ExperimentsUC {
+	mock := &ExperimentsUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,17 @@
+package experiments
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// InsertExposure records the variant of a flag served to a subject, keeping the first exposure
+	InsertExposure(flag, variant, subject string) error
+
+	// LinkExposures attributes the exposures of an anonymous subject to a user, keeping earlier links
+	LinkExposures(subject string, userID uuid.UUID) error
+
+	// FetchVariantSummaries aggregates exposures and the orders placed after them per flag and variant
+	FetchVariantSummaries() ([]*models.VariantSummary, error)
+}
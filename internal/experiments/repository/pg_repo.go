@@ -0,0 +1,99 @@
+// Package repository provides persistence for experiment exposures.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// ExperimentsRepository handles experiment-related database operations.
+type ExperimentsRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewExperimentsRepository returns a new ExperimentsRepository.
+func NewExperimentsRepository(db *sql.DB) *ExperimentsRepository {
+	return &ExperimentsRepository{
+		DB: db,
+	}
+}
+
+// InsertExposure inserts an exposure, ignoring subjects already exposed to the flag.
+func (r *ExperimentsRepository) InsertExposure(flag, variant, subject string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into experiment_exposures (flag, subject, variant, created_at) values ($1, $2, $3, $4)
+				on conflict (flag, subject) do nothing`
+
+	_, err := r.DB.ExecContext(ctx, query, flag, subject, variant, time.Now())
+
+	return err
+}
+
+// LinkExposures attributes the exposures of the anonymous subject to the user userID.
+// Exposures already linked to a user keep it.
+func (r *ExperimentsRepository) LinkExposures(subject string, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update experiment_exposures set user_id = $1 where subject = $2 and user_id is null`
+
+	_, err := r.DB.ExecContext(ctx, query, userID, subject)
+
+	return err
+}
+
+// FetchVariantSummaries aggregates, per flag and variant, the exposed subjects and
+// the orders they placed after their exposure. An order converts the exposures of its
+// user and the anonymous exposures linked to them. Revenue only counts orders in the shop
+// currency, as amounts in different currencies cannot be added up.
+func (r *ExperimentsRepository) FetchVariantSummaries() ([]*models.VariantSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := `
+			select e.flag, e.variant, count(distinct e.subject), count(distinct o.user_id),
+				count(distinct o.order_id), coalesce(sum(o.total_price) filter (where o.currency = $1), 0)
+			from experiment_exposures e
+			left join orders o on (o.user_id::text = e.subject or o.user_id = e.user_id) and o.created_at >= e.created_at
+			group by e.flag, e.variant
+			order by e.flag, e.variant
+	`
+
+	rows, err := r.DB.QueryContext(ctx, query, money.DefaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*models.VariantSummary
+	for rows.Next() {
+		var s models.VariantSummary
+		err := rows.Scan(
+			&s.Flag,
+			&s.Variant,
+			&s.Exposed,
+			&s.Converted,
+			&s.Orders,
+			&s.Revenue,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		summaries = append(summaries, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,89 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/experiments/repository"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertExposure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := `insert into experiment_exposures \(flag, subject, variant, created_at\) values \(\$1, \$2, \$3, \$4\) on conflict \(flag, subject\) do nothing`
+
+	t.Run("Exposure inserted successfully", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("newcheckout", "user-1", "treatment", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		repo := repository.NewExperimentsRepository(db)
+
+		err := repo.InsertExposure("newcheckout", "treatment", "user-1")
+		require.NoError(t, err)
+	})
+}
+
+func TestLinkExposures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := `update experiment_exposures set user_id = \$1 where subject = \$2 and user_id is null`
+
+	t.Run("Anonymous exposures linked successfully", func(t *testing.T) {
+		userID := uuid.New()
+		mock.ExpectExec(query).WithArgs(userID, "visitor").WillReturnResult(sqlmock.NewResult(0, 3))
+
+		repo := repository.NewExperimentsRepository(db)
+
+		err := repo.LinkExposures("visitor", userID)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFetchVariantSummaries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Run("Summaries fetched successfully", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"flag", "variant", "exposed", "converted", "orders", "revenue"}).
+			AddRow("newcheckout", "control", 100, 5, 6, 600).
+			AddRow("newcheckout", "treatment", 100, 8, 8, 900)
+
+		mock.ExpectQuery(`select e.flag, e.variant, .* from experiment_exposures e`).WithArgs("USD").WillReturnRows(rows)
+
+		repo := repository.NewExperimentsRepository(db)
+
+		summaries, err := repo.FetchVariantSummaries()
+		require.NoError(t, err)
+
+		assert.Len(t, summaries, 2)
+		assert.Equal(t, "treatment", summaries[1].Variant)
+		assert.Equal(t, money.Of(900), summaries[1].Revenue)
+	})
+
+	t.Run("Anonymous exposures convert for their linked user", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"flag", "variant", "exposed", "converted", "orders", "revenue"}).
+			AddRow("newcheckout", "treatment", 1, 1, 1, 500)
+
+		mock.ExpectQuery(`left join orders o on \(o.user_id::text = e.subject or o.user_id = e.user_id\)`).
+			WithArgs("USD").WillReturnRows(rows)
+
+		repo := repository.NewExperimentsRepository(db)
+
+		summaries, err := repo.FetchVariantSummaries()
+		require.NoError(t, err)
+
+		require.Len(t, summaries, 1)
+		assert.Equal(t, 1, summaries[0].Converted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
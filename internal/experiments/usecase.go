@@ -0,0 +1,14 @@
+package experiments
+
+import "github.com/jofosuware/go/shopit/internal/models"
+
+type ExperimentsUC interface {
+	// RecordExposure records the variant of a flag served to a subject
+	RecordExposure(flag, variant, subject string) error
+
+	// LinkSubject attributes the exposures of an anonymous subject to a user
+	LinkSubject(subject, userID string) error
+
+	// GetSummary returns the conversion of every variant of every flag
+	GetSummary() ([]*models.VariantSummary, error)
+}
@@ -0,0 +1,64 @@
+// Package usecase records feature flag exposures and summarises conversion per variant.
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/jofosuware/go/shopit/internal/experiments"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// ExperimentsUC provides experiment metrics use cases.
+type ExperimentsUC struct {
+	repo experiments.Repo
+}
+
+// NewExperimentsUC returns a new ExperimentsUC.
+func NewExperimentsUC(repo experiments.Repo) *ExperimentsUC {
+	return &ExperimentsUC{
+		repo: repo,
+	}
+}
+
+// RecordExposure records the variant of a flag served to a subject.
+// It satisfies featureflag.Recorder.
+func (e *ExperimentsUC) RecordExposure(flag, variant, subject string) error {
+	if err := e.repo.InsertExposure(flag, variant, subject); err != nil {
+		return fmt.Errorf("error saving exposure: %v", err)
+	}
+
+	return nil
+}
+
+// LinkSubject attributes the exposures of the anonymous subject to the user userID, so
+// that the orders the user places convert them. It satisfies featureflag.Recorder.
+func (e *ExperimentsUC) LinkSubject(subject, userID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("error parsing user id: %v", err)
+	}
+
+	if err := e.repo.LinkExposures(subject, id); err != nil {
+		return fmt.Errorf("error linking exposures: %v", err)
+	}
+
+	return nil
+}
+
+// GetSummary returns exposures, conversions and the conversion rate of every variant.
+func (e *ExperimentsUC) GetSummary() ([]*models.VariantSummary, error) {
+	summaries, err := e.repo.FetchVariantSummaries()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching variant summaries: %v", err)
+	}
+
+	for _, s := range summaries {
+		if s.Exposed > 0 {
+			s.ConversionRate = float64(s.Converted) / float64(s.Exposed)
+		}
+	}
+
+	return summaries, nil
+}
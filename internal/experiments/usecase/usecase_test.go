@@ -0,0 +1,68 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/experiments/mocks"
+	"github.com/jofosuware/go/shopit/internal/experiments/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordExposure(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	e := usecase.NewExperimentsUC(repo)
+
+	t.Run("Exposure is successfully recorded", func(t *testing.T) {
+		repo.On("InsertExposure", "newcheckout", "treatment", "user-1").Return(nil).Once()
+
+		err := e.RecordExposure("newcheckout", "treatment", "user-1")
+		require.NoError(t, err)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		repo.On("InsertExposure", "newcheckout", "control", "user-2").Return(errors.New("db error")).Once()
+
+		err := e.RecordExposure("newcheckout", "control", "user-2")
+		assert.Error(t, err)
+	})
+}
+
+func TestLinkSubject(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	e := usecase.NewExperimentsUC(repo)
+
+	t.Run("Anonymous exposures are linked to the user", func(t *testing.T) {
+		userID := uuid.New()
+		repo.On("LinkExposures", "visitor", userID).Return(nil).Once()
+
+		err := e.LinkSubject("visitor", userID.String())
+		require.NoError(t, err)
+	})
+
+	t.Run("Invalid user id", func(t *testing.T) {
+		err := e.LinkSubject("visitor", "visitor")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetSummary(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	e := usecase.NewExperimentsUC(repo)
+
+	t.Run("Conversion rates are computed", func(t *testing.T) {
+		repo.On("FetchVariantSummaries").Return([]*models.VariantSummary{
+			{Flag: "newcheckout", Variant: "control", Exposed: 200, Converted: 10},
+			{Flag: "newcheckout", Variant: "treatment", Exposed: 0},
+		}, nil).Once()
+
+		summaries, err := e.GetSummary()
+		require.NoError(t, err)
+
+		assert.InDelta(t, 0.05, summaries[0].ConversionRate, 1e-9)
+		assert.Zero(t, summaries[1].ConversionRate)
+	})
+}
@@ -0,0 +1,80 @@
+// Package delivery provides HTTP handlers for the data export audit log.
+//
+// It lets admins review who downloaded which data, with which filters, for
+// compliance with customer data handling requirements.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/exports"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// ExportHandlers provides HTTP handler methods for data export audit endpoints.
+type ExportHandlers struct {
+	logger    logger.Logger
+	exportsUC exports.ExportsUC
+}
+
+// NewExportHandlers returns a new ExportHandlers.
+func NewExportHandlers(logger logger.Logger, exportsUC exports.ExportsUC) *ExportHandlers {
+	return &ExportHandlers{
+		logger:    logger,
+		exportsUC: exportsUC,
+	}
+}
+
+// GetExports returns the latest data exports, last first (admin).
+// Endpoint: GET /api/v1/admin/exports?kind=<orders|products|sales>&userId=<uuid>&limit=<int>
+// Every kind and admin is listed without them; limit defaults to 50 and is capped at 200.
+func (h *ExportHandlers) GetExports(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var userID uuid.NullUUID
+	if s := q.Get("userId"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			_ = utils.BadRequest(w, r, errors.New("userId must be a uuid"))
+			h.logger.Errorf("error parsing id: %v", err)
+			return
+		}
+		userID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	var limit int
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			_ = utils.BadRequest(w, r, errors.New("limit must be a positive number"))
+			h.logger.Errorf("error parsing limit: %v", l)
+			return
+		}
+		limit = n
+	}
+
+	list, err := h.exportsUC.GetExports(q.Get("kind"), userID, limit)
+	if err != nil {
+		if errors.Is(err, exports.ErrInvalidKind) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error getting exports: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting exports: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success bool                `json:"success"`
+		Exports []models.DataExport `json:"exports"`
+	}{
+		Success: true,
+		Exports: list,
+	})
+}
@@ -0,0 +1,53 @@
+package delivery_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/exports"
+	"github.com/jofosuware/go/shopit/internal/exports/delivery"
+	"github.com/jofosuware/go/shopit/internal/exports/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetExports(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	exportsUC := mocks.NewExportsUC(t)
+	h := delivery.NewExportHandlers(logger, exportsUC)
+
+	call := func(query string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.GetExports(rr, httptest.NewRequest(http.MethodGet, "/admin/exports"+query, nil))
+		return rr
+	}
+
+	t.Run("Exports of an admin are listed", func(t *testing.T) {
+		userID := uuid.New()
+		exportsUC.On("GetExports", models.ExportOrders, uuid.NullUUID{UUID: userID, Valid: true}, 10).
+			Return([]models.DataExport{{Email: "admin@shopit.com", Kind: models.ExportOrders}}, nil).Once()
+
+		rr := call("?kind=orders&limit=10&userId=" + userID.String())
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "admin@shopit.com")
+	})
+
+	t.Run("Invalid user id", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("?userId=1").Code)
+	})
+
+	t.Run("Unknown kind", func(t *testing.T) {
+		exportsUC.On("GetExports", "users", uuid.NullUUID{}, 0).Return(nil, exports.ErrInvalidKind).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("?kind=users").Code)
+	})
+}
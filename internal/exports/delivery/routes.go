@@ -0,0 +1,22 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// ExportRouter returns a chi.Router with admin-only data export audit routes.
+//
+//   - GET / → Latest data exports
+func (h *ExportHandlers) ExportRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+	mux.Use(utils.IsAdmin)
+
+	mux.Get("/", h.GetExports)
+
+	return mux
+}
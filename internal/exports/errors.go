@@ -0,0 +1,6 @@
+package exports
+
+import "errors"
+
+// ErrInvalidKind is returned when listing exports of a kind that does not exist.
+var ErrInvalidKind = errors.New("kind must be orders, products or sales")
@@ -0,0 +1,76 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	uuid "github.com/google/uuid"
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+// FetchExports provides a mock function with given fields: kind, userID, limit
+func (_m *Repo) FetchExports(kind string, userID uuid.NullUUID, limit int) ([]models.DataExport, error) {
+	ret := _m.Called(kind, userID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchExports")
+	}
+
+	var r0 []models.DataExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, uuid.NullUUID, int) ([]models.DataExport, error)); ok {
+		return rf(kind, userID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, uuid.NullUUID, int) []models.DataExport); ok {
+		r0 = rf(kind, userID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.DataExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, uuid.NullUUID, int) error); ok {
+		r1 = rf(kind, userID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertExport provides a mock function with given fields: e
+func (_m *Repo) InsertExport(e models.DataExport) error {
+	ret := _m.Called(e)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertExport")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.DataExport) error); ok {
+		r0 = rf(e)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
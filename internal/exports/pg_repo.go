@@ -0,0 +1,14 @@
+package exports
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// InsertExport saves the audit record of a data export
+	InsertExport(e models.DataExport) error
+
+	// FetchExports fetches up to limit exports, last first, of a kind and admin when they are set
+	FetchExports(kind string, userID uuid.NullUUID, limit int) ([]models.DataExport, error)
+}
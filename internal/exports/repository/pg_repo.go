@@ -0,0 +1,76 @@
+// Package repository stores the audit log of data exports.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// ExportsRepository handles data export audit database operations.
+type ExportsRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewExportsRepository returns a new ExportsRepository.
+func NewExportsRepository(db *sql.DB) *ExportsRepository {
+	return &ExportsRepository{
+		DB: db,
+	}
+}
+
+// InsertExport inserts the audit record of a data export.
+func (r *ExportsRepository) InsertExport(e models.DataExport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	filters, err := json.Marshal(e.Filters)
+	if err != nil {
+		return err
+	}
+
+	query := `insert into data_exports (user_id, email, kind, filters, row_count, bytes, sha256, created_at)
+				values ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err = r.DB.ExecContext(ctx, query, e.UserID, e.Email, e.Kind, filters, e.Rows, e.Bytes, e.SHA256, time.Now())
+
+	return err
+}
+
+// FetchExports fetches up to limit exports, last first. An empty kind and an unset
+// userID match every export.
+func (r *ExportsRepository) FetchExports(kind string, userID uuid.NullUUID, limit int) ([]models.DataExport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select export_id, user_id, email, kind, filters, row_count, bytes, sha256, created_at from data_exports
+				where ($1 = '' or kind = $1) and ($2::uuid is null or user_id = $2)
+				order by created_at desc limit $3`
+
+	rows, err := r.DB.QueryContext(ctx, query, kind, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.DataExport{}
+	for rows.Next() {
+		var e models.DataExport
+		var filters []byte
+		err := rows.Scan(&e.ID, &e.UserID, &e.Email, &e.Kind, &filters, &e.Rows, &e.Bytes, &e.SHA256, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(filters, &e.Filters); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+
+	return list, rows.Err()
+}
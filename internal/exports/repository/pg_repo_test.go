@@ -0,0 +1,63 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/exports/repository"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertExport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewExportsRepository(db)
+	e := models.DataExport{UserID: uuid.NullUUID{UUID: uuid.New(), Valid: true}, Email: "admin@shopit.com",
+		Kind: models.ExportOrders, Filters: map[string]string{"anonymize": "true"}, Rows: 2, Bytes: 40, SHA256: "abc"}
+
+	mock.ExpectExec(regexp.QuoteMeta("insert into data_exports")).
+		WithArgs(e.UserID, e.Email, e.Kind, []byte(`{"anonymize":"true"}`), 2, int64(40), "abc", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.InsertExport(e))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchExports(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewExportsRepository(db)
+	columns := []string{"export_id", "user_id", "email", "kind", "filters", "row_count", "bytes", "sha256", "created_at"}
+
+	t.Run("Exports of a kind", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("from data_exports where ($1 = '' or kind = $1)")).
+			WithArgs(models.ExportProducts, uuid.NullUUID{}, 50).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(uuid.New(), nil, "gone@shopit.com", models.ExportProducts,
+				[]byte(`{}`), 10, 512, "abc", time.Now()))
+
+		list, err := repo.FetchExports(models.ExportProducts, uuid.NullUUID{}, 50)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.False(t, list[0].UserID.Valid)
+		assert.Equal(t, map[string]string{}, list[0].Filters)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No exports", func(t *testing.T) {
+		mock.ExpectQuery("from data_exports").WillReturnRows(sqlmock.NewRows(columns))
+
+		list, err := repo.FetchExports("", uuid.NullUUID{}, 50)
+		require.NoError(t, err)
+		assert.NotNil(t, list)
+		assert.Empty(t, list)
+	})
+}
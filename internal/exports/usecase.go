@@ -0,0 +1,14 @@
+package exports
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type ExportsUC interface {
+	// RecordExport saves the audit record of a data export
+	RecordExport(e models.DataExport) error
+
+	// GetExports returns the latest exports, of a kind and admin when they are set
+	GetExports(kind string, userID uuid.NullUUID, limit int) ([]models.DataExport, error)
+}
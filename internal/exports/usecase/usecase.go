@@ -0,0 +1,63 @@
+// Package usecase keeps the audit log of the data exports admins download.
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/exports"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// Export list limits
+const (
+	// DefaultExportLimit is how many exports are listed without a limit
+	DefaultExportLimit = 50
+	// MaxExportLimit caps the exports listed at once
+	MaxExportLimit = 200
+)
+
+// ExportsUC provides data export audit use cases.
+type ExportsUC struct {
+	repo exports.Repo
+}
+
+// NewExportsUC returns a new ExportsUC.
+func NewExportsUC(repo exports.Repo) *ExportsUC {
+	return &ExportsUC{
+		repo: repo,
+	}
+}
+
+// RecordExport saves the audit record of a data export.
+// It satisfies audit.Recorder.
+func (u *ExportsUC) RecordExport(e models.DataExport) error {
+	if err := u.repo.InsertExport(e); err != nil {
+		return fmt.Errorf("error saving export: %v", err)
+	}
+
+	return nil
+}
+
+// GetExports returns up to limit exports, last first, of kind and of the admin
+// userID when they are set. A non-positive limit stands for DefaultExportLimit, and
+// limit is capped at MaxExportLimit. It returns exports.ErrInvalidKind for an
+// unknown kind.
+func (u *ExportsUC) GetExports(kind string, userID uuid.NullUUID, limit int) ([]models.DataExport, error) {
+	if kind != "" && kind != models.ExportOrders && kind != models.ExportProducts && kind != models.ExportSales {
+		return nil, exports.ErrInvalidKind
+	}
+	if limit <= 0 {
+		limit = DefaultExportLimit
+	}
+	if limit > MaxExportLimit {
+		limit = MaxExportLimit
+	}
+
+	list, err := u.repo.FetchExports(kind, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching exports: %v", err)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,58 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/exports"
+	"github.com/jofosuware/go/shopit/internal/exports/mocks"
+	"github.com/jofosuware/go/shopit/internal/exports/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordExport(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewExportsUC(repo)
+	e := models.DataExport{Kind: models.ExportOrders, Rows: 2}
+
+	repo.On("InsertExport", e).Return(errors.New("database error")).Once()
+
+	assert.Error(t, u.RecordExport(e))
+}
+
+func TestGetExports(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewExportsUC(repo)
+	userID := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+
+	t.Run("Limit is capped", func(t *testing.T) {
+		repo.On("FetchExports", models.ExportOrders, userID, usecase.MaxExportLimit).
+			Return([]models.DataExport{}, nil).Once()
+
+		_, err := u.GetExports(models.ExportOrders, userID, 1000)
+		require.NoError(t, err)
+	})
+
+	t.Run("Limit defaults", func(t *testing.T) {
+		repo.On("FetchExports", "", uuid.NullUUID{}, usecase.DefaultExportLimit).Return([]models.DataExport{}, nil).Once()
+
+		_, err := u.GetExports("", uuid.NullUUID{}, 0)
+		require.NoError(t, err)
+	})
+
+	t.Run("Sales reports are listed", func(t *testing.T) {
+		repo.On("FetchExports", models.ExportSales, uuid.NullUUID{}, usecase.DefaultExportLimit).
+			Return([]models.DataExport{}, nil).Once()
+
+		_, err := u.GetExports(models.ExportSales, uuid.NullUUID{}, 0)
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown kind", func(t *testing.T) {
+		_, err := u.GetExports("users", uuid.NullUUID{}, 0)
+		assert.ErrorIs(t, err, exports.ErrInvalidKind)
+	})
+}
@@ -0,0 +1,105 @@
+// Package delivery serves the GraphQL API, for clients that prefer it to the REST
+// endpoints.
+//
+// The schema, in schema.graphql, exposes products with their reviews, and the orders
+// of the signed in user; its resolvers delegate to the product, order and user use
+// cases. The products and reviews resolved for the elements of a list are fetched
+// in batches by request scoped loaders, so a query costs a few use case calls
+// whatever the length of its lists.
+package delivery
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"net/http"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/orders"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+const (
+	// maxDepth caps how deeply a query nests its selections.
+	maxDepth = 8
+
+	// maxParallelism is how many fields of a query are resolved at once, and so how
+	// many keys a loader batches at most.
+	maxParallelism = 50
+)
+
+// GraphQLHandlers provides HTTP handler methods for the GraphQL endpoint.
+type GraphQLHandlers struct {
+	logger logger.Logger
+	prodUC products.ProductUC
+	schema *graphql.Schema
+}
+
+// NewGraphQLHandlers returns a new GraphQLHandlers resolving the schema with the
+// given use cases, showing products as storefront configures. It panics when the
+// schema does not match the resolvers.
+func NewGraphQLHandlers(logger logger.Logger, prodUC products.ProductUC, ordersUC orders.OrderUC,
+	authUC auth.AuthenticateUC, rates *exchange.Converter, storefront config.Storefront) *GraphQLHandlers {
+	root := &Resolver{logger: logger, prodUC: prodUC, ordersUC: ordersUC, authUC: authUC, rates: rates,
+		hideStock: storefront.HideStock}
+
+	return &GraphQLHandlers{
+		logger: logger,
+		prodUC: prodUC,
+		schema: graphql.MustParseSchema(schemaSource, root,
+			graphql.MaxDepth(maxDepth), graphql.MaxParallelism(maxParallelism)),
+	}
+}
+
+// Query executes a GraphQL query. Products are priced in the currency of the
+// X-Currency header, else of the user preference, else of the shop. Errors of the
+// query are reported in the errors of the response, with a 200 status.
+// Endpoint: POST /api/v1/graphql
+// Expects JSON body: {"query": <query>, "operationName": <name>, "variables": {...}}.
+func (h *GraphQLHandlers) Query(w http.ResponseWriter, r *http.Request) {
+	currency, err := exchange.Currency(r)
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error executing graphql query: %v", err)
+		return
+	}
+
+	payload := struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}{}
+
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			_ = utils.TooLarge(w, r, maxBytesErr.Limit)
+		} else {
+			_ = utils.BadRequest(w, r, errors.New("invalid json"))
+		}
+		h.logger.Errorf("error reading json: %v", err)
+		return
+	}
+
+	if strings.TrimSpace(payload.Query) == "" {
+		_ = utils.BadRequest(w, r, errors.New("query must be provided"))
+		h.logger.Errorf("error executing graphql query: %v", "query is empty")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), currencyKey{}, currency)
+	ctx = withLoaders(ctx, newLoaders(h.prodUC))
+
+	res := h.schema.Exec(ctx, payload.Query, payload.OperationName, payload.Variables)
+
+	_ = utils.WriteJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,311 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
+	mockAuth "github.com/jofosuware/go/shopit/internal/auth/mocks"
+	"github.com/jofosuware/go/shopit/internal/graphql/delivery"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockOrders "github.com/jofosuware/go/shopit/internal/orders/mocks"
+	mockProducts "github.com/jofosuware/go/shopit/internal/products/mocks"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// query posts query to h as user, nil for an anonymous request.
+func query(t *testing.T, h *delivery.GraphQLHandlers, user *models.User, query string, headers ...string) (int, graphqlResponse) {
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	if user != nil {
+		req = req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, user))
+	}
+
+	rr := httptest.NewRecorder()
+	h.Query(rr, req)
+
+	var resp graphqlResponse
+	if rr.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	}
+	return rr.Code, resp
+}
+
+func newHandlers(t *testing.T) (*delivery.GraphQLHandlers, *mockLogger.Logger, *mockProducts.ProductUC, *mockOrders.OrderUC, *mockAuth.AuthenticateUC) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := mockProducts.NewProductUC(t)
+	ordersUC := mockOrders.NewOrderUC(t)
+	authUC := mockAuth.NewAuthenticateUC(t)
+	rates := exchange.New(exchange.NewStatic(map[string]float64{"EUR": 0.5}))
+
+	return delivery.NewGraphQLHandlers(logger, prodUC, ordersUC, authUC, rates, config.Storefront{}), logger, prodUC, ordersUC, authUC
+}
+
+func TestProductsQuery(t *testing.T) {
+	h, logger, prodUC, _, _ := newHandlers(t)
+
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	prods := []models.Product{
+		{ProductId: a, Name: "Tripod", Price: money.New(4999, "USD"), Images: []models.Images{{Url: "https://img.example.com/tripod.jpg"}}},
+		{ProductId: b, Name: "Lens", Price: money.New(19900, "USD")},
+		{ProductId: c, Name: "Strap", Price: money.New(1500, "USD")},
+	}
+
+	t.Run("Reviews of every product are fetched at once", func(t *testing.T) {
+		prodUC.On("GetProducts", "camera", "", 1).
+			Return(&models.GetProd{ProductCount: 3, ResPerPage: 4, Products: prods}, nil).Once()
+		prodUC.On("GetReviewsByProductIds", mock.MatchedBy(func(ids []uuid.UUID) bool {
+			return assert.ElementsMatch(t, []uuid.UUID{a, b, c}, ids)
+		})).Return(map[uuid.UUID][]models.Reviews{
+			a: {{ReviewsId: uuid.New(), Name: "Ama", Rating: 5, Comment: "Sturdy", ProductId: a}},
+		}, nil).Once()
+
+		code, resp := query(t, h, nil, `{ products(keyword: "camera") { total products { name price { amount currency } images { url } reviews { name rating } } } }`)
+		require.Equal(t, http.StatusOK, code)
+		require.Empty(t, resp.Errors)
+
+		var data struct {
+			Products struct {
+				Total    int
+				Products []struct {
+					Name   string
+					Price  struct{ Amount, Currency string }
+					Images []struct {
+						URL string
+					}
+					Reviews []struct {
+						Name   string
+						Rating int
+					}
+				}
+			}
+		}
+		require.NoError(t, json.Unmarshal(resp.Data, &data))
+		assert.Equal(t, 3, data.Products.Total)
+		require.Len(t, data.Products.Products, 3)
+		assert.Equal(t, "49.99", data.Products.Products[0].Price.Amount)
+		assert.Equal(t, "https://img.example.com/tripod.jpg", data.Products.Products[0].Images[0].URL)
+		require.Len(t, data.Products.Products[0].Reviews, 1)
+		assert.Equal(t, 5, data.Products.Products[0].Reviews[0].Rating)
+		assert.Empty(t, data.Products.Products[1].Reviews)
+	})
+
+	t.Run("Prices are in the currency asked for", func(t *testing.T) {
+		require.NoError(t, money.Accept("EUR"))
+		t.Cleanup(func() { money.Accept() })
+
+		prodUC.On("GetProducts", "", "", 2).
+			Return(&models.GetProd{ProductCount: 3, ResPerPage: 4, Products: prods[:1]}, nil).Once()
+
+		code, resp := query(t, h, nil, `{ products(page: 2) { products { price { amount currency } } } }`,
+			exchange.Header, "eur")
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `{"products":{"products":[{"price":{"amount":"25.00","currency":"EUR"}}]}}`, string(resp.Data))
+	})
+
+	t.Run("Use case errors are not shown", func(t *testing.T) {
+		prodUC.On("GetProducts", "", "", 1).Return(nil, errors.New("pq: connection refused")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		code, resp := query(t, h, nil, `{ products { total } }`)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "something went wrong, try again", resp.Errors[0].Message)
+	})
+}
+
+func TestProductQuery(t *testing.T) {
+	h, _, prodUC, _, _ := newHandlers(t)
+
+	t.Run("Hidden product is not found", func(t *testing.T) {
+		id := uuid.New()
+		prodUC.On("GetProductsByIds", []uuid.UUID{id}).
+			Return([]*models.Product{{ProductId: id, Name: "Prototype", Hidden: true}}, nil).Once()
+
+		code, resp := query(t, h, nil, `{ product(id: "`+id.String()+`") { name } }`)
+		require.Equal(t, http.StatusOK, code)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"product":null}`, string(resp.Data))
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		code, resp := query(t, h, nil, `{ product(id: "42") { name } }`)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "invalid id", resp.Errors[0].Message)
+	})
+
+	t.Run("Stock", func(t *testing.T) {
+		id := uuid.New()
+		prod := &models.Product{ProductId: id, Status: models.ProductPublished, Stock: 3, LowStockThreshold: 5}
+		q := `{ product(id: "` + id.String() + `") { stock inStock lowStock } }`
+
+		prodUC.On("GetProductsByIds", []uuid.UUID{id}).Return([]*models.Product{prod}, nil).Once()
+		code, resp := query(t, h, nil, q)
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `{"product":{"stock":3,"inStock":true,"lowStock":true}}`, string(resp.Data))
+
+		hidden := delivery.NewGraphQLHandlers(mockLogger.NewLogger(t), prodUC, mockOrders.NewOrderUC(t),
+			mockAuth.NewAuthenticateUC(t), exchange.New(exchange.NewStatic(nil)), config.Storefront{HideStock: true})
+		prodUC.On("GetProductsByIds", []uuid.UUID{id}).Return([]*models.Product{prod}, nil).Once()
+		code, resp = query(t, hidden, nil, q)
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `{"product":{"stock":null,"inStock":true,"lowStock":true}}`, string(resp.Data))
+	})
+}
+
+func TestMeQuery(t *testing.T) {
+	h, _, prodUC, ordersUC, authUC := newHandlers(t)
+	user := &models.User{ID: uuid.New(), Name: "Kofi", Email: "kofi@example.com", Role: "user"}
+
+	t.Run("Anonymous request", func(t *testing.T) {
+		code, resp := query(t, h, nil, `{ me { name } }`)
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `{"me":null}`, string(resp.Data))
+	})
+
+	t.Run("Products of every order item are fetched at once", func(t *testing.T) {
+		tripod, lens := uuid.New(), uuid.New()
+		orders := []*models.Order{
+			{OrderID: uuid.New(), UserID: user.ID, OrderStatus: models.OrderProcessing,
+				OrderItems: []*models.Item{{Name: "Tripod", Quantity: 1, ProductID: tripod}, {Name: "Lens", Quantity: 2, ProductID: lens}}},
+			{OrderID: uuid.New(), UserID: user.ID, OrderStatus: models.OrderDelivered,
+				OrderItems: []*models.Item{{Name: "Tripod", Quantity: 1, ProductID: tripod}}},
+		}
+
+		authUC.On("GetUserDetails", user.ID).Return(user, nil).Once()
+		ordersUC.On("GetUserOrders", user.ID).Return(orders, nil).Once()
+		prodUC.On("GetProductsByIds", mock.MatchedBy(func(ids []uuid.UUID) bool {
+			return assert.ElementsMatch(t, []uuid.UUID{tripod, lens}, ids)
+		})).Return([]*models.Product{{ProductId: tripod, Name: "Tripod", Status: models.ProductPublished}}, nil).Once()
+
+		code, resp := query(t, h, user, `{ me { email orders { status items { quantity product { name } } } } }`)
+		require.Equal(t, http.StatusOK, code)
+		require.Empty(t, resp.Errors)
+
+		var data struct {
+			Me struct {
+				Email  string
+				Orders []struct {
+					Status string
+					Items  []struct {
+						Quantity int
+						Product  *struct{ Name string }
+					}
+				}
+			}
+		}
+		require.NoError(t, json.Unmarshal(resp.Data, &data))
+		assert.Equal(t, "kofi@example.com", data.Me.Email)
+		require.Len(t, data.Me.Orders, 2)
+		require.NotNil(t, data.Me.Orders[0].Items[0].Product)
+		assert.Equal(t, "Tripod", data.Me.Orders[0].Items[0].Product.Name)
+		// the lens was deleted since
+		assert.Nil(t, data.Me.Orders[0].Items[1].Product)
+	})
+}
+
+func TestOrderQuery(t *testing.T) {
+	h, _, _, ordersUC, _ := newHandlers(t)
+	user := &models.User{ID: uuid.New(), Role: "user"}
+
+	t.Run("Order of another user is not found", func(t *testing.T) {
+		id := uuid.New()
+		ordersUC.On("GetSingleOrder", id).Return(&models.Order{OrderID: id, UserID: uuid.New()}, nil).Once()
+
+		code, resp := query(t, h, user, `{ order(id: "`+id.String()+`") { status } }`)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "order not found", resp.Errors[0].Message)
+	})
+
+	t.Run("Admin finds any order", func(t *testing.T) {
+		id := uuid.New()
+		ordersUC.On("GetSingleOrder", id).
+			Return(&models.Order{OrderID: id, UserID: uuid.New(), OrderStatus: models.OrderShipped,
+				TotalPrice: money.New(2500, "EUR")}, nil).Once()
+
+		admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+		code, resp := query(t, h, admin, `{ order(id: "`+id.String()+`") { status totalPrice { amount currency } } }`)
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `{"order":{"status":"Shipped","totalPrice":{"amount":"25.00","currency":"EUR"}}}`, string(resp.Data))
+	})
+
+	t.Run("Order totals are broken down", func(t *testing.T) {
+		id := uuid.New()
+		ordersUC.On("GetSingleOrder", id).
+			Return(&models.Order{OrderID: id, UserID: user.ID, Currency: "EUR", ItemPrice: money.New(2000, "EUR"),
+				TaxPrice: money.New(300, "EUR"), CouponCode: "SAVE2", Discount: money.New(200, "EUR"),
+				TotalPrice: money.New(2100, "EUR")}, nil).Once()
+
+		code, resp := query(t, h, user, `{ order(id: "`+id.String()+`") { totals {
+			subtotal { amount } taxes { rate amount { amount } } discounts { kind code amount { amount } } grandTotal { amount }
+		} } }`)
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `{"order":{"totals":{
+			"subtotal":{"amount":"20.00"},
+			"taxes":[{"rate":"15","amount":{"amount":"3.00"}}],
+			"discounts":[{"kind":"coupon","code":"SAVE2","amount":{"amount":"2.00"}}],
+			"grandTotal":{"amount":"21.00"}
+		}}}`, string(resp.Data))
+	})
+
+	t.Run("Missing order", func(t *testing.T) {
+		id := uuid.New()
+		ordersUC.On("GetSingleOrder", id).Return(nil, sql.ErrNoRows).Once()
+
+		_, resp := query(t, h, user, `{ order(id: "`+id.String()+`") { status } }`)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "order not found", resp.Errors[0].Message)
+	})
+
+	t.Run("Anonymous request", func(t *testing.T) {
+		_, resp := query(t, h, nil, `{ order(id: "`+uuid.NewString()+`") { status } }`)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "user is not logged in", resp.Errors[0].Message)
+	})
+}
+
+func TestQuery(t *testing.T) {
+	h, logger, _, _, _ := newHandlers(t)
+
+	t.Run("Invalid json", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.Query(rr, httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"query":`)))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Query too deep", func(t *testing.T) {
+		code, resp := query(t, h, nil, `{ __schema { types { fields { type { ofType { ofType { ofType { ofType { name } } } } } } } } }`)
+		require.Equal(t, http.StatusOK, code)
+		require.NotEmpty(t, resp.Errors)
+		assert.Contains(t, resp.Errors[0].Message, "exceeds max depth")
+	})
+}
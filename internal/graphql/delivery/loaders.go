@@ -0,0 +1,137 @@
+package delivery
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/dataloader"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+)
+
+// loaderWait is how long a loader collects keys before fetching them in one batch.
+const loaderWait = 5 * time.Millisecond
+
+// loadersKey is the request context key of the loaders.
+type loadersKey struct{}
+
+// loaders batch the lookups the resolvers of a query make, so resolving a field of
+// every element of a list costs one use case call instead of one per element. They
+// also cache what they fetched for the rest of the query.
+type loaders struct {
+	products *dataloader.Loader
+	reviews  *dataloader.Loader
+}
+
+// newLoaders returns the loaders of one query.
+func newLoaders(prodUC products.ProductUC) *loaders {
+	return &loaders{
+		products: dataloader.NewBatchedLoader(productBatch(prodUC), dataloader.WithWait(loaderWait)),
+		reviews:  dataloader.NewBatchedLoader(reviewBatch(prodUC), dataloader.WithWait(loaderWait)),
+	}
+}
+
+// withLoaders returns a copy of ctx carrying l.
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+// loadersFrom returns the loaders of the query ctx belongs to.
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// loadProduct returns the product with the given id, nil when there is none.
+func loadProduct(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	v, err := loadersFrom(ctx).products.Load(ctx, dataloader.StringKey(id.String()))()
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*models.Product), nil
+}
+
+// loadReviews returns the reviews of the product with the given id.
+func loadReviews(ctx context.Context, id uuid.UUID) ([]models.Reviews, error) {
+	v, err := loadersFrom(ctx).reviews.Load(ctx, dataloader.StringKey(id.String()))()
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]models.Reviews), nil
+}
+
+// productBatch fetches the products of a batch of ids with GetProductsByIds.
+func productBatch(prodUC products.ProductUC) dataloader.BatchFunc {
+	return func(_ context.Context, keys dataloader.Keys) []*dataloader.Result {
+		ids, err := parseKeys(keys)
+		if err != nil {
+			return failBatch(len(keys), err)
+		}
+
+		prods, err := prodUC.GetProductsByIds(ids)
+		if err != nil {
+			return failBatch(len(keys), err)
+		}
+
+		byId := make(map[uuid.UUID]*models.Product, len(prods))
+		for _, p := range prods {
+			byId[p.ProductId] = p
+		}
+
+		results := make([]*dataloader.Result, len(ids))
+		for i, id := range ids {
+			results[i] = &dataloader.Result{Data: byId[id]}
+		}
+
+		return results
+	}
+}
+
+// reviewBatch fetches the reviews of a batch of product ids with
+// GetReviewsByProductIds.
+func reviewBatch(prodUC products.ProductUC) dataloader.BatchFunc {
+	return func(_ context.Context, keys dataloader.Keys) []*dataloader.Result {
+		ids, err := parseKeys(keys)
+		if err != nil {
+			return failBatch(len(keys), err)
+		}
+
+		reviews, err := prodUC.GetReviewsByProductIds(ids)
+		if err != nil {
+			return failBatch(len(keys), err)
+		}
+
+		results := make([]*dataloader.Result, len(ids))
+		for i, id := range ids {
+			results[i] = &dataloader.Result{Data: reviews[id]}
+		}
+
+		return results
+	}
+}
+
+// parseKeys returns the ids keys hold.
+func parseKeys(keys dataloader.Keys) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, len(keys))
+	for i, k := range keys {
+		id, err := uuid.Parse(k.String())
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+// failBatch returns n results failing with err.
+func failBatch(n int, err error) []*dataloader.Result {
+	results := make([]*dataloader.Result, n)
+	for i := range results {
+		results[i] = &dataloader.Result{Error: err}
+	}
+
+	return results
+}
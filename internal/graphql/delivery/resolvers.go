@@ -0,0 +1,394 @@
+package delivery
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/orders"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+var (
+	// errInternal replaces the errors a client cannot act on, which are logged instead.
+	errInternal = errors.New("something went wrong, try again")
+
+	errNotLoggedIn   = errors.New("user is not logged in")
+	errInvalidID     = errors.New("invalid id")
+	errOrderNotFound = errors.New("order not found")
+)
+
+// currencyKey is the request context key of the currency products are priced in.
+type currencyKey struct{}
+
+// Resolver resolves the queries of the schema with the product, order and user use
+// cases.
+type Resolver struct {
+	logger   logger.Logger
+	prodUC   products.ProductUC
+	ordersUC orders.OrderUC
+	authUC   auth.AuthenticateUC
+	rates    *exchange.Converter
+	// hideStock leaves the stock of products out, as config.Storefront
+	hideStock bool
+}
+
+// internal logs err and returns errInternal in its place.
+func (r *Resolver) internal(err error) error {
+	r.logger.Errorf("graphql: %v", err)
+	return errInternal
+}
+
+// Products resolves the products query.
+func (r *Resolver) Products(args struct {
+	Keyword  *string
+	Category *string
+	Page     int32
+}) (*productPageResolver, error) {
+	var keyword, category string
+	if args.Keyword != nil {
+		keyword = *args.Keyword
+	}
+	if args.Category != nil {
+		category = *args.Category
+	}
+	res, err := r.prodUC.GetProducts(keyword, category, int(args.Page))
+	if err != nil {
+		if errors.Is(err, products.ErrCategoryNotFound) {
+			return nil, err
+		}
+		return nil, r.internal(fmt.Errorf("error getting products: %w", err))
+	}
+
+	prods := make([]*productResolver, len(res.Products))
+	for i := range res.Products {
+		prods[i] = &productResolver{root: r, p: &res.Products[i]}
+	}
+
+	return &productPageResolver{total: res.ProductCount, perPage: res.ResPerPage, products: prods}, nil
+}
+
+// Product resolves the product query. Hidden and unpublished products are not found.
+func (r *Resolver) Product(ctx context.Context, args struct{ ID graphql.ID }) (*productResolver, error) {
+	id, err := uuid.Parse(string(args.ID))
+	if err != nil {
+		return nil, errInvalidID
+	}
+
+	p, err := loadProduct(ctx, id)
+	if err != nil {
+		return nil, r.internal(fmt.Errorf("error getting product: %w", err))
+	}
+	if p == nil || !p.Listed() {
+		return nil, nil
+	}
+
+	return &productResolver{root: r, p: p}, nil
+}
+
+// Me resolves the me query, null when no user is signed in.
+func (r *Resolver) Me(ctx context.Context) (*userResolver, error) {
+	user, ok := ctx.Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		return nil, nil
+	}
+
+	u, err := r.authUC.GetUserDetails(user.ID)
+	if err != nil {
+		return nil, r.internal(fmt.Errorf("error getting user details: %w", err))
+	}
+
+	return &userResolver{root: r, u: u}, nil
+}
+
+// Order resolves the order query. Users only find their own orders; admins find
+// any.
+func (r *Resolver) Order(ctx context.Context, args struct{ ID graphql.ID }) (*orderResolver, error) {
+	user, ok := ctx.Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		return nil, errNotLoggedIn
+	}
+
+	id, err := uuid.Parse(string(args.ID))
+	if err != nil {
+		return nil, errInvalidID
+	}
+
+	o, err := r.ordersUC.GetSingleOrder(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errOrderNotFound
+		}
+		return nil, r.internal(fmt.Errorf("error getting order: %w", err))
+	}
+	if o.UserID != user.ID && user.Role != models.RoleAdmin {
+		return nil, errOrderNotFound
+	}
+
+	return &orderResolver{root: r, o: o}, nil
+}
+
+type productPageResolver struct {
+	total    int
+	perPage  int
+	products []*productResolver
+}
+
+func (p *productPageResolver) Total() int32                 { return int32(p.total) }
+func (p *productPageResolver) PerPage() int32               { return int32(p.perPage) }
+func (p *productPageResolver) Products() []*productResolver { return p.products }
+
+type moneyResolver struct {
+	m money.Money
+}
+
+func (m moneyResolver) Amount() string { return m.m.Decimal() }
+
+func (m moneyResolver) Currency() string {
+	if m.m.Currency == "" {
+		return money.DefaultCurrency
+	}
+	return m.m.Currency
+}
+
+type productResolver struct {
+	root *Resolver
+	p    *models.Product
+}
+
+func (p *productResolver) ID() graphql.ID      { return graphql.ID(p.p.ProductId.String()) }
+func (p *productResolver) Name() string        { return p.p.Name }
+func (p *productResolver) Sku() *string        { return optional(p.p.SKU) }
+func (p *productResolver) Description() string { return p.p.Description }
+func (p *productResolver) Category() string    { return p.p.Category }
+func (p *productResolver) Seller() string      { return p.p.Seller }
+func (p *productResolver) InStock() bool       { return p.p.InStock() }
+func (p *productResolver) LowStock() bool      { return p.p.LowStock() }
+func (p *productResolver) Ratings() int32      { return int32(p.p.Ratings) }
+func (p *productResolver) NumOfReviews() int32 { return int32(p.p.NumOfReviews) }
+func (p *productResolver) Images() []imageResolver {
+	images := make([]imageResolver, len(p.p.Images))
+	for i, img := range p.p.Images {
+		images[i] = imageResolver{url: img.Url}
+	}
+	return images
+}
+
+// Stock returns the units of the product left, nil when the shop hides them.
+func (p *productResolver) Stock() *int32 {
+	if p.root.hideStock {
+		return nil
+	}
+
+	stock := int32(p.p.Stock)
+	return &stock
+}
+
+// Price returns the price of the product in the currency of the query, the shop
+// currency when it cannot be converted.
+func (p *productResolver) Price(ctx context.Context) moneyResolver {
+	currency, _ := ctx.Value(currencyKey{}).(string)
+	if currency == "" {
+		return moneyResolver{p.p.Price}
+	}
+
+	price, err := p.root.rates.Convert(p.p.Price, currency)
+	if err != nil {
+		p.root.logger.Errorf("error converting product price: %v", err)
+		return moneyResolver{p.p.Price}
+	}
+
+	return moneyResolver{price}
+}
+
+// Reviews returns the reviews of the product, fetched along with those of the other
+// products of the query.
+func (p *productResolver) Reviews(ctx context.Context) ([]*reviewResolver, error) {
+	reviews, err := loadReviews(ctx, p.p.ProductId)
+	if err != nil {
+		return nil, p.root.internal(fmt.Errorf("error getting reviews: %w", err))
+	}
+
+	res := make([]*reviewResolver, len(reviews))
+	for i := range reviews {
+		res[i] = &reviewResolver{r: &reviews[i]}
+	}
+
+	return res, nil
+}
+
+type imageResolver struct {
+	url string
+}
+
+func (i imageResolver) URL() string { return i.url }
+
+type reviewResolver struct {
+	r *models.Reviews
+}
+
+func (r *reviewResolver) ID() graphql.ID          { return graphql.ID(r.r.ReviewsId.String()) }
+func (r *reviewResolver) Name() string            { return r.r.Name }
+func (r *reviewResolver) Rating() int32           { return int32(r.r.Rating) }
+func (r *reviewResolver) Comment() string         { return r.r.Comment }
+func (r *reviewResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.r.CreatedAt} }
+
+type userResolver struct {
+	root *Resolver
+	u    *models.User
+}
+
+func (u *userResolver) ID() graphql.ID          { return graphql.ID(u.u.ID.String()) }
+func (u *userResolver) Name() string            { return u.u.Name }
+func (u *userResolver) Email() string           { return u.u.Email }
+func (u *userResolver) Role() string            { return u.u.Role }
+func (u *userResolver) Avatar() *string         { return optional(u.u.Avatar.Url) }
+func (u *userResolver) Currency() *string       { return optional(u.u.Currency) }
+func (u *userResolver) Locale() *string         { return optional(u.u.Locale) }
+func (u *userResolver) CreatedAt() graphql.Time { return graphql.Time{Time: u.u.CreatedAt} }
+
+// Orders returns the orders of the user, newest first.
+func (u *userResolver) Orders() ([]*orderResolver, error) {
+	ords, err := u.root.ordersUC.GetUserOrders(u.u.ID)
+	if err != nil {
+		return nil, u.root.internal(fmt.Errorf("error getting user orders: %w", err))
+	}
+
+	sort.SliceStable(ords, func(i, j int) bool { return ords[i].CreatedAt.After(ords[j].CreatedAt) })
+
+	res := make([]*orderResolver, len(ords))
+	for i, o := range ords {
+		res[i] = &orderResolver{root: u.root, o: o}
+	}
+
+	return res, nil
+}
+
+type orderResolver struct {
+	root *Resolver
+	o    *models.Order
+}
+
+func (o *orderResolver) ID() graphql.ID               { return graphql.ID(o.o.OrderID.String()) }
+func (o *orderResolver) Status() string               { return o.o.OrderStatus }
+func (o *orderResolver) Currency() string             { return moneyResolver{o.o.TotalPrice}.Currency() }
+func (o *orderResolver) ItemsPrice() moneyResolver    { return moneyResolver{o.o.ItemPrice} }
+func (o *orderResolver) TaxPrice() moneyResolver      { return moneyResolver{o.o.TaxPrice} }
+func (o *orderResolver) ShippingPrice() moneyResolver { return moneyResolver{o.o.ShippingPrice} }
+func (o *orderResolver) Discount() moneyResolver      { return moneyResolver{o.o.Discount} }
+func (o *orderResolver) TotalPrice() moneyResolver    { return moneyResolver{o.o.TotalPrice} }
+func (o *orderResolver) Totals() totalsResolver       { return totalsResolver{o.o.Totals()} }
+func (o *orderResolver) CouponCode() *string          { return optional(o.o.CouponCode) }
+func (o *orderResolver) PaymentStatus() string        { return o.o.PaymentInfo.Status }
+func (o *orderResolver) Shipping() shippingResolver   { return shippingResolver{&o.o.ShippingInfo} }
+func (o *orderResolver) PaidAt() *graphql.Time        { return optionalTime(o.o.PaidAt) }
+func (o *orderResolver) DeliveredAt() *graphql.Time   { return optionalTime(o.o.DeliveredAt) }
+func (o *orderResolver) CreatedAt() graphql.Time      { return graphql.Time{Time: o.o.CreatedAt} }
+
+func (o *orderResolver) Items() []*itemResolver {
+	items := make([]*itemResolver, len(o.o.OrderItems))
+	for i, item := range o.o.OrderItems {
+		items[i] = &itemResolver{root: o.root, i: item}
+	}
+	return items
+}
+
+type totalsResolver struct {
+	t models.OrderTotals
+}
+
+func (t totalsResolver) Subtotal() moneyResolver   { return moneyResolver{t.t.Subtotal} }
+func (t totalsResolver) Shipping() moneyResolver   { return moneyResolver{t.t.Shipping} }
+func (t totalsResolver) GrandTotal() moneyResolver { return moneyResolver{t.t.GrandTotal} }
+
+func (t totalsResolver) Taxes() []taxLineResolver {
+	lines := make([]taxLineResolver, len(t.t.Taxes))
+	for i, l := range t.t.Taxes {
+		lines[i] = taxLineResolver{l}
+	}
+	return lines
+}
+
+func (t totalsResolver) Discounts() []discountLineResolver {
+	lines := make([]discountLineResolver, len(t.t.Discounts))
+	for i, l := range t.t.Discounts {
+		lines[i] = discountLineResolver{l}
+	}
+	return lines
+}
+
+type taxLineResolver struct {
+	l models.TaxLine
+}
+
+func (l taxLineResolver) Rate() *string         { return optional(l.l.Rate) }
+func (l taxLineResolver) Amount() moneyResolver { return moneyResolver{l.l.Amount} }
+
+type discountLineResolver struct {
+	l models.DiscountLine
+}
+
+func (l discountLineResolver) Kind() string          { return l.l.Kind }
+func (l discountLineResolver) Code() *string         { return optional(l.l.Code) }
+func (l discountLineResolver) Amount() moneyResolver { return moneyResolver{l.l.Amount} }
+
+type shippingResolver struct {
+	s *models.Shipping
+}
+
+func (s shippingResolver) Address() string    { return s.s.Address }
+func (s shippingResolver) City() string       { return s.s.City }
+func (s shippingResolver) PostalCode() string { return s.s.PostalCode }
+func (s shippingResolver) Country() string    { return s.s.Country }
+func (s shippingResolver) PhoneNo() string    { return s.s.PhoneNo }
+
+type itemResolver struct {
+	root *Resolver
+	i    *models.Item
+}
+
+func (i *itemResolver) Name() string         { return i.i.Name }
+func (i *itemResolver) Quantity() int32      { return int32(i.i.Quantity) }
+func (i *itemResolver) Price() moneyResolver { return moneyResolver{i.i.Price} }
+func (i *itemResolver) Image() string        { return i.i.Image }
+
+// Product returns the product ordered, fetched along with those of the other items
+// of the query.
+func (i *itemResolver) Product(ctx context.Context) (*productResolver, error) {
+	p, err := loadProduct(ctx, i.i.ProductID)
+	if err != nil {
+		return nil, i.root.internal(fmt.Errorf("error getting product: %w", err))
+	}
+	if p == nil || !p.Listed() {
+		return nil, nil
+	}
+
+	return &productResolver{root: i.root, p: p}, nil
+}
+
+// optional returns s, nil when it is empty.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// optionalTime returns t, nil when it is zero.
+func optionalTime(t time.Time) *graphql.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &graphql.Time{Time: t}
+}
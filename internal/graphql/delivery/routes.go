@@ -0,0 +1,19 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// GraphQLRouter returns a chi.Router with the GraphQL route.
+//
+//   - POST / → Execute a query (signed in for the user and its orders)
+func (h *GraphQLHandlers) GraphQLRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.With(utils.MayAuthenticate).Post("/", h.Query)
+
+	return mux
+}
@@ -0,0 +1,127 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	pb "github.com/jofosuware/go/shopit/pkg/pb/shopit/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// parseID parses the id in field of a request, returns an invalid argument error
+// when it is not a UUID.
+func parseID(field, id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "%s must be a valid id", field)
+	}
+
+	return parsed, nil
+}
+
+// toMoney converts an amount, in DefaultCurrency when it has no currency.
+func toMoney(m money.Money) *pb.Money {
+	currency := m.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	return &pb.Money{Amount: m.Amount, Currency: currency}
+}
+
+// toTimestamp converts a time, nil for the zero time.
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
+
+func toProduct(p *models.Product) *pb.Product {
+	res := &pb.Product{
+		Id:           p.ProductId.String(),
+		Name:         p.Name,
+		Sku:          p.SKU,
+		Description:  p.Description,
+		Price:        toMoney(p.Price),
+		Category:     p.Category,
+		Seller:       p.Seller,
+		Stock:        int32(p.Stock),
+		Ratings:      int32(p.Ratings),
+		NumOfReviews: int32(p.NumOfReviews),
+		Hidden:       p.Hidden,
+		Images:       make([]*pb.Image, len(p.Images)),
+		CreatedAt:    toTimestamp(p.CreatedAt),
+	}
+	if p.CategoryId.Valid {
+		res.CategoryId = p.CategoryId.UUID.String()
+	}
+	for i, img := range p.Images {
+		res.Images[i] = &pb.Image{Url: img.Url, PublicId: img.PublicId}
+	}
+
+	return res
+}
+
+func toReview(r models.Reviews) *pb.Review {
+	return &pb.Review{
+		Id:        r.ReviewsId.String(),
+		ProductId: r.ProductId.String(),
+		UserId:    r.UserId.String(),
+		Name:      r.Name,
+		Rating:    int32(r.Rating),
+		Comment:   r.Comment,
+		CreatedAt: toTimestamp(r.CreatedAt),
+	}
+}
+
+func toOrder(o *models.Order) *pb.Order {
+	res := &pb.Order{
+		Id:       o.OrderID.String(),
+		UserId:   o.UserID.String(),
+		Status:   o.OrderStatus,
+		Currency: o.Currency,
+		Items:    make([]*pb.OrderItem, len(o.OrderItems)),
+		Shipping: &pb.Shipping{
+			Address:    o.ShippingInfo.Address,
+			City:       o.ShippingInfo.City,
+			PostalCode: o.ShippingInfo.PostalCode,
+			Country:    o.ShippingInfo.Country,
+			PhoneNo:    o.ShippingInfo.PhoneNo,
+		},
+		Payment: &pb.Payment{
+			Id:       o.PaymentInfo.ID,
+			Provider: o.PaymentInfo.Provider,
+			Status:   o.PaymentInfo.Status,
+		},
+		ItemsPrice:    toMoney(o.ItemPrice),
+		TaxPrice:      toMoney(o.TaxPrice),
+		ShippingPrice: toMoney(o.ShippingPrice),
+		Discount:      toMoney(o.Discount),
+		TotalPrice:    toMoney(o.TotalPrice),
+		CouponCode:    o.CouponCode,
+		Gift:          o.Gift,
+		PaidAt:        toTimestamp(o.PaidAt),
+		DeliveredAt:   toTimestamp(o.DeliveredAt),
+		CreatedAt:     toTimestamp(o.CreatedAt),
+	}
+	for i, item := range o.OrderItems {
+		res.Items[i] = &pb.OrderItem{
+			ProductId: item.ProductID.String(),
+			Name:      item.Name,
+			Quantity:  int32(item.Quantity),
+			Price:     toMoney(item.Price),
+			Image:     item.Image,
+		}
+		if item.VariantID.Valid {
+			res.Items[i].VariantId = item.VariantID.UUID.String()
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,90 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/integration"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	pb "github.com/jofosuware/go/shopit/pkg/pb/shopit/v1"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// APIKeyMetadata is the metadata key carrying the API key of a call.
+const APIKeyMetadata = "x-api-key"
+
+// scopes maps the full name of each method to the scope its callers need. Methods
+// missing from it are denied.
+var scopes = map[string]string{
+	pb.ProductService_GetProduct_FullMethodName:         models.ScopeProductsRead,
+	pb.ProductService_BatchGetProducts_FullMethodName:   models.ScopeProductsRead,
+	pb.ProductService_ListProducts_FullMethodName:       models.ScopeProductsRead,
+	pb.ProductService_ListProductReviews_FullMethodName: models.ScopeProductsRead,
+	pb.OrderService_GetOrder_FullMethodName:             models.ScopeOrdersRead,
+	pb.OrderService_ListUserOrders_FullMethodName:       models.ScopeOrdersRead,
+}
+
+// interceptors run around every call of the server.
+type interceptors struct {
+	logger        logger.Logger
+	integrationUC integration.IntegrationUC
+}
+
+// authenticate only lets through calls with a valid API key granted the scope of
+// their method, and stores the service principal of the key in their context.
+func (i *interceptors) authenticate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	keys := md.Get(APIKeyMetadata)
+	if len(keys) == 0 || keys[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing api key")
+	}
+
+	key, err := i.integrationUC.Authenticate(keys[0])
+	if err != nil {
+		if errors.Is(err, integration.ErrInvalidAPIKey) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, internalError(ctx, i.logger, fmt.Errorf("error authenticating api key: %w", err))
+	}
+
+	principal := key.Principal()
+	scope, ok := scopes[info.FullMethod]
+	if !ok || !principal.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "api key lacks the %s scope", scope)
+	}
+
+	return handler(context.WithValue(ctx, utils.ServiceContextKey, principal), req)
+}
+
+// recoverPanic turns a panic of a call into an internal error, so it does not take
+// down the server.
+func (i *interceptors) recoverPanic(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (res interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = internalError(ctx, i.logger, fmt.Errorf("panic: %v\n%s", p, debug.Stack()))
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+// internalError logs err under a new error id, and returns the internal error the
+// caller gets instead: it only carries the id, to report to support.
+func internalError(ctx context.Context, logger logger.Logger, err error) error {
+	errorID := uuid.New().String()
+	method, _ := grpc.Method(ctx)
+
+	logger.Errorf("error id %s: %s: %v", errorID, method, err)
+
+	return status.Errorf(codes.Internal, "internal server error, contact support with the error id %s", errorID)
+}
@@ -0,0 +1,59 @@
+package delivery
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jofosuware/go/shopit/internal/orders"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	pb "github.com/jofosuware/go/shopit/pkg/pb/shopit/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// orderServer implements the OrderService over the order use case.
+type orderServer struct {
+	pb.UnimplementedOrderServiceServer
+	logger   logger.Logger
+	ordersUC orders.OrderUC
+}
+
+// GetOrder returns an order with its items, shipping and payment.
+func (s *orderServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := s.ordersUC.GetSingleOrder(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, orders.ErrOrderNotFound.Error())
+		}
+		return nil, internalError(ctx, s.logger, fmt.Errorf("error getting order: %w", err))
+	}
+
+	return toOrder(o), nil
+}
+
+// ListUserOrders returns the orders of a user, none for a user that does not exist.
+func (s *orderServer) ListUserOrders(ctx context.Context, req *pb.ListUserOrdersRequest) (*pb.ListUserOrdersResponse, error) {
+	id, err := parseID("user_id", req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	ords, err := s.ordersUC.GetUserOrders(id)
+	if err != nil {
+		return nil, internalError(ctx, s.logger, fmt.Errorf("error getting user orders: %w", err))
+	}
+
+	res := &pb.ListUserOrdersResponse{Orders: make([]*pb.Order, len(ords))}
+	for i, o := range ords {
+		res.Orders[i] = toOrder(o)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,86 @@
+package delivery_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	pb "github.com/jofosuware/go/shopit/pkg/pb/shopit/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetOrder(t *testing.T) {
+	s := newTestServer(t)
+	client := pb.NewOrderServiceClient(s.conn)
+	id, productID, variantID := uuid.New(), uuid.New(), uuid.New()
+	paid := time.Date(2026, 10, 2, 14, 0, 0, 0, time.UTC)
+
+	t.Run("Order with its items", func(t *testing.T) {
+		s.grant(models.ScopeOrdersRead)
+		s.ordersUC.On("GetSingleOrder", id).Return(&models.Order{
+			OrderID:      id,
+			OrderStatus:  "Shipped",
+			Currency:     "EUR",
+			ShippingInfo: models.Shipping{City: "Accra", Country: "Ghana"},
+			PaymentInfo:  models.Payment{ID: "pi_1", Provider: models.PaymentStripe, Status: models.PaymentSucceeded},
+			OrderItems: []*models.Item{{
+				ProductID: productID,
+				VariantID: uuid.NullUUID{UUID: variantID, Valid: true},
+				Name:      "Desk lamp",
+				Quantity:  2,
+				Price:     money.New(2500, "EUR"),
+			}},
+			TotalPrice: money.New(5000, "EUR"),
+			PaidAt:     paid,
+		}, nil).Once()
+
+		o, err := client.GetOrder(withKey(testKey), &pb.GetOrderRequest{Id: id.String()})
+		require.NoError(t, err)
+		assert.Equal(t, "Shipped", o.Status)
+		assert.Equal(t, "Accra", o.Shipping.City)
+		assert.Equal(t, "pi_1", o.Payment.Id)
+		assert.Equal(t, productID.String(), o.Items[0].ProductId)
+		assert.Equal(t, variantID.String(), o.Items[0].VariantId)
+		assert.Equal(t, int32(2), o.Items[0].Quantity)
+		assert.Equal(t, int64(5000), o.TotalPrice.Amount)
+		assert.Equal(t, paid, o.PaidAt.AsTime())
+		assert.Nil(t, o.DeliveredAt)
+	})
+
+	t.Run("Missing order", func(t *testing.T) {
+		s.grant(models.ScopeOrdersRead)
+		s.ordersUC.On("GetSingleOrder", id).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := client.GetOrder(withKey(testKey), &pb.GetOrderRequest{Id: id.String()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Key without the scope", func(t *testing.T) {
+		s.grant(models.ScopeProductsRead)
+
+		_, err := client.GetOrder(withKey(testKey), &pb.GetOrderRequest{Id: id.String()})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestListUserOrders(t *testing.T) {
+	s := newTestServer(t)
+	userID := uuid.New()
+	s.grant(models.ScopeOrdersRead)
+	s.ordersUC.On("GetUserOrders", userID).Return([]*models.Order{
+		{OrderID: uuid.New(), UserID: userID},
+		{OrderID: uuid.New(), UserID: userID},
+	}, nil).Once()
+
+	res, err := pb.NewOrderServiceClient(s.conn).ListUserOrders(withKey(testKey),
+		&pb.ListUserOrdersRequest{UserId: userID.String()})
+	require.NoError(t, err)
+	require.Len(t, res.Orders, 2)
+	assert.Equal(t, userID.String(), res.Orders[1].UserId)
+}
@@ -0,0 +1,128 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	pb "github.com/jofosuware/go/shopit/pkg/pb/shopit/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchProducts caps the ids of one BatchGetProducts call.
+const maxBatchProducts = 100
+
+// productServer implements the ProductService over the product use case.
+type productServer struct {
+	pb.UnimplementedProductServiceServer
+	logger logger.Logger
+	prodUC products.ProductUC
+}
+
+// GetProduct returns a product with its images, hidden or not.
+func (s *productServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	prods, err := s.prodUC.GetProductsByIds([]uuid.UUID{id})
+	if err != nil {
+		return nil, internalError(ctx, s.logger, fmt.Errorf("error getting product: %w", err))
+	}
+	if len(prods) == 0 {
+		return nil, status.Error(codes.NotFound, products.ErrProductNotFound.Error())
+	}
+
+	return toProduct(prods[0]), nil
+}
+
+// BatchGetProducts returns the products with the given ids that exist, in one use
+// case call.
+func (s *productServer) BatchGetProducts(ctx context.Context, req *pb.BatchGetProductsRequest) (*pb.BatchGetProductsResponse, error) {
+	if len(req.GetIds()) > maxBatchProducts {
+		return nil, status.Errorf(codes.InvalidArgument, "ids must not be more than %d", maxBatchProducts)
+	}
+
+	ids := make([]uuid.UUID, len(req.GetIds()))
+	for i, v := range req.GetIds() {
+		id, err := parseID(fmt.Sprintf("ids[%d]", i), v)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+
+	res := &pb.BatchGetProductsResponse{}
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	prods, err := s.prodUC.GetProductsByIds(ids)
+	if err != nil {
+		return nil, internalError(ctx, s.logger, fmt.Errorf("error getting products: %w", err))
+	}
+
+	res.Products = make([]*pb.Product, len(prods))
+	for i, p := range prods {
+		res.Products[i] = toProduct(p)
+	}
+
+	return res, nil
+}
+
+// ListProducts returns a page of the visible products, as the storefront lists them.
+func (s *productServer) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
+	if req.GetPage() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page must not be negative")
+	}
+
+	page := int(req.GetPage())
+	if page == 0 {
+		page = 1
+	}
+
+	got, err := s.prodUC.GetProducts(req.GetKeyword(), req.GetCategory(), page)
+	if err != nil {
+		if errors.Is(err, products.ErrCategoryNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, internalError(ctx, s.logger, fmt.Errorf("error getting products: %w", err))
+	}
+
+	res := &pb.ListProductsResponse{
+		Products: make([]*pb.Product, len(got.Products)),
+		Total:    int32(got.ProductCount),
+		PerPage:  int32(got.ResPerPage),
+	}
+	for i := range got.Products {
+		res.Products[i] = toProduct(&got.Products[i])
+	}
+
+	return res, nil
+}
+
+// ListProductReviews returns the reviews of a product.
+func (s *productServer) ListProductReviews(ctx context.Context, req *pb.ListProductReviewsRequest) (*pb.ListProductReviewsResponse, error) {
+	id, err := parseID("product_id", req.GetProductId())
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.prodUC.GetProductReviews(id, models.ReviewsOldest)
+	if err != nil {
+		return nil, internalError(ctx, s.logger, fmt.Errorf("error getting reviews: %w", err))
+	}
+
+	res := &pb.ListProductReviewsResponse{Reviews: make([]*pb.Review, len(reviews))}
+	for i, r := range reviews {
+		res.Reviews[i] = toReview(r)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,148 @@
+package delivery_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	pb "github.com/jofosuware/go/shopit/pkg/pb/shopit/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetProduct(t *testing.T) {
+	s := newTestServer(t)
+	client := pb.NewProductServiceClient(s.conn)
+	id, categoryID := uuid.New(), uuid.New()
+	created := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+
+	t.Run("Hidden product is found", func(t *testing.T) {
+		s.grant(models.ScopeProductsRead)
+		s.prodUC.On("GetProductsByIds", []uuid.UUID{id}).Return([]*models.Product{{
+			ProductId:  id,
+			Name:       "Prototype",
+			Price:      money.New(4999, "EUR"),
+			CategoryId: uuid.NullUUID{UUID: categoryID, Valid: true},
+			Hidden:     true,
+			Stock:      3,
+			Images:     []models.Images{{PublicId: "products/a", Url: "https://img.example.com/a.png"}},
+			CreatedAt:  created,
+		}}, nil).Once()
+
+		p, err := client.GetProduct(withKey(testKey), &pb.GetProductRequest{Id: id.String()})
+		require.NoError(t, err)
+		assert.Equal(t, id.String(), p.Id)
+		assert.Equal(t, categoryID.String(), p.CategoryId)
+		assert.True(t, p.Hidden)
+		assert.Equal(t, int32(3), p.Stock)
+		assert.Equal(t, int64(4999), p.Price.Amount)
+		assert.Equal(t, "EUR", p.Price.Currency)
+		assert.Equal(t, "https://img.example.com/a.png", p.Images[0].Url)
+		assert.Equal(t, created, p.CreatedAt.AsTime())
+	})
+
+	t.Run("Missing product", func(t *testing.T) {
+		s.grant(models.ScopeProductsRead)
+		s.prodUC.On("GetProductsByIds", []uuid.UUID{id}).Return(nil, nil).Once()
+
+		_, err := client.GetProduct(withKey(testKey), &pb.GetProductRequest{Id: id.String()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		s.grant(models.ScopeProductsRead)
+
+		_, err := client.GetProduct(withKey(testKey), &pb.GetProductRequest{Id: "42"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestBatchGetProducts(t *testing.T) {
+	s := newTestServer(t)
+	client := pb.NewProductServiceClient(s.conn)
+
+	t.Run("Existing products", func(t *testing.T) {
+		ids := []uuid.UUID{uuid.New(), uuid.New()}
+		s.grant(models.ScopeProductsRead)
+		s.prodUC.On("GetProductsByIds", ids).Return([]*models.Product{{ProductId: ids[1], Name: "Lamp"}}, nil).Once()
+
+		res, err := client.BatchGetProducts(withKey(testKey),
+			&pb.BatchGetProductsRequest{Ids: []string{ids[0].String(), ids[1].String()}})
+		require.NoError(t, err)
+		require.Len(t, res.Products, 1)
+		assert.Equal(t, "Lamp", res.Products[0].Name)
+		assert.Equal(t, money.DefaultCurrency, res.Products[0].Price.Currency)
+	})
+
+	t.Run("Too many ids", func(t *testing.T) {
+		ids := make([]string, 101)
+		for i := range ids {
+			ids[i] = uuid.NewString()
+		}
+		s.grant(models.ScopeProductsRead)
+
+		_, err := client.BatchGetProducts(withKey(testKey), &pb.BatchGetProductsRequest{Ids: ids})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestListProducts(t *testing.T) {
+	s := newTestServer(t)
+	client := pb.NewProductServiceClient(s.conn)
+
+	t.Run("First page", func(t *testing.T) {
+		s.grant(models.ScopeProductsRead)
+		s.prodUC.On("GetProducts", "lamp", "lighting", 1).Return(&models.GetProd{
+			ProductCount: 5,
+			ResPerPage:   4,
+			Products:     []models.Product{{ProductId: uuid.New(), Name: "Desk lamp"}},
+		}, nil).Once()
+
+		res, err := client.ListProducts(withKey(testKey), &pb.ListProductsRequest{Keyword: "lamp", Category: "lighting"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(5), res.Total)
+		assert.Equal(t, int32(4), res.PerPage)
+		assert.Equal(t, "Desk lamp", res.Products[0].Name)
+	})
+
+	t.Run("Unknown category", func(t *testing.T) {
+		s.grant(models.ScopeProductsRead)
+		s.prodUC.On("GetProducts", "", "nope", 2).Return(nil, products.ErrCategoryNotFound).Once()
+
+		_, err := client.ListProducts(withKey(testKey), &pb.ListProductsRequest{Category: "nope", Page: 2})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Use case fails", func(t *testing.T) {
+		s.grant(models.ScopeProductsRead)
+		s.prodUC.On("GetProducts", "", "", 1).Return(nil, errors.New("db down")).Once()
+		s.logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+		_, err := client.ListProducts(withKey(testKey), &pb.ListProductsRequest{})
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestListProductReviews(t *testing.T) {
+	s := newTestServer(t)
+	id, userID := uuid.New(), uuid.New()
+	s.grant(models.ScopeProductsRead)
+	s.prodUC.On("GetProductReviews", id, models.ReviewsOldest).Return([]models.Reviews{{
+		ReviewsId: uuid.New(), ProductId: id, UserId: userID, Name: "Ama", Rating: 5, Comment: "Bright",
+	}}, nil).Once()
+
+	res, err := pb.NewProductServiceClient(s.conn).ListProductReviews(withKey(testKey),
+		&pb.ListProductReviewsRequest{ProductId: id.String()})
+	require.NoError(t, err)
+	require.Len(t, res.Reviews, 1)
+	assert.Equal(t, userID.String(), res.Reviews[0].UserId)
+	assert.Equal(t, int32(5), res.Reviews[0].Rating)
+	assert.Nil(t, res.Reviews[0].CreatedAt)
+}
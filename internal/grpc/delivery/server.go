@@ -0,0 +1,79 @@
+// Package delivery serves the gRPC API internal services read the catalog and the
+// orders with, without going through the REST endpoints.
+//
+// The services are defined in proto/shopit/v1 and delegate to the product and order
+// use cases. Callers authenticate with an integration API key in the x-api-key
+// metadata, granted the scope of the service they call: products:read or
+// orders:read. The server runs over TLS, optionally requiring client certificates.
+package delivery
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jofosuware/go/shopit/internal/integration"
+	"github.com/jofosuware/go/shopit/internal/orders"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	pb "github.com/jofosuware/go/shopit/pkg/pb/shopit/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// NewServer returns a gRPC server with the product and order services registered,
+// authenticating its callers with the API keys of integrationUC. It serves over TLS
+// with tlsConfig, in plain text when it is nil.
+func NewServer(logger logger.Logger, prodUC products.ProductUC, ordersUC orders.OrderUC,
+	integrationUC integration.IntegrationUC, tlsConfig *tls.Config) *grpc.Server {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	i := &interceptors{logger: logger, integrationUC: integrationUC}
+	srv := grpc.NewServer(
+		grpc.Creds(creds),
+		grpc.ChainUnaryInterceptor(i.recoverPanic, i.authenticate),
+	)
+
+	pb.RegisterProductServiceServer(srv, &productServer{logger: logger, prodUC: prodUC})
+	pb.RegisterOrderServiceServer(srv, &orderServer{logger: logger, ordersUC: ordersUC})
+
+	return srv
+}
+
+// TLSConfig returns the TLS config of a server with the certificate and key in
+// certFile and keyFile. With clientCAFile, clients must present a certificate
+// signed by one of the CAs it holds.
+func TLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading grpc certificate: %w", err)
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading grpc client CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("grpc client CA file holds no PEM certificate")
+		}
+
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
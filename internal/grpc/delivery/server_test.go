@@ -0,0 +1,227 @@
+package delivery_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/grpc/delivery"
+	"github.com/jofosuware/go/shopit/internal/integration"
+	mockIntegration "github.com/jofosuware/go/shopit/internal/integration/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockOrders "github.com/jofosuware/go/shopit/internal/orders/mocks"
+	mockProducts "github.com/jofosuware/go/shopit/internal/products/mocks"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	pb "github.com/jofosuware/go/shopit/pkg/pb/shopit/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testKey is the API key the test clients call with.
+const testKey = "test-key"
+
+type testServer struct {
+	conn          *grpc.ClientConn
+	logger        *mockLogger.Logger
+	prodUC        *mockProducts.ProductUC
+	ordersUC      *mockOrders.OrderUC
+	integrationUC *mockIntegration.IntegrationUC
+}
+
+// newTestServer serves the gRPC API over an in-memory connection, with mocked use
+// cases, and returns a connection to it.
+func newTestServer(t *testing.T) *testServer {
+	s := &testServer{
+		logger:        mockLogger.NewLogger(t),
+		prodUC:        mockProducts.NewProductUC(t),
+		ordersUC:      mockOrders.NewOrderUC(t),
+		integrationUC: mockIntegration.NewIntegrationUC(t),
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := delivery.NewServer(s.logger, s.prodUC, s.ordersUC, s.integrationUC, nil)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	s.conn = conn
+
+	return s
+}
+
+// grant makes testKey a valid key granted scopes.
+func (s *testServer) grant(scopes ...string) {
+	s.integrationUC.On("Authenticate", testKey).
+		Return(&models.APIKey{ID: uuid.New(), Name: "warehouse", Scopes: scopes}, nil).Once()
+}
+
+// withKey returns a context calling with the API key.
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), delivery.APIKeyMetadata, key)
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := newTestServer(t)
+	client := pb.NewProductServiceClient(s.conn)
+	req := &pb.GetProductRequest{Id: uuid.NewString()}
+
+	t.Run("Missing key", func(t *testing.T) {
+		_, err := client.GetProduct(context.Background(), req)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Revoked key", func(t *testing.T) {
+		s.integrationUC.On("Authenticate", "revoked").Return(nil, integration.ErrInvalidAPIKey).Once()
+
+		_, err := client.GetProduct(withKey("revoked"), req)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Key without the scope", func(t *testing.T) {
+		s.grant(models.ScopeOrdersRead)
+
+		_, err := client.GetProduct(withKey(testKey), req)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Authentication fails", func(t *testing.T) {
+		s.integrationUC.On("Authenticate", testKey).Return(nil, errors.New("connection refused")).Once()
+		s.logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+		_, err := client.GetProduct(withKey(testKey), req)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.NotContains(t, err.Error(), "connection refused")
+	})
+}
+
+func TestRecoverPanic(t *testing.T) {
+	s := newTestServer(t)
+	id := uuid.New()
+	s.grant(models.ScopeProductsRead)
+	s.prodUC.On("GetProductsByIds", []uuid.UUID{id}).Run(func(mock.Arguments) { panic("boom") }).Once()
+	s.logger.On("Errorf", mock.Anything, mock.Anything, pb.ProductService_GetProduct_FullMethodName, mock.Anything).Once()
+
+	_, err := pb.NewProductServiceClient(s.conn).GetProduct(withKey(testKey), &pb.GetProductRequest{Id: id.String()})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newCert(t, dir, "ca", nil, nil)
+	newCert(t, dir, "server", ca, caKey)
+	newCert(t, dir, "client", ca, caKey)
+
+	tlsConfig, err := delivery.TLSConfig(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"),
+		filepath.Join(dir, "ca.crt"))
+	require.NoError(t, err)
+
+	s := &testServer{
+		logger:        mockLogger.NewLogger(t),
+		prodUC:        mockProducts.NewProductUC(t),
+		ordersUC:      mockOrders.NewOrderUC(t),
+		integrationUC: mockIntegration.NewIntegrationUC(t),
+	}
+	srv := delivery.NewServer(s.logger, s.prodUC, s.ordersUC, s.integrationUC, tlsConfig)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	call := func(certs ...tls.Certificate) error {
+		creds := credentials.NewTLS(&tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: certs})
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(withKey(testKey), 5*time.Second)
+		defer cancel()
+		_, err = pb.NewProductServiceClient(conn).BatchGetProducts(ctx, &pb.BatchGetProductsRequest{})
+		return err
+	}
+
+	t.Run("Client with a certificate", func(t *testing.T) {
+		cert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+		require.NoError(t, err)
+		s.integrationUC.On("Authenticate", testKey).
+			Return(&models.APIKey{Scopes: []string{models.ScopeProductsRead}}, nil).Once()
+
+		assert.NoError(t, call(cert))
+	})
+
+	t.Run("Client without a certificate", func(t *testing.T) {
+		assert.Equal(t, codes.Unavailable, status.Code(call()))
+	})
+
+	t.Run("Missing certificate", func(t *testing.T) {
+		_, err := delivery.TLSConfig(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "server.key"), "")
+		assert.Error(t, err)
+	})
+}
+
+// newCert writes name.crt and name.key to dir, for a certificate of localhost
+// signed by parent, or a self-signed CA without one.
+func newCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".crt"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".key"),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
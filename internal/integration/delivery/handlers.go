@@ -0,0 +1,316 @@
+// Package delivery provides HTTP handlers for the integration endpoints.
+//
+// External systems (POS, ERP) push stock levels and pull new orders with a scoped
+// API key sent in the X-API-Key header; admins manage the keys. A valid key stands
+// in for a user: its service principal is stored in the request context. Admins also
+// register the webhooks the shop posts its events to, and read their delivery log;
+// the events themselves are documented with the schema of their payload.
+package delivery
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/integration"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// APIKeyHeader is the request header carrying the API key.
+const APIKeyHeader = "X-API-Key"
+
+// maxStockLevels caps the products of one inventory sync.
+const maxStockLevels = 1000
+
+// IntegrationHandlers provides HTTP handler methods for integration endpoints.
+type IntegrationHandlers struct {
+	logger        logger.Logger
+	integrationUC integration.IntegrationUC
+}
+
+// NewIntegrationHandlers returns a new IntegrationHandlers.
+func NewIntegrationHandlers(logger logger.Logger, integrationUC integration.IntegrationUC) *IntegrationHandlers {
+	return &IntegrationHandlers{
+		logger:        logger,
+		integrationUC: integrationUC,
+	}
+}
+
+// Authenticate only lets through requests with a valid API key, and stores the
+// service principal of the key in the request context.
+func (h *IntegrationHandlers) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plainText := r.Header.Get(APIKeyHeader)
+		if plainText == "" {
+			_ = utils.InvalidCredentials(w, r)
+			return
+		}
+
+		key, err := h.integrationUC.Authenticate(plainText)
+		if err != nil {
+			if errors.Is(err, integration.ErrInvalidAPIKey) {
+				_ = utils.InvalidCredentials(w, r)
+				return
+			}
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error authenticating api key: %w", err))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), utils.ServiceContextKey, key.Principal())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireScope only lets through requests with a valid API key granted scope. It
+// authenticates the key unless Authenticate already did.
+func (h *IntegrationHandlers) RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		check := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := r.Context().Value(utils.ServiceContextKey).(*models.ServicePrincipal)
+			if !principal.HasScope(scope) {
+				_ = utils.Forbidden(w, r)
+				h.logger.Errorf("api key %s lacks scope %s", principal.KeyID, scope)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+
+		authenticated := h.Authenticate(check)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Value(utils.ServiceContextKey).(*models.ServicePrincipal); ok {
+				check.ServeHTTP(w, r)
+				return
+			}
+			authenticated.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetPrincipal returns the service principal of the API key, so an external system
+// can check its key and scopes.
+// Endpoint: GET /api/v1/integration/me
+func (h *IntegrationHandlers) GetPrincipal(w http.ResponseWriter, r *http.Request) {
+	principal, ok := r.Context().Value(utils.ServiceContextKey).(*models.ServicePrincipal)
+	if !ok {
+		_ = utils.InvalidCredentials(w, r)
+		h.logger.Errorf("no service principal in context")
+		return
+	}
+
+	jr := struct {
+		Success   bool                     `json:"success"`
+		Principal *models.ServicePrincipal `json:"principal"`
+	}{
+		Success:   true,
+		Principal: principal,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// SyncInventory sets the stock of the listed products; unlisted products are left alone.
+// Endpoint: PUT /api/v1/integration/inventory
+// Expects JSON body: {"items": [{"product": <id>, "stock": <int>}]}.
+func (h *IntegrationHandlers) SyncInventory(w http.ResponseWriter, r *http.Request) {
+	var req inventoryRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	levels := make([]models.StockLevel, 0, len(req.Items))
+	for _, i := range req.Items {
+		levels = append(levels, models.StockLevel{ProductID: uuid.MustParse(i.Product), Stock: i.Stock})
+	}
+
+	report, err := h.integrationUC.SyncInventory(levels)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error syncing inventory: %w", err))
+		return
+	}
+
+	jr := struct {
+		Success bool `json:"success"`
+		*models.InventorySyncReport
+	}{
+		Success:             true,
+		InventorySyncReport: report,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// GetOrders returns the orders after the since cursor, oldest first. Pass the returned
+// next cursor as since to fetch the following page or the next new orders. The cursor
+// is opaque; an RFC 3339 time is also accepted and returns the orders created from
+// that time on.
+// Endpoint: GET /api/v1/integration/orders?since=<cursor>&limit=<int>
+func (h *IntegrationHandlers) GetOrders(w http.ResponseWriter, r *http.Request) {
+	var cursor models.OrderCursor
+	if s := r.URL.Query().Get("since"); s != "" {
+		c, err := parseCursor(s)
+		if err != nil {
+			_ = utils.BadRequest(w, r, errors.New("since must be a cursor returned as next"))
+			h.logger.Errorf("error parsing since: %v", err)
+			return
+		}
+		cursor = c
+	}
+
+	var limit int
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			_ = utils.BadRequest(w, r, errors.New("limit must be a positive number"))
+			h.logger.Errorf("error parsing limit: %v", l)
+			return
+		}
+		limit = n
+	}
+
+	ords, err := h.integrationUC.GetOrdersSince(cursor, limit)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error fetching orders: %w", err))
+		return
+	}
+
+	next := cursor
+	if len(ords) > 0 {
+		last := ords[len(ords)-1]
+		next = models.OrderCursor{CreatedAt: last.CreatedAt, OrderID: last.OrderID}
+	}
+	if ords == nil {
+		ords = []*models.Order{}
+	}
+
+	jr := struct {
+		Success bool            `json:"success"`
+		Orders  []*models.Order `json:"orders"`
+		Next    string          `json:"next"`
+	}{
+		Success: true,
+		Orders:  ords,
+		Next:    formatCursor(next),
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// formatCursor returns c in the opaque form handed to integrations.
+func formatCursor(c models.OrderCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + " " + c.OrderID.String()))
+}
+
+// parseCursor parses a cursor returned by formatCursor, or an RFC 3339 time, which
+// stands for the orders created from that time on.
+func parseCursor(s string) (models.OrderCursor, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return models.OrderCursor{CreatedAt: t}, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return models.OrderCursor{}, err
+	}
+
+	created, id, ok := strings.Cut(string(b), " ")
+	if !ok {
+		return models.OrderCursor{}, fmt.Errorf("malformed cursor %q", s)
+	}
+
+	var c models.OrderCursor
+	if c.CreatedAt, err = time.Parse(time.RFC3339Nano, created); err != nil {
+		return models.OrderCursor{}, err
+	}
+	if c.OrderID, err = uuid.Parse(id); err != nil {
+		return models.OrderCursor{}, err
+	}
+
+	return c, nil
+}
+
+// CreateAPIKey creates an API key (admin). The plain text key is only returned here.
+// Endpoint: POST /api/v1/integration/keys
+// Expects JSON body: {"name": <string>, "scopes": [<scope>]}.
+func (h *IntegrationHandlers) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req apiKeyRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	key, err := h.integrationUC.CreateAPIKey(req.Name, req.Scopes)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error creating api key: %w", err))
+		return
+	}
+
+	jr := struct {
+		Success bool           `json:"success"`
+		Key     *models.APIKey `json:"key"`
+	}{
+		Success: true,
+		Key:     key,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusCreated, jr)
+}
+
+// GetAPIKeys lists the API keys (admin).
+// Endpoint: GET /api/v1/integration/keys
+func (h *IntegrationHandlers) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.integrationUC.GetAPIKeys()
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error fetching api keys: %w", err))
+		return
+	}
+
+	if keys == nil {
+		keys = []*models.APIKey{}
+	}
+
+	jr := struct {
+		Success bool             `json:"success"`
+		Keys    []*models.APIKey `json:"keys"`
+	}{
+		Success: true,
+		Keys:    keys,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// RevokeAPIKey revokes an API key (admin).
+// Endpoint: DELETE /api/v1/integration/keys/{id}
+func (h *IntegrationHandlers) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	if err := h.integrationUC.RevokeAPIKey(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = utils.BadRequest(w, r, errors.New("api key not found"))
+			h.logger.Errorf("error revoking api key: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error revoking api key: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success bool `json:"success"`
+	}{Success: true})
+}
@@ -0,0 +1,195 @@
+package delivery_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/integration"
+	"github.com/jofosuware/go/shopit/internal/integration/delivery"
+	"github.com/jofosuware/go/shopit/internal/integration/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireScope(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	integrationUC := mocks.NewIntegrationUC(t)
+
+	h := delivery.NewIntegrationHandlers(logger, integrationUC)
+	protected := h.RequireScope(models.ScopeInventoryWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(key string) int {
+		req := httptest.NewRequest(http.MethodPut, "/inventory", nil)
+		if key != "" {
+			req.Header.Set(delivery.APIKeyHeader, key)
+		}
+		rr := httptest.NewRecorder()
+		protected.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	t.Run("Key with the scope", func(t *testing.T) {
+		integrationUC.On("Authenticate", "good").
+			Return(&models.APIKey{Scopes: []string{models.ScopeInventoryWrite}}, nil).Once()
+
+		assert.Equal(t, http.StatusNoContent, serve("good"))
+	})
+
+	t.Run("Key without the scope", func(t *testing.T) {
+		integrationUC.On("Authenticate", "reader").
+			Return(&models.APIKey{Scopes: []string{models.ScopeOrdersRead}}, nil).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusForbidden, serve("reader"))
+	})
+
+	t.Run("Invalid key", func(t *testing.T) {
+		integrationUC.On("Authenticate", "bad").Return(nil, integration.ErrInvalidAPIKey).Once()
+
+		assert.Equal(t, http.StatusUnauthorized, serve("bad"))
+	})
+
+	t.Run("Missing key", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(""))
+	})
+}
+
+func TestGetPrincipal(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	integrationUC := mocks.NewIntegrationUC(t)
+
+	h := delivery.NewIntegrationHandlers(logger, integrationUC)
+	protected := h.Authenticate(h.RequireScope(models.ScopeOrdersRead)(http.HandlerFunc(h.GetPrincipal)))
+
+	t.Run("Principal of the key", func(t *testing.T) {
+		keyID := uuid.New()
+		integrationUC.On("Authenticate", "good").
+			Return(&models.APIKey{ID: keyID, Name: "ERP", Scopes: []string{models.ScopeOrdersRead}}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(delivery.APIKeyHeader, "good")
+		rr := httptest.NewRecorder()
+		protected.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Principal models.ServicePrincipal `json:"principal"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, keyID, resp.Principal.KeyID)
+		assert.Equal(t, "ERP", resp.Principal.Name)
+		assert.Equal(t, []string{models.ScopeOrdersRead}, resp.Principal.Scopes)
+	})
+
+	t.Run("Missing key", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		protected.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/me", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestSyncInventory(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	integrationUC := mocks.NewIntegrationUC(t)
+
+	h := delivery.NewIntegrationHandlers(logger, integrationUC)
+	prodID := uuid.New()
+
+	t.Run("Stock levels are applied", func(t *testing.T) {
+		integrationUC.On("SyncInventory", []models.StockLevel{{ProductID: prodID, Stock: 4}}).
+			Return(&models.InventorySyncReport{Updated: 1, Unknown: []uuid.UUID{}}, nil).Once()
+
+		body := fmt.Sprintf(`{"items":[{"product":%q,"stock":4}]}`, prodID)
+		req := httptest.NewRequest(http.MethodPut, "/inventory", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+
+		h.SyncInventory(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var res struct {
+			Updated int `json:"updated"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		assert.Equal(t, 1, res.Updated)
+	})
+
+	t.Run("Negative stock", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		body := fmt.Sprintf(`{"items":[{"product":%q,"stock":-1}]}`, prodID)
+		req := httptest.NewRequest(http.MethodPut, "/inventory", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+
+		h.SyncInventory(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+func TestGetOrders(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	integrationUC := mocks.NewIntegrationUC(t)
+
+	h := delivery.NewIntegrationHandlers(logger, integrationUC)
+	since := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	last := models.Order{OrderID: uuid.New(), CreatedAt: since.Add(time.Minute)}
+
+	get := func(t *testing.T, query string) (string, []*models.Order) {
+		req := httptest.NewRequest(http.MethodGet, "/orders?"+query, nil)
+		rr := httptest.NewRecorder()
+
+		h.GetOrders(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var res struct {
+			Orders []*models.Order `json:"orders"`
+			Next   string          `json:"next"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		return res.Next, res.Orders
+	}
+
+	var next string
+	t.Run("Next cursor is the last order", func(t *testing.T) {
+		integrationUC.On("GetOrdersSince", models.OrderCursor{CreatedAt: since}, 10).
+			Return([]*models.Order{{OrderID: uuid.New(), CreatedAt: since.Add(time.Second)}, &last}, nil).Once()
+
+		var ords []*models.Order
+		next, ords = get(t, "since="+since.Format(time.RFC3339)+"&limit=10")
+		assert.Len(t, ords, 2)
+	})
+
+	t.Run("Cursor resumes after the last order", func(t *testing.T) {
+		cursor := models.OrderCursor{CreatedAt: last.CreatedAt, OrderID: last.OrderID}
+		integrationUC.On("GetOrdersSince", mock.MatchedBy(func(c models.OrderCursor) bool {
+			return c.CreatedAt.Equal(cursor.CreatedAt) && c.OrderID == cursor.OrderID
+		}), 0).Return(nil, nil).Once()
+
+		again, ords := get(t, "since="+next)
+		assert.Empty(t, ords)
+		assert.Equal(t, next, again, "the cursor stays put until new orders come in")
+	})
+
+	t.Run("Invalid since", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/orders?since=yesterday", nil)
+		rr := httptest.NewRecorder()
+
+		h.GetOrders(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
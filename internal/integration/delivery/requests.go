@@ -0,0 +1,51 @@
+package delivery
+
+import (
+	"fmt"
+
+	"github.com/jofosuware/go/shopit/internal/integration"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// inventoryRequest is the body of SyncInventory.
+type inventoryRequest struct {
+	Items []stockRequest `json:"items" validate:"min=1,dive"`
+}
+
+// stockRequest is a stock level of an inventoryRequest.
+type stockRequest struct {
+	Product string `json:"product" validate:"uuid"`
+	Stock   int    `json:"stock" validate:"min=0"`
+}
+
+func (req *inventoryRequest) Validate(v *validator.Validator) {
+	v.CheckCode(len(req.Items) <= maxStockLevels, "items", validator.CodeTooMany,
+		fmt.Sprintf("items must have at most %d items", maxStockLevels))
+}
+
+// apiKeyRequest is the body of CreateAPIKey.
+type apiKeyRequest struct {
+	Name   string   `json:"name" validate:"required"`
+	Scopes []string `json:"scopes" validate:"min=1"`
+}
+
+func (req *apiKeyRequest) Validate(v *validator.Validator) {
+	for _, s := range req.Scopes {
+		v.CheckCode(models.ValidScope(s), "scopes", validator.CodeOneOf, integration.ErrInvalidScope.Error())
+	}
+}
+
+// webhookRequest is the body of CreateWebhook.
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events" validate:"min=1"`
+}
+
+func (req *webhookRequest) Validate(v *validator.Validator) {
+	v.CheckCode(models.ValidWebhookURL(req.URL), "url", validator.CodeURL, integration.ErrInvalidWebhookURL.Error())
+	for _, e := range req.Events {
+		v.CheckCode(models.ValidWebhookEvent(e), "events", validator.CodeOneOf,
+			integration.ErrInvalidWebhookEvent.Error())
+	}
+}
@@ -0,0 +1,47 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// IntegrationRouter returns a chi.Router with the integration routes.
+//
+//   - PUT    /inventory                → Push stock levels (inventory:write)
+//   - GET    /orders                   → Pull new orders (orders:read)
+//   - GET    /me                       → Service principal of the API key (any scope)
+//   - GET    /events                   → Webhook events with their schema and sample
+//   - POST   /keys                     → Create an API key (admin)
+//   - GET    /keys                     → List API keys (admin)
+//   - DELETE /keys/{id}                → Revoke an API key (admin)
+//   - POST   /webhooks                 → Register a webhook (admin)
+//   - GET    /webhooks                 → List webhooks (admin)
+//   - DELETE /webhooks/{id}            → Delete a webhook (admin)
+//   - GET    /webhooks/{id}/deliveries → Delivery log of a webhook (admin)
+func (h *IntegrationHandlers) IntegrationRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.With(h.RequireScope(models.ScopeInventoryWrite)).Put("/inventory", h.SyncInventory)
+	mux.With(h.RequireScope(models.ScopeOrdersRead)).Get("/orders", h.GetOrders)
+	mux.With(h.Authenticate).Get("/me", h.GetPrincipal)
+	mux.Get("/events", h.GetEventTypes)
+
+	mux.Group(func(r chi.Router) {
+		r.Use(utils.IsAuthenticated)
+		r.Use(utils.IsAdmin)
+
+		r.Post("/keys", h.CreateAPIKey)
+		r.Get("/keys", h.GetAPIKeys)
+		r.Delete("/keys/{id}", h.RevokeAPIKey)
+
+		r.Post("/webhooks", h.CreateWebhook)
+		r.Get("/webhooks", h.GetWebhooks)
+		r.Delete("/webhooks/{id}", h.DeleteWebhook)
+		r.Get("/webhooks/{id}/deliveries", h.GetWebhookDeliveries)
+	})
+
+	return mux
+}
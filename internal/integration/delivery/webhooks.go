@@ -0,0 +1,154 @@
+package delivery
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// CreateWebhook registers a webhook posted the events it subscribes to (admin). The
+// response carries its signing secret, which is not shown again.
+// Endpoint: POST /api/v1/integration/webhooks
+// Expects JSON body: {"url": <url>, "events": ["order.created", ...]}.
+func (h *IntegrationHandlers) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	hook, err := h.integrationUC.CreateWebhook(req.URL, req.Events)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error creating webhook: %w", err))
+		return
+	}
+
+	jr := struct {
+		Success bool            `json:"success"`
+		Webhook *models.Webhook `json:"webhook"`
+	}{
+		Success: true,
+		Webhook: hook,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusCreated, jr)
+}
+
+// GetWebhooks lists the webhooks (admin).
+// Endpoint: GET /api/v1/integration/webhooks
+func (h *IntegrationHandlers) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.integrationUC.GetWebhooks()
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error fetching webhooks: %w", err))
+		return
+	}
+
+	if hooks == nil {
+		hooks = []*models.Webhook{}
+	}
+
+	jr := struct {
+		Success  bool              `json:"success"`
+		Webhooks []*models.Webhook `json:"webhooks"`
+	}{
+		Success:  true,
+		Webhooks: hooks,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// DeleteWebhook deletes a webhook with its pending deliveries and log (admin).
+// Endpoint: DELETE /api/v1/integration/webhooks/{id}
+func (h *IntegrationHandlers) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	if err := h.integrationUC.DeleteWebhook(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = utils.BadRequest(w, r, errors.New("webhook not found"))
+			h.logger.Errorf("error deleting webhook: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error deleting webhook: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success bool `json:"success"`
+	}{Success: true})
+}
+
+// GetWebhookDeliveries returns the delivery log of a webhook, newest first (admin).
+// Endpoint: GET /api/v1/integration/webhooks/{id}/deliveries?limit={n}
+func (h *IntegrationHandlers) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	var limit int
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			_ = utils.BadRequest(w, r, errors.New("limit must be a positive number"))
+			h.logger.Errorf("error parsing limit: %v", l)
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.integrationUC.GetWebhookDeliveries(id, limit)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error fetching webhook deliveries: %w", err))
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []*models.WebhookDelivery{}
+	}
+
+	jr := struct {
+		Success    bool                      `json:"success"`
+		Deliveries []*models.WebhookDelivery `json:"deliveries"`
+	}{
+		Success:    true,
+		Deliveries: deliveries,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// GetEventTypes lists the webhook events with the JSON Schema of their payload and a
+// sample of it, for integrators building their receivers. It documents the shop, so
+// it needs no key.
+// Endpoint: GET /api/v1/integration/events
+func (h *IntegrationHandlers) GetEventTypes(w http.ResponseWriter, r *http.Request) {
+	types, err := h.integrationUC.GetEventTypes()
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting event types: %w", err))
+		return
+	}
+
+	jr := struct {
+		Success bool               `json:"success"`
+		Events  []models.EventType `json:"events"`
+	}{
+		Success: true,
+		Events:  types,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
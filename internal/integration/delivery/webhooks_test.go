@@ -0,0 +1,139 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/integration/delivery"
+	"github.com/jofosuware/go/shopit/internal/integration/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	integrationUC := mocks.NewIntegrationUC(t)
+
+	h := delivery.NewIntegrationHandlers(logger, integrationUC)
+	create := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.CreateWebhook(rr, httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(body)))
+		return rr
+	}
+
+	t.Run("Webhook is created with its secret", func(t *testing.T) {
+		events := []string{models.WebhookOrderCreated, models.WebhookProductUpdated}
+		integrationUC.On("CreateWebhook", "https://erp.example.com/hooks", events).
+			Return(&models.Webhook{ID: uuid.New(), URL: "https://erp.example.com/hooks", Events: events,
+				Secret: "whsec_abc"}, nil).Once()
+
+		rr := create(`{"url":"https://erp.example.com/hooks","events":["order.created","product.updated"]}`)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		var resp struct {
+			Webhook models.Webhook `json:"webhook"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "whsec_abc", resp.Webhook.Secret)
+	})
+
+	t.Run("Unknown event and invalid url", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := create(`{"url":"erp.example.com","events":["user.registered"]}`)
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		var resp struct {
+			Errors map[string]string `json:"errors"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Contains(t, resp.Errors, "url")
+		assert.Contains(t, resp.Errors, "events")
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	integrationUC := mocks.NewIntegrationUC(t)
+
+	h := delivery.NewIntegrationHandlers(logger, integrationUC)
+	remove := func(id uuid.UUID) int {
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		req := httptest.NewRequest(http.MethodDelete, "/webhooks/"+id.String(), nil)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+
+		rr := httptest.NewRecorder()
+		h.DeleteWebhook(rr, req)
+		return rr.Code
+	}
+
+	t.Run("Webhook is deleted", func(t *testing.T) {
+		id := uuid.New()
+		integrationUC.On("DeleteWebhook", id).Return(nil).Once()
+
+		assert.Equal(t, http.StatusOK, remove(id))
+	})
+
+	t.Run("Unknown webhook", func(t *testing.T) {
+		id := uuid.New()
+		integrationUC.On("DeleteWebhook", id).Return(sql.ErrNoRows).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, remove(id))
+	})
+}
+
+func TestGetEventTypes(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	integrationUC := mocks.NewIntegrationUC(t)
+
+	h := delivery.NewIntegrationHandlers(logger, integrationUC)
+	list := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.IntegrationRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/events", nil))
+		return rr
+	}
+
+	t.Run("Events are listed without a key", func(t *testing.T) {
+		integrationUC.On("GetEventTypes").Return([]models.EventType{{
+			Name:        models.WebhookOrderCreated,
+			Description: "An order was placed.",
+			Schema:      json.RawMessage(`{"type":"object"}`),
+			Sample:      json.RawMessage(`{"event":"order.created"}`),
+		}}, nil).Once()
+
+		rr := list()
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Events []struct {
+				Name   string                 `json:"name"`
+				Schema map[string]interface{} `json:"schema"`
+				Sample map[string]interface{} `json:"sample"`
+			} `json:"events"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		require.Len(t, res.Events, 1)
+		assert.Equal(t, models.WebhookOrderCreated, res.Events[0].Name)
+		assert.Equal(t, "object", res.Events[0].Schema["type"])
+		assert.Equal(t, "order.created", res.Events[0].Sample["event"])
+	})
+
+	t.Run("Use case failure", func(t *testing.T) {
+		integrationUC.On("GetEventTypes").Return(nil, assert.AnError).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusInternalServerError, list().Code)
+	})
+}
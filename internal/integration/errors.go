@@ -0,0 +1,21 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// ErrInvalidAPIKey is returned when an API key is unknown or revoked.
+var ErrInvalidAPIKey = errors.New("invalid or revoked api key")
+
+// ErrInvalidScope is returned when a scope is not one of models.Scopes.
+var ErrInvalidScope = fmt.Errorf("scopes must be one of: %s", strings.Join(models.Scopes, ", "))
+
+// ErrInvalidWebhookEvent is returned when an event is not one of models.WebhookEvents.
+var ErrInvalidWebhookEvent = fmt.Errorf("events must be one of: %s", strings.Join(models.WebhookEvents, ", "))
+
+// ErrInvalidWebhookURL is returned when a webhook URL is not an absolute http or https URL.
+var ErrInvalidWebhookURL = errors.New("url must be an absolute http or https url")
@@ -0,0 +1,386 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	events "github.com/jofosuware/go/shopit/pkg/events"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "github.com/jofosuware/go/shopit/internal/models"
+
+	uuid "github.com/google/uuid"
+)
+
+// IntegrationUC is an autogenerated mock type for the IntegrationUC type
+type IntegrationUC struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: key
+func (_m *IntegrationUC) Authenticate(key string) (*models.APIKey, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authenticate")
+	}
+
+	var r0 *models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.APIKey, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.APIKey); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAPIKey provides a mock function with given fields: name, scopes
+func (_m *IntegrationUC) CreateAPIKey(name string, scopes []string) (*models.APIKey, error) {
+	ret := _m.Called(name, scopes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 *models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string) (*models.APIKey, error)); ok {
+		return rf(name, scopes)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string) *models.APIKey); ok {
+		r0 = rf(name, scopes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(name, scopes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateWebhook provides a mock function with given fields: url, _a1
+func (_m *IntegrationUC) CreateWebhook(url string, _a1 []string) (*models.Webhook, error) {
+	ret := _m.Called(url, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebhook")
+	}
+
+	var r0 *models.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string) (*models.Webhook, error)); ok {
+		return rf(url, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string) *models.Webhook); ok {
+		r0 = rf(url, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(url, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteWebhook provides a mock function with given fields: id
+func (_m *IntegrationUC) DeleteWebhook(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeliverWebhooks provides a mock function with given fields: ctx
+func (_m *IntegrationUC) DeliverWebhooks(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeliverWebhooks")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAPIKeys provides a mock function with given fields:
+func (_m *IntegrationUC) GetAPIKeys() ([]*models.APIKey, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeys")
+	}
+
+	var r0 []*models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.APIKey, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.APIKey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEventTypes provides a mock function with given fields:
+func (_m *IntegrationUC) GetEventTypes() ([]models.EventType, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEventTypes")
+	}
+
+	var r0 []models.EventType
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]models.EventType, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []models.EventType); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.EventType)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrdersSince provides a mock function with given fields: cursor, limit
+func (_m *IntegrationUC) GetOrdersSince(cursor models.OrderCursor, limit int) ([]*models.Order, error) {
+	ret := _m.Called(cursor, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrdersSince")
+	}
+
+	var r0 []*models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderCursor, int) ([]*models.Order, error)); ok {
+		return rf(cursor, limit)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderCursor, int) []*models.Order); ok {
+		r0 = rf(cursor, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderCursor, int) error); ok {
+		r1 = rf(cursor, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhookDeliveries provides a mock function with given fields: webhookID, limit
+func (_m *IntegrationUC) GetWebhookDeliveries(webhookID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	ret := _m.Called(webhookID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhookDeliveries")
+	}
+
+	var r0 []*models.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) ([]*models.WebhookDelivery, error)); ok {
+		return rf(webhookID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) []*models.WebhookDelivery); ok {
+		r0 = rf(webhookID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(webhookID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhooks provides a mock function with given fields:
+func (_m *IntegrationUC) GetWebhooks() ([]*models.Webhook, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhooks")
+	}
+
+	var r0 []*models.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.Webhook, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.Webhook); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HandleEvent provides a mock function with given fields: e
+func (_m *IntegrationUC) HandleEvent(e events.Event) error {
+	ret := _m.Called(e)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HandleEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(events.Event) error); ok {
+		r0 = rf(e)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeAPIKey provides a mock function with given fields: id
+func (_m *IntegrationUC) RevokeAPIKey(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SyncInventory provides a mock function with given fields: levels
+func (_m *IntegrationUC) SyncInventory(levels []models.StockLevel) (*models.InventorySyncReport, error) {
+	ret := _m.Called(levels)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SyncInventory")
+	}
+
+	var r0 *models.InventorySyncReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]models.StockLevel) (*models.InventorySyncReport, error)); ok {
+		return rf(levels)
+	}
+	if rf, ok := ret.Get(0).(func([]models.StockLevel) *models.InventorySyncReport); ok {
+		r0 = rf(levels)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.InventorySyncReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]models.StockLevel) error); ok {
+		r1 = rf(levels)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIntegrationUC creates a new instance of IntegrationUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIntegrationUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IntegrationUC {
+	mock := &IntegrationUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,420 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "github.com/jofosuware/go/shopit/internal/models"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+// ClaimWebhookDeliveries provides a mock function with given fields: ctx, now, leaseUntil, limit
+func (_m *Repo) ClaimWebhookDeliveries(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	ret := _m.Called(ctx, now, leaseUntil, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimWebhookDeliveries")
+	}
+
+	var r0 []*models.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, int) ([]*models.WebhookDelivery, error)); ok {
+		return rf(ctx, now, leaseUntil, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, int) []*models.WebhookDelivery); ok {
+		r0 = rf(ctx, now, leaseUntil, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time, int) error); ok {
+		r1 = rf(ctx, now, leaseUntil, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteWebhook provides a mock function with given fields: id
+func (_m *Repo) DeleteWebhook(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteWebhookDeliveries provides a mock function with given fields: ctx, before
+func (_m *Repo) DeleteWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhookDeliveries")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchAPIKeyByHash provides a mock function with given fields: hash
+func (_m *Repo) FetchAPIKeyByHash(hash []byte) (*models.APIKey, error) {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchAPIKeyByHash")
+	}
+
+	var r0 *models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]byte) (*models.APIKey, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func([]byte) *models.APIKey); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]byte) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchAPIKeys provides a mock function with given fields:
+func (_m *Repo) FetchAPIKeys() ([]*models.APIKey, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchAPIKeys")
+	}
+
+	var r0 []*models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.APIKey, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.APIKey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchOrdersSince provides a mock function with given fields: cursor, until, limit
+func (_m *Repo) FetchOrdersSince(cursor models.OrderCursor, until time.Time, limit int) ([]*models.Order, error) {
+	ret := _m.Called(cursor, until, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchOrdersSince")
+	}
+
+	var r0 []*models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderCursor, time.Time, int) ([]*models.Order, error)); ok {
+		return rf(cursor, until, limit)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderCursor, time.Time, int) []*models.Order); ok {
+		r0 = rf(cursor, until, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderCursor, time.Time, int) error); ok {
+		r1 = rf(cursor, until, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchWebhookDeliveries provides a mock function with given fields: webhookID, limit
+func (_m *Repo) FetchWebhookDeliveries(webhookID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	ret := _m.Called(webhookID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchWebhookDeliveries")
+	}
+
+	var r0 []*models.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) ([]*models.WebhookDelivery, error)); ok {
+		return rf(webhookID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) []*models.WebhookDelivery); ok {
+		r0 = rf(webhookID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(webhookID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchWebhooks provides a mock function with given fields:
+func (_m *Repo) FetchWebhooks() ([]*models.Webhook, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchWebhooks")
+	}
+
+	var r0 []*models.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.Webhook, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.Webhook); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertAPIKey provides a mock function with given fields: key, hash
+func (_m *Repo) InsertAPIKey(key models.APIKey, hash []byte) (*models.APIKey, error) {
+	ret := _m.Called(key, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertAPIKey")
+	}
+
+	var r0 *models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.APIKey, []byte) (*models.APIKey, error)); ok {
+		return rf(key, hash)
+	}
+	if rf, ok := ret.Get(0).(func(models.APIKey, []byte) *models.APIKey); ok {
+		r0 = rf(key, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.APIKey, []byte) error); ok {
+		r1 = rf(key, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertWebhook provides a mock function with given fields: w
+func (_m *Repo) InsertWebhook(w models.Webhook) (*models.Webhook, error) {
+	ret := _m.Called(w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertWebhook")
+	}
+
+	var r0 *models.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Webhook) (*models.Webhook, error)); ok {
+		return rf(w)
+	}
+	if rf, ok := ret.Get(0).(func(models.Webhook) *models.Webhook); ok {
+		r0 = rf(w)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Webhook) error); ok {
+		r1 = rf(w)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertWebhookDeliveries provides a mock function with given fields: eventID, event, payload
+func (_m *Repo) InsertWebhookDeliveries(eventID uuid.UUID, event string, payload []byte) (int64, error) {
+	ret := _m.Called(eventID, event, payload)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertWebhookDeliveries")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string, []byte) (int64, error)); ok {
+		return rf(eventID, event, payload)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string, []byte) int64); ok {
+		r0 = rf(eventID, event, payload)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string, []byte) error); ok {
+		r1 = rf(eventID, event, payload)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAPIKey provides a mock function with given fields: id
+func (_m *Repo) RevokeAPIKey(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateKeyLastUsed provides a mock function with given fields: id, at
+func (_m *Repo) UpdateKeyLastUsed(id uuid.UUID, at time.Time) error {
+	ret := _m.Called(id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateKeyLastUsed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) error); ok {
+		r0 = rf(id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStockLevel provides a mock function with given fields: productID, stock
+func (_m *Repo) UpdateStockLevel(productID uuid.UUID, stock int) error {
+	ret := _m.Called(productID, stock)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStockLevel")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) error); ok {
+		r0 = rf(productID, stock)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateWebhookDelivery provides a mock function with given fields: d
+func (_m *Repo) UpdateWebhookDelivery(d *models.WebhookDelivery) error {
+	ret := _m.Called(d)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWebhookDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.WebhookDelivery) error); ok {
+		r0 = rf(d)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
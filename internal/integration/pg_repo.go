@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// InsertAPIKey inserts an API key with the hash of its plain text, returns the key and an error on failure
+	InsertAPIKey(key models.APIKey, hash []byte) (*models.APIKey, error)
+
+	// FetchAPIKeyByHash fetches an API key by the hash of its plain text, returns an error on failure
+	FetchAPIKeyByHash(hash []byte) (*models.APIKey, error)
+
+	// FetchAPIKeys fetches all API keys, returns an error on failure
+	FetchAPIKeys() ([]*models.APIKey, error)
+
+	// RevokeAPIKey revokes an API key, returns sql.ErrNoRows when there is no such key
+	RevokeAPIKey(id uuid.UUID) error
+
+	// UpdateKeyLastUsed records when an API key was last used, returns an error on failure
+	UpdateKeyLastUsed(id uuid.UUID, at time.Time) error
+
+	// UpdateStockLevel sets the stock of a product, returns sql.ErrNoRows when there is no such product
+	UpdateStockLevel(productID uuid.UUID, stock int) error
+
+	// FetchOrdersSince fetches up to limit orders after cursor created before until, oldest first, returns an error on failure
+	FetchOrdersSince(cursor models.OrderCursor, until time.Time, limit int) ([]*models.Order, error)
+
+	// InsertWebhook inserts a webhook with its secret, returns the webhook and an error on failure
+	InsertWebhook(w models.Webhook) (*models.Webhook, error)
+
+	// FetchWebhooks fetches all webhooks without their secret, returns an error on failure
+	FetchWebhooks() ([]*models.Webhook, error)
+
+	// DeleteWebhook deletes a webhook and its deliveries, returns sql.ErrNoRows when there is no such webhook
+	DeleteWebhook(id uuid.UUID) error
+
+	// InsertWebhookDeliveries queues an event for the webhooks subscribed to it, returns how many were queued
+	InsertWebhookDeliveries(eventID uuid.UUID, event string, payload []byte) (int64, error)
+
+	// ClaimWebhookDeliveries leases up to limit deliveries due at now until leaseUntil, returns an error on failure
+	ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*models.WebhookDelivery, error)
+
+	// UpdateWebhookDelivery records the outcome of the last attempt of a delivery, returns an error on failure
+	UpdateWebhookDelivery(d *models.WebhookDelivery) error
+
+	// FetchWebhookDeliveries fetches the latest limit deliveries of a webhook, returns an error on failure
+	FetchWebhookDeliveries(webhookID uuid.UUID, limit int) ([]*models.WebhookDelivery, error)
+
+	// DeleteWebhookDeliveries deletes the finished deliveries created before a time, returns how many were deleted
+	DeleteWebhookDeliveries(ctx context.Context, before time.Time) (int64, error)
+}
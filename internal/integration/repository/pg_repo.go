@@ -0,0 +1,211 @@
+// Package repository provides persistence for API keys, webhooks and their deliveries, and the data synced
+// with external systems.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// IntegrationRepository handles integration-related database operations.
+type IntegrationRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewIntegrationRepository returns a new IntegrationRepository.
+func NewIntegrationRepository(db *sql.DB) *IntegrationRepository {
+	return &IntegrationRepository{
+		DB: db,
+	}
+}
+
+// InsertAPIKey inserts an API key with the hash of its plain text.
+func (r *IntegrationRepository) InsertAPIKey(key models.APIKey, hash []byte) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into api_keys (name, hash, scopes, created_at) values ($1, $2, $3, $4)
+				returning key_id, created_at`
+
+	err := r.DB.QueryRowContext(ctx, query, key.Name, hash, strings.Join(key.Scopes, ","), time.Now()).
+		Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// FetchAPIKeyByHash fetches an API key by the hash of its plain text.
+func (r *IntegrationRepository) FetchAPIKeyByHash(hash []byte) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select key_id, name, scopes, revoked, last_used_at, created_at from api_keys where hash = $1`
+
+	return scanAPIKey(r.DB.QueryRowContext(ctx, query, hash))
+}
+
+// FetchAPIKeys fetches all API keys, newest first.
+func (r *IntegrationRepository) FetchAPIKeys() ([]*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select key_id, name, scopes, revoked, last_used_at, created_at from api_keys order by created_at desc`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key. It returns sql.ErrNoRows when there is no such key.
+func (r *IntegrationRepository) RevokeAPIKey(id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.DB.ExecContext(ctx, `update api_keys set revoked = true where key_id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(res)
+}
+
+// UpdateKeyLastUsed records when an API key was last used.
+func (r *IntegrationRepository) UpdateKeyLastUsed(id uuid.UUID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, `update api_keys set last_used_at = $1 where key_id = $2`, at, id)
+
+	return err
+}
+
+// UpdateStockLevel sets the stock of a product. It returns sql.ErrNoRows when there is no such product.
+func (r *IntegrationRepository) UpdateStockLevel(productID uuid.UUID, stock int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.DB.ExecContext(ctx, `update products set stock = $1 where product_id = $2`, stock, productID)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(res)
+}
+
+// FetchOrdersSince fetches up to limit orders after cursor and created before until,
+// in the order of their creation time and id, with their gift options so fulfilment
+// systems can honour them and their amounts in the order currency.
+func (r *IntegrationRepository) FetchOrdersSince(cursor models.OrderCursor, until time.Time, limit int) ([]*models.Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := `select order_id, user_id, paid_at, item_price, tax_price, shipping_price,
+		total_price, order_status, delivered_at, created_at, variant, gift, gift_message, hide_prices, currency
+		from orders where (created_at, order_id) > ($1, $2) and created_at < $3
+		order by created_at, order_id limit $4`
+
+	rows, err := r.DB.QueryContext(ctx, query, cursor.CreatedAt, cursor.OrderID, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ords []*models.Order
+	for rows.Next() {
+		var ord models.Order
+		err := rows.Scan(
+			&ord.OrderID,
+			&ord.UserID,
+			&ord.PaidAt,
+			&ord.ItemPrice,
+			&ord.TaxPrice,
+			&ord.ShippingPrice,
+			&ord.TotalPrice,
+			&ord.OrderStatus,
+			&ord.DeliveredAt,
+			&ord.CreatedAt,
+			&ord.Variant,
+			&ord.Gift,
+			&ord.GiftMessage,
+			&ord.HidePrices,
+			&ord.Currency,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ord.UseCurrency(ord.Currency)
+
+		ords = append(ords, &ord)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ords, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row scanner) (*models.APIKey, error) {
+	var (
+		k        models.APIKey
+		scopes   string
+		lastUsed sql.NullTime
+	)
+
+	if err := row.Scan(&k.ID, &k.Name, &scopes, &k.Revoked, &lastUsed, &k.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if scopes != "" {
+		k.Scopes = strings.Split(scopes, ",")
+	}
+	if lastUsed.Valid {
+		k.LastUsedAt = &lastUsed.Time
+	}
+
+	return &k, nil
+}
+
+// requireRow returns sql.ErrNoRows when res affected no row.
+func requireRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/integration/repository"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIntegrationRepository(db)
+	id := uuid.New()
+
+	mock.ExpectQuery(`insert into api_keys`).
+		WithArgs("erp", []byte("hash"), "inventory:write,orders:read", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"key_id", "created_at"}).AddRow(id, time.Now()))
+
+	key, err := repo.InsertAPIKey(models.APIKey{Name: "erp", Scopes: []string{models.ScopeInventoryWrite, models.ScopeOrdersRead}}, []byte("hash"))
+	require.NoError(t, err)
+	assert.Equal(t, id, key.ID)
+}
+
+func TestFetchAPIKeyByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIntegrationRepository(db)
+	query := regexp.QuoteMeta(`select key_id, name, scopes, revoked, last_used_at, created_at from api_keys where hash = $1`)
+
+	t.Run("Key is found", func(t *testing.T) {
+		id := uuid.New()
+		rows := sqlmock.NewRows([]string{"key_id", "name", "scopes", "revoked", "last_used_at", "created_at"}).
+			AddRow(id, "erp", "inventory:write,orders:read", false, nil, time.Now())
+		mock.ExpectQuery(query).WithArgs([]byte("hash")).WillReturnRows(rows)
+
+		key, err := repo.FetchAPIKeyByHash([]byte("hash"))
+		require.NoError(t, err)
+
+		assert.Equal(t, id, key.ID)
+		assert.Equal(t, []string{models.ScopeInventoryWrite, models.ScopeOrdersRead}, key.Scopes)
+		assert.Nil(t, key.LastUsedAt)
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs([]byte("other")).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.FetchAPIKeyByHash([]byte("other"))
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestUpdateStockLevel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIntegrationRepository(db)
+	query := regexp.QuoteMeta(`update products set stock = $1 where product_id = $2`)
+	id := uuid.New()
+
+	t.Run("Stock is set", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(5, id).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.UpdateStockLevel(id, 5))
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(5, id).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.UpdateStockLevel(id, 5), sql.ErrNoRows)
+	})
+}
+
+func TestFetchOrdersSince(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIntegrationRepository(db)
+	cursor := models.OrderCursor{CreatedAt: time.Now().Add(-time.Hour), OrderID: uuid.New()}
+	until := time.Now()
+	id := uuid.New()
+
+	rows := sqlmock.NewRows([]string{"order_id", "user_id", "paid_at", "item_price", "tax_price", "shipping_price",
+		"total_price", "order_status", "delivered_at", "created_at", "variant", "gift", "gift_message", "hide_prices",
+		"currency"}).
+		AddRow(id, uuid.New(), time.Now(), 100, 5, 10, 115, "Processing", time.Time{}, time.Now(), "", true, "Enjoy!", true, "EUR")
+	mock.ExpectQuery(`select order_id, .* from orders where \(created_at, order_id\) > \(\$1, \$2\) and created_at < \$3\s+order by created_at, order_id limit \$4`).
+		WithArgs(cursor.CreatedAt, cursor.OrderID, until, 50).WillReturnRows(rows)
+
+	ords, err := repo.FetchOrdersSince(cursor, until, 50)
+	require.NoError(t, err)
+	require.Len(t, ords, 1)
+	assert.Equal(t, id, ords[0].OrderID)
+	assert.Equal(t, "Enjoy!", ords[0].GiftMessage)
+	assert.True(t, ords[0].HidePrices)
+	assert.Equal(t, money.New(115, "EUR"), ords[0].TotalPrice)
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// InsertWebhook inserts a webhook with its signing secret.
+func (r *IntegrationRepository) InsertWebhook(w models.Webhook) (*models.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into webhooks (url, events, secret, created_at) values ($1, $2, $3, $4)
+				returning webhook_id, created_at`
+
+	err := r.DB.QueryRowContext(ctx, query, w.URL, strings.Join(w.Events, ","), w.Secret, time.Now()).
+		Scan(&w.ID, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// FetchWebhooks fetches all webhooks without their secret, newest first.
+func (r *IntegrationRepository) FetchWebhooks() ([]*models.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, `select webhook_id, url, events, created_at from webhooks order by created_at desc`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []*models.Webhook
+	for rows.Next() {
+		var (
+			w      models.Webhook
+			events string
+		)
+		if err := rows.Scan(&w.ID, &w.URL, &events, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		if events != "" {
+			w.Events = strings.Split(events, ",")
+		}
+
+		hooks = append(hooks, &w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+// DeleteWebhook deletes a webhook and its delivery log. It returns sql.ErrNoRows
+// when there is no such webhook.
+func (r *IntegrationRepository) DeleteWebhook(id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.DB.ExecContext(ctx, `delete from webhooks where webhook_id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(res)
+}
+
+// InsertWebhookDeliveries queues the event eventID named event, carrying payload, for
+// every webhook subscribed to it, and returns how many deliveries were queued.
+func (r *IntegrationRepository) InsertWebhookDeliveries(eventID uuid.UUID, event string, payload []byte) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into webhook_deliveries (webhook_id, event_id, event, payload)
+				select webhook_id, $1, $2, $3 from webhooks where $2 = any(string_to_array(events, ','))`
+
+	res, err := r.DB.ExecContext(ctx, query, eventID, event, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// ClaimWebhookDeliveries leases up to limit pending deliveries due at now until
+// leaseUntil, oldest first, counting the attempt. The deliveries carry the URL and
+// secret of their webhook.
+func (r *IntegrationRepository) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update webhook_deliveries d set attempts = d.attempts + 1, next_attempt_at = $1
+				from webhooks w
+				where w.webhook_id = d.webhook_id and d.delivery_id in (
+					select delivery_id from webhook_deliveries
+					where status = $2 and next_attempt_at <= $3
+					order by created_at
+					limit $4
+					for update skip locked)
+				returning d.delivery_id, d.webhook_id, d.event_id, d.event, d.payload, d.status, d.attempts,
+					d.created_at, w.url, w.secret`
+
+	rows, err := r.DB.QueryContext(ctx, query, leaseUntil, models.DeliveryPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.Event, &d.Payload, &d.Status, &d.Attempts,
+			&d.CreatedAt, &d.URL, &d.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// returning does not keep the order of the subquery
+	sort.SliceStable(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt) })
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery records the outcome of the last attempt of a delivery.
+func (r *IntegrationRepository) UpdateWebhookDelivery(d *models.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update webhook_deliveries set status = $1, response_status = $2, last_error = $3,
+				next_attempt_at = coalesce($4, next_attempt_at), delivered_at = $5
+				where delivery_id = $6`
+
+	_, err := r.DB.ExecContext(ctx, query, d.Status, d.ResponseStatus, d.LastError, d.NextAttemptAt,
+		d.DeliveredAt, d.ID)
+
+	return err
+}
+
+// FetchWebhookDeliveries fetches the latest limit deliveries of a webhook, newest
+// first.
+func (r *IntegrationRepository) FetchWebhookDeliveries(webhookID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select delivery_id, webhook_id, event_id, event, status, attempts, response_status, last_error,
+				next_attempt_at, delivered_at, created_at
+				from webhook_deliveries where webhook_id = $1
+				order by created_at desc limit $2`
+
+	rows, err := r.DB.QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		var (
+			d           models.WebhookDelivery
+			nextAttempt time.Time
+			delivered   sql.NullTime
+		)
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.Event, &d.Status, &d.Attempts, &d.ResponseStatus,
+			&d.LastError, &nextAttempt, &delivered, &d.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if d.Status == models.DeliveryPending {
+			d.NextAttemptAt = &nextAttempt
+		}
+		if delivered.Valid {
+			d.DeliveredAt = &delivered.Time
+		}
+
+		deliveries = append(deliveries, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// DeleteWebhookDeliveries deletes the deliveries delivered or given up on created
+// before, and returns how many were deleted.
+func (r *IntegrationRepository) DeleteWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	res, err := r.DB.ExecContext(ctx, `delete from webhook_deliveries where status <> $1 and created_at < $2`,
+		models.DeliveryPending, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,93 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/integration/repository"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertWebhookDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIntegrationRepository(db)
+	eventID := uuid.New()
+	payload := []byte(`{"event":"order.created"}`)
+
+	mock.ExpectExec(regexp.QuoteMeta("from webhooks where $2 = any(string_to_array(events, ','))")).
+		WithArgs(eventID, models.WebhookOrderCreated, payload).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	n, err := repo.InsertWebhookDeliveries(eventID, models.WebhookOrderCreated, payload)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimWebhookDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIntegrationRepository(db)
+	now := time.Now()
+	first, second := uuid.New(), uuid.New()
+	columns := []string{"delivery_id", "webhook_id", "event_id", "event", "payload", "status", "attempts", "created_at",
+		"url", "secret"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("update webhook_deliveries d set attempts = d.attempts + 1")).
+		WithArgs(now.Add(time.Minute), models.DeliveryPending, now, 10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(second, uuid.New(), uuid.New(), models.WebhookProductUpdated, []byte(`{}`), models.DeliveryPending,
+				1, now, "https://erp.example.com/hooks", "whsec_a").
+			AddRow(first, uuid.New(), uuid.New(), models.WebhookOrderCreated, []byte(`{}`), models.DeliveryPending,
+				2, now.Add(-time.Minute), "https://crm.example.com/hooks", "whsec_b"))
+
+	deliveries, err := repo.ClaimWebhookDeliveries(context.Background(), now, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, first, deliveries[0].ID)
+	assert.Equal(t, "https://crm.example.com/hooks", deliveries[0].URL)
+	assert.Equal(t, "whsec_b", deliveries[0].Secret)
+	assert.Equal(t, second, deliveries[1].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchWebhookDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIntegrationRepository(db)
+	webhookID := uuid.New()
+	now := time.Now()
+	columns := []string{"delivery_id", "webhook_id", "event_id", "event", "status", "attempts", "response_status",
+		"last_error", "next_attempt_at", "delivered_at", "created_at"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("from webhook_deliveries where webhook_id = $1")).
+		WithArgs(webhookID, 50).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(uuid.New(), webhookID, uuid.New(), models.WebhookOrderCreated, models.DeliveryPending, 2, 503,
+				"maintenance", now.Add(time.Minute), nil, now).
+			AddRow(uuid.New(), webhookID, uuid.New(), models.WebhookOrderCreated, models.DeliveryDelivered, 1, 200,
+				"", now, now, now.Add(-time.Hour)))
+
+	deliveries, err := repo.FetchWebhookDeliveries(webhookID, 50)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, 503, deliveries[0].ResponseStatus)
+	require.NotNil(t, deliveries[0].NextAttemptAt)
+	assert.Nil(t, deliveries[0].DeliveredAt)
+	assert.Nil(t, deliveries[1].NextAttemptAt)
+	assert.NotNil(t, deliveries[1].DeliveredAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/events"
+)
+
+type IntegrationUC interface {
+	// CreateAPIKey creates an API key granted scopes, returns it with its plain text key
+	CreateAPIKey(name string, scopes []string) (*models.APIKey, error)
+
+	// GetAPIKeys returns all API keys without their plain text
+	GetAPIKeys() ([]*models.APIKey, error)
+
+	// RevokeAPIKey revokes an API key, returns an error on failure
+	RevokeAPIKey(id uuid.UUID) error
+
+	// Authenticate returns the API key matching a plain text key, or ErrInvalidAPIKey
+	Authenticate(key string) (*models.APIKey, error)
+
+	// SyncInventory sets the stock of the listed products, returns which were applied
+	SyncInventory(levels []models.StockLevel) (*models.InventorySyncReport, error)
+
+	// GetOrdersSince returns up to limit orders after cursor, with their items, shipping and payment
+	GetOrdersSince(cursor models.OrderCursor, limit int) ([]*models.Order, error)
+
+	// CreateWebhook registers a webhook subscribed to events, returns it with its signing secret
+	CreateWebhook(url string, events []string) (*models.Webhook, error)
+
+	// GetWebhooks returns all webhooks without their secret
+	GetWebhooks() ([]*models.Webhook, error)
+
+	// DeleteWebhook deletes a webhook and its delivery log, returns an error on failure
+	DeleteWebhook(id uuid.UUID) error
+
+	// GetWebhookDeliveries returns the latest deliveries of a webhook, newest first
+	GetWebhookDeliveries(webhookID uuid.UUID, limit int) ([]*models.WebhookDelivery, error)
+
+	// GetEventTypes returns the webhook events with the JSON Schema of their payload and a sample of it
+	GetEventTypes() ([]models.EventType, error)
+
+	// HandleEvent queues a domain event for the webhooks subscribed to it
+	HandleEvent(e events.Event) error
+
+	// DeliverWebhooks posts the deliveries due to their webhook, returns how many were delivered
+	DeliverWebhooks(ctx context.Context) (int, error)
+}
@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/jsonschema"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// eventType describes a webhook event: data is a sample of what it carries, from
+// which the schema of its payload is generated too.
+type eventType struct {
+	name        string
+	description string
+	data        interface{}
+}
+
+// eventTypes lists the webhook events in the order of models.WebhookEvents.
+var eventTypes = []eventType{
+	{
+		name:        models.WebhookOrderCreated,
+		description: "An order was placed. Data is the order, with its items, shipping and payment.",
+		data:        sampleOrder(models.OrderProcessing),
+	},
+	{
+		name:        models.WebhookOrderDelivered,
+		description: "An order was marked delivered. Data is the order, with its items, shipping and payment.",
+		data:        sampleOrder(models.OrderDelivered),
+	},
+	{
+		name:        models.WebhookProductUpdated,
+		description: "A product was updated by an admin. Data is the product as updated, with its images and variants.",
+		data:        sampleProduct(),
+	},
+}
+
+// sampleTime is when the sample events occurred.
+var sampleTime = time.Date(2024, time.March, 14, 9, 30, 0, 0, time.UTC)
+
+// GetEventTypes returns the webhook events with the JSON Schema of their payload and
+// a sample of it, so integrators can build their receivers without the source.
+func (i *IntegrationUC) GetEventTypes() ([]models.EventType, error) {
+	r := jsonschema.New()
+	r.Define(money.Money{}, &jsonschema.Schema{
+		Type:        "object",
+		Description: "An amount in minor units of currency, such as 4999 USD for $49.99.",
+		Properties: map[string]*jsonschema.Schema{
+			"amount":   {Type: "integer"},
+			"currency": {Type: "string"},
+		},
+		Required: []string{"amount", "currency"},
+	})
+	// orders are written with their totals, see models.Order.MarshalJSON
+	order := r.Reflect(struct {
+		models.Order
+		Totals models.OrderTotals `json:"totals"`
+	}{})
+	order.Schema = ""
+	r.Define(models.Order{}, order)
+
+	types := make([]models.EventType, 0, len(eventTypes))
+	for _, e := range eventTypes {
+		s := r.Reflect(webhookPayload{})
+		s.Title = e.name
+		s.Description = e.description
+		s.Properties["event"].Enum = []interface{}{e.name}
+		s.Properties["data"] = r.Reflect(e.data)
+		s.Properties["data"].Schema = ""
+
+		schema, err := json.Marshal(s)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding schema of %s: %v", e.name, err)
+		}
+
+		sample, err := json.Marshal(webhookPayload{
+			ID:         uuid.MustParse("5f0c8a52-3b1e-4d6a-9c2f-7e8b1a4d6c30"),
+			Event:      e.name,
+			OccurredAt: sampleTime,
+			Data:       e.data,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error encoding sample of %s: %v", e.name, err)
+		}
+
+		types = append(types, models.EventType{
+			Name:        e.name,
+			Description: e.description,
+			Schema:      schema,
+			Sample:      sample,
+		})
+	}
+
+	return types, nil
+}
+
+// sampleOrder returns the order of the samples, in status.
+func sampleOrder(status string) models.Order {
+	orderID := uuid.MustParse("0b6f2c4e-8d1a-4f3b-a5c7-2e9d4b6f8a10")
+	productID := uuid.MustParse("3c7e1a9b-5d2f-4b8e-9a6c-1f4d7b2e5c80")
+	created := sampleTime.Add(-2 * time.Hour)
+
+	o := models.Order{
+		OrderID: orderID,
+		ShippingInfo: models.Shipping{
+			Address:    "12 Independence Avenue",
+			City:       "Accra",
+			PhoneNo:    "+233201234567",
+			PostalCode: "GA-183",
+			Country:    "Ghana",
+			OrderID:    orderID,
+			CreatedAt:  created,
+		},
+		OrderItems: []*models.Item{{
+			ItemID:    productID,
+			Name:      "Wireless Headphones",
+			Price:     money.New(4999, "USD"),
+			Quantity:  2,
+			Image:     "https://res.cloudinary.com/shopit/image/upload/products/headphones.jpg",
+			ProductID: productID,
+			OrderID:   orderID,
+			CreatedAt: created,
+		}},
+		PaymentInfo: models.Payment{
+			ID:        "pi_3OtQ2kLkdIwHu7ix0a1b2c3d",
+			Provider:  "stripe",
+			Status:    models.PaymentSucceeded,
+			OrderID:   orderID,
+			CreatedAt: created,
+		},
+		UserID:        uuid.MustParse("9a4d2b7e-1c6f-4e3a-8b5d-6f2a9c4e1b70"),
+		Currency:      "USD",
+		PaidAt:        created,
+		ItemPrice:     money.New(9998, "USD"),
+		TaxPrice:      money.New(1500, "USD"),
+		ShippingPrice: money.New(0, "USD"),
+		TotalPrice:    money.New(11498, "USD"),
+		Discount:      money.New(0, "USD"),
+		OrderStatus:   status,
+		CreatedAt:     created,
+	}
+	if status == models.OrderDelivered {
+		o.DeliveredAt = sampleTime
+	}
+
+	return o
+}
+
+// sampleProduct returns the product of the samples.
+func sampleProduct() models.Product {
+	productID := uuid.MustParse("3c7e1a9b-5d2f-4b8e-9a6c-1f4d7b2e5c80")
+	created := sampleTime.AddDate(0, -1, 0)
+
+	return models.Product{
+		ProductId:   productID,
+		Name:        "Wireless Headphones",
+		SKU:         "WH-1000",
+		Price:       money.New(4999, "USD"),
+		Description: "Over-ear headphones with noise cancelling and 30 hours of battery.",
+		Ratings:     4,
+		Images: []models.Images{{
+			PublicId:  "products/headphones",
+			Url:       "https://res.cloudinary.com/shopit/image/upload/products/headphones.jpg",
+			ProductId: productID,
+			CreatedAt: created,
+		}},
+		Category:      "Headphones",
+		CategoryId:    uuid.NullUUID{UUID: uuid.MustParse("7d1b4e8a-2f5c-4a9d-b3e6-8c1f5a2d7e40"), Valid: true},
+		TaxClass:      models.TaxStandard,
+		ShippingClass: models.ShippingStandard,
+		Seller:        "Sony",
+		Stock:         25,
+		NumOfReviews:  12,
+		Reviews:       []models.Reviews{},
+		Variants: []models.Variant{{
+			VariantId:  uuid.MustParse("e2a6c9f1-4b7d-4e2a-9c5f-3b8e1d6a4f20"),
+			ProductId:  productID,
+			SKU:        "WH-1000-BLK",
+			Attributes: map[string]string{"color": "black"},
+			PriceDelta: money.New(0, "USD"),
+			Stock:      25,
+			CreatedAt:  created,
+		}},
+		UserId:    uuid.MustParse("9a4d2b7e-1c6f-4e3a-8b5d-6f2a9c4e1b70"),
+		CreatedAt: created,
+	}
+}
@@ -0,0 +1,68 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/internal/integration/mocks"
+	"github.com/jofosuware/go/shopit/internal/integration/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockOrders "github.com/jofosuware/go/shopit/internal/orders/mocks"
+	"github.com/jofosuware/go/shopit/pkg/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEventTypes(t *testing.T) {
+	i := usecase.NewIntegrationUC(mocks.NewRepo(t), mockOrders.NewRepo(t), usecase.WebhookOptions{})
+
+	types, err := i.GetEventTypes()
+	require.NoError(t, err)
+
+	names := make([]string, len(types))
+	for n, e := range types {
+		names[n] = e.Name
+	}
+	assert.Equal(t, models.WebhookEvents, names)
+
+	for _, e := range types {
+		t.Run(e.Name, func(t *testing.T) {
+			var schema jsonschema.Schema
+			require.NoError(t, json.Unmarshal(e.Schema, &schema))
+
+			var sample map[string]interface{}
+			require.NoError(t, json.Unmarshal(e.Sample, &sample))
+
+			assert.Equal(t, jsonschema.Draft, schema.Schema)
+			assert.Equal(t, e.Name, sample["event"])
+			assert.Equal(t, []interface{}{e.Name}, schema.Properties["event"].Enum)
+			assertRequired(t, &schema, sample)
+
+			data := schema.Properties["data"]
+			assert.Contains(t, data.Properties, "id")
+			assert.Equal(t, "uuid", data.Properties["id"].Format)
+			assertRequired(t, data, sample["data"].(map[string]interface{}))
+		})
+	}
+
+	t.Run("Money is described by its encoding", func(t *testing.T) {
+		var schema jsonschema.Schema
+		require.NoError(t, json.Unmarshal(types[0].Schema, &schema))
+
+		total := schema.Properties["data"].Properties["totalPrice"]
+		assert.Equal(t, "object", total.Type)
+		assert.Equal(t, []string{"amount", "currency"}, total.Required)
+	})
+}
+
+// assertRequired asserts the sample has the properties schema requires.
+func assertRequired(t *testing.T, schema *jsonschema.Schema, sample map[string]interface{}) {
+	t.Helper()
+
+	for _, name := range schema.Required {
+		assert.Contains(t, sample, name)
+	}
+	for name := range sample {
+		assert.Contains(t, schema.Properties, name)
+	}
+}
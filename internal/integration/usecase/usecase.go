@@ -0,0 +1,199 @@
+// Package usecase implements the sync between the shop and external systems (POS, ERP).
+//
+// External systems authenticate with scoped API keys. Inventory sync is a delta: only
+// the products listed are touched. Orders are pulled with a cursor, the creation time
+// and id of the last order received, so each pull only returns what is new. Systems
+// that would rather be pushed to register webhooks: the events they subscribed to are
+// posted to them, signed with their secret and retried until they are accepted.
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/integration"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/orders"
+)
+
+const (
+	// DefaultOrdersLimit is the page size of GetOrdersSince when none is given.
+	DefaultOrdersLimit = 100
+
+	// MaxOrdersLimit caps the page size of GetOrdersSince.
+	MaxOrdersLimit = 500
+
+	// OrderSettleDelay is how old an order must be before GetOrdersSince returns it,
+	// longer than the transaction that places it may run.
+	OrderSettleDelay = 10 * time.Second
+)
+
+// keyPrefix marks shop API keys, so a leaked key is easy to recognise.
+const keyPrefix = "shopit_"
+
+// IntegrationUC provides integration use cases.
+type IntegrationUC struct {
+	repo       integration.Repo
+	ordersRepo orders.Repo
+	webhooks   WebhookOptions
+	client     *http.Client
+	now        func() time.Time
+}
+
+// NewIntegrationUC returns a new IntegrationUC, delivering webhooks with the options of
+// webhooks.
+func NewIntegrationUC(repo integration.Repo, ordersRepo orders.Repo, webhooks WebhookOptions) *IntegrationUC {
+	webhooks = webhooks.withDefaults()
+
+	return &IntegrationUC{
+		repo:       repo,
+		ordersRepo: ordersRepo,
+		webhooks:   webhooks,
+		client: &http.Client{
+			Timeout: webhooks.Timeout,
+			// a redirect is a failed delivery, the webhook url must be fixed instead
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		now: time.Now,
+	}
+}
+
+// CreateAPIKey creates an API key granted scopes. Only the hash of the key is stored,
+// so the returned plain text key cannot be shown again.
+func (i *IntegrationUC) CreateAPIKey(name string, scopes []string) (*models.APIKey, error) {
+	for _, s := range scopes {
+		if !models.ValidScope(s) {
+			return nil, integration.ErrInvalidScope
+		}
+	}
+
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("error generating api key: %v", err)
+	}
+	plainText := keyPrefix + base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+
+	key, err := i.repo.InsertAPIKey(models.APIKey{Name: name, Scopes: scopes}, hashKey(plainText))
+	if err != nil {
+		return nil, fmt.Errorf("error saving api key: %v", err)
+	}
+
+	key.Key = plainText
+
+	return key, nil
+}
+
+// GetAPIKeys returns all API keys.
+func (i *IntegrationUC) GetAPIKeys() ([]*models.APIKey, error) {
+	keys, err := i.repo.FetchAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching api keys: %v", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key. It returns sql.ErrNoRows when there is no such key.
+func (i *IntegrationUC) RevokeAPIKey(id uuid.UUID) error {
+	if err := i.repo.RevokeAPIKey(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return fmt.Errorf("error revoking api key: %v", err)
+	}
+
+	return nil
+}
+
+// Authenticate returns the API key matching the plain text key, or ErrInvalidAPIKey.
+func (i *IntegrationUC) Authenticate(key string) (*models.APIKey, error) {
+	k, err := i.repo.FetchAPIKeyByHash(hashKey(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, integration.ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("error fetching api key: %v", err)
+	}
+
+	if k.Revoked {
+		return nil, integration.ErrInvalidAPIKey
+	}
+
+	// last use is informational, a failure to record it must not block the sync
+	_ = i.repo.UpdateKeyLastUsed(k.ID, time.Now())
+
+	return k, nil
+}
+
+// SyncInventory sets the stock of the listed products and leaves all others alone.
+// Products that do not exist are reported as unknown rather than failing the sync.
+func (i *IntegrationUC) SyncInventory(levels []models.StockLevel) (*models.InventorySyncReport, error) {
+	report := &models.InventorySyncReport{Unknown: []uuid.UUID{}}
+
+	for _, l := range levels {
+		if err := i.repo.UpdateStockLevel(l.ProductID, l.Stock); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				report.Unknown = append(report.Unknown, l.ProductID)
+				continue
+			}
+			return nil, fmt.Errorf("error updating stock of %s after %d updates: %v", l.ProductID, report.Updated, err)
+		}
+
+		report.Updated++
+	}
+
+	return report, nil
+}
+
+// GetOrdersSince returns up to limit orders after cursor, oldest first, with their
+// items, shipping and payment. A non-positive limit falls back to DefaultOrdersLimit.
+// Orders are only returned once they are OrderSettleDelay old, so that an order
+// whose transaction commits after later ones were read is not passed over.
+func (i *IntegrationUC) GetOrdersSince(cursor models.OrderCursor, limit int) ([]*models.Order, error) {
+	if limit <= 0 {
+		limit = DefaultOrdersLimit
+	}
+	if limit > MaxOrdersLimit {
+		limit = MaxOrdersLimit
+	}
+
+	ords, err := i.repo.FetchOrdersSince(cursor, i.now().Add(-OrderSettleDelay), limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching orders: %v", err)
+	}
+
+	for _, ord := range ords {
+		items, err := i.ordersRepo.FetchItemsById(ord.OrderID)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching items of order %s: %v", ord.OrderID, err)
+		}
+		ord.OrderItems = items
+
+		shipping, err := i.ordersRepo.FetchShippingById(ord.OrderID)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching shipping of order %s: %v", ord.OrderID, err)
+		}
+		ord.ShippingInfo = *shipping
+
+		payment, err := i.ordersRepo.FetchPaymentById(ord.OrderID)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching payment of order %s: %v", ord.OrderID, err)
+		}
+		ord.PaymentInfo = *payment
+	}
+
+	return ords, nil
+}
+
+// hashKey returns the stored form of a plain text API key.
+func hashKey(key string) []byte {
+	h := sha256.Sum256([]byte(key))
+	return h[:]
+}
@@ -0,0 +1,136 @@
+package usecase_test
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/integration"
+	"github.com/jofosuware/go/shopit/internal/integration/mocks"
+	"github.com/jofosuware/go/shopit/internal/integration/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockOrders "github.com/jofosuware/go/shopit/internal/orders/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	i := usecase.NewIntegrationUC(repo, mockOrders.NewRepo(t), usecase.WebhookOptions{})
+
+	t.Run("Only the hash of the key is stored", func(t *testing.T) {
+		var stored []byte
+		repo.On("InsertAPIKey", models.APIKey{Name: "erp", Scopes: []string{models.ScopeOrdersRead}}, mock.Anything).
+			Run(func(args mock.Arguments) { stored = args.Get(1).([]byte) }).
+			Return(&models.APIKey{ID: uuid.New(), Name: "erp"}, nil).Once()
+
+		key, err := i.CreateAPIKey("erp", []string{models.ScopeOrdersRead})
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(key.Key, "shopit_"))
+		hash := sha256.Sum256([]byte(key.Key))
+		assert.Equal(t, hash[:], stored)
+	})
+
+	t.Run("Unknown scope", func(t *testing.T) {
+		_, err := i.CreateAPIKey("erp", []string{"admin"})
+		assert.ErrorIs(t, err, integration.ErrInvalidScope)
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	i := usecase.NewIntegrationUC(repo, mockOrders.NewRepo(t), usecase.WebhookOptions{})
+
+	hash := sha256.Sum256([]byte("shopit_key"))
+
+	t.Run("Valid key", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchAPIKeyByHash", hash[:]).Return(&models.APIKey{ID: id}, nil).Once()
+		repo.On("UpdateKeyLastUsed", id, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		key, err := i.Authenticate("shopit_key")
+		require.NoError(t, err)
+		assert.Equal(t, id, key.ID)
+	})
+
+	t.Run("Revoked key", func(t *testing.T) {
+		repo.On("FetchAPIKeyByHash", hash[:]).Return(&models.APIKey{ID: uuid.New(), Revoked: true}, nil).Once()
+
+		_, err := i.Authenticate("shopit_key")
+		assert.ErrorIs(t, err, integration.ErrInvalidAPIKey)
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		repo.On("FetchAPIKeyByHash", mock.Anything).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := i.Authenticate("shopit_other")
+		assert.ErrorIs(t, err, integration.ErrInvalidAPIKey)
+	})
+}
+
+func TestSyncInventory(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	i := usecase.NewIntegrationUC(repo, mockOrders.NewRepo(t), usecase.WebhookOptions{})
+
+	known, unknown := uuid.New(), uuid.New()
+
+	t.Run("Unknown products are reported", func(t *testing.T) {
+		repo.On("UpdateStockLevel", known, 7).Return(nil).Once()
+		repo.On("UpdateStockLevel", unknown, 2).Return(sql.ErrNoRows).Once()
+
+		report, err := i.SyncInventory([]models.StockLevel{{ProductID: known, Stock: 7}, {ProductID: unknown, Stock: 2}})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, []uuid.UUID{unknown}, report.Unknown)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		repo.On("UpdateStockLevel", known, 1).Return(errors.New("db error")).Once()
+
+		_, err := i.SyncInventory([]models.StockLevel{{ProductID: known, Stock: 1}})
+		assert.Error(t, err)
+	})
+}
+
+func TestGetOrdersSince(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	ordersRepo := mockOrders.NewRepo(t)
+	i := usecase.NewIntegrationUC(repo, ordersRepo, usecase.WebhookOptions{})
+
+	since := models.OrderCursor{CreatedAt: time.Now().Add(-time.Hour), OrderID: uuid.New()}
+	// orders are only read once the transactions placing them had time to commit
+	settled := mock.MatchedBy(func(until time.Time) bool {
+		return !until.After(time.Now().Add(-usecase.OrderSettleDelay))
+	})
+
+	t.Run("Orders come with items, shipping and payment", func(t *testing.T) {
+		orderID := uuid.New()
+		repo.On("FetchOrdersSince", since, settled, usecase.DefaultOrdersLimit).Return([]*models.Order{{OrderID: orderID}}, nil).Once()
+		ordersRepo.On("FetchItemsById", orderID).Return([]*models.Item{{Name: "Laptop"}}, nil).Once()
+		ordersRepo.On("FetchShippingById", orderID).Return(&models.Shipping{City: "Accra"}, nil).Once()
+		ordersRepo.On("FetchPaymentById", orderID).Return(&models.Payment{ID: "pi_1"}, nil).Once()
+
+		ords, err := i.GetOrdersSince(since, 0)
+		require.NoError(t, err)
+
+		require.Len(t, ords, 1)
+		assert.Equal(t, "Laptop", ords[0].OrderItems[0].Name)
+		assert.Equal(t, "Accra", ords[0].ShippingInfo.City)
+		assert.Equal(t, "pi_1", ords[0].PaymentInfo.ID)
+	})
+
+	t.Run("Limit is capped", func(t *testing.T) {
+		repo.On("FetchOrdersSince", since, settled, usecase.MaxOrdersLimit).Return(nil, nil).Once()
+
+		ords, err := i.GetOrdersSince(since, 10000)
+		require.NoError(t, err)
+		assert.Empty(t, ords)
+	})
+}
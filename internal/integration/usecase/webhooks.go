@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/integration"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/events"
+)
+
+// Headers of a webhook request.
+const (
+	// EventHeader names the event posted.
+	EventHeader = "X-ShopIT-Event"
+
+	// DeliveryHeader identifies the delivery; retries of a delivery keep it.
+	DeliveryHeader = "X-ShopIT-Delivery"
+
+	// SignatureHeader carries the signature of the request, see Sign.
+	SignatureHeader = "X-ShopIT-Signature"
+)
+
+// Defaults of the WebhookOptions.
+const (
+	DefaultWebhookTimeout     = 10 * time.Second
+	DefaultWebhookBatchSize   = 50
+	DefaultWebhookMaxAttempts = 10
+	DefaultWebhookRetention   = 30 * 24 * time.Hour
+)
+
+const (
+	// DefaultDeliveriesLimit is how many deliveries GetWebhookDeliveries returns when
+	// no limit is given.
+	DefaultDeliveriesLimit = 50
+
+	// MaxDeliveriesLimit caps the deliveries GetWebhookDeliveries returns.
+	MaxDeliveriesLimit = 200
+)
+
+// secretPrefix marks webhook signing secrets, so a leaked secret is easy to recognise.
+const secretPrefix = "whsec_"
+
+// webhookLease is how long a claimed delivery is left to its attempt before it can be
+// claimed again, in case the process stopped while posting it.
+const webhookLease = 5 * time.Minute
+
+// firstWebhookBackoff is how long a failed delivery waits for its next attempt; the
+// wait doubles with every attempt, up to maxWebhookBackoff.
+const (
+	firstWebhookBackoff = 30 * time.Second
+	maxWebhookBackoff   = 6 * time.Hour
+)
+
+// maxErrorBody is how much of the body of a rejected delivery is kept as its error.
+const maxErrorBody = 512
+
+// webhookEvents maps the domain events posted to webhooks to their webhook event.
+var webhookEvents = map[string]string{
+	events.OrderCreated:   models.WebhookOrderCreated,
+	events.OrderDelivered: models.WebhookOrderDelivered,
+	events.ProductUpdated: models.WebhookProductUpdated,
+}
+
+// WebhookEventNames are the domain events HandleEvent is to be subscribed to.
+func WebhookEventNames() []string {
+	names := make([]string, 0, len(webhookEvents))
+	for name := range webhookEvents {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// WebhookOptions of the webhook deliveries. Timeout is how long a webhook has to
+// answer, BatchSize how many deliveries DeliverWebhooks posts at most, MaxAttempts
+// how many times a delivery is attempted before it is given up on, and Retention how
+// long finished deliveries are kept in the log. Zero values fall back to the
+// defaults.
+type WebhookOptions struct {
+	Timeout     time.Duration
+	BatchSize   int
+	MaxAttempts int
+	Retention   time.Duration
+}
+
+func (o WebhookOptions) withDefaults() WebhookOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultWebhookTimeout
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultWebhookBatchSize
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultWebhookMaxAttempts
+	}
+	if o.Retention <= 0 {
+		o.Retention = DefaultWebhookRetention
+	}
+
+	return o
+}
+
+// webhookPayload is the body posted to a webhook. ID identifies the event, the same
+// for every webhook and attempt, so receivers can drop the events they already got.
+type webhookPayload struct {
+	ID         uuid.UUID   `json:"id"`
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       interface{} `json:"data"`
+}
+
+// Sign returns the signature of a webhook request posting body at t with secret:
+// "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">". Receivers recompute
+// it to check the request came from the shop, and reject old times against replays.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%s,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// CreateWebhook registers a webhook posted the events it subscribed to. Its secret
+// signs the requests; it is stored to sign them, but only returned here.
+func (i *IntegrationUC) CreateWebhook(url string, subscribed []string) (*models.Webhook, error) {
+	if !models.ValidWebhookURL(url) {
+		return nil, integration.ErrInvalidWebhookURL
+	}
+	if len(subscribed) == 0 {
+		return nil, integration.ErrInvalidWebhookEvent
+	}
+	for _, e := range subscribed {
+		if !models.ValidWebhookEvent(e) {
+			return nil, integration.ErrInvalidWebhookEvent
+		}
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("error generating webhook secret: %v", err)
+	}
+
+	secret := secretPrefix + hex.EncodeToString(b)
+
+	w, err := i.repo.InsertWebhook(models.Webhook{URL: url, Events: subscribed, Secret: secret})
+	if err != nil {
+		return nil, fmt.Errorf("error saving webhook: %v", err)
+	}
+
+	return w, nil
+}
+
+// GetWebhooks returns all webhooks, without their secret.
+func (i *IntegrationUC) GetWebhooks() ([]*models.Webhook, error) {
+	hooks, err := i.repo.FetchWebhooks()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching webhooks: %v", err)
+	}
+
+	return hooks, nil
+}
+
+// DeleteWebhook deletes a webhook along with its pending deliveries and log. It
+// returns sql.ErrNoRows when there is no such webhook.
+func (i *IntegrationUC) DeleteWebhook(id uuid.UUID) error {
+	if err := i.repo.DeleteWebhook(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return fmt.Errorf("error deleting webhook: %v", err)
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries returns up to limit of the latest deliveries of a webhook,
+// newest first. A non-positive limit falls back to DefaultDeliveriesLimit.
+func (i *IntegrationUC) GetWebhookDeliveries(webhookID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = DefaultDeliveriesLimit
+	}
+	if limit > MaxDeliveriesLimit {
+		limit = MaxDeliveriesLimit
+	}
+
+	deliveries, err := i.repo.FetchWebhookDeliveries(webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// HandleEvent queues a delivery of e to every webhook subscribed to it. It handles the
+// events of WebhookEventNames; the others are ignored.
+func (i *IntegrationUC) HandleEvent(e events.Event) error {
+	event, ok := webhookEvents[e.Name]
+	if !ok {
+		return nil
+	}
+
+	id := uuid.New()
+	payload, err := json.Marshal(webhookPayload{ID: id, Event: event, OccurredAt: e.OccurredAt, Data: e.Data})
+	if err != nil {
+		return fmt.Errorf("error encoding %s webhook: %v", event, err)
+	}
+
+	if _, err := i.repo.InsertWebhookDeliveries(id, event, payload); err != nil {
+		return fmt.Errorf("error queueing %s webhooks: %v", event, err)
+	}
+
+	return nil
+}
+
+// DeliverWebhooks posts a batch of the deliveries due, oldest first, and returns how
+// many were accepted. A webhook accepts a delivery by answering with a 2xx status;
+// otherwise it is retried after a wait doubling with its attempts, and given up on
+// after MaxAttempts. Finished deliveries older than Retention are deleted. A post is
+// cancelled with ctx, and once ctx is done no other delivery is posted; those left
+// claimed are retried when their lease ends. Outcomes are still recorded.
+func (i *IntegrationUC) DeliverWebhooks(ctx context.Context) (int, error) {
+	now := i.now()
+
+	if _, err := i.repo.DeleteWebhookDeliveries(ctx, now.Add(-i.webhooks.Retention)); err != nil {
+		return 0, fmt.Errorf("error purging webhook deliveries: %v", err)
+	}
+
+	deliveries, err := i.repo.ClaimWebhookDeliveries(ctx, now, now.Add(webhookLease), i.webhooks.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming webhook deliveries: %v", err)
+	}
+
+	var delivered int
+	var errs []error
+	for _, d := range deliveries {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		i.deliver(ctx, d)
+		if err := i.repo.UpdateWebhookDelivery(d); err != nil {
+			errs = append(errs, fmt.Errorf("error recording webhook delivery %s: %v", d.ID, err))
+			continue
+		}
+
+		switch d.Status {
+		case models.DeliveryDelivered:
+			delivered++
+		case models.DeliveryFailed:
+			errs = append(errs, fmt.Errorf("giving up on webhook delivery %s after %d attempts: %s",
+				d.ID, d.Attempts, d.LastError))
+		}
+	}
+
+	return delivered, errors.Join(errs...)
+}
+
+// deliver posts d to its webhook and sets its outcome.
+func (i *IntegrationUC) deliver(ctx context.Context, d *models.WebhookDelivery) {
+	now := i.now()
+
+	d.ResponseStatus, d.LastError = 0, ""
+
+	status, err := i.post(ctx, d, now)
+	d.ResponseStatus = status
+	if err == nil {
+		d.Status = models.DeliveryDelivered
+		d.DeliveredAt = &now
+		d.NextAttemptAt = nil
+		return
+	}
+
+	d.LastError = err.Error()
+	if d.Attempts >= i.webhooks.MaxAttempts {
+		d.Status = models.DeliveryFailed
+		d.NextAttemptAt = nil
+		return
+	}
+
+	next := now.Add(webhookBackoff(d.Attempts))
+	d.Status = models.DeliveryPending
+	d.NextAttemptAt = &next
+}
+
+// post sends d to its webhook, signed at now, and returns the status it answered.
+func (i *IntegrationUC) post(ctx context.Context, d *models.WebhookDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ShopIT-Webhooks/1.0")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, d.ID.String())
+	req.Header.Set(SignatureHeader, Sign(d.Secret, now, d.Payload))
+
+	res, err := i.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+		return res.StatusCode, fmt.Errorf("webhook answered %s: %s", res.Status, bytes.TrimSpace(body))
+	}
+
+	// drain the body so the connection is reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<20))
+
+	return res.StatusCode, nil
+}
+
+// webhookBackoff is how long a delivery waits after its attempts failed.
+func webhookBackoff(attempts int) time.Duration {
+	wait := firstWebhookBackoff
+	for n := 1; n < attempts && wait < maxWebhookBackoff; n++ {
+		wait *= 2
+	}
+
+	return min(wait, maxWebhookBackoff)
+}
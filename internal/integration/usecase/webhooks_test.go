@@ -0,0 +1,201 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/integration"
+	"github.com/jofosuware/go/shopit/internal/integration/mocks"
+	"github.com/jofosuware/go/shopit/internal/integration/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockOrders "github.com/jofosuware/go/shopit/internal/orders/mocks"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	i := usecase.NewIntegrationUC(repo, mockOrders.NewRepo(t), usecase.WebhookOptions{})
+
+	t.Run("Webhook is created with a secret", func(t *testing.T) {
+		var stored models.Webhook
+		repo.On("InsertWebhook", mock.AnythingOfType("models.Webhook")).
+			Run(func(args mock.Arguments) { stored = args.Get(0).(models.Webhook) }).
+			Return(func(w models.Webhook) *models.Webhook { w.ID = uuid.New(); return &w }, nil).Once()
+
+		hook, err := i.CreateWebhook("https://erp.example.com/hooks", []string{models.WebhookOrderCreated})
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(hook.Secret, "whsec_"))
+		assert.Equal(t, stored.Secret, hook.Secret)
+		assert.Equal(t, []string{models.WebhookOrderCreated}, stored.Events)
+	})
+
+	t.Run("Unknown event", func(t *testing.T) {
+		_, err := i.CreateWebhook("https://erp.example.com/hooks", []string{"user.registered"})
+		assert.ErrorIs(t, err, integration.ErrInvalidWebhookEvent)
+	})
+
+	t.Run("Invalid url", func(t *testing.T) {
+		_, err := i.CreateWebhook("ftp://erp.example.com", []string{models.WebhookOrderCreated})
+		assert.ErrorIs(t, err, integration.ErrInvalidWebhookURL)
+	})
+}
+
+func TestHandleEvent(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	i := usecase.NewIntegrationUC(repo, mockOrders.NewRepo(t), usecase.WebhookOptions{})
+
+	t.Run("Event is queued for its webhooks", func(t *testing.T) {
+		order := models.Order{OrderID: uuid.New(), OrderStatus: models.OrderDelivered}
+		repo.On("InsertWebhookDeliveries", mock.Anything, models.WebhookOrderDelivered, mock.Anything).
+			Run(func(args mock.Arguments) {
+				var payload struct {
+					ID    uuid.UUID    `json:"id"`
+					Event string       `json:"event"`
+					Data  models.Order `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(args.Get(2).([]byte), &payload))
+				assert.Equal(t, args.Get(0), payload.ID)
+				assert.Equal(t, models.WebhookOrderDelivered, payload.Event)
+				assert.Equal(t, order.OrderID, payload.Data.OrderID)
+			}).Return(int64(1), nil).Once()
+
+		require.NoError(t, i.HandleEvent(events.Event{Name: events.OrderDelivered, OccurredAt: time.Now(), Data: order}))
+	})
+
+	t.Run("Other events are ignored", func(t *testing.T) {
+		require.NoError(t, i.HandleEvent(events.Event{Name: events.OrderStatusChanged, Data: models.Order{}}))
+	})
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	newDelivery := func(url string, attempts int) *models.WebhookDelivery {
+		return &models.WebhookDelivery{
+			ID:       uuid.New(),
+			Event:    models.WebhookOrderCreated,
+			Payload:  []byte(`{"id":"1","event":"order.created"}`),
+			Status:   models.DeliveryPending,
+			Attempts: attempts,
+			URL:      url,
+			Secret:   "whsec_test",
+		}
+	}
+
+	t.Run("Delivery is signed and accepted", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			sig := r.Header.Get(usecase.SignatureHeader)
+			var unix int64
+			_, err := fmt.Sscanf(sig, "t=%d,", &unix)
+			assert.NoError(t, err)
+
+			assert.Equal(t, usecase.Sign("whsec_test", time.Unix(unix, 0), body), sig)
+			assert.Equal(t, models.WebhookOrderCreated, r.Header.Get(usecase.EventHeader))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		repo := mocks.NewRepo(t)
+		i := usecase.NewIntegrationUC(repo, mockOrders.NewRepo(t), usecase.WebhookOptions{})
+
+		d := newDelivery(srv.URL, 1)
+		repo.On("DeleteWebhookDeliveries", mock.Anything, mock.Anything).Return(int64(0), nil).Once()
+		repo.On("ClaimWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything, usecase.DefaultWebhookBatchSize).
+			Return([]*models.WebhookDelivery{d}, nil).Once()
+		repo.On("UpdateWebhookDelivery", d).Return(nil).Once()
+
+		n, err := i.DeliverWebhooks(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, models.DeliveryDelivered, d.Status)
+		assert.Equal(t, http.StatusNoContent, d.ResponseStatus)
+		assert.NotNil(t, d.DeliveredAt)
+	})
+
+	t.Run("Rejected delivery is retried later", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		repo := mocks.NewRepo(t)
+		i := usecase.NewIntegrationUC(repo, mockOrders.NewRepo(t), usecase.WebhookOptions{MaxAttempts: 3})
+
+		d := newDelivery(srv.URL, 2)
+		repo.On("DeleteWebhookDeliveries", mock.Anything, mock.Anything).Return(int64(0), nil).Once()
+		repo.On("ClaimWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return([]*models.WebhookDelivery{d}, nil).Once()
+		repo.On("UpdateWebhookDelivery", d).Return(nil).Once()
+
+		n, err := i.DeliverWebhooks(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, models.DeliveryPending, d.Status)
+		assert.Equal(t, http.StatusServiceUnavailable, d.ResponseStatus)
+		assert.Contains(t, d.LastError, "maintenance")
+		require.NotNil(t, d.NextAttemptAt)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), *d.NextAttemptAt, 5*time.Second)
+	})
+
+	t.Run("Delivery is given up on after its last attempt", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "https://elsewhere.example.com", http.StatusFound)
+		}))
+		defer srv.Close()
+
+		repo := mocks.NewRepo(t)
+		i := usecase.NewIntegrationUC(repo, mockOrders.NewRepo(t), usecase.WebhookOptions{MaxAttempts: 3})
+
+		d := newDelivery(srv.URL, 3)
+		repo.On("DeleteWebhookDeliveries", mock.Anything, mock.Anything).Return(int64(0), nil).Once()
+		repo.On("ClaimWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return([]*models.WebhookDelivery{d}, nil).Once()
+		repo.On("UpdateWebhookDelivery", d).Return(nil).Once()
+
+		_, err := i.DeliverWebhooks(context.Background())
+		assert.ErrorContains(t, err, "giving up")
+		assert.Equal(t, models.DeliveryFailed, d.Status)
+		assert.Equal(t, http.StatusFound, d.ResponseStatus)
+		assert.Nil(t, d.NextAttemptAt)
+	})
+
+	t.Run("Claim error", func(t *testing.T) {
+		repo := mocks.NewRepo(t)
+		i := usecase.NewIntegrationUC(repo, mockOrders.NewRepo(t), usecase.WebhookOptions{})
+
+		repo.On("DeleteWebhookDeliveries", mock.Anything, mock.Anything).Return(int64(0), nil).Once()
+		repo.On("ClaimWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, errors.New("db down")).Once()
+
+		_, err := i.DeliverWebhooks(context.Background())
+		assert.ErrorContains(t, err, "db down")
+	})
+
+	t.Run("Nothing is posted once the run is cancelled", func(t *testing.T) {
+		repo := mocks.NewRepo(t)
+		i := usecase.NewIntegrationUC(repo, mockOrders.NewRepo(t), usecase.WebhookOptions{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		d := newDelivery("http://127.0.0.1:1", 1)
+		repo.On("DeleteWebhookDeliveries", mock.Anything, mock.Anything).Return(int64(0), nil).Once()
+		repo.On("ClaimWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(mock.Arguments) { cancel() }).
+			Return([]*models.WebhookDelivery{d}, nil).Once()
+
+		n, err := i.DeliverWebhooks(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, n)
+	})
+}
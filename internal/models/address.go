@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxAddresses caps the saved addresses of a user.
+const MaxAddresses = 20
+
+// Address is a shipping address saved to the address book of a user, to ship orders
+// to without entering it again. Label names it for the user, such as "Home". A user
+// has at most one Default address.
+type Address struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"userId"`
+	Label      string    `json:"label"`
+	Address    string    `json:"address"`
+	City       string    `json:"city"`
+	PhoneNo    string    `json:"phoneNo"`
+	PostalCode string    `json:"postalCode"`
+	Country    string    `json:"country"`
+	Default    bool      `json:"default"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Shipping returns the shipping info of an order shipped to a.
+func (a Address) Shipping() Shipping {
+	return Shipping{
+		Address:    a.Address,
+		City:       a.City,
+		PhoneNo:    a.PhoneNo,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// Orders customers can be listed in, descending with a "-" prefix such as "-spent"
+const (
+	CustomersOrders    = "orders"
+	CustomersSpent     = "spent"
+	CustomersAverage   = "average"
+	CustomersLastOrder = "last_order"
+)
+
+// CustomerSorts are the orders customers can be listed in.
+var CustomerSorts = []string{CustomersOrders, CustomersSpent, CustomersAverage, CustomersLastOrder}
+
+// CustomerFilter selects a page of the customers who ordered in Currency, in the
+// order of Sort.
+type CustomerFilter struct {
+	Currency string
+	Sort     string
+	Page     int
+	PerPage  int
+}
+
+// CustomerValue aggregates the orders of a customer in a currency, cancelled orders
+// left out. AverageOrder is rounded down to a minor unit.
+type CustomerValue struct {
+	UserID       uuid.UUID   `json:"userId"`
+	Name         string      `json:"name"`
+	Email        string      `json:"email"`
+	Orders       int         `json:"orders"`
+	Spent        money.Money `json:"spent"`
+	AverageOrder money.Money `json:"averageOrder"`
+	FirstOrderAt time.Time   `json:"firstOrderAt"`
+	LastOrderAt  time.Time   `json:"lastOrderAt"`
+}
+
+// CustomerSummary aggregates the customers who ordered in a currency. A repeat
+// customer placed more than one order; RepeatRate is their share of the customers
+// and AverageValue the average spend of a customer, their lifetime value.
+type CustomerSummary struct {
+	Currency        string      `json:"currency"`
+	Customers       int         `json:"customers"`
+	RepeatCustomers int         `json:"repeatCustomers"`
+	RepeatRate      float64     `json:"repeatRate"`
+	Revenue         money.Money `json:"revenue"`
+	AverageValue    money.Money `json:"averageValue"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// ReconcileReport summarises a run of the orphaned asset reconciliation.
+type ReconcileReport struct {
+	Scanned   int      `json:"scanned"`
+	Orphaned  int      `json:"orphaned"`
+	Destroyed int      `json:"destroyed"`
+	Failed    []string `json:"failed,omitempty"`
+}
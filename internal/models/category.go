@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxCategoryNameLength caps the name of a category, in characters.
+const MaxCategoryNameLength = 100
+
+// MaxBulkCategories caps the categories named by a bulk update.
+const MaxBulkCategories = 100
+
+// Tax classes of categories and products.
+const (
+	TaxStandard = "standard"
+	TaxReduced  = "reduced"
+	TaxZero     = "zero"
+	TaxExempt   = "exempt"
+)
+
+// TaxClasses are the tax classes a category can be set to.
+var TaxClasses = []string{TaxStandard, TaxReduced, TaxZero, TaxExempt}
+
+// Shipping classes of categories and products.
+const (
+	ShippingStandard = "standard"
+	ShippingBulky    = "bulky"
+	ShippingFragile  = "fragile"
+	ShippingFreight  = "freight"
+)
+
+// ShippingClasses are the shipping classes a category can be set to.
+var ShippingClasses = []string{ShippingStandard, ShippingBulky, ShippingFragile, ShippingFreight}
+
+// Category is a node of the product category tree. A category without a parent is
+// a root. Names are unique regardless of case, so a product can also be filed under
+// a category by name. Products created in a category take its tax class, shipping
+// class and visibility; bulk updates change them for the category and its products.
+type Category struct {
+	CategoryId    uuid.UUID     `json:"id"`
+	Name          string        `json:"name"`
+	ParentId      uuid.NullUUID `json:"parentId"`
+	TaxClass      string        `json:"taxClass"`
+	ShippingClass string        `json:"shippingClass"`
+	Hidden        bool          `json:"hidden"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	Children      []*Category   `json:"children,omitempty"`
+}
+
+// CategoryBulkUpdate sets the tax class, shipping class or visibility of categories,
+// and of their subcategories when IncludeSubcategories is set, cascading to their
+// products. Nil fields are left as they are.
+type CategoryBulkUpdate struct {
+	Categories           []uuid.UUID `json:"categories"`
+	IncludeSubcategories bool        `json:"includeSubcategories"`
+	TaxClass             *string     `json:"taxClass"`
+	ShippingClass        *string     `json:"shippingClass"`
+	Hidden               *bool       `json:"hidden"`
+}
+
+// CategoryBulkSummary counts the categories and products a bulk update changed.
+type CategoryBulkSummary struct {
+	Categories int64 `json:"categories"`
+	Products   int64 `json:"products"`
+}
+
+// CategoryTree arranges a flat list of categories into trees, returning the roots.
+// Siblings keep the order of cats. A category whose parent is not in cats is
+// treated as a root.
+func CategoryTree(cats []Category) []*Category {
+	nodes := make(map[uuid.UUID]*Category, len(cats))
+	for i := range cats {
+		c := cats[i]
+		c.Children = nil
+		nodes[c.CategoryId] = &c
+	}
+
+	roots := []*Category{}
+	for i := range cats {
+		node := nodes[cats[i].CategoryId]
+		if parent, ok := nodes[node.ParentId.UUID]; node.ParentId.Valid && ok {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		roots = append(roots, node)
+	}
+
+	return roots
+}
+
+// IsDescendant reports whether the category id is ancestor itself or lies below it,
+// following the parents in cats.
+func IsDescendant(cats []Category, id, ancestor uuid.UUID) bool {
+	parents := make(map[uuid.UUID]uuid.NullUUID, len(cats))
+	for _, c := range cats {
+		parents[c.CategoryId] = c.ParentId
+	}
+
+	// the depth bound stops at a cycle, which the tables do not allow anyway
+	for depth := 0; depth <= len(cats); depth++ {
+		if id == ancestor {
+			return true
+		}
+		parent, ok := parents[id]
+		if !ok || !parent.Valid {
+			return false
+		}
+		id = parent.UUID
+	}
+
+	return false
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// Checkout session statuses
+const (
+	CheckoutOpen      = "open"
+	CheckoutCompleted = "completed"
+	CheckoutExpired   = "expired"
+)
+
+// CheckoutSession locks the prices of a cart until ExpiresAt. The payment intent
+// and the order placed at the end of checkout are charged the locked totals.
+// CreditApplied is the store credit taken off TotalPrice; it is spent when the
+// order is placed. All amounts are in Currency.
+type CheckoutSession struct {
+	ID              uuid.UUID       `json:"id"`
+	UserID          uuid.UUID       `json:"userID"`
+	Currency        string          `json:"currency"`
+	Items           []*CheckoutItem `json:"items"`
+	ItemsPrice      money.Money     `json:"itemsPrice"`
+	TaxPrice        money.Money     `json:"taxPrice"`
+	ShippingPrice   money.Money     `json:"shippingPrice"`
+	CreditApplied   money.Money     `json:"creditApplied"`
+	TotalPrice      money.Money     `json:"totalPrice"`
+	Status          string          `json:"status"`
+	PaymentIntentID string          `json:"-"`
+	OrderID         uuid.NullUUID   `json:"orderID"`
+	ExpiresAt       time.Time       `json:"expiresAt"`
+	CreatedAt       time.Time       `json:"createdAt"`
+}
+
+// CheckoutItem is a cart line with the product price locked at session creation.
+// VariantID is set when the line is a variant of the product.
+type CheckoutItem struct {
+	ProductID uuid.UUID     `json:"product"`
+	VariantID uuid.NullUUID `json:"variant"`
+	Name      string        `json:"name"`
+	Price     money.Money   `json:"price"`
+	Quantity  int           `json:"quantity"`
+}
+
+// Item returns the locked line for productID and variantID, or nil when it is not
+// part of the session.
+func (s *CheckoutSession) Item(productID uuid.UUID, variantID uuid.NullUUID) *CheckoutItem {
+	for _, i := range s.Items {
+		if i.ProductID == productID && i.VariantID == variantID {
+			return i
+		}
+	}
+
+	return nil
+}
+
+// UseCurrency sets the currency of the session and labels its amounts with it, for
+// sessions read from the database.
+func (s *CheckoutSession) UseCurrency(code string) {
+	s.Currency = code
+	for _, m := range []*money.Money{&s.ItemsPrice, &s.TaxPrice, &s.ShippingPrice, &s.CreditApplied, &s.TotalPrice} {
+		*m = m.WithCurrency(code)
+	}
+	for _, i := range s.Items {
+		i.Price = i.Price.WithCurrency(code)
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bulk cleanups an admin can run.
+const (
+	// CleanupInactiveUsers deletes the customer accounts created before the cutoff
+	// that never placed an order.
+	CleanupInactiveUsers = "inactive-users"
+	// CleanupExpiredSessions deletes the sign-in tokens that expired before the cutoff.
+	CleanupExpiredSessions = "expired-sessions"
+)
+
+// CleanupTargets lists the bulk cleanups, in the order they are documented.
+var CleanupTargets = []string{CleanupInactiveUsers, CleanupExpiredSessions}
+
+// ValidCleanupTarget reports whether target is one of CleanupTargets.
+func ValidCleanupTarget(target string) bool {
+	for _, t := range CleanupTargets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanupPlan is the result of the dry run of a bulk cleanup: what it would delete
+// and the token that confirms it. Matched counts the records that would be deleted
+// and Sample lists the ids of the first of them. There is no token when nothing
+// matched.
+type CleanupPlan struct {
+	Target    string      `json:"target"`
+	Before    time.Time   `json:"before"`
+	Matched   int         `json:"matched"`
+	Sample    []uuid.UUID `json:"sample"`
+	Token     string      `json:"token,omitempty"`
+	ExpiresAt *time.Time  `json:"expiresAt,omitempty"`
+}
+
+// CleanupResult reports a confirmed bulk cleanup.
+type CleanupResult struct {
+	Target  string `json:"target"`
+	Deleted int64  `json:"deleted"`
+}
+
+// CleanupConfirmation is the stored side of the token of a dry run. Only the hash of
+// the token is kept, with the admin who ran the dry run and a digest of the ids it
+// matched, so the cleanup only runs if it would delete what the admin was shown.
+// UsedAt is set once the cleanup ran, so the token cannot be replayed.
+type CleanupConfirmation struct {
+	ID        uuid.UUID  `json:"-"`
+	AdminID   uuid.UUID  `json:"-"`
+	Target    string     `json:"-"`
+	Before    time.Time  `json:"-"`
+	TokenHash []byte     `json:"-"`
+	Digest    []byte     `json:"-"`
+	Matched   int        `json:"-"`
+	Expiry    time.Time  `json:"-"`
+	UsedAt    *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// Store credit reasons
+const (
+	CreditRefund     = "refund"
+	CreditGoodwill   = "goodwill"
+	CreditAdjustment = "adjustment"
+	// CreditCheckout is spending credit on a checkout session
+	CreditCheckout = "checkout"
+	// CreditCheckoutReleased gives back credit of a checkout session whose order failed
+	CreditCheckoutReleased = "checkout_released"
+)
+
+// CreditReasons lists the reasons an admin can grant or deduct credit for.
+var CreditReasons = []string{CreditRefund, CreditGoodwill, CreditAdjustment}
+
+// ValidCreditReason reports whether reason is one of CreditReasons.
+func ValidCreditReason(reason string) bool {
+	for _, r := range CreditReasons {
+		if r == reason {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CreditEntry is a line of the store credit ledger of a user: a positive amount
+// credits the account, a negative one spends or deducts credit.
+type CreditEntry struct {
+	ID        uuid.UUID     `json:"id"`
+	UserID    uuid.UUID     `json:"userID"`
+	Amount    money.Money   `json:"amount"`
+	Reason    string        `json:"reason"`
+	Note      string        `json:"note,omitempty"`
+	SessionID uuid.NullUUID `json:"checkoutSession"`
+	CreatedBy uuid.NullUUID `json:"createdBy"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+// CreditBalance is the store credit of a user with the latest ledger entries.
+type CreditBalance struct {
+	UserID  uuid.UUID      `json:"userID"`
+	Balance money.Money    `json:"balance"`
+	Entries []*CreditEntry `json:"entries"`
+}
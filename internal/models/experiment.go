@@ -0,0 +1,15 @@
+package models
+
+import "github.com/jofosuware/go/shopit/pkg/money"
+
+// VariantSummary aggregates the exposures and conversions of one variant of a feature flag.
+// A subject converts when it places an order after being exposed.
+type VariantSummary struct {
+	Flag           string      `json:"flag"`
+	Variant        string      `json:"variant"`
+	Exposed        int         `json:"exposed"`
+	Converted      int         `json:"converted"`
+	Orders         int         `json:"orders"`
+	Revenue        money.Money `json:"revenue"`
+	ConversionRate float64     `json:"conversionRate"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Data export kinds
+const (
+	// ExportOrders is the CSV export of orders
+	ExportOrders = "orders"
+	// ExportProducts is the CSV export of the catalog
+	ExportProducts = "products"
+	// ExportSales is the sales report, as CSV or XLSX
+	ExportSales = "sales"
+)
+
+// DataExport is the audit record of a file of shop data downloaded by an admin: who
+// downloaded it, with which filters, how many rows it had and the SHA-256 of its
+// content, so a leaked file can be traced back to its export. UserID is unset once
+// the admin is deleted; Email is kept.
+type DataExport struct {
+	ID        uuid.UUID         `json:"id"`
+	UserID    uuid.NullUUID     `json:"userId"`
+	Email     string            `json:"email"`
+	Kind      string            `json:"kind"`
+	Filters   map[string]string `json:"filters"`
+	Rows      int               `json:"rows"`
+	Bytes     int64             `json:"bytes"`
+	SHA256    string            `json:"sha256"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
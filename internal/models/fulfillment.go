@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// PackingItem is a product and how many units of it go in the box.
+type PackingItem struct {
+	ProductID uuid.UUID `json:"product"`
+	Name      string    `json:"name"`
+	SKU       string    `json:"sku,omitempty"`
+	Quantity  int       `json:"quantity"`
+}
+
+// PickLine is a product to pick from the shelves, summed over the selected orders.
+type PickLine struct {
+	PackingItem
+	// Orders is how many of the selected orders contain the product
+	Orders int `json:"orders"`
+}
+
+// PickList is what warehouse staff pick for a batch of orders.
+type PickList struct {
+	Orders     []uuid.UUID `json:"orders"`
+	Lines      []PickLine  `json:"lines"`
+	TotalUnits int         `json:"totalUnits"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+// PackingSlip lists what goes in the box of one order and where it is shipped.
+// Totals is left out of gift orders that hide their prices, since the slip
+// travels to the recipient.
+type PackingSlip struct {
+	OrderID     uuid.UUID     `json:"orderID"`
+	OrderStatus string        `json:"orderStatus"`
+	OrderedAt   time.Time     `json:"orderedAt"`
+	ShipTo      Shipping      `json:"shipTo"`
+	Items       []PackingItem `json:"items"`
+	TotalUnits  int           `json:"totalUnits"`
+	Gift        bool          `json:"gift"`
+	GiftMessage string        `json:"giftMessage,omitempty"`
+	Totals      *SlipTotals   `json:"totals,omitempty"`
+}
+
+// SlipTotals are the prices of an order as printed on its packing slip.
+type SlipTotals struct {
+	ItemsPrice    money.Money `json:"itemsPrice"`
+	ShippingPrice money.Money `json:"shippingPrice"`
+	TaxPrice      money.Money `json:"taxPrice"`
+	Discount      money.Money `json:"discount"`
+	TotalPrice    money.Money `json:"totalPrice"`
+}
+
+// DeliveryEstimate is the window in which a shipment is expected to arrive.
+// MinDays and MaxDays count business days, handling included.
+type DeliveryEstimate struct {
+	Method   string    `json:"method"`
+	MinDays  int       `json:"minDays"`
+	MaxDays  int       `json:"maxDays"`
+	Earliest time.Time `json:"earliest"`
+	Latest   time.Time `json:"latest"`
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API key scopes
+const (
+	ScopeInventoryWrite = "inventory:write"
+	ScopeOrdersRead     = "orders:read"
+	ScopeProductsRead   = "products:read"
+)
+
+// Scopes lists the scopes an API key can be granted.
+var Scopes = []string{ScopeInventoryWrite, ScopeOrdersRead, ScopeProductsRead}
+
+// ValidScope reports whether scope is one of Scopes.
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// APIKey authenticates an external system (POS, ERP) on the integration endpoints.
+// Key is the plain text key; it is only set when the key is created.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Key        string     `json:"key,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	Revoked    bool       `json:"revoked"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Principal returns the service principal the key authenticates as.
+func (k *APIKey) Principal() *ServicePrincipal {
+	return &ServicePrincipal{KeyID: k.ID, Name: k.Name, Scopes: k.Scopes}
+}
+
+// ServicePrincipal is the identity of an external system authenticated by an API
+// key, stored in the request context in place of a user.
+type ServicePrincipal struct {
+	KeyID  uuid.UUID `json:"keyID"`
+	Name   string    `json:"name"`
+	Scopes []string  `json:"scopes"`
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p *ServicePrincipal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StockLevel is the stock of a product as counted by an external system.
+type StockLevel struct {
+	ProductID uuid.UUID `json:"product"`
+	Stock     int       `json:"stock"`
+}
+
+// InventorySyncReport tells an external system which stock levels were applied.
+type InventorySyncReport struct {
+	Updated int         `json:"updated"`
+	Unknown []uuid.UUID `json:"unknown"`
+}
+
+// Webhook events, named after the domain events they carry.
+const (
+	WebhookOrderCreated   = "order.created"
+	WebhookOrderDelivered = "order.delivered"
+	WebhookProductUpdated = "product.updated"
+)
+
+// WebhookEvents lists the events a webhook can subscribe to.
+var WebhookEvents = []string{WebhookOrderCreated, WebhookOrderDelivered, WebhookProductUpdated}
+
+// ValidWebhookEvent reports whether event is one of WebhookEvents.
+func ValidWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EventType documents a webhook event for integrators: Schema is the JSON Schema of
+// the payload posted, Sample a payload as it would be posted.
+type EventType struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+	Sample      json.RawMessage `json:"sample"`
+}
+
+// ValidWebhookURL reports whether raw is an absolute http or https URL a webhook can
+// be posted to.
+func ValidWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is an endpoint of an external system the shop posts the events it
+// subscribed to. Secret signs the payloads; it is only set when the webhook is
+// created.
+type Webhook struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookDelivery is an event posted, or to be posted, to a webhook. It is kept as
+// the delivery log: Attempts, ResponseStatus and LastError tell how the last attempt
+// went. URL and Secret are those of the webhook, set when the delivery is claimed.
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	WebhookID      uuid.UUID       `json:"webhookID"`
+	EventID        uuid.UUID       `json:"eventID"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"-"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	URL            string          `json:"-"`
+	Secret         string          `json:"-"`
+}
+
+// OrderCursor is the position of an integration in the orders feed: the creation time
+// and id of the last order it received. Orders are read in that order, so orders
+// created at the same time are neither skipped nor read twice.
+type OrderCursor struct {
+	CreatedAt time.Time
+	OrderID   uuid.UUID
+}
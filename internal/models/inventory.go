@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stock adjustment reasons
+const (
+	// StockShipped takes the items of an order from stock as it ships
+	StockShipped = "shipped"
+	// StockOrderDeleted gives back the items of a deleted order that had shipped
+	StockOrderDeleted = "order_deleted"
+	// StockRestock is a correction made by an admin
+	StockRestock = "restock"
+)
+
+// StockAdjustment is a line of the inventory log: a positive quantity adds units to
+// the stock of a product, or of its variant, a negative one takes them. Adjustments
+// made for an order name it; those made by an admin name the admin.
+type StockAdjustment struct {
+	ID        uuid.UUID     `json:"id"`
+	ProductID uuid.UUID     `json:"productID"`
+	VariantID uuid.NullUUID `json:"variantID"`
+	Quantity  int           `json:"quantity"`
+	Reason    string        `json:"reason"`
+	OrderID   uuid.NullUUID `json:"orderID"`
+	UserID    uuid.NullUUID `json:"userID"`
+	Note      string        `json:"note"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+// OrderHoldsStock reports whether an order in status has taken its items from stock.
+// Orders take them when they ship, or are delivered without shipping first.
+func OrderHoldsStock(status string) bool {
+	return status == OrderShipped || status == OrderDelivered
+}
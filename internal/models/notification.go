@@ -0,0 +1,155 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// Notification channels
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+)
+
+// NotificationChannels lists the channels a user can be notified on.
+var NotificationChannels = []string{ChannelEmail, ChannelSMS, ChannelPush}
+
+// Notification events a user can turn channels on or off for. Account security
+// messages, such as password reset links, are not optional and always emailed.
+const (
+	// EventOrderPlaced confirms an order
+	EventOrderPlaced = "order_placed"
+	// EventOrderStatus tells the status of an order changed
+	EventOrderStatus = "order_status"
+	// EventPriceDrop tells the price of a wishlisted product was lowered
+	EventPriceDrop = "price_drop"
+	// EventTicketUpdate tells the support team answered a ticket or changed its status
+	EventTicketUpdate = "ticket_update"
+)
+
+// Events only shown in the notification center, which have no preferences.
+const (
+	// EventPasswordChanged tells the password of a user was changed
+	EventPasswordChanged = "password_changed"
+	// EventPromo announces a promotion of the shop to every user
+	EventPromo = "promo"
+)
+
+// NotificationEvents lists the events a user has preferences for.
+var NotificationEvents = []string{EventOrderPlaced, EventOrderStatus, EventPriceDrop, EventTicketUpdate}
+
+// ValidNotificationEvent reports whether event is one of NotificationEvents.
+func ValidNotificationEvent(event string) bool {
+	for _, e := range NotificationEvents {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NotificationPreference turns the channels of an event on or off.
+type NotificationPreference struct {
+	Email bool `json:"email"`
+	SMS   bool `json:"sms"`
+	Push  bool `json:"push"`
+}
+
+// Enabled reports whether channel is turned on.
+func (p NotificationPreference) Enabled(channel string) bool {
+	switch channel {
+	case ChannelEmail:
+		return p.Email
+	case ChannelSMS:
+		return p.SMS
+	case ChannelPush:
+		return p.Push
+	}
+
+	return false
+}
+
+// NotificationPreferences maps events to the channels a user is notified on.
+type NotificationPreferences map[string]NotificationPreference
+
+// Notification is a message about Event. Email channels render Template with Data
+// under Subject; channels without templates, such as SMS and push, send Text.
+type Notification struct {
+	Event    string
+	Subject  string
+	Template string
+	Data     interface{}
+	Text     string
+}
+
+// UserNotification is a notification in the notification center of a user, shown
+// under the bell of the storefront whatever the channels the user turned on. ReadAt
+// is nil until the user reads it.
+type UserNotification struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"-"`
+	Event     string     `json:"event"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"readAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// How often an admin is emailed the digest of the shop activity.
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestFrequencies lists the frequencies an admin can receive the digest at.
+var DigestFrequencies = []string{DigestOff, DigestDaily, DigestWeekly}
+
+// DigestPeriod returns how much activity a digest sent at frequency covers, which is
+// also how often it is sent. It reports false for DigestOff and unknown frequencies.
+func DigestPeriod(frequency string) (time.Duration, bool) {
+	switch frequency {
+	case DigestDaily:
+		return 24 * time.Hour, true
+	case DigestWeekly:
+		return 7 * 24 * time.Hour, true
+	}
+
+	return 0, false
+}
+
+// DigestSubscription is how often an admin receives the digest, and when it was last
+// sent to them.
+type DigestSubscription struct {
+	UserID     uuid.UUID  `json:"-"`
+	Frequency  string     `json:"frequency"`
+	LastSentAt *time.Time `json:"lastSentAt,omitempty"`
+}
+
+// Digest summarizes the activity of the shop between From and To. Revenue only
+// counts orders in the shop currency, and LowStock lists the products and variants
+// left with the fewest units.
+type Digest struct {
+	From           time.Time
+	To             time.Time
+	NewOrders      int
+	Revenue        money.Money
+	FailedPayments int
+	NewReviews     int
+	LowStock       []LowStockItem
+}
+
+// LowStockItem is a product, or one of its variants when VariantID is set, running out
+// of stock. Threshold is the low-stock threshold of the product, when known.
+type LowStockItem struct {
+	ProductID uuid.UUID     `json:"productId"`
+	VariantID uuid.NullUUID `json:"variantId"`
+	Name      string        `json:"name"`
+	SKU       string        `json:"sku"`
+	Stock     int           `json:"stock"`
+	Threshold int           `json:"threshold"`
+}
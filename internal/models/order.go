@@ -1,25 +1,222 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// MaxGiftMessageLength is the longest gift message an order can carry.
+const MaxGiftMessageLength = 500
+
+// Statuses an order goes through.
+const (
+	OrderProcessing = "Processing"
+	OrderShipped    = "Shipped"
+	OrderDelivered  = "Delivered"
+	OrderCancelled  = "Cancelled"
+)
+
+// orderTransitions gives the statuses an admin can move an order to from each status.
+// Delivered and Cancelled orders are final.
+var orderTransitions = map[string][]string{
+	OrderProcessing: {OrderShipped, OrderDelivered},
+	OrderShipped:    {OrderDelivered},
+}
+
+// ParseOrderStatus returns the order status named by s, ignoring case and surrounding
+// spaces. It reports false when s names no status.
+func ParseOrderStatus(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	for _, status := range []string{OrderProcessing, OrderShipped, OrderDelivered, OrderCancelled} {
+		if strings.EqualFold(s, status) {
+			return status, true
+		}
+	}
+
+	return "", false
+}
+
+// NextOrderStatuses returns the statuses an order in status can be moved to. An order
+// left in a status that is not known is treated as Processing, so it can be put back
+// on track.
+func NextOrderStatuses(status string) []string {
+	current, ok := ParseOrderStatus(status)
+	if !ok {
+		current = OrderProcessing
+	}
+
+	return orderTransitions[current]
+}
+
+// Statuses of an order payment, named as Stripe reports them; the statuses of other
+// providers are mapped to these. Orders are placed with a payment that requires a
+// payment method, which only the provider moves on. A payment authorized for manual capture requires
+// capture until the order ships. A payment whose order the customer cancelled after
+// the money was taken is pending refund, which the shop issues.
+const (
+	PaymentRequiresMethod  = "requires_payment_method"
+	PaymentRequiresAction  = "requires_action"
+	PaymentProcessing      = "processing"
+	PaymentRequiresCapture = "requires_capture"
+	PaymentSucceeded       = "succeeded"
+	PaymentCanceled        = "canceled"
+	PaymentFailed          = "failed"
+	PaymentRefundPending   = "refund_pending"
+)
+
+// Providers payments are made with.
+const (
+	PaymentStripe = "stripe"
+	PaymentPayPal = "paypal"
 )
 
 type Order struct {
-	OrderID       uuid.UUID `json:"id"`
-	ShippingInfo  Shipping  `json:"shippingInfo"`
-	OrderItems    []*Item   `json:"orderItems"`
-	PaymentInfo   Payment   `json:"paymentInfo"`
-	UserID        uuid.UUID `json:"userID"`
-	PaidAt        time.Time `json:"paidAt"`
-	ItemPrice     int       `json:"itemsPrice"`
-	TaxPrice      float64       `json:"taxPrice"`
-	ShippingPrice int       `json:"shippingPrice"`
-	TotalPrice    int       `json:"totalPrice"`
-	OrderStatus   string    `json:"orderStatus"`
-	DeliveredAt   time.Time `json:"deliveredAt"`
-	CreatedAt     time.Time `json:"createdAt"`
+	OrderID       uuid.UUID   `json:"id"`
+	ShippingInfo  Shipping    `json:"shippingInfo"`
+	OrderItems    []*Item     `json:"orderItems"`
+	PaymentInfo   Payment     `json:"paymentInfo"`
+	UserID        uuid.UUID   `json:"userID"`
+	Currency      string      `json:"currency"`
+	PaidAt        time.Time   `json:"paidAt"`
+	ItemPrice     money.Money `json:"itemsPrice"`
+	TaxPrice      money.Money `json:"taxPrice"`
+	ShippingPrice money.Money `json:"shippingPrice"`
+	TotalPrice    money.Money `json:"totalPrice"`
+	CouponCode    string      `json:"couponCode,omitempty"`
+	Discount      money.Money `json:"discount"`
+	Gift          bool        `json:"gift"`
+	GiftMessage   string      `json:"giftMessage,omitempty"`
+	HidePrices    bool        `json:"hidePrices"`
+	OrderStatus   string      `json:"orderStatus"`
+	Variant       string      `json:"variant"`
+	DeliveredAt   time.Time   `json:"deliveredAt"`
+	CreatedAt     time.Time   `json:"createdAt"`
+}
+
+// UseCurrency sets the currency of the order and labels its amounts with it, for
+// orders read from the database.
+func (o *Order) UseCurrency(code string) {
+	o.Currency = code
+	for _, m := range []*money.Money{&o.ItemPrice, &o.TaxPrice, &o.ShippingPrice, &o.TotalPrice, &o.Discount} {
+		*m = m.WithCurrency(code)
+	}
+	for _, i := range o.OrderItems {
+		i.Price = i.Price.WithCurrency(code)
+	}
+}
+
+// AmountDue recomputes what the order costs from its items, shipping and tax, less
+// the coupon discount, in the currency of the order.
+func (o *Order) AmountDue() money.Money {
+	due := money.New(0, o.Currency)
+	for _, i := range o.OrderItems {
+		due = due.Add(i.Price.WithCurrency(o.Currency).Mul(i.Quantity))
+	}
+	due = due.Add(o.ShippingPrice).Add(o.TaxPrice).Sub(o.Discount)
+
+	return money.Max(due, money.New(0, o.Currency))
+}
+
+// Kinds of the discount lines of an order total.
+const (
+	DiscountCoupon      = "coupon"
+	DiscountStoreCredit = "storeCredit"
+)
+
+// OrderTotals breaks down what an order is charged: the items, a line per tax rate,
+// the shipping and the discounts taken off, all in the currency of the order.
+// GrandTotal is the amount charged, the sum of the other lines.
+type OrderTotals struct {
+	Subtotal   money.Money    `json:"subtotal"`
+	Taxes      []TaxLine      `json:"taxes"`
+	Shipping   money.Money    `json:"shipping"`
+	Discounts  []DiscountLine `json:"discounts"`
+	GrandTotal money.Money    `json:"grandTotal"`
+}
+
+// TaxLine is the tax charged at a rate, a percentage of the subtotal such as "15" or
+// "7.5". The rate is empty when the order has no subtotal to take it from.
+type TaxLine struct {
+	Rate   string      `json:"rate"`
+	Amount money.Money `json:"amount"`
+}
+
+// DiscountLine is an amount taken off an order, with the code of the coupon for
+// coupon discounts.
+type DiscountLine struct {
+	Kind   string      `json:"kind"`
+	Code   string      `json:"code,omitempty"`
+	Amount money.Money `json:"amount"`
+}
+
+// Totals prices the order from the amounts recorded on it. Orders record a single tax
+// amount, so they have one tax line when they are taxed. Store credit spent on the
+// order, which is not recorded, is what the charged total falls short of the other
+// lines.
+func (o *Order) Totals() OrderTotals {
+	t := OrderTotals{
+		Subtotal:   o.ItemPrice.WithCurrency(o.Currency),
+		Taxes:      []TaxLine{},
+		Shipping:   o.ShippingPrice.WithCurrency(o.Currency),
+		Discounts:  []DiscountLine{},
+		GrandTotal: o.TotalPrice.WithCurrency(o.Currency),
+	}
+
+	if tax := o.TaxPrice.WithCurrency(o.Currency); !tax.IsZero() {
+		t.Taxes = append(t.Taxes, TaxLine{Rate: taxRate(tax, t.Subtotal), Amount: tax})
+	}
+	if discount := o.Discount.WithCurrency(o.Currency); discount.IsPositive() {
+		t.Discounts = append(t.Discounts, DiscountLine{Kind: DiscountCoupon, Code: o.CouponCode, Amount: discount})
+	}
+
+	credit := t.Subtotal.Add(t.Shipping)
+	for _, l := range t.Taxes {
+		credit = credit.Add(l.Amount)
+	}
+	for _, l := range t.Discounts {
+		credit = credit.Sub(l.Amount)
+	}
+	if credit = credit.Sub(t.GrandTotal); credit.IsPositive() {
+		t.Discounts = append(t.Discounts, DiscountLine{Kind: DiscountStoreCredit, Amount: credit})
+	}
+
+	return t
+}
+
+// taxRate returns the percentage tax is of subtotal, rounded to two decimals with
+// trailing zeros left out.
+func taxRate(tax, subtotal money.Money) string {
+	if !subtotal.IsPositive() {
+		return ""
+	}
+
+	bp := (tax.Amount*10000 + subtotal.Amount/2) / subtotal.Amount
+	rate := fmt.Sprintf("%d.%02d", bp/100, bp%100)
+
+	return strings.TrimSuffix(strings.TrimRight(rate, "0"), ".")
+}
+
+// MarshalJSON writes the order with its totals, so clients show the breakdown instead
+// of adding up the amounts themselves.
+func (o Order) MarshalJSON() ([]byte, error) {
+	type order Order
+	return json.Marshal(struct {
+		order
+		Totals OrderTotals `json:"totals"`
+	}{order(o), o.Totals()})
+}
+
+// OrderSummary is an order as exported for analytics: its amounts, statuses and the
+// city and country it ships to, with the number of items it holds instead of the
+// items.
+type OrderSummary struct {
+	Order
+	ItemCount int
 }
 
 type Shipping struct {
@@ -34,24 +231,64 @@ type Shipping struct {
 }
 
 type Item struct {
-	ItemID    uuid.UUID `json:"product"`
-	Name      string    `json:"name"`
-	Price     int       `json:"price"`
-	Quantity  int       `json:"quantity"`
-	Image     string    `json:"image"`
-	ProductID uuid.UUID `json:"productID"`
-	OrderID   uuid.UUID `json:"orderID"`
+	ItemID    uuid.UUID     `json:"product"`
+	Name      string        `json:"name"`
+	Price     money.Money   `json:"price"`
+	Quantity  int           `json:"quantity"`
+	Image     string        `json:"image"`
+	ProductID uuid.UUID     `json:"productID"`
+	VariantID uuid.NullUUID `json:"variantID"`
+	OrderID   uuid.UUID     `json:"orderID"`
 	CreatedAt time.Time
 }
 
 type Payment struct {
 	ID        string    `json:"id"`
+	Provider  string    `json:"provider"`
 	Status    string    `json:"status"`
 	OrderID   uuid.UUID `json:"orderID,omitempty"`
 	CreatedAt time.Time
 }
 
+// Outstanding reports whether the payment has not taken the money, because it failed,
+// was canceled or was never completed, so its order can be paid again.
+func (p *Payment) Outstanding() bool {
+	switch p.Status {
+	case PaymentProcessing, PaymentRequiresCapture, PaymentSucceeded, PaymentRefundPending:
+		return false
+	}
+	return true
+}
+
+// OrderCancellation records the customer who cancelled an order, and why.
+type OrderCancellation struct {
+	OrderID   uuid.UUID `json:"orderID"`
+	UserID    uuid.UUID `json:"userID"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Invoice is the archived copy of the invoice of a paid order.
+type Invoice struct {
+	OrderID   uuid.UUID `json:"orderID"`
+	URL       string    `json:"url"`
+	PublicID  string    `json:"publicID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// EventOrderStatusChanged names the realtime event pushed when an order changes status.
+const EventOrderStatusChanged = "status"
+
+// OrderStatusChange is pushed to the subscribers of an order when its status changes,
+// and first when they subscribe.
+type OrderStatusChange struct {
+	OrderID   uuid.UUID `json:"orderId"`
+	Status    string    `json:"status"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
 type OrderResponse struct {
-	Success bool  `json:"success"`
-	Order   Order `json:"order,omitempty"`
+	Success           bool              `json:"success"`
+	Order             Order             `json:"order,omitempty"`
+	EstimatedDelivery *DeliveryEstimate `json:"estimatedDelivery,omitempty"`
 }
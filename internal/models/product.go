@@ -1,26 +1,186 @@
 package models
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/money"
 )
 
 // Product full model
 type Product struct {
-	ProductId    uuid.UUID `json:"id"`
-	Name         string    `json:"name"`
-	Price        float64   `json:"price"`
-	Description  string    `json:"description"`
-	Ratings      int       `json:"ratings"`
-	Images       []Images  `json:"images"`
-	Category     string    `json:"category"`
-	Seller       string    `json:"seller"`
-	Stock        int       `json:"stock"`
-	NumOfReviews int       `json:"numOfReviews"`
-	Reviews      []Reviews `json:"reviews"`
-	UserId       uuid.UUID `json:"userId"`
-	CreatedAt    time.Time
+	ProductId     uuid.UUID     `json:"id"`
+	Name          string        `json:"name"`
+	SKU           string        `json:"sku,omitempty"`
+	Price         money.Money   `json:"price"`
+	Description   string        `json:"description"`
+	Ratings       int           `json:"ratings"`
+	Images        []Images      `json:"images"`
+	Category      string        `json:"category"`
+	CategoryId    uuid.NullUUID `json:"categoryId"`
+	TaxClass      string        `json:"taxClass"`
+	ShippingClass string        `json:"shippingClass"`
+	Hidden        bool          `json:"hidden"`
+	Status        string        `json:"status"`
+	PublishAt     *time.Time    `json:"publishAt,omitempty"`
+	Seller        string        `json:"seller"`
+	Stock         int           `json:"stock"`
+	NumOfReviews  int           `json:"numOfReviews"`
+	Reviews       []Reviews     `json:"reviews"`
+	Variants      []Variant     `json:"variants,omitempty"`
+	UserId        uuid.UUID     `json:"userId"`
+	CreatedAt     time.Time
+	// LowStockThreshold is the stock at or under which admins are alerted
+	LowStockThreshold int `json:"-"`
+}
+
+// Product statuses. Only published products are shown to shoppers; drafts are being
+// prepared and archived products are no longer sold. A draft with a PublishAt is
+// published by the scheduler once that time has come.
+const (
+	ProductDraft     = "draft"
+	ProductPublished = "published"
+	ProductArchived  = "archived"
+)
+
+// ProductStatuses lists the statuses of a product.
+var ProductStatuses = []string{ProductDraft, ProductPublished, ProductArchived}
+
+// Listed reports whether shoppers can see the product: it is published and its
+// category is not hidden.
+func (p *Product) Listed() bool {
+	return !p.Hidden && p.Status == ProductPublished
+}
+
+// InStock reports whether units of the product are left.
+func (p *Product) InStock() bool {
+	return p.Stock > 0
+}
+
+// LowStock reports whether the product is in stock at or under its low-stock
+// threshold.
+func (p *Product) LowStock() bool {
+	return p.InStock() && p.Stock <= p.LowStockThreshold
+}
+
+// Recommendation is a product suggested alongside others, with the image to show
+// it by.
+type Recommendation struct {
+	ProductId uuid.UUID   `json:"id"`
+	Name      string      `json:"name"`
+	Price     money.Money `json:"price"`
+	Ratings   int         `json:"ratings"`
+	Image     string      `json:"image,omitempty"`
+}
+
+// Viewer is who viewed a product: a signed in user, or else the anonymous session of
+// a visitor.
+type Viewer struct {
+	UserID  uuid.NullUUID
+	Session string
+}
+
+// Variant is a version of a product, such as a size or a colour, with its own SKU and
+// stock. It costs the product price plus PriceDelta. Attributes name the variant,
+// e.g. {"size": "M", "color": "red"}.
+type Variant struct {
+	VariantId  uuid.UUID         `json:"id"`
+	ProductId  uuid.UUID         `json:"productId"`
+	SKU        string            `json:"sku,omitempty"`
+	Attributes map[string]string `json:"attributes"`
+	PriceDelta money.Money       `json:"priceDelta"`
+	Stock      int               `json:"stock"`
+	CreatedAt  time.Time
+}
+
+// Label returns the attribute values of the variant ordered by attribute name,
+// e.g. "red, M" for {"color": "red", "size": "M"}.
+func (v Variant) Label() string {
+	keys := make([]string, 0, len(v.Attributes))
+	for k := range v.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = v.Attributes[k]
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// MaxVariants caps the variants of a product.
+const MaxVariants = 100
+
+// VariantErrors returns what is wrong with the variants of p, keyed by their position
+// such as "variants[0]". Every variant needs attributes, a price above zero and no
+// negative stock; no two variants may share attributes or a SKU.
+func (p *Product) VariantErrors() map[string]string {
+	errs := make(map[string]string)
+	if len(p.Variants) > MaxVariants {
+		errs["variants"] = fmt.Sprintf("a product must not have more than %d variants", MaxVariants)
+		return errs
+	}
+
+	labels := make(map[string]bool, len(p.Variants))
+	skus := make(map[string]bool, len(p.Variants))
+	for i, v := range p.Variants {
+		key := fmt.Sprintf("variants[%d]", i)
+		label := canonicalAttributes(v.Attributes)
+
+		switch {
+		case len(v.Attributes) == 0:
+			errs[key] = "variant attributes must be provided"
+		case !validAttributes(v.Attributes):
+			errs[key] = "variant attribute names and values must be 1 to 50 characters"
+		case labels[label]:
+			errs[key] = fmt.Sprintf("variant %q is listed twice", v.Label())
+		case v.SKU != "" && skus[v.SKU]:
+			errs[key] = fmt.Sprintf("sku %q is used by another variant", v.SKU)
+		case len(v.SKU) > 64:
+			errs[key] = "variant sku must not be more than 64 characters"
+		case !p.Price.Add(v.PriceDelta).IsPositive():
+			errs[key] = "variant price must be greater than zero"
+		case v.Stock < 0:
+			errs[key] = "variant stock must not be negative"
+		}
+
+		labels[label] = true
+		if v.SKU != "" {
+			skus[v.SKU] = true
+		}
+	}
+
+	return errs
+}
+
+// validAttributes reports whether every attribute name and value is 1 to 50 characters.
+func validAttributes(attrs map[string]string) bool {
+	for k, v := range attrs {
+		if n := utf8.RuneCountInString(k); n == 0 || n > 50 {
+			return false
+		}
+		if n := utf8.RuneCountInString(v); n == 0 || n > 50 {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalAttributes returns attrs as "name=value" pairs ordered by name, so equal
+// attribute sets compare equal.
+func canonicalAttributes(attrs map[string]string) string {
+	pairs := make([]string, 0, len(attrs))
+	for k, v := range attrs {
+		pairs = append(pairs, strings.ToLower(k)+"="+strings.ToLower(v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
 }
 
 // Images model
@@ -39,19 +199,127 @@ type Reviews struct {
 	Comment   string    `json:"comment"`
 	UserId    uuid.UUID `json:"userId"`
 	ProductId uuid.UUID `json:"productId"`
+	// Images are the photos the reviewer attached, up to MaxReviewImages
+	Images []ReviewImage `json:"images,omitempty"`
+	// VerifiedPurchase is set when the reviewer has a delivered order of the product
+	VerifiedPurchase bool `json:"verifiedPurchase"`
+	// Helpful and Unhelpful count the votes of users on the review
+	Helpful   int `json:"helpful"`
+	Unhelpful int `json:"unhelpful"`
 	CreatedAt time.Time
 }
 
+// Orders the reviews of a product are listed in.
+const (
+	ReviewsOldest  = "oldest"
+	ReviewsNewest  = "newest"
+	ReviewsHelpful = "helpful"
+)
+
+// ReviewSorts are the orders reviews can be listed in, the first by default.
+var ReviewSorts = []string{ReviewsOldest, ReviewsNewest, ReviewsHelpful}
+
+// ReviewVotes counts the votes of users on a review.
+type ReviewVotes struct {
+	Helpful   int `json:"helpful"`
+	Unhelpful int `json:"unhelpful"`
+}
+
+// MaxReviewImages caps the images of a review.
+const MaxReviewImages = 3
+
+// ReviewImage is a photo attached to a review.
+type ReviewImage struct {
+	PublicId  string    `json:"publicId"`
+	Url       string    `json:"url"`
+	ReviewId  uuid.UUID `json:"reviewId"`
+	CreatedAt time.Time
+}
+
+// ProductValidationReport is the outcome of validating a product without saving it.
+// Errors maps a field, or an image by position such as "images[0]", to what is wrong with it,
+// and Codes maps it to the code of the error.
+type ProductValidationReport struct {
+	Valid  bool              `json:"valid"`
+	Errors map[string]string `json:"errors,omitempty"`
+	Codes  map[string]string `json:"codes,omitempty"`
+}
+
+// ProductImportReport is the outcome of a product CSV import. Rows with errors are
+// skipped; Errors lists them by their line in the file, the header being line 1.
+type ProductImportReport struct {
+	Created int                  `json:"created"`
+	Updated int                  `json:"updated"`
+	Failed  int                  `json:"failed"`
+	Errors  []ProductImportError `json:"errors"`
+}
+
+// ProductImportError tells what is wrong with a row of a product CSV import.
+type ProductImportError struct {
+	Line   int               `json:"line"`
+	Errors map[string]string `json:"errors"`
+}
+
+// ProductUpdate sets the price, the stock, or both, of a product in a bulk update. A
+// nil field is left as it is.
+type ProductUpdate struct {
+	ProductId uuid.UUID    `json:"productId"`
+	Price     *money.Money `json:"price"`
+	Stock     *int         `json:"stock"`
+}
+
+// BulkUpdateReport is the outcome of a bulk update of products. Invalid updates are
+// skipped; Errors lists them by their index in the request, the first being 0.
+type BulkUpdateReport struct {
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Errors  []BulkUpdateError `json:"errors"`
+}
+
+// BulkUpdateError tells what is wrong with an update of a bulk update.
+type BulkUpdateError struct {
+	Index     int               `json:"index"`
+	ProductId uuid.UUID         `json:"productId"`
+	Errors    map[string]string `json:"errors"`
+}
+
 type ProdResponse struct {
 	Success bool    `json:"success"`
 	Token   string  `json:"token,omitempty"`
 	Product Product `json:"product"`
 }
 
+// Orders products can be listed in, ascending or, prefixed with "-", descending.
+const (
+	ProductsDate  = "date"
+	ProductsPrice = "price"
+	ProductsStock = "stock"
+)
+
+// ProductSorts are the orders products can be listed in.
+var ProductSorts = []string{ProductsDate, ProductsPrice, ProductsStock}
+
+// ProductFilter selects a page of products: those whose name contains one of
+// Keywords, within the category subtree of Category when it is set, and owned by the
+// user Owner when it is set. A PerPage of 0 means 12 products a page; an empty Sort
+// lists the oldest first. Hidden products, and those that are not published, are only
+// listed with Hidden.
+type ProductFilter struct {
+	Keywords []string
+	Category uuid.NullUUID
+	Owner    uuid.NullUUID
+	Page     int
+	PerPage  int
+	Sort     string
+	Hidden   bool
+}
+
 type GetProd struct {
 	Success               bool      `json:"success"`
 	ProductCount          int       `json:"productCount"`
 	ResPerPage            int       `json:"resPerPage"`
 	FilteredProductsCount int       `json:"filteredProductsCount"`
 	Products              []Product `json:"products"`
+	// SearchID identifies a keyword search, to report the clicks on its results
+	SearchID string `json:"searchId,omitempty"`
 }
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// Coupon discount types
+const (
+	// CouponPercentage takes a percentage off the items price
+	CouponPercentage = "percentage"
+	// CouponFixed takes a fixed amount off the items price
+	CouponFixed = "fixed"
+)
+
+// MaxCouponCodeLength is the longest a coupon code can be.
+const MaxCouponCodeLength = 50
+
+// Coupon is a discount code. Value is a percentage for a percentage coupon and an
+// amount in minor units for a fixed one. The coupon only applies to an items price of at least
+// MinOrderValue. A zero MaxUses or MaxUsesPerUser means no limit and a nil ExpiresAt
+// means the coupon does not expire.
+type Coupon struct {
+	ID             uuid.UUID   `json:"id"`
+	Code           string      `json:"code"`
+	Type           string      `json:"type"`
+	Value          int         `json:"value"`
+	MinOrderValue  money.Money `json:"minOrderValue"`
+	MaxUses        int         `json:"maxUses"`
+	MaxUsesPerUser int         `json:"maxUsesPerUser"`
+	UsedCount      int         `json:"usedCount"`
+	ExpiresAt      *time.Time  `json:"expiresAt"`
+	Active         bool        `json:"active"`
+	CreatedAt      time.Time   `json:"createdAt"`
+}
+
+// Discount returns what the coupon takes off itemsPrice, never more than itemsPrice.
+// A percentage is rounded down to a minor unit.
+func (c *Coupon) Discount(itemsPrice money.Money) money.Money {
+	if !itemsPrice.IsPositive() {
+		return money.New(0, itemsPrice.Currency)
+	}
+
+	if c.Type == CouponPercentage {
+		return money.Min(itemsPrice.Percent(c.Value), itemsPrice)
+	}
+
+	return money.Min(money.New(int64(c.Value), itemsPrice.Currency), itemsPrice)
+}
+
+// CouponRedemption records a use of a coupon. OrderID is set once the order it was
+// redeemed for is placed.
+type CouponRedemption struct {
+	ID        uuid.UUID     `json:"id"`
+	CouponID  uuid.UUID     `json:"couponID"`
+	Code      string        `json:"code"`
+	UserID    uuid.UUID     `json:"userID"`
+	OrderID   uuid.NullUUID `json:"orderID"`
+	Discount  money.Money   `json:"discount"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+// CouponQuote is what a coupon takes off a cart.
+type CouponQuote struct {
+	Code            string      `json:"code"`
+	Type            string      `json:"type"`
+	Value           int         `json:"value"`
+	ItemsPrice      money.Money `json:"itemsPrice"`
+	Discount        money.Money `json:"discount"`
+	DiscountedPrice money.Money `json:"discountedPrice"`
+}
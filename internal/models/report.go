@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// Periods sales are reported by
+const (
+	GroupByDay   = "day"
+	GroupByWeek  = "week"
+	GroupByMonth = "month"
+)
+
+// Formats of the report files
+const (
+	ReportCSV  = "csv"
+	ReportXLSX = "xlsx"
+)
+
+// SalesPeriod is the sales of a day, week or month in a currency. Orders, Revenue
+// and Tax are of the orders placed in the period, leaving out those cancelled before
+// they were paid; Refunds and Refunded are of the paid orders cancelled in the
+// period, whenever they were placed. Period is the start of the period in UTC, a
+// Monday for weeks.
+type SalesPeriod struct {
+	Period   time.Time
+	Currency string
+	Orders   int
+	Revenue  money.Money
+	Tax      money.Money
+	Refunds  int
+	Refunded money.Money
+}
+
+// UseCurrency sets the currency of the period and labels its amounts with it, for
+// periods read from the database.
+func (p *SalesPeriod) UseCurrency(code string) {
+	p.Currency = code
+	for _, m := range []*money.Money{&p.Revenue, &p.Tax, &p.Refunded} {
+		*m = m.WithCurrency(code)
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Events of the search log.
+const (
+	SearchEventSearch = "search"
+	SearchEventClick  = "click"
+)
+
+// MaxSearchKeyword caps the length of the keywords recorded in the search log.
+const MaxSearchKeyword = 100
+
+// SearchEvent is a catalog search by keyword, or a click on one of its results.
+// A click carries the search it came from and the product clicked.
+type SearchEvent struct {
+	ID        uuid.UUID     `json:"id"`
+	Event     string        `json:"event"`
+	Keyword   string        `json:"keyword"`
+	Results   int           `json:"results"`
+	SearchID  uuid.NullUUID `json:"searchId"`
+	ProductID uuid.NullUUID `json:"productId"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+// SearchTerm sums up the searches for a keyword.
+type SearchTerm struct {
+	Keyword        string    `json:"keyword"`
+	Searches       int       `json:"searches"`
+	LastSearchedAt time.Time `json:"lastSearchedAt"`
+}
+
+// MaxSynonymTerms caps the terms of a synonym set.
+const MaxSynonymTerms = 20
+
+// SynonymSet is a set of search terms that mean the same thing, such as "sneakers"
+// and "trainers". A search for one of the terms also finds the products named with
+// the others. Terms are kept in lower case.
+type SynonymSet struct {
+	ID        uuid.UUID `json:"id"`
+	Terms     []string  `json:"terms"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "github.com/google/uuid"
+
+// Most records of each kind a seed run generates.
+const (
+	MaxSeedUsers             = 10000
+	MaxSeedProducts          = 10000
+	MaxSeedImagesPerProduct  = 5
+	MaxSeedReviewsPerProduct = 50
+	MaxSeedOrdersPerUser     = 20
+)
+
+// SeedOptions sets the volumes of fake data a seed run generates. Reviews are
+// written by the generated users on the generated products, and orders placed by
+// them for those products. Seed makes a run repeatable: the same seed generates
+// the same names, products, ratings and orders, zero a random seed; emails stay
+// unique so a seed can be run again. Owner is the user the products are
+// listed by, the first generated user when unset. Admin makes the first generated
+// user an admin, for a local database to be administered right away; the seed
+// endpoint does not take it.
+type SeedOptions struct {
+	Users             int       `json:"users"`
+	Products          int       `json:"products"`
+	ImagesPerProduct  int       `json:"imagesPerProduct"`
+	ReviewsPerProduct int       `json:"reviewsPerProduct"`
+	OrdersPerUser     int       `json:"ordersPerUser"`
+	Seed              int64     `json:"seed"`
+	Owner             uuid.UUID `json:"-"`
+	Admin             bool      `json:"-"`
+}
+
+// SeedReport counts the records a seed run created. Every generated user signs in
+// with Password, the admin among them, if any, with the email Admin.
+type SeedReport struct {
+	Users      int    `json:"users"`
+	Categories int    `json:"categories"`
+	Products   int    `json:"products"`
+	Images     int    `json:"images"`
+	Reviews    int    `json:"reviews"`
+	Orders     int    `json:"orders"`
+	Password   string `json:"password"`
+	Admin      string `json:"admin,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Seller application statuses
+const (
+	// ApplicationPending awaits the review of an admin
+	ApplicationPending = "pending"
+	// ApplicationApproved gave its user the seller role
+	ApplicationApproved = "approved"
+	// ApplicationRejected was turned down; its user may apply again
+	ApplicationRejected = "rejected"
+)
+
+// ApplicationStatuses lists the statuses a seller application can be in.
+var ApplicationStatuses = []string{ApplicationPending, ApplicationApproved, ApplicationRejected}
+
+// SellerApplication is the request of a customer to sell their products in the shop.
+// Name and Email are the ones of its user. ReviewedBy and ReviewedAt are set once an
+// admin approved or rejected it; ReviewedBy is null again once that admin is deleted.
+type SellerApplication struct {
+	ID         uuid.UUID     `json:"id"`
+	UserID     uuid.UUID     `json:"userId"`
+	Name       string        `json:"name"`
+	Email      string        `json:"email"`
+	StoreName  string        `json:"storeName"`
+	Message    string        `json:"message"`
+	Status     string        `json:"status"`
+	ReviewedBy uuid.NullUUID `json:"reviewedBy"`
+	ReviewedAt *time.Time    `json:"reviewedAt,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Support ticket statuses
+const (
+	// TicketOpen awaits an answer of the support team
+	TicketOpen = "open"
+	// TicketAnswered awaits the customer
+	TicketAnswered = "answered"
+	// TicketClosed is resolved; a reply of the customer opens it again
+	TicketClosed = "closed"
+)
+
+// TicketStatuses lists the statuses a support ticket can be in.
+var TicketStatuses = []string{TicketOpen, TicketAnswered, TicketClosed}
+
+// ValidTicketStatus reports whether status is one of TicketStatuses.
+func ValidTicketStatus(status string) bool {
+	for _, s := range TicketStatuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Ticket is a support request of a customer, optionally about one of their orders.
+// Messages are the conversation with the support team, oldest first; they are only
+// filled when a single ticket is read.
+type Ticket struct {
+	ID        uuid.UUID       `json:"id"`
+	UserID    uuid.UUID       `json:"userId"`
+	OrderID   uuid.NullUUID   `json:"orderId"`
+	Subject   string          `json:"subject"`
+	Status    string          `json:"status"`
+	Messages  []TicketMessage `json:"messages,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// TicketMessage is a message of a support ticket, written by its customer or, when
+// FromAdmin is set, by an admin. UserID is null once its author is deleted.
+type TicketMessage struct {
+	ID        uuid.UUID     `json:"id"`
+	TicketID  uuid.UUID     `json:"ticketId"`
+	UserID    uuid.NullUUID `json:"userId"`
+	FromAdmin bool          `json:"fromAdmin"`
+	Body      string        `json:"body"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+// TicketUpdate is a change of a support ticket to notify about: a ticket opened, a
+// message added, or a new status without a message. Changes made by an admin are
+// sent to the customer, the others to the admins.
+type TicketUpdate struct {
+	TicketID  uuid.UUID `json:"ticketId"`
+	UserID    uuid.UUID `json:"userId"`
+	Subject   string    `json:"subject"`
+	Status    string    `json:"status"`
+	Message   string    `json:"message"`
+	FromAdmin bool      `json:"fromAdmin"`
+}
@@ -6,7 +6,8 @@ import (
 	"github.com/google/uuid"
 )
 
-// Token is the type for authentication tokens
+// Token is the type for authentication tokens. UserAgent and IP describe the device
+// that signed in with it.
 type Token struct {
 	ID        uuid.UUID `json:"-"`
 	PlainText string    `json:"token"`
@@ -14,6 +15,35 @@ type Token struct {
 	Hash      []byte    `json:"-"`
 	Expiry    time.Time `json:"expiry"`
 	Scope     string    `json:"-"`
+	UserAgent string    `json:"-"`
+	IP        string    `json:"-"`
 	CreatedAt time.Time `json:"-"`
 	UpdatedAt time.Time `json:"-"`
 }
+
+// Session is an unexpired authentication token of a user, as listed to them: the
+// device that signed in with it, and whether it is the one of the listing request.
+type Session struct {
+	ID        uuid.UUID `json:"id"`
+	UserAgent string    `json:"userAgent"`
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"createdAt"`
+	Expiry    time.Time `json:"expiry"`
+	Current   bool      `json:"current"`
+}
+
+// MagicLink is a passwordless sign-in link emailed to a user. Only the hashes of its
+// token and of the device that requested it are stored. IP and UserAgent describe
+// that device; UsedAt is set once the link signed the user in, so it cannot be
+// replayed.
+type MagicLink struct {
+	ID         uuid.UUID  `json:"-"`
+	UserID     uuid.UUID  `json:"-"`
+	TokenHash  []byte     `json:"-"`
+	DeviceHash []byte     `json:"-"`
+	IP         string     `json:"-"`
+	UserAgent  string     `json:"-"`
+	Expiry     time.Time  `json:"-"`
+	UsedAt     *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"-"`
+}
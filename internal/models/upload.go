@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Direct upload kinds
+const (
+	// UploadProductImage is an image an admin adds to a product
+	UploadProductImage = "product_image"
+)
+
+// Upload is a file a user was allowed to upload straight to storage. It is claimed
+// once attached to the record it was uploaded for, which must happen before it
+// expires; unclaimed uploads are left to the orphaned asset cleanup.
+type Upload struct {
+	PublicID  string     `json:"publicId"`
+	UserID    uuid.UUID  `json:"userId"`
+	Kind      string     `json:"kind"`
+	URL       string     `json:"url"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	ClaimedAt *time.Time `json:"claimedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -4,8 +4,31 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/money"
 )
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+	// RoleSeller manages the products they list, and only those
+	RoleSeller = "seller"
+)
+
+// Roles lists the roles a user can be given.
+var Roles = []string{RoleUser, RoleAdmin, RoleSeller}
+
+// ValidRole reports whether role is one of Roles.
+func ValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
 // User full model
 type User struct {
 	ID        uuid.UUID
@@ -15,6 +38,45 @@ type User struct {
 	Role      string    `json:"role"`
 	Avatar    Avatar    `json:"avatar"`
 	CreatedAt time.Time `json:"createdAt"`
+	// DeleteAfter is set while the account is scheduled for deletion
+	DeleteAfter *time.Time `json:"deleteAfter,omitempty"`
+	// StoreCredit is the balance of the store credit ledger
+	StoreCredit money.Money `json:"storeCredit"`
+	// Currency is the currency the user prefers to shop in, empty for the shop currency
+	Currency string `json:"currency"`
+	// Locale is the locale the user reads emails and invoices in, empty for the shop locale
+	Locale string `json:"locale"`
+	// Phone is an E.164 phone number, empty when the user has not given one
+	Phone string `json:"phone"`
+	// DateOfBirth is a date at midnight UTC, unset when the user has not given it
+	DateOfBirth *time.Time `json:"dateOfBirth,omitempty"`
+	// Newsletter is set when the user opted in to marketing emails
+	Newsletter bool `json:"newsletter"`
+}
+
+// ProfileUpdate holds the optional profile details of a user to change. Nil fields
+// are left as they are; an empty phone and a zero date of birth clear them.
+type ProfileUpdate struct {
+	Phone       *string
+	DateOfBirth *time.Time
+	Newsletter  *bool
+}
+
+// Apply sets the details of p on u.
+func (p ProfileUpdate) Apply(u *User) {
+	if p.Phone != nil {
+		u.Phone = *p.Phone
+	}
+	if p.DateOfBirth != nil {
+		u.DateOfBirth = nil
+		if !p.DateOfBirth.IsZero() {
+			dob := *p.DateOfBirth
+			u.DateOfBirth = &dob
+		}
+	}
+	if p.Newsletter != nil {
+		u.Newsletter = *p.Newsletter
+	}
 }
 
 // Avatar model
@@ -24,6 +86,20 @@ type Avatar struct {
 	UserId   uuid.UUID
 }
 
+// PendingAvatar is the avatar, a base64 data URI, of a user that could not be stored
+// when they signed up and is uploaded again in the background.
+type PendingAvatar struct {
+	UserID uuid.UUID `json:"userId"`
+	Avatar string    `json:"avatar"`
+}
+
+// PasswordChange is the password of a user changed at ChangedAt, by the user or
+// with a reset link.
+type PasswordChange struct {
+	UserID    uuid.UUID `json:"userId"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
 type UserResponse struct {
 	Success bool   `json:"success"`
 	Token   string `json:"token,omitempty"`
@@ -39,3 +115,19 @@ type Passwords struct {
 	Password    string
 	OldPassword string
 }
+
+// AccountMerge is the audit record of a duplicate account merged into another. The
+// merged account is deleted; its name and email are kept here, with how many of its
+// orders, reviews and store credit entries were moved to the kept account.
+type AccountMerge struct {
+	ID           uuid.UUID `json:"id"`
+	KeptUserID   uuid.UUID `json:"keptUserId"`
+	MergedUserID uuid.UUID `json:"mergedUserId"`
+	MergedName   string    `json:"mergedName"`
+	MergedEmail  string    `json:"mergedEmail"`
+	Orders       int       `json:"orders"`
+	Reviews      int       `json:"reviews"`
+	StoreCredits int       `json:"storeCredits"`
+	MergedBy     uuid.UUID `json:"mergedBy"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// MaxWishlistItems caps the products on the wishlist of a user.
+const MaxWishlistItems = 100
+
+// WishlistItem is a product a user saved to buy later, as it is now.
+type WishlistItem struct {
+	ProductID uuid.UUID   `json:"productId"`
+	Name      string      `json:"name"`
+	Price     money.Money `json:"price"`
+	Image     string      `json:"image,omitempty"`
+	Stock     int         `json:"stock"`
+	AddedAt   time.Time   `json:"addedAt"`
+}
+
+// PriceDrop tells a user who wishlisted a product that an admin lowered its price
+// from OldPrice to NewPrice.
+type PriceDrop struct {
+	UserID    uuid.UUID   `json:"userId"`
+	ProductID uuid.UUID   `json:"productId"`
+	Name      string      `json:"name"`
+	OldPrice  money.Money `json:"oldPrice"`
+	NewPrice  money.Money `json:"newPrice"`
+}
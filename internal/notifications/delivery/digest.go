@@ -0,0 +1,67 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+type digestResponse struct {
+	Success bool                       `json:"success"`
+	Digest  *models.DigestSubscription `json:"digest"`
+}
+
+// GetDigest returns how often the current admin receives the digest of the shop
+// activity.
+// Endpoint: GET /api/v1/notifications/digest
+func (h *NotificationHandlers) GetDigest(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	sub, err := h.notificationUC.GetDigestSubscription(user.ID)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting digest subscription: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, digestResponse{Success: true, Digest: sub})
+}
+
+// UpdateDigest sets how often the current admin receives the digest of the shop
+// activity: new orders, revenue, failed payments, new reviews and low stock.
+// Endpoint: PUT /api/v1/notifications/digest
+// Expects JSON body: {"frequency": "off" | "daily" | "weekly"}.
+func (h *NotificationHandlers) UpdateDigest(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	var req digestRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	sub, err := h.notificationUC.UpdateDigestSubscription(user.ID, req.Frequency)
+	if err != nil {
+		if notifications.IsClientError(err) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error updating digest subscription: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error updating digest subscription: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, digestResponse{Success: true, Digest: sub})
+}
@@ -0,0 +1,75 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/internal/notifications/delivery"
+	"github.com/jofosuware/go/shopit/internal/notifications/mocks"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateDigest(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	notificationUC := mocks.NewNotificationUC(t)
+
+	h := delivery.NewNotificationHandlers(logger, notificationUC)
+	admin := models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	newRequest := func(method, body string) *http.Request {
+		req := httptest.NewRequest(method, "/notifications/digest", bytes.NewBufferString(body))
+		return req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, &admin))
+	}
+
+	t.Run("Frequency is updated", func(t *testing.T) {
+		notificationUC.On("UpdateDigestSubscription", admin.ID, models.DigestDaily).
+			Return(&models.DigestSubscription{UserID: admin.ID, Frequency: models.DigestDaily}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.UpdateDigest(rr, newRequest(http.MethodPut, `{"frequency":"daily"}`))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Digest models.DigestSubscription `json:"digest"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.DigestDaily, resp.Digest.Frequency)
+	})
+
+	t.Run("Frequency is returned", func(t *testing.T) {
+		notificationUC.On("GetDigestSubscription", admin.ID).
+			Return(&models.DigestSubscription{UserID: admin.ID, Frequency: models.DigestOff}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.GetDigest(rr, newRequest(http.MethodGet, ""))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Digest models.DigestSubscription `json:"digest"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.DigestOff, resp.Digest.Frequency)
+	})
+
+	t.Run("Unknown frequency", func(t *testing.T) {
+		notificationUC.On("UpdateDigestSubscription", admin.ID, "hourly").Return(nil, notifications.ErrUnknownFrequency).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.UpdateDigest(rr, newRequest(http.MethodPut, `{"frequency":"hourly"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,84 @@
+// Package delivery provides HTTP handlers for the notification center, notification
+// preferences and admin digests.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// NotificationHandlers provides HTTP handler methods for notification endpoints.
+type NotificationHandlers struct {
+	logger         logger.Logger
+	notificationUC notifications.NotificationUC
+}
+
+// NewNotificationHandlers returns a new NotificationHandlers.
+func NewNotificationHandlers(logger logger.Logger, notificationUC notifications.NotificationUC) *NotificationHandlers {
+	return &NotificationHandlers{
+		logger:         logger,
+		notificationUC: notificationUC,
+	}
+}
+
+type preferencesResponse struct {
+	Success     bool                           `json:"success"`
+	Preferences models.NotificationPreferences `json:"preferences"`
+}
+
+// GetPreferences returns the notification preferences of the current user for
+// every event.
+// Endpoint: GET /api/v1/notifications/preferences
+func (h *NotificationHandlers) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	prefs, err := h.notificationUC.GetPreferences(user.ID)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting notification preferences: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, preferencesResponse{Success: true, Preferences: prefs})
+}
+
+// UpdatePreferences changes the notification preferences of the current user for
+// the events in the body; other events keep theirs.
+// Endpoint: PUT /api/v1/notifications/preferences
+// Expects JSON body: {"<event>": {"email": <bool>, "sms": <bool>, "push": <bool>}, ...}.
+func (h *NotificationHandlers) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	var payload models.NotificationPreferences
+	if !utils.Bind(w, r, h.logger, &payload) {
+		return
+	}
+
+	prefs, err := h.notificationUC.UpdatePreferences(user.ID, payload)
+	if err != nil {
+		if notifications.IsClientError(err) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error updating notification preferences: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error updating notification preferences: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, preferencesResponse{Success: true, Preferences: prefs})
+}
@@ -0,0 +1,63 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/internal/notifications/delivery"
+	"github.com/jofosuware/go/shopit/internal/notifications/mocks"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdatePreferences(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	notificationUC := mocks.NewNotificationUC(t)
+
+	h := delivery.NewNotificationHandlers(logger, notificationUC)
+	user := models.User{ID: uuid.New()}
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "/notifications/preferences", bytes.NewBufferString(body))
+		return req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, &user))
+	}
+
+	t.Run("Preferences are updated", func(t *testing.T) {
+		update := models.NotificationPreferences{models.EventOrderStatus: {Email: true, Push: true}}
+		notificationUC.On("UpdatePreferences", user.ID, update).Return(models.NotificationPreferences{
+			models.EventOrderPlaced: {Email: true},
+			models.EventOrderStatus: {Email: true, Push: true},
+		}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.UpdatePreferences(rr, newRequest(`{"order_status":{"email":true,"sms":false,"push":true}}`))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Preferences models.NotificationPreferences `json:"preferences"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.True(t, resp.Preferences[models.EventOrderStatus].Push)
+		assert.True(t, resp.Preferences[models.EventOrderPlaced].Email)
+	})
+
+	t.Run("Unknown event", func(t *testing.T) {
+		notificationUC.On("UpdatePreferences", user.ID, mock.Anything).Return(nil, notifications.ErrUnknownEvent).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.UpdatePreferences(rr, newRequest(`{"newsletter":{"email":true}}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
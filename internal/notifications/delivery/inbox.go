@@ -0,0 +1,149 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// GetNotifications returns the latest notifications of the current user, last first,
+// and how many are unread, for the notification bell.
+// Endpoint: GET /api/v1/notifications?unread=<bool>&limit=<int>
+// Only the unread notifications are listed with unread=true; limit defaults to 20 and
+// is capped at 100.
+func (h *NotificationHandlers) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	q := r.URL.Query()
+
+	var unread bool
+	if u := q.Get("unread"); u != "" {
+		b, err := strconv.ParseBool(u)
+		if err != nil {
+			_ = utils.BadRequest(w, r, errors.New("unread must be true or false"))
+			h.logger.Errorf("error parsing unread: %v", u)
+			return
+		}
+		unread = b
+	}
+
+	var limit int
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			_ = utils.BadRequest(w, r, errors.New("limit must be a positive number"))
+			h.logger.Errorf("error parsing limit: %v", l)
+			return
+		}
+		limit = n
+	}
+
+	list, count, err := h.notificationUC.GetNotifications(user.ID, unread, limit)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting notifications: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success       bool                      `json:"success"`
+		Unread        int                       `json:"unread"`
+		Notifications []models.UserNotification `json:"notifications"`
+	}{
+		Success:       true,
+		Unread:        count,
+		Notifications: list,
+	})
+}
+
+// MarkRead marks a notification of the current user read.
+// Endpoint: PUT /api/v1/notifications/{id}/read
+func (h *NotificationHandlers) MarkRead(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	if err := h.notificationUC.MarkRead(user.ID, id); err != nil {
+		if notifications.IsClientError(err) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error marking notification read: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error marking notification read: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, models.Response{Success: true, Message: "notification read"})
+}
+
+// MarkAllRead marks every unread notification of the current user read.
+// Endpoint: PUT /api/v1/notifications/read
+func (h *NotificationHandlers) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	count, err := h.notificationUC.MarkAllRead(user.ID)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error marking notifications read: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success bool  `json:"success"`
+		Read    int64 `json:"read"`
+	}{
+		Success: true,
+		Read:    count,
+	})
+}
+
+// Broadcast adds a promotion to the notification center of every user (admin). It is
+// not emailed.
+// Endpoint: POST /api/v1/notifications/broadcast
+// Expects JSON body: {"title": <string>, "body": <string>}.
+func (h *NotificationHandlers) Broadcast(w http.ResponseWriter, r *http.Request) {
+	var req broadcastRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	count, err := h.notificationUC.Broadcast(strings.TrimSpace(req.Title), strings.TrimSpace(req.Body))
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error broadcasting notification: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusCreated, struct {
+		Success  bool  `json:"success"`
+		Notified int64 `json:"notified"`
+	}{
+		Success:  true,
+		Notified: count,
+	})
+}
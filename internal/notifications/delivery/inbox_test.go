@@ -0,0 +1,153 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/internal/notifications/delivery"
+	"github.com/jofosuware/go/shopit/internal/notifications/mocks"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNotifications(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	notificationUC := mocks.NewNotificationUC(t)
+
+	h := delivery.NewNotificationHandlers(logger, notificationUC)
+	user := models.User{ID: uuid.New()}
+	call := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		h.GetNotifications(rr, req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, &user)))
+		return rr
+	}
+
+	t.Run("Unread notifications and count", func(t *testing.T) {
+		list := []models.UserNotification{{ID: uuid.New(), Event: models.EventPromo, Title: "Summer sale"}}
+		notificationUC.On("GetNotifications", user.ID, true, 5).Return(list, 2, nil).Once()
+
+		rr := call("/notifications?unread=true&limit=5")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Unread        int                       `json:"unread"`
+			Notifications []models.UserNotification `json:"notifications"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, 2, resp.Unread)
+		assert.Equal(t, list, resp.Notifications)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("/notifications?limit=0").Code)
+	})
+
+	t.Run("Invalid unread", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("/notifications?unread=maybe").Code)
+	})
+}
+
+func TestMarkRead(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	notificationUC := mocks.NewNotificationUC(t)
+
+	h := delivery.NewNotificationHandlers(logger, notificationUC)
+	user := models.User{ID: uuid.New()}
+	call := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/notifications/"+id+"/read", nil)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+		rr := httptest.NewRecorder()
+		h.MarkRead(rr, req.WithContext(context.WithValue(ctx, utils.UserContextKey, &user)))
+		return rr
+	}
+
+	t.Run("Notification is read", func(t *testing.T) {
+		id := uuid.New()
+		notificationUC.On("MarkRead", user.ID, id).Return(nil).Once()
+
+		assert.Equal(t, http.StatusOK, call(id.String()).Code)
+	})
+
+	t.Run("No such notification", func(t *testing.T) {
+		id := uuid.New()
+		notificationUC.On("MarkRead", user.ID, id).Return(notifications.ErrNotificationNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call(id.String()).Code)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("not-an-id").Code)
+	})
+}
+
+func TestMarkAllRead(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	notificationUC := mocks.NewNotificationUC(t)
+
+	h := delivery.NewNotificationHandlers(logger, notificationUC)
+	user := models.User{ID: uuid.New()}
+	notificationUC.On("MarkAllRead", user.ID).Return(int64(3), nil).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/notifications/read", nil)
+	rr := httptest.NewRecorder()
+	h.MarkAllRead(rr, req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, &user)))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"success":true,"read":3}`, rr.Body.String())
+}
+
+func TestBroadcast(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	notificationUC := mocks.NewNotificationUC(t)
+
+	h := delivery.NewNotificationHandlers(logger, notificationUC)
+	call := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.Broadcast(rr, httptest.NewRequest(http.MethodPost, "/notifications/broadcast", bytes.NewBufferString(body)))
+		return rr
+	}
+
+	t.Run("Promotion is broadcast", func(t *testing.T) {
+		notificationUC.On("Broadcast", "Summer sale", "20% off everything").Return(int64(42), nil).Once()
+
+		rr := call(`{"title":" Summer sale ","body":"20% off everything"}`)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		assert.JSONEq(t, `{"success":true,"notified":42}`, rr.Body.String())
+	})
+
+	t.Run("Title is required", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, call(`{"title":"  ","body":"20% off"}`).Code)
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		notificationUC.On("Broadcast", "Summer sale", "").Return(int64(0), errors.New("db down")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusInternalServerError, call(`{"title":"Summer sale"}`).Code)
+	})
+}
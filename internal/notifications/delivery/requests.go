@@ -0,0 +1,13 @@
+package delivery
+
+// digestRequest is the body of UpdateDigest. The frequency is checked by the use case.
+type digestRequest struct {
+	Frequency string `json:"frequency" validate:"required"`
+}
+
+// broadcastRequest is the body of Broadcast. The lengths are the ones of the
+// notifications table.
+type broadcastRequest struct {
+	Title string `json:"title" validate:"notblank,max=255"`
+	Body  string `json:"body" validate:"max=2000"`
+}
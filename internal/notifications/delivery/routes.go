@@ -0,0 +1,35 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// NotificationRouter returns a chi.Router with the notification routes.
+//
+//   - GET / → List the latest notifications of the current user, with the unread count
+//   - PUT /read → Mark every notification of the current user read
+//   - PUT /{id}/read → Mark a notification of the current user read
+//   - POST /broadcast → Add a promotion to the notification center of every user (admin)
+//   - GET /preferences → Get the notification preferences of the current user
+//   - PUT /preferences → Update the notification preferences of the current user
+//   - GET /digest → Get how often the current admin receives the digest (admin)
+//   - PUT /digest → Set how often the current admin receives the digest (admin)
+func (h *NotificationHandlers) NotificationRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+
+	mux.Get("/", h.GetNotifications)
+	mux.Put("/read", h.MarkAllRead)
+	mux.Put("/{id}/read", h.MarkRead)
+	mux.With(utils.IsAdmin).Post("/broadcast", h.Broadcast)
+	mux.Get("/preferences", h.GetPreferences)
+	mux.Put("/preferences", h.UpdatePreferences)
+	mux.With(utils.IsAdmin).Get("/digest", h.GetDigest)
+	mux.With(utils.IsAdmin).Put("/digest", h.UpdateDigest)
+
+	return mux
+}
@@ -0,0 +1,29 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+var (
+	// ErrUnknownEvent is returned when preferences name an event that is not one of models.NotificationEvents.
+	ErrUnknownEvent = fmt.Errorf("event must be one of: %s", strings.Join(models.NotificationEvents, ", "))
+
+	// ErrUnknownFrequency is returned when a digest frequency is not one of models.DigestFrequencies.
+	ErrUnknownFrequency = fmt.Errorf("frequency must be one of: %s", strings.Join(models.DigestFrequencies, ", "))
+
+	// ErrUnknownChannel is returned when default preferences name a channel that is not one of models.NotificationChannels.
+	ErrUnknownChannel = fmt.Errorf("channel must be one of: %s", strings.Join(models.NotificationChannels, ", "))
+
+	// ErrNotificationNotFound is returned when a user has no notification with the given id.
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// IsClientError reports whether err is caused by the request rather than the server.
+func IsClientError(err error) bool {
+	return errors.Is(err, ErrUnknownEvent) || errors.Is(err, ErrUnknownFrequency) ||
+		errors.Is(err, ErrNotificationNotFound)
+}
@@ -0,0 +1,308 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// NotificationUC is an autogenerated mock type for the NotificationUC type
+type NotificationUC struct {
+	mock.Mock
+}
+
+// Broadcast provides a mock function with given fields: title, body
+func (_m *NotificationUC) Broadcast(title string, body string) (int64, error) {
+	ret := _m.Called(title, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Broadcast")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (int64, error)); ok {
+		return rf(title, body)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) int64); ok {
+		r0 = rf(title, body)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(title, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDigestSubscription provides a mock function with given fields: userID
+func (_m *NotificationUC) GetDigestSubscription(userID uuid.UUID) (*models.DigestSubscription, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDigestSubscription")
+	}
+
+	var r0 *models.DigestSubscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.DigestSubscription, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.DigestSubscription); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DigestSubscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNotifications provides a mock function with given fields: userID, unread, limit
+func (_m *NotificationUC) GetNotifications(userID uuid.UUID, unread bool, limit int) ([]models.UserNotification, int, error) {
+	ret := _m.Called(userID, unread, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetNotifications")
+	}
+
+	var r0 []models.UserNotification
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, bool, int) ([]models.UserNotification, int, error)); ok {
+		return rf(userID, unread, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, bool, int) []models.UserNotification); ok {
+		r0 = rf(userID, unread, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.UserNotification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, bool, int) int); ok {
+		r1 = rf(userID, unread, limit)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(uuid.UUID, bool, int) error); ok {
+		r2 = rf(userID, unread, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetPreferences provides a mock function with given fields: userID
+func (_m *NotificationUC) GetPreferences(userID uuid.UUID) (models.NotificationPreferences, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPreferences")
+	}
+
+	var r0 models.NotificationPreferences
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (models.NotificationPreferences, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) models.NotificationPreferences); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.NotificationPreferences)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkAllRead provides a mock function with given fields: userID
+func (_m *NotificationUC) MarkAllRead(userID uuid.UUID) (int64, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkAllRead")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (int64, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) int64); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkRead provides a mock function with given fields: userID, id
+func (_m *NotificationUC) MarkRead(userID uuid.UUID, id uuid.UUID) error {
+	ret := _m.Called(userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkRead")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Notify provides a mock function with given fields: userID, n
+func (_m *NotificationUC) Notify(userID uuid.UUID, n models.Notification) error {
+	ret := _m.Called(userID, n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Notify")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.Notification) error); ok {
+		r0 = rf(userID, n)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendDigests provides a mock function with given fields: ctx
+func (_m *NotificationUC) SendDigests(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendDigests")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateDigestSubscription provides a mock function with given fields: userID, frequency
+func (_m *NotificationUC) UpdateDigestSubscription(userID uuid.UUID, frequency string) (*models.DigestSubscription, error) {
+	ret := _m.Called(userID, frequency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDigestSubscription")
+	}
+
+	var r0 *models.DigestSubscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) (*models.DigestSubscription, error)); ok {
+		return rf(userID, frequency)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) *models.DigestSubscription); ok {
+		r0 = rf(userID, frequency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DigestSubscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(userID, frequency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePreferences provides a mock function with given fields: userID, prefs
+func (_m *NotificationUC) UpdatePreferences(userID uuid.UUID, prefs models.NotificationPreferences) (models.NotificationPreferences, error) {
+	ret := _m.Called(userID, prefs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePreferences")
+	}
+
+	var r0 models.NotificationPreferences
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.NotificationPreferences) (models.NotificationPreferences, error)); ok {
+		return rf(userID, prefs)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.NotificationPreferences) models.NotificationPreferences); ok {
+		r0 = rf(userID, prefs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.NotificationPreferences)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, models.NotificationPreferences) error); ok {
+		r1 = rf(userID, prefs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewNotificationUC creates a new instance of NotificationUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewNotificationUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *NotificationUC {
+	mock := &NotificationUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
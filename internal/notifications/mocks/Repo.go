@@ -0,0 +1,417 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+// CountUnread provides a mock function with given fields: userID
+func (_m *Repo) CountUnread(userID uuid.UUID) (int, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountUnread")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (int, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) int); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchAdmins provides a mock function with given fields:
+func (_m *Repo) FetchAdmins() ([]*models.User, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchAdmins")
+	}
+
+	var r0 []*models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.User, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.User); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchDigest provides a mock function with given fields: ctx, from, to, lowStock, limit
+func (_m *Repo) FetchDigest(ctx context.Context, from time.Time, to time.Time, lowStock int, limit int) (*models.Digest, error) {
+	ret := _m.Called(ctx, from, to, lowStock, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchDigest")
+	}
+
+	var r0 *models.Digest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, int, int) (*models.Digest, error)); ok {
+		return rf(ctx, from, to, lowStock, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, int, int) *models.Digest); ok {
+		r0 = rf(ctx, from, to, lowStock, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Digest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time, int, int) error); ok {
+		r1 = rf(ctx, from, to, lowStock, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchDigestSubscribers provides a mock function with given fields: ctx
+func (_m *Repo) FetchDigestSubscribers(ctx context.Context) ([]*models.DigestSubscription, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchDigestSubscribers")
+	}
+
+	var r0 []*models.DigestSubscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*models.DigestSubscription, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.DigestSubscription); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.DigestSubscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchDigestSubscription provides a mock function with given fields: userID
+func (_m *Repo) FetchDigestSubscription(userID uuid.UUID) (*models.DigestSubscription, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchDigestSubscription")
+	}
+
+	var r0 *models.DigestSubscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.DigestSubscription, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.DigestSubscription); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DigestSubscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchNotifications provides a mock function with given fields: userID, unread, limit
+func (_m *Repo) FetchNotifications(userID uuid.UUID, unread bool, limit int) ([]models.UserNotification, error) {
+	ret := _m.Called(userID, unread, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchNotifications")
+	}
+
+	var r0 []models.UserNotification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, bool, int) ([]models.UserNotification, error)); ok {
+		return rf(userID, unread, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, bool, int) []models.UserNotification); ok {
+		r0 = rf(userID, unread, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.UserNotification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, bool, int) error); ok {
+		r1 = rf(userID, unread, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchPreferences provides a mock function with given fields: userID
+func (_m *Repo) FetchPreferences(userID uuid.UUID) (models.NotificationPreferences, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchPreferences")
+	}
+
+	var r0 models.NotificationPreferences
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (models.NotificationPreferences, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) models.NotificationPreferences); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.NotificationPreferences)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchRecipient provides a mock function with given fields: userID
+func (_m *Repo) FetchRecipient(userID uuid.UUID) (*models.User, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchRecipient")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.User, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.User); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertBroadcast provides a mock function with given fields: event, title, body
+func (_m *Repo) InsertBroadcast(event string, title string, body string) (int64, error) {
+	ret := _m.Called(event, title, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertBroadcast")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (int64, error)); ok {
+		return rf(event, title, body)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) int64); ok {
+		r0 = rf(event, title, body)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(event, title, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertNotification provides a mock function with given fields: n
+func (_m *Repo) InsertNotification(n *models.UserNotification) error {
+	ret := _m.Called(n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertNotification")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.UserNotification) error); ok {
+		r0 = rf(n)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkAllRead provides a mock function with given fields: userID, readAt
+func (_m *Repo) MarkAllRead(userID uuid.UUID, readAt time.Time) (int64, error) {
+	ret := _m.Called(userID, readAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkAllRead")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) (int64, error)); ok {
+		return rf(userID, readAt)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) int64); ok {
+		r0 = rf(userID, readAt)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, time.Time) error); ok {
+		r1 = rf(userID, readAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkDigestSent provides a mock function with given fields: userID, sentAt
+func (_m *Repo) MarkDigestSent(userID uuid.UUID, sentAt time.Time) error {
+	ret := _m.Called(userID, sentAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkDigestSent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) error); ok {
+		r0 = rf(userID, sentAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkRead provides a mock function with given fields: userID, id, readAt
+func (_m *Repo) MarkRead(userID uuid.UUID, id uuid.UUID, readAt time.Time) error {
+	ret := _m.Called(userID, id, readAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkRead")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, time.Time) error); ok {
+		r0 = rf(userID, id, readAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertDigestSubscription provides a mock function with given fields: userID, frequency
+func (_m *Repo) UpsertDigestSubscription(userID uuid.UUID, frequency string) error {
+	ret := _m.Called(userID, frequency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertDigestSubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = rf(userID, frequency)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertPreferences provides a mock function with given fields: userID, prefs
+func (_m *Repo) UpsertPreferences(userID uuid.UUID, prefs models.NotificationPreferences) error {
+	ret := _m.Called(userID, prefs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertPreferences")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.NotificationPreferences) error); ok {
+		r0 = rf(userID, prefs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
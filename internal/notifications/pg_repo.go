@@ -0,0 +1,64 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// FetchPreferences fetches the preferences a user set, by event, returns an error on failure
+	FetchPreferences(userID uuid.UUID) (models.NotificationPreferences, error)
+
+	// UpsertPreferences saves the preferences of a user for the given events, returns an error on failure
+	UpsertPreferences(userID uuid.UUID, prefs models.NotificationPreferences) error
+
+	// FetchRecipient fetches the name and contact details of a user, returns sql.ErrNoRows when there is no such user
+	FetchRecipient(userID uuid.UUID) (*models.User, error)
+
+	// FetchAdmins fetches the name and contact details of the admins, returns an error on failure
+	FetchAdmins() ([]*models.User, error)
+
+	// FetchDigestSubscription fetches how often a user receives the admin digest, returns sql.ErrNoRows when
+	// the user never chose
+	FetchDigestSubscription(userID uuid.UUID) (*models.DigestSubscription, error)
+
+	// UpsertDigestSubscription saves how often a user receives the admin digest, returns an error on failure
+	UpsertDigestSubscription(userID uuid.UUID, frequency string) error
+
+	// FetchDigestSubscribers fetches the subscriptions of the admins receiving the digest, returns an error
+	// on failure
+	FetchDigestSubscribers(ctx context.Context) ([]*models.DigestSubscription, error)
+
+	// MarkDigestSent records when the last digest was sent to a user, returns an error on failure
+	MarkDigestSent(userID uuid.UUID, sentAt time.Time) error
+
+	// FetchDigest summarizes the activity of the shop in [from, to) with at most limit items of at most
+	// lowStock units, returns the digest and an error on failure
+	FetchDigest(ctx context.Context, from, to time.Time, lowStock, limit int) (*models.Digest, error)
+
+	// InsertNotification adds a notification to the notification center of its user, returns an error on
+	// failure
+	InsertNotification(n *models.UserNotification) error
+
+	// InsertBroadcast adds a notification to the notification center of every user, returns how many
+	// users were notified and an error on failure
+	InsertBroadcast(event, title, body string) (int64, error)
+
+	// FetchNotifications fetches the latest limit notifications of a user, only the unread ones when
+	// unread is set, returns an error on failure
+	FetchNotifications(userID uuid.UUID, unread bool, limit int) ([]models.UserNotification, error)
+
+	// CountUnread counts the unread notifications of a user, returns an error on failure
+	CountUnread(userID uuid.UUID) (int, error)
+
+	// MarkRead marks a notification of a user read, returns sql.ErrNoRows when the user has no such
+	// notification
+	MarkRead(userID, id uuid.UUID, readAt time.Time) error
+
+	// MarkAllRead marks the unread notifications of a user read, returns how many were marked and an
+	// error on failure
+	MarkAllRead(userID uuid.UUID, readAt time.Time) (int64, error)
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// FetchDigestSubscription fetches how often a user receives the admin digest. It
+// returns sql.ErrNoRows when the user never chose.
+func (r *NotificationsRepository) FetchDigestSubscription(userID uuid.UUID) (*models.DigestSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	s := models.DigestSubscription{UserID: userID}
+
+	err := r.DB.QueryRowContext(ctx, "select frequency, last_sent_at from digest_subscriptions where user_id = $1", userID).
+		Scan(&s.Frequency, &s.LastSentAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// UpsertDigestSubscription saves how often a user receives the admin digest. The
+// time the last digest was sent is kept.
+func (r *NotificationsRepository) UpsertDigestSubscription(userID uuid.UUID, frequency string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into digest_subscriptions (user_id, frequency, updated_at) values ($1, $2, $3)
+				on conflict (user_id) do update set frequency = excluded.frequency, updated_at = excluded.updated_at`
+
+	_, err := r.DB.ExecContext(ctx, query, userID, frequency, time.Now())
+
+	return err
+}
+
+// FetchDigestSubscribers fetches the subscriptions of the admins receiving the digest,
+// leaving out accounts scheduled for deletion.
+func (r *NotificationsRepository) FetchDigestSubscribers(ctx context.Context) ([]*models.DigestSubscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select d.user_id, d.frequency, d.last_sent_at
+				from digest_subscriptions d
+				inner join users u on (u.user_id = d.user_id)
+				where d.frequency <> $1 and u.role = $2 and u.delete_after is null`
+
+	rows, err := r.DB.QueryContext(ctx, query, models.DigestOff, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*models.DigestSubscription
+	for rows.Next() {
+		var s models.DigestSubscription
+		if err := rows.Scan(&s.UserID, &s.Frequency, &s.LastSentAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subs, nil
+}
+
+// MarkDigestSent records when the last digest was sent to a user.
+func (r *NotificationsRepository) MarkDigestSent(userID uuid.UUID, sentAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, "update digest_subscriptions set last_sent_at = $1 where user_id = $2", sentAt, userID)
+
+	return err
+}
+
+// FetchDigest summarizes the activity of the shop in [from, to): the orders placed,
+// their revenue in the shop currency, the payments that failed and the reviews
+// posted, along with the limit products and variants of visible products with at
+// most lowStock units left, fewest first.
+func (r *NotificationsRepository) FetchDigest(ctx context.Context, from, to time.Time, lowStock, limit int) (*models.Digest, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	d := models.Digest{From: from, To: to}
+
+	query := `select count(*), coalesce(sum(total_price) filter (where currency = $1), 0)
+				from orders where created_at >= $2 and created_at < $3 and order_status <> $4`
+
+	err := r.DB.QueryRowContext(ctx, query, money.DefaultCurrency, from, to, models.OrderCancelled).
+		Scan(&d.NewOrders, &d.Revenue)
+	if err != nil {
+		return nil, fmt.Errorf("error summarizing orders: %v", err)
+	}
+
+	query = `select count(*) from payments where status = $1 and created_at >= $2 and created_at < $3`
+
+	if err := r.DB.QueryRowContext(ctx, query, models.PaymentFailed, from, to).Scan(&d.FailedPayments); err != nil {
+		return nil, fmt.Errorf("error counting failed payments: %v", err)
+	}
+
+	query = `select count(*) from reviews where created_at >= $1 and created_at < $2`
+
+	if err := r.DB.QueryRowContext(ctx, query, from, to).Scan(&d.NewReviews); err != nil {
+		return nil, fmt.Errorf("error counting reviews: %v", err)
+	}
+
+	query = `select p.product_id, p.name, coalesce(v.sku, ''), coalesce(v.stock, p.stock)
+				from products p
+				left join product_variants v on (v.product_id = p.product_id)
+				where not p.hidden and coalesce(v.stock, p.stock) <= $1
+				order by 4, 2
+				limit $2`
+
+	rows, err := r.DB.QueryContext(ctx, query, lowStock, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching low stock: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i models.LowStockItem
+		if err := rows.Scan(&i.ProductID, &i.Name, &i.SKU, &i.Stock); err != nil {
+			return nil, fmt.Errorf("error fetching low stock: %v", err)
+		}
+		d.LowStock = append(d.LowStock, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error fetching low stock: %v", err)
+	}
+
+	return &d, nil
+}
@@ -0,0 +1,73 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications/repository"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchDigestSubscribers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewNotificationsRepository(db)
+	first, second := uuid.New(), uuid.New()
+	sent := time.Now().Add(-24 * time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta("where d.frequency <> $1 and u.role = $2 and u.delete_after is null")).
+		WithArgs(models.DigestOff, models.RoleAdmin).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "frequency", "last_sent_at"}).
+			AddRow(first, models.DigestDaily, nil).
+			AddRow(second, models.DigestWeekly, sent))
+
+	subs, err := repo.FetchDigestSubscribers(context.Background())
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+	assert.Nil(t, subs[0].LastSentAt)
+	assert.Equal(t, models.DigestWeekly, subs[1].Frequency)
+	assert.Equal(t, sent, *subs[1].LastSentAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchDigest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewNotificationsRepository(db)
+	from, to := time.Now().Add(-24*time.Hour), time.Now()
+	productID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("from orders where created_at >= $2 and created_at < $3 and order_status <> $4")).
+		WithArgs(money.DefaultCurrency, from, to, models.OrderCancelled).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "sum"}).AddRow(3, 12500))
+	mock.ExpectQuery(regexp.QuoteMeta("from payments where status = $1")).
+		WithArgs(models.PaymentFailed, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("from reviews where created_at >= $1")).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta("where not p.hidden and coalesce(v.stock, p.stock) <= $1")).
+		WithArgs(5, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "name", "sku", "stock"}).
+			AddRow(productID, "Kente Scarf", "KS-RED-M", 2))
+
+	d, err := repo.FetchDigest(context.Background(), from, to, 5, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 3, d.NewOrders)
+	assert.Equal(t, money.New(12500, money.DefaultCurrency), d.Revenue)
+	assert.Equal(t, 1, d.FailedPayments)
+	assert.Equal(t, 2, d.NewReviews)
+	assert.Equal(t, []models.LowStockItem{{ProductID: productID, Name: "Kente Scarf", SKU: "KS-RED-M", Stock: 2}}, d.LowStock)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
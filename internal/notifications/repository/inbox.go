@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// InsertNotification adds n to the notification center of its user, setting its id
+// and creation time.
+func (r *NotificationsRepository) InsertNotification(n *models.UserNotification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into notifications (user_id, event, title, body)
+				values ($1, $2, $3, $4)
+				returning notification_id, created_at`
+
+	return r.DB.QueryRowContext(ctx, query, n.UserID, n.Event, n.Title, n.Body).Scan(&n.ID, &n.CreatedAt)
+}
+
+// InsertBroadcast adds a notification of event to the notification center of every
+// user not scheduled for deletion, in one statement. It returns how many users were
+// notified.
+func (r *NotificationsRepository) InsertBroadcast(event, title, body string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	query := `insert into notifications (user_id, event, title, body)
+				select user_id, $1, $2, $3 from users where delete_after is null`
+
+	res, err := r.DB.ExecContext(ctx, query, event, title, body)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// FetchNotifications fetches the latest limit notifications of a user, last first,
+// only the unread ones when unread is set.
+func (r *NotificationsRepository) FetchNotifications(userID uuid.UUID, unread bool, limit int) ([]models.UserNotification, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select notification_id, event, title, body, read_at, created_at
+				from notifications
+				where user_id = $1 and (not $2 or read_at is null)
+				order by created_at desc
+				limit $3`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID, unread, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.UserNotification{}
+	for rows.Next() {
+		n := models.UserNotification{UserID: userID}
+		var readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.Event, &n.Title, &n.Body, &readAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+
+		list = append(list, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// CountUnread counts the notifications of a user not read yet.
+func (r *NotificationsRepository) CountUnread(userID uuid.UUID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var n int
+	err := r.DB.QueryRowContext(ctx, "select count(*) from notifications where user_id = $1 and read_at is null", userID).
+		Scan(&n)
+
+	return n, err
+}
+
+// MarkRead marks the notification id of a user read at readAt, keeping the time it
+// was first read. It returns sql.ErrNoRows when the user has no such notification.
+func (r *NotificationsRepository) MarkRead(userID, id uuid.UUID, readAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update notifications set read_at = coalesce(read_at, $3)
+				where notification_id = $1 and user_id = $2`
+
+	res, err := r.DB.ExecContext(ctx, query, id, userID, readAt)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// MarkAllRead marks the unread notifications of a user read at readAt, returns how
+// many were marked.
+func (r *NotificationsRepository) MarkAllRead(userID uuid.UUID, readAt time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.DB.ExecContext(ctx, "update notifications set read_at = $2 where user_id = $1 and read_at is null",
+		userID, readAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
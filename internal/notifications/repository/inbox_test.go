@@ -0,0 +1,116 @@
+package repository_test
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertNotification(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	id, userID, created := uuid.New(), uuid.New(), time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("insert into notifications (user_id, event, title, body)")).
+		WithArgs(userID, models.EventOrderStatus, "Your ShopIT order status", "Your order is now shipped.").
+		WillReturnRows(sqlmock.NewRows([]string{"notification_id", "created_at"}).AddRow(id, created))
+
+	n := models.UserNotification{
+		UserID: userID,
+		Event:  models.EventOrderStatus,
+		Title:  "Your ShopIT order status",
+		Body:   "Your order is now shipped.",
+	}
+	require.NoError(t, repository.NewNotificationsRepository(db).InsertNotification(&n))
+	assert.Equal(t, id, n.ID)
+	assert.Equal(t, created, n.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertBroadcast(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("select user_id, $1, $2, $3 from users where delete_after is null")).
+		WithArgs(models.EventPromo, "Summer sale", "20% off everything").
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	n, err := repository.NewNotificationsRepository(db).InsertBroadcast(models.EventPromo, "Summer sale", "20% off everything")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchNotifications(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	userID, first, second := uuid.New(), uuid.New(), uuid.New()
+	created, read := time.Now(), time.Now().Add(time.Minute)
+
+	mock.ExpectQuery(regexp.QuoteMeta("where user_id = $1 and (not $2 or read_at is null)")).
+		WithArgs(userID, false, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"notification_id", "event", "title", "body", "read_at", "created_at"}).
+			AddRow(first, models.EventPromo, "Summer sale", "", nil, created).
+			AddRow(second, models.EventPasswordChanged, "Your password was changed", "", read, created))
+
+	list, err := repository.NewNotificationsRepository(db).FetchNotifications(userID, false, 20)
+	require.NoError(t, err)
+	assert.Equal(t, []models.UserNotification{
+		{ID: first, UserID: userID, Event: models.EventPromo, Title: "Summer sale", CreatedAt: created},
+		{ID: second, UserID: userID, Event: models.EventPasswordChanged, Title: "Your password was changed",
+			ReadAt: &read, CreatedAt: created},
+	}, list)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkRead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewNotificationsRepository(db)
+	userID, id, now := uuid.New(), uuid.New(), time.Now()
+
+	t.Run("Notification of the user", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("set read_at = coalesce(read_at, $3)")).WithArgs(id, userID, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.MarkRead(userID, id, now))
+	})
+
+	t.Run("Notification of another user", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("set read_at = coalesce(read_at, $3)")).WithArgs(id, userID, now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.MarkRead(userID, id, now), sql.ErrNoRows)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkAllRead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	userID, now := uuid.New(), time.Now()
+	mock.ExpectExec(regexp.QuoteMeta("where user_id = $1 and read_at is null")).WithArgs(userID, now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := repository.NewNotificationsRepository(db).MarkAllRead(userID, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,132 @@
+// Package repository provides persistence for notification preferences and admin
+// digests.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// NotificationsRepository handles notification preference database operations.
+type NotificationsRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewNotificationsRepository returns a new NotificationsRepository.
+func NewNotificationsRepository(db *sql.DB) *NotificationsRepository {
+	return &NotificationsRepository{
+		DB: db,
+	}
+}
+
+// FetchPreferences fetches the preferences a user set, by event. Events the user
+// never set are missing.
+func (r *NotificationsRepository) FetchPreferences(userID uuid.UUID) (models.NotificationPreferences, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "select event, email, sms, push from notification_preferences where user_id = $1"
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefs := make(models.NotificationPreferences)
+	for rows.Next() {
+		var (
+			event string
+			p     models.NotificationPreference
+		)
+		if err := rows.Scan(&event, &p.Email, &p.SMS, &p.Push); err != nil {
+			return nil, err
+		}
+
+		prefs[event] = p
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
+// UpsertPreferences saves the preferences of a user for the events in prefs, in one
+// transaction. Other events are left alone.
+func (r *NotificationsRepository) UpsertPreferences(userID uuid.UUID, prefs models.NotificationPreferences) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `insert into notification_preferences (user_id, event, email, sms, push, updated_at)
+				values ($1, $2, $3, $4, $5, $6)
+				on conflict (user_id, event) do update
+				set email = excluded.email, sms = excluded.sms, push = excluded.push, updated_at = excluded.updated_at`
+
+	for event, p := range prefs {
+		if _, err := tx.ExecContext(ctx, query, userID, event, p.Email, p.SMS, p.Push, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FetchRecipient fetches the name, email and locale of a user. It returns sql.ErrNoRows when
+// there is no such user.
+func (r *NotificationsRepository) FetchRecipient(userID uuid.UUID) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	u := models.User{ID: userID}
+
+	err := r.DB.QueryRowContext(ctx, "select name, email, locale from users where user_id = $1", userID).
+		Scan(&u.Name, &u.Email, &u.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+// FetchAdmins fetches the id, name, email and locale of the admins, leaving out accounts
+// scheduled for deletion.
+func (r *NotificationsRepository) FetchAdmins() ([]*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select user_id, name, email, locale from users where role = $1 and delete_after is null`
+
+	rows, err := r.DB.QueryContext(ctx, query, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var admins []*models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Locale); err != nil {
+			return nil, err
+		}
+		admins = append(admins, &u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return admins, nil
+}
@@ -0,0 +1,70 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchPreferences(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewNotificationsRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("from notification_preferences where user_id = $1")).WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"event", "email", "sms", "push"}).
+			AddRow(models.EventOrderStatus, false, true, false))
+
+	prefs, err := repo.FetchPreferences(userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationPreferences{
+		models.EventOrderStatus: {SMS: true},
+	}, prefs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertPreferences(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewNotificationsRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("on conflict (user_id, event) do update")).
+		WithArgs(userID, models.EventOrderPlaced, true, false, true, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.UpsertPreferences(userID, models.NotificationPreferences{
+		models.EventOrderPlaced: {Email: true, Push: true},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchAdmins(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	id := uuid.New()
+	mock.ExpectQuery(regexp.QuoteMeta("select user_id, name, email, locale from users where role = $1 and delete_after is null")).
+		WithArgs(models.RoleAdmin).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "email", "locale"}).
+			AddRow(id, "Ama Mensah", "ama@example.com", "fr"))
+
+	admins, err := repository.NewNotificationsRepository(db).FetchAdmins()
+	require.NoError(t, err)
+	assert.Equal(t, []*models.User{{ID: id, Name: "Ama Mensah", Email: "ama@example.com", Locale: "fr"}}, admins)
+}
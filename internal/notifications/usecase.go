@@ -0,0 +1,47 @@
+package notifications
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type NotificationUC interface {
+	// GetPreferences returns the preferences of a user for every event, defaults filled in
+	GetPreferences(userID uuid.UUID) (models.NotificationPreferences, error)
+
+	// UpdatePreferences changes the preferences of a user for the given events, returns all preferences
+	UpdatePreferences(userID uuid.UUID, prefs models.NotificationPreferences) (models.NotificationPreferences, error)
+
+	// Notify sends a notification to a user on the channels they turned on for its event
+	Notify(userID uuid.UUID, n models.Notification) error
+
+	// GetDigestSubscription returns how often an admin receives the digest, off when they never chose
+	GetDigestSubscription(userID uuid.UUID) (*models.DigestSubscription, error)
+
+	// UpdateDigestSubscription sets how often an admin receives the digest, returns the subscription
+	UpdateDigestSubscription(userID uuid.UUID, frequency string) (*models.DigestSubscription, error)
+
+	// SendDigests emails the digest to the admins whose digest is due, returns how many were sent
+	SendDigests(ctx context.Context) (int, error)
+
+	// GetNotifications returns the latest notifications of a user, only the unread ones when unread is set,
+	// and how many are unread
+	GetNotifications(userID uuid.UUID, unread bool, limit int) ([]models.UserNotification, int, error)
+
+	// MarkRead marks a notification of a user read, returns ErrNotificationNotFound when there is no such
+	// notification
+	MarkRead(userID, id uuid.UUID) error
+
+	// MarkAllRead marks the unread notifications of a user read, returns how many were marked
+	MarkAllRead(userID uuid.UUID) (int64, error)
+
+	// Broadcast adds a promotion to the notification center of every user, returns how many were notified
+	Broadcast(title, body string) (int64, error)
+}
+
+// Sender delivers notifications on one channel.
+type Sender interface {
+	// Send delivers n to the user, returns an error on failure
+	Send(to *models.User, n models.Notification) error
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+)
+
+// DefaultLowStock is the stock at or under which the digest lists a product when no
+// threshold is configured.
+const DefaultLowStock = 5
+
+// digestLowStockItems is how many running out products a digest lists at most.
+const digestLowStockItems = 20
+
+// digestSlack lets a digest go out a little early, so that a job running at about the
+// same time every day does not put it off by a whole run.
+const digestSlack = 5 * time.Minute
+
+// GetDigestSubscription returns how often userID receives the admin digest, off when
+// they never chose.
+func (n *NotificationsUC) GetDigestSubscription(userID uuid.UUID) (*models.DigestSubscription, error) {
+	sub, err := n.repo.FetchDigestSubscription(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &models.DigestSubscription{UserID: userID, Frequency: models.DigestOff}, nil
+		}
+		return nil, fmt.Errorf("error fetching digest subscription: %v", err)
+	}
+
+	return sub, nil
+}
+
+// UpdateDigestSubscription sets how often userID receives the admin digest, one of
+// models.DigestFrequencies.
+func (n *NotificationsUC) UpdateDigestSubscription(userID uuid.UUID, frequency string) (*models.DigestSubscription, error) {
+	frequency = strings.ToLower(strings.TrimSpace(frequency))
+	if _, ok := models.DigestPeriod(frequency); !ok && frequency != models.DigestOff {
+		return nil, fmt.Errorf("%q: %w", frequency, notifications.ErrUnknownFrequency)
+	}
+
+	if err := n.repo.UpsertDigestSubscription(userID, frequency); err != nil {
+		return nil, fmt.Errorf("error saving digest subscription: %v", err)
+	}
+
+	return n.GetDigestSubscription(userID)
+}
+
+// SendDigests emails the digest of the shop activity to every admin whose digest is
+// due: once a period has passed since their last one, or right away for their first.
+// A digest covers the activity since the last one, at most a period back. The digest
+// is sent whatever the notification preferences of the admin, and a failure does not
+// stop the others; their errors are joined. Once ctx is done no other digest is sent,
+// the rest being due on the next run.
+func (n *NotificationsUC) SendDigests(ctx context.Context) (int, error) {
+	subs, err := n.repo.FetchDigestSubscribers(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching digest subscribers: %v", err)
+	}
+
+	email, ok := n.senders[models.ChannelEmail]
+	if !ok {
+		return 0, nil
+	}
+
+	now := time.Now()
+	var sent int
+	var errs []error
+	for _, sub := range subs {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		period, ok := models.DigestPeriod(sub.Frequency)
+		if !ok {
+			continue
+		}
+
+		from := now.Add(-period)
+		if sub.LastSentAt != nil {
+			if now.Sub(*sub.LastSentAt) < period-digestSlack {
+				continue
+			}
+			if sub.LastSentAt.After(from) {
+				from = *sub.LastSentAt
+			}
+		}
+
+		if err := n.sendDigest(ctx, email, sub.UserID, from, now); err != nil {
+			errs = append(errs, fmt.Errorf("error sending digest to %s: %v", sub.UserID, err))
+			continue
+		}
+		sent++
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// sendDigest emails userID the digest of [from, to) and records it as sent.
+func (n *NotificationsUC) sendDigest(ctx context.Context, email notifications.Sender, userID uuid.UUID, from, to time.Time) error {
+	digest, err := n.repo.FetchDigest(ctx, from, to, n.lowStock, digestLowStockItems)
+	if err != nil {
+		return err
+	}
+
+	recipient, err := n.repo.FetchRecipient(userID)
+	if err != nil {
+		return fmt.Errorf("error fetching recipient: %v", err)
+	}
+
+	err = email.Send(recipient, models.Notification{
+		Subject:  "Your ShopIT digest",
+		Template: "admin-digest",
+		Data:     digestData(digest, recipient.Locale),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := n.repo.MarkDigestSent(userID, to); err != nil {
+		return fmt.Errorf("error recording digest: %v", err)
+	}
+
+	return nil
+}
+
+// digestData is the data of the digest email, dates written in locale. LowStock
+// holds one "name (sku): stock" line per item.
+func digestData(d *models.Digest, locale string) map[string]string {
+	lines := make([]string, 0, len(d.LowStock))
+	for _, i := range d.LowStock {
+		name := i.Name
+		if i.SKU != "" {
+			name = fmt.Sprintf("%s (%s)", i.Name, i.SKU)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %d", name, i.Stock))
+	}
+
+	return map[string]string{
+		"From":           i18n.FormatDate(locale, d.From),
+		"To":             i18n.FormatDate(locale, d.To),
+		"NewOrders":      strconv.Itoa(d.NewOrders),
+		"Revenue":        d.Revenue.String(),
+		"FailedPayments": strconv.Itoa(d.FailedPayments),
+		"NewReviews":     strconv.Itoa(d.NewReviews),
+		"LowStock":       strings.Join(lines, "\n"),
+	}
+}
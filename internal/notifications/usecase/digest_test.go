@@ -0,0 +1,140 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/internal/notifications/mocks"
+	"github.com/jofosuware/go/shopit/internal/notifications/usecase"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateDigestSubscription(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	n := usecase.NewNotificationsUC(repo, models.NotificationPreferences{}, nil, 0)
+
+	userID := uuid.New()
+
+	t.Run("Frequency is saved", func(t *testing.T) {
+		repo.On("UpsertDigestSubscription", userID, models.DigestWeekly).Return(nil).Once()
+		repo.On("FetchDigestSubscription", userID).
+			Return(&models.DigestSubscription{UserID: userID, Frequency: models.DigestWeekly}, nil).Once()
+
+		sub, err := n.UpdateDigestSubscription(userID, " Weekly")
+		require.NoError(t, err)
+		assert.Equal(t, models.DigestWeekly, sub.Frequency)
+	})
+
+	t.Run("Off when never chosen", func(t *testing.T) {
+		repo.On("FetchDigestSubscription", userID).Return(nil, sql.ErrNoRows).Once()
+
+		sub, err := n.GetDigestSubscription(userID)
+		require.NoError(t, err)
+		assert.Equal(t, models.DigestOff, sub.Frequency)
+	})
+
+	t.Run("Unknown frequency", func(t *testing.T) {
+		_, err := n.UpdateDigestSubscription(userID, "hourly")
+		assert.ErrorIs(t, err, notifications.ErrUnknownFrequency)
+		assert.True(t, notifications.IsClientError(err))
+	})
+}
+
+func TestSendDigests(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	email := mocks.NewSender(t)
+	n := usecase.NewNotificationsUC(repo, models.NotificationPreferences{}, map[string]notifications.Sender{
+		models.ChannelEmail: email,
+	}, 0)
+
+	admin := &models.User{ID: uuid.New(), Email: "admin@example.com", Locale: "en"}
+	digest := &models.Digest{
+		NewOrders: 3,
+		Revenue:   money.New(12500, money.DefaultCurrency),
+		LowStock:  []models.LowStockItem{{Name: "Kente Scarf", SKU: "KS-RED-M", Stock: 2}, {Name: "Shea Butter Soap", Stock: 5}},
+	}
+
+	t.Run("First digest is sent right away", func(t *testing.T) {
+		repo.On("FetchDigestSubscribers", mock.Anything).
+			Return([]*models.DigestSubscription{{UserID: admin.ID, Frequency: models.DigestDaily}}, nil).Once()
+		repo.On("FetchDigest", mock.Anything, mock.Anything, mock.Anything, usecase.DefaultLowStock, mock.Anything).
+			Run(func(args mock.Arguments) {
+				from, to := args.Get(1).(time.Time), args.Get(2).(time.Time)
+				assert.Equal(t, 24*time.Hour, to.Sub(from))
+			}).Return(digest, nil).Once()
+		repo.On("FetchRecipient", admin.ID).Return(admin, nil).Once()
+		email.On("Send", admin, mock.MatchedBy(func(msg models.Notification) bool {
+			data := msg.Data.(map[string]string)
+			return msg.Template == "admin-digest" && data["NewOrders"] == "3" && data["Revenue"] == "125.00 USD" &&
+				data["LowStock"] == "Kente Scarf (KS-RED-M): 2\nShea Butter Soap: 5"
+		})).Return(nil).Once()
+		repo.On("MarkDigestSent", admin.ID, mock.Anything).Return(nil).Once()
+
+		sent, err := n.SendDigests(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+	})
+
+	t.Run("Digest is not sent before it is due", func(t *testing.T) {
+		last := time.Now().Add(-2 * time.Hour)
+		repo.On("FetchDigestSubscribers", mock.Anything).
+			Return([]*models.DigestSubscription{{UserID: admin.ID, Frequency: models.DigestDaily, LastSentAt: &last}}, nil).Once()
+
+		sent, err := n.SendDigests(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+	})
+
+	t.Run("Digest covers the activity since the last one", func(t *testing.T) {
+		last := time.Now().Add(-3 * 24 * time.Hour)
+		repo.On("FetchDigestSubscribers", mock.Anything).
+			Return([]*models.DigestSubscription{{UserID: admin.ID, Frequency: models.DigestWeekly, LastSentAt: &last}}, nil).Once()
+
+		sent, err := n.SendDigests(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+
+		last = time.Now().Add(-8 * 24 * time.Hour)
+		repo.On("FetchDigestSubscribers", mock.Anything).
+			Return([]*models.DigestSubscription{{UserID: admin.ID, Frequency: models.DigestWeekly, LastSentAt: &last}}, nil).Once()
+		repo.On("FetchDigest", mock.Anything, mock.Anything, mock.Anything, usecase.DefaultLowStock, mock.Anything).
+			Run(func(args mock.Arguments) {
+				from, to := args.Get(1).(time.Time), args.Get(2).(time.Time)
+				assert.Equal(t, 7*24*time.Hour, to.Sub(from))
+			}).Return(digest, nil).Once()
+		repo.On("FetchRecipient", admin.ID).Return(admin, nil).Once()
+		email.On("Send", admin, mock.Anything).Return(nil).Once()
+		repo.On("MarkDigestSent", admin.ID, mock.Anything).Return(nil).Once()
+
+		sent, err = n.SendDigests(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+	})
+
+	t.Run("A failing digest does not stop the others", func(t *testing.T) {
+		other := &models.User{ID: uuid.New(), Email: "other@example.com"}
+		repo.On("FetchDigestSubscribers", mock.Anything).Return([]*models.DigestSubscription{
+			{UserID: admin.ID, Frequency: models.DigestDaily},
+			{UserID: other.ID, Frequency: models.DigestDaily},
+		}, nil).Once()
+		repo.On("FetchDigest", mock.Anything, mock.Anything, mock.Anything, usecase.DefaultLowStock, mock.Anything).Return(digest, nil).Twice()
+		repo.On("FetchRecipient", admin.ID).Return(admin, nil).Once()
+		repo.On("FetchRecipient", other.ID).Return(other, nil).Once()
+		email.On("Send", admin, mock.Anything).Return(errors.New("smtp down")).Once()
+		email.On("Send", other, mock.Anything).Return(nil).Once()
+		repo.On("MarkDigestSent", other.ID, mock.Anything).Return(nil).Once()
+
+		sent, err := n.SendDigests(context.Background())
+		assert.ErrorContains(t, err, "smtp down")
+		assert.Equal(t, 1, sent)
+	})
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/events"
+)
+
+// HandleOrderEvent notifies the customer of an order placed or of its new status. It
+// handles events.OrderCreated and events.OrderStatusChanged.
+func (n *NotificationsUC) HandleOrderEvent(e events.Event) error {
+	order, ok := e.Data.(models.Order)
+	if !ok {
+		return fmt.Errorf("unexpected %s event data %T", e.Name, e.Data)
+	}
+
+	switch e.Name {
+	case events.OrderCreated:
+		return n.Notify(order.UserID, models.Notification{
+			Event:    models.EventOrderPlaced,
+			Subject:  "Your ShopIT order",
+			Template: "order-placed",
+			Data:     orderPlacedData(&order),
+			Text:     fmt.Sprintf("Your ShopIT order %s has been placed.", order.OrderID),
+		})
+	case events.OrderStatusChanged:
+		return n.Notify(order.UserID, models.Notification{
+			Event:    models.EventOrderStatus,
+			Subject:  "Your ShopIT order status",
+			Template: "order-status",
+			Data: map[string]string{
+				"OrderID": order.OrderID.String(),
+				"Status":  order.OrderStatus,
+			},
+			Text: fmt.Sprintf("Your ShopIT order %s is now %s.", order.OrderID, order.OrderStatus),
+		})
+	}
+
+	return nil
+}
+
+// HandlePriceDrop tells a user who wishlisted a product that its price was lowered.
+// It handles events.ProductPriceDropped.
+func (n *NotificationsUC) HandlePriceDrop(e events.Event) error {
+	drop, ok := e.Data.(models.PriceDrop)
+	if !ok {
+		return fmt.Errorf("unexpected %s event data %T", e.Name, e.Data)
+	}
+
+	return n.Notify(drop.UserID, models.Notification{
+		Event:    models.EventPriceDrop,
+		Subject:  "A product on your ShopIT wishlist is cheaper",
+		Template: "price-drop",
+		Data: map[string]string{
+			"ProductID": drop.ProductID.String(),
+			"Name":      drop.Name,
+			"OldPrice":  drop.OldPrice.String(),
+			"NewPrice":  drop.NewPrice.String(),
+		},
+		Text: fmt.Sprintf("%s on your ShopIT wishlist is now %s instead of %s.", drop.Name, drop.NewPrice, drop.OldPrice),
+	})
+}
+
+// HandleLowStock emails every admin that a product, or variant, is running out of
+// stock, whatever their notification preferences. A failure does not stop the
+// others; their errors are joined. It handles events.StockLow.
+func (n *NotificationsUC) HandleLowStock(e events.Event) error {
+	item, ok := e.Data.(models.LowStockItem)
+	if !ok {
+		return fmt.Errorf("unexpected %s event data %T", e.Name, e.Data)
+	}
+
+	name := item.Name
+	if item.SKU != "" {
+		name += " (" + item.SKU + ")"
+	}
+
+	return n.emailAdmins(models.Notification{
+		Subject:  "A ShopIT product is running out of stock",
+		Template: "low-stock",
+		Data: map[string]string{
+			"ProductID": item.ProductID.String(),
+			"Name":      name,
+			"Stock":     strconv.Itoa(item.Stock),
+			"Threshold": strconv.Itoa(item.Threshold),
+		},
+	})
+}
+
+// HandleTicketUpdate notifies the customer of the answer, or new status, an admin gave
+// their support ticket, and emails every admin the tickets customers open and their
+// replies, whatever the preferences of the admins. It handles events.TicketUpdated.
+func (n *NotificationsUC) HandleTicketUpdate(e events.Event) error {
+	update, ok := e.Data.(models.TicketUpdate)
+	if !ok {
+		return fmt.Errorf("unexpected %s event data %T", e.Name, e.Data)
+	}
+
+	data := map[string]string{
+		"TicketID": update.TicketID.String(),
+		"Subject":  update.Subject,
+		"Status":   update.Status,
+		"Message":  update.Message,
+	}
+
+	if !update.FromAdmin {
+		return n.emailAdmins(models.Notification{
+			Subject:  "A ShopIT customer wrote to support: " + update.Subject,
+			Template: "ticket-message",
+			Data:     data,
+		})
+	}
+
+	text := fmt.Sprintf("Your ShopIT support ticket %q is now %s.", update.Subject, update.Status)
+	if update.Message != "" {
+		text = fmt.Sprintf("The ShopIT support team answered your ticket %q.", update.Subject)
+	}
+
+	return n.Notify(update.UserID, models.Notification{
+		Event:    models.EventTicketUpdate,
+		Subject:  "Your ShopIT support ticket: " + update.Subject,
+		Template: "ticket-update",
+		Data:     data,
+		Text:     text,
+	})
+}
+
+// emailAdmins emails notification to every admin when there is an email sender. A
+// failure does not stop the others; their errors are joined.
+func (n *NotificationsUC) emailAdmins(notification models.Notification) error {
+	email, ok := n.senders[models.ChannelEmail]
+	if !ok {
+		return nil
+	}
+
+	admins, err := n.repo.FetchAdmins()
+	if err != nil {
+		return fmt.Errorf("error fetching admins: %v", err)
+	}
+
+	var errs []error
+	for _, admin := range admins {
+		if err := email.Send(admin, notification); err != nil {
+			errs = append(errs, fmt.Errorf("error emailing %s: %v", admin.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// orderPlacedData is the data of the order confirmation email. Gift orders say
+// so and repeat their message.
+func orderPlacedData(ord *models.Order) map[string]string {
+	data := map[string]string{
+		"OrderID": ord.OrderID.String(),
+		"Total":   ord.TotalPrice.String(),
+	}
+	if ord.Gift {
+		data["Gift"] = "true"
+		data["GiftMessage"] = ord.GiftMessage
+		if ord.HidePrices {
+			data["HidePrices"] = "true"
+		}
+	}
+
+	return data
+}
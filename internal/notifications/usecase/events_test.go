@@ -0,0 +1,186 @@
+package usecase_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/internal/notifications/mocks"
+	"github.com/jofosuware/go/shopit/internal/notifications/usecase"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleOrderEvent(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	email := mocks.NewSender(t)
+	defaults := models.NotificationPreferences{
+		models.EventOrderPlaced: {Email: true},
+		models.EventOrderStatus: {Email: true},
+	}
+	n := usecase.NewNotificationsUC(repo, defaults, map[string]notifications.Sender{models.ChannelEmail: email}, 0)
+
+	user := &models.User{ID: uuid.New(), Email: "ama@example.com"}
+
+	t.Run("Gift orders are emailed with their message", func(t *testing.T) {
+		order := models.Order{OrderID: uuid.New(), UserID: user.ID, Gift: true, GiftMessage: "Happy birthday!"}
+
+		repo.On("InsertNotification", mock.Anything).Return(nil).Once()
+		repo.On("FetchPreferences", user.ID).Return(models.NotificationPreferences{}, nil).Once()
+		repo.On("FetchRecipient", user.ID).Return(user, nil).Once()
+		email.On("Send", user, mock.MatchedBy(func(msg models.Notification) bool {
+			data := msg.Data.(map[string]string)
+			return msg.Event == models.EventOrderPlaced && data["Gift"] == "true" &&
+				data["GiftMessage"] == "Happy birthday!" && data["HidePrices"] == ""
+		})).Return(nil).Once()
+
+		require.NoError(t, n.HandleOrderEvent(events.Event{Name: events.OrderCreated, Data: order}))
+	})
+
+	t.Run("Status changes are emailed and kept in the notification center", func(t *testing.T) {
+		order := models.Order{OrderID: uuid.New(), UserID: user.ID, OrderStatus: models.OrderDelivered}
+
+		repo.On("InsertNotification", &models.UserNotification{
+			UserID: user.ID,
+			Event:  models.EventOrderStatus,
+			Title:  "Your ShopIT order status",
+			Body:   fmt.Sprintf("Your ShopIT order %s is now %s.", order.OrderID, models.OrderDelivered),
+		}).Return(nil).Once()
+		repo.On("FetchPreferences", user.ID).Return(models.NotificationPreferences{}, nil).Once()
+		repo.On("FetchRecipient", user.ID).Return(user, nil).Once()
+		email.On("Send", user, mock.MatchedBy(func(msg models.Notification) bool {
+			return msg.Event == models.EventOrderStatus && msg.Data.(map[string]string)["Status"] == models.OrderDelivered
+		})).Return(nil).Once()
+
+		require.NoError(t, n.HandleOrderEvent(events.Event{Name: events.OrderStatusChanged, Data: order}))
+	})
+
+	t.Run("Other events are ignored", func(t *testing.T) {
+		require.NoError(t, n.HandleOrderEvent(events.Event{Name: events.OrderDelivered, Data: models.Order{}}))
+	})
+
+	t.Run("Unexpected data", func(t *testing.T) {
+		assert.Error(t, n.HandleOrderEvent(events.Event{Name: events.OrderCreated, Data: user}))
+	})
+}
+
+func TestHandlePriceDrop(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	email := mocks.NewSender(t)
+	n := usecase.NewNotificationsUC(repo, models.NotificationPreferences{models.EventPriceDrop: {Email: true}},
+		map[string]notifications.Sender{models.ChannelEmail: email}, 0)
+
+	user := &models.User{ID: uuid.New(), Email: "ama@example.com"}
+	drop := models.PriceDrop{UserID: user.ID, ProductID: uuid.New(), Name: "Kente Scarf", OldPrice: money.Of(4500),
+		NewPrice: money.Of(3600)}
+
+	t.Run("Price drops are emailed", func(t *testing.T) {
+		repo.On("InsertNotification", mock.Anything).Return(nil).Once()
+		repo.On("FetchPreferences", user.ID).Return(models.NotificationPreferences{}, nil).Once()
+		repo.On("FetchRecipient", user.ID).Return(user, nil).Once()
+		email.On("Send", user, mock.MatchedBy(func(msg models.Notification) bool {
+			data := msg.Data.(map[string]string)
+			return msg.Event == models.EventPriceDrop && msg.Template == "price-drop" && data["Name"] == "Kente Scarf" &&
+				data["OldPrice"] == drop.OldPrice.String() && data["NewPrice"] == drop.NewPrice.String()
+		})).Return(nil).Once()
+
+		require.NoError(t, n.HandlePriceDrop(events.Event{Name: events.ProductPriceDropped, Data: drop}))
+	})
+
+	t.Run("Users who turned them off are skipped", func(t *testing.T) {
+		repo.On("InsertNotification", mock.Anything).Return(nil).Once()
+		repo.On("FetchPreferences", user.ID).
+			Return(models.NotificationPreferences{models.EventPriceDrop: {}}, nil).Once()
+
+		require.NoError(t, n.HandlePriceDrop(events.Event{Name: events.ProductPriceDropped, Data: drop}))
+	})
+
+	t.Run("Unexpected data", func(t *testing.T) {
+		assert.Error(t, n.HandlePriceDrop(events.Event{Name: events.ProductPriceDropped, Data: user}))
+	})
+}
+
+func TestHandleLowStock(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	email := mocks.NewSender(t)
+	n := usecase.NewNotificationsUC(repo, models.NotificationPreferences{},
+		map[string]notifications.Sender{models.ChannelEmail: email}, 0)
+
+	first := &models.User{ID: uuid.New(), Email: "ama@example.com"}
+	second := &models.User{ID: uuid.New(), Email: "kofi@example.com"}
+	item := models.LowStockItem{ProductID: uuid.New(), Name: "Kente Scarf", SKU: "KS-RED-M", Stock: 4, Threshold: 5}
+
+	t.Run("Every admin is emailed", func(t *testing.T) {
+		repo.On("FetchAdmins").Return([]*models.User{first, second}, nil).Once()
+		sent := mock.MatchedBy(func(msg models.Notification) bool {
+			data := msg.Data.(map[string]string)
+			return msg.Template == "low-stock" && data["Name"] == "Kente Scarf (KS-RED-M)" && data["Stock"] == "4" &&
+				data["Threshold"] == "5"
+		})
+		email.On("Send", first, sent).Return(nil).Once()
+		email.On("Send", second, sent).Return(nil).Once()
+
+		require.NoError(t, n.HandleLowStock(events.Event{Name: events.StockLow, Data: item}))
+	})
+
+	t.Run("A failure does not stop the others", func(t *testing.T) {
+		repo.On("FetchAdmins").Return([]*models.User{first, second}, nil).Once()
+		email.On("Send", first, mock.Anything).Return(errors.New("smtp down")).Once()
+		email.On("Send", second, mock.Anything).Return(nil).Once()
+
+		assert.Error(t, n.HandleLowStock(events.Event{Name: events.StockLow, Data: item}))
+	})
+
+	t.Run("Unexpected data", func(t *testing.T) {
+		assert.Error(t, n.HandleLowStock(events.Event{Name: events.StockLow, Data: first}))
+	})
+}
+
+func TestHandleTicketUpdate(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	email := mocks.NewSender(t)
+	n := usecase.NewNotificationsUC(repo, models.NotificationPreferences{models.EventTicketUpdate: {Email: true}},
+		map[string]notifications.Sender{models.ChannelEmail: email}, 0)
+
+	user := &models.User{ID: uuid.New(), Email: "ama@example.com"}
+	admin := &models.User{ID: uuid.New(), Email: "kofi@example.com", Role: models.RoleAdmin}
+	update := models.TicketUpdate{TicketID: uuid.New(), UserID: user.ID, Subject: "Missing scarf",
+		Status: models.TicketOpen, Message: "My parcel had no scarf."}
+
+	t.Run("Messages of customers are emailed to the admins", func(t *testing.T) {
+		repo.On("FetchAdmins").Return([]*models.User{admin}, nil).Once()
+		email.On("Send", admin, mock.MatchedBy(func(msg models.Notification) bool {
+			return msg.Template == "ticket-message" && msg.Data.(map[string]string)["Message"] == update.Message
+		})).Return(nil).Once()
+
+		require.NoError(t, n.HandleTicketUpdate(events.Event{Name: events.TicketUpdated, Data: update}))
+	})
+
+	t.Run("Answers of admins are sent to the customer", func(t *testing.T) {
+		answer := update
+		answer.Status = models.TicketAnswered
+		answer.Message = "Sorry, we are sending it today."
+		answer.FromAdmin = true
+
+		repo.On("InsertNotification", mock.Anything).Return(nil).Once()
+		repo.On("FetchPreferences", user.ID).Return(models.NotificationPreferences{}, nil).Once()
+		repo.On("FetchRecipient", user.ID).Return(user, nil).Once()
+		email.On("Send", user, mock.MatchedBy(func(msg models.Notification) bool {
+			data := msg.Data.(map[string]string)
+			return msg.Event == models.EventTicketUpdate && msg.Template == "ticket-update" &&
+				data["Message"] == answer.Message && data["Status"] == models.TicketAnswered
+		})).Return(nil).Once()
+
+		require.NoError(t, n.HandleTicketUpdate(events.Event{Name: events.TicketUpdated, Data: answer}))
+	})
+
+	t.Run("Unexpected data", func(t *testing.T) {
+		assert.Error(t, n.HandleTicketUpdate(events.Event{Name: events.TicketUpdated, Data: user}))
+	})
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/pkg/events"
+)
+
+// Notification center limits
+const (
+	// DefaultInboxLimit is how many notifications are listed without a limit
+	DefaultInboxLimit = 20
+	// MaxInboxLimit caps the notifications listed at once
+	MaxInboxLimit = 100
+)
+
+// record adds notification to the notification center of userID, under its subject
+// and with its text.
+func (n *NotificationsUC) record(userID uuid.UUID, notification models.Notification) error {
+	err := n.repo.InsertNotification(&models.UserNotification{
+		UserID: userID,
+		Event:  notification.Event,
+		Title:  notification.Subject,
+		Body:   notification.Text,
+	})
+	if err != nil {
+		return fmt.Errorf("error recording notification: %v", err)
+	}
+
+	return nil
+}
+
+// GetNotifications returns up to limit notifications of userID, last first, only
+// the unread ones when unread is set, and how many are unread. A non-positive limit
+// stands for DefaultInboxLimit, and limit is capped at MaxInboxLimit.
+func (n *NotificationsUC) GetNotifications(userID uuid.UUID, unread bool, limit int) ([]models.UserNotification, int, error) {
+	if limit <= 0 {
+		limit = DefaultInboxLimit
+	}
+	if limit > MaxInboxLimit {
+		limit = MaxInboxLimit
+	}
+
+	list, err := n.repo.FetchNotifications(userID, unread, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error fetching notifications: %v", err)
+	}
+
+	count, err := n.repo.CountUnread(userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting unread notifications: %v", err)
+	}
+
+	return list, count, nil
+}
+
+// MarkRead marks the notification id of userID read. It returns
+// notifications.ErrNotificationNotFound when the user has no such notification.
+func (n *NotificationsUC) MarkRead(userID, id uuid.UUID) error {
+	if err := n.repo.MarkRead(userID, id, time.Now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notifications.ErrNotificationNotFound
+		}
+		return fmt.Errorf("error marking notification read: %v", err)
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every unread notification of userID read, returns how many
+// were marked.
+func (n *NotificationsUC) MarkAllRead(userID uuid.UUID) (int64, error) {
+	count, err := n.repo.MarkAllRead(userID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("error marking notifications read: %v", err)
+	}
+
+	return count, nil
+}
+
+// Broadcast adds a promotion to the notification center of every user, returns how
+// many users were notified. It is not sent on any channel.
+func (n *NotificationsUC) Broadcast(title, body string) (int64, error) {
+	count, err := n.repo.InsertBroadcast(models.EventPromo, title, body)
+	if err != nil {
+		return 0, fmt.Errorf("error broadcasting notification: %v", err)
+	}
+
+	return count, nil
+}
+
+// HandlePasswordChanged tells a user, in their notification center, that their
+// password was changed, so that they notice a change they did not make. It handles
+// events.PasswordChanged.
+func (n *NotificationsUC) HandlePasswordChanged(e events.Event) error {
+	change, ok := e.Data.(models.PasswordChange)
+	if !ok {
+		return fmt.Errorf("unexpected %s event data %T", e.Name, e.Data)
+	}
+
+	return n.record(change.UserID, models.Notification{
+		Event:   models.EventPasswordChanged,
+		Subject: "Your ShopIT password was changed",
+		Text: fmt.Sprintf("Your ShopIT password was changed on %s. If you did not change it, reset it now.",
+			change.ChangedAt.UTC().Format("2 January 2006 at 15:04 UTC")),
+	})
+}
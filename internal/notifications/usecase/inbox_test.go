@@ -0,0 +1,125 @@
+package usecase_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/internal/notifications/mocks"
+	"github.com/jofosuware/go/shopit/internal/notifications/usecase"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNotifications(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	n := usecase.NewNotificationsUC(repo, models.NotificationPreferences{}, nil, 0)
+
+	userID := uuid.New()
+	list := []models.UserNotification{{ID: uuid.New(), UserID: userID, Event: models.EventPromo, Title: "Summer sale"}}
+
+	t.Run("Latest notifications and the unread count", func(t *testing.T) {
+		repo.On("FetchNotifications", userID, false, usecase.DefaultInboxLimit).Return(list, nil).Once()
+		repo.On("CountUnread", userID).Return(4, nil).Once()
+
+		got, unread, err := n.GetNotifications(userID, false, 0)
+		require.NoError(t, err)
+		assert.Equal(t, list, got)
+		assert.Equal(t, 4, unread)
+	})
+
+	t.Run("Limit is capped", func(t *testing.T) {
+		repo.On("FetchNotifications", userID, true, usecase.MaxInboxLimit).Return(list, nil).Once()
+		repo.On("CountUnread", userID).Return(1, nil).Once()
+
+		_, _, err := n.GetNotifications(userID, true, 1000)
+		require.NoError(t, err)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		repo.On("FetchNotifications", userID, false, 5).Return(nil, errors.New("db down")).Once()
+
+		_, _, err := n.GetNotifications(userID, false, 5)
+		assert.ErrorContains(t, err, "db down")
+	})
+}
+
+func TestMarkRead(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	n := usecase.NewNotificationsUC(repo, models.NotificationPreferences{}, nil, 0)
+
+	userID, id := uuid.New(), uuid.New()
+
+	t.Run("Notification of the user", func(t *testing.T) {
+		repo.On("MarkRead", userID, id, mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, n.MarkRead(userID, id))
+	})
+
+	t.Run("No such notification", func(t *testing.T) {
+		repo.On("MarkRead", userID, id, mock.Anything).Return(sql.ErrNoRows).Once()
+
+		err := n.MarkRead(userID, id)
+		assert.ErrorIs(t, err, notifications.ErrNotificationNotFound)
+		assert.True(t, notifications.IsClientError(err))
+	})
+}
+
+func TestMarkAllRead(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	n := usecase.NewNotificationsUC(repo, models.NotificationPreferences{}, nil, 0)
+
+	userID := uuid.New()
+	repo.On("MarkAllRead", userID, mock.Anything).Return(int64(3), nil).Once()
+
+	count, err := n.MarkAllRead(userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestBroadcast(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	n := usecase.NewNotificationsUC(repo, models.NotificationPreferences{}, nil, 0)
+
+	repo.On("InsertBroadcast", models.EventPromo, "Summer sale", "20% off everything").Return(int64(42), nil).Once()
+
+	count, err := n.Broadcast("Summer sale", "20% off everything")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), count)
+}
+
+func TestHandlePasswordChanged(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	email := mocks.NewSender(t)
+	n := usecase.NewNotificationsUC(repo, models.NotificationPreferences{}, map[string]notifications.Sender{
+		models.ChannelEmail: email,
+	}, 0)
+
+	userID := uuid.New()
+	changedAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+	t.Run("Kept in the notification center only", func(t *testing.T) {
+		repo.On("InsertNotification", &models.UserNotification{
+			UserID: userID,
+			Event:  models.EventPasswordChanged,
+			Title:  "Your ShopIT password was changed",
+			Body:   "Your ShopIT password was changed on 17 October 2026 at 09:30 UTC. If you did not change it, reset it now.",
+		}).Return(nil).Once()
+
+		err := n.HandlePasswordChanged(events.Event{
+			Name: events.PasswordChanged,
+			Data: models.PasswordChange{UserID: userID, ChangedAt: changedAt},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Unexpected data", func(t *testing.T) {
+		assert.Error(t, n.HandlePasswordChanged(events.Event{Name: events.PasswordChanged, Data: userID}))
+	})
+}
@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	"github.com/jofosuware/go/shopit/pkg/mailer"
+)
+
+// EmailSender sends notifications as emails rendered from their template.
+type EmailSender struct {
+	mail mailer.Mailer
+}
+
+// NewEmailSender returns a new EmailSender.
+func NewEmailSender(mail mailer.Mailer) *EmailSender {
+	return &EmailSender{
+		mail: mail,
+	}
+}
+
+// Send emails n to the user, in their locale.
+func (e *EmailSender) Send(to *models.User, n models.Notification) error {
+	return e.mail.SendMail(mailer.DefaultFrom, to.Email, i18n.T(to.Locale, n.Subject), n.Template, to.Locale, n.Data)
+}
@@ -0,0 +1,159 @@
+// Package usecase implements notification preferences, the notification center and
+// the admin digest.
+//
+// A user turns the email, SMS and push channels on or off per event. Events the
+// user never set follow the store defaults. Every notification goes through Notify,
+// which only sends on the channels the user turned on and that have a sender;
+// channels without one, such as SMS until a provider is configured, are skipped.
+// Whatever the channels, every notification is kept in the notification center of
+// the user, where it stays unread until the user marks it read.
+//
+// Admins can also receive a daily or weekly digest email of the shop activity.
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+)
+
+// NotificationsUC provides notification use cases.
+type NotificationsUC struct {
+	repo     notifications.Repo
+	defaults models.NotificationPreferences
+	senders  map[string]notifications.Sender
+	lowStock int
+}
+
+// NewNotificationsUC returns a new NotificationsUC. defaults holds the preferences
+// of users for the events they never set, and senders the sender of each channel.
+// The admin digest lists the products with at most lowStock units left; a
+// non-positive lowStock falls back to DefaultLowStock.
+func NewNotificationsUC(repo notifications.Repo, defaults models.NotificationPreferences,
+	senders map[string]notifications.Sender, lowStock int) *NotificationsUC {
+	if lowStock <= 0 {
+		lowStock = DefaultLowStock
+	}
+
+	return &NotificationsUC{
+		repo:     repo,
+		defaults: defaults,
+		senders:  senders,
+		lowStock: lowStock,
+	}
+}
+
+// Defaults turns the configured channels of each event into preferences. Events
+// missing from channels have every channel off.
+func Defaults(channels map[string][]string) (models.NotificationPreferences, error) {
+	prefs := make(models.NotificationPreferences, len(models.NotificationEvents))
+	for _, e := range models.NotificationEvents {
+		prefs[e] = models.NotificationPreference{}
+	}
+
+	for event, chans := range channels {
+		if !models.ValidNotificationEvent(event) {
+			return nil, fmt.Errorf("notification defaults: %q: %w", event, notifications.ErrUnknownEvent)
+		}
+
+		var p models.NotificationPreference
+		for _, c := range chans {
+			switch c {
+			case models.ChannelEmail:
+				p.Email = true
+			case models.ChannelSMS:
+				p.SMS = true
+			case models.ChannelPush:
+				p.Push = true
+			default:
+				return nil, fmt.Errorf("notification defaults: %s: %q: %w", event, c, notifications.ErrUnknownChannel)
+			}
+		}
+		prefs[event] = p
+	}
+
+	return prefs, nil
+}
+
+// GetPreferences returns the preferences of userID for every event, the defaults
+// standing in for events the user never set.
+func (n *NotificationsUC) GetPreferences(userID uuid.UUID) (models.NotificationPreferences, error) {
+	saved, err := n.repo.FetchPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching notification preferences: %v", err)
+	}
+
+	prefs := make(models.NotificationPreferences, len(models.NotificationEvents))
+	for _, e := range models.NotificationEvents {
+		if p, ok := saved[e]; ok {
+			prefs[e] = p
+			continue
+		}
+		prefs[e] = n.defaults[e]
+	}
+
+	return prefs, nil
+}
+
+// UpdatePreferences saves the preferences of userID for the events in prefs and
+// returns the preferences for every event.
+func (n *NotificationsUC) UpdatePreferences(userID uuid.UUID, prefs models.NotificationPreferences) (models.NotificationPreferences, error) {
+	for event := range prefs {
+		if !models.ValidNotificationEvent(event) {
+			return nil, fmt.Errorf("%q: %w", event, notifications.ErrUnknownEvent)
+		}
+	}
+
+	if len(prefs) > 0 {
+		if err := n.repo.UpsertPreferences(userID, prefs); err != nil {
+			return nil, fmt.Errorf("error saving notification preferences: %v", err)
+		}
+	}
+
+	return n.GetPreferences(userID)
+}
+
+// Notify adds the notification to the notification center of userID and sends it on
+// every channel the user turned on for its event. A failing channel does not stop the
+// others; their errors are joined.
+func (n *NotificationsUC) Notify(userID uuid.UUID, notification models.Notification) error {
+	if !models.ValidNotificationEvent(notification.Event) {
+		return fmt.Errorf("%q: %w", notification.Event, notifications.ErrUnknownEvent)
+	}
+
+	if err := n.record(userID, notification); err != nil {
+		return err
+	}
+
+	prefs, err := n.GetPreferences(userID)
+	if err != nil {
+		return err
+	}
+	pref := prefs[notification.Event]
+
+	var (
+		to   *models.User
+		errs []error
+	)
+	for _, c := range models.NotificationChannels {
+		sender, ok := n.senders[c]
+		if !ok || !pref.Enabled(c) {
+			continue
+		}
+
+		if to == nil {
+			if to, err = n.repo.FetchRecipient(userID); err != nil {
+				return fmt.Errorf("error fetching recipient: %v", err)
+			}
+		}
+
+		if err := sender.Send(to, notification); err != nil {
+			errs = append(errs, fmt.Errorf("error sending %s notification: %v", c, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,141 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	"github.com/jofosuware/go/shopit/internal/notifications/mocks"
+	"github.com/jofosuware/go/shopit/internal/notifications/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaults(t *testing.T) {
+	prefs, err := usecase.Defaults(map[string][]string{models.EventOrderPlaced: {"email", "push"}})
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationPreference{Email: true, Push: true}, prefs[models.EventOrderPlaced])
+	assert.Equal(t, models.NotificationPreference{}, prefs[models.EventOrderStatus])
+
+	_, err = usecase.Defaults(map[string][]string{"newsletter": {"email"}})
+	assert.ErrorIs(t, err, notifications.ErrUnknownEvent)
+
+	_, err = usecase.Defaults(map[string][]string{models.EventOrderPlaced: {"fax"}})
+	assert.ErrorIs(t, err, notifications.ErrUnknownChannel)
+}
+
+func TestGetPreferences(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	defaults := models.NotificationPreferences{
+		models.EventOrderPlaced: {Email: true},
+		models.EventOrderStatus: {Email: true},
+	}
+	n := usecase.NewNotificationsUC(repo, defaults, nil, 0)
+
+	userID := uuid.New()
+	repo.On("FetchPreferences", userID).
+		Return(models.NotificationPreferences{models.EventOrderStatus: {SMS: true}}, nil).Once()
+
+	prefs, err := n.GetPreferences(userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationPreferences{
+		models.EventOrderPlaced:  {Email: true},
+		models.EventOrderStatus:  {SMS: true},
+		models.EventPriceDrop:    {},
+		models.EventTicketUpdate: {},
+	}, prefs)
+}
+
+func TestUpdatePreferences(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	n := usecase.NewNotificationsUC(repo, models.NotificationPreferences{}, nil, 0)
+
+	userID := uuid.New()
+
+	t.Run("Preferences are saved", func(t *testing.T) {
+		update := models.NotificationPreferences{models.EventOrderPlaced: {Push: true}}
+		repo.On("UpsertPreferences", userID, update).Return(nil).Once()
+		repo.On("FetchPreferences", userID).Return(update, nil).Once()
+
+		prefs, err := n.UpdatePreferences(userID, update)
+		require.NoError(t, err)
+		assert.True(t, prefs[models.EventOrderPlaced].Push)
+		assert.Contains(t, prefs, models.EventOrderStatus)
+	})
+
+	t.Run("Unknown event", func(t *testing.T) {
+		_, err := n.UpdatePreferences(userID, models.NotificationPreferences{"newsletter": {Email: true}})
+		assert.ErrorIs(t, err, notifications.ErrUnknownEvent)
+		assert.True(t, notifications.IsClientError(err))
+	})
+}
+
+func TestNotify(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	email, sms := mocks.NewSender(t), mocks.NewSender(t)
+	defaults := models.NotificationPreferences{
+		models.EventOrderPlaced: {Email: true},
+		models.EventOrderStatus: {Email: true},
+	}
+	n := usecase.NewNotificationsUC(repo, defaults, map[string]notifications.Sender{
+		models.ChannelEmail: email,
+		models.ChannelSMS:   sms,
+	}, 0)
+
+	user := &models.User{ID: uuid.New(), Email: "ama@example.com"}
+	msg := models.Notification{Event: models.EventOrderStatus, Subject: "Your ShopIT order status",
+		Template: "order-status", Text: "Your ShopIT order is now shipped."}
+	recorded := &models.UserNotification{UserID: user.ID, Event: models.EventOrderStatus,
+		Title: "Your ShopIT order status", Body: "Your ShopIT order is now shipped."}
+
+	t.Run("Sent on the enabled channels only", func(t *testing.T) {
+		repo.On("InsertNotification", recorded).Return(nil).Once()
+		repo.On("FetchPreferences", user.ID).
+			Return(models.NotificationPreferences{models.EventOrderStatus: {SMS: true, Push: true}}, nil).Once()
+		repo.On("FetchRecipient", user.ID).Return(user, nil).Once()
+		sms.On("Send", user, msg).Return(nil).Once()
+
+		require.NoError(t, n.Notify(user.ID, msg))
+	})
+
+	t.Run("Defaults apply", func(t *testing.T) {
+		repo.On("InsertNotification", recorded).Return(nil).Once()
+		repo.On("FetchPreferences", user.ID).Return(models.NotificationPreferences{}, nil).Once()
+		repo.On("FetchRecipient", user.ID).Return(user, nil).Once()
+		email.On("Send", user, msg).Return(nil).Once()
+
+		require.NoError(t, n.Notify(user.ID, msg))
+	})
+
+	t.Run("Kept in the notification center with everything turned off", func(t *testing.T) {
+		repo.On("InsertNotification", recorded).Return(nil).Once()
+		repo.On("FetchPreferences", user.ID).
+			Return(models.NotificationPreferences{models.EventOrderStatus: {}}, nil).Once()
+
+		require.NoError(t, n.Notify(user.ID, msg))
+	})
+
+	t.Run("A failing channel does not stop the others", func(t *testing.T) {
+		repo.On("InsertNotification", recorded).Return(nil).Once()
+		repo.On("FetchPreferences", user.ID).
+			Return(models.NotificationPreferences{models.EventOrderStatus: {Email: true, SMS: true}}, nil).Once()
+		repo.On("FetchRecipient", user.ID).Return(user, nil).Once()
+		email.On("Send", user, msg).Return(errors.New("smtp error")).Once()
+		sms.On("Send", user, msg).Return(nil).Once()
+
+		assert.ErrorContains(t, n.Notify(user.ID, msg), "smtp error")
+	})
+
+	t.Run("Not sent when it cannot be kept", func(t *testing.T) {
+		repo.On("InsertNotification", recorded).Return(errors.New("db down")).Once()
+
+		assert.ErrorContains(t, n.Notify(user.ID, msg), "db down")
+	})
+
+	t.Run("Unknown event", func(t *testing.T) {
+		err := n.Notify(user.ID, models.Notification{Event: "newsletter"})
+		assert.ErrorIs(t, err, notifications.ErrUnknownEvent)
+	})
+}
@@ -0,0 +1,131 @@
+package delivery
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/pdf"
+)
+
+// Formats the warehouse documents can be downloaded in.
+const (
+	formatJSON = "json"
+	formatPDF  = "pdf"
+)
+
+// pickListPDF lays out a pick list for printing.
+func pickListPDF(list *models.PickList) *pdf.Document {
+	d := pdf.New("Pick list")
+	d.Heading("Pick list")
+	d.Line(fmt.Sprintf("Created %s, %d orders, %d units", list.CreatedAt.Format("2006-01-02 15:04"), len(list.Orders), list.TotalUnits))
+	d.Blank()
+	d.Line(fmt.Sprintf("%-5s %-16s %-40s %s", "Qty", "SKU", "Product", "Orders"))
+	d.Line(strings.Repeat("-", 70))
+	for _, l := range list.Lines {
+		d.Line(fmt.Sprintf("%-5d %-16s %-40s %d", l.Quantity, l.SKU, truncate(l.Name, 40), l.Orders))
+	}
+	d.Blank()
+	d.Line("Orders:")
+	for _, id := range list.Orders {
+		d.Line("  " + id.String())
+	}
+
+	return d
+}
+
+// packingSlipPDF lays out a packing slip for printing.
+func packingSlipPDF(slip *models.PackingSlip) *pdf.Document {
+	d := pdf.New("Packing slip " + slip.OrderID.String())
+	d.Heading("Packing slip")
+	d.Line("Order:   " + slip.OrderID.String())
+	d.Line("Ordered: " + slip.OrderedAt.Format("2006-01-02"))
+	d.Blank()
+	d.Heading("Ship to")
+	d.Line(slip.ShipTo.Address)
+	d.Line(strings.TrimSpace(slip.ShipTo.PostalCode + " " + slip.ShipTo.City))
+	d.Line(slip.ShipTo.Country)
+	d.Line("Phone: " + slip.ShipTo.PhoneNo)
+	d.Blank()
+	d.Line(fmt.Sprintf("%-5s %-16s %s", "Qty", "SKU", "Product"))
+	d.Line(strings.Repeat("-", 70))
+	for _, item := range slip.Items {
+		d.Line(fmt.Sprintf("%-5d %-16s %s", item.Quantity, item.SKU, truncate(item.Name, 48)))
+	}
+	d.Line(strings.Repeat("-", 70))
+	d.Line(fmt.Sprintf("%-5d units", slip.TotalUnits))
+	if t := slip.Totals; t != nil {
+		d.Blank()
+		d.Line(fmt.Sprintf("%-10s %14s", "Items", t.ItemsPrice))
+		d.Line(fmt.Sprintf("%-10s %14s", "Shipping", t.ShippingPrice))
+		d.Line(fmt.Sprintf("%-10s %14s", "Tax", t.TaxPrice))
+		if t.Discount.IsPositive() {
+			d.Line(fmt.Sprintf("%-10s %14s", "Discount", t.Discount.Neg()))
+		}
+		d.Line(fmt.Sprintf("%-10s %14s", "Total", t.TotalPrice))
+	}
+	if slip.Gift {
+		d.Blank()
+		d.Heading("A gift for you")
+		for _, l := range wrap(slip.GiftMessage, 80) {
+			d.Line(l)
+		}
+	}
+
+	return d
+}
+
+// writePDF sends d, a PDF document or its bytes, as a PDF download.
+func writePDF(w http.ResponseWriter, filename string, d io.WriterTo) error {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	_, err := d.WriteTo(w)
+	return err
+}
+
+// wrap breaks s into lines of at most n characters, at spaces where it can,
+// keeping the line breaks s has.
+func wrap(s string, n int) []string {
+	if s == "" {
+		return nil
+	}
+
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for len([]rune(word)) > n {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				r := []rune(word)
+				lines = append(lines, string(r[:n]))
+				word = string(r[n:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case len([]rune(line))+1+len([]rune(word)) <= n:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
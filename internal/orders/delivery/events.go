@@ -0,0 +1,89 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/orders"
+	"github.com/jofosuware/go/shopit/pkg/realtime"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// GetOrderEvents streams the status changes of an order as server-sent events, for
+// the owner of the order or an admin. The current status is sent first, then each
+// change as it happens; the stream ends once the order is Delivered or Cancelled.
+// Endpoint: GET /api/v1/orders/{id}/events
+// Events: "status" with data {"orderId": <id>, "status": <string>, "changedAt": <time>}.
+func (h *OrderHandlers) GetOrderEvents(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("user is not logged in"))
+		h.logger.Error("error getting user from context")
+		return
+	}
+
+	parsedId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	order, sub, err := h.ordersUC.SubscribeOrder(parsedId, user)
+	if err != nil {
+		if errors.Is(err, orders.ErrOrderNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error subscribing to order: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error subscribing to order: %w", err))
+		return
+	}
+	defer sub.Close()
+
+	stream, err := realtime.NewStream(w)
+	if err != nil {
+		h.logger.Errorf("error opening event stream: %v", err)
+		return
+	}
+
+	status := order.OrderStatus
+	err = stream.Send(realtime.Event{
+		Name: models.EventOrderStatusChanged,
+		Data: models.OrderStatusChange{OrderID: order.OrderID, Status: status, ChangedAt: time.Now()},
+	})
+
+	keepAlive := time.NewTicker(realtime.KeepAlive)
+	defer keepAlive.Stop()
+
+	for err == nil && !finalStatus(status) {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			err = stream.Ping()
+		case e, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if change, isStatus := e.Data.(models.OrderStatusChange); isStatus {
+				status = change.Status
+			}
+			err = stream.Send(e)
+		}
+	}
+
+	if err != nil {
+		h.logger.Errorf("error streaming events of order %s: %v", order.OrderID, err)
+	}
+}
+
+// finalStatus reports whether an order in status can no longer change.
+func finalStatus(status string) bool {
+	return status == models.OrderDelivered || status == models.OrderCancelled
+}
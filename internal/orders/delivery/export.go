@@ -0,0 +1,93 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/orders"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// exportDate is the layout of the from and to dates of the order export.
+const exportDate = "2006-01-02"
+
+// ExportOrders sends the orders created between two dates as a CSV file, for
+// analytics (admin). With anonymize, order and user ids are replaced with stable
+// pseudonyms so the file can be shared with third-party analysts.
+// Endpoint: GET /api/v1/orders/admin/export
+// Query params: from and to (YYYY-MM-DD, both included; every order up to today by
+// default), anonymize (bool).
+func (h *OrderHandlers) ExportOrders(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var from time.Time
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	for name, t := range map[string]*time.Time{"from": &from, "to": &to} {
+		if s := q.Get(name); s != "" {
+			d, err := time.Parse(exportDate, s)
+			if err != nil {
+				_ = utils.BadRequest(w, r, fmt.Errorf("%s must be a date (YYYY-MM-DD)", name))
+				h.logger.Errorf("error parsing %s: %v", name, err)
+				return
+			}
+			*t = d
+		}
+	}
+	if to.Before(from) {
+		_ = utils.BadRequest(w, r, errors.New("from must not be after to"))
+		h.logger.Errorf("error exporting orders: from %s is after to %s", from, to)
+		return
+	}
+
+	var anonymize bool
+	if s := q.Get("anonymize"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			_ = utils.BadRequest(w, r, errors.New("anonymize must be true or false"))
+			h.logger.Errorf("error parsing anonymize: %v", err)
+			return
+		}
+		anonymize = b
+	}
+
+	filename := "orders.csv"
+	if anonymize {
+		filename = "orders-anonymized.csv"
+	}
+
+	cw := &countingWriter{w: w}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := h.ordersUC.ExportOrders(cw, from, to.AddDate(0, 0, 1), anonymize); err != nil {
+		if cw.n > 0 {
+			// The response is already under way, so the truncated file is all the
+			// client gets.
+			h.logger.Errorf("error exporting orders: %v", err)
+			return
+		}
+		w.Header().Del("Content-Disposition")
+		if errors.Is(err, orders.ErrAnonymizationDisabled) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error exporting orders: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error exporting orders: %w", err))
+	}
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -5,40 +5,84 @@
 package delivery
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/addresses"
+	"github.com/jofosuware/go/shopit/internal/checkout"
 	"github.com/jofosuware/go/shopit/internal/models"
 	"github.com/jofosuware/go/shopit/internal/orders"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/internal/promotions"
+	"github.com/jofosuware/go/shopit/pkg/eta"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
+	"github.com/jofosuware/go/shopit/pkg/featureflag"
+	"github.com/jofosuware/go/shopit/pkg/invoice"
 	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/money"
 	"github.com/jofosuware/go/shopit/pkg/utils"
 	"github.com/jofosuware/go/shopit/pkg/validator"
 )
 
+// recommendationsLimit is how many products the order confirmation suggests.
+const recommendationsLimit = 4
+
 // UserContextKey is the request context key used to store the authenticated user.
 const UserContextKey = utils.UserContextKey
 
 // OrderHandlers provides HTTP handler methods for order endpoints.
 type OrderHandlers struct {
-	logger   logger.Logger
-	ordersUC orders.OrderUC
+	logger      logger.Logger
+	ordersUC    orders.OrderUC
+	checkoutUC  checkout.CheckoutUC
+	promotionUC promotions.PromotionUC
+	productsUC  products.ProductUC
+	addressUC   addresses.AddressUC
+	estimator   *eta.Estimator
+	rates       *exchange.Converter
 }
 
-// NewOrderHandlers returns a new OrderHandlers with the provided logger and usecase.
-func NewOrderHandlers(logger logger.Logger, ordersUC orders.OrderUC) *OrderHandlers {
+// NewOrderHandlers returns a new OrderHandlers with the provided logger, usecases,
+// delivery estimator and currency converter.
+func NewOrderHandlers(logger logger.Logger, ordersUC orders.OrderUC, checkoutUC checkout.CheckoutUC,
+	promotionUC promotions.PromotionUC, productsUC products.ProductUC, addressUC addresses.AddressUC,
+	estimator *eta.Estimator, rates *exchange.Converter) *OrderHandlers {
 	return &OrderHandlers{
-		logger:   logger,
-		ordersUC: ordersUC,
+		logger:      logger,
+		ordersUC:    ordersUC,
+		checkoutUC:  checkoutUC,
+		promotionUC: promotionUC,
+		productsUC:  productsUC,
+		addressUC:   addressUC,
+		estimator:   estimator,
+		rates:       rates,
 	}
 }
 
 // CreateOrder creates a new order.
 // Endpoint: POST /api/v1/orders/new
-// Expects JSON body describing order items, shipping, and payment.
+// Expects JSON body describing order items, shipping, and payment. When it names a
+// checkoutSession, the locked prices of the session replace the submitted ones;
+// otherwise the items are priced from the catalog and only the shipping and tax prices
+// are taken as submitted. A
+// couponCode is redeemed for the order and its discount taken off the total; it
+// cannot be combined with a checkout session, whose total is already charged.
+// Gift orders (gift) can carry a giftMessage printed on the packing slip, and
+// hidePrices leaves the prices off the slip. The response suggests products to buy
+// with the order under recommendations. The order is in the currency of its checkout
+// session, else of its submitted amounts, which must all be in one currency. The
+// paymentInfo provider is stripe or paypal, stripe when it is not given; the payment
+// starts out requiring a payment method whatever status is submitted, and only the
+// provider moves it on. Shipping and tax prices cannot be negative. Instead of
+// shippingInfo, addressId ships the order to an address of the user's address book.
 func (h *OrderHandlers) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(UserContextKey).(*models.User)
 	if !ok {
@@ -48,88 +92,265 @@ func (h *OrderHandlers) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ord := &models.Order{
-		OrderItems:   []*models.Item{new(models.Item)},
 		ShippingInfo: models.Shipping{},
 		PaymentInfo:  models.Payment{},
 	}
 
-	order := struct {
-		OrderItems []*struct {
-			Product  string `json:"product"`
-			Name     string `json:"name"`
-			Price    int    `json:"price"`
-			Image    string `json:"image"`
-			Stock    int    `json:"stock"`
-			Quantity int    `json:"quantity"`
-		} `json:"orderItems"`
-		ShippingInfo *struct {
-			Address    string `json:"address"`
-			City       string `json:"city"`
-			PhoneNo    string `json:"phoneNo"`
-			PostalCode string `json:"postalCode"`
-			Country    string `json:"country"`
-		} `json:"shippingInfo"`
-		ItemsPrice    string  `json:"itemsPrice"`
-		ShippingPrice int     `json:"shippingPrice"`
-		TaxPrice      float64 `json:"taxPrice"`
-		TotalPrice    string  `json:"totalPrice"`
-		PaymentInfo   *struct {
-			ID     string `json:"id"`
-			Status string `json:"status"`
-		} `json:"paymentInfo"`
-	}{}
-
-	if err := utils.ReadJSON(w, r, &order); err != nil {
-		_ = utils.BadRequest(w, r, errors.New("bad request"))
-		h.logger.Errorf("error parsing payload: %v", err)
-		return
-	}
-
-	parsedId, err := uuid.Parse(order.OrderItems[0].Product)
-	itemPrice, _ := strconv.ParseFloat(order.ItemsPrice, 64)
-	totalPrice, _ := strconv.ParseFloat(order.TotalPrice, 64)
-	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("bad request"))
-		h.logger.Errorf("error parsing payload: %v", err)
-		return
-	}
-
-	ord.OrderItems[0].ProductID = parsedId
-	ord.OrderItems[0].Name = order.OrderItems[0].Name
-	ord.OrderItems[0].Price = order.OrderItems[0].Price
-	ord.OrderItems[0].Quantity = order.OrderItems[0].Quantity
-	ord.OrderItems[0].Image = order.OrderItems[0].Image
-	ord.ShippingInfo.Address = order.ShippingInfo.Address
-	ord.ShippingInfo.City = order.ShippingInfo.City
-	ord.ShippingInfo.PhoneNo = order.ShippingInfo.PhoneNo
-	ord.ShippingInfo.PostalCode = order.ShippingInfo.PostalCode
-	ord.ShippingInfo.Country = order.ShippingInfo.Country
-	ord.ItemPrice = int(itemPrice)
+	var order orderRequest
+	if !utils.Bind(w, r, h.logger, &order) {
+		return
+	}
+
+	currency := order.currency()
+	paymentProvider := order.provider()
+
+	for _, i := range order.OrderItems {
+		item := &models.Item{
+			ProductID: uuid.MustParse(i.Product),
+			Name:      i.Name,
+			Price:     i.Price,
+			Quantity:  i.Quantity,
+			Image:     i.Image,
+		}
+		if i.Variant != "" {
+			item.VariantID = uuid.NullUUID{UUID: uuid.MustParse(i.Variant), Valid: true}
+		}
+		ord.OrderItems = append(ord.OrderItems, item)
+	}
+
+	ord.ItemPrice = order.ItemsPrice
 	ord.ShippingPrice = order.ShippingPrice
 	ord.TaxPrice = order.TaxPrice
-	ord.TotalPrice = int(totalPrice)
+	ord.TotalPrice = order.TotalPrice
+	ord.Currency = currency
 	ord.PaymentInfo.ID = order.PaymentInfo.ID
-	ord.PaymentInfo.Status = order.PaymentInfo.Status
+	ord.PaymentInfo.Provider = paymentProvider
+	ord.PaymentInfo.Status = models.PaymentRequiresMethod
 	ord.UserID = user.ID
 	ord.PaidAt = time.Now()
-	ord.OrderStatus = "Processing"
+	ord.OrderStatus = models.OrderProcessing
 	ord.DeliveredAt = time.Time{}
+	ord.Variant = featureflag.Variant(r.Context())
+	ord.Gift = order.Gift
+	ord.GiftMessage = strings.TrimSpace(order.GiftMessage)
+	ord.HidePrices = order.HidePrices
+
+	if order.AddressID != "" {
+		addr, err := h.addressUC.GetAddress(uuid.MustParse(order.AddressID), user.ID)
+		if err != nil {
+			if addresses.IsClientError(err) {
+				_ = utils.BadRequest(w, r, err)
+				h.logger.Errorf("error getting shipping address: %v", err)
+				return
+			}
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting shipping address: %w", err))
+			return
+		}
+
+		ord.ShippingInfo = addr.Shipping()
+	} else {
+		ord.ShippingInfo.Address = order.ShippingInfo.Address
+		ord.ShippingInfo.City = order.ShippingInfo.City
+		ord.ShippingInfo.PhoneNo = order.ShippingInfo.PhoneNo
+		ord.ShippingInfo.PostalCode = order.ShippingInfo.PostalCode
+		ord.ShippingInfo.Country = order.ShippingInfo.Country
+	}
+
+	var sessionID uuid.UUID
+	if order.CheckoutSession != "" {
+		sessionID = uuid.MustParse(order.CheckoutSession)
+
+		session, err := h.checkoutUC.Claim(r.Context(), sessionID, user.ID, ord.PaymentInfo.ID)
+		if err != nil {
+			if checkout.IsClientError(err) {
+				_ = utils.BadRequest(w, r, err)
+				h.logger.Errorf("error claiming checkout session: %v", err)
+				return
+			}
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error claiming checkout session: %w", err))
+			return
+		}
 
-	ord, err = h.ordersUC.CreateOrder(*ord)
+		if err := applySession(ord, session); err != nil {
+			h.releaseSession(r.Context(), sessionID)
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error applying checkout session: %v", err)
+			return
+		}
+	} else if err := h.priceOrder(r.Context(), ord); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = utils.BadRequest(w, r, errors.New("product not found"))
+			h.logger.Errorf("error pricing order: %v", err)
+			return
+		}
+		if checkout.IsClientError(err) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error pricing order: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error pricing order: %w", err))
+		return
+	}
+
+	var (
+		redemption *models.CouponRedemption
+		err        error
+	)
+	if code := strings.TrimSpace(order.CouponCode); code != "" {
+		if order.CheckoutSession != "" {
+			h.releaseSession(r.Context(), sessionID)
+			_ = utils.BadRequest(w, r, errors.New("a coupon cannot be used with a checkout session"))
+			h.logger.Errorf("error redeeming coupon: coupon used with a checkout session")
+			return
+		}
+
+		redemption, err = h.promotionUC.Redeem(code, user.ID, ord.ItemPrice)
+		if err != nil {
+			if promotions.IsClientError(err) {
+				_ = utils.BadRequest(w, r, err)
+				h.logger.Errorf("error redeeming coupon: %v", err)
+				return
+			}
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error redeeming coupon: %w", err))
+			return
+		}
+
+		applyCoupon(ord, redemption)
+	}
+
+	productIds := make([]uuid.UUID, 0, len(ord.OrderItems))
+	for _, item := range ord.OrderItems {
+		productIds = append(productIds, item.ProductID)
+	}
+
+	ord, err = h.ordersUC.CreateOrder(r.Context(), *ord)
 	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error creating order: %v", err)
+		if order.CheckoutSession != "" {
+			h.releaseSession(r.Context(), sessionID)
+		}
+		if redemption != nil {
+			h.releaseCoupon(redemption.ID)
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error creating order: %w", err))
 		return
 	}
 
-	jr := models.OrderResponse{
-		Success: true,
-		Order:   *ord,
+	if order.CheckoutSession != "" {
+		if err := h.checkoutUC.Complete(r.Context(), sessionID, ord.OrderID); err != nil {
+			// the order is placed and the session already claimed, so only log it
+			h.logger.Errorf("error completing checkout session: %v", err)
+		}
+	}
+
+	if redemption != nil {
+		if err := h.promotionUC.AttachOrder(redemption.ID, ord.OrderID); err != nil {
+			// the order is placed and the coupon already used, so only log it
+			h.logger.Errorf("error recording coupon order: %v", err)
+		}
+	}
+
+	jr := struct {
+		models.OrderResponse
+		Recommendations []models.Recommendation `json:"recommendations"`
+	}{
+		OrderResponse:   models.OrderResponse{Success: true, Order: *ord},
+		Recommendations: h.recommend(productIds),
 	}
 
 	_ = utils.WriteJSON(w, http.StatusOK, jr)
 }
 
+// recommend returns the products to suggest with an order of productIds. The order
+// is already placed, so failures are only logged and nothing is suggested.
+func (h *OrderHandlers) recommend(productIds []uuid.UUID) []models.Recommendation {
+	recs, err := h.productsUC.GetRecommendations(productIds, recommendationsLimit)
+	if err != nil {
+		h.logger.Errorf("error getting recommendations: %v", err)
+		return []models.Recommendation{}
+	}
+
+	return recs
+}
+
+// applySession replaces the prices of ord with the prices locked by session.
+func applySession(ord *models.Order, session *models.CheckoutSession) error {
+	for _, item := range ord.OrderItems {
+		locked := session.Item(item.ProductID, item.VariantID)
+		if locked == nil {
+			return errors.New("order item is not part of the checkout session")
+		}
+
+		item.Name = locked.Name
+		item.Price = locked.Price
+		item.Quantity = locked.Quantity
+	}
+
+	ord.Currency = session.Currency
+	ord.ItemPrice = session.ItemsPrice
+	ord.ShippingPrice = session.ShippingPrice
+	ord.TaxPrice = session.TaxPrice
+	ord.TotalPrice = session.TotalPrice
+
+	return nil
+}
+
+// priceOrder prices the items of an order placed without a checkout session from the
+// catalog, in the currency of the order, and totals it with the submitted shipping and
+// tax prices. The submitted item prices are not trusted, since the order is charged
+// for what it adds up to.
+func (h *OrderHandlers) priceOrder(ctx context.Context, ord *models.Order) error {
+	items := make([]*models.CheckoutItem, len(ord.OrderItems))
+	for n, i := range ord.OrderItems {
+		items[n] = &models.CheckoutItem{ProductID: i.ProductID, VariantID: i.VariantID, Quantity: i.Quantity}
+	}
+
+	total, err := h.checkoutUC.PriceItems(ctx, ord.Currency, items)
+	if err != nil {
+		return err
+	}
+
+	for n, i := range items {
+		ord.OrderItems[n].Name = i.Name
+		ord.OrderItems[n].Price = i.Price
+	}
+	ord.ItemPrice = total
+	ord.TotalPrice = total.Add(ord.ShippingPrice).Add(ord.TaxPrice)
+
+	return nil
+}
+
+// inCurrency reports whether every non-zero amount of amounts is in currency.
+func inCurrency(currency string, amounts ...money.Money) bool {
+	for _, m := range amounts {
+		if !m.IsZero() && m.Currency != "" && m.Currency != currency {
+			return false
+		}
+	}
+
+	return true
+}
+
+// applyCoupon records the coupon redeemed for ord and takes its discount off the total.
+func applyCoupon(ord *models.Order, redemption *models.CouponRedemption) {
+	ord.CouponCode = redemption.Code
+	ord.Discount = redemption.Discount
+	ord.TotalPrice = money.Max(ord.TotalPrice.Sub(redemption.Discount), money.Money{})
+}
+
+// releaseCoupon gives back a coupon redeemed for an order that was not placed.
+func (h *OrderHandlers) releaseCoupon(id uuid.UUID) {
+	if err := h.promotionUC.Release(id); err != nil {
+		h.logger.Errorf("error releasing coupon: %v", err)
+	}
+}
+
+// releaseSession reopens a claimed checkout session whose order was not placed.
+func (h *OrderHandlers) releaseSession(ctx context.Context, id uuid.UUID) {
+	if err := h.checkoutUC.Release(ctx, id); err != nil {
+		h.logger.Errorf("error releasing checkout session: %v", err)
+	}
+}
+
 // GetSingleOrder returns an order by its ID.
 // Endpoint: GET /api/v1/orders/{id}
 func (h *OrderHandlers) GetSingleOrder(w http.ResponseWriter, r *http.Request) {
@@ -144,19 +365,41 @@ func (h *OrderHandlers) GetSingleOrder(w http.ResponseWriter, r *http.Request) {
 
 	order, err := h.ordersUC.GetSingleOrder(parsedId)
 	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error getting order: %v", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = utils.BadRequest(w, r, errors.New("order not found"))
+			h.logger.Errorf("error getting order: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting order: %w", err))
 		return
 	}
 
 	jr := models.OrderResponse{
-		Success: true,
-		Order:   *order,
+		Success:           true,
+		Order:             *order,
+		EstimatedDelivery: h.estimateDelivery(order),
 	}
 
 	_ = utils.WriteJSON(w, http.StatusOK, jr)
 }
 
+// estimateDelivery returns when an undelivered order is expected to arrive by the
+// default shipping method, counting from payment.
+func (h *OrderHandlers) estimateDelivery(order *models.Order) *models.DeliveryEstimate {
+	if order.OrderStatus == models.OrderDelivered || order.ShippingInfo.Country == "" {
+		return nil
+	}
+
+	from := order.PaidAt
+	if from.IsZero() {
+		from = order.CreatedAt
+	}
+
+	// the default method is always configured
+	est, _ := h.estimator.Estimate("", order.ShippingInfo.Country, from)
+	return est
+}
+
 // GetUserOrders returns orders for the currently authenticated user.
 // Endpoint: GET /api/v1/orders/me
 func (h *OrderHandlers) GetUserOrders(w http.ResponseWriter, r *http.Request) {
@@ -169,8 +412,7 @@ func (h *OrderHandlers) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 
 	ords, err := h.ordersUC.GetUserOrders(user.ID)
 	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error getting user orders: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting user orders: %w", err))
 		return
 	}
 
@@ -185,25 +427,30 @@ func (h *OrderHandlers) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 	_ = utils.WriteJSON(w, http.StatusOK, jr)
 }
 
-// GetAllOrders returns all orders (admin).
+// GetAllOrders returns all orders (admin). The total amount is in the shop currency,
+// converting orders in other currencies at the current exchange rates.
 // Endpoint: GET /api/v1/orders/admin/orders
 func (h *OrderHandlers) GetAllOrders(w http.ResponseWriter, r *http.Request) {
 	ords, err := h.ordersUC.GetAllOrders()
 	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error getting orders: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting orders: %w", err))
 		return
 	}
 
-	var totalAmount = 0
+	var totalAmount = money.Of(0)
 
 	for _, ord := range ords {
-		totalAmount += ord.TotalPrice
+		total, err := h.rates.Convert(ord.TotalPrice, money.DefaultCurrency)
+		if err != nil {
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error converting order total: %w", err))
+			return
+		}
+		totalAmount = totalAmount.Add(total)
 	}
 
 	jr := struct {
 		Success     bool            `json:"success"`
-		TotalAmount int             `json:"totalAmount"`
+		TotalAmount money.Money     `json:"totalAmount"`
 		Orders      []*models.Order `json:"orders"`
 	}{
 		Success:     true,
@@ -216,7 +463,12 @@ func (h *OrderHandlers) GetAllOrders(w http.ResponseWriter, r *http.Request) {
 
 // UpdateOrder updates an order's status (admin).
 // Endpoint: PUT /api/v1/orders/admin/order/{id}
-// Expects form data: status.
+// Expects form data: status, in any case. A Processing order can be marked Shipped or
+// Delivered and a Shipped order Delivered; any other status is answered with 422 and
+// the allowed ones. A payment only authorized at checkout is captured when the order
+// is marked Shipped (or Delivered); the status is not changed when the capture fails.
+// The items of the order are taken from stock then, once. Cancelled orders cannot be
+// updated.
 func (h *OrderHandlers) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -227,49 +479,62 @@ func (h *OrderHandlers) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = r.ParseMultipartForm(100000)
-	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error parsing form: %v", err)
-		return
-	}
-
-	status := r.Form.Get("status")
-
-	v := validator.New()
-
-	v.Check(status != "", "status", "status field is empty")
-
-	if !v.Valid() {
-		_ = utils.BadRequest(w, r, errors.New("forms must be filled"))
-		h.logger.Errorf("Form validation error")
+	var req statusRequest
+	if !utils.Bind(w, r, h.logger, &req) {
 		return
 	}
 
 	order, err := h.ordersUC.GetSingleOrder(parsedId)
 	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error fetching order: %v", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = utils.BadRequest(w, r, errors.New("order not found"))
+			h.logger.Errorf("error fetching order: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error fetching order: %w", err))
 		return
 	}
 
-	if order.OrderStatus == "Delivered" {
+	if order.OrderStatus == models.OrderDelivered {
 		_ = utils.BadRequest(w, r, errors.New("you have already delivered this order"))
 		h.logger.Infof("you have already delivered this order")
 		return
 	}
 
-	for _, item := range order.OrderItems {
-		err := h.ordersUC.UpdateStock(item.ProductID, item.Quantity)
-		if err != nil {
-			_ = utils.BadRequest(w, r, err)
-			h.logger.Errorf("error updating stock: %v", err)
+	if order.OrderStatus == models.OrderCancelled {
+		_ = utils.BadRequest(w, r, errors.New("this order was cancelled"))
+		h.logger.Errorf("error updating order: order %s was cancelled", order.OrderID)
+		return
+	}
+
+	next := models.NextOrderStatuses(order.OrderStatus)
+	status, _ := models.ParseOrderStatus(req.Status)
+
+	v := validator.New()
+	v.CheckCode(hasStatus(next, status), "status", validator.CodeOneOf,
+		fmt.Sprintf("status must be one of: %s", strings.Join(next, ", ")))
+
+	if !v.Valid() {
+		utils.FailedValidation(w, r, v)
+		h.logger.Errorf("Failed validation: %v", v.Errors)
+		return
+	}
+
+	// an authorized payment is charged when the order leaves the warehouse
+	if status == models.OrderShipped || status == models.OrderDelivered {
+		if err := h.ordersUC.CapturePayment(order); err != nil {
+			if errors.Is(err, orders.ErrPaymentVoided) || errors.Is(err, orders.ErrCaptureFailed) {
+				_ = utils.BadRequest(w, r, err)
+				h.logger.Errorf("error capturing payment: %v", err)
+				return
+			}
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error capturing payment: %w", err))
 			return
 		}
 	}
 
 	order.OrderStatus = status
-	if status == "Delivered" {
+	if status == models.OrderDelivered {
 		order.DeliveredAt = time.Now()
 	} else {
 		order.DeliveredAt = time.Time{}
@@ -277,8 +542,7 @@ func (h *OrderHandlers) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 
 	err = h.ordersUC.UpdateOrder(*order)
 	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error updating order: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error updating order: %w", err))
 		return
 	}
 
@@ -291,7 +555,62 @@ func (h *OrderHandlers) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	_ = utils.WriteJSON(w, http.StatusOK, jsonRes)
 }
 
-// DeleteOrder deletes an order (admin).
+// CancelOrder cancels an order of the current user while it is still processing. An
+// authorized payment is voided and a payment already taken is marked for refund. A
+// cancelled order, or one that has shipped, is answered with 409.
+// Endpoint: POST /api/v1/orders/{id}/cancel
+// Accepts JSON: {"reason": <why it is cancelled>}, or no body.
+func (h *OrderHandlers) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	parsedId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	var req cancelRequest
+	if r.ContentLength != 0 && !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	order, err := h.ordersUC.CancelOrder(parsedId, user.ID, strings.TrimSpace(req.Reason))
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrOrderCancelled), errors.Is(err, orders.ErrOrderShipped):
+			_ = utils.WriteJSON(w, http.StatusConflict, models.Response{Message: err.Error()})
+			h.logger.Errorf("error cancelling order: %v", err)
+		case errors.Is(err, orders.ErrOrderNotFound):
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error cancelling order: %v", err)
+		default:
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error cancelling order: %w", err))
+		}
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, models.OrderResponse{Success: true, Order: *order})
+}
+
+// hasStatus reports whether status is one of statuses.
+func hasStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DeleteOrder deletes an order (admin). The items of a shipped or delivered order go
+// back to stock.
 // Endpoint: DELETE /api/v1/orders/admin/order/{id}
 func (h *OrderHandlers) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -305,8 +624,12 @@ func (h *OrderHandlers) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 
 	err = h.ordersUC.DeleteOrder(parsedId)
 	if err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error deleting the order: %v", err)
+		if errors.Is(err, orders.ErrOrderNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error deleting the order: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error deleting the order: %w", err))
 		return
 	}
 
@@ -318,3 +641,160 @@ func (h *OrderHandlers) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 
 	_ = utils.WriteJSON(w, http.StatusOK, jsonRes)
 }
+
+// GetPickList returns the items to pick across the selected orders (admin).
+// Endpoint: GET /api/v1/orders/admin/picklist?orders={id},{id}&format=json|pdf
+func (h *OrderHandlers) GetPickList(w http.ResponseWriter, r *http.Request) {
+	format, ok := h.documentFormat(w, r)
+	if !ok {
+		return
+	}
+
+	var ids []uuid.UUID
+	for _, s := range strings.Split(r.URL.Query().Get("orders"), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := uuid.Parse(s)
+		if err != nil {
+			_ = utils.BadRequest(w, r, fmt.Errorf("invalid order id %q", s))
+			h.logger.Errorf("error parsing id: %v", err)
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	list, err := h.ordersUC.GetPickList(ids)
+	if err != nil {
+		if errors.Is(err, orders.ErrInvalidSelection) || errors.Is(err, orders.ErrOrderNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error getting pick list: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting pick list: %w", err))
+		return
+	}
+
+	if format == formatPDF {
+		if err := writePDF(w, "pick-list.pdf", pickListPDF(list)); err != nil {
+			h.logger.Errorf("error writing pick list: %v", err)
+		}
+		return
+	}
+
+	jr := struct {
+		Success  bool             `json:"success"`
+		PickList *models.PickList `json:"pickList"`
+	}{
+		Success:  true,
+		PickList: list,
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// GetInvoice downloads the invoice of an order as PDF, for the owner of the order or
+// an admin. With invoice archiving enabled, the invoice of a paid order is also
+// uploaded to storage the first time it is downloaded; failing to archive it does
+// not fail the download.
+// Endpoint: GET /api/v1/orders/{id}/invoice
+func (h *OrderHandlers) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("user is not logged in"))
+		h.logger.Error("error getting user from context")
+		return
+	}
+
+	parsedId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	order, doc, err := h.ordersUC.GetInvoice(parsedId, user)
+	if err != nil {
+		if errors.Is(err, orders.ErrOrderNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error getting invoice: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting invoice: %w", err))
+		return
+	}
+
+	if err := h.ordersUC.ArchiveInvoice(r.Context(), order, doc); err != nil {
+		h.logger.Errorf("error archiving invoice of order %s: %v", order.OrderID, err)
+	}
+
+	if err := writePDF(w, invoice.Filename(order.OrderID), bytes.NewReader(doc)); err != nil {
+		h.logger.Errorf("error writing invoice: %v", err)
+	}
+}
+
+// GetPackingSlip returns the packing slip of an order (admin).
+// Endpoint: GET /api/v1/orders/admin/order/{id}/packingslip?format=json|pdf
+func (h *OrderHandlers) GetPackingSlip(w http.ResponseWriter, r *http.Request) {
+	format, ok := h.documentFormat(w, r)
+	if !ok {
+		return
+	}
+
+	parsedId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	slip, err := h.ordersUC.GetPackingSlip(parsedId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = utils.BadRequest(w, r, errors.New("order not found"))
+			h.logger.Errorf("error getting packing slip: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting packing slip: %w", err))
+		return
+	}
+
+	if format == formatPDF {
+		if err := writePDF(w, "packing-slip-"+slip.OrderID.String()+".pdf", packingSlipPDF(slip)); err != nil {
+			h.logger.Errorf("error writing packing slip: %v", err)
+		}
+		return
+	}
+
+	jr := struct {
+		Success     bool                `json:"success"`
+		PackingSlip *models.PackingSlip `json:"packingSlip"`
+	}{
+		Success:     true,
+		PackingSlip: slip,
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// documentFormat reads the format query parameter of the warehouse documents,
+// writing a bad request when it is not supported.
+func (h *OrderHandlers) documentFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		return formatJSON, true
+	case formatJSON, formatPDF:
+		return format, true
+	}
+
+	_ = utils.BadRequest(w, r, fmt.Errorf("unsupported format %q, use json or pdf", format))
+	h.logger.Errorf("unsupported document format: %s", format)
+	return "", false
+}
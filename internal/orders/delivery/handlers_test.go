@@ -3,18 +3,37 @@ package delivery_test
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/addresses"
+	addrMocks "github.com/jofosuware/go/shopit/internal/addresses/mocks"
+	"github.com/jofosuware/go/shopit/internal/checkout"
+	mockCheckout "github.com/jofosuware/go/shopit/internal/checkout/mocks"
 	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/orders"
 	"github.com/jofosuware/go/shopit/internal/orders/delivery"
 	mockOrder "github.com/jofosuware/go/shopit/internal/orders/mocks"
+	prodMocks "github.com/jofosuware/go/shopit/internal/products/mocks"
+	"github.com/jofosuware/go/shopit/internal/promotions"
+	promoMocks "github.com/jofosuware/go/shopit/internal/promotions/mocks"
+	"github.com/jofosuware/go/shopit/pkg/eta"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
 	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/realtime"
 	"github.com/jofosuware/go/shopit/pkg/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -23,23 +42,57 @@ import (
 
 const UserContextKey = utils.UserContextKey
 
+func newEstimator(t *testing.T) *eta.Estimator {
+	e, err := eta.New(config.Delivery{
+		Warehouse:     "GH",
+		HandlingDays:  1,
+		DefaultMethod: "standard",
+		Methods: map[string]config.DeliveryMethod{
+			"standard": {Domestic: "2-4", International: "7-14"},
+		},
+	})
+	require.NoError(t, err)
+	return e
+}
+
+func newRates() *exchange.Converter {
+	return exchange.New(exchange.NewStatic(map[string]float64{"EUR": 0.5}))
+}
+
+// priceItems expects the items of an order placed without a checkout session to be
+// priced from the catalog at price each.
+func priceItems(checkoutUC *mockCheckout.CheckoutUC, price money.Money) {
+	checkoutUC.On("PriceItems", mock.Anything, money.DefaultCurrency, mock.Anything).
+		Return(func(_ context.Context, _ string, items []*models.CheckoutItem) (money.Money, error) {
+			total := money.Of(0)
+			for _, i := range items {
+				i.Name = "Catalog Product"
+				i.Price = price
+				total = total.Add(price.Mul(i.Quantity))
+			}
+			return total, nil
+		}).Once()
+}
+
 func TestCreateOrder(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	orderUC := mockOrder.NewOrderUC(t)
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
+	productsUC := prodMocks.NewProductUC(t)
 
-	o := delivery.NewOrderHandlers(logger, orderUC)
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promoMocks.NewPromotionUC(t), productsUC, addrMocks.NewAddressUC(t), newEstimator(t), newRates())
 
 	t.Run("Order successfully created", func(t *testing.T) {
 		// Prepare the payload matching the handler's anonymous struct.
 		prodID := uuid.New().String()
 		payload := struct {
 			OrderItems []*struct {
-				Product  string `json:"product"`
-				Name     string `json:"name"`
-				Price    int    `json:"price"`
-				Image    string `json:"image"`
-				Stock    int    `json:"stock"`
-				Quantity int    `json:"quantity"`
+				Product  string      `json:"product"`
+				Name     string      `json:"name"`
+				Price    money.Money `json:"price"`
+				Image    string      `json:"image"`
+				Stock    int         `json:"stock"`
+				Quantity int         `json:"quantity"`
 			} `json:"orderItems"`
 			ShippingInfo *struct {
 				Address    string `json:"address"`
@@ -48,27 +101,27 @@ func TestCreateOrder(t *testing.T) {
 				PostalCode string `json:"postalCode"`
 				Country    string `json:"country"`
 			} `json:"shippingInfo"`
-			ItemsPrice    string `json:"itemsPrice"`
-			ShippingPrice int    `json:"shippingPrice"`
-			TaxPrice      int    `json:"taxPrice"`
-			TotalPrice    string `json:"totalPrice"`
+			ItemsPrice    money.Money `json:"itemsPrice"`
+			ShippingPrice money.Money `json:"shippingPrice"`
+			TaxPrice      money.Money `json:"taxPrice"`
+			TotalPrice    money.Money `json:"totalPrice"`
 			PaymentInfo   *struct {
 				ID     string `json:"id"`
 				Status string `json:"status"`
 			} `json:"paymentInfo"`
 		}{
 			OrderItems: []*struct {
-				Product  string `json:"product"`
-				Name     string `json:"name"`
-				Price    int    `json:"price"`
-				Image    string `json:"image"`
-				Stock    int    `json:"stock"`
-				Quantity int    `json:"quantity"`
+				Product  string      `json:"product"`
+				Name     string      `json:"name"`
+				Price    money.Money `json:"price"`
+				Image    string      `json:"image"`
+				Stock    int         `json:"stock"`
+				Quantity int         `json:"quantity"`
 			}{
 				{
 					Product:  prodID,
 					Name:     "Test Product",
-					Price:    100,
+					Price:    money.Of(10000),
 					Image:    "http://example.com/image.png",
 					Stock:    10,
 					Quantity: 1,
@@ -87,16 +140,16 @@ func TestCreateOrder(t *testing.T) {
 				PostalCode: "00000",
 				Country:    "TestLand",
 			},
-			ItemsPrice:    "100",
-			ShippingPrice: 10,
-			TaxPrice:      5,
-			TotalPrice:    "115",
+			ItemsPrice:    money.Of(10000),
+			ShippingPrice: money.Of(1000),
+			TaxPrice:      money.Of(500),
+			TotalPrice:    money.Of(11500),
 			PaymentInfo: &struct {
 				ID     string `json:"id"`
 				Status string `json:"status"`
 			}{
 				ID:     "pay1",
-				Status: "pending",
+				Status: models.PaymentSucceeded,
 			},
 		}
 
@@ -113,9 +166,16 @@ func TestCreateOrder(t *testing.T) {
 		ctx := context.WithValue(req.Context(), UserContextKey, &user)
 		req = req.WithContext(ctx)
 
-		// Expect the use case CreateOrder to be invoked.
-		// (Using mock.Anything here for simplicity; you can use a more precise matcher if needed.)
-		orderUC.On("CreateOrder", mock.Anything).Return(&models.Order{}, nil)
+		// The submitted prices are replaced with the catalog ones, and the submitted
+		// payment status is ignored.
+		priceItems(checkoutUC, money.Of(8000))
+		orderUC.On("CreateOrder", mock.Anything, mock.MatchedBy(func(ord models.Order) bool {
+			return len(ord.OrderItems) == 1 && ord.OrderItems[0].Price == money.Of(8000) &&
+				ord.OrderItems[0].Name == "Catalog Product" && ord.ItemPrice == money.Of(8000) &&
+				ord.TotalPrice == money.Of(9500) && ord.PaymentInfo.Status == models.PaymentRequiresMethod
+		})).Return(&models.Order{}, nil).Once()
+		productsUC.On("GetRecommendations", []uuid.UUID{uuid.MustParse(prodID)}, 4).
+			Return([]models.Recommendation{{ProductId: uuid.New(), Name: "Tripod"}}, nil).Once()
 
 		o.CreateOrder(rr, req)
 
@@ -123,14 +183,401 @@ func TestCreateOrder(t *testing.T) {
 		want := http.StatusOK
 
 		assert.Equal(t, want, got)
+
+		var resp struct {
+			Recommendations []models.Recommendation `json:"recommendations"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Recommendations, 1)
+		assert.Equal(t, "Tripod", resp.Recommendations[0].Name)
+	})
+
+	t.Run("Every item is kept", func(t *testing.T) {
+		first, second := uuid.New(), uuid.New()
+		body := fmt.Sprintf(`{"orderItems":[{"product":%q,"quantity":1},{"product":%q,"quantity":3}],`+
+			`"shippingInfo":{"address":"1 Main St"},"paymentInfo":{"id":"pay1"}}`, first, second)
+		req, err := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, &models.User{ID: uuid.New()}))
+
+		priceItems(checkoutUC, money.Of(1000))
+		orderUC.On("CreateOrder", mock.Anything, mock.MatchedBy(func(ord models.Order) bool {
+			return len(ord.OrderItems) == 2 && ord.OrderItems[1].ProductID == second &&
+				ord.OrderItems[1].Quantity == 3 && ord.TotalPrice == money.Of(4000)
+		})).Return(&models.Order{}, nil).Once()
+		productsUC.On("GetRecommendations", []uuid.UUID{first, second}, 4).Return([]models.Recommendation{}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		body := fmt.Sprintf(`{"orderItems":[{"product":%q,"quantity":1}],"shippingInfo":{"address":"1 Main St"},`+
+			`"paymentInfo":{"id":"pay1"}}`, uuid.New())
+		req, err := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, &models.User{ID: uuid.New()}))
+
+		checkoutUC.On("PriceItems", mock.Anything, money.DefaultCurrency, mock.Anything).
+			Return(money.Money{}, fmt.Errorf("product: %w", sql.ErrNoRows)).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Quantity must be positive", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		body := fmt.Sprintf(`{"orderItems":[{"product":%q,"quantity":0}],"shippingInfo":{"address":"1 Main St"},`+
+			`"paymentInfo":{"id":"pay1"}}`, uuid.New())
+		req, err := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, &models.User{ID: uuid.New()}))
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Amounts in several currencies", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		require.NoError(t, money.Accept("EUR"))
+		t.Cleanup(func() { money.Accept() })
+
+		body := fmt.Sprintf(`{"orderItems":[{"product":%q,"quantity":1,"price":"100.00"}],"itemsPrice":"100.00",`+
+			`"totalPrice":{"amount":10000,"currency":"EUR"},"paymentInfo":{"id":"pay1"}}`, uuid.New())
+		req, err := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, &models.User{ID: uuid.New()}))
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Negative shipping and tax prices", func(t *testing.T) {
+		for _, amounts := range []string{`"shippingPrice":"-10.00"`, `"taxPrice":"-1.00"`} {
+			logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+			body := fmt.Sprintf(`{"orderItems":[{"product":%q,"quantity":1}],"shippingInfo":{"address":"1 Main St"},`+
+				`"paymentInfo":{"id":"pay1"},%s}`, uuid.New(), amounts)
+			req, err := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), UserContextKey, &models.User{ID: uuid.New()}))
+
+			rr := httptest.NewRecorder()
+			o.CreateOrder(rr, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, amounts)
+		}
+	})
+
+	t.Run("Unknown payment provider", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		body := fmt.Sprintf(`{"orderItems":[{"product":%q,"quantity":1,"price":"100.00"}],"itemsPrice":"100.00",`+
+			`"totalPrice":"100.00","paymentInfo":{"id":"pay1","provider":"bitcoin"}}`, uuid.New())
+		req, err := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, &models.User{ID: uuid.New()}))
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+func TestCreateOrderWithCheckoutSession(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
+	productsUC := prodMocks.NewProductUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promoMocks.NewPromotionUC(t), productsUC, addrMocks.NewAddressUC(t), newEstimator(t), newRates())
+
+	user := models.User{ID: uuid.New()}
+	prodID, sessionID := uuid.New(), uuid.New()
+	newRequest := func(t *testing.T) *http.Request {
+		body := `{"orderItems":[{"product":"` + prodID.String() + `","name":"Test Product","price":"1","quantity":5}],
+			"shippingInfo":{"address":"123 Test Street"},"itemsPrice":"5","totalPrice":"5",
+			"paymentInfo":{"id":"pi_1","status":"succeeded"},"checkoutSession":"` + sessionID.String() + `"}`
+
+		req, err := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+		require.NoError(t, err)
+
+		return req.WithContext(context.WithValue(req.Context(), UserContextKey, &user))
+	}
+
+	t.Run("Locked prices replace the submitted ones", func(t *testing.T) {
+		session := &models.CheckoutSession{
+			ID:            sessionID,
+			Items:         []*models.CheckoutItem{{ProductID: prodID, Name: "Test Product", Price: money.Of(100), Quantity: 2}},
+			ItemsPrice:    money.Of(200),
+			ShippingPrice: money.Of(10),
+			TaxPrice:      money.Of(5),
+			TotalPrice:    money.Of(215),
+		}
+		checkoutUC.On("Claim", mock.Anything, sessionID, user.ID, "pi_1").Return(session, nil).Once()
+
+		orderID := uuid.New()
+		orderUC.On("CreateOrder", mock.Anything, mock.MatchedBy(func(ord models.Order) bool {
+			return ord.TotalPrice == money.Of(215) && ord.ItemPrice == money.Of(200) && ord.OrderItems[0].Price == money.Of(100) &&
+				ord.OrderItems[0].Quantity == 2
+		})).Return(&models.Order{OrderID: orderID}, nil).Once()
+		checkoutUC.On("Complete", mock.Anything, sessionID, orderID).Return(nil).Once()
+		productsUC.On("GetRecommendations", []uuid.UUID{prodID}, 4).Return([]models.Recommendation{}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Expired session is rejected", func(t *testing.T) {
+		checkoutUC.On("Claim", mock.Anything, sessionID, user.ID, "pi_1").Return(nil, checkout.ErrSessionExpired).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Session is released when the order fails", func(t *testing.T) {
+		session := &models.CheckoutSession{
+			ID:    sessionID,
+			Items: []*models.CheckoutItem{{ProductID: prodID, Price: money.Of(100), Quantity: 2}},
+		}
+		checkoutUC.On("Claim", mock.Anything, sessionID, user.ID, "pi_1").Return(session, nil).Once()
+		orderUC.On("CreateOrder", mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+		checkoutUC.On("Release", mock.Anything, sessionID).Return(nil).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestCreateOrderWithCoupon(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
+	promotionUC := promoMocks.NewPromotionUC(t)
+	productsUC := prodMocks.NewProductUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promotionUC, productsUC, addrMocks.NewAddressUC(t), newEstimator(t), newRates())
+
+	user := models.User{ID: uuid.New()}
+	prodID := uuid.New()
+	newRequest := func(t *testing.T, extra string) *http.Request {
+		body := `{"orderItems":[{"product":"` + prodID.String() + `","name":"Test Product","price":"100","quantity":2}],
+			"shippingInfo":{"address":"123 Test Street"},"itemsPrice":"200","totalPrice":"215",
+			"paymentInfo":{"id":"pi_1","status":"succeeded"},"couponCode":" save10 "` + extra + `}`
+
+		req, err := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+		require.NoError(t, err)
+
+		return req.WithContext(context.WithValue(req.Context(), UserContextKey, &user))
+	}
+
+	t.Run("Discount is recorded on the order", func(t *testing.T) {
+		redemption := &models.CouponRedemption{ID: uuid.New(), Code: "SAVE10", Discount: money.Of(2000)}
+		priceItems(checkoutUC, money.Of(10000))
+		promotionUC.On("Redeem", "save10", user.ID, money.Of(20000)).Return(redemption, nil).Once()
+
+		orderID := uuid.New()
+		orderUC.On("CreateOrder", mock.Anything, mock.MatchedBy(func(ord models.Order) bool {
+			return ord.CouponCode == "SAVE10" && ord.Discount == money.Of(2000) && ord.TotalPrice == money.Of(18000)
+		})).Return(&models.Order{OrderID: orderID}, nil).Once()
+		promotionUC.On("AttachOrder", redemption.ID, orderID).Return(nil).Once()
+		productsUC.On("GetRecommendations", []uuid.UUID{prodID}, 4).Return([]models.Recommendation{}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t, ""))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Unusable coupon is rejected", func(t *testing.T) {
+		priceItems(checkoutUC, money.Of(10000))
+		promotionUC.On("Redeem", "save10", user.ID, money.Of(20000)).Return(nil, promotions.ErrCouponExpired).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t, ""))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Coupon is released when the order fails", func(t *testing.T) {
+		redemption := &models.CouponRedemption{ID: uuid.New(), Code: "SAVE10", Discount: money.Of(2000)}
+		priceItems(checkoutUC, money.Of(10000))
+		promotionUC.On("Redeem", "save10", user.ID, money.Of(20000)).Return(redemption, nil).Once()
+		orderUC.On("CreateOrder", mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+		promotionUC.On("Release", redemption.ID).Return(nil).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t, ""))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestCreateGiftOrder(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+	productsUC := prodMocks.NewProductUC(t)
+
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promoMocks.NewPromotionUC(t),
+		productsUC, addrMocks.NewAddressUC(t), newEstimator(t), newRates())
+
+	user := models.User{ID: uuid.New()}
+	newRequest := func(t *testing.T, gift string) *http.Request {
+		body := `{"orderItems":[{"product":"` + uuid.NewString() + `","name":"Test Product","price":"100","quantity":2}],
+			"shippingInfo":{"address":"123 Test Street"},"itemsPrice":"200","totalPrice":"215",
+			"paymentInfo":{"id":"pi_1","status":"succeeded"},` + gift + `}`
+
+		req, err := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+		require.NoError(t, err)
+
+		return req.WithContext(context.WithValue(req.Context(), UserContextKey, &user))
+	}
+
+	t.Run("Gift options are saved", func(t *testing.T) {
+		priceItems(checkoutUC, money.Of(10000))
+		orderUC.On("CreateOrder", mock.Anything, mock.MatchedBy(func(ord models.Order) bool {
+			return ord.Gift && ord.GiftMessage == "Happy birthday!" && ord.HidePrices
+		})).Return(&models.Order{OrderID: uuid.New(), Gift: true, GiftMessage: "Happy birthday!", HidePrices: true}, nil).Once()
+		productsUC.On("GetRecommendations", mock.Anything, 4).Return(nil, errors.New("db error")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t, `"gift":true,"giftMessage":" Happy birthday! ","hidePrices":true`))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Recommendations []models.Recommendation `json:"recommendations"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.NotNil(t, resp.Recommendations, "a failed lookup suggests nothing")
+		assert.Empty(t, resp.Recommendations)
+	})
+
+	t.Run("Gift options need a gift order", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t, `"giftMessage":"Hi","hidePrices":true`))
+
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		var resp struct {
+			Errors map[string]string `json:"errors"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Contains(t, resp.Errors, "giftMessage")
+		assert.Contains(t, resp.Errors, "hidePrices")
+	})
+
+	t.Run("Gift message too long", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t, `"gift":true,"giftMessage":"`+strings.Repeat("a", models.MaxGiftMessageLength+1)+`"`))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+func TestCreateOrderWithSavedAddress(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+	productsUC := prodMocks.NewProductUC(t)
+	addressUC := addrMocks.NewAddressUC(t)
+
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promoMocks.NewPromotionUC(t),
+		productsUC, addressUC, newEstimator(t), newRates())
+
+	user := models.User{ID: uuid.New()}
+	newRequest := func(t *testing.T, shipping string) *http.Request {
+		body := `{"orderItems":[{"product":"` + uuid.NewString() + `","name":"Test Product","price":"100","quantity":1}],
+			"itemsPrice":"100","totalPrice":"100","paymentInfo":{"id":"pi_1","status":"succeeded"}` + shipping + `}`
+
+		req, err := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+		require.NoError(t, err)
+
+		return req.WithContext(context.WithValue(req.Context(), UserContextKey, &user))
+	}
+
+	t.Run("Order is shipped to the address", func(t *testing.T) {
+		addr := models.Address{ID: uuid.New(), UserID: user.ID, Label: "Home", Address: "1 Main St", City: "Accra",
+			PhoneNo: "0200000000", PostalCode: "00233", Country: "Ghana"}
+		addressUC.On("GetAddress", addr.ID, user.ID).Return(&addr, nil).Once()
+		priceItems(checkoutUC, money.Of(10000))
+		orderUC.On("CreateOrder", mock.Anything, mock.MatchedBy(func(ord models.Order) bool {
+			return ord.ShippingInfo == addr.Shipping()
+		})).Return(&models.Order{OrderID: uuid.New()}, nil).Once()
+		productsUC.On("GetRecommendations", mock.Anything, 4).Return([]models.Recommendation{}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t, `,"addressId":"`+addr.ID.String()+`"`))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Address of another user", func(t *testing.T) {
+		id := uuid.New()
+		addressUC.On("GetAddress", id, user.ID).Return(nil, addresses.ErrAddressNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t, `,"addressId":"`+id.String()+`"`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Shipping is required", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t, ""))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Address and shipping info together", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.CreateOrder(rr, newRequest(t, `,"addressId":"`+uuid.NewString()+`","shippingInfo":{"address":"1 Main St"}`))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
 	})
 }
 
 func TestGetSingleOrder(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	orderUC := mockOrder.NewOrderUC(t)
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
 
-	o := delivery.NewOrderHandlers(logger, orderUC)
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
 
 	t.Run("Order successfully retrieved", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/orders/id", nil)
@@ -154,13 +601,82 @@ func TestGetSingleOrder(t *testing.T) {
 
 		assert.Equal(t, want, got)
 	})
+
+	t.Run("Undelivered order has a delivery estimate", func(t *testing.T) {
+		id := uuid.New()
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/id", nil)
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+
+		orderUC.On("GetSingleOrder", id).Return(&models.Order{
+			ShippingInfo: models.Shipping{Country: "GH"},
+			OrderStatus:  "Processing",
+		}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		o.GetSingleOrder(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp models.OrderResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.NotNil(t, resp.EstimatedDelivery)
+		assert.Equal(t, "standard", resp.EstimatedDelivery.Method)
+		assert.Equal(t, 3, resp.EstimatedDelivery.MinDays)
+		assert.Equal(t, 5, resp.EstimatedDelivery.MaxDays)
+	})
+
+	t.Run("Order has its totals broken down", func(t *testing.T) {
+		id := uuid.New()
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/id", nil)
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+
+		orderUC.On("GetSingleOrder", id).Return(&models.Order{
+			Currency:      "USD",
+			OrderStatus:   models.OrderDelivered,
+			ItemPrice:     money.New(20000, "USD"),
+			TaxPrice:      money.New(1500, "USD"),
+			ShippingPrice: money.New(1000, "USD"),
+			CouponCode:    "SAVE10",
+			Discount:      money.New(2000, "USD"),
+			TotalPrice:    money.New(18000, "USD"),
+		}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		o.GetSingleOrder(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Order struct {
+				Totals models.OrderTotals `json:"totals"`
+			} `json:"order"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.OrderTotals{
+			Subtotal: money.New(20000, "USD"),
+			Taxes:    []models.TaxLine{{Rate: "7.5", Amount: money.New(1500, "USD")}},
+			Shipping: money.New(1000, "USD"),
+			Discounts: []models.DiscountLine{
+				{Kind: models.DiscountCoupon, Code: "SAVE10", Amount: money.New(2000, "USD")},
+				{Kind: models.DiscountStoreCredit, Amount: money.New(2500, "USD")},
+			},
+			GrandTotal: money.New(18000, "USD"),
+		}, resp.Order.Totals)
+	})
 }
 
 func TestGetUserOrders(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	orderUC := mockOrder.NewOrderUC(t)
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
 
-	o := delivery.NewOrderHandlers(logger, orderUC)
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
 
 	t.Run("Orders successfully retrieved", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/orders/user", nil)
@@ -188,8 +704,9 @@ func TestGetUserOrders(t *testing.T) {
 func TestGetAllOrders(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	orderUC := mockOrder.NewOrderUC(t)
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
 
-	o := delivery.NewOrderHandlers(logger, orderUC)
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
 
 	t.Run("All orders are successfully fetched", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/orders", nil)
@@ -197,7 +714,7 @@ func TestGetAllOrders(t *testing.T) {
 
 		rr := httptest.NewRecorder()
 
-		orderUC.On("GetAllOrders").Return([]*models.Order{}, nil)
+		orderUC.On("GetAllOrders").Return([]*models.Order{}, nil).Once()
 
 		o.GetAllOrders(rr, req)
 
@@ -206,13 +723,51 @@ func TestGetAllOrders(t *testing.T) {
 
 		assert.Equal(t, want, got)
 	})
+
+	t.Run("Total amount is in the shop currency", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/orders", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		orderUC.On("GetAllOrders").Return([]*models.Order{
+			{TotalPrice: money.Of(1000)},
+			{Currency: "EUR", TotalPrice: money.New(500, "EUR")},
+		}, nil).Once()
+
+		o.GetAllOrders(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp struct {
+			TotalAmount money.Money `json:"totalAmount"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, money.Of(2000), resp.TotalAmount)
+	})
+
+	t.Run("Internal failure returns an error id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/orders", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		orderUC.On("GetAllOrders").Return(nil, errors.New("connection refused")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+		o.GetAllOrders(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.NotEmpty(t, rr.Header().Get("X-Error-Id"))
+		assert.NotContains(t, rr.Body.String(), "connection refused")
+	})
 }
 
 func TestUpdateOrder(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	orderUC := mockOrder.NewOrderUC(t)
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
 
-	o := delivery.NewOrderHandlers(logger, orderUC)
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
 
 	t.Run("Order is successfully updated", func(t *testing.T) {
 		// Build multipart form data with the new status.
@@ -236,6 +791,7 @@ func TestUpdateOrder(t *testing.T) {
 
 		// Create a fake order that is currently 'Processing'
 		ord := models.Order{
+			UserID: uuid.New(),
 			OrderItems: []*models.Item{
 				{
 					Quantity:  5,
@@ -248,8 +804,8 @@ func TestUpdateOrder(t *testing.T) {
 		// Expect GetSingleOrder to be called with the order id.
 		orderUC.On("GetSingleOrder", id).Return(&ord, nil)
 
-		// Expect UpdateStock to be called for each item.
-		orderUC.On("UpdateStock", ord.OrderItems[0].ProductID, ord.OrderItems[0].Quantity).Return(nil)
+		// Delivering the order captures its payment if it was only authorized.
+		orderUC.On("CapturePayment", &ord).Return(nil).Once()
 
 		// For UpdateOrder, we expect that the order status is updated to "Delivered" and DeliveredAt is set.
 		orderUC.
@@ -265,13 +821,167 @@ func TestUpdateOrder(t *testing.T) {
 		// Assert that the response code is 200.
 		assert.Equal(t, http.StatusOK, rr.Code)
 	})
+
+	newRequest := func(id uuid.UUID, status string) *http.Request {
+		payload, ct, err := utils.CreateMultipartForm(url.Values{"status": {status}})
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPatch, "/order/update", payload)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+	}
+
+	t.Run("Failed capture keeps the status", func(t *testing.T) {
+		id := uuid.New()
+		ord := models.Order{UserID: uuid.New(), OrderStatus: models.OrderProcessing,
+			PaymentInfo: models.Payment{ID: "pi_1", Status: models.PaymentRequiresCapture}}
+
+		orderUC.On("GetSingleOrder", id).Return(&ord, nil).Once()
+		orderUC.On("CapturePayment", &ord).Return(fmt.Errorf("%w: card declined", orders.ErrCaptureFailed)).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.UpdateOrder(rr, newRequest(id, models.OrderShipped))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Cancelled order is not shipped", func(t *testing.T) {
+		id := uuid.New()
+		ord := models.Order{OrderID: id, OrderStatus: models.OrderCancelled}
+
+		orderUC.On("GetSingleOrder", id).Return(&ord, nil).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.UpdateOrder(rr, newRequest(id, models.OrderShipped))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Status in any case", func(t *testing.T) {
+		id := uuid.New()
+		ord := models.Order{UserID: uuid.New(), OrderStatus: models.OrderProcessing}
+
+		orderUC.On("GetSingleOrder", id).Return(&ord, nil).Once()
+		orderUC.On("CapturePayment", &ord).Return(nil).Once()
+		orderUC.On("UpdateOrder", mock.MatchedBy(func(updated models.Order) bool {
+			return updated.OrderStatus == models.OrderShipped
+		})).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		o.UpdateOrder(rr, newRequest(id, " shipped "))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	for _, tt := range []struct {
+		name, from, to, allowed string
+	}{
+		{"Unknown status", models.OrderProcessing, "Shiped", "Shipped, Delivered"},
+		{"Status cannot go back", models.OrderShipped, models.OrderProcessing, "Delivered"},
+		{"Order cannot be cancelled", models.OrderProcessing, models.OrderCancelled, "Shipped, Delivered"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			orderUC.On("GetSingleOrder", id).Return(&models.Order{OrderStatus: tt.from}, nil).Once()
+			logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+			rr := httptest.NewRecorder()
+			o.UpdateOrder(rr, newRequest(id, tt.to))
+
+			require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+			var res struct {
+				Errors map[string]string `json:"errors"`
+			}
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+			assert.Equal(t, "status must be one of: "+tt.allowed, res.Errors["status"])
+		})
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, mockCheckout.NewCheckoutUC(t), promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
+
+	user := &models.User{ID: uuid.New()}
+	id := uuid.New()
+	call := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/"+id.String()+"/cancel", bytes.NewBufferString(body))
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+		rr := httptest.NewRecorder()
+		o.CancelOrder(rr, req.WithContext(context.WithValue(ctx, delivery.UserContextKey, user)))
+		return rr
+	}
+
+	t.Run("Order is cancelled", func(t *testing.T) {
+		orderUC.On("CancelOrder", id, user.ID, "ordered twice").
+			Return(&models.Order{OrderID: id, OrderStatus: models.OrderCancelled,
+				PaymentInfo: models.Payment{Status: models.PaymentRefundPending}}, nil).Once()
+
+		rr := call(`{"reason": " ordered twice "}`)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res models.OrderResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, models.OrderCancelled, res.Order.OrderStatus)
+		assert.Equal(t, models.PaymentRefundPending, res.Order.PaymentInfo.Status)
+	})
+
+	t.Run("Order is cancelled without a reason", func(t *testing.T) {
+		orderUC.On("CancelOrder", id, user.ID, "").Return(&models.Order{OrderID: id}, nil).Once()
+
+		rr := call("")
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Shipped order", func(t *testing.T) {
+		orderUC.On("CancelOrder", id, user.ID, "").Return(nil, orders.ErrOrderShipped).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call("")
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Cancelled order", func(t *testing.T) {
+		orderUC.On("CancelOrder", id, user.ID, "").Return(nil, orders.ErrOrderCancelled).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call("")
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Order of another user", func(t *testing.T) {
+		orderUC.On("CancelOrder", id, user.ID, "").Return(nil, orders.ErrOrderNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call("")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Reason too long", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call(`{"reason": "` + strings.Repeat("a", 501) + `"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
 }
 
 func TestDeleteOrder(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	orderUC := mockOrder.NewOrderUC(t)
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
 
-	o := delivery.NewOrderHandlers(logger, orderUC)
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
 
 	t.Run("Order is successfully deleted", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "order/delete/id", nil)
@@ -295,4 +1005,406 @@ func TestDeleteOrder(t *testing.T) {
 
 		assert.Equal(t, want, got)
 	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		id := uuid.New()
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		req := httptest.NewRequest(http.MethodDelete, "/"+id.String(), nil)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+		rr := httptest.NewRecorder()
+
+		orderUC.On("DeleteOrder", id).Return(orders.ErrOrderNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		o.DeleteOrder(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestAdjustStock(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, mockCheckout.NewCheckoutUC(t), promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
+
+	admin := &models.User{ID: uuid.New(), Role: "admin"}
+	productId, variantId := uuid.New(), uuid.New()
+	call := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/inventory/restock", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		o.AdjustStock(rr, req.WithContext(context.WithValue(req.Context(), delivery.UserContextKey, admin)))
+		return rr
+	}
+
+	t.Run("Stock is adjusted", func(t *testing.T) {
+		orderUC.On("AdjustStock", models.StockAdjustment{ProductID: productId,
+			VariantID: uuid.NullUUID{UUID: variantId, Valid: true}, Quantity: -2,
+			UserID: uuid.NullUUID{UUID: admin.ID, Valid: true}, Note: "damaged"}).Return(nil).Once()
+		logger.On("Infof", mock.Anything, productId, -2, admin.ID).Once()
+
+		rr := call(fmt.Sprintf(`{"productId": %q, "variantId": %q, "quantity": -2, "note": " damaged "}`, productId, variantId))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		orderUC.On("AdjustStock", mock.Anything).Return(orders.ErrStockItemNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call(fmt.Sprintf(`{"productId": %q, "quantity": 5}`, productId))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Quantity is required", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call(fmt.Sprintf(`{"productId": %q, "quantity": 0}`, productId))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+func TestGetStockAdjustments(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, mockCheckout.NewCheckoutUC(t), promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
+
+	productId := uuid.New()
+	call := func(id, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/inventory/"+id+"/adjustments"+query, nil)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("productId", id)
+		rr := httptest.NewRecorder()
+		o.GetStockAdjustments(rr, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)))
+		return rr
+	}
+
+	t.Run("Adjustments are listed", func(t *testing.T) {
+		orderUC.On("GetStockAdjustments", productId, 20).
+			Return([]models.StockAdjustment{{ProductID: productId, Quantity: -1, Reason: models.StockShipped}}, nil).Once()
+
+		rr := call(productId.String(), "?limit=20")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Adjustments []models.StockAdjustment `json:"adjustments"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		require.Len(t, res.Adjustments, 1)
+		assert.Equal(t, models.StockShipped, res.Adjustments[0].Reason)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call(productId.String(), "?limit=none")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid product id", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call("abc", "")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestExportOrders(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, mockCheckout.NewCheckoutUC(t), promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
+
+	from, to := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Anonymized export", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/export?from=2026-09-01&to=2026-09-30&anonymize=true", nil)
+		rr := httptest.NewRecorder()
+
+		orderUC.On("ExportOrders", mock.Anything, from, to, true).Run(func(args mock.Arguments) {
+			_, _ = args.Get(0).(io.Writer).Write([]byte("order_id,user_id\n"))
+		}).Return(nil).Once()
+
+		o.ExportOrders(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "orders-anonymized.csv")
+		assert.Equal(t, "order_id,user_id\n", rr.Body.String())
+	})
+
+	t.Run("Anonymization not enabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/export?from=2026-09-01&to=2026-09-30&anonymize=1", nil)
+		rr := httptest.NewRecorder()
+
+		orderUC.On("ExportOrders", mock.Anything, from, to, true).Return(orders.ErrAnonymizationDisabled).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		o.ExportOrders(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Empty(t, rr.Header().Get("Content-Disposition"))
+	})
+
+	for name, tc := range map[string]struct {
+		query string
+		args  []interface{}
+	}{
+		"Invalid date":      {"from=09/01/2026", []interface{}{mock.Anything, mock.Anything, mock.Anything}},
+		"Reversed period":   {"from=2026-10-01&to=2026-09-01", []interface{}{mock.Anything, mock.Anything, mock.Anything}},
+		"Invalid anonymize": {"anonymize=maybe", []interface{}{mock.Anything, mock.Anything}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/export?"+tc.query, nil)
+			rr := httptest.NewRecorder()
+
+			logger.On("Errorf", tc.args...).Once()
+
+			o.ExportOrders(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
+func TestGetPickList(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
+
+	a, b := uuid.New(), uuid.New()
+	list := &models.PickList{
+		Orders:     []uuid.UUID{a, b},
+		Lines:      []models.PickLine{{PackingItem: models.PackingItem{ProductID: uuid.New(), Name: "Camera", Quantity: 3}, Orders: 2}},
+		TotalUnits: 3,
+	}
+
+	t.Run("Pick list as json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/picklist?orders="+a.String()+","+b.String(), nil)
+		rr := httptest.NewRecorder()
+
+		orderUC.On("GetPickList", []uuid.UUID{a, b}).Return(list, nil).Once()
+
+		o.GetPickList(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			PickList models.PickList `json:"pickList"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		assert.Equal(t, 3, res.PickList.TotalUnits)
+	})
+
+	t.Run("Pick list as pdf", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/picklist?format=pdf&orders="+a.String()+","+b.String(), nil)
+		rr := httptest.NewRecorder()
+
+		orderUC.On("GetPickList", []uuid.UUID{a, b}).Return(list, nil).Once()
+
+		o.GetPickList(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/pdf", rr.Header().Get("Content-Type"))
+		assert.True(t, bytes.HasPrefix(rr.Body.Bytes(), []byte("%PDF-")))
+	})
+
+	t.Run("Missing order", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/picklist?orders="+a.String(), nil)
+		rr := httptest.NewRecorder()
+
+		orderUC.On("GetPickList", []uuid.UUID{a}).Return(nil, orders.ErrOrderNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		o.GetPickList(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/picklist?format=csv", nil)
+		rr := httptest.NewRecorder()
+
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		o.GetPickList(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestGetInvoice(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, mockCheckout.NewCheckoutUC(t), promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
+
+	user := &models.User{ID: uuid.New()}
+	id := uuid.New()
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/"+id.String()+"/invoice", nil)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+		return req.WithContext(context.WithValue(ctx, delivery.UserContextKey, user))
+	}
+	order := &models.Order{OrderID: id, UserID: user.ID}
+	doc := []byte("%PDF-1.4\ninvoice")
+
+	t.Run("Invoice is downloaded", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		orderUC.On("GetInvoice", id, user).Return(order, doc, nil).Once()
+		orderUC.On("ArchiveInvoice", mock.Anything, order, doc).Return(nil).Once()
+
+		o.GetInvoice(rr, newRequest())
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/pdf", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "invoice-"+id.String()+".pdf")
+		assert.Equal(t, doc, rr.Body.Bytes())
+	})
+
+	t.Run("Invoice is downloaded when archiving fails", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		orderUC.On("GetInvoice", id, user).Return(order, doc, nil).Once()
+		orderUC.On("ArchiveInvoice", mock.Anything, order, doc).Return(errors.New("storage down")).Once()
+		logger.On("Errorf", mock.Anything, id, mock.Anything).Once()
+
+		o.GetInvoice(rr, newRequest())
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, doc, rr.Body.Bytes())
+	})
+
+	t.Run("Order of another user", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		orderUC.On("GetInvoice", id, user).Return(nil, nil, orders.ErrOrderNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		o.GetInvoice(rr, newRequest())
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestGetPackingSlip(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, checkoutUC, promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
+
+	id := uuid.New()
+	newRequest := func(target string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+	}
+
+	t.Run("Packing slip as pdf", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		orderUC.On("GetPackingSlip", id).Return(&models.PackingSlip{
+			OrderID: id,
+			Items:   []models.PackingItem{{Name: "Camera", Quantity: 1}},
+		}, nil).Once()
+
+		o.GetPackingSlip(rr, newRequest("/admin/order/id/packingslip?format=pdf"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, bytes.HasPrefix(rr.Body.Bytes(), []byte("%PDF-")))
+	})
+
+	t.Run("Gift slip carries the message and no prices", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		orderUC.On("GetPackingSlip", id).Return(&models.PackingSlip{
+			OrderID:     id,
+			Items:       []models.PackingItem{{Name: "Camera", Quantity: 1}},
+			Gift:        true,
+			GiftMessage: "Happy birthday!",
+		}, nil).Once()
+
+		o.GetPackingSlip(rr, newRequest("/admin/order/id/packingslip?format=pdf"))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Happy birthday!")
+		assert.NotContains(t, rr.Body.String(), "Total")
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		orderUC.On("GetPackingSlip", id).Return(nil, sql.ErrNoRows).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		o.GetPackingSlip(rr, newRequest("/admin/order/id/packingslip"))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestGetOrderEvents(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	orderUC := mockOrder.NewOrderUC(t)
+
+	o := delivery.NewOrderHandlers(logger, orderUC, mockCheckout.NewCheckoutUC(t), promoMocks.NewPromotionUC(t), prodMocks.NewProductUC(t), addrMocks.NewAddressUC(t), newEstimator(t), newRates())
+
+	user := &models.User{ID: uuid.New()}
+	id := uuid.New()
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/"+id.String()+"/events", nil)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+		return req.WithContext(context.WithValue(ctx, delivery.UserContextKey, user))
+	}
+
+	t.Run("Status changes are streamed until the order is delivered", func(t *testing.T) {
+		hub := realtime.NewHub()
+		sub := hub.Subscribe(id.String())
+		orderUC.On("SubscribeOrder", id, user).
+			Return(&models.Order{OrderID: id, UserID: user.ID, OrderStatus: models.OrderShipped}, sub, nil).Once()
+
+		hub.Publish(id.String(), realtime.Event{
+			Name: models.EventOrderStatusChanged,
+			Data: models.OrderStatusChange{OrderID: id, Status: models.OrderDelivered},
+		})
+
+		rr := httptest.NewRecorder()
+		o.GetOrderEvents(rr, newRequest())
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+		body := rr.Body.String()
+		assert.Equal(t, 2, strings.Count(body, "event: status\n"))
+		assert.Less(t, strings.Index(body, `"status":"Shipped"`), strings.Index(body, `"status":"Delivered"`))
+	})
+
+	t.Run("Stream of a delivered order ends at once", func(t *testing.T) {
+		sub := realtime.NewHub().Subscribe(id.String())
+		orderUC.On("SubscribeOrder", id, user).
+			Return(&models.Order{OrderID: id, UserID: user.ID, OrderStatus: models.OrderDelivered}, sub, nil).Once()
+
+		rr := httptest.NewRecorder()
+		o.GetOrderEvents(rr, newRequest())
+
+		assert.Equal(t, 1, strings.Count(rr.Body.String(), "event: status\n"))
+	})
+
+	t.Run("Order of another user", func(t *testing.T) {
+		orderUC.On("SubscribeOrder", id, user).Return(nil, nil, orders.ErrOrderNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		o.GetOrderEvents(rr, newRequest())
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
 }
@@ -0,0 +1,98 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/orders"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// AdjustStock corrects the stock of a product, or of one of its variants, by a signed
+// quantity and logs it as a restock by the admin (admin).
+// Endpoint: POST /api/v1/orders/admin/inventory/restock
+// Expects JSON: {"productId": <uuid>, "variantId": <uuid, optional>, "quantity": <non-zero int>,
+// "note": <why, optional>}.
+func (h *OrderHandlers) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	admin, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	var req stockRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	adj := models.StockAdjustment{
+		ProductID: uuid.MustParse(req.ProductID),
+		Quantity:  req.Quantity,
+		UserID:    uuid.NullUUID{UUID: admin.ID, Valid: true},
+		Note:      strings.TrimSpace(req.Note),
+	}
+	if req.VariantID != "" {
+		adj.VariantID = uuid.NullUUID{UUID: uuid.MustParse(req.VariantID), Valid: true}
+	}
+
+	if err := h.ordersUC.AdjustStock(adj); err != nil {
+		if errors.Is(err, orders.ErrStockItemNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error adjusting stock: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error adjusting stock: %w", err))
+		return
+	}
+
+	h.logger.Infof("stock of %s adjusted by %d by %s", adj.ProductID, adj.Quantity, admin.ID)
+
+	_ = utils.WriteJSON(w, http.StatusOK, models.Response{Success: true, Message: "stock adjusted"})
+}
+
+// GetStockAdjustments returns the inventory log of a product and its variants, newest
+// first: the units orders took as they shipped, gave back when they were deleted, and
+// the restocks of admins (admin).
+// Endpoint: GET /api/v1/orders/admin/inventory/{productId}/adjustments?limit={n}
+func (h *OrderHandlers) GetStockAdjustments(w http.ResponseWriter, r *http.Request) {
+	productId, err := uuid.Parse(chi.URLParam(r, "productId"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	var limit int
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			_ = utils.BadRequest(w, r, errors.New("limit must be a positive number"))
+			h.logger.Errorf("error parsing limit: %v", l)
+			return
+		}
+		limit = n
+	}
+
+	adjs, err := h.ordersUC.GetStockAdjustments(productId, limit)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error fetching stock adjustments: %w", err))
+		return
+	}
+
+	jr := struct {
+		Success     bool                     `json:"success"`
+		Adjustments []models.StockAdjustment `json:"adjustments"`
+	}{
+		Success:     true,
+		Adjustments: adjs,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
@@ -0,0 +1,114 @@
+package delivery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// orderRequest is the body of CreateOrder.
+type orderRequest struct {
+	OrderItems      []orderItemRequest `json:"orderItems" validate:"min=1,dive"`
+	ShippingInfo    *shippingRequest   `json:"shippingInfo"`
+	AddressID       string             `json:"addressId" validate:"omitempty,uuid"`
+	ItemsPrice      money.Money        `json:"itemsPrice"`
+	ShippingPrice   money.Money        `json:"shippingPrice"`
+	TaxPrice        money.Money        `json:"taxPrice"`
+	TotalPrice      money.Money        `json:"totalPrice"`
+	PaymentInfo     *paymentRequest    `json:"paymentInfo" validate:"required"`
+	CheckoutSession string             `json:"checkoutSession" validate:"omitempty,uuid"`
+	CouponCode      string             `json:"couponCode"`
+	Gift            bool               `json:"gift"`
+	GiftMessage     string             `json:"giftMessage"`
+	HidePrices      bool               `json:"hidePrices"`
+}
+
+// orderItemRequest is an item of an orderRequest.
+type orderItemRequest struct {
+	Product  string      `json:"product" validate:"uuid"`
+	Variant  string      `json:"variant" validate:"omitempty,uuid"`
+	Name     string      `json:"name"`
+	Price    money.Money `json:"price"`
+	Image    string      `json:"image"`
+	Stock    int         `json:"stock"`
+	Quantity int         `json:"quantity" validate:"min=1"`
+}
+
+// shippingRequest is where an orderRequest is shipped.
+type shippingRequest struct {
+	Address    string `json:"address"`
+	City       string `json:"city"`
+	PhoneNo    string `json:"phoneNo"`
+	PostalCode string `json:"postalCode"`
+	Country    string `json:"country"`
+}
+
+// paymentRequest is the payment of an orderRequest. Its status is not taken from the
+// client; only the payment provider reports it.
+type paymentRequest struct {
+	ID       string `json:"id"`
+	Provider string `json:"provider"`
+}
+
+// currency returns the currency of the submitted amounts, the shop currency if they
+// do not name one.
+func (req *orderRequest) currency() string {
+	if req.TotalPrice.Currency == "" {
+		return money.DefaultCurrency
+	}
+	return req.TotalPrice.Currency
+}
+
+// provider returns the payment provider, stripe when it is not given.
+func (req *orderRequest) provider() string {
+	if req.PaymentInfo == nil || req.PaymentInfo.Provider == "" {
+		return models.PaymentStripe
+	}
+	return strings.ToLower(req.PaymentInfo.Provider)
+}
+
+func (req *orderRequest) Validate(v *validator.Validator) {
+	amounts := []money.Money{req.ItemsPrice, req.ShippingPrice, req.TaxPrice}
+	for _, i := range req.OrderItems {
+		amounts = append(amounts, i.Price)
+	}
+
+	giftMessage := strings.TrimSpace(req.GiftMessage)
+	provider := req.provider()
+
+	v.CheckCode(req.ShippingInfo != nil || req.AddressID != "", "shippingInfo", validator.CodeRequired,
+		"shippingInfo or addressId must be provided")
+	v.Check(req.ShippingInfo == nil || req.AddressID == "", "addressId", "addressId cannot be combined with shippingInfo")
+	v.Check(inCurrency(req.currency(), amounts...), "totalPrice", "amounts must all be in the same currency")
+	v.CheckCode(!req.ShippingPrice.IsNegative(), "shippingPrice", validator.CodeTooSmall,
+		"shipping price must not be negative")
+	v.CheckCode(!req.TaxPrice.IsNegative(), "taxPrice", validator.CodeTooSmall, "tax price must not be negative")
+	v.CheckCode(provider == models.PaymentStripe || provider == models.PaymentPayPal, "paymentInfo",
+		validator.CodeOneOf, "payment provider must be stripe or paypal")
+	v.CheckCode(len([]rune(giftMessage)) <= models.MaxGiftMessageLength, "giftMessage", validator.CodeTooLong,
+		fmt.Sprintf("gift message must not be more than %d characters", models.MaxGiftMessageLength))
+	v.Check(req.Gift || giftMessage == "", "giftMessage", "gift message is only allowed on gift orders")
+	v.Check(req.Gift || !req.HidePrices, "hidePrices", "prices can only be hidden on gift orders")
+}
+
+// cancelRequest is the optional body of CancelOrder.
+type cancelRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// stockRequest is the body of AdjustStock. Quantity is signed and cannot be zero.
+type stockRequest struct {
+	ProductID string `json:"productId" validate:"required,uuid"`
+	VariantID string `json:"variantId" validate:"omitempty,uuid"`
+	Quantity  int    `json:"quantity" validate:"required"`
+	Note      string `json:"note" validate:"max=500"`
+}
+
+// statusRequest is the body of UpdateOrder. The statuses the order can move to are
+// checked by the handler, which knows its current status.
+type statusRequest struct {
+	Status string `json:"status" validate:"required"`
+}
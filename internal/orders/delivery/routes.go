@@ -2,6 +2,9 @@ package delivery
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/audit"
+	"github.com/jofosuware/go/shopit/pkg/loadshed"
 	"github.com/jofosuware/go/shopit/pkg/utils"
 	"net/http"
 )
@@ -13,10 +16,18 @@ func (h *OrderHandlers) OrderRouter() http.Handler {
 
 	mux.Post("/new", h.CreateOrder)
 	mux.Get("/{id}", h.GetSingleOrder)
+	mux.Get("/{id}/invoice", h.GetInvoice)
+	mux.Get("/{id}/events", h.GetOrderEvents)
+	mux.Post("/{id}/cancel", h.CancelOrder)
 	mux.Get("/me", h.GetUserOrders)
-	mux.Get("/admin/orders", h.GetAllOrders)
-	mux.Put("/admin/order/{id}", h.UpdateOrder)
-	mux.Delete("/admin/order/{id}", h.DeleteOrder)
+	mux.With(utils.IsAdmin).Get("/admin/orders", h.GetAllOrders)
+	mux.With(utils.IsAdmin).Put("/admin/order/{id}", h.UpdateOrder)
+	mux.With(utils.IsAdmin).Delete("/admin/order/{id}", h.DeleteOrder)
+	mux.With(utils.IsAdmin).Get("/admin/picklist", h.GetPickList)
+	mux.With(utils.IsAdmin).Get("/admin/order/{id}/packingslip", h.GetPackingSlip)
+	mux.With(utils.IsAdmin, loadshed.LowPriority, audit.Export(models.ExportOrders)).Get("/admin/export", h.ExportOrders)
+	mux.With(utils.IsAdmin).Post("/admin/inventory/restock", h.AdjustStock)
+	mux.With(utils.IsAdmin).Get("/admin/inventory/{productId}/adjustments", h.GetStockAdjustments)
 
 	return mux
 }
@@ -0,0 +1,38 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxPickListOrders is the most orders a single pick list can cover.
+const MaxPickListOrders = 100
+
+var (
+	// ErrOrderNotFound is returned when a selected order does not exist.
+	ErrOrderNotFound = errors.New("order not found")
+
+	// ErrOrderPaid is returned when paying an order whose payment already went through.
+	ErrOrderPaid = errors.New("this order is already paid")
+
+	// ErrOrderCancelled is returned when paying or cancelling a cancelled order.
+	ErrOrderCancelled = errors.New("this order is cancelled")
+
+	// ErrOrderShipped is returned when cancelling an order that has shipped or been delivered.
+	ErrOrderShipped = errors.New("this order has shipped and can no longer be cancelled")
+
+	// ErrStockItemNotFound is returned when adjusting the stock of a product, or variant, that does not exist.
+	ErrStockItemNotFound = errors.New("product or variant not found")
+
+	// ErrInvalidSelection is returned when no orders, or too many, are selected.
+	ErrInvalidSelection = fmt.Errorf("select between 1 and %d orders", MaxPickListOrders)
+
+	// ErrPaymentVoided is returned when shipping an order whose payment was voided.
+	ErrPaymentVoided = errors.New("the payment of this order was voided")
+
+	// ErrAnonymizationDisabled is returned when exporting anonymized orders without a pseudonym key.
+	ErrAnonymizationDisabled = errors.New("anonymized exports are not enabled: set analytics.pseudonymKey")
+
+	// ErrCaptureFailed is returned when the authorized payment of an order cannot be captured.
+	ErrCaptureFailed = errors.New("the payment of this order could not be captured")
+)
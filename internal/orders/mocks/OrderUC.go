@@ -3,9 +3,17 @@
 package mocks
 
 import (
-	models "github.com/jofosuware/go/shopit/internal/models"
+	context "context"
+	io "io"
+
 	mock "github.com/stretchr/testify/mock"
 
+	models "github.com/jofosuware/go/shopit/internal/models"
+
+	realtime "github.com/jofosuware/go/shopit/pkg/realtime"
+
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -14,29 +22,131 @@ type OrderUC struct {
 	mock.Mock
 }
 
-// CreateOrder provides a mock function with given fields: order
-func (_m *OrderUC) CreateOrder(order models.Order) (*models.Order, error) {
+// AdjustStock provides a mock function with given fields: adj
+func (_m *OrderUC) AdjustStock(adj models.StockAdjustment) error {
+	ret := _m.Called(adj)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AdjustStock")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.StockAdjustment) error); ok {
+		r0 = rf(adj)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ArchiveInvoice provides a mock function with given fields: ctx, order, doc
+func (_m *OrderUC) ArchiveInvoice(ctx context.Context, order *models.Order, doc []byte) error {
+	ret := _m.Called(ctx, order, doc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchiveInvoice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Order, []byte) error); ok {
+		r0 = rf(ctx, order, doc)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AttachPayment provides a mock function with given fields: ctx, orderId, p
+func (_m *OrderUC) AttachPayment(ctx context.Context, orderId uuid.UUID, p models.Payment) error {
+	ret := _m.Called(ctx, orderId, p)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AttachPayment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, models.Payment) error); ok {
+		r0 = rf(ctx, orderId, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CancelOrder provides a mock function with given fields: id, userID, reason
+func (_m *OrderUC) CancelOrder(id uuid.UUID, userID uuid.UUID, reason string) (*models.Order, error) {
+	ret := _m.Called(id, userID, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelOrder")
+	}
+
+	var r0 *models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, string) (*models.Order, error)); ok {
+		return rf(id, userID, reason)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, string) *models.Order); ok {
+		r0 = rf(id, userID, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = rf(id, userID, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CapturePayment provides a mock function with given fields: order
+func (_m *OrderUC) CapturePayment(order *models.Order) error {
 	ret := _m.Called(order)
 
+	if len(ret) == 0 {
+		panic("no return value specified for CapturePayment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Order) error); ok {
+		r0 = rf(order)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateOrder provides a mock function with given fields: ctx, order
+func (_m *OrderUC) CreateOrder(ctx context.Context, order models.Order) (*models.Order, error) {
+	ret := _m.Called(ctx, order)
+
 	if len(ret) == 0 {
 		panic("no return value specified for CreateOrder")
 	}
 
 	var r0 *models.Order
 	var r1 error
-	if rf, ok := ret.Get(0).(func(models.Order) (*models.Order, error)); ok {
-		return rf(order)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Order) (*models.Order, error)); ok {
+		return rf(ctx, order)
 	}
-	if rf, ok := ret.Get(0).(func(models.Order) *models.Order); ok {
-		r0 = rf(order)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Order) *models.Order); ok {
+		r0 = rf(ctx, order)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Order)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(models.Order) error); ok {
-		r1 = rf(order)
+	if rf, ok := ret.Get(1).(func(context.Context, models.Order) error); ok {
+		r1 = rf(ctx, order)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -62,6 +172,24 @@ func (_m *OrderUC) DeleteOrder(orderId uuid.UUID) error {
 	return r0
 }
 
+// ExportOrders provides a mock function with given fields: w, from, to, anonymize
+func (_m *OrderUC) ExportOrders(w io.Writer, from time.Time, to time.Time, anonymize bool) error {
+	ret := _m.Called(w, from, to, anonymize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportOrders")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, time.Time, time.Time, bool) error); ok {
+		r0 = rf(w, from, to, anonymize)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAllOrders provides a mock function with given fields:
 func (_m *OrderUC) GetAllOrders() ([]*models.Order, error) {
 	ret := _m.Called()
@@ -92,6 +220,135 @@ func (_m *OrderUC) GetAllOrders() ([]*models.Order, error) {
 	return r0, r1
 }
 
+// GetInvoice provides a mock function with given fields: id, user
+func (_m *OrderUC) GetInvoice(id uuid.UUID, user *models.User) (*models.Order, []byte, error) {
+	ret := _m.Called(id, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvoice")
+	}
+
+	var r0 *models.Order
+	var r1 []byte
+	var r2 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.User) (*models.Order, []byte, error)); ok {
+		return rf(id, user)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.User) *models.Order); ok {
+		r0 = rf(id, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, *models.User) []byte); ok {
+		r1 = rf(id, user)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(uuid.UUID, *models.User) error); ok {
+		r2 = rf(id, user)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetPackingSlip provides a mock function with given fields: orderId
+func (_m *OrderUC) GetPackingSlip(orderId uuid.UUID) (*models.PackingSlip, error) {
+	ret := _m.Called(orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPackingSlip")
+	}
+
+	var r0 *models.PackingSlip
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.PackingSlip, error)); ok {
+		return rf(orderId)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.PackingSlip); ok {
+		r0 = rf(orderId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PackingSlip)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPayableOrder provides a mock function with given fields: ctx, id, userID
+func (_m *OrderUC) GetPayableOrder(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Order, error) {
+	ret := _m.Called(ctx, id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPayableOrder")
+	}
+
+	var r0 *models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (*models.Order, error)); ok {
+		return rf(ctx, id, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) *models.Order); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPickList provides a mock function with given fields: orderIds
+func (_m *OrderUC) GetPickList(orderIds []uuid.UUID) (*models.PickList, error) {
+	ret := _m.Called(orderIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPickList")
+	}
+
+	var r0 *models.PickList
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) (*models.PickList, error)); ok {
+		return rf(orderIds)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) *models.PickList); ok {
+		r0 = rf(orderIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PickList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(orderIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSingleOrder provides a mock function with given fields: id
 func (_m *OrderUC) GetSingleOrder(id uuid.UUID) (*models.Order, error) {
 	ret := _m.Called(id)
@@ -122,6 +379,36 @@ func (_m *OrderUC) GetSingleOrder(id uuid.UUID) (*models.Order, error) {
 	return r0, r1
 }
 
+// GetStockAdjustments provides a mock function with given fields: productId, limit
+func (_m *OrderUC) GetStockAdjustments(productId uuid.UUID, limit int) ([]models.StockAdjustment, error) {
+	ret := _m.Called(productId, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStockAdjustments")
+	}
+
+	var r0 []models.StockAdjustment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) ([]models.StockAdjustment, error)); ok {
+		return rf(productId, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) []models.StockAdjustment); ok {
+		r0 = rf(productId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.StockAdjustment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(productId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserOrders provides a mock function with given fields: userId
 func (_m *OrderUC) GetUserOrders(userId uuid.UUID) ([]*models.Order, error) {
 	ret := _m.Called(userId)
@@ -152,6 +439,45 @@ func (_m *OrderUC) GetUserOrders(userId uuid.UUID) ([]*models.Order, error) {
 	return r0, r1
 }
 
+// SubscribeOrder provides a mock function with given fields: id, user
+func (_m *OrderUC) SubscribeOrder(id uuid.UUID, user *models.User) (*models.Order, *realtime.Subscription, error) {
+	ret := _m.Called(id, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubscribeOrder")
+	}
+
+	var r0 *models.Order
+	var r1 *realtime.Subscription
+	var r2 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.User) (*models.Order, *realtime.Subscription, error)); ok {
+		return rf(id, user)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.User) *models.Order); ok {
+		r0 = rf(id, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, *models.User) *realtime.Subscription); ok {
+		r1 = rf(id, user)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*realtime.Subscription)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(uuid.UUID, *models.User) error); ok {
+		r2 = rf(id, user)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // UpdateOrder provides a mock function with given fields: order
 func (_m *OrderUC) UpdateOrder(order models.Order) error {
 	ret := _m.Called(order)
@@ -170,17 +496,17 @@ func (_m *OrderUC) UpdateOrder(order models.Order) error {
 	return r0
 }
 
-// UpdateStock provides a mock function with given fields: productId, quantity
-func (_m *OrderUC) UpdateStock(productId uuid.UUID, quantity int) error {
-	ret := _m.Called(productId, quantity)
+// UpdatePaymentStatus provides a mock function with given fields: ctx, provider, id, status
+func (_m *OrderUC) UpdatePaymentStatus(ctx context.Context, provider string, id string, status string) error {
+	ret := _m.Called(ctx, provider, id, status)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateStock")
+		panic("no return value specified for UpdatePaymentStatus")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID, int) error); ok {
-		r0 = rf(productId, quantity)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, provider, id, status)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -188,6 +514,34 @@ func (_m *OrderUC) UpdateStock(productId uuid.UUID, quantity int) error {
 	return r0
 }
 
+// VoidUncapturedPayments provides a mock function with given fields: ctx
+func (_m *OrderUC) VoidUncapturedPayments(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VoidUncapturedPayments")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewOrderUC creates a new instance of OrderUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewOrderUC(t interface {
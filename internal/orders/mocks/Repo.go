@@ -3,9 +3,13 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/jofosuware/go/shopit/internal/models"
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -14,17 +18,83 @@ type Repo struct {
 	mock.Mock
 }
 
-// DeleteOrderById provides a mock function with given fields: orderId
-func (_m *Repo) DeleteOrderById(orderId uuid.UUID) error {
-	ret := _m.Called(orderId)
+// AdjustStock provides a mock function with given fields: adjs
+func (_m *Repo) AdjustStock(adjs []models.StockAdjustment) error {
+	ret := _m.Called(adjs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AdjustStock")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]models.StockAdjustment) error); ok {
+		r0 = rf(adjs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CancelOrder provides a mock function with given fields: c, paymentStatus
+func (_m *Repo) CancelOrder(c models.OrderCancellation, paymentStatus string) error {
+	ret := _m.Called(c, paymentStatus)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.OrderCancellation, string) error); ok {
+		r0 = rf(c, paymentStatus)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateOrder provides a mock function with given fields: ctx, order
+func (_m *Repo) CreateOrder(ctx context.Context, order models.Order) (*models.Order, error) {
+	ret := _m.Called(ctx, order)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOrder")
+	}
+
+	var r0 *models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Order) (*models.Order, error)); ok {
+		return rf(ctx, order)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Order) *models.Order); ok {
+		r0 = rf(ctx, order)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Order) error); ok {
+		r1 = rf(ctx, order)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteOrderById provides a mock function with given fields: orderId, adjs
+func (_m *Repo) DeleteOrderById(orderId uuid.UUID, adjs []models.StockAdjustment) error {
+	ret := _m.Called(orderId, adjs)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteOrderById")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
-		r0 = rf(orderId)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, []models.StockAdjustment) error); ok {
+		r0 = rf(orderId, adjs)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -152,6 +222,36 @@ func (_m *Repo) FetchAllShipping() ([]*models.Shipping, error) {
 	return r0, r1
 }
 
+// FetchInvoice provides a mock function with given fields: orderId
+func (_m *Repo) FetchInvoice(orderId uuid.UUID) (*models.Invoice, error) {
+	ret := _m.Called(orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchInvoice")
+	}
+
+	var r0 *models.Invoice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Invoice, error)); ok {
+		return rf(orderId)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Invoice); ok {
+		r0 = rf(orderId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Invoice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FetchItemsById provides a mock function with given fields: orderId
 func (_m *Repo) FetchItemsById(orderId uuid.UUID) ([]*models.Item, error) {
 	ret := _m.Called(orderId)
@@ -182,6 +282,36 @@ func (_m *Repo) FetchItemsById(orderId uuid.UUID) ([]*models.Item, error) {
 	return r0, r1
 }
 
+// FetchOrderAdjustments provides a mock function with given fields: orderId
+func (_m *Repo) FetchOrderAdjustments(orderId uuid.UUID) ([]models.StockAdjustment, error) {
+	ret := _m.Called(orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchOrderAdjustments")
+	}
+
+	var r0 []models.StockAdjustment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.StockAdjustment, error)); ok {
+		return rf(orderId)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.StockAdjustment); ok {
+		r0 = rf(orderId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.StockAdjustment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FetchOrderById provides a mock function with given fields: orderId
 func (_m *Repo) FetchOrderById(orderId uuid.UUID) (*models.Order, error) {
 	ret := _m.Called(orderId)
@@ -212,6 +342,36 @@ func (_m *Repo) FetchOrderById(orderId uuid.UUID) (*models.Order, error) {
 	return r0, r1
 }
 
+// FetchOrderIds provides a mock function with given fields: ids
+func (_m *Repo) FetchOrderIds(ids []uuid.UUID) ([]uuid.UUID, error) {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchOrderIds")
+	}
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]uuid.UUID, error)); ok {
+		return rf(ids)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []uuid.UUID); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FetchOrdersById provides a mock function with given fields: userID
 func (_m *Repo) FetchOrdersById(userID uuid.UUID) ([]*models.Order, error) {
 	ret := _m.Called(userID)
@@ -272,6 +432,36 @@ func (_m *Repo) FetchPaymentById(orderId uuid.UUID) (*models.Payment, error) {
 	return r0, r1
 }
 
+// FetchPickLines provides a mock function with given fields: orderIds
+func (_m *Repo) FetchPickLines(orderIds []uuid.UUID) ([]models.PickLine, error) {
+	ret := _m.Called(orderIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchPickLines")
+	}
+
+	var r0 []models.PickLine
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]models.PickLine, error)); ok {
+		return rf(orderIds)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []models.PickLine); ok {
+		r0 = rf(orderIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PickLine)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(orderIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FetchShippingById provides a mock function with given fields: orderId
 func (_m *Repo) FetchShippingById(orderId uuid.UUID) (*models.Shipping, error) {
 	ret := _m.Called(orderId)
@@ -302,6 +492,84 @@ func (_m *Repo) FetchShippingById(orderId uuid.UUID) (*models.Shipping, error) {
 	return r0, r1
 }
 
+// FetchStockAdjustments provides a mock function with given fields: productId, limit
+func (_m *Repo) FetchStockAdjustments(productId uuid.UUID, limit int) ([]models.StockAdjustment, error) {
+	ret := _m.Called(productId, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchStockAdjustments")
+	}
+
+	var r0 []models.StockAdjustment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) ([]models.StockAdjustment, error)); ok {
+		return rf(productId, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) []models.StockAdjustment); ok {
+		r0 = rf(productId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.StockAdjustment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(productId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchUncapturedPayments provides a mock function with given fields: ctx, before
+func (_m *Repo) FetchUncapturedPayments(ctx context.Context, before time.Time) ([]*models.Payment, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchUncapturedPayments")
+	}
+
+	var r0 []*models.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]*models.Payment, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []*models.Payment); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertInvoice provides a mock function with given fields: inv
+func (_m *Repo) InsertInvoice(inv models.Invoice) error {
+	ret := _m.Called(inv)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertInvoice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.Invoice) error); ok {
+		r0 = rf(inv)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertItem provides a mock function with given fields: i
 func (_m *Repo) InsertItem(i models.Item) (*models.Item, error) {
 	ret := _m.Called(i)
@@ -422,17 +690,53 @@ func (_m *Repo) InsertShipping(s models.Shipping) (*models.Shipping, error) {
 	return r0, r1
 }
 
-// UpdateOrder provides a mock function with given fields: orderId, ord
-func (_m *Repo) UpdateOrder(orderId uuid.UUID, ord models.Order) error {
-	ret := _m.Called(orderId, ord)
+// ReplacePayment provides a mock function with given fields: ctx, orderId, p
+func (_m *Repo) ReplacePayment(ctx context.Context, orderId uuid.UUID, p models.Payment) error {
+	ret := _m.Called(ctx, orderId, p)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplacePayment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, models.Payment) error); ok {
+		r0 = rf(ctx, orderId, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StreamOrderSummaries provides a mock function with given fields: from, to, fn
+func (_m *Repo) StreamOrderSummaries(from time.Time, to time.Time, fn func(*models.OrderSummary) error) error {
+	ret := _m.Called(from, to, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamOrderSummaries")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(time.Time, time.Time, func(*models.OrderSummary) error) error); ok {
+		r0 = rf(from, to, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateOrder provides a mock function with given fields: orderId, ord, adjs
+func (_m *Repo) UpdateOrder(orderId uuid.UUID, ord models.Order, adjs []models.StockAdjustment) error {
+	ret := _m.Called(orderId, ord, adjs)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateOrder")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID, models.Order) error); ok {
-		r0 = rf(orderId, ord)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.Order, []models.StockAdjustment) error); ok {
+		r0 = rf(orderId, ord, adjs)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -440,17 +744,53 @@ func (_m *Repo) UpdateOrder(orderId uuid.UUID, ord models.Order) error {
 	return r0
 }
 
-// UpdateStock provides a mock function with given fields: productId, quantity
-func (_m *Repo) UpdateStock(productId uuid.UUID, quantity int) error {
-	ret := _m.Called(productId, quantity)
+// UpdatePaymentStatus provides a mock function with given fields: orderId, status
+func (_m *Repo) UpdatePaymentStatus(orderId uuid.UUID, status string) error {
+	ret := _m.Called(orderId, status)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateStock")
+		panic("no return value specified for UpdatePaymentStatus")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID, int) error); ok {
-		r0 = rf(productId, quantity)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = rf(orderId, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePaymentStatusById provides a mock function with given fields: ctx, provider, id, status
+func (_m *Repo) UpdatePaymentStatusById(ctx context.Context, provider string, id string, status string) error {
+	ret := _m.Called(ctx, provider, id, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePaymentStatusById")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, provider, id, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// VoidOrder provides a mock function with given fields: orderId
+func (_m *Repo) VoidOrder(orderId uuid.UUID) error {
+	ret := _m.Called(orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VoidOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(orderId)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1,11 +1,18 @@
 package orders
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/internal/models"
 )
 
 type Repo interface {
+	// CreateOrder inserts an order with its shipping, items and payment and writes its creation to the
+	// outbox, all or nothing, returns the order and error on failure
+	CreateOrder(ctx context.Context, order models.Order) (*models.Order, error)
+
 	// InsertOrder inserts an order into the database, returns the order and error on failure
 	InsertOrder(order models.Order) (*models.Order, error)
 
@@ -30,6 +37,10 @@ type Repo interface {
 	// FetchItemsById fetches items by orderId, returns the items and an error on failure
 	FetchItemsById(orderId uuid.UUID) ([]*models.Item, error)
 
+	// StreamOrderSummaries calls fn with the summary of every order created in [from, to), oldest first,
+	// returns the first error of fn and an error on failure
+	StreamOrderSummaries(from, to time.Time, fn func(s *models.OrderSummary) error) error
+
 	// FetchAllItems fetches all items, returns items and an error on failure
 	FetchAllItems() ([]*models.Item, error)
 
@@ -45,12 +56,57 @@ type Repo interface {
 	// FetchAllShipping fetches all shipping, return shipping and an error on failure
 	FetchAllShipping() ([]*models.Shipping, error)
 
-	// DeleteOrderById deletes order by orderId and returns an error if failed
-	DeleteOrderById(orderId uuid.UUID) error
+	// DeleteOrderById deletes order by orderId and applies adjs to the stock, returns an error if failed
+	DeleteOrderById(orderId uuid.UUID, adjs []models.StockAdjustment) error
+
+	// UpdateOrder updates an order in the database, applies adjs to the stock and writes its status change
+	// to the outbox, returns an error on failure
+	UpdateOrder(orderId uuid.UUID, ord models.Order, adjs []models.StockAdjustment) error
+
+	// AdjustStock applies adjs to the stock of products or of their variants and logs them, returns
+	// sql.ErrNoRows when a product or variant is missing
+	AdjustStock(adjs []models.StockAdjustment) error
+
+	// FetchStockAdjustments fetches the latest adjustments of the stock of a product, returns the
+	// adjustments and an error on failure
+	FetchStockAdjustments(productId uuid.UUID, limit int) ([]models.StockAdjustment, error)
+
+	// FetchOrderAdjustments fetches the adjustments of the stock made for an order, returns the
+	// adjustments and an error on failure
+	FetchOrderAdjustments(orderId uuid.UUID) ([]models.StockAdjustment, error)
+
+	// FetchOrderIds returns which of the given order ids exist, and an error on failure
+	FetchOrderIds(ids []uuid.UUID) ([]uuid.UUID, error)
+
+	// FetchPickLines sums the items of the given orders per product, returns the lines and an error on failure
+	FetchPickLines(orderIds []uuid.UUID) ([]models.PickLine, error)
+
+	// UpdatePaymentStatus sets the status of the payment of an order, returns an error on failure
+	UpdatePaymentStatus(orderId uuid.UUID, status string) error
+
+	// UpdatePaymentStatusById sets the status of a payment made with provider, returns sql.ErrNoRows
+	// when there is no such payment
+	UpdatePaymentStatusById(ctx context.Context, provider, id, status string) error
+
+	// ReplacePayment replaces the payment of an order with p, returns sql.ErrNoRows when the order has no payment
+	ReplacePayment(ctx context.Context, orderId uuid.UUID, p models.Payment) error
+
+	// FetchUncapturedPayments fetches the payments of unshipped orders still waiting for capture
+	// that were made before the given time, returns the payments and an error on failure
+	FetchUncapturedPayments(ctx context.Context, before time.Time) ([]*models.Payment, error)
+
+	// VoidOrder marks the payment of an order canceled, cancels the order and writes its status change to
+	// the outbox, returns an error on failure
+	VoidOrder(orderId uuid.UUID) error
+
+	// CancelOrder cancels an order still processing, records its cancellation, sets the status of its payment
+	// and writes its status change to the outbox, returns sql.ErrNoRows when the order is not processing
+	CancelOrder(c models.OrderCancellation, paymentStatus string) error
 
-	// UpdateOrder updates an order in the database, returns an error on failure
-	UpdateOrder(orderId uuid.UUID, ord models.Order) error
+	// FetchInvoice fetches the archived invoice of an order, returns sql.ErrNoRows when it is not archived
+	FetchInvoice(orderId uuid.UUID) (*models.Invoice, error)
 
-	// UpdateStock updates the product's stock, returns an error on failure
-	UpdateStock(productId uuid.UUID, quantity int) error
+	// InsertInvoice records the archived invoice of an order, keeping the one already recorded, returns an
+	// error on failure
+	InsertInvoice(inv models.Invoice) error
 }
@@ -4,11 +4,17 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/outbox"
+	"github.com/jofosuware/go/shopit/pkg/sqlb"
 )
 
 // OrdersRepository handles order-related persistence operations.
@@ -22,17 +28,81 @@ func NewOrdersRepository(db *sql.DB) *OrdersRepository {
 	return &OrdersRepository{DB: db}
 }
 
-// InsertOrder inserts an order into the database.
+// queryRower runs queries returning a single row, as *sql.DB and *sql.Tx do.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// CreateOrder inserts an order with its shipping, items and payment in one
+// transaction, writing the order as its events.OrderCreated to the outbox.
+func (o *OrdersRepository) CreateOrder(ctx context.Context, ord models.Order) (*models.Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	tx, err := o.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	order, err := insertOrder(ctx, tx, ord)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting order: %v", err)
+	}
+
+	ord.ShippingInfo.OrderID = order.OrderID
+	shipping, err := insertShipping(ctx, tx, ord.ShippingInfo)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting shipping: %v", err)
+	}
+	order.ShippingInfo = *shipping
+
+	items := make([]models.Item, len(ord.OrderItems))
+	for n, i := range ord.OrderItems {
+		items[n] = *i
+		items[n].OrderID = order.OrderID
+	}
+	order.OrderItems, err = insertItems(ctx, tx, items)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting items: %v", err)
+	}
+
+	ord.PaymentInfo.OrderID = order.OrderID
+	payment, err := insertPayment(ctx, tx, ord.PaymentInfo)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting payment: %v", err)
+	}
+	order.PaymentInfo = *payment
+
+	if err := outbox.Write(ctx, tx, events.OrderCreated, *order); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// InsertOrder inserts an order into the database, in the shop currency when it has
+// none.
 func (o *OrdersRepository) InsertOrder(order models.Order) (*models.Order, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
+	return insertOrder(ctx, o.DB, order)
+}
+
+func insertOrder(ctx context.Context, q queryRower, order models.Order) (*models.Order, error) {
 	query := `insert into orders (item_price, tax_price, shipping_price, total_price, order_status,
-				paid_at, delivered_at, user_id, created_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning 
+				paid_at, delivered_at, user_id, created_at, variant, coupon_code, discount, gift, gift_message, hide_prices,
+				currency)
+				values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) returning 
 				order_id, item_price, tax_price, shipping_price, total_price, order_status, paid_at, delivered_at,
-				user_id, created_at`
+				user_id, created_at, variant, coupon_code, discount, gift, gift_message, hide_prices, currency`
 
-	err := o.DB.QueryRowContext(ctx, query,
+	err := q.QueryRowContext(ctx, query,
 		order.ItemPrice,
 		order.TaxPrice,
 		order.ShippingPrice,
@@ -42,6 +112,13 @@ func (o *OrdersRepository) InsertOrder(order models.Order) (*models.Order, error
 		order.DeliveredAt,
 		order.UserID,
 		time.Now(),
+		order.Variant,
+		order.CouponCode,
+		order.Discount,
+		order.Gift,
+		order.GiftMessage,
+		order.HidePrices,
+		orderCurrency(order),
 	).Scan(
 		&order.OrderID,
 		&order.ItemPrice,
@@ -53,30 +130,46 @@ func (o *OrdersRepository) InsertOrder(order models.Order) (*models.Order, error
 		&order.DeliveredAt,
 		&order.UserID,
 		&order.CreatedAt,
+		&order.Variant,
+		&order.CouponCode,
+		&order.Discount,
+		&order.Gift,
+		&order.GiftMessage,
+		&order.HidePrices,
+		&order.Currency,
 	)
 
 	if err != nil {
 		return nil, err
 	}
+	order.UseCurrency(order.Currency)
 
 	return &order, nil
 }
 
-// InsertItem inserts an order item into the database.
+// InsertItem inserts an order item into the database. Its price is in the currency
+// of its order.
 func (o *OrdersRepository) InsertItem(item models.Item) (*models.Item, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := `insert into order_items (name, price, quantity, image, product_id, order_id, created_at)
-				values ($1, $2, $3, $4, $5, $6, $7) returning item_id, name, price, quantity, image,
-				product_id, order_id, created_at
+	return insertItem(ctx, o.DB, item)
+}
+
+func insertItem(ctx context.Context, q queryRower, item models.Item) (*models.Item, error) {
+	currency := item.Price.Currency
+
+	query := `insert into order_items (name, price, quantity, image, product_id, variant_id, order_id, created_at)
+				values ($1, $2, $3, $4, $5, $6, $7, $8) returning item_id, name, price, quantity, image,
+				product_id, variant_id, order_id, created_at
 	`
-	err := o.DB.QueryRowContext(ctx, query,
+	err := q.QueryRowContext(ctx, query,
 		item.Name,
 		item.Price,
 		item.Quantity,
 		item.Image,
 		item.ProductID,
+		item.VariantID,
 		item.OrderID,
 		time.Now(),
 	).Scan(
@@ -86,6 +179,7 @@ func (o *OrdersRepository) InsertItem(item models.Item) (*models.Item, error) {
 		&item.Quantity,
 		&item.Image,
 		&item.ProductID,
+		&item.VariantID,
 		&item.OrderID,
 		&item.CreatedAt,
 	)
@@ -93,25 +187,88 @@ func (o *OrdersRepository) InsertItem(item models.Item) (*models.Item, error) {
 	if err != nil {
 		return nil, err
 	}
+	item.Price = item.Price.WithCurrency(currency)
 
 	return &item, nil
 }
 
+// insertItems inserts items with one statement, returning them in the same order.
+func insertItems(ctx context.Context, tx *sql.Tx, items []models.Item) ([]*models.Item, error) {
+	inserted := make([]*models.Item, 0, len(items))
+	if len(items) == 0 {
+		return inserted, nil
+	}
+
+	now := time.Now()
+	values := make([]string, len(items))
+	args := make([]interface{}, 0, len(items)*8)
+	for n, i := range items {
+		values[n] = placeholders(n*8, 8)
+		args = append(args, i.Name, i.Price, i.Quantity, i.Image, i.ProductID, i.VariantID, i.OrderID, now)
+	}
+
+	// the rows of a multi-row insert are returned in the order of its values
+	query := `insert into order_items (name, price, quantity, image, product_id, variant_id, order_id, created_at)
+				values ` + strings.Join(values, ", ") + ` returning item_id, name, price, quantity, image,
+				product_id, variant_id, order_id, created_at`
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := items[len(inserted)]
+		currency := item.Price.Currency
+		err := rows.Scan(
+			&item.ItemID,
+			&item.Name,
+			&item.Price,
+			&item.Quantity,
+			&item.Image,
+			&item.ProductID,
+			&item.VariantID,
+			&item.OrderID,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		item.Price = item.Price.WithCurrency(currency)
+		inserted = append(inserted, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(inserted) != len(items) {
+		return nil, fmt.Errorf("inserted %d of %d items", len(inserted), len(items))
+	}
+
+	return inserted, nil
+}
+
 // InsertPayment inserts a payment record for an order.
 func (o *OrdersRepository) InsertPayment(p models.Payment) (*models.Payment, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := `insert into payments (payment_id, status, order_id, created_at) values ($1, $2, $3, $4) returning
-				payment_id, status, order_id, created_at
+	return insertPayment(ctx, o.DB, p)
+}
+
+func insertPayment(ctx context.Context, q queryRower, p models.Payment) (*models.Payment, error) {
+	query := `insert into payments (payment_id, provider, status, order_id, created_at) values ($1, $2, $3, $4, $5) returning
+				payment_id, provider, status, order_id, created_at
 	`
-	err := o.DB.QueryRowContext(ctx, query,
+	err := q.QueryRowContext(ctx, query,
 		p.ID,
+		paymentProvider(p),
 		p.Status,
 		p.OrderID,
 		time.Now(),
 	).Scan(
 		&p.ID,
+		&p.Provider,
 		&p.Status,
 		&p.OrderID,
 		&p.CreatedAt,
@@ -129,10 +286,14 @@ func (o *OrdersRepository) InsertShipping(shipping models.Shipping) (*models.Shi
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
+	return insertShipping(ctx, o.DB, shipping)
+}
+
+func insertShipping(ctx context.Context, q queryRower, shipping models.Shipping) (*models.Shipping, error) {
 	query := `insert into shippings (address, city, phone, postal, country, order_id, created_at) values ($1, $2, $3, $4, $5, $6, $7) returning
 				shipping_id, address, city, phone, postal, country, order_id, created_at
 	`
-	err := o.DB.QueryRowContext(ctx, query,
+	err := q.QueryRowContext(ctx, query,
 		shipping.Address,
 		shipping.City,
 		shipping.PhoneNo,
@@ -163,9 +324,15 @@ func (o *OrdersRepository) FetchOrderById(id uuid.UUID) (*models.Order, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := `select * from orders where order_id = $1`
+	return fetchOrder(ctx, o.DB, id)
+}
+
+func fetchOrder(ctx context.Context, q queryRower, id uuid.UUID) (*models.Order, error) {
+	query := `select order_id, item_price, tax_price, shipping_price, total_price, order_status, paid_at, delivered_at,
+				user_id, created_at, variant, coupon_code, discount, gift, gift_message, hide_prices, currency
+				from orders where order_id = $1`
 	var order models.Order
-	err := o.DB.QueryRowContext(ctx, query, id).Scan(
+	err := q.QueryRowContext(ctx, query, id).Scan(
 		&order.OrderID,
 		&order.ItemPrice,
 		&order.TaxPrice,
@@ -176,11 +343,19 @@ func (o *OrdersRepository) FetchOrderById(id uuid.UUID) (*models.Order, error) {
 		&order.DeliveredAt,
 		&order.UserID,
 		&order.CreatedAt,
+		&order.Variant,
+		&order.CouponCode,
+		&order.Discount,
+		&order.Gift,
+		&order.GiftMessage,
+		&order.HidePrices,
+		&order.Currency,
 	)
 
 	if err != nil {
 		return nil, err
 	}
+	order.UseCurrency(order.Currency)
 
 	return &order, nil
 }
@@ -191,7 +366,8 @@ func (o *OrdersRepository) FetchOrdersById(userID uuid.UUID) ([]*models.Order, e
 	defer cancel()
 
 	query := `select order_id, item_price, tax_price, shipping_price, total_price, order_status, paid_at, delivered_at,
-				user_id, created_at from orders where user_id = $1`
+				user_id, created_at, variant, coupon_code, discount, gift, gift_message, hide_prices, currency
+				from orders where user_id = $1`
 
 	rows, err := o.DB.QueryContext(ctx, query, userID)
 	if err != nil {
@@ -214,11 +390,19 @@ func (o *OrdersRepository) FetchOrdersById(userID uuid.UUID) ([]*models.Order, e
 			&order.DeliveredAt,
 			&order.UserID,
 			&order.CreatedAt,
+			&order.Variant,
+			&order.CouponCode,
+			&order.Discount,
+			&order.Gift,
+			&order.GiftMessage,
+			&order.HidePrices,
+			&order.Currency,
 		)
 
 		if err != nil {
 			return nil, err
 		}
+		order.UseCurrency(order.Currency)
 
 		orders = append(orders, &order)
 		if err := rows.Err(); err != nil {
@@ -229,12 +413,13 @@ func (o *OrdersRepository) FetchOrdersById(userID uuid.UUID) ([]*models.Order, e
 	return orders, nil
 }
 
-// FetchItemsById fetches items for a given order ID.
+// FetchItemsById fetches items for a given order ID, priced in the order currency.
 func (o *OrdersRepository) FetchItemsById(orderId uuid.UUID) ([]*models.Item, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := `select item_id, name, price, quantity, image, product_id, order_id, created_at from order_items where order_id = $1`
+	query := `select i.item_id, i.name, i.price, o.currency, i.quantity, i.image, i.product_id, i.variant_id, i.order_id,
+				i.created_at from order_items i join orders o on o.order_id = i.order_id where i.order_id = $1`
 
 	rows, err := o.DB.QueryContext(ctx, query, orderId)
 	if err != nil {
@@ -250,9 +435,11 @@ func (o *OrdersRepository) FetchItemsById(orderId uuid.UUID) ([]*models.Item, er
 			&item.ItemID,
 			&item.Name,
 			&item.Price,
+			&item.Price.Currency,
 			&item.Quantity,
 			&item.Image,
 			&item.ProductID,
+			&item.VariantID,
 			&item.OrderID,
 			&item.CreatedAt,
 		)
@@ -275,12 +462,13 @@ func (o *OrdersRepository) FetchPaymentById(orderId uuid.UUID) (*models.Payment,
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := `select * from payments where order_id = $1`
+	query := `select payment_id, provider, status, order_id, created_at from payments where order_id = $1`
 
 	var payment models.Payment
 
 	err := o.DB.QueryRowContext(ctx, query, orderId).Scan(
 		&payment.ID,
+		&payment.Provider,
 		&payment.Status,
 		&payment.OrderID,
 		&payment.CreatedAt,
@@ -320,18 +508,29 @@ func (o *OrdersRepository) FetchShippingById(orderId uuid.UUID) (*models.Shippin
 	return &shipping, nil
 }
 
-// DeleteOrderById deletes an order by its ID.
-func (o *OrdersRepository) DeleteOrderById(orderId uuid.UUID) error {
+// DeleteOrderById deletes an order by its ID, applying adjs to the stock in the same
+// transaction.
+func (o *OrdersRepository) DeleteOrderById(orderId uuid.UUID, adjs []models.StockAdjustment) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
+	tx, err := o.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `delete from orders where order_id = $1`
-	_, err := o.DB.ExecContext(ctx, query, orderId)
+	_, err = tx.ExecContext(ctx, query, orderId)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if err := adjustStock(ctx, tx, adjs); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // FetchAllOrders returns all orders.
@@ -340,7 +539,8 @@ func (o *OrdersRepository) FetchAllOrders() ([]*models.Order, error) {
 	defer cancel()
 
 	query := `select order_id, user_id, paid_at, item_price, tax_price, shipping_price, 
-		total_price, order_status, delivered_at, created_at from orders`
+		total_price, order_status, delivered_at, created_at, variant, coupon_code, discount, gift, gift_message, hide_prices,
+		currency from orders`
 
 	rows, err := o.DB.QueryContext(ctx, query)
 	if err != nil {
@@ -364,11 +564,19 @@ func (o *OrdersRepository) FetchAllOrders() ([]*models.Order, error) {
 			&ord.OrderStatus,
 			&ord.DeliveredAt,
 			&ord.CreatedAt,
+			&ord.Variant,
+			&ord.CouponCode,
+			&ord.Discount,
+			&ord.Gift,
+			&ord.GiftMessage,
+			&ord.HidePrices,
+			&ord.Currency,
 		)
 
 		if err != nil {
 			return nil, err
 		}
+		ord.UseCurrency(ord.Currency)
 
 		ords = append(ords, &ord)
 
@@ -380,12 +588,71 @@ func (o *OrdersRepository) FetchAllOrders() ([]*models.Order, error) {
 	return ords, nil
 }
 
-// FetchAllItems returns all order items.
+// StreamOrderSummaries calls fn with the summary of every order created in [from, to),
+// oldest first, without loading them in memory. It stops at the first error of fn and
+// returns it.
+func (o *OrdersRepository) StreamOrderSummaries(from, to time.Time, fn func(s *models.OrderSummary) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	query := `select o.order_id, o.user_id, o.order_status, o.currency, o.item_price, o.tax_price, o.shipping_price,
+		o.discount, o.total_price, o.coupon_code, o.variant, o.gift, coalesce(s.city, ''), coalesce(s.country, ''),
+		coalesce(sum(i.quantity), 0), o.created_at, o.paid_at, o.delivered_at
+		from orders o
+		left join shippings s on s.order_id = o.order_id
+		left join order_items i on i.order_id = o.order_id
+		where o.created_at >= $1 and o.created_at < $2
+		group by o.order_id, s.city, s.country
+		order by o.created_at, o.order_id`
+
+	rows, err := o.DB.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.OrderSummary
+		err := rows.Scan(
+			&s.OrderID,
+			&s.UserID,
+			&s.OrderStatus,
+			&s.Currency,
+			&s.ItemPrice,
+			&s.TaxPrice,
+			&s.ShippingPrice,
+			&s.Discount,
+			&s.TotalPrice,
+			&s.CouponCode,
+			&s.Variant,
+			&s.Gift,
+			&s.ShippingInfo.City,
+			&s.ShippingInfo.Country,
+			&s.ItemCount,
+			&s.CreatedAt,
+			&s.PaidAt,
+			&s.DeliveredAt,
+		)
+		if err != nil {
+			return err
+		}
+		s.UseCurrency(s.Currency)
+
+		if err := fn(&s); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// FetchAllItems returns all order items, priced in the currency of their order.
 func (o *OrdersRepository) FetchAllItems() ([]*models.Item, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := `select item_id, name, price, quantity, image, product_id, order_id, created_at from order_items`
+	query := `select i.item_id, i.name, i.price, o.currency, i.quantity, i.image, i.product_id, i.variant_id, i.order_id,
+				i.created_at from order_items i join orders o on o.order_id = i.order_id`
 
 	rows, err := o.DB.QueryContext(ctx, query)
 	if err != nil {
@@ -401,9 +668,11 @@ func (o *OrdersRepository) FetchAllItems() ([]*models.Item, error) {
 			&item.ItemID,
 			&item.Name,
 			&item.Price,
+			&item.Price.Currency,
 			&item.Quantity,
 			&item.Image,
 			&item.ProductID,
+			&item.VariantID,
 			&item.OrderID,
 			&item.CreatedAt,
 		)
@@ -426,7 +695,7 @@ func (o *OrdersRepository) FetchAllPayment() ([]*models.Payment, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := `select payment_id, status, order_id, created_at from payments`
+	query := `select payment_id, provider, status, order_id, created_at from payments`
 
 	rows, err := o.DB.QueryContext(ctx, query)
 	if err != nil {
@@ -439,6 +708,7 @@ func (o *OrdersRepository) FetchAllPayment() ([]*models.Payment, error) {
 		var payment models.Payment
 		err := rows.Scan(
 			&payment.ID,
+			&payment.Provider,
 			&payment.Status,
 			&payment.OrderID,
 			&payment.CreatedAt,
@@ -498,32 +768,487 @@ func (o *OrdersRepository) FetchAllShipping() ([]*models.Shipping, error) {
 	return shipping, nil
 }
 
-// UpdateOrder updates an order's status and delivered time.
-func (o *OrdersRepository) UpdateOrder(orderId uuid.UUID, ord models.Order) error {
+// UpdateOrder updates an order's status and delivered time, applying adjs to the stock
+// and writing ord as its events.OrderStatusChanged to the outbox in the same
+// transaction.
+func (o *OrdersRepository) UpdateOrder(orderId uuid.UUID, ord models.Order, adjs []models.StockAdjustment) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
+	tx, err := o.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `update orders set order_status = $1, delivered_at = $2 where order_id = $3`
 
-	_, err := o.DB.ExecContext(ctx, query, ord.OrderStatus, ord.DeliveredAt, orderId)
+	_, err = tx.ExecContext(ctx, query, ord.OrderStatus, ord.DeliveredAt, orderId)
+	if err != nil {
+		return err
+	}
+
+	if err := adjustStock(ctx, tx, adjs); err != nil {
+		return err
+	}
+
+	if err := outbox.Write(ctx, tx, events.OrderStatusChanged, ord); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AdjustStock applies adjs to the stock and logs them, all or nothing. It returns
+// sql.ErrNoRows when a product, or variant of it, does not exist.
+func (o *OrdersRepository) AdjustStock(adjs []models.StockAdjustment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := o.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := adjustStock(ctx, tx, adjs); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// adjustStock adds the quantity of each adjustment to the stock of its product, or of
+// its variant when it has one, and logs it. A decrease that brings the stock to the
+// low-stock threshold of the product or under writes an events.StockLow to the outbox.
+// It returns sql.ErrNoRows when there is no such product or variant.
+func adjustStock(ctx context.Context, tx *sql.Tx, adjs []models.StockAdjustment) error {
+	now := time.Now()
+	for _, a := range adjs {
+		item := models.LowStockItem{ProductID: a.ProductID, VariantID: a.VariantID}
+
+		var row *sql.Row
+		if a.VariantID.Valid {
+			query := `update product_variants v set stock = v.stock + $1 from products p
+						where v.variant_id = $2 and v.product_id = $3 and p.product_id = v.product_id
+						returning v.stock, p.low_stock_threshold, p.name, coalesce(v.sku, '')`
+			row = tx.QueryRowContext(ctx, query, a.Quantity, a.VariantID.UUID, a.ProductID)
+		} else {
+			query := `update products set stock = stock + $1 where product_id = $2
+						returning stock, low_stock_threshold, name, coalesce(sku, '')`
+			row = tx.QueryRowContext(ctx, query, a.Quantity, a.ProductID)
+		}
+		if err := row.Scan(&item.Stock, &item.Threshold, &item.Name, &item.SKU); err != nil {
+			return err
+		}
+
+		// alert once, as the stock crosses the threshold
+		if a.Quantity < 0 && item.Stock <= item.Threshold && item.Stock-a.Quantity > item.Threshold {
+			if err := outbox.Write(ctx, tx, events.StockLow, item); err != nil {
+				return err
+			}
+		}
+
+		query := `insert into inventory_adjustments (product_id, variant_id, quantity, reason, order_id, user_id, note,
+					created_at) values ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+		_, err := tx.ExecContext(ctx, query, a.ProductID, a.VariantID, a.Quantity, a.Reason, a.OrderID, a.UserID, a.Note, now)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-// UpdateStock decrements a product's stock by the given quantity.
-func (o *OrdersRepository) UpdateStock(productId uuid.UUID, quantity int) error {
+// FetchStockAdjustments fetches the latest limit adjustments of the stock of a product
+// and its variants, newest first.
+func (o *OrdersRepository) FetchStockAdjustments(productId uuid.UUID, limit int) ([]models.StockAdjustment, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := `update products set stock = stock - $1 where product_id = $2`
+	query := `select adjustment_id, product_id, variant_id, quantity, reason, order_id, user_id, note, created_at
+				from inventory_adjustments where product_id = $1 order by created_at desc limit $2`
 
-	_, err := o.DB.ExecContext(ctx, query, quantity, productId)
+	rows, err := o.DB.QueryContext(ctx, query, productId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	adjs := []models.StockAdjustment{}
+	for rows.Next() {
+		var a models.StockAdjustment
+		err := rows.Scan(&a.ID, &a.ProductID, &a.VariantID, &a.Quantity, &a.Reason, &a.OrderID, &a.UserID, &a.Note,
+			&a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		adjs = append(adjs, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return adjs, nil
+}
+
+// FetchOrderAdjustments fetches the adjustments of the stock made for an order, oldest
+// first.
+func (o *OrdersRepository) FetchOrderAdjustments(orderId uuid.UUID) ([]models.StockAdjustment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select adjustment_id, product_id, variant_id, quantity, reason, order_id, user_id, note, created_at
+				from inventory_adjustments where order_id = $1 order by created_at`
+
+	rows, err := o.DB.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	adjs := []models.StockAdjustment{}
+	for rows.Next() {
+		var a models.StockAdjustment
+		err := rows.Scan(&a.ID, &a.ProductID, &a.VariantID, &a.Quantity, &a.Reason, &a.OrderID, &a.UserID, &a.Note,
+			&a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		adjs = append(adjs, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return adjs, nil
+}
+
+// FetchOrderIds returns which of the given order ids exist.
+func (o *OrdersRepository) FetchOrderIds(ids []uuid.UUID) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query, args := sqlb.Select("order_id").From("orders").Where(sqlb.In("order_id", ids)).Build()
+
+	rows, err := o.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		found = append(found, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// FetchPickLines sums the items of the given orders per product, ordered by name.
+func (o *OrdersRepository) FetchPickLines(orderIds []uuid.UUID) ([]models.PickLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query, args := sqlb.Select("i.product_id, min(i.name), coalesce(p.sku, ''), sum(i.quantity), count(distinct i.order_id)").
+		From("order_items i left join products p on p.product_id = i.product_id").
+		Where(sqlb.In("i.order_id", orderIds)).
+		GroupBy("i.product_id, p.sku").
+		OrderBy("min(i.name)", "i.product_id").
+		Build()
+
+	rows, err := o.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []models.PickLine
+	for rows.Next() {
+		var l models.PickLine
+		err := rows.Scan(
+			&l.ProductID,
+			&l.Name,
+			&l.SKU,
+			&l.Quantity,
+			&l.Orders,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// UpdatePaymentStatus sets the status of the payment of an order.
+func (o *OrdersRepository) UpdatePaymentStatus(orderId uuid.UUID, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update payments set status = $1 where order_id = $2`
+
+	_, err := o.DB.ExecContext(ctx, query, status, orderId)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// UpdatePaymentStatusById sets the status of the payment id made with provider.
+// It returns sql.ErrNoRows when there is no such payment.
+func (o *OrdersRepository) UpdatePaymentStatusById(ctx context.Context, provider, id, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update payments set status = $1 where payment_id = $2 and provider = $3`
+
+	res, err := o.DB.ExecContext(ctx, query, status, id, provider)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReplacePayment replaces the payment of the order orderId with p, restarting its
+// capture window. It returns sql.ErrNoRows when the order has no payment.
+func (o *OrdersRepository) ReplacePayment(ctx context.Context, orderId uuid.UUID, p models.Payment) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update payments set payment_id = $1, provider = $2, status = $3, created_at = $4 where order_id = $5`
+
+	res, err := o.DB.ExecContext(ctx, query, p.ID, paymentProvider(p), p.Status, time.Now(), orderId)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// FetchUncapturedPayments fetches the payments still waiting for capture that were made
+// before the given time for orders that have not shipped.
+func (o *OrdersRepository) FetchUncapturedPayments(ctx context.Context, before time.Time) ([]*models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select p.payment_id, p.provider, p.status, p.order_id, p.created_at
+				from payments p
+				join orders o on o.order_id = p.order_id
+				where p.status = $1 and p.created_at < $2 and o.order_status = $3
+				order by p.created_at`
+
+	rows, err := o.DB.QueryContext(ctx, query, models.PaymentRequiresCapture, before, models.OrderProcessing)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*models.Payment
+	for rows.Next() {
+		var payment models.Payment
+		err := rows.Scan(
+			&payment.ID,
+			&payment.Provider,
+			&payment.Status,
+			&payment.OrderID,
+			&payment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, &payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// VoidOrder records the payment of an order as canceled and cancels the order, writing
+// its events.OrderStatusChanged to the outbox in the same transaction.
+func (o *OrdersRepository) VoidOrder(orderId uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := o.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, `update payments set status = $1 where order_id = $2`,
+		models.PaymentCanceled, orderId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `update orders set order_status = $1 where order_id = $2`,
+		models.OrderCancelled, orderId)
+	if err != nil {
+		return err
+	}
+
+	order, err := fetchOrder(ctx, tx, orderId)
+	if err != nil {
+		return err
+	}
+
+	if err := outbox.Write(ctx, tx, events.OrderStatusChanged, *order); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CancelOrder cancels an order still processing, records who cancelled it and why and
+// sets the status of its payment, writing its events.OrderStatusChanged to the outbox in
+// the same transaction. It returns sql.ErrNoRows when the order is not processing, so
+// an order shipped meanwhile is not cancelled.
+func (o *OrdersRepository) CancelOrder(c models.OrderCancellation, paymentStatus string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := o.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `update orders set order_status = $1 where order_id = $2 and order_status = $3`,
+		models.OrderCancelled, c.OrderID, models.OrderProcessing)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.ExecContext(ctx, `insert into order_cancellations (order_id, user_id, reason, created_at)
+			values ($1, $2, $3, $4)`, c.OrderID, c.UserID, c.Reason, time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `update payments set status = $1 where order_id = $2`, paymentStatus, c.OrderID)
+	if err != nil {
+		return err
+	}
+
+	order, err := fetchOrder(ctx, tx, c.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if err := outbox.Write(ctx, tx, events.OrderStatusChanged, *order); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FetchInvoice fetches the archived invoice of an order.
+func (o *OrdersRepository) FetchInvoice(orderId uuid.UUID) (*models.Invoice, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select order_id, url, public_id, created_at from invoices where order_id = $1`
+
+	var inv models.Invoice
+
+	err := o.DB.QueryRowContext(ctx, query, orderId).Scan(
+		&inv.OrderID,
+		&inv.URL,
+		&inv.PublicID,
+		&inv.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &inv, nil
+}
+
+// InsertInvoice records the archived invoice of an order. An invoice archived twice
+// by concurrent downloads keeps the first record.
+func (o *OrdersRepository) InsertInvoice(inv models.Invoice) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into invoices (order_id, url, public_id, created_at) values ($1, $2, $3, $4)
+			on conflict (order_id) do nothing`
+
+	_, err := o.DB.ExecContext(ctx, query, inv.OrderID, inv.URL, inv.PublicID, time.Now())
+
+	return err
+}
+
+// paymentProvider returns the provider of p, Stripe when it has none.
+func paymentProvider(p models.Payment) string {
+	if p.Provider == "" {
+		return models.PaymentStripe
+	}
+
+	return p.Provider
+}
+
+// orderCurrency returns the currency of order, the shop currency when it has none.
+func orderCurrency(order models.Order) string {
+	if order.Currency == "" {
+		return money.DefaultCurrency
+	}
+	return order.Currency
+}
+
+// placeholders returns the n placeholders of a row of values, numbered from from+1.
+func placeholders(from, n int) string {
+	ph := make([]string, n)
+	for i := range ph {
+		ph[i] = fmt.Sprintf("$%d", from+i+1)
+	}
+	return "(" + strings.Join(ph, ", ") + ")"
+}
@@ -1,6 +1,11 @@
 package repository_test
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -8,6 +13,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/internal/models"
 	"github.com/jofosuware/go/shopit/internal/orders/repository"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,24 +25,30 @@ func TestInsertOrder(t *testing.T) {
 	defer db.Close()
 
 	// Updated query includes delivered_at and a 9th argument.
-	query := `insert into orders \(item_price, tax_price, shipping_price, total_price, order_status, paid_at, delivered_at, user_id, created_at\) values \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, \$9\) returning order_id, item_price, tax_price, shipping_price, total_price, order_status, paid_at, delivered_at, user_id, created_at`
+	query := `insert into orders \(item_price, tax_price, shipping_price, total_price, order_status, paid_at, delivered_at, user_id, created_at, variant, coupon_code, discount, gift, gift_message, hide_prices, currency\) values \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, \$9, \$10, \$11, \$12, \$13, \$14, \$15, \$16\) returning order_id, item_price, tax_price, shipping_price, total_price, order_status, paid_at, delivered_at, user_id, created_at, variant, coupon_code, discount, gift, gift_message, hide_prices, currency`
 
 	order := models.Order{
-		ItemPrice:     100,
-		TaxPrice:      10,
-		ShippingPrice: 20,
-		TotalPrice:    130,
+		ItemPrice:     money.Of(100),
+		TaxPrice:      money.Of(10),
+		ShippingPrice: money.Of(20),
+		TotalPrice:    money.Of(130),
 		OrderStatus:   "pending",
 		PaidAt:        time.Now(),
 		DeliveredAt:   time.Time{}, // freshly inserted order's DeliveredAt is empty
 		UserID:        uuid.New(),
+		Variant:       "newcheckout",
+		CouponCode:    "SAVE10",
+		Discount:      money.Of(10),
+		Gift:          true,
+		GiftMessage:   "Happy birthday!",
+		HidePrices:    true,
 	}
 
 	t.Run("Order inserted successfully", func(t *testing.T) {
 		// For created_at we allow any argument.
 		row := sqlmock.NewRows([]string{
-			"order_id", "item_price", "tax_price", "shipping_price", "total_price", "order_status", "paid_at", "delivered_at", "user_id", "created_at",
-		}).AddRow(uuid.New(), order.ItemPrice, order.TaxPrice, order.ShippingPrice, order.TotalPrice, order.OrderStatus, order.PaidAt, order.DeliveredAt, order.UserID, time.Now())
+			"order_id", "item_price", "tax_price", "shipping_price", "total_price", "order_status", "paid_at", "delivered_at", "user_id", "created_at", "variant", "coupon_code", "discount", "gift", "gift_message", "hide_prices", "currency",
+		}).AddRow(uuid.New(), order.ItemPrice, order.TaxPrice, order.ShippingPrice, order.TotalPrice, order.OrderStatus, order.PaidAt, order.DeliveredAt, order.UserID, time.Now(), order.Variant, order.CouponCode, order.Discount, order.Gift, order.GiftMessage, order.HidePrices, "USD")
 
 		mock.ExpectQuery(query).WithArgs(
 			order.ItemPrice,
@@ -47,6 +60,13 @@ func TestInsertOrder(t *testing.T) {
 			order.DeliveredAt,
 			order.UserID,
 			sqlmock.AnyArg(),
+			order.Variant,
+			order.CouponCode,
+			order.Discount,
+			order.Gift,
+			order.GiftMessage,
+			order.HidePrices,
+			"USD",
 		).WillReturnRows(row)
 
 		repo := repository.NewOrdersRepository(db)
@@ -56,6 +76,132 @@ func TestInsertOrder(t *testing.T) {
 
 		assert.NotNil(t, result)
 		assert.Equal(t, order.ItemPrice, result.ItemPrice)
+		assert.Equal(t, order.Variant, result.Variant)
+		assert.Equal(t, order.CouponCode, result.CouponCode)
+		assert.Equal(t, order.Discount, result.Discount)
+		assert.Equal(t, order.GiftMessage, result.GiftMessage)
+		assert.True(t, result.HidePrices)
+	})
+}
+
+// orderRows returns the columns of an order as read back from the orders table.
+func orderRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"order_id", "item_price", "tax_price", "shipping_price", "total_price", "order_status",
+		"paid_at", "delivered_at", "user_id", "created_at", "variant", "coupon_code", "discount", "gift", "gift_message",
+		"hide_prices", "currency"})
+}
+
+func TestCreateOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOrdersRepository(db)
+	orderId, userId := uuid.New(), uuid.New()
+	ord := models.Order{
+		UserID:       userId,
+		OrderStatus:  models.OrderProcessing,
+		TotalPrice:   money.Of(130),
+		ShippingInfo: models.Shipping{Address: "1 Main St", City: "Accra"},
+		OrderItems:   []*models.Item{{Name: "Mug", Price: money.Of(50), Quantity: 1}, {Name: "Tea", Price: money.Of(80), Quantity: 1}},
+		PaymentInfo:  models.Payment{ID: "pi_1", Status: models.PaymentSucceeded},
+	}
+
+	expectInserts := func() {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`insert into orders`).WillReturnRows(orderRows().AddRow(orderId, 0, 0, 0, 130,
+			models.OrderProcessing, time.Now(), time.Time{}, userId, time.Now(), "", "", 0, false, "", false, "USD"))
+		mock.ExpectQuery(`insert into shippings`).WillReturnRows(sqlmock.NewRows([]string{"shipping_id", "address", "city", "phone", "postal", "country", "order_id", "created_at"}).
+			AddRow(uuid.New(), "1 Main St", "Accra", "", "", "", orderId, time.Now()))
+		items := sqlmock.NewRows([]string{"item_id", "name", "price", "quantity", "image", "product_id", "variant_id", "order_id", "created_at"})
+		var args []driver.Value
+		for _, i := range ord.OrderItems {
+			args = append(args, i.Name, i.Price, i.Quantity, i.Image, i.ProductID, i.VariantID, orderId, sqlmock.AnyArg())
+			items.AddRow(uuid.New(), i.Name, i.Price, i.Quantity, "", uuid.Nil, nil, orderId, time.Now())
+		}
+		mock.ExpectQuery(`insert into order_items .* values \(\$1, .*, \$8\), \(\$9, .*, \$16\) returning`).
+			WithArgs(args...).WillReturnRows(items)
+	}
+
+	t.Run("Order and its creation event are committed together", func(t *testing.T) {
+		expectInserts()
+		mock.ExpectQuery(`insert into payments`).WithArgs("pi_1", models.PaymentStripe, models.PaymentSucceeded, orderId, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"payment_id", "provider", "status", "order_id", "created_at"}).
+				AddRow("pi_1", models.PaymentStripe, models.PaymentSucceeded, orderId, time.Now()))
+		mock.ExpectExec(`insert into outbox \(name, payload\) values \(\$1, \$2\)`).
+			WithArgs(events.OrderCreated, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		order, err := repo.CreateOrder(context.Background(), ord)
+		require.NoError(t, err)
+		assert.Equal(t, orderId, order.OrderID)
+		assert.Equal(t, orderId, order.ShippingInfo.OrderID)
+		require.Len(t, order.OrderItems, 2)
+		assert.Equal(t, "Tea", order.OrderItems[1].Name)
+		assert.Equal(t, "pi_1", order.PaymentInfo.ID)
+		assert.Equal(t, uuid.Nil, ord.OrderItems[0].OrderID, "the items passed in are left as they were")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Nothing is kept when a record fails", func(t *testing.T) {
+		expectInserts()
+		mock.ExpectQuery(`insert into payments`).WillReturnError(errors.New("duplicate payment"))
+		mock.ExpectRollback()
+
+		_, err := repo.CreateOrder(context.Background(), ord)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOrdersRepository(db)
+	ord := models.Order{OrderID: uuid.New(), OrderStatus: models.OrderShipped}
+
+	t.Run("Status change is written to the outbox", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`update orders set order_status = \$1, delivered_at = \$2 where order_id = \$3`).
+			WithArgs(models.OrderShipped, ord.DeliveredAt, ord.OrderID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`insert into outbox`).WithArgs(events.OrderStatusChanged, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.UpdateOrder(ord.OrderID, ord, nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Items are taken from stock with the status change", func(t *testing.T) {
+		productId := uuid.New()
+		adjs := []models.StockAdjustment{{ProductID: productId, Quantity: -2, Reason: models.StockShipped,
+			OrderID: uuid.NullUUID{UUID: ord.OrderID, Valid: true}}}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`update orders`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`update products set stock = stock \+ \$1 where product_id = \$2`).
+			WithArgs(-2, productId).WillReturnRows(sqlmock.NewRows([]string{"stock", "low_stock_threshold", "name", "sku"}).AddRow(20, 5, "Kente Scarf", ""))
+		mock.ExpectExec(`insert into inventory_adjustments`).
+			WithArgs(productId, uuid.NullUUID{}, -2, models.StockShipped, adjs[0].OrderID, uuid.NullUUID{}, "",
+				sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`insert into outbox`).WithArgs(events.OrderStatusChanged, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.UpdateOrder(ord.OrderID, ord, adjs))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Status is not changed when the outbox fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`update orders`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`insert into outbox`).WillReturnError(errors.New("db down"))
+		mock.ExpectRollback()
+
+		assert.Error(t, repo.UpdateOrder(ord.OrderID, ord, nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -64,9 +210,9 @@ func TestInsertItems(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	query := `insert into order_items \(name, price, quantity, image, product_id, order_id, created_at\)
-				values \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\) returning item_id, name, price, quantity, image,
-				product_id, order_id, created_at
+	query := `insert into order_items \(name, price, quantity, image, product_id, variant_id, order_id, created_at\)
+				values \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8\) returning item_id, name, price, quantity, image,
+				product_id, variant_id, order_id, created_at
 	`
 
 	item := models.Item{
@@ -75,14 +221,14 @@ func TestInsertItems(t *testing.T) {
 		ProductID: uuid.New(),
 		Quantity:  2,
 		Image:     "test_image.jpg",
-		Price:     100,
+		Price:     money.Of(100),
 	}
 
 	t.Run("Items inserted successfully", func(t *testing.T) {
-		row := sqlmock.NewRows([]string{"item_id", "name", "price", "quantity", "image", "product_id", "order_id", "created_at"}).
-			AddRow(uuid.UUID{}, item.Name, item.Price, item.Quantity, item.Image, item.ProductID, item.OrderID, time.Now())
+		row := sqlmock.NewRows([]string{"item_id", "name", "price", "quantity", "image", "product_id", "variant_id", "order_id", "created_at"}).
+			AddRow(uuid.UUID{}, item.Name, item.Price, item.Quantity, item.Image, item.ProductID, nil, item.OrderID, time.Now())
 
-		mock.ExpectQuery(query).WithArgs(item.Name, item.Price, item.Quantity, item.Image, item.ProductID, item.OrderID, sqlmock.AnyArg()).WillReturnRows(row)
+		mock.ExpectQuery(query).WithArgs(item.Name, item.Price, item.Quantity, item.Image, item.ProductID, item.VariantID, item.OrderID, sqlmock.AnyArg()).WillReturnRows(row)
 
 		repo := repository.NewOrdersRepository(db)
 
@@ -99,8 +245,7 @@ func TestInsertPayment(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	// Updated query: now includes payment_id as first argument and four arguments in total.
-	query := `insert into payments \(payment_id, status, order_id, created_at\) values \(\$1, \$2, \$3, \$4\) returning\s+payment_id, status, order_id, created_at`
+	query := `insert into payments \(payment_id, provider, status, order_id, created_at\) values \(\$1, \$2, \$3, \$4, \$5\) returning\s+payment_id, provider, status, order_id, created_at`
 
 	payment := models.Payment{
 		ID:        "",
@@ -110,10 +255,11 @@ func TestInsertPayment(t *testing.T) {
 	}
 
 	t.Run("Payment inserted successfully", func(t *testing.T) {
-		row := sqlmock.NewRows([]string{"payment_id", "status", "order_id", "created_at"}).
-			AddRow(payment.ID, payment.Status, payment.OrderID, time.Now())
+		row := sqlmock.NewRows([]string{"payment_id", "provider", "status", "order_id", "created_at"}).
+			AddRow(payment.ID, models.PaymentStripe, payment.Status, payment.OrderID, time.Now())
 
-		mock.ExpectQuery(query).WithArgs(payment.ID, payment.Status, payment.OrderID, sqlmock.AnyArg()).WillReturnRows(row)
+		// payments without a provider were made with Stripe
+		mock.ExpectQuery(query).WithArgs(payment.ID, models.PaymentStripe, payment.Status, payment.OrderID, sqlmock.AnyArg()).WillReturnRows(row)
 
 		repo := repository.NewOrdersRepository(db)
 
@@ -122,6 +268,7 @@ func TestInsertPayment(t *testing.T) {
 
 		assert.NotNil(t, p)
 		assert.Equal(t, payment.OrderID, p.OrderID)
+		assert.Equal(t, models.PaymentStripe, p.Provider)
 	})
 }
 
@@ -166,14 +313,14 @@ func TestFetchOrderById(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	query := `select \* from orders where order_id = \$1`
+	query := `select order_id, item_price, tax_price, shipping_price, total_price, order_status, paid_at, delivered_at, user_id, created_at, variant, coupon_code, discount, gift, gift_message, hide_prices, currency from orders where order_id = \$1`
 
 	order := models.Order{
 		OrderID:       uuid.New(),
-		ItemPrice:     100,
-		TaxPrice:      10,
-		ShippingPrice: 20,
-		TotalPrice:    130,
+		ItemPrice:     money.Of(100),
+		TaxPrice:      money.Of(10),
+		ShippingPrice: money.Of(20),
+		TotalPrice:    money.Of(130),
 		OrderStatus:   "pending",
 		PaidAt:        time.Now(),
 		DeliveredAt:   time.Now(),
@@ -182,8 +329,8 @@ func TestFetchOrderById(t *testing.T) {
 	}
 
 	t.Run("Order fetched successfully", func(t *testing.T) {
-		row := sqlmock.NewRows([]string{"order_id", "item_price", "tax_price", "shipping_price", "total_price", "order_status", "paid_at", "delivered_at", "user_id", "created_at"}).
-			AddRow(order.OrderID, order.ItemPrice, order.TaxPrice, order.ShippingPrice, order.TotalPrice, order.OrderStatus, order.PaidAt, order.DeliveredAt, order.UserID, order.CreatedAt)
+		row := sqlmock.NewRows([]string{"order_id", "item_price", "tax_price", "shipping_price", "total_price", "order_status", "paid_at", "delivered_at", "user_id", "created_at", "variant", "coupon_code", "discount", "gift", "gift_message", "hide_prices", "currency"}).
+			AddRow(order.OrderID, order.ItemPrice, order.TaxPrice, order.ShippingPrice, order.TotalPrice, order.OrderStatus, order.PaidAt, order.DeliveredAt, order.UserID, order.CreatedAt, order.Variant, "", 0, false, "", false, "EUR")
 
 		mock.ExpectQuery(query).WithArgs(order.OrderID).WillReturnRows(row)
 
@@ -194,6 +341,9 @@ func TestFetchOrderById(t *testing.T) {
 
 		assert.NotNil(t, o)
 		assert.Equal(t, order.OrderID, o.OrderID)
+		assert.Equal(t, "EUR", o.Currency)
+		assert.Equal(t, order.TotalPrice.Amount, o.TotalPrice.Amount)
+		assert.Equal(t, "EUR", o.TotalPrice.Currency)
 	})
 }
 
@@ -203,15 +353,15 @@ func TestFetchOrdersById(t *testing.T) {
 	defer db.Close()
 
 	// The query used in FetchOrdersById, matching the column order of Scan()
-	query := `select order_id, item_price, tax_price, shipping_price, total_price, order_status, paid_at, delivered_at, user_id, created_at from orders where user_id = \$1`
+	query := `select order_id, item_price, tax_price, shipping_price, total_price, order_status, paid_at, delivered_at, user_id, created_at, variant, coupon_code, discount, gift, gift_message, hide_prices, currency from orders where user_id = \$1`
 
 	// Create a sample expected order.
 	expOrder := models.Order{
 		OrderID:       uuid.New(),
-		ItemPrice:     100,
-		TaxPrice:      10,
-		ShippingPrice: 20,
-		TotalPrice:    130,
+		ItemPrice:     money.Of(100),
+		TaxPrice:      money.Of(10),
+		ShippingPrice: money.Of(20),
+		TotalPrice:    money.Of(130),
 		OrderStatus:   "pending",
 		PaidAt:        time.Now(),
 		DeliveredAt:   time.Now(),
@@ -221,7 +371,7 @@ func TestFetchOrdersById(t *testing.T) {
 
 	t.Run("Orders fetched successfully", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{
-			"order_id", "item_price", "tax_price", "shipping_price", "total_price", "order_status", "paid_at", "delivered_at", "user_id", "created_at",
+			"order_id", "item_price", "tax_price", "shipping_price", "total_price", "order_status", "paid_at", "delivered_at", "user_id", "created_at", "variant", "coupon_code", "discount", "gift", "gift_message", "hide_prices", "currency",
 		}).AddRow(
 			expOrder.OrderID,
 			expOrder.ItemPrice,
@@ -233,6 +383,13 @@ func TestFetchOrdersById(t *testing.T) {
 			expOrder.DeliveredAt,
 			expOrder.UserID,
 			expOrder.CreatedAt,
+			expOrder.Variant,
+			expOrder.CouponCode,
+			expOrder.Discount,
+			expOrder.Gift,
+			expOrder.GiftMessage,
+			expOrder.HidePrices,
+			"USD",
 		)
 
 		mock.ExpectQuery(query).WithArgs(expOrder.UserID).WillReturnRows(rows)
@@ -252,12 +409,12 @@ func TestFetchItemsById(t *testing.T) {
 	defer db.Close()
 
 	// Updated query: selecting specific columns in the defined order.
-	query := `select item_id, name, price, quantity, image, product_id, order_id, created_at from order_items where order_id = \$1`
+	query := `select i.item_id, i.name, i.price, o.currency, i.quantity, i.image, i.product_id, i.variant_id, i.order_id, i.created_at from order_items i join orders o on o.order_id = i.order_id where i.order_id = \$1`
 
 	item := models.Item{
 		ItemID:    uuid.New(),
 		Name:      "test_name",
-		Price:     100,
+		Price:     money.Of(100),
 		Quantity:  3,
 		Image:     "test_image",
 		ProductID: uuid.New(),
@@ -267,14 +424,16 @@ func TestFetchItemsById(t *testing.T) {
 
 	t.Run("Items fetched successfully", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{
-			"item_id", "name", "price", "quantity", "image", "product_id", "order_id", "created_at",
+			"item_id", "name", "price", "currency", "quantity", "image", "product_id", "variant_id", "order_id", "created_at",
 		}).AddRow(
 			item.ItemID,
 			item.Name,
 			item.Price,
+			"USD",
 			item.Quantity,
 			item.Image,
 			item.ProductID,
+			nil,
 			item.OrderID,
 			item.CreatedAt,
 		)
@@ -295,7 +454,7 @@ func TestFetchPaymentById(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	query := `select \* from payments where order_id = \$1`
+	query := `select payment_id, provider, status, order_id, created_at from payments where order_id = \$1`
 
 	payment := models.Payment{
 		ID:        "unique_id",
@@ -305,8 +464,8 @@ func TestFetchPaymentById(t *testing.T) {
 	}
 
 	t.Run("Payment fetched successfully", func(t *testing.T) {
-		row := sqlmock.NewRows([]string{"id", "status", "order_id", "created_at"}).
-			AddRow(payment.ID, payment.Status, payment.OrderID, payment.CreatedAt)
+		row := sqlmock.NewRows([]string{"payment_id", "provider", "status", "order_id", "created_at"}).
+			AddRow(payment.ID, models.PaymentPayPal, payment.Status, payment.OrderID, payment.CreatedAt)
 
 		mock.ExpectQuery(query).WithArgs(payment.OrderID).WillReturnRows(row)
 
@@ -364,12 +523,33 @@ func TestDeleteOrderById(t *testing.T) {
 	orderId := uuid.New()
 
 	t.Run("Order deleted successfully", func(t *testing.T) {
+		mock.ExpectBegin()
 		mock.ExpectExec(query).WithArgs(orderId).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		repo := repository.NewOrdersRepository(db)
 
-		err := repo.DeleteOrderById(orderId)
+		err := repo.DeleteOrderById(orderId, nil)
 		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Items go back to stock with the deletion", func(t *testing.T) {
+		productId, variantId := uuid.New(), uuid.New()
+		adjs := []models.StockAdjustment{{ProductID: productId, VariantID: uuid.NullUUID{UUID: variantId, Valid: true},
+			Quantity: 3, Reason: models.StockOrderDeleted, OrderID: uuid.NullUUID{UUID: orderId, Valid: true}}}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(query).WithArgs(orderId).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`update product_variants v set stock = v.stock \+ \$1 from products p\s+where v.variant_id = \$2 and v.product_id = \$3`).
+			WithArgs(3, variantId, productId).WillReturnRows(sqlmock.NewRows([]string{"stock", "low_stock_threshold", "name", "sku"}).AddRow(3, 5, "Kente Scarf", "KS-RED-M"))
+		mock.ExpectExec(`insert into inventory_adjustments`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		repo := repository.NewOrdersRepository(db)
+
+		require.NoError(t, repo.DeleteOrderById(orderId, adjs))
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -379,7 +559,7 @@ func TestFetchAllOrders(t *testing.T) {
 	defer db.Close()
 
 	// Updated query: selecting specific columns in the defined order.
-	query := `select order_id, user_id, paid_at, item_price, tax_price, shipping_price, total_price, order_status, delivered_at, created_at from orders`
+	query := `select order_id, user_id, paid_at, item_price, tax_price, shipping_price, total_price, order_status, delivered_at, created_at, variant, coupon_code, discount, gift, gift_message, hide_prices, currency from orders`
 
 	// Create a sample expected order.
 	ords := []*models.Order{
@@ -387,10 +567,10 @@ func TestFetchAllOrders(t *testing.T) {
 			OrderID:       uuid.New(),
 			UserID:        uuid.New(),
 			PaidAt:        time.Now(),
-			ItemPrice:     1,
-			TaxPrice:      1,
-			ShippingPrice: 1,
-			TotalPrice:    5,
+			ItemPrice:     money.Of(1),
+			TaxPrice:      money.Of(1),
+			ShippingPrice: money.Of(1),
+			TotalPrice:    money.Of(5),
 			OrderStatus:   "Processing",
 			DeliveredAt:   time.Now(),
 			CreatedAt:     time.Now(),
@@ -399,7 +579,7 @@ func TestFetchAllOrders(t *testing.T) {
 
 	t.Run("All orders successfully fetched", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{
-			"order_id", "user_id", "paid_at", "item_price", "tax_price", "shipping_price", "total_price", "order_status", "delivered_at", "created_at",
+			"order_id", "user_id", "paid_at", "item_price", "tax_price", "shipping_price", "total_price", "order_status", "delivered_at", "created_at", "variant", "coupon_code", "discount", "gift", "gift_message", "hide_prices", "currency",
 		}).AddRow(
 			ords[0].OrderID,
 			ords[0].UserID,
@@ -411,6 +591,13 @@ func TestFetchAllOrders(t *testing.T) {
 			ords[0].OrderStatus,
 			ords[0].DeliveredAt,
 			ords[0].CreatedAt,
+			ords[0].Variant,
+			ords[0].CouponCode,
+			ords[0].Discount,
+			ords[0].Gift,
+			ords[0].GiftMessage,
+			ords[0].HidePrices,
+			"USD",
 		)
 
 		mock.ExpectQuery(query).WithArgs().WillReturnRows(rows)
@@ -425,13 +612,57 @@ func TestFetchAllOrders(t *testing.T) {
 	})
 }
 
+func TestStreamOrderSummaries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOrdersRepository(db)
+
+	from, to := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"order_id", "user_id", "order_status", "currency", "item_price", "tax_price", "shipping_price",
+		"discount", "total_price", "coupon_code", "variant", "gift", "city", "country", "items", "created_at", "paid_at",
+		"delivered_at"}
+	query := `select o.order_id, .* from orders o .* where o.created_at >= \$1 and o.created_at < \$2 .* order by o.created_at`
+
+	t.Run("Every order streamed", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), uuid.New(), "Delivered", "EUR", 1000, 100, 200, 0, 1300, "", "", false, "Accra", "Ghana", 3, from, from, to).
+			AddRow(uuid.New(), uuid.New(), "Processing", "USD", 500, 0, 0, 50, 450, "SAVE10", "", true, "", "", 1, from, from, to)
+		mock.ExpectQuery(query).WithArgs(from, to).WillReturnRows(rows)
+
+		var summaries []models.OrderSummary
+		err := repo.StreamOrderSummaries(from, to, func(s *models.OrderSummary) error {
+			summaries = append(summaries, *s)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, summaries, 2)
+		assert.Equal(t, 3, summaries[0].ItemCount)
+		assert.Equal(t, "Ghana", summaries[0].ShippingInfo.Country)
+		assert.Equal(t, money.New(1300, "EUR"), summaries[0].TotalPrice)
+		assert.Equal(t, "SAVE10", summaries[1].CouponCode)
+	})
+
+	t.Run("Callback error stops the stream", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), uuid.New(), "Delivered", "EUR", 1000, 100, 200, 0, 1300, "", "", false, "Accra", "Ghana", 3, from, from, to)
+		mock.ExpectQuery(query).WithArgs(from, to).WillReturnRows(rows)
+
+		err := repo.StreamOrderSummaries(from, to, func(s *models.OrderSummary) error { return errors.New("write error") })
+		assert.EqualError(t, err, "write error")
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestFetchAllItems(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
 
 	// Updated query: selecting specific columns in the defined order.
-	query := `select item_id, name, price, quantity, image, product_id, order_id, created_at from order_items`
+	query := `select i.item_id, i.name, i.price, o.currency, i.quantity, i.image, i.product_id, i.variant_id, i.order_id, i.created_at from order_items i join orders o on o.order_id = i.order_id`
 
 	item := models.Item{
 		ItemID: uuid.New(),
@@ -440,8 +671,8 @@ func TestFetchAllItems(t *testing.T) {
 
 	t.Run("Items are successfully fetched", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{
-			"item_id", "name", "price", "quantity", "image", "product_id", "order_id", "created_at",
-		}).AddRow(item.ItemID, item.Name, item.Price, item.Quantity, item.Image, item.ProductID, item.OrderID, item.CreatedAt)
+			"item_id", "name", "price", "currency", "quantity", "image", "product_id", "variant_id", "order_id", "created_at",
+		}).AddRow(item.ItemID, item.Name, item.Price, "USD", item.Quantity, item.Image, item.ProductID, nil, item.OrderID, item.CreatedAt)
 
 		mock.ExpectQuery(query).WillReturnRows(rows)
 
@@ -459,8 +690,7 @@ func TestFetchAllPayment(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	// Updated query: selecting specific columns from payments.
-	query := `select payment_id, status, order_id, created_at from payments`
+	query := `select payment_id, provider, status, order_id, created_at from payments`
 
 	payment := models.Payment{
 		ID:        "test_id",
@@ -470,8 +700,8 @@ func TestFetchAllPayment(t *testing.T) {
 	}
 
 	t.Run("Payments successfully fetched", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"payment_id", "status", "order_id", "created_at"}).
-			AddRow(payment.ID, payment.Status, payment.OrderID, payment.CreatedAt)
+		rows := sqlmock.NewRows([]string{"payment_id", "provider", "status", "order_id", "created_at"}).
+			AddRow(payment.ID, models.PaymentStripe, payment.Status, payment.OrderID, payment.CreatedAt)
 
 		mock.ExpectQuery(query).WillReturnRows(rows)
 		repo := repository.NewOrdersRepository(db)
@@ -530,22 +760,345 @@ func TestFetchAllShipping(t *testing.T) {
 	})
 }
 
-func TestUpdateStock(t *testing.T) {
+func TestAdjustStock(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
 
-	query := `update products set stock = stock - \$1 where product_id = \$2`
+	repo := repository.NewOrdersRepository(db)
+	productId := uuid.New()
+	adminId := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	adj := models.StockAdjustment{ProductID: productId, Quantity: 10, Reason: models.StockRestock, UserID: adminId,
+		Note: "recount"}
+
+	t.Run("Stock is adjusted and logged", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`update products set stock = stock \+ \$1 where product_id = \$2`).
+			WithArgs(10, productId).WillReturnRows(sqlmock.NewRows([]string{"stock", "low_stock_threshold", "name", "sku"}).AddRow(12, 5, "Kente Scarf", ""))
+		mock.ExpectExec(`insert into inventory_adjustments \(product_id, variant_id, quantity, reason, order_id, user_id, note,`).
+			WithArgs(productId, uuid.NullUUID{}, 10, models.StockRestock, uuid.NullUUID{}, adminId, "recount",
+				sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.AdjustStock([]models.StockAdjustment{adj}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`update products set stock`).WithArgs(10, productId).WillReturnRows(sqlmock.NewRows([]string{"stock", "low_stock_threshold", "name", "sku"}))
+		mock.ExpectRollback()
+
+		err := repo.AdjustStock([]models.StockAdjustment{adj})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Alert is written as the stock falls to the threshold", func(t *testing.T) {
+		taken := models.StockAdjustment{ProductID: productId, Quantity: -3, Reason: models.StockRestock, UserID: adminId}
+		alert, err := json.Marshal(models.LowStockItem{ProductID: productId, Name: "Kente Scarf", Stock: 4, Threshold: 5})
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`update products set stock`).WithArgs(-3, productId).
+			WillReturnRows(sqlmock.NewRows([]string{"stock", "low_stock_threshold", "name", "sku"}).AddRow(4, 5, "Kente Scarf", ""))
+		mock.ExpectExec(`insert into outbox`).WithArgs(events.StockLow, alert).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`insert into inventory_adjustments`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.AdjustStock([]models.StockAdjustment{taken}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No alert when the stock was already low", func(t *testing.T) {
+		taken := models.StockAdjustment{ProductID: productId, Quantity: -1, Reason: models.StockRestock, UserID: adminId}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`update products set stock`).WithArgs(-1, productId).
+			WillReturnRows(sqlmock.NewRows([]string{"stock", "low_stock_threshold", "name", "sku"}).AddRow(3, 5, "Kente Scarf", ""))
+		mock.ExpectExec(`insert into inventory_adjustments`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.AdjustStock([]models.StockAdjustment{taken}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFetchStockAdjustments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
 
+	repo := repository.NewOrdersRepository(db)
 	productId := uuid.New()
-	quantity := 5
+	orderId := uuid.New()
+
+	mock.ExpectQuery(`select adjustment_id, .+ from inventory_adjustments where product_id = \$1 order by created_at desc limit \$2`).
+		WithArgs(productId, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"adjustment_id", "product_id", "variant_id", "quantity", "reason", "order_id",
+			"user_id", "note", "created_at"}).
+			AddRow(uuid.New(), productId, nil, -2, models.StockShipped, orderId, nil, "", time.Now()))
+
+	adjs, err := repo.FetchStockAdjustments(productId, 50)
+	require.NoError(t, err)
+	require.Len(t, adjs, 1)
+	assert.Equal(t, -2, adjs[0].Quantity)
+	assert.Equal(t, uuid.NullUUID{UUID: orderId, Valid: true}, adjs[0].OrderID)
+	assert.False(t, adjs[0].VariantID.Valid)
+}
+
+func TestFetchOrderAdjustments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOrdersRepository(db)
+	orderId := uuid.New()
+
+	mock.ExpectQuery(`select adjustment_id, .+ from inventory_adjustments where order_id = \$1 order by created_at`).
+		WithArgs(orderId).
+		WillReturnRows(sqlmock.NewRows([]string{"adjustment_id", "product_id", "variant_id", "quantity", "reason", "order_id",
+			"user_id", "note", "created_at"}).
+			AddRow(uuid.New(), uuid.New(), nil, -2, models.StockShipped, orderId, nil, "", time.Now()))
+
+	adjs, err := repo.FetchOrderAdjustments(orderId)
+	require.NoError(t, err)
+	require.Len(t, adjs, 1)
+	assert.Equal(t, -2, adjs[0].Quantity)
+	assert.Equal(t, models.StockShipped, adjs[0].Reason)
+}
+
+func TestFetchOrderIds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := `select order_id from orders where order_id in \(\$1, \$2\)`
+
+	a, b := uuid.New(), uuid.New()
+
+	t.Run("Existing ids are returned", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(a, b).WillReturnRows(sqlmock.NewRows([]string{"order_id"}).AddRow(a))
+
+		repo := repository.NewOrdersRepository(db)
+
+		ids, err := repo.FetchOrderIds([]uuid.UUID{a, b})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{a}, ids)
+	})
+}
+
+func TestFetchPickLines(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := `select i.product_id, min\(i.name\), coalesce\(p.sku, ''\), sum\(i.quantity\), count\(distinct i.order_id\)
+				from order_items i
+				left join products p on p.product_id = i.product_id
+				where i.order_id in \(\$1, \$2\)
+				group by i.product_id, p.sku`
+
+	a, b, productId := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("Items are summed per product", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"product_id", "name", "sku", "quantity", "orders"}).
+			AddRow(productId, "Camera", "CAM-1", 3, 2)
+		mock.ExpectQuery(query).WithArgs(a, b).WillReturnRows(rows)
+
+		repo := repository.NewOrdersRepository(db)
+
+		lines, err := repo.FetchPickLines([]uuid.UUID{a, b})
+		require.NoError(t, err)
+		require.Len(t, lines, 1)
+		assert.Equal(t, productId, lines[0].ProductID)
+		assert.Equal(t, 3, lines[0].Quantity)
+		assert.Equal(t, 2, lines[0].Orders)
+	})
+}
+
+func TestFetchUncapturedPayments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := `select p.payment_id, p.provider, p.status, p.order_id, p.created_at
+				from payments p
+				join orders o on o.order_id = p.order_id
+				where p.status = \$1 and p.created_at < \$2 and o.order_status = \$3
+				order by p.created_at`
+
+	before, orderId := time.Now(), uuid.New()
 
-	t.Run("Stock is successfully updated", func(t *testing.T) {
-		mock.ExpectExec(query).WithArgs(quantity, productId).WillReturnResult(sqlmock.NewResult(1, 1))
+	t.Run("Payments waiting for capture are returned", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"payment_id", "provider", "status", "order_id", "created_at"}).
+			AddRow("pi_1", models.PaymentStripe, models.PaymentRequiresCapture, orderId, before.Add(-time.Hour))
+		mock.ExpectQuery(query).WithArgs(models.PaymentRequiresCapture, before, models.OrderProcessing).WillReturnRows(rows)
 
 		repo := repository.NewOrdersRepository(db)
 
-		err := repo.UpdateStock(productId, quantity)
+		payments, err := repo.FetchUncapturedPayments(context.Background(), before)
 		require.NoError(t, err)
+		require.Len(t, payments, 1)
+		assert.Equal(t, "pi_1", payments[0].ID)
+		assert.Equal(t, models.PaymentStripe, payments[0].Provider)
+		assert.Equal(t, orderId, payments[0].OrderID)
 	})
 }
+
+func TestUpdatePaymentStatusById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := `update payments set status = \$1 where payment_id = \$2 and provider = \$3`
+	repo := repository.NewOrdersRepository(db)
+
+	t.Run("Payment status updated", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(models.PaymentSucceeded, "5O190127TN364715T", models.PaymentPayPal).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.UpdatePaymentStatusById(context.Background(), models.PaymentPayPal, "5O190127TN364715T", models.PaymentSucceeded))
+	})
+
+	t.Run("Payment not found", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(models.PaymentCanceled, "pi_1", models.PaymentStripe).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdatePaymentStatusById(context.Background(), models.PaymentStripe, "pi_1", models.PaymentCanceled)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestReplacePayment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	orderId := uuid.New()
+	query := `update payments set payment_id = \$1, provider = \$2, status = \$3, created_at = \$4 where order_id = \$5`
+	repo := repository.NewOrdersRepository(db)
+
+	t.Run("Payment replaced", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs("pi_2", models.PaymentStripe, "requires_payment_method", sqlmock.AnyArg(), orderId).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.ReplacePayment(context.Background(), orderId, models.Payment{ID: "pi_2", Status: "requires_payment_method"}))
+	})
+
+	t.Run("Order without payment", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs("ORDER-1", models.PaymentPayPal, models.PaymentRequiresAction, sqlmock.AnyArg(), orderId).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.ReplacePayment(context.Background(), orderId, models.Payment{ID: "ORDER-1", Provider: models.PaymentPayPal, Status: models.PaymentRequiresAction})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestVoidOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	orderId := uuid.New()
+
+	t.Run("Payment and order are cancelled together", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`update payments set status = \$1 where order_id = \$2`).
+			WithArgs(models.PaymentCanceled, orderId).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`update orders set order_status = \$1 where order_id = \$2`).
+			WithArgs(models.OrderCancelled, orderId).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`select order_id, .+ from orders where order_id = \$1`).WithArgs(orderId).
+			WillReturnRows(orderRows().AddRow(orderId, 100, 10, 20, 130, models.OrderCancelled, time.Now(), time.Time{},
+				uuid.New(), time.Now(), "", "", 0, false, "", false, "USD"))
+		mock.ExpectExec(`insert into outbox \(name, payload\) values \(\$1, \$2\)`).
+			WithArgs(events.OrderStatusChanged, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		repo := repository.NewOrdersRepository(db)
+
+		require.NoError(t, repo.VoidOrder(orderId))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCancelOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	c := models.OrderCancellation{OrderID: uuid.New(), UserID: uuid.New(), Reason: "ordered twice"}
+	repo := repository.NewOrdersRepository(db)
+
+	t.Run("Order is cancelled with its payment", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`update orders set order_status = \$1 where order_id = \$2 and order_status = \$3`).
+			WithArgs(models.OrderCancelled, c.OrderID, models.OrderProcessing).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`insert into order_cancellations \(order_id, user_id, reason, created_at\)`).
+			WithArgs(c.OrderID, c.UserID, c.Reason, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`update payments set status = \$1 where order_id = \$2`).
+			WithArgs(models.PaymentRefundPending, c.OrderID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`select order_id, .+ from orders where order_id = \$1`).WithArgs(c.OrderID).
+			WillReturnRows(orderRows().AddRow(c.OrderID, 100, 10, 20, 130, models.OrderCancelled, time.Now(), time.Time{},
+				c.UserID, time.Now(), "", "", 0, false, "", false, "USD"))
+		mock.ExpectExec(`insert into outbox \(name, payload\) values \(\$1, \$2\)`).
+			WithArgs(events.OrderStatusChanged, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.CancelOrder(c, models.PaymentRefundPending))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Order no longer processing", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`update orders set order_status = \$1 where order_id = \$2 and order_status = \$3`).
+			WithArgs(models.OrderCancelled, c.OrderID, models.OrderProcessing).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.CancelOrder(c, models.PaymentRefundPending)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFetchInvoice(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	orderId := uuid.New()
+	query := `select order_id, url, public_id, created_at from invoices where order_id = \$1`
+	repo := repository.NewOrdersRepository(db)
+
+	t.Run("Archived invoice", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orderId).WillReturnRows(sqlmock.NewRows([]string{"order_id", "url", "public_id", "created_at"}).
+			AddRow(orderId, "https://cdn.test/invoices/1.pdf", "invoices/1.pdf", time.Now()))
+
+		inv, err := repo.FetchInvoice(orderId)
+		require.NoError(t, err)
+		assert.Equal(t, "invoices/1.pdf", inv.PublicID)
+	})
+
+	t.Run("Invoice not archived", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orderId).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.FetchInvoice(orderId)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestInsertInvoice(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	inv := models.Invoice{OrderID: uuid.New(), URL: "https://cdn.test/invoices/1.pdf", PublicID: "invoices/1.pdf"}
+
+	mock.ExpectExec(`insert into invoices \(order_id, url, public_id, created_at\) values \(\$1, \$2, \$3, \$4\)\s+on conflict \(order_id\) do nothing`).
+		WithArgs(inv.OrderID, inv.URL, inv.PublicID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repository.NewOrdersRepository(db).InsertInvoice(inv))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,13 +1,18 @@
 package orders
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/realtime"
 )
 
 type OrderUC interface {
 	// CreateOrder process and save orders, returns orders when successful and error when failed
-	CreateOrder(order models.Order) (*models.Order, error)
+	CreateOrder(ctx context.Context, order models.Order) (*models.Order, error)
 
 	// GetSingleOrder returns a single order by id, return error when failed
 	GetSingleOrder(id uuid.UUID) (*models.Order, error)
@@ -18,12 +23,61 @@ type OrderUC interface {
 	// GetAllOrders returns all orders and return an error when failed
 	GetAllOrders() ([]*models.Order, error)
 
-	// UpdateStock updates the product's quantity, returns an error on failure
-	UpdateStock(productId uuid.UUID, quantity int) error
-
-	// UpdateOrder updates an order, returns an error on failure
+	// UpdateOrder updates an order, takes its items from stock when it ships and publishes its status change,
+	// returns an error on failure
 	UpdateOrder(order models.Order) error
 
-	// DeleteOrder deletes an order, returns an error on failure
+	// SubscribeOrder subscribes to the status changes of an order, for the owner of the order or an admin,
+	// returns the order and an error when it is missing
+	SubscribeOrder(id uuid.UUID, user *models.User) (*models.Order, *realtime.Subscription, error)
+
+	// DeleteOrder deletes an order, giving back the stock it holds, returns an error on failure
 	DeleteOrder(orderId uuid.UUID) error
+
+	// AdjustStock corrects the stock of a product or of its variant and logs it, returns an error when the
+	// product or variant is missing
+	AdjustStock(adj models.StockAdjustment) error
+
+	// GetStockAdjustments returns the latest adjustments of the stock of a product, returns an error on failure
+	GetStockAdjustments(productId uuid.UUID, limit int) ([]models.StockAdjustment, error)
+
+	// GetPickList sums the items to pick across the given orders, returns an error on failure
+	GetPickList(orderIds []uuid.UUID) (*models.PickList, error)
+
+	// GetPackingSlip returns what to pack and where to ship for an order, returns an error on failure
+	GetPackingSlip(orderId uuid.UUID) (*models.PackingSlip, error)
+
+	// GetPayableOrder returns an order of userID whose payment has not gone through, to pay it again, returns
+	// an error when the order is missing, paid or cancelled
+	GetPayableOrder(ctx context.Context, id, userID uuid.UUID) (*models.Order, error)
+
+	// CancelOrder cancels a processing order of userID with a reason, voiding or marking its payment for
+	// refund, returns the order and an error when it is missing, cancelled or shipped
+	CancelOrder(id, userID uuid.UUID, reason string) (*models.Order, error)
+
+	// AttachPayment replaces the outstanding payment of an order with p, returns an error on failure
+	AttachPayment(ctx context.Context, orderId uuid.UUID, p models.Payment) error
+
+	// GetInvoice returns an order with its invoice as PDF, for the owner of the order or an admin, returns
+	// an error when the order is missing
+	GetInvoice(id uuid.UUID, user *models.User) (*models.Order, []byte, error)
+
+	// ArchiveInvoice archives the invoice of a paid order once, when archiving is enabled, returns an error
+	// on failure
+	ArchiveInvoice(ctx context.Context, order *models.Order, doc []byte) error
+
+	// CapturePayment captures the authorized payment of an order that ships, returns an error on failure
+	CapturePayment(order *models.Order) error
+
+	// UpdatePaymentStatus records the status a provider webhook reported for a payment, returns an error
+	// on failure
+	UpdatePaymentStatus(ctx context.Context, provider, id, status string) error
+
+	// ExportOrders writes the orders created in [from, to) as CSV, with pseudonyms instead of ids when
+	// anonymize is set, returns an error on failure
+	ExportOrders(w io.Writer, from, to time.Time, anonymize bool) error
+
+	// VoidUncapturedPayments voids the payments of orders not shipped within the capture window,
+	// returns how many were voided
+	VoidUncapturedPayments(ctx context.Context) (int, error)
 }
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/realtime"
+)
+
+// StatusPusher returns the handler of events.OrderStatusChanged that pushes the new
+// status of the order to its subscribers on hub.
+func StatusPusher(hub *realtime.Hub) events.Handler {
+	return func(e events.Event) error {
+		order, ok := e.Data.(models.Order)
+		if !ok {
+			return fmt.Errorf("unexpected %s event data %T", e.Name, e.Data)
+		}
+
+		hub.Publish(order.OrderID.String(), realtime.Event{
+			Name: models.EventOrderStatusChanged,
+			Data: models.OrderStatusChange{OrderID: order.OrderID, Status: order.OrderStatus, ChangedAt: e.OccurredAt},
+		})
+
+		return nil
+	}
+}
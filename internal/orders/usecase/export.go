@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/orders"
+)
+
+// exportColumns is the header of the order export.
+var exportColumns = []string{"order_id", "user_id", "status", "currency", "items", "items_price", "tax_price",
+	"shipping_price", "discount", "total_price", "coupon_code", "variant", "gift", "city", "country", "created_at",
+	"paid_at", "delivered_at"}
+
+// ExportOrders writes the orders created in [from, to) as CSV, one line per order,
+// for analysis. Contact details, addresses and gift messages are never exported. With
+// anonymize, order and user ids are replaced with stable pseudonyms, so the orders of
+// a customer stay linkable without identifying them, and the city is left out; it
+// fails with orders.ErrAnonymizationDisabled without a pseudonym key.
+func (o *OrderUC) ExportOrders(w io.Writer, from, to time.Time, anonymize bool) error {
+	if anonymize && o.pseudonyms == nil {
+		return orders.ErrAnonymizationDisabled
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportColumns); err != nil {
+		return err
+	}
+
+	err := o.repo.StreamOrderSummaries(from, to, func(s *models.OrderSummary) error {
+		orderID, userID, city := s.OrderID.String(), s.UserID.String(), s.ShippingInfo.City
+		if anonymize {
+			orderID, userID, city = o.pseudonyms.Of("order", orderID), o.pseudonyms.Of("user", userID), ""
+		}
+
+		return cw.Write([]string{
+			orderID,
+			userID,
+			s.OrderStatus,
+			s.Currency,
+			strconv.Itoa(s.ItemCount),
+			s.ItemPrice.Decimal(),
+			s.TaxPrice.Decimal(),
+			s.ShippingPrice.Decimal(),
+			s.Discount.Decimal(),
+			s.TotalPrice.Decimal(),
+			s.CouponCode,
+			s.Variant,
+			strconv.FormatBool(s.Gift),
+			city,
+			s.ShippingInfo.Country,
+			exportTime(s.CreatedAt),
+			exportTime(s.PaidAt),
+			exportTime(s.DeliveredAt),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("error exporting orders: %v", err)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportTime formats t as RFC 3339 in UTC, or blank when it is not set.
+func exportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
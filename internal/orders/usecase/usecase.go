@@ -1,69 +1,90 @@
 package usecase
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/internal/models"
 	"github.com/jofosuware/go/shopit/internal/orders"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/invoice"
+	"github.com/jofosuware/go/shopit/pkg/payments"
+	"github.com/jofosuware/go/shopit/pkg/pseudonym"
+	"github.com/jofosuware/go/shopit/pkg/realtime"
+	"github.com/jofosuware/go/shopit/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// DefaultAdjustmentsLimit is how many adjustments GetStockAdjustments returns when
+	// no limit is given.
+	DefaultAdjustmentsLimit = 50
+
+	// MaxAdjustmentsLimit caps the adjustments GetStockAdjustments returns.
+	MaxAdjustmentsLimit = 200
 )
 
+// DefaultCaptureWindow is how long an authorized payment waits for its order to ship.
+// Stripe releases card authorizations after seven days.
+const DefaultCaptureWindow = 6 * 24 * time.Hour
+
 // OrderUC provides order-related use cases.
 type OrderUC struct {
-	repo orders.Repo
+	repo          orders.Repo
+	providers     payments.Providers
+	captureWindow time.Duration
+	invoices      *invoice.Generator
+	pseudonyms    *pseudonym.Hasher
+	events        *events.Bus
+	hub           *realtime.Hub
+	now           func() time.Time
 }
 
-// NewOrderUC returns a new OrderUC.
-func NewOrderUC(repo orders.Repo) *OrderUC {
-	return &OrderUC{
-		repo: repo,
+// NewOrderUC returns a new OrderUC. Authorized payments are captured through the
+// provider they were made with when their order ships and voided when it has not
+// shipped within captureWindow; a non-positive captureWindow falls back to
+// DefaultCaptureWindow. Invoices are rendered and archived with invoices, and
+// anonymized exports pseudonymize ids with pseudonyms; a nil pseudonyms disables
+// them. Orders placed and their status changes are published on bus; SubscribeOrder
+// subscribes to the status changes StatusPusher pushes on hub, and a nil hub gets a
+// hub of its own.
+func NewOrderUC(repo orders.Repo, providers payments.Providers, captureWindow time.Duration,
+	invoices *invoice.Generator, pseudonyms *pseudonym.Hasher, bus *events.Bus, hub *realtime.Hub) *OrderUC {
+	if captureWindow <= 0 {
+		captureWindow = DefaultCaptureWindow
 	}
-}
-
-// CreateOrder creates an order and persists related records (shipping, items, payment).
-func (o *OrderUC) CreateOrder(ord models.Order) (*models.Order, error) {
-	order, err := o.repo.InsertOrder(ord)
-	if err != nil {
-		return nil, err
+	if hub == nil {
+		hub = realtime.NewHub()
 	}
 
-	// Update the ShippingInfo's order id
-	ord.ShippingInfo.OrderID = order.OrderID
-
-	shipping, err := o.repo.InsertShipping(ord.ShippingInfo)
-	if err != nil {
-		err = o.repo.DeleteOrderById(order.OrderID)
-		if err != nil {
-			return nil, err
-		}
-		return nil, err
+	return &OrderUC{
+		repo:          repo,
+		providers:     providers,
+		captureWindow: captureWindow,
+		invoices:      invoices,
+		pseudonyms:    pseudonyms,
+		events:        bus,
+		hub:           hub,
+		now:           time.Now,
 	}
+}
 
-	// Update the OrderItems order id
-	ord.OrderItems[0].OrderID = order.OrderID
+// CreateOrder creates an order and persists related records (shipping, items, payment).
+// Its confirmation is sent from the outbox the repository writes it to.
+func (o *OrderUC) CreateOrder(ctx context.Context, ord models.Order) (_ *models.Order, err error) {
+	ctx, span := tracing.Start(ctx, "OrderUC.CreateOrder")
+	defer tracing.End(span, &err)
 
-	item, err := o.repo.InsertItem(*ord.OrderItems[0])
+	order, err := o.repo.CreateOrder(ctx, ord)
 	if err != nil {
-		err = o.repo.DeleteOrderById(order.OrderID)
-		if err != nil {
-			return nil, err
-		}
 		return nil, err
 	}
 
-	// Update the PaymentInfo's order id
-	ord.PaymentInfo.OrderID = order.OrderID
-
-	payment, err := o.repo.InsertPayment(ord.PaymentInfo)
-	if err != nil {
-		err = o.repo.DeleteOrderById(order.OrderID)
-		if err != nil {
-			return nil, err
-		}
-		return nil, err
-	}
-
-	order.ShippingInfo = *shipping
-	order.OrderItems = append(order.OrderItems, item)
-	order.PaymentInfo = *payment
+	o.events.Publish(events.OrderCreated, *order)
 
 	return order, nil
 }
@@ -170,19 +191,146 @@ func (o *OrderUC) GetAllOrders() ([]*models.Order, error) {
 	return ords, nil
 }
 
-// UpdateOrder updates an order.
+// UpdateOrder updates an order and publishes its status change, and its delivery
+// when it is delivered. An order takes its items from stock once, when it moves to a
+// status that holds stock from one that does not; it returns orders.ErrOrderNotFound
+// when there is no such order.
 func (o *OrderUC) UpdateOrder(order models.Order) error {
-	err := o.repo.UpdateOrder(order.OrderID, order)
+	current, err := o.repo.FetchOrderById(order.OrderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return orders.ErrOrderNotFound
+		}
+		return fmt.Errorf("error fetching order: %v", err)
+	}
+
+	var adjs []models.StockAdjustment
+	if !models.OrderHoldsStock(current.OrderStatus) && models.OrderHoldsStock(order.OrderStatus) {
+		adjs, err = o.itemAdjustments(order.OrderID, -1, models.StockShipped)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = o.repo.UpdateOrder(order.OrderID, order, adjs)
 	if err != nil {
 		return err
 	}
 
+	o.publishStatus(order)
+
 	return nil
 }
 
-// UpdateStock updates a product's stock by decrementing the given quantity.
-func (o *OrderUC) UpdateStock(productId uuid.UUID, quantity int) error {
-	err := o.repo.UpdateStock(productId, quantity)
+// SubscribeOrder subscribes to the status changes of the order id, for the owner of
+// the order or an admin, and returns the order as it is once subscribed. Orders of
+// other users are not found.
+func (o *OrderUC) SubscribeOrder(id uuid.UUID, user *models.User) (*models.Order, *realtime.Subscription, error) {
+	// subscribe first so that no change is missed between reading the order and
+	// subscribing
+	sub := o.hub.Subscribe(id.String())
+
+	order, err := o.repo.FetchOrderById(id)
+	if err != nil {
+		sub.Close()
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, orders.ErrOrderNotFound
+		}
+		return nil, nil, fmt.Errorf("error fetching order: %v", err)
+	}
+
+	if order.UserID != user.ID && user.Role != models.RoleAdmin {
+		sub.Close()
+		return nil, nil, orders.ErrOrderNotFound
+	}
+
+	return order, sub, nil
+}
+
+// publishStatus publishes the status change of order, and its delivery when it is
+// delivered.
+func (o *OrderUC) publishStatus(order models.Order) {
+	o.events.Publish(events.OrderStatusChanged, order)
+	if order.OrderStatus == models.OrderDelivered {
+		o.events.Publish(events.OrderDelivered, order)
+	}
+}
+
+// restockAdjustments returns the adjustments giving back to stock what the adjustments
+// taken made for an order still hold, per product or variant.
+func restockAdjustments(orderId uuid.UUID, taken []models.StockAdjustment) []models.StockAdjustment {
+	type stockItem struct {
+		product uuid.UUID
+		variant uuid.NullUUID
+	}
+
+	var items []stockItem
+	held := map[stockItem]int{}
+	for _, a := range taken {
+		item := stockItem{a.ProductID, a.VariantID}
+		if _, ok := held[item]; !ok {
+			items = append(items, item)
+		}
+		held[item] -= a.Quantity
+	}
+
+	var adjs []models.StockAdjustment
+	for _, item := range items {
+		if held[item] > 0 {
+			adjs = append(adjs, models.StockAdjustment{
+				ProductID: item.product,
+				VariantID: item.variant,
+				Quantity:  held[item],
+				Reason:    models.StockOrderDeleted,
+				OrderID:   uuid.NullUUID{UUID: orderId, Valid: true},
+			})
+		}
+	}
+
+	return adjs
+}
+
+// itemAdjustments returns the adjustments moving the items of an order in or out of
+// stock: sign is 1 to give them back and -1 to take them.
+func (o *OrderUC) itemAdjustments(orderId uuid.UUID, sign int, reason string) ([]models.StockAdjustment, error) {
+	items, err := o.repo.FetchItemsById(orderId)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching items: %v", err)
+	}
+
+	adjs := make([]models.StockAdjustment, 0, len(items))
+	for _, i := range items {
+		adjs = append(adjs, models.StockAdjustment{
+			ProductID: i.ProductID,
+			VariantID: i.VariantID,
+			Quantity:  sign * i.Quantity,
+			Reason:    reason,
+			OrderID:   uuid.NullUUID{UUID: orderId, Valid: true},
+		})
+	}
+
+	return adjs, nil
+}
+
+// DeleteOrder deletes an order by ID. The units the inventory log shows the order
+// took from stock go back to stock, so an order that never shipped, or shipped before
+// stock was taken on shipping, leaves the stock as it is. It returns
+// orders.ErrOrderNotFound when there is no such order.
+func (o *OrderUC) DeleteOrder(orderId uuid.UUID) error {
+	_, err := o.repo.FetchOrderById(orderId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return orders.ErrOrderNotFound
+		}
+		return fmt.Errorf("error fetching order: %v", err)
+	}
+
+	taken, err := o.repo.FetchOrderAdjustments(orderId)
+	if err != nil {
+		return fmt.Errorf("error fetching stock adjustments: %v", err)
+	}
+
+	err = o.repo.DeleteOrderById(orderId, restockAdjustments(orderId, taken))
 	if err != nil {
 		return err
 	}
@@ -190,12 +338,364 @@ func (o *OrderUC) UpdateStock(productId uuid.UUID, quantity int) error {
 	return nil
 }
 
-// DeleteOrder deletes an order by ID.
-func (o *OrderUC) DeleteOrder(orderId uuid.UUID) error {
-	err := o.repo.DeleteOrderById(orderId)
+// AdjustStock corrects the stock of a product, or of its variant, by the signed
+// quantity of adj and logs it as a restock. It returns orders.ErrStockItemNotFound
+// when there is no such product or variant.
+func (o *OrderUC) AdjustStock(adj models.StockAdjustment) error {
+	adj.Reason = models.StockRestock
+
+	if err := o.repo.AdjustStock([]models.StockAdjustment{adj}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return orders.ErrStockItemNotFound
+		}
+		return fmt.Errorf("error adjusting stock: %v", err)
+	}
+
+	return nil
+}
+
+// GetStockAdjustments returns the latest limit adjustments of the stock of a product
+// and its variants, newest first; DefaultAdjustmentsLimit when limit is not positive,
+// at most MaxAdjustmentsLimit.
+func (o *OrderUC) GetStockAdjustments(productId uuid.UUID, limit int) ([]models.StockAdjustment, error) {
+	if limit <= 0 {
+		limit = DefaultAdjustmentsLimit
+	}
+	if limit > MaxAdjustmentsLimit {
+		limit = MaxAdjustmentsLimit
+	}
+
+	adjs, err := o.repo.FetchStockAdjustments(productId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching stock adjustments: %v", err)
+	}
+
+	return adjs, nil
+}
+
+// GetPickList sums the items to pick across the given orders. Duplicate ids are
+// ignored, and every id must belong to an existing order.
+func (o *OrderUC) GetPickList(orderIds []uuid.UUID) (*models.PickList, error) {
+	seen := make(map[uuid.UUID]bool, len(orderIds))
+	var ids []uuid.UUID
+	for _, id := range orderIds {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 || len(ids) > orders.MaxPickListOrders {
+		return nil, orders.ErrInvalidSelection
+	}
+
+	found, err := o.repo.FetchOrderIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(found) != len(ids) {
+		exists := make(map[uuid.UUID]bool, len(found))
+		for _, id := range found {
+			exists[id] = true
+		}
+		for _, id := range ids {
+			if !exists[id] {
+				return nil, fmt.Errorf("%w: %s", orders.ErrOrderNotFound, id)
+			}
+		}
+	}
+
+	lines, err := o.repo.FetchPickLines(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	list := models.PickList{
+		Orders:    ids,
+		Lines:     lines,
+		CreatedAt: time.Now(),
+	}
+	for _, l := range lines {
+		list.TotalUnits += l.Quantity
+	}
+
+	return &list, nil
+}
+
+// GetPackingSlip returns the items to pack for an order and where to ship it. The
+// prices are left out of gift orders that hide them.
+func (o *OrderUC) GetPackingSlip(orderId uuid.UUID) (*models.PackingSlip, error) {
+	order, err := o.repo.FetchOrderById(orderId)
 	if err != nil {
+		return nil, err
+	}
+
+	shipping, err := o.repo.FetchShippingById(orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := o.repo.FetchPickLines([]uuid.UUID{orderId})
+	if err != nil {
+		return nil, err
+	}
+
+	slip := models.PackingSlip{
+		OrderID:     order.OrderID,
+		OrderStatus: order.OrderStatus,
+		OrderedAt:   order.CreatedAt,
+		ShipTo:      *shipping,
+		Gift:        order.Gift,
+		GiftMessage: order.GiftMessage,
+	}
+	if !order.Gift || !order.HidePrices {
+		slip.Totals = &models.SlipTotals{
+			ItemsPrice:    order.ItemPrice,
+			ShippingPrice: order.ShippingPrice,
+			TaxPrice:      order.TaxPrice,
+			Discount:      order.Discount,
+			TotalPrice:    order.TotalPrice,
+		}
+	}
+	for _, l := range lines {
+		slip.Items = append(slip.Items, l.PackingItem)
+		slip.TotalUnits += l.Quantity
+	}
+
+	return &slip, nil
+}
+
+// CapturePayment captures the payment of an order that ships when it was only
+// authorized. Payments charged straight away have nothing to capture.
+func (o *OrderUC) CapturePayment(order *models.Order) error {
+	switch order.PaymentInfo.Status {
+	case models.PaymentCanceled:
+		return orders.ErrPaymentVoided
+	case models.PaymentRequiresCapture:
+	default:
+		return nil
+	}
+
+	provider, err := o.providers.Get(order.PaymentInfo.Provider)
+	if err != nil {
+		return fmt.Errorf("%w: %v", orders.ErrCaptureFailed, err)
+	}
+
+	if err := provider.CapturePayment(order.PaymentInfo.ID); err != nil {
+		return fmt.Errorf("%w: %v", orders.ErrCaptureFailed, err)
+	}
+
+	if err := o.repo.UpdatePaymentStatus(order.OrderID, models.PaymentSucceeded); err != nil {
 		return err
 	}
+	order.PaymentInfo.Status = models.PaymentSucceeded
+
+	return nil
+}
+
+// VoidUncapturedPayments voids the authorized payments of orders that have not shipped
+// within the capture window and cancels the orders. A payment that cannot be voided is
+// skipped, so the others are still voided; the failures are returned together. Once ctx
+// is done no other payment is voided, the rest waiting for the next run.
+func (o *OrderUC) VoidUncapturedPayments(ctx context.Context) (int, error) {
+	payments, err := o.repo.FetchUncapturedPayments(ctx, o.now().Add(-o.captureWindow))
+	if err != nil {
+		return 0, fmt.Errorf("error fetching uncaptured payments: %v", err)
+	}
+
+	var voided int
+	var errs []error
+	for _, p := range payments {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		provider, err := o.providers.Get(p.Provider)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error voiding payment %s: %v", p.ID, err))
+			continue
+		}
+
+		if err := provider.VoidPayment(p.ID); err != nil {
+			errs = append(errs, fmt.Errorf("error voiding payment %s: %v", p.ID, err))
+			continue
+		}
+
+		if err := o.repo.VoidOrder(p.OrderID); err != nil {
+			errs = append(errs, fmt.Errorf("error cancelling order %s: %v", p.OrderID, err))
+			continue
+		}
+		voided++
+
+		order, err := o.repo.FetchOrderById(p.OrderID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error fetching cancelled order %s: %v", p.OrderID, err))
+			continue
+		}
+		o.publishStatus(*order)
+	}
+
+	return voided, errors.Join(errs...)
+}
+
+// GetPayableOrder returns the order id of userID, with its items and payment, when
+// its payment has not gone through so that it can be paid again. Orders of other
+// users are not found.
+func (o *OrderUC) GetPayableOrder(ctx context.Context, id, userID uuid.UUID) (_ *models.Order, err error) {
+	_, span := tracing.Start(ctx, "OrderUC.GetPayableOrder", attribute.String("order.id", id.String()))
+	defer tracing.End(span, &err)
+
+	order, err := o.GetSingleOrder(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, orders.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("error fetching order: %v", err)
+	}
+
+	switch {
+	case order.UserID != userID:
+		return nil, orders.ErrOrderNotFound
+	case order.OrderStatus == models.OrderCancelled:
+		return nil, orders.ErrOrderCancelled
+	case !order.PaymentInfo.Outstanding():
+		return nil, orders.ErrOrderPaid
+	}
+
+	return order, nil
+}
+
+// CancelOrder cancels the order id of userID while it is still processing, recording
+// reason, and publishes its status change. An authorized payment is voided; a payment
+// that took the money, or may still take it, is marked pending refund. Stock is taken
+// when an order ships, so a processing order has none to give back. Orders of other
+// users are not found; it returns orders.ErrOrderCancelled for a cancelled order and
+// orders.ErrOrderShipped for one that has shipped.
+func (o *OrderUC) CancelOrder(id, userID uuid.UUID, reason string) (*models.Order, error) {
+	order, err := o.GetSingleOrder(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, orders.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("error fetching order: %v", err)
+	}
+
+	switch {
+	case order.UserID != userID:
+		return nil, orders.ErrOrderNotFound
+	case order.OrderStatus == models.OrderCancelled:
+		return nil, orders.ErrOrderCancelled
+	case order.OrderStatus != models.OrderProcessing:
+		return nil, orders.ErrOrderShipped
+	}
+
+	status := models.PaymentCanceled
+	switch order.PaymentInfo.Status {
+	case models.PaymentSucceeded, models.PaymentProcessing:
+		status = models.PaymentRefundPending
+	case models.PaymentRequiresCapture:
+		provider, err := o.providers.Get(order.PaymentInfo.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("error voiding payment: %v", err)
+		}
+		if err := provider.VoidPayment(order.PaymentInfo.ID); err != nil {
+			return nil, fmt.Errorf("error voiding payment: %v", err)
+		}
+	}
+
+	c := models.OrderCancellation{OrderID: order.OrderID, UserID: userID, Reason: reason}
+	if err := o.repo.CancelOrder(c, status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// shipped since it was fetched
+			return nil, orders.ErrOrderShipped
+		}
+		return nil, fmt.Errorf("error cancelling order: %v", err)
+	}
+
+	order.OrderStatus = models.OrderCancelled
+	order.PaymentInfo.Status = status
+	o.publishStatus(*order)
+
+	return order, nil
+}
+
+// AttachPayment replaces the outstanding payment of the order orderId with p, so
+// capture, voids and webhooks follow the new payment.
+func (o *OrderUC) AttachPayment(ctx context.Context, orderId uuid.UUID, p models.Payment) (err error) {
+	ctx, span := tracing.Start(ctx, "OrderUC.AttachPayment", attribute.String("order.id", orderId.String()))
+	defer tracing.End(span, &err)
+
+	if err := o.repo.ReplacePayment(ctx, orderId, p); err != nil {
+		return fmt.Errorf("error saving payment: %v", err)
+	}
+
+	return nil
+}
+
+// GetInvoice returns the order id with its invoice as PDF in the locale of user, for
+// the owner of the order or an admin. Orders of other users are not found.
+func (o *OrderUC) GetInvoice(id uuid.UUID, user *models.User) (*models.Order, []byte, error) {
+	order, err := o.GetSingleOrder(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, orders.ErrOrderNotFound
+		}
+		return nil, nil, fmt.Errorf("error fetching order: %v", err)
+	}
+
+	if order.UserID != user.ID && user.Role != models.RoleAdmin {
+		return nil, nil, orders.ErrOrderNotFound
+	}
+
+	doc, err := o.invoices.Render(order, user.Locale)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return order, doc, nil
+}
+
+// ArchiveInvoice archives doc, the invoice of order, once the order is paid. Nothing
+// is done when archiving is disabled or the invoice is already archived, so the
+// archive keeps the invoice as it was first downloaded after payment.
+func (o *OrderUC) ArchiveInvoice(ctx context.Context, order *models.Order, doc []byte) error {
+	if !o.invoices.Archives() || order.PaymentInfo.Status != models.PaymentSucceeded {
+		return nil
+	}
+
+	_, err := o.repo.FetchInvoice(order.OrderID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error fetching invoice: %v", err)
+	}
+
+	inv, err := o.invoices.Archive(ctx, order.OrderID, doc)
+	if err != nil {
+		return err
+	}
+
+	if err := o.repo.InsertInvoice(*inv); err != nil {
+		return fmt.Errorf("error saving invoice: %v", err)
+	}
+
+	return nil
+}
+
+// UpdatePaymentStatus records the status a webhook of provider reported for the
+// payment id. A payment whose order has not been placed yet is not recorded; the
+// order records its status when it is placed.
+func (o *OrderUC) UpdatePaymentStatus(ctx context.Context, provider, id, status string) (err error) {
+	ctx, span := tracing.Start(ctx, "OrderUC.UpdatePaymentStatus", attribute.String("payment.provider", provider))
+	defer tracing.End(span, &err)
+
+	err = o.repo.UpdatePaymentStatusById(ctx, provider, id, status)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error updating payment status: %v", err)
+	}
 
 	return nil
 }
@@ -1,13 +1,30 @@
 package usecase_test
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/cloudinary/cloudinary-go/api/uploader"
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
 	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/orders"
 	"github.com/jofosuware/go/shopit/internal/orders/mocks"
 	"github.com/jofosuware/go/shopit/internal/orders/usecase"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	mockCloudinary "github.com/jofosuware/go/shopit/pkg/cloudinary/mocks"
+	"github.com/jofosuware/go/shopit/pkg/invoice"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/payments"
+	mockPayments "github.com/jofosuware/go/shopit/pkg/payments/mocks"
+	"github.com/jofosuware/go/shopit/pkg/pseudonym"
+	"github.com/jofosuware/go/shopit/pkg/realtime"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -15,7 +32,7 @@ import (
 
 func TestCreateOrder(t *testing.T) {
 	repo := mocks.NewRepo(t)
-	o := usecase.NewOrderUC(repo)
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
 
 	t.Run("Order is successfully created", func(t *testing.T) {
 		order := &models.Order{
@@ -31,33 +48,20 @@ func TestCreateOrder(t *testing.T) {
 				},
 			},
 			PaymentInfo:   models.Payment{},
-			ItemPrice:     0,
-			TaxPrice:      0,
-			ShippingPrice: 0,
-			TotalPrice:   0,
+			ItemPrice:     money.Of(0),
+			TaxPrice:      money.Of(0),
+			ShippingPrice: money.Of(0),
+			TotalPrice:   money.Of(0),
 			UserID: 	  uuid.New(),
 			PaidAt: 	time.Now(),
 			OrderStatus:   "Processing",
 			DeliveredAt:   time.Time{},
 		}
 
-		 // Use matchers to allow the ShippingInfo to have an updated OrderID.
-		repo.On("InsertOrder", *order).Return(order, nil)
-		repo.
-			On("InsertShipping", mock.MatchedBy(func(s models.Shipping) bool {
-				// We expect the OrderID to have been populated (i.e. not the zero value).
-				return s.OrderID != uuid.Nil
-			})).
-			Return(&models.Shipping{}, nil)
-		repo.
-			On("InsertItem", mock.AnythingOfType("models.Item")).
-			Return(&models.Item{}, nil)
-		repo.
-			On("InsertPayment", mock.AnythingOfType("models.Payment")).
-			Return(&models.Payment{}, nil)
+		repo.On("CreateOrder", mock.Anything, *order).Return(order, nil).Once()
 
 		// Call CreateOrder which should also update fields such as PaidAt and OrderStatus.
-		createdOrder, err := o.CreateOrder(*order)
+		createdOrder, err := o.CreateOrder(context.Background(), *order)
 		require.NoError(t, err)
 
 		// Assertions to match the changes in the CreateOrder method.
@@ -68,12 +72,29 @@ func TestCreateOrder(t *testing.T) {
 		assert.Equal(t, "Processing", createdOrder.OrderStatus)
 		assert.False(t, createdOrder.PaidAt.IsZero(), "PaidAt timestamp should be set")
 	})
+
+	t.Run("Nothing is published when the order is not saved", func(t *testing.T) {
+		bus := events.New(mockLogger.NewLogger(t))
+		published := make(chan string, 1)
+		bus.Subscribe(func(e events.Event) error {
+			published <- e.Name
+			return nil
+		})
+		o := usecase.NewOrderUC(repo, nil, 0, nil, nil, bus, nil)
+
+		repo.On("CreateOrder", mock.Anything, mock.AnythingOfType("models.Order")).Return(nil, errors.New("db down")).Once()
+
+		_, err := o.CreateOrder(context.Background(), models.Order{UserID: uuid.New()})
+		assert.Error(t, err)
+		bus.Close()
+		assert.Empty(t, published)
+	})
 }
 
 func TestGetSingleOrder(t *testing.T) {
 	repo := mocks.NewRepo(t)
 
-	o := usecase.NewOrderUC(repo)
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
 
 	t.Run("Order is successfully retrieved", func(t *testing.T) {
 		id := uuid.New()
@@ -94,7 +115,7 @@ func TestGetSingleOrder(t *testing.T) {
 func TestGetUserOrders(t *testing.T) {
 	repo := mocks.NewRepo(t)
 
-	o := usecase.NewOrderUC(repo)
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
 
 	t.Run("Orders are successfully retrieved", func(t *testing.T) {
 		userId := uuid.New()
@@ -116,7 +137,7 @@ func TestGetUserOrders(t *testing.T) {
 func TestGetAllOrders(t *testing.T) {
 	repo := mocks.NewRepo(t)
 
-	o := usecase.NewOrderUC(repo)
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
 
 	t.Run("All orders are successfully retrieved", func(t *testing.T) {
 
@@ -135,51 +156,734 @@ func TestGetAllOrders(t *testing.T) {
 func TestUpdateOrder(t *testing.T) {
 	repo := mocks.NewRepo(t)
 
-	o := usecase.NewOrderUC(repo)
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
 
 	t.Run("Order is successfully updated", func(t *testing.T) {
-		ord := models.Order{}
+		ord := models.Order{OrderID: uuid.New(), OrderStatus: models.OrderProcessing}
 
-		repo.On("UpdateOrder", ord.OrderID, ord).Return(nil)
+		repo.On("FetchOrderById", ord.OrderID).Return(&models.Order{OrderID: ord.OrderID}, nil).Once()
+		repo.On("UpdateOrder", ord.OrderID, ord, []models.StockAdjustment(nil)).Return(nil).Once()
 
 		err := o.UpdateOrder(ord)
 		require.NoError(t, err)
 	})
+
+	t.Run("Items are taken from stock once, as the order ships", func(t *testing.T) {
+		ord := models.Order{OrderID: uuid.New(), OrderStatus: models.OrderShipped}
+		variantId := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+		items := []*models.Item{{ProductID: uuid.New(), VariantID: variantId, Quantity: 2}}
+		adjs := []models.StockAdjustment{{ProductID: items[0].ProductID, VariantID: variantId, Quantity: -2,
+			Reason: models.StockShipped, OrderID: uuid.NullUUID{UUID: ord.OrderID, Valid: true}}}
+
+		repo.On("FetchOrderById", ord.OrderID).Return(&models.Order{OrderID: ord.OrderID, OrderStatus: models.OrderProcessing}, nil).Once()
+		repo.On("FetchItemsById", ord.OrderID).Return(items, nil).Once()
+		repo.On("UpdateOrder", ord.OrderID, ord, adjs).Return(nil).Once()
+		require.NoError(t, o.UpdateOrder(ord))
+
+		delivered := models.Order{OrderID: ord.OrderID, OrderStatus: models.OrderDelivered}
+		repo.On("FetchOrderById", ord.OrderID).Return(&models.Order{OrderID: ord.OrderID, OrderStatus: models.OrderShipped}, nil).Once()
+		repo.On("UpdateOrder", ord.OrderID, delivered, []models.StockAdjustment(nil)).Return(nil).Once()
+		require.NoError(t, o.UpdateOrder(delivered))
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchOrderById", id).Return(nil, sql.ErrNoRows).Once()
+
+		err := o.UpdateOrder(models.Order{OrderID: id})
+		assert.ErrorIs(t, err, orders.ErrOrderNotFound)
+	})
+
+	t.Run("Delivery is published after the status change", func(t *testing.T) {
+		published := make(chan string, 2)
+		bus := events.New(mockLogger.NewLogger(t))
+		bus.Subscribe(func(e events.Event) error {
+			published <- e.Name
+			return nil
+		})
+
+		o := usecase.NewOrderUC(repo, nil, 0, nil, nil, bus, nil)
+		ord := models.Order{OrderID: uuid.New(), OrderStatus: models.OrderDelivered}
+		repo.On("FetchOrderById", ord.OrderID).Return(&models.Order{OrderID: ord.OrderID, OrderStatus: models.OrderShipped}, nil).Once()
+		repo.On("UpdateOrder", ord.OrderID, ord, []models.StockAdjustment(nil)).Return(nil).Once()
+
+		require.NoError(t, o.UpdateOrder(ord))
+		bus.Close()
+		close(published)
+
+		var names []string
+		for name := range published {
+			names = append(names, name)
+		}
+		assert.Equal(t, []string{events.OrderStatusChanged, events.OrderDelivered}, names)
+	})
 }
 
-func TestUpdateStock(t *testing.T) {
+func TestAdjustStock(t *testing.T) {
 	repo := mocks.NewRepo(t)
 
-	o := usecase.NewOrderUC(repo)
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
+	adj := models.StockAdjustment{ProductID: uuid.New(), Quantity: 5, UserID: uuid.NullUUID{UUID: uuid.New(), Valid: true}}
+	restock := adj
+	restock.Reason = models.StockRestock
 
-	t.Run("Stock is successfully updated", func(t *testing.T) {
-		ord := models.Order{
-			OrderItems: []*models.Item{
-				{
-					ProductID: uuid.New(),
-					Quantity:  5,
-				},
-			},
-		}
+	t.Run("Stock is adjusted as a restock", func(t *testing.T) {
+		repo.On("AdjustStock", []models.StockAdjustment{restock}).Return(nil).Once()
+
+		require.NoError(t, o.AdjustStock(adj))
+	})
 
-		repo.On("UpdateStock", ord.OrderItems[0].ProductID, ord.OrderItems[0].Quantity).Return(nil)
+	t.Run("Product not found", func(t *testing.T) {
+		repo.On("AdjustStock", []models.StockAdjustment{restock}).Return(sql.ErrNoRows).Once()
 
-		err := o.UpdateStock(ord.OrderItems[0].ProductID, ord.OrderItems[0].Quantity)
-		require.NoError(t, err)
+		assert.ErrorIs(t, o.AdjustStock(adj), orders.ErrStockItemNotFound)
 	})
 }
 
+func TestGetStockAdjustments(t *testing.T) {
+	repo := mocks.NewRepo(t)
+
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
+	productId := uuid.New()
+
+	repo.On("FetchStockAdjustments", productId, usecase.DefaultAdjustmentsLimit).Return([]models.StockAdjustment{}, nil).Once()
+	_, err := o.GetStockAdjustments(productId, 0)
+	require.NoError(t, err)
+
+	repo.On("FetchStockAdjustments", productId, usecase.MaxAdjustmentsLimit).Return([]models.StockAdjustment{}, nil).Once()
+	_, err = o.GetStockAdjustments(productId, 10000)
+	require.NoError(t, err)
+}
+
 func TestDeleteOrder(t *testing.T) {
 	repo := mocks.NewRepo(t)
 
-	o := usecase.NewOrderUC(repo)
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
 
 	t.Run("Order is successfully deleted", func(t *testing.T) {
 		id := uuid.New()
 
-		repo.On("DeleteOrderById", id).Return(nil)
+		repo.On("FetchOrderById", id).Return(&models.Order{OrderID: id, OrderStatus: models.OrderProcessing}, nil).Once()
+		repo.On("FetchOrderAdjustments", id).Return([]models.StockAdjustment{}, nil).Once()
+		repo.On("DeleteOrderById", id, []models.StockAdjustment(nil)).Return(nil).Once()
 
 		err := o.DeleteOrder(id)
 		require.NoError(t, err)
 	})
+
+	t.Run("Items a shipped order took go back to stock", func(t *testing.T) {
+		id := uuid.New()
+		orderId := uuid.NullUUID{UUID: id, Valid: true}
+		product, variant := uuid.New(), uuid.NullUUID{UUID: uuid.New(), Valid: true}
+		taken := []models.StockAdjustment{
+			{ProductID: product, Quantity: -3, Reason: models.StockShipped, OrderID: orderId},
+			{ProductID: product, VariantID: variant, Quantity: -1, Reason: models.StockShipped, OrderID: orderId},
+		}
+		adjs := []models.StockAdjustment{
+			{ProductID: product, Quantity: 3, Reason: models.StockOrderDeleted, OrderID: orderId},
+			{ProductID: product, VariantID: variant, Quantity: 1, Reason: models.StockOrderDeleted, OrderID: orderId},
+		}
+
+		repo.On("FetchOrderById", id).Return(&models.Order{OrderID: id, OrderStatus: models.OrderShipped}, nil).Once()
+		repo.On("FetchOrderAdjustments", id).Return(taken, nil).Once()
+		repo.On("DeleteOrderById", id, adjs).Return(nil).Once()
+
+		require.NoError(t, o.DeleteOrder(id))
+	})
+
+	t.Run("Shipped order that took no stock", func(t *testing.T) {
+		id := uuid.New()
+
+		repo.On("FetchOrderById", id).Return(&models.Order{OrderID: id, OrderStatus: models.OrderDelivered}, nil).Once()
+		repo.On("FetchOrderAdjustments", id).Return([]models.StockAdjustment{}, nil).Once()
+		repo.On("DeleteOrderById", id, []models.StockAdjustment(nil)).Return(nil).Once()
+
+		require.NoError(t, o.DeleteOrder(id))
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchOrderById", id).Return(nil, sql.ErrNoRows).Once()
+
+		assert.ErrorIs(t, o.DeleteOrder(id), orders.ErrOrderNotFound)
+	})
+}
+
+func TestGetPickList(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
+
+	a, b := uuid.New(), uuid.New()
+
+	t.Run("Items are summed across orders", func(t *testing.T) {
+		lines := []models.PickLine{
+			{PackingItem: models.PackingItem{Name: "Camera", Quantity: 3}, Orders: 2},
+			{PackingItem: models.PackingItem{Name: "Lens", Quantity: 1}, Orders: 1},
+		}
+		repo.On("FetchOrderIds", []uuid.UUID{a, b}).Return([]uuid.UUID{a, b}, nil).Once()
+		repo.On("FetchPickLines", []uuid.UUID{a, b}).Return(lines, nil).Once()
+
+		list, err := o.GetPickList([]uuid.UUID{a, b, a})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{a, b}, list.Orders)
+		assert.Equal(t, 4, list.TotalUnits)
+	})
+
+	t.Run("Missing order", func(t *testing.T) {
+		repo.On("FetchOrderIds", []uuid.UUID{a, b}).Return([]uuid.UUID{a}, nil).Once()
+
+		_, err := o.GetPickList([]uuid.UUID{a, b})
+		assert.ErrorIs(t, err, orders.ErrOrderNotFound)
+		assert.Contains(t, err.Error(), b.String())
+	})
+
+	t.Run("No orders selected", func(t *testing.T) {
+		_, err := o.GetPickList(nil)
+		assert.ErrorIs(t, err, orders.ErrInvalidSelection)
+	})
+}
+
+func TestGetPackingSlip(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
+
+	id := uuid.New()
+
+	t.Run("Slip lists items and the shipping address", func(t *testing.T) {
+		repo.On("FetchOrderById", id).Return(&models.Order{OrderID: id, OrderStatus: "Processing"}, nil).Once()
+		repo.On("FetchShippingById", id).Return(&models.Shipping{City: "Accra"}, nil).Once()
+		repo.On("FetchPickLines", []uuid.UUID{id}).Return([]models.PickLine{
+			{PackingItem: models.PackingItem{Name: "Camera", Quantity: 2}, Orders: 1},
+		}, nil).Once()
+
+		slip, err := o.GetPackingSlip(id)
+		require.NoError(t, err)
+		assert.Equal(t, "Accra", slip.ShipTo.City)
+		assert.Equal(t, []models.PackingItem{{Name: "Camera", Quantity: 2}}, slip.Items)
+		assert.Equal(t, 2, slip.TotalUnits)
+		require.NotNil(t, slip.Totals)
+	})
+
+	t.Run("Gift slip hides prices", func(t *testing.T) {
+		repo.On("FetchOrderById", id).Return(&models.Order{OrderID: id, TotalPrice: money.Of(215), Gift: true,
+			GiftMessage: "Happy birthday!", HidePrices: true}, nil).Once()
+		repo.On("FetchShippingById", id).Return(&models.Shipping{}, nil).Once()
+		repo.On("FetchPickLines", []uuid.UUID{id}).Return([]models.PickLine{}, nil).Once()
+
+		slip, err := o.GetPackingSlip(id)
+		require.NoError(t, err)
+		assert.True(t, slip.Gift)
+		assert.Equal(t, "Happy birthday!", slip.GiftMessage)
+		assert.Nil(t, slip.Totals)
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		repo.On("FetchOrderById", id).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := o.GetPackingSlip(id)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestCapturePayment(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	stripeProvider := mockPayments.NewProvider(t)
+	paypalProvider := mockPayments.NewProvider(t)
+
+	o := usecase.NewOrderUC(repo, payments.Providers{
+		models.PaymentStripe: stripeProvider,
+		models.PaymentPayPal: paypalProvider,
+	}, 0, nil, nil, nil, nil)
+
+	t.Run("Authorized payment is captured", func(t *testing.T) {
+		ord := models.Order{OrderID: uuid.New(), PaymentInfo: models.Payment{ID: "pi_1", Status: models.PaymentRequiresCapture}}
+
+		stripeProvider.On("CapturePayment", "pi_1").Return(nil).Once()
+		repo.On("UpdatePaymentStatus", ord.OrderID, models.PaymentSucceeded).Return(nil).Once()
+
+		require.NoError(t, o.CapturePayment(&ord))
+		assert.Equal(t, models.PaymentSucceeded, ord.PaymentInfo.Status)
+	})
+
+	t.Run("Payment is captured by its provider", func(t *testing.T) {
+		ord := models.Order{OrderID: uuid.New(), PaymentInfo: models.Payment{ID: "5O190127TN364715T",
+			Provider: models.PaymentPayPal, Status: models.PaymentRequiresCapture}}
+
+		paypalProvider.On("CapturePayment", "5O190127TN364715T").Return(nil).Once()
+		repo.On("UpdatePaymentStatus", ord.OrderID, models.PaymentSucceeded).Return(nil).Once()
+
+		require.NoError(t, o.CapturePayment(&ord))
+	})
+
+	t.Run("Provider not configured", func(t *testing.T) {
+		ord := models.Order{PaymentInfo: models.Payment{ID: "x", Provider: "adyen", Status: models.PaymentRequiresCapture}}
+
+		assert.ErrorIs(t, o.CapturePayment(&ord), orders.ErrCaptureFailed)
+	})
+
+	t.Run("Charged payment has nothing to capture", func(t *testing.T) {
+		ord := models.Order{PaymentInfo: models.Payment{ID: "pi_2", Status: models.PaymentSucceeded}}
+
+		require.NoError(t, o.CapturePayment(&ord))
+	})
+
+	t.Run("Voided payment", func(t *testing.T) {
+		ord := models.Order{PaymentInfo: models.Payment{ID: "pi_3", Status: models.PaymentCanceled}}
+
+		assert.ErrorIs(t, o.CapturePayment(&ord), orders.ErrPaymentVoided)
+	})
+
+	t.Run("Capture refused by Stripe", func(t *testing.T) {
+		ord := models.Order{PaymentInfo: models.Payment{ID: "pi_4", Status: models.PaymentRequiresCapture}}
+
+		stripeProvider.On("CapturePayment", "pi_4").Return(errors.New("authorization expired")).Once()
+
+		assert.ErrorIs(t, o.CapturePayment(&ord), orders.ErrCaptureFailed)
+	})
+}
+
+func TestVoidUncapturedPayments(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	stripeProvider := mockPayments.NewProvider(t)
+	paypalProvider := mockPayments.NewProvider(t)
+
+	cancelled := make(chan models.Order, 2)
+	bus := events.New(mockLogger.NewLogger(t))
+	bus.Subscribe(func(e events.Event) error {
+		cancelled <- e.Data.(models.Order)
+		return nil
+	}, events.OrderStatusChanged)
+
+	o := usecase.NewOrderUC(repo, payments.Providers{
+		models.PaymentStripe: stripeProvider,
+		models.PaymentPayPal: paypalProvider,
+	}, 48*time.Hour, nil, nil, bus, nil)
+
+	voided := &models.Payment{ID: "pi_1", Provider: models.PaymentStripe, OrderID: uuid.New()}
+	failed := &models.Payment{ID: "pi_2", Provider: models.PaymentStripe, OrderID: uuid.New()}
+	paypal := &models.Payment{ID: "5O190127TN364715T", Provider: models.PaymentPayPal, OrderID: uuid.New()}
+
+	repo.On("FetchUncapturedPayments", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return time.Until(before) < -47*time.Hour
+	})).Return([]*models.Payment{failed, voided, paypal}, nil).Once()
+	stripeProvider.On("VoidPayment", "pi_2").Return(errors.New("stripe unavailable")).Once()
+	stripeProvider.On("VoidPayment", "pi_1").Return(nil).Once()
+	paypalProvider.On("VoidPayment", paypal.ID).Return(nil).Once()
+	repo.On("VoidOrder", voided.OrderID).Return(nil).Once()
+	repo.On("VoidOrder", paypal.OrderID).Return(nil).Once()
+	repo.On("FetchOrderById", voided.OrderID).Return(&models.Order{OrderID: voided.OrderID, OrderStatus: models.OrderCancelled}, nil).Once()
+	repo.On("FetchOrderById", paypal.OrderID).Return(&models.Order{OrderID: paypal.OrderID, OrderStatus: models.OrderCancelled}, nil).Once()
+
+	n, err := o.VoidUncapturedPayments(context.Background())
+	assert.Error(t, err, "the failed payment is reported")
+	assert.Equal(t, 2, n)
+
+	bus.Close()
+	close(cancelled)
+	var ids []uuid.UUID
+	for ord := range cancelled {
+		assert.Equal(t, models.OrderCancelled, ord.OrderStatus)
+		ids = append(ids, ord.OrderID)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{voided.OrderID, paypal.OrderID}, ids, "cancellations are published")
+}
+
+func TestUpdatePaymentStatus(t *testing.T) {
+	repo := mocks.NewRepo(t)
+
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
+
+	t.Run("Status is recorded", func(t *testing.T) {
+		repo.On("UpdatePaymentStatusById", mock.Anything, models.PaymentPayPal, "5O190127TN364715T", models.PaymentSucceeded).Return(nil).Once()
+
+		assert.NoError(t, o.UpdatePaymentStatus(context.Background(), models.PaymentPayPal, "5O190127TN364715T", models.PaymentSucceeded))
+	})
+
+	t.Run("Payment of an order not placed yet", func(t *testing.T) {
+		repo.On("UpdatePaymentStatusById", mock.Anything, models.PaymentStripe, "pi_1", models.PaymentSucceeded).Return(sql.ErrNoRows).Once()
+
+		assert.NoError(t, o.UpdatePaymentStatus(context.Background(), models.PaymentStripe, "pi_1", models.PaymentSucceeded))
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		repo.On("UpdatePaymentStatusById", mock.Anything, models.PaymentStripe, "pi_2", models.PaymentFailed).Return(errors.New("db down")).Once()
+
+		assert.Error(t, o.UpdatePaymentStatus(context.Background(), models.PaymentStripe, "pi_2", models.PaymentFailed))
+	})
+}
+
+func TestGetPayableOrder(t *testing.T) {
+	repo := mocks.NewRepo(t)
+
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
+	userID := uuid.New()
+
+	expectOrder := func(order *models.Order, status string) {
+		repo.On("FetchOrderById", order.OrderID).Return(order, nil).Once()
+		repo.On("FetchShippingById", order.OrderID).Return(&models.Shipping{}, nil).Once()
+		repo.On("FetchItemsById", order.OrderID).Return([]*models.Item{{Price: money.Of(1000), Quantity: 1}}, nil).Once()
+		repo.On("FetchPaymentById", order.OrderID).Return(&models.Payment{ID: "pi_1", Status: status}, nil).Once()
+	}
+
+	t.Run("Order with a failed payment", func(t *testing.T) {
+		order := &models.Order{OrderID: uuid.New(), UserID: userID, OrderStatus: models.OrderProcessing}
+		expectOrder(order, models.PaymentFailed)
+
+		got, err := o.GetPayableOrder(context.Background(), order.OrderID, userID)
+		require.NoError(t, err)
+		assert.Len(t, got.OrderItems, 1)
+	})
+
+	t.Run("Paid order", func(t *testing.T) {
+		order := &models.Order{OrderID: uuid.New(), UserID: userID, OrderStatus: models.OrderProcessing}
+		expectOrder(order, models.PaymentSucceeded)
+
+		_, err := o.GetPayableOrder(context.Background(), order.OrderID, userID)
+		assert.ErrorIs(t, err, orders.ErrOrderPaid)
+	})
+
+	t.Run("Cancelled order", func(t *testing.T) {
+		order := &models.Order{OrderID: uuid.New(), UserID: userID, OrderStatus: models.OrderCancelled}
+		expectOrder(order, models.PaymentCanceled)
+
+		_, err := o.GetPayableOrder(context.Background(), order.OrderID, userID)
+		assert.ErrorIs(t, err, orders.ErrOrderCancelled)
+	})
+
+	t.Run("Order of another user", func(t *testing.T) {
+		order := &models.Order{OrderID: uuid.New(), UserID: uuid.New(), OrderStatus: models.OrderProcessing}
+		expectOrder(order, models.PaymentFailed)
+
+		_, err := o.GetPayableOrder(context.Background(), order.OrderID, userID)
+		assert.ErrorIs(t, err, orders.ErrOrderNotFound)
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchOrderById", id).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := o.GetPayableOrder(context.Background(), id, userID)
+		assert.ErrorIs(t, err, orders.ErrOrderNotFound)
+	})
+}
+
+func TestCancelOrder(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	provider := mockPayments.NewProvider(t)
+
+	o := usecase.NewOrderUC(repo, payments.Providers{models.PaymentStripe: provider}, 0, nil, nil, nil, nil)
+	userID := uuid.New()
+
+	expectOrder := func(order *models.Order, status string) {
+		repo.On("FetchOrderById", order.OrderID).Return(order, nil).Once()
+		repo.On("FetchShippingById", order.OrderID).Return(&models.Shipping{}, nil).Once()
+		repo.On("FetchItemsById", order.OrderID).Return([]*models.Item{{Price: money.Of(1000), Quantity: 1}}, nil).Once()
+		repo.On("FetchPaymentById", order.OrderID).
+			Return(&models.Payment{ID: "pi_1", Provider: models.PaymentStripe, Status: status}, nil).Once()
+	}
+	cancellation := func(order *models.Order, reason string) models.OrderCancellation {
+		return models.OrderCancellation{OrderID: order.OrderID, UserID: userID, Reason: reason}
+	}
+
+	t.Run("Paid order is marked for refund", func(t *testing.T) {
+		order := &models.Order{OrderID: uuid.New(), UserID: userID, OrderStatus: models.OrderProcessing}
+		expectOrder(order, models.PaymentSucceeded)
+		repo.On("CancelOrder", cancellation(order, "ordered twice"), models.PaymentRefundPending).Return(nil).Once()
+
+		got, err := o.CancelOrder(order.OrderID, userID, "ordered twice")
+		require.NoError(t, err)
+		assert.Equal(t, models.OrderCancelled, got.OrderStatus)
+		assert.Equal(t, models.PaymentRefundPending, got.PaymentInfo.Status)
+	})
+
+	t.Run("Authorized payment is voided", func(t *testing.T) {
+		order := &models.Order{OrderID: uuid.New(), UserID: userID, OrderStatus: models.OrderProcessing}
+		expectOrder(order, models.PaymentRequiresCapture)
+		provider.On("VoidPayment", "pi_1").Return(nil).Once()
+		repo.On("CancelOrder", cancellation(order, ""), models.PaymentCanceled).Return(nil).Once()
+
+		got, err := o.CancelOrder(order.OrderID, userID, "")
+		require.NoError(t, err)
+		assert.Equal(t, models.PaymentCanceled, got.PaymentInfo.Status)
+	})
+
+	t.Run("Payment that cannot be voided", func(t *testing.T) {
+		order := &models.Order{OrderID: uuid.New(), UserID: userID, OrderStatus: models.OrderProcessing}
+		expectOrder(order, models.PaymentRequiresCapture)
+		provider.On("VoidPayment", "pi_1").Return(errors.New("stripe unavailable")).Once()
+
+		_, err := o.CancelOrder(order.OrderID, userID, "")
+		assert.Error(t, err, "the order is not cancelled")
+	})
+
+	t.Run("Shipped order", func(t *testing.T) {
+		order := &models.Order{OrderID: uuid.New(), UserID: userID, OrderStatus: models.OrderShipped}
+		expectOrder(order, models.PaymentSucceeded)
+
+		_, err := o.CancelOrder(order.OrderID, userID, "")
+		assert.ErrorIs(t, err, orders.ErrOrderShipped)
+	})
+
+	t.Run("Order shipped while cancelling", func(t *testing.T) {
+		order := &models.Order{OrderID: uuid.New(), UserID: userID, OrderStatus: models.OrderProcessing}
+		expectOrder(order, models.PaymentFailed)
+		repo.On("CancelOrder", cancellation(order, ""), models.PaymentCanceled).Return(sql.ErrNoRows).Once()
+
+		_, err := o.CancelOrder(order.OrderID, userID, "")
+		assert.ErrorIs(t, err, orders.ErrOrderShipped)
+	})
+
+	t.Run("Cancelled order", func(t *testing.T) {
+		order := &models.Order{OrderID: uuid.New(), UserID: userID, OrderStatus: models.OrderCancelled}
+		expectOrder(order, models.PaymentCanceled)
+
+		_, err := o.CancelOrder(order.OrderID, userID, "")
+		assert.ErrorIs(t, err, orders.ErrOrderCancelled)
+	})
+
+	t.Run("Order of another user", func(t *testing.T) {
+		order := &models.Order{OrderID: uuid.New(), UserID: uuid.New(), OrderStatus: models.OrderProcessing}
+		expectOrder(order, models.PaymentSucceeded)
+
+		_, err := o.CancelOrder(order.OrderID, userID, "")
+		assert.ErrorIs(t, err, orders.ErrOrderNotFound)
+	})
+}
+
+func TestAttachPayment(t *testing.T) {
+	repo := mocks.NewRepo(t)
+
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
+	orderId := uuid.New()
+	p := models.Payment{ID: "pi_2", Provider: models.PaymentStripe, Status: "requires_payment_method"}
+
+	t.Run("Payment is replaced", func(t *testing.T) {
+		repo.On("ReplacePayment", mock.Anything, orderId, p).Return(nil).Once()
+
+		assert.NoError(t, o.AttachPayment(context.Background(), orderId, p))
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		repo.On("ReplacePayment", mock.Anything, orderId, p).Return(errors.New("db down")).Once()
+
+		assert.Error(t, o.AttachPayment(context.Background(), orderId, p))
+	})
+}
+
+func TestGetInvoice(t *testing.T) {
+	repo := mocks.NewRepo(t)
+
+	o := usecase.NewOrderUC(repo, nil, 0, invoice.New(config.Invoices{}, nil), nil, nil, nil)
+	owner := &models.User{ID: uuid.New()}
+
+	expectOrder := func(id uuid.UUID) {
+		repo.On("FetchOrderById", id).Return(&models.Order{OrderID: id, UserID: owner.ID}, nil).Once()
+		repo.On("FetchShippingById", id).Return(&models.Shipping{}, nil).Once()
+		repo.On("FetchItemsById", id).Return([]*models.Item{{Name: "Camera", Price: money.Of(1000), Quantity: 1}}, nil).Once()
+		repo.On("FetchPaymentById", id).Return(&models.Payment{ID: "pi_1", Status: models.PaymentSucceeded}, nil).Once()
+	}
+
+	t.Run("Owner gets the invoice", func(t *testing.T) {
+		id := uuid.New()
+		expectOrder(id)
+
+		order, doc, err := o.GetInvoice(id, owner)
+		require.NoError(t, err)
+		assert.Equal(t, id, order.OrderID)
+		assert.Contains(t, string(doc), "Camera")
+	})
+
+	t.Run("Admin gets the invoice", func(t *testing.T) {
+		id := uuid.New()
+		expectOrder(id)
+
+		_, _, err := o.GetInvoice(id, &models.User{ID: uuid.New(), Role: models.RoleAdmin})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Order of another user", func(t *testing.T) {
+		id := uuid.New()
+		expectOrder(id)
+
+		_, _, err := o.GetInvoice(id, &models.User{ID: uuid.New()})
+		assert.ErrorIs(t, err, orders.ErrOrderNotFound)
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchOrderById", id).Return(nil, sql.ErrNoRows).Once()
+
+		_, _, err := o.GetInvoice(id, owner)
+		assert.ErrorIs(t, err, orders.ErrOrderNotFound)
+	})
+}
+
+func TestArchiveInvoice(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	store := mockCloudinary.NewCloudUploader(t)
+
+	o := usecase.NewOrderUC(repo, nil, 0, invoice.New(config.Invoices{Archive: true}, store), nil, nil, nil)
+	doc := []byte("%PDF-1.4")
+	newOrder := func(status string) *models.Order {
+		return &models.Order{OrderID: uuid.New(), PaymentInfo: models.Payment{Status: status}}
+	}
+
+	t.Run("Paid invoice is archived", func(t *testing.T) {
+		order := newOrder(models.PaymentSucceeded)
+		repo.On("FetchInvoice", order.OrderID).Return(nil, sql.ErrNoRows).Once()
+		store.On("UploadToCloud", mock.Anything, cloudinary.FolderInvoices, doc).
+			Return(&uploader.UploadResult{PublicID: "invoices/1.pdf", URL: "https://cdn.test/invoices/1.pdf"}, nil).Once()
+		repo.On("InsertInvoice", models.Invoice{OrderID: order.OrderID, URL: "https://cdn.test/invoices/1.pdf", PublicID: "invoices/1.pdf"}).
+			Return(nil).Once()
+
+		assert.NoError(t, o.ArchiveInvoice(context.Background(), order, doc))
+	})
+
+	t.Run("Invoice already archived", func(t *testing.T) {
+		order := newOrder(models.PaymentSucceeded)
+		repo.On("FetchInvoice", order.OrderID).Return(&models.Invoice{OrderID: order.OrderID}, nil).Once()
+
+		assert.NoError(t, o.ArchiveInvoice(context.Background(), order, doc))
+	})
+
+	t.Run("Unpaid order is not archived", func(t *testing.T) {
+		assert.NoError(t, o.ArchiveInvoice(context.Background(), newOrder(models.PaymentRequiresAction), doc))
+	})
+
+	t.Run("Archiving disabled", func(t *testing.T) {
+		o := usecase.NewOrderUC(repo, nil, 0, invoice.New(config.Invoices{}, store), nil, nil, nil)
+
+		assert.NoError(t, o.ArchiveInvoice(context.Background(), newOrder(models.PaymentSucceeded), doc))
+	})
+
+	t.Run("Upload fails", func(t *testing.T) {
+		order := newOrder(models.PaymentSucceeded)
+		repo.On("FetchInvoice", order.OrderID).Return(nil, sql.ErrNoRows).Once()
+		store.On("UploadToCloud", mock.Anything, cloudinary.FolderInvoices, doc).Return(&uploader.UploadResult{}, errors.New("storage down")).Once()
+
+		assert.Error(t, o.ArchiveInvoice(context.Background(), order, doc))
+	})
+}
+
+func TestSubscribeOrder(t *testing.T) {
+	repo := mocks.NewRepo(t)
+
+	hub := realtime.NewHub()
+	bus := events.New(mockLogger.NewLogger(t))
+	bus.Subscribe(usecase.StatusPusher(hub), events.OrderStatusChanged)
+	t.Cleanup(bus.Close)
+
+	o := usecase.NewOrderUC(repo, nil, 0, nil, nil, bus, hub)
+	owner := &models.User{ID: uuid.New()}
+	id := uuid.New()
+
+	t.Run("Owner is told of status changes", func(t *testing.T) {
+		repo.On("FetchOrderById", id).Return(&models.Order{OrderID: id, UserID: owner.ID, OrderStatus: models.OrderProcessing}, nil).Once()
+
+		order, sub, err := o.SubscribeOrder(id, owner)
+		require.NoError(t, err)
+		defer sub.Close()
+		assert.Equal(t, models.OrderProcessing, order.OrderStatus)
+
+		shipped := models.Order{OrderID: id, UserID: owner.ID, OrderStatus: models.OrderShipped}
+		repo.On("FetchOrderById", id).Return(&models.Order{OrderID: id, UserID: owner.ID, OrderStatus: models.OrderProcessing}, nil).Once()
+		repo.On("FetchItemsById", id).Return([]*models.Item{}, nil).Once()
+		repo.On("UpdateOrder", id, shipped, []models.StockAdjustment{}).Return(nil).Once()
+		require.NoError(t, o.UpdateOrder(shipped))
+
+		e := <-sub.C
+		assert.Equal(t, models.EventOrderStatusChanged, e.Name)
+		change := e.Data.(models.OrderStatusChange)
+		assert.Equal(t, id, change.OrderID)
+		assert.Equal(t, models.OrderShipped, change.Status)
+	})
+
+	t.Run("Admin can subscribe", func(t *testing.T) {
+		repo.On("FetchOrderById", id).Return(&models.Order{OrderID: id, UserID: owner.ID}, nil).Once()
+
+		_, sub, err := o.SubscribeOrder(id, &models.User{ID: uuid.New(), Role: models.RoleAdmin})
+		require.NoError(t, err)
+		sub.Close()
+	})
+
+	t.Run("Orders of other users are not found", func(t *testing.T) {
+		repo.On("FetchOrderById", id).Return(&models.Order{OrderID: id, UserID: owner.ID}, nil).Once()
+
+		_, _, err := o.SubscribeOrder(id, &models.User{ID: uuid.New()})
+		assert.ErrorIs(t, err, orders.ErrOrderNotFound)
+	})
+
+	t.Run("Missing order", func(t *testing.T) {
+		missing := uuid.New()
+		repo.On("FetchOrderById", missing).Return(nil, sql.ErrNoRows).Once()
+
+		_, _, err := o.SubscribeOrder(missing, owner)
+		assert.ErrorIs(t, err, orders.ErrOrderNotFound)
+	})
+}
+
+func TestExportOrders(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	hasher := pseudonym.New("secret")
+	o := usecase.NewOrderUC(repo, nil, 0, nil, hasher, nil, nil)
+
+	from, to := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	summary := models.OrderSummary{
+		Order: models.Order{
+			OrderID:      uuid.New(),
+			UserID:       uuid.New(),
+			OrderStatus:  models.OrderDelivered,
+			Currency:     "USD",
+			ItemPrice:    money.New(2500, "USD"),
+			TotalPrice:   money.New(2750, "USD"),
+			GiftMessage:  "Happy birthday!",
+			ShippingInfo: models.Shipping{Address: "1 Market Street", City: "Accra", PhoneNo: "0244000000", Country: "Ghana"},
+			CreatedAt:    time.Date(2026, 9, 2, 10, 0, 0, 0, time.UTC),
+		},
+		ItemCount: 2,
+	}
+	stream := func(args mock.Arguments) {
+		fn := args.Get(2).(func(s *models.OrderSummary) error)
+		require.NoError(t, fn(&summary))
+	}
+
+	t.Run("Orders are exported with their ids", func(t *testing.T) {
+		repo.On("StreamOrderSummaries", from, to, mock.Anything).Run(stream).Return(nil).Once()
+
+		var buf bytes.Buffer
+		require.NoError(t, o.ExportOrders(&buf, from, to, false))
+
+		assert.Equal(t, "order_id,user_id,status,currency,items,items_price,tax_price,shipping_price,discount,"+
+			"total_price,coupon_code,variant,gift,city,country,created_at,paid_at,delivered_at\n"+
+			summary.OrderID.String()+","+summary.UserID.String()+",Delivered,USD,2,25.00,0.00,0.00,0.00,27.50,,,false,"+
+			"Accra,Ghana,2026-09-02T10:00:00Z,,\n", buf.String())
+	})
+
+	t.Run("Anonymized orders carry pseudonyms", func(t *testing.T) {
+		repo.On("StreamOrderSummaries", from, to, mock.Anything).Run(stream).Return(nil).Once()
+
+		var buf bytes.Buffer
+		require.NoError(t, o.ExportOrders(&buf, from, to, true))
+
+		out := buf.String()
+		assert.Contains(t, out, hasher.Of("order", summary.OrderID.String())+","+hasher.Of("user", summary.UserID.String())+",")
+		assert.Contains(t, out, ",false,,Ghana,")
+		for _, pii := range []string{summary.OrderID.String(), summary.UserID.String(), "Accra", "Market", "0244", "birthday"} {
+			assert.NotContains(t, out, pii)
+		}
+	})
+
+	t.Run("Anonymization needs a pseudonym key", func(t *testing.T) {
+		o := usecase.NewOrderUC(repo, nil, 0, nil, nil, nil, nil)
+
+		err := o.ExportOrders(&bytes.Buffer{}, from, to, true)
+		assert.ErrorIs(t, err, orders.ErrAnonymizationDisabled)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		repo.On("StreamOrderSummaries", from, to, mock.Anything).Return(errors.New("db error")).Once()
+
+		err := o.ExportOrders(&bytes.Buffer{}, from, to, false)
+		assert.ErrorContains(t, err, "db error")
+	})
 }
@@ -1,70 +1,215 @@
 // Package delivery provides HTTP handlers for payment endpoints.
 //
-// It wires handler methods for processing payments and exposing payment-related
-// configuration to callers.
+// It wires handler methods for processing payments, receiving the webhooks of
+// payment providers and exposing payment-related configuration to callers.
 package delivery
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/config"
-	"github.com/jofosuware/go/shopit/pkg/card"
+	"github.com/jofosuware/go/shopit/internal/checkout"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/orders"
 	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/payments"
 	"github.com/jofosuware/go/shopit/pkg/utils"
 )
 
 // PaymentHandler provides HTTP handler methods for payment endpoints.
 type PaymentHandler struct {
-	cfg    *config.Config
-	logger logger.Logger
-	card   card.Carder
+	cfg        *config.Config
+	logger     logger.Logger
+	provider   payments.Provider
+	providers  payments.Providers
+	checkoutUC checkout.CheckoutUC
+	ordersUC   orders.OrderUC
 }
 
-// NewPaymentHandler returns a new PaymentHandler.
-func NewPaymentHandler(cfg *config.Config, logger logger.Logger, card card.Carder) *PaymentHandler {
+// NewPaymentHandler returns a new PaymentHandler. New payments are created with
+// provider; webhooks are accepted from every provider in providers.
+func NewPaymentHandler(cfg *config.Config, logger logger.Logger, provider payments.Provider, providers payments.Providers, checkoutUC checkout.CheckoutUC, ordersUC orders.OrderUC) *PaymentHandler {
 	return &PaymentHandler{
-		cfg:    cfg,
-		logger: logger,
-		card:   card,
+		cfg:        cfg,
+		logger:     logger,
+		provider:   provider,
+		providers:  providers,
+		checkoutUC: checkoutUC,
+		ordersUC:   ordersUC,
 	}
 }
 
-// ProcessPayment processes a payment and returns a payment intent client secret.
+// ProcessPayment creates a payment with the configured provider for the amount the
+// server computes, so the customer cannot choose what they pay. A Stripe payment
+// returns the client secret of its payment intent; a PayPal payment returns the URL
+// the customer approves it at.
 // Endpoint: POST /api/v1/payment/process
-// Expects JSON body: {"amount": <int>}.
+// Expects JSON body: {"checkoutSession": <id>} or {"orderId": <id>}. A checkout
+// session is charged its locked total in the session currency and the payment is
+// linked to the session. An order of the user whose payment did not go through is
+// charged the total of its items, shipping and tax less its discount, and the new
+// payment replaces the old one. When nothing is due, for instance when store credit
+// covers the whole session, no payment is created and the client secret is empty.
 func (h *PaymentHandler) ProcessPayment(w http.ResponseWriter, r *http.Request) {
-	type payment struct {
-		Amount int `json:"amount"`
+	var p paymentRequest
+	if !utils.Bind(w, r, h.logger, &p) {
+		return
+	}
+
+	var (
+		amount  money.Money
+		session *models.CheckoutSession
+		order   *models.Order
+		err     error
+	)
+
+	if p.CheckoutSession != "" {
+		session, err = h.openSession(r, p.CheckoutSession)
+		if err != nil {
+			if checkout.IsClientError(err) {
+				_ = utils.BadRequest(w, r, err)
+				h.logger.Errorf("error fetching checkout session: %v", err)
+				return
+			}
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error fetching checkout session: %w", err))
+			return
+		}
+
+		amount = session.TotalPrice
+	} else {
+		order, err = h.payableOrder(r, p.OrderID)
+		if err != nil {
+			if isOrderError(err) {
+				_ = utils.BadRequest(w, r, err)
+				h.logger.Errorf("error fetching order: %v", err)
+				return
+			}
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error fetching order: %w", err))
+			return
+		}
+
+		amount = order.AmountDue()
 	}
 
-	var p payment
+	// store credit or a discount covers everything, there is nothing to charge
+	if amount.IsZero() {
+		_ = utils.WriteJSON(w, http.StatusOK, struct {
+			Success      bool   `json:"success"`
+			ClientSecret string `json:"client_secret"`
+		}{Success: true})
+		return
+	}
 
-	err := utils.ReadJSON(w, r, &p)
+	if amount.Currency == "" {
+		amount = money.Of(amount.Amount)
+	}
+
+	pay, _, err := h.provider.CreatePayment(amount)
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("invalid json"))
-		h.logger.Errorf("error reading json: %v", err)
+		_ = utils.BadRequest(w, r, errors.New("error creating payment"))
+		h.logger.Errorf("error creating %s payment: %v", h.provider.Name(), err)
 		return
 	}
 
-	pi, _, err := h.card.CreatePaymentIntent("usd", p.Amount)
+	switch {
+	case session != nil:
+		err = h.checkoutUC.AttachPayment(r.Context(), session.ID, pay.ID)
+	case order != nil:
+		err = h.ordersUC.AttachPayment(r.Context(), order.OrderID, models.Payment{ID: pay.ID, Provider: pay.Provider, Status: pay.Status})
+	}
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("error charging card"))
-		h.logger.Errorf("error creating payment intent: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error attaching payment: %w", err))
 		return
 	}
 
 	jsonRes := struct {
-		Success      bool   `json:"success"`
-		ClientSecret string `json:"client_secret"`
+		Success      bool        `json:"success"`
+		Provider     string      `json:"provider"`
+		PaymentID    string      `json:"paymentId"`
+		Amount       money.Money `json:"amount"`
+		ClientSecret string      `json:"client_secret"`
+		ApprovalURL  string      `json:"approvalUrl,omitempty"`
 	}{
 		Success:      true,
-		ClientSecret: pi.ClientSecret,
+		Provider:     pay.Provider,
+		PaymentID:    pay.ID,
+		Amount:       amount,
+		ClientSecret: pay.ClientSecret,
+		ApprovalURL:  pay.ApprovalURL,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jsonRes)
+}
+
+// ConfirmPayment completes a payment the customer approved and returns its status.
+// A PayPal order is captured, or authorized with manual capture; a Stripe payment
+// intent, confirmed in the browser, only has its status read.
+// Endpoint: POST /api/v1/payment/confirm
+// Expects JSON body: {"paymentId": <id>}
+func (h *PaymentHandler) ConfirmPayment(w http.ResponseWriter, r *http.Request) {
+	var input confirmRequest
+	if !utils.Bind(w, r, h.logger, &input) {
+		return
+	}
+
+	status, err := h.provider.ConfirmPayment(input.PaymentID)
+	if err != nil {
+		_ = utils.BadRequest(w, r, errors.New("error confirming payment"))
+		h.logger.Errorf("error confirming %s payment %s: %v", h.provider.Name(), input.PaymentID, err)
+		return
+	}
+
+	jsonRes := struct {
+		Success bool   `json:"success"`
+		Status  string `json:"status"`
+	}{
+		Success: true,
+		Status:  status,
 	}
 
 	_ = utils.WriteJSON(w, http.StatusOK, jsonRes)
 }
 
+// Webhook receives the payment notifications of a provider and records the payment
+// status they report. Notifications are verified by the provider; those for
+// payments of orders not placed yet are ignored.
+// Endpoint: POST /api/v1/payment/webhook/{provider}
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	provider, err := h.providers.Get(chi.URLParam(r, "provider"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error receiving webhook: %v", err)
+		return
+	}
+
+	event, err := provider.ParseWebhook(r)
+	if err != nil {
+		if errors.Is(err, payments.ErrInvalidWebhook) {
+			_ = utils.BadRequest(w, r, payments.ErrInvalidWebhook)
+			h.logger.Errorf("error verifying %s webhook: %v", provider.Name(), err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error reading %s webhook: %w", provider.Name(), err))
+		return
+	}
+
+	if event != nil {
+		if err := h.ordersUC.UpdatePaymentStatus(r.Context(), provider.Name(), event.PaymentID, event.Status); err != nil {
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error updating payment status: %w", err))
+			return
+		}
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success bool `json:"success"`
+	}{Success: true})
+}
+
 // SendStripeApi returns the Stripe API key for the frontend to initialize Stripe.
 // Endpoint: GET /api/v1/payment/stripeapi
 func (h *PaymentHandler) SendStripeApi(w http.ResponseWriter, r *http.Request) {
@@ -76,3 +221,58 @@ func (h *PaymentHandler) SendStripeApi(w http.ResponseWriter, r *http.Request) {
 
 	_ = utils.WriteJSON(w, http.StatusOK, jsonRes)
 }
+
+// payableOrder returns the order id of the current user, when its payment has not
+// gone through.
+func (h *PaymentHandler) payableOrder(r *http.Request, id string) (*models.Order, error) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		return nil, orders.ErrOrderNotFound
+	}
+
+	orderID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, orders.ErrOrderNotFound
+	}
+
+	return h.ordersUC.GetPayableOrder(r.Context(), orderID, user.ID)
+}
+
+// isOrderError reports whether err is an order that cannot be paid rather than a
+// server failure.
+func isOrderError(err error) bool {
+	for _, e := range []error{orders.ErrOrderNotFound, orders.ErrOrderPaid, orders.ErrOrderCancelled} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// openSession returns the open checkout session id of the current user.
+func (h *PaymentHandler) openSession(r *http.Request, id string) (*models.CheckoutSession, error) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		return nil, checkout.ErrSessionNotFound
+	}
+
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, checkout.ErrSessionNotFound
+	}
+
+	session, err := h.checkoutUC.GetSession(r.Context(), sessionID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch session.Status {
+	case models.CheckoutExpired:
+		return nil, checkout.ErrSessionExpired
+	case models.CheckoutCompleted:
+		return nil, checkout.ErrSessionClosed
+	}
+
+	return session, nil
+}
@@ -2,42 +2,299 @@ package delivery_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/config"
+	mockCheckout "github.com/jofosuware/go/shopit/internal/checkout/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/orders"
+	mockOrders "github.com/jofosuware/go/shopit/internal/orders/mocks"
 	"github.com/jofosuware/go/shopit/internal/payment/delivery"
-	mockCard "github.com/jofosuware/go/shopit/pkg/card/mocks"
 	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/payments"
+	mockPayments "github.com/jofosuware/go/shopit/pkg/payments/mocks"
+	"github.com/jofosuware/go/shopit/pkg/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
-	"github.com/stripe/stripe-go/v72"
 )
 
 func TestProcessPayment(t *testing.T) {
 	cfg := config.Config{}
 	logger := mockLogger.NewLogger(t)
-	carder := mockCard.NewCarder(t)
+	provider := mockPayments.NewProvider(t)
+	checkoutUC := mockCheckout.NewCheckoutUC(t)
 
-	h := delivery.NewPaymentHandler(&cfg, logger, carder)
+	ordersUC := mockOrders.NewOrderUC(t)
+	h := delivery.NewPaymentHandler(&cfg, logger, provider, payments.Providers{models.PaymentStripe: provider},
+		checkoutUC, ordersUC)
 
-	// Updated JSON payload: amount is an integer (5) instead of a string.
-	jsonData := []byte(`{"amount": 5}`)
-	t.Run("Payment is successfully processed", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodPost, "/payment", bytes.NewBuffer(jsonData))
+	user := models.User{ID: uuid.New()}
+	newRequest := func(body string) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, "/payment", bytes.NewBufferString(body))
 		require.NoError(t, err)
+		return req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, &user))
+	}
+
+	// amounts are in minor units of the shop currency
+	newOrder := func(status string) *models.Order {
+		return &models.Order{
+			OrderID:  uuid.New(),
+			UserID:   user.ID,
+			Currency: money.DefaultCurrency,
+			OrderItems: []*models.Item{
+				{Price: money.Of(1000), Quantity: 2},
+				{Price: money.Of(250), Quantity: 1},
+			},
+			ShippingPrice: money.Of(500),
+			TaxPrice:      money.Of(225),
+			Discount:      money.Of(475),
+			// the stored total is not trusted
+			TotalPrice:  money.Of(1),
+			PaymentInfo: models.Payment{ID: "pi_old", Status: status},
+		}
+	}
+
+	t.Run("Order total is charged", func(t *testing.T) {
+		order := newOrder(models.PaymentFailed)
+		ordersUC.On("GetPayableOrder", mock.Anything, order.OrderID, user.ID).Return(order, nil).Once()
+		provider.On("CreatePayment", money.Of(2500)).
+			Return(&payments.Payment{ID: "pi_0", Provider: models.PaymentStripe, Status: "requires_payment_method", ClientSecret: "test_secret"}, "", nil).Once()
+		ordersUC.On("AttachPayment", mock.Anything, order.OrderID,
+			models.Payment{ID: "pi_0", Provider: models.PaymentStripe, Status: "requires_payment_method"}).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.ProcessPayment(rr, newRequest(`{"orderId": "`+order.OrderID.String()+`", "amount": 1}`))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"client_secret":"test_secret"`)
+	})
+
+	t.Run("PayPal payment returns its approval url", func(t *testing.T) {
+		order := newOrder(models.PaymentCanceled)
+		ordersUC.On("GetPayableOrder", mock.Anything, order.OrderID, user.ID).Return(order, nil).Once()
+		provider.On("CreatePayment", money.Of(2500)).Return(&payments.Payment{
+			ID: "5O190127TN364715T", Provider: models.PaymentPayPal, ApprovalURL: "https://paypal.test/approve",
+		}, "", nil).Once()
+		ordersUC.On("AttachPayment", mock.Anything, order.OrderID, mock.Anything).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.ProcessPayment(rr, newRequest(`{"orderId": "`+order.OrderID.String()+`"}`))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"provider":"paypal"`)
+		assert.Contains(t, rr.Body.String(), `"paymentId":"5O190127TN364715T"`)
+		assert.Contains(t, rr.Body.String(), `"approvalUrl":"https://paypal.test/approve"`)
+	})
+
+	t.Run("Paid order is rejected", func(t *testing.T) {
+		id := uuid.New()
+		ordersUC.On("GetPayableOrder", mock.Anything, id, user.ID).Return(nil, orders.ErrOrderPaid).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.ProcessPayment(rr, newRequest(`{"orderId": "`+id.String()+`"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), orders.ErrOrderPaid.Error())
+	})
+
+	t.Run("Order or checkout session is required", func(t *testing.T) {
+		for _, body := range []string{
+			`{"amount": {"amount": 500, "currency": "USD"}}`,
+			`{"orderId": "` + uuid.NewString() + `", "checkoutSession": "` + uuid.NewString() + `"}`,
+		} {
+			logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+			rr := httptest.NewRecorder()
+			h.ProcessPayment(rr, newRequest(body))
+
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		}
+	})
+
+	t.Run("Checkout session total is charged", func(t *testing.T) {
+		user := models.User{ID: uuid.New()}
+		sessionID := uuid.New()
+
+		req, err := http.NewRequest(http.MethodPost, "/payment",
+			bytes.NewBufferString(`{"checkoutSession": "`+sessionID.String()+`"}`))
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, &user))
+
+		rr := httptest.NewRecorder()
+
+		checkoutUC.On("GetSession", mock.Anything, sessionID, user.ID).
+			Return(&models.CheckoutSession{ID: sessionID, Status: models.CheckoutOpen, TotalPrice: money.Of(21500)}, nil).Once()
+		provider.On("CreatePayment", money.Of(21500)).
+			Return(&payments.Payment{ID: "pi_1", Provider: models.PaymentStripe, ClientSecret: "test_secret"}, "", nil).Once()
+		checkoutUC.On("AttachPayment", mock.Anything, sessionID, "pi_1").Return(nil).Once()
+
+		h.ProcessPayment(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Checkout session paid by store credit", func(t *testing.T) {
+		user := models.User{ID: uuid.New()}
+		sessionID := uuid.New()
+
+		req, err := http.NewRequest(http.MethodPost, "/payment",
+			bytes.NewBufferString(`{"checkoutSession": "`+sessionID.String()+`"}`))
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, &user))
 
 		rr := httptest.NewRecorder()
 
-		// Expect CreatePaymentIntent to be called with "usd" and amount 5.
-		carder.On("CreatePaymentIntent", "usd", 5).Return(&stripe.PaymentIntent{ClientSecret: "test_secret"}, "", nil)
+		checkoutUC.On("GetSession", mock.Anything, sessionID, user.ID).Return(&models.CheckoutSession{
+			ID: sessionID, Status: models.CheckoutOpen, CreditApplied: money.Of(21500), TotalPrice: money.Of(0),
+		}, nil).Once()
 
 		h.ProcessPayment(rr, req)
 
-		got := rr.Code
-		want := http.StatusOK
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"client_secret":""`)
+	})
+
+	t.Run("Expired checkout session is rejected", func(t *testing.T) {
+		user := models.User{ID: uuid.New()}
+		sessionID := uuid.New()
+
+		req, err := http.NewRequest(http.MethodPost, "/payment",
+			bytes.NewBufferString(`{"checkoutSession": "`+sessionID.String()+`"}`))
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, &user))
+
+		rr := httptest.NewRecorder()
+
+		checkoutUC.On("GetSession", mock.Anything, sessionID, user.ID).
+			Return(&models.CheckoutSession{ID: sessionID, Status: models.CheckoutExpired}, nil).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.ProcessPayment(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestConfirmPayment(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	provider := mockPayments.NewProvider(t)
+
+	h := delivery.NewPaymentHandler(&config.Config{}, logger, provider, payments.Providers{models.PaymentPayPal: provider},
+		mockCheckout.NewCheckoutUC(t), mockOrders.NewOrderUC(t))
+
+	t.Run("Approved payment is confirmed", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/payment/confirm", bytes.NewBufferString(`{"paymentId": "ORDER-1"}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		provider.On("ConfirmPayment", "ORDER-1").Return(models.PaymentSucceeded, nil).Once()
+
+		h.ConfirmPayment(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"status":"succeeded"`)
+	})
+
+	t.Run("Payment id is required", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/payment/confirm", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.ConfirmPayment(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Provider fails to confirm", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/payment/confirm", bytes.NewBufferString(`{"paymentId": "ORDER-2"}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		provider.On("ConfirmPayment", "ORDER-2").Return("", errors.New("order not approved")).Once()
+		provider.On("Name").Return(models.PaymentPayPal).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+		h.ConfirmPayment(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestWebhook(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	provider := mockPayments.NewProvider(t)
+	ordersUC := mockOrders.NewOrderUC(t)
+
+	h := delivery.NewPaymentHandler(&config.Config{}, logger, provider, payments.Providers{models.PaymentPayPal: provider},
+		mockCheckout.NewCheckoutUC(t), ordersUC)
+
+	newRequest := func(name string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/payment/webhook/"+name, bytes.NewBufferString(`{}`))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("provider", name)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("Payment status is updated", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := newRequest("paypal")
+
+		provider.On("ParseWebhook", req).
+			Return(&payments.Event{PaymentID: "ORDER-1", Status: models.PaymentSucceeded}, nil).Once()
+		provider.On("Name").Return(models.PaymentPayPal).Once()
+		ordersUC.On("UpdatePaymentStatus", mock.Anything, models.PaymentPayPal, "ORDER-1", models.PaymentSucceeded).Return(nil).Once()
+
+		h.Webhook(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Notification without a status change", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := newRequest("paypal")
+
+		provider.On("ParseWebhook", req).Return(nil, nil).Once()
+
+		h.Webhook(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Unverified webhook is rejected", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := newRequest("paypal")
+
+		provider.On("ParseWebhook", req).Return(nil, fmt.Errorf("%w: bad signature", payments.ErrInvalidWebhook)).Once()
+		provider.On("Name").Return(models.PaymentPayPal).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		h.Webhook(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Provider not configured", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.Webhook(rr, newRequest("stripe"))
 
-		assert.Equal(t, want, got)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
 	})
 }
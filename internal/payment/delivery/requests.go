@@ -0,0 +1,20 @@
+package delivery
+
+import "github.com/jofosuware/go/shopit/pkg/validator"
+
+// paymentRequest is the body of ProcessPayment. It names an order or a checkout
+// session, not both.
+type paymentRequest struct {
+	OrderID         string `json:"orderId" validate:"omitempty,uuid"`
+	CheckoutSession string `json:"checkoutSession" validate:"omitempty,uuid"`
+}
+
+func (req *paymentRequest) Validate(v *validator.Validator) {
+	v.CheckCode((req.OrderID == "") != (req.CheckoutSession == ""), "orderId", validator.CodeRequired,
+		"either orderId or checkoutSession must be provided")
+}
+
+// confirmRequest is the body of ConfirmPayment.
+type confirmRequest struct {
+	PaymentID string `json:"paymentId" validate:"required"`
+}
@@ -9,10 +9,14 @@ import (
 func (h *PaymentHandler) PaymentRouter() http.Handler {
 	mux := chi.NewRouter()
 
+	// providers sign their webhooks instead of authenticating
+	mux.Post("/webhook/{provider}", h.Webhook)
+
 	mux.Group(func(r chi.Router) {
 		r.Use(utils.IsAuthenticated)
 
 		r.Post("/process", h.ProcessPayment)
+		r.Post("/confirm", h.ConfirmPayment)
 		r.Get("/stripeapi", h.SendStripeApi)
 	})
 
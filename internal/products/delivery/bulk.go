@@ -0,0 +1,47 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// BulkUpdateProducts sets the price, the stock, or both, of many products in one
+// transaction and reports the updates that were skipped as invalid (admin).
+// Endpoint: PATCH /api/v1/product/admin/products/bulk
+// Expects JSON body: {"updates": [{"productId": <id>, "price": <amount>, "stock": <int>}]},
+// price and stock each optional, at most products.MaxBulkUpdates updates.
+func (h *ProdHandlers) BulkUpdateProducts(w http.ResponseWriter, r *http.Request) {
+	var req bulkUpdateRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	report, err := h.prodUC.BulkUpdateProducts(req.Updates)
+	if err != nil {
+		if errors.Is(err, products.ErrProductNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error updating products: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error updating products: %w", err))
+		return
+	}
+
+	jr := struct {
+		Success bool `json:"success"`
+		*models.BulkUpdateReport
+	}{
+		Success:          true,
+		BulkUpdateReport: report,
+	}
+
+	if err = utils.WriteJSON(w, http.StatusOK, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
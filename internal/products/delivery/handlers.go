@@ -1,43 +1,67 @@
 // Package delivery provides HTTP handlers for product endpoints.
 //
 // It wires handler methods for creating, listing, updating, and deleting
-// products, as well as for product reviews.
+// products, as well as for product reviews, product views and related products,
+// search analytics and search synonyms.
 package delivery
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
 	"github.com/jofosuware/go/shopit/internal/models"
 	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
 	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/sheets"
 	"github.com/jofosuware/go/shopit/pkg/utils"
 	"github.com/jofosuware/go/shopit/pkg/validator"
 )
 
+// MaxImportSize is the largest CSV file accepted by ImportProducts, in bytes.
+const MaxImportSize = 10 << 20
+
 // UserContextKey is the request context key used to store the authenticated user.
 const UserContextKey = utils.UserContextKey
 
 // ProdHandlers provides HTTP handler methods for product endpoints.
 type ProdHandlers struct {
-	logger logger.Logger
-	prodUC products.ProductUC
+	logger    logger.Logger
+	prodUC    products.ProductUC
+	rates     *exchange.Converter
+	hideStock bool
 }
 
-// NewProdHandlers returns a new ProdHandlers with the provided logger and usecase.
-func NewProdHandlers(logger logger.Logger, prodUC products.ProductUC) *ProdHandlers {
+// NewProdHandlers returns a new ProdHandlers with the provided logger, usecase and
+// currency converter. Products are shown to shoppers as storefront configures.
+func NewProdHandlers(logger logger.Logger, prodUC products.ProductUC, rates *exchange.Converter,
+	storefront config.Storefront) *ProdHandlers {
 	return &ProdHandlers{
-		logger: logger,
-		prodUC: prodUC,
+		logger:    logger,
+		prodUC:    prodUC,
+		rates:     rates,
+		hideStock: storefront.HideStock,
 	}
 }
 
-// CreateProduct creates a new product (admin).
+// CreateProduct creates a new product owned by the current user (admin or seller).
 // Endpoint: POST /api/v1/product/admin/product/new
-// Expects form data: name, price, description, images, category, seller, stock.
+// Expects form data: name, sku, price, description, images, categoryId (or a category
+// name), seller, stock, and optionally currency (the shop currency by default) and
+// variants as a JSON array of {"sku", "attributes", "priceDelta", "stock"}; priceDelta is
+// in the product currency.
 func (h *ProdHandlers) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(UserContextKey).(*models.User)
 	if !ok {
@@ -45,111 +69,492 @@ func (h *ProdHandlers) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		h.logger.Errorf("reading json error: %s", "user must login as admin to perform this task")
 		return
 	}
-	var p models.Product
+	var req productRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+	p := req.product()
+	p.UserId = user.ID
 
-	// Parse form
-	err := r.ParseMultipartForm(100000)
+	res, err := h.prodUC.CreateProduct(r.Context(), p, formImages(r))
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("reading json error: %v", err)
+		if h.invalidProduct(w, r, err) {
+			h.logger.Errorf("error creating product: %v", err)
+			return
+		}
+		if errors.Is(err, cloudinary.ErrUnavailable) {
+			_ = utils.ServiceUnavailable(w, r, errors.New("image storage is temporarily unavailable, try again later"))
+			h.logger.Errorf("error creating product: %v", err)
+			return
+		}
+		if errors.Is(err, products.ErrCategoryNotFound) || errors.Is(err, cloudinary.ErrRejected) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error creating product: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error creating product: %w", err))
+		return
+	}
+
+	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// ValidateProduct runs the product validation pipeline on the submitted product without
+// saving it, and reports every problem found (admin). A price or stock that cannot be
+// read is answered with 422, like on creation.
+// Endpoint: POST /api/v1/product/admin/validate
+// Expects the form data of product creation, plus an optional id to validate an update.
+func (h *ProdHandlers) ValidateProduct(w http.ResponseWriter, r *http.Request) {
+	err := utils.ParseMultipartForm(w, r)
+	if err != nil {
+		_ = utils.ReadError(w, r, err)
+		h.logger.Errorf("error parsing form: %v", err)
 		return
 	}
 
-	name := r.Form.Get("name")
-	price, _ := strconv.ParseFloat(r.Form.Get("price"), 64)
-	description := r.Form.Get("description")
-	ratings, _ := strconv.Atoi(r.Form.Get("ratings"))
-	multipartForm := r.MultipartForm
-	images := multipartForm.File["images"]
-	category := r.Form.Get("category")
-	seller := r.Form.Get("seller")
-	stock, _ := strconv.Atoi(r.Form.Get("stock"))
+	var p models.Product
+
+	if id := r.Form.Get("id"); id != "" {
+		p.ProductId, err = uuid.Parse(id)
+		if err != nil {
+			_ = utils.BadRequest(w, r, errors.New("invalid product id"))
+			h.logger.Errorf("error parsing uuid: %v", err)
+			return
+		}
+	}
 
-	// validate data
 	v := validator.New()
 
-	v.Check(name != "", "name", "product name must be provided")
-	v.Check(description != "", "description", "product description must be provided")
-	v.Check(seller != "", "seller", "product seller must be provided")
+	p.Name = r.Form.Get("name")
+	p.SKU = r.Form.Get("sku")
+	p.Price, err = money.Parse(r.Form.Get("price"), readCurrency(r))
+	v.Check(err == nil, "price", "price must be an amount such as 49.99")
+	p.Description = r.Form.Get("description")
+	p.Category = r.Form.Get("category")
+	p.CategoryId, err = readCategoryID(r)
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing category id: %v", err)
+		return
+	}
+	p.Seller = r.Form.Get("seller")
+	if stock := r.Form.Get("stock"); stock != "" {
+		p.Stock, err = strconv.Atoi(stock)
+		v.Check(err == nil, "stock", "stock must be a whole number")
+	}
 
 	if !v.Valid() {
-		utils.FailedValidation(w, r, v.Errors)
+		utils.FailedValidation(w, r, v)
 		h.logger.Errorf("Failed validation: %v", v.Errors)
 		return
 	}
 
-	p.Name = name
-	p.Price = price
-	p.Description = description
-	p.Category = category
-	p.Ratings = ratings
-	p.Seller = seller
-	p.Stock = stock
-	p.UserId = user.ID
-
-	res, err := h.prodUC.CreateProduct(p, images)
+	res, err := h.prodUC.ValidateProduct(p, r.MultipartForm.File["images"])
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("error creating product: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error validating product: %w", err))
 		return
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// ImportProducts creates and updates products from a CSV file and reports the rows
+// that could not be imported (admin).
+// Endpoint: POST /api/v1/product/admin/import
+// Expects a CSV body whose header names the columns id, sku, name, description, price,
+// stock, category and seller; id and sku are optional.
+func (h *ProdHandlers) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("user must login as admin to perform this task"))
+		h.logger.Errorf("reading json error: %s", "user must login as admin to perform this task")
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, MaxImportSize)
+
+	report, err := h.prodUC.ImportProducts(r.Context(), body, user.ID)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			_ = utils.TooLarge(w, r, MaxImportSize)
+			h.logger.Errorf("error importing products: %v", err)
+		case errors.Is(err, products.ErrInvalidCSV), errors.Is(err, products.ErrTooManyRows):
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error importing products: %v", err)
+		default:
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error importing products: %w", err))
+		}
+		return
+	}
+
+	jr := struct {
+		Success bool `json:"success"`
+		*models.ProductImportReport
+	}{
+		Success:             true,
+		ProductImportReport: report,
+	}
+
+	if err = utils.WriteJSON(w, http.StatusOK, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// SyncSheet imports the products of the catalog Google Sheet, in the format of
+// ImportProducts, and reports the rows that could not be imported (admin). Products
+// it adds are owned by the admin syncing.
+// Endpoint: POST /api/v1/product/admin/import/sheet
+func (h *ProdHandlers) SyncSheet(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("user must login as admin to perform this task"))
+		h.logger.Errorf("reading json error: %s", "user must login as admin to perform this task")
+		return
+	}
+
+	report, err := h.prodUC.SyncSheet(r.Context(), user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, products.ErrSheetNotConfigured), errors.Is(err, sheets.ErrNotShared),
+			errors.Is(err, sheets.ErrTooLarge), errors.Is(err, products.ErrInvalidCSV),
+			errors.Is(err, products.ErrTooManyRows):
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error syncing catalog sheet: %v", err)
+		default:
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error syncing catalog sheet: %w", err))
+		}
+		return
+	}
+
+	jr := struct {
+		Success bool `json:"success"`
+		*models.ProductImportReport
+	}{
+		Success:             true,
+		ProductImportReport: report,
+	}
+
+	if err = utils.WriteJSON(w, http.StatusOK, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
 
-// GetProducts returns a list of products.
+// ExportProducts sends every product as a CSV file that ImportProducts accepts (admin).
+// Endpoint: GET /api/v1/product/admin/export
+func (h *ProdHandlers) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	cw := &countingWriter{w: w}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="products.csv"`)
+
+	if err := h.prodUC.ExportProducts(cw); err != nil {
+		if cw.n == 0 {
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error exporting products: %w", err))
+			return
+		}
+		// The response is already under way, so the truncated file is all the
+		// client gets.
+		h.logger.Errorf("error exporting products: %v", err)
+	}
+}
+
+// invalidProduct answers a products.ValidationError with 422 and the error of each
+// field, and reports whether err was one.
+func (h *ProdHandlers) invalidProduct(w http.ResponseWriter, r *http.Request, err error) bool {
+	var invalid *products.ValidationError
+	if !errors.As(err, &invalid) {
+		return false
+	}
+
+	utils.FailedValidation(w, r, &validator.Validator{Errors: invalid.Errors, Codes: invalid.Codes})
+
+	return true
+}
+
+// formImages returns the images uploaded with the form of r, none when the body was not
+// a multipart form.
+func formImages(r *http.Request) []*multipart.FileHeader {
+	if r.MultipartForm == nil {
+		return nil
+	}
+
+	return r.MultipartForm.File["images"]
+}
+
+// readCurrency returns the currency form field upper case, the shop currency when it
+// is absent.
+func readCurrency(r *http.Request) string {
+	currency := strings.ToUpper(strings.TrimSpace(r.Form.Get("currency")))
+	if currency == "" {
+		return money.DefaultCurrency
+	}
+
+	return currency
+}
+
+// variantsIn labels the price deltas of variants with currency, the currency of their
+// product.
+func variantsIn(variants []models.Variant, currency string) []models.Variant {
+	for i := range variants {
+		variants[i].PriceDelta = variants[i].PriceDelta.WithCurrency(currency)
+	}
+
+	return variants
+}
+
+// localize converts the prices of p to currency for display. Prices that cannot be
+// converted are left in the product currency.
+func (h *ProdHandlers) localize(p *models.Product, currency string) {
+	price, err := h.rates.Convert(p.Price, currency)
+	if err != nil {
+		h.logger.Errorf("error converting product price: %v", err)
+		return
+	}
+
+	for i, v := range p.Variants {
+		delta, err := h.rates.Convert(v.PriceDelta, currency)
+		if err != nil {
+			h.logger.Errorf("error converting variant price: %v", err)
+			return
+		}
+		p.Variants[i].PriceDelta = delta
+	}
+	p.Price = price
+}
+
+// readCategoryID parses the categoryId form field. The category may be given by
+// name in the category field instead.
+func readCategoryID(r *http.Request) (uuid.NullUUID, error) {
+	raw := strings.TrimSpace(r.Form.Get("categoryId"))
+	if raw == "" {
+		return uuid.NullUUID{}, nil
+	}
+
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.NullUUID{}, errors.New("category id must be a valid uuid")
+	}
+
+	return uuid.NullUUID{UUID: id, Valid: true}, nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// GetProducts returns a list of products, priced in the currency of the X-Currency
+// header, else of the user preference, else of the shop. The first page of a keyword
+// search is recorded with its result count, and its searchId is returned to report
+// the clicks on its results.
 // Endpoint: GET /api/v1/product/products
-// Query params: keyword, page.
+// Query params: keyword, category (an id or a name, subcategories included), page.
 func (h *ProdHandlers) GetProducts(w http.ResponseWriter, r *http.Request) {
+	currency, err := exchange.Currency(r)
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error getting products: %v", err)
+		return
+	}
+
 	keyword := r.URL.Query().Get("keyword")
+	category := r.URL.Query().Get("category")
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
 
-	res, err := h.prodUC.GetProducts(keyword, page)
+	res, err := h.prodUC.GetProducts(keyword, category, page)
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("error getting products: %v", err)
+		if errors.Is(err, products.ErrCategoryNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error getting products: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting products: %w", err))
 		return
 	}
 
-	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+	for i := range res.Products {
+		h.localize(&res.Products[i], currency)
+	}
+
+	// the first page of a keyword search is logged for the search analytics
+	if strings.TrimSpace(keyword) != "" && page <= 1 {
+		id, err := h.prodUC.RecordSearch(keyword, res.ProductCount)
+		if err != nil {
+			h.logger.Errorf("error recording search: %v", err)
+		} else if id != uuid.Nil {
+			res.SearchID = id.String()
+		}
+	}
+
+	if err = utils.WriteJSON(w, http.StatusOK, h.publicProducts(res)); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
 
-// GetAdminProducts returns all products (admin).
-// Endpoint: GET /api/v1/product/admin/products
-func (h *ProdHandlers) GetAdminProducts(w http.ResponseWriter, r *http.Request) {
-	prods, err := h.prodUC.GetAdminProducts()
+// RecordSearchClick records a click on a product found by a keyword search.
+// Endpoint: POST /api/v1/product/search/click
+// Expects JSON body: {"searchId": <id>, "productId": <id>}, the searchId returned by
+// GetProducts.
+func (h *ProdHandlers) RecordSearchClick(w http.ResponseWriter, r *http.Request) {
+	var req searchClickRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+	searchID, productID := uuid.MustParse(req.SearchID), uuid.MustParse(req.ProductID)
+
+	if err := h.prodUC.RecordSearchClick(searchID, productID); err != nil {
+		if errors.Is(err, products.ErrSearchNotFound) || errors.Is(err, products.ErrProductNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error recording search click: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error recording search click: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success bool `json:"success"`
+	}{Success: true})
+}
+
+// GetZeroResultSearches returns the keywords searched that found no product, searched
+// most first, with how often and when they were last searched (admin).
+// Endpoint: GET /api/v1/product/admin/search/zero-results?since=<RFC 3339 time>&limit=<int>
+// Without since the last 30 days are counted; limit defaults to 20 and is capped at 100.
+func (h *ProdHandlers) GetZeroResultSearches(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			_ = utils.BadRequest(w, r, errors.New("since must be an RFC 3339 time"))
+			h.logger.Errorf("error parsing since: %v", err)
+			return
+		}
+		since = t
+	}
+
+	var limit int
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			_ = utils.BadRequest(w, r, errors.New("limit must be a positive number"))
+			h.logger.Errorf("error parsing limit: %v", l)
+			return
+		}
+		limit = n
+	}
+
+	terms, err := h.prodUC.GetZeroResultSearches(since, limit)
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("error getting products: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting zero result searches: %w", err))
 		return
 	}
+	if terms == nil {
+		terms = []models.SearchTerm{}
+	}
 
 	jr := struct {
-		Success  bool              `json:"success"`
-		Products []*models.Product `json:"products"`
+		Success  bool                `json:"success"`
+		Searches []models.SearchTerm `json:"searches"`
 	}{
 		Success:  true,
-		Products: prods,
+		Searches: terms,
 	}
 
-	if err = utils.WriteJSON(w, http.StatusOK, jr); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// GetAdminProducts returns a page of products in every status, hidden ones included,
+// with their images (admin or seller). Sellers only list their own products; admins
+// list those of the seller sellerId when it is given.
+// Endpoint: GET /api/v1/product/admin/products?keyword=<string>&sort=<string>&page=<int>&limit=<int>&sellerId=<uuid>
+// sort is date, price or stock, prefixed with "-" to sort descending, and defaults to
+// -date; limit defaults to 20 and is capped at 100.
+func (h *ProdHandlers) GetAdminProducts(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("user cannot be found, login"))
+		h.logger.Errorf("error getting user: %v", errors.New("user not found"))
+		return
+	}
+
+	var owner uuid.NullUUID
+	if user.Role != models.RoleAdmin {
+		owner = uuid.NullUUID{UUID: user.ID, Valid: true}
+	} else if s := r.URL.Query().Get("sellerId"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			_ = utils.BadRequest(w, r, errors.New("sellerId must be a uuid"))
+			h.logger.Errorf("error parsing sellerId: %v", err)
+			return
+		}
+		owner = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	sort := r.URL.Query().Get("sort")
+	if sort != "" && !slices.Contains(models.ProductSorts, strings.TrimPrefix(sort, "-")) {
+		_ = utils.BadRequest(w, r, fmt.Errorf("sort must be one of %s, optionally prefixed with -", strings.Join(models.ProductSorts, ", ")))
+		h.logger.Errorf("error parsing sort: %v", sort)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+	var limit int
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			_ = utils.BadRequest(w, r, errors.New("limit must be a positive number"))
+			h.logger.Errorf("error parsing limit: %v", l)
+			return
+		}
+		limit = n
+	}
+
+	res, err := h.prodUC.GetAdminProducts(r.URL.Query().Get("keyword"), sort, page, limit, owner)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting products: %w", err))
+		return
+	}
+	if res.Products == nil {
+		res.Products = []models.Product{}
+	}
+
+	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
 
-// GetSingleProduct returns a product by ID.
+// GetSingleProduct returns a product by ID, priced like GetProducts. Hidden and
+// missing products are not found.
 // Endpoint: GET /api/v1/product/product/{id}
 func (h *ProdHandlers) GetSingleProduct(w http.ResponseWriter, r *http.Request) {
+	currency, err := exchange.Currency(r)
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error getting product: %v", err)
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 
 	if id == "" {
@@ -167,26 +572,38 @@ func (h *ProdHandlers) GetSingleProduct(w http.ResponseWriter, r *http.Request)
 
 	res, err := h.prodUC.GetSingleProduct(parsedId)
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("error getting product: %v", err)
+		if errors.Is(err, products.ErrProductNotFound) {
+			_ = utils.NotFound(w, r, err)
+			h.logger.Errorf("error getting product: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting product: %w", err))
 		return
 	}
 
-	jr := models.ProdResponse{
+	if !res.Listed() {
+		_ = utils.NotFound(w, r, products.ErrProductNotFound)
+		h.logger.Errorf("error getting product: %v", products.ErrProductNotFound)
+		return
+	}
+	h.localize(res, currency)
+
+	jr := productResponse{
 		Success: true,
-		Product: *res,
+		Product: h.publicProduct(res),
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, jr); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
 
-// UpdateProduct updates a product (admin).
+// UpdateProduct updates a product (admin, or the seller who owns it).
 // Endpoint: PUT /api/v1/product/admin/product/{id}
-// Expects form data similar to product creation.
+// Expects form data similar to product creation. Variants listed with their id are
+// updated, those without are added and the others removed; leaving variants out of
+// the form keeps them as they are.
 func (h *ProdHandlers) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(UserContextKey).(*models.User)
 	if !ok {
@@ -209,66 +626,59 @@ func (h *ProdHandlers) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var p models.Product
-
-	// Parse form
-	err = r.ParseMultipartForm(100000)
-	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("reading json error: %v", err)
-		return
-	}
-
-	name := r.Form.Get("name")
-	price, _ := strconv.ParseFloat(r.Form.Get("price"), 64)
-	description := r.Form.Get("description")
-	ratings, _ := strconv.Atoi(r.Form.Get("ratings"))
-	multipartForm := r.MultipartForm
-	images := multipartForm.File["images"]
-	img, _ := utils.ExtractImages(images)
-	category := r.Form.Get("category")
-	seller := r.Form.Get("seller")
-	stock, _ := strconv.Atoi(r.Form.Get("stock"))
-
-	// validate data
-	v := validator.New()
-
-	v.Check(name != "", "name", "product name must be provided")
-	v.Check(description != "", "description", "product description must be provided")
-	v.Check(seller != "", "seller", "product seller must be provided")
-
-	if !v.Valid() {
-		utils.FailedValidation(w, r, v.Errors)
-		h.logger.Errorf("Failed validation: %v", v.Errors)
+	var req productRequest
+	if !utils.Bind(w, r, h.logger, &req) {
 		return
 	}
+	img, _ := utils.ExtractImages(formImages(r))
 
-	p.Name = name
-	p.Price = price
-	p.Description = description
-	p.Category = category
-	p.Ratings = ratings
-	p.Seller = seller
-	p.Stock = stock
-	p.UserId = user.ID
-
-	res, err := h.prodUC.UpdateProduct(parsedId, p, img)
+	res, err := h.prodUC.UpdateProduct(r.Context(), parsedId, req.product(), img, user)
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("error updating product: %v", err)
+		if h.invalidProduct(w, r, err) {
+			h.logger.Errorf("error updating product: %v", err)
+			return
+		}
+		if errors.Is(err, products.ErrNotProductOwner) {
+			_ = utils.Forbidden(w, r)
+			h.logger.Errorf("error updating product: %v", err)
+			return
+		}
+		if errors.Is(err, cloudinary.ErrUnavailable) {
+			_ = utils.ServiceUnavailable(w, r, errors.New("image storage is temporarily unavailable, try again later"))
+			h.logger.Errorf("error updating product: %v", err)
+			return
+		}
+		if errors.Is(err, products.ErrProductNotFound) {
+			_ = utils.NotFound(w, r, err)
+			h.logger.Errorf("error updating product: %v", err)
+			return
+		}
+		if errors.Is(err, products.ErrVariantNotFound) || errors.Is(err, products.ErrCategoryNotFound) ||
+			errors.Is(err, cloudinary.ErrRejected) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error updating product: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error updating product: %w", err))
 		return
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, res); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
 
-// DeleteProduct deletes a product (admin).
+// DeleteProduct deletes a product (admin, or the seller who owns it).
 // Endpoint: DELETE /api/v1/product/admin/product/{id}
 func (h *ProdHandlers) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("user cannot be found, login"))
+		h.logger.Errorf("error getting user: %v", errors.New("user not found"))
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 
 	if id == "" {
@@ -284,10 +694,24 @@ func (h *ProdHandlers) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.prodUC.DeleteProduct(parsedId)
+	err = h.prodUC.DeleteProduct(r.Context(), parsedId, user)
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("error deleting product: %v", err)
+		if errors.Is(err, cloudinary.ErrUnavailable) {
+			_ = utils.ServiceUnavailable(w, r, errors.New("image storage is temporarily unavailable, try again later"))
+			h.logger.Errorf("error deleting product: %v", err)
+			return
+		}
+		if errors.Is(err, products.ErrNotProductOwner) {
+			_ = utils.Forbidden(w, r)
+			h.logger.Errorf("error deleting product: %v", err)
+			return
+		}
+		if errors.Is(err, products.ErrProductNotFound) {
+			_ = utils.NotFound(w, r, err)
+			h.logger.Errorf("error deleting product: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error deleting product: %w", err))
 		return
 	}
 
@@ -300,15 +724,15 @@ func (h *ProdHandlers) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, jr); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
 
 // CreateProductReview creates a new review for a product.
 // Endpoint: POST /api/v1/product/review
-// Expects form data: rating, comment, productId.
+// Expects form data: rating, comment, productId, and up to 3 images, jpeg, png, gif or
+// webp files.
 func (h *ProdHandlers) CreateProductReview(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(UserContextKey).(*models.User)
 	if !ok {
@@ -317,32 +741,22 @@ func (h *ProdHandlers) CreateProductReview(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err := r.ParseMultipartForm(100000)
-	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("error parsing form: %v", err)
+	var req reviewRequest
+	if !utils.Bind(w, r, h.logger, &req) {
 		return
 	}
 
-	rating := r.Form.Get("rating")
-	comment := r.Form.Get("comment")
-	productId := r.Form.Get("productId")
-
-	rtg, _ := strconv.Atoi(rating)
-	parsedProdId, _ := uuid.Parse(productId)
-
 	review := models.Reviews{
 		UserId:    user.ID,
 		Name:      user.Name,
-		Rating:    rtg,
-		Comment:   comment,
-		ProductId: parsedProdId,
+		Rating:    req.Rating,
+		Comment:   req.Comment,
+		ProductId: req.ProductID,
 	}
 
-	err = h.prodUC.CreateProductReview(review)
+	err := h.prodUC.CreateProductReview(r.Context(), review, formImages(r))
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("error creating product review: %v", err)
+		h.writeImageError(w, r, "error creating product review", err)
 		return
 	}
 
@@ -353,15 +767,15 @@ func (h *ProdHandlers) CreateProductReview(w http.ResponseWriter, r *http.Reques
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, jr); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
 
 // GetProductReviews returns reviews for a product.
 // Endpoint: GET /api/v1/product/reviews
-// Query param: id (product ID).
+// Query params: id (product ID), sort (oldest, the default, newest or helpful, the
+// most helpful first).
 func (h *ProdHandlers) GetProductReviews(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 	if id == "" {
@@ -377,10 +791,16 @@ func (h *ProdHandlers) GetProductReviews(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	reviews, err := h.prodUC.GetProductReviews(parsedId)
+	sort := r.URL.Query().Get("sort")
+	if sort != "" && !slices.Contains(models.ReviewSorts, sort) {
+		_ = utils.BadRequest(w, r, fmt.Errorf("sort must be one of %s", strings.Join(models.ReviewSorts, ", ")))
+		h.logger.Errorf("error parsing sort: %v", sort)
+		return
+	}
+
+	reviews, err := h.prodUC.GetProductReviews(parsedId, sort)
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("error getting product reviews: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting product reviews: %w", err))
 		return
 	}
 
@@ -393,8 +813,7 @@ func (h *ProdHandlers) GetProductReviews(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, jr); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
@@ -426,8 +845,12 @@ func (h *ProdHandlers) DeleteProductReview(w http.ResponseWriter, r *http.Reques
 
 	err = h.prodUC.DeleteProductReview(parsedProductId, parsedId)
 	if err != nil {
-		_ = utils.BadRequest(w, r, errors.New("something went wrong, try again"))
-		h.logger.Errorf("error deleting product review: %v", err)
+		if errors.Is(err, products.ErrReviewNotFound) || errors.Is(err, products.ErrProductNotFound) {
+			_ = utils.NotFound(w, r, err)
+			h.logger.Errorf("error deleting product review: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error deleting product review: %w", err))
 		return
 	}
 
@@ -438,8 +861,7 @@ func (h *ProdHandlers) DeleteProductReview(w http.ResponseWriter, r *http.Reques
 	}
 
 	if err = utils.WriteJSON(w, http.StatusOK, jr); err != nil {
-		_ = utils.BadRequest(w, r, err)
-		h.logger.Errorf("error writing json: %v", err)
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
 		return
 	}
 }
@@ -2,21 +2,36 @@ package delivery_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
 	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
 	"github.com/jofosuware/go/shopit/internal/products/delivery"
 	prodMock "github.com/jofosuware/go/shopit/internal/products/mocks"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
+	"github.com/jofosuware/go/shopit/pkg/featureflag"
 	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/sheets"
 	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/jofosuware/go/shopit/pkg/validator"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -26,7 +41,7 @@ func TestAddProduct(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	prodUC := prodMock.NewProductUC(t)
 
-	h := delivery.NewProdHandlers(logger, prodUC)
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
 	t.Run("Product added successfully", func(t *testing.T) {
 		formData := url.Values{
 			"name":        {"test"},
@@ -50,7 +65,7 @@ func TestAddProduct(t *testing.T) {
 
 		multipartForm := req.MultipartForm
 		images := multipartForm.File["images"]
-		price, _ := strconv.ParseFloat(formData.Get("price"), 64)
+		price, _ := money.Parse(formData.Get("price"), money.DefaultCurrency)
 		stock, _ := strconv.Atoi(formData.Get("stock"))
 
 		user := models.User{
@@ -61,7 +76,7 @@ func TestAddProduct(t *testing.T) {
 		ctx := context.WithValue(req.Context(), UserContextKey, &user)
 		req = req.WithContext(ctx)
 
-		prodUC.On("CreateProduct", models.Product{
+		prodUC.On("CreateProduct", mock.Anything, models.Product{
 			Name:        formData.Get("name"),
 			Price:       price,
 			Description: formData.Get("description"),
@@ -79,13 +94,149 @@ func TestAddProduct(t *testing.T) {
 		assert.Equal(t, want, got)
 	})
 
+	newRequest := func(t *testing.T, formData url.Values) *http.Request {
+		payload, ct, err := utils.CreateMultipartForm(formData)
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, "/new", payload)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+		return req.WithContext(context.WithValue(req.Context(), UserContextKey, &models.User{ID: uuid.New()}))
+	}
+
+	t.Run("Product added with variants", func(t *testing.T) {
+		categoryID := uuid.New()
+		rr := httptest.NewRecorder()
+		prodUC.On("CreateProduct", mock.Anything, mock.MatchedBy(func(p models.Product) bool {
+			return len(p.Variants) == 2 && p.Variants[1].Attributes["size"] == "M" && p.Variants[1].PriceDelta == money.Of(500) &&
+				p.CategoryId == uuid.NullUUID{UUID: categoryID, Valid: true}
+		}), mock.Anything).Return(&models.ProdResponse{Success: true}, nil).Once()
+
+		h.CreateProduct(rr, newRequest(t, url.Values{
+			"name":        {"Shirt"},
+			"price":       {"20"},
+			"description": {"A shirt"},
+			"seller":      {"test"},
+			"categoryId":  {categoryID.String()},
+			"variants":    {`[{"attributes": {"size": "S"}, "stock": 3}, {"attributes": {"size": "M"}, "priceDelta": "5.00"}]`},
+		}))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Product priced in another currency", func(t *testing.T) {
+		require.NoError(t, money.Accept("EUR"))
+		t.Cleanup(func() { money.Accept() })
+
+		rr := httptest.NewRecorder()
+		prodUC.On("CreateProduct", mock.Anything, mock.MatchedBy(func(p models.Product) bool {
+			return p.Price == money.New(2000, "EUR") && p.Variants[0].PriceDelta == money.New(500, "EUR")
+		}), mock.Anything).Return(&models.ProdResponse{Success: true}, nil).Once()
+
+		h.CreateProduct(rr, newRequest(t, url.Values{
+			"name":        {"Shirt"},
+			"price":       {"20"},
+			"currency":    {"eur"},
+			"description": {"A shirt"},
+			"seller":      {"test"},
+			"category":    {"Clothes"},
+			"variants":    {`[{"attributes": {"size": "M"}, "priceDelta": "5.00"}]`},
+		}))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Currency not accepted", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.CreateProduct(rr, newRequest(t, url.Values{
+			"name":        {"Shirt"},
+			"price":       {"20"},
+			"currency":    {"XYZ"},
+			"description": {"A shirt"},
+			"seller":      {"test"},
+			"category":    {"Clothes"},
+		}))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Invalid variants", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.CreateProduct(rr, newRequest(t, url.Values{
+			"name":        {"Shirt"},
+			"price":       {"20"},
+			"description": {"A shirt"},
+			"seller":      {"test"},
+			"variants":    {`[{"attributes": {"size": "S"}}, {"attributes": {"size": "S"}}]`},
+		}))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Malformed variants", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.CreateProduct(rr, newRequest(t, url.Values{"name": {"Shirt"}, "variants": {"size=S"}}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unknown category", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		prodUC.On("CreateProduct", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("%w: %q", products.ErrCategoryNotFound, "Gadgets")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.CreateProduct(rr, newRequest(t, url.Values{
+			"name":        {"Shirt"},
+			"price":       {"20"},
+			"description": {"A shirt"},
+			"seller":      {"test"},
+			"category":    {"Gadgets"},
+		}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Malformed category id", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.CreateProduct(rr, newRequest(t, url.Values{"name": {"Shirt"}, "categoryId": {"cameras"}}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid product", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		prodUC.On("CreateProduct", mock.Anything, mock.Anything, mock.Anything).Return(nil, &products.ValidationError{
+			Errors: map[string]string{"images": "product images must be provided"},
+			Codes:  map[string]string{"images": validator.CodeRequired},
+		}).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.CreateProduct(rr, newRequest(t, url.Values{
+			"name":        {"Shirt"},
+			"price":       {"20"},
+			"description": {"A shirt"},
+			"seller":      {"test"},
+			"category":    {"Clothes/Shoes"},
+		}))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "product images must be provided")
+	})
 }
 
 func TestGetProducts(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	prodUC := prodMock.NewProductUC(t)
 
-	h := delivery.NewProdHandlers(logger, prodUC)
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
 
 	t.Run("Products retrieved successfully", func(t *testing.T) {
 		req, err := http.NewRequest("GET", "/products", nil)
@@ -93,7 +244,7 @@ func TestGetProducts(t *testing.T) {
 
 		rr := httptest.NewRecorder()
 
-		prodUC.On("GetProducts", "", 0).Return(&models.GetProd{}, nil)
+		prodUC.On("GetProducts", "", "", 0).Return(&models.GetProd{}, nil)
 
 		h.GetProducts(rr, req)
 
@@ -102,266 +253,1611 @@ func TestGetProducts(t *testing.T) {
 
 		assert.Equal(t, want, got)
 	})
-}
 
-func TestGetAdminProducts(t *testing.T) {
-	logger := mockLogger.NewLogger(t)
-	prodUC := prodMock.NewProductUC(t)
+	t.Run("Unknown category", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/products?category=Gadgets&page=2", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
 
-	h := delivery.NewProdHandlers(logger, prodUC)
+		prodUC.On("GetProducts", "", "Gadgets", 2).Return(nil, fmt.Errorf("%w: %q", products.ErrCategoryNotFound, "Gadgets")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
 
-	t.Run("Products retrieved successfully", func(t *testing.T) {
-		req, err := http.NewRequest("GET", "/admin/products", nil)
+		h.GetProducts(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Prices in the currency of the header", func(t *testing.T) {
+		require.NoError(t, money.Accept("EUR"))
+		t.Cleanup(func() { money.Accept() })
+
+		req, err := http.NewRequest("GET", "/products?keyword=lens", nil)
 		require.NoError(t, err)
+		req.Header.Set(exchange.Header, "eur")
 
 		rr := httptest.NewRecorder()
 
-		prodUC.On("GetAdminProducts").Return([]*models.Product{}, nil)
+		prodUC.On("GetProducts", "lens", "", 0).Return(&models.GetProd{
+			Products: []models.Product{{
+				Price:    money.Of(1000),
+				Variants: []models.Variant{{PriceDelta: money.Of(200)}},
+			}},
+		}, nil).Once()
+		prodUC.On("RecordSearch", "lens", 0).Return(uuid.New(), nil).Once()
 
-		h.GetAdminProducts(rr, req)
+		h.GetProducts(rr, req)
 
-		got := rr.Code
-		want := http.StatusOK
+		require.Equal(t, http.StatusOK, rr.Code)
 
-		assert.Equal(t, want, got)
+		var res models.GetProd
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		require.Len(t, res.Products, 1)
+		assert.Equal(t, money.New(500, "EUR"), res.Products[0].Price)
+		assert.Equal(t, money.New(100, "EUR"), res.Products[0].Variants[0].PriceDelta)
 	})
-}
 
-func TestGetSingleProduct(t *testing.T) {
-	logger := mockLogger.NewLogger(t)
-	prodUC := prodMock.NewProductUC(t)
+	t.Run("Keyword search is recorded", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/products?keyword=Tripod", nil)
+		require.NoError(t, err)
 
-	h := delivery.NewProdHandlers(logger, prodUC)
+		rr := httptest.NewRecorder()
+		searchID := uuid.New()
 
-	t.Run("Product retrieved successfully", func(t *testing.T) {
-		id := uuid.New()
+		prodUC.On("GetProducts", "Tripod", "", 0).Return(&models.GetProd{ProductCount: 0}, nil).Once()
+		prodUC.On("RecordSearch", "Tripod", 0).Return(searchID, nil).Once()
 
-		req, err := http.NewRequest("GET", "/product/"+id.String(), nil)
+		h.GetProducts(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"searchId":"`+searchID.String()+`"`)
+	})
+
+	t.Run("Following pages are not recorded", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/products?keyword=lens&page=2", nil)
 		require.NoError(t, err)
 
 		rr := httptest.NewRecorder()
 
-		// Create a chi router and set the URL param
-		rCtx := chi.NewRouteContext()
-		rCtx.URLParams.Add("id", id.String())
-		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+		prodUC.On("GetProducts", "lens", "", 2).Return(&models.GetProd{ProductCount: 9}, nil).Once()
 
-		prodUC.On("GetSingleProduct", id).Return(&models.Product{}, nil)
+		h.GetProducts(rr, req)
 
-		h.GetSingleProduct(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "searchId")
+	})
 
-		got := rr.Code
-		want := http.StatusOK
+	t.Run("Search is served when recording fails", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/products?keyword=lens", nil)
+		require.NoError(t, err)
 
-		assert.Equal(t, want, got)
+		rr := httptest.NewRecorder()
+
+		prodUC.On("GetProducts", "lens", "", 0).Return(&models.GetProd{ProductCount: 3}, nil).Once()
+		prodUC.On("RecordSearch", "lens", 3).Return(uuid.Nil, errors.New("db down")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.GetProducts(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "searchId")
+	})
+
+	t.Run("Currency not accepted", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/products", nil)
+		require.NoError(t, err)
+		req.Header.Set(exchange.Header, "XYZ")
+
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.GetProducts(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
 	})
 }
 
-func TestUpdateProduct(t *testing.T) {
+func TestRecordSearchClick(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	prodUC := prodMock.NewProductUC(t)
 
-	h := delivery.NewProdHandlers(logger, prodUC)
-
-	t.Run("Product updated successfully", func(t *testing.T) {
-		id := uuid.New()
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
 
-		formData := url.Values{
-			"name":        {"test"},
-			"price":       {"100"},
-			"description": {"test"},
-			"category":    {"test"},
-			"stock":       {"100"},
-			"seller":      {"test"},
-			"images":      {"something1.jpg", "something2.jpg"},
-		}
+	searchID, productID := uuid.New(), uuid.New()
+	newRequest := func(body string) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/search/click", strings.NewReader(body))
+	}
+	body := `{"searchId": "` + searchID.String() + `", "productId": "` + productID.String() + `"}`
 
-		payload, ct, _ := utils.CreateMultipartForm(formData)
+	t.Run("Click is recorded", func(t *testing.T) {
+		rr := httptest.NewRecorder()
 
-		req, err := http.NewRequest("PUT", "/product/id", payload)
-		require.NoError(t, err)
+		prodUC.On("RecordSearchClick", searchID, productID).Return(nil).Once()
 
-		rr := httptest.NewRecorder()
-		req.Header.Set("Content-Type", ct)
+		h.RecordSearchClick(rr, newRequest(body))
 
-		// Create a chi router and set the URL param
-		rCtx := chi.NewRouteContext()
-		rCtx.URLParams.Add("id", id.String())
-		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
 
-		err = req.ParseMultipartForm(100000)
-		require.NoError(t, err)
+	t.Run("Unknown search", func(t *testing.T) {
+		rr := httptest.NewRecorder()
 
-		multipartForm := req.MultipartForm
-		images := multipartForm.File["images"]
-		img, _ := utils.ExtractImages(images)
-		price, _ := strconv.ParseFloat(formData.Get("price"), 64)
-		stock, _ := strconv.Atoi(formData.Get("stock"))
+		prodUC.On("RecordSearchClick", searchID, productID).Return(products.ErrSearchNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
 
-		user := models.User{
-			ID: uuid.New(),
-		}
+		h.RecordSearchClick(rr, newRequest(body))
 
-		// mock session
-		ctx := context.WithValue(req.Context(), UserContextKey, &user)
-		req = req.WithContext(ctx)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
 
-		prodUC.On("UpdateProduct", id, models.Product{
-			Name:        formData.Get("name"),
-			Price:       price,
-			Description: formData.Get("description"),
-			Category:    formData.Get("category"),
-			Stock:       stock,
-			Seller:      formData.Get("seller"),
-			UserId:      user.ID,
-		}, img).Return(&models.ProdResponse{}, nil)
+	t.Run("Invalid ids", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
 
-		h.UpdateProduct(rr, req)
+		rr := httptest.NewRecorder()
 
-		got := rr.Code
-		want := http.StatusOK
+		h.RecordSearchClick(rr, newRequest(`{"searchId": "1", "productId": ""}`))
 
-		assert.Equal(t, want, got)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "searchId")
+		assert.Contains(t, rr.Body.String(), "productId")
 	})
 }
 
-func TestDeleteProduct(t *testing.T) {
+func TestGetZeroResultSearches(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	prodUC := prodMock.NewProductUC(t)
 
-	h := delivery.NewProdHandlers(logger, prodUC)
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
 
-	t.Run("Product deleted successfully", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodDelete, "/product/id", nil)
-		require.NoError(t, err)
+	t.Run("Top zero result searches", func(t *testing.T) {
+		since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		req := httptest.NewRequest(http.MethodGet, "/admin/search/zero-results?since=2026-10-01T00:00:00Z&limit=5", nil)
+		rr := httptest.NewRecorder()
+
+		prodUC.On("GetZeroResultSearches", since, 5).Return([]models.SearchTerm{
+			{Keyword: "tripod", Searches: 12, LastSearchedAt: since},
+		}, nil).Once()
+
+		h.GetZeroResultSearches(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"keyword":"tripod"`)
+		assert.Contains(t, rr.Body.String(), `"searches":12`)
+	})
 
+	t.Run("No searches", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/search/zero-results", nil)
 		rr := httptest.NewRecorder()
 
-		id := uuid.New()
+		prodUC.On("GetZeroResultSearches", time.Time{}, 0).Return(nil, nil).Once()
 
-		// Create a chi router and set the URL param
-		rCtx := chi.NewRouteContext()
-		rCtx.URLParams.Add("id", id.String())
-		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+		h.GetZeroResultSearches(rr, req)
 
-		prodUC.On("DeleteProduct", id).Return(nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"searches":[]`)
+	})
 
-		h.DeleteProduct(rr, req)
+	t.Run("Invalid limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/search/zero-results?limit=-1", nil)
+		rr := httptest.NewRecorder()
 
-		got := rr.Code
-		want := http.StatusOK
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
 
-		assert.Equal(t, want, got)
+		h.GetZeroResultSearches(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
 	})
 }
 
-func TestCreateProductReview(t *testing.T) {
+func TestSynonyms(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	prodUC := prodMock.NewProductUC(t)
 
-	h := delivery.NewProdHandlers(logger, prodUC)
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+	id := uuid.New()
 
-	t.Run("Product review created successfully", func(t *testing.T) {
+	withID := func(req *http.Request) *http.Request {
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+	}
 
-		formData := url.Values{
-			"rating":    {"5"},
-			"comment":   {"test"},
-			"productId": {"test"},
-		}
+	t.Run("Synonyms listed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/synonyms", nil)
+		rr := httptest.NewRecorder()
 
-		payload, ct, _ := utils.CreateMultipartForm(formData)
+		prodUC.On("GetSynonyms").Return([]models.SynonymSet{{ID: id, Terms: []string{"sneakers", "trainers"}}}, nil).Once()
 
-		req, err := http.NewRequest(http.MethodPost, "/product/id/review", payload)
-		require.NoError(t, err)
+		h.GetSynonyms(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"trainers"`)
+	})
 
+	t.Run("Synonyms created", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/synonyms", strings.NewReader(`{"terms": ["Sneakers", "trainers"]}`))
 		rr := httptest.NewRecorder()
-		req.Header.Set("Content-Type", ct)
 
-		_ = req.ParseMultipartForm(100000)
-		rating, _ := strconv.Atoi(req.Form.Get("rating"))
-		comment := req.Form.Get("comment")
-		productId := req.Form.Get("productId")
-		userId := uuid.New()
+		prodUC.On("CreateSynonyms", models.SynonymSet{Terms: []string{"Sneakers", "trainers"}}).
+			Return(&models.SynonymSet{ID: id, Terms: []string{"sneakers", "trainers"}}, nil).Once()
 
-		parsedProdId, _ := uuid.Parse(productId)
-		review := models.Reviews{
-			Rating:    rating,
-			Comment:   comment,
-			UserId:    userId,
-			ProductId: parsedProdId,
-		}
+		h.CreateSynonyms(rr, req)
 
-		user := models.User{
-			ID: userId,
-		}
+		require.Equal(t, http.StatusCreated, rr.Code)
+		assert.Contains(t, rr.Body.String(), id.String())
+	})
 
-		// mock session
-		ctx := context.WithValue(req.Context(), UserContextKey, &user)
-		req = req.WithContext(ctx)
+	t.Run("One term is not a set", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/synonyms", strings.NewReader(`{"terms": ["sneakers", " Sneakers "]}`))
+		rr := httptest.NewRecorder()
 
-		prodUC.On("CreateProductReview", review).Return(nil)
+		h.CreateSynonyms(rr, req)
 
-		h.CreateProductReview(rr, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
 
-		got := rr.Code
-		want := http.StatusOK
+	t.Run("Term in another set", func(t *testing.T) {
+		req := withID(httptest.NewRequest(http.MethodPut, "/admin/synonyms/"+id.String(), strings.NewReader(`{"terms": ["tv", "trainers"]}`)))
+		rr := httptest.NewRecorder()
 
-		assert.Equal(t, want, got)
+		prodUC.On("UpdateSynonyms", id, models.SynonymSet{Terms: []string{"tv", "trainers"}}).
+			Return(nil, products.ErrSynonymExists).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		h.UpdateSynonyms(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Synonyms deleted", func(t *testing.T) {
+		req := withID(httptest.NewRequest(http.MethodDelete, "/admin/synonyms/"+id.String(), nil))
+		rr := httptest.NewRecorder()
+
+		prodUC.On("DeleteSynonyms", id).Return(nil).Once()
+
+		h.DeleteSynonyms(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
 	})
 }
 
-func TestGetProductReviews(t *testing.T) {
+// newRates returns a converter at one euro for two dollars.
+func newRates() *exchange.Converter {
+	return exchange.New(exchange.NewStatic(map[string]float64{"EUR": 0.5}))
+}
+
+func TestGetAdminProducts(t *testing.T) {
 	logger := mockLogger.NewLogger(t)
 	prodUC := prodMock.NewProductUC(t)
 
-	h := delivery.NewProdHandlers(logger, prodUC)
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
 
-	t.Run("Product reviews fetched successfully", func(t *testing.T) {
-		id := uuid.New()
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	as := func(req *http.Request, user *models.User) *http.Request {
+		return req.WithContext(context.WithValue(req.Context(), UserContextKey, user))
+	}
 
-		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/product/reviews?id=%v", id), nil)
+	t.Run("Products retrieved successfully", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/admin/products", nil)
 		require.NoError(t, err)
 
 		rr := httptest.NewRecorder()
 
-		prodUC.On("GetProductReviews", id).Return([]models.Reviews{}, nil)
+		prodUC.On("GetAdminProducts", "", "", 0, 0, uuid.NullUUID{}).Return(&models.GetProd{Success: true}, nil).Once()
 
-		h.GetProductReviews(rr, req)
+		h.GetAdminProducts(rr, as(req, admin))
 
 		got := rr.Code
 		want := http.StatusOK
 
 		assert.Equal(t, want, got)
+
+		var res models.GetProd
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		assert.NotNil(t, res.Products)
 	})
-}
 
-func TestDeleteProductReview(t *testing.T) {
-	logger := mockLogger.NewLogger(t)
-	prodUC := prodMock.NewProductUC(t)
+	t.Run("Search, sort and page passed on", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/admin/products?keyword=lens&sort=-stock&page=2&limit=50", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
 
-	h := delivery.NewProdHandlers(logger, prodUC)
+		prodUC.On("GetAdminProducts", "lens", "-stock", 2, 50, uuid.NullUUID{}).
+			Return(&models.GetProd{Success: true, ProductCount: 1, Products: []models.Product{{Name: "Lens"}}}, nil).Once()
 
-	t.Run("Product review deleted successfully", func(t *testing.T) {
-		prodId := uuid.New()
-		rId := uuid.New()
-		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/product/reviews/?id=%s&productId=%s", rId, prodId), nil)
+		h.GetAdminProducts(rr, as(req, admin))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Unknown sort", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/admin/products?sort=name", nil)
 		require.NoError(t, err)
 
 		rr := httptest.NewRecorder()
 
-		productId := req.URL.Query().Get("productId")
-		reviewId := req.URL.Query().Get("id")
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
 
-		parsedProdId, _ := uuid.Parse(productId)
-		parsedReviewId, _ := uuid.Parse(reviewId)
+		h.GetAdminProducts(rr, as(req, admin))
 
-		prodUC.On("DeleteProductReview", parsedProdId, parsedReviewId).Return(nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
 
-		h.DeleteProductReview(rr, req)
+	t.Run("Invalid limit", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/admin/products?limit=0", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.GetAdminProducts(rr, as(req, admin))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Admin lists the products of a seller", func(t *testing.T) {
+		sellerID := uuid.New()
+		req, err := http.NewRequest("GET", "/admin/products?sellerId="+sellerID.String(), nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		prodUC.On("GetAdminProducts", "", "", 0, 0, uuid.NullUUID{UUID: sellerID, Valid: true}).
+			Return(&models.GetProd{Success: true}, nil).Once()
+
+		h.GetAdminProducts(rr, as(req, admin))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Sellers only list their own products", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/admin/products?sellerId="+uuid.NewString(), nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		seller := &models.User{ID: uuid.New(), Role: models.RoleSeller}
+
+		prodUC.On("GetAdminProducts", "", "", 0, 0, uuid.NullUUID{UUID: seller.ID, Valid: true}).
+			Return(&models.GetProd{Success: true}, nil).Once()
+
+		h.GetAdminProducts(rr, as(req, seller))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Invalid seller id", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/admin/products?sellerId=42", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.GetAdminProducts(rr, as(req, admin))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestGetSingleProduct(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	t.Run("Product retrieved successfully", func(t *testing.T) {
+		id := uuid.New()
+
+		req, err := http.NewRequest("GET", "/product/"+id.String(), nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		// Create a chi router and set the URL param
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+
+		prodUC.On("GetSingleProduct", id).Return(&models.Product{Status: models.ProductPublished}, nil)
+
+		h.GetSingleProduct(rr, req)
+
+		got := rr.Code
+		want := http.StatusOK
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Hidden product is not found", func(t *testing.T) {
+		id := uuid.New()
+
+		req, err := http.NewRequest("GET", "/product/"+id.String(), nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+
+		prodUC.On("GetSingleProduct", id).Return(&models.Product{Hidden: true}, nil)
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.GetSingleProduct(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Draft product is not found", func(t *testing.T) {
+		id := uuid.New()
+
+		req, err := http.NewRequest("GET", "/product/"+id.String(), nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+
+		prodUC.On("GetSingleProduct", id).Return(&models.Product{Status: models.ProductDraft}, nil)
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.GetSingleProduct(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Missing product is not found", func(t *testing.T) {
+		id := uuid.New()
+
+		req, err := http.NewRequest("GET", "/product/"+id.String(), nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+
+		prodUC.On("GetSingleProduct", id).Return(nil, products.ErrProductNotFound)
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.GetSingleProduct(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestUpdateProduct(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	t.Run("Product updated successfully", func(t *testing.T) {
+		id := uuid.New()
+
+		formData := url.Values{
+			"name":        {"test"},
+			"price":       {"100"},
+			"description": {"test"},
+			"category":    {"test"},
+			"stock":       {"100"},
+			"seller":      {"test"},
+			"images":      {"something1.jpg", "something2.jpg"},
+		}
+
+		payload, ct, _ := utils.CreateMultipartForm(formData)
+
+		req, err := http.NewRequest("PUT", "/product/id", payload)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		req.Header.Set("Content-Type", ct)
+
+		// Create a chi router and set the URL param
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+
+		err = req.ParseMultipartForm(100000)
+		require.NoError(t, err)
+
+		multipartForm := req.MultipartForm
+		images := multipartForm.File["images"]
+		img, _ := utils.ExtractImages(images)
+		price, _ := money.Parse(formData.Get("price"), money.DefaultCurrency)
+		stock, _ := strconv.Atoi(formData.Get("stock"))
+
+		user := models.User{
+			ID: uuid.New(),
+		}
+
+		// mock session
+		ctx := context.WithValue(req.Context(), UserContextKey, &user)
+		req = req.WithContext(ctx)
+
+		prodUC.On("UpdateProduct", mock.Anything, id, models.Product{
+			Name:        formData.Get("name"),
+			Price:       price,
+			Description: formData.Get("description"),
+			Category:    formData.Get("category"),
+			Stock:       stock,
+			Seller:      formData.Get("seller"),
+		}, img, &user).Return(&models.ProdResponse{}, nil)
+
+		h.UpdateProduct(rr, req)
+
+		got := rr.Code
+		want := http.StatusOK
+
+		assert.Equal(t, want, got)
+	})
+
+	scheduled := func(status string) *http.Request {
+		formData := url.Values{
+			"name":        {"test"},
+			"price":       {"100"},
+			"description": {"test"},
+			"category":    {"test"},
+			"seller":      {"test"},
+			"status":      {status},
+			"publishAt":   {"2026-11-01T09:00:00Z"},
+		}
+		payload, ct, _ := utils.CreateMultipartForm(formData)
+
+		req, err := http.NewRequest("PUT", "/product/id", payload)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", uuid.NewString())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+
+		return req.WithContext(context.WithValue(ctx, UserContextKey, &models.User{ID: uuid.New()}))
+	}
+
+	t.Run("Draft scheduled for publishing", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		publishAt := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+
+		prodUC.On("UpdateProduct", mock.Anything, mock.Anything, mock.MatchedBy(func(p models.Product) bool {
+			return p.Status == models.ProductDraft && p.PublishAt != nil && p.PublishAt.Equal(publishAt)
+		}), mock.Anything, mock.Anything).Return(&models.ProdResponse{}, nil).Once()
+
+		h.UpdateProduct(rr, scheduled(" Draft "))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Only drafts are scheduled", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.UpdateProduct(rr, scheduled(models.ProductPublished))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "publishAt")
+	})
+
+	t.Run("Product of another seller", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		prodUC.On("UpdateProduct", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, products.ErrNotProductOwner).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.UpdateProduct(rr, scheduled(models.ProductDraft))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestDeleteProduct(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
+	t.Run("Product deleted successfully", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, "/product/id", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		id := uuid.New()
+
+		// Create a chi router and set the URL param
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, admin))
+
+		prodUC.On("DeleteProduct", mock.Anything, id, admin).Return(nil)
+
+		h.DeleteProduct(rr, req)
 
 		got := rr.Code
 		want := http.StatusOK
 
 		assert.Equal(t, want, got)
 	})
+
+	t.Run("Product of another seller", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, "/product/id", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		id := uuid.New()
+		seller := &models.User{ID: uuid.New(), Role: models.RoleSeller}
+
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, seller))
+
+		prodUC.On("DeleteProduct", mock.Anything, id, seller).Return(products.ErrNotProductOwner).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.DeleteProduct(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestCreateProductReview(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	t.Run("Product review created successfully", func(t *testing.T) {
+
+		formData := url.Values{
+			"rating":    {"5"},
+			"comment":   {"test"},
+			"productId": {uuid.New().String()},
+		}
+
+		payload, ct, _ := utils.CreateMultipartForm(formData)
+
+		req, err := http.NewRequest(http.MethodPost, "/product/id/review", payload)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		req.Header.Set("Content-Type", ct)
+
+		_ = req.ParseMultipartForm(100000)
+		rating, _ := strconv.Atoi(req.Form.Get("rating"))
+		comment := req.Form.Get("comment")
+		productId := req.Form.Get("productId")
+		userId := uuid.New()
+
+		parsedProdId, _ := uuid.Parse(productId)
+		review := models.Reviews{
+			Rating:    rating,
+			Comment:   comment,
+			UserId:    userId,
+			ProductId: parsedProdId,
+		}
+
+		user := models.User{
+			ID: userId,
+		}
+
+		// mock session
+		ctx := context.WithValue(req.Context(), UserContextKey, &user)
+		req = req.WithContext(ctx)
+
+		prodUC.On("CreateProductReview", mock.Anything, review, []*multipart.FileHeader(nil)).Return(nil)
+
+		h.CreateProductReview(rr, req)
+
+		got := rr.Code
+		want := http.StatusOK
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Rating out of range", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		formData := url.Values{
+			"rating":    {"6"},
+			"comment":   {"test"},
+			"productId": {uuid.New().String()},
+		}
+
+		payload, ct, _ := utils.CreateMultipartForm(formData)
+
+		req, err := http.NewRequest(http.MethodPost, "/product/id/review", payload)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+
+		ctx := context.WithValue(req.Context(), UserContextKey, &models.User{ID: uuid.New()})
+		req = req.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		h.CreateProductReview(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "rating must be at most 5")
+	})
+
+	t.Run("Too many images", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		payload, ct, _ := utils.CreateMultipartForm(url.Values{
+			"rating":    {"4"},
+			"productId": {uuid.New().String()},
+		})
+
+		req, err := http.NewRequest(http.MethodPost, "/product/review", payload)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, &models.User{ID: uuid.New()}))
+
+		prodUC.On("CreateProductReview", mock.Anything, mock.Anything, mock.Anything).Return(&products.ImageError{
+			Field:  "images",
+			Code:   validator.CodeTooMany,
+			Reason: "a review must not have more than 3 images",
+		}).Once()
+
+		rr := httptest.NewRecorder()
+		h.CreateProductReview(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), validator.CodeTooMany)
+	})
+}
+
+func TestGetProductReviews(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	t.Run("Product reviews fetched successfully", func(t *testing.T) {
+		id := uuid.New()
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/product/reviews?id=%v", id), nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		prodUC.On("GetProductReviews", id, "").Return([]models.Reviews{{
+			ReviewsId:        uuid.New(),
+			Rating:           5,
+			Images:           []models.ReviewImage{{PublicId: "reviews/a", Url: "https://img/a.png"}},
+			VerifiedPurchase: true,
+		}}, nil)
+
+		h.GetProductReviews(rr, req)
+
+		got := rr.Code
+		want := http.StatusOK
+
+		assert.Equal(t, want, got)
+
+		var body struct {
+			Reviews []models.Reviews `json:"reviews"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		require.Len(t, body.Reviews, 1)
+		assert.True(t, body.Reviews[0].VerifiedPurchase)
+		assert.Equal(t, "https://img/a.png", body.Reviews[0].Images[0].Url)
+	})
+
+	t.Run("Most helpful first", func(t *testing.T) {
+		id := uuid.New()
+		prodUC.On("GetProductReviews", id, models.ReviewsHelpful).Return([]models.Reviews{}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.GetProductReviews(rr, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/product/reviews?id=%v&sort=helpful", id), nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Unknown sort", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.GetProductReviews(rr, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/product/reviews?id=%v&sort=rating", uuid.New()), nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestVoteReview(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	reviewId, user := uuid.New(), &models.User{ID: uuid.New()}
+
+	vote := func(method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/reviews/"+reviewId.String()+"/vote", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", reviewId.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		req = req.WithContext(context.WithValue(ctx, UserContextKey, user))
+
+		rr := httptest.NewRecorder()
+		if method == http.MethodDelete {
+			h.WithdrawReviewVote(rr, req)
+		} else {
+			h.VoteReview(rr, req)
+		}
+		return rr
+	}
+
+	t.Run("Vote is recorded", func(t *testing.T) {
+		prodUC.On("VoteReview", reviewId, user.ID, false).Return(models.ReviewVotes{Helpful: 1, Unhelpful: 1}, nil).Once()
+
+		rr := vote(http.MethodPut, `{"helpful": false}`)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var body struct {
+			Helpful   int `json:"helpful"`
+			Unhelpful int `json:"unhelpful"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, 1, body.Unhelpful)
+	})
+
+	t.Run("Helpful is required", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := vote(http.MethodPut, `{}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Review not found", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+		prodUC.On("VoteReview", reviewId, user.ID, true).Return(models.ReviewVotes{}, products.ErrReviewNotFound).Once()
+
+		rr := vote(http.MethodPut, `{"helpful": true}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Vote is withdrawn", func(t *testing.T) {
+		prodUC.On("WithdrawReviewVote", reviewId, user.ID).Return(models.ReviewVotes{Helpful: 4}, nil).Once()
+
+		rr := vote(http.MethodDelete, "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestDeleteProductReview(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	t.Run("Product review deleted successfully", func(t *testing.T) {
+		prodId := uuid.New()
+		rId := uuid.New()
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/product/reviews/?id=%s&productId=%s", rId, prodId), nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		productId := req.URL.Query().Get("productId")
+		reviewId := req.URL.Query().Get("id")
+
+		parsedProdId, _ := uuid.Parse(productId)
+		parsedReviewId, _ := uuid.Parse(reviewId)
+
+		prodUC.On("DeleteProductReview", parsedProdId, parsedReviewId).Return(nil)
+
+		h.DeleteProductReview(rr, req)
+
+		got := rr.Code
+		want := http.StatusOK
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Review not found", func(t *testing.T) {
+		prodId, rId := uuid.New(), uuid.New()
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/product/reviews/?id=%s&productId=%s", rId, prodId), nil)
+		require.NoError(t, err)
+
+		prodUC.On("DeleteProductReview", prodId, rId).Return(products.ErrReviewNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.DeleteProductReview(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestValidateProduct(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	newRequest := func(t *testing.T, formData url.Values) *http.Request {
+		payload, ct, err := utils.CreateMultipartForm(formData)
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, "/admin/validate", payload)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ct)
+		return req
+	}
+
+	t.Run("Report is returned", func(t *testing.T) {
+		id := uuid.New()
+		rr := httptest.NewRecorder()
+		prodUC.On("ValidateProduct", models.Product{ProductId: id, Name: "test", SKU: "SKU-1", Price: money.Of(10000), Category: "Home"}, mock.Anything).
+			Return(&models.ProductValidationReport{Valid: false, Errors: map[string]string{"seller": "product seller must be provided"}}, nil).Once()
+
+		h.ValidateProduct(rr, newRequest(t, url.Values{
+			"id":       {id.String()},
+			"name":     {"test"},
+			"sku":      {"SKU-1"},
+			"price":    {"100"},
+			"category": {"Home"},
+		}))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var report models.ProductValidationReport
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		assert.False(t, report.Valid)
+		assert.Contains(t, report.Errors, "seller")
+	})
+
+	t.Run("Invalid product id", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.ValidateProduct(rr, newRequest(t, url.Values{"id": {"not-a-uuid"}}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unreadable price and stock", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.ValidateProduct(rr, newRequest(t, url.Values{"name": {"test"}, "price": {"cheap"}, "stock": {"lots"}}))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), "price must be an amount")
+		assert.Contains(t, rr.Body.String(), "stock must be a whole number")
+	})
+}
+
+func TestImportProducts(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	user := models.User{ID: uuid.New()}
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/admin/import", strings.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), UserContextKey, &user))
+	}
+
+	t.Run("Report is returned", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		prodUC.On("ImportProducts", mock.Anything, mock.Anything, user.ID).Return(&models.ProductImportReport{
+			Created: 2,
+			Failed:  1,
+			Errors:  []models.ProductImportError{{Line: 3, Errors: map[string]string{"price": "product price must be a number"}}},
+		}, nil).Once()
+
+		h.ImportProducts(rr, newRequest("name,price\n"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Success bool `json:"success"`
+			models.ProductImportReport
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		assert.True(t, res.Success)
+		assert.Equal(t, 2, res.Created)
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, 3, res.Errors[0].Line)
+	})
+
+	t.Run("Invalid CSV", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		prodUC.On("ImportProducts", mock.Anything, mock.Anything, user.ID).Return(nil, fmt.Errorf("%w: missing column", products.ErrInvalidCSV)).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.ImportProducts(rr, newRequest("name\n"))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Not logged in", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.ImportProducts(rr, httptest.NewRequest(http.MethodPost, "/admin/import", strings.NewReader("")))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestSyncSheet(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	user := models.User{ID: uuid.New()}
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/admin/import/sheet", nil)
+		return req.WithContext(context.WithValue(req.Context(), UserContextKey, &user))
+	}
+
+	t.Run("Report is returned", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		prodUC.On("SyncSheet", mock.Anything, user.ID).Return(&models.ProductImportReport{Created: 2, Updated: 1}, nil).Once()
+
+		h.SyncSheet(rr, newRequest())
+
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			models.ProductImportReport
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		assert.Equal(t, 2, res.Created)
+		assert.Equal(t, 1, res.Updated)
+	})
+
+	t.Run("Sheet not shared", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		prodUC.On("SyncSheet", mock.Anything, user.ID).Return(nil, fmt.Errorf("error fetching sheet: %w", sheets.ErrNotShared)).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.SyncSheet(rr, newRequest())
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Sheet unreachable", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		prodUC.On("SyncSheet", mock.Anything, user.ID).Return(nil, errors.New("error fetching sheet: timeout")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+		h.SyncSheet(rr, newRequest())
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestExportProducts(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	t.Run("CSV is sent", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		prodUC.On("ExportProducts", mock.Anything).Run(func(args mock.Arguments) {
+			_, _ = args.Get(0).(io.Writer).Write([]byte("id,sku,name\n"))
+		}).Return(nil).Once()
+
+		h.ExportProducts(rr, httptest.NewRequest(http.MethodGet, "/admin/export", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "products.csv")
+		assert.Equal(t, "id,sku,name\n", rr.Body.String())
+	})
+
+	t.Run("Error before any output", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		prodUC.On("ExportProducts", mock.Anything).Return(errors.New("db error")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+		h.ExportProducts(rr, httptest.NewRequest(http.MethodGet, "/admin/export", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestGetLowStock(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+
+	t.Run("Products running out are listed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/low-stock?limit=10", nil)
+		rr := httptest.NewRecorder()
+
+		prodUC.On("GetLowStock", 10).Return([]models.LowStockItem{
+			{ProductID: uuid.New(), Name: "Kente Scarf", Stock: 2, Threshold: 5},
+		}, nil).Once()
+
+		h.GetLowStock(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"name":"Kente Scarf"`)
+		assert.Contains(t, rr.Body.String(), `"threshold":5`)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/low-stock?limit=-1", nil)
+		rr := httptest.NewRecorder()
+
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		h.GetLowStock(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestBulkUpdateProducts(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+	id := uuid.New()
+
+	call := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/admin/products/bulk", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		h.BulkUpdateProducts(rr, req)
+		return rr
+	}
+
+	t.Run("Updates applied and reported", func(t *testing.T) {
+		price, stock := money.Of(4999), 3
+		updates := []models.ProductUpdate{{ProductId: id, Price: &price}, {ProductId: id, Stock: &stock}}
+		report := &models.BulkUpdateReport{Updated: 1, Failed: 1, Errors: []models.BulkUpdateError{
+			{Index: 1, ProductId: id, Errors: map[string]string{"productId": "product is updated more than once"}},
+		}}
+		prodUC.On("BulkUpdateProducts", updates).Return(report, nil).Once()
+
+		rr := call(`{"updates": [{"productId": "` + id.String() + `", "price": "49.99"}, {"productId": "` + id.String() + `", "stock": 3}]}`)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Success bool `json:"success"`
+			models.BulkUpdateReport
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		assert.True(t, res.Success)
+		assert.Equal(t, *report, res.BulkUpdateReport)
+	})
+
+	t.Run("Updates are required", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, call(`{"updates": []}`).Code)
+	})
+
+	t.Run("Invalid price", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call(`{"updates": [{"productId": "`+id.String()+`", "price": "cheap"}]}`).Code)
+	})
+
+	t.Run("Product deleted while updating", func(t *testing.T) {
+		prodUC.On("BulkUpdateProducts", mock.Anything).Return(nil, products.ErrProductNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call(`{"updates": [{"productId": "`+id.String()+`", "stock": 0}]}`).Code)
+	})
+}
+
+func TestSetLowStockThreshold(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+	id := uuid.New()
+
+	call := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/admin/product/"+id.String()+"/low-stock", strings.NewReader(body))
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		rr := httptest.NewRecorder()
+		h.SetLowStockThreshold(rr, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)))
+		return rr
+	}
+
+	t.Run("Threshold is set", func(t *testing.T) {
+		prodUC.On("SetLowStockThreshold", id, 0).Return(nil).Once()
+
+		assert.Equal(t, http.StatusOK, call(`{"threshold": 0}`).Code)
+	})
+
+	t.Run("Threshold is required", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, call(`{}`).Code)
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		prodUC.On("SetLowStockThreshold", id, 8).Return(products.ErrProductNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call(`{"threshold": 8}`).Code)
+	})
+}
+
+func TestAddImages(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+	id := uuid.New()
+	user := &models.User{ID: uuid.New(), Role: models.RoleSeller}
+
+	call := func() *httptest.ResponseRecorder {
+		payload, ct, err := utils.CreateMultipartForm(url.Values{})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/product/"+id.String()+"/images", payload)
+		req.Header.Set("Content-Type", ct)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+		rr := httptest.NewRecorder()
+		h.AddImages(rr, req.WithContext(context.WithValue(ctx, delivery.UserContextKey, user)))
+		return rr
+	}
+
+	t.Run("Images are added", func(t *testing.T) {
+		prodUC.On("AddImages", mock.Anything, id, mock.Anything, user).Return([]models.Images{
+			{PublicId: "products/new", Url: "https://img/new.png", ProductId: id},
+		}, nil).Once()
+
+		rr := call()
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "products/new")
+	})
+
+	t.Run("Image is rejected", func(t *testing.T) {
+		prodUC.On("AddImages", mock.Anything, id, mock.Anything, user).Return(nil, &products.ImageError{
+			Field: "images", Code: "ERR_REQUIRED", Reason: "at least one product image must be provided",
+		}).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := call()
+
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"images":"ERR_REQUIRED"`)
+	})
+
+	t.Run("Storage refuses the image", func(t *testing.T) {
+		prodUC.On("AddImages", mock.Anything, id, mock.Anything, user).Return(nil, &cloudinary.OperationError{
+			Op: "upload", Attempts: 1, Err: errors.New("Invalid image file"),
+		}).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call().Code)
+	})
+
+	t.Run("Storage is unavailable", func(t *testing.T) {
+		prodUC.On("AddImages", mock.Anything, id, mock.Anything, user).Return(nil, &cloudinary.OperationError{
+			Op: "upload", Attempts: 3, Err: errors.New("connection reset"), Transient: true,
+		}).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusServiceUnavailable, call().Code)
+	})
+
+	t.Run("Product of another seller", func(t *testing.T) {
+		prodUC.On("AddImages", mock.Anything, id, mock.Anything, user).Return(nil, products.ErrNotProductOwner).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusForbidden, call().Code)
+	})
+}
+
+func TestAddImagesFromUploads(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+	id := uuid.New()
+	user := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
+	call := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/product/"+id.String()+"/images", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+		rr := httptest.NewRecorder()
+		h.AddImages(rr, req.WithContext(context.WithValue(ctx, delivery.UserContextKey, user)))
+		return rr
+	}
+
+	t.Run("Uploads are attached", func(t *testing.T) {
+		prodUC.On("AttachImages", id, []string{"products/new"}, user).Return([]models.Images{
+			{PublicId: "products/new", Url: "https://img/new.png", ProductId: id},
+		}, nil).Once()
+
+		rr := call(`{"publicIds": ["products/new"]}`)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "products/new")
+	})
+
+	t.Run("Public ids are required", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, call(`{"publicIds": []}`).Code)
+	})
+
+	t.Run("Upload expired or already used", func(t *testing.T) {
+		prodUC.On("AttachImages", id, []string{"products/used"}, user).Return(nil, products.ErrUploadNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call(`{"publicIds": ["products/used"]}`).Code)
+	})
+}
+
+func TestDeleteImage(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+	id := uuid.New()
+	user := &models.User{ID: uuid.New(), Role: models.RoleSeller}
+
+	call := func(publicId string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete,
+			"/admin/product/"+id.String()+"/images?publicId="+url.QueryEscape(publicId), nil)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+		rr := httptest.NewRecorder()
+		h.DeleteImage(rr, req.WithContext(context.WithValue(ctx, delivery.UserContextKey, user)))
+		return rr
+	}
+
+	t.Run("Image is deleted", func(t *testing.T) {
+		prodUC.On("DeleteImage", mock.Anything, id, "products/old", user).Return([]models.Images{}, nil).Once()
+
+		rr := call("products/old")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"images":[]`)
+	})
+
+	t.Run("Public id is required", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("").Code)
+	})
+
+	t.Run("Image not found", func(t *testing.T) {
+		prodUC.On("DeleteImage", mock.Anything, id, "products/other", user).Return(nil, products.ErrImageNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusNotFound, call("products/other").Code)
+	})
+
+	t.Run("Product of another seller", func(t *testing.T) {
+		prodUC.On("DeleteImage", mock.Anything, id, "products/old", user).Return(nil, products.ErrNotProductOwner).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusForbidden, call("products/old").Code)
+	})
+}
+
+func TestRecordView(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+	id := uuid.New()
+
+	call := func(req *http.Request) *httptest.ResponseRecorder {
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		rr := httptest.NewRecorder()
+		featureflag.New(&config.Config{}).Middleware(http.HandlerFunc(h.RecordView)).
+			ServeHTTP(rr, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)))
+		return rr
+	}
+
+	t.Run("Anonymous view is recorded under the cohort cookie", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/"+id.String()+"/view", nil)
+		req.AddCookie(&http.Cookie{Name: featureflag.CookieName, Value: "visitor"})
+
+		prodUC.On("RecordView", id, models.Viewer{Session: "visitor"}).Return(nil).Once()
+
+		assert.Equal(t, http.StatusOK, call(req).Code)
+	})
+
+	t.Run("View is recorded under the signed in user", func(t *testing.T) {
+		user := &models.User{ID: uuid.New()}
+		req := httptest.NewRequest(http.MethodPost, "/"+id.String()+"/view", nil)
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, user))
+
+		prodUC.On("RecordView", id, models.Viewer{UserID: uuid.NullUUID{UUID: user.ID, Valid: true}}).Return(nil).Once()
+
+		assert.Equal(t, http.StatusOK, call(req).Code)
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/"+id.String()+"/view", nil)
+
+		prodUC.On("RecordView", id, mock.Anything).Return(products.ErrProductNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call(req).Code)
+	})
+}
+
+func TestGetRecentlyViewed(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+	handler := featureflag.New(&config.Config{}).Middleware(http.HandlerFunc(h.GetRecentlyViewed))
+
+	t.Run("Views are listed in the currency of the header", func(t *testing.T) {
+		require.NoError(t, money.Accept("EUR"))
+		t.Cleanup(func() { money.Accept() })
+
+		req := httptest.NewRequest(http.MethodGet, "/recently-viewed?limit=3", nil)
+		req.AddCookie(&http.Cookie{Name: featureflag.CookieName, Value: "visitor"})
+		req.Header.Set(exchange.Header, "EUR")
+		rr := httptest.NewRecorder()
+
+		prodUC.On("GetRecentlyViewed", models.Viewer{Session: "visitor"}, 3).Return([]models.Recommendation{
+			{ProductId: uuid.New(), Name: "Tripod", Price: money.Of(1000)},
+		}, nil).Once()
+
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var res struct {
+			Products []models.Recommendation `json:"products"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		require.Len(t, res.Products, 1)
+		assert.Equal(t, money.New(500, "EUR"), res.Products[0].Price)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/recently-viewed?limit=0", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestGetRelatedProducts(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	prodUC := prodMock.NewProductUC(t)
+
+	h := delivery.NewProdHandlers(logger, prodUC, newRates(), config.Storefront{})
+	id := uuid.New()
+
+	call := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		rr := httptest.NewRecorder()
+		h.GetRelatedProducts(rr, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)))
+		return rr
+	}
+
+	t.Run("Related products are listed", func(t *testing.T) {
+		recs := []models.Recommendation{{ProductId: uuid.New(), Name: "Tripod", Price: money.Of(1000), Ratings: 4}}
+		prodUC.On("GetRelatedProducts", id, 0).Return(recs, nil).Once()
+
+		rr := call("/" + id.String() + "/related")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var res struct {
+			Success  bool                    `json:"success"`
+			Products []models.Recommendation `json:"products"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.True(t, res.Success)
+		assert.Equal(t, recs, res.Products)
+	})
+
+	t.Run("Cached products are not converted in place", func(t *testing.T) {
+		require.NoError(t, money.Accept("EUR"))
+		t.Cleanup(func() { money.Accept() })
+
+		recs := []models.Recommendation{{ProductId: uuid.New(), Price: money.Of(1000)}}
+		prodUC.On("GetRelatedProducts", id, 0).Return(recs, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/"+id.String()+"/related", nil)
+		req.Header.Set(exchange.Header, "EUR")
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		rr := httptest.NewRecorder()
+		h.GetRelatedProducts(rr, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"currency":"EUR"`)
+		assert.Equal(t, money.Of(1000), recs[0].Price)
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		prodUC.On("GetRelatedProducts", id, 4).Return(nil, products.ErrProductNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("/"+id.String()+"/related?limit=4").Code)
+	})
+}
+
+func TestStockVisibility(t *testing.T) {
+	id := uuid.New()
+	prod := &models.Product{
+		ProductId:         id,
+		Status:            models.ProductPublished,
+		Stock:             3,
+		LowStockThreshold: 5,
+		Variants:          []models.Variant{{Stock: 0}, {Stock: 9}},
+	}
+
+	get := func(storefront config.Storefront) map[string]interface{} {
+		prodUC := prodMock.NewProductUC(t)
+		h := delivery.NewProdHandlers(mockLogger.NewLogger(t), prodUC, newRates(), storefront)
+		prodUC.On("GetSingleProduct", id).Return(prod, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/product/"+id.String(), nil)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("id", id.String())
+		rr := httptest.NewRecorder()
+		h.GetSingleProduct(rr, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)))
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Product map[string]interface{} `json:"product"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		return res.Product
+	}
+
+	t.Run("Stock is shown with its flags", func(t *testing.T) {
+		p := get(config.Storefront{})
+
+		assert.Equal(t, float64(3), p["stock"])
+		assert.Equal(t, true, p["inStock"])
+		assert.Equal(t, true, p["lowStock"])
+		assert.NotContains(t, p, "lowStockThreshold")
+	})
+
+	t.Run("Hidden stock leaves only its flags", func(t *testing.T) {
+		p := get(config.Storefront{HideStock: true})
+
+		assert.NotContains(t, p, "stock")
+		assert.Equal(t, true, p["inStock"])
+		assert.Equal(t, true, p["lowStock"])
+
+		variants := p["variants"].([]interface{})
+		require.Len(t, variants, 2)
+		assert.NotContains(t, variants[0], "stock")
+		assert.Equal(t, false, variants[0].(map[string]interface{})["inStock"])
+		assert.Equal(t, true, variants[1].(map[string]interface{})["inStock"])
+		assert.Equal(t, false, variants[1].(map[string]interface{})["lowStock"])
+	})
 }
@@ -0,0 +1,137 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+type imagesResponse struct {
+	Success bool            `json:"success"`
+	Images  []models.Images `json:"images"`
+}
+
+// AddImages appends images to those of a product, keeping the others (admin, or the
+// seller who owns it).
+// Endpoint: POST /api/v1/product/admin/product/{id}/images
+// Expects form data: images, one or more jpeg, png, gif or webp files. A JSON body of
+// {"publicIds": [...]} instead attaches images uploaded with POST /api/v1/uploads/presign.
+func (h *ProdHandlers) AddImages(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		h.attachImages(w, r, id, user)
+		return
+	}
+
+	if err := utils.ParseMultipartForm(w, r); err != nil {
+		_ = utils.ReadError(w, r, err)
+		h.logger.Errorf("error parsing form: %v", err)
+		return
+	}
+
+	images, err := h.prodUC.AddImages(r.Context(), id, formImages(r), user)
+	if err != nil {
+		h.writeImageError(w, r, "error adding images", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, imagesResponse{Success: true, Images: images})
+}
+
+// attachImages is AddImages for images uploaded directly to storage.
+func (h *ProdHandlers) attachImages(w http.ResponseWriter, r *http.Request, id uuid.UUID, user *models.User) {
+	var req attachImagesRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	images, err := h.prodUC.AttachImages(id, req.PublicIds, user)
+	if err != nil {
+		h.writeImageError(w, r, "error attaching images", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, imagesResponse{Success: true, Images: images})
+}
+
+// DeleteImage deletes an image of a product, keeping the others (admin, or the seller
+// who owns it).
+// Endpoint: DELETE /api/v1/product/admin/product/{id}/images?publicId=<string>
+// publicId is the public id of the image, as listed with the product.
+func (h *ProdHandlers) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	publicId := r.URL.Query().Get("publicId")
+	if publicId == "" {
+		_ = utils.BadRequest(w, r, errors.New("publicId must be provided"))
+		h.logger.Errorf("error deleting image: %v", "publicId is empty")
+		return
+	}
+
+	images, err := h.prodUC.DeleteImage(r.Context(), id, publicId, user)
+	if err != nil {
+		h.writeImageError(w, r, "error deleting image", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, imagesResponse{Success: true, Images: images})
+}
+
+func (h *ProdHandlers) writeImageError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	var imageErr *products.ImageError
+	switch {
+	case errors.As(err, &imageErr):
+		v := validator.New()
+		v.AddErrorCode(imageErr.Field, imageErr.Code, imageErr.Reason)
+		utils.FailedValidation(w, r, v)
+		h.logger.Errorf("%s: %v", msg, err)
+	case errors.Is(err, products.ErrNotProductOwner):
+		_ = utils.Forbidden(w, r)
+		h.logger.Errorf("%s: %v", msg, err)
+	case errors.Is(err, products.ErrProductNotFound) || errors.Is(err, products.ErrImageNotFound):
+		_ = utils.NotFound(w, r, err)
+		h.logger.Errorf("%s: %v", msg, err)
+	case errors.Is(err, products.ErrUploadNotFound) || errors.Is(err, cloudinary.ErrRejected):
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("%s: %v", msg, err)
+	case errors.Is(err, cloudinary.ErrUnavailable):
+		_ = utils.ServiceUnavailable(w, r, errors.New("image storage is temporarily unavailable, try again later"))
+		h.logger.Errorf("%s: %v", msg, err)
+	default:
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("%s: %w", msg, err))
+	}
+}
@@ -0,0 +1,199 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// productRequest is the form of CreateProduct and UpdateProduct. The category may be
+// given by name or by id; the price is in currency, the shop currency when it is
+// absent. Validate normalizes the currency and parses the price. A product is
+// created published when its status is absent, and keeps its status on an update;
+// only a draft may be scheduled with publishAt.
+type productRequest struct {
+	Name        string        `json:"name" validate:"required"`
+	SKU         string        `json:"sku"`
+	Price       string        `json:"price"`
+	Currency    string        `json:"currency"`
+	Description string        `json:"description" validate:"required"`
+	Ratings     int           `json:"ratings"`
+	Category    string        `json:"category"`
+	CategoryID  optionalID    `json:"categoryId"`
+	Seller      string        `json:"seller" validate:"required"`
+	Stock       int           `json:"stock"`
+	Variants    variantsField `json:"variants"`
+	Status      string        `json:"status"`
+	PublishAt   optionalTime  `json:"publishAt"`
+
+	price money.Money
+}
+
+func (req *productRequest) Validate(v *validator.Validator) {
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if req.Currency == "" {
+		req.Currency = money.DefaultCurrency
+	}
+
+	var err error
+	req.price, err = money.Parse(req.Price, req.Currency)
+
+	v.CheckCode(req.Category != "" || req.CategoryID.Valid, "category", validator.CodeRequired,
+		"category must be provided")
+	v.Check(err == nil, "price", "price must be an amount such as 49.99")
+	v.CheckCode(money.Supported(req.Currency), "currency", validator.CodeOneOf,
+		fmt.Sprintf("currency must be one of: %s", strings.Join(money.Currencies(), ", ")))
+
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	v.CheckCode(req.Status == "" || slices.Contains(models.ProductStatuses, req.Status), "status", validator.CodeOneOf,
+		fmt.Sprintf("status must be one of: %s", strings.Join(models.ProductStatuses, ", ")))
+	v.Check(req.PublishAt.Time == nil || req.Status == models.ProductDraft, "publishAt",
+		"publishAt may only be given to a draft")
+
+	p := req.product()
+	for key, msg := range p.VariantErrors() {
+		v.AddError(key, msg)
+	}
+}
+
+// product returns the product described by req, without its images and owner.
+func (req *productRequest) product() models.Product {
+	return models.Product{
+		Name:        req.Name,
+		SKU:         req.SKU,
+		Price:       req.price,
+		Description: req.Description,
+		Category:    req.Category,
+		CategoryId:  req.CategoryID.NullUUID,
+		Ratings:     req.Ratings,
+		Seller:      req.Seller,
+		Stock:       req.Stock,
+		Variants:    variantsIn(req.Variants.List, req.Currency),
+		Status:      req.Status,
+		PublishAt:   req.PublishAt.Time,
+	}
+}
+
+// optionalID is an id form field that may be left blank.
+type optionalID struct {
+	uuid.NullUUID
+}
+
+func (id *optionalID) UnmarshalText(text []byte) error {
+	raw := strings.TrimSpace(string(text))
+	if raw == "" {
+		id.NullUUID = uuid.NullUUID{}
+		return nil
+	}
+
+	return id.NullUUID.UnmarshalText([]byte(raw))
+}
+
+// optionalTime is a time form field, such as "2026-11-01T09:00:00Z", that may be left
+// blank.
+type optionalTime struct {
+	Time *time.Time
+}
+
+func (t *optionalTime) UnmarshalText(text []byte) error {
+	raw := strings.TrimSpace(string(text))
+	if raw == "" {
+		t.Time = nil
+		return nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return errors.New("publishAt must be a time such as 2026-11-01T09:00:00Z")
+	}
+	t.Time = &parsed
+
+	return nil
+}
+
+// variantsField is the variants form field, a JSON array of variants. List is nil
+// when the field is absent and empty when it is "[]" or blank, so an update can tell
+// leaving the variants as they are from removing them all.
+type variantsField struct {
+	List []models.Variant
+}
+
+func (f *variantsField) UnmarshalText(text []byte) error {
+	f.List = []models.Variant{}
+	if strings.TrimSpace(string(text)) == "" {
+		return nil
+	}
+
+	if err := json.Unmarshal(text, &f.List); err != nil {
+		f.List = nil
+		return errors.New("variants must be a JSON array of variants")
+	}
+
+	return nil
+}
+
+// searchClickRequest is the body of RecordSearchClick.
+type searchClickRequest struct {
+	SearchID  string `json:"searchId" validate:"required,uuid"`
+	ProductID string `json:"productId" validate:"required,uuid"`
+}
+
+// bulkUpdateRequest is the body of BulkUpdateProducts. The updates are checked one by
+// one by the usecase; the max is products.MaxBulkUpdates.
+type bulkUpdateRequest struct {
+	Updates []models.ProductUpdate `json:"updates" validate:"required,min=1,max=5000"`
+}
+
+// thresholdRequest is the body of SetLowStockThreshold. The threshold is a pointer so
+// that 0, alerting only once a product is sold out, is told from a missing one.
+type thresholdRequest struct {
+	Threshold *int `json:"threshold" validate:"required,min=0"`
+}
+
+// attachImagesRequest is the JSON body of AddImages, the public ids of presigned
+// uploads to attach.
+type attachImagesRequest struct {
+	PublicIds []string `json:"publicIds" validate:"required,min=1,max=10,dive,required"`
+}
+
+// reviewRequest is the form of CreateProductReview.
+type reviewRequest struct {
+	Rating    int       `json:"rating" validate:"min=1,max=5"`
+	Comment   string    `json:"comment"`
+	ProductID uuid.UUID `json:"productId" validate:"required"`
+}
+
+// voteRequest is the body of VoteReview.
+type voteRequest struct {
+	Helpful *bool `json:"helpful" validate:"required"`
+}
+
+// synonymsRequest is the body of CreateSynonyms and UpdateSynonyms. A set needs at
+// least two different terms once they are normalized as keywords are.
+type synonymsRequest struct {
+	Terms []string `json:"terms"`
+}
+
+func (req *synonymsRequest) Validate(v *validator.Validator) {
+	distinct := make(map[string]bool, len(req.Terms))
+	for _, t := range req.Terms {
+		t = strings.ToLower(strings.Join(strings.Fields(t), " "))
+		v.CheckCode(t != "", "terms", validator.CodeRequired, "terms must not be blank")
+		v.CheckCode(utf8.RuneCountInString(t) <= models.MaxSearchKeyword, "terms", validator.CodeTooLong,
+			fmt.Sprintf("terms must not be more than %d characters long", models.MaxSearchKeyword))
+		v.Check(!strings.Contains(t, ","), "terms", "terms must not contain commas")
+		distinct[t] = true
+	}
+	v.CheckCode(len(distinct) >= 2, "terms", validator.CodeTooFew, "at least two different terms must be provided")
+	v.CheckCode(len(req.Terms) <= models.MaxSynonymTerms, "terms", validator.CodeTooMany,
+		fmt.Sprintf("must not be more than %d terms", models.MaxSynonymTerms))
+}
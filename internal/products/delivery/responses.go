@@ -0,0 +1,76 @@
+package delivery
+
+import (
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// publicProduct is a product as shown to shoppers, with whether it and its variants
+// are in stock and low on stock. Their stock is left out when the shop hides it.
+type publicProduct struct {
+	models.Product
+	Stock    *int            `json:"stock,omitempty"`
+	InStock  bool            `json:"inStock"`
+	LowStock bool            `json:"lowStock"`
+	Variants []publicVariant `json:"variants,omitempty"`
+}
+
+// publicVariant is a variant of a publicProduct. It is low on stock at or under the
+// low-stock threshold of its product.
+type publicVariant struct {
+	models.Variant
+	Stock    *int `json:"stock,omitempty"`
+	InStock  bool `json:"inStock"`
+	LowStock bool `json:"lowStock"`
+}
+
+// productResponse is the response of GetSingleProduct.
+type productResponse struct {
+	Success bool          `json:"success"`
+	Product publicProduct `json:"product"`
+}
+
+// productsResponse is the response of GetProducts.
+type productsResponse struct {
+	*models.GetProd
+	Products []publicProduct `json:"products"`
+}
+
+// publicProduct returns p as shown to shoppers.
+func (h *ProdHandlers) publicProduct(p *models.Product) publicProduct {
+	pub := publicProduct{
+		Product:  *p,
+		Stock:    h.stock(p.Stock),
+		InStock:  p.InStock(),
+		LowStock: p.LowStock(),
+	}
+
+	for _, v := range p.Variants {
+		pub.Variants = append(pub.Variants, publicVariant{
+			Variant:  v,
+			Stock:    h.stock(v.Stock),
+			InStock:  v.Stock > 0,
+			LowStock: v.Stock > 0 && v.Stock <= p.LowStockThreshold,
+		})
+	}
+
+	return pub
+}
+
+// publicProducts returns res with its products as shown to shoppers.
+func (h *ProdHandlers) publicProducts(res *models.GetProd) productsResponse {
+	pub := productsResponse{GetProd: res, Products: make([]publicProduct, len(res.Products))}
+	for i := range res.Products {
+		pub.Products[i] = h.publicProduct(&res.Products[i])
+	}
+
+	return pub
+}
+
+// stock returns the stock to show shoppers, nil when the shop hides it.
+func (h *ProdHandlers) stock(n int) *int {
+	if h.hideStock {
+		return nil
+	}
+
+	return &n
+}
@@ -2,29 +2,64 @@ package delivery
 
 import (
 	"net/http"
+	"strings"
 
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/audit"
+	"github.com/jofosuware/go/shopit/pkg/etag"
+	"github.com/jofosuware/go/shopit/pkg/loadshed"
 	"github.com/jofosuware/go/shopit/pkg/utils"
 
 	"github.com/go-chi/chi/v5"
 )
 
+// canSell only lets through the users who manage products: admins, and sellers for
+// their own products.
+var canSell = utils.HasRole(models.RoleAdmin, models.RoleSeller)
+
 func (h *ProdHandlers) ProdRouter() http.Handler {
 	mux := chi.NewRouter()
 
-	mux.Get("/products", h.GetProducts)
-	mux.Get("/product/{id}", h.GetSingleProduct)
+	mux.With(loadshed.LowPriorityWhen(isSearch), etag.Middleware).Get("/products", h.GetProducts)
+	mux.With(etag.Middleware).Get("/product/{id}", h.GetSingleProduct)
+	mux.With(etag.Middleware).Get("/{id}/related", h.GetRelatedProducts)
+	mux.With(utils.MayAuthenticate).Post("/{id}/view", h.RecordView)
+	mux.With(utils.MayAuthenticate).Get("/recently-viewed", h.GetRecentlyViewed)
+	mux.With(loadshed.LowPriority).Post("/search/click", h.RecordSearchClick)
 
 	mux.Group(func(r chi.Router) {
 		r.Use(utils.IsAuthenticated)
 
-		r.Post("/new", h.CreateProduct)
-		r.Get("/admin/products", h.GetAdminProducts)
-		r.Put("/admin/product/{id}", h.UpdateProduct)
-		r.Delete("/admin/product/{id}", h.DeleteProduct)
+		r.With(canSell, utils.Uploads).Post("/new", h.CreateProduct)
+		r.With(canSell).Get("/admin/products", h.GetAdminProducts)
+		r.With(canSell).Put("/admin/product/{id}", h.UpdateProduct)
+		r.With(canSell).Delete("/admin/product/{id}", h.DeleteProduct)
+		r.With(canSell, utils.Uploads).Post("/admin/product/{id}/images", h.AddImages)
+		r.With(canSell).Delete("/admin/product/{id}/images", h.DeleteImage)
 		r.Put("/review", h.CreateProductReview)
 		r.Get("/reviews", h.GetProductReviews)
 		r.Delete("/reviews", h.DeleteProductReview)
+		r.Put("/reviews/{id}/vote", h.VoteReview)
+		r.Delete("/reviews/{id}/vote", h.WithdrawReviewVote)
+
+		r.With(utils.IsAdmin, utils.Uploads).Post("/admin/validate", h.ValidateProduct)
+		r.With(utils.IsAdmin).Post("/admin/import", h.ImportProducts)
+		r.With(utils.IsAdmin).Post("/admin/import/sheet", h.SyncSheet)
+		r.With(utils.IsAdmin, loadshed.LowPriority, audit.Export(models.ExportProducts)).Get("/admin/export", h.ExportProducts)
+		r.With(utils.IsAdmin).Patch("/admin/products/bulk", h.BulkUpdateProducts)
+		r.With(utils.IsAdmin).Get("/admin/low-stock", h.GetLowStock)
+		r.With(utils.IsAdmin).Put("/admin/product/{id}/low-stock", h.SetLowStockThreshold)
+		r.With(utils.IsAdmin, loadshed.LowPriority).Get("/admin/search/zero-results", h.GetZeroResultSearches)
+		r.With(utils.IsAdmin).Get("/admin/synonyms", h.GetSynonyms)
+		r.With(utils.IsAdmin).Post("/admin/synonyms", h.CreateSynonyms)
+		r.With(utils.IsAdmin).Put("/admin/synonyms/{id}", h.UpdateSynonyms)
+		r.With(utils.IsAdmin).Delete("/admin/synonyms/{id}", h.DeleteSynonyms)
 	})
 
 	return mux
 }
+
+// isSearch reports whether r lists the products found by a keyword search.
+func isSearch(r *http.Request) bool {
+	return strings.TrimSpace(r.URL.Query().Get("keyword")) != ""
+}
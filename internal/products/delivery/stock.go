@@ -0,0 +1,72 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// GetLowStock returns the products, and variants, with at most the low-stock threshold
+// of their product left, fewest units first (admin).
+// Endpoint: GET /api/v1/product/admin/low-stock?limit=<int>
+// limit defaults to 50 and is capped at 200.
+func (h *ProdHandlers) GetLowStock(w http.ResponseWriter, r *http.Request) {
+	var limit int
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			_ = utils.BadRequest(w, r, errors.New("limit must be a positive number"))
+			h.logger.Errorf("error parsing limit: %v", l)
+			return
+		}
+		limit = n
+	}
+
+	items, err := h.prodUC.GetLowStock(limit)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting low stock: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success  bool                  `json:"success"`
+		Products []models.LowStockItem `json:"products"`
+	}{Success: true, Products: items})
+}
+
+// SetLowStockThreshold sets the stock at or under which admins are alerted about a
+// product (admin).
+// Endpoint: PUT /api/v1/product/admin/product/{id}/low-stock
+// Expects JSON body: {"threshold": <int, 0 or more>}.
+func (h *ProdHandlers) SetLowStockThreshold(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	var req thresholdRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	if err := h.prodUC.SetLowStockThreshold(id, *req.Threshold); err != nil {
+		if errors.Is(err, products.ErrProductNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error setting low-stock threshold: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error setting low-stock threshold: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, models.Response{Success: true, Message: "low-stock threshold set"})
+}
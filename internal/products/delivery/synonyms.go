@@ -0,0 +1,120 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+type synonymResponse struct {
+	Success  bool               `json:"success"`
+	Synonyms *models.SynonymSet `json:"synonyms"`
+}
+
+// GetSynonyms returns the synonym sets applied to keyword searches (admin).
+// Endpoint: GET /api/v1/product/admin/synonyms
+func (h *ProdHandlers) GetSynonyms(w http.ResponseWriter, r *http.Request) {
+	sets, err := h.prodUC.GetSynonyms()
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting synonyms: %w", err))
+		return
+	}
+	if sets == nil {
+		sets = []models.SynonymSet{}
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success  bool                `json:"success"`
+		Synonyms []models.SynonymSet `json:"synonyms"`
+	}{Success: true, Synonyms: sets})
+}
+
+// CreateSynonyms creates a synonym set (admin). A search for one of its terms also
+// finds the products named with the others.
+// Endpoint: POST /api/v1/product/admin/synonyms
+// Expects JSON body: {"terms": ["sneakers", "trainers"]}.
+func (h *ProdHandlers) CreateSynonyms(w http.ResponseWriter, r *http.Request) {
+	set, ok := h.readSynonyms(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.prodUC.CreateSynonyms(set)
+	if err != nil {
+		h.writeSynonymsError(w, r, "error creating synonyms", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusCreated, synonymResponse{Success: true, Synonyms: created})
+}
+
+// UpdateSynonyms replaces the terms of a synonym set (admin).
+// Endpoint: PUT /api/v1/product/admin/synonyms/{id}
+// Expects JSON body: {"terms": [<string>]}.
+func (h *ProdHandlers) UpdateSynonyms(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	set, ok := h.readSynonyms(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.prodUC.UpdateSynonyms(id, set)
+	if err != nil {
+		h.writeSynonymsError(w, r, "error updating synonyms", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, synonymResponse{Success: true, Synonyms: updated})
+}
+
+// DeleteSynonyms deletes a synonym set (admin).
+// Endpoint: DELETE /api/v1/product/admin/synonyms/{id}
+func (h *ProdHandlers) DeleteSynonyms(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	if err := h.prodUC.DeleteSynonyms(id); err != nil {
+		h.writeSynonymsError(w, r, "error deleting synonyms", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, models.Response{Success: true, Message: "synonyms deleted"})
+}
+
+// readSynonyms reads and validates the synonym set in the request body. It writes the
+// response and returns false when the body is not a valid set.
+func (h *ProdHandlers) readSynonyms(w http.ResponseWriter, r *http.Request) (models.SynonymSet, bool) {
+	var req synonymsRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return models.SynonymSet{}, false
+	}
+
+	return models.SynonymSet{Terms: req.Terms}, true
+}
+
+// writeSynonymsError answers 400 for the errors caused by the request and 500 for the
+// others.
+func (h *ProdHandlers) writeSynonymsError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if errors.Is(err, products.ErrSynonymsNotFound) || errors.Is(err, products.ErrSynonymExists) {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("%s: %v", msg, err)
+		return
+	}
+	_ = utils.ServerError(w, r, h.logger, fmt.Errorf("%s: %w", msg, err))
+}
@@ -0,0 +1,156 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
+	"github.com/jofosuware/go/shopit/pkg/featureflag"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// RecordView records that the user, or else the anonymous visitor of the cohort
+// cookie, viewed a product, for their recently viewed products.
+// Endpoint: POST /api/v1/product/{id}/view
+func (h *ProdHandlers) RecordView(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	if err := h.prodUC.RecordView(id, viewer(r)); err != nil {
+		if errors.Is(err, products.ErrProductNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error recording product view: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error recording product view: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success bool `json:"success"`
+	}{Success: true})
+}
+
+// GetRecentlyViewed returns the products the user, or else the anonymous visitor,
+// viewed, the last viewed first, priced like GetProducts.
+// Endpoint: GET /api/v1/product/recently-viewed?limit=<int>
+// limit defaults to 8 and is capped at 24.
+func (h *ProdHandlers) GetRecentlyViewed(w http.ResponseWriter, r *http.Request) {
+	currency, err := exchange.Currency(r)
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error getting recently viewed products: %v", err)
+		return
+	}
+
+	limit, ok := h.readLimit(w, r)
+	if !ok {
+		return
+	}
+
+	recs, err := h.prodUC.GetRecentlyViewed(viewer(r), limit)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting recently viewed products: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success  bool                    `json:"success"`
+		Products []models.Recommendation `json:"products"`
+	}{Success: true, Products: h.localizeRecommendations(recs, currency)})
+}
+
+// GetRelatedProducts returns products in stock in the category of a product, those
+// rated closest to it first, priced like GetProducts.
+// Endpoint: GET /api/v1/product/{id}/related?limit=<int>
+// limit defaults to 8 and is capped at 24.
+func (h *ProdHandlers) GetRelatedProducts(w http.ResponseWriter, r *http.Request) {
+	currency, err := exchange.Currency(r)
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error getting related products: %v", err)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	limit, ok := h.readLimit(w, r)
+	if !ok {
+		return
+	}
+
+	recs, err := h.prodUC.GetRelatedProducts(id, limit)
+	if err != nil {
+		if errors.Is(err, products.ErrProductNotFound) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error getting related products: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting related products: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, struct {
+		Success  bool                    `json:"success"`
+		Products []models.Recommendation `json:"products"`
+	}{Success: true, Products: h.localizeRecommendations(recs, currency)})
+}
+
+// readLimit parses the optional limit query parameter, answering with 400 when it is
+// not a positive number.
+func (h *ProdHandlers) readLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	l := r.URL.Query().Get("limit")
+	if l == "" {
+		return 0, true
+	}
+
+	n, err := strconv.Atoi(l)
+	if err != nil || n < 1 {
+		_ = utils.BadRequest(w, r, errors.New("limit must be a positive number"))
+		h.logger.Errorf("error parsing limit: %v", l)
+		return 0, false
+	}
+
+	return n, true
+}
+
+// localizeRecommendations returns recs priced in currency, or as they are when they
+// cannot be converted. recs may be cached and are left untouched.
+func (h *ProdHandlers) localizeRecommendations(recs []models.Recommendation, currency string) []models.Recommendation {
+	localized := make([]models.Recommendation, len(recs))
+	for i, rec := range recs {
+		price, err := h.rates.Convert(rec.Price, currency)
+		if err != nil {
+			h.logger.Errorf("error converting product price: %v", err)
+			return recs
+		}
+		rec.Price = price
+		localized[i] = rec
+	}
+
+	return localized
+}
+
+// viewer returns the signed in user of r, or else its anonymous visitor.
+func viewer(r *http.Request) models.Viewer {
+	if user, ok := r.Context().Value(utils.UserContextKey).(*models.User); ok && user != nil {
+		return models.Viewer{UserID: uuid.NullUUID{UUID: user.ID, Valid: true}}
+	}
+
+	return models.Viewer{Session: featureflag.Session(r.Context())}
+}
@@ -0,0 +1,88 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+type votesResponse struct {
+	Success bool `json:"success"`
+	models.ReviewVotes
+}
+
+// VoteReview records whether the user found a review helpful, replacing the vote they
+// gave before, and returns the votes on the review.
+// Endpoint: PUT /api/v1/product/reviews/{id}/vote
+// Expects JSON: {"helpful": true} or {"helpful": false}.
+func (h *ProdHandlers) VoteReview(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("user cannot be found, login"))
+		h.logger.Errorf("error getting user: %v", errors.New("user not found"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	var req voteRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	votes, err := h.prodUC.VoteReview(id, user.ID, *req.Helpful)
+	if err != nil {
+		h.writeVoteError(w, r, "error voting on review", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, votesResponse{Success: true, ReviewVotes: votes})
+}
+
+// WithdrawReviewVote withdraws the vote of the user on a review and returns the votes
+// on the review.
+// Endpoint: DELETE /api/v1/product/reviews/{id}/vote
+func (h *ProdHandlers) WithdrawReviewVote(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("user cannot be found, login"))
+		h.logger.Errorf("error getting user: %v", errors.New("user not found"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	votes, err := h.prodUC.WithdrawReviewVote(id, user.ID)
+	if err != nil {
+		h.writeVoteError(w, r, "error withdrawing review vote", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, votesResponse{Success: true, ReviewVotes: votes})
+}
+
+func (h *ProdHandlers) writeVoteError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if errors.Is(err, products.ErrReviewNotFound) {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("%s: %v", msg, err)
+		return
+	}
+
+	_ = utils.ServerError(w, r, h.logger, fmt.Errorf("%s: %w", msg, err))
+}
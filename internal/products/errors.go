@@ -0,0 +1,74 @@
+package products
+
+import "errors"
+
+// ErrProductNotFound is returned for a product that is hidden from the storefront.
+var ErrProductNotFound = errors.New("product not found")
+
+// ErrNotProductOwner is returned when a seller changes a product of another user.
+var ErrNotProductOwner = errors.New("product belongs to another seller")
+
+// ErrVariantNotFound is returned when a variant to update is not one of the product.
+var ErrVariantNotFound = errors.New("variant not found")
+
+// ErrInvalidCSV is returned when an import is not a CSV file with the product columns.
+var ErrInvalidCSV = errors.New("invalid product csv")
+
+// ErrTooManyRows is returned when an import has more rows than MaxImportRows.
+var ErrTooManyRows = errors.New("too many rows in product csv")
+
+// MaxImportRows caps the products of one CSV import.
+const MaxImportRows = 10000
+
+// MaxBulkUpdates caps the updates of one bulk update.
+const MaxBulkUpdates = 5000
+
+// ErrSheetNotConfigured is returned when syncing the catalog without a catalog sheet.
+var ErrSheetNotConfigured = errors.New("no catalog sheet is configured: set catalogSync.sheetId")
+
+// ErrCategoryNotFound is returned when a product is filed under a category that does not exist.
+var ErrCategoryNotFound = errors.New("category not found")
+
+// ErrSearchNotFound is returned when a click names a search that was not recorded.
+var ErrSearchNotFound = errors.New("search not found")
+
+// ErrSynonymsNotFound is returned when a synonym set does not exist.
+var ErrSynonymsNotFound = errors.New("synonym set not found")
+
+// ErrSynonymExists is returned when a term of a synonym set is already in another set.
+var ErrSynonymExists = errors.New("term is already in another synonym set")
+
+// ErrReviewNotFound is returned when voting on a review that does not exist.
+var ErrReviewNotFound = errors.New("review not found")
+
+// ErrImageNotFound is returned when an image to delete is not one of the product.
+var ErrImageNotFound = errors.New("image not found")
+
+// ErrUploadNotFound is returned when an upload to attach to a product was not made by
+// the user, has expired or is already attached.
+var ErrUploadNotFound = errors.New("upload not found, expired or already used")
+
+// ImageError is returned when an image to add to a product is rejected: it is
+// missing, too large, unreadable or not an image. Field is the form field at fault,
+// such as images[1], Code its validator code, and Reason is shown to the client.
+type ImageError struct {
+	Field  string
+	Code   string
+	Reason string
+}
+
+func (e *ImageError) Error() string {
+	return e.Reason
+}
+
+// ValidationError is returned when a product to save is invalid. Errors maps each
+// field at fault, or an image by position such as images[0], to what is wrong with it
+// and Codes maps it to its validator code.
+type ValidationError struct {
+	Errors map[string]string
+	Codes  map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid product"
+}
@@ -3,11 +3,17 @@
 package mocks
 
 import (
-	multipart "mime/multipart"
+	context "context"
+	io "io"
 
-	models "github.com/jofosuware/go/shopit/internal/models"
 	mock "github.com/stretchr/testify/mock"
 
+	models "github.com/jofosuware/go/shopit/internal/models"
+
+	multipart "mime/multipart"
+
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -16,9 +22,99 @@ type ProductUC struct {
 	mock.Mock
 }
 
-// CreateProduct provides a mock function with given fields: p, imgs
-func (_m *ProductUC) CreateProduct(p models.Product, imgs []*multipart.FileHeader) (*models.ProdResponse, error) {
-	ret := _m.Called(p, imgs)
+// AddImages provides a mock function with given fields: ctx, productId, img, user
+func (_m *ProductUC) AddImages(ctx context.Context, productId uuid.UUID, img []*multipart.FileHeader, user *models.User) ([]models.Images, error) {
+	ret := _m.Called(ctx, productId, img, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddImages")
+	}
+
+	var r0 []models.Images
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []*multipart.FileHeader, *models.User) ([]models.Images, error)); ok {
+		return rf(ctx, productId, img, user)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []*multipart.FileHeader, *models.User) []models.Images); ok {
+		r0 = rf(ctx, productId, img, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Images)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, []*multipart.FileHeader, *models.User) error); ok {
+		r1 = rf(ctx, productId, img, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AttachImages provides a mock function with given fields: productId, publicIds, user
+func (_m *ProductUC) AttachImages(productId uuid.UUID, publicIds []string, user *models.User) ([]models.Images, error) {
+	ret := _m.Called(productId, publicIds, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AttachImages")
+	}
+
+	var r0 []models.Images
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, []string, *models.User) ([]models.Images, error)); ok {
+		return rf(productId, publicIds, user)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, []string, *models.User) []models.Images); ok {
+		r0 = rf(productId, publicIds, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Images)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, []string, *models.User) error); ok {
+		r1 = rf(productId, publicIds, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BulkUpdateProducts provides a mock function with given fields: updates
+func (_m *ProductUC) BulkUpdateProducts(updates []models.ProductUpdate) (*models.BulkUpdateReport, error) {
+	ret := _m.Called(updates)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkUpdateProducts")
+	}
+
+	var r0 *models.BulkUpdateReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]models.ProductUpdate) (*models.BulkUpdateReport, error)); ok {
+		return rf(updates)
+	}
+	if rf, ok := ret.Get(0).(func([]models.ProductUpdate) *models.BulkUpdateReport); ok {
+		r0 = rf(updates)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BulkUpdateReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]models.ProductUpdate) error); ok {
+		r1 = rf(updates)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateProduct provides a mock function with given fields: ctx, p, img
+func (_m *ProductUC) CreateProduct(ctx context.Context, p models.Product, img []*multipart.FileHeader) (*models.ProdResponse, error) {
+	ret := _m.Called(ctx, p, img)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateProduct")
@@ -26,19 +122,19 @@ func (_m *ProductUC) CreateProduct(p models.Product, imgs []*multipart.FileHeade
 
 	var r0 *models.ProdResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(models.Product, []*multipart.FileHeader) (*models.ProdResponse, error)); ok {
-		return rf(p, imgs)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Product, []*multipart.FileHeader) (*models.ProdResponse, error)); ok {
+		return rf(ctx, p, img)
 	}
-	if rf, ok := ret.Get(0).(func(models.Product, []*multipart.FileHeader) *models.ProdResponse); ok {
-		r0 = rf(p, imgs)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Product, []*multipart.FileHeader) *models.ProdResponse); ok {
+		r0 = rf(ctx, p, img)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.ProdResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(models.Product, []*multipart.FileHeader) error); ok {
-		r1 = rf(p, imgs)
+	if rf, ok := ret.Get(1).(func(context.Context, models.Product, []*multipart.FileHeader) error); ok {
+		r1 = rf(ctx, p, img)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -46,17 +142,17 @@ func (_m *ProductUC) CreateProduct(p models.Product, imgs []*multipart.FileHeade
 	return r0, r1
 }
 
-// CreateProductReview provides a mock function with given fields: review
-func (_m *ProductUC) CreateProductReview(review models.Reviews) error {
-	ret := _m.Called(review)
+// CreateProductReview provides a mock function with given fields: ctx, review, img
+func (_m *ProductUC) CreateProductReview(ctx context.Context, review models.Reviews, img []*multipart.FileHeader) error {
+	ret := _m.Called(ctx, review, img)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateProductReview")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(models.Reviews) error); ok {
-		r0 = rf(review)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Reviews, []*multipart.FileHeader) error); ok {
+		r0 = rf(ctx, review, img)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -64,17 +160,77 @@ func (_m *ProductUC) CreateProductReview(review models.Reviews) error {
 	return r0
 }
 
-// DeleteProduct provides a mock function with given fields: productId
-func (_m *ProductUC) DeleteProduct(productId uuid.UUID) error {
-	ret := _m.Called(productId)
+// CreateSynonyms provides a mock function with given fields: s
+func (_m *ProductUC) CreateSynonyms(s models.SynonymSet) (*models.SynonymSet, error) {
+	ret := _m.Called(s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSynonyms")
+	}
+
+	var r0 *models.SynonymSet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.SynonymSet) (*models.SynonymSet, error)); ok {
+		return rf(s)
+	}
+	if rf, ok := ret.Get(0).(func(models.SynonymSet) *models.SynonymSet); ok {
+		r0 = rf(s)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SynonymSet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.SynonymSet) error); ok {
+		r1 = rf(s)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteImage provides a mock function with given fields: ctx, productId, publicId, user
+func (_m *ProductUC) DeleteImage(ctx context.Context, productId uuid.UUID, publicId string, user *models.User) ([]models.Images, error) {
+	ret := _m.Called(ctx, productId, publicId, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteImage")
+	}
+
+	var r0 []models.Images
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, *models.User) ([]models.Images, error)); ok {
+		return rf(ctx, productId, publicId, user)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, *models.User) []models.Images); ok {
+		r0 = rf(ctx, productId, publicId, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Images)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, *models.User) error); ok {
+		r1 = rf(ctx, productId, publicId, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteProduct provides a mock function with given fields: ctx, productId, user
+func (_m *ProductUC) DeleteProduct(ctx context.Context, productId uuid.UUID, user *models.User) error {
+	ret := _m.Called(ctx, productId, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteProduct")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
-		r0 = rf(productId)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, *models.User) error); ok {
+		r0 = rf(ctx, productId, user)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -100,29 +256,65 @@ func (_m *ProductUC) DeleteProductReview(productId uuid.UUID, reviewId uuid.UUID
 	return r0
 }
 
-// GetAdminProducts provides a mock function with given fields:
-func (_m *ProductUC) GetAdminProducts() ([]*models.Product, error) {
-	ret := _m.Called()
+// DeleteSynonyms provides a mock function with given fields: id
+func (_m *ProductUC) DeleteSynonyms(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSynonyms")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExportProducts provides a mock function with given fields: w
+func (_m *ProductUC) ExportProducts(w io.Writer) error {
+	ret := _m.Called(w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportProducts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer) error); ok {
+		r0 = rf(w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAdminProducts provides a mock function with given fields: keyword, sort, page, limit, owner
+func (_m *ProductUC) GetAdminProducts(keyword string, sort string, page int, limit int, owner uuid.NullUUID) (*models.GetProd, error) {
+	ret := _m.Called(keyword, sort, page, limit, owner)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAdminProducts")
 	}
 
-	var r0 []*models.Product
+	var r0 *models.GetProd
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]*models.Product, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(string, string, int, int, uuid.NullUUID) (*models.GetProd, error)); ok {
+		return rf(keyword, sort, page, limit, owner)
 	}
-	if rf, ok := ret.Get(0).(func() []*models.Product); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(string, string, int, int, uuid.NullUUID) *models.GetProd); ok {
+		r0 = rf(keyword, sort, page, limit, owner)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Product)
+			r0 = ret.Get(0).(*models.GetProd)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(string, string, int, int, uuid.NullUUID) error); ok {
+		r1 = rf(keyword, sort, page, limit, owner)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -130,9 +322,39 @@ func (_m *ProductUC) GetAdminProducts() ([]*models.Product, error) {
 	return r0, r1
 }
 
-// GetProductReviews provides a mock function with given fields: productId
-func (_m *ProductUC) GetProductReviews(productId uuid.UUID) ([]models.Reviews, error) {
-	ret := _m.Called(productId)
+// GetLowStock provides a mock function with given fields: limit
+func (_m *ProductUC) GetLowStock(limit int) ([]models.LowStockItem, error) {
+	ret := _m.Called(limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLowStock")
+	}
+
+	var r0 []models.LowStockItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) ([]models.LowStockItem, error)); ok {
+		return rf(limit)
+	}
+	if rf, ok := ret.Get(0).(func(int) []models.LowStockItem); ok {
+		r0 = rf(limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LowStockItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProductReviews provides a mock function with given fields: productId, sort
+func (_m *ProductUC) GetProductReviews(productId uuid.UUID, sort string) ([]models.Reviews, error) {
+	ret := _m.Called(productId, sort)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetProductReviews")
@@ -140,19 +362,19 @@ func (_m *ProductUC) GetProductReviews(productId uuid.UUID) ([]models.Reviews, e
 
 	var r0 []models.Reviews
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.Reviews, error)); ok {
-		return rf(productId)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) ([]models.Reviews, error)); ok {
+		return rf(productId, sort)
 	}
-	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.Reviews); ok {
-		r0 = rf(productId)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) []models.Reviews); ok {
+		r0 = rf(productId, sort)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Reviews)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
-		r1 = rf(productId)
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(productId, sort)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -160,9 +382,9 @@ func (_m *ProductUC) GetProductReviews(productId uuid.UUID) ([]models.Reviews, e
 	return r0, r1
 }
 
-// GetProducts provides a mock function with given fields: keyword, page
-func (_m *ProductUC) GetProducts(keyword string, page int) (*models.GetProd, error) {
-	ret := _m.Called(keyword, page)
+// GetProducts provides a mock function with given fields: keyword, category, page
+func (_m *ProductUC) GetProducts(keyword string, category string, page int) (*models.GetProd, error) {
+	ret := _m.Called(keyword, category, page)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetProducts")
@@ -170,19 +392,169 @@ func (_m *ProductUC) GetProducts(keyword string, page int) (*models.GetProd, err
 
 	var r0 *models.GetProd
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, int) (*models.GetProd, error)); ok {
-		return rf(keyword, page)
+	if rf, ok := ret.Get(0).(func(string, string, int) (*models.GetProd, error)); ok {
+		return rf(keyword, category, page)
 	}
-	if rf, ok := ret.Get(0).(func(string, int) *models.GetProd); ok {
-		r0 = rf(keyword, page)
+	if rf, ok := ret.Get(0).(func(string, string, int) *models.GetProd); ok {
+		r0 = rf(keyword, category, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.GetProd)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, int) error); ok {
-		r1 = rf(keyword, page)
+	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
+		r1 = rf(keyword, category, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProductsByIds provides a mock function with given fields: productIds
+func (_m *ProductUC) GetProductsByIds(productIds []uuid.UUID) ([]*models.Product, error) {
+	ret := _m.Called(productIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProductsByIds")
+	}
+
+	var r0 []*models.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]*models.Product, error)); ok {
+		return rf(productIds)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []*models.Product); ok {
+		r0 = rf(productIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(productIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRecentlyViewed provides a mock function with given fields: viewer, limit
+func (_m *ProductUC) GetRecentlyViewed(viewer models.Viewer, limit int) ([]models.Recommendation, error) {
+	ret := _m.Called(viewer, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRecentlyViewed")
+	}
+
+	var r0 []models.Recommendation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Viewer, int) ([]models.Recommendation, error)); ok {
+		return rf(viewer, limit)
+	}
+	if rf, ok := ret.Get(0).(func(models.Viewer, int) []models.Recommendation); ok {
+		r0 = rf(viewer, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Recommendation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Viewer, int) error); ok {
+		r1 = rf(viewer, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRecommendations provides a mock function with given fields: productIds, limit
+func (_m *ProductUC) GetRecommendations(productIds []uuid.UUID, limit int) ([]models.Recommendation, error) {
+	ret := _m.Called(productIds, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRecommendations")
+	}
+
+	var r0 []models.Recommendation
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID, int) ([]models.Recommendation, error)); ok {
+		return rf(productIds, limit)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID, int) []models.Recommendation); ok {
+		r0 = rf(productIds, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Recommendation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID, int) error); ok {
+		r1 = rf(productIds, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRelatedProducts provides a mock function with given fields: productId, limit
+func (_m *ProductUC) GetRelatedProducts(productId uuid.UUID, limit int) ([]models.Recommendation, error) {
+	ret := _m.Called(productId, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRelatedProducts")
+	}
+
+	var r0 []models.Recommendation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) ([]models.Recommendation, error)); ok {
+		return rf(productId, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) []models.Recommendation); ok {
+		r0 = rf(productId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Recommendation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(productId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewsByProductIds provides a mock function with given fields: productIds
+func (_m *ProductUC) GetReviewsByProductIds(productIds []uuid.UUID) (map[uuid.UUID][]models.Reviews, error) {
+	ret := _m.Called(productIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewsByProductIds")
+	}
+
+	var r0 map[uuid.UUID][]models.Reviews
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) (map[uuid.UUID][]models.Reviews, error)); ok {
+		return rf(productIds)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) map[uuid.UUID][]models.Reviews); ok {
+		r0 = rf(productIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID][]models.Reviews)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(productIds)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -220,9 +592,241 @@ func (_m *ProductUC) GetSingleProduct(productId uuid.UUID) (*models.Product, err
 	return r0, r1
 }
 
-// UpdateProduct provides a mock function with given fields: productId, p, imgs
-func (_m *ProductUC) UpdateProduct(productId uuid.UUID, p models.Product, imgs []*multipart.File) (*models.ProdResponse, error) {
-	ret := _m.Called(productId, p, imgs)
+// GetSynonyms provides a mock function with given fields:
+func (_m *ProductUC) GetSynonyms() ([]models.SynonymSet, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSynonyms")
+	}
+
+	var r0 []models.SynonymSet
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]models.SynonymSet, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []models.SynonymSet); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SynonymSet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetZeroResultSearches provides a mock function with given fields: since, limit
+func (_m *ProductUC) GetZeroResultSearches(since time.Time, limit int) ([]models.SearchTerm, error) {
+	ret := _m.Called(since, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetZeroResultSearches")
+	}
+
+	var r0 []models.SearchTerm
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, int) ([]models.SearchTerm, error)); ok {
+		return rf(since, limit)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, int) []models.SearchTerm); ok {
+		r0 = rf(since, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SearchTerm)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(since, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportProducts provides a mock function with given fields: ctx, r, userID
+func (_m *ProductUC) ImportProducts(ctx context.Context, r io.Reader, userID uuid.UUID) (*models.ProductImportReport, error) {
+	ret := _m.Called(ctx, r, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportProducts")
+	}
+
+	var r0 *models.ProductImportReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader, uuid.UUID) (*models.ProductImportReport, error)); ok {
+		return rf(ctx, r, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader, uuid.UUID) *models.ProductImportReport); ok {
+		r0 = rf(ctx, r, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ProductImportReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, io.Reader, uuid.UUID) error); ok {
+		r1 = rf(ctx, r, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PublishScheduled provides a mock function with given fields: ctx
+func (_m *ProductUC) PublishScheduled(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishScheduled")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordSearch provides a mock function with given fields: keyword, results
+func (_m *ProductUC) RecordSearch(keyword string, results int) (uuid.UUID, error) {
+	ret := _m.Called(keyword, results)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordSearch")
+	}
+
+	var r0 uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) (uuid.UUID, error)); ok {
+		return rf(keyword, results)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) uuid.UUID); ok {
+		r0 = rf(keyword, results)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(keyword, results)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordSearchClick provides a mock function with given fields: searchId, productId
+func (_m *ProductUC) RecordSearchClick(searchId uuid.UUID, productId uuid.UUID) error {
+	ret := _m.Called(searchId, productId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordSearchClick")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(searchId, productId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RecordView provides a mock function with given fields: productId, viewer
+func (_m *ProductUC) RecordView(productId uuid.UUID, viewer models.Viewer) error {
+	ret := _m.Called(productId, viewer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordView")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.Viewer) error); ok {
+		r0 = rf(productId, viewer)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetLowStockThreshold provides a mock function with given fields: productId, threshold
+func (_m *ProductUC) SetLowStockThreshold(productId uuid.UUID, threshold int) error {
+	ret := _m.Called(productId, threshold)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLowStockThreshold")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) error); ok {
+		r0 = rf(productId, threshold)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SyncSheet provides a mock function with given fields: ctx, userID
+func (_m *ProductUC) SyncSheet(ctx context.Context, userID uuid.UUID) (*models.ProductImportReport, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SyncSheet")
+	}
+
+	var r0 *models.ProductImportReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.ProductImportReport, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.ProductImportReport); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ProductImportReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateProduct provides a mock function with given fields: ctx, productId, p, img, user
+func (_m *ProductUC) UpdateProduct(ctx context.Context, productId uuid.UUID, p models.Product, img []*multipart.File, user *models.User) (*models.ProdResponse, error) {
+	ret := _m.Called(ctx, productId, p, img, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateProduct")
@@ -230,19 +834,135 @@ func (_m *ProductUC) UpdateProduct(productId uuid.UUID, p models.Product, imgs [
 
 	var r0 *models.ProdResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID, models.Product, []*multipart.File) (*models.ProdResponse, error)); ok {
-		return rf(productId, p, imgs)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, models.Product, []*multipart.File, *models.User) (*models.ProdResponse, error)); ok {
+		return rf(ctx, productId, p, img, user)
 	}
-	if rf, ok := ret.Get(0).(func(uuid.UUID, models.Product, []*multipart.File) *models.ProdResponse); ok {
-		r0 = rf(productId, p, imgs)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, models.Product, []*multipart.File, *models.User) *models.ProdResponse); ok {
+		r0 = rf(ctx, productId, p, img, user)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.ProdResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(uuid.UUID, models.Product, []*multipart.File) error); ok {
-		r1 = rf(productId, p, imgs)
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, models.Product, []*multipart.File, *models.User) error); ok {
+		r1 = rf(ctx, productId, p, img, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateSynonyms provides a mock function with given fields: id, s
+func (_m *ProductUC) UpdateSynonyms(id uuid.UUID, s models.SynonymSet) (*models.SynonymSet, error) {
+	ret := _m.Called(id, s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSynonyms")
+	}
+
+	var r0 *models.SynonymSet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.SynonymSet) (*models.SynonymSet, error)); ok {
+		return rf(id, s)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.SynonymSet) *models.SynonymSet); ok {
+		r0 = rf(id, s)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SynonymSet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, models.SynonymSet) error); ok {
+		r1 = rf(id, s)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateProduct provides a mock function with given fields: p, img
+func (_m *ProductUC) ValidateProduct(p models.Product, img []*multipart.FileHeader) (*models.ProductValidationReport, error) {
+	ret := _m.Called(p, img)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateProduct")
+	}
+
+	var r0 *models.ProductValidationReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Product, []*multipart.FileHeader) (*models.ProductValidationReport, error)); ok {
+		return rf(p, img)
+	}
+	if rf, ok := ret.Get(0).(func(models.Product, []*multipart.FileHeader) *models.ProductValidationReport); ok {
+		r0 = rf(p, img)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ProductValidationReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Product, []*multipart.FileHeader) error); ok {
+		r1 = rf(p, img)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VoteReview provides a mock function with given fields: reviewId, userId, helpful
+func (_m *ProductUC) VoteReview(reviewId uuid.UUID, userId uuid.UUID, helpful bool) (models.ReviewVotes, error) {
+	ret := _m.Called(reviewId, userId, helpful)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VoteReview")
+	}
+
+	var r0 models.ReviewVotes
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, bool) (models.ReviewVotes, error)); ok {
+		return rf(reviewId, userId, helpful)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, bool) models.ReviewVotes); ok {
+		r0 = rf(reviewId, userId, helpful)
+	} else {
+		r0 = ret.Get(0).(models.ReviewVotes)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, bool) error); ok {
+		r1 = rf(reviewId, userId, helpful)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WithdrawReviewVote provides a mock function with given fields: reviewId, userId
+func (_m *ProductUC) WithdrawReviewVote(reviewId uuid.UUID, userId uuid.UUID) (models.ReviewVotes, error) {
+	ret := _m.Called(reviewId, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithdrawReviewVote")
+	}
+
+	var r0 models.ReviewVotes
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) (models.ReviewVotes, error)); ok {
+		return rf(reviewId, userId)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) models.ReviewVotes); ok {
+		r0 = rf(reviewId, userId)
+	} else {
+		r0 = ret.Get(0).(models.ReviewVotes)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(reviewId, userId)
 	} else {
 		r1 = ret.Error(1)
 	}
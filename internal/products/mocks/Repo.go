@@ -3,9 +3,13 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/jofosuware/go/shopit/internal/models"
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -14,6 +18,60 @@ type Repo struct {
 	mock.Mock
 }
 
+// AttachUploads provides a mock function with given fields: productId, userId, publicIds
+func (_m *Repo) AttachUploads(productId uuid.UUID, userId uuid.UUID, publicIds []string) error {
+	ret := _m.Called(productId, userId, publicIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AttachUploads")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, []string) error); ok {
+		r0 = rf(productId, userId, publicIds)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// BulkUpdateProducts provides a mock function with given fields: updates
+func (_m *Repo) BulkUpdateProducts(updates []models.ProductUpdate) error {
+	ret := _m.Called(updates)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkUpdateProducts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]models.ProductUpdate) error); ok {
+		r0 = rf(updates)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteImage provides a mock function with given fields: productId, publicId
+func (_m *Repo) DeleteImage(productId uuid.UUID, publicId string) error {
+	ret := _m.Called(productId, publicId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteImage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = rf(productId, publicId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteImageUrlById provides a mock function with given fields: id
 func (_m *Repo) DeleteImageUrlById(id uuid.UUID) error {
 	ret := _m.Called(id)
@@ -68,6 +126,42 @@ func (_m *Repo) DeleteReviewById(productId uuid.UUID) error {
 	return r0
 }
 
+// DeleteReviewVote provides a mock function with given fields: reviewId, userId
+func (_m *Repo) DeleteReviewVote(reviewId uuid.UUID, userId uuid.UUID) error {
+	ret := _m.Called(reviewId, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteReviewVote")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(reviewId, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSynonyms provides a mock function with given fields: id
+func (_m *Repo) DeleteSynonyms(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSynonyms")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // FetchAllProducts provides a mock function with given fields:
 func (_m *Repo) FetchAllProducts() ([]*models.Product, error) {
 	ret := _m.Called()
@@ -128,6 +222,66 @@ func (_m *Repo) FetchImageUrlById(id uuid.UUID) ([]models.Images, error) {
 	return r0, r1
 }
 
+// FetchImagesByProductIds provides a mock function with given fields: ids
+func (_m *Repo) FetchImagesByProductIds(ids []uuid.UUID) ([]models.Images, error) {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchImagesByProductIds")
+	}
+
+	var r0 []models.Images
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]models.Images, error)); ok {
+		return rf(ids)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []models.Images); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Images)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchLowStock provides a mock function with given fields: limit
+func (_m *Repo) FetchLowStock(limit int) ([]models.LowStockItem, error) {
+	ret := _m.Called(limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchLowStock")
+	}
+
+	var r0 []models.LowStockItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) ([]models.LowStockItem, error)); ok {
+		return rf(limit)
+	}
+	if rf, ok := ret.Get(0).(func(int) []models.LowStockItem); ok {
+		r0 = rf(limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LowStockItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FetchProductById provides a mock function with given fields: id
 func (_m *Repo) FetchProductById(id uuid.UUID) (*models.Product, error) {
 	ret := _m.Called(id)
@@ -158,9 +312,9 @@ func (_m *Repo) FetchProductById(id uuid.UUID) (*models.Product, error) {
 	return r0, r1
 }
 
-// FetchProductByName provides a mock function with given fields: keyword, page
-func (_m *Repo) FetchProductByName(keyword string, page int) ([]models.Product, int, error) {
-	ret := _m.Called(keyword, page)
+// FetchProductByName provides a mock function with given fields: filter
+func (_m *Repo) FetchProductByName(filter models.ProductFilter) ([]models.Product, int, error) {
+	ret := _m.Called(filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FetchProductByName")
@@ -169,25 +323,25 @@ func (_m *Repo) FetchProductByName(keyword string, page int) ([]models.Product,
 	var r0 []models.Product
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(string, int) ([]models.Product, int, error)); ok {
-		return rf(keyword, page)
+	if rf, ok := ret.Get(0).(func(models.ProductFilter) ([]models.Product, int, error)); ok {
+		return rf(filter)
 	}
-	if rf, ok := ret.Get(0).(func(string, int) []models.Product); ok {
-		r0 = rf(keyword, page)
+	if rf, ok := ret.Get(0).(func(models.ProductFilter) []models.Product); ok {
+		r0 = rf(filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Product)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, int) int); ok {
-		r1 = rf(keyword, page)
+	if rf, ok := ret.Get(1).(func(models.ProductFilter) int); ok {
+		r1 = rf(filter)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(string, int) error); ok {
-		r2 = rf(keyword, page)
+	if rf, ok := ret.Get(2).(func(models.ProductFilter) error); ok {
+		r2 = rf(filter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -195,9 +349,129 @@ func (_m *Repo) FetchProductByName(keyword string, page int) ([]models.Product,
 	return r0, r1, r2
 }
 
-// FetchReviewById provides a mock function with given fields: productId
-func (_m *Repo) FetchReviewById(productId uuid.UUID) ([]models.Reviews, error) {
-	ret := _m.Called(productId)
+// FetchProductKeys provides a mock function with given fields: ctx
+func (_m *Repo) FetchProductKeys(ctx context.Context) (map[uuid.UUID]string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchProductKeys")
+	}
+
+	var r0 map[uuid.UUID]string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (map[uuid.UUID]string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) map[uuid.UUID]string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchProductsByIds provides a mock function with given fields: ids
+func (_m *Repo) FetchProductsByIds(ids []uuid.UUID) ([]*models.Product, error) {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchProductsByIds")
+	}
+
+	var r0 []*models.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]*models.Product, error)); ok {
+		return rf(ids)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []*models.Product); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchRecentlyViewed provides a mock function with given fields: viewer, limit
+func (_m *Repo) FetchRecentlyViewed(viewer models.Viewer, limit int) ([]models.Recommendation, error) {
+	ret := _m.Called(viewer, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchRecentlyViewed")
+	}
+
+	var r0 []models.Recommendation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Viewer, int) ([]models.Recommendation, error)); ok {
+		return rf(viewer, limit)
+	}
+	if rf, ok := ret.Get(0).(func(models.Viewer, int) []models.Recommendation); ok {
+		r0 = rf(viewer, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Recommendation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Viewer, int) error); ok {
+		r1 = rf(viewer, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchRelatedProducts provides a mock function with given fields: productIds, limit
+func (_m *Repo) FetchRelatedProducts(productIds []uuid.UUID, limit int) ([]models.Recommendation, error) {
+	ret := _m.Called(productIds, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchRelatedProducts")
+	}
+
+	var r0 []models.Recommendation
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID, int) ([]models.Recommendation, error)); ok {
+		return rf(productIds, limit)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID, int) []models.Recommendation); ok {
+		r0 = rf(productIds, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Recommendation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID, int) error); ok {
+		r1 = rf(productIds, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchReviewById provides a mock function with given fields: productId, sort
+func (_m *Repo) FetchReviewById(productId uuid.UUID, sort string) ([]models.Reviews, error) {
+	ret := _m.Called(productId, sort)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FetchReviewById")
@@ -205,19 +479,77 @@ func (_m *Repo) FetchReviewById(productId uuid.UUID) ([]models.Reviews, error) {
 
 	var r0 []models.Reviews
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.Reviews, error)); ok {
-		return rf(productId)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) ([]models.Reviews, error)); ok {
+		return rf(productId, sort)
 	}
-	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.Reviews); ok {
-		r0 = rf(productId)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) []models.Reviews); ok {
+		r0 = rf(productId, sort)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Reviews)
 		}
 	}
 
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(productId, sort)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchReviewImages provides a mock function with given fields: reviewIds
+func (_m *Repo) FetchReviewImages(reviewIds []uuid.UUID) ([]models.ReviewImage, error) {
+	ret := _m.Called(reviewIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchReviewImages")
+	}
+
+	var r0 []models.ReviewImage
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]models.ReviewImage, error)); ok {
+		return rf(reviewIds)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []models.ReviewImage); ok {
+		r0 = rf(reviewIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.ReviewImage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(reviewIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchReviewVotes provides a mock function with given fields: reviewId
+func (_m *Repo) FetchReviewVotes(reviewId uuid.UUID) (models.ReviewVotes, error) {
+	ret := _m.Called(reviewId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchReviewVotes")
+	}
+
+	var r0 models.ReviewVotes
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (models.ReviewVotes, error)); ok {
+		return rf(reviewId)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) models.ReviewVotes); ok {
+		r0 = rf(reviewId)
+	} else {
+		r0 = ret.Get(0).(models.ReviewVotes)
+	}
+
 	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
-		r1 = rf(productId)
+		r1 = rf(reviewId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -255,27 +587,167 @@ func (_m *Repo) FetchReviews() ([]models.Reviews, error) {
 	return r0, r1
 }
 
-// InsertImageUrl provides a mock function with given fields: img
-func (_m *Repo) InsertImageUrl(img *models.Images) (models.Images, error) {
-	ret := _m.Called(img)
+// FetchReviewsByProductIds provides a mock function with given fields: ids
+func (_m *Repo) FetchReviewsByProductIds(ids []uuid.UUID) ([]models.Reviews, error) {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchReviewsByProductIds")
+	}
+
+	var r0 []models.Reviews
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]models.Reviews, error)); ok {
+		return rf(ids)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []models.Reviews); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Reviews)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchSynonyms provides a mock function with given fields:
+func (_m *Repo) FetchSynonyms() ([]models.SynonymSet, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchSynonyms")
+	}
+
+	var r0 []models.SynonymSet
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]models.SynonymSet, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []models.SynonymSet); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SynonymSet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchVariants provides a mock function with given fields: productId
+func (_m *Repo) FetchVariants(productId uuid.UUID) ([]models.Variant, error) {
+	ret := _m.Called(productId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchVariants")
+	}
+
+	var r0 []models.Variant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.Variant, error)); ok {
+		return rf(productId)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.Variant); ok {
+		r0 = rf(productId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Variant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(productId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchZeroResultSearches provides a mock function with given fields: since, limit
+func (_m *Repo) FetchZeroResultSearches(since time.Time, limit int) ([]models.SearchTerm, error) {
+	ret := _m.Called(since, limit)
 
 	if len(ret) == 0 {
-		panic("no return value specified for InsertImageUrl")
+		panic("no return value specified for FetchZeroResultSearches")
 	}
 
-	var r0 models.Images
+	var r0 []models.SearchTerm
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*models.Images) (models.Images, error)); ok {
-		return rf(img)
+	if rf, ok := ret.Get(0).(func(time.Time, int) ([]models.SearchTerm, error)); ok {
+		return rf(since, limit)
 	}
-	if rf, ok := ret.Get(0).(func(*models.Images) models.Images); ok {
-		r0 = rf(img)
+	if rf, ok := ret.Get(0).(func(time.Time, int) []models.SearchTerm); ok {
+		r0 = rf(since, limit)
 	} else {
-		r0 = ret.Get(0).(models.Images)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SearchTerm)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*models.Images) error); ok {
-		r1 = rf(img)
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(since, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportProducts provides a mock function with given fields: ctx, inserts, updates
+func (_m *Repo) ImportProducts(ctx context.Context, inserts []*models.Product, updates []*models.Product) error {
+	ret := _m.Called(ctx, inserts, updates)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportProducts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*models.Product, []*models.Product) error); ok {
+		r0 = rf(ctx, inserts, updates)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertImageUrls provides a mock function with given fields: imgs
+func (_m *Repo) InsertImageUrls(imgs []models.Images) ([]models.Images, error) {
+	ret := _m.Called(imgs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertImageUrls")
+	}
+
+	var r0 []models.Images
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]models.Images) ([]models.Images, error)); ok {
+		return rf(imgs)
+	}
+	if rf, ok := ret.Get(0).(func([]models.Images) []models.Images); ok {
+		r0 = rf(imgs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Images)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]models.Images) error); ok {
+		r1 = rf(imgs)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -329,6 +801,224 @@ func (_m *Repo) InsertReview(r *models.Reviews) error {
 	return r0
 }
 
+// InsertSearch provides a mock function with given fields: keyword, results
+func (_m *Repo) InsertSearch(keyword string, results int) (uuid.UUID, error) {
+	ret := _m.Called(keyword, results)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertSearch")
+	}
+
+	var r0 uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) (uuid.UUID, error)); ok {
+		return rf(keyword, results)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) uuid.UUID); ok {
+		r0 = rf(keyword, results)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(keyword, results)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertSearchClick provides a mock function with given fields: searchId, productId
+func (_m *Repo) InsertSearchClick(searchId uuid.UUID, productId uuid.UUID) error {
+	ret := _m.Called(searchId, productId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertSearchClick")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(searchId, productId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertSynonyms provides a mock function with given fields: s
+func (_m *Repo) InsertSynonyms(s *models.SynonymSet) (models.SynonymSet, error) {
+	ret := _m.Called(s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertSynonyms")
+	}
+
+	var r0 models.SynonymSet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*models.SynonymSet) (models.SynonymSet, error)); ok {
+		return rf(s)
+	}
+	if rf, ok := ret.Get(0).(func(*models.SynonymSet) models.SynonymSet); ok {
+		r0 = rf(s)
+	} else {
+		r0 = ret.Get(0).(models.SynonymSet)
+	}
+
+	if rf, ok := ret.Get(1).(func(*models.SynonymSet) error); ok {
+		r1 = rf(s)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertView provides a mock function with given fields: productId, viewer
+func (_m *Repo) InsertView(productId uuid.UUID, viewer models.Viewer) error {
+	ret := _m.Called(productId, viewer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertView")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.Viewer) error); ok {
+		r0 = rf(productId, viewer)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PublishScheduled provides a mock function with given fields: ctx, now
+func (_m *Repo) PublishScheduled(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishScheduled")
+	}
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]uuid.UUID, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []uuid.UUID); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SKUExists provides a mock function with given fields: sku, exclude
+func (_m *Repo) SKUExists(sku string, exclude uuid.UUID) (bool, error) {
+	ret := _m.Called(sku, exclude)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SKUExists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, uuid.UUID) (bool, error)); ok {
+		return rf(sku, exclude)
+	}
+	if rf, ok := ret.Get(0).(func(string, uuid.UUID) bool); ok {
+		r0 = rf(sku, exclude)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, uuid.UUID) error); ok {
+		r1 = rf(sku, exclude)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveVariants provides a mock function with given fields: productId, variants
+func (_m *Repo) SaveVariants(productId uuid.UUID, variants []models.Variant) ([]models.Variant, error) {
+	ret := _m.Called(productId, variants)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveVariants")
+	}
+
+	var r0 []models.Variant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, []models.Variant) ([]models.Variant, error)); ok {
+		return rf(productId, variants)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, []models.Variant) []models.Variant); ok {
+		r0 = rf(productId, variants)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Variant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, []models.Variant) error); ok {
+		r1 = rf(productId, variants)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StreamProducts provides a mock function with given fields: fn
+func (_m *Repo) StreamProducts(fn func(*models.Product) error) error {
+	ret := _m.Called(fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamProducts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(func(*models.Product) error) error); ok {
+		r0 = rf(fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateLowStockThreshold provides a mock function with given fields: productId, threshold
+func (_m *Repo) UpdateLowStockThreshold(productId uuid.UUID, threshold int) error {
+	ret := _m.Called(productId, threshold)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateLowStockThreshold")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) error); ok {
+		r0 = rf(productId, threshold)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateProduct provides a mock function with given fields: productId, p
 func (_m *Repo) UpdateProduct(productId uuid.UUID, p *models.Product) (models.Product, error) {
 	ret := _m.Called(productId, p)
@@ -357,6 +1047,24 @@ func (_m *Repo) UpdateProduct(productId uuid.UUID, p *models.Product) (models.Pr
 	return r0, r1
 }
 
+// UpdateRatings provides a mock function with given fields: productId
+func (_m *Repo) UpdateRatings(productId uuid.UUID) error {
+	ret := _m.Called(productId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRatings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(productId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateReview provides a mock function with given fields: r
 func (_m *Repo) UpdateReview(r *models.Reviews) error {
 	ret := _m.Called(r)
@@ -375,6 +1083,52 @@ func (_m *Repo) UpdateReview(r *models.Reviews) error {
 	return r0
 }
 
+// UpdateSynonyms provides a mock function with given fields: id, s
+func (_m *Repo) UpdateSynonyms(id uuid.UUID, s *models.SynonymSet) (models.SynonymSet, error) {
+	ret := _m.Called(id, s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSynonyms")
+	}
+
+	var r0 models.SynonymSet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.SynonymSet) (models.SynonymSet, error)); ok {
+		return rf(id, s)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.SynonymSet) models.SynonymSet); ok {
+		r0 = rf(id, s)
+	} else {
+		r0 = ret.Get(0).(models.SynonymSet)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, *models.SynonymSet) error); ok {
+		r1 = rf(id, s)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpsertReviewVote provides a mock function with given fields: reviewId, userId, helpful
+func (_m *Repo) UpsertReviewVote(reviewId uuid.UUID, userId uuid.UUID, helpful bool) error {
+	ret := _m.Called(reviewId, userId, helpful)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertReviewVote")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, bool) error); ok {
+		r0 = rf(reviewId, userId, helpful)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRepo(t interface {
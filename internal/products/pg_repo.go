@@ -1,6 +1,9 @@
 package products
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/internal/models"
 )
@@ -9,11 +12,11 @@ type Repo interface {
 	// InsertProduct insert new product into the product table
 	InsertProduct(p *models.Product) (models.Product, error)
 
-	// InsertImageUrl inserts product image resource locator into the database
-	InsertImageUrl(img *models.Images) (models.Images, error)
+	// InsertImageUrls inserts the resource locators of product images into the database in one statement
+	InsertImageUrls(imgs []models.Images) ([]models.Images, error)
 
-	// FetchProductByName fetches product from the product's table by name
-	FetchProductByName(keyword string, page int) ([]models.Product, int, error)
+	// FetchProductByName fetches the page of products selected by filter and the number of products it selects
+	FetchProductByName(filter models.ProductFilter) ([]models.Product, int, error)
 
 	// FetchImageUrlById fetches image url by product id from the database
 	FetchImageUrlById(id uuid.UUID) ([]models.Images, error)
@@ -24,9 +27,23 @@ type Repo interface {
 	// FetchProductById fetches product from the product's table by id
 	FetchProductById(id uuid.UUID) (*models.Product, error)
 
+	// FetchProductsByIds fetches the products with the given ids, skipping those that do not exist
+	FetchProductsByIds(ids []uuid.UUID) ([]*models.Product, error)
+
+	// FetchImagesByProductIds fetches the images of the given products, oldest first
+	FetchImagesByProductIds(ids []uuid.UUID) ([]models.Images, error)
+
 	// DeleteImageUrlById deletes image url by id from the database
 	DeleteImageUrlById(id uuid.UUID) error
 
+	// AttachUploads claims the pending product image uploads of a user and adds them to the images of a product,
+	// returns sql.ErrNoRows when one of them cannot be claimed
+	AttachUploads(productId, userId uuid.UUID, publicIds []string) error
+
+	// DeleteImage deletes an image of a product by its public id, returns sql.ErrNoRows when the product has no
+	// such image
+	DeleteImage(productId uuid.UUID, publicId string) error
+
 	// DeleteProductById deletes product from product's table by id
 	DeleteProductById(id uuid.UUID) error
 
@@ -36,15 +53,103 @@ type Repo interface {
 	// UpdateProduct updates a product in the database by id
 	UpdateProduct(productId uuid.UUID, p *models.Product) (models.Product, error)
 
-	// InsertReview inserts a review for a product into the reviews table
+	// SKUExists reports whether a product other than exclude already uses the sku
+	SKUExists(sku string, exclude uuid.UUID) (bool, error)
+
+	// FetchProductKeys fetches the id and sku of every product, returns an error on failure
+	FetchProductKeys(ctx context.Context) (map[uuid.UUID]string, error)
+
+	// ImportProducts inserts and updates products in batches within one transaction, returns an error on failure
+	ImportProducts(ctx context.Context, inserts, updates []*models.Product) error
+
+	// BulkUpdateProducts sets the price and stock of products within one transaction, returns sql.ErrNoRows when a
+	// product does not exist
+	BulkUpdateProducts(updates []models.ProductUpdate) error
+
+	// PublishScheduled publishes the drafts whose publish time is at or before now, returns their ids
+	PublishScheduled(ctx context.Context, now time.Time) ([]uuid.UUID, error)
+
+	// StreamProducts calls fn with every product ordered by name, stops at the first error and returns it
+	StreamProducts(fn func(p *models.Product) error) error
+
+	// FetchVariants fetches the variants of a product, returns an error on failure
+	FetchVariants(productId uuid.UUID) ([]models.Variant, error)
+
+	// SaveVariants replaces the variants of a product, returns the saved variants and sql.ErrNoRows when one to update does not exist
+	SaveVariants(productId uuid.UUID, variants []models.Variant) ([]models.Variant, error)
+
+	// FetchRelatedProducts fetches up to limit products in stock related to the given ones, excluding them: those
+	// bought with them first, then those of their categories rated closest to them
+	FetchRelatedProducts(productIds []uuid.UUID, limit int) ([]models.Recommendation, error)
+
+	// InsertView records that a viewer viewed a product, or when they last did, returns sql.ErrNoRows when the
+	// product does not exist, is hidden or is not published
+	InsertView(productId uuid.UUID, viewer models.Viewer) error
+
+	// FetchRecentlyViewed fetches up to limit products a viewer viewed, the last viewed first
+	FetchRecentlyViewed(viewer models.Viewer, limit int) ([]models.Recommendation, error)
+
+	// InsertReview inserts a review for a product into the reviews table, with its images, and sets its id
 	InsertReview(r *models.Reviews) error
 
 	// UpdateReview updates reviews with changes by reviewId
 	UpdateReview(r *models.Reviews) error
 
-	// FetchReviewById fetches a product review by its ID from the database
-	FetchReviewById(productId uuid.UUID) ([]models.Reviews, error)
+	// UpdateRatings recomputes the ratings and number of reviews of a product from its reviews, returns
+	// sql.ErrNoRows when it does not exist
+	UpdateRatings(productId uuid.UUID) error
 
-	// DeleteReviewById deletes a product review by its ID
+	// FetchReviewById fetches the reviews of a product by its ID from the database, in the order of one of
+	// models.ReviewSorts, with their votes
+	FetchReviewById(productId uuid.UUID, sort string) ([]models.Reviews, error)
+
+	// UpsertReviewVote records or changes the vote of a user on a review, returns sql.ErrNoRows when the review
+	// does not exist
+	UpsertReviewVote(reviewId, userId uuid.UUID, helpful bool) error
+
+	// DeleteReviewVote withdraws the vote of a user on a review
+	DeleteReviewVote(reviewId, userId uuid.UUID) error
+
+	// FetchReviewVotes counts the votes on a review, returns sql.ErrNoRows when the review does not exist
+	FetchReviewVotes(reviewId uuid.UUID) (models.ReviewVotes, error)
+
+	// FetchReviewImages fetches the images of the given reviews, oldest first
+	FetchReviewImages(reviewIds []uuid.UUID) ([]models.ReviewImage, error)
+
+	// FetchReviewsByProductIds fetches the reviews of the given products, oldest first
+	FetchReviewsByProductIds(ids []uuid.UUID) ([]models.Reviews, error)
+
+	// DeleteReviewById deletes a product review by its ID, returns sql.ErrNoRows when it does not exist
 	DeleteReviewById(productId uuid.UUID) error
+
+	// InsertSearch records a keyword search and how many products it found, returns the id of the search
+	InsertSearch(keyword string, results int) (uuid.UUID, error)
+
+	// InsertSearchClick records a click on a product found by a search, returns sql.ErrNoRows when the search
+	// does not exist
+	InsertSearchClick(searchId, productId uuid.UUID) error
+
+	// FetchZeroResultSearches sums up the searches since the given time that found nothing per keyword, up to
+	// limit keywords searched most first
+	FetchZeroResultSearches(since time.Time, limit int) ([]models.SearchTerm, error)
+
+	// FetchSynonyms fetches every synonym set, returns an error on failure
+	FetchSynonyms() ([]models.SynonymSet, error)
+
+	// InsertSynonyms inserts a synonym set, returns the saved set
+	InsertSynonyms(s *models.SynonymSet) (models.SynonymSet, error)
+
+	// UpdateSynonyms replaces the terms of a synonym set, returns sql.ErrNoRows when it does not exist
+	UpdateSynonyms(id uuid.UUID, s *models.SynonymSet) (models.SynonymSet, error)
+
+	// DeleteSynonyms deletes a synonym set, returns sql.ErrNoRows when it does not exist
+	DeleteSynonyms(id uuid.UUID) error
+
+	// FetchLowStock fetches up to limit products and variants at or under the low-stock threshold of their
+	// product, fewest units first
+	FetchLowStock(limit int) ([]models.LowStockItem, error)
+
+	// UpdateLowStockThreshold sets the low-stock threshold of a product, returns sql.ErrNoRows when it does
+	// not exist
+	UpdateLowStockThreshold(productId uuid.UUID, threshold int) error
 }
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+)
+
+// RelatedCache is a products.Repo keeping the related products of products for TTL,
+// so busy product pages and order confirmations do not query them every time.
+// Changes to the catalog show in them once they expire.
+type RelatedCache struct {
+	products.Repo
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	related map[relatedKey]relatedEntry
+}
+
+// relatedKey is the sorted products and the limit of related products.
+type relatedKey struct {
+	productIds string
+	limit      int
+}
+
+type relatedEntry struct {
+	recs    []models.Recommendation
+	fetched time.Time
+}
+
+// NewRelatedCache returns repo with its related products cached for ttl. A ttl of 0
+// returns repo as is.
+func NewRelatedCache(repo products.Repo, ttl time.Duration) products.Repo {
+	if ttl <= 0 {
+		return repo
+	}
+
+	return &RelatedCache{
+		Repo:    repo,
+		ttl:     ttl,
+		now:     time.Now,
+		related: make(map[relatedKey]relatedEntry),
+	}
+}
+
+// FetchRelatedProducts returns the cached related products of productIds, fetching
+// them when they are older than the TTL.
+func (c *RelatedCache) FetchRelatedProducts(productIds []uuid.UUID, limit int) ([]models.Recommendation, error) {
+	key := newRelatedKey(productIds, limit)
+
+	c.mu.Lock()
+	e, ok := c.related[key]
+	c.mu.Unlock()
+	if ok && c.now().Sub(e.fetched) < c.ttl {
+		return e.recs, nil
+	}
+
+	recs, err := c.Repo.FetchRelatedProducts(productIds, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evict()
+	c.related[key] = relatedEntry{recs: recs, fetched: c.now()}
+
+	return recs, nil
+}
+
+// newRelatedKey returns the key of productIds, in any order, and limit.
+func newRelatedKey(productIds []uuid.UUID, limit int) relatedKey {
+	ids := make([]string, len(productIds))
+	for i, id := range productIds {
+		ids[i] = id.String()
+	}
+	slices.Sort(ids)
+
+	return relatedKey{productIds: strings.Join(ids, ","), limit: limit}
+}
+
+// evict drops the expired entries, so products no longer viewed do not stay cached.
+func (c *RelatedCache) evict() {
+	now := c.now()
+	for key, e := range c.related {
+		if now.Sub(e.fetched) >= c.ttl {
+			delete(c.related, key)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products/mocks"
+	"github.com/jofosuware/go/shopit/internal/products/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelatedCache(t *testing.T) {
+	ids := []uuid.UUID{uuid.New()}
+	recs := []models.Recommendation{{ProductId: uuid.New(), Name: "Tripod"}}
+
+	t.Run("Related products are fetched once per TTL", func(t *testing.T) {
+		repo := new(mocks.Repo)
+		repo.On("FetchRelatedProducts", ids, 8).Return(recs, nil).Once()
+		repo.On("FetchRelatedProducts", ids, 4).Return(recs[:0], nil).Once()
+		c := repository.NewRelatedCache(repo, time.Hour)
+
+		for i := 0; i < 3; i++ {
+			got, err := c.FetchRelatedProducts(ids, 8)
+			require.NoError(t, err)
+			assert.Equal(t, recs, got)
+		}
+		got, err := c.FetchRelatedProducts(ids, 4)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Products in any order share their related products", func(t *testing.T) {
+		a, b := uuid.New(), uuid.New()
+		repo := new(mocks.Repo)
+		repo.On("FetchRelatedProducts", []uuid.UUID{a, b}, 4).Return(recs, nil).Once()
+		c := repository.NewRelatedCache(repo, time.Hour)
+
+		_, err := c.FetchRelatedProducts([]uuid.UUID{a, b}, 4)
+		require.NoError(t, err)
+		got, err := c.FetchRelatedProducts([]uuid.UUID{b, a}, 4)
+		require.NoError(t, err)
+		assert.Equal(t, recs, got)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Expired products are fetched again", func(t *testing.T) {
+		repo := new(mocks.Repo)
+		repo.On("FetchRelatedProducts", ids, 8).Return(recs, nil).Twice()
+		c := repository.NewRelatedCache(repo, time.Millisecond)
+
+		_, err := c.FetchRelatedProducts(ids, 8)
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+		_, err = c.FetchRelatedProducts(ids, 8)
+		require.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Errors are not cached", func(t *testing.T) {
+		repo := new(mocks.Repo)
+		repo.On("FetchRelatedProducts", ids, 8).Return(nil, errors.New("db down")).Once()
+		repo.On("FetchRelatedProducts", ids, 8).Return(recs, nil).Once()
+		c := repository.NewRelatedCache(repo, time.Hour)
+
+		_, err := c.FetchRelatedProducts(ids, 8)
+		assert.Error(t, err)
+		got, err := c.FetchRelatedProducts(ids, 8)
+		require.NoError(t, err)
+		assert.Equal(t, recs, got)
+	})
+
+	t.Run("A TTL of 0 disables the cache", func(t *testing.T) {
+		repo := new(mocks.Repo)
+		assert.Same(t, repo, repository.NewRelatedCache(repo, 0))
+	})
+}
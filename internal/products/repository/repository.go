@@ -4,12 +4,42 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/driver"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/outbox"
+	"github.com/jofosuware/go/shopit/pkg/sqlb"
 )
 
+// importBatchSize is how many products one statement of an import writes.
+const importBatchSize = 500
+
+// productColumns lists the products columns in the order scanned into models.Product.
+// The currency comes after the price, so it labels the scanned price.
+const productColumns = `product_id, name, price, description, ratings, category, seller, stock,
+				num_of_reviews, user_id, created_at, coalesce(sku, ''), category_id, currency, tax_class,
+				shipping_class, hidden, low_stock_threshold, status, publish_at`
+
+// categorySubtree selects the id of the category in the ? parameter and of every
+// category below it.
+const categorySubtree = `with recursive subtree as (
+				select category_id from categories where category_id = ?
+				union all
+				select c.category_id from categories c join subtree s on c.parent_id = s.category_id
+			) select category_id from subtree`
+
+// synonymSeparator joins the terms of a synonym set in the terms column.
+const synonymSeparator = ","
+
 // ProdRepository handles product-related database operations.
 type ProdRepository struct {
 	// DB is the database connection.
@@ -23,7 +53,8 @@ func NewProdRepository(db *sql.DB) *ProdRepository {
 	}
 }
 
-// InsertProduct inserts a new product into the products table.
+// InsertProduct inserts a new product into the products table. It takes the tax
+// class, shipping class and visibility of its category.
 func (r *ProdRepository) InsertProduct(p *models.Product) (models.Product, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -32,10 +63,12 @@ func (r *ProdRepository) InsertProduct(p *models.Product) (models.Product, error
 
 	query := `
 				insert into products (name, price, description, ratings, category, seller, stock,
-				num_of_reviews, user_id, created_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-				returning product_id, name, price, description, ratings, category, seller, stock,
-				num_of_reviews, user_id, created_at
-	`
+				num_of_reviews, user_id, created_at, sku, category_id, currency, status, publish_at,
+				tax_class, shipping_class, hidden)
+				select $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15,
+				coalesce(c.tax_class, 'standard'), coalesce(c.shipping_class, 'standard'), coalesce(c.hidden, false)
+				from (select 1) as one left join categories c on c.category_id = $12
+				returning ` + productColumns
 	err := r.DB.QueryRowContext(ctx, query,
 		p.Name,
 		p.Price,
@@ -47,6 +80,11 @@ func (r *ProdRepository) InsertProduct(p *models.Product) (models.Product, error
 		p.NumOfReviews,
 		p.UserId,
 		time.Now(),
+		nullString(p.SKU),
+		p.CategoryId,
+		currency(p.Price),
+		p.Status,
+		p.PublishAt,
 	).Scan(
 		&prod.ProductId,
 		&prod.Name,
@@ -59,6 +97,15 @@ func (r *ProdRepository) InsertProduct(p *models.Product) (models.Product, error
 		&prod.NumOfReviews,
 		&prod.UserId,
 		&prod.CreatedAt,
+		&prod.SKU,
+		&prod.CategoryId,
+		&prod.Price.Currency,
+		&prod.TaxClass,
+		&prod.ShippingClass,
+		&prod.Hidden,
+		&prod.LowStockThreshold,
+		&prod.Status,
+		&prod.PublishAt,
 	)
 
 	if err != nil {
@@ -68,71 +115,111 @@ func (r *ProdRepository) InsertProduct(p *models.Product) (models.Product, error
 	return prod, nil
 }
 
-// InsertImageUrl inserts a product image record into the images table.
-func (r *ProdRepository) InsertImageUrl(img *models.Images) (models.Images, error) {
+// InsertImageUrls inserts product image records into the images table with one
+// statement, returning them in the same order.
+func (r *ProdRepository) InsertImageUrls(imgs []models.Images) ([]models.Images, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	var image models.Images
+	images := make([]models.Images, 0, len(imgs))
+	if len(imgs) == 0 {
+		return images, nil
+	}
 
-	query := `
-			insert into images (public_id, url, product_id, created_at) 
-				values ($1, $2, $3, $4) returning public_id, url, product_id, created_at
-	`
+	now := time.Now()
+	values := make([]string, len(imgs))
+	args := make([]interface{}, 0, len(imgs)*4)
+	for i, img := range imgs {
+		values[i] = placeholders(i*4, 4)
+		args = append(args, img.PublicId, img.Url, img.ProductId, now)
+	}
 
-	err := r.DB.QueryRowContext(ctx, query,
-		img.PublicId,
-		img.Url,
-		img.ProductId,
-		time.Now(),
-	).Scan(
-		&image.PublicId,
-		&image.Url,
-		&image.ProductId,
-		&image.CreatedAt,
-	)
+	// the rows of a multi-row insert are returned in the order of its values
+	query := `insert into images (public_id, url, product_id, created_at) values ` + strings.Join(values, ", ") +
+		` returning public_id, url, product_id, created_at`
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return image, err
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var image models.Images
+		if err := rows.Scan(&image.PublicId, &image.Url, &image.ProductId, &image.CreatedAt); err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return image, nil
+	return images, nil
+}
+
+// productOrders are the orders of models.ProductSorts.
+var productOrders = sqlb.Sort{
+	models.ProductsDate:  "created_at",
+	models.ProductsPrice: "price",
+	models.ProductsStock: "stock",
 }
 
-// FetchProductByName returns products filtered by name (ILIKE) with pagination.
-func (r *ProdRepository) FetchProductByName(keyword string, page int) ([]models.Product, int, error) {
+// FetchProductByName returns the page of products selected by filter, with the
+// number of products it selects over all pages. Keywords are matched with ILIKE;
+// a Sort that is not one of models.ProductSorts lists the oldest first.
+func (r *ProdRepository) FetchProductByName(filter models.ProductFilter) ([]models.Product, int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	var p []models.Product
-	var rows *sql.Rows
-	var err error
 	var count int
 
-	limit := 12
+	limit := filter.PerPage
+	if limit <= 0 {
+		limit = 12
+	}
+	page := filter.Page
+	if page < 1 {
+		page = 1
+	}
 	offset := (page - 1) * limit
 
-	err = r.DB.QueryRowContext(ctx, "select count(*) from products").Scan(&count)
+	names := make([]sqlb.Cond, len(filter.Keywords))
+	for i, k := range filter.Keywords {
+		names[i] = sqlb.Expr("name ILIKE ?", "%"+k+"%")
+	}
+
+	conds := []sqlb.Cond{sqlb.Or(names...)}
+	if !filter.Hidden {
+		conds = append([]sqlb.Cond{sqlb.Expr("not hidden and status = 'published'")}, conds...)
+	}
+	if filter.Category.Valid {
+		conds = append(conds, sqlb.Expr("category_id in ("+categorySubtree+")", filter.Category.UUID))
+	}
+	if filter.Owner.Valid {
+		conds = append(conds, sqlb.Expr("user_id = ?", filter.Owner.UUID))
+	}
+
+	query, args := sqlb.Select("count(*)").From("products").Where(conds...).Build()
+	err := r.DB.QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return p, 0, err
 	}
 
-	query := "select * from products order by created_at limit $1 offset $2"
-
-	if keyword != "" {
-		query = "select * from products where name ILIKE  $1 order by created_at limit $2 offset $3"
-		rows, err = r.DB.QueryContext(ctx, query, "%"+keyword+"%",
-			limit, offset,
-		)
-		if err != nil {
-			return p, 0, err
-		}
+	q := sqlb.Select(productColumns).From("products").Where(conds...)
+	if order, ok := productOrders.Order(filter.Sort); ok {
+		q.OrderBy(order, "product_id")
 	} else {
-		rows, err = r.DB.QueryContext(ctx, query,
-			limit, offset,
-		)
-		if err != nil {
-			return p, 0, err
-		}
+		q.OrderBy("created_at")
+	}
+
+	query, args = q.Page(limit, offset).Build()
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return p, 0, err
 	}
 
 	defer rows.Close()
@@ -152,6 +239,15 @@ func (r *ProdRepository) FetchProductByName(keyword string, page int) ([]models.
 			&prod.NumOfReviews,
 			&prod.UserId,
 			&prod.CreatedAt,
+			&prod.SKU,
+			&prod.CategoryId,
+			&prod.Price.Currency,
+			&prod.TaxClass,
+			&prod.ShippingClass,
+			&prod.Hidden,
+			&prod.LowStockThreshold,
+			&prod.Status,
+			&prod.PublishAt,
 		)
 		if err != nil {
 			return p, 0, err
@@ -211,7 +307,7 @@ func (r *ProdRepository) FetchAllProducts() ([]*models.Product, error) {
 
 	var products []*models.Product
 
-	query := "select * from products"
+	query := "select " + productColumns + " from products"
 
 	rows, err := r.DB.QueryContext(ctx, query)
 	if err != nil {
@@ -233,6 +329,15 @@ func (r *ProdRepository) FetchAllProducts() ([]*models.Product, error) {
 			&prod.NumOfReviews,
 			&prod.UserId,
 			&prod.CreatedAt,
+			&prod.SKU,
+			&prod.CategoryId,
+			&prod.Price.Currency,
+			&prod.TaxClass,
+			&prod.ShippingClass,
+			&prod.Hidden,
+			&prod.LowStockThreshold,
+			&prod.Status,
+			&prod.PublishAt,
 		)
 		if err != nil {
 			return nil, err
@@ -254,7 +359,7 @@ func (r *ProdRepository) FetchProductById(id uuid.UUID) (*models.Product, error)
 
 	var prod models.Product
 
-	query := "select * from products where product_id = $1"
+	query := "select " + productColumns + " from products where product_id = $1"
 
 	err := r.DB.QueryRowContext(ctx, query, id).Scan(
 		&prod.ProductId,
@@ -268,6 +373,15 @@ func (r *ProdRepository) FetchProductById(id uuid.UUID) (*models.Product, error)
 		&prod.NumOfReviews,
 		&prod.UserId,
 		&prod.CreatedAt,
+		&prod.SKU,
+		&prod.CategoryId,
+		&prod.Price.Currency,
+		&prod.TaxClass,
+		&prod.ShippingClass,
+		&prod.Hidden,
+		&prod.LowStockThreshold,
+		&prod.Status,
+		&prod.PublishAt,
 	)
 
 	if err != nil {
@@ -277,126 +391,313 @@ func (r *ProdRepository) FetchProductById(id uuid.UUID) (*models.Product, error)
 	return &prod, nil
 }
 
-// DeleteImageUrlById deletes image records for a product ID.
-func (r *ProdRepository) DeleteImageUrlById(id uuid.UUID) error {
+// FetchProductsByIds returns the products with the given ids, in no particular
+// order. Ids without a product are skipped.
+func (r *ProdRepository) FetchProductsByIds(ids []uuid.UUID) ([]*models.Product, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "delete from images where product_id = $1"
+	query, args := sqlb.Select(productColumns).From("products").Where(sqlb.In("product_id", ids)).Build()
 
-	_, err := r.DB.ExecContext(ctx, query, id)
+	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	return nil
+	var products []*models.Product
+	for rows.Next() {
+		var prod models.Product
+		err = rows.Scan(
+			&prod.ProductId,
+			&prod.Name,
+			&prod.Price,
+			&prod.Description,
+			&prod.Ratings,
+			&prod.Category,
+			&prod.Seller,
+			&prod.Stock,
+			&prod.NumOfReviews,
+			&prod.UserId,
+			&prod.CreatedAt,
+			&prod.SKU,
+			&prod.CategoryId,
+			&prod.Price.Currency,
+			&prod.TaxClass,
+			&prod.ShippingClass,
+			&prod.Hidden,
+			&prod.LowStockThreshold,
+			&prod.Status,
+			&prod.PublishAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, &prod)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
 }
 
-// DeleteProductById deletes a product by its ID.
-func (r *ProdRepository) DeleteProductById(id uuid.UUID) error {
+// FetchImagesByProductIds returns the images of the given products, oldest first.
+func (r *ProdRepository) FetchImagesByProductIds(ids []uuid.UUID) ([]models.Images, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "delete from products where product_id = $1"
+	query, args := sqlb.Select("public_id, url, product_id, created_at").From("images").
+		Where(sqlb.In("product_id", ids)).
+		OrderBy("created_at").
+		Build()
 
-	_, err := r.DB.ExecContext(ctx, query, id)
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var img []models.Images
+	for rows.Next() {
+		var image models.Images
+		if err := rows.Scan(&image.PublicId, &image.Url, &image.ProductId, &image.CreatedAt); err != nil {
+			return nil, err
+		}
+		img = append(img, image)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return img, nil
+}
+
+// FetchRelatedProducts returns up to limit visible products in stock related to the
+// visible productIds, which are left out. Products bought in the same orders as them
+// come first, the more orders the better; products of the same categories fill the
+// rest, those rated closest to them first. It returns none when no product of
+// productIds exists and is visible.
+func (r *ProdRepository) FetchRelatedProducts(productIds []uuid.UUID, limit int) ([]models.Recommendation, error) {
+	if len(productIds) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := make([]interface{}, 0, len(productIds)+1)
+	for _, id := range productIds {
+		args = append(args, id)
+	}
+	args = append(args, limit)
+
+	ids := placeholders(0, len(productIds))
+	query := `with viewed as (
+				select product_id, category_id, ratings from products
+				where product_id in ` + ids + ` and not hidden and status = 'published'
+			)
+			select p.product_id, p.name, p.price, p.currency, p.ratings,
+				coalesce((select i.url from images i where i.product_id = p.product_id order by i.created_at limit 1), '')
+			from products p
+			left join (
+				select oi.product_id, count(distinct oi.order_id) as orders from order_items oi
+				where oi.order_id in (select order_id from order_items where product_id in (select product_id from viewed))
+				group by oi.product_id
+			) together on together.product_id = p.product_id
+			where p.product_id not in ` + ids + ` and p.stock > 0 and not p.hidden and p.status = 'published'
+				and (together.orders is not null or p.category_id in (select category_id from viewed))
+			order by coalesce(together.orders, 0) desc, abs(p.ratings - (select avg(ratings) from viewed)),
+				p.ratings desc, p.created_at desc
+			limit $` + fmt.Sprint(len(args))
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRecommendations(rows)
+}
+
+// InsertView records that viewer viewed a visible product, or moves the time it last
+// did to now. It returns sql.ErrNoRows when the product does not exist, is hidden or is
+// not published.
+func (r *ProdRepository) InsertView(productId uuid.UUID, viewer models.Viewer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var session interface{}
+	conflict := "(user_id, product_id) where user_id is not null"
+	if !viewer.UserID.Valid {
+		session = viewer.Session
+		conflict = "(session_id, product_id) where user_id is null"
+	}
+
+	query := `insert into product_views (product_id, user_id, session_id, viewed_at)
+				select product_id, $2, $3, $4 from products where product_id = $1 and not hidden and status = 'published'
+				on conflict ` + conflict + ` do update set viewed_at = excluded.viewed_at`
+
+	res, err := r.DB.ExecContext(ctx, query, productId, viewer.UserID, session, time.Now())
 	if err != nil {
 		return err
 	}
 
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
 	return nil
 }
 
-// FetchReviews returns all product reviews.
-func (r *ProdRepository) FetchReviews() ([]models.Reviews, error) {
+// FetchRecentlyViewed returns up to limit visible products viewer viewed, the last
+// viewed first. The views of a signed in user are theirs only, not those of the
+// anonymous session they browsed with before signing in.
+func (r *ProdRepository) FetchRecentlyViewed(viewer models.Viewer, limit int) ([]models.Recommendation, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	var reviews []models.Reviews
+	var who interface{} = viewer.UserID
+	match := "v.user_id = $1"
+	if !viewer.UserID.Valid {
+		who = viewer.Session
+		match = "v.session_id = $1 and v.user_id is null"
+	}
 
-	query := "select * from reviews"
+	query := `select p.product_id, p.name, p.price, p.currency, p.ratings,
+				coalesce((select i.url from images i where i.product_id = p.product_id order by i.created_at limit 1), '')
+			from product_views v
+			join products p on p.product_id = v.product_id
+			where ` + match + ` and not p.hidden and p.status = 'published'
+			order by v.viewed_at desc
+			limit $2`
 
-	rows, err := r.DB.QueryContext(ctx, query)
+	rows, err := r.DB.QueryContext(ctx, query, who, limit)
 	if err != nil {
 		return nil, err
 	}
+
+	return scanRecommendations(rows)
+}
+
+// scanRecommendations scans and closes rows of product id, name, price, currency,
+// ratings and image.
+func scanRecommendations(rows *sql.Rows) ([]models.Recommendation, error) {
 	defer rows.Close()
 
+	var recs []models.Recommendation
 	for rows.Next() {
-		var review models.Reviews
-		err = rows.Scan(
-			&review.ReviewsId,
-			&review.Name,
-			&review.Rating,
-			&review.Comment,
-			&review.UserId,
-			&review.ProductId,
-			&review.CreatedAt,
-		)
-		if err != nil {
+		var rec models.Recommendation
+		if err := rows.Scan(&rec.ProductId, &rec.Name, &rec.Price, &rec.Price.Currency, &rec.Ratings, &rec.Image); err != nil {
 			return nil, err
 		}
-		reviews = append(reviews, review)
 
-		if err = rows.Err(); err != nil {
-			return nil, err
-		}
+		recs = append(recs, rec)
 	}
 
-	return reviews, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return recs, nil
 }
 
-// UpdateProduct updates a product by ID and returns the updated product.
-func (r *ProdRepository) UpdateProduct(productId uuid.UUID, p *models.Product) (models.Product, error) {
+// DeleteImageUrlById deletes image records for a product ID.
+func (r *ProdRepository) DeleteImageUrlById(id uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "update products set name = $1, price = $2, description = $3, ratings = $4, category = $5, seller = $6, stock = $7, num_of_reviews = $8, user_id = $9, created_at = $10 where product_id = $11 returning *"
-	args := []interface{}{p.Name, p.Price, p.Description, p.Ratings, p.Category, p.Seller, p.Stock, p.NumOfReviews, p.UserId, p.CreatedAt, productId}
+	query := "delete from images where product_id = $1"
 
-	err := r.DB.QueryRowContext(ctx, query, args...).Scan(
-		&p.ProductId,
-		&p.Name,
-		&p.Price,
-		&p.Description,
-		&p.Ratings,
-		&p.Category,
-		&p.Seller,
-		&p.Stock,
-		&p.NumOfReviews,
-		&p.UserId,
-		&p.CreatedAt,
-	)
+	_, err := r.DB.ExecContext(ctx, query, id)
 	if err != nil {
-		return models.Product{}, err
+		return err
 	}
 
-	return *p, nil
+	return nil
 }
 
-// InsertReview inserts a review for a product.
-func (r *ProdRepository) InsertReview(review *models.Reviews) error {
+// AttachUploads claims the unexpired product image uploads of a user with the given
+// public ids and inserts them as images of a product. Nothing is attached unless all
+// of them can be claimed.
+func (r *ProdRepository) AttachUploads(productId, userId uuid.UUID, publicIds []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	claim := `
+		update uploads set claimed_at = $1
+			where public_id = $2 and user_id = $3 and kind = $4 and claimed_at is null and expires_at > $1
+			returning url
+	`
+	insert := "insert into images (public_id, url, product_id, created_at) values ($1, $2, $3, $4)"
+
+	now := time.Now()
+	for _, publicId := range publicIds {
+		var url string
+		err := tx.QueryRowContext(ctx, claim, now, publicId, userId, models.UploadProductImage).Scan(&url)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, insert, publicId, url, productId, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteImage deletes the image of a product with the given public id.
+func (r *ProdRepository) DeleteImage(productId uuid.UUID, publicId string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "insert into reviews (name, ratings, comment, user_id, product_id, created_at) values ($1, $2, $3, $4, $5, $6)"
+	query := "delete from images where product_id = $1 and public_id = $2"
+
+	res, err := r.DB.ExecContext(ctx, query, productId, publicId)
+	if err != nil {
+		return err
+	}
 
-	_, err := r.DB.ExecContext(ctx, query, review.Name, review.Rating, review.Comment, review.UserId, review.ProductId, review.CreatedAt)
+	n, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
 
 	return nil
 }
 
-// UpdateReview updates an existing review.
-func (r *ProdRepository) UpdateReview(review *models.Reviews) error {
+// DeleteProductById deletes a product by its ID.
+func (r *ProdRepository) DeleteProductById(id uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "update reviews set name = $1, ratings = $2, comment = $3, user_id = $4, product_id = $5, created_at = $6 where reviews_id = $7"
+	query := "delete from products where product_id = $1"
 
-	_, err := r.DB.ExecContext(ctx, query, review.Name, review.Rating, review.Comment, review.UserId, review.ProductId, review.CreatedAt, review.ReviewsId)
+	_, err := r.DB.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -404,16 +705,16 @@ func (r *ProdRepository) UpdateReview(review *models.Reviews) error {
 	return nil
 }
 
-// FetchReviewById returns reviews for a given product ID.
-func (r *ProdRepository) FetchReviewById(productId uuid.UUID) ([]models.Reviews, error) {
+// FetchReviews returns all product reviews.
+func (r *ProdRepository) FetchReviews() ([]models.Reviews, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	var reviews []models.Reviews
 
-	query := "select * from reviews where product_id = $1"
+	query := "select * from reviews"
 
-	rows, err := r.DB.QueryContext(ctx, query, productId)
+	rows, err := r.DB.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -443,16 +744,1021 @@ func (r *ProdRepository) FetchReviewById(productId uuid.UUID) ([]models.Reviews,
 	return reviews, nil
 }
 
-// DeleteReviewById deletes a review by its ID.
-func (r *ProdRepository) DeleteReviewById(reviewId uuid.UUID) error {
+// FetchVariants fetches the variants of a product in the order they were created.
+func (r *ProdRepository) FetchVariants(productId uuid.UUID) ([]models.Variant, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "delete from reviews where reviews_id = $1"
+	query := `select v.variant_id, v.product_id, coalesce(v.sku, ''), v.attributes, v.price_delta, p.currency,
+				v.stock, v.created_at
+				from product_variants v join products p on p.product_id = v.product_id
+				where v.product_id = $1 order by v.created_at, v.variant_id`
 
-	_, err := r.DB.ExecContext(ctx, query, reviewId)
+	rows, err := r.DB.QueryContext(ctx, query, productId)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []models.Variant
+	for rows.Next() {
+		var (
+			v     models.Variant
+			attrs []byte
+		)
+		err := rows.Scan(&v.VariantId, &v.ProductId, &v.SKU, &attrs, &v.PriceDelta, &v.PriceDelta.Currency, &v.Stock,
+			&v.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(attrs, &v.Attributes); err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+// SaveVariants makes variants the variants of a product within one transaction.
+// Variants with an id are updated, those without are inserted and the product's
+// other variants are deleted; order items keep their line but lose the reference.
+// It returns sql.ErrNoRows when a variant to update is not one of the product.
+func (r *ProdRepository) SaveVariants(productId uuid.UUID, variants []models.Variant) ([]models.Variant, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// variants left out are deleted first, so the listed ones can take over their skus
+	keep := make(map[uuid.UUID]bool, len(variants))
+	for _, v := range variants {
+		keep[v.VariantId] = true
+	}
+
+	rows, err := tx.QueryContext(ctx, `select variant_id from product_variants where product_id = $1`, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if !keep[id] {
+			stale = append(stale, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, id := range stale {
+		if _, err := tx.ExecContext(ctx, `delete from product_variants where variant_id = $1`, id); err != nil {
+			return nil, err
+		}
+	}
+
+	saved := make([]models.Variant, 0, len(variants))
+	for _, v := range variants {
+		attrs, err := json.Marshal(v.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		v.ProductId = productId
+		if v.VariantId == uuid.Nil {
+			query := `insert into product_variants (product_id, sku, attributes, price_delta, stock, created_at)
+						values ($1, $2, $3, $4, $5, $6) returning variant_id, created_at`
+			err = tx.QueryRowContext(ctx, query, productId, nullString(v.SKU), attrs, v.PriceDelta, v.Stock, time.Now()).
+				Scan(&v.VariantId, &v.CreatedAt)
+		} else {
+			query := `update product_variants set sku = $1, attributes = $2, price_delta = $3, stock = $4
+						where variant_id = $5 and product_id = $6 returning created_at`
+			err = tx.QueryRowContext(ctx, query, nullString(v.SKU), attrs, v.PriceDelta, v.Stock, v.VariantId, productId).
+				Scan(&v.CreatedAt)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		saved = append(saved, v)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// UpdateProduct updates a product by ID and returns the updated product. An empty
+// status keeps the status and publish time of the product. When its price is lowered
+// in the same currency, an events.ProductPriceDropped is written to the outbox for
+// every user who wishlisted it, in the same transaction. The ratings and review count
+// are left as the reviews made them.
+func (r *ProdRepository) UpdateProduct(productId uuid.UUID, p *models.Product) (models.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Product{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var old money.Money
+	err = tx.QueryRowContext(ctx, "select price, currency from products where product_id = $1 for update", productId).
+		Scan(&old, &old.Currency)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	query := "update products set name = $1, price = $2, description = $3, category = $4, seller = $5, stock = $6, user_id = $7, created_at = $8, sku = $9, category_id = $10, currency = $11, status = coalesce($13, status), publish_at = case when $13 is null then publish_at else $14 end where product_id = $12 returning " + productColumns
+	args := []interface{}{p.Name, p.Price, p.Description, p.Category, p.Seller, p.Stock, p.UserId, p.CreatedAt, nullString(p.SKU), p.CategoryId, currency(p.Price), productId, nullString(p.Status), p.PublishAt}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
+		&p.ProductId,
+		&p.Name,
+		&p.Price,
+		&p.Description,
+		&p.Ratings,
+		&p.Category,
+		&p.Seller,
+		&p.Stock,
+		&p.NumOfReviews,
+		&p.UserId,
+		&p.CreatedAt,
+		&p.SKU,
+		&p.CategoryId,
+		&p.Price.Currency,
+		&p.TaxClass,
+		&p.ShippingClass,
+		&p.Hidden,
+		&p.LowStockThreshold,
+		&p.Status,
+		&p.PublishAt,
+	)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	if p.Price.Currency == old.Currency && p.Price.Less(old) {
+		if err := writePriceDrops(ctx, tx, *p, old); err != nil {
+			return models.Product{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Product{}, err
+	}
+
+	return *p, nil
+}
+
+// writePriceDrops writes an events.ProductPriceDropped to the outbox for every user
+// who wishlisted p, whose price was old.
+func writePriceDrops(ctx context.Context, tx *sql.Tx, p models.Product, old money.Money) error {
+	rows, err := tx.QueryContext(ctx, "select user_id from wishlist_items where product_id = $1", p.ProductId)
+	if err != nil {
+		return fmt.Errorf("error fetching wishlisting users: %v", err)
+	}
+
+	var users []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		users = append(users, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range users {
+		drop := models.PriceDrop{UserID: id, ProductID: p.ProductId, Name: p.Name, OldPrice: old, NewPrice: p.Price}
+		if err := outbox.Write(ctx, tx, events.ProductPriceDropped, drop); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SKUExists reports whether a product other than exclude already uses sku.
+func (r *ProdRepository) SKUExists(sku string, exclude uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var exists bool
+
+	query := "select exists(select 1 from products where sku = $1 and product_id <> $2)"
+
+	err := r.DB.QueryRowContext(ctx, query, sku, exclude).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// InsertReview inserts a review for a product with its images, setting the id of the
+// review on it and on its images.
+func (r *ProdRepository) InsertReview(review *models.Reviews) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `insert into reviews (name, ratings, comment, user_id, product_id, created_at) values ($1, $2, $3, $4, $5, $6)
+				returning reviews_id`
+
+	err = tx.QueryRowContext(ctx, query, review.Name, review.Rating, review.Comment, review.UserId, review.ProductId,
+		review.CreatedAt).Scan(&review.ReviewsId)
+	if err != nil {
+		return err
+	}
+
+	insert := "insert into review_images (public_id, url, review_id, created_at) values ($1, $2, $3, $4)"
+	for i := range review.Images {
+		img := &review.Images[i]
+		img.ReviewId = review.ReviewsId
+		if _, err := tx.ExecContext(ctx, insert, img.PublicId, img.Url, img.ReviewId, img.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateReview updates an existing review.
+func (r *ProdRepository) UpdateReview(review *models.Reviews) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "update reviews set name = $1, ratings = $2, comment = $3, user_id = $4, product_id = $5, created_at = $6 where reviews_id = $7"
+
+	_, err := r.DB.ExecContext(ctx, query, review.Name, review.Rating, review.Comment, review.UserId, review.ProductId, review.CreatedAt, review.ReviewsId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateRatings sets the ratings of a product to the average rating of its reviews,
+// rounded, and its number of reviews to their count, in one statement so concurrent
+// reviews cannot overwrite each other. A product without reviews is rated 0. It
+// returns sql.ErrNoRows when the product does not exist.
+func (r *ProdRepository) UpdateRatings(productId uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update products p set ratings = r.ratings, num_of_reviews = r.reviews
+				from (select coalesce(round(avg(ratings)), 0) as ratings, count(*) as reviews
+					from reviews where product_id = $1) r
+				where p.product_id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, productId)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// reviewColumns lists the reviews columns, with whether the reviewer has a delivered
+// order of the product and the votes on the review, in the order scanned into
+// models.Reviews. The status of a delivered order is its ? parameter.
+const reviewColumns = `r.reviews_id, r.name, r.ratings, r.comment, r.user_id, r.product_id, r.created_at,
+				exists (select 1 from orders o join order_items i on i.order_id = o.order_id
+					where o.user_id = r.user_id and i.product_id = r.product_id and o.order_status = ?),
+				(select count(*) from review_votes v where v.review_id = r.reviews_id and v.helpful) as helpful,
+				(select count(*) from review_votes v where v.review_id = r.reviews_id and not v.helpful) as unhelpful`
+
+// reviewOrders orders reviews by the models.ReviewSorts; equally helpful reviews go
+// newest first.
+var reviewOrders = map[string][]string{
+	models.ReviewsOldest:  {"r.created_at"},
+	models.ReviewsNewest:  {"r.created_at desc"},
+	models.ReviewsHelpful: {"helpful desc", "unhelpful", "r.created_at desc"},
+}
+
+// FetchReviewById returns reviews for a given product ID in the order of sort, one
+// of models.ReviewSorts, oldest first for any other. A review is a verified purchase
+// when its reviewer has a delivered order of the product.
+func (r *ProdRepository) FetchReviewById(productId uuid.UUID, sort string) ([]models.Reviews, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var reviews []models.Reviews
+
+	order, ok := reviewOrders[sort]
+	if !ok {
+		order = reviewOrders[models.ReviewsOldest]
+	}
+
+	query, args := sqlb.Select(reviewColumns, models.OrderDelivered).From("reviews r").
+		Where(sqlb.Expr("r.product_id = ?", productId)).
+		OrderBy(order...).
+		Build()
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var review models.Reviews
+		err = rows.Scan(
+			&review.ReviewsId,
+			&review.Name,
+			&review.Rating,
+			&review.Comment,
+			&review.UserId,
+			&review.ProductId,
+			&review.CreatedAt,
+			&review.VerifiedPurchase,
+			&review.Helpful,
+			&review.Unhelpful,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return reviews, nil
+}
+
+// FetchReviewsByProductIds returns the reviews of the given products, oldest first.
+func (r *ProdRepository) FetchReviewsByProductIds(ids []uuid.UUID) ([]models.Reviews, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query, args := sqlb.Select("reviews_id, name, ratings, comment, user_id, product_id, created_at").From("reviews").
+		Where(sqlb.In("product_id", ids)).
+		OrderBy("created_at").
+		Build()
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []models.Reviews
+	for rows.Next() {
+		var review models.Reviews
+		err := rows.Scan(
+			&review.ReviewsId,
+			&review.Name,
+			&review.Rating,
+			&review.Comment,
+			&review.UserId,
+			&review.ProductId,
+			&review.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// UpsertReviewVote records the vote of a user on a review, replacing the vote they
+// gave before. It returns sql.ErrNoRows when the review does not exist.
+func (r *ProdRepository) UpsertReviewVote(reviewId, userId uuid.UUID, helpful bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into review_votes (review_id, user_id, helpful, created_at)
+				select $1, $2, $3, $4 where exists (select 1 from reviews where reviews_id = $1)
+				on conflict (review_id, user_id) do update set helpful = excluded.helpful, created_at = excluded.created_at`
+
+	res, err := r.DB.ExecContext(ctx, query, reviewId, userId, helpful, time.Now())
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteReviewVote withdraws the vote of a user on a review. A user who has not voted
+// is left as is.
+func (r *ProdRepository) DeleteReviewVote(reviewId, userId uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "delete from review_votes where review_id = $1 and user_id = $2"
+
+	_, err := r.DB.ExecContext(ctx, query, reviewId, userId)
+	return err
+}
+
+// FetchReviewVotes counts the votes on a review. It returns sql.ErrNoRows when the
+// review does not exist.
+func (r *ProdRepository) FetchReviewVotes(reviewId uuid.UUID) (models.ReviewVotes, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select count(v.user_id) filter (where v.helpful), count(v.user_id) filter (where not v.helpful)
+				from reviews r left join review_votes v on v.review_id = r.reviews_id
+				where r.reviews_id = $1 group by r.reviews_id`
+
+	var votes models.ReviewVotes
+	err := r.DB.QueryRowContext(ctx, query, reviewId).Scan(&votes.Helpful, &votes.Unhelpful)
+	return votes, err
+}
+
+// FetchReviewImages returns the images of the given reviews, oldest first.
+func (r *ProdRepository) FetchReviewImages(reviewIds []uuid.UUID) ([]models.ReviewImage, error) {
+	if len(reviewIds) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query, args := sqlb.Select("public_id, url, review_id, created_at").From("review_images").
+		Where(sqlb.In("review_id", reviewIds)).
+		OrderBy("created_at").
+		Build()
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []models.ReviewImage
+	for rows.Next() {
+		var img models.ReviewImage
+		if err := rows.Scan(&img.PublicId, &img.Url, &img.ReviewId, &img.CreatedAt); err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// DeleteReviewById deletes a review by its ID. It returns sql.ErrNoRows when there is
+// no such review.
+func (r *ProdRepository) DeleteReviewById(reviewId uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "delete from reviews where reviews_id = $1"
+
+	res, err := r.DB.ExecContext(ctx, query, reviewId)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// FetchProductKeys fetches the id and sku of every product, the sku being "" when
+// the product has none.
+func (r *ProdRepository) FetchProductKeys(ctx context.Context) (map[uuid.UUID]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, `select product_id, coalesce(sku, '') from products`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[uuid.UUID]string)
+	for rows.Next() {
+		var (
+			id  uuid.UUID
+			sku string
+		)
+		if err := rows.Scan(&id, &sku); err != nil {
+			return nil, err
+		}
+		keys[id] = sku
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// ImportProducts inserts and updates products within one transaction, so an import is
+// applied entirely or not at all. Inserts are copied with COPY on pgx connections, or
+// else inserted importBatchSize products per statement, like updates. Inserted
+// products must have their id set. Updates only change the imported columns.
+func (r *ProdRepository) ImportProducts(ctx context.Context, inserts, updates []*models.Product) error {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	// the transaction is begun on a connection of its own, for COPY to run in it
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	err = driver.WithPgx(conn, func(c *pgx.Conn) error {
+		_, err := c.CopyFrom(ctx, pgx.Identifier{"products"}, importColumns,
+			pgx.CopyFromSlice(len(inserts), func(i int) ([]interface{}, error) {
+				return importValues(inserts[i], now), nil
+			}))
+		return err
+	})
+	if err == nil {
+		inserts = nil
+	} else if !errors.Is(err, driver.ErrNotPgx) {
+		return err
+	}
+
+	for start := 0; start < len(inserts); start += importBatchSize {
+		batch := inserts[start:min(start+importBatchSize, len(inserts))]
+
+		values := make([]string, len(batch))
+		args := make([]interface{}, 0, len(batch)*len(importColumns))
+		for i, p := range batch {
+			values[i] = placeholders(i*len(importColumns), len(importColumns))
+			args = append(args, importValues(p, now)...)
+		}
+
+		query := `insert into products (` + strings.Join(importColumns, ", ") + `) values ` + strings.Join(values, ", ")
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+
+	for start := 0; start < len(updates); start += importBatchSize {
+		batch := updates[start:min(start+importBatchSize, len(updates))]
+
+		values := make([]string, len(batch))
+		args := make([]interface{}, 0, len(batch)*10)
+		for i, p := range batch {
+			n := i * 10
+			values[i] = fmt.Sprintf("($%d::uuid, $%d::varchar, $%d::varchar, $%d::varchar, $%d::bigint, $%d::varchar, "+
+				"$%d::integer, $%d::varchar, $%d::uuid, $%d::varchar)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10)
+			args = append(args, p.ProductId, p.Name, nullString(p.SKU), p.Description, p.Price, currency(p.Price),
+				p.Stock, p.Category, p.CategoryId, p.Seller)
+		}
+
+		query := `update products p set name = v.name, sku = v.sku, description = v.description, price = v.price,
+				currency = v.currency, stock = v.stock, category = v.category, category_id = v.category_id,
+				seller = v.seller
+				from (values ` + strings.Join(values, ", ") + `) as v (product_id, name, sku, description, price,
+				currency, stock, category, category_id, seller) where p.product_id = v.product_id`
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// importColumns are the columns of the products inserted by ImportProducts.
+var importColumns = []string{"product_id", "name", "sku", "description", "price", "currency", "stock", "category",
+	"category_id", "seller", "ratings", "num_of_reviews", "user_id", "created_at"}
+
+// importValues returns the values of the importColumns of an imported product.
+func importValues(p *models.Product, now time.Time) []interface{} {
+	return []interface{}{p.ProductId, p.Name, nullString(p.SKU), p.Description, p.Price, currency(p.Price),
+		p.Stock, p.Category, p.CategoryId, p.Seller, 0, 0, p.UserId, now}
+}
+
+// BulkUpdateProducts sets the price, the stock, or both, of the product of each
+// update within one transaction, so the updates are applied entirely or not at all.
+// A lowered price writes the price drops of the product like UpdateProduct, and a
+// stock brought to the low-stock threshold or under writes an events.StockLow. It
+// returns sql.ErrNoRows when a product does not exist.
+func (r *ProdRepository) BulkUpdateProducts(updates []models.ProductUpdate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, u := range updates {
+		old := models.Product{ProductId: u.ProductId}
+		query := `select name, coalesce(sku, ''), price, currency, stock, low_stock_threshold from products
+					where product_id = $1 for update`
+		err := tx.QueryRowContext(ctx, query, u.ProductId).
+			Scan(&old.Name, &old.SKU, &old.Price, &old.Price.Currency, &old.Stock, &old.LowStockThreshold)
+		if err != nil {
+			return err
+		}
+
+		p := old
+		if u.Price != nil {
+			p.Price = *u.Price
+		}
+		if u.Stock != nil {
+			p.Stock = *u.Stock
+		}
+
+		query = "update products set price = $1, currency = $2, stock = $3 where product_id = $4"
+		if _, err := tx.ExecContext(ctx, query, p.Price, currency(p.Price), p.Stock, u.ProductId); err != nil {
+			return err
+		}
+
+		if currency(p.Price) == old.Price.Currency && p.Price.Less(old.Price) {
+			if err := writePriceDrops(ctx, tx, p, old.Price); err != nil {
+				return err
+			}
+		}
+
+		// alert once, as the stock crosses the threshold
+		if p.Stock <= p.LowStockThreshold && old.Stock > p.LowStockThreshold {
+			item := models.LowStockItem{ProductID: p.ProductId, Name: p.Name, SKU: p.SKU, Stock: p.Stock,
+				Threshold: p.LowStockThreshold}
+			if err := outbox.Write(ctx, tx, events.StockLow, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// PublishScheduled publishes the drafts whose publish time is at or before now and
+// returns their ids. The publish time is kept as when they were published.
+func (r *ProdRepository) PublishScheduled(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `update products set status = $1 where status = $2 and publish_at <= $3 returning product_id`
+
+	rows, err := r.DB.QueryContext(ctx, query, models.ProductPublished, models.ProductDraft, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// StreamProducts calls fn with every product ordered by name, without loading the
+// catalog in memory. It stops at the first error of fn and returns it.
+func (r *ProdRepository) StreamProducts(fn func(p *models.Product) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, "select "+productColumns+" from products order by name, product_id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.Product
+		err := rows.Scan(
+			&p.ProductId,
+			&p.Name,
+			&p.Price,
+			&p.Description,
+			&p.Ratings,
+			&p.Category,
+			&p.Seller,
+			&p.Stock,
+			&p.NumOfReviews,
+			&p.UserId,
+			&p.CreatedAt,
+			&p.SKU,
+			&p.CategoryId,
+			&p.Price.Currency,
+			&p.TaxClass,
+			&p.ShippingClass,
+			&p.Hidden,
+			&p.LowStockThreshold,
+			&p.Status,
+			&p.PublishAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(&p); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// InsertSearch records a keyword search and the number of products it found.
+func (r *ProdRepository) InsertSearch(keyword string, results int) (uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into search_events (event, keyword, results, created_at) values ($1, $2, $3, $4)
+				returning event_id`
+
+	var id uuid.UUID
+	err := r.DB.QueryRowContext(ctx, query, models.SearchEventSearch, keyword, results, time.Now()).Scan(&id)
+
+	return id, err
+}
+
+// InsertSearchClick records a click on a product found by the search searchId, under
+// the keyword of the search.
+func (r *ProdRepository) InsertSearchClick(searchId, productId uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into search_events (event, keyword, search_id, product_id, created_at)
+				select $1, keyword, event_id, $2, $3 from search_events where event_id = $4 and event = $5`
+
+	res, err := r.DB.ExecContext(ctx, query, models.SearchEventClick, productId, time.Now(), searchId, models.SearchEventSearch)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// FetchZeroResultSearches counts, per keyword, the searches made since the given time
+// that found no product, keeping the limit keywords searched most.
+func (r *ProdRepository) FetchZeroResultSearches(since time.Time, limit int) ([]models.SearchTerm, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := `select keyword, count(*), max(created_at) from search_events
+				where event = $1 and results = 0 and created_at >= $2
+				group by keyword
+				order by count(*) desc, max(created_at) desc
+				limit $3`
+
+	rows, err := r.DB.QueryContext(ctx, query, models.SearchEventSearch, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var terms []models.SearchTerm
+	for rows.Next() {
+		var t models.SearchTerm
+		if err := rows.Scan(&t.Keyword, &t.Searches, &t.LastSearchedAt); err != nil {
+			return nil, err
+		}
+		terms = append(terms, t)
+	}
+
+	return terms, rows.Err()
+}
+
+// FetchSynonyms fetches every synonym set, oldest first.
+func (r *ProdRepository) FetchSynonyms() ([]models.SynonymSet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select synonym_id, terms, created_at, updated_at from search_synonyms order by created_at`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sets []models.SynonymSet
+	for rows.Next() {
+		var s models.SynonymSet
+		var terms string
+		if err := rows.Scan(&s.ID, &terms, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		s.Terms = strings.Split(terms, synonymSeparator)
+		sets = append(sets, s)
+	}
+
+	return sets, rows.Err()
+}
+
+// InsertSynonyms inserts a synonym set.
+func (r *ProdRepository) InsertSynonyms(s *models.SynonymSet) (models.SynonymSet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into search_synonyms (terms, created_at, updated_at) values ($1, $2, $3)
+				returning synonym_id, created_at, updated_at`
+
+	set := models.SynonymSet{Terms: s.Terms}
+	now := time.Now()
+	err := r.DB.QueryRowContext(ctx, query, strings.Join(s.Terms, synonymSeparator), now, now).
+		Scan(&set.ID, &set.CreatedAt, &set.UpdatedAt)
+
+	return set, err
+}
+
+// UpdateSynonyms replaces the terms of the synonym set id.
+func (r *ProdRepository) UpdateSynonyms(id uuid.UUID, s *models.SynonymSet) (models.SynonymSet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update search_synonyms set terms = $1, updated_at = $2 where synonym_id = $3
+				returning synonym_id, created_at, updated_at`
+
+	set := models.SynonymSet{Terms: s.Terms}
+	err := r.DB.QueryRowContext(ctx, query, strings.Join(s.Terms, synonymSeparator), time.Now(), id).
+		Scan(&set.ID, &set.CreatedAt, &set.UpdatedAt)
+
+	return set, err
+}
+
+// DeleteSynonyms deletes the synonym set id.
+func (r *ProdRepository) DeleteSynonyms(id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.DB.ExecContext(ctx, "delete from search_synonyms where synonym_id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// placeholders returns "($from+1, ..., $from+n)".
+func placeholders(from, n int) string {
+	ph := make([]string, n)
+	for i := range ph {
+		ph[i] = fmt.Sprintf("$%d", from+i+1)
+	}
+	return "(" + strings.Join(ph, ", ") + ")"
+}
+
+// currency returns the currency of a price, the shop currency when it has none.
+func currency(price money.Money) string {
+	if price.Currency == "" {
+		return money.DefaultCurrency
+	}
+	return price.Currency
+}
+
+// nullString stores an empty string as NULL, so that products without a SKU
+// don't collide on the unique constraint.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// FetchLowStock fetches up to limit products, and variants, with at most the low-stock
+// threshold of their product left, fewest units first. A product with variants is
+// listed by its variants.
+func (r *ProdRepository) FetchLowStock(limit int) ([]models.LowStockItem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select p.product_id, v.variant_id, p.name, coalesce(v.sku, p.sku, ''), coalesce(v.stock, p.stock),
+				p.low_stock_threshold
+				from products p
+				left join product_variants v on (v.product_id = p.product_id)
+				where coalesce(v.stock, p.stock) <= p.low_stock_threshold
+				order by 5, 3
+				limit $1`
+
+	rows, err := r.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.LowStockItem{}
+	for rows.Next() {
+		var i models.LowStockItem
+		if err := rows.Scan(&i.ProductID, &i.VariantID, &i.Name, &i.SKU, &i.Stock, &i.Threshold); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+
+	return items, rows.Err()
+}
+
+// UpdateLowStockThreshold sets the low-stock threshold of a product. It returns
+// sql.ErrNoRows when there is no such product.
+func (r *ProdRepository) UpdateLowStockThreshold(productId uuid.UUID, threshold int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.DB.ExecContext(ctx, `update products set low_stock_threshold = $1 where product_id = $2`,
+		threshold, productId)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
 	}
 
 	return nil
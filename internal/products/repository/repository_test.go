@@ -1,6 +1,8 @@
 package repository_test
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
@@ -9,6 +11,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/internal/models"
 	"github.com/jofosuware/go/shopit/internal/products/repository"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -23,7 +27,7 @@ func TestInsertProduct(t *testing.T) {
 
 	p := models.Product{
 		Name:         "Test Product",
-		Price:        2000,
+		Price:        money.Of(2000),
 		Description:  "Test description",
 		Ratings:      1,
 		Category:     "Home",
@@ -35,19 +39,23 @@ func TestInsertProduct(t *testing.T) {
 
 	query := `
 				insert into products \(name, price, description, ratings, category, seller, stock,
-				num_of_reviews, user_id, created_at\) values \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, \$9, \$10\)
+				num_of_reviews, user_id, created_at, sku, category_id, currency, status, publish_at,
+				tax_class, shipping_class, hidden\)
+				select \$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, \$9, \$10, \$11, \$12, \$13, \$14, \$15,
+				coalesce\(c.tax_class, 'standard'\), coalesce\(c.shipping_class, 'standard'\), coalesce\(c.hidden, false\)
+				from \(select 1\) as one left join categories c on c.category_id = \$12
 				returning product_id, name, price, description, ratings, category, seller, stock,
-				num_of_reviews, user_id, created_at
-	`
+				num_of_reviews, user_id, created_at, coalesce\(sku, ''\), category_id, currency, tax_class,
+				shipping_class, hidden, low_stock_threshold, status, publish_at`
 	t.Run("test product insertion successful", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller",
-			"stock", "num_of_reviews", "user_id", "created_at",
+			"stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at",
 		}).AddRow(uuid.UUID{}, p.Name, p.Price, p.Description, p.Ratings, p.Category, p.Seller, p.Stock, p.NumOfReviews, p.UserId,
-			time.Now(),
+			time.Now(), "", nil, "USD", "standard", "standard", false, 5, "published", nil,
 		)
 
 		mock.ExpectQuery(query).WithArgs(p.Name, p.Price, p.Description, p.Ratings, p.Category, p.Seller, p.Stock, p.NumOfReviews, p.UserId,
-			sqlmock.AnyArg()).WillReturnRows(rows)
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "USD", p.Status, p.PublishAt).WillReturnRows(rows)
 
 		result, err := repo.InsertProduct(&p)
 		require.NoError(t, err)
@@ -57,14 +65,14 @@ func TestInsertProduct(t *testing.T) {
 
 	t.Run("test product insertion failure", func(t *testing.T) {
 		mock.ExpectQuery(query).WithArgs(p.Name, p.Price, p.Description, p.Ratings, p.Category, p.Seller, p.Stock, p.NumOfReviews, p.UserId,
-			sqlmock.AnyArg()).WillReturnError(errors.New("database error"))
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "USD", p.Status, p.PublishAt).WillReturnError(errors.New("database error"))
 
 		_, err := repo.InsertProduct(&p)
 		assert.Error(t, err)
 	})
 }
 
-func TestInsertImageUrl(t *testing.T) {
+func TestInsertImageUrls(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 
@@ -73,37 +81,42 @@ func TestInsertImageUrl(t *testing.T) {
 	repo := repository.NewProdRepository(db)
 	var today = time.Now()
 
-	img := models.Images{
-		PublicId:  "publicId",
-		Url:       "www.testing.com",
-		ProductId: uuid.UUID{},
-		CreatedAt: today,
+	productId := uuid.New()
+	imgs := []models.Images{
+		{PublicId: "publicId1", Url: "www.testing.com/1", ProductId: productId, CreatedAt: today},
+		{PublicId: "publicId2", Url: "www.testing.com/2", ProductId: productId, CreatedAt: today},
 	}
 
-	query := `
-			insert into images \(public_id, url, product_id, created_at\) 
-				values \(\$1, \$2, \$3, \$4\) returning public_id, url, product_id, created_at
-	`
+	query := `insert into images \(public_id, url, product_id, created_at\) values \(\$1, \$2, \$3, \$4\), ` +
+		`\(\$5, \$6, \$7, \$8\) returning public_id, url, product_id, created_at`
 	t.Run("Test image insertion successful", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"public_id", "url", "product_id", "created_at"}).AddRow(
-			img.PublicId, img.Url, img.ProductId, today,
-		)
+		rows := sqlmock.NewRows([]string{"public_id", "url", "product_id", "created_at"})
+		for _, img := range imgs {
+			rows.AddRow(img.PublicId, img.Url, img.ProductId, today)
+		}
 
-		mock.ExpectQuery(query).WithArgs(img.PublicId, img.Url, img.ProductId, sqlmock.AnyArg()).WillReturnRows(rows)
+		mock.ExpectQuery(query).WithArgs(imgs[0].PublicId, imgs[0].Url, productId, sqlmock.AnyArg(),
+			imgs[1].PublicId, imgs[1].Url, productId, sqlmock.AnyArg()).WillReturnRows(rows)
 
-		result, err := repo.InsertImageUrl(&img)
+		result, err := repo.InsertImageUrls(imgs)
 		require.NoError(t, err)
 
-		assert.Equal(t, img, result)
+		assert.Equal(t, imgs, result)
 	})
 
 	t.Run("Test image insertion failed", func(t *testing.T) {
-		mock.ExpectQuery(query).WithArgs(img.PublicId, img.Url, img.ProductId, sqlmock.AnyArg()).
-			WillReturnError(errors.New("database error"))
+		mock.ExpectQuery(query).WillReturnError(errors.New("database error"))
 
-		_, err := repo.InsertImageUrl(&img)
+		_, err := repo.InsertImageUrls(imgs)
 		assert.Error(t, err)
 	})
+
+	t.Run("No images", func(t *testing.T) {
+		result, err := repo.InsertImageUrls(nil)
+		require.NoError(t, err)
+		assert.Empty(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestFetchProductByName(t *testing.T) {
@@ -116,13 +129,13 @@ func TestFetchProductByName(t *testing.T) {
 
 	t.Run("Success without keyword", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"count"}).AddRow(1)
-		mock.ExpectQuery("select count\\(\\*\\) from products").WillReturnRows(rows)
+		mock.ExpectQuery("select count\\(\\*\\) from products where not hidden and status = 'published'").WillReturnRows(rows)
 
-		productRows := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at"}).
-			AddRow(uuid.UUID{}, "Test Product", 100.00, "Test Description", 4, "Test Category", "Test Seller", 10, 5, uuid.UUID{}, time.Now())
-		mock.ExpectQuery("select \\* from products order by created_at limit").WithArgs(12, 0).WillReturnRows(productRows)
+		productRows := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}).
+			AddRow(uuid.UUID{}, "Test Product", 10000, "Test Description", 4, "Test Category", "Test Seller", 10, 5, uuid.UUID{}, time.Now(), "", nil, "USD", "standard", "standard", false, 5, "published", nil)
+		mock.ExpectQuery("select product_id, .* from products where not hidden and status = 'published' order by created_at limit").WithArgs(12, 0).WillReturnRows(productRows)
 
-		products, count, err := repo.FetchProductByName("", 1)
+		products, count, err := repo.FetchProductByName(models.ProductFilter{Page: 1})
 		assert.NoError(t, err)
 		assert.Len(t, products, 1)
 		assert.Equal(t, 1, count)
@@ -131,22 +144,84 @@ func TestFetchProductByName(t *testing.T) {
 	t.Run("Success with keyword", func(t *testing.T) {
 		keyword := "Test"
 		rows := sqlmock.NewRows([]string{"count"}).AddRow(1)
-		mock.ExpectQuery("select count\\(\\*\\) from products").WillReturnRows(rows)
+		mock.ExpectQuery("select count\\(\\*\\) from products where not hidden and status = 'published'").WillReturnRows(rows)
 
-		productRows := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at"}).
-			AddRow(uuid.UUID{}, "Test Product", 100.00, "Test Description", 4, "Test Category", "Test Seller", 10, 5, uuid.UUID{}, time.Now())
-		mock.ExpectQuery("select \\* from products where name ILIKE").WithArgs("%"+keyword+"%", 12, 0).WillReturnRows(productRows)
+		productRows := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}).
+			AddRow(uuid.UUID{}, "Test Product", 10000, "Test Description", 4, "Test Category", "Test Seller", 10, 5, uuid.UUID{}, time.Now(), "", nil, "USD", "standard", "standard", false, 5, "published", nil)
+		mock.ExpectQuery("select product_id, .* from products where not hidden and status = 'published' and \\(name ILIKE \\$1\\)").WithArgs("%"+keyword+"%", 12, 0).WillReturnRows(productRows)
 
-		products, count, err := repo.FetchProductByName(keyword, 1)
+		products, count, err := repo.FetchProductByName(models.ProductFilter{Keywords: []string{keyword}, Page: 1})
 		assert.NoError(t, err)
 		assert.Len(t, products, 1)
 		assert.Equal(t, 1, count)
 	})
 
+	t.Run("Success with keyword and synonyms", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"count"}).AddRow(1)
+		mock.ExpectQuery("select count\\(\\*\\) from products where not hidden and status = 'published'").WillReturnRows(rows)
+
+		productRows := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}).
+			AddRow(uuid.UUID{}, "Running Trainers", 10000, "Test Description", 4, "Test Category", "Test Seller", 10, 5, uuid.UUID{}, time.Now(), "", nil, "USD", "standard", "standard", false, 5, "published", nil)
+		mock.ExpectQuery("select product_id, .* from products where not hidden and status = 'published' and \\(name ILIKE \\$1 or name ILIKE \\$2\\) order by created_at limit \\$3 offset \\$4").
+			WithArgs("%sneakers%", "%trainers%", 12, 0).WillReturnRows(productRows)
+
+		products, _, err := repo.FetchProductByName(models.ProductFilter{Keywords: []string{"sneakers", "trainers"}, Page: 1})
+		assert.NoError(t, err)
+		assert.Len(t, products, 1)
+	})
+
+	t.Run("Success with keyword and category", func(t *testing.T) {
+		category := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+		rows := sqlmock.NewRows([]string{"count"}).AddRow(1)
+		mock.ExpectQuery("select count\\(\\*\\) from products where not hidden and status = 'published'").WillReturnRows(rows)
+
+		productRows := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}).
+			AddRow(uuid.UUID{}, "Test Product", 10000, "Test Description", 4, "Test Category", "Test Seller", 10, 5, uuid.UUID{}, time.Now(), "", category.UUID, "USD", "standard", "standard", false, 5, "published", nil)
+		mock.ExpectQuery("select product_id, .* from products where not hidden and status = 'published' and \\(name ILIKE \\$1\\) and category_id in \\(with recursive subtree as .* where category_id = \\$2 .*\\) order by created_at limit \\$3 offset \\$4").
+			WithArgs("%Test%", category.UUID, 12, 0).WillReturnRows(productRows)
+
+		products, _, err := repo.FetchProductByName(models.ProductFilter{Keywords: []string{"Test"}, Category: category, Page: 1})
+		assert.NoError(t, err)
+		require.Len(t, products, 1)
+		assert.Equal(t, category, products[0].CategoryId)
+	})
+
+	t.Run("Success with hidden products sorted", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"count"}).AddRow(30)
+		mock.ExpectQuery("select count\\(\\*\\) from products where \\(name ILIKE \\$1\\)$").WithArgs("%lens%").WillReturnRows(rows)
+
+		productRows := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}).
+			AddRow(uuid.UUID{}, "Lens", 10000, "Test Description", 4, "Test Category", "Test Seller", 10, 5, uuid.UUID{}, time.Now(), "", nil, "USD", "standard", "standard", true, 5, "published", nil)
+		mock.ExpectQuery("select product_id, .* from products where \\(name ILIKE \\$1\\) order by stock desc, product_id limit \\$2 offset \\$3").
+			WithArgs("%lens%", 20, 20).WillReturnRows(productRows)
+
+		products, count, err := repo.FetchProductByName(models.ProductFilter{Keywords: []string{"lens"}, Page: 2, PerPage: 20, Sort: "-stock", Hidden: true})
+		assert.NoError(t, err)
+		require.Len(t, products, 1)
+		assert.True(t, products[0].Hidden)
+		assert.Equal(t, 30, count)
+	})
+
+	t.Run("Success with the products of an owner", func(t *testing.T) {
+		owner := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+		rows := sqlmock.NewRows([]string{"count"}).AddRow(1)
+		mock.ExpectQuery("select count\\(\\*\\) from products where user_id = \\$1$").WithArgs(owner.UUID).WillReturnRows(rows)
+
+		productRows := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}).
+			AddRow(uuid.UUID{}, "Lens", 10000, "Test Description", 4, "Test Category", "Test Seller", 10, 5, owner.UUID, time.Now(), "", nil, "USD", "standard", "standard", false, 5, "draft", nil)
+		mock.ExpectQuery("select product_id, .* from products where user_id = \\$1 order by created_at limit \\$2 offset \\$3").
+			WithArgs(owner.UUID, 20, 0).WillReturnRows(productRows)
+
+		products, _, err := repo.FetchProductByName(models.ProductFilter{Owner: owner, Page: 1, PerPage: 20, Hidden: true})
+		assert.NoError(t, err)
+		require.Len(t, products, 1)
+		assert.Equal(t, owner.UUID, products[0].UserId)
+	})
+
 	t.Run("Failure on count query", func(t *testing.T) {
-		mock.ExpectQuery("select count\\(\\*\\) from products").WillReturnError(errors.New("error"))
+		mock.ExpectQuery("select count\\(\\*\\) from products where not hidden and status = 'published'").WillReturnError(errors.New("error"))
 
-		products, count, err := repo.FetchProductByName("", 1)
+		products, count, err := repo.FetchProductByName(models.ProductFilter{Page: 1})
 		assert.Error(t, err)
 		assert.Nil(t, products)
 		assert.Equal(t, 0, count)
@@ -154,11 +229,11 @@ func TestFetchProductByName(t *testing.T) {
 
 	t.Run("Failure on product query", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"count"}).AddRow(1)
-		mock.ExpectQuery("select count\\(\\*\\) from products").WillReturnRows(rows)
+		mock.ExpectQuery("select count\\(\\*\\) from products where not hidden and status = 'published'").WillReturnRows(rows)
 
-		mock.ExpectQuery("select \\* from products order by created_at limit").WithArgs(12, 0).WillReturnError(errors.New("error"))
+		mock.ExpectQuery("select product_id, .* from products where not hidden and status = 'published' order by created_at limit").WithArgs(12, 0).WillReturnError(errors.New("error"))
 
-		products, count, err := repo.FetchProductByName("", 1)
+		products, count, err := repo.FetchProductByName(models.ProductFilter{Page: 1})
 		assert.Error(t, err)
 		assert.Nil(t, products)
 		assert.Equal(t, 0, count)
@@ -212,11 +287,11 @@ func TestFetchAllProducts(t *testing.T) {
 
 	repo := repository.NewProdRepository(db)
 
-	query := "select \\* from products"
+	query := "select product_id, .* from products"
 
 	t.Run("Successful fetch", func(t *testing.T) {
-		row := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at"}).
-			AddRow(uuid.UUID{}, "Test Product", 100.00, "Test Description", 4, "Test Category", "Test Seller", 10, 5, uuid.UUID{}, time.Now())
+		row := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}).
+			AddRow(uuid.UUID{}, "Test Product", 10000, "Test Description", 4, "Test Category", "Test Seller", 10, 5, uuid.UUID{}, time.Now(), "", nil, "USD", "standard", "standard", false, 5, "published", nil)
 
 		mock.ExpectQuery(query).WillReturnRows(row)
 
@@ -235,11 +310,11 @@ func TestFetchProductById(t *testing.T) {
 
 	repo := repository.NewProdRepository(db)
 
-	query := "select \\* from products where product_id = \\$1"
+	query := "select product_id, .* from products where product_id = \\$1"
 
 	t.Run("Successful fetch", func(t *testing.T) {
-		row := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at"}).
-			AddRow(uuid.UUID{}, "Test Product", 100.00, "Test Description", 4, "Test Category", "Test Seller", 10, 5, uuid.UUID{}, time.Now())
+		row := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}).
+			AddRow(uuid.UUID{}, "Test Product", 10000, "Test Description", 4, "Test Category", "Test Seller", 10, 5, uuid.UUID{}, time.Now(), "", nil, "USD", "standard", "standard", false, 5, "published", nil)
 
 		mock.ExpectQuery(query).WithArgs(uuid.UUID{}).WillReturnRows(row)
 
@@ -250,6 +325,48 @@ func TestFetchProductById(t *testing.T) {
 	})
 }
 
+func TestFetchProductsByIds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+	a, b := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`select product_id, .* from products where product_id in \(\$1, \$2\)`).
+		WithArgs(a, b).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}).
+			AddRow(b, "Lens", 19900, "Wide angle", 4, "Cameras", "Kofi", 3, 1, uuid.New(), time.Now(), "", nil, "USD", "standard", "standard", false, 5, "published", nil))
+
+	prods, err := repo.FetchProductsByIds([]uuid.UUID{a, b})
+	require.NoError(t, err)
+	require.Len(t, prods, 1)
+	assert.Equal(t, b, prods[0].ProductId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	prods, err = repo.FetchProductsByIds(nil)
+	require.NoError(t, err)
+	assert.Empty(t, prods)
+}
+
+func TestFetchImagesByProductIds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	a, b := uuid.New(), uuid.New()
+	mock.ExpectQuery(`select public_id, url, product_id, created_at from images\s+where product_id in \(\$1, \$2\) order by created_at`).
+		WithArgs(a, b).
+		WillReturnRows(sqlmock.NewRows([]string{"public_id", "url", "product_id", "created_at"}).
+			AddRow("tripod", "https://img.example.com/tripod.jpg", a, time.Now()))
+
+	img, err := repository.NewProdRepository(db).FetchImagesByProductIds([]uuid.UUID{a, b})
+	require.NoError(t, err)
+	require.Len(t, img, 1)
+	assert.Equal(t, a, img[0].ProductId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeleteImageUrlById(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -316,27 +433,84 @@ func TestUpdateProduct(t *testing.T) {
 
 	repo := repository.NewProdRepository(db)
 
-	query := "update products set name = \\$1, price = \\$2, description = \\$3, ratings = \\$4, category = \\$5, seller = \\$6, stock = \\$7, num_of_reviews = \\$8, user_id = \\$9, created_at = \\$10 where product_id = \\$11 returning \\*"
+	query := "update products set name = \\$1, price = \\$2, description = \\$3, category = \\$4, seller = \\$5, stock = \\$6, user_id = \\$7, created_at = \\$8, sku = \\$9, category_id = \\$10, currency = \\$11, status = coalesce\\(\\$13, status\\), publish_at = case when \\$13 is null then publish_at else \\$14 end where product_id = \\$12 returning product_id, .*"
 	product := &models.Product{
 		ProductId:   uuid.UUID{},
 		Name:        "Test Product",
-		Price:       100.00,
+		Price:       money.Of(10000),
 		Description: "Test Description",
 		Category:    "Test Category",
 		Seller:      "Test Seller",
 		Stock:       10,
 	}
 
-	t.Run("Successful update", func(t *testing.T) {
-		row := sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at"}).
-			AddRow(product.ProductId, product.Name, product.Price, product.Description, product.Ratings, product.Category, product.Seller, product.Stock, product.NumOfReviews, product.UserId, product.CreatedAt)
+	oldPrice := "select price, currency from products where product_id = \\$1 for update"
+	row := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}).
+			AddRow(product.ProductId, product.Name, product.Price, product.Description, product.Ratings, product.Category, product.Seller, product.Stock, product.NumOfReviews, product.UserId, product.CreatedAt, "", nil, "USD", "standard", "standard", false, 5, "published", nil)
+	}
 
-		mock.ExpectQuery(query).WithArgs(product.Name, product.Price, product.Description, product.Ratings, product.Category, product.Seller, product.Stock, product.NumOfReviews, product.UserId, product.CreatedAt, product.ProductId).WillReturnRows(row)
+	t.Run("Successful update", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(oldPrice).WithArgs(product.ProductId).
+			WillReturnRows(sqlmock.NewRows([]string{"price", "currency"}).AddRow(10000, "USD"))
+		mock.ExpectQuery(query).WithArgs(product.Name, product.Price, product.Description, product.Category, product.Seller, product.Stock, product.UserId, product.CreatedAt, sqlmock.AnyArg(), product.CategoryId, "USD", product.ProductId, nil, nil).WillReturnRows(row())
+		mock.ExpectCommit()
 
 		prod, err := repo.UpdateProduct(product.ProductId, product)
 		assert.NoError(t, err)
 
 		assert.NotNil(t, prod)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ratings are left to the reviews", func(t *testing.T) {
+		stale := *product
+		stale.Ratings, stale.NumOfReviews = 1, 1
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(oldPrice).WithArgs(product.ProductId).
+			WillReturnRows(sqlmock.NewRows([]string{"price", "currency"}).AddRow(10000, "USD"))
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"product_id", "name", "price", "description", "ratings", "category", "seller", "stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}).
+			AddRow(product.ProductId, product.Name, product.Price, product.Description, 4, product.Category, product.Seller, product.Stock, 12, product.UserId, product.CreatedAt, "", nil, "USD", "standard", "standard", false, 5, "published", nil))
+		mock.ExpectCommit()
+
+		prod, err := repo.UpdateProduct(product.ProductId, &stale)
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, prod.Ratings)
+		assert.Equal(t, 12, prod.NumOfReviews)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Price drop is written to the outbox for wishlisting users", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(oldPrice).WithArgs(product.ProductId).
+			WillReturnRows(sqlmock.NewRows([]string{"price", "currency"}).AddRow(12500, "USD"))
+		mock.ExpectQuery(query).WillReturnRows(row())
+		mock.ExpectQuery("select user_id from wishlist_items where product_id = \\$1").WithArgs(product.ProductId).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(uuid.New()).AddRow(uuid.New()))
+		mock.ExpectExec("insert into outbox").WithArgs(events.ProductPriceDropped, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("insert into outbox").WithArgs(events.ProductPriceDropped, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err := repo.UpdateProduct(product.ProductId, product)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Price raise notifies no one", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(oldPrice).WithArgs(product.ProductId).
+			WillReturnRows(sqlmock.NewRows([]string{"price", "currency"}).AddRow(9000, "USD"))
+		mock.ExpectQuery(query).WillReturnRows(row())
+		mock.ExpectCommit()
+
+		_, err := repo.UpdateProduct(product.ProductId, product)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -346,23 +520,48 @@ func TestInsertReview(t *testing.T) {
 
 	repo := repository.NewProdRepository(db)
 
-	query := "insert into reviews \\(name, ratings, comment, user_id, product_id, created_at\\) values \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6\\)"
+	query := "insert into reviews \\(name, ratings, comment, user_id, product_id, created_at\\) values \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6\\)\\s+returning reviews_id"
+	insertImage := "insert into review_images \\(public_id, url, review_id, created_at\\) values \\(\\$1, \\$2, \\$3, \\$4\\)"
 
-	review := &models.Reviews{
-		Name:      "Test Name",
-		Rating:    4,
-		Comment:   "Test Comment",
-		UserId:    uuid.UUID{},
-		ProductId: uuid.UUID{},
-		CreatedAt: time.Now(),
-	}
+	reviewId := uuid.New()
 
 	t.Run("Successful insert", func(t *testing.T) {
-		mock.ExpectExec(query).WithArgs(review.Name, review.Rating, review.Comment, review.UserId, review.ProductId, review.CreatedAt).WillReturnResult(sqlmock.NewResult(1, 1))
+		review := &models.Reviews{
+			Name:      "Test Name",
+			Rating:    4,
+			Comment:   "Test Comment",
+			UserId:    uuid.UUID{},
+			ProductId: uuid.UUID{},
+			CreatedAt: time.Now(),
+			Images:    []models.ReviewImage{{PublicId: "reviews/a", Url: "https://img/a.png"}},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).WithArgs(review.Name, review.Rating, review.Comment, review.UserId, review.ProductId, review.CreatedAt).
+			WillReturnRows(sqlmock.NewRows([]string{"reviews_id"}).AddRow(reviewId))
+		mock.ExpectExec(insertImage).WithArgs("reviews/a", "https://img/a.png", reviewId, time.Time{}).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		err := repo.InsertReview(review)
-		assert.NoError(t, err)
+		require.NoError(t, err)
+
+		assert.Equal(t, reviewId, review.ReviewsId)
+		assert.Equal(t, reviewId, review.Images[0].ReviewId)
 	})
+
+	t.Run("Review is not saved when an image is not", func(t *testing.T) {
+		review := &models.Reviews{Name: "Test Name", Images: []models.ReviewImage{{PublicId: "reviews/a"}}}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"reviews_id"}).AddRow(reviewId))
+		mock.ExpectExec(insertImage).WillReturnError(errors.New("error"))
+		mock.ExpectRollback()
+
+		assert.Error(t, repo.InsertReview(review))
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestUpdateReview(t *testing.T) {
@@ -397,7 +596,14 @@ func TestFetchReviewById(t *testing.T) {
 
 	repo := repository.NewProdRepository(db)
 
-	query := "select \\* from reviews where product_id = \\$1"
+	query := `select r.reviews_id, .* exists \(select 1 from orders o join order_items i on i.order_id = o.order_id
+					where o.user_id = r.user_id and i.product_id = r.product_id and o.order_status = \$1\),
+				\(select count\(\*\) from review_votes v where v.review_id = r.reviews_id and v.helpful\) as helpful,
+				\(select count\(\*\) from review_votes v where v.review_id = r.reviews_id and not v.helpful\) as unhelpful
+			from reviews r where r.product_id = \$2 order by `
+
+	columns := []string{"review_id", "name", "rating", "comment", "user_id", "product_id", "created_at", "verified",
+		"helpful", "unhelpful"}
 
 	review := &models.Reviews{
 		ReviewsId: uuid.UUID{},
@@ -409,19 +615,144 @@ func TestFetchReviewById(t *testing.T) {
 	}
 
 	t.Run("Successful fetch", func(t *testing.T) {
-		row := sqlmock.NewRows([]string{"review_id", "name", "rating", "comment", "user_id", "product_id", "created_at"}).
-			AddRow(review.ReviewsId, review.Name, review.Rating, review.Comment, review.UserId, review.ProductId, review.CreatedAt)
+		row := sqlmock.NewRows(columns).
+			AddRow(review.ReviewsId, review.Name, review.Rating, review.Comment, review.UserId, review.ProductId, review.CreatedAt, true, 3, 1)
 
-		mock.ExpectQuery(query).WithArgs(review.ReviewsId).WillReturnRows(row)
+		mock.ExpectQuery(query+`r.created_at$`).WithArgs(models.OrderDelivered, review.ProductId).WillReturnRows(row)
 
-		rev, err := repo.FetchReviewById(review.ReviewsId)
+		rev, err := repo.FetchReviewById(review.ProductId, "")
 		assert.NoError(t, err)
 
 		assert.NotNil(t, rev)
 		assert.Equal(t, review.ReviewsId, rev[0].ReviewsId)
+		assert.True(t, rev[0].VerifiedPurchase)
+		assert.Equal(t, 3, rev[0].Helpful)
+		assert.Equal(t, 1, rev[0].Unhelpful)
+	})
+
+	t.Run("Most helpful first", func(t *testing.T) {
+		mock.ExpectQuery(query+`helpful desc, unhelpful, r.created_at desc$`).
+			WithArgs(models.OrderDelivered, review.ProductId).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		rev, err := repo.FetchReviewById(review.ProductId, models.ReviewsHelpful)
+		assert.NoError(t, err)
+		assert.Empty(t, rev)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertReviewVote(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+
+	query := `insert into review_votes \(review_id, user_id, helpful, created_at\)
+				select \$1, \$2, \$3, \$4 where exists \(select 1 from reviews where reviews_id = \$1\)
+				on conflict \(review_id, user_id\) do update set helpful = excluded.helpful`
+
+	reviewId, userId := uuid.New(), uuid.New()
+
+	t.Run("Vote is recorded", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(reviewId, userId, true, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.UpsertReviewVote(reviewId, userId, true))
+	})
+
+	t.Run("Review not found", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(reviewId, userId, false, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.UpsertReviewVote(reviewId, userId, false), sql.ErrNoRows)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchReviewVotes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+
+	query := `select count\(v.user_id\) filter \(where v.helpful\), count\(v.user_id\) filter \(where not v.helpful\)
+				from reviews r left join review_votes v on v.review_id = r.reviews_id
+				where r.reviews_id = \$1 group by r.reviews_id`
+
+	reviewId := uuid.New()
+
+	t.Run("Votes are counted", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(reviewId).WillReturnRows(sqlmock.NewRows([]string{"helpful", "unhelpful"}).AddRow(4, 2))
+
+		votes, err := repo.FetchReviewVotes(reviewId)
+		require.NoError(t, err)
+		assert.Equal(t, models.ReviewVotes{Helpful: 4, Unhelpful: 2}, votes)
+	})
+
+	t.Run("Review not found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(reviewId).WillReturnRows(sqlmock.NewRows([]string{"helpful", "unhelpful"}))
+
+		_, err := repo.FetchReviewVotes(reviewId)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchReviewImages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+
+	a, b := uuid.New(), uuid.New()
+
+	t.Run("Images of the reviews are fetched", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"public_id", "url", "review_id", "created_at"}).
+			AddRow("reviews/a", "https://img/a.png", a, time.Now())
+		mock.ExpectQuery(`select public_id, url, review_id, created_at from review_images where review_id in \(\$1, \$2\) order by created_at`).
+			WithArgs(a, b).
+			WillReturnRows(rows)
+
+		images, err := repo.FetchReviewImages([]uuid.UUID{a, b})
+		require.NoError(t, err)
+
+		require.Len(t, images, 1)
+		assert.Equal(t, "reviews/a", images[0].PublicId)
+		assert.Equal(t, a, images[0].ReviewId)
+	})
+
+	t.Run("No reviews", func(t *testing.T) {
+		images, err := repo.FetchReviewImages(nil)
+		require.NoError(t, err)
+		assert.Nil(t, images)
 	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestFetchReviewsByProductIds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	a, b, user := uuid.New(), uuid.New(), uuid.New()
+	mock.ExpectQuery(`select reviews_id, name, ratings, comment, user_id, product_id, created_at from reviews\s+where product_id in \(\$1, \$2\) order by created_at`).
+		WithArgs(a, b).
+		WillReturnRows(sqlmock.NewRows([]string{"reviews_id", "name", "ratings", "comment", "user_id", "product_id", "created_at"}).
+			AddRow(uuid.New(), "Ama", 5, "Sturdy", user, b, time.Now()))
+
+	reviews, err := repository.NewProdRepository(db).FetchReviewsByProductIds([]uuid.UUID{a, b})
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, b, reviews[0].ProductId)
+	assert.Equal(t, user, reviews[0].UserId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
 func TestDeleteReviewById(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -446,4 +777,664 @@ func TestDeleteReviewById(t *testing.T) {
 		assert.NoError(t, err)
 
 	})
+
+	t.Run("Review not found", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(review.ReviewsId).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeleteReviewById(review.ReviewsId)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestUpdateRatings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+	id := uuid.New()
+	query := `update products p set ratings = r.ratings, num_of_reviews = r.reviews\s+` +
+		`from \(select coalesce\(round\(avg\(ratings\)\), 0\) as ratings, count\(\*\) as reviews\s+` +
+		`from reviews where product_id = \$1\) r\s+where p.product_id = \$1`
+
+	t.Run("Ratings are aggregated from the reviews", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.UpdateRatings(id))
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.UpdateRatings(id), sql.ErrNoRows)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSKUExists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+
+	query := "select exists\\(select 1 from products where sku = \\$1 and product_id <> \\$2\\)"
+
+	t.Run("SKU taken", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("SKU-1", uuid.Nil).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		exists, err := repo.SKUExists("SKU-1", uuid.Nil)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("SKU-1", uuid.Nil).WillReturnError(errors.New("error"))
+
+		_, err := repo.SKUExists("SKU-1", uuid.Nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestFetchProductKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+
+	query := "select product_id, coalesce\\(sku, ''\\) from products"
+	id := uuid.New()
+
+	t.Run("Keys fetched", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"product_id", "sku"}).AddRow(id, "CAM-1"))
+
+		keys, err := repo.FetchProductKeys(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]string{id: "CAM-1"}, keys)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(errors.New("error"))
+
+		_, err := repo.FetchProductKeys(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+func TestImportProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+
+	p := models.Product{
+		ProductId:   uuid.New(),
+		Name:        "Camera",
+		SKU:         "CAM-1",
+		Description: "A camera",
+		Price:       money.Of(250),
+		Stock:       3,
+		Category:    "Cameras",
+		Seller:      "Ebay",
+		UserId:      uuid.New(),
+	}
+
+	t.Run("Inserts and updates committed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("insert into products \\(product_id, name, sku").
+			WithArgs(p.ProductId, p.Name, p.SKU, p.Description, p.Price, "USD", p.Stock, p.Category, p.CategoryId, p.Seller, 0, 0, p.UserId,
+				sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("update products p set name = v.name").
+			WithArgs(p.ProductId, p.Name, p.SKU, p.Description, p.Price, "USD", p.Stock, p.Category, p.CategoryId, p.Seller).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.ImportProducts(context.Background(), []*models.Product{&p}, []*models.Product{&p})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("insert into products").WillReturnError(errors.New("error"))
+		mock.ExpectRollback()
+
+		err := repo.ImportProducts(context.Background(), []*models.Product{&p}, nil)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBulkUpdateProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+
+	lock := "select name, coalesce\\(sku, ''\\), price, currency, stock, low_stock_threshold from products where product_id = \\$1 for update"
+	update := "update products set price = \\$1, currency = \\$2, stock = \\$3 where product_id = \\$4"
+	columns := []string{"name", "sku", "price", "currency", "stock", "low_stock_threshold"}
+
+	id := uuid.New()
+	price, stock := money.Of(8000), 2
+
+	t.Run("Price and stock updated", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(id).WillReturnRows(sqlmock.NewRows(columns).AddRow("Camera", "CAM-1", 7000, "USD", 10, 5))
+		mock.ExpectExec(update).WithArgs(price, "USD", 10, id).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.BulkUpdateProducts([]models.ProductUpdate{{ProductId: id, Price: &price}})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Price drop and low stock are written to the outbox", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(id).WillReturnRows(sqlmock.NewRows(columns).AddRow("Camera", "CAM-1", 9000, "USD", 10, 5))
+		mock.ExpectExec(update).WithArgs(price, "USD", stock, id).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("select user_id from wishlist_items where product_id = \\$1").WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(uuid.New()))
+		mock.ExpectExec("insert into outbox").WithArgs(events.ProductPriceDropped, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("insert into outbox").WithArgs(events.StockLow, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.BulkUpdateProducts([]models.ProductUpdate{{ProductId: id, Price: &price, Stock: &stock}})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Stock already low alerts no one", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(id).WillReturnRows(sqlmock.NewRows(columns).AddRow("Camera", "CAM-1", 8000, "USD", 3, 5))
+		mock.ExpectExec(update).WithArgs(price, "USD", stock, id).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.BulkUpdateProducts([]models.ProductUpdate{{ProductId: id, Stock: &stock}})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Missing product rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(id).WillReturnRows(sqlmock.NewRows(columns).AddRow("Camera", "CAM-1", 8000, "USD", 3, 5))
+		mock.ExpectExec(update).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(lock).WithArgs(sqlmock.AnyArg()).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err := repo.BulkUpdateProducts([]models.ProductUpdate{{ProductId: id, Stock: &stock}, {ProductId: uuid.New(), Stock: &stock}})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPublishScheduled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+	now := time.Now()
+	query := `update products set status = \$1 where status = \$2 and publish_at <= \$3 returning product_id`
+
+	t.Run("Due drafts are published", func(t *testing.T) {
+		id := uuid.New()
+		mock.ExpectQuery(query).WithArgs(models.ProductPublished, models.ProductDraft, now).
+			WillReturnRows(sqlmock.NewRows([]string{"product_id"}).AddRow(id))
+
+		ids, err := repo.PublishScheduled(context.Background(), now)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{id}, ids)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(errors.New("error"))
+
+		_, err := repo.PublishScheduled(context.Background(), now)
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+
+	columns := []string{"product_id", "name", "price", "description", "ratings", "category", "seller",
+		"stock", "num_of_reviews", "user_id", "created_at", "sku", "category_id", "currency", "tax_class", "shipping_class", "hidden", "low_stock_threshold", "status", "publish_at"}
+	query := "select product_id, name, .* from products order by name, product_id"
+
+	t.Run("Every product streamed", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), "Camera", 250, "A camera", 0, "Cameras", "Ebay", 3, 0, uuid.New(), time.Now(), "CAM-1", nil, "USD", "standard", "standard", false, 5, "published", nil).
+			AddRow(uuid.New(), "Lens", 120, "A lens", 0, "Cameras", "Ebay", 4, 0, uuid.New(), time.Now(), "", nil, "USD", "standard", "standard", false, 5, "published", nil)
+		mock.ExpectQuery(query).WillReturnRows(rows)
+
+		var names []string
+		err := repo.StreamProducts(func(p *models.Product) error {
+			names = append(names, p.Name)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Camera", "Lens"}, names)
+	})
+
+	t.Run("Callback error stops the stream", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), "Camera", 250, "A camera", 0, "Cameras", "Ebay", 3, 0, uuid.New(), time.Now(), "CAM-1", nil, "USD", "standard", "standard", false, 5, "published", nil)
+		mock.ExpectQuery(query).WillReturnRows(rows)
+
+		err := repo.StreamProducts(func(p *models.Product) error { return errors.New("write error") })
+		assert.EqualError(t, err, "write error")
+	})
+}
+
+func TestFetchVariants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+
+	query := "select v.variant_id, v.product_id, coalesce\\(v.sku, ''\\), v.attributes, v.price_delta, p.currency,\\s+" +
+		"v.stock, v.created_at\\s+from product_variants v join products p on p.product_id = v.product_id\\s+" +
+		"where v.product_id = \\$1"
+	productID := uuid.New()
+
+	t.Run("Variants fetched", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"variant_id", "product_id", "sku", "attributes", "price_delta", "currency", "stock", "created_at"}).
+			AddRow(uuid.New(), productID, "SHIRT-M", []byte(`{"size": "M"}`), 5, "USD", 2, time.Now())
+		mock.ExpectQuery(query).WithArgs(productID).WillReturnRows(rows)
+
+		variants, err := repo.FetchVariants(productID)
+		require.NoError(t, err)
+		require.Len(t, variants, 1)
+		assert.Equal(t, map[string]string{"size": "M"}, variants[0].Attributes)
+		assert.Equal(t, money.Of(5), variants[0].PriceDelta)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(productID).WillReturnError(errors.New("error"))
+
+		_, err := repo.FetchVariants(productID)
+		assert.Error(t, err)
+	})
+}
+
+func TestSaveVariants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+
+	productID := uuid.New()
+	kept, removed := uuid.New(), uuid.New()
+	variants := []models.Variant{
+		{VariantId: kept, Attributes: map[string]string{"size": "M"}, Stock: 2},
+		{SKU: "SHIRT-L", Attributes: map[string]string{"size": "L"}, PriceDelta: money.Of(5), Stock: 1},
+	}
+
+	t.Run("Variants updated, inserted and removed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("select variant_id from product_variants where product_id = \\$1").WithArgs(productID).
+			WillReturnRows(sqlmock.NewRows([]string{"variant_id"}).AddRow(kept).AddRow(removed))
+		mock.ExpectExec("delete from product_variants where variant_id = \\$1").WithArgs(removed).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("update product_variants set sku = \\$1").
+			WithArgs(nil, []byte(`{"size":"M"}`), 0, 2, kept, productID).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+		mock.ExpectQuery("insert into product_variants").
+			WithArgs(productID, "SHIRT-L", []byte(`{"size":"L"}`), 5, 1, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"variant_id", "created_at"}).AddRow(uuid.New(), time.Now()))
+		mock.ExpectCommit()
+
+		saved, err := repo.SaveVariants(productID, variants)
+		require.NoError(t, err)
+		require.Len(t, saved, 2)
+		assert.NotEqual(t, uuid.Nil, saved[1].VariantId)
+		assert.Equal(t, productID, saved[1].ProductId)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown variant", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("select variant_id from product_variants").WithArgs(productID).
+			WillReturnRows(sqlmock.NewRows([]string{"variant_id"}))
+		mock.ExpectQuery("update product_variants").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := repo.SaveVariants(productID, variants[:1])
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFetchRelatedProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+	a, b, related := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`with viewed as \(\s+select product_id, category_id, ratings from products\s+`+
+		`where product_id in \(\$1, \$2\) and not hidden and status = 'published'\s+\).*`+
+		`where p.product_id not in \(\$1, \$2\) and p.stock > 0 .*`+
+		`order by coalesce\(together.orders, 0\) desc, abs\(p.ratings - \(select avg\(ratings\) from viewed\)\),\s+`+
+		`p.ratings desc, p.created_at desc\s+limit \$3`).
+		WithArgs(a, b, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "name", "price", "currency", "ratings", "image"}).
+			AddRow(related, "Tripod", 4999, "USD", 4, "https://img.example.com/tripod.jpg"))
+
+	recs, err := repo.FetchRelatedProducts([]uuid.UUID{a, b}, 4)
+	require.NoError(t, err)
+	require.Len(t, recs, 1)
+	assert.Equal(t, related, recs[0].ProductId)
+	assert.Equal(t, "https://img.example.com/tripod.jpg", recs[0].Image)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertView(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+	productID, userID := uuid.New(), uuid.New()
+	insert := `insert into product_views \(product_id, user_id, session_id, viewed_at\)\s+` +
+		`select product_id, \$2, \$3, \$4 from products where product_id = \$1 and not hidden and status = 'published'\s+`
+
+	t.Run("Users views are upserted by user", func(t *testing.T) {
+		user := uuid.NullUUID{UUID: userID, Valid: true}
+		mock.ExpectExec(insert+`on conflict \(user_id, product_id\) where user_id is not null do update set viewed_at = excluded.viewed_at`).
+			WithArgs(productID, user, nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.InsertView(productID, models.Viewer{UserID: user}))
+	})
+
+	t.Run("Anonymous views are upserted by session", func(t *testing.T) {
+		mock.ExpectExec(insert+`on conflict \(session_id, product_id\) where user_id is null do update set viewed_at = excluded.viewed_at`).
+			WithArgs(productID, uuid.NullUUID{}, "visitor", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.InsertView(productID, models.Viewer{Session: "visitor"}))
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		mock.ExpectExec(insert).
+			WithArgs(productID, uuid.NullUUID{}, "visitor", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.InsertView(productID, models.Viewer{Session: "visitor"}), sql.ErrNoRows)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchRecentlyViewed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+	viewed := uuid.New()
+	columns := []string{"product_id", "name", "price", "currency", "ratings", "image"}
+
+	t.Run("Views of a user", func(t *testing.T) {
+		user := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+		mock.ExpectQuery(`from product_views v\s+join products p on p.product_id = v.product_id\s+`+
+			`where v.user_id = \$1 and not p.hidden and p.status = 'published'\s+order by v.viewed_at desc\s+limit \$2`).
+			WithArgs(user, 8).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(viewed, "Tripod", 4999, "USD", 4, ""))
+
+		recs, err := repo.FetchRecentlyViewed(models.Viewer{UserID: user}, 8)
+		require.NoError(t, err)
+		require.Len(t, recs, 1)
+		assert.Equal(t, viewed, recs[0].ProductId)
+	})
+
+	t.Run("Views of an anonymous session", func(t *testing.T) {
+		mock.ExpectQuery(`where v.session_id = \$1 and v.user_id is null and not p.hidden`).
+			WithArgs("visitor", 8).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		recs, err := repo.FetchRecentlyViewed(models.Viewer{Session: "visitor"}, 8)
+		require.NoError(t, err)
+		assert.Empty(t, recs)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertSearch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	id := uuid.New()
+	mock.ExpectQuery(`insert into search_events \(event, keyword, results, created_at\) values \(\$1, \$2, \$3, \$4\)\s+returning event_id`).
+		WithArgs(models.SearchEventSearch, "tripod", 0, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"event_id"}).AddRow(id))
+
+	got, err := repository.NewProdRepository(db).InsertSearch("tripod", 0)
+	require.NoError(t, err)
+	assert.Equal(t, id, got)
+}
+
+func TestInsertSearchClick(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	searchID, productID := uuid.New(), uuid.New()
+	query := `insert into search_events \(event, keyword, search_id, product_id, created_at\)\s+select \$1, keyword, event_id, \$2, \$3 from search_events where event_id = \$4 and event = \$5`
+	repo := repository.NewProdRepository(db)
+
+	t.Run("Click is recorded under the search keyword", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(models.SearchEventClick, productID, sqlmock.AnyArg(), searchID, models.SearchEventSearch).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.InsertSearchClick(searchID, productID))
+	})
+
+	t.Run("Search not found", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(models.SearchEventClick, productID, sqlmock.AnyArg(), searchID, models.SearchEventSearch).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.InsertSearchClick(searchID, productID), sql.ErrNoRows)
+	})
+}
+
+func TestFetchZeroResultSearches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	since, last := time.Now().Add(-time.Hour), time.Now()
+	mock.ExpectQuery(`select keyword, count\(\*\), max\(created_at\) from search_events\s+where event = \$1 and results = 0 and created_at >= \$2\s+group by keyword\s+order by count\(\*\) desc, max\(created_at\) desc\s+limit \$3`).
+		WithArgs(models.SearchEventSearch, since, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"keyword", "count", "max"}).
+			AddRow("tripod", 12, last).
+			AddRow("drone", 4, last))
+
+	terms, err := repository.NewProdRepository(db).FetchZeroResultSearches(since, 20)
+	require.NoError(t, err)
+	assert.Equal(t, []models.SearchTerm{
+		{Keyword: "tripod", Searches: 12, LastSearchedAt: last},
+		{Keyword: "drone", Searches: 4, LastSearchedAt: last},
+	}, terms)
+}
+
+func TestFetchSynonyms(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	id, now := uuid.New(), time.Now()
+	mock.ExpectQuery(`select synonym_id, terms, created_at, updated_at from search_synonyms order by created_at`).
+		WillReturnRows(sqlmock.NewRows([]string{"synonym_id", "terms", "created_at", "updated_at"}).
+			AddRow(id, "sneakers,trainers", now, now))
+
+	sets, err := repository.NewProdRepository(db).FetchSynonyms()
+	require.NoError(t, err)
+	assert.Equal(t, []models.SynonymSet{{ID: id, Terms: []string{"sneakers", "trainers"}, CreatedAt: now, UpdatedAt: now}}, sets)
+}
+
+func TestInsertSynonyms(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	id, now := uuid.New(), time.Now()
+	mock.ExpectQuery(`insert into search_synonyms \(terms, created_at, updated_at\) values \(\$1, \$2, \$3\)\s+returning synonym_id, created_at, updated_at`).
+		WithArgs("sneakers,trainers", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"synonym_id", "created_at", "updated_at"}).AddRow(id, now, now))
+
+	set, err := repository.NewProdRepository(db).InsertSynonyms(&models.SynonymSet{Terms: []string{"sneakers", "trainers"}})
+	require.NoError(t, err)
+	assert.Equal(t, models.SynonymSet{ID: id, Terms: []string{"sneakers", "trainers"}, CreatedAt: now, UpdatedAt: now}, set)
+}
+
+func TestUpdateSynonyms(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	id, now := uuid.New(), time.Now()
+	query := `update search_synonyms set terms = \$1, updated_at = \$2 where synonym_id = \$3\s+returning synonym_id, created_at, updated_at`
+	repo := repository.NewProdRepository(db)
+
+	t.Run("Terms are replaced", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("tv,television", sqlmock.AnyArg(), id).
+			WillReturnRows(sqlmock.NewRows([]string{"synonym_id", "created_at", "updated_at"}).AddRow(id, now, now))
+
+		set, err := repo.UpdateSynonyms(id, &models.SynonymSet{Terms: []string{"tv", "television"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"tv", "television"}, set.Terms)
+	})
+
+	t.Run("Set not found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("tv,television", sqlmock.AnyArg(), id).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.UpdateSynonyms(id, &models.SynonymSet{Terms: []string{"tv", "television"}})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestDeleteSynonyms(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	id := uuid.New()
+	repo := repository.NewProdRepository(db)
+
+	mock.ExpectExec(`delete from search_synonyms where synonym_id = \$1`).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.DeleteSynonyms(id))
+
+	mock.ExpectExec(`delete from search_synonyms where synonym_id = \$1`).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.DeleteSynonyms(id), sql.ErrNoRows)
+}
+
+func TestFetchLowStock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	productID, variantID := uuid.New(), uuid.New()
+	mock.ExpectQuery(`select p.product_id, v.variant_id, p.name, coalesce\(v.sku, p.sku, ''\), coalesce\(v.stock, p.stock\),\s+p.low_stock_threshold\s+from products p\s+left join product_variants v on \(v.product_id = p.product_id\)\s+where coalesce\(v.stock, p.stock\) <= p.low_stock_threshold\s+order by 5, 3\s+limit \$1`).
+		WithArgs(50).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "variant_id", "name", "sku", "stock", "low_stock_threshold"}).
+			AddRow(productID, variantID, "Kente Scarf", "KS-RED-M", 2, 5))
+
+	items, err := repository.NewProdRepository(db).FetchLowStock(50)
+	require.NoError(t, err)
+	assert.Equal(t, []models.LowStockItem{{ProductID: productID, VariantID: uuid.NullUUID{UUID: variantID, Valid: true},
+		Name: "Kente Scarf", SKU: "KS-RED-M", Stock: 2, Threshold: 5}}, items)
+}
+
+func TestUpdateLowStockThreshold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+	productID := uuid.New()
+	query := `update products set low_stock_threshold = \$1 where product_id = \$2`
+
+	mock.ExpectExec(query).WithArgs(10, productID).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.UpdateLowStockThreshold(productID, 10))
+
+	mock.ExpectExec(query).WithArgs(10, productID).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.UpdateLowStockThreshold(productID, 10), sql.ErrNoRows)
+}
+
+func TestDeleteImage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+	productID := uuid.New()
+	query := `delete from images where product_id = \$1 and public_id = \$2`
+
+	mock.ExpectExec(query).WithArgs(productID, "products/abc").WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.DeleteImage(productID, "products/abc"))
+
+	mock.ExpectExec(query).WithArgs(productID, "products/abc").WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.DeleteImage(productID, "products/abc"), sql.ErrNoRows)
+}
+
+func TestAttachUploads(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProdRepository(db)
+	productID, userID := uuid.New(), uuid.New()
+	claim := `update uploads set claimed_at = \$1`
+	insert := `insert into images \(public_id, url, product_id, created_at\)`
+
+	t.Run("Uploads are claimed and attached", func(t *testing.T) {
+		mock.ExpectBegin()
+		for _, id := range []string{"products/a", "products/b"} {
+			mock.ExpectQuery(claim).WithArgs(sqlmock.AnyArg(), id, userID, models.UploadProductImage).
+				WillReturnRows(sqlmock.NewRows([]string{"url"}).AddRow("https://img/" + id))
+			mock.ExpectExec(insert).WithArgs(id, "https://img/"+id, productID, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.AttachUploads(productID, userID, []string{"products/a", "products/b"}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Nothing is attached when an upload cannot be claimed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(claim).WithArgs(sqlmock.AnyArg(), "products/a", userID, models.UploadProductImage).
+			WillReturnRows(sqlmock.NewRows([]string{"url"}).AddRow("https://img/products/a"))
+		mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(claim).WithArgs(sqlmock.AnyArg(), "products/used", userID, models.UploadProductImage).
+			WillReturnRows(sqlmock.NewRows([]string{"url"}))
+		mock.ExpectRollback()
+
+		err := repo.AttachUploads(productID, userID, []string{"products/a", "products/used"})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
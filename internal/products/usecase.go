@@ -1,7 +1,10 @@
 package products
 
 import (
+	"context"
+	"io"
 	"mime/multipart"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/internal/models"
@@ -9,29 +12,122 @@ import (
 
 type ProductUC interface {
 	// CreateProduct creates a new product and uploads its images to cloudinary
-	CreateProduct(p models.Product, img []*multipart.FileHeader) (*models.ProdResponse, error)
+	CreateProduct(ctx context.Context, p models.Product, img []*multipart.FileHeader) (*models.ProdResponse, error)
 
-	// GetProducts retrieves products based on a keyword and page number
-	GetProducts(keyword string, page int) (*models.GetProd, error)
+	// ValidateProduct runs the product validation pipeline without saving anything
+	ValidateProduct(p models.Product, img []*multipart.FileHeader) (*models.ProductValidationReport, error)
 
-	// GetAdminProducts retrieves all products for admin use
-	GetAdminProducts() ([]*models.Product, error)
+	// ImportProducts saves the valid rows of a product CSV and reports the invalid ones
+	ImportProducts(ctx context.Context, r io.Reader, userID uuid.UUID) (*models.ProductImportReport, error)
+
+	// SyncSheet imports the products of the catalog sheet like ImportProducts, returns the report and an
+	// error when there is no catalog sheet or it cannot be read
+	SyncSheet(ctx context.Context, userID uuid.UUID) (*models.ProductImportReport, error)
+
+	// BulkUpdateProducts saves the valid price and stock updates in one transaction and reports the invalid ones
+	BulkUpdateProducts(updates []models.ProductUpdate) (*models.BulkUpdateReport, error)
+
+	// PublishScheduled publishes the drafts whose publish time has come, returns how many it published
+	PublishScheduled(ctx context.Context) (int, error)
+
+	// ExportProducts writes every product as CSV
+	ExportProducts(w io.Writer) error
+
+	// GetProducts retrieves products based on a keyword, a category (id or name) including its subcategories, and page number
+	GetProducts(keyword, category string, page int) (*models.GetProd, error)
+
+	// GetAdminProducts retrieves a page of products for admin use, hidden and unpublished ones included, found by keyword,
+	// owned by owner when it is set and sorted by one of models.ProductSorts
+	GetAdminProducts(keyword, sort string, page, limit int, owner uuid.NullUUID) (*models.GetProd, error)
 
 	// GetSingleProduct retrieves a single product by its ID
 	GetSingleProduct(productId uuid.UUID) (*models.Product, error)
 
-	// UpdateProduct updates a product's details and images by its id
-	UpdateProduct(productId uuid.UUID, p models.Product, img []*multipart.File) (*models.ProdResponse, error)
+	// GetProductsByIds retrieves the products with the given ids and their images, skipping those that do not exist
+	GetProductsByIds(productIds []uuid.UUID) ([]*models.Product, error)
+
+	// UpdateProduct updates a product's details and images by its id for an admin or the seller who owns it,
+	// returns an error when it does not exist or user may not change it
+	UpdateProduct(ctx context.Context, productId uuid.UUID, p models.Product, img []*multipart.File, user *models.User) (*models.ProdResponse, error)
+
+	// AddImages uploads images to cloudinary and appends them to those of a product, returns all its images and
+	// an error when user may not change it
+	AddImages(ctx context.Context, productId uuid.UUID, img []*multipart.FileHeader, user *models.User) ([]models.Images, error)
+
+	// AttachImages adds images user uploaded directly to those of a product, returns all its images and an
+	// error when user may not change it
+	AttachImages(productId uuid.UUID, publicIds []string, user *models.User) ([]models.Images, error)
+
+	// DeleteImage deletes an image of a product from cloudinary and the database, returns the images left and
+	// an error when the product has no such image or user may not change it
+	DeleteImage(ctx context.Context, productId uuid.UUID, publicId string, user *models.User) ([]models.Images, error)
+
+	// DeleteProduct deletes product from the product's table by its id for an admin or the seller who owns it,
+	// returns an error when it does not exist or user may not delete it
+	DeleteProduct(ctx context.Context, productId uuid.UUID, user *models.User) error
+
+	// GetRecommendations returns up to limit products to suggest alongside the given ones
+	GetRecommendations(productIds []uuid.UUID, limit int) ([]models.Recommendation, error)
+
+	// GetRelatedProducts returns up to limit products bought with a product or in its category with similar
+	// ratings, returns an error when it does not exist
+	GetRelatedProducts(productId uuid.UUID, limit int) ([]models.Recommendation, error)
 
-	// DeleteProduct deletes product from the product's table by its id
-	DeleteProduct(productId uuid.UUID) error
+	// RecordView records that a viewer viewed a product, returns an error when it does not exist
+	RecordView(productId uuid.UUID, viewer models.Viewer) error
 
-	// CreateProductReview process product's review and save it into the database
-	CreateProductReview(review models.Reviews) error
+	// GetRecentlyViewed returns up to limit products a viewer viewed, the last viewed first
+	GetRecentlyViewed(viewer models.Viewer, limit int) ([]models.Recommendation, error)
 
-	// GetProductReviews fetches all reviews for a particular product
-	GetProductReviews(productId uuid.UUID) ([]models.Reviews, error)
+	// CreateProductReview process product's review and its images and save it into the database
+	CreateProductReview(ctx context.Context, review models.Reviews, img []*multipart.FileHeader) error
+
+	// GetProductReviews fetches all reviews for a particular product with their images, in the order of one of
+	// models.ReviewSorts
+	GetProductReviews(productId uuid.UUID, sort string) ([]models.Reviews, error)
+
+	// VoteReview records whether a user found a review helpful, replacing their earlier vote, and returns the
+	// votes on the review
+	VoteReview(reviewId, userId uuid.UUID, helpful bool) (models.ReviewVotes, error)
+
+	// WithdrawReviewVote withdraws the vote of a user on a review and returns the votes on the review
+	WithdrawReviewVote(reviewId, userId uuid.UUID) (models.ReviewVotes, error)
+
+	// GetReviewsByProductIds fetches the reviews of the given products, keyed by product id
+	GetReviewsByProductIds(productIds []uuid.UUID) (map[uuid.UUID][]models.Reviews, error)
 
 	// DeleteProductReview deletes a particular review for a product by its id
 	DeleteProductReview(productId uuid.UUID, reviewId uuid.UUID) error
+
+	// RecordSearch records a keyword search and how many products it found, returns the id of the search
+	RecordSearch(keyword string, results int) (uuid.UUID, error)
+
+	// RecordSearchClick records a click on a product found by a search, returns an error when the search or
+	// the product does not exist
+	RecordSearchClick(searchId, productId uuid.UUID) error
+
+	// GetZeroResultSearches returns the keywords searched since the given time that found nothing, searched
+	// most first
+	GetZeroResultSearches(since time.Time, limit int) ([]models.SearchTerm, error)
+
+	// GetSynonyms returns every synonym set applied to searches
+	GetSynonyms() ([]models.SynonymSet, error)
+
+	// CreateSynonyms saves a synonym set, returns an error when one of its terms is in another set
+	CreateSynonyms(s models.SynonymSet) (*models.SynonymSet, error)
+
+	// UpdateSynonyms replaces the terms of a synonym set, returns an error when it does not exist or one of its
+	// terms is in another set
+	UpdateSynonyms(id uuid.UUID, s models.SynonymSet) (*models.SynonymSet, error)
+
+	// DeleteSynonyms deletes a synonym set, returns an error when it does not exist
+	DeleteSynonyms(id uuid.UUID) error
+
+	// GetLowStock returns the products and variants at or under the low-stock threshold of their product,
+	// fewest units first
+	GetLowStock(limit int) ([]models.LowStockItem, error)
+
+	// SetLowStockThreshold sets the stock at or under which admins are alerted about a product, returns an
+	// error when it does not exist
+	SetLowStockThreshold(productId uuid.UUID, threshold int) error
 }
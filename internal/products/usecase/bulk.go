@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// BulkUpdateProducts sets the price, the stock, or both, of many products at once,
+// such as for a sale or an inventory sync. Each update must name an existing product
+// at most once and change something; invalid updates are skipped and reported by
+// their index, and the valid ones are saved in one transaction. Like ImportProducts,
+// it does not publish events.ProductUpdated.
+func (p *ProductsUC) BulkUpdateProducts(updates []models.ProductUpdate) (*models.BulkUpdateReport, error) {
+	report := models.BulkUpdateReport{Errors: []models.BulkUpdateError{}}
+
+	checks := make([]*validator.Validator, len(updates))
+	seen := make(map[uuid.UUID]bool, len(updates))
+	var ids []uuid.UUID
+	for i, u := range updates {
+		v := validator.New()
+		checkUpdate(v, u)
+		if u.ProductId != uuid.Nil {
+			v.CheckCode(!seen[u.ProductId], "productId", validator.CodeDuplicate, "product is updated more than once")
+			if !seen[u.ProductId] {
+				ids = append(ids, u.ProductId)
+			}
+			seen[u.ProductId] = true
+		}
+		checks[i] = v
+	}
+
+	found := make(map[uuid.UUID]bool, len(ids))
+	if len(ids) > 0 {
+		prods, err := p.repo.FetchProductsByIds(ids)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching products: %v", err)
+		}
+		for _, prod := range prods {
+			found[prod.ProductId] = true
+		}
+	}
+
+	var valid []models.ProductUpdate
+	for i, u := range updates {
+		v := checks[i]
+		if u.ProductId != uuid.Nil {
+			v.CheckCode(found[u.ProductId], "productId", validator.CodeNotFound, "product not found")
+		}
+
+		if !v.Valid() {
+			report.Failed++
+			report.Errors = append(report.Errors, models.BulkUpdateError{Index: i, ProductId: u.ProductId, Errors: v.Errors})
+			continue
+		}
+		valid = append(valid, u)
+	}
+
+	if len(valid) > 0 {
+		if err := p.repo.BulkUpdateProducts(valid); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: deleted while updating", products.ErrProductNotFound)
+			}
+			return nil, fmt.Errorf("error updating products: %v", err)
+		}
+	}
+	report.Updated = len(valid)
+
+	return &report, nil
+}
+
+// checkUpdate adds to v what is wrong with u, leaving out whether its product exists.
+func checkUpdate(v *validator.Validator, u models.ProductUpdate) {
+	v.CheckCode(u.ProductId != uuid.Nil, "productId", validator.CodeRequired, "product id must be provided")
+	v.CheckCode(u.Price != nil || u.Stock != nil, "price", validator.CodeRequired, "price or stock must be provided")
+	if u.Price != nil {
+		v.CheckCode(u.Price.IsPositive(), "price", validator.CodeTooSmall, "product price must be greater than zero")
+	}
+	if u.Stock != nil {
+		v.CheckCode(*u.Stock >= 0, "stock", validator.CodeTooSmall, "product stock must not be negative")
+	}
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// categoryIndex looks categories up by id and by name, regardless of case.
+type categoryIndex struct {
+	byID   map[uuid.UUID]models.Category
+	byName map[string]models.Category
+}
+
+// categories fetches the categories products can be filed under.
+func (p *ProductsUC) categories() (*categoryIndex, error) {
+	cats, err := p.cats.FetchCategories()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching categories: %v", err)
+	}
+
+	ci := categoryIndex{
+		byID:   make(map[uuid.UUID]models.Category, len(cats)),
+		byName: make(map[string]models.Category, len(cats)),
+	}
+	for _, c := range cats {
+		ci.byID[c.CategoryId] = c
+		ci.byName[strings.ToLower(c.Name)] = c
+	}
+
+	return &ci, nil
+}
+
+// file files prod under its category: the one of CategoryId when it is set,
+// otherwise the one named Category. It fails with products.ErrCategoryNotFound
+// when there is no such category.
+func (ci *categoryIndex) file(prod *models.Product) error {
+	c, ok := ci.byName[strings.ToLower(prod.Category)]
+	if prod.CategoryId.Valid {
+		c, ok = ci.byID[prod.CategoryId.UUID]
+	}
+
+	if !ok {
+		if prod.CategoryId.Valid {
+			return fmt.Errorf("%w: %s", products.ErrCategoryNotFound, prod.CategoryId.UUID)
+		}
+		return fmt.Errorf("%w: %q", products.ErrCategoryNotFound, prod.Category)
+	}
+
+	prod.CategoryId = uuid.NullUUID{UUID: c.CategoryId, Valid: true}
+	prod.Category = c.Name
+
+	return nil
+}
+
+// check files prod under its category like file, recording in v when it does not
+// exist. A product without a category is left to checkProduct.
+func (ci *categoryIndex) check(v *validator.Validator, prod *models.Product) {
+	if prod.Category == "" && !prod.CategoryId.Valid {
+		return
+	}
+
+	if err := ci.file(prod); err != nil {
+		if prod.CategoryId.Valid {
+			v.AddErrorCode("category", validator.CodeNotFound, fmt.Sprintf("category %s does not exist", prod.CategoryId.UUID))
+			return
+		}
+		v.AddErrorCode("category", validator.CodeNotFound, fmt.Sprintf("category %q does not exist", prod.Category))
+	}
+}
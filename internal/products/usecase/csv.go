@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// csvColumns are the columns of a product CSV, in export order.
+var csvColumns = []string{"id", "sku", "name", "description", "price", "currency", "stock", "category", "seller"}
+
+// requiredColumns are the columns an import must have. Rows are matched to existing
+// products by id, then by sku, so both are optional, and prices without a currency
+// are in the shop currency.
+var requiredColumns = []string{"name", "description", "price", "stock", "category", "seller"}
+
+// ImportProducts reads a product CSV and saves its valid rows. The first line is a
+// header naming the columns, in any order. Products are filed under the category
+// named in their row. A row with an id updates that product, a
+// row whose sku belongs to a product updates it, any other row creates a product
+// owned by userID. Invalid rows are skipped and reported by line number; the valid
+// ones are saved in one transaction.
+func (p *ProductsUC) ImportProducts(ctx context.Context, r io.Reader, userID uuid.UUID) (*models.ProductImportReport, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading header: %v", products.ErrInvalidCSV, err)
+	}
+
+	index, err := columnIndex(header)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := p.repo.FetchProductKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching products: %v", err)
+	}
+
+	cats, err := p.categories()
+	if err != nil {
+		return nil, err
+	}
+
+	skus := make(map[string]uuid.UUID, len(keys))
+	for id, sku := range keys {
+		if sku != "" {
+			skus[sku] = id
+		}
+	}
+
+	report := models.ProductImportReport{Errors: []models.ProductImportError{}}
+	var inserts, updates []*models.Product
+	seen := make(map[uuid.UUID]bool)
+	rows := 0
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+
+		rows++
+		if rows > products.MaxImportRows {
+			return nil, fmt.Errorf("%w: more than %d", products.ErrTooManyRows, products.MaxImportRows)
+		}
+
+		if err != nil {
+			var perr *csv.ParseError
+			if !errors.As(err, &perr) {
+				return nil, fmt.Errorf("error reading csv: %v", err)
+			}
+			// The reader cannot resynchronise reliably after a malformed row, so the
+			// rows after it are not imported.
+			report.Failed++
+			report.Errors = append(report.Errors, models.ProductImportError{
+				Line:   perr.StartLine,
+				Errors: map[string]string{"csv": perr.Err.Error()},
+			})
+			break
+		}
+
+		line, _ := cr.FieldPos(0)
+		prod, v := parseRow(record, index, userID)
+		cats.check(v, prod)
+		if v.Valid() {
+			resolveRow(v, prod, keys, skus, seen)
+		}
+
+		if !v.Valid() {
+			report.Failed++
+			report.Errors = append(report.Errors, models.ProductImportError{Line: line, Errors: v.Errors})
+			continue
+		}
+
+		if prod.ProductId == uuid.Nil {
+			prod.ProductId = uuid.New()
+			inserts = append(inserts, prod)
+		} else {
+			if _, ok := index["sku"]; !ok {
+				prod.SKU = keys[prod.ProductId]
+			}
+			updates = append(updates, prod)
+		}
+		seen[prod.ProductId] = true
+		if prod.SKU != "" {
+			skus[prod.SKU] = prod.ProductId
+		}
+	}
+
+	if len(inserts)+len(updates) > 0 {
+		if err := p.repo.ImportProducts(ctx, inserts, updates); err != nil {
+			return nil, fmt.Errorf("error importing products: %v", err)
+		}
+	}
+
+	report.Created = len(inserts)
+	report.Updated = len(updates)
+
+	return &report, nil
+}
+
+// SyncSheet imports the rows of the catalog sheet, in the format of ImportProducts,
+// creating the products it adds for userID. It returns products.ErrSheetNotConfigured
+// when there is no catalog sheet.
+func (p *ProductsUC) SyncSheet(ctx context.Context, userID uuid.UUID) (*models.ProductImportReport, error) {
+	if p.sheet == nil {
+		return nil, products.ErrSheetNotConfigured
+	}
+
+	body, err := p.sheet.Open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching sheet: %w", err)
+	}
+	defer body.Close()
+
+	return p.ImportProducts(ctx, body, userID)
+}
+
+// columnIndex maps the columns of a CSV header to their position.
+func columnIndex(header []string) (map[string]int, error) {
+	known := make(map[string]bool, len(csvColumns))
+	for _, c := range csvColumns {
+		known[c] = true
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !known[name] {
+			return nil, fmt.Errorf("%w: unknown column %q", products.ErrInvalidCSV, name)
+		}
+		if _, ok := index[name]; ok {
+			return nil, fmt.Errorf("%w: duplicate column %q", products.ErrInvalidCSV, name)
+		}
+		index[name] = i
+	}
+
+	for _, c := range requiredColumns {
+		if _, ok := index[c]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", products.ErrInvalidCSV, c)
+		}
+	}
+
+	return index, nil
+}
+
+// parseRow builds a product from a CSV record and validates its fields.
+func parseRow(record []string, index map[string]int, userID uuid.UUID) (*models.Product, *validator.Validator) {
+	v := validator.New()
+	field := func(name string) string {
+		i, ok := index[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	prod := models.Product{
+		Name:        field("name"),
+		Description: field("description"),
+		Category:    field("category"),
+		Seller:      field("seller"),
+		SKU:         field("sku"),
+		UserId:      userID,
+	}
+
+	if id := field("id"); id != "" {
+		parsed, err := uuid.Parse(id)
+		v.CheckCode(err == nil, "id", validator.CodeID, "product id must be a valid uuid")
+		prod.ProductId = parsed
+	}
+
+	currency := strings.ToUpper(field("currency"))
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	v.CheckCode(money.Supported(currency), "currency", validator.CodeOneOf,
+		fmt.Sprintf("currency must be one of: %s", strings.Join(money.Currencies(), ", ")))
+
+	price, err := money.Parse(field("price"), currency)
+	v.Check(err == nil, "price", "product price must be an amount such as 49.99")
+	prod.Price = price
+
+	stock, err := strconv.Atoi(field("stock"))
+	v.Check(err == nil, "stock", "product stock must be a whole number")
+	prod.Stock = stock
+
+	checkProduct(v, &prod)
+
+	return &prod, v
+}
+
+// resolveRow checks a valid row against the catalog and the rows before it: its id
+// must belong to a product, its sku must not belong to another product and no
+// product may be imported twice. A row without an id updates the product owning
+// its sku.
+func resolveRow(v *validator.Validator, prod *models.Product, keys map[uuid.UUID]string, skus map[string]uuid.UUID, seen map[uuid.UUID]bool) {
+	owner, taken := skus[prod.SKU]
+	if prod.SKU == "" {
+		taken = false
+	}
+
+	if prod.ProductId == uuid.Nil {
+		if taken && keys[owner] == prod.SKU && !seen[owner] {
+			prod.ProductId = owner
+			return
+		}
+		v.CheckCode(!taken, "sku", validator.CodeTaken,
+			fmt.Sprintf("sku %q is already used by another product", prod.SKU))
+		return
+	}
+
+	_, exists := keys[prod.ProductId]
+	v.CheckCode(exists, "id", validator.CodeNotFound, "product not found")
+	v.CheckCode(!seen[prod.ProductId], "id", validator.CodeDuplicate, "product is already imported by an earlier line")
+	v.CheckCode(!taken || owner == prod.ProductId, "sku", validator.CodeTaken,
+		fmt.Sprintf("sku %q is already used by another product", prod.SKU))
+}
+
+// ExportProducts writes every product as CSV, in the format ImportProducts reads.
+func (p *ProductsUC) ExportProducts(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+
+	err := p.repo.StreamProducts(func(prod *models.Product) error {
+		return cw.Write([]string{
+			prod.ProductId.String(),
+			prod.SKU,
+			prod.Name,
+			prod.Description,
+			prod.Price.Decimal(),
+			exportCurrency(prod.Price),
+			strconv.Itoa(prod.Stock),
+			prod.Category,
+			prod.Seller,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("error exporting products: %v", err)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportCurrency returns the currency of price, the shop currency when it has none.
+func exportCurrency(price money.Money) string {
+	if price.Currency == "" {
+		return money.DefaultCurrency
+	}
+
+	return price.Currency
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"mime/multipart"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// AddImages uploads images to cloudinary and appends them to those of a product of
+// user, an admin or the seller who owns it, leaving its other images alone. Every
+// image is checked before any is uploaded; the first one rejected is returned as an
+// *products.ImageError. The product, with all its images, is published as
+// events.ProductUpdated.
+func (p *ProductsUC) AddImages(ctx context.Context, id uuid.UUID, img []*multipart.FileHeader, user *models.User) ([]models.Images, error) {
+	if len(img) == 0 {
+		return nil, &products.ImageError{
+			Field:  "images",
+			Code:   validator.CodeRequired,
+			Reason: "at least one product image must be provided",
+		}
+	}
+	for i, header := range img {
+		if msg := checkImage(header); msg != "" {
+			return nil, &products.ImageError{Field: fmt.Sprintf("images[%d]", i), Code: validator.CodeInvalid, Reason: msg}
+		}
+	}
+
+	prod, err := p.ownedProduct(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	uploaded := make([]models.Images, 0, len(img))
+	for _, header := range img {
+		image, err := header.Open()
+		if err != nil {
+			p.discardImages(ctx, uploaded)
+			return nil, fmt.Errorf("error opening image: %v", err)
+		}
+
+		res, err := p.cld.UploadToCloud(ctx, cloudinary.FolderProducts, image)
+		image.Close()
+		if err != nil {
+			p.discardImages(ctx, uploaded)
+			return nil, fmt.Errorf("error uploading image: %w", err)
+		}
+
+		uploaded = append(uploaded, models.Images{PublicId: res.PublicID, Url: res.URL, ProductId: id})
+	}
+
+	if _, err := p.saveImages(ctx, uploaded); err != nil {
+		return nil, err
+	}
+
+	return p.imagesChanged(prod)
+}
+
+// AttachImages appends the images user uploaded directly to storage, with presigned
+// uploads, to those of a product of theirs, or of any product for an admin. They must
+// all be unexpired and not yet attached, else none is and products.ErrUploadNotFound
+// is returned. The product, with all its images, is published as
+// events.ProductUpdated.
+func (p *ProductsUC) AttachImages(id uuid.UUID, publicIds []string, user *models.User) ([]models.Images, error) {
+	prod, err := p.ownedProduct(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.repo.AttachUploads(id, user.ID, publicIds); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, products.ErrUploadNotFound
+		}
+		return nil, fmt.Errorf("error attaching uploads: %v", err)
+	}
+
+	return p.imagesChanged(prod)
+}
+
+// DeleteImage deletes the image with the given public id of a product of user, an
+// admin or the seller who owns it, leaving its other images alone. The record is
+// deleted first, so a failure to remove the asset from cloudinary only leaves an
+// orphan for the reconciliation job. The product, with the images left, is published
+// as events.ProductUpdated.
+func (p *ProductsUC) DeleteImage(ctx context.Context, id uuid.UUID, publicId string, user *models.User) ([]models.Images, error) {
+	prod, err := p.ownedProduct(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.repo.DeleteImage(id, publicId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, products.ErrImageNotFound
+		}
+		return nil, fmt.Errorf("error deleting image from database: %v", err)
+	}
+	p.discardAsset(ctx, publicId)
+
+	return p.imagesChanged(prod)
+}
+
+// product returns the product with id, products.ErrProductNotFound when there is none.
+func (p *ProductsUC) product(id uuid.UUID) (*models.Product, error) {
+	prod, err := p.repo.FetchProductById(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, products.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("error fetching product: %v", err)
+	}
+
+	return prod, nil
+}
+
+// imagesChanged publishes prod, with its images and variants, as events.ProductUpdated
+// and returns its images.
+func (p *ProductsUC) imagesChanged(prod *models.Product) ([]models.Images, error) {
+	images, err := p.repo.FetchImageUrlById(prod.ProductId)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching image url: %v", err)
+	}
+
+	prod.Variants, err = p.repo.FetchVariants(prod.ProductId)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching variants: %v", err)
+	}
+	if images == nil {
+		images = []models.Images{}
+	}
+	prod.Images = images
+
+	p.events.Publish(events.ProductUpdated, *prod)
+
+	return images, nil
+}
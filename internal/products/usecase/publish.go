@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jofosuware/go/shopit/pkg/events"
+)
+
+// PublishScheduled publishes the drafts whose publish time has come and returns how
+// many it published. Each is published as events.ProductUpdated, so webhooks learn
+// that it went on sale.
+func (p *ProductsUC) PublishScheduled(ctx context.Context) (int, error) {
+	ids, err := p.repo.PublishScheduled(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("error publishing products: %v", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	prods, err := p.GetProductsByIds(ids)
+	if err != nil {
+		// the products are published already, only their events are lost
+		return len(ids), err
+	}
+	for _, prod := range prods {
+		p.events.Publish(events.ProductUpdated, *prod)
+	}
+
+	return len(ids), nil
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+)
+
+const (
+	// DefaultSearchWindow is how far back GetZeroResultSearches looks when no time is given.
+	DefaultSearchWindow = 30 * 24 * time.Hour
+
+	// DefaultSearchTermsLimit is how many keywords GetZeroResultSearches returns when no limit is given.
+	DefaultSearchTermsLimit = 20
+
+	// MaxSearchTermsLimit caps the keywords GetZeroResultSearches returns.
+	MaxSearchTermsLimit = 100
+)
+
+// RecordSearch records a search for keyword that found results products and returns
+// the id of the search. Keywords are recorded in lower case with their spaces
+// collapsed, so that the same search typed differently adds up; blank keywords are
+// not recorded and get a nil id.
+func (p *ProductsUC) RecordSearch(keyword string, results int) (uuid.UUID, error) {
+	keyword = normalizeKeyword(keyword)
+	if keyword == "" {
+		return uuid.Nil, nil
+	}
+
+	id, err := p.repo.InsertSearch(keyword, results)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("error saving search: %v", err)
+	}
+
+	return id, nil
+}
+
+// RecordSearchClick records a click on the product productId among the results of
+// the search searchId.
+func (p *ProductsUC) RecordSearchClick(searchId, productId uuid.UUID) error {
+	if _, err := p.repo.FetchProductById(productId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return products.ErrProductNotFound
+		}
+		return fmt.Errorf("error fetching product: %v", err)
+	}
+
+	if err := p.repo.InsertSearchClick(searchId, productId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return products.ErrSearchNotFound
+		}
+		return fmt.Errorf("error saving search click: %v", err)
+	}
+
+	return nil
+}
+
+// GetZeroResultSearches returns the keywords searched since the given time that found
+// no product, searched most first, so merchandisers know what to stock or alias. A
+// zero since looks back DefaultSearchWindow and a non-positive limit falls back to
+// DefaultSearchTermsLimit.
+func (p *ProductsUC) GetZeroResultSearches(since time.Time, limit int) ([]models.SearchTerm, error) {
+	if since.IsZero() {
+		since = time.Now().Add(-DefaultSearchWindow)
+	}
+	if limit <= 0 {
+		limit = DefaultSearchTermsLimit
+	}
+	if limit > MaxSearchTermsLimit {
+		limit = MaxSearchTermsLimit
+	}
+
+	terms, err := p.repo.FetchZeroResultSearches(since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching searches: %v", err)
+	}
+
+	return terms, nil
+}
+
+// normalizeKeyword lower cases keyword, collapses its spaces and caps its length.
+func normalizeKeyword(keyword string) string {
+	k := []rune(strings.ToLower(strings.Join(strings.Fields(keyword), " ")))
+	if len(k) > models.MaxSearchKeyword {
+		k = k[:models.MaxSearchKeyword]
+	}
+
+	return strings.TrimSpace(string(k))
+}
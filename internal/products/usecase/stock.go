@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+)
+
+const (
+	// DefaultLowStockLimit is how many products GetLowStock returns when no limit is given.
+	DefaultLowStockLimit = 50
+
+	// MaxLowStockLimit caps the products GetLowStock returns.
+	MaxLowStockLimit = 200
+)
+
+// GetLowStock returns the products, and variants, with at most the low-stock threshold
+// of their product left, fewest units first. A non-positive limit falls back to
+// DefaultLowStockLimit.
+func (p *ProductsUC) GetLowStock(limit int) ([]models.LowStockItem, error) {
+	if limit <= 0 {
+		limit = DefaultLowStockLimit
+	}
+	if limit > MaxLowStockLimit {
+		limit = MaxLowStockLimit
+	}
+
+	items, err := p.repo.FetchLowStock(limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching low stock: %v", err)
+	}
+
+	return items, nil
+}
+
+// SetLowStockThreshold sets the stock at or under which a product, or any of its
+// variants, is running out. Admins are alerted when an order or a restock brings it
+// there. It returns products.ErrProductNotFound when there is no such product.
+func (p *ProductsUC) SetLowStockThreshold(productId uuid.UUID, threshold int) error {
+	if err := p.repo.UpdateLowStockThreshold(productId, threshold); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return products.ErrProductNotFound
+		}
+		return fmt.Errorf("error updating low-stock threshold: %v", err)
+	}
+
+	return nil
+}
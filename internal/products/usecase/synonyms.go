@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+)
+
+// MaxSearchKeywords caps the keywords a search matches product names with, the
+// keyword searched included.
+const MaxSearchKeywords = 10
+
+// GetSynonyms returns every synonym set.
+func (p *ProductsUC) GetSynonyms() ([]models.SynonymSet, error) {
+	sets, err := p.repo.FetchSynonyms()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching synonyms: %v", err)
+	}
+
+	return sets, nil
+}
+
+// CreateSynonyms saves s, its terms normalized like searched keywords. A term can
+// only be in one set.
+func (p *ProductsUC) CreateSynonyms(s models.SynonymSet) (*models.SynonymSet, error) {
+	s.Terms = normalizeTerms(s.Terms)
+	if err := p.checkSynonyms(uuid.Nil, s.Terms); err != nil {
+		return nil, err
+	}
+
+	set, err := p.repo.InsertSynonyms(&s)
+	if err != nil {
+		return nil, fmt.Errorf("error saving synonyms: %v", err)
+	}
+
+	return &set, nil
+}
+
+// UpdateSynonyms replaces the terms of the synonym set id with those of s.
+func (p *ProductsUC) UpdateSynonyms(id uuid.UUID, s models.SynonymSet) (*models.SynonymSet, error) {
+	s.Terms = normalizeTerms(s.Terms)
+	if err := p.checkSynonyms(id, s.Terms); err != nil {
+		return nil, err
+	}
+
+	set, err := p.repo.UpdateSynonyms(id, &s)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, products.ErrSynonymsNotFound
+		}
+		return nil, fmt.Errorf("error updating synonyms: %v", err)
+	}
+
+	return &set, nil
+}
+
+// DeleteSynonyms deletes the synonym set id.
+func (p *ProductsUC) DeleteSynonyms(id uuid.UUID) error {
+	if err := p.repo.DeleteSynonyms(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return products.ErrSynonymsNotFound
+		}
+		return fmt.Errorf("error deleting synonyms: %v", err)
+	}
+
+	return nil
+}
+
+// checkSynonyms fails with products.ErrSynonymExists when one of terms is in a set
+// other than the set id.
+func (p *ProductsUC) checkSynonyms(id uuid.UUID, terms []string) error {
+	sets, err := p.GetSynonyms()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sets {
+		if s.ID == id {
+			continue
+		}
+		for _, t := range s.Terms {
+			for _, term := range terms {
+				if t == term {
+					return fmt.Errorf("%w: %q", products.ErrSynonymExists, term)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// searchKeywords returns keyword followed by the keywords meaning the same under
+// sets: keyword with a term of a set, a whole word or words of it, replaced by each
+// other term of the set. "red sneakers" with the set {sneakers, trainers} gives
+// "red sneakers" and "red trainers". At most MaxSearchKeywords are returned.
+func searchKeywords(keyword string, sets []models.SynonymSet) []string {
+	keywords := []string{keyword}
+	seen := map[string]bool{normalizeKeyword(keyword): true}
+	padded := " " + normalizeKeyword(keyword) + " "
+
+	for _, s := range sets {
+		for _, t := range s.Terms {
+			if !strings.Contains(padded, " "+t+" ") {
+				continue
+			}
+
+			for _, other := range s.Terms {
+				k := strings.TrimSpace(strings.Replace(padded, " "+t+" ", " "+other+" ", 1))
+				if seen[k] {
+					continue
+				}
+				if len(keywords) == MaxSearchKeywords {
+					return keywords
+				}
+				seen[k] = true
+				keywords = append(keywords, k)
+			}
+			break
+		}
+	}
+
+	return keywords
+}
+
+// normalizeTerms normalizes terms like searched keywords and drops the blank and
+// repeated ones.
+func normalizeTerms(terms []string) []string {
+	var normalized []string
+	seen := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		t = normalizeKeyword(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		normalized = append(normalized, t)
+	}
+
+	return normalized
+}
@@ -1,62 +1,119 @@
 package usecase
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/categories"
 	"github.com/jofosuware/go/shopit/internal/models"
 	"github.com/jofosuware/go/shopit/internal/products"
 	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/sheets"
+	"github.com/jofosuware/go/shopit/pkg/validator"
 )
 
+// MaxImageSize is the largest product image accepted, in bytes.
+const MaxImageSize = 5 << 20
+
+const (
+	// DefaultAdminProductsLimit is how many products a page of GetAdminProducts has when no limit is given.
+	DefaultAdminProductsLimit = 20
+
+	// MaxAdminProductsLimit caps the products a page of GetAdminProducts has.
+	MaxAdminProductsLimit = 100
+)
+
+// imageTypes are the sniffed content types accepted for product images.
+var imageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
 // ProductsUC provides product-related use cases.
 type ProductsUC struct {
-	cld  cloudinary.CloudUploader
-	repo products.Repo
+	cld    cloudinary.CloudUploader
+	repo   products.Repo
+	cats   categories.Repo
+	events *events.Bus
+	sheet  *sheets.Sheet
 }
 
-// NewProductsUC returns a new ProductsUC.
-func NewProductsUC(cld cloudinary.CloudUploader, repo products.Repo) *ProductsUC {
+// NewProductsUC returns a new ProductsUC. Products are filed under the categories of
+// cats, and their updates are published on bus. The catalog is synced from sheet; a
+// nil sheet disables the sync.
+func NewProductsUC(cld cloudinary.CloudUploader, repo products.Repo, cats categories.Repo, bus *events.Bus,
+	sheet *sheets.Sheet) *ProductsUC {
 	return &ProductsUC{
-		repo: repo,
-		cld:  cld,
+		repo:   repo,
+		cld:    cld,
+		cats:   cats,
+		events: bus,
+		sheet:  sheet,
 	}
 }
 
-// CreateProduct creates a new product and uploads its images to cloudinary.
-func (p *ProductsUC) CreateProduct(prod models.Product, img []*multipart.FileHeader) (*models.ProdResponse, error) {
+// CreateProduct creates a new product and uploads its images to cloudinary. The
+// product is filed under the category of its category id, or else of its category
+// name, which must exist. A product without a status is published. It returns a
+// products.ValidationError when the product fails the checks of ValidateProduct.
+func (p *ProductsUC) CreateProduct(ctx context.Context, prod models.Product, img []*multipart.FileHeader) (*models.ProdResponse, error) {
+	prod.ProductId = uuid.Nil
+	if err := p.validate(&prod, img); err != nil {
+		return nil, err
+	}
+	if prod.Status == "" {
+		prod.Status = models.ProductPublished
+	}
+
+	variants := prod.Variants
 	prod, err := p.repo.InsertProduct(&prod)
 	if err != nil {
 		return nil, fmt.Errorf("error saving product: %v", err)
 	}
 
+	if len(variants) > 0 {
+		prod.Variants, err = p.repo.SaveVariants(prod.ProductId, variants)
+		if err != nil {
+			return nil, fmt.Errorf("error saving variants: %v", err)
+		}
+	}
+
 	// Upload images to cloudinary and save their urls
+	var uploaded []models.Images
 	for _, imgHeader := range img {
 		image, err := imgHeader.Open()
 		if err != nil {
+			p.discardImages(ctx, uploaded)
 			return nil, fmt.Errorf("error opening image: %v", err)
 		}
 
-		res, err := p.cld.UploadToCloud("products", image)
+		res, err := p.cld.UploadToCloud(ctx, cloudinary.FolderProducts, image)
+		image.Close()
 		if err != nil {
-			return nil, fmt.Errorf("error uploading image: %v", err)
+			p.discardImages(ctx, uploaded)
+			return nil, fmt.Errorf("error uploading image: %w", err)
 		}
 
-		var img models.Images
-
-		img.PublicId = res.PublicID
-		img.Url = res.URL
-		img.ProductId = prod.ProductId
+		uploaded = append(uploaded, models.Images{PublicId: res.PublicID, Url: res.URL, ProductId: prod.ProductId})
+	}
 
-		// saving image url
-		img, err = p.repo.InsertImageUrl(&img)
+	if len(uploaded) > 0 {
+		prod.Images, err = p.saveImages(ctx, uploaded)
 		if err != nil {
-			return nil, fmt.Errorf("error saving image url: %v", err)
+			return nil, err
 		}
-
-		prod.Images = append(prod.Images, img)
-		image.Close()
 	}
 
 	pr := models.ProdResponse{
@@ -67,9 +124,144 @@ func (p *ProductsUC) CreateProduct(prod models.Product, img []*multipart.FileHea
 	return &pr, nil
 }
 
-// GetProducts returns products filtered by keyword with pagination.
-func (p *ProductsUC) GetProducts(keyword string, page int) (*models.GetProd, error) {
-	prods, count, err := p.repo.FetchProductByName(keyword, page)
+// ValidateProduct checks a product the way it would be saved: its fields, its images,
+// its category and the uniqueness of its SKU. Nothing is stored. A product with an id
+// is validated as an update of that product, so its own SKU is not a conflict and new
+// images are optional.
+func (p *ProductsUC) ValidateProduct(prod models.Product, img []*multipart.FileHeader) (*models.ProductValidationReport, error) {
+	err := p.validate(&prod, img)
+
+	var invalid *products.ValidationError
+	if errors.As(err, &invalid) {
+		return &models.ProductValidationReport{Errors: invalid.Errors, Codes: invalid.Codes}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProductValidationReport{Valid: true}, nil
+}
+
+// validate runs the checks of ValidateProduct on prod, filing it under its category.
+// It returns a products.ValidationError listing what is wrong when the product is
+// invalid.
+func (p *ProductsUC) validate(prod *models.Product, img []*multipart.FileHeader) error {
+	v := validator.New()
+
+	checkProduct(v, prod)
+
+	cats, err := p.categories()
+	if err != nil {
+		return err
+	}
+	cats.check(v, prod)
+
+	v.CheckCode(len(img) > 0 || prod.ProductId != uuid.Nil, "images", validator.CodeRequired,
+		"at least one product image must be provided")
+	for i, header := range img {
+		if msg := checkImage(header); msg != "" {
+			v.AddError(fmt.Sprintf("images[%d]", i), msg)
+		}
+	}
+
+	if prod.SKU != "" {
+		exists, err := p.repo.SKUExists(prod.SKU, prod.ProductId)
+		if err != nil {
+			return fmt.Errorf("error checking sku: %v", err)
+		}
+		v.CheckCode(!exists, "sku", validator.CodeTaken,
+			fmt.Sprintf("sku %q is already used by another product", prod.SKU))
+	}
+
+	if !v.Valid() {
+		return &products.ValidationError{Errors: v.Errors, Codes: v.Codes}
+	}
+
+	return nil
+}
+
+// checkProduct records in v what is wrong with the fields of a product.
+func checkProduct(v *validator.Validator, prod *models.Product) {
+	v.CheckCode(prod.Name != "", "name", validator.CodeRequired, "product name must be provided")
+	v.CheckCode(utf8.RuneCountInString(prod.Name) <= 64, "name", validator.CodeTooLong,
+		"product name must not be more than 64 characters")
+	v.CheckCode(prod.Description != "", "description", validator.CodeRequired, "product description must be provided")
+	v.CheckCode(utf8.RuneCountInString(prod.Description) <= 1000, "description", validator.CodeTooLong,
+		"product description must not be more than 1000 characters")
+	v.CheckCode(prod.Seller != "", "seller", validator.CodeRequired, "product seller must be provided")
+	v.CheckCode(utf8.RuneCountInString(prod.Seller) <= 250, "seller", validator.CodeTooLong,
+		"product seller must not be more than 250 characters")
+	v.CheckCode(prod.Price.IsPositive(), "price", validator.CodeTooSmall, "product price must be greater than zero")
+	v.CheckCode(prod.Stock >= 0, "stock", validator.CodeTooSmall, "product stock must not be negative")
+	v.CheckCode(prod.Category != "" || prod.CategoryId.Valid, "category", validator.CodeRequired,
+		"product category must be provided")
+	v.CheckCode(utf8.RuneCountInString(prod.SKU) <= 64, "sku", validator.CodeTooLong, "product sku must not be more than 64 characters")
+
+	for key, msg := range prod.VariantErrors() {
+		v.AddError(key, msg)
+	}
+}
+
+// checkImage returns what is wrong with an uploaded product image, or "" if it is acceptable.
+func checkImage(header *multipart.FileHeader) string {
+	if header.Size > MaxImageSize {
+		return fmt.Sprintf("%s is larger than %d MB", header.Filename, MaxImageSize>>20)
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		return fmt.Sprintf("%s could not be read", header.Filename)
+	}
+	defer f.Close()
+
+	// DetectContentType considers at most the first 512 bytes
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err == io.EOF {
+		return fmt.Sprintf("%s is empty", header.Filename)
+	}
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return fmt.Sprintf("%s could not be read", header.Filename)
+	}
+
+	if !imageTypes[http.DetectContentType(buf[:n])] {
+		return fmt.Sprintf("%s is not a jpeg, png, gif or webp image", header.Filename)
+	}
+
+	return ""
+}
+
+// GetProducts returns products filtered by keyword with pagination. The keyword
+// also finds the products named with its synonyms. When category, an id or a name,
+// is set only products of that category and the categories below it are returned.
+func (p *ProductsUC) GetProducts(keyword, category string, page int) (*models.GetProd, error) {
+	var categoryID uuid.NullUUID
+	if category != "" {
+		cats, err := p.categories()
+		if err != nil {
+			return nil, err
+		}
+
+		filter := models.Product{Category: category}
+		if id, err := uuid.Parse(category); err == nil {
+			filter.CategoryId = uuid.NullUUID{UUID: id, Valid: true}
+		}
+		if err := cats.file(&filter); err != nil {
+			return nil, err
+		}
+		categoryID = filter.CategoryId
+	}
+
+	var keywords []string
+	if strings.TrimSpace(keyword) != "" {
+		sets, err := p.GetSynonyms()
+		if err != nil {
+			return nil, err
+		}
+		keywords = searchKeywords(keyword, sets)
+	}
+
+	prods, count, err := p.repo.FetchProductByName(models.ProductFilter{Keywords: keywords, Category: categoryID, Page: page})
 	if err != nil {
 		return nil, fmt.Errorf("error fetching products: %v", err)
 	}
@@ -93,20 +285,68 @@ func (p *ProductsUC) GetProducts(keyword string, page int) (*models.GetProd, err
 	return &jr, nil
 }
 
-// GetAdminProducts returns all products for admin.
-func (p *ProductsUC) GetAdminProducts() ([]*models.Product, error) {
-	prods, err := p.repo.FetchAllProducts()
+// GetAdminProducts returns a page of products in every status, hidden ones included,
+// with their images in one query. A keyword finds the products whose name contains it, without
+// synonyms. Only the products of owner are listed when it is set. A non-positive limit
+// falls back to DefaultAdminProductsLimit and an empty sort lists the newest first.
+func (p *ProductsUC) GetAdminProducts(keyword, sort string, page, limit int, owner uuid.NullUUID) (*models.GetProd, error) {
+	if limit <= 0 {
+		limit = DefaultAdminProductsLimit
+	}
+	if limit > MaxAdminProductsLimit {
+		limit = MaxAdminProductsLimit
+	}
+	if sort == "" {
+		sort = "-" + models.ProductsDate
+	}
+
+	filter := models.ProductFilter{Owner: owner, Page: page, PerPage: limit, Sort: sort, Hidden: true}
+	if k := strings.TrimSpace(keyword); k != "" {
+		filter.Keywords = []string{k}
+	}
+
+	prods, count, err := p.repo.FetchProductByName(filter)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching products: %v", err)
 	}
 
-	return prods, nil
+	if len(prods) > 0 {
+		ids := make([]uuid.UUID, len(prods))
+		for i, prod := range prods {
+			ids[i] = prod.ProductId
+		}
+
+		images, err := p.repo.FetchImagesByProductIds(ids)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching image url: %v", err)
+		}
+
+		byProduct := make(map[uuid.UUID][]models.Images, len(prods))
+		for _, img := range images {
+			byProduct[img.ProductId] = append(byProduct[img.ProductId], img)
+		}
+		for i := range prods {
+			prods[i].Images = byProduct[prods[i].ProductId]
+		}
+	}
+
+	return &models.GetProd{
+		Success:               true,
+		ProductCount:          count,
+		ResPerPage:            limit,
+		FilteredProductsCount: len(prods),
+		Products:              prods,
+	}, nil
 }
 
-// GetSingleProduct returns a product by ID, including images and reviews.
+// GetSingleProduct returns a product by ID, including images, reviews and variants.
+// It returns products.ErrProductNotFound when there is no such product.
 func (p *ProductsUC) GetSingleProduct(id uuid.UUID) (*models.Product, error) {
 	prod, err := p.repo.FetchProductById(id)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, products.ErrProductNotFound
+		}
 		return nil, fmt.Errorf("error fetching product: %v", err)
 	}
 
@@ -115,19 +355,100 @@ func (p *ProductsUC) GetSingleProduct(id uuid.UUID) (*models.Product, error) {
 		return nil, fmt.Errorf("error fetching image url: %v", err)
 	}
 
-	review, err := p.repo.FetchReviewById(prod.ProductId)
+	review, err := p.repo.FetchReviewById(prod.ProductId, models.ReviewsOldest)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching review: %v", err)
 	}
 
+	variants, err := p.repo.FetchVariants(prod.ProductId)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching variants: %v", err)
+	}
+
 	prod.Images = img
 	prod.Reviews = review
+	prod.Variants = variants
 
 	return prod, nil
 }
 
-// UpdateProduct updates a product's details and images by ID.
-func (p *ProductsUC) UpdateProduct(id uuid.UUID, prod models.Product, img []*multipart.File) (*models.ProdResponse, error) {
+// GetProductsByIds returns the products with the given ids, with their images, in
+// two queries whatever the number of ids. Ids without a product are skipped.
+func (p *ProductsUC) GetProductsByIds(ids []uuid.UUID) ([]*models.Product, error) {
+	prods, err := p.repo.FetchProductsByIds(ids)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching products: %v", err)
+	}
+	if len(prods) == 0 {
+		return nil, nil
+	}
+
+	found := make([]uuid.UUID, len(prods))
+	for i, prod := range prods {
+		found[i] = prod.ProductId
+	}
+
+	images, err := p.repo.FetchImagesByProductIds(found)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching image url: %v", err)
+	}
+
+	byProduct := make(map[uuid.UUID][]models.Images, len(prods))
+	for _, img := range images {
+		byProduct[img.ProductId] = append(byProduct[img.ProductId], img)
+	}
+	for _, prod := range prods {
+		prod.Images = byProduct[prod.ProductId]
+	}
+
+	return prods, nil
+}
+
+// GetRecommendations returns up to limit products to suggest alongside productIds:
+// those most often bought with them, then others of their categories rated closest
+// to them. Duplicate ids are ignored.
+func (p *ProductsUC) GetRecommendations(productIds []uuid.UUID, limit int) ([]models.Recommendation, error) {
+	seen := make(map[uuid.UUID]bool, len(productIds))
+	ids := make([]uuid.UUID, 0, len(productIds))
+	for _, id := range productIds {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 || limit <= 0 {
+		return []models.Recommendation{}, nil
+	}
+
+	recs, err := p.repo.FetchRelatedProducts(ids, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching related products: %v", err)
+	}
+	if recs == nil {
+		recs = []models.Recommendation{}
+	}
+
+	return recs, nil
+}
+
+// UpdateProduct updates a product's details and images by ID for user, who must be an
+// admin or the seller who owns it; the product keeps its owner. The product is filed
+// under its category like on creation, and returns a products.ValidationError when it
+// fails the checks of ValidateProduct. The updated product is published as
+// events.ProductUpdated.
+func (p *ProductsUC) UpdateProduct(ctx context.Context, id uuid.UUID, prod models.Product, img []*multipart.File, user *models.User) (*models.ProdResponse, error) {
+	existing, err := p.ownedProduct(id, user)
+	if err != nil {
+		return nil, err
+	}
+	prod.UserId = existing.UserId
+
+	prod.ProductId = id
+	if err := p.validate(&prod, nil); err != nil {
+		return nil, err
+	}
+
 	// Fetch existing images
 	images, err := p.repo.FetchImageUrlById(id)
 	if err != nil {
@@ -137,9 +458,9 @@ func (p *ProductsUC) UpdateProduct(id uuid.UUID, prod models.Product, img []*mul
 	if len(img) > 0 {
 		// Delete existing images from cloudinary
 		for _, img := range images {
-			_, err := p.cld.Destroy(img.PublicId)
+			_, err := p.cld.Destroy(ctx, img.PublicId)
 			if err != nil {
-				return nil, fmt.Errorf("error deleting image from cloudinary: %v", err)
+				return nil, fmt.Errorf("error deleting image from cloudinary: %w", err)
 			}
 		}
 
@@ -150,35 +471,44 @@ func (p *ProductsUC) UpdateProduct(id uuid.UUID, prod models.Product, img []*mul
 		}
 
 		// Upload new images to cloudinary and save their urls
-		images = []models.Images{}
+		var uploaded []models.Images
 		for _, img := range img {
-			res, err := p.cld.UploadToCloud("products", img)
+			res, err := p.cld.UploadToCloud(ctx, cloudinary.FolderProducts, img)
 			if err != nil {
-				return nil, fmt.Errorf("error uploading image to cloudinary: %v", err)
+				p.discardImages(ctx, uploaded)
+				return nil, fmt.Errorf("error uploading image to cloudinary: %w", err)
 			}
 
-			var img models.Images
-			img.PublicId = res.PublicID
-			img.Url = res.URL
-			img.ProductId = id
-
-			// Save image url to database
-			img, err = p.repo.InsertImageUrl(&img)
-			if err != nil {
-				return nil, fmt.Errorf("error saving image url: %v", err)
-			}
+			uploaded = append(uploaded, models.Images{PublicId: res.PublicID, Url: res.URL, ProductId: id})
+		}
 
-			images = append(images, img)
+		images, err = p.saveImages(ctx, uploaded)
+		if err != nil {
+			return nil, err
 		}
 	}
 
+	variants := prod.Variants
 	prod, err = p.repo.UpdateProduct(id, &prod)
 	if err != nil {
 		return nil, fmt.Errorf("error updating product: %v", err)
 	}
 
+	// nil leaves the variants alone, an empty list removes them
+	if variants != nil {
+		prod.Variants, err = p.repo.SaveVariants(id, variants)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, products.ErrVariantNotFound
+			}
+			return nil, fmt.Errorf("error saving variants: %v", err)
+		}
+	}
+
 	prod.Images = images
 
+	p.events.Publish(events.ProductUpdated, prod)
+
 	res := models.ProdResponse{
 		Success: true,
 		Product: prod,
@@ -187,8 +517,13 @@ func (p *ProductsUC) UpdateProduct(id uuid.UUID, prod models.Product, img []*mul
 	return &res, nil
 }
 
-// DeleteProduct deletes a product and its images by ID.
-func (p *ProductsUC) DeleteProduct(id uuid.UUID) error {
+// DeleteProduct deletes a product and its images by ID for user, who must be an admin
+// or the seller who owns it.
+func (p *ProductsUC) DeleteProduct(ctx context.Context, id uuid.UUID, user *models.User) error {
+	if _, err := p.ownedProduct(id, user); err != nil {
+		return err
+	}
+
 	// Fetch existing images
 	img, err := p.repo.FetchImageUrlById(id)
 	if err != nil {
@@ -197,9 +532,9 @@ func (p *ProductsUC) DeleteProduct(id uuid.UUID) error {
 
 	// Delete existing images from cloudinary
 	for _, img := range img {
-		_, err := p.cld.Destroy(img.PublicId)
+		_, err := p.cld.Destroy(ctx, img.PublicId)
 		if err != nil {
-			return fmt.Errorf("error deleting image from cloudinary: %v", err)
+			return fmt.Errorf("error deleting image from cloudinary: %w", err)
 		}
 	}
 
@@ -212,80 +547,174 @@ func (p *ProductsUC) DeleteProduct(id uuid.UUID) error {
 	return nil
 }
 
-// CreateProductReview creates and persists a product review, updating aggregate ratings.
-func (p *ProductsUC) CreateProductReview(review models.Reviews) error {
-	product, err := p.repo.FetchProductById(review.ProductId)
+// ownedProduct returns the product of id when user may change it: admins change every
+// product and sellers the ones they own. It returns products.ErrProductNotFound when
+// it does not exist and products.ErrNotProductOwner when it is not one of user.
+func (p *ProductsUC) ownedProduct(id uuid.UUID, user *models.User) (*models.Product, error) {
+	prod, err := p.repo.FetchProductById(id)
 	if err != nil {
-		return fmt.Errorf("error fetching product: %v", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, products.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("error fetching product: %v", err)
 	}
 
-	reviews, err := p.repo.FetchReviewById(review.ProductId)
-	if err != nil {
-		return fmt.Errorf("error fetching reviews: %v", err)
+	if user.Role != models.RoleAdmin && prod.UserId != user.ID {
+		return nil, products.ErrNotProductOwner
 	}
 
-	reviews = append(reviews, review)
-	product.NumOfReviews = len(reviews)
+	return prod, nil
+}
 
-	err = p.repo.InsertReview(&review)
-	if err != nil {
-		return fmt.Errorf("error inserting reviews: %v", err)
+// CreateProductReview creates and persists a product review with up to
+// models.MaxReviewImages images, updating aggregate ratings. Every image is checked
+// before any is uploaded; the first one rejected is returned as an
+// *products.ImageError. It returns products.ErrProductNotFound when there is no such
+// product.
+func (p *ProductsUC) CreateProductReview(ctx context.Context, review models.Reviews, img []*multipart.FileHeader) error {
+	if len(img) > models.MaxReviewImages {
+		return &products.ImageError{
+			Field:  "images",
+			Code:   validator.CodeTooMany,
+			Reason: fmt.Sprintf("a review must not have more than %d images", models.MaxReviewImages),
+		}
+	}
+	for i, header := range img {
+		if msg := checkImage(header); msg != "" {
+			return &products.ImageError{Field: fmt.Sprintf("images[%d]", i), Code: validator.CodeInvalid, Reason: msg}
+		}
 	}
 
-	var totalRating = 0
-	for _, rv := range reviews {
-		totalRating += rv.Rating
+	if _, err := p.product(review.ProductId); err != nil {
+		return err
 	}
 
-	product.Ratings = totalRating / len(reviews)
-	_, err = p.repo.UpdateProduct(review.ProductId, product)
+	for _, header := range img {
+		image, err := header.Open()
+		if err != nil {
+			p.discardReviewImages(ctx, review.Images)
+			return fmt.Errorf("error opening image: %v", err)
+		}
+
+		res, err := p.cld.UploadToCloud(ctx, cloudinary.FolderReviews, image)
+		image.Close()
+		if err != nil {
+			p.discardReviewImages(ctx, review.Images)
+			return fmt.Errorf("error uploading image: %w", err)
+		}
+
+		review.Images = append(review.Images, models.ReviewImage{PublicId: res.PublicID, Url: res.URL, CreatedAt: time.Now()})
+	}
 
+	err := p.repo.InsertReview(&review)
 	if err != nil {
-		return fmt.Errorf("error updating product: %v", err)
+		p.discardReviewImages(ctx, review.Images)
+		return fmt.Errorf("error inserting reviews: %v", err)
+	}
+
+	if err := p.repo.UpdateRatings(review.ProductId); err != nil {
+		return fmt.Errorf("error updating product ratings: %v", err)
 	}
 
 	return nil
 }
 
-// GetProductReviews returns all reviews for a product.
-func (p *ProductsUC) GetProductReviews(id uuid.UUID) ([]models.Reviews, error) {
-	reviews, err := p.repo.FetchReviewById(id)
+// GetProductReviews returns all reviews for a product with their images and votes, in
+// the order of sort, one of models.ReviewSorts, oldest first when it is "".
+func (p *ProductsUC) GetProductReviews(id uuid.UUID, sort string) ([]models.Reviews, error) {
+	reviews, err := p.repo.FetchReviewById(id, sort)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching reviews: %v", err)
 	}
 
+	ids := make([]uuid.UUID, len(reviews))
+	for i, r := range reviews {
+		ids[i] = r.ReviewsId
+	}
+
+	images, err := p.repo.FetchReviewImages(ids)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching review images: %v", err)
+	}
+
+	byReview := make(map[uuid.UUID][]models.ReviewImage)
+	for _, img := range images {
+		byReview[img.ReviewId] = append(byReview[img.ReviewId], img)
+	}
+	for i := range reviews {
+		reviews[i].Images = byReview[reviews[i].ReviewsId]
+	}
+
 	return reviews, nil
 }
 
-// DeleteProductReview deletes a review and updates the product's ratings.
+// GetReviewsByProductIds returns the reviews of the given products in one query,
+// keyed by product id. Products without reviews are left out.
+func (p *ProductsUC) GetReviewsByProductIds(ids []uuid.UUID) (map[uuid.UUID][]models.Reviews, error) {
+	reviews, err := p.repo.FetchReviewsByProductIds(ids)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reviews: %v", err)
+	}
+
+	byProduct := make(map[uuid.UUID][]models.Reviews)
+	for _, r := range reviews {
+		byProduct[r.ProductId] = append(byProduct[r.ProductId], r)
+	}
+
+	return byProduct, nil
+}
+
+// DeleteProductReview deletes a review and updates the product's ratings. It returns
+// products.ErrReviewNotFound when there is no such review and
+// products.ErrProductNotFound when there is no such product.
 func (p *ProductsUC) DeleteProductReview(productId uuid.UUID, reviewId uuid.UUID) error {
 	err := p.repo.DeleteReviewById(reviewId)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return products.ErrReviewNotFound
+		}
 		return fmt.Errorf("error deleting review: %v", err)
 	}
 
-	product, err := p.repo.FetchProductById(productId)
-	if err != nil {
-		return fmt.Errorf("error fetching product: %v", err)
+	if err := p.repo.UpdateRatings(productId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return products.ErrProductNotFound
+		}
+		return fmt.Errorf("error updating product ratings: %v", err)
 	}
 
-	reviews, err := p.repo.FetchReviewById(productId)
-	if err != nil {
-		return fmt.Errorf("error fetching reviews: %v", err)
+	return nil
+}
+
+// discardReviewImages removes the uploaded images of a review that could not be saved.
+func (p *ProductsUC) discardReviewImages(ctx context.Context, images []models.ReviewImage) {
+	for _, img := range images {
+		p.discardAsset(ctx, img.PublicId)
 	}
+}
 
-	var totalRating = 0
-	for _, rv := range reviews {
-		totalRating += rv.Rating
+// saveImages saves the urls of uploaded product images in one statement, removing the
+// images when they could not be saved.
+func (p *ProductsUC) saveImages(ctx context.Context, uploaded []models.Images) ([]models.Images, error) {
+	images, err := p.repo.InsertImageUrls(uploaded)
+	if err != nil {
+		p.discardImages(ctx, uploaded)
+		return nil, fmt.Errorf("error saving image url: %v", err)
 	}
 
-	product.Ratings = totalRating / len(reviews)
-	product.NumOfReviews = len(reviews)
+	return images, nil
+}
 
-	_, err = p.repo.UpdateProduct(productId, product)
-	if err != nil {
-		return fmt.Errorf("error updating product: %v", err)
+// discardImages removes uploaded product images that could not be saved.
+func (p *ProductsUC) discardImages(ctx context.Context, images []models.Images) {
+	for _, img := range images {
+		p.discardAsset(ctx, img.PublicId)
 	}
+}
 
-	return nil
+// discardAsset removes an uploaded image whose database record could not be saved.
+// It outlives a cancelled ctx, and failures are ignored: the orphan reconciliation job
+// removes whatever is left behind.
+func (p *ProductsUC) discardAsset(ctx context.Context, publicID string) {
+	_, _ = p.cld.Destroy(context.WithoutCancel(ctx), publicID)
 }
@@ -1,14 +1,33 @@
 package usecase_test
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/cloudinary/cloudinary-go/api/uploader"
 	"github.com/google/uuid"
+	mockCategories "github.com/jofosuware/go/shopit/internal/categories/mocks"
 	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
 	mockProd "github.com/jofosuware/go/shopit/internal/products/mocks"
 	"github.com/jofosuware/go/shopit/internal/products/usecase"
 	mockCloudinary "github.com/jofosuware/go/shopit/pkg/cloudinary/mocks"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/sheets"
+	"github.com/jofosuware/go/shopit/pkg/validator"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -16,7 +35,7 @@ import (
 // 	cld := mockCloudinary.NewCloudUploader(t)
 // 	repo := mockProd.NewRepo(t)
 
-// 	u := usecase.NewProductsUC(cld, repo)
+// 	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
 
 // 	t.Run("Create Product successfully", func(t *testing.T) {
 // 		formData := url.Values{
@@ -66,10 +85,10 @@ import (
 // 		img.ProductId = p.ProductId
 
 // 		repo.On("InsertProduct", &p).Return(p, nil)
-// 		cld.On("UploadToCloud", "products", buf).Return(&res, nil)
+// 		cld.On("UploadToCloud", mock.Anything, "products", buf).Return(&res, nil)
 // 		repo.On("InsertImageUrl", &img).Return(img, nil)
 
-// 		resp, err := u.CreateProduct(p, i)
+// 		resp, err := u.CreateProduct(context.Background(), p, i)
 // 		require.NoError(t, err)
 
 // 		assert.NotNil(t, resp)
@@ -77,11 +96,46 @@ import (
 // 	})
 // }
 
+var (
+	electronics = models.Category{CategoryId: uuid.New(), Name: "Electronics"}
+	cameras     = models.Category{CategoryId: uuid.New(), Name: "Cameras", ParentId: uuid.NullUUID{UUID: electronics.CategoryId, Valid: true}}
+	clothes     = models.Category{CategoryId: uuid.New(), Name: "Clothes/Shoes"}
+
+	admin = &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+)
+
+// newProduct returns a product named name, filed under category, that passes validation.
+func newProduct(name, category string) models.Product {
+	return models.Product{
+		Name:        name,
+		Description: "A " + strings.ToLower(name),
+		Price:       money.Of(10),
+		Category:    category,
+		Seller:      "Ebay",
+	}
+}
+
+// productImage returns the image of a new product and expects it to be uploaded and saved.
+func productImage(t *testing.T, cld *mockCloudinary.CloudUploader, repo *mockProd.Repo) []*multipart.FileHeader {
+	cld.On("UploadToCloud", mock.Anything, "products", mock.Anything).
+		Return(&uploader.UploadResult{PublicID: "products/1", URL: "https://cdn/1.png"}, nil).Once()
+	repo.On("InsertImageUrls", mock.Anything).Return([]models.Images{{PublicId: "products/1"}}, nil).Once()
+
+	return fileHeaders(t, map[string][]byte{"product.png": []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")})
+}
+
+// newCategoryRepo returns a category repository holding electronics, cameras and clothes.
+func newCategoryRepo(t *testing.T) *mockCategories.Repo {
+	cats := mockCategories.NewRepo(t)
+	cats.On("FetchCategories").Return([]models.Category{cameras, clothes, electronics}, nil).Maybe()
+	return cats
+}
+
 func TestGetProducts(t *testing.T) {
 	cld := mockCloudinary.NewCloudUploader(t)
 	repo := mockProd.NewRepo(t)
 
-	u := usecase.NewProductsUC(cld, repo)
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
 
 	t.Run("Get Products successfully", func(t *testing.T) {
 		var products []models.Product
@@ -89,35 +143,115 @@ func TestGetProducts(t *testing.T) {
 		products = append(products, models.Product{
 			ProductId:   uuid.New(),
 			Name:        "test",
-			Price:       100,
+			Price:       money.Of(100),
 			Description: "test",
 			Category:    "home",
 			Stock:       100,
 			Seller:      "test",
 		})
 
-		repo.On("FetchProductByName", "", 1).Return(products, 1, nil)
+		repo.On("FetchProductByName", models.ProductFilter{Page: 1}).Return(products, 1, nil)
 		repo.On("FetchImageUrlById", products[0].ProductId).Return([]models.Images{}, nil)
 
-		res, err := u.GetProducts("", 1)
+		res, err := u.GetProducts("", "", 1)
 
 		require.NoError(t, err)
 		assert.NotNil(t, res)
 	})
+
+	t.Run("Filtered by category name or id", func(t *testing.T) {
+		filter := uuid.NullUUID{UUID: electronics.CategoryId, Valid: true}
+		repo.On("FetchSynonyms").Return([]models.SynonymSet{}, nil).Twice()
+		repo.On("FetchProductByName", models.ProductFilter{Keywords: []string{"lens"}, Category: filter, Page: 1}).Return([]models.Product{}, 0, nil).Twice()
+
+		_, err := u.GetProducts("lens", "electronics", 1)
+		require.NoError(t, err)
+
+		_, err = u.GetProducts("lens", electronics.CategoryId.String(), 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown category", func(t *testing.T) {
+		_, err := u.GetProducts("", "Gadgets", 1)
+		assert.ErrorIs(t, err, products.ErrCategoryNotFound)
+	})
+
+	t.Run("Keyword finds the products named with its synonyms", func(t *testing.T) {
+		repo := mockProd.NewRepo(t)
+		u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+		repo.On("FetchSynonyms").Return([]models.SynonymSet{
+			{ID: uuid.New(), Terms: []string{"tv", "television"}},
+			{ID: uuid.New(), Terms: []string{"sneakers", "trainers", "running shoes"}},
+		}, nil).Once()
+		repo.On("FetchProductByName", models.ProductFilter{Keywords: []string{"Red  Sneakers", "red trainers", "red running shoes"}, Page: 1}).
+			Return([]models.Product{}, 0, nil).Once()
+
+		_, err := u.GetProducts("Red  Sneakers", "", 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("Synonyms only replace whole words", func(t *testing.T) {
+		repo := mockProd.NewRepo(t)
+		u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+		repo.On("FetchSynonyms").Return([]models.SynonymSet{{ID: uuid.New(), Terms: []string{"tv", "television"}}}, nil).Once()
+		repo.On("FetchProductByName", models.ProductFilter{Keywords: []string{"tvstand"}, Page: 1}).Return([]models.Product{}, 0, nil).Once()
+
+		_, err := u.GetProducts("tvstand", "", 1)
+		require.NoError(t, err)
+	})
 }
 
 func TestGetAdminProducts(t *testing.T) {
 	cld := mockCloudinary.NewCloudUploader(t)
 	repo := mockProd.NewRepo(t)
 
-	u := usecase.NewProductsUC(cld, repo)
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
 
 	t.Run("Get Admin Products successfully", func(t *testing.T) {
-		repo.On("FetchAllProducts").Return([]*models.Product{}, nil)
-		prods, err := u.GetAdminProducts()
+		first, second := uuid.New(), uuid.New()
+		filter := models.ProductFilter{Keywords: []string{"lens"}, Page: 2, PerPage: 20, Sort: "-stock", Hidden: true}
+		repo.On("FetchProductByName", filter).
+			Return([]models.Product{{ProductId: first}, {ProductId: second, Hidden: true}}, 22, nil).Once()
+		repo.On("FetchImagesByProductIds", []uuid.UUID{first, second}).
+			Return([]models.Images{{ProductId: second, Url: "https://example.com/lens.png"}}, nil).Once()
+
+		res, err := u.GetAdminProducts(" lens ", "-stock", 2, 0, uuid.NullUUID{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 22, res.ProductCount)
+		assert.Equal(t, 20, res.ResPerPage)
+		require.Len(t, res.Products, 2)
+		assert.Empty(t, res.Products[0].Images)
+		assert.Equal(t, "https://example.com/lens.png", res.Products[1].Images[0].Url)
+	})
+
+	t.Run("Newest first by default and limit capped", func(t *testing.T) {
+		filter := models.ProductFilter{Page: 1, PerPage: usecase.MaxAdminProductsLimit, Sort: "-date", Hidden: true}
+		repo.On("FetchProductByName", filter).Return([]models.Product{}, 0, nil).Once()
+
+		res, err := u.GetAdminProducts("", "", 1, 500, uuid.NullUUID{})
+		require.NoError(t, err)
+
+		assert.Empty(t, res.Products)
+	})
+
+	t.Run("Products of an owner", func(t *testing.T) {
+		owner := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+		filter := models.ProductFilter{Owner: owner, Page: 1, PerPage: usecase.DefaultAdminProductsLimit, Sort: "-date", Hidden: true}
+		repo.On("FetchProductByName", filter).Return([]models.Product{}, 0, nil).Once()
+
+		_, err := u.GetAdminProducts("", "", 1, 0, owner)
 		require.NoError(t, err)
+	})
+
+	t.Run("Failure fetching products", func(t *testing.T) {
+		repo.On("FetchProductByName", mock.Anything).Return(nil, 0, errors.New("error")).Once()
 
-		assert.NotNil(t, prods)
+		res, err := u.GetAdminProducts("", "", 1, 0, uuid.NullUUID{})
+		assert.Error(t, err)
+		assert.Nil(t, res)
 	})
 }
 
@@ -125,19 +259,83 @@ func TestGetSingleProduct(t *testing.T) {
 	cld := mockCloudinary.NewCloudUploader(t)
 	repo := mockProd.NewRepo(t)
 
-	u := usecase.NewProductsUC(cld, repo)
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
 
 	t.Run("Get Single Product successfully", func(t *testing.T) {
 		id := uuid.New()
 
 		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id}, nil)
 		repo.On("FetchImageUrlById", id).Return([]models.Images{}, nil)
-		repo.On("FetchReviewById", id).Return([]models.Reviews{}, nil)
+		repo.On("FetchReviewById", id, models.ReviewsOldest).Return([]models.Reviews{}, nil)
+		repo.On("FetchVariants", id).Return([]models.Variant{{ProductId: id, Attributes: map[string]string{"size": "M"}}}, nil)
 
 		prod, err := u.GetSingleProduct(id)
 		require.NoError(t, err)
 
 		assert.NotNil(t, prod)
+		assert.Len(t, prod.Variants, 1)
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchProductById", id).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := u.GetSingleProduct(id)
+		assert.ErrorIs(t, err, products.ErrProductNotFound)
+	})
+}
+
+func TestGetProductsByIds(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+
+	t.Run("Images are fetched at once", func(t *testing.T) {
+		a, b, missing := uuid.New(), uuid.New(), uuid.New()
+		repo.On("FetchProductsByIds", []uuid.UUID{a, b, missing}).
+			Return([]*models.Product{{ProductId: a}, {ProductId: b}}, nil).Once()
+		repo.On("FetchImagesByProductIds", []uuid.UUID{a, b}).
+			Return([]models.Images{{Url: "https://img.example.com/1.jpg", ProductId: b}, {Url: "https://img.example.com/2.jpg", ProductId: b}}, nil).Once()
+
+		prods, err := u.GetProductsByIds([]uuid.UUID{a, b, missing})
+		require.NoError(t, err)
+		require.Len(t, prods, 2)
+		assert.Empty(t, prods[0].Images)
+		assert.Len(t, prods[1].Images, 2)
+	})
+
+	t.Run("No product found", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchProductsByIds", []uuid.UUID{id}).Return(nil, nil).Once()
+
+		prods, err := u.GetProductsByIds([]uuid.UUID{id})
+		require.NoError(t, err)
+		assert.Empty(t, prods)
+	})
+}
+
+func TestGetRecommendations(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	t.Run("Duplicate products are asked once", func(t *testing.T) {
+		a, b := uuid.New(), uuid.New()
+		repo.On("FetchRelatedProducts", []uuid.UUID{a, b}, 4).
+			Return([]models.Recommendation{{ProductId: uuid.New()}}, nil).Once()
+
+		recs, err := u.GetRecommendations([]uuid.UUID{a, b, a}, 4)
+		require.NoError(t, err)
+		assert.Len(t, recs, 1)
+	})
+
+	t.Run("Nothing related", func(t *testing.T) {
+		repo.On("FetchRelatedProducts", mock.Anything, 4).Return(nil, nil).Once()
+
+		recs, err := u.GetRecommendations([]uuid.UUID{uuid.New()}, 4)
+		require.NoError(t, err)
+		assert.NotNil(t, recs)
+		assert.Empty(t, recs)
 	})
 }
 
@@ -145,11 +343,11 @@ func TestGetSingleProduct(t *testing.T) {
 // 	cld := mockCloudinary.NewCloudUploader(t)
 // 	repo := mockProd.NewRepo(t)
 
-// 	u := usecase.NewProductsUC(cld, repo)
+// 	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
 
 // 	t.Run("Update Product successfully", func(t *testing.T) {
 
-// 		res, err := u.UpdateProduct(uuid.New(), models.Product{}, []*multipart.FileHeader{})
+// 		res, err := u.UpdateProduct(context.Background(), uuid.New(), models.Product{}, []*multipart.FileHeader{})
 // 		require.NoError(t, err)
 
 // 		assert.NotNil(t, res)
@@ -161,7 +359,7 @@ func TestDeleteProduct(t *testing.T) {
 	cld := mockCloudinary.NewCloudUploader(t)
 	repo := mockProd.NewRepo(t)
 
-	u := usecase.NewProductsUC(cld, repo)
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
 
 	t.Run("Delete Product successfully", func(t *testing.T) {
 		id := uuid.New()
@@ -171,20 +369,104 @@ func TestDeleteProduct(t *testing.T) {
 			},
 		}
 
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id, UserId: uuid.New()}, nil)
 		repo.On("FetchImageUrlById", id).Return(i, nil)
-		cld.On("Destroy", i[0].PublicId).Return(nil, nil)
+		cld.On("Destroy", mock.Anything, i[0].PublicId).Return(nil, nil)
 		repo.On("DeleteProductById", id).Return(nil)
 
-		err := u.DeleteProduct(id)
+		err := u.DeleteProduct(context.Background(), id, admin)
+		require.NoError(t, err)
+	})
+}
+
+func TestProductOwnership(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	seller := &models.User{ID: uuid.New(), Role: models.RoleSeller}
+	id := uuid.New()
+
+	t.Run("Seller updates their product", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id, UserId: seller.ID}, nil).Once()
+		repo.On("FetchImageUrlById", id).Return([]models.Images{}, nil).Once()
+		repo.On("UpdateProduct", id, mock.MatchedBy(func(p *models.Product) bool {
+			return p.UserId == seller.ID
+		})).Return(models.Product{ProductId: id}, nil).Once()
+
+		_, err := u.UpdateProduct(context.Background(), id, newProduct("Shirt", "Clothes/Shoes"), nil, seller)
+		require.NoError(t, err)
+	})
+
+	t.Run("Product keeps its owner when an admin updates it", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id, UserId: seller.ID}, nil).Once()
+		repo.On("FetchImageUrlById", id).Return([]models.Images{}, nil).Once()
+		repo.On("UpdateProduct", id, mock.MatchedBy(func(p *models.Product) bool {
+			return p.UserId == seller.ID
+		})).Return(models.Product{ProductId: id}, nil).Once()
+
+		p := newProduct("Shirt", "Clothes/Shoes")
+		p.UserId = admin.ID
+
+		_, err := u.UpdateProduct(context.Background(), id, p, nil, admin)
 		require.NoError(t, err)
 	})
+
+	t.Run("Seller cannot change the products of others", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id, UserId: uuid.New()}, nil).Twice()
+
+		_, err := u.UpdateProduct(context.Background(), id, models.Product{Name: "Shirt"}, nil, seller)
+		assert.ErrorIs(t, err, products.ErrNotProductOwner)
+
+		assert.ErrorIs(t, u.DeleteProduct(context.Background(), id, seller), products.ErrNotProductOwner)
+	})
+
+	t.Run("Missing product", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(nil, sql.ErrNoRows).Once()
+
+		assert.ErrorIs(t, u.DeleteProduct(context.Background(), id, seller), products.ErrProductNotFound)
+	})
+}
+
+func TestSaveInvalidProduct(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	id := uuid.New()
+	p := newProduct("Shirt", "Clothes/Shoes")
+	p.Price = money.Of(0)
+
+	t.Run("Create", func(t *testing.T) {
+		_, err := u.CreateProduct(context.Background(), p, nil)
+
+		var invalid *products.ValidationError
+		require.ErrorAs(t, err, &invalid)
+		assert.Contains(t, invalid.Errors, "price")
+		assert.Contains(t, invalid.Errors, "images")
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id}, nil).Once()
+
+		_, err := u.UpdateProduct(context.Background(), id, p, nil, admin)
+
+		var invalid *products.ValidationError
+		require.ErrorAs(t, err, &invalid)
+		assert.Contains(t, invalid.Errors, "price")
+		assert.NotContains(t, invalid.Errors, "images")
+	})
 }
 
 func TestCreateProductReview(t *testing.T) {
 	cld := mockCloudinary.NewCloudUploader(t)
 	repo := mockProd.NewRepo(t)
 
-	u := usecase.NewProductsUC(cld, repo)
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
 
 	t.Run("Create Product Review successfully", func(t *testing.T) {
 		review := models.Reviews{
@@ -195,85 +477,1086 @@ func TestCreateProductReview(t *testing.T) {
 			UserId:    uuid.New(),
 		}
 
-		product := models.Product{
-			Reviews: []models.Reviews{review},
-		}
+		repo.On("FetchProductById", review.ProductId).Return(&models.Product{ProductId: review.ProductId}, nil).Once()
+		repo.On("InsertReview", &review).Return(nil).Once()
+		repo.On("UpdateRatings", review.ProductId).Return(nil).Once()
+
+		err := u.CreateProductReview(context.Background(), review, nil)
+		require.NoError(t, err)
+	})
 
-		repo.On("FetchProductById", review.ProductId).Return(&product, nil)
-		repo.On("FetchReviewById", review.ProductId).Return([]models.Reviews{review}, nil)
-		repo.On("InsertReview", &review).Return(nil)
+	t.Run("Images are uploaded with the review", func(t *testing.T) {
+		review := models.Reviews{ProductId: uuid.New(), Rating: 4}
 
-		product.NumOfReviews = 1
-		product.Ratings = 5
-		repo.On("UpdateProduct", review.ProductId, &product).Return(product, nil)
+		repo.On("FetchProductById", review.ProductId).Return(&models.Product{ProductId: review.ProductId}, nil).Once()
+		cld.On("UploadToCloud", mock.Anything, "reviews", mock.Anything).
+			Return(&uploader.UploadResult{PublicID: "reviews/a", URL: "https://img/a.png"}, nil).Once()
+		repo.On("InsertReview", mock.MatchedBy(func(r *models.Reviews) bool {
+			return len(r.Images) == 1 && r.Images[0].PublicId == "reviews/a" && r.Images[0].Url == "https://img/a.png"
+		})).Return(nil).Once()
+		repo.On("UpdateRatings", review.ProductId).Return(nil).Once()
 
-		err := u.CreateProductReview(review)
+		err := u.CreateProductReview(context.Background(), review, fileHeaders(t, map[string][]byte{"a.png": png}))
 		require.NoError(t, err)
 	})
+
+	t.Run("Uploaded images are discarded when the review is not saved", func(t *testing.T) {
+		review := models.Reviews{ProductId: uuid.New(), Rating: 4}
+
+		repo.On("FetchProductById", review.ProductId).Return(&models.Product{ProductId: review.ProductId}, nil).Once()
+		cld.On("UploadToCloud", mock.Anything, "reviews", mock.Anything).
+			Return(&uploader.UploadResult{PublicID: "reviews/b", URL: "https://img/b.png"}, nil).Once()
+		repo.On("InsertReview", mock.Anything).Return(errors.New("db down")).Once()
+		cld.On("Destroy", mock.Anything, "reviews/b").Return(&uploader.DestroyResult{Result: "ok"}, nil).Once()
+
+		assert.Error(t, u.CreateProductReview(context.Background(), review, fileHeaders(t, map[string][]byte{"b.png": png})))
+	})
+
+	t.Run("No more than three images", func(t *testing.T) {
+		img := fileHeaders(t, map[string][]byte{"a.png": png, "b.png": png, "c.png": png, "d.png": png})
+
+		err := u.CreateProductReview(context.Background(), models.Reviews{ProductId: uuid.New()}, img)
+
+		var imageErr *products.ImageError
+		require.ErrorAs(t, err, &imageErr)
+		assert.Equal(t, "images", imageErr.Field)
+		assert.Equal(t, validator.CodeTooMany, imageErr.Code)
+	})
+
+	t.Run("Image is rejected before anything is uploaded", func(t *testing.T) {
+		err := u.CreateProductReview(context.Background(), models.Reviews{ProductId: uuid.New()},
+			fileHeaders(t, map[string][]byte{"notes.txt": []byte("plain text")}))
+
+		var imageErr *products.ImageError
+		require.ErrorAs(t, err, &imageErr)
+		assert.Equal(t, "images[0]", imageErr.Field)
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		review := models.Reviews{ProductId: uuid.New(), Rating: 3}
+
+		repo.On("FetchProductById", review.ProductId).Return(nil, sql.ErrNoRows).Once()
+
+		assert.ErrorIs(t, u.CreateProductReview(context.Background(), review, nil), products.ErrProductNotFound)
+	})
+
+	t.Run("Ratings are not updated when the review is not saved", func(t *testing.T) {
+		review := models.Reviews{ProductId: uuid.New(), Rating: 3}
+
+		repo.On("FetchProductById", review.ProductId).Return(&models.Product{ProductId: review.ProductId}, nil).Once()
+		repo.On("InsertReview", &review).Return(errors.New("db down")).Once()
+
+		assert.Error(t, u.CreateProductReview(context.Background(), review, nil))
+	})
 }
 
 func TestGetProductReviews(t *testing.T) {
 	cld := mockCloudinary.NewCloudUploader(t)
 	repo := mockProd.NewRepo(t)
 
-	u := usecase.NewProductsUC(cld, repo)
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
 
 	t.Run("Get Product Reviews successfully", func(t *testing.T) {
 		id := uuid.New()
 
-		rvs := models.Reviews{
-			ProductId: id,
-			Name:      "test",
-			Rating:    5,
-			Comment:   "test",
-			UserId:    uuid.New(),
+		rvs := []models.Reviews{
+			{ReviewsId: uuid.New(), ProductId: id, Name: "test", Rating: 5, Comment: "test", UserId: uuid.New(), VerifiedPurchase: true},
+			{ReviewsId: uuid.New(), ProductId: id, Name: "other", Rating: 2, UserId: uuid.New()},
 		}
+		img := models.ReviewImage{PublicId: "reviews/a", Url: "https://img/a.png", ReviewId: rvs[0].ReviewsId}
+
+		repo.On("FetchReviewById", id, models.ReviewsHelpful).Return(rvs, nil)
+		repo.On("FetchReviewImages", []uuid.UUID{rvs[0].ReviewsId, rvs[1].ReviewsId}).Return([]models.ReviewImage{img}, nil)
+
+		reviews, err := u.GetProductReviews(id, models.ReviewsHelpful)
+		require.NoError(t, err)
+
+		require.Len(t, reviews, 2)
+		assert.Equal(t, []models.ReviewImage{img}, reviews[0].Images)
+		assert.True(t, reviews[0].VerifiedPurchase)
+		assert.Empty(t, reviews[1].Images)
+	})
+}
+
+func TestVoteReview(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+
+	reviewId, userId := uuid.New(), uuid.New()
+
+	t.Run("Vote is recorded", func(t *testing.T) {
+		repo.On("UpsertReviewVote", reviewId, userId, true).Return(nil).Once()
+		repo.On("FetchReviewVotes", reviewId).Return(models.ReviewVotes{Helpful: 3, Unhelpful: 1}, nil).Once()
+
+		votes, err := u.VoteReview(reviewId, userId, true)
+		require.NoError(t, err)
+		assert.Equal(t, models.ReviewVotes{Helpful: 3, Unhelpful: 1}, votes)
+	})
+
+	t.Run("Review not found", func(t *testing.T) {
+		repo.On("UpsertReviewVote", reviewId, userId, false).Return(sql.ErrNoRows).Once()
+
+		_, err := u.VoteReview(reviewId, userId, false)
+		assert.ErrorIs(t, err, products.ErrReviewNotFound)
+	})
+}
+
+func TestWithdrawReviewVote(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
 
-		repo.On("FetchReviewById", id).Return([]models.Reviews{rvs}, nil)
+	reviewId, userId := uuid.New(), uuid.New()
 
-		reviews, err := u.GetProductReviews(id)
+	t.Run("Vote is withdrawn", func(t *testing.T) {
+		repo.On("DeleteReviewVote", reviewId, userId).Return(nil).Once()
+		repo.On("FetchReviewVotes", reviewId).Return(models.ReviewVotes{Helpful: 2}, nil).Once()
+
+		votes, err := u.WithdrawReviewVote(reviewId, userId)
 		require.NoError(t, err)
+		assert.Equal(t, models.ReviewVotes{Helpful: 2}, votes)
+	})
 
-		assert.NotNil(t, reviews)
+	t.Run("Review not found", func(t *testing.T) {
+		repo.On("DeleteReviewVote", reviewId, userId).Return(nil).Once()
+		repo.On("FetchReviewVotes", reviewId).Return(models.ReviewVotes{}, sql.ErrNoRows).Once()
+
+		_, err := u.WithdrawReviewVote(reviewId, userId)
+		assert.ErrorIs(t, err, products.ErrReviewNotFound)
 	})
 }
 
+func TestGetReviewsByProductIds(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+
+	a, b := uuid.New(), uuid.New()
+	repo.On("FetchReviewsByProductIds", []uuid.UUID{a, b}).
+		Return([]models.Reviews{{Name: "Ama", ProductId: a}, {Name: "Kofi", ProductId: a}}, nil).Once()
+
+	reviews, err := u.GetReviewsByProductIds([]uuid.UUID{a, b})
+	require.NoError(t, err)
+	assert.Len(t, reviews[a], 2)
+	assert.Empty(t, reviews[b])
+}
+
 func TestDeleteProductReview(t *testing.T) {
 	cld := mockCloudinary.NewCloudUploader(t)
 	repo := mockProd.NewRepo(t)
 
-	u := usecase.NewProductsUC(cld, repo)
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
 
 	t.Run("Delete Product Review successfully", func(t *testing.T) {
 		productId := uuid.New()
 		reviewId := uuid.New()
 
-		product := models.Product{
-			Ratings:      5,
-			NumOfReviews: 1,
+		repo.On("DeleteReviewById", reviewId).Return(nil).Once()
+		repo.On("UpdateRatings", productId).Return(nil).Once()
+
+		err := u.DeleteProductReview(productId, reviewId)
+		require.NoError(t, err)
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		productId, reviewId := uuid.New(), uuid.New()
+
+		repo.On("DeleteReviewById", reviewId).Return(nil).Once()
+		repo.On("UpdateRatings", productId).Return(sql.ErrNoRows).Once()
+
+		assert.ErrorIs(t, u.DeleteProductReview(productId, reviewId), products.ErrProductNotFound)
+	})
+
+	t.Run("Review not found", func(t *testing.T) {
+		productId, reviewId := uuid.New(), uuid.New()
+
+		repo.On("DeleteReviewById", reviewId).Return(sql.ErrNoRows).Once()
+
+		assert.ErrorIs(t, u.DeleteProductReview(productId, reviewId), products.ErrReviewNotFound)
+	})
+}
+
+// fileHeaders returns the file headers of a multipart form holding the given files.
+func fileHeaders(t *testing.T, files map[string][]byte) []*multipart.FileHeader {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, content := range files {
+		part, err := w.CreateFormFile("images", name)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = form.RemoveAll() })
+
+	return form.File["images"]
+}
+
+func TestValidateProduct(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	valid := models.Product{
+		Name:        "Camera",
+		SKU:         "CAM-1",
+		Price:       money.Of(250),
+		Description: "A camera",
+		Category:    "Cameras",
+		Seller:      "Ebay",
+		Stock:       3,
+	}
+
+	t.Run("Valid product", func(t *testing.T) {
+		repo.On("SKUExists", "CAM-1", uuid.Nil).Return(false, nil).Once()
+
+		report, err := u.ValidateProduct(valid, fileHeaders(t, map[string][]byte{"camera.png": png}))
+		require.NoError(t, err)
+
+		assert.True(t, report.Valid)
+		assert.Empty(t, report.Errors)
+	})
+
+	t.Run("Every problem is reported", func(t *testing.T) {
+		p := valid
+		p.Name = ""
+		p.Price = money.Of(0)
+		p.Category = "Gadgets"
+		repo.On("SKUExists", "CAM-1", uuid.Nil).Return(true, nil).Once()
+
+		report, err := u.ValidateProduct(p, fileHeaders(t, map[string][]byte{"notes.txt": []byte("plain text")}))
+		require.NoError(t, err)
+
+		assert.False(t, report.Valid)
+		for _, field := range []string{"name", "price", "category", "sku", "images[0]"} {
+			assert.Contains(t, report.Errors, field)
 		}
+		assert.Equal(t, validator.CodeRequired, report.Codes["name"])
+		assert.Equal(t, validator.CodeTaken, report.Codes["sku"])
+		assert.Equal(t, validator.CodeNotFound, report.Codes["category"])
+	})
 
-		reviews := []models.Reviews{
-			{
-				ProductId: productId,
-				Rating:    5,
+	t.Run("Images are optional for an existing product", func(t *testing.T) {
+		p := valid
+		p.ProductId = uuid.New()
+		repo.On("SKUExists", "CAM-1", p.ProductId).Return(false, nil).Once()
+
+		report, err := u.ValidateProduct(p, nil)
+		require.NoError(t, err)
+
+		assert.True(t, report.Valid)
+	})
+
+	t.Run("SKU lookup error", func(t *testing.T) {
+		repo.On("SKUExists", "CAM-1", uuid.Nil).Return(false, errors.New("db error")).Once()
+
+		_, err := u.ValidateProduct(valid, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestImportProducts(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	userID := uuid.New()
+	existing := uuid.New()
+	header := "id,sku,name,description,price,stock,category,seller\n"
+
+	t.Run("Rows are created, updated and reported", func(t *testing.T) {
+		csv := header +
+			",NEW-1,Lens,A lens,119.99,4,Cameras,Ebay\n" +
+			",CAM-1,Camera,A camera,250,3,Cameras,Ebay\n" +
+			",,Tripod,,abc,2,Gadgets,Ebay\n" +
+			uuid.NewString() + ",,Bag,A bag,30,1,Cameras,Ebay\n"
+		repo.On("FetchProductKeys", mock.Anything).Return(map[uuid.UUID]string{existing: "CAM-1"}, nil).Once()
+		repo.On("ImportProducts", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			inserts := args.Get(1).([]*models.Product)
+			updates := args.Get(2).([]*models.Product)
+			require.Len(t, inserts, 1)
+			require.Len(t, updates, 1)
+			assert.Equal(t, "Lens", inserts[0].Name)
+			assert.Equal(t, money.Of(11999), inserts[0].Price)
+			assert.Equal(t, userID, inserts[0].UserId)
+			assert.NotEqual(t, uuid.Nil, inserts[0].ProductId)
+			assert.Equal(t, existing, updates[0].ProductId)
+		}).Return(nil).Once()
+
+		report, err := u.ImportProducts(context.Background(), strings.NewReader(csv), userID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 2, report.Failed)
+		require.Len(t, report.Errors, 2)
+		assert.Equal(t, 4, report.Errors[0].Line)
+		for _, field := range []string{"description", "price", "category"} {
+			assert.Contains(t, report.Errors[0].Errors, field)
+		}
+		assert.Equal(t, 5, report.Errors[1].Line)
+		assert.Contains(t, report.Errors[1].Errors, "id")
+	})
+
+	t.Run("Prices in the currency of their row", func(t *testing.T) {
+		require.NoError(t, money.Accept("EUR"))
+		t.Cleanup(func() { money.Accept() })
+
+		csv := "name,description,price,currency,stock,category,seller\n" +
+			"Lens,A lens,120,eur,4,Cameras,Ebay\n" +
+			"Bag,A bag,30,XYZ,1,Cameras,Ebay\n"
+		repo.On("FetchProductKeys", mock.Anything).Return(map[uuid.UUID]string{}, nil).Once()
+		repo.On("ImportProducts", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			inserts := args.Get(1).([]*models.Product)
+			require.Len(t, inserts, 1)
+			assert.Equal(t, money.New(12000, "EUR"), inserts[0].Price)
+		}).Return(nil).Once()
+
+		report, err := u.ImportProducts(context.Background(), strings.NewReader(csv), userID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Created)
+		require.Len(t, report.Errors, 1)
+		assert.Contains(t, report.Errors[0].Errors, "currency")
+	})
+
+	t.Run("Duplicate sku in the file", func(t *testing.T) {
+		csv := "name,sku,description,price,stock,category,seller\n" +
+			"Lens,NEW-1,A lens,120,4,Cameras,Ebay\n" +
+			"Lens 2,NEW-1,A lens,120,4,Cameras,Ebay\n"
+		repo.On("FetchProductKeys", mock.Anything).Return(map[uuid.UUID]string{}, nil).Once()
+		repo.On("ImportProducts", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		report, err := u.ImportProducts(context.Background(), strings.NewReader(csv), userID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Created)
+		require.Len(t, report.Errors, 1)
+		assert.Equal(t, 3, report.Errors[0].Line)
+		assert.Contains(t, report.Errors[0].Errors, "sku")
+	})
+
+	t.Run("Nothing valid is not saved", func(t *testing.T) {
+		repo.On("FetchProductKeys", mock.Anything).Return(map[uuid.UUID]string{}, nil).Once()
+
+		report, err := u.ImportProducts(context.Background(), strings.NewReader(header+",,,,,,,\n"), userID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Failed)
+	})
+
+	t.Run("Invalid header", func(t *testing.T) {
+		_, err := u.ImportProducts(context.Background(), strings.NewReader("name,colour\n"), userID)
+		assert.ErrorIs(t, err, products.ErrInvalidCSV)
+
+		_, err = u.ImportProducts(context.Background(), strings.NewReader("name,price\n"), userID)
+		assert.ErrorIs(t, err, products.ErrInvalidCSV)
+	})
+
+	t.Run("Save error", func(t *testing.T) {
+		repo.On("FetchProductKeys", mock.Anything).Return(map[uuid.UUID]string{}, nil).Once()
+		repo.On("ImportProducts", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+
+		_, err := u.ImportProducts(context.Background(), strings.NewReader(header+",,Lens,A lens,120,4,Cameras,Ebay\n"), userID)
+		assert.Error(t, err)
+	})
+}
+
+func TestBulkUpdateProducts(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	existing, missing := uuid.New(), uuid.New()
+	price, free := money.Of(4999), money.Of(0)
+	stock, negative := 12, -1
+
+	t.Run("Valid updates saved and invalid ones reported", func(t *testing.T) {
+		updates := []models.ProductUpdate{
+			{ProductId: existing, Price: &price, Stock: &stock},
+			{ProductId: missing, Stock: &stock},
+			{ProductId: existing, Stock: &stock},
+			{Price: &free, Stock: &negative},
+			{ProductId: uuid.New()},
+		}
+		repo.On("FetchProductsByIds", mock.Anything).Return([]*models.Product{{ProductId: existing}}, nil).Once()
+		repo.On("BulkUpdateProducts", updates[:1]).Return(nil).Once()
+
+		report, err := u.BulkUpdateProducts(updates)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 4, report.Failed)
+		require.Len(t, report.Errors, 4)
+		assert.Equal(t, 1, report.Errors[0].Index)
+		assert.Equal(t, "product not found", report.Errors[0].Errors["productId"])
+		assert.Equal(t, "product is updated more than once", report.Errors[1].Errors["productId"])
+		assert.Equal(t, map[string]string{
+			"productId": "product id must be provided",
+			"price":     "product price must be greater than zero",
+			"stock":     "product stock must not be negative",
+		}, report.Errors[2].Errors)
+		assert.Equal(t, "price or stock must be provided", report.Errors[3].Errors["price"])
+	})
+
+	t.Run("Nothing valid saves nothing", func(t *testing.T) {
+		report, err := u.BulkUpdateProducts([]models.ProductUpdate{{Stock: &stock}})
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, report.Updated)
+		assert.Equal(t, 1, report.Failed)
+	})
+
+	t.Run("Product deleted while updating", func(t *testing.T) {
+		repo.On("FetchProductsByIds", []uuid.UUID{existing}).Return([]*models.Product{{ProductId: existing}}, nil).Once()
+		repo.On("BulkUpdateProducts", mock.Anything).Return(sql.ErrNoRows).Once()
+
+		_, err := u.BulkUpdateProducts([]models.ProductUpdate{{ProductId: existing, Stock: &stock}})
+		assert.ErrorIs(t, err, products.ErrProductNotFound)
+	})
+}
+
+func TestSyncSheet(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+	userID := uuid.New()
+
+	t.Run("Rows of the sheet are imported", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			fmt.Fprint(w, "name,description,price,stock,category,seller\nLens,A lens,119.99,4,Cameras,Ebay\n")
+		}))
+		defer srv.Close()
+
+		u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil,
+			&sheets.Sheet{URL: srv.URL, Client: srv.Client()})
+
+		repo.On("FetchProductKeys", mock.Anything).Return(map[uuid.UUID]string{}, nil).Once()
+		repo.On("ImportProducts", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			inserts := args.Get(1).([]*models.Product)
+			require.Len(t, inserts, 1)
+			assert.Equal(t, userID, inserts[0].UserId)
+		}).Return(nil).Once()
+
+		report, err := u.SyncSheet(context.Background(), userID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Created)
+	})
+
+	t.Run("Sheet not shared", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+		}))
+		defer srv.Close()
+
+		u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil,
+			&sheets.Sheet{URL: srv.URL, Client: srv.Client()})
+
+		_, err := u.SyncSheet(context.Background(), userID)
+		assert.ErrorIs(t, err, sheets.ErrNotShared)
+	})
+
+	t.Run("No sheet configured", func(t *testing.T) {
+		u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+
+		_, err := u.SyncSheet(context.Background(), userID)
+		assert.ErrorIs(t, err, products.ErrSheetNotConfigured)
+	})
+}
+
+func TestExportProducts(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	id := uuid.New()
+
+	t.Run("Products are written as CSV", func(t *testing.T) {
+		repo.On("StreamProducts", mock.Anything).Run(func(args mock.Arguments) {
+			fn := args.Get(0).(func(*models.Product) error)
+			require.NoError(t, fn(&models.Product{
+				ProductId: id, SKU: "CAM-1", Name: "Camera", Description: "A camera, black",
+				Price: money.Of(25000), Stock: 3, Category: "Cameras", Seller: "Ebay",
+			}))
+		}).Return(nil).Once()
+
+		var buf bytes.Buffer
+		require.NoError(t, u.ExportProducts(&buf))
+
+		assert.Equal(t, "id,sku,name,description,price,currency,stock,category,seller\n"+
+			id.String()+",CAM-1,Camera,\"A camera, black\",250.00,USD,3,Cameras,Ebay\n", buf.String())
+	})
+
+	t.Run("Stream error", func(t *testing.T) {
+		repo.On("StreamProducts", mock.Anything).Return(errors.New("db error")).Once()
+
+		assert.Error(t, u.ExportProducts(&bytes.Buffer{}))
+	})
+}
+
+func TestProductVariants(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	id := uuid.New()
+	variants := []models.Variant{
+		{Attributes: map[string]string{"size": "S"}, Stock: 2},
+		{Attributes: map[string]string{"size": "M"}, PriceDelta: money.Of(5), Stock: 1},
+	}
+
+	t.Run("Variants are saved with a new product", func(t *testing.T) {
+		repo.On("InsertProduct", mock.Anything).Return(models.Product{ProductId: id, Name: "Shirt"}, nil).Once()
+		repo.On("SaveVariants", id, variants).Return(variants, nil).Once()
+
+		p := newProduct("Shirt", "Clothes/Shoes")
+		p.Variants = variants
+
+		res, err := u.CreateProduct(context.Background(), p, productImage(t, cld, repo))
+		require.NoError(t, err)
+
+		assert.Len(t, res.Product.Variants, 2)
+	})
+
+	t.Run("Update without variants leaves them alone", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id}, nil).Once()
+		repo.On("FetchImageUrlById", id).Return([]models.Images{}, nil).Once()
+		repo.On("UpdateProduct", id, mock.Anything).Return(models.Product{ProductId: id}, nil).Once()
+
+		_, err := u.UpdateProduct(context.Background(), id, newProduct("Shirt", "Clothes/Shoes"), nil, admin)
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown variant", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id}, nil).Once()
+		repo.On("FetchImageUrlById", id).Return([]models.Images{}, nil).Once()
+		repo.On("UpdateProduct", id, mock.Anything).Return(models.Product{ProductId: id}, nil).Once()
+		repo.On("SaveVariants", id, []models.Variant{}).Return(nil, sql.ErrNoRows).Once()
+
+		p := newProduct("Shirt", "Clothes/Shoes")
+		p.Variants = []models.Variant{}
+
+		_, err := u.UpdateProduct(context.Background(), id, p, nil, admin)
+		assert.ErrorIs(t, err, products.ErrVariantNotFound)
+	})
+
+	t.Run("Variants are validated", func(t *testing.T) {
+		p := models.Product{
+			ProductId: id, Name: "Shirt", Description: "A shirt", Price: money.Of(10), Category: "Clothes/Shoes", Seller: "Ebay",
+			Variants: []models.Variant{
+				{Attributes: map[string]string{"size": "M"}},
+				{Attributes: map[string]string{"Size": "m"}},
+				{},
+				{Attributes: map[string]string{"size": "L"}, PriceDelta: money.Of(-10)},
 			},
 		}
 
-		repo.On("DeleteReviewById", reviewId).Return(nil)
-		repo.On("FetchProductById", productId).Return(&product, nil)
-		repo.On("FetchReviewById", productId).Return(reviews, nil)
+		report, err := u.ValidateProduct(p, nil)
+		require.NoError(t, err)
 
-		var totalRating = 0
-		for _, rv := range reviews {
-			totalRating += rv.Rating
+		assert.False(t, report.Valid)
+		assert.NotContains(t, report.Errors, "variants[0]")
+		for _, field := range []string{"variants[1]", "variants[2]", "variants[3]"} {
+			assert.Contains(t, report.Errors, field)
 		}
+	})
+}
 
-		product.Ratings = totalRating / len(reviews)
-		product.NumOfReviews = len(reviews)
-		repo.On("UpdateProduct", productId, &product).Return(models.Product{}, nil)
+func TestProductCategories(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
 
-		err := u.DeleteProductReview(productId, reviewId)
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	t.Run("Product is filed under its category id", func(t *testing.T) {
+		p := newProduct("Camera", "")
+		p.CategoryId = uuid.NullUUID{UUID: cameras.CategoryId, Valid: true}
+		repo.On("InsertProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Category == "Cameras" && p.CategoryId.UUID == cameras.CategoryId
+		})).Return(models.Product{ProductId: uuid.New()}, nil).Once()
+
+		_, err := u.CreateProduct(context.Background(), p, productImage(t, cld, repo))
+		require.NoError(t, err)
+	})
+
+	t.Run("Category names are matched regardless of case", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id}, nil).Once()
+		repo.On("FetchImageUrlById", id).Return([]models.Images{}, nil).Once()
+		repo.On("UpdateProduct", id, mock.MatchedBy(func(p *models.Product) bool {
+			return p.Category == "Clothes/Shoes" && p.CategoryId.UUID == clothes.CategoryId
+		})).Return(models.Product{ProductId: id}, nil).Once()
+
+		_, err := u.UpdateProduct(context.Background(), id, newProduct("Shirt", "clothes/shoes"), nil, admin)
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown category id", func(t *testing.T) {
+		p := newProduct("Camera", "Cameras")
+		p.CategoryId = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+
+		_, err := u.CreateProduct(context.Background(), p, nil)
+
+		var invalid *products.ValidationError
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, validator.CodeNotFound, invalid.Codes["category"])
+	})
+}
+
+func TestRecordSearch(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+
+	t.Run("Keyword is normalized", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("InsertSearch", "wide angle lens", 0).Return(id, nil).Once()
+
+		got, err := u.RecordSearch("  Wide   Angle\tLENS ", 0)
+		require.NoError(t, err)
+		assert.Equal(t, id, got)
+	})
+
+	t.Run("Long keyword is cut", func(t *testing.T) {
+		repo.On("InsertSearch", strings.Repeat("a", models.MaxSearchKeyword), 2).Return(uuid.New(), nil).Once()
+
+		_, err := u.RecordSearch(strings.Repeat("a", 300), 2)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Blank keyword is not recorded", func(t *testing.T) {
+		id, err := u.RecordSearch("   ", 0)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Nil, id)
+	})
+}
+
+func TestRecordSearchClick(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+	searchID, productID := uuid.New(), uuid.New()
+
+	t.Run("Click is recorded", func(t *testing.T) {
+		repo.On("FetchProductById", productID).Return(&models.Product{ProductId: productID}, nil).Once()
+		repo.On("InsertSearchClick", searchID, productID).Return(nil).Once()
+
+		assert.NoError(t, u.RecordSearchClick(searchID, productID))
+	})
+
+	t.Run("Unknown search", func(t *testing.T) {
+		repo.On("FetchProductById", productID).Return(&models.Product{ProductId: productID}, nil).Once()
+		repo.On("InsertSearchClick", searchID, productID).Return(sql.ErrNoRows).Once()
+
+		assert.ErrorIs(t, u.RecordSearchClick(searchID, productID), products.ErrSearchNotFound)
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		repo.On("FetchProductById", productID).Return(nil, sql.ErrNoRows).Once()
+
+		assert.ErrorIs(t, u.RecordSearchClick(searchID, productID), products.ErrProductNotFound)
+	})
+}
+
+func TestGetRelatedProducts(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+	productID := uuid.New()
+	recs := []models.Recommendation{{ProductId: uuid.New(), Name: "Tripod", Ratings: 4}}
+
+	t.Run("Related products are returned", func(t *testing.T) {
+		repo.On("FetchRelatedProducts", []uuid.UUID{productID}, usecase.DefaultRelatedLimit).Return(recs, nil).Once()
+
+		got, err := u.GetRelatedProducts(productID, 0)
+		require.NoError(t, err)
+		assert.Equal(t, recs, got)
+	})
+
+	t.Run("Limit is capped", func(t *testing.T) {
+		repo.On("FetchRelatedProducts", []uuid.UUID{productID}, usecase.MaxRelatedLimit).Return(recs, nil).Once()
+
+		_, err := u.GetRelatedProducts(productID, 1000)
+		require.NoError(t, err)
+	})
+
+	t.Run("Product alone in its category", func(t *testing.T) {
+		repo.On("FetchRelatedProducts", []uuid.UUID{productID}, 4).Return(nil, nil).Once()
+		repo.On("FetchProductById", productID).Return(&models.Product{ProductId: productID, Status: models.ProductPublished}, nil).Once()
+
+		got, err := u.GetRelatedProducts(productID, 4)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Recommendation{}, got)
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		repo.On("FetchRelatedProducts", []uuid.UUID{productID}, 4).Return(nil, nil).Once()
+		repo.On("FetchProductById", productID).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := u.GetRelatedProducts(productID, 4)
+		assert.ErrorIs(t, err, products.ErrProductNotFound)
+	})
+
+	t.Run("Hidden product", func(t *testing.T) {
+		repo.On("FetchRelatedProducts", []uuid.UUID{productID}, 4).Return(nil, nil).Once()
+		repo.On("FetchProductById", productID).Return(&models.Product{ProductId: productID, Hidden: true}, nil).Once()
+
+		_, err := u.GetRelatedProducts(productID, 4)
+		assert.ErrorIs(t, err, products.ErrProductNotFound)
+	})
+}
+
+func TestRecordView(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+	productID := uuid.New()
+	user := models.Viewer{UserID: uuid.NullUUID{UUID: uuid.New(), Valid: true}}
+	visitor := models.Viewer{Session: uuid.NewString()}
+
+	t.Run("Views of users and visitors are recorded", func(t *testing.T) {
+		repo.On("InsertView", productID, user).Return(nil).Once()
+		repo.On("InsertView", productID, visitor).Return(nil).Once()
+
+		assert.NoError(t, u.RecordView(productID, user))
+		assert.NoError(t, u.RecordView(productID, visitor))
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		repo.On("InsertView", productID, visitor).Return(sql.ErrNoRows).Once()
+
+		assert.ErrorIs(t, u.RecordView(productID, visitor), products.ErrProductNotFound)
+	})
+
+	t.Run("Viewers without a session are not recorded", func(t *testing.T) {
+		assert.NoError(t, u.RecordView(productID, models.Viewer{}))
+		assert.NoError(t, u.RecordView(productID, models.Viewer{Session: strings.Repeat("x", 65)}))
+	})
+}
+
+func TestGetRecentlyViewed(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+	visitor := models.Viewer{Session: uuid.NewString()}
+
+	t.Run("Views are returned", func(t *testing.T) {
+		recs := []models.Recommendation{{ProductId: uuid.New(), Name: "Tripod"}}
+		repo.On("FetchRecentlyViewed", visitor, 5).Return(recs, nil).Once()
+
+		got, err := u.GetRecentlyViewed(visitor, 5)
+		require.NoError(t, err)
+		assert.Equal(t, recs, got)
+	})
+
+	t.Run("No views", func(t *testing.T) {
+		repo.On("FetchRecentlyViewed", visitor, usecase.DefaultRelatedLimit).Return(nil, nil).Once()
+
+		got, err := u.GetRecentlyViewed(visitor, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Recommendation{}, got)
+	})
+
+	t.Run("Viewers without a session have no views", func(t *testing.T) {
+		got, err := u.GetRecentlyViewed(models.Viewer{}, 0)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+func TestGetZeroResultSearches(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+	terms := []models.SearchTerm{{Keyword: "tripod", Searches: 3}}
+
+	t.Run("Defaults to the last 30 days", func(t *testing.T) {
+		repo.On("FetchZeroResultSearches", mock.MatchedBy(func(since time.Time) bool {
+			return time.Since(since).Round(time.Hour) == usecase.DefaultSearchWindow
+		}), usecase.DefaultSearchTermsLimit).Return(terms, nil).Once()
+
+		got, err := u.GetZeroResultSearches(time.Time{}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, terms, got)
+	})
+
+	t.Run("Limit is capped", func(t *testing.T) {
+		since := time.Now().Add(-time.Hour)
+		repo.On("FetchZeroResultSearches", since, usecase.MaxSearchTermsLimit).Return(nil, nil).Once()
+
+		_, err := u.GetZeroResultSearches(since, 1000)
+		assert.NoError(t, err)
+	})
+}
+
+func TestGetLowStock(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+
+	repo.On("FetchLowStock", usecase.DefaultLowStockLimit).Return([]models.LowStockItem{}, nil).Once()
+	_, err := u.GetLowStock(0)
+	require.NoError(t, err)
+
+	repo.On("FetchLowStock", usecase.MaxLowStockLimit).Return([]models.LowStockItem{}, nil).Once()
+	_, err = u.GetLowStock(1000)
+	require.NoError(t, err)
+}
+
+func TestSetLowStockThreshold(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+	productID := uuid.New()
+
+	repo.On("UpdateLowStockThreshold", productID, 3).Return(nil).Once()
+	require.NoError(t, u.SetLowStockThreshold(productID, 3))
+
+	repo.On("UpdateLowStockThreshold", productID, 3).Return(sql.ErrNoRows).Once()
+	assert.ErrorIs(t, u.SetLowStockThreshold(productID, 3), products.ErrProductNotFound)
+}
+
+func TestSynonyms(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	existing := models.SynonymSet{ID: uuid.New(), Terms: []string{"sneakers", "trainers"}}
+
+	t.Run("Terms are normalized", func(t *testing.T) {
+		repo := mockProd.NewRepo(t)
+		u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+		repo.On("FetchSynonyms").Return([]models.SynonymSet{existing}, nil).Once()
+		repo.On("InsertSynonyms", &models.SynonymSet{Terms: []string{"tv", "television"}}).
+			Return(models.SynonymSet{ID: uuid.New(), Terms: []string{"tv", "television"}}, nil).Once()
+
+		set, err := u.CreateSynonyms(models.SynonymSet{Terms: []string{" TV", "Television", "tv"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"tv", "television"}, set.Terms)
+	})
+
+	t.Run("Term in another set", func(t *testing.T) {
+		repo := mockProd.NewRepo(t)
+		u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+		repo.On("FetchSynonyms").Return([]models.SynonymSet{existing}, nil).Once()
+
+		_, err := u.CreateSynonyms(models.SynonymSet{Terms: []string{"kicks", "Sneakers"}})
+		assert.ErrorIs(t, err, products.ErrSynonymExists)
+	})
+
+	t.Run("Set keeps its own terms on update", func(t *testing.T) {
+		repo := mockProd.NewRepo(t)
+		u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+		terms := []string{"sneakers", "trainers", "kicks"}
+		repo.On("FetchSynonyms").Return([]models.SynonymSet{existing}, nil).Once()
+		repo.On("UpdateSynonyms", existing.ID, &models.SynonymSet{Terms: terms}).
+			Return(models.SynonymSet{ID: existing.ID, Terms: terms}, nil).Once()
+
+		set, err := u.UpdateSynonyms(existing.ID, models.SynonymSet{Terms: terms})
 		require.NoError(t, err)
+		assert.Equal(t, terms, set.Terms)
+	})
+
+	t.Run("Set not found", func(t *testing.T) {
+		repo := mockProd.NewRepo(t)
+		u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+		id := uuid.New()
+		repo.On("FetchSynonyms").Return([]models.SynonymSet{}, nil).Once()
+		repo.On("UpdateSynonyms", id, mock.Anything).Return(models.SynonymSet{}, sql.ErrNoRows).Once()
+		repo.On("DeleteSynonyms", id).Return(sql.ErrNoRows).Once()
+
+		_, err := u.UpdateSynonyms(id, models.SynonymSet{Terms: []string{"tv", "television"}})
+		assert.ErrorIs(t, err, products.ErrSynonymsNotFound)
+		assert.ErrorIs(t, u.DeleteSynonyms(id), products.ErrSynonymsNotFound)
+	})
+}
+
+func TestAddImages(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	id := uuid.New()
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	kept := models.Images{PublicId: "products/kept", Url: "https://img/kept.png", ProductId: id}
+	added := models.Images{PublicId: "products/new", Url: "https://img/new.png", ProductId: id}
+
+	t.Run("Images are appended", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id}, nil).Once()
+		cld.On("UploadToCloud", mock.Anything, "products", mock.Anything).
+			Return(&uploader.UploadResult{PublicID: added.PublicId, URL: added.Url}, nil).Once()
+		repo.On("InsertImageUrls", []models.Images{added}).Return([]models.Images{added}, nil).Once()
+		repo.On("FetchImageUrlById", id).Return([]models.Images{kept, added}, nil).Once()
+		repo.On("FetchVariants", id).Return(nil, nil).Once()
+
+		images, err := u.AddImages(context.Background(), id, fileHeaders(t, map[string][]byte{"new.png": png}), admin)
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.Images{kept, added}, images)
+	})
+
+	t.Run("Image is rejected before anything is uploaded", func(t *testing.T) {
+		_, err := u.AddImages(context.Background(), id, fileHeaders(t, map[string][]byte{"notes.txt": []byte("plain text")}), admin)
+
+		var imageErr *products.ImageError
+		require.ErrorAs(t, err, &imageErr)
+		assert.Equal(t, "images[0]", imageErr.Field)
+		assert.Equal(t, validator.CodeInvalid, imageErr.Code)
+	})
+
+	t.Run("Images are required", func(t *testing.T) {
+		_, err := u.AddImages(context.Background(), id, nil, admin)
+
+		var imageErr *products.ImageError
+		require.ErrorAs(t, err, &imageErr)
+		assert.Equal(t, validator.CodeRequired, imageErr.Code)
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := u.AddImages(context.Background(), id, fileHeaders(t, map[string][]byte{"new.png": png}), admin)
+		assert.ErrorIs(t, err, products.ErrProductNotFound)
+	})
+
+	t.Run("Product of another seller", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id, UserId: uuid.New()}, nil).Once()
+
+		seller := &models.User{ID: uuid.New(), Role: models.RoleSeller}
+		_, err := u.AddImages(context.Background(), id, fileHeaders(t, map[string][]byte{"new.png": png}), seller)
+		assert.ErrorIs(t, err, products.ErrNotProductOwner)
+	})
+}
+
+func TestAttachImages(t *testing.T) {
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(mockCloudinary.NewCloudUploader(t), repo, newCategoryRepo(t), nil, nil)
+
+	id, userID := uuid.New(), uuid.New()
+	seller := &models.User{ID: userID, Role: models.RoleSeller}
+	attached := models.Images{PublicId: "products/new", Url: "https://img/new.png", ProductId: id}
+
+	t.Run("Uploads are attached", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id, UserId: userID}, nil).Once()
+		repo.On("AttachUploads", id, userID, []string{"products/new"}).Return(nil).Once()
+		repo.On("FetchImageUrlById", id).Return([]models.Images{attached}, nil).Once()
+		repo.On("FetchVariants", id).Return(nil, nil).Once()
+
+		images, err := u.AttachImages(id, []string{"products/new"}, seller)
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.Images{attached}, images)
+	})
+
+	t.Run("Upload expired or already used", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id, UserId: userID}, nil).Once()
+		repo.On("AttachUploads", id, userID, []string{"products/new"}).Return(sql.ErrNoRows).Once()
+
+		_, err := u.AttachImages(id, []string{"products/new"}, seller)
+		assert.ErrorIs(t, err, products.ErrUploadNotFound)
+	})
+
+	t.Run("Product of another seller", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id, UserId: uuid.New()}, nil).Once()
+
+		_, err := u.AttachImages(id, []string{"products/new"}, seller)
+		assert.ErrorIs(t, err, products.ErrNotProductOwner)
+	})
+}
+
+func TestDeleteImage(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
+
+	u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+
+	id := uuid.New()
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	kept := models.Images{PublicId: "products/kept", Url: "https://img/kept.png", ProductId: id}
+
+	t.Run("Only the image is deleted", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id}, nil).Once()
+		repo.On("DeleteImage", id, "products/old").Return(nil).Once()
+		cld.On("Destroy", mock.Anything, "products/old").Return(nil, nil).Once()
+		repo.On("FetchImageUrlById", id).Return([]models.Images{kept}, nil).Once()
+		repo.On("FetchVariants", id).Return(nil, nil).Once()
+
+		images, err := u.DeleteImage(context.Background(), id, "products/old", admin)
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.Images{kept}, images)
+	})
+
+	t.Run("Image not found", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id}, nil).Once()
+		repo.On("DeleteImage", id, "products/other").Return(sql.ErrNoRows).Once()
+
+		_, err := u.DeleteImage(context.Background(), id, "products/other", admin)
+		assert.ErrorIs(t, err, products.ErrImageNotFound)
+	})
+
+	t.Run("Product of another seller", func(t *testing.T) {
+		repo.On("FetchProductById", id).Return(&models.Product{ProductId: id, UserId: uuid.New()}, nil).Once()
+
+		seller := &models.User{ID: uuid.New(), Role: models.RoleSeller}
+		_, err := u.DeleteImage(context.Background(), id, "products/old", seller)
+		assert.ErrorIs(t, err, products.ErrNotProductOwner)
+	})
+}
+
+func TestPublishScheduled(t *testing.T) {
+	cld := mockCloudinary.NewCloudUploader(t)
+	repo := mockProd.NewRepo(t)
+
+	t.Run("Published drafts are announced", func(t *testing.T) {
+		published := make(chan events.Event, 2)
+		bus := events.New(mockLogger.NewLogger(t))
+		bus.Subscribe(func(e events.Event) error {
+			published <- e
+			return nil
+		}, events.ProductUpdated)
+		u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), bus, nil)
+
+		id := uuid.New()
+		repo.On("PublishScheduled", mock.Anything, mock.AnythingOfType("time.Time")).Return([]uuid.UUID{id}, nil).Once()
+		repo.On("FetchProductsByIds", []uuid.UUID{id}).
+			Return([]*models.Product{{ProductId: id, Status: models.ProductPublished}}, nil).Once()
+		repo.On("FetchImagesByProductIds", []uuid.UUID{id}).Return(nil, nil).Once()
+
+		n, err := u.PublishScheduled(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		bus.Close()
+		close(published)
+		e := <-published
+		assert.Equal(t, id, e.Data.(models.Product).ProductId)
+	})
+
+	t.Run("Nothing due", func(t *testing.T) {
+		u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+		repo.On("PublishScheduled", mock.Anything, mock.AnythingOfType("time.Time")).Return(nil, nil).Once()
+
+		n, err := u.PublishScheduled(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		u := usecase.NewProductsUC(cld, repo, newCategoryRepo(t), nil, nil)
+		repo.On("PublishScheduled", mock.Anything, mock.AnythingOfType("time.Time")).Return(nil, errors.New("db down")).Once()
+
+		_, err := u.PublishScheduled(context.Background())
+		assert.Error(t, err)
 	})
 }
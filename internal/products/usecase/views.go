@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+)
+
+const (
+	// DefaultRelatedLimit is how many products GetRelatedProducts and GetRecentlyViewed
+	// return when no limit is given.
+	DefaultRelatedLimit = 8
+
+	// MaxRelatedLimit caps the products GetRelatedProducts and GetRecentlyViewed return.
+	MaxRelatedLimit = 24
+
+	// maxSessionLen is the longest anonymous session recorded with views.
+	maxSessionLen = 64
+)
+
+// GetRelatedProducts returns up to limit products in stock related to productId, like
+// GetRecommendations: those most often bought with it, then others of its category
+// rated closest to it. A non-positive limit falls back to DefaultRelatedLimit. It
+// returns products.ErrProductNotFound when the product does not exist or is hidden.
+func (p *ProductsUC) GetRelatedProducts(productId uuid.UUID, limit int) ([]models.Recommendation, error) {
+	limit = relatedLimit(limit)
+
+	recs, err := p.repo.FetchRelatedProducts([]uuid.UUID{productId}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching related products: %v", err)
+	}
+
+	if len(recs) == 0 {
+		// none could be the product missing as well as having nothing related
+		prod, err := p.product(productId)
+		if err != nil {
+			return nil, err
+		}
+		if !prod.Listed() {
+			return nil, products.ErrProductNotFound
+		}
+
+		return []models.Recommendation{}, nil
+	}
+
+	return recs, nil
+}
+
+// RecordView records that viewer viewed productId, for their recently viewed products.
+// Viewers without a user or a session, such as clients that do not keep cookies, are
+// not recorded. It returns products.ErrProductNotFound when the product does not exist
+// or is hidden.
+func (p *ProductsUC) RecordView(productId uuid.UUID, viewer models.Viewer) error {
+	if !known(viewer) {
+		return nil
+	}
+
+	if err := p.repo.InsertView(productId, viewer); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return products.ErrProductNotFound
+		}
+		return fmt.Errorf("error saving product view: %v", err)
+	}
+
+	return nil
+}
+
+// GetRecentlyViewed returns up to limit products viewer viewed, the last viewed first.
+// A non-positive limit falls back to DefaultRelatedLimit.
+func (p *ProductsUC) GetRecentlyViewed(viewer models.Viewer, limit int) ([]models.Recommendation, error) {
+	if !known(viewer) {
+		return []models.Recommendation{}, nil
+	}
+
+	recs, err := p.repo.FetchRecentlyViewed(viewer, relatedLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching recently viewed products: %v", err)
+	}
+	if recs == nil {
+		recs = []models.Recommendation{}
+	}
+
+	return recs, nil
+}
+
+// known reports whether the views of viewer can be told apart from others.
+func known(viewer models.Viewer) bool {
+	if viewer.UserID.Valid {
+		return true
+	}
+
+	return viewer.Session != "" && len(viewer.Session) <= maxSessionLen
+}
+
+func relatedLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultRelatedLimit
+	}
+	if limit > MaxRelatedLimit {
+		return MaxRelatedLimit
+	}
+
+	return limit
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/products"
+)
+
+// VoteReview records whether a user found a review helpful, replacing the vote they
+// gave before, and returns the votes on the review. It returns
+// products.ErrReviewNotFound when there is no such review.
+func (p *ProductsUC) VoteReview(reviewId, userId uuid.UUID, helpful bool) (models.ReviewVotes, error) {
+	if err := p.repo.UpsertReviewVote(reviewId, userId, helpful); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ReviewVotes{}, products.ErrReviewNotFound
+		}
+		return models.ReviewVotes{}, fmt.Errorf("error saving review vote: %v", err)
+	}
+
+	return p.reviewVotes(reviewId)
+}
+
+// WithdrawReviewVote withdraws the vote of a user on a review, if they voted, and
+// returns the votes on the review. It returns products.ErrReviewNotFound when there
+// is no such review.
+func (p *ProductsUC) WithdrawReviewVote(reviewId, userId uuid.UUID) (models.ReviewVotes, error) {
+	if err := p.repo.DeleteReviewVote(reviewId, userId); err != nil {
+		return models.ReviewVotes{}, fmt.Errorf("error deleting review vote: %v", err)
+	}
+
+	return p.reviewVotes(reviewId)
+}
+
+// reviewVotes returns the votes on a review, products.ErrReviewNotFound when there is
+// no such review.
+func (p *ProductsUC) reviewVotes(reviewId uuid.UUID) (models.ReviewVotes, error) {
+	votes, err := p.repo.FetchReviewVotes(reviewId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ReviewVotes{}, products.ErrReviewNotFound
+		}
+		return models.ReviewVotes{}, fmt.Errorf("error fetching review votes: %v", err)
+	}
+
+	return votes, nil
+}
@@ -0,0 +1,206 @@
+// Package delivery provides HTTP handlers for coupons.
+//
+// Admins manage coupon codes; customers check what a code takes off their cart
+// before placing the order it is redeemed for.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/promotions"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// couponCodeRX matches the characters a coupon code can be made of.
+var couponCodeRX = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// PromotionHandlers provides HTTP handler methods for coupon endpoints.
+type PromotionHandlers struct {
+	logger      logger.Logger
+	promotionUC promotions.PromotionUC
+}
+
+// NewPromotionHandlers returns a new PromotionHandlers.
+func NewPromotionHandlers(logger logger.Logger, promotionUC promotions.PromotionUC) *PromotionHandlers {
+	return &PromotionHandlers{
+		logger:      logger,
+		promotionUC: promotionUC,
+	}
+}
+
+type couponResponse struct {
+	Success bool           `json:"success"`
+	Coupon  *models.Coupon `json:"coupon"`
+}
+
+// GetCoupons returns every coupon (admin).
+// Endpoint: GET /api/v1/promotions/admin/coupons
+func (h *PromotionHandlers) GetCoupons(w http.ResponseWriter, r *http.Request) {
+	coupons, err := h.promotionUC.GetCoupons()
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting coupons: %w", err))
+		return
+	}
+
+	jr := struct {
+		Success bool             `json:"success"`
+		Coupons []*models.Coupon `json:"coupons"`
+	}{
+		Success: true,
+		Coupons: coupons,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// GetCoupon returns a coupon (admin).
+// Endpoint: GET /api/v1/promotions/admin/coupon/{id}
+func (h *PromotionHandlers) GetCoupon(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	c, err := h.promotionUC.GetCoupon(id)
+	if err != nil {
+		h.writeError(w, r, "error getting coupon", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, couponResponse{Success: true, Coupon: c})
+}
+
+// CreateCoupon creates a coupon (admin).
+// Endpoint: POST /api/v1/promotions/admin/coupon
+// Expects JSON body: {"code": <string>, "type": "percentage|fixed", "value": <int>, "minOrderValue": <money>,
+// "maxUses": <int>, "maxUsesPerUser": <int>, "expiresAt": <RFC 3339 time or null>, "active": <bool>}.
+func (h *PromotionHandlers) CreateCoupon(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.readCoupon(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.promotionUC.CreateCoupon(c)
+	if err != nil {
+		h.writeError(w, r, "error creating coupon", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusCreated, couponResponse{Success: true, Coupon: created})
+}
+
+// UpdateCoupon replaces the settings of a coupon (admin).
+// Endpoint: PUT /api/v1/promotions/admin/coupon/{id}
+// Expects the JSON body of CreateCoupon.
+func (h *PromotionHandlers) UpdateCoupon(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	c, ok := h.readCoupon(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.promotionUC.UpdateCoupon(id, c)
+	if err != nil {
+		h.writeError(w, r, "error updating coupon", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, couponResponse{Success: true, Coupon: updated})
+}
+
+// DeleteCoupon deletes a coupon (admin).
+// Endpoint: DELETE /api/v1/promotions/admin/coupon/{id}
+func (h *PromotionHandlers) DeleteCoupon(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	if err := h.promotionUC.DeleteCoupon(id); err != nil {
+		h.writeError(w, r, "error deleting coupon", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, models.Response{Success: true, Message: "coupon deleted"})
+}
+
+// ApplyCoupon returns what a coupon takes off the cart of the current user, without
+// using it. The coupon is redeemed when the order is placed with its code.
+// Endpoint: POST /api/v1/cart/apply-coupon
+// Expects JSON body: {"code": <string>, "itemsPrice": <money>}.
+func (h *PromotionHandlers) ApplyCoupon(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	var req applyCouponRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	quote, err := h.promotionUC.ApplyCoupon(strings.TrimSpace(req.Code), user.ID, req.ItemsPrice)
+	if err != nil {
+		h.writeError(w, r, "error applying coupon", err)
+		return
+	}
+
+	jr := struct {
+		Success bool                `json:"success"`
+		Coupon  *models.CouponQuote `json:"coupon"`
+	}{
+		Success: true,
+		Coupon:  quote,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// readCoupon reads and validates the coupon in the request body. It writes the
+// response and returns false when the body is not a valid coupon.
+func (h *PromotionHandlers) readCoupon(w http.ResponseWriter, r *http.Request) (models.Coupon, bool) {
+	var req couponRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return models.Coupon{}, false
+	}
+
+	return models.Coupon{
+		Code:           strings.TrimSpace(req.Code),
+		Type:           req.Type,
+		Value:          req.Value,
+		MinOrderValue:  req.MinOrderValue,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+		ExpiresAt:      req.ExpiresAt,
+		Active:         req.Active == nil || *req.Active,
+	}, true
+}
+
+func (h *PromotionHandlers) writeError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if promotions.IsClientError(err) {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("%s: %v", msg, err)
+		return
+	}
+	_ = utils.ServerError(w, r, h.logger, fmt.Errorf("%s: %w", msg, err))
+}
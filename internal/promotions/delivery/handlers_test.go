@@ -0,0 +1,110 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/promotions"
+	"github.com/jofosuware/go/shopit/internal/promotions/delivery"
+	"github.com/jofosuware/go/shopit/internal/promotions/mocks"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCoupon(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	promotionUC := mocks.NewPromotionUC(t)
+
+	h := delivery.NewPromotionHandlers(logger, promotionUC)
+	newRequest := func(body string) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/promotions/admin/coupon", bytes.NewBufferString(body))
+	}
+
+	t.Run("Coupon is created", func(t *testing.T) {
+		promotionUC.On("CreateCoupon", mock.MatchedBy(func(c models.Coupon) bool {
+			return c.Code == "SAVE10" && c.Type == models.CouponPercentage && c.Value == 10 && c.MaxUses == 100 &&
+				c.ExpiresAt != nil && c.Active
+		})).Return(&models.Coupon{ID: uuid.New(), Code: "SAVE10"}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.CreateCoupon(rr, newRequest(`{"code":"SAVE10","type":"percentage","value":10,"maxUses":100,
+			"expiresAt":"2030-01-01T00:00:00Z"}`))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Invalid coupon", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.CreateCoupon(rr, newRequest(`{"code":"SAVE 10","type":"percentage","value":150,"maxUses":-1}`))
+
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		var resp struct {
+			Errors map[string]string `json:"errors"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Contains(t, resp.Errors, "code")
+		assert.Contains(t, resp.Errors, "value")
+		assert.Contains(t, resp.Errors, "maxUses")
+	})
+
+	t.Run("Code taken", func(t *testing.T) {
+		promotionUC.On("CreateCoupon", mock.Anything).Return(nil, promotions.ErrCouponExists).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.CreateCoupon(rr, newRequest(`{"code":"SAVE10","type":"fixed","value":10}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestApplyCoupon(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	promotionUC := mocks.NewPromotionUC(t)
+
+	h := delivery.NewPromotionHandlers(logger, promotionUC)
+	user := models.User{ID: uuid.New()}
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/cart/apply-coupon", bytes.NewBufferString(body))
+		return req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, &user))
+	}
+
+	t.Run("Discount is quoted", func(t *testing.T) {
+		promotionUC.On("ApplyCoupon", "save10", user.ID, money.Of(20000)).
+			Return(&models.CouponQuote{Code: "SAVE10", ItemsPrice: money.Of(20000), Discount: money.Of(2000), DiscountedPrice: money.Of(18000)}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.ApplyCoupon(rr, newRequest(`{"code":"save10","itemsPrice":"200.00"}`))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Coupon models.CouponQuote `json:"coupon"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, money.Of(18000), resp.Coupon.DiscountedPrice)
+	})
+
+	t.Run("Expired coupon", func(t *testing.T) {
+		promotionUC.On("ApplyCoupon", "old", user.ID, money.Of(20000)).Return(nil, promotions.ErrCouponExpired).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.ApplyCoupon(rr, newRequest(`{"code":"old","itemsPrice":{"amount":20000,"currency":"USD"}}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
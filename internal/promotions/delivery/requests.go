@@ -0,0 +1,51 @@
+package delivery
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// applyCouponRequest is the body of ApplyCoupon.
+type applyCouponRequest struct {
+	Code       string      `json:"code" validate:"notblank"`
+	ItemsPrice money.Money `json:"itemsPrice"`
+}
+
+func (req *applyCouponRequest) Validate(v *validator.Validator) {
+	v.CheckCode(req.ItemsPrice.IsPositive(), "itemsPrice", validator.CodeTooSmall, "items price must be positive")
+}
+
+// couponRequest is the body of the endpoints creating and updating a coupon. A coupon
+// is active unless active is false.
+type couponRequest struct {
+	Code           string      `json:"code" validate:"notblank"`
+	Type           string      `json:"type"`
+	Value          int         `json:"value" validate:"gt=0"`
+	MinOrderValue  money.Money `json:"minOrderValue"`
+	MaxUses        int         `json:"maxUses" validate:"min=0"`
+	MaxUsesPerUser int         `json:"maxUsesPerUser" validate:"min=0"`
+	ExpiresAt      *time.Time  `json:"expiresAt"`
+	Active         *bool       `json:"active"`
+}
+
+func (req *couponRequest) Validate(v *validator.Validator) {
+	code := strings.TrimSpace(req.Code)
+
+	v.CheckCode(len(code) <= models.MaxCouponCodeLength, "code", validator.CodeTooLong,
+		fmt.Sprintf("coupon code must not be more than %d characters", models.MaxCouponCodeLength))
+	v.Check(code == "" || couponCodeRX.MatchString(code), "code",
+		"coupon code may only contain letters, digits, dashes and underscores")
+	v.CheckCode(req.Type == models.CouponPercentage || req.Type == models.CouponFixed, "type", validator.CodeOneOf,
+		fmt.Sprintf("type must be %s or %s", models.CouponPercentage, models.CouponFixed))
+	v.CheckCode(req.Type != models.CouponPercentage || req.Value <= 100, "value", validator.CodeTooLarge,
+		"a percentage must not be more than 100")
+	v.CheckCode(!req.MinOrderValue.IsNegative(), "minOrderValue", validator.CodeTooSmall,
+		"minimum order value must not be negative")
+	v.Check(req.MinOrderValue.Currency == "" || req.MinOrderValue.Currency == money.DefaultCurrency, "minOrderValue",
+		"minimum order value must be in the shop currency")
+}
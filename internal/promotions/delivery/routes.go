@@ -0,0 +1,43 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// PromotionRouter returns a chi.Router with the coupon admin routes.
+//
+//   - GET    /admin/coupons      → List coupons (admin)
+//   - GET    /admin/coupon/{id}  → Get a coupon (admin)
+//   - POST   /admin/coupon       → Create a coupon (admin)
+//   - PUT    /admin/coupon/{id}  → Update a coupon (admin)
+//   - DELETE /admin/coupon/{id}  → Delete a coupon (admin)
+func (h *PromotionHandlers) PromotionRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+	mux.Use(utils.IsAdmin)
+
+	mux.Get("/admin/coupons", h.GetCoupons)
+	mux.Get("/admin/coupon/{id}", h.GetCoupon)
+	mux.Post("/admin/coupon", h.CreateCoupon)
+	mux.Put("/admin/coupon/{id}", h.UpdateCoupon)
+	mux.Delete("/admin/coupon/{id}", h.DeleteCoupon)
+
+	return mux
+}
+
+// CartRouter returns a chi.Router with the cart routes.
+//
+//   - POST /apply-coupon → Check what a coupon takes off a cart
+func (h *PromotionHandlers) CartRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+
+	mux.Post("/apply-coupon", h.ApplyCoupon)
+
+	return mux
+}
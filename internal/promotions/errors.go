@@ -0,0 +1,38 @@
+package promotions
+
+import "errors"
+
+var (
+	// ErrCouponNotFound is returned when a coupon does not exist.
+	ErrCouponNotFound = errors.New("coupon not found")
+
+	// ErrCouponExists is returned when another coupon already has the code.
+	ErrCouponExists = errors.New("a coupon with this code already exists")
+
+	// ErrCouponInactive is returned when a coupon was switched off.
+	ErrCouponInactive = errors.New("coupon is not active")
+
+	// ErrCouponExpired is returned when a coupon is past its expiry.
+	ErrCouponExpired = errors.New("coupon has expired")
+
+	// ErrCouponUsedUp is returned when a coupon reached its usage limit, overall or for the user.
+	ErrCouponUsedUp = errors.New("coupon usage limit reached")
+
+	// ErrBelowMinimum is returned when the items price is below the minimum order value of a coupon.
+	ErrBelowMinimum = errors.New("order does not reach the minimum value of the coupon")
+
+	// ErrCouponCurrency is returned when a coupon is used on an order in another currency than the shop's.
+	ErrCouponCurrency = errors.New("coupons can only be used on orders in the shop currency")
+)
+
+// IsClientError reports whether err is caused by the request rather than the server.
+func IsClientError(err error) bool {
+	for _, e := range []error{ErrCouponNotFound, ErrCouponExists, ErrCouponInactive, ErrCouponExpired,
+		ErrCouponUsedUp, ErrBelowMinimum, ErrCouponCurrency} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+
+	return false
+}
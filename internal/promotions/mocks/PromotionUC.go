@@ -0,0 +1,264 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	money "github.com/jofosuware/go/shopit/pkg/money"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// PromotionUC is an autogenerated mock type for the PromotionUC type
+type PromotionUC struct {
+	mock.Mock
+}
+
+// ApplyCoupon provides a mock function with given fields: code, userID, itemsPrice
+func (_m *PromotionUC) ApplyCoupon(code string, userID uuid.UUID, itemsPrice money.Money) (*models.CouponQuote, error) {
+	ret := _m.Called(code, userID, itemsPrice)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyCoupon")
+	}
+
+	var r0 *models.CouponQuote
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, uuid.UUID, money.Money) (*models.CouponQuote, error)); ok {
+		return rf(code, userID, itemsPrice)
+	}
+	if rf, ok := ret.Get(0).(func(string, uuid.UUID, money.Money) *models.CouponQuote); ok {
+		r0 = rf(code, userID, itemsPrice)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CouponQuote)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, uuid.UUID, money.Money) error); ok {
+		r1 = rf(code, userID, itemsPrice)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AttachOrder provides a mock function with given fields: redemptionID, orderID
+func (_m *PromotionUC) AttachOrder(redemptionID uuid.UUID, orderID uuid.UUID) error {
+	ret := _m.Called(redemptionID, orderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AttachOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(redemptionID, orderID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateCoupon provides a mock function with given fields: c
+func (_m *PromotionUC) CreateCoupon(c models.Coupon) (*models.Coupon, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCoupon")
+	}
+
+	var r0 *models.Coupon
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Coupon) (*models.Coupon, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(models.Coupon) *models.Coupon); ok {
+		r0 = rf(c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Coupon)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Coupon) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteCoupon provides a mock function with given fields: id
+func (_m *PromotionUC) DeleteCoupon(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCoupon")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCoupon provides a mock function with given fields: id
+func (_m *PromotionUC) GetCoupon(id uuid.UUID) (*models.Coupon, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCoupon")
+	}
+
+	var r0 *models.Coupon
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Coupon, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Coupon); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Coupon)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCoupons provides a mock function with given fields:
+func (_m *PromotionUC) GetCoupons() ([]*models.Coupon, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCoupons")
+	}
+
+	var r0 []*models.Coupon
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.Coupon, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.Coupon); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Coupon)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Redeem provides a mock function with given fields: code, userID, itemsPrice
+func (_m *PromotionUC) Redeem(code string, userID uuid.UUID, itemsPrice money.Money) (*models.CouponRedemption, error) {
+	ret := _m.Called(code, userID, itemsPrice)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Redeem")
+	}
+
+	var r0 *models.CouponRedemption
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, uuid.UUID, money.Money) (*models.CouponRedemption, error)); ok {
+		return rf(code, userID, itemsPrice)
+	}
+	if rf, ok := ret.Get(0).(func(string, uuid.UUID, money.Money) *models.CouponRedemption); ok {
+		r0 = rf(code, userID, itemsPrice)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CouponRedemption)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, uuid.UUID, money.Money) error); ok {
+		r1 = rf(code, userID, itemsPrice)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: redemptionID
+func (_m *PromotionUC) Release(redemptionID uuid.UUID) error {
+	ret := _m.Called(redemptionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(redemptionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCoupon provides a mock function with given fields: id, c
+func (_m *PromotionUC) UpdateCoupon(id uuid.UUID, c models.Coupon) (*models.Coupon, error) {
+	ret := _m.Called(id, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCoupon")
+	}
+
+	var r0 *models.Coupon
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.Coupon) (*models.Coupon, error)); ok {
+		return rf(id, c)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, models.Coupon) *models.Coupon); ok {
+		r0 = rf(id, c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Coupon)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, models.Coupon) error); ok {
+		r1 = rf(id, c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPromotionUC creates a new instance of PromotionUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPromotionUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PromotionUC {
+	mock := &PromotionUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,321 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+// CountRedemptions provides a mock function with given fields: couponID, userID
+func (_m *Repo) CountRedemptions(couponID uuid.UUID, userID uuid.UUID) (int, error) {
+	ret := _m.Called(couponID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountRedemptions")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) (int, error)); ok {
+		return rf(couponID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) int); ok {
+		r0 = rf(couponID, userID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(couponID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CouponCodeExists provides a mock function with given fields: code, exclude
+func (_m *Repo) CouponCodeExists(code string, exclude uuid.UUID) (bool, error) {
+	ret := _m.Called(code, exclude)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CouponCodeExists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, uuid.UUID) (bool, error)); ok {
+		return rf(code, exclude)
+	}
+	if rf, ok := ret.Get(0).(func(string, uuid.UUID) bool); ok {
+		r0 = rf(code, exclude)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, uuid.UUID) error); ok {
+		r1 = rf(code, exclude)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteCoupon provides a mock function with given fields: id
+func (_m *Repo) DeleteCoupon(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCoupon")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRedemption provides a mock function with given fields: id
+func (_m *Repo) DeleteRedemption(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRedemption")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FetchCouponByCode provides a mock function with given fields: code
+func (_m *Repo) FetchCouponByCode(code string) (*models.Coupon, error) {
+	ret := _m.Called(code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchCouponByCode")
+	}
+
+	var r0 *models.Coupon
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.Coupon, error)); ok {
+		return rf(code)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.Coupon); ok {
+		r0 = rf(code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Coupon)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchCouponById provides a mock function with given fields: id
+func (_m *Repo) FetchCouponById(id uuid.UUID) (*models.Coupon, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchCouponById")
+	}
+
+	var r0 *models.Coupon
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Coupon, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Coupon); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Coupon)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchCoupons provides a mock function with given fields:
+func (_m *Repo) FetchCoupons() ([]*models.Coupon, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchCoupons")
+	}
+
+	var r0 []*models.Coupon
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.Coupon, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.Coupon); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Coupon)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertCoupon provides a mock function with given fields: c
+func (_m *Repo) InsertCoupon(c models.Coupon) (*models.Coupon, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertCoupon")
+	}
+
+	var r0 *models.Coupon
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Coupon) (*models.Coupon, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(models.Coupon) *models.Coupon); ok {
+		r0 = rf(c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Coupon)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Coupon) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertRedemption provides a mock function with given fields: r, now
+func (_m *Repo) InsertRedemption(r models.CouponRedemption, now time.Time) (*models.CouponRedemption, error) {
+	ret := _m.Called(r, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertRedemption")
+	}
+
+	var r0 *models.CouponRedemption
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.CouponRedemption, time.Time) (*models.CouponRedemption, error)); ok {
+		return rf(r, now)
+	}
+	if rf, ok := ret.Get(0).(func(models.CouponRedemption, time.Time) *models.CouponRedemption); ok {
+		r0 = rf(r, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CouponRedemption)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.CouponRedemption, time.Time) error); ok {
+		r1 = rf(r, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCoupon provides a mock function with given fields: c
+func (_m *Repo) UpdateCoupon(c models.Coupon) (*models.Coupon, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCoupon")
+	}
+
+	var r0 *models.Coupon
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Coupon) (*models.Coupon, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(models.Coupon) *models.Coupon); ok {
+		r0 = rf(c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Coupon)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Coupon) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateRedemptionOrder provides a mock function with given fields: id, orderID
+func (_m *Repo) UpdateRedemptionOrder(id uuid.UUID, orderID uuid.UUID) error {
+	ret := _m.Called(id, orderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRedemptionOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(id, orderID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
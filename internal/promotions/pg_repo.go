@@ -0,0 +1,43 @@
+package promotions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// FetchCoupons fetches every coupon, newest first, returns an error on failure
+	FetchCoupons() ([]*models.Coupon, error)
+
+	// FetchCouponById fetches a coupon by id, returns sql.ErrNoRows when there is no such coupon
+	FetchCouponById(id uuid.UUID) (*models.Coupon, error)
+
+	// FetchCouponByCode fetches a coupon by code regardless of case, returns sql.ErrNoRows when there is no such coupon
+	FetchCouponByCode(code string) (*models.Coupon, error)
+
+	// CouponCodeExists reports whether a coupon other than exclude has code, regardless of case
+	CouponCodeExists(code string, exclude uuid.UUID) (bool, error)
+
+	// InsertCoupon inserts a coupon, returns the saved coupon and an error on failure
+	InsertCoupon(c models.Coupon) (*models.Coupon, error)
+
+	// UpdateCoupon updates a coupon but not its usage count, returns sql.ErrNoRows when there is no such coupon
+	UpdateCoupon(c models.Coupon) (*models.Coupon, error)
+
+	// DeleteCoupon deletes a coupon with its redemptions, returns sql.ErrNoRows when there is no such coupon
+	DeleteCoupon(id uuid.UUID) error
+
+	// CountRedemptions counts the redemptions of a coupon by a user, returns an error on failure
+	CountRedemptions(couponID, userID uuid.UUID) (int, error)
+
+	// InsertRedemption uses a coupon once, returns sql.ErrNoRows when the coupon can no longer be used at now
+	InsertRedemption(r models.CouponRedemption, now time.Time) (*models.CouponRedemption, error)
+
+	// UpdateRedemptionOrder records the order a redemption was made for, returns an error on failure
+	UpdateRedemptionOrder(id, orderID uuid.UUID) error
+
+	// DeleteRedemption gives back the use of a coupon, returns an error on failure
+	DeleteRedemption(id uuid.UUID) error
+}
@@ -0,0 +1,274 @@
+// Package repository provides persistence for coupons and their redemptions.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// PromotionsRepository handles coupon database operations.
+type PromotionsRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewPromotionsRepository returns a new PromotionsRepository.
+func NewPromotionsRepository(db *sql.DB) *PromotionsRepository {
+	return &PromotionsRepository{
+		DB: db,
+	}
+}
+
+const couponColumns = `coupon_id, code, type, value, min_order_value, max_uses, max_uses_per_user, used_count,
+				expires_at, active, created_at`
+
+// FetchCoupons fetches every coupon, newest first.
+func (r *PromotionsRepository) FetchCoupons() ([]*models.Coupon, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, "select "+couponColumns+" from coupons order by created_at desc")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var coupons []*models.Coupon
+	for rows.Next() {
+		c, err := scanCoupon(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		coupons = append(coupons, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return coupons, nil
+}
+
+// FetchCouponById fetches a coupon by id. It returns sql.ErrNoRows when there is no
+// such coupon.
+func (r *PromotionsRepository) FetchCouponById(id uuid.UUID) (*models.Coupon, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	row := r.DB.QueryRowContext(ctx, "select "+couponColumns+" from coupons where coupon_id = $1", id)
+
+	return scanCoupon(row)
+}
+
+// FetchCouponByCode fetches a coupon by code, regardless of case. It returns
+// sql.ErrNoRows when there is no such coupon.
+func (r *PromotionsRepository) FetchCouponByCode(code string) (*models.Coupon, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	row := r.DB.QueryRowContext(ctx, "select "+couponColumns+" from coupons where upper(code) = upper($1)", code)
+
+	return scanCoupon(row)
+}
+
+// CouponCodeExists reports whether a coupon other than exclude already has code,
+// regardless of case.
+func (r *PromotionsRepository) CouponCodeExists(code string, exclude uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var exists bool
+
+	query := "select exists(select 1 from coupons where upper(code) = upper($1) and coupon_id <> $2)"
+
+	if err := r.DB.QueryRowContext(ctx, query, code, exclude).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// InsertCoupon inserts a coupon.
+func (r *PromotionsRepository) InsertCoupon(c models.Coupon) (*models.Coupon, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into coupons (code, type, value, min_order_value, max_uses, max_uses_per_user, expires_at,
+				active, created_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning ` + couponColumns
+
+	row := r.DB.QueryRowContext(ctx, query, c.Code, c.Type, c.Value, c.MinOrderValue, c.MaxUses,
+		c.MaxUsesPerUser, c.ExpiresAt, c.Active, time.Now())
+
+	return scanCoupon(row)
+}
+
+// UpdateCoupon updates a coupon, leaving its usage count alone. It returns
+// sql.ErrNoRows when there is no such coupon.
+func (r *PromotionsRepository) UpdateCoupon(c models.Coupon) (*models.Coupon, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `update coupons set code = $1, type = $2, value = $3, min_order_value = $4, max_uses = $5,
+				max_uses_per_user = $6, expires_at = $7, active = $8 where coupon_id = $9 returning ` + couponColumns
+
+	row := r.DB.QueryRowContext(ctx, query, c.Code, c.Type, c.Value, c.MinOrderValue, c.MaxUses,
+		c.MaxUsesPerUser, c.ExpiresAt, c.Active, c.ID)
+
+	return scanCoupon(row)
+}
+
+// DeleteCoupon deletes a coupon and its redemptions; orders keep the code and
+// discount they were placed with. It returns sql.ErrNoRows when there is no such
+// coupon.
+func (r *PromotionsRepository) DeleteCoupon(id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.DB.ExecContext(ctx, "delete from coupons where coupon_id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CountRedemptions counts the redemptions of a coupon by a user.
+func (r *PromotionsRepository) CountRedemptions(couponID, userID uuid.UUID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var n int
+
+	query := "select count(*) from coupon_redemptions where coupon_id = $1 and user_id = $2"
+
+	if err := r.DB.QueryRowContext(ctx, query, couponID, userID).Scan(&n); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// InsertRedemption counts a use of the coupon and records the redemption. The coupon
+// row stays locked until the redemption is saved, so concurrent redemptions cannot
+// exceed its limits. It returns sql.ErrNoRows when the coupon is inactive, expired at
+// now or used up, overall or by the user.
+func (r *PromotionsRepository) InsertRedemption(red models.CouponRedemption, now time.Time) (*models.CouponRedemption, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `update coupons set used_count = used_count + 1
+				where coupon_id = $1 and active and (max_uses = 0 or used_count < max_uses)
+				and (expires_at is null or expires_at > $2)
+				returning code, max_uses_per_user`
+
+	var perUser int
+	if err := tx.QueryRowContext(ctx, query, red.CouponID, now).Scan(&red.Code, &perUser); err != nil {
+		return nil, err
+	}
+
+	if perUser > 0 {
+		var used int
+		err := tx.QueryRowContext(ctx, "select count(*) from coupon_redemptions where coupon_id = $1 and user_id = $2",
+			red.CouponID, red.UserID).Scan(&used)
+		if err != nil {
+			return nil, err
+		}
+
+		if used >= perUser {
+			return nil, sql.ErrNoRows
+		}
+	}
+
+	query = `insert into coupon_redemptions (coupon_id, user_id, discount, created_at) values ($1, $2, $3, $4)
+				returning redemption_id, created_at`
+
+	err = tx.QueryRowContext(ctx, query, red.CouponID, red.UserID, red.Discount, now).Scan(&red.ID, &red.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &red, nil
+}
+
+// UpdateRedemptionOrder records the order a redemption was made for.
+func (r *PromotionsRepository) UpdateRedemptionOrder(id, orderID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, "update coupon_redemptions set order_id = $1 where redemption_id = $2", orderID, id)
+
+	return err
+}
+
+// DeleteRedemption deletes a redemption and gives its use back to the coupon.
+func (r *PromotionsRepository) DeleteRedemption(id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var couponID uuid.UUID
+	err = tx.QueryRowContext(ctx, "delete from coupon_redemptions where redemption_id = $1 returning coupon_id", id).
+		Scan(&couponID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "update coupons set used_count = used_count - 1 where coupon_id = $1 and used_count > 0",
+		couponID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCoupon(row scanner) (*models.Coupon, error) {
+	var (
+		c       models.Coupon
+		expires sql.NullTime
+	)
+
+	err := row.Scan(&c.ID, &c.Code, &c.Type, &c.Value, &c.MinOrderValue, &c.MaxUses, &c.MaxUsesPerUser,
+		&c.UsedCount, &expires, &c.Active, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if expires.Valid {
+		c.ExpiresAt = &expires.Time
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,114 @@
+package repository_test
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/promotions/repository"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var couponColumns = []string{"coupon_id", "code", "type", "value", "min_order_value", "max_uses", "max_uses_per_user",
+	"used_count", "expires_at", "active", "created_at"}
+
+func TestFetchCouponByCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPromotionsRepository(db)
+	id := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("from coupons where upper(code) = upper($1)")).WithArgs("save10").
+		WillReturnRows(sqlmock.NewRows(couponColumns).
+			AddRow(id, "SAVE10", models.CouponPercentage, 10, 0, 100, 1, 3, nil, true, time.Now()))
+
+	c, err := repo.FetchCouponByCode("save10")
+	require.NoError(t, err)
+	assert.Equal(t, id, c.ID)
+	assert.Equal(t, 3, c.UsedCount)
+	assert.Nil(t, c.ExpiresAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertRedemption(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPromotionsRepository(db)
+	now := time.Now()
+	use := regexp.QuoteMeta("update coupons set used_count = used_count + 1")
+	count := regexp.QuoteMeta("select count(*) from coupon_redemptions where coupon_id = $1 and user_id = $2")
+
+	t.Run("Coupon is used", func(t *testing.T) {
+		red := models.CouponRedemption{CouponID: uuid.New(), UserID: uuid.New(), Discount: money.Of(20)}
+		id := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(use).WithArgs(red.CouponID, now).
+			WillReturnRows(sqlmock.NewRows([]string{"code", "max_uses_per_user"}).AddRow("SAVE10", 2))
+		mock.ExpectQuery(count).WithArgs(red.CouponID, red.UserID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery("insert into coupon_redemptions").WithArgs(red.CouponID, red.UserID, 20, now).
+			WillReturnRows(sqlmock.NewRows([]string{"redemption_id", "created_at"}).AddRow(id, now))
+		mock.ExpectCommit()
+
+		got, err := repo.InsertRedemption(red, now)
+		require.NoError(t, err)
+		assert.Equal(t, id, got.ID)
+		assert.Equal(t, "SAVE10", got.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Coupon used up", func(t *testing.T) {
+		red := models.CouponRedemption{CouponID: uuid.New(), UserID: uuid.New(), Discount: money.Of(20)}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(use).WithArgs(red.CouponID, now).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := repo.InsertRedemption(red, now)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("User limit reached", func(t *testing.T) {
+		red := models.CouponRedemption{CouponID: uuid.New(), UserID: uuid.New(), Discount: money.Of(20)}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(use).WithArgs(red.CouponID, now).
+			WillReturnRows(sqlmock.NewRows([]string{"code", "max_uses_per_user"}).AddRow("SAVE10", 1))
+		mock.ExpectQuery(count).WithArgs(red.CouponID, red.UserID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectRollback()
+
+		_, err := repo.InsertRedemption(red, now)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteRedemption(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPromotionsRepository(db)
+	id, couponID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("delete from coupon_redemptions").WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"coupon_id"}).AddRow(couponID))
+	mock.ExpectExec(regexp.QuoteMeta("update coupons set used_count = used_count - 1")).WithArgs(couponID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.DeleteRedemption(id))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,36 @@
+package promotions
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+type PromotionUC interface {
+	// GetCoupons returns every coupon, newest first
+	GetCoupons() ([]*models.Coupon, error)
+
+	// GetCoupon returns a coupon by id
+	GetCoupon(id uuid.UUID) (*models.Coupon, error)
+
+	// CreateCoupon creates a coupon, returns the saved coupon
+	CreateCoupon(c models.Coupon) (*models.Coupon, error)
+
+	// UpdateCoupon updates a coupon, returns the saved coupon
+	UpdateCoupon(id uuid.UUID, c models.Coupon) (*models.Coupon, error)
+
+	// DeleteCoupon deletes a coupon, returns an error on failure
+	DeleteCoupon(id uuid.UUID) error
+
+	// ApplyCoupon checks that a user can use a coupon on itemsPrice, returns the discount it gives
+	ApplyCoupon(code string, userID uuid.UUID, itemsPrice money.Money) (*models.CouponQuote, error)
+
+	// Redeem uses a coupon of a user on itemsPrice for an order about to be placed
+	Redeem(code string, userID uuid.UUID, itemsPrice money.Money) (*models.CouponRedemption, error)
+
+	// AttachOrder records the order placed with a redemption, returns an error on failure
+	AttachOrder(redemptionID, orderID uuid.UUID) error
+
+	// Release gives back a redemption whose order could not be placed, returns an error on failure
+	Release(redemptionID uuid.UUID) error
+}
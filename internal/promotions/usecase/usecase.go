@@ -0,0 +1,229 @@
+// Package usecase implements coupon codes.
+//
+// A coupon takes a percentage or a fixed amount off the items price of an order. It
+// can expire, be limited in uses overall and per user, and require a minimum items
+// price. Applying a coupon to a cart only checks it; redeeming it for an order
+// counts the use, which is given back if the order cannot be placed.
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/promotions"
+	"github.com/jofosuware/go/shopit/pkg/driver"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// PromotionsUC provides coupon use cases.
+type PromotionsUC struct {
+	repo promotions.Repo
+	now  func() time.Time
+}
+
+// NewPromotionsUC returns a new PromotionsUC.
+func NewPromotionsUC(repo promotions.Repo) *PromotionsUC {
+	return &PromotionsUC{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// GetCoupons returns every coupon, newest first.
+func (p *PromotionsUC) GetCoupons() ([]*models.Coupon, error) {
+	coupons, err := p.repo.FetchCoupons()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching coupons: %v", err)
+	}
+
+	return coupons, nil
+}
+
+// GetCoupon returns a coupon by id.
+func (p *PromotionsUC) GetCoupon(id uuid.UUID) (*models.Coupon, error) {
+	c, err := p.repo.FetchCouponById(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, promotions.ErrCouponNotFound
+		}
+		return nil, fmt.Errorf("error fetching coupon: %v", err)
+	}
+
+	return c, nil
+}
+
+// CreateCoupon creates a coupon. Codes are stored upper case and must be unique
+// regardless of case.
+func (p *PromotionsUC) CreateCoupon(c models.Coupon) (*models.Coupon, error) {
+	c.Code = strings.ToUpper(c.Code)
+	if err := p.checkCode(c.Code, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	created, err := p.repo.InsertCoupon(c)
+	if err != nil {
+		if driver.IsUniqueViolation(err, couponCodeIndex) {
+			return nil, promotions.ErrCouponExists
+		}
+		return nil, fmt.Errorf("error saving coupon: %v", err)
+	}
+
+	return created, nil
+}
+
+// UpdateCoupon replaces the settings of coupon id. Its usage count is kept, so
+// lowering MaxUses below it uses the coupon up.
+func (p *PromotionsUC) UpdateCoupon(id uuid.UUID, c models.Coupon) (*models.Coupon, error) {
+	c.ID = id
+	c.Code = strings.ToUpper(c.Code)
+	if err := p.checkCode(c.Code, id); err != nil {
+		return nil, err
+	}
+
+	updated, err := p.repo.UpdateCoupon(c)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, promotions.ErrCouponNotFound
+		}
+		if driver.IsUniqueViolation(err, couponCodeIndex) {
+			return nil, promotions.ErrCouponExists
+		}
+		return nil, fmt.Errorf("error updating coupon: %v", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteCoupon deletes coupon id. Orders placed with it keep their discount.
+func (p *PromotionsUC) DeleteCoupon(id uuid.UUID) error {
+	if err := p.repo.DeleteCoupon(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return promotions.ErrCouponNotFound
+		}
+		return fmt.Errorf("error deleting coupon: %v", err)
+	}
+
+	return nil
+}
+
+// ApplyCoupon checks that userID can use the coupon with code on itemsPrice and
+// returns the discount it gives, without using it.
+func (p *PromotionsUC) ApplyCoupon(code string, userID uuid.UUID, itemsPrice money.Money) (*models.CouponQuote, error) {
+	c, err := p.usable(code, userID, itemsPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	discount := c.Discount(itemsPrice)
+
+	return &models.CouponQuote{
+		Code:            c.Code,
+		Type:            c.Type,
+		Value:           c.Value,
+		ItemsPrice:      itemsPrice,
+		Discount:        discount,
+		DiscountedPrice: itemsPrice.Sub(discount),
+	}, nil
+}
+
+// Redeem uses the coupon with code for an order of userID with itemsPrice. Attach
+// the order once it is placed, or release the redemption if it cannot be.
+func (p *PromotionsUC) Redeem(code string, userID uuid.UUID, itemsPrice money.Money) (*models.CouponRedemption, error) {
+	c, err := p.usable(code, userID, itemsPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	red, err := p.repo.InsertRedemption(models.CouponRedemption{
+		CouponID: c.ID,
+		UserID:   userID,
+		Discount: c.Discount(itemsPrice),
+	}, p.now())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// used up, switched off or expired since it was checked
+			return nil, promotions.ErrCouponUsedUp
+		}
+		return nil, fmt.Errorf("error redeeming coupon: %v", err)
+	}
+
+	return red, nil
+}
+
+// AttachOrder records the order placed with a redemption.
+func (p *PromotionsUC) AttachOrder(redemptionID, orderID uuid.UUID) error {
+	if err := p.repo.UpdateRedemptionOrder(redemptionID, orderID); err != nil {
+		return fmt.Errorf("error saving coupon order: %v", err)
+	}
+
+	return nil
+}
+
+// Release gives back the use of a redemption whose order could not be placed.
+func (p *PromotionsUC) Release(redemptionID uuid.UUID) error {
+	if err := p.repo.DeleteRedemption(redemptionID); err != nil {
+		return fmt.Errorf("error releasing coupon: %v", err)
+	}
+
+	return nil
+}
+
+// usable returns the coupon with code when userID can use it on itemsPrice.
+func (p *PromotionsUC) usable(code string, userID uuid.UUID, itemsPrice money.Money) (*models.Coupon, error) {
+	c, err := p.repo.FetchCouponByCode(code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, promotions.ErrCouponNotFound
+		}
+		return nil, fmt.Errorf("error fetching coupon: %v", err)
+	}
+
+	switch {
+	case !c.Active:
+		return nil, promotions.ErrCouponInactive
+	case c.ExpiresAt != nil && !p.now().Before(*c.ExpiresAt):
+		return nil, promotions.ErrCouponExpired
+	case c.MaxUses > 0 && c.UsedCount >= c.MaxUses:
+		return nil, promotions.ErrCouponUsedUp
+	case itemsPrice.Currency != "" && itemsPrice.Currency != money.DefaultCurrency:
+		// coupon amounts are in the shop currency
+		return nil, promotions.ErrCouponCurrency
+	case itemsPrice.Less(c.MinOrderValue):
+		return nil, fmt.Errorf("%w (%s)", promotions.ErrBelowMinimum, c.MinOrderValue)
+	}
+
+	if c.MaxUsesPerUser > 0 {
+		used, err := p.repo.CountRedemptions(c.ID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("error counting coupon redemptions: %v", err)
+		}
+		if used >= c.MaxUsesPerUser {
+			return nil, promotions.ErrCouponUsedUp
+		}
+	}
+
+	return c, nil
+}
+
+// couponCodeIndex is the unique index on the codes of coupons, which a coupon saved
+// with the code of another one created since checkCode breaks.
+const couponCodeIndex = "coupons_code_idx"
+
+// checkCode fails with promotions.ErrCouponExists when a coupon other than exclude
+// already has code.
+func (p *PromotionsUC) checkCode(code string, exclude uuid.UUID) error {
+	exists, err := p.repo.CouponCodeExists(code, exclude)
+	if err != nil {
+		return fmt.Errorf("error checking coupon code: %v", err)
+	}
+	if exists {
+		return promotions.ErrCouponExists
+	}
+
+	return nil
+}
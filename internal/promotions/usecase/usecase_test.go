@@ -0,0 +1,151 @@
+package usecase_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/promotions"
+	"github.com/jofosuware/go/shopit/internal/promotions/mocks"
+	"github.com/jofosuware/go/shopit/internal/promotions/usecase"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscount(t *testing.T) {
+	percent := models.Coupon{Type: models.CouponPercentage, Value: 15}
+	fixed := models.Coupon{Type: models.CouponFixed, Value: 50}
+
+	assert.Equal(t, money.Of(30), percent.Discount(money.Of(200)))
+	assert.Equal(t, money.Of(299), percent.Discount(money.Of(1999)), "a percentage is rounded down to a cent")
+	assert.Equal(t, money.Of(50), fixed.Discount(money.Of(200)))
+	assert.Equal(t, money.Of(40), fixed.Discount(money.Of(40)), "a fixed discount does not exceed the items price")
+	assert.Equal(t, money.Of(0), fixed.Discount(money.Of(0)))
+}
+
+func TestCreateCoupon(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	p := usecase.NewPromotionsUC(repo)
+
+	t.Run("Code is stored upper case", func(t *testing.T) {
+		repo.On("CouponCodeExists", "SAVE10", uuid.Nil).Return(false, nil).Once()
+		repo.On("InsertCoupon", mock.MatchedBy(func(c models.Coupon) bool { return c.Code == "SAVE10" })).
+			Return(&models.Coupon{ID: uuid.New(), Code: "SAVE10"}, nil).Once()
+
+		c, err := p.CreateCoupon(models.Coupon{Code: "save10", Type: models.CouponPercentage, Value: 10})
+		require.NoError(t, err)
+		assert.Equal(t, "SAVE10", c.Code)
+	})
+
+	t.Run("Code taken", func(t *testing.T) {
+		repo.On("CouponCodeExists", "SAVE10", uuid.Nil).Return(true, nil).Once()
+
+		_, err := p.CreateCoupon(models.Coupon{Code: "Save10"})
+		assert.ErrorIs(t, err, promotions.ErrCouponExists)
+	})
+
+	t.Run("Code taken since it was checked", func(t *testing.T) {
+		repo.On("CouponCodeExists", "SAVE10", uuid.Nil).Return(false, nil).Once()
+		repo.On("InsertCoupon", mock.Anything).
+			Return(nil, &pgconn.PgError{Code: "23505", ConstraintName: "coupons_code_idx"}).Once()
+
+		_, err := p.CreateCoupon(models.Coupon{Code: "save10"})
+		assert.ErrorIs(t, err, promotions.ErrCouponExists)
+	})
+}
+
+func TestApplyCoupon(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	p := usecase.NewPromotionsUC(repo)
+
+	userID := uuid.New()
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	coupon := func(mod func(c *models.Coupon)) *models.Coupon {
+		c := &models.Coupon{ID: uuid.New(), Code: "SAVE10", Type: models.CouponPercentage, Value: 10,
+			MinOrderValue: money.Of(100), ExpiresAt: &future, Active: true}
+		mod(c)
+		return c
+	}
+
+	t.Run("Discount is quoted", func(t *testing.T) {
+		repo.On("FetchCouponByCode", "save10").Return(coupon(func(c *models.Coupon) {}), nil).Once()
+
+		q, err := p.ApplyCoupon("save10", userID, money.Of(250))
+		require.NoError(t, err)
+		assert.Equal(t, money.Of(25), q.Discount)
+		assert.Equal(t, money.Of(225), q.DiscountedPrice)
+	})
+
+	t.Run("Unknown code", func(t *testing.T) {
+		repo.On("FetchCouponByCode", "nope").Return(nil, sql.ErrNoRows).Once()
+
+		_, err := p.ApplyCoupon("nope", userID, money.Of(250))
+		assert.ErrorIs(t, err, promotions.ErrCouponNotFound)
+	})
+
+	tests := []struct {
+		name string
+		mod  func(c *models.Coupon)
+		want error
+	}{
+		{"Inactive", func(c *models.Coupon) { c.Active = false }, promotions.ErrCouponInactive},
+		{"Expired", func(c *models.Coupon) { c.ExpiresAt = &past }, promotions.ErrCouponExpired},
+		{"Used up", func(c *models.Coupon) { c.MaxUses, c.UsedCount = 5, 5 }, promotions.ErrCouponUsedUp},
+		{"Below minimum", func(c *models.Coupon) { c.MinOrderValue = money.Of(500) }, promotions.ErrBelowMinimum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.On("FetchCouponByCode", "save10").Return(coupon(tt.mod), nil).Once()
+
+			_, err := p.ApplyCoupon("save10", userID, money.Of(250))
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	t.Run("Used up by the user", func(t *testing.T) {
+		c := coupon(func(c *models.Coupon) { c.MaxUsesPerUser = 1 })
+		repo.On("FetchCouponByCode", "save10").Return(c, nil).Once()
+		repo.On("CountRedemptions", c.ID, userID).Return(1, nil).Once()
+
+		_, err := p.ApplyCoupon("save10", userID, money.Of(250))
+		assert.ErrorIs(t, err, promotions.ErrCouponUsedUp)
+	})
+
+	t.Run("Order in another currency", func(t *testing.T) {
+		repo.On("FetchCouponByCode", "save10").Return(coupon(func(c *models.Coupon) {}), nil).Once()
+
+		_, err := p.ApplyCoupon("save10", userID, money.New(25000, "EUR"))
+		assert.ErrorIs(t, err, promotions.ErrCouponCurrency)
+	})
+}
+
+func TestRedeem(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	p := usecase.NewPromotionsUC(repo)
+
+	userID := uuid.New()
+	c := &models.Coupon{ID: uuid.New(), Code: "TAKE50", Type: models.CouponFixed, Value: 50, Active: true}
+
+	t.Run("Coupon is redeemed", func(t *testing.T) {
+		repo.On("FetchCouponByCode", "take50").Return(c, nil).Once()
+		repo.On("InsertRedemption", models.CouponRedemption{CouponID: c.ID, UserID: userID, Discount: money.Of(50)}, mock.Anything).
+			Return(&models.CouponRedemption{ID: uuid.New(), Code: "TAKE50", Discount: money.Of(50)}, nil).Once()
+
+		red, err := p.Redeem("take50", userID, money.Of(300))
+		require.NoError(t, err)
+		assert.Equal(t, money.Of(50), red.Discount)
+	})
+
+	t.Run("Coupon used up concurrently", func(t *testing.T) {
+		repo.On("FetchCouponByCode", "take50").Return(c, nil).Once()
+		repo.On("InsertRedemption", mock.Anything, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := p.Redeem("take50", userID, money.Of(300))
+		assert.ErrorIs(t, err, promotions.ErrCouponUsedUp)
+	})
+}
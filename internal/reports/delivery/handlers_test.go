@@ -0,0 +1,101 @@
+package delivery_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/reports"
+	"github.com/jofosuware/go/shopit/internal/reports/delivery"
+	"github.com/jofosuware/go/shopit/internal/reports/mocks"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/xlsx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSalesReport(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	reportsUC := mocks.NewReportsUC(t)
+	h := delivery.NewReportHandlers(logger, reportsUC)
+
+	call := func(query string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.SalesReport(rr, httptest.NewRequest(http.MethodGet, "/admin/reports/sales?"+query, nil))
+		return rr
+	}
+	from, to := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Monthly sales as CSV", func(t *testing.T) {
+		reportsUC.On("SalesReport", mock.Anything, from, to, models.GroupByMonth, models.ReportCSV).
+			Run(func(args mock.Arguments) {
+				_, _ = args.Get(0).(io.Writer).Write([]byte("period,currency\n2026-09-01,USD\n"))
+			}).Return(1, nil).Once()
+
+		rr := call("from=2026-09-01&to=2026-09-30&group_by=month")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "sales-2026-09-01-2026-09-30.csv")
+		assert.Equal(t, "period,currency\n2026-09-01,USD\n", rr.Body.String())
+	})
+
+	t.Run("Daily sales as XLSX", func(t *testing.T) {
+		reportsUC.On("SalesReport", mock.Anything, from, to, models.GroupByDay, models.ReportXLSX).Return(30, nil).Once()
+
+		rr := call("from=2026-09-01&to=2026-09-30&format=xlsx")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, xlsx.ContentType, rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "sales-2026-09-01-2026-09-30.xlsx")
+	})
+
+	t.Run("Invalid group", func(t *testing.T) {
+		reportsUC.On("SalesReport", mock.Anything, from, to, "year", models.ReportCSV).
+			Return(0, reports.ErrInvalidGroupBy).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call("from=2026-09-01&to=2026-09-30&group_by=year")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Empty(t, rr.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		reportsUC.On("SalesReport", mock.Anything, from, to, models.GroupByDay, models.ReportCSV).
+			Return(0, errors.New("db down")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusInternalServerError, call("from=2026-09-01&to=2026-09-30").Code)
+	})
+
+	t.Run("Failure after the file started is only logged", func(t *testing.T) {
+		reportsUC.On("SalesReport", mock.Anything, from, to, models.GroupByDay, models.ReportCSV).
+			Run(func(args mock.Arguments) {
+				_, _ = args.Get(0).(io.Writer).Write([]byte("period,currency\n"))
+			}).Return(0, errors.New("db down")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call("from=2026-09-01&to=2026-09-30")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "period,currency\n", rr.Body.String())
+	})
+
+	for name, tc := range map[string]struct {
+		query string
+		args  []interface{}
+	}{
+		"Missing from":    {"to=2026-09-30", []interface{}{mock.Anything}},
+		"Invalid date":    {"from=09/01/2026", []interface{}{mock.Anything, mock.Anything, mock.Anything}},
+		"Reversed period": {"from=2026-10-01&to=2026-09-01", []interface{}{mock.Anything, mock.Anything, mock.Anything}},
+		"Invalid format":  {"from=2026-09-01&format=pdf", []interface{}{mock.Anything, mock.Anything}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			logger.On("Errorf", tc.args...).Once()
+
+			assert.Equal(t, http.StatusBadRequest, call(tc.query).Code)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/audit"
+	"github.com/jofosuware/go/shopit/pkg/loadshed"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// ReportRouter returns a chi.Router with admin-only report routes.
+//
+//   - GET /sales → Sales by day, week or month as a CSV or XLSX file
+func (h *ReportHandlers) ReportRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+	mux.Use(utils.IsAdmin)
+
+	mux.With(loadshed.LowPriority, audit.Export(models.ExportSales)).Get("/sales", h.SalesReport)
+
+	return mux
+}
@@ -0,0 +1,10 @@
+package reports
+
+import "errors"
+
+var (
+	// ErrInvalidGroupBy is returned when reporting sales by a period that is not supported.
+	ErrInvalidGroupBy = errors.New("group_by must be day, week or month")
+	// ErrInvalidFormat is returned when reporting in a file format that is not supported.
+	ErrInvalidFormat = errors.New("format must be csv or xlsx")
+)
@@ -0,0 +1,47 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+// StreamSales provides a mock function with given fields: from, to, groupBy, fn
+func (_m *Repo) StreamSales(from time.Time, to time.Time, groupBy string, fn func(*models.SalesPeriod) error) error {
+	ret := _m.Called(from, to, groupBy, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamSales")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(time.Time, time.Time, string, func(*models.SalesPeriod) error) error); ok {
+		r0 = rf(from, to, groupBy, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
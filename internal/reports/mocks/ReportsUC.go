@@ -0,0 +1,58 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ReportsUC is an autogenerated mock type for the ReportsUC type
+type ReportsUC struct {
+	mock.Mock
+}
+
+// SalesReport provides a mock function with given fields: w, from, to, groupBy, format
+func (_m *ReportsUC) SalesReport(w io.Writer, from time.Time, to time.Time, groupBy string, format string) (int, error) {
+	ret := _m.Called(w, from, to, groupBy, format)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SalesReport")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(io.Writer, time.Time, time.Time, string, string) (int, error)); ok {
+		return rf(w, from, to, groupBy, format)
+	}
+	if rf, ok := ret.Get(0).(func(io.Writer, time.Time, time.Time, string, string) int); ok {
+		r0 = rf(w, from, to, groupBy, format)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(io.Writer, time.Time, time.Time, string, string) error); ok {
+		r1 = rf(w, from, to, groupBy, format)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReportsUC creates a new instance of ReportsUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReportsUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReportsUC {
+	mock := &ReportsUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
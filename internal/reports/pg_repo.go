@@ -0,0 +1,12 @@
+package reports
+
+import (
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// StreamSales calls fn with the sales of every period of groupBy and currency in [from, to), oldest first
+	StreamSales(from, to time.Time, groupBy string, fn func(p *models.SalesPeriod) error) error
+}
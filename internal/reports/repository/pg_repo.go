@@ -0,0 +1,79 @@
+// Package repository aggregates the sales reports of the shop from its orders.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// ReportsRepository handles sales report database operations.
+type ReportsRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewReportsRepository returns a new ReportsRepository.
+func NewReportsRepository(db *sql.DB) *ReportsRepository {
+	return &ReportsRepository{
+		DB: db,
+	}
+}
+
+// StreamSales calls fn with the sales of every period of groupBy (day, week or month,
+// in UTC) and currency in [from, to), oldest first, aggregated by the database. A
+// cancelled order is a sale only when it was paid and is pending refund; it is then
+// also a refund of the period it was cancelled in. Periods without sales or refunds
+// are left out. It stops at the first error of fn and returns it.
+func (r *ReportsRepository) StreamSales(from, to time.Time, groupBy string, fn func(p *models.SalesPeriod) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// the refund_pending status is written out, for the payments to be looked up by
+	// payments_refund_pending_idx
+	query := `with sales as (
+			select date_trunc($3, o.created_at at time zone 'UTC') as period, o.currency,
+				count(*) as orders, sum(o.total_price) as revenue, sum(o.tax_price) as tax
+			from orders o
+			where o.created_at >= $1 and o.created_at < $2
+				and (o.order_status <> $4
+					or exists (select 1 from payments p where p.order_id = o.order_id and p.status = 'refund_pending'))
+			group by 1, 2
+		), refunds as (
+			select date_trunc($3, c.created_at at time zone 'UTC') as period, o.currency,
+				count(*) as refunds, sum(o.total_price) as refunded
+			from order_cancellations c
+			join orders o on o.order_id = c.order_id
+			where c.created_at >= $1 and c.created_at < $2
+				and exists (select 1 from payments p where p.order_id = o.order_id and p.status = 'refund_pending')
+			group by 1, 2
+		)
+		select coalesce(s.period, f.period), coalesce(s.currency, f.currency), coalesce(s.orders, 0),
+			coalesce(s.revenue, 0), coalesce(s.tax, 0), coalesce(f.refunds, 0), coalesce(f.refunded, 0)
+		from sales s
+		full join refunds f on f.period = s.period and f.currency = s.currency
+		order by 1, 2`
+
+	rows, err := r.DB.QueryContext(ctx, query, from, to, groupBy, models.OrderCancelled)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.SalesPeriod
+		if err := rows.Scan(&p.Period, &p.Currency, &p.Orders, &p.Revenue, &p.Tax, &p.Refunds, &p.Refunded); err != nil {
+			return err
+		}
+		p.Period = p.Period.UTC()
+		p.UseCurrency(p.Currency)
+
+		if err := fn(&p); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,60 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/reports/repository"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamSales(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReportsRepository(db)
+
+	from, to := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	week := time.Date(2026, 9, 7, 0, 0, 0, 0, time.UTC)
+	columns := []string{"period", "currency", "orders", "revenue", "tax", "refunds", "refunded"}
+	query := `with sales as \(.* from orders o .* from order_cancellations c .* full join refunds f .* order by 1, 2`
+
+	t.Run("Every period streamed", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(week, "EUR", 3, 4500, 450, 0, 0).
+			AddRow(week, "USD", 10, 104990, 9990, 1, 1300)
+		mock.ExpectQuery(query).
+			WithArgs(from, to, models.GroupByWeek, models.OrderCancelled).
+			WillReturnRows(rows)
+
+		var periods []models.SalesPeriod
+		err := repo.StreamSales(from, to, models.GroupByWeek, func(p *models.SalesPeriod) error {
+			periods = append(periods, *p)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, periods, 2)
+		assert.Equal(t, week, periods[0].Period)
+		assert.Equal(t, money.New(4500, "EUR"), periods[0].Revenue)
+		assert.Equal(t, 10, periods[1].Orders)
+		assert.Equal(t, money.New(9990, "USD"), periods[1].Tax)
+		assert.Equal(t, 1, periods[1].Refunds)
+		assert.Equal(t, money.New(1300, "USD"), periods[1].Refunded)
+	})
+
+	t.Run("Callback error stops the stream", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).AddRow(week, "EUR", 3, 4500, 450, 0, 0)
+		mock.ExpectQuery(query).WillReturnRows(rows)
+
+		err := repo.StreamSales(from, to, models.GroupByWeek, func(p *models.SalesPeriod) error { return errors.New("write error") })
+		assert.EqualError(t, err, "write error")
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
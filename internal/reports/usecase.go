@@ -0,0 +1,11 @@
+package reports
+
+import (
+	"io"
+	"time"
+)
+
+type ReportsUC interface {
+	// SalesReport writes the sales of [from, to) by groupBy in format and returns the rows written
+	SalesReport(w io.Writer, from, to time.Time, groupBy, format string) (int, error)
+}
@@ -0,0 +1,135 @@
+// Package usecase writes the sales reports admins download, as CSV or XLSX files.
+package usecase
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/reports"
+	"github.com/jofosuware/go/shopit/pkg/xlsx"
+)
+
+// salesColumns is the header of the sales report.
+var salesColumns = []any{"period", "currency", "orders", "revenue", "tax", "refunds", "refunded", "net_revenue"}
+
+// ReportsUC provides sales report use cases.
+type ReportsUC struct {
+	repo reports.Repo
+}
+
+// NewReportsUC returns a new ReportsUC.
+func NewReportsUC(repo reports.Repo) *ReportsUC {
+	return &ReportsUC{
+		repo: repo,
+	}
+}
+
+// SalesReport writes the sales of [from, to) as format, a line per period of groupBy
+// and currency, oldest first, and returns the lines written besides the header.
+// Amounts are in major units of their currency; they are never converted, so a shop
+// selling in several currencies has a line for each in a period.
+//
+// The range is aggregated a window of about a month at a time, each window ending at
+// the start of a period, so that long ranges are streamed in bounded queries and no
+// period is split between two. It returns reports.ErrInvalidGroupBy or
+// reports.ErrInvalidFormat before writing anything.
+func (u *ReportsUC) SalesReport(w io.Writer, from, to time.Time, groupBy, format string) (int, error) {
+	if groupBy != models.GroupByDay && groupBy != models.GroupByWeek && groupBy != models.GroupByMonth {
+		return 0, reports.ErrInvalidGroupBy
+	}
+
+	var t table
+	switch format {
+	case models.ReportCSV:
+		t = csvTable{csv.NewWriter(w)}
+	case models.ReportXLSX:
+		t = xlsx.NewWriter(w, "Sales")
+	default:
+		return 0, reports.ErrInvalidFormat
+	}
+
+	if err := t.Write(salesColumns); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	for start := from.UTC(); start.Before(to); {
+		end := windowEnd(start, groupBy)
+		if end.After(to) {
+			end = to
+		}
+
+		err := u.repo.StreamSales(start, end, groupBy, func(p *models.SalesPeriod) error {
+			rows++
+			return t.Write([]any{
+				p.Period.Format("2006-01-02"),
+				p.Currency,
+				p.Orders,
+				xlsx.Number(p.Revenue.Decimal()),
+				xlsx.Number(p.Tax.Decimal()),
+				p.Refunds,
+				xlsx.Number(p.Refunded.Decimal()),
+				xlsx.Number(p.Revenue.Sub(p.Refunded).Decimal()),
+			})
+		})
+		if err != nil {
+			return rows, fmt.Errorf("error reporting sales: %v", err)
+		}
+		if err := t.Flush(); err != nil {
+			return rows, err
+		}
+
+		start = end
+	}
+
+	return rows, t.Close()
+}
+
+// windowEnd returns the end of the window of periods of groupBy starting at t, in
+// UTC: a month of days, five weeks or a month.
+func windowEnd(t time.Time, groupBy string) time.Time {
+	y, m, d := t.Date()
+
+	switch groupBy {
+	case models.GroupByDay:
+		return time.Date(y, m, d+31, 0, 0, 0, 0, time.UTC)
+	case models.GroupByWeek:
+		monday := d - (int(t.Weekday())+6)%7
+		return time.Date(y, m, monday+5*7, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// table is a report file, written a row at a time.
+type table interface {
+	Write(row []any) error
+	Flush() error
+	Close() error
+}
+
+// csvTable writes a report as CSV.
+type csvTable struct {
+	w *csv.Writer
+}
+
+func (c csvTable) Write(row []any) error {
+	record := make([]string, len(row))
+	for i, v := range row {
+		record[i] = fmt.Sprint(v)
+	}
+
+	return c.w.Write(record)
+}
+
+func (c csvTable) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c csvTable) Close() error {
+	return c.Flush()
+}
@@ -0,0 +1,119 @@
+package usecase_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/reports"
+	"github.com/jofosuware/go/shopit/internal/reports/mocks"
+	"github.com/jofosuware/go/shopit/internal/reports/usecase"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestSalesReport(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewReportsUC(repo)
+
+	period := models.SalesPeriod{
+		Period:   date(2026, 9, 1),
+		Currency: "USD",
+		Orders:   10,
+		Revenue:  money.New(104990, "USD"),
+		Tax:      money.New(9990, "USD"),
+		Refunds:  1,
+		Refunded: money.New(1300, "USD"),
+	}
+	stream := func(periods ...models.SalesPeriod) func(args mock.Arguments) {
+		return func(args mock.Arguments) {
+			fn := args.Get(3).(func(p *models.SalesPeriod) error)
+			for _, p := range periods {
+				require.NoError(t, fn(&p))
+			}
+		}
+	}
+
+	t.Run("Sales are reported as CSV", func(t *testing.T) {
+		from, to := date(2026, 9, 1), date(2026, 10, 1)
+		repo.On("StreamSales", from, to, models.GroupByMonth, mock.Anything).Run(stream(period)).Return(nil).Once()
+
+		var buf bytes.Buffer
+		rows, err := u.SalesReport(&buf, from, to, models.GroupByMonth, models.ReportCSV)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, rows)
+		assert.Equal(t, "period,currency,orders,revenue,tax,refunds,refunded,net_revenue\n"+
+			"2026-09-01,USD,10,1049.90,99.90,1,13.00,1036.90\n", buf.String())
+	})
+
+	t.Run("Long ranges are queried a window at a time", func(t *testing.T) {
+		// from a Wednesday; windows end on Mondays
+		from, to := date(2026, 9, 2), date(2026, 11, 20)
+		repo.On("StreamSales", from, date(2026, 10, 5), models.GroupByWeek, mock.Anything).Run(stream(period)).Return(nil).Once()
+		repo.On("StreamSales", date(2026, 10, 5), date(2026, 11, 9), models.GroupByWeek, mock.Anything).Return(nil).Once()
+		repo.On("StreamSales", date(2026, 11, 9), to, models.GroupByWeek, mock.Anything).Run(stream(period, period)).Return(nil).Once()
+
+		rows, err := u.SalesReport(&bytes.Buffer{}, from, to, models.GroupByWeek, models.ReportCSV)
+		require.NoError(t, err)
+		assert.Equal(t, 3, rows)
+	})
+
+	t.Run("Days and months are windowed too", func(t *testing.T) {
+		repo.On("StreamSales", date(2026, 1, 15), date(2026, 2, 15), models.GroupByDay, mock.Anything).Return(nil).Once()
+		repo.On("StreamSales", date(2026, 2, 15), date(2026, 3, 1), models.GroupByDay, mock.Anything).Return(nil).Once()
+		_, err := u.SalesReport(&bytes.Buffer{}, date(2026, 1, 15), date(2026, 3, 1), models.GroupByDay, models.ReportCSV)
+		require.NoError(t, err)
+
+		repo.On("StreamSales", date(2026, 1, 15), date(2026, 2, 1), models.GroupByMonth, mock.Anything).Return(nil).Once()
+		repo.On("StreamSales", date(2026, 2, 1), date(2026, 2, 10), models.GroupByMonth, mock.Anything).Return(nil).Once()
+		_, err = u.SalesReport(&bytes.Buffer{}, date(2026, 1, 15), date(2026, 2, 10), models.GroupByMonth, models.ReportCSV)
+		require.NoError(t, err)
+	})
+
+	t.Run("Sales are reported as XLSX", func(t *testing.T) {
+		from, to := date(2026, 9, 1), date(2026, 9, 2)
+		repo.On("StreamSales", from, to, models.GroupByDay, mock.Anything).Run(stream(period)).Return(nil).Once()
+
+		var buf bytes.Buffer
+		rows, err := u.SalesReport(&buf, from, to, models.GroupByDay, models.ReportXLSX)
+		require.NoError(t, err)
+		assert.Equal(t, 1, rows)
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		assert.Contains(t, names, "xl/worksheets/sheet1.xml")
+	})
+
+	t.Run("Invalid group and format", func(t *testing.T) {
+		from, to := date(2026, 9, 1), date(2026, 10, 1)
+
+		var buf bytes.Buffer
+		_, err := u.SalesReport(&buf, from, to, "year", models.ReportCSV)
+		assert.ErrorIs(t, err, reports.ErrInvalidGroupBy)
+		_, err = u.SalesReport(&buf, from, to, models.GroupByDay, "pdf")
+		assert.ErrorIs(t, err, reports.ErrInvalidFormat)
+		assert.Zero(t, buf.Len())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		from, to := date(2026, 9, 1), date(2026, 10, 1)
+		repo.On("StreamSales", from, to, models.GroupByMonth, mock.Anything).Return(errors.New("db error")).Once()
+
+		_, err := u.SalesReport(&bytes.Buffer{}, from, to, models.GroupByMonth, models.ReportCSV)
+		assert.ErrorContains(t, err, "db error")
+	})
+}
@@ -0,0 +1,79 @@
+// Package delivery provides the HTTP handler of the fake data generator of staging
+// and demo environments.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/seed"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// SeedHandlers provides HTTP handler methods for the fake data generator.
+type SeedHandlers struct {
+	logger logger.Logger
+	seedUC seed.SeedUC
+}
+
+// NewSeedHandlers returns a new SeedHandlers.
+func NewSeedHandlers(logger logger.Logger, seedUC seed.SeedUC) *SeedHandlers {
+	return &SeedHandlers{
+		logger: logger,
+		seedUC: seedUC,
+	}
+}
+
+// Generate creates fake users, products with placeholder images under the categories
+// of their names, reviews and orders at the volumes asked for, the products listed by
+// the admin (admin). The generated
+// users sign in with the password of the report.
+// Endpoint: POST /api/v1/admin/seed
+// Expects JSON: {"users": <n>, "products": <n>, "imagesPerProduct": <n>, "reviewsPerProduct": <n>,
+// "ordersPerUser": <n>, "seed": <n>}, every field optional.
+func (h *SeedHandlers) Generate(w http.ResponseWriter, r *http.Request) {
+	var req seedRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	admin := r.Context().Value(utils.UserContextKey).(*models.User)
+
+	report, err := h.seedUC.Generate(models.SeedOptions{
+		Users:             req.Users,
+		Products:          req.Products,
+		ImagesPerProduct:  req.ImagesPerProduct,
+		ReviewsPerProduct: req.ReviewsPerProduct,
+		OrdersPerUser:     req.OrdersPerUser,
+		Seed:              req.Seed,
+		Owner:             admin.ID,
+	})
+	if err != nil {
+		if errors.Is(err, seed.ErrNoOwner) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error generating seed data: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error generating seed data: %w", err))
+		return
+	}
+
+	h.logger.Infof("seed data generated by %s: %d users, %d categories, %d products, %d images, %d reviews, %d orders",
+		admin.ID, report.Users, report.Categories, report.Products, report.Images, report.Reviews, report.Orders)
+
+	jr := struct {
+		Success bool               `json:"success"`
+		Report  *models.SeedReport `json:"report"`
+	}{
+		Success: true,
+		Report:  report,
+	}
+
+	if err := utils.WriteJSON(w, http.StatusCreated, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
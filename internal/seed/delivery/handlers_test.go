@@ -0,0 +1,70 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/seed/delivery"
+	"github.com/jofosuware/go/shopit/internal/seed/mocks"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	seedUC := mocks.NewSeedUC(t)
+	h := delivery.NewSeedHandlers(logger, seedUC)
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
+	call := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/seed", bytes.NewBufferString(body))
+		req = req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, admin))
+		rr := httptest.NewRecorder()
+		h.Generate(rr, req)
+		return rr
+	}
+
+	t.Run("Data is generated", func(t *testing.T) {
+		seedUC.On("Generate", models.SeedOptions{Users: 10, Products: 20, ImagesPerProduct: 2, Seed: 7, Owner: admin.ID}).
+			Return(&models.SeedReport{Users: 10, Products: 20, Images: 40, Password: "shopit-seed"}, nil).Once()
+		logger.On("Infof", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Once()
+
+		rr := call(`{"users": 10, "products": 20, "imagesPerProduct": 2, "seed": 7}`)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		var res struct {
+			Report models.SeedReport `json:"report"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, 40, res.Report.Images)
+		assert.Equal(t, "shopit-seed", res.Report.Password)
+	})
+
+	t.Run("Volume over its cap", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := call(`{"users": 10, "ordersPerUser": 100}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Generator fails", func(t *testing.T) {
+		seedUC.On("Generate", models.SeedOptions{Users: 1, Owner: admin.ID}).
+			Return(&models.SeedReport{}, errors.New("db down")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Once()
+
+		rr := call(`{"users": 1}`)
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
@@ -0,0 +1,11 @@
+package delivery
+
+// seedRequest is the body of Generate. Its caps are the models.MaxSeed constants.
+type seedRequest struct {
+	Users             int   `json:"users" validate:"min=0,max=10000"`
+	Products          int   `json:"products" validate:"min=0,max=10000"`
+	ImagesPerProduct  int   `json:"imagesPerProduct" validate:"min=0,max=5"`
+	ReviewsPerProduct int   `json:"reviewsPerProduct" validate:"min=0,max=50"`
+	OrdersPerUser     int   `json:"ordersPerUser" validate:"min=0,max=20"`
+	Seed              int64 `json:"seed"`
+}
@@ -0,0 +1,23 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// SeedRouter returns a chi.Router with the admin-only fake data generator, mounted
+// only when seed.Enabled is set.
+//
+//   - POST / → Generate fake users, products, reviews and orders
+func (h *SeedHandlers) SeedRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+	mux.Use(utils.IsAdmin)
+
+	mux.Post("/", h.Generate)
+
+	return mux
+}
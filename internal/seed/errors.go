@@ -0,0 +1,6 @@
+package seed
+
+import "errors"
+
+// ErrNoOwner is returned when products are generated without users or an owner to list them.
+var ErrNoOwner = errors.New("generated products need an owner: generate users or give an owner")
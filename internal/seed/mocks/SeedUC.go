@@ -0,0 +1,57 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SeedUC is an autogenerated mock type for the SeedUC type
+type SeedUC struct {
+	mock.Mock
+}
+
+// Generate provides a mock function with given fields: opts
+func (_m *SeedUC) Generate(opts models.SeedOptions) (*models.SeedReport, error) {
+	ret := _m.Called(opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Generate")
+	}
+
+	var r0 *models.SeedReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.SeedOptions) (*models.SeedReport, error)); ok {
+		return rf(opts)
+	}
+	if rf, ok := ret.Get(0).(func(models.SeedOptions) *models.SeedReport); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SeedReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.SeedOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSeedUC creates a new instance of SeedUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSeedUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SeedUC {
+	mock := &SeedUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
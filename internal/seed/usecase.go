@@ -0,0 +1,8 @@
+package seed
+
+import "github.com/jofosuware/go/shopit/internal/models"
+
+type SeedUC interface {
+	// Generate creates fake users, products with images, reviews and orders, returns what it created
+	Generate(opts models.SeedOptions) (*models.SeedReport, error)
+}
@@ -0,0 +1,332 @@
+// Package usecase generates fake data for staging and demo environments.
+//
+// A seed run creates users, products listed with placeholder images under the
+// categories of their names, reviews of the products by the users and orders of the
+// products placed by them, through the repositories of those domains. The data looks real but cannot reach anyone: emails
+// are on the reserved example.com domain, images are served by a placeholder service
+// and orders are paid with fake payment ids. Orders are inserted without going
+// through the outbox, so no confirmation email or webhook is sent for them.
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/auth"
+	"github.com/jofosuware/go/shopit/internal/categories"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/orders"
+	"github.com/jofosuware/go/shopit/internal/products"
+	"github.com/jofosuware/go/shopit/internal/seed"
+	"github.com/jofosuware/go/shopit/pkg/bcrypt"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// Password is the password every generated user signs in with.
+const Password = "shopit-seed"
+
+// ImageURL is the placeholder image of a product image, by its public id.
+const ImageURL = "https://picsum.photos/seed/%s/800/800"
+
+// seedFolder prefixes the public ids of generated images; nothing is stored under it.
+const seedFolder = "seed/"
+
+// history is how far back generated reviews and payments go.
+const history = 365 * 24 * time.Hour
+
+// SeedUC provides the fake data generator.
+type SeedUC struct {
+	users      auth.Repo
+	categories categories.Repo
+	products   products.Repo
+	orders     orders.Repo
+	bcrypt     bcrypt.Encryptor
+}
+
+// NewSeedUC returns a new SeedUC.
+func NewSeedUC(users auth.Repo, categories categories.Repo, products products.Repo, orders orders.Repo,
+	bcrypt bcrypt.Encryptor) *SeedUC {
+	return &SeedUC{
+		users:      users,
+		categories: categories,
+		products:   products,
+		orders:     orders,
+		bcrypt:     bcrypt,
+	}
+}
+
+// Generate creates the fake data opts asks for: users first, the first of them an
+// admin when opts.Admin is set, then products with their images and reviews, filed
+// under the categories of their names, which are created when missing, then the
+// orders of the users. It stops at the first
+// record it cannot save and returns what it created until then with the error. It
+// returns seed.ErrNoOwner for products without users or an owner to list them.
+func (s *SeedUC) Generate(opts models.SeedOptions) (*models.SeedReport, error) {
+	if opts.Products > 0 && opts.Users == 0 && opts.Owner == uuid.Nil {
+		return nil, seed.ErrNoOwner
+	}
+
+	f := gofakeit.New(opts.Seed)
+	report := &models.SeedReport{Password: Password}
+
+	hash, err := s.bcrypt.GenerateFromPassword([]byte(Password))
+	if err != nil {
+		return nil, fmt.Errorf("error hashing password: %w", err)
+	}
+
+	users := make([]*models.User, 0, opts.Users)
+	for i := 0; i < opts.Users; i++ {
+		user := fakeUser(f, string(hash))
+		if i == 0 && opts.Admin {
+			user.Role = models.RoleAdmin
+		}
+		u, err := s.users.InsertUser(user)
+		if err != nil {
+			return report, fmt.Errorf("error inserting user: %w", err)
+		}
+		if u.Role == models.RoleAdmin {
+			report.Admin = u.Email
+		}
+		users = append(users, u)
+		report.Users++
+	}
+
+	owner := opts.Owner
+	if owner == uuid.Nil && len(users) > 0 {
+		owner = users[0].ID
+	}
+
+	cats := map[string]uuid.UUID{}
+	if opts.Products > 0 {
+		existing, err := s.categories.FetchCategories()
+		if err != nil {
+			return report, fmt.Errorf("error fetching categories: %w", err)
+		}
+		for _, c := range existing {
+			cats[strings.ToLower(c.Name)] = c.CategoryId
+		}
+	}
+
+	prods := make([]models.Product, 0, opts.Products)
+	for i := 0; i < opts.Products; i++ {
+		reviews := fakeReviews(f, users, opts.ReviewsPerProduct)
+
+		p := fakeProduct(f, owner)
+		p.NumOfReviews = len(reviews)
+		p.Ratings = averageRating(reviews)
+
+		id, ok := cats[strings.ToLower(p.Category)]
+		if !ok {
+			c, err := s.categories.InsertCategory(&models.Category{Name: p.Category})
+			if err != nil {
+				return report, fmt.Errorf("error inserting category: %w", err)
+			}
+			id = c.CategoryId
+			cats[strings.ToLower(p.Category)] = id
+			report.Categories++
+		}
+		p.CategoryId = uuid.NullUUID{UUID: id, Valid: true}
+
+		prod, err := s.products.InsertProduct(&p)
+		if err != nil {
+			return report, fmt.Errorf("error inserting product: %w", err)
+		}
+		report.Products++
+
+		images := make([]models.Images, opts.ImagesPerProduct)
+		for j := range images {
+			publicID := seedFolder + uuid.NewString()
+			images[j] = models.Images{
+				PublicId:  publicID,
+				Url:       fmt.Sprintf(ImageURL, strings.TrimPrefix(publicID, seedFolder)),
+				ProductId: prod.ProductId,
+			}
+		}
+		if len(images) > 0 {
+			prod.Images, err = s.products.InsertImageUrls(images)
+			if err != nil {
+				return report, fmt.Errorf("error inserting images: %w", err)
+			}
+			report.Images += len(prod.Images)
+		}
+
+		for _, r := range reviews {
+			r.ProductId = prod.ProductId
+			if err := s.products.InsertReview(&r); err != nil {
+				return report, fmt.Errorf("error inserting review: %w", err)
+			}
+			report.Reviews++
+		}
+
+		prods = append(prods, prod)
+	}
+
+	if len(prods) == 0 {
+		return report, nil
+	}
+
+	for _, u := range users {
+		for i := 0; i < opts.OrdersPerUser; i++ {
+			if err := s.insertOrder(fakeOrder(f, u.ID, prods)); err != nil {
+				return report, err
+			}
+			report.Orders++
+		}
+	}
+
+	return report, nil
+}
+
+// insertOrder saves order with its shipping, items and payment.
+func (s *SeedUC) insertOrder(order models.Order) error {
+	ord, err := s.orders.InsertOrder(order)
+	if err != nil {
+		return fmt.Errorf("error inserting order: %w", err)
+	}
+
+	order.ShippingInfo.OrderID = ord.OrderID
+	if _, err := s.orders.InsertShipping(order.ShippingInfo); err != nil {
+		return fmt.Errorf("error inserting shipping: %w", err)
+	}
+
+	for _, i := range order.OrderItems {
+		i.OrderID = ord.OrderID
+		if _, err := s.orders.InsertItem(*i); err != nil {
+			return fmt.Errorf("error inserting item: %w", err)
+		}
+	}
+
+	order.PaymentInfo.OrderID = ord.OrderID
+	if _, err := s.orders.InsertPayment(order.PaymentInfo); err != nil {
+		return fmt.Errorf("error inserting payment: %w", err)
+	}
+
+	return nil
+}
+
+// fakeUser returns a customer with a unique example.com email and the password hash.
+func fakeUser(f *gofakeit.Faker, hash string) models.User {
+	first, last := f.FirstName(), f.LastName()
+
+	return models.User{
+		Name:     first + " " + last,
+		Email:    strings.ToLower(fmt.Sprintf("%s.%s.%s@example.com", first, last, uuid.NewString()[:8])),
+		Password: hash,
+		Role:     models.RoleUser,
+	}
+}
+
+// fakeProduct returns a product in the shop currency listed by owner.
+func fakeProduct(f *gofakeit.Faker, owner uuid.UUID) models.Product {
+	return models.Product{
+		Name:        truncate(f.ProductName(), 64),
+		Price:       money.Of(int64(f.IntRange(199, 49999))),
+		Description: truncate(f.ProductDescription(), 1000),
+		Category:    f.ProductCategory(),
+		Seller:      f.Company(),
+		Stock:       f.IntRange(0, 200),
+		Status:      models.ProductPublished,
+		UserId:      owner,
+	}
+}
+
+// fakeReviews returns up to n reviews by distinct users, leaning to good ratings.
+func fakeReviews(f *gofakeit.Faker, users []*models.User, n int) []models.Reviews {
+	n = min(f.IntRange(0, n), len(users))
+
+	reviews := make([]models.Reviews, 0, n)
+	for _, i := range f.Rand.Perm(len(users))[:n] {
+		reviews = append(reviews, models.Reviews{
+			Name:      users[i].Name,
+			Rating:    []int{1, 2, 3, 3, 4, 4, 4, 5, 5, 5}[f.IntRange(0, 9)],
+			Comment:   f.Sentence(f.IntRange(4, 20)),
+			UserId:    users[i].ID,
+			CreatedAt: f.DateRange(time.Now().Add(-history), time.Now()),
+		})
+	}
+
+	return reviews
+}
+
+// averageRating returns the rounded average rating of reviews, 0 without any.
+func averageRating(reviews []models.Reviews) int {
+	if len(reviews) == 0 {
+		return 0
+	}
+
+	sum := 0
+	for _, r := range reviews {
+		sum += r.Rating
+	}
+
+	return (sum*2 + len(reviews)) / (len(reviews) * 2)
+}
+
+// fakeOrder returns a paid order of userID for one to three of prods.
+func fakeOrder(f *gofakeit.Faker, userID uuid.UUID, prods []models.Product) models.Order {
+	n := f.IntRange(1, min(3, len(prods)))
+
+	order := models.Order{
+		UserID:      userID,
+		Currency:    money.DefaultCurrency,
+		ItemPrice:   money.Of(0),
+		OrderStatus: []string{models.OrderProcessing, models.OrderShipped, models.OrderDelivered}[f.IntRange(0, 2)],
+		PaidAt:      f.DateRange(time.Now().Add(-history), time.Now()),
+		ShippingInfo: models.Shipping{
+			Address:    f.Street(),
+			City:       f.City(),
+			PhoneNo:    f.Phone(),
+			PostalCode: f.Zip(),
+			Country:    f.Country(),
+		},
+		PaymentInfo: models.Payment{
+			ID:       "seed_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+			Provider: models.PaymentStripe,
+			Status:   models.PaymentSucceeded,
+		},
+	}
+	if order.OrderStatus == models.OrderDelivered {
+		order.DeliveredAt = order.PaidAt.Add(time.Duration(f.IntRange(1, 10)) * 24 * time.Hour)
+	}
+
+	for _, i := range f.Rand.Perm(len(prods))[:n] {
+		p := prods[i]
+		item := &models.Item{
+			Name:      p.Name,
+			Price:     p.Price,
+			Quantity:  f.IntRange(1, 3),
+			ProductID: p.ProductId,
+		}
+		if len(p.Images) > 0 {
+			item.Image = p.Images[0].Url
+		}
+		order.OrderItems = append(order.OrderItems, item)
+		order.ItemPrice = order.ItemPrice.Add(item.Price.Mul(item.Quantity))
+	}
+
+	order.TaxPrice = order.ItemPrice.Percent(15)
+	order.ShippingPrice = money.Of(500)
+	if order.ItemPrice.Amount >= 20000 {
+		order.ShippingPrice = money.Of(0)
+	}
+	order.TotalPrice = order.ItemPrice.Add(order.TaxPrice).Add(order.ShippingPrice)
+
+	return order
+}
+
+// truncate cuts s to at most n bytes, at a word boundary when it can.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	s = s[:n]
+	if i := strings.LastIndexByte(s, ' '); i > 0 {
+		s = s[:i]
+	}
+
+	return s
+}
@@ -0,0 +1,152 @@
+package usecase_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	authMocks "github.com/jofosuware/go/shopit/internal/auth/mocks"
+	catMocks "github.com/jofosuware/go/shopit/internal/categories/mocks"
+	"github.com/jofosuware/go/shopit/internal/models"
+	ordMocks "github.com/jofosuware/go/shopit/internal/orders/mocks"
+	prodMocks "github.com/jofosuware/go/shopit/internal/products/mocks"
+	"github.com/jofosuware/go/shopit/internal/seed"
+	"github.com/jofosuware/go/shopit/internal/seed/usecase"
+	bcryptMocks "github.com/jofosuware/go/shopit/pkg/bcrypt/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	users := authMocks.NewRepo(t)
+	categories := catMocks.NewRepo(t)
+	products := prodMocks.NewRepo(t)
+	orders := ordMocks.NewRepo(t)
+	hasher := bcryptMocks.NewEncryptor(t)
+	s := usecase.NewSeedUC(users, categories, products, orders, hasher)
+
+	t.Run("Data is generated", func(t *testing.T) {
+		hasher.On("GenerateFromPassword", []byte(usecase.Password)).Return([]byte("hash"), nil).Once()
+
+		var owner uuid.UUID
+		users.On("InsertUser", mock.MatchedBy(func(u models.User) bool {
+			return strings.HasSuffix(u.Email, "@example.com") && u.Password == "hash" && u.Role == models.RoleUser
+		})).Return(func(u models.User) (*models.User, error) {
+			u.ID = uuid.New()
+			if owner == uuid.Nil {
+				owner = u.ID
+			}
+			return &u, nil
+		}).Times(3)
+
+		categories.On("FetchCategories").Return(nil, nil).Once()
+		cats := map[uuid.UUID]string{}
+		categories.On("InsertCategory", mock.Anything).Return(func(c *models.Category) (*models.Category, error) {
+			c.CategoryId = uuid.New()
+			cats[c.CategoryId] = c.Name
+			return c, nil
+		})
+
+		products.On("InsertProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return p.UserId == owner && p.Name != "" && p.Price.IsPositive() && p.NumOfReviews <= 2 &&
+				p.Status == models.ProductPublished && cats[p.CategoryId.UUID] == p.Category
+		})).Return(func(p *models.Product) (models.Product, error) {
+			prod := *p
+			prod.ProductId = uuid.New()
+			return prod, nil
+		}).Times(2)
+		products.On("InsertImageUrls", mock.MatchedBy(func(imgs []models.Images) bool {
+			for _, img := range imgs {
+				if !strings.HasPrefix(img.PublicId, "seed/") || !strings.HasPrefix(img.Url, "https://") {
+					return false
+				}
+			}
+			return len(imgs) == 2
+		})).Return(func(imgs []models.Images) ([]models.Images, error) {
+			return imgs, nil
+		}).Times(2)
+		products.On("InsertReview", mock.MatchedBy(func(r *models.Reviews) bool {
+			return r.Rating >= 1 && r.Rating <= 5 && r.ProductId != uuid.Nil
+		})).Return(nil).Maybe()
+
+		orders.On("InsertOrder", mock.MatchedBy(func(o models.Order) bool {
+			return len(o.OrderItems) > 0 && o.TotalPrice == o.ItemPrice.Add(o.TaxPrice).Add(o.ShippingPrice)
+		})).Return(func(o models.Order) (*models.Order, error) {
+			o.OrderID = uuid.New()
+			return &o, nil
+		}).Times(6)
+		orders.On("InsertShipping", mock.Anything).Return(&models.Shipping{}, nil).Times(6)
+		orders.On("InsertItem", mock.Anything).Return(&models.Item{}, nil)
+		orders.On("InsertPayment", mock.MatchedBy(func(p models.Payment) bool {
+			return strings.HasPrefix(p.ID, "seed_") && p.Status == models.PaymentSucceeded
+		})).Return(&models.Payment{}, nil).Times(6)
+
+		report, err := s.Generate(models.SeedOptions{
+			Users:             3,
+			Products:          2,
+			ImagesPerProduct:  2,
+			ReviewsPerProduct: 2,
+			OrdersPerUser:     2,
+			Seed:              42,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, report.Users)
+		assert.Equal(t, len(cats), report.Categories)
+		assert.Equal(t, 2, report.Products)
+		assert.Equal(t, 4, report.Images)
+		assert.Equal(t, 6, report.Orders)
+		assert.LessOrEqual(t, report.Reviews, 4)
+		assert.Equal(t, usecase.Password, report.Password)
+		assert.Empty(t, report.Admin)
+	})
+
+	t.Run("First user is an admin", func(t *testing.T) {
+		hasher.On("GenerateFromPassword", []byte(usecase.Password)).Return([]byte("hash"), nil).Once()
+		users.On("InsertUser", mock.MatchedBy(func(u models.User) bool {
+			return u.Role == models.RoleAdmin
+		})).Return(&models.User{ID: uuid.New(), Email: "admin@example.com", Role: models.RoleAdmin}, nil).Once()
+		users.On("InsertUser", mock.MatchedBy(func(u models.User) bool {
+			return u.Role == models.RoleUser
+		})).Return(&models.User{ID: uuid.New(), Role: models.RoleUser}, nil).Once()
+
+		report, err := s.Generate(models.SeedOptions{Users: 2, Admin: true})
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Users)
+		assert.Equal(t, "admin@example.com", report.Admin)
+	})
+
+	t.Run("Products without an owner", func(t *testing.T) {
+		_, err := s.Generate(models.SeedOptions{Products: 5})
+		assert.ErrorIs(t, err, seed.ErrNoOwner)
+	})
+
+	t.Run("Products of the given owner", func(t *testing.T) {
+		owner := uuid.New()
+		hasher.On("GenerateFromPassword", []byte(usecase.Password)).Return([]byte("hash"), nil).Once()
+		categories.On("FetchCategories").Return(nil, nil).Once()
+		products.On("InsertProduct", mock.MatchedBy(func(p *models.Product) bool {
+			return p.UserId == owner && p.NumOfReviews == 0 && p.CategoryId.Valid
+		})).Return(models.Product{ProductId: uuid.New()}, nil).Once()
+
+		report, err := s.Generate(models.SeedOptions{Products: 1, ReviewsPerProduct: 5, OrdersPerUser: 5, Owner: owner})
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Categories)
+		assert.Equal(t, 1, report.Products)
+		assert.Zero(t, report.Reviews)
+		assert.Zero(t, report.Orders)
+	})
+
+	t.Run("Stops at the first failure", func(t *testing.T) {
+		hasher.On("GenerateFromPassword", []byte(usecase.Password)).Return([]byte("hash"), nil).Once()
+		users.On("InsertUser", mock.Anything).Return(&models.User{ID: uuid.New()}, nil).Once()
+		users.On("InsertUser", mock.Anything).Return(nil, errors.New("duplicate email")).Once()
+
+		report, err := s.Generate(models.SeedOptions{Users: 3, Products: 2})
+		require.Error(t, err)
+		assert.Equal(t, 1, report.Users)
+		assert.Zero(t, report.Products)
+	})
+}
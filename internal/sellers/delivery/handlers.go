@@ -0,0 +1,167 @@
+// Package delivery provides HTTP handlers for seller accounts.
+//
+// Customers apply to sell in the shop and follow their applications; admins list the
+// applications by status and approve or reject them.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/sellers"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// SellerHandlers provides HTTP handler methods for seller account endpoints.
+type SellerHandlers struct {
+	logger   logger.Logger
+	sellerUC sellers.SellerUC
+}
+
+// NewSellerHandlers returns a new SellerHandlers.
+func NewSellerHandlers(logger logger.Logger, sellerUC sellers.SellerUC) *SellerHandlers {
+	return &SellerHandlers{
+		logger:   logger,
+		sellerUC: sellerUC,
+	}
+}
+
+type applicationResponse struct {
+	Success     bool                      `json:"success"`
+	Application *models.SellerApplication `json:"application"`
+}
+
+type applicationsResponse struct {
+	Success      bool                       `json:"success"`
+	Applications []models.SellerApplication `json:"applications"`
+}
+
+// GetApplications returns the applications to sell of the current user, newest first.
+// Endpoint: GET /api/v1/sellers/applications
+func (h *SellerHandlers) GetApplications(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.sellerUC.GetApplications(user.ID)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting applications: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, applicationsResponse{Success: true, Applications: list})
+}
+
+// Apply applies for the current user to sell in the shop. Only customers apply, once
+// at a time.
+// Endpoint: POST /api/v1/sellers/applications
+// Expects JSON body: {"storeName": <string>, "message": <string, optional>}.
+func (h *SellerHandlers) Apply(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	var req applicationRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	a, err := h.sellerUC.Apply(user, strings.TrimSpace(req.StoreName), strings.TrimSpace(req.Message))
+	if err != nil {
+		h.writeError(w, r, "error applying to sell", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusCreated, applicationResponse{Success: true, Application: a})
+}
+
+// GetAllApplications returns the applications to sell, oldest first (admin).
+// Endpoint: GET /api/v1/sellers/admin/applications?status=<pending|approved|rejected>&limit=<int>
+// Every status is listed without one; limit defaults to 50 and is capped at 200.
+func (h *SellerHandlers) GetAllApplications(w http.ResponseWriter, r *http.Request) {
+	var limit int
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			_ = utils.BadRequest(w, r, errors.New("limit must be a positive number"))
+			h.logger.Errorf("error parsing limit: %v", l)
+			return
+		}
+		limit = n
+	}
+
+	list, err := h.sellerUC.GetAllApplications(r.URL.Query().Get("status"), limit)
+	if err != nil {
+		h.writeError(w, r, "error getting applications", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, applicationsResponse{Success: true, Applications: list})
+}
+
+// Approve approves a pending application; its user becomes a seller (admin).
+// Endpoint: PUT /api/v1/sellers/admin/applications/{id}/approve
+func (h *SellerHandlers) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, "error approving application", h.sellerUC.Approve)
+}
+
+// Reject rejects a pending application (admin).
+// Endpoint: PUT /api/v1/sellers/admin/applications/{id}/reject
+func (h *SellerHandlers) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, "error rejecting application", h.sellerUC.Reject)
+}
+
+// review reviews the application of the URL on behalf of the current admin.
+func (h *SellerHandlers) review(w http.ResponseWriter, r *http.Request, msg string,
+	fn func(id, adminID uuid.UUID) (*models.SellerApplication, error)) {
+	admin, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return
+	}
+
+	a, err := fn(id, admin.ID)
+	if err != nil {
+		h.writeError(w, r, msg, err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, applicationResponse{Success: true, Application: a})
+}
+
+// user returns the signed in user. It writes the response and returns false when
+// there is none.
+func (h *SellerHandlers) user(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return nil, false
+	}
+
+	return user, true
+}
+
+func (h *SellerHandlers) writeError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if sellers.IsClientError(err) {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("%s: %v", msg, err)
+		return
+	}
+	_ = utils.ServerError(w, r, h.logger, fmt.Errorf("%s: %w", msg, err))
+}
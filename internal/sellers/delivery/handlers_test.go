@@ -0,0 +1,144 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/sellers"
+	"github.com/jofosuware/go/shopit/internal/sellers/delivery"
+	"github.com/jofosuware/go/shopit/internal/sellers/mocks"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func request(user *models.User, method, target, id, body string) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	rCtx := chi.NewRouteContext()
+	rCtx.URLParams.Add("id", id)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+	return req.WithContext(context.WithValue(ctx, utils.UserContextKey, user))
+}
+
+func TestApply(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	sellerUC := mocks.NewSellerUC(t)
+	h := delivery.NewSellerHandlers(logger, sellerUC)
+	user := &models.User{ID: uuid.New(), Role: models.RoleUser}
+
+	call := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.Apply(rr, request(user, http.MethodPost, "/sellers/applications", "", body))
+		return rr
+	}
+
+	t.Run("Application is saved", func(t *testing.T) {
+		sellerUC.On("Apply", user, "Ama's Crafts", "Handmade baskets").
+			Return(&models.SellerApplication{ID: uuid.New(), UserID: user.ID, Status: models.ApplicationPending}, nil).Once()
+
+		rr := call(`{"storeName": " Ama's Crafts ", "message": "Handmade baskets"}`)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		var res struct {
+			Application models.SellerApplication `json:"application"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, models.ApplicationPending, res.Application.Status)
+	})
+
+	t.Run("Missing store name", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, call(`{"storeName": " "}`).Code)
+	})
+
+	t.Run("Application already pending", func(t *testing.T) {
+		sellerUC.On("Apply", user, "Ama's Crafts", "").Return(nil, sellers.ErrApplicationPending).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call(`{"storeName": "Ama's Crafts"}`).Code)
+	})
+}
+
+func TestGetAllApplications(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	sellerUC := mocks.NewSellerUC(t)
+	h := delivery.NewSellerHandlers(logger, sellerUC)
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
+	call := func(query string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.GetAllApplications(rr, request(admin, http.MethodGet, "/sellers/admin/applications"+query, "", ""))
+		return rr
+	}
+
+	t.Run("Pending applications", func(t *testing.T) {
+		sellerUC.On("GetAllApplications", models.ApplicationPending, 10).
+			Return([]models.SellerApplication{{ID: uuid.New()}}, nil).Once()
+
+		rr := call("?status=pending&limit=10")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Applications []models.SellerApplication `json:"applications"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Len(t, res.Applications, 1)
+	})
+
+	t.Run("Invalid status", func(t *testing.T) {
+		sellerUC.On("GetAllApplications", "open", 0).Return(nil, sellers.ErrInvalidStatus).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("?status=open").Code)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("?limit=0").Code)
+	})
+}
+
+func TestReview(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	sellerUC := mocks.NewSellerUC(t)
+	h := delivery.NewSellerHandlers(logger, sellerUC)
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	id := uuid.New()
+
+	t.Run("Approve", func(t *testing.T) {
+		sellerUC.On("Approve", id, admin.ID).
+			Return(&models.SellerApplication{ID: id, Status: models.ApplicationApproved}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.Approve(rr, request(admin, http.MethodPut, "/sellers/admin/applications/"+id.String()+"/approve", id.String(), ""))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Reject an application already reviewed", func(t *testing.T) {
+		sellerUC.On("Reject", id, admin.ID).Return(nil, sellers.ErrApplicationNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.Reject(rr, request(admin, http.MethodPut, "/sellers/admin/applications/"+id.String()+"/reject", id.String(), ""))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.Approve(rr, request(admin, http.MethodPut, "/sellers/admin/applications/42/approve", "42", ""))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,8 @@
+package delivery
+
+// applicationRequest is the body of the endpoint applying to sell. The lengths are the
+// ones of the seller_applications table.
+type applicationRequest struct {
+	StoreName string `json:"storeName" validate:"notblank,max=100"`
+	Message   string `json:"message" validate:"max=2000"`
+}
@@ -0,0 +1,29 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// SellerRouter returns a chi.Router with the seller account routes.
+//
+//   - GET  /applications                     → List the user's applications, newest first
+//   - POST /applications                     → Apply to sell
+//   - GET  /admin/applications               → List the applications, by status (admin)
+//   - PUT  /admin/applications/{id}/approve  → Approve an application (admin)
+//   - PUT  /admin/applications/{id}/reject   → Reject an application (admin)
+func (h *SellerHandlers) SellerRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+
+	mux.Get("/applications", h.GetApplications)
+	mux.Post("/applications", h.Apply)
+	mux.With(utils.IsAdmin).Get("/admin/applications", h.GetAllApplications)
+	mux.With(utils.IsAdmin).Put("/admin/applications/{id}/approve", h.Approve)
+	mux.With(utils.IsAdmin).Put("/admin/applications/{id}/reject", h.Reject)
+
+	return mux
+}
@@ -0,0 +1,25 @@
+package sellers
+
+import "errors"
+
+var (
+	// ErrApplicationNotFound is returned when reviewing an application that does not exist or was already
+	// reviewed.
+	ErrApplicationNotFound = errors.New("application not found or already reviewed")
+
+	// ErrApplicationPending is returned when a user applies while an application of theirs awaits review.
+	ErrApplicationPending = errors.New("you already have an application awaiting review")
+
+	// ErrCannotApply is returned when an admin or a seller applies to sell.
+	ErrCannotApply = errors.New("only customers can apply to sell")
+
+	// ErrInvalidStatus is returned when listing applications in a status that is not one of
+	// models.ApplicationStatuses.
+	ErrInvalidStatus = errors.New("status must be pending, approved or rejected")
+)
+
+// IsClientError reports whether err is caused by the request rather than the server.
+func IsClientError(err error) bool {
+	return errors.Is(err, ErrApplicationNotFound) || errors.Is(err, ErrApplicationPending) ||
+		errors.Is(err, ErrCannotApply) || errors.Is(err, ErrInvalidStatus)
+}
@@ -0,0 +1,149 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+// FetchApplications provides a mock function with given fields: status, limit
+func (_m *Repo) FetchApplications(status string, limit int) ([]models.SellerApplication, error) {
+	ret := _m.Called(status, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchApplications")
+	}
+
+	var r0 []models.SellerApplication
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]models.SellerApplication, error)); ok {
+		return rf(status, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []models.SellerApplication); ok {
+		r0 = rf(status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SellerApplication)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchUserApplications provides a mock function with given fields: userID
+func (_m *Repo) FetchUserApplications(userID uuid.UUID) ([]models.SellerApplication, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchUserApplications")
+	}
+
+	var r0 []models.SellerApplication
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.SellerApplication, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.SellerApplication); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SellerApplication)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertApplication provides a mock function with given fields: a
+func (_m *Repo) InsertApplication(a models.SellerApplication) (*models.SellerApplication, error) {
+	ret := _m.Called(a)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertApplication")
+	}
+
+	var r0 *models.SellerApplication
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.SellerApplication) (*models.SellerApplication, error)); ok {
+		return rf(a)
+	}
+	if rf, ok := ret.Get(0).(func(models.SellerApplication) *models.SellerApplication); ok {
+		r0 = rf(a)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SellerApplication)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.SellerApplication) error); ok {
+		r1 = rf(a)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReviewApplication provides a mock function with given fields: id, adminID, status
+func (_m *Repo) ReviewApplication(id uuid.UUID, adminID uuid.UUID, status string) (*models.SellerApplication, error) {
+	ret := _m.Called(id, adminID, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReviewApplication")
+	}
+
+	var r0 *models.SellerApplication
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, string) (*models.SellerApplication, error)); ok {
+		return rf(id, adminID, status)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, string) *models.SellerApplication); ok {
+		r0 = rf(id, adminID, status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SellerApplication)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = rf(id, adminID, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
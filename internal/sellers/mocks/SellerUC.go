@@ -0,0 +1,179 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// SellerUC is an autogenerated mock type for the SellerUC type
+type SellerUC struct {
+	mock.Mock
+}
+
+// Apply provides a mock function with given fields: user, storeName, message
+func (_m *SellerUC) Apply(user *models.User, storeName string, message string) (*models.SellerApplication, error) {
+	ret := _m.Called(user, storeName, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Apply")
+	}
+
+	var r0 *models.SellerApplication
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*models.User, string, string) (*models.SellerApplication, error)); ok {
+		return rf(user, storeName, message)
+	}
+	if rf, ok := ret.Get(0).(func(*models.User, string, string) *models.SellerApplication); ok {
+		r0 = rf(user, storeName, message)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SellerApplication)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*models.User, string, string) error); ok {
+		r1 = rf(user, storeName, message)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Approve provides a mock function with given fields: id, adminID
+func (_m *SellerUC) Approve(id uuid.UUID, adminID uuid.UUID) (*models.SellerApplication, error) {
+	ret := _m.Called(id, adminID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Approve")
+	}
+
+	var r0 *models.SellerApplication
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) (*models.SellerApplication, error)); ok {
+		return rf(id, adminID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) *models.SellerApplication); ok {
+		r0 = rf(id, adminID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SellerApplication)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(id, adminID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllApplications provides a mock function with given fields: status, limit
+func (_m *SellerUC) GetAllApplications(status string, limit int) ([]models.SellerApplication, error) {
+	ret := _m.Called(status, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllApplications")
+	}
+
+	var r0 []models.SellerApplication
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]models.SellerApplication, error)); ok {
+		return rf(status, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []models.SellerApplication); ok {
+		r0 = rf(status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SellerApplication)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetApplications provides a mock function with given fields: userID
+func (_m *SellerUC) GetApplications(userID uuid.UUID) ([]models.SellerApplication, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetApplications")
+	}
+
+	var r0 []models.SellerApplication
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.SellerApplication, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.SellerApplication); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SellerApplication)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reject provides a mock function with given fields: id, adminID
+func (_m *SellerUC) Reject(id uuid.UUID, adminID uuid.UUID) (*models.SellerApplication, error) {
+	ret := _m.Called(id, adminID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reject")
+	}
+
+	var r0 *models.SellerApplication
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) (*models.SellerApplication, error)); ok {
+		return rf(id, adminID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) *models.SellerApplication); ok {
+		r0 = rf(id, adminID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SellerApplication)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(id, adminID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSellerUC creates a new instance of SellerUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSellerUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SellerUC {
+	mock := &SellerUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
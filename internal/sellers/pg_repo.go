@@ -0,0 +1,23 @@
+package sellers
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// InsertApplication inserts a pending application of a user to sell, returns the saved application and a
+	// unique violation when the user already has one pending
+	InsertApplication(a models.SellerApplication) (*models.SellerApplication, error)
+
+	// FetchUserApplications fetches the applications of a user, newest first, returns an error on failure
+	FetchUserApplications(userID uuid.UUID) ([]models.SellerApplication, error)
+
+	// FetchApplications fetches up to limit applications in status, of any status when it is empty, oldest
+	// first
+	FetchApplications(status string, limit int) ([]models.SellerApplication, error)
+
+	// ReviewApplication sets the status of a pending application, and gives its user the seller role when it
+	// is approved, in one transaction, returns sql.ErrNoRows when it does not exist or is not pending
+	ReviewApplication(id, adminID uuid.UUID, status string) (*models.SellerApplication, error)
+}
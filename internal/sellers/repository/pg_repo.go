@@ -0,0 +1,152 @@
+// Package repository provides persistence for seller applications.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// SellersRepository handles seller application database operations.
+type SellersRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewSellersRepository returns a new SellersRepository.
+func NewSellersRepository(db *sql.DB) *SellersRepository {
+	return &SellersRepository{
+		DB: db,
+	}
+}
+
+// applicationColumns are the columns of an application a, with the name and email of
+// its user u.
+const applicationColumns = "a.application_id, a.user_id, u.name, u.email, a.store_name, a.message, a.status, " +
+	"a.reviewed_by, a.reviewed_at, a.created_at"
+
+// InsertApplication inserts a pending application of a user. It returns a unique
+// violation of seller_applications_pending_idx when the user already has one pending.
+func (r *SellersRepository) InsertApplication(a models.SellerApplication) (*models.SellerApplication, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `with a as (
+				insert into seller_applications (user_id, store_name, message, status, created_at)
+				values ($1, $2, $3, $4, $5) returning *
+			) select ` + applicationColumns + ` from a join users u on u.user_id = a.user_id`
+
+	return scanApplication(r.DB.QueryRowContext(ctx, query, a.UserID, a.StoreName, a.Message,
+		models.ApplicationPending, time.Now()))
+}
+
+// FetchUserApplications fetches the applications of a user, newest first.
+func (r *SellersRepository) FetchUserApplications(userID uuid.UUID) ([]models.SellerApplication, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "select " + applicationColumns + ` from seller_applications a join users u on u.user_id = a.user_id
+				where a.user_id = $1 order by a.created_at desc`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanApplications(rows)
+}
+
+// FetchApplications fetches up to limit applications in status, or of any status when
+// status is empty, oldest first so that admins review them in turn.
+func (r *SellersRepository) FetchApplications(status string, limit int) ([]models.SellerApplication, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "select " + applicationColumns + ` from seller_applications a join users u on u.user_id = a.user_id
+				where $1 = '' or a.status = $1 order by a.created_at limit $2`
+
+	rows, err := r.DB.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanApplications(rows)
+}
+
+// ReviewApplication sets the status of a pending application and the admin who
+// reviewed it. When it is approved its user is given the seller role in the same
+// transaction, unless they are an admin. It returns sql.ErrNoRows when the application
+// does not exist or is not pending.
+func (r *SellersRepository) ReviewApplication(id, adminID uuid.UUID, status string) (*models.SellerApplication, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `with a as (
+				update seller_applications set status = $1, reviewed_by = $2, reviewed_at = $3
+				where application_id = $4 and status = $5 returning *
+			) select ` + applicationColumns + ` from a join users u on u.user_id = a.user_id`
+
+	a, err := scanApplication(tx.QueryRowContext(ctx, query, status, adminID, time.Now(), id, models.ApplicationPending))
+	if err != nil {
+		return nil, err
+	}
+
+	if status == models.ApplicationApproved {
+		_, err := tx.ExecContext(ctx, "update users set role = $1 where user_id = $2 and role = $3",
+			models.RoleSeller, a.UserID, models.RoleUser)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanApplication(row scanner) (*models.SellerApplication, error) {
+	var a models.SellerApplication
+
+	err := row.Scan(&a.ID, &a.UserID, &a.Name, &a.Email, &a.StoreName, &a.Message, &a.Status, &a.ReviewedBy,
+		&a.ReviewedAt, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func scanApplications(rows *sql.Rows) ([]models.SellerApplication, error) {
+	defer rows.Close()
+
+	applications := []models.SellerApplication{}
+	for rows.Next() {
+		a, err := scanApplication(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		applications = append(applications, *a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applications, nil
+}
@@ -0,0 +1,108 @@
+package repository_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/sellers/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var applicationColumns = []string{"application_id", "user_id", "name", "email", "store_name", "message", "status",
+	"reviewed_by", "reviewed_at", "created_at"}
+
+func TestInsertApplication(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewSellersRepository(db)
+	userID, id := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`insert into seller_applications .* select a.application_id, .* from a join users u`).
+		WithArgs(userID, "Ama's Crafts", "Handmade baskets", models.ApplicationPending, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(applicationColumns).AddRow(id, userID, "Ama", "ama@example.com",
+			"Ama's Crafts", "Handmade baskets", models.ApplicationPending, nil, nil, time.Now()))
+
+	a, err := repo.InsertApplication(models.SellerApplication{UserID: userID, StoreName: "Ama's Crafts",
+		Message: "Handmade baskets"})
+	require.NoError(t, err)
+	assert.Equal(t, id, a.ID)
+	assert.Equal(t, "ama@example.com", a.Email)
+	assert.False(t, a.ReviewedBy.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchApplications(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewSellersRepository(db)
+
+	mock.ExpectQuery(`where \$1 = '' or a.status = \$1 order by a.created_at limit \$2`).
+		WithArgs(models.ApplicationPending, 50).
+		WillReturnRows(sqlmock.NewRows(applicationColumns).AddRow(uuid.New(), uuid.New(), "Ama", "ama@example.com",
+			"Ama's Crafts", "", models.ApplicationPending, nil, nil, time.Now()))
+
+	list, err := repo.FetchApplications(models.ApplicationPending, 50)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReviewApplication(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewSellersRepository(db)
+	id, userID, adminID := uuid.New(), uuid.New(), uuid.New()
+	reviewed := func(status string) *sqlmock.Rows {
+		return sqlmock.NewRows(applicationColumns).AddRow(id, userID, "Ama", "ama@example.com", "Ama's Crafts", "",
+			status, adminID, time.Now(), time.Now())
+	}
+
+	t.Run("Approval makes the user a seller", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("update seller_applications set status = \\$1").
+			WithArgs(models.ApplicationApproved, adminID, sqlmock.AnyArg(), id, models.ApplicationPending).
+			WillReturnRows(reviewed(models.ApplicationApproved))
+		mock.ExpectExec("update users set role = \\$1 where user_id = \\$2 and role = \\$3").
+			WithArgs(models.RoleSeller, userID, models.RoleUser).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		a, err := repo.ReviewApplication(id, adminID, models.ApplicationApproved)
+		require.NoError(t, err)
+		assert.Equal(t, models.ApplicationApproved, a.Status)
+		assert.Equal(t, uuid.NullUUID{UUID: adminID, Valid: true}, a.ReviewedBy)
+	})
+
+	t.Run("Rejection leaves the role alone", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("update seller_applications set status = \\$1").
+			WithArgs(models.ApplicationRejected, adminID, sqlmock.AnyArg(), id, models.ApplicationPending).
+			WillReturnRows(reviewed(models.ApplicationRejected))
+		mock.ExpectCommit()
+
+		_, err := repo.ReviewApplication(id, adminID, models.ApplicationRejected)
+		require.NoError(t, err)
+	})
+
+	t.Run("Application already reviewed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("update seller_applications").WillReturnRows(sqlmock.NewRows(applicationColumns))
+		mock.ExpectRollback()
+
+		_, err := repo.ReviewApplication(id, adminID, models.ApplicationApproved)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
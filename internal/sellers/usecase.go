@@ -0,0 +1,26 @@
+package sellers
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type SellerUC interface {
+	// Apply applies for a customer to sell in the shop, returns the application and an error when the user
+	// cannot apply or already has an application pending
+	Apply(user *models.User, storeName, message string) (*models.SellerApplication, error)
+
+	// GetApplications returns the applications of a user, newest first
+	GetApplications(userID uuid.UUID) ([]models.SellerApplication, error)
+
+	// GetAllApplications returns the applications in status, of any status when it is empty, oldest first
+	GetAllApplications(status string, limit int) ([]models.SellerApplication, error)
+
+	// Approve approves a pending application and gives its user the seller role, returns the application and
+	// an error when it does not exist or was already reviewed
+	Approve(id, adminID uuid.UUID) (*models.SellerApplication, error)
+
+	// Reject rejects a pending application, returns the application and an error when it does not exist or
+	// was already reviewed
+	Reject(id, adminID uuid.UUID) (*models.SellerApplication, error)
+}
@@ -0,0 +1,124 @@
+// Package usecase implements seller accounts.
+//
+// A customer applies to sell in the shop with the name of their store, and an admin
+// approves or rejects the application. Approving it gives the customer the seller
+// role, with which they create products and update, delete and list the ones they
+// own; a rejected customer may apply again.
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/sellers"
+	"github.com/jofosuware/go/shopit/pkg/driver"
+)
+
+// Application list limits
+const (
+	// DefaultApplicationLimit is how many applications admins list without a limit
+	DefaultApplicationLimit = 50
+	// MaxApplicationLimit caps the applications admins list at once
+	MaxApplicationLimit = 200
+)
+
+// pendingApplicationIndex is the unique index keeping a user to one pending application.
+const pendingApplicationIndex = "seller_applications_pending_idx"
+
+// SellersUC provides seller account use cases.
+type SellersUC struct {
+	repo sellers.Repo
+}
+
+// NewSellersUC returns a new SellersUC.
+func NewSellersUC(repo sellers.Repo) *SellersUC {
+	return &SellersUC{
+		repo: repo,
+	}
+}
+
+// Apply applies for user to sell under storeName. It returns sellers.ErrCannotApply
+// when user is an admin or already a seller, and sellers.ErrApplicationPending when an
+// application of theirs awaits review.
+func (u *SellersUC) Apply(user *models.User, storeName, message string) (*models.SellerApplication, error) {
+	if user.Role != models.RoleUser {
+		return nil, sellers.ErrCannotApply
+	}
+
+	a, err := u.repo.InsertApplication(models.SellerApplication{
+		UserID:    user.ID,
+		StoreName: storeName,
+		Message:   message,
+	})
+	if err != nil {
+		if driver.IsUniqueViolation(err, pendingApplicationIndex) {
+			return nil, sellers.ErrApplicationPending
+		}
+		return nil, fmt.Errorf("error inserting application: %w", err)
+	}
+
+	return a, nil
+}
+
+// GetApplications returns the applications of a user, newest first.
+func (u *SellersUC) GetApplications(userID uuid.UUID) ([]models.SellerApplication, error) {
+	list, err := u.repo.FetchUserApplications(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching applications: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetAllApplications returns up to limit applications in status, of any status when
+// status is empty, oldest first. A non-positive limit stands for
+// DefaultApplicationLimit, and limit is capped at MaxApplicationLimit.
+func (u *SellersUC) GetAllApplications(status string, limit int) ([]models.SellerApplication, error) {
+	if status != "" && !slices.Contains(models.ApplicationStatuses, status) {
+		return nil, sellers.ErrInvalidStatus
+	}
+
+	if limit <= 0 {
+		limit = DefaultApplicationLimit
+	}
+	if limit > MaxApplicationLimit {
+		limit = MaxApplicationLimit
+	}
+
+	list, err := u.repo.FetchApplications(status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching applications: %w", err)
+	}
+
+	return list, nil
+}
+
+// Approve approves a pending application on behalf of an admin and gives its user the
+// seller role. It returns sellers.ErrApplicationNotFound when the application does not
+// exist or was already reviewed.
+func (u *SellersUC) Approve(id, adminID uuid.UUID) (*models.SellerApplication, error) {
+	return u.review(id, adminID, models.ApplicationApproved)
+}
+
+// Reject rejects a pending application on behalf of an admin. It returns
+// sellers.ErrApplicationNotFound when the application does not exist or was already
+// reviewed.
+func (u *SellersUC) Reject(id, adminID uuid.UUID) (*models.SellerApplication, error) {
+	return u.review(id, adminID, models.ApplicationRejected)
+}
+
+func (u *SellersUC) review(id, adminID uuid.UUID, status string) (*models.SellerApplication, error) {
+	a, err := u.repo.ReviewApplication(id, adminID, status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sellers.ErrApplicationNotFound
+		}
+		return nil, fmt.Errorf("error reviewing application: %w", err)
+	}
+
+	return a, nil
+}
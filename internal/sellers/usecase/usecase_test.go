@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/sellers"
+	"github.com/jofosuware/go/shopit/internal/sellers/mocks"
+	"github.com/jofosuware/go/shopit/internal/sellers/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewSellersUC(repo)
+	customer := &models.User{ID: uuid.New(), Role: models.RoleUser}
+	application := models.SellerApplication{UserID: customer.ID, StoreName: "Ama's Crafts", Message: "Baskets"}
+
+	t.Run("Customer applies", func(t *testing.T) {
+		saved := application
+		saved.Status = models.ApplicationPending
+		repo.On("InsertApplication", application).Return(&saved, nil).Once()
+
+		a, err := u.Apply(customer, "Ama's Crafts", "Baskets")
+		require.NoError(t, err)
+		assert.Equal(t, models.ApplicationPending, a.Status)
+	})
+
+	t.Run("Application already pending", func(t *testing.T) {
+		repo.On("InsertApplication", application).
+			Return(nil, &pgconn.PgError{Code: "23505", ConstraintName: "seller_applications_pending_idx"}).Once()
+
+		_, err := u.Apply(customer, "Ama's Crafts", "Baskets")
+		assert.ErrorIs(t, err, sellers.ErrApplicationPending)
+	})
+
+	t.Run("Sellers and admins cannot apply", func(t *testing.T) {
+		for _, role := range []string{models.RoleSeller, models.RoleAdmin} {
+			_, err := u.Apply(&models.User{ID: uuid.New(), Role: role}, "Store", "")
+			assert.ErrorIs(t, err, sellers.ErrCannotApply)
+		}
+	})
+}
+
+func TestGetAllApplications(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewSellersUC(repo)
+
+	t.Run("Limit defaults and is capped", func(t *testing.T) {
+		repo.On("FetchApplications", "", usecase.DefaultApplicationLimit).Return([]models.SellerApplication{}, nil).Once()
+		repo.On("FetchApplications", models.ApplicationPending, usecase.MaxApplicationLimit).
+			Return([]models.SellerApplication{}, nil).Once()
+
+		_, err := u.GetAllApplications("", 0)
+		require.NoError(t, err)
+		_, err = u.GetAllApplications(models.ApplicationPending, 1000)
+		require.NoError(t, err)
+	})
+
+	t.Run("Invalid status", func(t *testing.T) {
+		_, err := u.GetAllApplications("open", 0)
+		assert.ErrorIs(t, err, sellers.ErrInvalidStatus)
+	})
+}
+
+func TestReview(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewSellersUC(repo)
+	id, adminID := uuid.New(), uuid.New()
+
+	t.Run("Approve", func(t *testing.T) {
+		repo.On("ReviewApplication", id, adminID, models.ApplicationApproved).
+			Return(&models.SellerApplication{ID: id, Status: models.ApplicationApproved}, nil).Once()
+
+		a, err := u.Approve(id, adminID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ApplicationApproved, a.Status)
+	})
+
+	t.Run("Reject an application already reviewed", func(t *testing.T) {
+		repo.On("ReviewApplication", id, adminID, models.ApplicationRejected).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := u.Reject(id, adminID)
+		assert.ErrorIs(t, err, sellers.ErrApplicationNotFound)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		repo.On("ReviewApplication", id, adminID, models.ApplicationApproved).Return(nil, errors.New("db down")).Once()
+
+		_, err := u.Approve(id, adminID)
+		assert.ErrorContains(t, err, "db down")
+	})
+}
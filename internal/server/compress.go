@@ -0,0 +1,24 @@
+package server
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// compressedTypes are the content types of the responses that are compressed.
+var compressedTypes = []string{"application/json", "text/csv"}
+
+// compress compresses the JSON and CSV responses of clients accepting it at level,
+// with brotli when they accept it, then gzip or deflate. Brotli levels go up to 11,
+// so the gzip levels of 1 to 9 are valid for it too.
+func compress(level int) func(http.Handler) http.Handler {
+	c := middleware.NewCompressor(level, compressedTypes...)
+	c.SetEncoder("br", func(w io.Writer, level int) io.Writer {
+		return brotli.NewWriterLevel(w, level)
+	})
+
+	return c.Handler
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	body := `{"products":[` + strings.Repeat(`{"name":"Wireless mouse"},`, 100) + `{}]}`
+	handler := compress(5)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, body)
+	}))
+
+	serve := func(acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Brotli is preferred", func(t *testing.T) {
+		rr := serve("gzip, deflate, br")
+		require.Equal(t, "br", rr.Header().Get("Content-Encoding"))
+
+		got, err := io.ReadAll(brotli.NewReader(rr.Body))
+		require.NoError(t, err)
+		assert.Equal(t, body, string(got))
+	})
+
+	t.Run("Gzip without brotli", func(t *testing.T) {
+		rr := serve("gzip")
+		require.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+
+		zr, err := gzip.NewReader(rr.Body)
+		require.NoError(t, err)
+		got, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(got))
+	})
+
+	t.Run("Identity", func(t *testing.T) {
+		rr := serve("")
+		assert.Empty(t, rr.Header().Get("Content-Encoding"))
+		assert.Equal(t, body, rr.Body.String())
+	})
+}
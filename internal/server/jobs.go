@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/scheduler"
+)
+
+// scheduleJobs registers the background jobs enabled by the config on a scheduler. A
+// run is cancelled once it takes its whole interval, and starts up to a tenth of its
+// interval late, so that the instances of the server do not all run a job at once.
+func (s *Serve) scheduleJobs() *scheduler.Scheduler {
+	jobs := scheduler.New(s.logger.With("module", "scheduler"))
+	every := func(name string, interval time.Duration, fn scheduler.Func) {
+		jobs.Every(name, interval, fn, scheduler.Timeout(interval), scheduler.Jitter(interval/10))
+	}
+
+	every("asset reconciliation", s.cfg.Storage.ReconcileInterval, s.reconcileAssets)
+	every("token cleanup", s.cfg.Server.TokenCleanupInterval, s.cleanupTokens)
+	every("account purge", s.cfg.Server.AccountPurgeInterval, s.purgeDeletedAccounts)
+	every("product publishing", s.cfg.Server.PublishInterval, s.publishScheduledProducts)
+	every("checkout session expiry", s.cfg.Checkout.ExpiryInterval, s.expireCheckoutSessions)
+	every("outbox dispatch", s.cfg.Outbox.DispatchInterval, s.dispatchOutbox)
+	every("admin digest", s.cfg.Notifications.DigestInterval, s.sendDigests)
+	every("webhook delivery", s.cfg.Webhooks.DeliveryInterval, s.deliverWebhooks)
+	if s.cfg.CatalogSync.SheetID != "" {
+		every("catalog sync", s.cfg.CatalogSync.Interval, s.syncCatalog)
+	}
+	if s.cfg.Stripe.ManualCapture {
+		every("payment void", s.cfg.Stripe.VoidInterval, s.voidUncapturedPayments)
+	}
+
+	return jobs
+}
+
+// reconcileAssets destroys orphaned uploads.
+func (s *Serve) reconcileAssets(ctx context.Context) error {
+	report, err := assetsUseCase.ReconcileOrphans(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("asset reconciliation: scanned=%d orphaned=%d destroyed=%d failed=%d",
+		report.Scanned, report.Orphaned, report.Destroyed, len(report.Failed))
+	return nil
+}
+
+// cleanupTokens deletes expired authentication and password reset tokens, and magic
+// links.
+func (s *Serve) cleanupTokens(ctx context.Context) error {
+	n, err := authUseCase.DeleteExpiredTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("token cleanup: removed=%d", n)
+	return nil
+}
+
+// purgeDeletedAccounts deletes the accounts whose deletion grace period is over.
+func (s *Serve) purgeDeletedAccounts(ctx context.Context) error {
+	n, err := authUseCase.PurgeDeletedAccounts(ctx)
+
+	s.logger.Infof("account purge: deleted=%d", n)
+	return err
+}
+
+// expireCheckoutSessions expires checkout sessions whose price lock ran out.
+func (s *Serve) expireCheckoutSessions(ctx context.Context) error {
+	n, err := checkoutUseCase.ExpireSessions(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("checkout session expiry: expired=%d", n)
+	return nil
+}
+
+// publishScheduledProducts publishes the drafts whose publish time has come.
+func (s *Serve) publishScheduledProducts(ctx context.Context) error {
+	n, err := prodUseCase.PublishScheduled(ctx)
+
+	if n > 0 {
+		s.logger.Infof("product publishing: published=%d", n)
+	}
+	return err
+}
+
+// syncCatalog imports the products of the catalog sheet.
+func (s *Serve) syncCatalog(ctx context.Context) error {
+	owner, _ := uuid.Parse(s.cfg.CatalogSync.Owner)
+
+	report, err := prodUseCase.SyncSheet(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("catalog sync: created=%d updated=%d failed=%d", report.Created, report.Updated, report.Failed)
+	return nil
+}
+
+// voidUncapturedPayments voids the authorized payments of orders that did not ship in time.
+func (s *Serve) voidUncapturedPayments(ctx context.Context) error {
+	n, err := ordUseCase.VoidUncapturedPayments(ctx)
+
+	s.logger.Infof("payment void: voided=%d", n)
+	return err
+}
+
+// dispatchOutbox delivers the pending events of the outbox.
+func (s *Serve) dispatchOutbox(ctx context.Context) error {
+	n, err := outboxDispatcher.Dispatch(ctx)
+
+	if n > 0 {
+		s.logger.Infof("outbox dispatch: delivered=%d", n)
+	}
+	return err
+}
+
+// sendDigests emails the admin digests that are due.
+func (s *Serve) sendDigests(ctx context.Context) error {
+	n, err := notifyUseCase.SendDigests(ctx)
+
+	if n > 0 {
+		s.logger.Infof("admin digest: sent=%d", n)
+	}
+	return err
+}
+
+// deliverWebhooks posts the webhook deliveries that are due.
+func (s *Serve) deliverWebhooks(ctx context.Context) error {
+	n, err := integrationUseCase.DeliverWebhooks(ctx)
+
+	if n > 0 {
+		s.logger.Infof("webhook delivery: delivered=%d", n)
+	}
+	return err
+}
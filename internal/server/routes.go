@@ -2,30 +2,76 @@ package server
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	"github.com/jofosuware/go/shopit/pkg/storage"
+	"github.com/jofosuware/go/shopit/pkg/tracing"
+	"github.com/jofosuware/go/shopit/pkg/utils"
 )
 
 func (s *Serve) Routes() http.Handler {
 	mux := chi.NewRouter()
 
+	if s.cfg.Tracing.Enabled {
+		mux.Use(tracing.Middleware)
+	}
 	mux.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://shopit-1-87gz.onrender.com", "http://localhost:3000"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Origin"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Origin", "X-Currency", "Accept-Language"},
 		ExposedHeaders:   []string{"Link", "Access-Control-Allow-Credentials"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
-	mux.Use(middleware.Recoverer)
+	if s.cfg.Server.SSL && s.cfg.Server.HSTSMaxAge > 0 {
+		mux.Use(hsts(s.cfg.Server.HSTSMaxAge))
+	}
+	if s.cfg.Server.CompressionLevel > 0 {
+		mux.Use(compress(s.cfg.Server.CompressionLevel))
+	}
+	mux.Use(utils.PrettyJSON)
+	mux.Use(resolver.Middleware)
+	mux.Use(utils.RequestLogger(s.logger))
+	mux.Use(utils.RecoverPanic(s.logger, reporter))
+	mux.Use(shedder.Middleware)
+	mux.Use(i18n.Middleware)
+	mux.Use(limiter.Middleware)
+	mux.Use(features.Middleware)
+	mux.Use(auditor.Middleware)
+
+	if strings.EqualFold(s.cfg.Storage.Provider, storage.ProviderLocal) {
+		mux.Handle(storage.StaticPrefix+"/*", storage.StaticHandler(s.cfg.Storage.Local.Dir))
+	}
 
 	mux.Mount("/api/v1/auth", authHandlers.AuthRouter())
 	mux.Mount("/api/v1/product", prodHandlers.ProdRouter())
+	mux.Mount("/api/v1/categories", categoryHandlers.CategoryRouter())
 	mux.Mount("/api/v1/orders", ordHandlers.OrderRouter())
+	mux.Mount("/api/v1/addresses", addressHandlers.AddressRouter())
+	mux.Mount("/api/v1/wishlist", wishlistHandlers.WishlistRouter())
+	mux.Mount("/api/v1/tickets", ticketHandlers.TicketRouter())
+	mux.Mount("/api/v1/sellers", sellerHandlers.SellerRouter())
+	mux.Mount("/api/v1/uploads", uploadHandlers.UploadRouter())
 	mux.Mount("/api/v1/payment", payHandlers.PaymentRouter())
+	mux.Mount("/api/v1/checkout", checkoutHandlers.CheckoutRouter())
+	mux.Mount("/api/v1/credit", creditHandlers.CreditRouter())
+	mux.Mount("/api/v1/promotions", promoHandlers.PromotionRouter())
+	mux.Mount("/api/v1/cart", promoHandlers.CartRouter())
+	mux.Mount("/api/v1/notifications", notificationHandlers.NotificationRouter())
+	mux.Mount("/api/v1/integration", integrationHandlers.IntegrationRouter())
+	mux.Mount("/api/v1/graphql", graphqlHandlers.GraphQLRouter())
+	mux.Mount("/api/v1/admin/system", sysHandlers.SystemRouter())
+	mux.Mount("/api/v1/admin/experiments", expHandlers.ExperimentRouter())
+	mux.Mount("/api/v1/admin/exports", exportHandlers.ExportRouter())
+	mux.Mount("/api/v1/admin/reports", reportHandlers.ReportRouter())
+	mux.Mount("/api/v1/admin/analytics", analyticsHandlers.AnalyticsRouter())
+	if s.cfg.Seed.Enabled {
+		mux.Mount("/api/v1/admin/seed", seedHandlers.SeedRouter())
+	}
 
 	return mux
 }
@@ -1,15 +1,56 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	address "github.com/jofosuware/go/shopit/internal/addresses/delivery"
+	analytics "github.com/jofosuware/go/shopit/internal/analytics/delivery"
+	"github.com/jofosuware/go/shopit/internal/assets"
+	authentication "github.com/jofosuware/go/shopit/internal/auth"
 	auth "github.com/jofosuware/go/shopit/internal/auth/delivery"
+	category "github.com/jofosuware/go/shopit/internal/categories/delivery"
+	"github.com/jofosuware/go/shopit/internal/checkout"
+	checkoutHTTP "github.com/jofosuware/go/shopit/internal/checkout/delivery"
+	credit "github.com/jofosuware/go/shopit/internal/credit/delivery"
+	experiment "github.com/jofosuware/go/shopit/internal/experiments/delivery"
+	export "github.com/jofosuware/go/shopit/internal/exports/delivery"
+	graphql "github.com/jofosuware/go/shopit/internal/graphql/delivery"
+	integrations "github.com/jofosuware/go/shopit/internal/integration"
+	integration "github.com/jofosuware/go/shopit/internal/integration/delivery"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	notification "github.com/jofosuware/go/shopit/internal/notifications/delivery"
+	"github.com/jofosuware/go/shopit/internal/orders"
 	order "github.com/jofosuware/go/shopit/internal/orders/delivery"
 	payment "github.com/jofosuware/go/shopit/internal/payment/delivery"
+	"github.com/jofosuware/go/shopit/internal/products"
 	product "github.com/jofosuware/go/shopit/internal/products/delivery"
+	promotion "github.com/jofosuware/go/shopit/internal/promotions/delivery"
+	report "github.com/jofosuware/go/shopit/internal/reports/delivery"
+	seed "github.com/jofosuware/go/shopit/internal/seed/delivery"
+	seller "github.com/jofosuware/go/shopit/internal/sellers/delivery"
+	system "github.com/jofosuware/go/shopit/internal/system/delivery"
+	ticket "github.com/jofosuware/go/shopit/internal/tickets/delivery"
+	upload "github.com/jofosuware/go/shopit/internal/uploads/delivery"
+	wishlist "github.com/jofosuware/go/shopit/internal/wishlist/delivery"
+	"github.com/jofosuware/go/shopit/pkg/audit"
+	"github.com/jofosuware/go/shopit/pkg/errreport"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/featureflag"
+	"github.com/jofosuware/go/shopit/pkg/loadshed"
+	"github.com/jofosuware/go/shopit/pkg/outbox"
+	"github.com/jofosuware/go/shopit/pkg/ratelimiter"
+	"github.com/jofosuware/go/shopit/pkg/realip"
+	"github.com/jofosuware/go/shopit/pkg/realtime"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
 
 	"github.com/jofosuware/go/shopit/config"
 	"github.com/jofosuware/go/shopit/pkg/logger"
@@ -19,6 +60,41 @@ var authHandlers *auth.AuthHandlers
 var ordHandlers *order.OrderHandlers
 var payHandlers *payment.PaymentHandler
 var prodHandlers *product.ProdHandlers
+var categoryHandlers *category.CategoryHandlers
+var sysHandlers *system.SystemHandlers
+var expHandlers *experiment.ExperimentHandlers
+var checkoutHandlers *checkoutHTTP.CheckoutHandlers
+var integrationHandlers *integration.IntegrationHandlers
+var graphqlHandlers *graphql.GraphQLHandlers
+var creditHandlers *credit.CreditHandlers
+var promoHandlers *promotion.PromotionHandlers
+var notificationHandlers *notification.NotificationHandlers
+var seedHandlers *seed.SeedHandlers
+var addressHandlers *address.AddressHandlers
+var wishlistHandlers *wishlist.WishlistHandlers
+var ticketHandlers *ticket.TicketHandlers
+var sellerHandlers *seller.SellerHandlers
+var uploadHandlers *upload.UploadHandlers
+var exportHandlers *export.ExportHandlers
+var reportHandlers *report.ReportHandlers
+var analyticsHandlers *analytics.AnalyticsHandlers
+var resolver *realip.Resolver
+var limiter *ratelimiter.RateLimiter
+var shedder *loadshed.Shedder
+var reporter errreport.Reporter
+var assetsUseCase assets.AssetsUC
+var authUseCase authentication.AuthenticateUC
+var checkoutUseCase checkout.CheckoutUC
+var ordUseCase orders.OrderUC
+var prodUseCase products.ProductUC
+var notifyUseCase notifications.NotificationUC
+var integrationUseCase integrations.IntegrationUC
+var features *featureflag.Flags
+var auditor *audit.Auditor
+var orderEvents *realtime.Hub
+var domainEvents *events.Bus
+var outboxDispatcher *outbox.Dispatcher
+var grpcServer *grpc.Server
 
 // Serve holds the Server configuration
 type Serve struct {
@@ -35,6 +111,26 @@ func NewServer(cfg *config.Config, logger logger.Logger, db *sql.DB) *Serve {
 	}
 }
 
+// Reload applies the settings of cfg that can change while the server runs, the rate
+// limits, to the server set up by Setup. The log level is set on the logger itself.
+func (s *Serve) Reload(cfg *config.Config) {
+	rl, burst := rateLimit(cfg.RateLimit)
+	limiter.SetLimit(rl, burst)
+	s.logger.Infof("rate limit reloaded: %v/s, burst %d", rl, burst)
+}
+
+// rateLimit returns the rate and burst of c, 20 and 40 when unset.
+func rateLimit(c config.RateLimit) (rate.Limit, int) {
+	rl, burst := rate.Limit(c.Rate), c.Burst
+	if rl <= 0 {
+		rl = 20
+	}
+	if burst <= 0 {
+		burst = 40
+	}
+	return rl, burst
+}
+
 func (s *Serve) Run() error {
 	srv := &http.Server{
 		Addr:              fmt.Sprintf(":%s", s.cfg.Server.Port),
@@ -44,8 +140,88 @@ func (s *Serve) Run() error {
 		ReadHeaderTimeout: 5 * time.Second,
 		WriteTimeout:      5 * time.Second,
 	}
+	manager := s.certManager()
+	redirect := s.redirectServer(manager)
+
+	var grpcListener net.Listener
+	if grpcServer != nil {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%s", s.cfg.GRPC.Port))
+		if err != nil {
+			return fmt.Errorf("error listening for grpc: %w", err)
+		}
+		grpcListener = lis
+	}
+
+	// background jobs and the listeners stop on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	jobs := s.scheduleJobs()
+	jobs.Start(ctx)
+
+	errCh := make(chan error, 3)
+	go func() {
+		errCh <- s.serve(srv, manager)
+	}()
+
+	s.logger.Infof("Starting Back end Serve in %s mode on port %s (SSL: %t)", s.cfg.Server.Mode, s.cfg.Server.Port,
+		s.cfg.Server.SSL)
+
+	if redirect != nil {
+		go func() {
+			errCh <- redirect.ListenAndServe()
+		}()
+
+		s.logger.Infof("Redirecting HTTP on port %s to HTTPS", s.cfg.Server.RedirectPort)
+	}
+
+	if grpcListener != nil {
+		go func() {
+			errCh <- grpcServer.Serve(grpcListener)
+		}()
+
+		s.logger.Infof("Starting gRPC server on port %s", s.cfg.GRPC.Port)
+	}
+
+	select {
+	case err := <-errCh:
+		stop()
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		_ = srv.Close()
+		if redirect != nil {
+			_ = redirect.Close()
+		}
+		jobsCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = jobs.Shutdown(jobsCtx)
+		domainEvents.Close()
+		return err
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// open event streams would hold the shutdown until its deadline
+	orderEvents.Close()
+
+	err := srv.Shutdown(shutdownCtx)
+	if redirect != nil {
+		_ = redirect.Shutdown(shutdownCtx)
+	}
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+	if err := jobs.Shutdown(shutdownCtx); err != nil {
+		s.logger.Warnf("background jobs cancelled: %v", err)
+	}
 
-	s.logger.Infof("Starting Back end Serve in %s mode on port %s", s.cfg.Server.Mode, s.cfg.Server.Port)
+	// events published by the last requests and jobs are still handled
+	domainEvents.Close()
 
-	return srv.ListenAndServe()
+	return err
 }
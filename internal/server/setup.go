@@ -1,9 +1,47 @@
 package server
 
 import (
+	"crypto/tls"
+	"os"
+	"strings"
+	"time"
+
+	addrHTTP "github.com/jofosuware/go/shopit/internal/addresses/delivery"
+	addrRepository "github.com/jofosuware/go/shopit/internal/addresses/repository"
+	addrUC "github.com/jofosuware/go/shopit/internal/addresses/usecase"
+	analyticsHTTP "github.com/jofosuware/go/shopit/internal/analytics/delivery"
+	analyticsRepository "github.com/jofosuware/go/shopit/internal/analytics/repository"
+	analyticsUC "github.com/jofosuware/go/shopit/internal/analytics/usecase"
+	assetsRepository "github.com/jofosuware/go/shopit/internal/assets/repository"
+	assetsUC "github.com/jofosuware/go/shopit/internal/assets/usecase"
 	authHTTP "github.com/jofosuware/go/shopit/internal/auth/delivery"
 	authRepository "github.com/jofosuware/go/shopit/internal/auth/repository"
 	authUC "github.com/jofosuware/go/shopit/internal/auth/usecase"
+	categoryHTTP "github.com/jofosuware/go/shopit/internal/categories/delivery"
+	categoryRepository "github.com/jofosuware/go/shopit/internal/categories/repository"
+	categoryUC "github.com/jofosuware/go/shopit/internal/categories/usecase"
+	checkoutHTTP "github.com/jofosuware/go/shopit/internal/checkout/delivery"
+	checkoutRepository "github.com/jofosuware/go/shopit/internal/checkout/repository"
+	checkoutUC "github.com/jofosuware/go/shopit/internal/checkout/usecase"
+	creditHTTP "github.com/jofosuware/go/shopit/internal/credit/delivery"
+	creditRepository "github.com/jofosuware/go/shopit/internal/credit/repository"
+	creditUC "github.com/jofosuware/go/shopit/internal/credit/usecase"
+	expHTTP "github.com/jofosuware/go/shopit/internal/experiments/delivery"
+	expRepository "github.com/jofosuware/go/shopit/internal/experiments/repository"
+	expUC "github.com/jofosuware/go/shopit/internal/experiments/usecase"
+	exportHTTP "github.com/jofosuware/go/shopit/internal/exports/delivery"
+	exportRepository "github.com/jofosuware/go/shopit/internal/exports/repository"
+	exportUC "github.com/jofosuware/go/shopit/internal/exports/usecase"
+	graphqlHTTP "github.com/jofosuware/go/shopit/internal/graphql/delivery"
+	grpcAPI "github.com/jofosuware/go/shopit/internal/grpc/delivery"
+	integrationHTTP "github.com/jofosuware/go/shopit/internal/integration/delivery"
+	integrationRepository "github.com/jofosuware/go/shopit/internal/integration/repository"
+	integrationUC "github.com/jofosuware/go/shopit/internal/integration/usecase"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/notifications"
+	notificationHTTP "github.com/jofosuware/go/shopit/internal/notifications/delivery"
+	notificationRepository "github.com/jofosuware/go/shopit/internal/notifications/repository"
+	notificationUC "github.com/jofosuware/go/shopit/internal/notifications/usecase"
 	ordHTTP "github.com/jofosuware/go/shopit/internal/orders/delivery"
 	ordRepository "github.com/jofosuware/go/shopit/internal/orders/repository"
 	ordUC "github.com/jofosuware/go/shopit/internal/orders/usecase"
@@ -11,44 +49,295 @@ import (
 	prodHTTP "github.com/jofosuware/go/shopit/internal/products/delivery"
 	prodRepository "github.com/jofosuware/go/shopit/internal/products/repository"
 	prodUC "github.com/jofosuware/go/shopit/internal/products/usecase"
+	promoHTTP "github.com/jofosuware/go/shopit/internal/promotions/delivery"
+	promoRepository "github.com/jofosuware/go/shopit/internal/promotions/repository"
+	promoUC "github.com/jofosuware/go/shopit/internal/promotions/usecase"
+	reportHTTP "github.com/jofosuware/go/shopit/internal/reports/delivery"
+	reportRepository "github.com/jofosuware/go/shopit/internal/reports/repository"
+	reportUC "github.com/jofosuware/go/shopit/internal/reports/usecase"
+	seedHTTP "github.com/jofosuware/go/shopit/internal/seed/delivery"
+	seedUC "github.com/jofosuware/go/shopit/internal/seed/usecase"
+	sellerHTTP "github.com/jofosuware/go/shopit/internal/sellers/delivery"
+	sellerRepository "github.com/jofosuware/go/shopit/internal/sellers/repository"
+	sellerUC "github.com/jofosuware/go/shopit/internal/sellers/usecase"
+	sysHTTP "github.com/jofosuware/go/shopit/internal/system/delivery"
+	ticketHTTP "github.com/jofosuware/go/shopit/internal/tickets/delivery"
+	ticketRepository "github.com/jofosuware/go/shopit/internal/tickets/repository"
+	ticketUC "github.com/jofosuware/go/shopit/internal/tickets/usecase"
+	uploadHTTP "github.com/jofosuware/go/shopit/internal/uploads/delivery"
+	uploadRepository "github.com/jofosuware/go/shopit/internal/uploads/repository"
+	uploadUC "github.com/jofosuware/go/shopit/internal/uploads/usecase"
+	wishlistHTTP "github.com/jofosuware/go/shopit/internal/wishlist/delivery"
+	wishlistRepository "github.com/jofosuware/go/shopit/internal/wishlist/repository"
+	wishlistUC "github.com/jofosuware/go/shopit/internal/wishlist/usecase"
+	"github.com/jofosuware/go/shopit/pkg/audit"
 	"github.com/jofosuware/go/shopit/pkg/bcrypt"
 	"github.com/jofosuware/go/shopit/pkg/card"
 	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/errreport"
+	"github.com/jofosuware/go/shopit/pkg/eta"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
+	"github.com/jofosuware/go/shopit/pkg/featureflag"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	"github.com/jofosuware/go/shopit/pkg/invoice"
+	"github.com/jofosuware/go/shopit/pkg/loadshed"
 	"github.com/jofosuware/go/shopit/pkg/mailer"
+	"github.com/jofosuware/go/shopit/pkg/moderation"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/outbox"
+	"github.com/jofosuware/go/shopit/pkg/payments"
+	"github.com/jofosuware/go/shopit/pkg/pseudonym"
+	"github.com/jofosuware/go/shopit/pkg/ratelimiter"
+	"github.com/jofosuware/go/shopit/pkg/realip"
+	"github.com/jofosuware/go/shopit/pkg/realtime"
+	"github.com/jofosuware/go/shopit/pkg/sheets"
+	"github.com/jofosuware/go/shopit/pkg/storage"
 	"github.com/jofosuware/go/shopit/pkg/token"
+	"github.com/jofosuware/go/shopit/pkg/urlsigner"
 	"github.com/jofosuware/go/shopit/pkg/utils"
 )
 
 // Setup instantiate handlers and repositories
 func (s *Serve) Setup() {
-	cld, err := cloudinary.NewCloudinary(s.cfg)
+	if err := money.SetCurrency(s.cfg.Server.Currency); err != nil {
+		s.logger.Fatal(err)
+	}
+	if err := money.Accept(s.cfg.Currencies.Accepted...); err != nil {
+		s.logger.Fatal(err)
+	}
+	if err := i18n.SetDefault(s.cfg.Server.Locale); err != nil {
+		s.logger.Fatal(err)
+	}
+	if s.cfg.Server.Messages != "" {
+		if err := i18n.LoadCatalogs(os.DirFS(s.cfg.Server.Messages)); err != nil {
+			s.logger.Fatal(err)
+		}
+	}
+
+	// Exchange rates, fetched and cached when a rates url is set
+	var rateProvider exchange.Provider = exchange.NewStatic(s.cfg.Currencies.Rates)
+	if s.cfg.Currencies.RatesURL != "" {
+		rateProvider = exchange.NewCache(exchange.NewHTTP(s.cfg.Currencies.RatesURL, 5*time.Second), s.cfg.Currencies.RatesTTL)
+	}
+	rates := exchange.New(rateProvider)
+
+	store, err := storage.NewUploader(s.cfg)
 	if err != nil {
 		s.logger.Fatal(err)
 	}
+	retry := s.cfg.Storage.Retry
+	cld := cloudinary.NewRetryUploader(store, cloudinary.RetryOptions{
+		Timeout:          retry.Timeout,
+		MaxAttempts:      retry.MaxAttempts,
+		BaseDelay:        retry.BaseDelay,
+		MaxDelay:         retry.MaxDelay,
+		FailureThreshold: retry.FailureThreshold,
+		OpenTimeout:      retry.OpenTimeout,
+	})
+
+	// Domain events, handled by the subscribers set up below
+	domainEvents = events.New(s.logger.With("module", "events"))
+	domainEvents.Subscribe(func(e events.Event) error {
+		s.logger.Infof("event %s", e.Name)
+		return nil
+	})
+
+	// Events written to the outbox, delivered by the outbox job
+	outboxDispatcher = outbox.NewDispatcher(s.DB, s.logger.With("module", "outbox"), outbox.Options{
+		BatchSize:   s.cfg.Outbox.BatchSize,
+		MaxAttempts: s.cfg.Outbox.MaxAttempts,
+		Retention:   s.cfg.Outbox.Retention,
+	})
 
 	// Auth setups
 	authRepo := authRepository.NewAuthRepository(s.DB)
-	authUseCase := authUC.NewAuthUC(cld, authRepo, token.NewToken(), bcrypt.NewEncrypt(), mailer.NewMail(s.cfg))
-	authHandlers = authHTTP.NewAuthHandlers(s.logger, authUseCase)
+	authUsecase := authUC.NewAuthUC(cld, authRepo, token.NewToken(), bcrypt.NewEncrypt(), mailer.NewMail(s.cfg),
+		s.cfg.Server.AccountDeletionGrace, authUC.PasswordReset{
+			Expiry: s.cfg.PasswordReset.Expiry,
+			URL:    s.cfg.PasswordReset.URL,
+		}, authUC.MagicLinks{
+			Expiry:  s.cfg.MagicLink.Expiry,
+			BaseURL: s.cfg.MagicLink.BaseURL,
+			Signer:  &urlsigner.Signer{Secret: []byte(s.cfg.Server.JwtSecretKey)},
+		}, authUC.AvatarPolicy{
+			MaxSize:        s.cfg.Avatar.MaxSize,
+			MaxAspectRatio: s.cfg.Avatar.MaxAspectRatio,
+			AllowedTypes:   s.cfg.Avatar.AllowedTypes,
+			Moderator:      moderation.New(s.cfg.Avatar),
+		}, domainEvents)
+	authUseCase = authUsecase
+	authHandlers = authHTTP.NewAuthHandlers(s.logger.With("module", "auth"), authUseCase)
+	outbox.Handle[models.PendingAvatar](outboxDispatcher, authUsecase.HandleAvatarPending, events.AvatarPending)
 
 	// UTILS
 	utils.Repo = authRepo
+	utils.IndentJSON = s.cfg.Server.PrettyJSON
+	utils.MaxJSONBody = s.cfg.BodyLimit.JSON
+	utils.MaxMultipartBody = s.cfg.BodyLimit.Multipart
+	utils.MaxUploadBody = s.cfg.BodyLimit.Upload
+	utils.MaxFormMemory = s.cfg.BodyLimit.Memory
+
+	// Category setups
+	categoryRepo := categoryRepository.NewCategoriesRepository(s.DB)
+	categoryHandlers = categoryHTTP.NewCategoryHandlers(s.logger.With("module", "categories"), categoryUC.NewCategoriesUC(categoryRepo))
 
 	// Product setups
-	prodRepo := prodRepository.NewProdRepository(s.DB)
-	prodUseCase := prodUC.NewProductsUC(cld, prodRepo)
-	prodHandlers = prodHTTP.NewProdHandlers(s.logger, prodUseCase)
+	prodRepo := prodRepository.NewRelatedCache(prodRepository.NewProdRepository(s.DB), s.cfg.Related.CacheTTL)
+	var sheet *sheets.Sheet
+	if s.cfg.CatalogSync.SheetID != "" {
+		sheet = sheets.New(s.cfg.CatalogSync.SheetID, s.cfg.CatalogSync.GID, s.cfg.CatalogSync.Timeout)
+	}
+	prodUseCase = prodUC.NewProductsUC(cld, prodRepo, categoryRepo, domainEvents, sheet)
+	prodHandlers = prodHTTP.NewProdHandlers(s.logger.With("module", "products"), prodUseCase, rates, s.cfg.Storefront)
+
+	estimator, err := eta.New(s.cfg.Delivery)
+	if err != nil {
+		s.logger.Fatal(err)
+	}
+
+	// Store credit setups
+	creditRepo := creditRepository.NewCreditRepository(s.DB)
+	creditHandlers = creditHTTP.NewCreditHandlers(s.logger.With("module", "credit"), creditUC.NewCreditUC(creditRepo))
+
+	// Checkout setups
+	checkoutUseCase = checkoutUC.NewCheckoutUC(checkoutRepository.NewCheckoutRepository(s.DB), creditRepo,
+		rates, s.cfg.Checkout.LockDuration)
+	checkoutHandlers = checkoutHTTP.NewCheckoutHandlers(s.logger.With("module", "checkout"), checkoutUseCase, estimator)
+
+	// Promotion setups
+	promoUseCase := promoUC.NewPromotionsUC(promoRepository.NewPromotionsRepository(s.DB))
+	promoHandlers = promoHTTP.NewPromotionHandlers(s.logger.With("module", "promotions"), promoUseCase)
+
+	// Address book setups
+	addrUseCase := addrUC.NewAddressesUC(addrRepository.NewAddressesRepository(s.DB))
+	addressHandlers = addrHTTP.NewAddressHandlers(s.logger.With("module", "addresses"), addrUseCase)
+
+	// Wishlist setups
+	wishlistHandlers = wishlistHTTP.NewWishlistHandlers(s.logger.With("module", "wishlist"),
+		wishlistUC.NewWishlistUC(wishlistRepository.NewWishlistRepository(s.DB)))
+
+	// Support ticket setups
+	ticketHandlers = ticketHTTP.NewTicketHandlers(s.logger.With("module", "tickets"),
+		ticketUC.NewTicketsUC(ticketRepository.NewTicketsRepository(s.DB)))
+
+	// Seller account setups
+	sellerHandlers = sellerHTTP.NewSellerHandlers(s.logger.With("module", "sellers"),
+		sellerUC.NewSellersUC(sellerRepository.NewSellersRepository(s.DB)))
+
+	// Direct upload setups
+	uploadHandlers = uploadHTTP.NewUploadHandlers(s.logger.With("module", "uploads"), uploadUC.NewUploadsUC(cld,
+		uploadRepository.NewUploadsRepository(s.DB), s.cfg.Storage.PresignExpiry, s.cfg.Storage.PresignMaxSize))
+
+	// Notification setups
+	notificationDefaults, err := notificationUC.Defaults(s.cfg.Notifications.Defaults)
+	if err != nil {
+		s.logger.Fatal(err)
+	}
+	notifyUC := notificationUC.NewNotificationsUC(notificationRepository.NewNotificationsRepository(s.DB),
+		notificationDefaults, map[string]notifications.Sender{
+			models.ChannelEmail: notificationUC.NewEmailSender(mailer.NewMail(s.cfg)),
+		}, s.cfg.Notifications.DigestLowStock)
+	notifyUseCase = notifyUC
+	notificationHandlers = notificationHTTP.NewNotificationHandlers(s.logger.With("module", "notifications"), notifyUC)
+	outbox.Handle[models.Order](outboxDispatcher, notifyUC.HandleOrderEvent, events.OrderCreated,
+		events.OrderStatusChanged)
+	outbox.Handle[models.PriceDrop](outboxDispatcher, notifyUC.HandlePriceDrop, events.ProductPriceDropped)
+	outbox.Handle[models.LowStockItem](outboxDispatcher, notifyUC.HandleLowStock, events.StockLow)
+	outbox.Handle[models.TicketUpdate](outboxDispatcher, notifyUC.HandleTicketUpdate, events.TicketUpdated)
+	outbox.Handle[models.PasswordChange](outboxDispatcher, notifyUC.HandlePasswordChanged, events.PasswordChanged)
+
+	// Card payments
+	cd := card.Card{
+		Secret:        s.cfg.Stripe.Secret,
+		Key:           s.cfg.Stripe.Key,
+		Currency:      strings.ToLower(money.DefaultCurrency),
+		ManualCapture: s.cfg.Stripe.ManualCapture,
+	}
+	providers := payments.New(s.cfg, &cd)
+	payProvider, err := providers.Get(strings.ToLower(s.cfg.Payments.Provider))
+	if err != nil {
+		s.logger.Fatal(err)
+	}
 
 	// Order setups
 	ordRepo := ordRepository.NewOrdersRepository(s.DB)
-	ordUseCase := ordUC.NewOrderUC(ordRepo)
-	ordHandlers = ordHTTP.NewOrderHandlers(s.logger, ordUseCase)
+	orderEvents = realtime.NewHub()
+	ordUseCase = ordUC.NewOrderUC(ordRepo, providers, s.cfg.Stripe.CaptureWindow, invoice.New(s.cfg.Invoices, cld),
+		pseudonym.New(s.cfg.Analytics.PseudonymKey), domainEvents, orderEvents)
+	domainEvents.Subscribe(ordUC.StatusPusher(orderEvents), events.OrderStatusChanged)
+	ordHandlers = ordHTTP.NewOrderHandlers(s.logger.With("module", "orders"), ordUseCase, checkoutUseCase, promoUseCase, prodUseCase,
+		addrUseCase, estimator, rates)
+
+	// Integration setups
+	integrationUseCase = integrationUC.NewIntegrationUC(integrationRepository.NewIntegrationRepository(s.DB), ordRepo,
+		integrationUC.WebhookOptions{
+			Timeout:     s.cfg.Webhooks.Timeout,
+			BatchSize:   s.cfg.Webhooks.BatchSize,
+			MaxAttempts: s.cfg.Webhooks.MaxAttempts,
+			Retention:   s.cfg.Webhooks.Retention,
+		})
+	integrationHandlers = integrationHTTP.NewIntegrationHandlers(s.logger.With("module", "integration"), integrationUseCase)
+	domainEvents.Subscribe(integrationUseCase.HandleEvent, integrationUC.WebhookEventNames()...)
+
+	// GraphQL setups
+	graphqlHandlers = graphqlHTTP.NewGraphQLHandlers(s.logger.With("module", "graphql"), prodUseCase, ordUseCase, authUseCase, rates, s.cfg.Storefront)
+
+	// gRPC setups
+	if s.cfg.GRPC.Port != "" {
+		var tlsConfig *tls.Config
+		if s.cfg.GRPC.CertFile != "" {
+			tlsConfig, err = grpcAPI.TLSConfig(s.cfg.GRPC.CertFile, s.cfg.GRPC.KeyFile, s.cfg.GRPC.ClientCAFile)
+			if err != nil {
+				s.logger.Fatal(err)
+			}
+		}
+		grpcServer = grpcAPI.NewServer(s.logger.With("module", "grpc"), prodUseCase, ordUseCase, integrationUseCase, tlsConfig)
+	}
 
 	// Payment setups
-	cd := card.Card{
-		Secret:   s.cfg.Stripe.Secret,
-		Key:      s.cfg.Stripe.Key,
-		Currency: "usd",
+	payHandlers = payHTTP.NewPaymentHandler(s.cfg, s.logger.With("module", "payment"), payProvider, providers, checkoutUseCase, ordUseCase)
+
+	// Asset maintenance setups
+	assetsUseCase = assetsUC.NewAssetsUC(cld, assetsRepository.NewAssetsRepository(s.DB), s.cfg.Storage.OrphanGracePeriod)
+
+	// Fake data generator, for staging and demo environments only
+	if s.cfg.Seed.Enabled {
+		seedHandlers = seedHTTP.NewSeedHandlers(s.logger.With("module", "seed"), seedUC.NewSeedUC(authRepo, categoryRepo, prodRepo, ordRepo,
+			bcrypt.NewEncrypt()))
+	}
+
+	// Feature preview cohorts
+	features = featureflag.New(s.cfg)
+
+	// Experiment setups
+	expUseCase := expUC.NewExperimentsUC(expRepository.NewExperimentsRepository(s.DB))
+	features.SetRecorder(expUseCase)
+	expHandlers = expHTTP.NewExperimentHandlers(s.logger.With("module", "experiments"), expUseCase)
+
+	// Data export audit setups
+	exportUseCase := exportUC.NewExportsUC(exportRepository.NewExportsRepository(s.DB))
+	auditor = audit.New(exportUseCase, s.logger.With("module", "audit"))
+	exportHandlers = exportHTTP.NewExportHandlers(s.logger.With("module", "exports"), exportUseCase)
+
+	// Report setups
+	reportHandlers = reportHTTP.NewReportHandlers(s.logger.With("module", "reports"),
+		reportUC.NewReportsUC(reportRepository.NewReportsRepository(s.DB)))
+
+	// Analytics setups
+	analyticsHandlers = analyticsHTTP.NewAnalyticsHandlers(s.logger.With("module", "analytics"),
+		analyticsUC.NewAnalyticsUC(analyticsRepository.NewAnalyticsRepository(s.DB)))
+
+	// System setups
+	resolver, err = realip.New(s.cfg.Server.TrustedProxies)
+	if err != nil {
+		s.logger.Fatal(err)
+	}
+	limiter = ratelimiter.NewRateLimiter(rateLimit(s.cfg.RateLimit))
+	shedder = loadshed.New(s.cfg.LoadShedding, s.DB.Stats)
+	reporter, err = errreport.New(s.cfg)
+	if err != nil {
+		s.logger.Fatal(err)
 	}
-	payHandlers = payHTTP.NewPaymentHandler(s.cfg, s.logger, &cd)
+	sysHandlers = sysHTTP.NewSystemHandlers(s.logger.With("module", "system"), limiter, shedder, mailer.NewMail(s.cfg))
 }
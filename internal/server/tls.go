@@ -0,0 +1,86 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// certManager returns the manager of the Let's Encrypt certificates of the server,
+// nil unless it serves HTTPS without a certificate file.
+func (s *Serve) certManager() *autocert.Manager {
+	if !s.cfg.Server.SSL || s.cfg.Server.CertFile != "" {
+		return nil
+	}
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(s.cfg.Server.AutocertDomains...),
+		Cache:      autocert.DirCache(s.cfg.Server.AutocertCacheDir),
+		Email:      s.cfg.Server.AutocertEmail,
+	}
+}
+
+// serve runs srv until it is closed, over TLS when the server has SSL: with the
+// certificates of manager when there is one, or else of the certificate file.
+func (s *Serve) serve(srv *http.Server, manager *autocert.Manager) error {
+	switch {
+	case !s.cfg.Server.SSL:
+		return srv.ListenAndServe()
+	case manager != nil:
+		srv.TLSConfig = manager.TLSConfig()
+		return srv.ListenAndServeTLS("", "")
+	default:
+		return srv.ListenAndServeTLS(s.cfg.Server.CertFile, s.cfg.Server.KeyFile)
+	}
+}
+
+// redirectServer returns the plain HTTP server of the redirect port, sending requests
+// to the same URL over HTTPS and answering the challenges of manager if any. It
+// returns nil when the server has no SSL or redirect port.
+func (s *Serve) redirectServer(manager *autocert.Manager) *http.Server {
+	if !s.cfg.Server.SSL || s.cfg.Server.RedirectPort == "" {
+		return nil
+	}
+
+	var h http.Handler = redirectToHTTPS(s.cfg.Server.Port)
+	if manager != nil {
+		h = manager.HTTPHandler(h)
+	}
+
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%s", s.cfg.Server.RedirectPort),
+		Handler:           h,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// redirectToHTTPS redirects requests permanently to their URL over HTTPS on port.
+func redirectToHTTPS(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+// hsts tells browsers to reach the API over HTTPS only for maxAge, subdomains included.
+func hsts(maxAge time.Duration) func(http.Handler) http.Handler {
+	value := fmt.Sprintf("max-age=%d; includeSubDomains", int64(maxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Strict-Transport-Security", value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
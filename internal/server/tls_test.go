@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirectToHTTPS(t *testing.T) {
+	tests := []struct {
+		name, port, target, want string
+	}{
+		{"Default https port", "443", "http://shop.example.com/api/v1/products?page=2",
+			"https://shop.example.com/api/v1/products?page=2"},
+		{"Other https port", "8443", "http://shop.example.com:8080/api/v1/products",
+			"https://shop.example.com:8443/api/v1/products"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			redirectToHTTPS(tc.port).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+			assert.Equal(t, tc.want, rr.Header().Get("Location"))
+		})
+	}
+}
+
+func TestHSTS(t *testing.T) {
+	rr := httptest.NewRecorder()
+	hsts(24*time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, "max-age=86400; includeSubDomains", rr.Header().Get("Strict-Transport-Security"))
+}
+
+func TestCertManager(t *testing.T) {
+	serve := func(c config.ServerConfig) *Serve {
+		return &Serve{cfg: &config.Config{Server: c}}
+	}
+
+	assert.Nil(t, serve(config.ServerConfig{}).certManager())
+	assert.Nil(t, serve(config.ServerConfig{SSL: true, CertFile: "cert.pem", KeyFile: "key.pem"}).certManager())
+	assert.NotNil(t, serve(config.ServerConfig{SSL: true, AutocertDomains: []string{"api.example.com"}}).certManager())
+
+	assert.Nil(t, serve(config.ServerConfig{SSL: true, CertFile: "cert.pem", KeyFile: "key.pem"}).redirectServer(nil))
+	assert.NotNil(t, serve(config.ServerConfig{SSL: true, CertFile: "cert.pem", KeyFile: "key.pem",
+		RedirectPort: "80"}).redirectServer(nil))
+}
@@ -0,0 +1,216 @@
+// Package delivery provides HTTP handlers for system administration endpoints.
+//
+// It exposes operational state, such as the rate limiter's visitors and the load the
+// API sheds requests at, to admins and lets them reset the rate limits. Admins can also preview the email templates rendered
+// with sample data and send them to themselves while working on them.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/loadshed"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/mailer"
+	"github.com/jofosuware/go/shopit/pkg/ratelimiter"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// SystemHandlers provides HTTP handler methods for system endpoints.
+type SystemHandlers struct {
+	logger  logger.Logger
+	limiter *ratelimiter.RateLimiter
+	shedder *loadshed.Shedder
+	mail    mailer.Mailer
+}
+
+// NewSystemHandlers returns a new SystemHandlers.
+func NewSystemHandlers(logger logger.Logger, limiter *ratelimiter.RateLimiter, shedder *loadshed.Shedder, mail mailer.Mailer) *SystemHandlers {
+	return &SystemHandlers{
+		logger:  logger,
+		limiter: limiter,
+		shedder: shedder,
+		mail:    mail,
+	}
+}
+
+// GetRateLimits returns the visitors currently tracked by the rate limiter (admin).
+// Endpoint: GET /api/v1/admin/system/ratelimits
+func (h *SystemHandlers) GetRateLimits(w http.ResponseWriter, r *http.Request) {
+	visitors := h.limiter.Snapshot()
+
+	blocked := 0
+	for _, v := range visitors {
+		if v.Blocked > 0 {
+			blocked++
+		}
+	}
+
+	jr := struct {
+		Success         bool                       `json:"success"`
+		VisitorCount    int                        `json:"visitorCount"`
+		BlockedVisitors int                        `json:"blockedVisitors"`
+		Visitors        []ratelimiter.VisitorState `json:"visitors"`
+	}{
+		Success:         true,
+		VisitorCount:    len(visitors),
+		BlockedVisitors: blocked,
+		Visitors:        visitors,
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// ClearRateLimits resets the rate limit of one visitor, or of all visitors with all=true (admin).
+// Visitors are keyed by client IP only, so a user is unblocked through the IP they come from.
+// Endpoint: DELETE /api/v1/admin/system/ratelimits
+// Query params: key (client IP) or all=true, one of them.
+func (h *SystemHandlers) ClearRateLimits(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	all := r.URL.Query().Get("all") == "true"
+
+	v := validator.New()
+	v.CheckCode(key != "" || all, "key", validator.CodeRequired, "key or all=true must be given")
+	v.Check(key == "" || !all, "all", "all cannot be combined with key")
+	v.CheckCode(key == "" || net.ParseIP(key) != nil, "key", validator.CodeInvalid, "key must be a client IP address")
+	if !v.Valid() {
+		utils.FailedValidation(w, r, v)
+		return
+	}
+
+	var cleared int
+	if all {
+		cleared = h.limiter.ClearAll()
+	} else if h.limiter.Clear(key) {
+		cleared = 1
+	}
+
+	h.logger.Infof("rate limits cleared: key=%q cleared=%d", key, cleared)
+
+	jr := struct {
+		Success bool `json:"success"`
+		Cleared int  `json:"cleared"`
+	}{
+		Success: true,
+		Cleared: cleared,
+	}
+
+	if err := utils.WriteJSON(w, http.StatusOK, jr); err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error writing json: %w", err))
+		return
+	}
+}
+
+// GetLoad returns the requests in flight, the use of the database pool and how many
+// low priority requests were shed, with the thresholds they are shed at (admin).
+// Endpoint: GET /api/v1/admin/system/load
+func (h *SystemHandlers) GetLoad(w http.ResponseWriter, r *http.Request) {
+	jr := struct {
+		Success bool           `json:"success"`
+		Load    loadshed.Stats `json:"load"`
+	}{
+		Success: true,
+		Load:    h.shedder.Stats(),
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// GetEmailTemplates lists the email templates with their sample data (admin).
+// Endpoint: GET /api/v1/admin/system/emails
+func (h *SystemHandlers) GetEmailTemplates(w http.ResponseWriter, r *http.Request) {
+	type template struct {
+		Name       string            `json:"name"`
+		SampleData map[string]string `json:"sampleData"`
+	}
+
+	templates := []template{}
+	for _, name := range mailer.Templates() {
+		templates = append(templates, template{Name: name, SampleData: mailer.SampleData(name)})
+	}
+
+	jr := struct {
+		Success   bool       `json:"success"`
+		Templates []template `json:"templates"`
+	}{
+		Success:   true,
+		Templates: templates,
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
+
+// PreviewEmail renders an email template with sample data, as HTML or plain text, in a
+// locale or the shop default (admin).
+// Endpoint: GET /api/v1/admin/system/emails/{template}?format=html|plain&locale=<code>
+func (h *SystemHandlers) PreviewEmail(w http.ResponseWriter, r *http.Request) {
+	tmpl := chi.URLParam(r, "template")
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "html" && format != "plain" {
+		_ = utils.BadRequest(w, r, errors.New("format must be html or plain"))
+		h.logger.Errorf("invalid preview format: %q", format)
+		return
+	}
+
+	html, plain, err := mailer.Render(tmpl, r.URL.Query().Get("locale"), mailer.SampleData(tmpl))
+	if err != nil {
+		if errors.Is(err, mailer.ErrUnknownTemplate) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error rendering email: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error rendering email: %w", err))
+		return
+	}
+
+	if format == "plain" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(plain))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(html))
+}
+
+// SendTestEmail sends an email template rendered with sample data to an address, in a
+// locale or the shop default (admin).
+// Endpoint: POST /api/v1/admin/system/emails/{template}/test
+// Expects JSON body: {"to": <email>, "locale": <code>}.
+func (h *SystemHandlers) SendTestEmail(w http.ResponseWriter, r *http.Request) {
+	tmpl := chi.URLParam(r, "template")
+
+	var payload testEmailRequest
+	if !utils.Bind(w, r, h.logger, &payload) {
+		return
+	}
+
+	subject := fmt.Sprintf("[Test] %s", tmpl)
+	if err := h.mail.SendMail(mailer.DefaultFrom, payload.To, subject, tmpl, payload.Locale, mailer.SampleData(tmpl)); err != nil {
+		if errors.Is(err, mailer.ErrUnknownTemplate) {
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error sending test email: %v", err)
+			return
+		}
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error sending test email: %w", err))
+		return
+	}
+
+	h.logger.Infof("test email sent: template=%q to=%q", tmpl, payload.To)
+
+	jr := models.Response{
+		Success: true,
+		Message: fmt.Sprintf("%s sent to %s", tmpl, payload.To),
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, jr)
+}
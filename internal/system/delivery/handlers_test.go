@@ -0,0 +1,201 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/system/delivery"
+	"github.com/jofosuware/go/shopit/pkg/loadshed"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/mailer"
+	mockMailer "github.com/jofosuware/go/shopit/pkg/mailer/mocks"
+	"github.com/jofosuware/go/shopit/pkg/ratelimiter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRateLimits(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	rl := ratelimiter.NewRateLimiter(1, 1)
+	rl.AddVisitor("10.0.0.1")
+
+	h := delivery.NewSystemHandlers(logger, rl, nil, mockMailer.NewMailer(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/ratelimits", nil)
+	rr := httptest.NewRecorder()
+
+	h.GetRateLimits(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var res struct {
+		VisitorCount int `json:"visitorCount"`
+		Visitors     []struct {
+			Key string `json:"key"`
+		} `json:"visitors"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, 1, res.VisitorCount)
+	assert.Equal(t, "10.0.0.1", res.Visitors[0].Key)
+}
+
+func TestGetLoad(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	db := sql.DBStats{MaxOpenConnections: 10, InUse: 4}
+	shedder := loadshed.New(config.LoadShedding{MaxInFlight: 100, DBSaturation: 90}, func() sql.DBStats { return db })
+
+	h := delivery.NewSystemHandlers(logger, ratelimiter.NewRateLimiter(1, 1), shedder, mockMailer.NewMailer(t))
+
+	rr := httptest.NewRecorder()
+	shedder.Middleware(http.HandlerFunc(h.GetLoad)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/load", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var res struct {
+		Load loadshed.Stats `json:"load"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, loadshed.Stats{
+		InFlight:     1,
+		PeakInFlight: 1,
+		MaxInFlight:  100,
+		DBInUse:      4,
+		DBMaxOpen:    10,
+		DBSaturation: 90,
+	}, res.Load)
+}
+
+func TestClearRateLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		wantCleared int
+		wantLeft    int
+	}{
+		{name: "Clear a single visitor", url: "/ratelimits?key=10.0.0.1", wantCleared: 1, wantLeft: 1},
+		{name: "Clear an unknown visitor", url: "/ratelimits?key=10.0.0.9", wantCleared: 0, wantLeft: 2},
+		{name: "Clear all visitors", url: "/ratelimits?all=true", wantCleared: 2, wantLeft: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := mockLogger.NewLogger(t)
+			rl := ratelimiter.NewRateLimiter(1, 1)
+			rl.AddVisitor("10.0.0.1")
+			rl.AddVisitor("10.0.0.2")
+
+			h := delivery.NewSystemHandlers(logger, rl, nil, mockMailer.NewMailer(t))
+
+			logger.On("Infof", mock.Anything, mock.Anything, mock.Anything).Once()
+
+			req := httptest.NewRequest(http.MethodDelete, tt.url, nil)
+			rr := httptest.NewRecorder()
+
+			h.ClearRateLimits(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+
+			var res struct {
+				Cleared int `json:"cleared"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			assert.Equal(t, tt.wantCleared, res.Cleared)
+			assert.Len(t, rl.Snapshot(), tt.wantLeft)
+		})
+	}
+
+	t.Run("A key or all is required", func(t *testing.T) {
+		for _, url := range []string{"/ratelimits", "/ratelimits?all=false", "/ratelimits?key=10.0.0.1&all=true",
+			"/ratelimits?key=user-42"} {
+			rl := ratelimiter.NewRateLimiter(1, 1)
+			rl.AddVisitor("10.0.0.1")
+
+			h := delivery.NewSystemHandlers(mockLogger.NewLogger(t), rl, nil, mockMailer.NewMailer(t))
+
+			rr := httptest.NewRecorder()
+			h.ClearRateLimits(rr, httptest.NewRequest(http.MethodDelete, url, nil))
+
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, url)
+			assert.Len(t, rl.Snapshot(), 1, url)
+		}
+	})
+}
+
+func TestPreviewEmail(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	h := delivery.NewSystemHandlers(logger, ratelimiter.NewRateLimiter(1, 1), nil, mockMailer.NewMailer(t))
+
+	preview := func(tmpl, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/emails/"+tmpl+query, nil)
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("template", tmpl)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+
+		rr := httptest.NewRecorder()
+		h.PreviewEmail(rr, req)
+		return rr
+	}
+
+	t.Run("HTML preview", func(t *testing.T) {
+		rr := preview("password-reset", "")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, rr.Body.String(), "https://shop.example.com/password/reset/SAMPLETOKEN")
+	})
+
+	t.Run("Plain text preview", func(t *testing.T) {
+		rr := preview("account-created", "?format=plain")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Type"), "text/plain")
+		assert.NotContains(t, rr.Body.String(), "<html>")
+	})
+
+	t.Run("Unknown template", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := preview("welcome", "")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestSendTestEmail(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	mail := mockMailer.NewMailer(t)
+	h := delivery.NewSystemHandlers(logger, ratelimiter.NewRateLimiter(1, 1), nil, mail)
+
+	send := func(tmpl, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/emails/"+tmpl+"/test", bytes.NewBufferString(body))
+		rCtx := chi.NewRouteContext()
+		rCtx.URLParams.Add("template", tmpl)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rCtx))
+
+		rr := httptest.NewRecorder()
+		h.SendTestEmail(rr, req)
+		return rr
+	}
+
+	t.Run("Test email is sent", func(t *testing.T) {
+		mail.On("SendMail", mailer.DefaultFrom, "admin@example.com", "[Test] password-reset", "password-reset", "",
+			mailer.SampleData("password-reset")).Return(nil).Once()
+		logger.On("Infof", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := send("password-reset", `{"to":"admin@example.com"}`)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Invalid address", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := send("password-reset", `{"to":"admin"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
@@ -0,0 +1,8 @@
+package delivery
+
+// testEmailRequest is the body of SendTestEmail. An empty locale renders the
+// template in the shop default.
+type testEmailRequest struct {
+	To     string `json:"to" validate:"required,email"`
+	Locale string `json:"locale"`
+}
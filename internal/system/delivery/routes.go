@@ -0,0 +1,32 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// SystemRouter returns a chi.Router with admin-only system routes.
+//
+//   - GET    /ratelimits  → List rate limiter visitors
+//   - DELETE /ratelimits  → Clear one (?key=) or all visitors
+//   - GET    /load        → Requests in flight, database pool use and requests shed
+//   - GET    /emails                 → List email templates
+//   - GET    /emails/{template}      → Preview a template with sample data (?format=html|plain)
+//   - POST   /emails/{template}/test → Send a template with sample data to an address
+func (h *SystemHandlers) SystemRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+	mux.Use(utils.IsAdmin)
+
+	mux.Get("/ratelimits", h.GetRateLimits)
+	mux.Delete("/ratelimits", h.ClearRateLimits)
+	mux.Get("/load", h.GetLoad)
+	mux.Get("/emails", h.GetEmailTemplates)
+	mux.Get("/emails/{template}", h.PreviewEmail)
+	mux.Post("/emails/{template}/test", h.SendTestEmail)
+
+	return mux
+}
@@ -0,0 +1,223 @@
+// Package delivery provides HTTP handlers for support tickets.
+//
+// Customers open tickets, optionally about one of their orders, and follow the
+// conversation with the support team; admins list the tickets by status, answer them
+// and close them.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/tickets"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// TicketHandlers provides HTTP handler methods for support ticket endpoints.
+type TicketHandlers struct {
+	logger   logger.Logger
+	ticketUC tickets.TicketUC
+}
+
+// NewTicketHandlers returns a new TicketHandlers.
+func NewTicketHandlers(logger logger.Logger, ticketUC tickets.TicketUC) *TicketHandlers {
+	return &TicketHandlers{
+		logger:   logger,
+		ticketUC: ticketUC,
+	}
+}
+
+type ticketResponse struct {
+	Success bool           `json:"success"`
+	Ticket  *models.Ticket `json:"ticket"`
+}
+
+type ticketsResponse struct {
+	Success bool            `json:"success"`
+	Tickets []models.Ticket `json:"tickets"`
+}
+
+// GetTickets returns the tickets of the current user, last updated first.
+// Endpoint: GET /api/v1/tickets
+func (h *TicketHandlers) GetTickets(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.ticketUC.GetTickets(user.ID)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting tickets: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, ticketsResponse{Success: true, Tickets: list})
+}
+
+// OpenTicket opens a ticket of the current user. The admins are emailed its message.
+// Endpoint: POST /api/v1/tickets
+// Expects JSON body: {"subject": <string>, "message": <string>, "orderId": <uuid of an
+// order of the user, optional>}.
+func (h *TicketHandlers) OpenTicket(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	var req ticketRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	t := req.ticket()
+	t.UserID = user.ID
+
+	opened, err := h.ticketUC.OpenTicket(t, strings.TrimSpace(req.Message))
+	if err != nil {
+		h.writeError(w, r, "error opening ticket", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusCreated, ticketResponse{Success: true, Ticket: opened})
+}
+
+// GetTicket returns a ticket with its messages, to the user who opened it or an admin.
+// Endpoint: GET /api/v1/tickets/{id}
+func (h *TicketHandlers) GetTicket(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.id(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := h.ticketUC.GetTicket(id, user)
+	if err != nil {
+		h.writeError(w, r, "error getting ticket", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, ticketResponse{Success: true, Ticket: t})
+}
+
+// Reply adds a message to a ticket, from the user who opened it or an admin. A reply
+// of the customer opens the ticket again and is emailed to the admins; one of an admin
+// answers it and the customer is notified.
+// Endpoint: POST /api/v1/tickets/{id}/messages
+// Expects JSON body: {"message": <string>}.
+func (h *TicketHandlers) Reply(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.id(w, r)
+	if !ok {
+		return
+	}
+
+	var req messageRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	t, err := h.ticketUC.Reply(id, user, strings.TrimSpace(req.Message))
+	if err != nil {
+		h.writeError(w, r, "error replying to ticket", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, ticketResponse{Success: true, Ticket: t})
+}
+
+// GetAllTickets returns the latest tickets, last updated first (admin).
+// Endpoint: GET /api/v1/tickets/admin?status=<open|answered|closed>&limit=<int>
+// Every status is listed without one; limit defaults to 50 and is capped at 200.
+func (h *TicketHandlers) GetAllTickets(w http.ResponseWriter, r *http.Request) {
+	var limit int
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			_ = utils.BadRequest(w, r, errors.New("limit must be a positive number"))
+			h.logger.Errorf("error parsing limit: %v", l)
+			return
+		}
+		limit = n
+	}
+
+	list, err := h.ticketUC.GetAllTickets(r.URL.Query().Get("status"), limit)
+	if err != nil {
+		h.writeError(w, r, "error getting tickets", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, ticketsResponse{Success: true, Tickets: list})
+}
+
+// SetStatus sets the status of a ticket; the customer is notified of it (admin).
+// Endpoint: PUT /api/v1/tickets/admin/{id}/status
+// Expects JSON body: {"status": <open|answered|closed>}.
+func (h *TicketHandlers) SetStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.id(w, r)
+	if !ok {
+		return
+	}
+
+	var req statusRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	t, err := h.ticketUC.SetStatus(id, req.Status)
+	if err != nil {
+		h.writeError(w, r, "error setting ticket status", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, ticketResponse{Success: true, Ticket: t})
+}
+
+// user returns the signed in user. It writes the response and returns false when
+// there is none.
+func (h *TicketHandlers) user(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return nil, false
+	}
+
+	return user, true
+}
+
+// id returns the ticket id of the URL. It writes the response and returns false when
+// it is not an id.
+func (h *TicketHandlers) id(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing id: %v", err)
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+func (h *TicketHandlers) writeError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if tickets.IsClientError(err) {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("%s: %v", msg, err)
+		return
+	}
+	_ = utils.ServerError(w, r, h.logger, fmt.Errorf("%s: %w", msg, err))
+}
@@ -0,0 +1,161 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/tickets"
+	"github.com/jofosuware/go/shopit/internal/tickets/delivery"
+	"github.com/jofosuware/go/shopit/internal/tickets/mocks"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func request(user *models.User, method, id, body string) *http.Request {
+	req := httptest.NewRequest(method, "/tickets/"+id, bytes.NewBufferString(body))
+	rCtx := chi.NewRouteContext()
+	rCtx.URLParams.Add("id", id)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+	return req.WithContext(context.WithValue(ctx, utils.UserContextKey, user))
+}
+
+func TestOpenTicket(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	ticketUC := mocks.NewTicketUC(t)
+	h := delivery.NewTicketHandlers(logger, ticketUC)
+	user := &models.User{ID: uuid.New()}
+	orderID := uuid.New()
+
+	call := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.OpenTicket(rr, request(user, http.MethodPost, "", body))
+		return rr
+	}
+
+	t.Run("Ticket is opened", func(t *testing.T) {
+		ticketUC.On("OpenTicket", models.Ticket{UserID: user.ID, OrderID: uuid.NullUUID{UUID: orderID, Valid: true},
+			Subject: "Missing scarf"}, "No scarf.").
+			Return(func(t models.Ticket, _ string) (*models.Ticket, error) {
+				t.ID = uuid.New()
+				t.Status = models.TicketOpen
+				return &t, nil
+			}).Once()
+
+		rr := call(`{"subject": " Missing scarf ", "message": "No scarf. ", "orderId": "` + orderID.String() + `"}`)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		var res struct {
+			Ticket models.Ticket `json:"ticket"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, models.TicketOpen, res.Ticket.Status)
+	})
+
+	t.Run("Missing message", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, call(`{"subject": "Missing scarf", "message": " "}`).Code)
+	})
+
+	t.Run("Order of another user", func(t *testing.T) {
+		ticketUC.On("OpenTicket", mock.Anything, "No scarf.").Return(nil, tickets.ErrOrderNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := call(`{"subject": "Missing scarf", "message": "No scarf.", "orderId": "` + orderID.String() + `"}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestReply(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	ticketUC := mocks.NewTicketUC(t)
+	h := delivery.NewTicketHandlers(logger, ticketUC)
+	user := &models.User{ID: uuid.New()}
+	id := uuid.New()
+
+	call := func(id, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.Reply(rr, request(user, http.MethodPost, id, body))
+		return rr
+	}
+
+	t.Run("Reply is added", func(t *testing.T) {
+		ticketUC.On("Reply", id, user, "Any news?").Return(&models.Ticket{ID: id, Status: models.TicketOpen}, nil).Once()
+
+		assert.Equal(t, http.StatusOK, call(id.String(), `{"message": "Any news?"}`).Code)
+	})
+
+	t.Run("Ticket not found", func(t *testing.T) {
+		ticketUC.On("Reply", id, user, "Any news?").Return(nil, tickets.ErrTicketNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call(id.String(), `{"message": "Any news?"}`).Code)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusBadRequest, call("1", `{"message": "Any news?"}`).Code)
+	})
+}
+
+func TestGetAllTickets(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	ticketUC := mocks.NewTicketUC(t)
+	h := delivery.NewTicketHandlers(logger, ticketUC)
+
+	t.Run("Tickets are listed by status", func(t *testing.T) {
+		ticketUC.On("GetAllTickets", models.TicketOpen, 10).Return([]models.Ticket{{Subject: "Missing scarf"}}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.GetAllTickets(rr, httptest.NewRequest(http.MethodGet, "/tickets/admin?status=open&limit=10", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Missing scarf")
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.GetAllTickets(rr, httptest.NewRequest(http.MethodGet, "/tickets/admin?limit=0", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestSetStatus(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	ticketUC := mocks.NewTicketUC(t)
+	h := delivery.NewTicketHandlers(logger, ticketUC)
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	id := uuid.New()
+
+	call := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.SetStatus(rr, request(admin, http.MethodPut, id.String(), body))
+		return rr
+	}
+
+	t.Run("Ticket is closed", func(t *testing.T) {
+		ticketUC.On("SetStatus", id, models.TicketClosed).Return(&models.Ticket{ID: id, Status: models.TicketClosed}, nil).Once()
+
+		assert.Equal(t, http.StatusOK, call(`{"status": "closed"}`).Code)
+	})
+
+	t.Run("Unknown status", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, call(`{"status": "pending"}`).Code)
+	})
+}
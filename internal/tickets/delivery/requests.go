@@ -0,0 +1,36 @@
+package delivery
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// ticketRequest is the body of the endpoint opening a ticket. The lengths are the ones
+// of the tickets and ticket_messages tables.
+type ticketRequest struct {
+	Subject string `json:"subject" validate:"notblank,max=150"`
+	Message string `json:"message" validate:"notblank,max=5000"`
+	OrderID string `json:"orderId" validate:"omitempty,uuid"`
+}
+
+// ticket returns the ticket of the request, trimmed, without its user.
+func (req *ticketRequest) ticket() models.Ticket {
+	t := models.Ticket{Subject: strings.TrimSpace(req.Subject)}
+	if req.OrderID != "" {
+		t.OrderID = uuid.NullUUID{UUID: uuid.MustParse(req.OrderID), Valid: true}
+	}
+
+	return t
+}
+
+// messageRequest is the body of the endpoint replying to a ticket.
+type messageRequest struct {
+	Message string `json:"message" validate:"notblank,max=5000"`
+}
+
+// statusRequest is the body of the endpoint setting the status of a ticket.
+type statusRequest struct {
+	Status string `json:"status" validate:"required,oneof=open answered closed"`
+}
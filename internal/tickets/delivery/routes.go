@@ -0,0 +1,31 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// TicketRouter returns a chi.Router with the support ticket routes.
+//
+//   - GET  /                   → List the user's tickets, last updated first
+//   - POST /                   → Open a ticket
+//   - GET  /{id}               → Get a ticket with its messages (owner or admin)
+//   - POST /{id}/messages      → Reply to a ticket (owner or admin)
+//   - GET  /admin              → List the latest tickets, by status (admin)
+//   - PUT  /admin/{id}/status  → Set the status of a ticket (admin)
+func (h *TicketHandlers) TicketRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+
+	mux.Get("/", h.GetTickets)
+	mux.Post("/", h.OpenTicket)
+	mux.Get("/{id}", h.GetTicket)
+	mux.Post("/{id}/messages", h.Reply)
+	mux.With(utils.IsAdmin).Get("/admin", h.GetAllTickets)
+	mux.With(utils.IsAdmin).Put("/admin/{id}/status", h.SetStatus)
+
+	return mux
+}
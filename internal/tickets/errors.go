@@ -0,0 +1,19 @@
+package tickets
+
+import "errors"
+
+var (
+	// ErrTicketNotFound is returned when a ticket does not exist or belongs to another user.
+	ErrTicketNotFound = errors.New("ticket not found")
+
+	// ErrOrderNotFound is returned when a ticket is opened about an order the user did not place.
+	ErrOrderNotFound = errors.New("order not found")
+
+	// ErrInvalidStatus is returned when a ticket is given a status that is not one of models.TicketStatuses.
+	ErrInvalidStatus = errors.New("status must be open, answered or closed")
+)
+
+// IsClientError reports whether err is caused by the request rather than the server.
+func IsClientError(err error) bool {
+	return errors.Is(err, ErrTicketNotFound) || errors.Is(err, ErrOrderNotFound) || errors.Is(err, ErrInvalidStatus)
+}
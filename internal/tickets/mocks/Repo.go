@@ -0,0 +1,243 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// Repo is an autogenerated mock type for the Repo type
+type Repo struct {
+	mock.Mock
+}
+
+// FetchMessages provides a mock function with given fields: ticketID
+func (_m *Repo) FetchMessages(ticketID uuid.UUID) ([]models.TicketMessage, error) {
+	ret := _m.Called(ticketID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchMessages")
+	}
+
+	var r0 []models.TicketMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.TicketMessage, error)); ok {
+		return rf(ticketID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.TicketMessage); ok {
+		r0 = rf(ticketID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TicketMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(ticketID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchTicket provides a mock function with given fields: id
+func (_m *Repo) FetchTicket(id uuid.UUID) (*models.Ticket, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchTicket")
+	}
+
+	var r0 *models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Ticket, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Ticket); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchTickets provides a mock function with given fields: status, limit
+func (_m *Repo) FetchTickets(status string, limit int) ([]models.Ticket, error) {
+	ret := _m.Called(status, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchTickets")
+	}
+
+	var r0 []models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]models.Ticket, error)); ok {
+		return rf(status, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []models.Ticket); ok {
+		r0 = rf(status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchUserTickets provides a mock function with given fields: userID
+func (_m *Repo) FetchUserTickets(userID uuid.UUID) ([]models.Ticket, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchUserTickets")
+	}
+
+	var r0 []models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.Ticket, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.Ticket); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertMessage provides a mock function with given fields: m, status
+func (_m *Repo) InsertMessage(m models.TicketMessage, status string) error {
+	ret := _m.Called(m, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.TicketMessage, string) error); ok {
+		r0 = rf(m, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertTicket provides a mock function with given fields: t, m
+func (_m *Repo) InsertTicket(t models.Ticket, m models.TicketMessage) (*models.Ticket, error) {
+	ret := _m.Called(t, m)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertTicket")
+	}
+
+	var r0 *models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Ticket, models.TicketMessage) (*models.Ticket, error)); ok {
+		return rf(t, m)
+	}
+	if rf, ok := ret.Get(0).(func(models.Ticket, models.TicketMessage) *models.Ticket); ok {
+		r0 = rf(t, m)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Ticket, models.TicketMessage) error); ok {
+		r1 = rf(t, m)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OwnsOrder provides a mock function with given fields: userID, orderID
+func (_m *Repo) OwnsOrder(userID uuid.UUID, orderID uuid.UUID) (bool, error) {
+	ret := _m.Called(userID, orderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OwnsOrder")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) (bool, error)); ok {
+		return rf(userID, orderID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) bool); ok {
+		r0 = rf(userID, orderID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(userID, orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateStatus provides a mock function with given fields: id, status
+func (_m *Repo) UpdateStatus(id uuid.UUID, status string) error {
+	ret := _m.Called(id, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = rf(id, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepo creates a new instance of Repo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repo {
+	mock := &Repo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,209 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/jofosuware/go/shopit/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// TicketUC is an autogenerated mock type for the TicketUC type
+type TicketUC struct {
+	mock.Mock
+}
+
+// GetAllTickets provides a mock function with given fields: status, limit
+func (_m *TicketUC) GetAllTickets(status string, limit int) ([]models.Ticket, error) {
+	ret := _m.Called(status, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllTickets")
+	}
+
+	var r0 []models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]models.Ticket, error)); ok {
+		return rf(status, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []models.Ticket); ok {
+		r0 = rf(status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTicket provides a mock function with given fields: id, user
+func (_m *TicketUC) GetTicket(id uuid.UUID, user *models.User) (*models.Ticket, error) {
+	ret := _m.Called(id, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicket")
+	}
+
+	var r0 *models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.User) (*models.Ticket, error)); ok {
+		return rf(id, user)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.User) *models.Ticket); ok {
+		r0 = rf(id, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, *models.User) error); ok {
+		r1 = rf(id, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTickets provides a mock function with given fields: userID
+func (_m *TicketUC) GetTickets(userID uuid.UUID) ([]models.Ticket, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTickets")
+	}
+
+	var r0 []models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.Ticket, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.Ticket); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OpenTicket provides a mock function with given fields: t, message
+func (_m *TicketUC) OpenTicket(t models.Ticket, message string) (*models.Ticket, error) {
+	ret := _m.Called(t, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OpenTicket")
+	}
+
+	var r0 *models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.Ticket, string) (*models.Ticket, error)); ok {
+		return rf(t, message)
+	}
+	if rf, ok := ret.Get(0).(func(models.Ticket, string) *models.Ticket); ok {
+		r0 = rf(t, message)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.Ticket, string) error); ok {
+		r1 = rf(t, message)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reply provides a mock function with given fields: id, user, message
+func (_m *TicketUC) Reply(id uuid.UUID, user *models.User, message string) (*models.Ticket, error) {
+	ret := _m.Called(id, user, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reply")
+	}
+
+	var r0 *models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.User, string) (*models.Ticket, error)); ok {
+		return rf(id, user, message)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.User, string) *models.Ticket); ok {
+		r0 = rf(id, user, message)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, *models.User, string) error); ok {
+		r1 = rf(id, user, message)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetStatus provides a mock function with given fields: id, status
+func (_m *TicketUC) SetStatus(id uuid.UUID, status string) (*models.Ticket, error) {
+	ret := _m.Called(id, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetStatus")
+	}
+
+	var r0 *models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) (*models.Ticket, error)); ok {
+		return rf(id, status)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) *models.Ticket); ok {
+		r0 = rf(id, status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(id, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTicketUC creates a new instance of TicketUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTicketUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TicketUC {
+	mock := &TicketUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,34 @@
+package tickets
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// OwnsOrder reports whether the user placed the order, returns an error on failure
+	OwnsOrder(userID, orderID uuid.UUID) (bool, error)
+
+	// InsertTicket inserts a ticket with its first message and queues its update, returns the saved ticket
+	InsertTicket(t models.Ticket, m models.TicketMessage) (*models.Ticket, error)
+
+	// FetchUserTickets fetches the tickets of a user, last updated first, returns an error on failure
+	FetchUserTickets(userID uuid.UUID) ([]models.Ticket, error)
+
+	// FetchTickets fetches up to limit tickets in status, of any status when it is empty, last updated first
+	FetchTickets(status string, limit int) ([]models.Ticket, error)
+
+	// FetchTicket fetches a ticket without its messages, returns sql.ErrNoRows when it does not exist
+	FetchTicket(id uuid.UUID) (*models.Ticket, error)
+
+	// FetchMessages fetches the messages of a ticket, oldest first, returns an error on failure
+	FetchMessages(ticketID uuid.UUID) ([]models.TicketMessage, error)
+
+	// InsertMessage adds a message to a ticket, sets its status and queues its update, returns sql.ErrNoRows
+	// when the ticket does not exist
+	InsertMessage(m models.TicketMessage, status string) error
+
+	// UpdateStatus sets the status of a ticket and queues its update, returns sql.ErrNoRows when it does not
+	// exist
+	UpdateStatus(id uuid.UUID, status string) error
+}
@@ -0,0 +1,269 @@
+// Package repository provides persistence for support tickets.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/outbox"
+)
+
+// TicketsRepository handles support ticket database operations.
+type TicketsRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewTicketsRepository returns a new TicketsRepository.
+func NewTicketsRepository(db *sql.DB) *TicketsRepository {
+	return &TicketsRepository{
+		DB: db,
+	}
+}
+
+const ticketColumns = "ticket_id, user_id, order_id, subject, status, created_at, updated_at"
+
+// OwnsOrder reports whether the user placed the order.
+func (r *TicketsRepository) OwnsOrder(userID, orderID uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var owns bool
+	query := "select exists(select 1 from orders where order_id = $1 and user_id = $2)"
+	if err := r.DB.QueryRowContext(ctx, query, orderID, userID).Scan(&owns); err != nil {
+		return false, err
+	}
+
+	return owns, nil
+}
+
+// InsertTicket inserts a ticket with its first message, and writes its update to the
+// outbox in the same transaction.
+func (r *TicketsRepository) InsertTicket(t models.Ticket, m models.TicketMessage) (*models.Ticket, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	query := `insert into tickets (user_id, order_id, subject, status, created_at, updated_at)
+				values ($1, $2, $3, $4, $5, $5) returning ` + ticketColumns
+
+	saved, err := scanTicket(tx.QueryRowContext(ctx, query, t.UserID, t.OrderID, t.Subject, t.Status, now))
+	if err != nil {
+		return nil, err
+	}
+
+	m.TicketID = saved.ID
+	if err := insertMessage(ctx, tx, m, now); err != nil {
+		return nil, err
+	}
+
+	err = outbox.Write(ctx, tx, events.TicketUpdated, models.TicketUpdate{
+		TicketID:  saved.ID,
+		UserID:    saved.UserID,
+		Subject:   saved.Subject,
+		Status:    saved.Status,
+		Message:   m.Body,
+		FromAdmin: m.FromAdmin,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// FetchUserTickets fetches the tickets of a user, last updated first.
+func (r *TicketsRepository) FetchUserTickets(userID uuid.UUID) ([]models.Ticket, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "select " + ticketColumns + " from tickets where user_id = $1 order by updated_at desc"
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTickets(rows)
+}
+
+// FetchTickets fetches up to limit tickets in status, or of any status when status is
+// empty, last updated first.
+func (r *TicketsRepository) FetchTickets(status string, limit int) ([]models.Ticket, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "select " + ticketColumns + ` from tickets where $1 = '' or status = $1
+				order by updated_at desc limit $2`
+
+	rows, err := r.DB.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTickets(rows)
+}
+
+// FetchTicket fetches a ticket without its messages. It returns sql.ErrNoRows when
+// the ticket does not exist.
+func (r *TicketsRepository) FetchTicket(id uuid.UUID) (*models.Ticket, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanTicket(r.DB.QueryRowContext(ctx, "select "+ticketColumns+" from tickets where ticket_id = $1", id))
+}
+
+// FetchMessages fetches the messages of a ticket, oldest first.
+func (r *TicketsRepository) FetchMessages(ticketID uuid.UUID) ([]models.TicketMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select message_id, ticket_id, user_id, from_admin, body, created_at from ticket_messages
+				where ticket_id = $1 order by created_at`
+
+	rows, err := r.DB.QueryContext(ctx, query, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []models.TicketMessage{}
+	for rows.Next() {
+		var m models.TicketMessage
+		if err := rows.Scan(&m.ID, &m.TicketID, &m.UserID, &m.FromAdmin, &m.Body, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// InsertMessage adds a message to a ticket and sets its status, and writes its update
+// to the outbox in the same transaction. It returns sql.ErrNoRows when the ticket
+// does not exist.
+func (r *TicketsRepository) InsertMessage(m models.TicketMessage, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	update, err := updateStatus(ctx, tx, m.TicketID, status, now)
+	if err != nil {
+		return err
+	}
+
+	if err := insertMessage(ctx, tx, m, now); err != nil {
+		return err
+	}
+
+	update.Message = m.Body
+	update.FromAdmin = m.FromAdmin
+	if err := outbox.Write(ctx, tx, events.TicketUpdated, update); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateStatus sets the status of a ticket, and writes its update to the outbox in
+// the same transaction as made by an admin. It returns sql.ErrNoRows when the ticket
+// does not exist.
+func (r *TicketsRepository) UpdateStatus(id uuid.UUID, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	update, err := updateStatus(ctx, tx, id, status, time.Now())
+	if err != nil {
+		return err
+	}
+
+	update.FromAdmin = true
+	if err := outbox.Write(ctx, tx, events.TicketUpdated, update); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateStatus sets the status of a ticket and returns its update, without a message.
+func updateStatus(ctx context.Context, tx *sql.Tx, id uuid.UUID, status string, now time.Time) (models.TicketUpdate, error) {
+	update := models.TicketUpdate{TicketID: id, Status: status}
+
+	err := tx.QueryRowContext(ctx, "update tickets set status = $1, updated_at = $2 where ticket_id = $3 returning user_id, subject",
+		status, now, id).Scan(&update.UserID, &update.Subject)
+
+	return update, err
+}
+
+func insertMessage(ctx context.Context, tx *sql.Tx, m models.TicketMessage, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `insert into ticket_messages (ticket_id, user_id, from_admin, body, created_at)
+				values ($1, $2, $3, $4, $5)`, m.TicketID, m.UserID, m.FromAdmin, m.Body, now)
+
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTicket(row scanner) (*models.Ticket, error) {
+	var t models.Ticket
+
+	err := row.Scan(&t.ID, &t.UserID, &t.OrderID, &t.Subject, &t.Status, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func scanTickets(rows *sql.Rows) ([]models.Ticket, error) {
+	defer rows.Close()
+
+	tickets := []models.Ticket{}
+	for rows.Next() {
+		t, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		tickets = append(tickets, *t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
@@ -0,0 +1,151 @@
+package repository_test
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/tickets/repository"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ticketColumns = []string{"ticket_id", "user_id", "order_id", "subject", "status", "created_at", "updated_at"}
+
+func TestOwnsOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTicketsRepository(db)
+	userID, orderID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("select exists(select 1 from orders where order_id = $1 and user_id = $2)")).
+		WithArgs(orderID, userID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	owns, err := repo.OwnsOrder(userID, orderID)
+	require.NoError(t, err)
+	assert.True(t, owns)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertTicket(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTicketsRepository(db)
+	now := time.Now()
+	ticket := models.Ticket{UserID: uuid.New(), Subject: "Missing scarf", Status: models.TicketOpen}
+	message := models.TicketMessage{UserID: uuid.NullUUID{UUID: ticket.UserID, Valid: true}, Body: "No scarf."}
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("insert into tickets").
+		WithArgs(ticket.UserID, ticket.OrderID, "Missing scarf", models.TicketOpen, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(ticketColumns).
+			AddRow(id, ticket.UserID, nil, "Missing scarf", models.TicketOpen, now, now))
+	mock.ExpectExec("insert into ticket_messages").
+		WithArgs(id, message.UserID, false, "No scarf.", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("insert into outbox").WithArgs(events.TicketUpdated, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	saved, err := repo.InsertTicket(ticket, message)
+	require.NoError(t, err)
+	assert.Equal(t, id, saved.ID)
+	assert.False(t, saved.OrderID.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchTickets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTicketsRepository(db)
+	now := time.Now()
+
+	t.Run("Tickets of a status", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("from tickets where $1 = '' or status = $1")).
+			WithArgs(models.TicketOpen, 50).
+			WillReturnRows(sqlmock.NewRows(ticketColumns).
+				AddRow(uuid.New(), uuid.New(), uuid.New(), "Missing scarf", models.TicketOpen, now, now))
+
+		list, err := repo.FetchTickets(models.TicketOpen, 50)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.True(t, list[0].OrderID.Valid)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No tickets", func(t *testing.T) {
+		mock.ExpectQuery("from tickets where user_id").WillReturnRows(sqlmock.NewRows(ticketColumns))
+
+		list, err := repo.FetchUserTickets(uuid.New())
+		require.NoError(t, err)
+		assert.NotNil(t, list)
+		assert.Empty(t, list)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInsertMessage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTicketsRepository(db)
+	update := regexp.QuoteMeta("update tickets set status = $1, updated_at = $2 where ticket_id = $3 returning user_id, subject")
+	message := models.TicketMessage{TicketID: uuid.New(), UserID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		FromAdmin: true, Body: "It ships today."}
+
+	t.Run("Message answers the ticket", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(update).WithArgs(models.TicketAnswered, sqlmock.AnyArg(), message.TicketID).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "subject"}).AddRow(uuid.New(), "Missing scarf"))
+		mock.ExpectExec("insert into ticket_messages").
+			WithArgs(message.TicketID, message.UserID, true, "It ships today.", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("insert into outbox").WithArgs(events.TicketUpdated, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.InsertMessage(message, models.TicketAnswered))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ticket not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(update).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, repo.InsertMessage(message, models.TicketAnswered), sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTicketsRepository(db)
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("update tickets set status").WithArgs(models.TicketClosed, sqlmock.AnyArg(), id).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "subject"}).AddRow(uuid.New(), "Missing scarf"))
+	mock.ExpectExec("insert into outbox").WithArgs(events.TicketUpdated, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.UpdateStatus(id, models.TicketClosed))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
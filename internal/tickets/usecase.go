@@ -0,0 +1,30 @@
+package tickets
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type TicketUC interface {
+	// OpenTicket opens a ticket of a user with its first message, returns the ticket and an error when the
+	// order it is about is not one of the user
+	OpenTicket(t models.Ticket, message string) (*models.Ticket, error)
+
+	// GetTickets returns the tickets of a user
+	GetTickets(userID uuid.UUID) ([]models.Ticket, error)
+
+	// GetAllTickets returns the latest tickets in status, of any status when it is empty
+	GetAllTickets(status string, limit int) ([]models.Ticket, error)
+
+	// GetTicket returns a ticket with its messages, for the user who opened it or an admin, returns an error
+	// when it is missing
+	GetTicket(id uuid.UUID, user *models.User) (*models.Ticket, error)
+
+	// Reply adds a message of the user who opened a ticket or of an admin, returns the ticket with its
+	// messages and an error when it is missing
+	Reply(id uuid.UUID, user *models.User, message string) (*models.Ticket, error)
+
+	// SetStatus sets the status of a ticket, returns the ticket and an error when it is missing or the status
+	// is not valid
+	SetStatus(id uuid.UUID, status string) (*models.Ticket, error)
+}
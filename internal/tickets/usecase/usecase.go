@@ -0,0 +1,193 @@
+// Package usecase implements support tickets.
+//
+// A customer opens a ticket with a message, optionally about one of their orders, and
+// the support team answers it. A ticket is open while it awaits the support team and
+// answered while it awaits the customer; an admin closes it once resolved, and a
+// reply of the customer opens it again. Every change is queued in the outbox with the
+// change itself, so that the customer is notified of the answers and new statuses of
+// admins, and the admins are emailed the messages of customers.
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/tickets"
+)
+
+// Ticket list limits
+const (
+	// DefaultTicketLimit is how many tickets admins list without a limit
+	DefaultTicketLimit = 50
+	// MaxTicketLimit caps the tickets admins list at once
+	MaxTicketLimit = 200
+)
+
+// TicketsUC provides support ticket use cases.
+type TicketsUC struct {
+	repo tickets.Repo
+}
+
+// NewTicketsUC returns a new TicketsUC.
+func NewTicketsUC(repo tickets.Repo) *TicketsUC {
+	return &TicketsUC{
+		repo: repo,
+	}
+}
+
+// OpenTicket opens t for its user with message as its first message. A ticket about
+// an order must be about an order of the user, else tickets.ErrOrderNotFound is
+// returned.
+func (u *TicketsUC) OpenTicket(t models.Ticket, message string) (*models.Ticket, error) {
+	if t.OrderID.Valid {
+		owns, err := u.repo.OwnsOrder(t.UserID, t.OrderID.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("error checking order: %w", err)
+		}
+		if !owns {
+			return nil, tickets.ErrOrderNotFound
+		}
+	}
+
+	t.Status = models.TicketOpen
+	saved, err := u.repo.InsertTicket(t, models.TicketMessage{
+		UserID: uuid.NullUUID{UUID: t.UserID, Valid: true},
+		Body:   message,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error inserting ticket: %w", err)
+	}
+
+	return u.withMessages(saved)
+}
+
+// GetTickets returns the tickets of a user, last updated first, without their
+// messages.
+func (u *TicketsUC) GetTickets(userID uuid.UUID) ([]models.Ticket, error) {
+	list, err := u.repo.FetchUserTickets(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching tickets: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetAllTickets returns up to limit tickets in status, of any status when status is
+// empty, last updated first, without their messages. A non-positive limit stands for
+// DefaultTicketLimit, and limit is capped at MaxTicketLimit.
+func (u *TicketsUC) GetAllTickets(status string, limit int) ([]models.Ticket, error) {
+	if status != "" && !models.ValidTicketStatus(status) {
+		return nil, tickets.ErrInvalidStatus
+	}
+
+	if limit <= 0 {
+		limit = DefaultTicketLimit
+	}
+	if limit > MaxTicketLimit {
+		limit = MaxTicketLimit
+	}
+
+	list, err := u.repo.FetchTickets(status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching tickets: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetTicket returns a ticket with its messages, for the user who opened it or an
+// admin. It returns tickets.ErrTicketNotFound to anyone else.
+func (u *TicketsUC) GetTicket(id uuid.UUID, user *models.User) (*models.Ticket, error) {
+	t, err := u.ticket(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.withMessages(t)
+}
+
+// Reply adds message to a ticket and returns the ticket with its messages. A reply
+// of the user who opened the ticket opens it again; one of an admin answers it. It
+// returns tickets.ErrTicketNotFound to anyone else.
+func (u *TicketsUC) Reply(id uuid.UUID, user *models.User, message string) (*models.Ticket, error) {
+	t, err := u.ticket(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	m := models.TicketMessage{
+		TicketID:  t.ID,
+		UserID:    uuid.NullUUID{UUID: user.ID, Valid: true},
+		FromAdmin: t.UserID != user.ID,
+		Body:      message,
+	}
+
+	status := models.TicketOpen
+	if m.FromAdmin {
+		status = models.TicketAnswered
+	}
+
+	if err := u.repo.InsertMessage(m, status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, tickets.ErrTicketNotFound
+		}
+		return nil, fmt.Errorf("error inserting message: %w", err)
+	}
+
+	return u.GetTicket(id, user)
+}
+
+// SetStatus sets the status of a ticket and returns the ticket with its messages. The
+// customer is notified of it. It returns tickets.ErrInvalidStatus for a status that is
+// not one of models.TicketStatuses and tickets.ErrTicketNotFound for a missing ticket.
+func (u *TicketsUC) SetStatus(id uuid.UUID, status string) (*models.Ticket, error) {
+	if !models.ValidTicketStatus(status) {
+		return nil, tickets.ErrInvalidStatus
+	}
+
+	if err := u.repo.UpdateStatus(id, status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, tickets.ErrTicketNotFound
+		}
+		return nil, fmt.Errorf("error updating ticket status: %w", err)
+	}
+
+	t, err := u.repo.FetchTicket(id)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching ticket: %w", err)
+	}
+
+	return u.withMessages(t)
+}
+
+// ticket returns a ticket for the user who opened it or an admin, and
+// tickets.ErrTicketNotFound for anyone else.
+func (u *TicketsUC) ticket(id uuid.UUID, user *models.User) (*models.Ticket, error) {
+	t, err := u.repo.FetchTicket(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, tickets.ErrTicketNotFound
+		}
+		return nil, fmt.Errorf("error fetching ticket: %w", err)
+	}
+
+	if t.UserID != user.ID && user.Role != models.RoleAdmin {
+		return nil, tickets.ErrTicketNotFound
+	}
+
+	return t, nil
+}
+
+// withMessages fills the messages of t.
+func (u *TicketsUC) withMessages(t *models.Ticket) (*models.Ticket, error) {
+	messages, err := u.repo.FetchMessages(t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching messages: %w", err)
+	}
+	t.Messages = messages
+
+	return t, nil
+}
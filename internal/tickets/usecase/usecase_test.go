@@ -0,0 +1,141 @@
+package usecase_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/tickets"
+	"github.com/jofosuware/go/shopit/internal/tickets/mocks"
+	"github.com/jofosuware/go/shopit/internal/tickets/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenTicket(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewTicketsUC(repo)
+	userID, orderID := uuid.New(), uuid.New()
+	ticket := models.Ticket{UserID: userID, OrderID: uuid.NullUUID{UUID: orderID, Valid: true}, Subject: "Missing scarf"}
+
+	t.Run("Ticket is opened about an order", func(t *testing.T) {
+		repo.On("OwnsOrder", userID, orderID).Return(true, nil).Once()
+		repo.On("InsertTicket", mock.MatchedBy(func(t models.Ticket) bool { return t.Status == models.TicketOpen }),
+			models.TicketMessage{UserID: uuid.NullUUID{UUID: userID, Valid: true}, Body: "No scarf."}).
+			Return(func(t models.Ticket, _ models.TicketMessage) (*models.Ticket, error) {
+				t.ID = uuid.New()
+				return &t, nil
+			}).Once()
+		repo.On("FetchMessages", mock.Anything).Return([]models.TicketMessage{{Body: "No scarf."}}, nil).Once()
+
+		opened, err := u.OpenTicket(ticket, "No scarf.")
+		require.NoError(t, err)
+		assert.Equal(t, models.TicketOpen, opened.Status)
+		assert.Len(t, opened.Messages, 1)
+	})
+
+	t.Run("Order of another user", func(t *testing.T) {
+		repo.On("OwnsOrder", userID, orderID).Return(false, nil).Once()
+
+		_, err := u.OpenTicket(ticket, "No scarf.")
+		assert.ErrorIs(t, err, tickets.ErrOrderNotFound)
+	})
+}
+
+func TestGetAllTickets(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewTicketsUC(repo)
+
+	t.Run("Limit is capped", func(t *testing.T) {
+		repo.On("FetchTickets", models.TicketOpen, usecase.MaxTicketLimit).Return([]models.Ticket{}, nil).Once()
+
+		_, err := u.GetAllTickets(models.TicketOpen, 1000)
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown status", func(t *testing.T) {
+		_, err := u.GetAllTickets("pending", 0)
+		assert.ErrorIs(t, err, tickets.ErrInvalidStatus)
+	})
+}
+
+func TestGetTicket(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewTicketsUC(repo)
+	owner := &models.User{ID: uuid.New(), Role: models.RoleUser}
+	ticket := models.Ticket{ID: uuid.New(), UserID: owner.ID, Status: models.TicketOpen}
+
+	t.Run("Admins read any ticket", func(t *testing.T) {
+		repo.On("FetchTicket", ticket.ID).Return(&ticket, nil).Once()
+		repo.On("FetchMessages", ticket.ID).Return([]models.TicketMessage{}, nil).Once()
+
+		_, err := u.GetTicket(ticket.ID, &models.User{ID: uuid.New(), Role: models.RoleAdmin})
+		require.NoError(t, err)
+	})
+
+	t.Run("Ticket of another user", func(t *testing.T) {
+		repo.On("FetchTicket", ticket.ID).Return(&ticket, nil).Once()
+
+		_, err := u.GetTicket(ticket.ID, &models.User{ID: uuid.New(), Role: models.RoleUser})
+		assert.ErrorIs(t, err, tickets.ErrTicketNotFound)
+	})
+
+	t.Run("Ticket not found", func(t *testing.T) {
+		repo.On("FetchTicket", ticket.ID).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := u.GetTicket(ticket.ID, owner)
+		assert.ErrorIs(t, err, tickets.ErrTicketNotFound)
+	})
+}
+
+func TestReply(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewTicketsUC(repo)
+	owner := &models.User{ID: uuid.New(), Role: models.RoleUser}
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
+	reply := func(user *models.User, status string, fromAdmin bool) {
+		ticket := models.Ticket{ID: uuid.New(), UserID: owner.ID, Status: models.TicketClosed}
+
+		repo.On("FetchTicket", ticket.ID).Return(&ticket, nil).Twice()
+		repo.On("InsertMessage", models.TicketMessage{TicketID: ticket.ID, UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+			FromAdmin: fromAdmin, Body: "Any news?"}, status).Return(nil).Once()
+		repo.On("FetchMessages", ticket.ID).Return([]models.TicketMessage{}, nil).Once()
+
+		_, err := u.Reply(ticket.ID, user, "Any news?")
+		require.NoError(t, err)
+	}
+
+	t.Run("Reply of the customer opens the ticket again", func(t *testing.T) {
+		reply(owner, models.TicketOpen, false)
+	})
+
+	t.Run("Reply of an admin answers the ticket", func(t *testing.T) {
+		reply(admin, models.TicketAnswered, true)
+	})
+}
+
+func TestSetStatus(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	u := usecase.NewTicketsUC(repo)
+	id := uuid.New()
+
+	t.Run("Ticket is closed", func(t *testing.T) {
+		repo.On("UpdateStatus", id, models.TicketClosed).Return(nil).Once()
+		repo.On("FetchTicket", id).Return(&models.Ticket{ID: id, Status: models.TicketClosed}, nil).Once()
+		repo.On("FetchMessages", id).Return([]models.TicketMessage{}, nil).Once()
+
+		ticket, err := u.SetStatus(id, models.TicketClosed)
+		require.NoError(t, err)
+		assert.Equal(t, models.TicketClosed, ticket.Status)
+	})
+
+	t.Run("Ticket not found", func(t *testing.T) {
+		repo.On("UpdateStatus", id, models.TicketClosed).Return(sql.ErrNoRows).Once()
+
+		_, err := u.SetStatus(id, models.TicketClosed)
+		assert.ErrorIs(t, err, tickets.ErrTicketNotFound)
+	})
+}
@@ -0,0 +1,96 @@
+// Package delivery provides HTTP handlers for direct uploads.
+//
+// Browsers upload large files straight to storage with the parameters issued here,
+// then send the resulting public ids to the endpoint the files are for.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/uploads"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// UploadHandlers provides HTTP handler methods for direct upload endpoints.
+type UploadHandlers struct {
+	logger   logger.Logger
+	uploadUC uploads.UploadUC
+}
+
+// NewUploadHandlers returns a new UploadHandlers.
+func NewUploadHandlers(logger logger.Logger, uploadUC uploads.UploadUC) *UploadHandlers {
+	return &UploadHandlers{
+		logger:   logger,
+		uploadUC: uploadUC,
+	}
+}
+
+type presigned struct {
+	URL       string            `json:"url"`
+	Fields    map[string]string `json:"fields"`
+	PublicID  string            `json:"publicId"`
+	AssetURL  string            `json:"assetUrl"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+type presignResponse struct {
+	Success bool      `json:"success"`
+	Upload  presigned `json:"upload"`
+}
+
+// Presign issues the parameters for the current user to upload one file straight to
+// storage: a multipart form POST to url of fields, then the file in the field named
+// "file". The publicId is then sent to the endpoint the file is for, before expiresAt.
+// Endpoint: POST /api/v1/uploads/presign
+// Expects JSON body: {"kind": "product_image"}; product images are for admins and sellers.
+func (h *UploadHandlers) Presign(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return
+	}
+
+	var req presignRequest
+	if !utils.Bind(w, r, h.logger, &req) {
+		return
+	}
+
+	p, err := h.uploadUC.Presign(r.Context(), user, req.Kind)
+	if err != nil {
+		switch {
+		case errors.Is(err, uploads.ErrForbidden):
+			_ = utils.Forbidden(w, r)
+			h.logger.Errorf("error presigning upload: %v", err)
+		case errors.Is(err, uploads.ErrInvalidKind) || errors.Is(err, cloudinary.ErrRejected):
+			_ = utils.BadRequest(w, r, err)
+			h.logger.Errorf("error presigning upload: %v", err)
+		case errors.Is(err, cloudinary.ErrPresignUnsupported):
+			_ = utils.WriteJSON(w, http.StatusNotImplemented, struct {
+				Success bool   `json:"success"`
+				Message string `json:"message"`
+			}{Message: cloudinary.ErrPresignUnsupported.Error()})
+			h.logger.Errorf("error presigning upload: %v", err)
+		case errors.Is(err, cloudinary.ErrUnavailable):
+			_ = utils.ServiceUnavailable(w, r, errors.New("image storage is temporarily unavailable, try again later"))
+			h.logger.Errorf("error presigning upload: %v", err)
+		default:
+			_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error presigning upload: %w", err))
+		}
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, presignResponse{Success: true, Upload: presigned{
+		URL:       p.URL,
+		Fields:    p.Fields,
+		PublicID:  p.PublicID,
+		AssetURL:  p.AssetURL,
+		ExpiresAt: p.ExpiresAt,
+	}})
+}
@@ -0,0 +1,77 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/uploads"
+	"github.com/jofosuware/go/shopit/internal/uploads/delivery"
+	"github.com/jofosuware/go/shopit/internal/uploads/mocks"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresign(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	uploadUC := mocks.NewUploadUC(t)
+	h := delivery.NewUploadHandlers(logger, uploadUC)
+	user := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
+	call := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/uploads/presign", bytes.NewBufferString(body))
+		req = req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, user))
+		rr := httptest.NewRecorder()
+		h.Presign(rr, req)
+		return rr
+	}
+
+	t.Run("Upload is presigned", func(t *testing.T) {
+		uploadUC.On("Presign", mock.Anything, user, models.UploadProductImage).Return(&cloudinary.Presigned{
+			URL: "https://api.cloudinary.com/v1_1/shop/image/upload", Fields: map[string]string{"signature": "abc"},
+			PublicID: "products/a", ExpiresAt: time.Now().Add(time.Minute)}, nil).Once()
+
+		rr := call(`{"kind": "product_image"}`)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var res struct {
+			Upload struct {
+				PublicID string            `json:"publicId"`
+				Fields   map[string]string `json:"fields"`
+			} `json:"upload"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, "products/a", res.Upload.PublicID)
+		assert.Equal(t, "abc", res.Upload.Fields["signature"])
+	})
+
+	t.Run("Unknown kind", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, call(`{"kind": "invoice"}`).Code)
+	})
+
+	t.Run("Kind of other roles", func(t *testing.T) {
+		uploadUC.On("Presign", mock.Anything, user, models.UploadProductImage).Return(nil, uploads.ErrForbidden).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusForbidden, call(`{"kind": "product_image"}`).Code)
+	})
+
+	t.Run("Storage cannot presign", func(t *testing.T) {
+		uploadUC.On("Presign", mock.Anything, user, models.UploadProductImage).Return(nil, cloudinary.ErrPresignUnsupported).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		assert.Equal(t, http.StatusNotImplemented, call(`{"kind": "product_image"}`).Code)
+	})
+}
@@ -0,0 +1,6 @@
+package delivery
+
+// presignRequest is the body of Presign.
+type presignRequest struct {
+	Kind string `json:"kind" validate:"required,oneof=product_image"`
+}
@@ -0,0 +1,21 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// UploadRouter returns a chi.Router with the direct upload routes.
+//
+//   - POST /presign → Issue the parameters to upload one file straight to storage
+func (h *UploadHandlers) UploadRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+
+	mux.Post("/presign", h.Presign)
+
+	return mux
+}
@@ -0,0 +1,11 @@
+package uploads
+
+import "errors"
+
+var (
+	// ErrInvalidKind is returned when an upload is presigned for a kind that is not supported.
+	ErrInvalidKind = errors.New("kind must be product_image")
+
+	// ErrForbidden is returned when a user presigns an upload of a kind they cannot make.
+	ErrForbidden = errors.New("you are not allowed to upload files of this kind")
+)
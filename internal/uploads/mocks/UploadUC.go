@@ -0,0 +1,62 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	cloudinary "github.com/jofosuware/go/shopit/pkg/cloudinary"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "github.com/jofosuware/go/shopit/internal/models"
+)
+
+// UploadUC is an autogenerated mock type for the UploadUC type
+type UploadUC struct {
+	mock.Mock
+}
+
+// Presign provides a mock function with given fields: ctx, user, kind
+func (_m *UploadUC) Presign(ctx context.Context, user *models.User, kind string) (*cloudinary.Presigned, error) {
+	ret := _m.Called(ctx, user, kind)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Presign")
+	}
+
+	var r0 *cloudinary.Presigned
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, string) (*cloudinary.Presigned, error)); ok {
+		return rf(ctx, user, kind)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, string) *cloudinary.Presigned); ok {
+		r0 = rf(ctx, user, kind)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*cloudinary.Presigned)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User, string) error); ok {
+		r1 = rf(ctx, user, kind)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewUploadUC creates a new instance of UploadUC. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUploadUC(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UploadUC {
+	mock := &UploadUC{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,8 @@
+package uploads
+
+import "github.com/jofosuware/go/shopit/internal/models"
+
+type Repo interface {
+	// InsertUpload records an upload presigned for a user
+	InsertUpload(u models.Upload) error
+}
@@ -0,0 +1,34 @@
+// Package repository stores the uploads presigned for users.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// UploadsRepository handles upload-related database operations.
+type UploadsRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewUploadsRepository returns a new UploadsRepository.
+func NewUploadsRepository(db *sql.DB) *UploadsRepository {
+	return &UploadsRepository{
+		DB: db,
+	}
+}
+
+// InsertUpload records an upload presigned for a user, unclaimed.
+func (r *UploadsRepository) InsertUpload(u models.Upload) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into uploads (public_id, user_id, kind, url, expires_at) values ($1, $2, $3, $4, $5)`
+
+	_, err := r.DB.ExecContext(ctx, query, u.PublicID, u.UserID, u.Kind, u.URL, u.ExpiresAt)
+	return err
+}
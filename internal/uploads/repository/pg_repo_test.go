@@ -0,0 +1,31 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/uploads/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertUpload(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewUploadsRepository(db)
+	upload := models.Upload{PublicID: "products/a", UserID: uuid.New(), Kind: models.UploadProductImage,
+		URL: "https://res.cloudinary.com/shop/image/upload/products/a", ExpiresAt: time.Now().Add(time.Minute)}
+
+	mock.ExpectExec(regexp.QuoteMeta("insert into uploads (public_id, user_id, kind, url, expires_at)")).
+		WithArgs(upload.PublicID, upload.UserID, upload.Kind, upload.URL, upload.ExpiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.InsertUpload(upload))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
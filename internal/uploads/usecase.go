@@ -0,0 +1,14 @@
+package uploads
+
+import (
+	"context"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+)
+
+type UploadUC interface {
+	// Presign issues the parameters for user to upload one file of kind straight to storage, returns an error
+	// when the kind is not supported or not allowed to the user
+	Presign(ctx context.Context, user *models.User, kind string) (*cloudinary.Presigned, error)
+}
@@ -0,0 +1,96 @@
+// Package usecase issues direct uploads.
+//
+// Large files, such as product images, are uploaded by the browser straight to
+// storage with short-lived presigned parameters instead of through the API. Every
+// presigned upload is recorded for the user it was issued to; the endpoint the file
+// is meant for then receives its public id and claims it, which only that user can
+// do, once, before the upload expires. Files uploaded but never claimed are left to
+// the orphaned asset cleanup.
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/uploads"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+)
+
+// Presign defaults
+const (
+	// DefaultExpiry is how long presigned upload parameters stay valid by default
+	DefaultExpiry = 15 * time.Minute
+	// DefaultMaxSize is the largest file a presigned upload accepts by default, in bytes
+	DefaultMaxSize = 10 << 20
+)
+
+// kinds maps the upload kinds to the folder their files are stored in and the roles
+// that can upload them.
+var kinds = map[string]struct {
+	folder string
+	roles  []string
+}{
+	models.UploadProductImage: {folder: cloudinary.FolderProducts, roles: []string{models.RoleAdmin, models.RoleSeller}},
+}
+
+// UploadsUC provides direct upload use cases.
+type UploadsUC struct {
+	store   cloudinary.Presigner
+	repo    uploads.Repo
+	expiry  time.Duration
+	maxSize int64
+}
+
+// NewUploadsUC returns a new UploadsUC presigning uploads to store for expiry, of
+// files of at most maxSize bytes. Non-positive values fall back to DefaultExpiry and
+// DefaultMaxSize.
+func NewUploadsUC(store cloudinary.Presigner, repo uploads.Repo, expiry time.Duration, maxSize int64) *UploadsUC {
+	if expiry <= 0 {
+		expiry = DefaultExpiry
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	return &UploadsUC{
+		store:   store,
+		repo:    repo,
+		expiry:  expiry,
+		maxSize: maxSize,
+	}
+}
+
+// Presign issues the parameters for user to upload one file of kind straight to
+// storage and records the upload for them. It returns uploads.ErrInvalidKind for an
+// unknown kind, uploads.ErrForbidden for a kind user cannot upload and
+// cloudinary.ErrPresignUnsupported when storage cannot presign uploads.
+func (u *UploadsUC) Presign(ctx context.Context, user *models.User, kind string) (*cloudinary.Presigned, error) {
+	k, ok := kinds[kind]
+	if !ok {
+		return nil, uploads.ErrInvalidKind
+	}
+	if !slices.Contains(k.roles, user.Role) {
+		return nil, uploads.ErrForbidden
+	}
+
+	presigned, err := u.store.Presign(ctx, k.folder, u.maxSize, u.expiry)
+	if err != nil {
+		return nil, fmt.Errorf("error presigning upload: %w", err)
+	}
+
+	err = u.repo.InsertUpload(models.Upload{
+		PublicID:  presigned.PublicID,
+		UserID:    user.ID,
+		Kind:      kind,
+		URL:       presigned.AssetURL,
+		ExpiresAt: presigned.ExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error saving upload: %v", err)
+	}
+
+	return presigned, nil
+}
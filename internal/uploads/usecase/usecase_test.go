@@ -0,0 +1,61 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/uploads"
+	"github.com/jofosuware/go/shopit/internal/uploads/mocks"
+	"github.com/jofosuware/go/shopit/internal/uploads/usecase"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	cldMocks "github.com/jofosuware/go/shopit/pkg/cloudinary/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresign(t *testing.T) {
+	store := cldMocks.NewPresigner(t)
+	repo := mocks.NewRepo(t)
+	u := usecase.NewUploadsUC(store, repo, 0, 0)
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+
+	t.Run("Upload is presigned and recorded", func(t *testing.T) {
+		p := &cloudinary.Presigned{PublicID: "products/a", AssetURL: "https://cdn/products/a",
+			ExpiresAt: time.Now().Add(usecase.DefaultExpiry)}
+		store.On("Presign", mock.Anything, cloudinary.FolderProducts, int64(usecase.DefaultMaxSize), usecase.DefaultExpiry).
+			Return(p, nil).Once()
+		repo.On("InsertUpload", models.Upload{PublicID: "products/a", UserID: admin.ID, Kind: models.UploadProductImage,
+			URL: "https://cdn/products/a", ExpiresAt: p.ExpiresAt}).Return(nil).Once()
+
+		presigned, err := u.Presign(context.Background(), admin, models.UploadProductImage)
+		require.NoError(t, err)
+		assert.Equal(t, p, presigned)
+	})
+
+	t.Run("Customers cannot upload product images", func(t *testing.T) {
+		_, err := u.Presign(context.Background(), &models.User{ID: uuid.New(), Role: models.RoleUser}, models.UploadProductImage)
+		assert.ErrorIs(t, err, uploads.ErrForbidden)
+	})
+
+	t.Run("Unknown kind", func(t *testing.T) {
+		_, err := u.Presign(context.Background(), admin, "invoice")
+		assert.ErrorIs(t, err, uploads.ErrInvalidKind)
+	})
+
+	t.Run("Kind of other roles", func(t *testing.T) {
+		_, err := u.Presign(context.Background(), &models.User{ID: uuid.New(), Role: models.RoleUser}, models.UploadProductImage)
+		assert.ErrorIs(t, err, uploads.ErrForbidden)
+	})
+
+	t.Run("Storage cannot presign", func(t *testing.T) {
+		store.On("Presign", mock.Anything, cloudinary.FolderProducts, int64(usecase.DefaultMaxSize), usecase.DefaultExpiry).
+			Return(nil, cloudinary.ErrPresignUnsupported).Once()
+
+		_, err := u.Presign(context.Background(), admin, models.UploadProductImage)
+		assert.ErrorIs(t, err, cloudinary.ErrPresignUnsupported)
+	})
+}
@@ -0,0 +1,130 @@
+// Package delivery provides HTTP handlers for the wishlists of users.
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/wishlist"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// WishlistHandlers provides HTTP handler methods for wishlist endpoints.
+type WishlistHandlers struct {
+	logger     logger.Logger
+	wishlistUC wishlist.WishlistUC
+}
+
+// NewWishlistHandlers returns a new WishlistHandlers.
+func NewWishlistHandlers(logger logger.Logger, wishlistUC wishlist.WishlistUC) *WishlistHandlers {
+	return &WishlistHandlers{
+		logger:     logger,
+		wishlistUC: wishlistUC,
+	}
+}
+
+type wishlistResponse struct {
+	Success bool                  `json:"success"`
+	Items   []models.WishlistItem `json:"items"`
+}
+
+// GetWishlist returns the wishlist of the current user, newest first.
+// Endpoint: GET /api/v1/wishlist
+func (h *WishlistHandlers) GetWishlist(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.wishlistUC.GetWishlist(user.ID)
+	if err != nil {
+		_ = utils.ServerError(w, r, h.logger, fmt.Errorf("error getting wishlist: %w", err))
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, wishlistResponse{Success: true, Items: items})
+}
+
+// AddItem adds a product to the wishlist of the current user and returns the
+// wishlist. The user is notified when an admin lowers its price.
+// Endpoint: PUT /api/v1/wishlist/{productId}
+func (h *WishlistHandlers) AddItem(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	productID, ok := h.productID(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.wishlistUC.AddItem(user.ID, productID)
+	if err != nil {
+		h.writeError(w, r, "error adding wishlist item", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, wishlistResponse{Success: true, Items: items})
+}
+
+// RemoveItem removes a product from the wishlist of the current user.
+// Endpoint: DELETE /api/v1/wishlist/{productId}
+func (h *WishlistHandlers) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	productID, ok := h.productID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.wishlistUC.RemoveItem(user.ID, productID); err != nil {
+		h.writeError(w, r, "error removing wishlist item", err)
+		return
+	}
+
+	_ = utils.WriteJSON(w, http.StatusOK, models.Response{Success: true, Message: "product removed from wishlist"})
+}
+
+// user returns the signed in user. It writes the response and returns false when
+// there is none.
+func (h *WishlistHandlers) user(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user, ok := r.Context().Value(utils.UserContextKey).(*models.User)
+	if !ok {
+		_ = utils.BadRequest(w, r, errors.New("error getting user from session"))
+		h.logger.Errorf("error getting user from session")
+		return nil, false
+	}
+
+	return user, true
+}
+
+// productID returns the product id of the URL. It writes the response and returns
+// false when it is not an id.
+func (h *WishlistHandlers) productID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "productId"))
+	if err != nil {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("error parsing product id: %v", err)
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+func (h *WishlistHandlers) writeError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if wishlist.IsClientError(err) {
+		_ = utils.BadRequest(w, r, err)
+		h.logger.Errorf("%s: %v", msg, err)
+		return
+	}
+	_ = utils.ServerError(w, r, h.logger, fmt.Errorf("%s: %w", msg, err))
+}
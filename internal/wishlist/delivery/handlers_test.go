@@ -0,0 +1,89 @@
+package delivery_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/wishlist"
+	"github.com/jofosuware/go/shopit/internal/wishlist/delivery"
+	"github.com/jofosuware/go/shopit/internal/wishlist/mocks"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func request(user *models.User, method, productID string) *http.Request {
+	req := httptest.NewRequest(method, "/wishlist/"+productID, nil)
+	rCtx := chi.NewRouteContext()
+	rCtx.URLParams.Add("productId", productID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rCtx)
+	return req.WithContext(context.WithValue(ctx, utils.UserContextKey, user))
+}
+
+func TestAddItem(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	wishlistUC := mocks.NewWishlistUC(t)
+	h := delivery.NewWishlistHandlers(logger, wishlistUC)
+	user := &models.User{ID: uuid.New()}
+
+	t.Run("Product is added", func(t *testing.T) {
+		id := uuid.New()
+		wishlistUC.On("AddItem", user.ID, id).Return([]models.WishlistItem{{ProductID: id}}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.AddItem(rr, request(user, http.MethodPut, id.String()))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Missing product", func(t *testing.T) {
+		id := uuid.New()
+		wishlistUC.On("AddItem", user.ID, id).Return(nil, wishlist.ErrProductNotFound).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.AddItem(rr, request(user, http.MethodPut, id.String()))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid product id", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.AddItem(rr, request(user, http.MethodPut, "scarf"))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestRemoveItem(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	wishlistUC := mocks.NewWishlistUC(t)
+	h := delivery.NewWishlistHandlers(logger, wishlistUC)
+	user := &models.User{ID: uuid.New()}
+
+	t.Run("Product is removed", func(t *testing.T) {
+		id := uuid.New()
+		wishlistUC.On("RemoveItem", user.ID, id).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.RemoveItem(rr, request(user, http.MethodDelete, id.String()))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		id := uuid.New()
+		wishlistUC.On("RemoveItem", user.ID, id).Return(errors.New("db down")).Once()
+		logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Once()
+
+		rr := httptest.NewRecorder()
+		h.RemoveItem(rr, request(user, http.MethodDelete, id.String()))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
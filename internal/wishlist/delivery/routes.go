@@ -0,0 +1,25 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// WishlistRouter returns a chi.Router with the wishlist routes of the signed in user.
+//
+//   - GET    /             → List the products on the wishlist
+//   - PUT    /{productId}  → Add a product to the wishlist
+//   - DELETE /{productId}  → Remove a product from the wishlist
+func (h *WishlistHandlers) WishlistRouter() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(utils.IsAuthenticated)
+
+	mux.Get("/", h.GetWishlist)
+	mux.Put("/{productId}", h.AddItem)
+	mux.Delete("/{productId}", h.RemoveItem)
+
+	return mux
+}
@@ -0,0 +1,24 @@
+package wishlist
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+var (
+	// ErrProductNotFound is returned when the product added to a wishlist does not exist.
+	ErrProductNotFound = errors.New("product not found")
+
+	// ErrNotInWishlist is returned when the product removed from a wishlist is not on it.
+	ErrNotInWishlist = errors.New("product is not in the wishlist")
+
+	// ErrWishlistFull is returned when a user with models.MaxWishlistItems products on their wishlist adds another.
+	ErrWishlistFull = fmt.Errorf("a wishlist holds at most %d products", models.MaxWishlistItems)
+)
+
+// IsClientError reports whether err is caused by the request rather than the server.
+func IsClientError(err error) bool {
+	return errors.Is(err, ErrProductNotFound) || errors.Is(err, ErrNotInWishlist) || errors.Is(err, ErrWishlistFull)
+}
@@ -0,0 +1,21 @@
+package wishlist
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type Repo interface {
+	// FetchWishlist fetches the wishlist of a user, newest first, returns an error on failure
+	FetchWishlist(userID uuid.UUID) ([]models.WishlistItem, error)
+
+	// CountWishlistItems counts the products on the wishlist of a user, returns an error on failure
+	CountWishlistItems(userID uuid.UUID) (int, error)
+
+	// InsertWishlistItem adds a product to the wishlist of a user, doing nothing when it is already on it,
+	// returns sql.ErrNoRows when there is no such product
+	InsertWishlistItem(userID, productID uuid.UUID) error
+
+	// DeleteWishlistItem removes a product from the wishlist of a user, returns sql.ErrNoRows when it is not on it
+	DeleteWishlistItem(userID, productID uuid.UUID) error
+}
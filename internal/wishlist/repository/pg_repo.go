@@ -0,0 +1,132 @@
+// Package repository provides persistence for the wishlists of users.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// WishlistRepository handles wishlist database operations.
+type WishlistRepository struct {
+	// DB is the database connection.
+	DB *sql.DB
+}
+
+// NewWishlistRepository returns a new WishlistRepository.
+func NewWishlistRepository(db *sql.DB) *WishlistRepository {
+	return &WishlistRepository{
+		DB: db,
+	}
+}
+
+// FetchWishlist fetches the products on the wishlist of a user, newest first, with
+// their current price and stock.
+func (r *WishlistRepository) FetchWishlist(userID uuid.UUID) ([]models.WishlistItem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `select p.product_id, p.name, p.price, p.currency, p.stock,
+				coalesce((select i.url from images i where i.product_id = p.product_id order by i.created_at limit 1), ''),
+				w.created_at
+			from wishlist_items w
+			join products p on p.product_id = w.product_id
+			where w.user_id = $1
+			order by w.created_at desc`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.WishlistItem{}
+	for rows.Next() {
+		var i models.WishlistItem
+		if err := rows.Scan(&i.ProductID, &i.Name, &i.Price, &i.Price.Currency, &i.Stock, &i.Image, &i.AddedAt); err != nil {
+			return nil, err
+		}
+
+		items = append(items, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// CountWishlistItems counts the products on the wishlist of a user.
+func (r *WishlistRepository) CountWishlistItems(userID uuid.UUID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var n int
+	if err := r.DB.QueryRowContext(ctx, "select count(*) from wishlist_items where user_id = $1", userID).Scan(&n); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// InsertWishlistItem adds a product to the wishlist of a user. A product already on
+// it is left alone. It returns sql.ErrNoRows when there is no such product.
+func (r *WishlistRepository) InsertWishlistItem(userID, productID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `insert into wishlist_items (user_id, product_id, created_at)
+				select $1, product_id, $3 from products where product_id = $2
+				on conflict (user_id, product_id) do nothing`
+
+	res, err := r.DB.ExecContext(ctx, query, userID, productID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	// nothing inserted: either the product is already on the wishlist or it does not exist
+	var exists bool
+	if err := r.DB.QueryRowContext(ctx, "select exists(select 1 from products where product_id = $1)", productID).
+		Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteWishlistItem removes a product from the wishlist of a user. It returns
+// sql.ErrNoRows when the product is not on it.
+func (r *WishlistRepository) DeleteWishlistItem(userID, productID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.DB.ExecContext(ctx, "delete from wishlist_items where user_id = $1 and product_id = $2", userID, productID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/wishlist/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchWishlist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWishlistRepository(db)
+	userID, productID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("from wishlist_items w")).WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "name", "price", "currency", "stock", "url", "created_at"}).
+			AddRow(productID, "Kente Scarf", 3600, "USD", 4, "https://example.com/scarf.png", time.Now()))
+
+	items, err := repo.FetchWishlist(userID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, productID, items[0].ProductID)
+	assert.Equal(t, int64(3600), items[0].Price.Amount)
+	assert.Equal(t, "USD", items[0].Price.Currency)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertWishlistItem(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWishlistRepository(db)
+	userID, productID := uuid.New(), uuid.New()
+	insert := regexp.QuoteMeta("insert into wishlist_items (user_id, product_id, created_at)")
+	exists := regexp.QuoteMeta("select exists(select 1 from products where product_id = $1)")
+
+	t.Run("Product is added", func(t *testing.T) {
+		mock.ExpectExec(insert).WithArgs(userID, productID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.InsertWishlistItem(userID, productID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Product already on the wishlist", func(t *testing.T) {
+		mock.ExpectExec(insert).WithArgs(userID, productID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(exists).WithArgs(productID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		require.NoError(t, repo.InsertWishlistItem(userID, productID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Missing product", func(t *testing.T) {
+		mock.ExpectExec(insert).WithArgs(userID, productID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(exists).WithArgs(productID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		assert.ErrorIs(t, repo.InsertWishlistItem(userID, productID), sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteWishlistItem(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWishlistRepository(db)
+	userID, productID := uuid.New(), uuid.New()
+	del := regexp.QuoteMeta("delete from wishlist_items where user_id = $1 and product_id = $2")
+
+	mock.ExpectExec(del).WithArgs(userID, productID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.ErrorIs(t, repo.DeleteWishlistItem(userID, productID), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,17 @@
+package wishlist
+
+import (
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+type WishlistUC interface {
+	// GetWishlist returns the products on the wishlist of a user, newest first
+	GetWishlist(userID uuid.UUID) ([]models.WishlistItem, error)
+
+	// AddItem adds a product to the wishlist of a user, returns the wishlist
+	AddItem(userID, productID uuid.UUID) ([]models.WishlistItem, error)
+
+	// RemoveItem removes a product from the wishlist of a user
+	RemoveItem(userID, productID uuid.UUID) error
+}
@@ -0,0 +1,74 @@
+// Package usecase implements the wishlists of users.
+//
+// A user saves up to models.MaxWishlistItems products to buy later. When an admin
+// lowers the price of a product, everyone who wishlisted it is told through the
+// price_drop notification; see the products repository for where it is queued.
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/wishlist"
+)
+
+// WishlistUC provides wishlist use cases.
+type WishlistUC struct {
+	repo wishlist.Repo
+}
+
+// NewWishlistUC returns a new WishlistUC.
+func NewWishlistUC(repo wishlist.Repo) *WishlistUC {
+	return &WishlistUC{
+		repo: repo,
+	}
+}
+
+// GetWishlist returns the products on the wishlist of a user, newest first.
+func (w *WishlistUC) GetWishlist(userID uuid.UUID) ([]models.WishlistItem, error) {
+	items, err := w.repo.FetchWishlist(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching wishlist: %w", err)
+	}
+
+	return items, nil
+}
+
+// AddItem adds a product to the wishlist of a user and returns the wishlist. Adding
+// a product already on it changes nothing. It returns wishlist.ErrProductNotFound
+// when there is no such product and wishlist.ErrWishlistFull when the wishlist is
+// full.
+func (w *WishlistUC) AddItem(userID, productID uuid.UUID) ([]models.WishlistItem, error) {
+	n, err := w.repo.CountWishlistItems(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error counting wishlist items: %w", err)
+	}
+	if n >= models.MaxWishlistItems {
+		return nil, wishlist.ErrWishlistFull
+	}
+
+	if err := w.repo.InsertWishlistItem(userID, productID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, wishlist.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("error inserting wishlist item: %w", err)
+	}
+
+	return w.GetWishlist(userID)
+}
+
+// RemoveItem removes a product from the wishlist of a user. It returns
+// wishlist.ErrNotInWishlist when the product is not on it.
+func (w *WishlistUC) RemoveItem(userID, productID uuid.UUID) error {
+	if err := w.repo.DeleteWishlistItem(userID, productID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return wishlist.ErrNotInWishlist
+		}
+		return fmt.Errorf("error deleting wishlist item: %w", err)
+	}
+
+	return nil
+}
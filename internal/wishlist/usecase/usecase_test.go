@@ -0,0 +1,56 @@
+package usecase_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/internal/wishlist"
+	"github.com/jofosuware/go/shopit/internal/wishlist/mocks"
+	"github.com/jofosuware/go/shopit/internal/wishlist/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddItem(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	w := usecase.NewWishlistUC(repo)
+	userID, productID := uuid.New(), uuid.New()
+
+	t.Run("Product is added", func(t *testing.T) {
+		repo.On("CountWishlistItems", userID).Return(0, nil).Once()
+		repo.On("InsertWishlistItem", userID, productID).Return(nil).Once()
+		repo.On("FetchWishlist", userID).Return([]models.WishlistItem{{ProductID: productID}}, nil).Once()
+
+		items, err := w.AddItem(userID, productID)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, productID, items[0].ProductID)
+	})
+
+	t.Run("Missing product", func(t *testing.T) {
+		repo.On("CountWishlistItems", userID).Return(0, nil).Once()
+		repo.On("InsertWishlistItem", userID, productID).Return(sql.ErrNoRows).Once()
+
+		_, err := w.AddItem(userID, productID)
+		assert.ErrorIs(t, err, wishlist.ErrProductNotFound)
+	})
+
+	t.Run("Wishlist full", func(t *testing.T) {
+		repo.On("CountWishlistItems", userID).Return(models.MaxWishlistItems, nil).Once()
+
+		_, err := w.AddItem(userID, productID)
+		assert.ErrorIs(t, err, wishlist.ErrWishlistFull)
+	})
+}
+
+func TestRemoveItem(t *testing.T) {
+	repo := mocks.NewRepo(t)
+	w := usecase.NewWishlistUC(repo)
+	userID, productID := uuid.New(), uuid.New()
+
+	repo.On("DeleteWishlistItem", userID, productID).Return(sql.ErrNoRows).Once()
+
+	assert.ErrorIs(t, w.RemoveItem(userID, productID), wishlist.ErrNotInWishlist)
+}
@@ -0,0 +1 @@
+ALTER TABLE orders ADD COLUMN variant VARCHAR(255) NOT NULL DEFAULT '';
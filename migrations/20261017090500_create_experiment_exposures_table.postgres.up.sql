@@ -0,0 +1,7 @@
+CREATE TABLE experiment_exposures (
+    flag       VARCHAR(100)                     NOT NULL,
+    subject    VARCHAR(100)                     NOT NULL,
+    variant    VARCHAR(20)                      NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE         NOT NULL    DEFAULT NOW(),
+    PRIMARY KEY (flag, subject)
+)
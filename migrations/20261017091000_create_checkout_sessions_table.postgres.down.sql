@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS checkout_session_items;
+DROP TABLE IF EXISTS checkout_sessions;
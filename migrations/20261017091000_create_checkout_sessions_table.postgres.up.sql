@@ -0,0 +1,24 @@
+CREATE TABLE checkout_sessions (
+    session_id        UUID                             NOT NULL    DEFAULT uuid_generate_v4() PRIMARY KEY,
+    user_id           UUID                             NOT NULL,
+    item_price        INTEGER                          NOT NULL,
+    tax_price         INTEGER                          NOT NULL,
+    shipping_price    INTEGER                          NOT NULL,
+    total_price       INTEGER                          NOT NULL,
+    status            VARCHAR(20)                      NOT NULL    DEFAULT 'open',
+    payment_intent_id VARCHAR(255)                     NOT NULL    DEFAULT '',
+    order_id          UUID,
+    expires_at        TIMESTAMP WITH TIME ZONE         NOT NULL,
+    created_at        TIMESTAMP WITH TIME ZONE         NOT NULL    DEFAULT NOW()
+);
+
+CREATE INDEX checkout_sessions_status_expires_at_idx ON checkout_sessions (status, expires_at);
+
+CREATE TABLE checkout_session_items (
+    session_id UUID         NOT NULL REFERENCES checkout_sessions (session_id) ON DELETE CASCADE,
+    product_id UUID         NOT NULL,
+    name       VARCHAR(64)  NOT NULL,
+    price      INTEGER      NOT NULL,
+    quantity   INTEGER      NOT NULL,
+    PRIMARY KEY (session_id, product_id)
+);
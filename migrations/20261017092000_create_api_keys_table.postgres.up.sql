@@ -0,0 +1,11 @@
+CREATE TABLE api_keys (
+    key_id       UUID                             NOT NULL    DEFAULT uuid_generate_v4() PRIMARY KEY,
+    name         VARCHAR(100)                     NOT NULL,
+    hash         BYTEA                            NOT NULL    UNIQUE,
+    scopes       VARCHAR(255)                     NOT NULL,
+    revoked      BOOLEAN                          NOT NULL    DEFAULT FALSE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE         NOT NULL    DEFAULT NOW()
+);
+
+CREATE INDEX orders_created_at_idx ON orders (created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS users_delete_after_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS restore_token_hash;
+ALTER TABLE users DROP COLUMN IF EXISTS delete_after;
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN delete_after TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN restore_token_hash BYTEA;
+
+CREATE INDEX users_delete_after_idx ON users (delete_after) WHERE delete_after IS NOT NULL;
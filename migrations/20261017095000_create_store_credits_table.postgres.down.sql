@@ -0,0 +1,3 @@
+ALTER TABLE checkout_sessions DROP COLUMN IF EXISTS credit_applied;
+
+DROP TABLE IF EXISTS store_credits;
\ No newline at end of file
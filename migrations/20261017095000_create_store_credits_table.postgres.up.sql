@@ -0,0 +1,14 @@
+CREATE TABLE store_credits (
+    entry_id   UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    user_id    UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    amount     INTEGER                  NOT NULL CHECK (amount <> 0),
+    reason     VARCHAR(20)              NOT NULL,
+    note       VARCHAR(255)             NOT NULL DEFAULT '',
+    session_id UUID,
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX store_credits_user_id_created_at_idx ON store_credits (user_id, created_at);
+
+ALTER TABLE checkout_sessions ADD COLUMN credit_applied INTEGER NOT NULL DEFAULT 0;
\ No newline at end of file
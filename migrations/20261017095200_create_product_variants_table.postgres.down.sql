@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS checkout_session_items_line_idx;
+DELETE FROM checkout_session_items WHERE variant_id IS NOT NULL;
+ALTER TABLE checkout_session_items ADD PRIMARY KEY (session_id, product_id);
+ALTER TABLE checkout_session_items ALTER COLUMN name TYPE VARCHAR(64) USING LEFT(name, 64);
+ALTER TABLE checkout_session_items DROP COLUMN IF EXISTS variant_id;
+
+ALTER TABLE order_items ALTER COLUMN name TYPE VARCHAR(100) USING LEFT(name, 100);
+ALTER TABLE order_items DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS product_variants;
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TABLE product_variants (
+    variant_id  UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    product_id  UUID                     NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+    sku         VARCHAR(64)              UNIQUE,
+    attributes  JSONB                    NOT NULL DEFAULT '{}',
+    price_delta INTEGER                  NOT NULL DEFAULT 0,
+    stock       INTEGER                  NOT NULL DEFAULT 0,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX product_variants_product_id_idx ON product_variants (product_id);
+
+ALTER TABLE order_items ADD COLUMN variant_id UUID REFERENCES product_variants (variant_id) ON DELETE SET NULL;
+ALTER TABLE order_items ALTER COLUMN name TYPE VARCHAR(200);
+
+ALTER TABLE checkout_session_items ADD COLUMN variant_id UUID;
+ALTER TABLE checkout_session_items ALTER COLUMN name TYPE VARCHAR(200);
+ALTER TABLE checkout_session_items DROP CONSTRAINT checkout_session_items_pkey;
+CREATE UNIQUE INDEX checkout_session_items_line_idx
+    ON checkout_session_items (session_id, product_id, COALESCE(variant_id, '00000000-0000-0000-0000-000000000000'));
\ No newline at end of file
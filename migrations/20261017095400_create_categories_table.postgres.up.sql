@@ -0,0 +1,33 @@
+CREATE TABLE categories (
+    category_id UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    name        VARCHAR(100)             NOT NULL,
+    parent_id   UUID REFERENCES categories (category_id) ON DELETE RESTRICT,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX categories_name_idx ON categories (LOWER(name));
+CREATE INDEX categories_parent_id_idx ON categories (parent_id);
+
+INSERT INTO categories (name)
+VALUES ('Electronics'), ('Food'), ('Books'), ('Clothes/Shoes'), ('Beauty/Health'), ('Sports'), ('Outdoor'), ('Home');
+
+INSERT INTO categories (name, parent_id)
+SELECT v.name, c.category_id
+FROM categories c, (VALUES ('Cameras'), ('Laptops'), ('Accessories'), ('Headphones')) AS v (name)
+WHERE c.name = 'Electronics';
+
+-- keep every category products are already listed under
+INSERT INTO categories (name)
+SELECT DISTINCT ON (LOWER(category)) LEFT(category, 100)
+FROM products p
+WHERE category <> ''
+  AND NOT EXISTS (SELECT 1 FROM categories c WHERE LOWER(c.name) = LOWER(LEFT(p.category, 100)));
+
+ALTER TABLE products ADD COLUMN category_id UUID REFERENCES categories (category_id) ON DELETE RESTRICT;
+
+UPDATE products p
+SET category_id = c.category_id, category = c.name
+FROM categories c
+WHERE LOWER(c.name) = LOWER(LEFT(p.category, 100));
+
+CREATE INDEX products_category_id_idx ON products (category_id);
\ No newline at end of file
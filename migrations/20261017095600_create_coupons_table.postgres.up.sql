@@ -0,0 +1,29 @@
+CREATE TABLE coupons (
+    coupon_id         UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    code              VARCHAR(50)              NOT NULL,
+    type              VARCHAR(20)              NOT NULL CHECK (type IN ('percentage', 'fixed')),
+    value             INTEGER                  NOT NULL CHECK (value > 0),
+    min_order_value   INTEGER                  NOT NULL DEFAULT 0,
+    max_uses          INTEGER                  NOT NULL DEFAULT 0,
+    max_uses_per_user INTEGER                  NOT NULL DEFAULT 0,
+    used_count        INTEGER                  NOT NULL DEFAULT 0,
+    expires_at        TIMESTAMP WITH TIME ZONE,
+    active            BOOLEAN                  NOT NULL DEFAULT TRUE,
+    created_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX coupons_code_idx ON coupons (UPPER(code));
+
+CREATE TABLE coupon_redemptions (
+    redemption_id UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    coupon_id     UUID                     NOT NULL REFERENCES coupons (coupon_id) ON DELETE CASCADE,
+    user_id       UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    order_id      UUID                     REFERENCES orders (order_id) ON DELETE SET NULL,
+    discount      INTEGER                  NOT NULL,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX coupon_redemptions_coupon_id_user_id_idx ON coupon_redemptions (coupon_id, user_id);
+
+ALTER TABLE orders ADD COLUMN coupon_code VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN discount INTEGER NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE notification_preferences (
+    user_id    UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    event      VARCHAR(50)              NOT NULL,
+    email      BOOLEAN                  NOT NULL,
+    sms        BOOLEAN                  NOT NULL,
+    push       BOOLEAN                  NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, event)
+);
\ No newline at end of file
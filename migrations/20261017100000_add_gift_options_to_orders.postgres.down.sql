@@ -0,0 +1,3 @@
+ALTER TABLE orders DROP COLUMN hide_prices;
+ALTER TABLE orders DROP COLUMN gift_message;
+ALTER TABLE orders DROP COLUMN gift;
\ No newline at end of file
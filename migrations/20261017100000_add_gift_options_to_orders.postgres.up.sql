@@ -0,0 +1,3 @@
+ALTER TABLE orders ADD COLUMN gift BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE orders ADD COLUMN gift_message VARCHAR(500) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN hide_prices BOOLEAN NOT NULL DEFAULT false;
\ No newline at end of file
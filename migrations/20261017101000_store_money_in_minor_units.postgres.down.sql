@@ -0,0 +1,20 @@
+UPDATE coupons SET value = value / 100 WHERE type = 'fixed';
+ALTER TABLE coupons ALTER COLUMN min_order_value TYPE INTEGER USING min_order_value / 100;
+ALTER TABLE coupon_redemptions ALTER COLUMN discount TYPE INTEGER USING discount / 100;
+ALTER TABLE store_credits ALTER COLUMN amount TYPE INTEGER USING amount / 100;
+ALTER TABLE checkout_session_items ALTER COLUMN price TYPE INTEGER USING price / 100;
+ALTER TABLE checkout_sessions
+    ALTER COLUMN item_price TYPE INTEGER USING item_price / 100,
+    ALTER COLUMN tax_price TYPE INTEGER USING tax_price / 100,
+    ALTER COLUMN shipping_price TYPE INTEGER USING shipping_price / 100,
+    ALTER COLUMN credit_applied TYPE INTEGER USING credit_applied / 100,
+    ALTER COLUMN total_price TYPE INTEGER USING total_price / 100;
+ALTER TABLE order_items ALTER COLUMN price TYPE INTEGER USING price / 100;
+ALTER TABLE orders
+    ALTER COLUMN item_price TYPE INTEGER USING item_price / 100,
+    ALTER COLUMN tax_price TYPE INTEGER USING tax_price / 100,
+    ALTER COLUMN shipping_price TYPE INTEGER USING shipping_price / 100,
+    ALTER COLUMN total_price TYPE INTEGER USING total_price / 100,
+    ALTER COLUMN discount TYPE INTEGER USING discount / 100;
+ALTER TABLE product_variants ALTER COLUMN price_delta TYPE INTEGER USING price_delta / 100;
+ALTER TABLE products ALTER COLUMN price TYPE INTEGER USING price / 100;
\ No newline at end of file
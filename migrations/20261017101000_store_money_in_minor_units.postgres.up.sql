@@ -0,0 +1,22 @@
+-- money is stored in minor units (cents) of the shop currency instead of whole units
+ALTER TABLE products ALTER COLUMN price TYPE BIGINT USING price::BIGINT * 100;
+ALTER TABLE product_variants ALTER COLUMN price_delta TYPE BIGINT USING price_delta::BIGINT * 100;
+ALTER TABLE orders
+    ALTER COLUMN item_price TYPE BIGINT USING item_price::BIGINT * 100,
+    ALTER COLUMN tax_price TYPE BIGINT USING tax_price::BIGINT * 100,
+    ALTER COLUMN shipping_price TYPE BIGINT USING shipping_price::BIGINT * 100,
+    ALTER COLUMN total_price TYPE BIGINT USING total_price::BIGINT * 100,
+    ALTER COLUMN discount TYPE BIGINT USING discount::BIGINT * 100;
+ALTER TABLE order_items ALTER COLUMN price TYPE BIGINT USING price::BIGINT * 100;
+ALTER TABLE checkout_sessions
+    ALTER COLUMN item_price TYPE BIGINT USING item_price::BIGINT * 100,
+    ALTER COLUMN tax_price TYPE BIGINT USING tax_price::BIGINT * 100,
+    ALTER COLUMN shipping_price TYPE BIGINT USING shipping_price::BIGINT * 100,
+    ALTER COLUMN credit_applied TYPE BIGINT USING credit_applied::BIGINT * 100,
+    ALTER COLUMN total_price TYPE BIGINT USING total_price::BIGINT * 100;
+ALTER TABLE checkout_session_items ALTER COLUMN price TYPE BIGINT USING price::BIGINT * 100;
+ALTER TABLE store_credits ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100;
+ALTER TABLE coupon_redemptions ALTER COLUMN discount TYPE BIGINT USING discount::BIGINT * 100;
+-- the value of a percentage coupon stays a percentage
+ALTER TABLE coupons ALTER COLUMN min_order_value TYPE BIGINT USING min_order_value::BIGINT * 100;
+UPDATE coupons SET value = value * 100 WHERE type = 'fixed';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS payments_requires_capture_idx;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE INDEX payments_requires_capture_idx ON payments (created_at) WHERE status = 'requires_capture';
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN IF EXISTS currency;
+ALTER TABLE checkout_sessions DROP COLUMN IF EXISTS currency;
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
\ No newline at end of file
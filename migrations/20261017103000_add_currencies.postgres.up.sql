@@ -0,0 +1,5 @@
+-- existing prices, orders and sessions are in the shop currency; use its code instead of USD if it differs
+ALTER TABLE products ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE orders ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE checkout_sessions ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE users ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT '';
\ No newline at end of file
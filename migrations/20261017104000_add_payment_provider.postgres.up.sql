@@ -0,0 +1 @@
+ALTER TABLE payments ADD COLUMN provider VARCHAR(20) NOT NULL DEFAULT 'stripe';
\ No newline at end of file
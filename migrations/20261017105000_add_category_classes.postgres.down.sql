@@ -0,0 +1,6 @@
+ALTER TABLE products DROP COLUMN IF EXISTS hidden;
+ALTER TABLE products DROP COLUMN IF EXISTS shipping_class;
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class;
+ALTER TABLE categories DROP COLUMN IF EXISTS hidden;
+ALTER TABLE categories DROP COLUMN IF EXISTS shipping_class;
+ALTER TABLE categories DROP COLUMN IF EXISTS tax_class;
\ No newline at end of file
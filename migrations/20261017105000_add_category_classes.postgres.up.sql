@@ -0,0 +1,6 @@
+ALTER TABLE categories ADD COLUMN tax_class VARCHAR(20) NOT NULL DEFAULT 'standard';
+ALTER TABLE categories ADD COLUMN shipping_class VARCHAR(20) NOT NULL DEFAULT 'standard';
+ALTER TABLE categories ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE products ADD COLUMN tax_class VARCHAR(20) NOT NULL DEFAULT 'standard';
+ALTER TABLE products ADD COLUMN shipping_class VARCHAR(20) NOT NULL DEFAULT 'standard';
+ALTER TABLE products ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
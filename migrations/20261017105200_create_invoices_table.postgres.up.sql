@@ -0,0 +1,6 @@
+CREATE TABLE invoices (
+    order_id   UUID PRIMARY KEY         NOT NULL REFERENCES orders (order_id) ON DELETE CASCADE,
+    url        TEXT                     NOT NULL,
+    public_id  TEXT                     NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS search_events;
\ No newline at end of file
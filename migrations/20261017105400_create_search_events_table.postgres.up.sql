@@ -0,0 +1,11 @@
+CREATE TABLE search_events (
+    event_id   UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    event      VARCHAR(10)              NOT NULL,
+    keyword    VARCHAR(100)             NOT NULL,
+    results    INTEGER                  NOT NULL DEFAULT 0,
+    search_id  UUID REFERENCES search_events (event_id) ON DELETE CASCADE,
+    product_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX search_events_zero_results_idx ON search_events (created_at, keyword) WHERE event = 'search' AND results = 0;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS search_synonyms;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE search_synonyms (
+    synonym_id UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    terms      TEXT                     NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
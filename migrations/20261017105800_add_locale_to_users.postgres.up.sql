@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN locale VARCHAR(8) NOT NULL DEFAULT '';
\ No newline at end of file
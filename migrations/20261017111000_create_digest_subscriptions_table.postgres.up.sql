@@ -0,0 +1,6 @@
+CREATE TABLE digest_subscriptions (
+    user_id      UUID PRIMARY KEY         NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    frequency    VARCHAR(10)              NOT NULL,
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    updated_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TABLE webhooks (
+    webhook_id   UUID                             NOT NULL    DEFAULT uuid_generate_v4() PRIMARY KEY,
+    url          VARCHAR(2048)                    NOT NULL,
+    events       VARCHAR(255)                     NOT NULL,
+    secret       VARCHAR(100)                     NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE         NOT NULL    DEFAULT NOW()
+);
+
+CREATE TABLE webhook_deliveries (
+    delivery_id     UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    webhook_id      UUID                     NOT NULL REFERENCES webhooks (webhook_id) ON DELETE CASCADE,
+    event_id        UUID                     NOT NULL,
+    event           VARCHAR(50)              NOT NULL,
+    payload         JSONB                    NOT NULL,
+    status          VARCHAR(20)              NOT NULL DEFAULT 'pending',
+    attempts        INTEGER                  NOT NULL DEFAULT 0,
+    response_status INTEGER                  NOT NULL DEFAULT 0,
+    last_error      TEXT                     NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    delivered_at    TIMESTAMP WITH TIME ZONE,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, created_at);
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE magic_links (
+    magic_link_id UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    user_id       UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    token_hash    BYTEA                    NOT NULL UNIQUE,
+    device_hash   BYTEA                    NOT NULL,
+    ip            VARCHAR(45)              NOT NULL DEFAULT '',
+    user_agent    VARCHAR(512)             NOT NULL DEFAULT '',
+    expiry        TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at       TIMESTAMP WITH TIME ZONE,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX magic_links_expiry_idx ON magic_links (expiry);
\ No newline at end of file
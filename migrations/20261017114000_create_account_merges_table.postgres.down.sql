@@ -0,0 +1 @@
+DROP TABLE IF EXISTS account_merges;
\ No newline at end of file
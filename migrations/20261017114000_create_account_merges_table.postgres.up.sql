@@ -0,0 +1,14 @@
+CREATE TABLE account_merges (
+    merge_id       UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    kept_user_id   UUID                     NOT NULL,
+    merged_user_id UUID                     NOT NULL,
+    merged_name    VARCHAR(64)              NOT NULL,
+    merged_email   VARCHAR(64)              NOT NULL,
+    orders         INTEGER                  NOT NULL,
+    reviews        INTEGER                  NOT NULL,
+    store_credits  INTEGER                  NOT NULL,
+    merged_by      UUID                     NOT NULL,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX account_merges_kept_user_id_idx ON account_merges (kept_user_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS cleanup_confirmations;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE cleanup_confirmations (
+    cleanup_confirmation_id UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    admin_id                UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    target                  VARCHAR(32)              NOT NULL,
+    before                  TIMESTAMP WITH TIME ZONE NOT NULL,
+    token_hash              BYTEA                    NOT NULL UNIQUE,
+    digest                  BYTEA                    NOT NULL,
+    matched                 INTEGER                  NOT NULL,
+    expiry                  TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at                 TIMESTAMP WITH TIME ZONE,
+    created_at              TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX cleanup_confirmations_expiry_idx ON cleanup_confirmations (expiry);
\ No newline at end of file
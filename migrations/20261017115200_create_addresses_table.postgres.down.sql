@@ -0,0 +1 @@
+DROP TABLE IF EXISTS addresses;
\ No newline at end of file
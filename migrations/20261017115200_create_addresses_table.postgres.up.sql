@@ -0,0 +1,18 @@
+CREATE TABLE addresses (
+    address_id  UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    user_id     UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    label       VARCHAR(50)              NOT NULL DEFAULT '',
+    address     VARCHAR(100)             NOT NULL CHECK ( address <> '' ),
+    city        VARCHAR(100)             NOT NULL CHECK ( city <> '' ),
+    phone       VARCHAR(100)             NOT NULL CHECK ( phone <> '' ),
+    postal      VARCHAR(100)             NOT NULL CHECK ( postal <> '' ),
+    country     VARCHAR(100)             NOT NULL CHECK ( country <> '' ),
+    is_default  BOOLEAN                  NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX addresses_user_id_idx ON addresses (user_id);
+
+-- a user has at most one default address
+CREATE UNIQUE INDEX addresses_default_idx ON addresses (user_id) WHERE is_default;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE wishlist_items (
+    user_id    UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    product_id UUID                     NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, product_id)
+);
+
+-- who wishlisted a product is looked up when its price drops
+CREATE INDEX wishlist_items_product_id_idx ON wishlist_items (product_id);
\ No newline at end of file
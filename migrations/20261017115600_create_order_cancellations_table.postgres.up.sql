@@ -0,0 +1,6 @@
+CREATE TABLE order_cancellations (
+    order_id   UUID                     NOT NULL PRIMARY KEY REFERENCES orders (order_id) ON DELETE CASCADE,
+    user_id    UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    reason     VARCHAR(500)             NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
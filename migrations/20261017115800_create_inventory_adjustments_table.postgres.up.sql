@@ -0,0 +1,14 @@
+CREATE TABLE inventory_adjustments (
+    adjustment_id UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    product_id    UUID                     NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+    variant_id    UUID                     REFERENCES product_variants (variant_id) ON DELETE SET NULL,
+    quantity      INTEGER                  NOT NULL CHECK ( quantity <> 0 ),
+    reason        VARCHAR(20)              NOT NULL,
+    -- the order stays in the log after it is deleted
+    order_id      UUID,
+    user_id       UUID                     REFERENCES users (user_id) ON DELETE SET NULL,
+    note          VARCHAR(500)             NOT NULL DEFAULT '',
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX inventory_adjustments_product_id_idx ON inventory_adjustments (product_id, created_at DESC);
\ No newline at end of file
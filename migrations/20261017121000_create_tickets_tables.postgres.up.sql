@@ -0,0 +1,24 @@
+CREATE TABLE tickets (
+    ticket_id  UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    user_id    UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    -- the ticket stays open for support after its order is deleted
+    order_id   UUID                     REFERENCES orders (order_id) ON DELETE SET NULL,
+    subject    VARCHAR(150)             NOT NULL,
+    status     VARCHAR(20)              NOT NULL DEFAULT 'open' CHECK ( status IN ('open', 'answered', 'closed') ),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX tickets_user_id_idx ON tickets (user_id, updated_at DESC);
+CREATE INDEX tickets_status_idx ON tickets (status, updated_at DESC);
+
+CREATE TABLE ticket_messages (
+    message_id UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    ticket_id  UUID                     NOT NULL REFERENCES tickets (ticket_id) ON DELETE CASCADE,
+    user_id    UUID                     REFERENCES users (user_id) ON DELETE SET NULL,
+    from_admin BOOLEAN                  NOT NULL DEFAULT FALSE,
+    body       VARCHAR(5000)            NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ticket_messages_ticket_id_idx ON ticket_messages (ticket_id, created_at);
\ No newline at end of file
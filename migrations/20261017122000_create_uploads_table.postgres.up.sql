@@ -0,0 +1,12 @@
+CREATE TABLE uploads (
+    public_id  VARCHAR(255)             NOT NULL PRIMARY KEY,
+    user_id    UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    kind       VARCHAR(20)              NOT NULL,
+    url        TEXT                     NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    -- set once the upload is attached to the record it was made for
+    claimed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX uploads_pending_idx ON uploads (expires_at) WHERE claimed_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE data_exports (
+    export_id  UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    -- kept when the admin is deleted, the email still tells who exported
+    user_id    UUID                     REFERENCES users (user_id) ON DELETE SET NULL,
+    email      VARCHAR(255)             NOT NULL,
+    kind       VARCHAR(20)              NOT NULL,
+    filters    JSONB                    NOT NULL DEFAULT '{}',
+    row_count  INTEGER                  NOT NULL,
+    bytes      BIGINT                   NOT NULL,
+    sha256     CHAR(64)                 NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX data_exports_created_at_idx ON data_exports (created_at DESC);
\ No newline at end of file
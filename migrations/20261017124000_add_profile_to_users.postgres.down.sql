@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS newsletter;
+ALTER TABLE users DROP COLUMN IF EXISTS date_of_birth;
+ALTER TABLE users DROP COLUMN IF EXISTS phone;
\ No newline at end of file
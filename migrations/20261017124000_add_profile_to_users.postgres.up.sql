@@ -0,0 +1,4 @@
+-- E.164 numbers are at most 15 digits after the plus sign
+ALTER TABLE users ADD COLUMN phone VARCHAR(16);
+ALTER TABLE users ADD COLUMN date_of_birth DATE;
+ALTER TABLE users ADD COLUMN newsletter BOOLEAN NOT NULL DEFAULT false;
\ No newline at end of file
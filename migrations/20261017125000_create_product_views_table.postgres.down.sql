@@ -0,0 +1 @@
+DROP TABLE IF EXISTS product_views;
\ No newline at end of file
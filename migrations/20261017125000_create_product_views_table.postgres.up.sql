@@ -0,0 +1,13 @@
+CREATE TABLE product_views (
+    view_id    UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    product_id UUID                     NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+    -- set for signed in users, session for anonymous visitors
+    user_id    UUID                     REFERENCES users (user_id) ON DELETE CASCADE,
+    session_id VARCHAR(64),
+    viewed_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (user_id IS NOT NULL OR session_id IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX product_views_user_idx ON product_views (user_id, product_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX product_views_session_idx ON product_views (session_id, product_id) WHERE user_id IS NULL;
+CREATE INDEX product_views_viewed_at_idx ON product_views (viewed_at DESC);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS order_items_product_id_idx;
+DROP TABLE IF EXISTS review_images;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE review_images (
+    public_id  VARCHAR(300)             NOT NULL PRIMARY KEY,
+    url        VARCHAR(300)             NOT NULL,
+    review_id  UUID                     NOT NULL REFERENCES reviews (reviews_id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX review_images_review_id_idx ON review_images (review_id);
+
+-- verified purchases are looked up by the user and product of the review
+CREATE INDEX IF NOT EXISTS order_items_product_id_idx ON order_items (product_id);
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE review_votes (
+    review_id  UUID                     NOT NULL REFERENCES reviews (reviews_id) ON DELETE CASCADE,
+    user_id    UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    helpful    BOOLEAN                  NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    -- a user has one vote per review, changed by voting again
+    PRIMARY KEY (review_id, user_id)
+);
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE products ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'published'
+    CHECK ( status IN ('draft', 'published', 'archived') );
+-- when a draft is published by the scheduler
+ALTER TABLE products ADD COLUMN publish_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX products_publish_at_idx ON products (publish_at) WHERE status = 'draft' AND publish_at IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,23 @@
+-- emails are unique regardless of case, so that two registrations racing with the same
+-- address, or the same address in another case, cannot both create an account.
+-- Accounts whose emails differ only in case must be merged by hand first: which one to
+-- keep, with its orders and reviews, is not for a migration to decide.
+DO $$
+DECLARE
+    duplicates text;
+BEGIN
+    SELECT string_agg(email, ', ' ORDER BY email) INTO duplicates
+    FROM (SELECT LOWER(email) AS email FROM users GROUP BY LOWER(email) HAVING count(*) > 1) d;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'users share emails that differ only in case: %', duplicates
+            USING HINT = 'merge or rename these accounts, then run the migration again';
+    END IF;
+END
+$$;
+
+CREATE UNIQUE INDEX users_email_lower_idx ON users (LOWER(email));
+
+-- the case-insensitive index covers the exact one, and a single constraint reports
+-- duplicate emails
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
@@ -0,0 +1,6 @@
+-- every sign-in gets a token of its own, listed to its user as a session with the
+-- device it was made from
+ALTER TABLE tokens ADD COLUMN user_agent VARCHAR(512) NOT NULL DEFAULT '';
+ALTER TABLE tokens ADD COLUMN ip VARCHAR(45) NOT NULL DEFAULT '';
+
+CREATE INDEX tokens_user_id_idx ON tokens (user_id);
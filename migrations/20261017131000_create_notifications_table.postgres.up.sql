@@ -0,0 +1,13 @@
+CREATE TABLE notifications (
+    notification_id UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    user_id         UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    event           VARCHAR(50)              NOT NULL,
+    title           VARCHAR(255)             NOT NULL,
+    body            VARCHAR(2000)            NOT NULL DEFAULT '',
+    read_at         TIMESTAMP WITH TIME ZONE,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, created_at DESC);
+-- the unread count of the bell
+CREATE INDEX notifications_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
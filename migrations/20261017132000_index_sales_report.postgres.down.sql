@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS payments_refund_pending_idx;
+DROP INDEX IF EXISTS order_cancellations_created_at_idx;
@@ -0,0 +1,2 @@
+CREATE INDEX order_cancellations_created_at_idx ON order_cancellations (created_at);
+CREATE INDEX payments_refund_pending_idx ON payments (order_id) WHERE status = 'refund_pending';
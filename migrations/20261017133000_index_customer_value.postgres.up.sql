@@ -0,0 +1,3 @@
+-- the orders of a customer that count towards their lifetime value, read without visiting the table
+CREATE INDEX orders_customer_value_idx ON orders (currency, user_id) INCLUDE (total_price, created_at)
+    WHERE order_status <> 'Cancelled';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS products_user_id_idx;
+DROP TABLE IF EXISTS seller_applications;
@@ -0,0 +1,18 @@
+CREATE TABLE seller_applications (
+    application_id UUID                     NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    user_id        UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    store_name     VARCHAR(100)             NOT NULL,
+    message        VARCHAR(2000)            NOT NULL DEFAULT '',
+    status         VARCHAR(20)              NOT NULL DEFAULT 'pending' CHECK ( status IN ('pending', 'approved', 'rejected') ),
+    -- the admin who approved or rejected the application
+    reviewed_by    UUID                     REFERENCES users (user_id) ON DELETE SET NULL,
+    reviewed_at    TIMESTAMP WITH TIME ZONE,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- a user has at most one application awaiting review
+CREATE UNIQUE INDEX seller_applications_pending_idx ON seller_applications (user_id) WHERE status = 'pending';
+CREATE INDEX seller_applications_status_idx ON seller_applications (status, created_at DESC);
+
+-- the products of a seller, listed by them and by admins
+CREATE INDEX products_user_id_idx ON products (user_id, created_at);
@@ -0,0 +1,2 @@
+CREATE INDEX orders_created_at_idx ON orders (created_at);
+DROP INDEX IF EXISTS orders_created_at_order_id_idx;
@@ -0,0 +1,3 @@
+-- the orders feed of integrations reads orders after a (created_at, order_id) cursor
+CREATE INDEX orders_created_at_order_id_idx ON orders (created_at, order_id);
+DROP INDEX IF EXISTS orders_created_at_idx;
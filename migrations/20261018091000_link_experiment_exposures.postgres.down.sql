@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS experiment_exposures_subject_idx;
+
+ALTER TABLE experiment_exposures DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,5 @@
+-- the user an anonymous subject turned out to be once they signed in, for their orders
+-- to convert the exposures made before
+ALTER TABLE experiment_exposures ADD COLUMN user_id UUID REFERENCES users (user_id) ON DELETE SET NULL;
+
+CREATE INDEX experiment_exposures_subject_idx ON experiment_exposures (subject) WHERE user_id IS NULL;
@@ -0,0 +1,183 @@
+// Package audit records the data exports admins download.
+//
+// Export wraps the route of a CSV export: it hashes and counts the rows of the file
+// as it is streamed to the client, and once the response is complete records who
+// downloaded it and with which filters. Routes sending files of other formats, whose
+// rows cannot be counted from their bytes, report their rows with SetRows. The admin is always the authenticated user of
+// the request, never a value the client sends, so an export cannot be attributed to
+// someone else.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// Recorder persists the audit records of data exports.
+type Recorder interface {
+	RecordExport(e models.DataExport) error
+}
+
+type contextKey string
+
+const (
+	auditorContextKey contextKey = "audit"
+	exportContextKey  contextKey = "audit.export"
+)
+
+// Auditor records data exports with a Recorder.
+type Auditor struct {
+	recorder Recorder
+	logger   logger.Logger
+}
+
+// New returns an Auditor recording exports with recorder. Recording failures are
+// logged with logger, as the file has already been sent by then.
+func New(recorder Recorder, logger logger.Logger) *Auditor {
+	return &Auditor{
+		recorder: recorder,
+		logger:   logger,
+	}
+}
+
+// Middleware makes the auditor available to the routes wrapped with Export.
+func (a *Auditor) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), auditorContextKey, a)))
+	})
+}
+
+// Export records every successful response of the wrapped route as a data export of
+// kind, its query parameters as the filters. The response must be a CSV file with a
+// header line, which is not counted as a row, unless the route reports its rows
+// with SetRows. It must be used after
+// utils.IsAuthenticated; routes served without an Auditor are not recorded.
+func Export(kind string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a, ok := r.Context().Value(auditorContextKey).(*Auditor)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ew := &exportWriter{ResponseWriter: w, hash: sha256.New()}
+			r = r.WithContext(context.WithValue(r.Context(), exportContextKey, ew))
+			next.ServeHTTP(ew, r)
+
+			if ew.status != http.StatusOK {
+				return
+			}
+			a.record(r, kind, ew)
+		})
+	}
+}
+
+// SetRows sets the rows of the file sent in response to r, recorded instead of the
+// CSV records counted in it. It does nothing on routes not wrapped with Export.
+func SetRows(r *http.Request, rows int) {
+	if ew, ok := r.Context().Value(exportContextKey).(*exportWriter); ok {
+		ew.setRows = &rows
+	}
+}
+
+// record saves the export sent through ew.
+func (a *Auditor) record(r *http.Request, kind string, ew *exportWriter) {
+	e := models.DataExport{
+		Kind:    kind,
+		Filters: make(map[string]string),
+		Rows:    ew.rows(),
+		Bytes:   ew.bytes,
+		SHA256:  hex.EncodeToString(ew.hash.Sum(nil)),
+	}
+	if user, ok := r.Context().Value(utils.UserContextKey).(*models.User); ok {
+		e.UserID = uuid.NullUUID{UUID: user.ID, Valid: true}
+		e.Email = user.Email
+	}
+	for name, values := range r.URL.Query() {
+		e.Filters[name] = values[0]
+	}
+
+	if err := a.recorder.RecordExport(e); err != nil {
+		a.logger.Errorf("error recording %s export of %s: %v", kind, e.Email, err)
+	}
+}
+
+// exportWriter hashes and counts the CSV records of the response written through it.
+type exportWriter struct {
+	http.ResponseWriter
+	status  int
+	hash    hash.Hash
+	bytes   int64
+	records int
+	// quoted is set inside a quoted field, where line breaks do not end a record
+	quoted bool
+	// open is set when the last record has no line break yet
+	open bool
+	// setRows is the rows reported by the route with SetRows
+	setRows *int
+}
+
+func (w *exportWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(p)
+	w.hash.Write(p[:n])
+	w.bytes += int64(n)
+	for _, b := range p[:n] {
+		switch {
+		case b == '"':
+			w.quoted = !w.quoted
+			w.open = true
+		case b == '\n' && !w.quoted:
+			w.records++
+			w.open = false
+		default:
+			w.open = true
+		}
+	}
+
+	return n, err
+}
+
+// Flush sends the buffered response to the client, when the wrapped writer can.
+func (w *exportWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// rows returns the rows reported with SetRows, or else the records written, leaving
+// out the header.
+func (w *exportWriter) rows() int {
+	if w.setRows != nil {
+		return *w.setRows
+	}
+
+	records := w.records
+	if w.open {
+		records++
+	}
+	if records == 0 {
+		return 0
+	}
+
+	return records - 1
+}
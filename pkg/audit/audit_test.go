@@ -0,0 +1,103 @@
+package audit_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/audit"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	exports []models.DataExport
+	err     error
+}
+
+func (r *recorder) RecordExport(e models.DataExport) error {
+	r.exports = append(r.exports, e)
+	return r.err
+}
+
+func TestExport(t *testing.T) {
+	admin := &models.User{ID: uuid.New(), Email: "admin@shopit.com", Role: models.RoleAdmin}
+	csv := "order_id,gift\n1,\"line\none\"\n2,false\n"
+
+	serve := func(a *audit.Auditor, h http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/export?from=2026-01-01&anonymize=true", nil)
+		req = req.WithContext(context.WithValue(req.Context(), utils.UserContextKey, admin))
+		rr := httptest.NewRecorder()
+		handler := audit.Export(models.ExportOrders)(h)
+		if a != nil {
+			handler = a.Middleware(handler)
+		}
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Export is recorded", func(t *testing.T) {
+		rec := &recorder{}
+		rr := serve(audit.New(rec, mockLogger.NewLogger(t)), func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(csv[:20]))
+			_, _ = w.Write([]byte(csv[20:]))
+		})
+
+		require.Equal(t, csv, rr.Body.String())
+		require.Len(t, rec.exports, 1)
+
+		sum := sha256.Sum256([]byte(csv))
+		e := rec.exports[0]
+		assert.Equal(t, uuid.NullUUID{UUID: admin.ID, Valid: true}, e.UserID)
+		assert.Equal(t, "admin@shopit.com", e.Email)
+		assert.Equal(t, models.ExportOrders, e.Kind)
+		assert.Equal(t, map[string]string{"from": "2026-01-01", "anonymize": "true"}, e.Filters)
+		assert.Equal(t, 2, e.Rows)
+		assert.Equal(t, int64(len(csv)), e.Bytes)
+		assert.Equal(t, hex.EncodeToString(sum[:]), e.SHA256)
+	})
+
+	t.Run("Rows reported by the route are recorded", func(t *testing.T) {
+		rec := &recorder{}
+		serve(audit.New(rec, mockLogger.NewLogger(t)), func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("PK\x03\x04\n\n\n"))
+			audit.SetRows(r, 12)
+		})
+
+		require.Len(t, rec.exports, 1)
+		assert.Equal(t, 12, rec.exports[0].Rows)
+	})
+
+	t.Run("Failed export is not recorded", func(t *testing.T) {
+		rec := &recorder{}
+		serve(audit.New(rec, mockLogger.NewLogger(t)), func(w http.ResponseWriter, r *http.Request) {
+			_ = utils.BadRequest(w, r, errors.New("from must be a date"))
+		})
+
+		assert.Empty(t, rec.exports)
+	})
+
+	t.Run("Recording failure is logged", func(t *testing.T) {
+		logger := mockLogger.NewLogger(t)
+		logger.On("Errorf", mock.Anything, models.ExportOrders, "admin@shopit.com", mock.Anything).Once()
+
+		rr := serve(audit.New(&recorder{err: errors.New("database error")}, logger),
+			func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(csv)) })
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Without an auditor the export is only served", func(t *testing.T) {
+		rr := serve(nil, func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(csv)) })
+
+		assert.Equal(t, csv, rr.Body.String())
+	})
+}
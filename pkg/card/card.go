@@ -1,14 +1,26 @@
 package card
 
 import (
+	"strings"
+
+	"github.com/jofosuware/go/shopit/pkg/money"
 	"github.com/stripe/stripe-go/v72"
 	"github.com/stripe/stripe-go/v72/paymentintent"
 )
 
 // Carder is the interface to card type
 type Carder interface {
-	// CreatePaymentIntent attempts to get a payment intent object from Stripe
-	CreatePaymentIntent(currency string, amount int) (*stripe.PaymentIntent, string, error)
+	// CreatePaymentIntent attempts to get a payment intent object from Stripe for amount
+	CreatePaymentIntent(amount money.Money) (*stripe.PaymentIntent, string, error)
+
+	// GetPaymentIntent fetches a payment intent from Stripe
+	GetPaymentIntent(intentID string) (*stripe.PaymentIntent, error)
+
+	// CapturePayment captures the amount authorized by a payment intent
+	CapturePayment(intentID string) (*stripe.PaymentIntent, error)
+
+	// VoidPayment cancels a payment intent, releasing the amount it authorized
+	VoidPayment(intentID string) (*stripe.PaymentIntent, error)
 }
 
 // Card holds the information needed by this package
@@ -16,16 +28,29 @@ type Card struct {
 	Secret   string
 	Key      string
 	Currency string
+	// ManualCapture only authorizes payment intents; they are charged by CapturePayment
+	ManualCapture bool
 }
 
-// CreatePaymentIntent attempts to get a payment intent object from Stripe
-func (c *Card) CreatePaymentIntent(currency string, amount int) (*stripe.PaymentIntent, string, error) {
+// CreatePaymentIntent attempts to get a payment intent object from Stripe for amount,
+// charged in its currency or in Currency when it has none. Stripe takes amounts in
+// minor units with a lower case currency code.
+func (c *Card) CreatePaymentIntent(amount money.Money) (*stripe.PaymentIntent, string, error) {
 	stripe.Key = c.Secret
 
+	currency := amount.Currency
+	if currency == "" {
+		currency = c.Currency
+	}
+
 	// create a payment intent
 	params := &stripe.PaymentIntentParams{
-		Amount:   stripe.Int64(int64(amount)),
-		Currency: stripe.String(currency),
+		Amount:   stripe.Int64(amount.Amount),
+		Currency: stripe.String(strings.ToLower(currency)),
+	}
+
+	if c.ManualCapture {
+		params.CaptureMethod = stripe.String(string(stripe.PaymentIntentCaptureMethodManual))
 	}
 
 	params.AddMetadata("integration_check", "accept_a_payment")
@@ -41,6 +66,30 @@ func (c *Card) CreatePaymentIntent(currency string, amount int) (*stripe.Payment
 	return pi, "", nil
 }
 
+// GetPaymentIntent fetches the payment intent intentID.
+func (c *Card) GetPaymentIntent(intentID string) (*stripe.PaymentIntent, error) {
+	stripe.Key = c.Secret
+
+	return paymentintent.Get(intentID, nil)
+}
+
+// CapturePayment captures the whole amount authorized by the payment intent intentID.
+func (c *Card) CapturePayment(intentID string) (*stripe.PaymentIntent, error) {
+	stripe.Key = c.Secret
+
+	return paymentintent.Capture(intentID, &stripe.PaymentIntentCaptureParams{})
+}
+
+// VoidPayment cancels the payment intent intentID as abandoned, so the amount it
+// authorized is released to the customer.
+func (c *Card) VoidPayment(intentID string) (*stripe.PaymentIntent, error) {
+	stripe.Key = c.Secret
+
+	return paymentintent.Cancel(intentID, &stripe.PaymentIntentCancelParams{
+		CancellationReason: stripe.String(string(stripe.PaymentIntentCancellationReasonAbandoned)),
+	})
+}
+
 // cardErrorMessage returns human-readable versions of card error messages
 func cardErrorMessage(code stripe.ErrorCode) string {
 	var msg = ""
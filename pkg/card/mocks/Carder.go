@@ -3,7 +3,9 @@
 package mocks
 
 import (
+	money "github.com/jofosuware/go/shopit/pkg/money"
 	mock "github.com/stretchr/testify/mock"
+
 	stripe "github.com/stripe/stripe-go/v72"
 )
 
@@ -12,9 +14,39 @@ type Carder struct {
 	mock.Mock
 }
 
-// CreatePaymentIntent provides a mock function with given fields: currency, amount
-func (_m *Carder) CreatePaymentIntent(currency string, amount int) (*stripe.PaymentIntent, string, error) {
-	ret := _m.Called(currency, amount)
+// CapturePayment provides a mock function with given fields: intentID
+func (_m *Carder) CapturePayment(intentID string) (*stripe.PaymentIntent, error) {
+	ret := _m.Called(intentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CapturePayment")
+	}
+
+	var r0 *stripe.PaymentIntent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*stripe.PaymentIntent, error)); ok {
+		return rf(intentID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *stripe.PaymentIntent); ok {
+		r0 = rf(intentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*stripe.PaymentIntent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(intentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePaymentIntent provides a mock function with given fields: amount
+func (_m *Carder) CreatePaymentIntent(amount money.Money) (*stripe.PaymentIntent, string, error) {
+	ret := _m.Called(amount)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreatePaymentIntent")
@@ -23,25 +55,25 @@ func (_m *Carder) CreatePaymentIntent(currency string, amount int) (*stripe.Paym
 	var r0 *stripe.PaymentIntent
 	var r1 string
 	var r2 error
-	if rf, ok := ret.Get(0).(func(string, int) (*stripe.PaymentIntent, string, error)); ok {
-		return rf(currency, amount)
+	if rf, ok := ret.Get(0).(func(money.Money) (*stripe.PaymentIntent, string, error)); ok {
+		return rf(amount)
 	}
-	if rf, ok := ret.Get(0).(func(string, int) *stripe.PaymentIntent); ok {
-		r0 = rf(currency, amount)
+	if rf, ok := ret.Get(0).(func(money.Money) *stripe.PaymentIntent); ok {
+		r0 = rf(amount)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*stripe.PaymentIntent)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, int) string); ok {
-		r1 = rf(currency, amount)
+	if rf, ok := ret.Get(1).(func(money.Money) string); ok {
+		r1 = rf(amount)
 	} else {
 		r1 = ret.Get(1).(string)
 	}
 
-	if rf, ok := ret.Get(2).(func(string, int) error); ok {
-		r2 = rf(currency, amount)
+	if rf, ok := ret.Get(2).(func(money.Money) error); ok {
+		r2 = rf(amount)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -49,6 +81,66 @@ func (_m *Carder) CreatePaymentIntent(currency string, amount int) (*stripe.Paym
 	return r0, r1, r2
 }
 
+// GetPaymentIntent provides a mock function with given fields: intentID
+func (_m *Carder) GetPaymentIntent(intentID string) (*stripe.PaymentIntent, error) {
+	ret := _m.Called(intentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPaymentIntent")
+	}
+
+	var r0 *stripe.PaymentIntent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*stripe.PaymentIntent, error)); ok {
+		return rf(intentID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *stripe.PaymentIntent); ok {
+		r0 = rf(intentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*stripe.PaymentIntent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(intentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VoidPayment provides a mock function with given fields: intentID
+func (_m *Carder) VoidPayment(intentID string) (*stripe.PaymentIntent, error) {
+	ret := _m.Called(intentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VoidPayment")
+	}
+
+	var r0 *stripe.PaymentIntent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*stripe.PaymentIntent, error)); ok {
+		return rf(intentID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *stripe.PaymentIntent); ok {
+		r0 = rf(intentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*stripe.PaymentIntent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(intentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewCarder creates a new instance of Carder. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewCarder(t interface {
@@ -0,0 +1,36 @@
+// Package client is a typed Go client of the Shopit REST API, generated from
+// openapi.yaml with oapi-codegen so it follows the spec as it changes. Internal
+// services and tests call the API through it instead of writing requests by hand:
+//
+//	c, err := client.NewClientWithResponses("https://shop.example.com/api/v1",
+//		client.WithRequestEditorFn(client.BearerToken(token)))
+//
+// The generated code is in client.gen.go. Regenerate it with make client, or go
+// generate ./pkg/client, after changing openapi.yaml; the generator version is
+// pinned below, so no tool needs to be installed beforehand.
+package client
+
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.4.1 -config oapi-codegen.yaml ../../openapi.yaml
+
+import (
+	"context"
+	"net/http"
+)
+
+// BearerToken returns a request editor signing requests in with token, the JWT of a
+// user.
+func BearerToken(token string) func(ctx context.Context, req *http.Request) error {
+	return func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// APIKey returns a request editor authenticating requests with key, an integration
+// API key, for the integration endpoints.
+func APIKey(key string) func(ctx context.Context, req *http.Request) error {
+	return func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("X-API-Key", key)
+		return nil
+	}
+}
@@ -2,15 +2,30 @@ package cloudinary
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/cloudinary/cloudinary-go"
+	"github.com/cloudinary/cloudinary-go/api/admin"
 	"github.com/cloudinary/cloudinary-go/api/uploader"
 	"github.com/jofosuware/go/shopit/config"
 )
 
+// Folders used by the usecases when uploading assets. Every CloudUploader
+// implementation maps these onto its own layout (a Cloudinary folder, an S3
+// key prefix or a sub directory on disk).
+const (
+	FolderAvatars  = "avatar"
+	FolderProducts = "products"
+	FolderReviews  = "reviews"
+	FolderInvoices = "invoices"
+)
+
+// CloudUploader stores and deletes assets. Calls are bound by ctx; a RetryUploader
+// gives every attempt its own timeout.
 type CloudUploader interface {
-	UploadToCloud(folder string, data interface{}) (*uploader.UploadResult, error)
-	Destroy(id string) (*uploader.DestroyResult, error)
+	UploadToCloud(ctx context.Context, folder string, data interface{}) (*uploader.UploadResult, error)
+	Destroy(ctx context.Context, id string) (*uploader.DestroyResult, error)
 }
 
 type Cloudinary struct {
@@ -24,18 +39,79 @@ func NewCloudinary(cfg *config.Config) (*Cloudinary, error) {
 	}, err
 }
 
-func (c *Cloudinary) UploadToCloud(folder string, data interface{}) (*uploader.UploadResult, error) {
-	res, err := c.cld.Upload.Upload(context.Background(), data, uploader.UploadParams{Folder: folder})
+func (c *Cloudinary) UploadToCloud(ctx context.Context, folder string, data interface{}) (*uploader.UploadResult, error) {
+	res, err := c.cld.Upload.Upload(ctx, data, uploader.UploadParams{Folder: folder})
 	if err != nil {
-		return &uploader.UploadResult{}, err
+		return &uploader.UploadResult{}, responseError(err)
+	}
+
+	if res.Error.Message != "" {
+		return &uploader.UploadResult{}, errors.New(res.Error.Message)
 	}
+
 	return res, nil
 }
 
-func (c *Cloudinary) Destroy(id string) (*uploader.DestroyResult, error) {
-	res, err := c.cld.Upload.Destroy(context.Background(), uploader.DestroyParams{PublicID: id})
+func (c *Cloudinary) Destroy(ctx context.Context, id string) (*uploader.DestroyResult, error) {
+	res, err := c.cld.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: id})
 	if err != nil {
-		return &uploader.DestroyResult{}, err
+		return &uploader.DestroyResult{}, responseError(err)
 	}
+
+	if res.Error.Message != "" {
+		return &uploader.DestroyResult{}, errors.New(res.Error.Message)
+	}
+
 	return res, nil
 }
+
+// Asset is a stored file as reported by an AssetLister.
+type Asset struct {
+	PublicID  string
+	CreatedAt time.Time
+}
+
+// AssetLister is implemented by uploaders that can enumerate the assets stored in a folder.
+// It is used to reconcile storage with the database.
+type AssetLister interface {
+	ListAssets(ctx context.Context, folder string) ([]Asset, error)
+}
+
+// AssetStore is an uploader that can also list its assets.
+type AssetStore interface {
+	CloudUploader
+	AssetLister
+}
+
+// ListAssets returns every image uploaded under folder, following Cloudinary's pagination.
+func (c *Cloudinary) ListAssets(ctx context.Context, folder string) ([]Asset, error) {
+	var (
+		assets []Asset
+		cursor string
+	)
+
+	for {
+		res, err := c.cld.Admin.Assets(ctx, admin.AssetsParams{
+			DeliveryType: "upload",
+			Prefix:       folder + "/",
+			MaxResults:   500,
+			NextCursor:   cursor,
+		})
+		if err != nil {
+			return nil, responseError(err)
+		}
+
+		if res.Error.Message != "" {
+			return nil, errors.New(res.Error.Message)
+		}
+
+		for _, a := range res.Assets {
+			assets = append(assets, Asset{PublicID: a.PublicID, CreatedAt: a.CreatedAt})
+		}
+
+		if res.NextCursor == "" {
+			return assets, nil
+		}
+		cursor = res.NextCursor
+	}
+}
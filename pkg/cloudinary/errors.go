@@ -0,0 +1,83 @@
+package cloudinary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+var (
+	// ErrUnavailable is matched by every error returned while the cloud storage
+	// provider cannot be reached: timeouts, exhausted retries and an open circuit.
+	ErrUnavailable = errors.New("cloud storage unavailable")
+
+	// ErrCircuitOpen is returned without calling the provider while the circuit breaker is open.
+	ErrCircuitOpen = fmt.Errorf("%w: circuit breaker open", ErrUnavailable)
+
+	// ErrTimeout is returned when an operation exceeds its deadline.
+	ErrTimeout = fmt.Errorf("%w: operation timed out", ErrUnavailable)
+
+	// ErrRejected is matched by every error returned when the provider refused an
+	// operation for a reason trying again would not change, such as an invalid file.
+	ErrRejected = errors.New("cloud storage rejected the operation")
+
+	// ErrTransient marks a failure that may pass when the operation is tried again, such
+	// as a 5xx response of the provider. Uploaders wrap it around such failures; timeouts
+	// and network errors are recognised without it.
+	ErrTransient = errors.New("transient cloud storage failure")
+
+	// ErrListingUnsupported is returned by ListAssets when the underlying uploader cannot enumerate assets.
+	ErrListingUnsupported = errors.New("cloud storage provider does not support listing assets")
+)
+
+// OperationError describes a cloud storage operation that failed after all its attempts,
+// or at once when its failure was permanent.
+type OperationError struct {
+	Op        string
+	Attempts  int
+	Err       error
+	Transient bool
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("cloud storage %s failed after %d attempt(s): %v", e.Op, e.Attempts, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes an OperationError match ErrUnavailable when it failed transiently, and
+// ErrRejected otherwise.
+func (e *OperationError) Is(target error) bool {
+	if e.Transient {
+		return target == ErrUnavailable
+	}
+	return target == ErrRejected
+}
+
+// transient reports whether err may pass when the operation is tried again: timeouts,
+// network errors, responses cut short and failures marked ErrTransient.
+func transient(err error) bool {
+	if errors.Is(err, ErrTransient) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// responseError marks the failure to decode a response of the provider transient: the
+// SDK ignores the status code, so a 5xx page of a gateway only shows as a body that is
+// not JSON.
+func responseError(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("%w: %v", ErrTransient, err)
+	}
+
+	return err
+}
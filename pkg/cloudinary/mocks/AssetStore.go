@@ -0,0 +1,122 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	cloudinary "github.com/jofosuware/go/shopit/pkg/cloudinary"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uploader "github.com/cloudinary/cloudinary-go/api/uploader"
+)
+
+// AssetStore is an autogenerated mock type for the AssetStore type
+type AssetStore struct {
+	mock.Mock
+}
+
+// Destroy provides a mock function with given fields: ctx, id
+func (_m *AssetStore) Destroy(ctx context.Context, id string) (*uploader.DestroyResult, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Destroy")
+	}
+
+	var r0 *uploader.DestroyResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*uploader.DestroyResult, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *uploader.DestroyResult); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*uploader.DestroyResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAssets provides a mock function with given fields: ctx, folder
+func (_m *AssetStore) ListAssets(ctx context.Context, folder string) ([]cloudinary.Asset, error) {
+	ret := _m.Called(ctx, folder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAssets")
+	}
+
+	var r0 []cloudinary.Asset
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]cloudinary.Asset, error)); ok {
+		return rf(ctx, folder)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []cloudinary.Asset); ok {
+		r0 = rf(ctx, folder)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]cloudinary.Asset)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, folder)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UploadToCloud provides a mock function with given fields: ctx, folder, data
+func (_m *AssetStore) UploadToCloud(ctx context.Context, folder string, data interface{}) (*uploader.UploadResult, error) {
+	ret := _m.Called(ctx, folder, data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UploadToCloud")
+	}
+
+	var r0 *uploader.UploadResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}) (*uploader.UploadResult, error)); ok {
+		return rf(ctx, folder, data)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}) *uploader.UploadResult); ok {
+		r0 = rf(ctx, folder, data)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*uploader.UploadResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}) error); ok {
+		r1 = rf(ctx, folder, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAssetStore creates a new instance of AssetStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAssetStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AssetStore {
+	mock := &AssetStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	uploader "github.com/cloudinary/cloudinary-go/api/uploader"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,9 +14,9 @@ type CloudUploader struct {
 	mock.Mock
 }
 
-// Destroy provides a mock function with given fields: id
-func (_m *CloudUploader) Destroy(id string) (*uploader.DestroyResult, error) {
-	ret := _m.Called(id)
+// Destroy provides a mock function with given fields: ctx, id
+func (_m *CloudUploader) Destroy(ctx context.Context, id string) (*uploader.DestroyResult, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Destroy")
@@ -22,19 +24,19 @@ func (_m *CloudUploader) Destroy(id string) (*uploader.DestroyResult, error) {
 
 	var r0 *uploader.DestroyResult
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*uploader.DestroyResult, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*uploader.DestroyResult, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(string) *uploader.DestroyResult); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *uploader.DestroyResult); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*uploader.DestroyResult)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -42,9 +44,9 @@ func (_m *CloudUploader) Destroy(id string) (*uploader.DestroyResult, error) {
 	return r0, r1
 }
 
-// UploadToCloud provides a mock function with given fields: folder, data
-func (_m *CloudUploader) UploadToCloud(folder string, data interface{}) (*uploader.UploadResult, error) {
-	ret := _m.Called(folder, data)
+// UploadToCloud provides a mock function with given fields: ctx, folder, data
+func (_m *CloudUploader) UploadToCloud(ctx context.Context, folder string, data interface{}) (*uploader.UploadResult, error) {
+	ret := _m.Called(ctx, folder, data)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UploadToCloud")
@@ -52,19 +54,19 @@ func (_m *CloudUploader) UploadToCloud(folder string, data interface{}) (*upload
 
 	var r0 *uploader.UploadResult
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, interface{}) (*uploader.UploadResult, error)); ok {
-		return rf(folder, data)
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}) (*uploader.UploadResult, error)); ok {
+		return rf(ctx, folder, data)
 	}
-	if rf, ok := ret.Get(0).(func(string, interface{}) *uploader.UploadResult); ok {
-		r0 = rf(folder, data)
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}) *uploader.UploadResult); ok {
+		r0 = rf(ctx, folder, data)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*uploader.UploadResult)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, interface{}) error); ok {
-		r1 = rf(folder, data)
+	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}) error); ok {
+		r1 = rf(ctx, folder, data)
 	} else {
 		r1 = ret.Error(1)
 	}
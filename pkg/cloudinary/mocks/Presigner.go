@@ -0,0 +1,62 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	cloudinary "github.com/jofosuware/go/shopit/pkg/cloudinary"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Presigner is an autogenerated mock type for the Presigner type
+type Presigner struct {
+	mock.Mock
+}
+
+// Presign provides a mock function with given fields: ctx, folder, maxSize, expiry
+func (_m *Presigner) Presign(ctx context.Context, folder string, maxSize int64, expiry time.Duration) (*cloudinary.Presigned, error) {
+	ret := _m.Called(ctx, folder, maxSize, expiry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Presign")
+	}
+
+	var r0 *cloudinary.Presigned
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, time.Duration) (*cloudinary.Presigned, error)); ok {
+		return rf(ctx, folder, maxSize, expiry)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, time.Duration) *cloudinary.Presigned); ok {
+		r0 = rf(ctx, folder, maxSize, expiry)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*cloudinary.Presigned)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, time.Duration) error); ok {
+		r1 = rf(ctx, folder, maxSize, expiry)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPresigner creates a new instance of Presigner. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPresigner(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Presigner {
+	mock := &Presigner{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,84 @@
+package cloudinary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/api"
+	"github.com/google/uuid"
+)
+
+// ErrPresignUnsupported is returned by Presign when the underlying uploader cannot
+// issue presigned uploads.
+var ErrPresignUnsupported = errors.New("cloud storage provider does not support direct uploads")
+
+// MaxPresignExpiry is the longest a presigned upload can stay valid: Cloudinary
+// rejects upload signatures older than an hour.
+const MaxPresignExpiry = time.Hour
+
+// Presigned is what a client needs to upload one file straight to storage: a
+// multipart form POST to URL of Fields and then the file, in the field named
+// "file". Once uploaded, the file is stored under PublicID and served at AssetURL.
+// The parameters are refused after ExpiresAt.
+type Presigned struct {
+	URL       string
+	Fields    map[string]string
+	PublicID  string
+	AssetURL  string
+	ExpiresAt time.Time
+}
+
+// Presigner is implemented by uploaders that can let clients upload files directly,
+// without sending them through the API. maxSize is the largest file accepted, in
+// bytes, where the provider enforces it.
+type Presigner interface {
+	Presign(ctx context.Context, folder string, maxSize int64, expiry time.Duration) (*Presigned, error)
+}
+
+// presignFormats are the image formats Cloudinary accepts in a presigned upload.
+const presignFormats = "jpg,png,gif,webp"
+
+// Presign signs the upload of one image to folder/<uuid>. Cloudinary signed uploads
+// cannot cap the file size, so maxSize is left to the upload presets of the account,
+// and a signature is valid for at most MaxPresignExpiry whatever expiry is. Signing
+// is local, so ctx is unused.
+func (c *Cloudinary) Presign(_ context.Context, folder string, maxSize int64, expiry time.Duration) (*Presigned, error) {
+	publicID := path.Join(folder, uuid.New().String())
+
+	params := url.Values{
+		"public_id":       {publicID},
+		"allowed_formats": {presignFormats},
+	}
+	// SignParameters adds the timestamp it signs to params
+	signature, err := api.SignParameters(params, c.cld.Config.Cloud.APISecret)
+	if err != nil {
+		return nil, fmt.Errorf("error signing upload: %v", err)
+	}
+
+	image, err := c.cld.Image(publicID)
+	if err != nil {
+		return nil, fmt.Errorf("error building asset url: %v", err)
+	}
+	assetURL, err := image.String()
+	if err != nil {
+		return nil, fmt.Errorf("error building asset url: %v", err)
+	}
+
+	return &Presigned{
+		URL: fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/image/upload", c.cld.Config.Cloud.CloudName),
+		Fields: map[string]string{
+			"api_key":         c.cld.Config.Cloud.APIKey,
+			"timestamp":       params.Get("timestamp"),
+			"public_id":       publicID,
+			"allowed_formats": presignFormats,
+			"signature":       signature,
+		},
+		PublicID:  publicID,
+		AssetURL:  assetURL,
+		ExpiresAt: time.Now().Add(min(expiry, MaxPresignExpiry)),
+	}, nil
+}
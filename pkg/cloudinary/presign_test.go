@@ -0,0 +1,62 @@
+package cloudinary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePresigner struct {
+	fakeUploader
+}
+
+func (f *fakePresigner) Presign(_ context.Context, folder string, _ int64, expiry time.Duration) (*Presigned, error) {
+	if err := f.next(); err != nil {
+		return nil, err
+	}
+	return &Presigned{PublicID: folder + "/id", ExpiresAt: time.Now().Add(expiry)}, nil
+}
+
+func TestPresign(t *testing.T) {
+	cld, err := cloudinary.NewFromParams("shop", "key", "secret")
+	require.NoError(t, err)
+	c := &Cloudinary{cld: cld}
+
+	p, err := c.Presign(context.Background(), FolderProducts, 1<<20, 2*time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://api.cloudinary.com/v1_1/shop/image/upload", p.URL)
+	assert.True(t, strings.HasPrefix(p.PublicID, "products/"))
+	assert.Equal(t, p.PublicID, p.Fields["public_id"])
+	assert.Contains(t, p.AssetURL, p.PublicID)
+	assert.NotEmpty(t, p.Fields["signature"])
+	assert.NotEmpty(t, p.Fields["timestamp"])
+	assert.WithinDuration(t, time.Now().Add(MaxPresignExpiry), p.ExpiresAt, time.Minute)
+}
+
+func TestRetryUploaderPresign(t *testing.T) {
+	t.Run("Transient failure is retried", func(t *testing.T) {
+		f := &fakePresigner{fakeUploader{errs: []error{fmt.Errorf("%w: connection reset", ErrTransient)}}}
+		u := NewRetryUploader(f, RetryOptions{})
+		u.sleep = func(time.Duration) {}
+
+		p, err := u.Presign(context.Background(), FolderProducts, 1<<20, time.Minute)
+		require.NoError(t, err)
+
+		assert.Equal(t, "products/id", p.PublicID)
+		assert.Equal(t, 2, f.calls)
+	})
+
+	t.Run("Uploader cannot presign", func(t *testing.T) {
+		u := newTestUploader(&fakeUploader{}, RetryOptions{})
+
+		_, err := u.Presign(context.Background(), FolderProducts, 1<<20, time.Minute)
+		assert.ErrorIs(t, err, ErrPresignUnsupported)
+	})
+}
@@ -0,0 +1,317 @@
+package cloudinary
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime/multipart"
+	"sync"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/api/uploader"
+)
+
+// RetryOptions configures a RetryUploader. Zero values fall back to the defaults.
+type RetryOptions struct {
+	// Timeout bounds a single attempt, within the deadline of the caller's context.
+	Timeout time.Duration
+	// MaxAttempts bounds how many times an operation is tried.
+	MaxAttempts int
+	// BaseDelay is the backoff before the second attempt; it doubles on every retry
+	// and is fully jittered.
+	BaseDelay time.Duration
+	// MaxDelay caps a single backoff.
+	MaxDelay time.Duration
+	// FailureThreshold is the number of consecutive failed operations that opens the circuit.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a trial call is let through.
+	OpenTimeout time.Duration
+}
+
+// DefaultRetryOptions returns the options used for zero fields.
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{
+		Timeout:          30 * time.Second,
+		MaxAttempts:      3,
+		BaseDelay:        200 * time.Millisecond,
+		MaxDelay:         2 * time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+// RetryUploader wraps a CloudUploader with bounded, jittered retries and a circuit breaker.
+type RetryUploader struct {
+	next    CloudUploader
+	opts    RetryOptions
+	breaker *breaker
+	sleep   func(time.Duration)
+}
+
+// NewRetryUploader returns a RetryUploader around next.
+func NewRetryUploader(next CloudUploader, opts RetryOptions) *RetryUploader {
+	def := DefaultRetryOptions()
+	if opts.Timeout <= 0 {
+		opts.Timeout = def.Timeout
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = def.MaxAttempts
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = def.BaseDelay
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = def.MaxDelay
+	}
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = def.FailureThreshold
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = def.OpenTimeout
+	}
+
+	return &RetryUploader{
+		next:    next,
+		opts:    opts,
+		breaker: &breaker{threshold: opts.FailureThreshold, openTimeout: opts.OpenTimeout, now: time.Now},
+		sleep:   time.Sleep,
+	}
+}
+
+// UploadToCloud uploads data, retrying transient failures.
+func (u *RetryUploader) UploadToCloud(ctx context.Context, folder string, data interface{}) (*uploader.UploadResult, error) {
+	data, err := rewindable(data)
+	if err != nil {
+		return &uploader.UploadResult{}, err
+	}
+
+	var res *uploader.UploadResult
+	err = u.do(ctx, "upload", func(ctx context.Context, attempt int) error {
+		if attempt > 1 {
+			if err := rewind(data); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		res, err = u.next.UploadToCloud(ctx, folder, data)
+		return err
+	})
+	if err != nil {
+		return &uploader.UploadResult{}, err
+	}
+
+	return res, nil
+}
+
+// Destroy deletes an asset, retrying transient failures.
+func (u *RetryUploader) Destroy(ctx context.Context, id string) (*uploader.DestroyResult, error) {
+	var res *uploader.DestroyResult
+	err := u.do(ctx, "destroy", func(ctx context.Context, _ int) error {
+		var err error
+		res, err = u.next.Destroy(ctx, id)
+		return err
+	})
+	if err != nil {
+		return &uploader.DestroyResult{}, err
+	}
+
+	return res, nil
+}
+
+// ListAssets lists the assets under folder when the wrapped uploader is an AssetLister.
+func (u *RetryUploader) ListAssets(ctx context.Context, folder string) ([]Asset, error) {
+	lister, ok := u.next.(AssetLister)
+	if !ok {
+		return nil, ErrListingUnsupported
+	}
+
+	var assets []Asset
+	err := u.do(ctx, "list", func(ctx context.Context, _ int) error {
+		var err error
+		assets, err = lister.ListAssets(ctx, folder)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+// Presign issues a presigned upload when the wrapped uploader is a Presigner.
+func (u *RetryUploader) Presign(ctx context.Context, folder string, maxSize int64, expiry time.Duration) (*Presigned, error) {
+	presigner, ok := u.next.(Presigner)
+	if !ok {
+		return nil, ErrPresignUnsupported
+	}
+
+	var presigned *Presigned
+	err := u.do(ctx, "presign", func(ctx context.Context, _ int) error {
+		var err error
+		presigned, err = presigner.Presign(ctx, folder, maxSize, expiry)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return presigned, nil
+}
+
+// do runs fn until it succeeds or attempts are exhausted, honouring the circuit breaker.
+// Every attempt gets its own timeout within ctx. A permanent failure is returned at once
+// and does not count towards opening the circuit, nor does ctx ending.
+func (u *RetryUploader) do(ctx context.Context, op string, fn func(ctx context.Context, attempt int) error) error {
+	if !u.breaker.allow() {
+		return ErrCircuitOpen
+	}
+
+	var err error
+	attempt := 0
+	for attempt < u.opts.MaxAttempts {
+		attempt++
+
+		if err = u.attempt(ctx, attempt, fn); err == nil {
+			u.breaker.success()
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			// the caller gave up, which tells nothing about the provider
+			u.breaker.release()
+			return &OperationError{Op: op, Attempts: attempt, Err: timeoutError(err), Transient: true}
+		}
+
+		if !transient(err) {
+			// the provider answered, so the failure tells nothing against its health
+			u.breaker.success()
+			return &OperationError{Op: op, Attempts: attempt, Err: err}
+		}
+
+		if attempt < u.opts.MaxAttempts {
+			u.sleep(u.backoff(attempt))
+		}
+	}
+
+	u.breaker.failure()
+
+	return &OperationError{Op: op, Attempts: attempt, Err: timeoutError(err), Transient: true}
+}
+
+// attempt runs fn once, bound by the per attempt timeout.
+func (u *RetryUploader) attempt(ctx context.Context, attempt int, fn func(ctx context.Context, attempt int) error) error {
+	ctx, cancel := context.WithTimeout(ctx, u.opts.Timeout)
+	defer cancel()
+
+	return fn(ctx, attempt)
+}
+
+// timeoutError marks a deadline failure as ErrTimeout.
+func timeoutError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+
+	return err
+}
+
+// backoff returns a fully jittered exponential delay for the given attempt.
+func (u *RetryUploader) backoff(attempt int) time.Duration {
+	d := u.opts.BaseDelay << (attempt - 1)
+	if d <= 0 || d > u.opts.MaxDelay {
+		d = u.opts.MaxDelay
+	}
+
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// rewindable makes readers safe to send more than once, buffering them when they cannot seek.
+func rewindable(data interface{}) (interface{}, error) {
+	switch d := data.(type) {
+	case *multipart.File:
+		if d != nil && *d != nil {
+			return *d, nil
+		}
+	case io.ReadSeeker:
+		return d, nil
+	case io.Reader:
+		b, err := io.ReadAll(d)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(b), nil
+	}
+
+	return data, nil
+}
+
+// rewind resets a reader consumed by a previous attempt.
+func rewind(data interface{}) error {
+	if s, ok := data.(io.Seeker); ok {
+		_, err := s.Seek(0, io.SeekStart)
+		return err
+	}
+
+	return nil
+}
+
+// breaker is a consecutive-failure circuit breaker. After threshold failed
+// operations it rejects calls for openTimeout, then lets a single trial through.
+type breaker struct {
+	mu          sync.Mutex
+	threshold   int
+	openTimeout time.Duration
+	failures    int
+	openedAt    time.Time
+	trial       bool
+	now         func() time.Time
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+
+	if b.now().Sub(b.openedAt) < b.openTimeout || b.trial {
+		return false
+	}
+
+	// half-open: let one call probe the provider
+	b.trial = true
+
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+}
+
+// release ends a trial call without a verdict, so that the next call probes the provider.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
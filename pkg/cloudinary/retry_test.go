@@ -0,0 +1,179 @@
+package cloudinary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/api/uploader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUploader struct {
+	errs  []error
+	calls int
+	reads []string
+}
+
+func (f *fakeUploader) next() error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *fakeUploader) UploadToCloud(_ context.Context, folder string, data interface{}) (*uploader.UploadResult, error) {
+	if r, ok := data.(io.Reader); ok {
+		b, _ := io.ReadAll(r)
+		f.reads = append(f.reads, string(b))
+	}
+	if err := f.next(); err != nil {
+		return nil, err
+	}
+	return &uploader.UploadResult{PublicID: folder + "/id"}, nil
+}
+
+func (f *fakeUploader) Destroy(_ context.Context, id string) (*uploader.DestroyResult, error) {
+	if err := f.next(); err != nil {
+		return nil, err
+	}
+	return &uploader.DestroyResult{Result: "ok"}, nil
+}
+
+func newTestUploader(f *fakeUploader, opts RetryOptions) *RetryUploader {
+	u := NewRetryUploader(f, opts)
+	u.sleep = func(time.Duration) {}
+	return u
+}
+
+func TestRetryUploader(t *testing.T) {
+	t.Run("Transient failure is retried", func(t *testing.T) {
+		f := &fakeUploader{errs: []error{&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset")}}}
+		u := newTestUploader(f, RetryOptions{})
+
+		res, err := u.UploadToCloud(context.Background(), FolderProducts, bytes.NewBufferString("image"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "products/id", res.PublicID)
+		assert.Equal(t, 2, f.calls)
+		assert.Equal(t, []string{"image", "image"}, f.reads)
+	})
+
+	t.Run("Exhausted attempts return an unavailable error", func(t *testing.T) {
+		f := &fakeUploader{errs: []error{context.DeadlineExceeded, context.DeadlineExceeded, context.DeadlineExceeded}}
+		u := newTestUploader(f, RetryOptions{MaxAttempts: 3})
+
+		_, err := u.Destroy(context.Background(), "products/id")
+
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, 3, opErr.Attempts)
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.ErrorIs(t, err, ErrTimeout)
+	})
+
+	t.Run("Circuit opens after repeated failures", func(t *testing.T) {
+		f := &fakeUploader{errs: []error{fmt.Errorf("%w: 503", ErrTransient), fmt.Errorf("%w: 503", ErrTransient)}}
+		u := newTestUploader(f, RetryOptions{MaxAttempts: 1, FailureThreshold: 2, OpenTimeout: time.Minute})
+
+		now := time.Now()
+		u.breaker.now = func() time.Time { return now }
+
+		_, _ = u.Destroy(context.Background(), "a")
+		_, _ = u.Destroy(context.Background(), "a")
+
+		_, err := u.Destroy(context.Background(), "a")
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.Equal(t, 2, f.calls)
+
+		// after the open timeout a trial call is let through and closes the circuit
+		now = now.Add(2 * time.Minute)
+		_, err = u.Destroy(context.Background(), "a")
+		require.NoError(t, err)
+		assert.Equal(t, 3, f.calls)
+	})
+	t.Run("Permanent failure is not retried", func(t *testing.T) {
+		f := &fakeUploader{errs: []error{errors.New("Invalid image file"), errors.New("Invalid image file")}}
+		u := newTestUploader(f, RetryOptions{MaxAttempts: 3, FailureThreshold: 1})
+
+		_, err := u.UploadToCloud(context.Background(), FolderProducts, bytes.NewBufferString("image"))
+
+		assert.ErrorIs(t, err, ErrRejected)
+		assert.NotErrorIs(t, err, ErrUnavailable)
+		assert.Equal(t, 1, f.calls)
+
+		// it does not count towards opening the circuit
+		_, err = u.Destroy(context.Background(), "a")
+		assert.ErrorIs(t, err, ErrRejected)
+		assert.Equal(t, 2, f.calls)
+	})
+}
+
+func TestRetryUploaderContext(t *testing.T) {
+	t.Run("Attempts are bound by the timeout", func(t *testing.T) {
+		var deadlines []time.Duration
+		f := &ctxUploader{fn: func(ctx context.Context) error {
+			d, _ := ctx.Deadline()
+			deadlines = append(deadlines, time.Until(d))
+			return nil
+		}}
+		u := NewRetryUploader(f, RetryOptions{Timeout: time.Minute})
+
+		_, err := u.Destroy(context.Background(), "a")
+		require.NoError(t, err)
+
+		require.Len(t, deadlines, 1)
+		assert.InDelta(t, time.Minute, deadlines[0], float64(time.Second))
+	})
+
+	t.Run("Cancelled call is not retried and keeps the circuit closed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		f := &ctxUploader{fn: func(ctx context.Context) error {
+			calls++
+			cancel()
+			return ctx.Err()
+		}}
+		u := NewRetryUploader(f, RetryOptions{MaxAttempts: 3, FailureThreshold: 1})
+		u.sleep = func(time.Duration) {}
+
+		_, err := u.Destroy(ctx, "a")
+
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+		assert.True(t, u.breaker.allow())
+	})
+}
+
+// ctxUploader runs fn with the context of each call.
+type ctxUploader struct {
+	fakeUploader
+	fn func(ctx context.Context) error
+}
+
+func (c *ctxUploader) Destroy(ctx context.Context, _ string) (*uploader.DestroyResult, error) {
+	if err := c.fn(ctx); err != nil {
+		return nil, err
+	}
+	return &uploader.DestroyResult{Result: "ok"}, nil
+}
+
+func TestResponseError(t *testing.T) {
+	var v struct{}
+	syntaxErr := json.Unmarshal([]byte("<html>502 Bad Gateway</html>"), &v)
+
+	assert.True(t, transient(responseError(syntaxErr)), "a page that is not JSON comes from a failing gateway")
+	assert.False(t, transient(responseError(errors.New("must provide API Secret"))))
+}
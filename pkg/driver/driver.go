@@ -1,12 +1,18 @@
 package driver
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"time"
 
-	_ "github.com/jackc/pgconn"
-	_ "github.com/jackc/pgx/v4"
-	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/XSAM/otelsql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // DB holds the database connection pool
@@ -20,9 +26,42 @@ const maxOpenDbConn = 10
 const maxIdleDbConn = 5
 const maxDbLifetime = 5 * time.Minute
 
+// Modes of the statement cache of connections.
+const (
+	// StatementCachePrepare prepares the statements of queries on the server once
+	// per connection, which then only binds and executes them.
+	StatementCachePrepare = "prepare"
+	// StatementCacheDescribe only caches the description of the statements, for
+	// connection poolers such as PgBouncer that do not keep prepared statements.
+	StatementCacheDescribe = "describe"
+)
+
+// DefaultStatementCacheCapacity is the number of statements a connection keeps
+// prepared when no capacity is configured.
+const DefaultStatementCacheCapacity = 512
+
+// Options of the database pool.
+//
+// Each connection keeps the statements of its last StatementCacheCapacity distinct
+// queries prepared in StatementCacheMode, so that hot queries such as the token
+// lookup of every authenticated request are parsed and planned once. A capacity of
+// 0 keeps DefaultStatementCacheCapacity, or those of the dsn, and a negative one
+// turns the cache off.
+//
+// With a Logger, the queries that take SlowQueryThreshold or longer are logged at
+// warn level, and with LogQueries every query is logged at debug level. A
+// SlowQueryThreshold of 0 logs no slow queries.
+type Options struct {
+	StatementCacheCapacity int
+	StatementCacheMode     string
+	Logger                 logger.Logger
+	SlowQueryThreshold     time.Duration
+	LogQueries             bool
+}
+
 // ConnectSQL creates database pool for Postgres
-func ConnectSQL(dsn string) (*DB, error) {
-	d, err := NewDatabase(dsn)
+func ConnectSQL(dsn string, opts Options) (*DB, error) {
+	d, err := NewDatabase(dsn, opts)
 	if err != nil {
 		panic(err)
 	}
@@ -49,15 +88,63 @@ func testDB(d *sql.DB) error {
 	return nil
 }
 
-// NewDatabase creates a new database for the application
-func NewDatabase(dsn string) (*sql.DB, error) {
-	db, err := sql.Open("pgx", dsn)
+// NewDatabase creates a new database for the application. Queries made with the
+// context of a traced request or use case are recorded as spans of its trace, and
+// logged as opts says.
+func NewDatabase(dsn string, opts Options) (*sql.DB, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
 	if err != nil {
 		return nil, err
 	}
+	if opts.StatementCacheCapacity != 0 || opts.StatementCacheMode != "" {
+		if err := configureStatementCache(connConfig, opts.StatementCacheCapacity, opts.StatementCacheMode); err != nil {
+			return nil, err
+		}
+	}
+
+	c := stdlib.GetConnector(*connConfig)
+	if opts.Logger != nil && (opts.SlowQueryThreshold > 0 || opts.LogQueries) {
+		c = &queryLog{Connector: c, logger: opts.Logger, slow: opts.SlowQueryThreshold, all: opts.LogQueries}
+	}
 
-	if err = db.Ping(); err != nil {
+	db := otelsql.OpenDB(c,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+	)
+
+	if err := db.Ping(); err != nil {
 		return nil, err
 	}
 	return db, nil
 }
+
+// configureStatementCache makes the connections of config keep capacity statements,
+// DefaultStatementCacheCapacity when it is 0 and none when it is negative, in mode,
+// "prepare" by default.
+func configureStatementCache(config *pgx.ConnConfig, capacity int, mode string) error {
+	if capacity == 0 {
+		capacity = DefaultStatementCacheCapacity
+	}
+
+	switch {
+	case mode != "" && mode != StatementCachePrepare && mode != StatementCacheDescribe:
+		return fmt.Errorf("unknown statement cache mode %q", mode)
+	case capacity < 0:
+		config.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+		config.StatementCacheCapacity, config.DescriptionCacheCapacity = 0, 0
+	case mode == StatementCacheDescribe:
+		config.DefaultQueryExecMode = pgx.QueryExecModeCacheDescribe
+		config.StatementCacheCapacity, config.DescriptionCacheCapacity = 0, capacity
+	default:
+		config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+		config.StatementCacheCapacity, config.DescriptionCacheCapacity = capacity, 0
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureStatementCache(t *testing.T) {
+	newConfig := func(t *testing.T) *pgx.ConnConfig {
+		config, err := pgx.ParseConfig("postgres://localhost:5432/shopit")
+		require.NoError(t, err)
+		return config
+	}
+
+	t.Run("Default capacity and mode", func(t *testing.T) {
+		config := newConfig(t)
+		require.NoError(t, configureStatementCache(config, 0, ""))
+		assert.Equal(t, pgx.QueryExecModeCacheStatement, config.DefaultQueryExecMode)
+		assert.Equal(t, DefaultStatementCacheCapacity, config.StatementCacheCapacity)
+	})
+
+	t.Run("Describe mode", func(t *testing.T) {
+		config := newConfig(t)
+		require.NoError(t, configureStatementCache(config, 64, StatementCacheDescribe))
+		assert.Equal(t, pgx.QueryExecModeCacheDescribe, config.DefaultQueryExecMode)
+		assert.Equal(t, 0, config.StatementCacheCapacity)
+		assert.Equal(t, 64, config.DescriptionCacheCapacity)
+	})
+
+	t.Run("Negative capacity turns the cache off", func(t *testing.T) {
+		config := newConfig(t)
+		require.NoError(t, configureStatementCache(config, -1, ""))
+		assert.Equal(t, pgx.QueryExecModeDescribeExec, config.DefaultQueryExecMode)
+		assert.Equal(t, 0, config.StatementCacheCapacity)
+	})
+
+	t.Run("Unknown mode", func(t *testing.T) {
+		assert.EqualError(t, configureStatementCache(newConfig(t), 16, "always"), `unknown statement cache mode "always"`)
+	})
+}
+
+func TestNewDatabaseInvalidDSN(t *testing.T) {
+	_, err := NewDatabase("postgres://localhost:5432/shopit?default_query_exec_mode=sometimes", Options{})
+	assert.Error(t, err)
+
+	_, err = NewDatabase("postgres://localhost:5432/shopit", Options{StatementCacheMode: "sometimes"})
+	assert.EqualError(t, err, `unknown statement cache mode "sometimes"`)
+}
@@ -0,0 +1,60 @@
+package driver
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// ErrNotPgx is returned by WithPgx for connections of another driver, such as those
+// of sqlmock in tests, so that callers fall back to plain database/sql.
+var ErrNotPgx = errors.New("not a pgx connection")
+
+// rawConn is a driver connection wrapping another, such as those of the tracing and
+// query logging wrappers.
+type rawConn interface {
+	Raw() driver.Conn
+}
+
+// WithPgx calls fn with the native pgx connection of conn, for what database/sql
+// cannot do, such as COPY and batches. conn stays reserved while fn runs, so any
+// transaction begun on it with conn.BeginTx is the one fn's queries run in.
+func WithPgx(conn *sql.Conn, fn func(*pgx.Conn) error) error {
+	var fnErr error
+	err := conn.Raw(func(driverConn interface{}) error {
+		for {
+			switch c := driverConn.(type) {
+			case *stdlib.Conn:
+				fnErr = fn(c.Conn())
+				return nil
+			case rawConn:
+				driverConn = c.Raw()
+			default:
+				return ErrNotPgx
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return fnErr
+}
+
+// uniqueViolation is the SQLSTATE of a row breaking a unique constraint.
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err is a Postgres error for a row that breaks
+// a unique constraint, or for constraint when it is not empty.
+func IsUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return false
+	}
+
+	return constraint == "" || pgErr.ConstraintName == constraint
+}
@@ -0,0 +1,40 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPgx(t *testing.T) {
+	db := sql.OpenDB(&queryLog{Connector: fakeConnector{}})
+	defer db.Close()
+
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	called := false
+	err = WithPgx(conn, func(*pgx.Conn) error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrNotPgx)
+	assert.False(t, called)
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	violation := &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}
+
+	assert.True(t, IsUniqueViolation(fmt.Errorf("error saving user: %w", violation), ""))
+	assert.True(t, IsUniqueViolation(violation, "users_email_key"))
+	assert.False(t, IsUniqueViolation(violation, "coupons_code_idx"))
+	assert.False(t, IsUniqueViolation(&pgconn.PgError{Code: "23503"}, ""))
+	assert.False(t, IsUniqueViolation(sql.ErrNoRows, ""))
+}
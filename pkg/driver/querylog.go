@@ -0,0 +1,251 @@
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jofosuware/go/shopit/pkg/logger"
+)
+
+// queryLog wraps the connector of a database/sql driver, logging the queries slower
+// than slow with their duration and the rows they affected. Their arguments are
+// redacted to their types, as they hold personal data and credentials.
+type queryLog struct {
+	driver.Connector
+	logger logger.Logger
+	slow   time.Duration
+	// all logs every query at debug level
+	all bool
+}
+
+func (d *queryLog) Connect(ctx context.Context) (driver.Conn, error) {
+	c, err := d.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &queryLogConn{Conn: c, log: d}, nil
+}
+
+// log logs query, run with args in took and affecting rows rows (-1 when unknown), at
+// warn level when it is slow, or else at debug level when every query is logged.
+func (d *queryLog) log(ctx context.Context, query string, args []driver.NamedValue, took time.Duration, rows int64, err error) {
+	slow := d.slow > 0 && took >= d.slow
+	if !slow && !d.all {
+		return
+	}
+
+	fields := []interface{}{
+		"query", strings.Join(strings.Fields(query), " "),
+		"args", redact(args),
+		"duration_ms", took.Milliseconds(),
+	}
+	if rows >= 0 {
+		fields = append(fields, "rows_affected", rows)
+	}
+	if err != nil {
+		fields = append(fields, "error", err.Error())
+	}
+
+	l := logger.FromContext(ctx, d.logger).With(fields...)
+	if slow {
+		l.Warnf("slow query took %s", took.Round(time.Millisecond))
+	} else {
+		l.Debug("query")
+	}
+}
+
+// redact returns the types of args, such as "$1 string(5)", in place of their values.
+func redact(args []driver.NamedValue) []string {
+	redacted := make([]string, len(args))
+	for i, a := range args {
+		name := fmt.Sprintf("$%d", a.Ordinal)
+		if a.Name != "" {
+			name = "@" + a.Name
+		}
+
+		switch v := a.Value.(type) {
+		case nil:
+			redacted[i] = name + " null"
+		case string:
+			redacted[i] = fmt.Sprintf("%s string(%d)", name, len(v))
+		case []byte:
+			redacted[i] = fmt.Sprintf("%s bytes(%d)", name, len(v))
+		default:
+			redacted[i] = fmt.Sprintf("%s %T", name, v)
+		}
+	}
+
+	return redacted
+}
+
+// queryLogConn times the queries of a connection. The optional interfaces of the
+// connection it wraps are passed through, or skipped so that database/sql falls back.
+type queryLogConn struct {
+	driver.Conn
+	log *queryLog
+}
+
+func (c *queryLogConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	res, err := execer.ExecContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	c.log.log(ctx, query, args, time.Since(start), rowsAffected(res), err)
+
+	return res, err
+}
+
+func (c *queryLogConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	c.log.log(ctx, query, args, time.Since(start), -1, err)
+
+	return rows, err
+}
+
+func (c *queryLogConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		s   driver.Stmt
+		err error
+	)
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = preparer.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &queryLogStmt{Stmt: s, query: query, log: c.log}, nil
+}
+
+func (c *queryLogConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *queryLogConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+
+	return c.Conn.Begin()
+}
+
+func (c *queryLogConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *queryLogConn) CheckNamedValue(v *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(v)
+	}
+
+	return driver.ErrSkip
+}
+
+func (c *queryLogConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+// queryLogStmt times the executions of a prepared statement.
+type queryLogStmt struct {
+	driver.Stmt
+	query string
+	log   *queryLog
+}
+
+func (s *queryLogStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var (
+		res driver.Result
+		err error
+	)
+	start := time.Now()
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = execer.ExecContext(ctx, args)
+	} else {
+		res, err = s.Stmt.Exec(values(args))
+	}
+	s.log.log(ctx, s.query, args, time.Since(start), rowsAffected(res), err)
+
+	return res, err
+}
+
+func (s *queryLogStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var (
+		rows driver.Rows
+		err  error
+	)
+	start := time.Now()
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(values(args))
+	}
+	s.log.log(ctx, s.query, args, time.Since(start), -1, err)
+
+	return rows, err
+}
+
+func (s *queryLogStmt) CheckNamedValue(v *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(v)
+	}
+
+	return driver.ErrSkip
+}
+
+// values returns the values of args, for statements without the context methods.
+func values(args []driver.NamedValue) []driver.Value {
+	v := make([]driver.Value, len(args))
+	for i, a := range args {
+		v[i] = a.Value
+	}
+
+	return v
+}
+
+// rowsAffected returns the rows affected by res, -1 when they are not known.
+func rowsAffected(res driver.Result) int64 {
+	if res == nil {
+		return -1
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+// Raw returns the connection it wraps, for WithPgx.
+func (c *queryLogConn) Raw() driver.Conn {
+	return c.Conn
+}
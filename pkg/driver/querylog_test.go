@@ -0,0 +1,99 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConnector opens connections whose queries take delay and affect 3 rows.
+type fakeConnector struct {
+	delay time.Duration
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{delay: c.delay}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	driver.Conn
+	delay time.Duration
+}
+
+func (c *fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	time.Sleep(c.delay)
+	return driver.RowsAffected(3), nil
+}
+
+func (c *fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	time.Sleep(c.delay)
+	return fakeRows{}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string              { return []string{"id"} }
+func (fakeRows) Close() error                   { return nil }
+func (fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestQueryLog(t *testing.T) {
+	open := func(t *testing.T, delay time.Duration, l *mockLogger.Logger, all bool) *sql.DB {
+		db := sql.OpenDB(&queryLog{
+			Connector: fakeConnector{delay: delay},
+			logger:    l,
+			slow:      10 * time.Millisecond,
+			all:       all,
+		})
+		t.Cleanup(func() { _ = db.Close() })
+		return db
+	}
+
+	t.Run("Slow query is logged with redacted args", func(t *testing.T) {
+		l, child := mockLogger.NewLogger(t), mockLogger.NewLogger(t)
+		l.On("With", "query", "update users set name = $1 where id = $2",
+			"args", []string{"$1 string(3)", "$2 int64"},
+			"duration_ms", mock.Anything, "rows_affected", int64(3)).Return(child).Once()
+		child.On("Warnf", "slow query took %s", mock.Anything).Once()
+
+		_, err := open(t, 20*time.Millisecond, l, false).Exec(`update users
+			set name = $1
+			where id = $2`, "Ama", 7)
+		require.NoError(t, err)
+	})
+
+	t.Run("Fast query is not logged", func(t *testing.T) {
+		l := mockLogger.NewLogger(t)
+
+		rows, err := open(t, 0, l, false).Query("select id from users")
+		require.NoError(t, err)
+		assert.False(t, rows.Next())
+		require.NoError(t, rows.Close())
+	})
+
+	t.Run("Every query is logged at debug level", func(t *testing.T) {
+		l, child := mockLogger.NewLogger(t), mockLogger.NewLogger(t)
+		l.On("With", "query", "select id from users where email = $1", "args", []string{"$1 null"},
+			"duration_ms", mock.Anything).Return(child).Once()
+		child.On("Debug", "query").Once()
+
+		rows, err := open(t, 0, l, true).Query("select id from users where email = $1", nil)
+		require.NoError(t, err)
+		require.NoError(t, rows.Close())
+	})
+}
@@ -0,0 +1,92 @@
+// Package errreport sends the panics of request handlers to an error tracking service.
+//
+// utils.RecoverPanic logs a recovered panic, answers 500 with an error id and hands
+// the panic to a Reporter as an Event. The bundled Sentry reporter posts events to the
+// project of a Sentry DSN; Nop drops them. Other services are plugged in by
+// implementing Reporter.
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/pkg/realip"
+)
+
+// Providers of error reporting.
+const (
+	ProviderSentry = "sentry"
+)
+
+// DefaultTimeout bounds a report when no timeout is configured.
+const DefaultTimeout = 5 * time.Second
+
+// Event is a panic recovered while serving a request.
+type Event struct {
+	// ID is the error id the client was answered with
+	ID      string
+	Message string
+	Stack   []byte
+	Time    time.Time
+	Method  string
+	URL     string
+	// Header holds the request headers, without credentials
+	Header   http.Header
+	RemoteIP string
+}
+
+// NewEvent returns the Event of the panic value rvr, recovered while serving r and
+// answered with the error id id.
+func NewEvent(id string, rvr any, stack []byte, r *http.Request) Event {
+	header := r.Header.Clone()
+	for _, k := range []string{"Authorization", "Cookie", "X-Csrf-Token"} {
+		header.Del(k)
+	}
+
+	return Event{
+		ID:       id,
+		Message:  fmt.Sprintf("panic: %v", rvr),
+		Stack:    stack,
+		Time:     time.Now().UTC(),
+		Method:   r.Method,
+		URL:      r.URL.String(),
+		Header:   header,
+		RemoteIP: realip.FromRequest(r),
+	}
+}
+
+// Reporter sends events to an error tracking service.
+type Reporter interface {
+	Report(ctx context.Context, e Event) error
+}
+
+// Nop is a Reporter that drops every event.
+type Nop struct{}
+
+// Report drops the event.
+func (Nop) Report(context.Context, Event) error {
+	return nil
+}
+
+// New returns the Reporter selected by cfg.ErrorReporting.Provider, Nop when none is.
+// Events are tagged with the environment of cfg.ErrorReporting, server.Mode by
+// default, and the release server.AppVersion.
+func New(cfg *config.Config) (Reporter, error) {
+	environment := cfg.ErrorReporting.Environment
+	if environment == "" {
+		environment = cfg.Server.Mode
+	}
+
+	switch strings.ToLower(cfg.ErrorReporting.Provider) {
+	case "":
+		return Nop{}, nil
+	case ProviderSentry:
+		return NewSentry(cfg.ErrorReporting.DSN, environment, cfg.Server.AppVersion, cfg.ErrorReporting.Timeout)
+	default:
+		return nil, fmt.Errorf("unknown error reporting provider %q", cfg.ErrorReporting.Provider)
+	}
+}
@@ -0,0 +1,100 @@
+package errreport_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/pkg/errreport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	rep, err := errreport.New(&config.Config{})
+	require.NoError(t, err)
+	assert.IsType(t, errreport.Nop{}, rep)
+
+	rep, err = errreport.New(&config.Config{ErrorReporting: config.ErrorReporting{
+		Provider: "sentry", DSN: "https://key@o0.ingest.sentry.io/42",
+	}})
+	require.NoError(t, err)
+	assert.IsType(t, &errreport.Sentry{}, rep)
+
+	_, err = errreport.New(&config.Config{ErrorReporting: config.ErrorReporting{Provider: "rollbar"}})
+	assert.Error(t, err)
+}
+
+func TestNewSentry(t *testing.T) {
+	for _, dsn := range []string{"", "key@sentry.io/42", "https://sentry.io/42", "https://key@sentry.io"} {
+		_, err := errreport.NewSentry(dsn, "Production", "1.0.0", 0)
+		assert.Error(t, err, dsn)
+	}
+}
+
+func TestSentryReport(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/orders/new", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	r.Header.Set("User-Agent", "shopit-web")
+	e := errreport.NewEvent("3f1c2a9e-6a0b-4e55-9f0d-2b7f5c1d8e42", "boom", []byte("goroutine 1 [running]:"), r)
+
+	t.Run("Event is posted to the envelope endpoint", func(t *testing.T) {
+		var lines []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/prefix/api/42/envelope/", r.URL.Path)
+			assert.Equal(t, "application/x-sentry-envelope", r.Header.Get("Content-Type"))
+			assert.Contains(t, r.Header.Get("X-Sentry-Auth"), "sentry_key=key")
+
+			sc := bufio.NewScanner(r.Body)
+			sc.Buffer(nil, 1<<20)
+			for sc.Scan() {
+				lines = append(lines, sc.Text())
+			}
+		}))
+		defer srv.Close()
+
+		s, err := errreport.NewSentry(strings.Replace(srv.URL, "://", "://key@", 1)+"/prefix/42", "Production", "1.0.0", 0)
+		require.NoError(t, err)
+		require.NoError(t, s.Report(context.Background(), e))
+
+		require.Len(t, lines, 3)
+		var event struct {
+			EventID     string            `json:"event_id"`
+			Environment string            `json:"environment"`
+			Release     string            `json:"release"`
+			Message     string            `json:"message"`
+			Tags        map[string]string `json:"tags"`
+			Extra       map[string]string `json:"extra"`
+			Request     struct {
+				Method  string            `json:"method"`
+				Headers map[string]string `json:"headers"`
+			} `json:"request"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(lines[2]), &event))
+		assert.Equal(t, "3f1c2a9e6a0b4e559f0d2b7f5c1d8e42", event.EventID)
+		assert.Equal(t, "Production", event.Environment)
+		assert.Equal(t, "1.0.0", event.Release)
+		assert.Equal(t, "panic: boom", event.Message)
+		assert.Equal(t, e.ID, event.Tags["error_id"])
+		assert.Equal(t, "goroutine 1 [running]:", event.Extra["stack"])
+		assert.Equal(t, http.MethodPost, event.Request.Method)
+		assert.Equal(t, "shopit-web", event.Request.Headers["User-Agent"])
+		assert.NotContains(t, event.Request.Headers, "Authorization")
+	})
+
+	t.Run("Rejected event", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer srv.Close()
+
+		s, err := errreport.NewSentry(strings.Replace(srv.URL, "://", "://key@", 1)+"/42", "", "", 0)
+		require.NoError(t, err)
+		assert.Error(t, s.Report(context.Background(), e))
+	})
+}
@@ -0,0 +1,138 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Sentry is a Reporter that posts events to the envelope endpoint of a Sentry project.
+type Sentry struct {
+	dsn         string
+	endpoint    string
+	key         string
+	environment string
+	release     string
+	client      *http.Client
+}
+
+// NewSentry returns a Sentry reporter for the project of dsn, such as
+// https://<key>@o0.ingest.sentry.io/<project>, tagging events with environment and
+// release. A non-positive timeout falls back to DefaultTimeout.
+func NewSentry(dsn, environment, release string, timeout time.Duration) (*Sentry, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry dsn: %w", err)
+	}
+	project := path.Base(u.Path)
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User.Username() == "" ||
+		project == "/" || project == "." {
+		return nil, errors.New("invalid sentry dsn: want https://<key>@<host>/<project>")
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	endpoint := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   strings.TrimSuffix(path.Dir(u.Path), "/") + "/api/" + project + "/envelope/",
+	}
+
+	return &Sentry{
+		dsn:         dsn,
+		endpoint:    endpoint.String(),
+		key:         u.User.Username(),
+		environment: environment,
+		release:     release,
+		client:      &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// sentryEvent is the part of the Sentry event payload filled from an Event.
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Message     string            `json:"message"`
+	Request     sentryRequest     `json:"request"`
+	User        map[string]string `json:"user,omitempty"`
+	Tags        map[string]string `json:"tags"`
+	Extra       map[string]string `json:"extra"`
+}
+
+type sentryRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+// Report posts e to Sentry as an event whose id is the error id.
+func (s *Sentry) Report(ctx context.Context, e Event) error {
+	headers := make(map[string]string, len(e.Header))
+	for k := range e.Header {
+		headers[k] = e.Header.Get(k)
+	}
+
+	event := sentryEvent{
+		EventID:     strings.ReplaceAll(e.ID, "-", ""),
+		Timestamp:   e.Time.Format(time.RFC3339),
+		Platform:    "go",
+		Level:       "error",
+		Environment: s.environment,
+		Release:     s.release,
+		Message:     e.Message,
+		Request:     sentryRequest{Method: e.Method, URL: e.URL, Headers: headers},
+		Tags:        map[string]string{"error_id": e.ID},
+		Extra:       map[string]string{"stack": string(e.Stack)},
+	}
+	if e.RemoteIP != "" {
+		event.User = map[string]string{"ip_address": e.RemoteIP}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// An envelope is a header, then an item header and the item, each on a line
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	_ = enc.Encode(map[string]string{"event_id": event.EventID, "dsn": s.dsn, "sent_at": time.Now().UTC().Format(time.RFC3339)})
+	_ = enc.Encode(map[string]any{"type": "event", "length": len(payload)})
+	body.Write(payload)
+	body.WriteByte('\n')
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=shopit/%s, sentry_key=%s",
+		s.release, s.key))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting to sentry: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("sentry answered %s", res.Status)
+	}
+
+	return nil
+}
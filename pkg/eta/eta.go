@@ -0,0 +1,186 @@
+// Package eta estimates delivery windows from the configured transit matrices.
+//
+// An estimate combines the shipping method, the warehouse the order ships from and
+// the destination country: handling days at the warehouse plus the transit time of
+// the method for the destination, counted in business days.
+package eta
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/models"
+)
+
+// ErrUnknownMethod is returned when estimating for a method that is not configured.
+var ErrUnknownMethod = errors.New("unknown shipping method")
+
+// DefaultMethods are used when no methods are configured.
+var DefaultMethods = map[string]config.DeliveryMethod{
+	"standard": {Domestic: "2-5", International: "7-21"},
+}
+
+// days is a transit time range in business days.
+type days struct{ min, max int }
+
+type method struct {
+	domestic      days
+	international days
+	countries     map[string]days
+}
+
+// Estimator computes delivery windows.
+type Estimator struct {
+	warehouse     string
+	handling      int
+	defaultMethod string
+	methods       map[string]method
+}
+
+// New returns an Estimator for cfg, failing on malformed ranges or a default
+// method that is not configured. Method and country names are case-insensitive.
+func New(cfg config.Delivery) (*Estimator, error) {
+	if cfg.HandlingDays < 0 {
+		return nil, errors.New("delivery handling days must not be negative")
+	}
+
+	methods := cfg.Methods
+	if len(methods) == 0 {
+		methods = DefaultMethods
+	}
+
+	e := &Estimator{
+		warehouse:     normalize(cfg.Warehouse),
+		handling:      cfg.HandlingDays,
+		defaultMethod: normalize(cfg.DefaultMethod),
+		methods:       make(map[string]method, len(methods)),
+	}
+
+	for name, m := range methods {
+		var parsed method
+		var err error
+
+		if parsed.domestic, err = parseDays(m.Domestic); err != nil {
+			return nil, fmt.Errorf("delivery method %q domestic: %w", name, err)
+		}
+		if parsed.international, err = parseDays(m.International); err != nil {
+			return nil, fmt.Errorf("delivery method %q international: %w", name, err)
+		}
+
+		parsed.countries = make(map[string]days, len(m.Countries))
+		for country, r := range m.Countries {
+			if parsed.countries[normalize(country)], err = parseDays(r); err != nil {
+				return nil, fmt.Errorf("delivery method %q country %q: %w", name, country, err)
+			}
+		}
+
+		e.methods[normalize(name)] = parsed
+	}
+
+	if e.defaultMethod == "" {
+		e.defaultMethod = "standard"
+	}
+	if _, ok := e.methods[e.defaultMethod]; !ok {
+		return nil, fmt.Errorf("default delivery method %q is not configured", cfg.DefaultMethod)
+	}
+
+	return e, nil
+}
+
+// Methods returns the configured method names, sorted.
+func (e *Estimator) Methods() []string {
+	names := make([]string, 0, len(e.methods))
+	for name := range e.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Estimate returns when a shipment by methodName to country, ordered at from, is
+// expected to arrive. An empty methodName selects the default method.
+func (e *Estimator) Estimate(methodName, country string, from time.Time) (*models.DeliveryEstimate, error) {
+	name := normalize(methodName)
+	if name == "" {
+		name = e.defaultMethod
+	}
+
+	m, ok := e.methods[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMethod, methodName)
+	}
+
+	country = normalize(country)
+	transit, ok := m.countries[country]
+	if !ok {
+		transit = m.international
+		if country == e.warehouse {
+			transit = m.domestic
+		}
+	}
+
+	minDays, maxDays := e.handling+transit.min, e.handling+transit.max
+
+	return &models.DeliveryEstimate{
+		Method:   name,
+		MinDays:  minDays,
+		MaxDays:  maxDays,
+		Earliest: addBusinessDays(from, minDays),
+		Latest:   addBusinessDays(from, maxDays),
+	}, nil
+}
+
+// EstimateAll returns an estimate for every configured method, sorted by name.
+func (e *Estimator) EstimateAll(country string, from time.Time) []models.DeliveryEstimate {
+	var estimates []models.DeliveryEstimate
+	for _, name := range e.Methods() {
+		// the name is configured, so this cannot fail
+		est, _ := e.Estimate(name, country, from)
+		estimates = append(estimates, *est)
+	}
+	return estimates
+}
+
+// addBusinessDays returns the date n business days after t, skipping weekends.
+func addBusinessDays(t time.Time, n int) time.Time {
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for n > 0 {
+		d = d.AddDate(0, 0, 1)
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			n--
+		}
+	}
+	return d
+}
+
+// parseDays parses "min-max", or a single number of days.
+func parseDays(s string) (days, error) {
+	lo, hi, found := strings.Cut(strings.TrimSpace(s), "-")
+	if !found {
+		hi = lo
+	}
+
+	minDays, err := strconv.Atoi(strings.TrimSpace(lo))
+	if err != nil {
+		return days{}, fmt.Errorf("invalid range %q, use min-max days", s)
+	}
+	maxDays, err := strconv.Atoi(strings.TrimSpace(hi))
+	if err != nil {
+		return days{}, fmt.Errorf("invalid range %q, use min-max days", s)
+	}
+
+	if minDays < 0 || maxDays < minDays {
+		return days{}, fmt.Errorf("invalid range %q, min must not be negative or above max", s)
+	}
+
+	return days{min: minDays, max: maxDays}, nil
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
@@ -0,0 +1,85 @@
+package eta_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/pkg/eta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEstimator(t *testing.T) *eta.Estimator {
+	e, err := eta.New(config.Delivery{
+		Warehouse:     "Ghana",
+		HandlingDays:  1,
+		DefaultMethod: "standard",
+		Methods: map[string]config.DeliveryMethod{
+			"standard": {Domestic: "2-4", International: "7-14", Countries: map[string]string{"nigeria": "4-6"}},
+			"express":  {Domestic: "1", International: "3-5"},
+		},
+	})
+	require.NoError(t, err)
+	return e
+}
+
+func TestEstimate(t *testing.T) {
+	e := newEstimator(t)
+
+	// a Friday, so weekends are skipped right away
+	from := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+
+	t.Run("Domestic default method", func(t *testing.T) {
+		est, err := e.Estimate("", "ghana", from)
+		require.NoError(t, err)
+
+		assert.Equal(t, "standard", est.Method)
+		assert.Equal(t, 3, est.MinDays)
+		assert.Equal(t, 5, est.MaxDays)
+		assert.Equal(t, time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC), est.Earliest)
+		assert.Equal(t, time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC), est.Latest)
+	})
+
+	t.Run("Country override", func(t *testing.T) {
+		est, err := e.Estimate("standard", " Nigeria ", from)
+		require.NoError(t, err)
+
+		assert.Equal(t, 5, est.MinDays)
+		assert.Equal(t, 7, est.MaxDays)
+	})
+
+	t.Run("International", func(t *testing.T) {
+		est, err := e.Estimate("Express", "Kenya", from)
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, est.MinDays)
+		assert.Equal(t, 6, est.MaxDays)
+	})
+
+	t.Run("Unknown method", func(t *testing.T) {
+		_, err := e.Estimate("drone", "Ghana", from)
+		assert.ErrorIs(t, err, eta.ErrUnknownMethod)
+	})
+
+	t.Run("All methods", func(t *testing.T) {
+		all := e.EstimateAll("Ghana", from)
+		require.Len(t, all, 2)
+		assert.Equal(t, "express", all[0].Method)
+		assert.Equal(t, "standard", all[1].Method)
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := eta.New(config.Delivery{Methods: map[string]config.DeliveryMethod{
+		"standard": {Domestic: "5-2", International: "7"},
+	}})
+	assert.Error(t, err)
+
+	_, err = eta.New(config.Delivery{DefaultMethod: "express"})
+	assert.Error(t, err)
+
+	e, err := eta.New(config.Delivery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"standard"}, e.Methods())
+}
@@ -0,0 +1,81 @@
+package etag_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/pkg/etag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	body := `{"success":true,"products":[]}`
+	status := http.StatusOK
+	h := etag.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+
+	serve := func(method, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/product/products", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Response is tagged", func(t *testing.T) {
+		rr := serve(http.MethodGet, "")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, body, rr.Body.String())
+		assert.Equal(t, etag.Of([]byte(body)), rr.Header().Get("ETag"))
+		assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Unchanged response is not sent", func(t *testing.T) {
+		rr := serve(http.MethodGet, `"other", `+etag.Of([]byte(body)))
+
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+		assert.Empty(t, rr.Body.String())
+	})
+
+	t.Run("Changed response is sent", func(t *testing.T) {
+		rr := serve(http.MethodGet, etag.Of([]byte("stale")))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, body, rr.Body.String())
+	})
+
+	t.Run("Errors are not tagged", func(t *testing.T) {
+		status = http.StatusBadRequest
+		defer func() { status = http.StatusOK }()
+
+		rr := serve(http.MethodGet, etag.Of([]byte(body)))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, body, rr.Body.String())
+		assert.Empty(t, rr.Header().Get("ETag"))
+	})
+
+	t.Run("Other methods are not tagged", func(t *testing.T) {
+		rr := serve(http.MethodPost, etag.Of([]byte(body)))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("ETag"))
+	})
+}
+
+func TestMatch(t *testing.T) {
+	tag := etag.Of([]byte("body"))
+
+	assert.True(t, etag.Match(tag, tag))
+	assert.True(t, etag.Match(tag[2:], tag))
+	assert.True(t, etag.Match("*", tag))
+	assert.False(t, etag.Match("", tag))
+	assert.False(t, etag.Match(`W/"abc"`, tag))
+}
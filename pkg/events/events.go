@@ -0,0 +1,186 @@
+// Package events is the in-process bus usecases publish domain events on, so that
+// side effects such as realtime pushes and audit logs are added as subscribers
+// instead of inline. Side effects that must survive a restart, such as emails, are
+// handled from the outbox instead (see package outbox).
+//
+// Every subscriber receives its events in the order they were published, on a
+// goroutine of its own: a slow or failing subscriber neither delays the publisher
+// nor the other subscribers. Events are not persisted; those still queued when the
+// process stops are handled by Close.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jofosuware/go/shopit/pkg/logger"
+)
+
+// Names of the domain events.
+const (
+	// UserRegistered carries the models.User who signed up, without the password.
+	UserRegistered = "user.registered"
+
+	// AvatarPending carries the models.PendingAvatar of a user whose avatar could not be
+	// stored when they signed up. It is only written to the outbox.
+	AvatarPending = "user.avatar_pending"
+
+	// PasswordChanged carries the models.PasswordChange of a user who changed or reset
+	// their password. It is only written to the outbox.
+	PasswordChanged = "user.password_changed"
+
+	// OrderCreated carries the models.Order placed, with its items, shipping and payment.
+	OrderCreated = "order.created"
+
+	// OrderStatusChanged carries the models.Order in its new status.
+	OrderStatusChanged = "order.status_changed"
+
+	// OrderDelivered carries the models.Order delivered, after its OrderStatusChanged.
+	OrderDelivered = "order.delivered"
+
+	// ProductUpdated carries the models.Product updated by an admin, with its variants.
+	ProductUpdated = "product.updated"
+
+	// ProductPriceDropped carries the models.PriceDrop of a product for one user who
+	// wishlisted it. It is only written to the outbox.
+	ProductPriceDropped = "product.price_dropped"
+
+	// StockLow carries the models.LowStockItem of a product, or variant, whose stock
+	// just fell to its low-stock threshold or under. It is only written to the outbox.
+	StockLow = "product.stock_low"
+
+	// TicketUpdated carries the models.TicketUpdate of a support ticket opened, answered
+	// or given a new status. It is only written to the outbox.
+	TicketUpdated = "ticket.updated"
+)
+
+// queueSize is how many events a subscriber can fall behind before publishing waits
+// for it.
+const queueSize = 256
+
+// Event is something that happened in the domain.
+type Event struct {
+	Name       string
+	OccurredAt time.Time
+	Data       interface{}
+}
+
+// Handler handles an event. Its error is logged.
+type Handler func(e Event) error
+
+type subscriber struct {
+	names   map[string]bool
+	handler Handler
+	queue   chan Event
+}
+
+// wants reports whether s subscribed to events named name; a subscriber without
+// names wants every event.
+func (s *subscriber) wants(name string) bool {
+	return len(s.names) == 0 || s.names[name]
+}
+
+// Bus delivers the events published to their subscribers. A nil *Bus publishes to
+// no one.
+type Bus struct {
+	logger logger.Logger
+
+	mu          sync.RWMutex
+	subscribers []*subscriber
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+// New returns a Bus logging the errors of its handlers with logger.
+func New(logger logger.Logger) *Bus {
+	return &Bus{logger: logger}
+}
+
+// Subscribe calls handler with the events named names, or with every event when no
+// name is given.
+func (b *Bus) Subscribe(handler Handler, names ...string) {
+	if b == nil {
+		return
+	}
+
+	s := &subscriber{handler: handler, queue: make(chan Event, queueSize), names: make(map[string]bool, len(names))}
+	for _, n := range names {
+		s.names[n] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.subscribers = append(b.subscribers, s)
+	b.wg.Add(1)
+	go b.run(s)
+}
+
+// Publish queues an event named name carrying data for its subscribers. It only
+// waits when a subscriber has fallen queueSize events behind. Events published once
+// the bus is closed are dropped.
+func (b *Bus) Publish(name string, data interface{}) {
+	if b == nil {
+		return
+	}
+
+	e := Event{Name: name, OccurredAt: time.Now(), Data: data}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	for _, s := range b.subscribers {
+		if s.wants(name) {
+			s.queue <- e
+		}
+	}
+}
+
+// Close stops accepting events and waits for the subscribers to handle those already
+// published.
+func (b *Bus) Close() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, s := range b.subscribers {
+			close(s.queue)
+		}
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+}
+
+// run hands the events of s to its handler until the bus is closed.
+func (b *Bus) run(s *subscriber) {
+	defer b.wg.Done()
+
+	for e := range s.queue {
+		b.handle(s, e)
+	}
+}
+
+// handle calls the handler of s with e, logging its error or panic.
+func (b *Bus) handle(s *subscriber, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Errorf("panic handling %s event: %v", e.Name, r)
+		}
+	}()
+
+	if err := s.handler(e); err != nil {
+		b.logger.Errorf("error handling %s event: %v", e.Name, err)
+	}
+}
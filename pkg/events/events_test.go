@@ -0,0 +1,81 @@
+package events_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/pkg/events"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recorder is a handler remembering the names of the events it handled.
+type recorder struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (r *recorder) handle(e events.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.names = append(r.names, e.Name)
+	return nil
+}
+
+func TestBus(t *testing.T) {
+	t.Run("Subscribers get their events in order", func(t *testing.T) {
+		bus := events.New(mockLogger.NewLogger(t))
+		all, orders := &recorder{}, &recorder{}
+		bus.Subscribe(all.handle)
+		bus.Subscribe(orders.handle, events.OrderCreated, events.OrderDelivered)
+
+		bus.Publish(events.UserRegistered, nil)
+		bus.Publish(events.OrderCreated, nil)
+		bus.Publish(events.OrderStatusChanged, nil)
+		bus.Publish(events.OrderDelivered, nil)
+		bus.Close()
+
+		assert.Equal(t, []string{events.UserRegistered, events.OrderCreated, events.OrderStatusChanged, events.OrderDelivered}, all.names)
+		assert.Equal(t, []string{events.OrderCreated, events.OrderDelivered}, orders.names)
+	})
+
+	t.Run("Events published once closed are dropped", func(t *testing.T) {
+		bus := events.New(mockLogger.NewLogger(t))
+		rec := &recorder{}
+		bus.Subscribe(rec.handle)
+
+		bus.Close()
+		bus.Close()
+		bus.Publish(events.OrderCreated, nil)
+		bus.Subscribe(rec.handle)
+
+		assert.Empty(t, rec.names)
+	})
+
+	t.Run("Failing handlers are logged", func(t *testing.T) {
+		logger := mockLogger.NewLogger(t)
+		bus := events.New(logger)
+		bus.Subscribe(func(e events.Event) error { return errors.New("smtp error") }, events.OrderCreated)
+		bus.Subscribe(func(e events.Event) error { panic("boom") }, events.OrderCreated)
+		rec := &recorder{}
+		bus.Subscribe(rec.handle, events.OrderCreated)
+
+		logger.On("Errorf", "error handling %s event: %v", events.OrderCreated, mock.Anything).Once()
+		logger.On("Errorf", "panic handling %s event: %v", events.OrderCreated, "boom").Once()
+
+		bus.Publish(events.OrderCreated, nil)
+		bus.Close()
+
+		assert.Equal(t, []string{events.OrderCreated}, rec.names)
+	})
+
+	t.Run("Nil bus", func(t *testing.T) {
+		var bus *events.Bus
+		bus.Subscribe(func(e events.Event) error { return nil })
+		bus.Publish(events.OrderCreated, nil)
+		bus.Close()
+	})
+}
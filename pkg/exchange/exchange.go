@@ -0,0 +1,205 @@
+// Package exchange converts amounts between the currencies the shop accepts and
+// picks the currency a request is served in.
+//
+// Rates come from a Provider as the units of each currency that one unit of the
+// shop currency buys. A Cache keeps the rates of a Provider for a while, so a rate
+// source is asked at most once per TTL, and keeps serving the last rates when the
+// source is unavailable.
+package exchange
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// Header selects the currency of a request, such as "EUR".
+const Header = "X-Currency"
+
+// ErrNoRate is returned when there is no exchange rate for a currency.
+var ErrNoRate = errors.New("no exchange rate for currency")
+
+// Provider returns exchange rates.
+type Provider interface {
+	// Rates returns how many units of each currency one unit of base buys
+	Rates(base string) (map[string]float64, error)
+}
+
+// Static is a Provider of fixed rates from the shop currency, by currency code.
+type Static map[string]float64
+
+// NewStatic returns the fixed rates, with the codes upper case.
+func NewStatic(rates map[string]float64) Static {
+	s := make(Static, len(rates))
+	for code, rate := range rates {
+		s[strings.ToUpper(code)] = rate
+	}
+
+	return s
+}
+
+// Rates returns the fixed rates.
+func (s Static) Rates(string) (map[string]float64, error) {
+	return s, nil
+}
+
+// HTTP is a Provider fetching rates from a JSON API that answers {"rates": {"EUR":
+// 0.92, ...}}. URL may contain {base}, which is replaced by the base currency.
+type HTTP struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewHTTP returns an HTTP provider for url whose requests time out after timeout.
+func NewHTTP(url string, timeout time.Duration) *HTTP {
+	return &HTTP{URL: url, Client: &http.Client{Timeout: timeout}}
+}
+
+// Rates fetches the rates of base.
+func (h *HTTP) Rates(base string) (map[string]float64, error) {
+	res, err := h.Client.Get(strings.ReplaceAll(h.URL, "{base}", base))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchange rates: unexpected status %s", res.Status)
+	}
+
+	var body struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("exchange rates: %w", err)
+	}
+
+	return NewStatic(body.Rates), nil
+}
+
+// Cache keeps the rates of a Provider for TTL.
+type Cache struct {
+	provider Provider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	rates   map[string]map[string]float64
+	fetched map[string]time.Time
+}
+
+// NewCache returns a Cache of the rates of provider.
+func NewCache(provider Provider, ttl time.Duration) *Cache {
+	return &Cache{
+		provider: provider,
+		ttl:      ttl,
+		now:      time.Now,
+		rates:    make(map[string]map[string]float64),
+		fetched:  make(map[string]time.Time),
+	}
+}
+
+// Rates returns the cached rates of base, fetching them when they are older than the
+// TTL. When fetching fails the rates fetched before are returned, if any.
+func (c *Cache) Rates(base string) (map[string]float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rates, ok := c.rates[base]
+	if ok && c.now().Sub(c.fetched[base]) < c.ttl {
+		return rates, nil
+	}
+
+	fresh, err := c.provider.Rates(base)
+	if err != nil {
+		if ok {
+			return rates, nil
+		}
+		return nil, err
+	}
+
+	c.rates[base] = fresh
+	c.fetched[base] = c.now()
+
+	return fresh, nil
+}
+
+// Converter converts amounts between currencies with the rates of a Provider.
+type Converter struct {
+	base     string
+	provider Provider
+}
+
+// New returns a Converter using the rates of provider from the shop currency, which
+// must be set beforehand.
+func New(provider Provider) *Converter {
+	return &Converter{base: money.DefaultCurrency, provider: provider}
+}
+
+// Convert returns m in currency to, rounded to a minor unit.
+func (c *Converter) Convert(m money.Money, to string) (money.Money, error) {
+	to = strings.ToUpper(to)
+	from := m.Currency
+	if from == "" {
+		from = c.base
+	}
+	if from == to {
+		return money.New(m.Amount, to), nil
+	}
+
+	rates, err := c.provider.Rates(c.base)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("error fetching exchange rates: %v", err)
+	}
+
+	rate := func(code string) (float64, error) {
+		if code == c.base {
+			return 1, nil
+		}
+		if r, ok := rates[code]; ok && r > 0 {
+			return r, nil
+		}
+		return 0, fmt.Errorf("%w %s", ErrNoRate, code)
+	}
+
+	fromRate, err := rate(from)
+	if err != nil {
+		return money.Money{}, err
+	}
+	toRate, err := rate(to)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	scale := math.Pow10(money.Digits(to) - money.Digits(from))
+	amount := math.Round(float64(m.Amount) * toRate / fromRate * scale)
+
+	return money.New(int64(amount), to), nil
+}
+
+// Currency returns the currency to serve r in: the one named by the Header, else
+// the preferred currency of the signed in user, else the shop currency. A Header
+// naming a currency the shop does not accept is an error.
+func Currency(r *http.Request) (string, error) {
+	if code := strings.TrimSpace(r.Header.Get(Header)); code != "" {
+		if !money.Supported(code) {
+			return "", fmt.Errorf("%w: %s", money.ErrUnsupportedCurrency, code)
+		}
+		return strings.ToUpper(code), nil
+	}
+
+	if user, ok := r.Context().Value(utils.UserContextKey).(*models.User); ok && money.Supported(user.Currency) {
+		return strings.ToUpper(user.Currency), nil
+	}
+
+	return money.DefaultCurrency, nil
+}
@@ -0,0 +1,164 @@
+package exchange_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/exchange"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingProvider returns rates, or err when set, and counts the calls.
+type countingProvider struct {
+	rates map[string]float64
+	err   error
+	calls int
+}
+
+func (p *countingProvider) Rates(string) (map[string]float64, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.rates, nil
+}
+
+func acceptCurrencies(t *testing.T) {
+	require.NoError(t, money.Accept("EUR", "JPY"))
+	t.Cleanup(func() { money.Accept() })
+}
+
+func TestConvert(t *testing.T) {
+	acceptCurrencies(t)
+	rates := exchange.New(exchange.NewStatic(map[string]float64{"eur": 0.5, "JPY": 150}))
+
+	t.Run("From the shop currency", func(t *testing.T) {
+		m, err := rates.Convert(money.Of(1999), "EUR")
+		require.NoError(t, err)
+		assert.Equal(t, money.New(1000, "EUR"), m)
+	})
+
+	t.Run("Between other currencies", func(t *testing.T) {
+		m, err := rates.Convert(money.New(100, "EUR"), "jpy")
+		require.NoError(t, err)
+		assert.Equal(t, money.New(300, "JPY"), m)
+	})
+
+	t.Run("Same currency", func(t *testing.T) {
+		m, err := rates.Convert(money.New(100, "EUR"), "EUR")
+		require.NoError(t, err)
+		assert.Equal(t, money.New(100, "EUR"), m)
+	})
+
+	t.Run("No rate", func(t *testing.T) {
+		_, err := rates.Convert(money.Of(100), "GBP")
+		assert.ErrorIs(t, err, exchange.ErrNoRate)
+	})
+}
+
+func TestCache(t *testing.T) {
+	t.Run("Rates are fetched once per ttl", func(t *testing.T) {
+		p := &countingProvider{rates: map[string]float64{"EUR": 0.5}}
+		c := exchange.NewCache(p, time.Hour)
+
+		for i := 0; i < 3; i++ {
+			rates, err := c.Rates("USD")
+			require.NoError(t, err)
+			assert.Equal(t, 0.5, rates["EUR"])
+		}
+		assert.Equal(t, 1, p.calls)
+	})
+
+	t.Run("Stale rates are served when fetching fails", func(t *testing.T) {
+		p := &countingProvider{rates: map[string]float64{"EUR": 0.5}}
+		c := exchange.NewCache(p, 0)
+
+		_, err := c.Rates("USD")
+		require.NoError(t, err)
+
+		p.err = errors.New("unavailable")
+		rates, err := c.Rates("USD")
+		require.NoError(t, err)
+		assert.Equal(t, 0.5, rates["EUR"])
+		assert.Equal(t, 2, p.calls)
+	})
+
+	t.Run("No rates fetched yet", func(t *testing.T) {
+		c := exchange.NewCache(&countingProvider{err: errors.New("unavailable")}, time.Hour)
+
+		_, err := c.Rates("USD")
+		assert.Error(t, err)
+	})
+}
+
+func TestHTTP(t *testing.T) {
+	t.Run("Rates are read from the response", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/latest/USD", r.URL.Path)
+			fmt.Fprint(w, `{"rates": {"eur": 0.92}}`)
+		}))
+		defer srv.Close()
+
+		rates, err := exchange.NewHTTP(srv.URL+"/latest/{base}", time.Second).Rates("USD")
+		require.NoError(t, err)
+		assert.Equal(t, 0.92, rates["EUR"])
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		_, err := exchange.NewHTTP(srv.URL, time.Second).Rates("USD")
+		assert.Error(t, err)
+	})
+}
+
+func TestCurrency(t *testing.T) {
+	acceptCurrencies(t)
+
+	newRequest := func(header string, user *models.User) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/products", nil)
+		if header != "" {
+			r.Header.Set(exchange.Header, header)
+		}
+		if user != nil {
+			r = r.WithContext(context.WithValue(r.Context(), utils.UserContextKey, user))
+		}
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		user   *models.User
+		want   string
+	}{
+		{"Header", "eur", &models.User{Currency: "JPY"}, "EUR"},
+		{"User preference", "", &models.User{Currency: "JPY"}, "JPY"},
+		{"Shop currency", "", &models.User{}, money.DefaultCurrency},
+		{"Anonymous", "", nil, money.DefaultCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := exchange.Currency(newRequest(tt.header, tt.user))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("Header currency not accepted", func(t *testing.T) {
+		_, err := exchange.Currency(newRequest("GBP", nil))
+		assert.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+	})
+}
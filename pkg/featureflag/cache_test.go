@@ -0,0 +1,22 @@
+package featureflag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExposureCache(t *testing.T) {
+	c := newExposureCache(2)
+
+	assert.True(t, c.add("a"))
+	assert.True(t, c.add("b"))
+	assert.False(t, c.add("a"), "a is remembered")
+
+	assert.True(t, c.add("c"), "c evicts b, the least recently used")
+	assert.True(t, c.add("b"))
+	assert.Len(t, c.keys, 2)
+
+	c.remove("b")
+	assert.True(t, c.add("b"), "a removed key is reported again")
+}
@@ -0,0 +1,320 @@
+// Package featureflag evaluates preview cohorts for feature flags.
+//
+// A flag is enabled for a subject when the subject is on the flag's allowlist or
+// falls inside its percentage rollout. Subjects are bucketed by hashing the flag
+// name with the subject, so a user stays in the same cohort across requests while
+// different flags get independent cohorts.
+package featureflag
+
+import (
+	"container/list"
+	"context"
+	"hash/fnv"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// Variants an exposure is recorded under.
+const (
+	VariantTreatment = "treatment"
+	VariantControl   = "control"
+)
+
+// Recorder persists exposures, i.e. which variant of a flag a subject was served, and
+// links anonymous subjects to the users they turn out to be.
+type Recorder interface {
+	RecordExposure(flag, variant, subject string) error
+
+	// LinkSubject attributes the exposures of the anonymous subject to the user userID
+	LinkSubject(subject, userID string) error
+}
+
+// exposureCacheSize is how many flag and subject pairs are remembered as already
+// reported, so that a busy process does not write every exposure again.
+const exposureCacheSize = 10000
+
+// CookieName holds the anonymous subject id of visitors that are not logged in.
+const CookieName = "shopit_cohort"
+
+type contextKey string
+
+const (
+	flagsContextKey   contextKey = "featureflags"
+	subjectContextKey contextKey = "featureflags_subject"
+)
+
+// Flags holds the configured feature flags.
+type Flags struct {
+	flags    map[string]config.FeatureFlag
+	secure   bool
+	recorder Recorder
+	exposed  *exposureCache
+}
+
+// New returns Flags for cfg.Features. Flag names are case-insensitive.
+func New(cfg *config.Config) *Flags {
+	f := &Flags{
+		flags:   make(map[string]config.FeatureFlag, len(cfg.Features)),
+		secure:  cfg.Cookie.Secure,
+		exposed: newExposureCache(exposureCacheSize),
+	}
+	for name, flag := range cfg.Features {
+		f.flags[strings.ToLower(name)] = flag
+	}
+
+	return f
+}
+
+// Enabled reports whether the flag is enabled for subject. Unknown flags are disabled.
+func (f *Flags) Enabled(name, subject string) bool {
+	flag, ok := f.flags[strings.ToLower(name)]
+	if !ok {
+		return false
+	}
+
+	for _, s := range flag.Allowlist {
+		if s == subject && s != "" {
+			return true
+		}
+	}
+
+	if flag.Percentage >= 100 {
+		return true
+	}
+	if flag.Percentage <= 0 || subject == "" {
+		return false
+	}
+
+	return bucket(name, subject) < flag.Percentage
+}
+
+// SetRecorder makes Enabled and Variant report exposures to r. A flag and subject pair
+// is reported again only once it has dropped out of a bounded cache of recent ones, so
+// the recorder is expected to ignore duplicates.
+func (f *Flags) SetRecorder(r Recorder) {
+	f.recorder = r
+}
+
+// expose reports that subject was served variant of the flag. Exposure tracking is
+// best effort, so failures are not surfaced to the request.
+func (f *Flags) expose(name, variant, subject string) {
+	if f.recorder == nil || subject == "" {
+		return
+	}
+
+	name = strings.ToLower(name)
+	if _, ok := f.flags[name]; !ok {
+		return
+	}
+
+	key := name + "\x00" + subject
+	if !f.exposed.add(key) {
+		return
+	}
+
+	if err := f.recorder.RecordExposure(name, variant, subject); err != nil {
+		f.exposed.remove(key)
+	}
+}
+
+// link reports that the anonymous subject of the request behind ctx is the logged in
+// user, so that the orders of the user convert the exposures made before they logged
+// in. Like exposures, a link is reported again only once it has left the cache.
+func (f *Flags) link(ctx context.Context) {
+	session, subject := Session(ctx), Subject(ctx)
+	if f.recorder == nil || session == "" || subject == session {
+		return
+	}
+
+	key := "\x00" + session + "\x00" + subject
+	if !f.exposed.add(key) {
+		return
+	}
+
+	if err := f.recorder.LinkSubject(session, subject); err != nil {
+		f.exposed.remove(key)
+	}
+}
+
+// Cohorts returns the names of the flags enabled for subject, sorted.
+func (f *Flags) Cohorts(subject string) []string {
+	cohorts := []string{}
+	for name := range f.flags {
+		if f.Enabled(name, subject) {
+			cohorts = append(cohorts, name)
+		}
+	}
+	sort.Strings(cohorts)
+
+	return cohorts
+}
+
+// Middleware makes the flags available to handlers through Enabled. Visitors
+// without a cohort cookie get one so anonymous traffic is bucketed consistently.
+func (f *Flags) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		anonymous := ""
+		if c, err := r.Cookie(CookieName); err == nil && c.Value != "" {
+			anonymous = c.Value
+		} else {
+			anonymous = uuid.NewString()
+			http.SetCookie(w, &http.Cookie{
+				Name:     CookieName,
+				Value:    anonymous,
+				Path:     "/",
+				MaxAge:   365 * 24 * 60 * 60,
+				HttpOnly: true,
+				Secure:   f.secure,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		ctx := context.WithValue(r.Context(), flagsContextKey, f)
+		ctx = context.WithValue(ctx, subjectContextKey, anonymous)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Require responds with 404 unless the flag is enabled for the request, hiding
+// preview routes from everyone outside the cohort.
+func Require(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !Enabled(r.Context(), name) {
+				http.NotFound(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Enabled reports whether the flag is enabled for the request behind ctx and records
+// the exposure. The subject is the logged in user's id, or the anonymous cohort cookie otherwise;
+// a logged in user is linked to their cookie.
+func Enabled(ctx context.Context, name string) bool {
+	f, ok := ctx.Value(flagsContextKey).(*Flags)
+	if !ok {
+		return false
+	}
+	f.link(ctx)
+
+	subject := Subject(ctx)
+	enabled := f.Enabled(name, subject)
+
+	variant := VariantControl
+	if enabled {
+		variant = VariantTreatment
+	}
+	f.expose(name, variant, subject)
+
+	return enabled
+}
+
+// Variant returns the flags enabled for the request behind ctx as a comma separated
+// list, the form stored with orders. It is empty when the request is in no cohort.
+// The exposure to every flag is recorded, under the control variant for the flags
+// that are not enabled, so that orders convert against the subjects they were
+// served to, including the anonymous subject of a logged in user.
+func Variant(ctx context.Context) string {
+	f, ok := ctx.Value(flagsContextKey).(*Flags)
+	if !ok {
+		return ""
+	}
+	f.link(ctx)
+
+	subject := Subject(ctx)
+	cohorts := f.Cohorts(subject)
+	for name := range f.flags {
+		variant := VariantControl
+		if slices.Contains(cohorts, name) {
+			variant = VariantTreatment
+		}
+		f.expose(name, variant, subject)
+	}
+
+	return strings.Join(cohorts, ",")
+}
+
+// Subject returns the id flags are evaluated against for the request behind ctx.
+func Subject(ctx context.Context) string {
+	if u, ok := ctx.Value(utils.UserContextKey).(*models.User); ok && u != nil {
+		return u.ID.String()
+	}
+
+	return Session(ctx)
+}
+
+// Session returns the anonymous id of the visitor behind ctx, kept in the cohort cookie,
+// whether or not they are logged in. It is empty outside Middleware.
+func Session(ctx context.Context) string {
+	s, _ := ctx.Value(subjectContextKey).(string)
+
+	return s
+}
+
+// bucket maps subject to a stable value in [0, 100) for the flag.
+func bucket(name, subject string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.ToLower(name) + ":" + subject))
+
+	return int(h.Sum32() % 100)
+}
+
+// exposureCache remembers the most recently reported flag and subject pairs, evicting
+// the least recently used once it holds size of them.
+type exposureCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	keys  map[string]*list.Element
+}
+
+func newExposureCache(size int) *exposureCache {
+	return &exposureCache{
+		size:  size,
+		order: list.New(),
+		keys:  make(map[string]*list.Element, size),
+	}
+}
+
+// add remembers key and reports whether it was not remembered already.
+func (c *exposureCache) add(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.keys[key]; ok {
+		c.order.MoveToFront(e)
+		return false
+	}
+
+	c.keys[key] = c.order.PushFront(key)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.keys, oldest.Value.(string))
+	}
+
+	return true
+}
+
+// remove forgets key, so that it is reported again.
+func (c *exposureCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.keys[key]; ok {
+		c.order.Remove(e)
+		delete(c.keys, key)
+	}
+}
@@ -0,0 +1,180 @@
+package featureflag_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/featureflag"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFlags() *featureflag.Flags {
+	return featureflag.New(&config.Config{Features: map[string]config.FeatureFlag{
+		"newcheckout": {Percentage: 0, Allowlist: []string{"beta-user"}},
+		"newsearch":   {Percentage: 30},
+		"everyone":    {Percentage: 100},
+	}})
+}
+
+func TestEnabled(t *testing.T) {
+	f := newFlags()
+
+	t.Run("allowlist", func(t *testing.T) {
+		assert.True(t, f.Enabled("newcheckout", "beta-user"))
+		assert.True(t, f.Enabled("NewCheckout", "beta-user"))
+		assert.False(t, f.Enabled("newcheckout", "someone-else"))
+	})
+
+	t.Run("unknown flag is disabled", func(t *testing.T) {
+		assert.False(t, f.Enabled("missing", "beta-user"))
+	})
+
+	t.Run("percentage rollout is sticky and roughly proportional", func(t *testing.T) {
+		enabled := 0
+		for i := 0; i < 1000; i++ {
+			subject := fmt.Sprintf("user-%d", i)
+			got := f.Enabled("newsearch", subject)
+			assert.Equal(t, got, f.Enabled("newsearch", subject))
+			if got {
+				enabled++
+			}
+		}
+
+		assert.InDelta(t, 300, enabled, 60)
+	})
+
+	t.Run("full rollout", func(t *testing.T) {
+		assert.True(t, f.Enabled("everyone", ""))
+		cohorts := f.Cohorts("beta-user")
+		assert.Contains(t, cohorts, "everyone")
+		assert.Contains(t, cohorts, "newcheckout")
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	f := newFlags()
+
+	handler := f.Middleware(featureflag.Require("newcheckout")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	t.Run("anonymous visitor gets a cohort cookie", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/checkout", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Header().Get("Set-Cookie"), featureflag.CookieName+"=")
+	})
+
+	t.Run("allowlisted anonymous id", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/checkout", nil)
+		r.AddCookie(&http.Cookie{Name: featureflag.CookieName, Value: "beta-user"})
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("logged in user is evaluated by id", func(t *testing.T) {
+		user := &models.User{ID: uuid.New()}
+		f := featureflag.New(&config.Config{Features: map[string]config.FeatureFlag{
+			"newcheckout": {Allowlist: []string{user.ID.String()}},
+		}})
+
+		var enabled bool
+		h := f.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the user is added by IsAuthenticated further down the chain
+			ctx := context.WithValue(r.Context(), utils.UserContextKey, user)
+			enabled = featureflag.Enabled(ctx, "newcheckout")
+		}))
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.True(t, enabled)
+	})
+}
+
+type recorder struct {
+	exposures []string
+	links     []string
+}
+
+func (r *recorder) RecordExposure(flag, variant, subject string) error {
+	r.exposures = append(r.exposures, flag+"/"+variant+"/"+subject)
+	return nil
+}
+
+func (r *recorder) LinkSubject(subject, userID string) error {
+	r.links = append(r.links, subject+"/"+userID)
+	return nil
+}
+
+func TestExposure(t *testing.T) {
+	f := newFlags()
+	rec := &recorder{}
+	f.SetRecorder(rec)
+
+	var variant string
+	handler := f.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		featureflag.Enabled(r.Context(), "newcheckout")
+		featureflag.Enabled(r.Context(), "missing")
+		variant = featureflag.Variant(r.Context())
+	}))
+
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(&http.Cookie{Name: featureflag.CookieName, Value: "beta-user"})
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	assert.ElementsMatch(t, []string{"newcheckout/treatment/beta-user", "newsearch/treatment/beta-user",
+		"everyone/treatment/beta-user"}, rec.exposures, "every flag is recorded once")
+	assert.Contains(t, variant, "newcheckout")
+}
+
+func TestVariantExposure(t *testing.T) {
+	f := newFlags()
+	rec := &recorder{}
+	f.SetRecorder(rec)
+
+	ctx := context.WithValue(context.Background(), utils.UserContextKey, &models.User{ID: uuid.New()})
+	f.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		featureflag.Variant(r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	assert.Len(t, rec.exposures, 3)
+	assert.Contains(t, rec.exposures, "newcheckout/control/"+ctx.Value(utils.UserContextKey).(*models.User).ID.String())
+}
+
+func TestLinkSubject(t *testing.T) {
+	f := newFlags()
+	rec := &recorder{}
+	f.SetRecorder(rec)
+
+	handler := f.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		featureflag.Variant(r.Context())
+	}))
+
+	user := &models.User{ID: uuid.New()}
+	for _, ctx := range []context.Context{
+		context.Background(),
+		context.WithValue(context.Background(), utils.UserContextKey, user),
+		context.WithValue(context.Background(), utils.UserContextKey, user),
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		r.AddCookie(&http.Cookie{Name: featureflag.CookieName, Value: "visitor"})
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	assert.Contains(t, rec.exposures, "newcheckout/control/visitor", "the anonymous visit is exposed")
+	assert.Equal(t, []string{"visitor/" + user.ID.String()}, rec.links, "the cookie is linked to the user once")
+}
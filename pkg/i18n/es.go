@@ -0,0 +1,114 @@
+package i18n
+
+// spanish translates the English texts of the shop to Spanish.
+var spanish = map[string]string{
+	// emails
+	"ShopIT Password Recovery": "Recuperación de tu contraseña de ShopIT",
+	"Your ShopIT account":      "Tu cuenta de ShopIT",
+	"ShopIT Account Deletion":  "Eliminación de tu cuenta de ShopIT",
+	"Your ShopIT order":        "Tu pedido de ShopIT",
+	"Your ShopIT order status": "El estado de tu pedido de ShopIT",
+	"Your ShopIT digest":       "Tu resumen de ShopIT",
+	"Your ShopIT sign-in link": "Tu enlace de acceso a ShopIT",
+	"an unknown device":        "un dispositivo desconocido",
+	"1 minute":                 "1 minuto",
+	"%d minutes":               "%d minutos",
+	"1 hour":                   "1 hora",
+	"%d hours":                 "%d horas",
+
+	// invoices
+	"Invoice":      "Factura",
+	"Order":        "Pedido",
+	"Date":         "Fecha",
+	"Status":       "Estado",
+	"Ship to":      "Enviar a",
+	"Phone":        "Tel.",
+	"Qty":          "Cant.",
+	"Product":      "Producto",
+	"Unit price":   "Precio unit.",
+	"Amount":       "Importe",
+	"Items":        "Artículos",
+	"Shipping":     "Envío",
+	"Tax":          "Impuestos",
+	"Tax (%s)":     "Impuestos (%s)",
+	"Discount":     "Descuento",
+	"Discount %s":  "Descuento %s",
+	"Store credit": "Saldo a favor",
+	"Total":        "Total",
+	"Payment":      "Pago",
+	"Provider":     "Proveedor",
+	"Paid":         "Pagada",
+
+	// order statuses
+	"Processing": "En preparación",
+	"Shipped":    "Enviado",
+	"Delivered":  "Entregado",
+	"Cancelled":  "Cancelado",
+
+	// support ticket statuses
+	"open":     "abierta",
+	"answered": "respondida",
+	"closed":   "cerrada",
+
+	// payment statuses
+	"requires_action":  "pendiente de acción",
+	"processing":       "en curso",
+	"requires_capture": "autorizado",
+	"succeeded":        "completado",
+	"canceled":         "cancelado",
+	"failed":           "fallido",
+
+	// API responses
+	"account is scheduled for deletion, use the link in the email to restore it": "la eliminación de la cuenta está programada, usa el enlace del correo para restaurarla",
+	"avatar moderation is temporarily unavailable, try again later":              "la moderación de avatares no está disponible temporalmente, inténtalo más tarde",
+	"image storage is temporarily unavailable, try again later":                  "el almacenamiento de imágenes no está disponible temporalmente, inténtalo más tarde",
+	"internal server error, contact support with the error id":                   "error interno del servidor, contacta con soporte indicando el identificador del error",
+	"sign-in link is invalid, has expired or was already used":                   "el enlace de acceso no es válido, ha caducado o ya se ha usado",
+	"user must login as admin to perform this task":                              "inicia sesión como administrador para realizar esta acción",
+	"you are not allowed to access this resource":                                "no tienes permiso para acceder a este recurso",
+	"body must only have a single JSON value":                                    "el cuerpo solo debe contener un valor JSON",
+	"restore link is invalid or has expired":                                     "el enlace de restauración no es válido o ha caducado",
+	"the shop is busy, try again shortly":                                        "la tienda está muy solicitada, inténtalo de nuevo en unos instantes",
+	"invalid authentication credentials":                                         "credenciales de autenticación no válidas",
+	"something went wrong, try again":                                            "algo salió mal, inténtalo de nuevo",
+	"this order is already paid":                                                 "este pedido ya está pagado",
+	"product is out of stock":                                                    "el producto está agotado",
+	"user is not logged in":                                                      "el usuario no ha iniciado sesión",
+	"user already exists":                                                        "el usuario ya existe",
+	"category not found":                                                         "categoría no encontrada",
+	"failed validation":                                                          "error de validación",
+	"Too many requests":                                                          "Demasiadas solicitudes",
+	"product not found":                                                          "producto no encontrado",
+	"order not found":                                                            "pedido no encontrado",
+	"ticket not found":                                                           "solicitud no encontrada",
+	"user not found":                                                             "usuario no encontrado",
+
+	// validation
+	"%s must be provided":                "%s es obligatorio",
+	"%s must be a valid email address":   "%s debe ser una dirección de correo válida",
+	"%s must be a valid URL":             "%s debe ser una URL válida",
+	"%s must be a valid id":              "%s debe ser un identificador válido",
+	"%s must be one of: %s":              "%s debe ser uno de: %s",
+	"%s must match %s":                   "%s debe coincidir con %s",
+	"%s must differ from %s":             "%s debe ser distinto de %s",
+	"%s must not have duplicates":        "%s no debe tener duplicados",
+	"%s must be at least %s characters":  "%s debe tener al menos %s caracteres",
+	"%s must be at most %s characters":   "%s debe tener como máximo %s caracteres",
+	"%s must be exactly %s characters":   "%s debe tener exactamente %s caracteres",
+	"%s must have at least 1 item":       "%s debe tener al menos 1 elemento",
+	"%s must have at least %s items":     "%s debe tener al menos %s elementos",
+	"%s must have at most 1 item":        "%s debe tener como máximo 1 elemento",
+	"%s must have at most %s items":      "%s debe tener como máximo %s elementos",
+	"%s must have exactly 1 item":        "%s debe tener exactamente 1 elemento",
+	"%s must have exactly %s items":      "%s debe tener exactamente %s elementos",
+	"%s must not be negative":            "%s no debe ser negativo",
+	"%s must be at least %s":             "%s debe ser al menos %s",
+	"%s must be at most %s":              "%s debe ser como máximo %s",
+	"%s must be exactly %s":              "%s debe ser exactamente %s",
+	"%s must be more than %s":            "%s debe ser mayor que %s",
+	"%s must be less than %s":            "%s debe ser menor que %s",
+	"%s is invalid":                      "%s no es válido",
+	"%s must not be blank":               "%s no debe estar vacío",
+	"%s must not be in the future":       "%s no debe ser una fecha futura",
+	"%s must be an amount such as 49.99": "%s debe ser un importe como 49,99",
+}
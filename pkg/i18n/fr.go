@@ -0,0 +1,114 @@
+package i18n
+
+// french translates the English texts of the shop to French.
+var french = map[string]string{
+	// emails
+	"ShopIT Password Recovery": "Réinitialisation de votre mot de passe ShopIT",
+	"Your ShopIT account":      "Votre compte ShopIT",
+	"ShopIT Account Deletion":  "Suppression de votre compte ShopIT",
+	"Your ShopIT order":        "Votre commande ShopIT",
+	"Your ShopIT order status": "Le statut de votre commande ShopIT",
+	"Your ShopIT digest":       "Votre récapitulatif ShopIT",
+	"Your ShopIT sign-in link": "Votre lien de connexion ShopIT",
+	"an unknown device":        "un appareil inconnu",
+	"1 minute":                 "1 minute",
+	"%d minutes":               "%d minutes",
+	"1 hour":                   "1 heure",
+	"%d hours":                 "%d heures",
+
+	// invoices
+	"Invoice":      "Facture",
+	"Order":        "Commande",
+	"Date":         "Date",
+	"Status":       "Statut",
+	"Ship to":      "Livrer à",
+	"Phone":        "Tél.",
+	"Qty":          "Qté",
+	"Product":      "Produit",
+	"Unit price":   "Prix unit.",
+	"Amount":       "Montant",
+	"Items":        "Articles",
+	"Shipping":     "Livraison",
+	"Tax":          "Taxes",
+	"Tax (%s)":     "Taxes (%s)",
+	"Discount":     "Remise",
+	"Discount %s":  "Remise %s",
+	"Store credit": "Avoir",
+	"Total":        "Total",
+	"Payment":      "Paiement",
+	"Provider":     "Prestataire",
+	"Paid":         "Payée",
+
+	// order statuses
+	"Processing": "En préparation",
+	"Shipped":    "Expédiée",
+	"Delivered":  "Livrée",
+	"Cancelled":  "Annulée",
+
+	// support ticket statuses
+	"open":     "ouverte",
+	"answered": "répondue",
+	"closed":   "fermée",
+
+	// payment statuses
+	"requires_action":  "en attente d'action",
+	"processing":       "en cours",
+	"requires_capture": "autorisé",
+	"succeeded":        "réussi",
+	"canceled":         "annulé",
+	"failed":           "échoué",
+
+	// API responses
+	"account is scheduled for deletion, use the link in the email to restore it": "la suppression du compte est programmée, utilisez le lien de l'e-mail pour le restaurer",
+	"avatar moderation is temporarily unavailable, try again later":              "la modération des avatars est momentanément indisponible, réessayez plus tard",
+	"image storage is temporarily unavailable, try again later":                  "le stockage des images est momentanément indisponible, réessayez plus tard",
+	"internal server error, contact support with the error id":                   "erreur interne du serveur, contactez le support avec l'identifiant de l'erreur",
+	"sign-in link is invalid, has expired or was already used":                   "le lien de connexion est invalide, a expiré ou a déjà été utilisé",
+	"user must login as admin to perform this task":                              "connectez-vous en tant qu'administrateur pour effectuer cette action",
+	"you are not allowed to access this resource":                                "vous n'êtes pas autorisé à accéder à cette ressource",
+	"body must only have a single JSON value":                                    "le corps ne doit contenir qu'une seule valeur JSON",
+	"restore link is invalid or has expired":                                     "le lien de restauration est invalide ou a expiré",
+	"the shop is busy, try again shortly":                                        "la boutique est très sollicitée, réessayez dans quelques instants",
+	"invalid authentication credentials":                                         "identifiants d'authentification invalides",
+	"something went wrong, try again":                                            "une erreur est survenue, réessayez",
+	"this order is already paid":                                                 "cette commande est déjà payée",
+	"product is out of stock":                                                    "ce produit est en rupture de stock",
+	"user is not logged in":                                                      "l'utilisateur n'est pas connecté",
+	"user already exists":                                                        "cet utilisateur existe déjà",
+	"category not found":                                                         "catégorie introuvable",
+	"failed validation":                                                          "échec de la validation",
+	"Too many requests":                                                          "Trop de requêtes",
+	"product not found":                                                          "produit introuvable",
+	"order not found":                                                            "commande introuvable",
+	"ticket not found":                                                           "demande introuvable",
+	"user not found":                                                             "utilisateur introuvable",
+
+	// validation
+	"%s must be provided":                "%s doit être renseigné",
+	"%s must be a valid email address":   "%s doit être une adresse e-mail valide",
+	"%s must be a valid URL":             "%s doit être une URL valide",
+	"%s must be a valid id":              "%s doit être un identifiant valide",
+	"%s must be one of: %s":              "%s doit valoir l'une des valeurs : %s",
+	"%s must match %s":                   "%s doit correspondre à %s",
+	"%s must differ from %s":             "%s doit être différent de %s",
+	"%s must not have duplicates":        "%s ne doit pas contenir de doublons",
+	"%s must be at least %s characters":  "%s doit contenir au moins %s caractères",
+	"%s must be at most %s characters":   "%s doit contenir au plus %s caractères",
+	"%s must be exactly %s characters":   "%s doit contenir exactement %s caractères",
+	"%s must have at least 1 item":       "%s doit contenir au moins 1 élément",
+	"%s must have at least %s items":     "%s doit contenir au moins %s éléments",
+	"%s must have at most 1 item":        "%s doit contenir au plus 1 élément",
+	"%s must have at most %s items":      "%s doit contenir au plus %s éléments",
+	"%s must have exactly 1 item":        "%s doit contenir exactement 1 élément",
+	"%s must have exactly %s items":      "%s doit contenir exactement %s éléments",
+	"%s must not be negative":            "%s ne doit pas être négatif",
+	"%s must be at least %s":             "%s doit valoir au moins %s",
+	"%s must be at most %s":              "%s doit valoir au plus %s",
+	"%s must be exactly %s":              "%s doit valoir exactement %s",
+	"%s must be more than %s":            "%s doit être supérieur à %s",
+	"%s must be less than %s":            "%s doit être inférieur à %s",
+	"%s is invalid":                      "%s est invalide",
+	"%s must not be blank":               "%s ne doit pas être vide",
+	"%s must not be in the future":       "%s ne doit pas être dans le futur",
+	"%s must be an amount such as 49.99": "%s doit être un montant tel que 49,99",
+}
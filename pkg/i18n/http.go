@@ -0,0 +1,87 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type contextKey string
+
+const localeContextKey contextKey = "locale"
+
+// NewContext returns a copy of ctx carrying the locale code.
+func NewContext(ctx context.Context, code string) context.Context {
+	return context.WithValue(ctx, localeContextKey, code)
+}
+
+// FromContext returns the locale carried by ctx, DefaultLocale when it carries none.
+func FromContext(ctx context.Context) string {
+	if code, ok := ctx.Value(localeContextKey).(string); ok && code != "" {
+		return code
+	}
+
+	return DefaultLocale
+}
+
+// Negotiate returns the supported locale a client prefers by the Accept-Language header
+// it sent, such as "fr-CA,fr;q=0.9,en;q=0.8", and DefaultLocale when it accepts none of
+// them or sent no header.
+func Negotiate(header string) string {
+	type tag struct {
+		code string
+		q    float64
+	}
+
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		code, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if code == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		tags = append(tags, tag{code: code, q: q})
+	}
+
+	// the order of the header breaks ties between equal weights
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, t := range tags {
+		if t.code == "*" {
+			return DefaultLocale
+		}
+		if code := language(t.code); Supported(code) {
+			return code
+		}
+	}
+
+	return DefaultLocale
+}
+
+// Middleware serves each request in the locale its Accept-Language header prefers: the
+// locale is carried by the context of the request, for FromContext, and announced in
+// the Content-Language header of the response.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := Negotiate(r.Header.Get("Accept-Language"))
+
+		w.Header().Set("Content-Language", code)
+		w.Header().Add("Vary", "Accept-Language")
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), code)))
+	})
+}
@@ -0,0 +1,330 @@
+// Package i18n translates the texts the shop sends to customers, such as emails,
+// invoices and the messages of API responses, and formats amounts and dates the way
+// their locale writes them.
+//
+// Texts are written in English in the code and translated by catalogs keyed by the
+// English text, as gettext does: a text a catalog lacks stays in English. Users who
+// have not chosen a locale get DefaultLocale, set from the config at start. The
+// built-in catalogs can be extended or reworded with LoadCatalogs.
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// Locales the shop is translated to.
+const (
+	English = "en"
+	French  = "fr"
+	Spanish = "es"
+)
+
+// DefaultLocale is the locale of users who have not chosen one.
+var DefaultLocale = English
+
+// ErrUnsupportedLocale is returned when a locale has no catalog.
+var ErrUnsupportedLocale = errors.New("locale is not supported")
+
+// locale holds how a locale writes amounts and dates, and its translations.
+type locale struct {
+	decimal   string
+	group     string
+	shortDate string
+	longDate  func(t time.Time) string
+	messages  map[string]string
+	patterns  []pattern
+}
+
+var locales = map[string]*locale{
+	English: {
+		decimal:   ".",
+		group:     ",",
+		shortDate: "2006-01-02",
+		longDate:  func(t time.Time) string { return t.Format("January 2, 2006") },
+	},
+	French: {
+		decimal:   ",",
+		group:     "\u00a0", // no-break space
+		shortDate: "02/01/2006",
+		longDate: func(t time.Time) string {
+			return fmt.Sprintf("%d %s %d", t.Day(), frenchMonths[t.Month()-1], t.Year())
+		},
+		messages: french,
+	},
+	Spanish: {
+		decimal:   ",",
+		group:     ".",
+		shortDate: "02/01/2006",
+		longDate: func(t time.Time) string {
+			return fmt.Sprintf("%d de %s de %d", t.Day(), spanishMonths[t.Month()-1], t.Year())
+		},
+		messages: spanish,
+	},
+}
+
+func init() {
+	for _, l := range locales {
+		l.patterns = compile(l.messages)
+	}
+}
+
+var frenchMonths = [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août",
+	"septembre", "octobre", "novembre", "décembre"}
+
+var spanishMonths = [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto",
+	"septiembre", "octubre", "noviembre", "diciembre"}
+
+// SetDefault makes code, such as "fr", the locale of users who have not chosen one.
+func SetDefault(code string) error {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if !Supported(code) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedLocale, code)
+	}
+	DefaultLocale = code
+	return nil
+}
+
+// Supported reports whether the shop is translated to the locale code.
+func Supported(code string) bool {
+	_, ok := locales[code]
+	return ok
+}
+
+// Locales returns the codes of the supported locales, sorted.
+func Locales() []string {
+	codes := make([]string, 0, len(locales))
+	for c := range locales {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+// Match returns the supported locale of code, ignoring case and a region such as
+// "-CA" in "fr-CA", and DefaultLocale when code is empty or not supported.
+func Match(code string) string {
+	if code = language(code); Supported(code) {
+		return code
+	}
+
+	return DefaultLocale
+}
+
+// language returns the language of the locale code, lower case and without a region.
+func language(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+
+	return code
+}
+
+// T translates the English text msg to the locale code and formats it with args,
+// as fmt.Sprintf does when args are given. Texts without a translation stay in
+// English.
+func T(code, msg string, args ...interface{}) string {
+	if tr, ok := get(code).messages[msg]; ok {
+		msg = tr
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+
+	return msg
+}
+
+// Localize translates text, a message already formatted such as "quantity must be at
+// least 1", to the locale code. Texts translated as they are come first; otherwise the
+// catalog entries with verbs, such as "%s must be at least %s", are matched against
+// text and the values found are formatted into their translation. Texts matching no
+// entry stay as they are.
+func Localize(code, text string) string {
+	l := get(code)
+	if tr, ok := l.messages[text]; ok {
+		return tr
+	}
+
+	for _, p := range l.patterns {
+		if args, ok := p.match(text); ok {
+			return fmt.Sprintf(p.translation, args...)
+		}
+	}
+
+	return text
+}
+
+// LoadCatalogs adds the translations of the files named <locale>.json in fsys, such as
+// "fr.json", to the catalogs of their locale, replacing the built-in translations of
+// the same texts. Each file is a JSON object of English texts and their translation;
+// an en.json file rewords the English texts. Like SetDefault, it is called at start,
+// before the shop serves requests.
+func LoadCatalogs(fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		code := strings.TrimSuffix(path.Base(f), ".json")
+		l, ok := locales[code]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnsupportedLocale, f)
+		}
+
+		data, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return err
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("error reading catalog %s: %w", f, err)
+		}
+
+		if l.messages == nil {
+			l.messages = make(map[string]string, len(messages))
+		}
+		for msg, tr := range messages {
+			l.messages[msg] = tr
+		}
+		l.patterns = compile(l.messages)
+	}
+
+	return nil
+}
+
+// FormatMoney writes m with its currency the way the locale code does, such as
+// "1,234.50 USD" in English and "1 234,50 USD" in French.
+func FormatMoney(code string, m money.Money) string {
+	l := get(code)
+	amount, currency, _ := strings.Cut(m.String(), " ")
+
+	sign := ""
+	if strings.HasPrefix(amount, "-") {
+		sign, amount = "-", amount[1:]
+	}
+	units, minor, hasMinor := strings.Cut(amount, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			b.WriteString(l.group)
+		}
+		b.WriteRune(d)
+	}
+	if hasMinor {
+		b.WriteString(l.decimal)
+		b.WriteString(minor)
+	}
+
+	return b.String() + " " + currency
+}
+
+// FormatPercent writes rate, a percentage such as "7.5", the way the locale code
+// does, such as "7.5%" in English and "7,5%" in French.
+func FormatPercent(code, rate string) string {
+	return strings.Replace(rate, ".", get(code).decimal, 1) + "%"
+}
+
+// FormatDate writes the day of t in the short form of the locale code, such as
+// "2006-01-02" in English and "02/01/2006" in French.
+func FormatDate(code string, t time.Time) string {
+	return t.Format(get(code).shortDate)
+}
+
+// FormatLongDate writes the day of t in words the way the locale code does, such as
+// "January 2, 2006" in English and "2 janvier 2006" in French.
+func FormatLongDate(code string, t time.Time) string {
+	return get(code).longDate(t)
+}
+
+// get returns the locale of code, the default locale when code is not supported.
+func get(code string) *locale {
+	return locales[Match(code)]
+}
+
+// pattern is a catalog entry with verbs, matched against formatted texts.
+type pattern struct {
+	re          *regexp.Regexp
+	verbs       []byte
+	translation string
+}
+
+// match returns the values of the verbs of p in text, if text is a formatting of p.
+func (p pattern) match(text string) ([]interface{}, bool) {
+	m := p.re.FindStringSubmatch(text)
+	if m == nil {
+		return nil, false
+	}
+
+	args := make([]interface{}, len(p.verbs))
+	for i, verb := range p.verbs {
+		args[i] = m[i+1]
+		if verb == 'd' {
+			args[i], _ = strconv.Atoi(m[i+1])
+		}
+	}
+
+	return args, true
+}
+
+// compile returns the patterns of the entries of messages with %s, %v or %d verbs,
+// the longest first so the most specific entry of a text wins.
+func compile(messages map[string]string) []pattern {
+	var patterns []pattern
+	for msg, tr := range messages {
+		if !strings.Contains(msg, "%") {
+			continue
+		}
+
+		var (
+			expr  strings.Builder
+			verbs []byte
+		)
+		expr.WriteString("^")
+		for i := 0; i < len(msg); i++ {
+			if msg[i] != '%' || i+1 == len(msg) {
+				expr.WriteString(regexp.QuoteMeta(msg[i : i+1]))
+				continue
+			}
+			i++
+			switch msg[i] {
+			case 's', 'v':
+				expr.WriteString("(.+?)")
+				verbs = append(verbs, 's')
+			case 'd':
+				expr.WriteString("(-?[0-9]+)")
+				verbs = append(verbs, 'd')
+			default:
+				expr.WriteString(regexp.QuoteMeta(msg[i : i+1]))
+			}
+		}
+		expr.WriteString("$")
+
+		patterns = append(patterns, pattern{re: regexp.MustCompile(expr.String()), verbs: verbs, translation: tr})
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		a, b := patterns[i].re.String(), patterns[j].re.String()
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+
+	return patterns
+}
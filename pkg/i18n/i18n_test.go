@@ -0,0 +1,109 @@
+package i18n_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	assert.Equal(t, []string{i18n.English, i18n.Spanish, i18n.French}, i18n.Locales())
+	assert.Equal(t, i18n.French, i18n.Match("fr"))
+	assert.Equal(t, i18n.French, i18n.Match(" FR-ca "))
+	assert.Equal(t, i18n.French, i18n.Match("fr_BE"))
+	assert.Equal(t, i18n.DefaultLocale, i18n.Match("de"))
+	assert.Equal(t, i18n.DefaultLocale, i18n.Match(""))
+}
+
+func TestSetDefault(t *testing.T) {
+	t.Cleanup(func() { _ = i18n.SetDefault(i18n.English) })
+
+	assert.ErrorIs(t, i18n.SetDefault("de"), i18n.ErrUnsupportedLocale)
+	assert.NoError(t, i18n.SetDefault("FR"))
+	assert.Equal(t, "Facture", i18n.T("", "Invoice"))
+	assert.Equal(t, "Facture", i18n.T("de", "Invoice"))
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Invoice", i18n.T(i18n.English, "Invoice"))
+	assert.Equal(t, "Facture", i18n.T(i18n.French, "Invoice"))
+	assert.Equal(t, "3 heures", i18n.T(i18n.French, "%d hours", 3))
+	assert.Equal(t, "Not translated", i18n.T(i18n.French, "Not translated"))
+}
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "1,234,567.50 USD", i18n.FormatMoney(i18n.English, money.New(123456750, "USD")))
+	assert.Equal(t, "1\u00a0234\u00a0567,50 EUR", i18n.FormatMoney(i18n.French, money.New(123456750, "EUR")))
+	assert.Equal(t, "-5.00 USD", i18n.FormatMoney(i18n.English, money.New(-500, "USD")))
+	assert.Equal(t, "0,05 EUR", i18n.FormatMoney(i18n.French, money.New(5, "EUR")))
+	assert.Equal(t, "1.234.567,50 EUR", i18n.FormatMoney(i18n.Spanish, money.New(123456750, "EUR")))
+	assert.Equal(t, "12,000 JPY", i18n.FormatMoney(i18n.English, money.New(12000, "JPY")))
+}
+
+func TestFormatDate(t *testing.T) {
+	day := time.Date(2026, 8, 5, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "2026-08-05", i18n.FormatDate(i18n.English, day))
+	assert.Equal(t, "05/08/2026", i18n.FormatDate(i18n.French, day))
+	assert.Equal(t, "August 5, 2026", i18n.FormatLongDate(i18n.English, day))
+	assert.Equal(t, "5 août 2026", i18n.FormatLongDate(i18n.French, day))
+	assert.Equal(t, "5 de agosto de 2026", i18n.FormatLongDate(i18n.Spanish, day))
+}
+
+func TestLocalize(t *testing.T) {
+	assert.Equal(t, "utilisateur introuvable", i18n.Localize(i18n.French, "user not found"))
+	assert.Equal(t, "quantity doit valoir au moins 1", i18n.Localize(i18n.French, "quantity must be at least 1"))
+	assert.Equal(t, "name debe tener al menos 3 caracteres", i18n.Localize(i18n.Spanish, "name must be at least 3 characters"))
+	assert.Equal(t, "currency debe ser uno de: EUR, USD", i18n.Localize(i18n.Spanish, "currency must be one of: EUR, USD"))
+	assert.Equal(t, "3 horas", i18n.Localize(i18n.Spanish, "3 hours"))
+	assert.Equal(t, "user not found", i18n.Localize(i18n.English, "user not found"))
+	assert.Equal(t, "Not translated", i18n.Localize(i18n.French, "Not translated"))
+}
+
+func TestLoadCatalogs(t *testing.T) {
+	require.NoError(t, i18n.LoadCatalogs(fstest.MapFS{
+		"en.json": {Data: []byte(`{"%s must be provided": "please fill in %s"}`)},
+		"fr.json": {Data: []byte(`{"user not found": "aucun utilisateur"}`)},
+	}))
+
+	assert.Equal(t, "please fill in email", i18n.Localize(i18n.English, "email must be provided"))
+	assert.Equal(t, "aucun utilisateur", i18n.Localize(i18n.French, "user not found"))
+	assert.Equal(t, "commande introuvable", i18n.Localize(i18n.French, "order not found"))
+
+	err := i18n.LoadCatalogs(fstest.MapFS{"de.json": {Data: []byte(`{}`)}})
+	assert.ErrorIs(t, err, i18n.ErrUnsupportedLocale)
+	assert.Error(t, i18n.LoadCatalogs(fstest.MapFS{"fr.json": {Data: []byte(`[]`)}}))
+}
+
+func TestNegotiate(t *testing.T) {
+	assert.Equal(t, i18n.French, i18n.Negotiate("fr-CA,fr;q=0.9,en;q=0.8"))
+	assert.Equal(t, i18n.Spanish, i18n.Negotiate("de-DE, es;q=0.7, en;q=0.5"))
+	assert.Equal(t, i18n.English, i18n.Negotiate("es;q=0.5, en"))
+	assert.Equal(t, i18n.DefaultLocale, i18n.Negotiate("de, es;q=0"))
+	assert.Equal(t, i18n.DefaultLocale, i18n.Negotiate("*"))
+	assert.Equal(t, i18n.DefaultLocale, i18n.Negotiate(""))
+}
+
+func TestMiddleware(t *testing.T) {
+	var got string
+	handler := i18n.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = i18n.FromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "es-MX,es;q=0.9")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, i18n.Spanish, got)
+	assert.Equal(t, i18n.Spanish, w.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+	assert.Equal(t, i18n.DefaultLocale, i18n.FromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()))
+}
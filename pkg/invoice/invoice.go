@@ -0,0 +1,186 @@
+// Package invoice renders the invoices of orders as PDF and archives them to the
+// configured storage.
+//
+// An invoice lists the items of an order with their prices, the shipping, a line per
+// tax rate and per discount, the total charged, where the order ships and the status
+// of its payment. Amounts are the totals of the order as models.Order.Totals prices
+// them, in its currency. Invoices are rendered in the locale of whoever downloads
+// them, falling back to the shop default.
+package invoice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/pdf"
+)
+
+// ErrArchiveDisabled is returned when archiving an invoice without archiving enabled.
+var ErrArchiveDisabled = errors.New("invoice archiving is disabled")
+
+// Generator renders invoices and, when archiving is enabled, stores them.
+type Generator struct {
+	seller []string
+	store  cloudinary.CloudUploader
+}
+
+// New returns a Generator heading invoices with the seller of cfg. Invoices are
+// archived with store when cfg.Archive is set.
+func New(cfg config.Invoices, store cloudinary.CloudUploader) *Generator {
+	g := &Generator{}
+	for _, l := range strings.Split(cfg.Seller, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			g.seller = append(g.seller, l)
+		}
+	}
+	if cfg.Archive {
+		g.store = store
+	}
+
+	return g
+}
+
+// Filename returns the name the invoice of the order orderID is downloaded as.
+func Filename(orderID uuid.UUID) string {
+	return "invoice-" + orderID.String() + ".pdf"
+}
+
+// Render returns the invoice of o as PDF, in the locale code: its labels are
+// translated and its amounts and dates written the way the locale does. The order
+// must carry its items, shipping and payment.
+func (g *Generator) Render(o *models.Order, code string) ([]byte, error) {
+	t := func(msg string) string { return i18n.T(code, msg) }
+	amount := func(m money.Money) string { return i18n.FormatMoney(code, m) }
+
+	d := pdf.New(t("Invoice") + " " + o.OrderID.String())
+	for i, l := range g.seller {
+		if i == 0 {
+			d.Heading(l)
+			continue
+		}
+		d.Line(l)
+	}
+	if len(g.seller) > 0 {
+		d.Blank()
+	}
+
+	d.Heading(t("Invoice"))
+	fields(d, 7,
+		[2]string{t("Order"), o.OrderID.String()},
+		[2]string{t("Date"), i18n.FormatDate(code, o.CreatedAt)},
+		[2]string{t("Status"), t(o.OrderStatus)},
+	)
+	d.Blank()
+	d.Heading(t("Ship to"))
+	d.Line(o.ShippingInfo.Address)
+	d.Line(strings.TrimSpace(o.ShippingInfo.PostalCode + " " + o.ShippingInfo.City))
+	d.Line(o.ShippingInfo.Country)
+	d.Line(t("Phone") + ": " + o.ShippingInfo.PhoneNo)
+	d.Blank()
+	d.Line(fmt.Sprintf("%-5s %-40s %14s %14s", t("Qty"), t("Product"), t("Unit price"), t("Amount")))
+	d.Line(strings.Repeat("-", 76))
+	for _, i := range o.OrderItems {
+		d.Line(fmt.Sprintf("%-5d %-40s %14s %14s", i.Quantity, truncate(i.Name, 40), amount(i.Price), amount(i.Price.Mul(i.Quantity))))
+	}
+	d.Line(strings.Repeat("-", 76))
+	totals := o.Totals()
+	d.Line(fmt.Sprintf("%61s %14s", t("Items"), amount(totals.Subtotal)))
+	d.Line(fmt.Sprintf("%61s %14s", t("Shipping"), amount(totals.Shipping)))
+	for _, l := range totals.Taxes {
+		label := t("Tax")
+		if l.Rate != "" {
+			label = i18n.T(code, "Tax (%s)", i18n.FormatPercent(code, l.Rate))
+		}
+		d.Line(fmt.Sprintf("%61s %14s", label, amount(l.Amount)))
+	}
+	for _, l := range totals.Discounts {
+		label := t("Discount")
+		switch {
+		case l.Kind == models.DiscountStoreCredit:
+			label = t("Store credit")
+		case l.Code != "":
+			label = i18n.T(code, "Discount %s", l.Code)
+		}
+		d.Line(fmt.Sprintf("%61s %14s", label, amount(l.Amount.Neg())))
+	}
+	d.Line(fmt.Sprintf("%61s %14s", t("Total"), amount(totals.GrandTotal)))
+	d.Blank()
+	d.Heading(t("Payment"))
+	payment := [][2]string{
+		{t("Provider"), provider(o.PaymentInfo)},
+		{t("Status"), t(o.PaymentInfo.Status)},
+	}
+	if !o.PaidAt.IsZero() {
+		payment = append(payment, [2]string{t("Paid"), i18n.FormatDate(code, o.PaidAt)})
+	}
+	fields(d, 8, payment...)
+
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("error writing invoice: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Archives reports whether invoices are archived.
+func (g *Generator) Archives() bool {
+	return g.store != nil
+}
+
+// Archive uploads doc, the invoice of the order orderID, to the invoices folder of
+// the storage.
+func (g *Generator) Archive(ctx context.Context, orderID uuid.UUID, doc []byte) (*models.Invoice, error) {
+	if g.store == nil {
+		return nil, ErrArchiveDisabled
+	}
+
+	res, err := g.store.UploadToCloud(ctx, cloudinary.FolderInvoices, doc)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading invoice: %v", err)
+	}
+
+	return &models.Invoice{OrderID: orderID, URL: res.URL, PublicID: res.PublicID}, nil
+}
+
+// fields writes a line "label: value" for each label and value, with the values
+// aligned as if the labels were at least width characters long.
+func fields(d *pdf.Document, width int, lines ...[2]string) {
+	for _, l := range lines {
+		if n := utf8.RuneCountInString(l[0]); n > width {
+			width = n
+		}
+	}
+
+	for _, l := range lines {
+		d.Line(l[0] + ":" + strings.Repeat(" ", width-utf8.RuneCountInString(l[0])+1) + l[1])
+	}
+}
+
+// provider returns the provider p was made with; payments recorded without one
+// were made with Stripe.
+func provider(p models.Payment) string {
+	if p.Provider == "" {
+		return models.PaymentStripe
+	}
+	return p.Provider
+}
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,144 @@
+package invoice_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/api/uploader"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	mockCloudinary "github.com/jofosuware/go/shopit/pkg/cloudinary/mocks"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	"github.com/jofosuware/go/shopit/pkg/invoice"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	order := &models.Order{
+		OrderID:     uuid.New(),
+		OrderStatus: models.OrderProcessing,
+		ShippingInfo: models.Shipping{
+			Address: "1 Market Street", City: "Accra", PostalCode: "GA-100", Country: "Ghana", PhoneNo: "0200000000",
+		},
+		OrderItems:    []*models.Item{{Name: "Camera", Price: money.Of(12500), Quantity: 2}},
+		PaymentInfo:   models.Payment{ID: "pi_1", Status: models.PaymentSucceeded},
+		ItemPrice:     money.Of(25000),
+		ShippingPrice: money.Of(1000),
+		TaxPrice:      money.Of(2500),
+		Discount:      money.Of(500),
+		TotalPrice:    money.Of(28000),
+		PaidAt:        time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		CreatedAt:     time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("Invoice lists the order", func(t *testing.T) {
+		doc, err := invoice.New(config.Invoices{Seller: "Shopit Ltd\n  1 Market Street, Accra\n"}, nil).Render(order, i18n.English)
+		require.NoError(t, err)
+
+		out := string(doc)
+		assert.True(t, bytes.HasPrefix(doc, []byte("%PDF-")))
+		assert.Contains(t, out, "(Shopit Ltd) Tj")
+		assert.Contains(t, out, "(1 Market Street, Accra) Tj")
+		assert.Contains(t, out, "Order:   "+order.OrderID.String())
+		assert.Contains(t, out, "GA-100 Accra")
+		assert.Contains(t, out, "Camera")
+		assert.Contains(t, out, "125.00 USD")
+		assert.Contains(t, out, "250.00 USD")
+		assert.Contains(t, out, "Tax \\(10%\\)")
+		assert.Contains(t, out, "-5.00 USD")
+		assert.Contains(t, out, "280.00 USD")
+		assert.NotContains(t, out, "Store credit")
+		assert.Contains(t, out, "Provider: stripe")
+		assert.Contains(t, out, "Status:   succeeded")
+		assert.Contains(t, out, "Paid:     2026-10-16")
+	})
+
+	t.Run("Unpaid order without a discount", func(t *testing.T) {
+		unpaid := *order
+		unpaid.Discount = money.Money{}
+		unpaid.PaidAt = time.Time{}
+		unpaid.PaymentInfo = models.Payment{ID: "ORDER-1", Provider: models.PaymentPayPal, Status: models.PaymentRequiresAction}
+
+		doc, err := invoice.New(config.Invoices{}, nil).Render(&unpaid, "")
+		require.NoError(t, err)
+
+		out := string(doc)
+		assert.NotContains(t, out, "Discount")
+		assert.NotContains(t, out, "Paid:")
+		assert.Contains(t, out, "Provider: paypal")
+	})
+
+	t.Run("Store credit and coupon are listed", func(t *testing.T) {
+		credited := *order
+		credited.CouponCode = "SAVE5"
+		credited.TotalPrice = money.Of(25000)
+
+		doc, err := invoice.New(config.Invoices{}, nil).Render(&credited, i18n.English)
+		require.NoError(t, err)
+
+		out := string(doc)
+		assert.Contains(t, out, "Discount SAVE5")
+		assert.Contains(t, out, "Store credit")
+		assert.Contains(t, out, "-30.00 USD")
+		assert.Contains(t, out, "250.00 USD")
+	})
+
+	t.Run("Invoice in French", func(t *testing.T) {
+		doc, err := invoice.New(config.Invoices{}, nil).Render(order, i18n.French)
+		require.NoError(t, err)
+
+		out := string(doc)
+		assert.Contains(t, out, "(Facture) Tj")
+		assert.Contains(t, out, "Commande: "+order.OrderID.String())
+		assert.Contains(t, out, "Date:     15/10/2026")
+		assert.Contains(t, out, "Statut:   En pr\\351paration")
+		assert.Contains(t, out, "280,00 USD")
+		assert.Contains(t, out, "-5,00 USD")
+		assert.Contains(t, out, "Taxes \\(10%\\)")
+		assert.Contains(t, out, "Prestataire: stripe")
+		assert.Contains(t, out, "Statut:      r\\351ussi")
+		assert.Contains(t, out, "Pay\\351e:       16/10/2026")
+	})
+}
+
+func TestArchive(t *testing.T) {
+	orderID := uuid.New()
+	doc := []byte("%PDF-1.4")
+
+	t.Run("Invoice is uploaded", func(t *testing.T) {
+		store := mockCloudinary.NewCloudUploader(t)
+		store.On("UploadToCloud", mock.Anything, cloudinary.FolderInvoices, doc).
+			Return(&uploader.UploadResult{PublicID: "invoices/1.pdf", URL: "https://cdn.test/invoices/1.pdf"}, nil).Once()
+
+		g := invoice.New(config.Invoices{Archive: true}, store)
+		require.True(t, g.Archives())
+
+		inv, err := g.Archive(context.Background(), orderID, doc)
+		require.NoError(t, err)
+		assert.Equal(t, &models.Invoice{OrderID: orderID, URL: "https://cdn.test/invoices/1.pdf", PublicID: "invoices/1.pdf"}, inv)
+	})
+
+	t.Run("Upload fails", func(t *testing.T) {
+		store := mockCloudinary.NewCloudUploader(t)
+		store.On("UploadToCloud", mock.Anything, cloudinary.FolderInvoices, doc).Return(&uploader.UploadResult{}, errors.New("storage down")).Once()
+
+		_, err := invoice.New(config.Invoices{Archive: true}, store).Archive(context.Background(), orderID, doc)
+		assert.Error(t, err)
+	})
+
+	t.Run("Archiving disabled", func(t *testing.T) {
+		g := invoice.New(config.Invoices{}, mockCloudinary.NewCloudUploader(t))
+		assert.False(t, g.Archives())
+
+		_, err := g.Archive(context.Background(), orderID, doc)
+		assert.ErrorIs(t, err, invoice.ErrArchiveDisabled)
+	})
+}
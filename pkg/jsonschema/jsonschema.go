@@ -0,0 +1,179 @@
+// Package jsonschema generates JSON Schemas (draft 2020-12) of Go types from their
+// fields and json tags, so the JSON the shop sends is documented by the code that
+// encodes it.
+//
+// A struct is an object of its exported fields, named by their json tag; fields
+// tagged "-" are left out and those without omitempty are required. Pointers, slices
+// and maps may also be null, since that is how encoding/json writes them when nil.
+// Types with their own JSON encoding, such as those with a MarshalJSON method, are
+// described with Define.
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Draft is the JSON Schema version of the schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema. Type is a type name, or a list of them when a value may
+// be of several types, such as ["string", "null"].
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Reflector generates the schemas of Go types.
+type Reflector struct {
+	defined map[reflect.Type]*Schema
+}
+
+// New returns a Reflector knowing the JSON encoding of time.Time, uuid.UUID,
+// uuid.NullUUID and json.RawMessage.
+func New() *Reflector {
+	r := &Reflector{defined: make(map[reflect.Type]*Schema)}
+	r.Define(time.Time{}, &Schema{Type: "string", Format: "date-time"})
+	r.Define(uuid.UUID{}, &Schema{Type: "string", Format: "uuid"})
+	r.Define(uuid.NullUUID{}, &Schema{Type: []string{"string", "null"}, Format: "uuid"})
+	r.Define(json.RawMessage{}, &Schema{})
+
+	return r
+}
+
+// Define sets the schema of the type of v, for types whose JSON encoding is not that
+// of their fields.
+func (r *Reflector) Define(v interface{}, s *Schema) {
+	r.defined[reflect.TypeOf(v)] = s
+}
+
+// Reflect returns the schema of the type of v.
+func (r *Reflector) Reflect(v interface{}) *Schema {
+	s := r.reflect(reflect.TypeOf(v), map[reflect.Type]bool{})
+	s.Schema = Draft
+
+	return s
+}
+
+// reflect returns the schema of t. Types on the stack of the types being reflected
+// are recursive; they are left open rather than reflected forever.
+func (r *Reflector) reflect(t reflect.Type, stack map[reflect.Type]bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	if s, ok := r.defined[t]; ok {
+		copied := *s
+		return &copied
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(r.reflect(t.Elem(), stack))
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: []string{"string", "null"}, ContentEncoding: "base64"}
+		}
+		return nullable(&Schema{Type: "array", Items: r.reflect(t.Elem(), stack)})
+	case reflect.Array:
+		return &Schema{Type: "array", Items: r.reflect(t.Elem(), stack)}
+	case reflect.Map:
+		return nullable(&Schema{Type: "object", AdditionalProperties: r.reflect(t.Elem(), stack)})
+	case reflect.Struct:
+		if stack[t] {
+			return &Schema{Type: "object"}
+		}
+		stack[t] = true
+		defer delete(stack, t)
+
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		r.fields(t, s, stack)
+		return s
+	default:
+		// interfaces hold any value
+		return &Schema{}
+	}
+}
+
+// fields adds the fields of the struct t to s, those of embedded structs included.
+func (r *Reflector) fields(t reflect.Type, s *Schema, stack map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				r.fields(ft, s, stack)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = r.reflect(f.Type, stack)
+		if !hasOption(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// hasOption reports whether the options of a json tag include option.
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// nullable returns s, allowing null too.
+func nullable(s *Schema) *Schema {
+	switch t := s.Type.(type) {
+	case string:
+		s.Type = []string{t, "null"}
+	case []string:
+		for _, name := range t {
+			if name == "null" {
+				return s
+			}
+		}
+		s.Type = append(t, "null")
+	}
+
+	return s
+}
@@ -0,0 +1,112 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/jsonschema"
+	"github.com/stretchr/testify/assert"
+)
+
+type base struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time
+}
+
+type line struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+type document struct {
+	base
+	Title    string            `json:"title"`
+	Note     string            `json:"note,omitempty"`
+	Secret   string            `json:"-"`
+	Price    float64           `json:"price"`
+	Paid     bool              `json:"paid"`
+	Parent   *document         `json:"parent"`
+	Lines    []line            `json:"lines"`
+	Labels   map[string]string `json:"labels"`
+	Category uuid.NullUUID     `json:"category"`
+	Raw      []byte            `json:"raw"`
+	Extra    interface{}       `json:"extra"`
+	internal string
+}
+
+func TestReflect(t *testing.T) {
+	s := jsonschema.New().Reflect(document{})
+
+	assert.Equal(t, jsonschema.Draft, s.Schema)
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, []string{"id", "CreatedAt", "title", "price", "paid", "parent", "lines", "labels", "category", "raw", "extra"}, s.Required)
+
+	assert.NotContains(t, s.Properties, "Secret")
+	assert.NotContains(t, s.Properties, "internal")
+	assert.Contains(t, s.Properties, "note")
+
+	assert.Equal(t, &jsonschema.Schema{Type: "string", Format: "uuid"}, s.Properties["id"])
+	assert.Equal(t, &jsonschema.Schema{Type: "string", Format: "date-time"}, s.Properties["CreatedAt"])
+	assert.Equal(t, &jsonschema.Schema{Type: "number"}, s.Properties["price"])
+	assert.Equal(t, &jsonschema.Schema{Type: "boolean"}, s.Properties["paid"])
+	assert.Equal(t, &jsonschema.Schema{Type: []string{"string", "null"}, Format: "uuid"}, s.Properties["category"])
+	assert.Equal(t, &jsonschema.Schema{Type: []string{"string", "null"}, ContentEncoding: "base64"}, s.Properties["raw"])
+	assert.Equal(t, &jsonschema.Schema{}, s.Properties["extra"])
+
+	lines := s.Properties["lines"]
+	assert.Equal(t, []string{"array", "null"}, lines.Type)
+	assert.Equal(t, &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"name":     {Type: "string"},
+			"quantity": {Type: "integer"},
+		},
+		Required: []string{"name", "quantity"},
+	}, lines.Items)
+
+	assert.Equal(t, &jsonschema.Schema{
+		Type:                 []string{"object", "null"},
+		AdditionalProperties: &jsonschema.Schema{Type: "string"},
+	}, s.Properties["labels"])
+
+	// the recursive parent is left open
+	assert.Equal(t, &jsonschema.Schema{Type: []string{"object", "null"}}, s.Properties["parent"])
+}
+
+func TestDefine(t *testing.T) {
+	type amount struct {
+		Cents int64
+	}
+	type invoice struct {
+		Total amount  `json:"total"`
+		Due   *amount `json:"due"`
+	}
+
+	r := jsonschema.New()
+	r.Define(amount{}, &jsonschema.Schema{Type: "string", Description: "a decimal"})
+
+	s := r.Reflect(invoice{})
+
+	assert.Equal(t, &jsonschema.Schema{Type: "string", Description: "a decimal"}, s.Properties["total"])
+	assert.Equal(t, &jsonschema.Schema{Type: []string{"string", "null"}, Description: "a decimal"}, s.Properties["due"])
+
+	// the nullable copy leaves the definition alone
+	assert.Equal(t, "string", r.Reflect(invoice{}).Properties["total"].Type)
+}
+
+func TestSchemaJSON(t *testing.T) {
+	b, err := json.Marshal(jsonschema.New().Reflect(line{}))
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"quantity": {"type": "integer"}
+		},
+		"required": ["name", "quantity"]
+	}`, string(b))
+}
@@ -0,0 +1,166 @@
+// Package loadshed turns away low priority requests while the API is overloaded, so
+// searches and analytics give way before checkouts and orders degrade.
+//
+// Middleware counts the requests being served. Routes wrapped with LowPriority are
+// answered with 503 and a Retry-After header while MaxInFlight other requests are
+// being served or the database pool has DBSaturation percent of its connections in use;
+// other routes are always served. Server-sent event streams are not counted, as they
+// stay open without doing work.
+package loadshed
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/pkg/utils"
+)
+
+// ErrOverloaded is answered to the low priority requests turned away.
+var ErrOverloaded = errors.New("the shop is busy, try again shortly")
+
+type contextKey string
+
+const shedderContextKey contextKey = "loadshed"
+
+// Stats is a point-in-time view of the load, for admins. Overloaded is set when a low
+// priority request would be shed.
+type Stats struct {
+	InFlight     int  `json:"inFlight"`
+	PeakInFlight int  `json:"peakInFlight"`
+	Shed         int  `json:"shed"`
+	Overloaded   bool `json:"overloaded"`
+	MaxInFlight  int  `json:"maxInFlight"`
+	DBInUse      int  `json:"dbInUse"`
+	DBMaxOpen    int  `json:"dbMaxOpen"`
+	// DBWaitCount is how many times a query has waited for a free connection
+	DBWaitCount  int64 `json:"dbWaitCount"`
+	DBSaturation int   `json:"dbSaturation"`
+}
+
+// Shedder tracks the load of the API and sheds low priority requests.
+type Shedder struct {
+	maxInFlight  int
+	dbSaturation int
+	dbStats      func() sql.DBStats
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	shed     int
+}
+
+// New returns a Shedder with the thresholds of cfg, reading the use of the database
+// pool with dbStats, such as (*sql.DB).Stats. A nil dbStats leaves the pool out.
+func New(cfg config.LoadShedding, dbStats func() sql.DBStats) *Shedder {
+	return &Shedder{
+		maxInFlight:  cfg.MaxInFlight,
+		dbSaturation: cfg.DBSaturation,
+		dbStats:      dbStats,
+	}
+}
+
+// Middleware counts the requests in flight and makes the shedder available to the
+// routes wrapped with LowPriority.
+func (s *Shedder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			s.begin()
+			defer s.end()
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), shedderContextKey, s)))
+	})
+}
+
+// LowPriority answers the requests of the wrapped route with 503 while the API is
+// overloaded. Routes served without a Shedder are never shed.
+func LowPriority(next http.Handler) http.Handler {
+	return LowPriorityWhen(func(*http.Request) bool { return true })(next)
+}
+
+// LowPriorityWhen is LowPriority for the requests of the wrapped route match reports,
+// such as the keyword searches of a listing.
+func LowPriorityWhen(match func(r *http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s, ok := r.Context().Value(shedderContextKey).(*Shedder)
+			if ok && match(r) && s.shedding() {
+				_ = utils.ServiceUnavailable(w, r, ErrOverloaded)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Stats returns the current load and the thresholds it is shed at.
+func (s *Shedder) Stats() Stats {
+	s.mu.Lock()
+	st := Stats{
+		InFlight:     s.inFlight,
+		PeakInFlight: s.peak,
+		Shed:         s.shed,
+		MaxInFlight:  s.maxInFlight,
+		DBSaturation: s.dbSaturation,
+	}
+	s.mu.Unlock()
+
+	if s.dbStats != nil {
+		db := s.dbStats()
+		st.DBInUse = db.InUse
+		st.DBMaxOpen = db.MaxOpenConnections
+		st.DBWaitCount = db.WaitCount
+	}
+	st.Overloaded = s.overloaded(st)
+
+	return st
+}
+
+// shedding reports whether the API is overloaded, counting the request as shed when
+// it is.
+func (s *Shedder) shedding() bool {
+	st := s.Stats()
+	// the request asking is in flight itself
+	st.InFlight--
+	if !s.overloaded(st) {
+		return false
+	}
+
+	s.mu.Lock()
+	s.shed++
+	s.mu.Unlock()
+
+	return true
+}
+
+// overloaded reports whether st is at a threshold.
+func (s *Shedder) overloaded(st Stats) bool {
+	if s.maxInFlight > 0 && st.InFlight >= s.maxInFlight {
+		return true
+	}
+
+	return s.dbSaturation > 0 && st.DBMaxOpen > 0 && st.DBInUse*100 >= st.DBMaxOpen*s.dbSaturation
+}
+
+func (s *Shedder) begin() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight++
+	if s.inFlight > s.peak {
+		s.peak = s.inFlight
+	}
+}
+
+func (s *Shedder) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight--
+}
@@ -0,0 +1,140 @@
+package loadshed_test
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/pkg/loadshed"
+	"github.com/stretchr/testify/assert"
+)
+
+// serve sends a request to path through the shedder, with the route wrapped in
+// LowPriority when low is set, and returns the status answered.
+func serve(s *loadshed.Shedder, low bool, path string) int {
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	if low {
+		h = loadshed.LowPriority(h)
+	}
+
+	rr := httptest.NewRecorder()
+	s.Middleware(h).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return rr.Code
+}
+
+// hold starts n requests through s that stay in flight until the returned function
+// is called.
+func hold(s *loadshed.Shedder, n int) func() {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	for i := 0; i < n; i++ {
+		go func() {
+			s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				started <- struct{}{}
+				<-release
+			})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+		<-started
+	}
+
+	return func() { close(release) }
+}
+
+func TestShedder(t *testing.T) {
+	t.Run("Low priority requests are served under the thresholds", func(t *testing.T) {
+		s := loadshed.New(config.LoadShedding{MaxInFlight: 2}, nil)
+		release := hold(s, 1)
+		defer release()
+
+		assert.Equal(t, http.StatusOK, serve(s, true, "/search"))
+		assert.Equal(t, 0, s.Stats().Shed)
+	})
+
+	t.Run("Low priority requests are shed over MaxInFlight", func(t *testing.T) {
+		s := loadshed.New(config.LoadShedding{MaxInFlight: 2}, nil)
+		release := hold(s, 2)
+
+		assert.Equal(t, http.StatusServiceUnavailable, serve(s, true, "/search"))
+		assert.Equal(t, http.StatusOK, serve(s, false, "/orders/new"))
+
+		stats := s.Stats()
+		assert.Equal(t, 1, stats.Shed)
+		assert.Equal(t, 2, stats.InFlight)
+		assert.Equal(t, 3, stats.PeakInFlight)
+		assert.True(t, stats.Overloaded)
+
+		release()
+		assert.Eventually(t, func() bool { return s.Stats().InFlight == 0 }, time.Second, time.Millisecond)
+		assert.Equal(t, http.StatusOK, serve(s, true, "/search"))
+	})
+
+	t.Run("Low priority requests are shed when the database pool is saturated", func(t *testing.T) {
+		db := sql.DBStats{MaxOpenConnections: 10, InUse: 9, WaitCount: 4}
+		s := loadshed.New(config.LoadShedding{DBSaturation: 90}, func() sql.DBStats { return db })
+
+		assert.Equal(t, http.StatusServiceUnavailable, serve(s, true, "/search"))
+		assert.Equal(t, http.StatusOK, serve(s, false, "/orders/new"))
+
+		stats := s.Stats()
+		assert.Equal(t, 9, stats.DBInUse)
+		assert.Equal(t, 10, stats.DBMaxOpen)
+		assert.Equal(t, int64(4), stats.DBWaitCount)
+
+		db.InUse = 8
+		assert.Equal(t, http.StatusOK, serve(s, true, "/search"))
+	})
+
+	t.Run("Thresholds of 0 are disabled", func(t *testing.T) {
+		db := sql.DBStats{MaxOpenConnections: 10, InUse: 10}
+		s := loadshed.New(config.LoadShedding{}, func() sql.DBStats { return db })
+		release := hold(s, 5)
+		defer release()
+
+		assert.Equal(t, http.StatusOK, serve(s, true, "/search"))
+	})
+
+	t.Run("Event streams are not counted", func(t *testing.T) {
+		s := loadshed.New(config.LoadShedding{MaxInFlight: 1}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/orders/1/events", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, 0, s.Stats().InFlight)
+		})).ServeHTTP(httptest.NewRecorder(), req)
+	})
+
+	t.Run("Routes served without a shedder are not shed", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		loadshed.LowPriority(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/search", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestLowPriorityWhen(t *testing.T) {
+	s := loadshed.New(config.LoadShedding{MaxInFlight: 1}, nil)
+	release := hold(s, 1)
+	defer release()
+
+	search := func(r *http.Request) bool { return r.URL.Query().Get("keyword") != "" }
+	h := s.Middleware(loadshed.LowPriorityWhen(search)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/products?keyword=lens", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/products", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
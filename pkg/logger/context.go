@@ -0,0 +1,30 @@
+package logger
+
+import "context"
+
+// fieldsKey is the context key of the logger fields of a request.
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying the key-value pairs args, such as
+// "request_id", id, on top of the fields ctx already carries.
+func WithFields(ctx context.Context, args ...interface{}) context.Context {
+	fields, _ := ctx.Value(fieldsKey{}).([]interface{})
+
+	// The fields of ctx are copied so that its other children keep their own
+	merged := make([]interface{}, 0, len(fields)+len(args))
+	merged = append(merged, fields...)
+	merged = append(merged, args...)
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FromContext returns a child of l adding the fields carried by ctx, such as the
+// request_id and user_id of a request, or l itself when ctx carries none.
+func FromContext(ctx context.Context, l Logger) Logger {
+	fields, _ := ctx.Value(fieldsKey{}).([]interface{})
+	if len(fields) == 0 {
+		return l
+	}
+
+	return l.With(fields...)
+}
@@ -0,0 +1,28 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	l := mockLogger.NewLogger(t)
+
+	t.Run("Context without fields", func(t *testing.T) {
+		assert.Same(t, l, logger.FromContext(context.Background(), l))
+	})
+
+	t.Run("Fields are added to those of the parent", func(t *testing.T) {
+		child := mockLogger.NewLogger(t)
+		ctx := logger.WithFields(context.Background(), "request_id", "req-1")
+		_ = logger.WithFields(ctx, "user_id", "u-2")
+		ctx = logger.WithFields(ctx, "user_id", "u-1")
+
+		l.On("With", "request_id", "req-1", "user_id", "u-1").Return(child).Once()
+		assert.Same(t, child, logger.FromContext(ctx, l))
+	})
+}
@@ -2,7 +2,10 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	logger "github.com/jofosuware/go/shopit/pkg/logger"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Logger is an autogenerated mock type for the Logger type
 type Logger struct {
@@ -89,6 +92,44 @@ func (_m *Logger) InitLogger() {
 	_m.Called()
 }
 
+// Sampled provides a mock function with given fields:
+func (_m *Logger) Sampled() logger.Logger {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sampled")
+	}
+
+	var r0 logger.Logger
+	if rf, ok := ret.Get(0).(func() logger.Logger); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(logger.Logger)
+		}
+	}
+
+	return r0
+}
+
+// Sync provides a mock function with given fields:
+func (_m *Logger) Sync() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sync")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Warn provides a mock function with given fields: args
 func (_m *Logger) Warn(args ...interface{}) {
 	var _ca []interface{}
@@ -104,6 +145,28 @@ func (_m *Logger) Warnf(template string, args ...interface{}) {
 	_m.Called(_ca...)
 }
 
+// With provides a mock function with given fields: args
+func (_m *Logger) With(args ...interface{}) logger.Logger {
+	var _ca []interface{}
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for With")
+	}
+
+	var r0 logger.Logger
+	if rf, ok := ret.Get(0).(func(...interface{}) logger.Logger); ok {
+		r0 = rf(args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(logger.Logger)
+		}
+	}
+
+	return r0
+}
+
 // NewLogger creates a new instance of Logger. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewLogger(t interface {
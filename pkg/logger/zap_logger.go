@@ -1,7 +1,11 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -24,12 +28,23 @@ type Logger interface {
 	DPanicf(template string, args ...interface{})
 	Fatal(args ...interface{})
 	Fatalf(template string, args ...interface{})
+	// With returns a child logger adding the key-value pairs args, such as
+	// "module", "orders", to every message.
+	With(args ...interface{}) Logger
+	// Sampled returns a child logger for high-volume paths, writing only a sample of
+	// the messages it repeats. The loggers derived from it share its sampling.
+	Sampled() Logger
+	// Sync flushes the messages buffered by the logger.
+	Sync() error
 }
 
 // ApiLogger is the type struct for ApiLogger
 type ApiLogger struct {
 	cfg         *config.Config
 	sugarLogger *zap.SugaredLogger
+	level       zap.AtomicLevel
+	// sample wraps a core in the sampler of Sampled, nil when sampling is off
+	sample func(zapcore.Core) zapcore.Core
 }
 
 // NewApiLogger is constructor for ApiLogger
@@ -63,8 +78,10 @@ func (l *ApiLogger) InitLogger() {
 
 	logWriter := zapcore.AddSync(os.Stderr)
 
+	development := l.cfg.Logger.Development || l.cfg.Server.Mode == "Development"
+
 	var encoderCfg zapcore.EncoderConfig
-	if l.cfg.Server.Mode == "Development" {
+	if development {
 		encoderCfg = zap.NewDevelopmentEncoderConfig()
 	} else {
 		encoderCfg = zap.NewProductionEncoderConfig()
@@ -76,21 +93,84 @@ func (l *ApiLogger) InitLogger() {
 	encoderCfg.TimeKey = "TIME"
 	encoderCfg.NameKey = "NAME"
 	encoderCfg.MessageKey = "MESSAGE"
+	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
 
-	if l.cfg.Logger.Encoding == "console" {
+	// JSON in production and console lines in development, unless the config says
+	switch encoding := l.cfg.Logger.Encoding; {
+	case encoding == "console", encoding == "" && development:
 		encoder = zapcore.NewConsoleEncoder(encoderCfg)
-	} else {
+	default:
 		encoder = zapcore.NewJSONEncoder(encoderCfg)
 	}
 
-	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
-	core := zapcore.NewCore(encoder, logWriter, zap.NewAtomicLevelAt(logLevel))
-	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	l.level = zap.NewAtomicLevelAt(logLevel)
+	core := zapcore.NewCore(encoder, logWriter, l.level)
+
+	opts := []zap.Option{zap.AddCallerSkip(1)}
+	if !l.cfg.Logger.DisableCaller {
+		opts = append(opts, zap.AddCaller())
+	}
+	if !l.cfg.Logger.DisableStacktrace {
+		opts = append(opts, zap.AddStacktrace(zapcore.DPanicLevel))
+	}
+	if development {
+		opts = append(opts, zap.Development())
+	}
+	logger := zap.New(core, opts...)
+
+	if sampling := l.cfg.Logger.Sampling; sampling.Initial > 0 {
+		l.sample = func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewSamplerWithOptions(c, time.Second, sampling.Initial, sampling.Thereafter)
+		}
+	}
 
 	l.sugarLogger = logger.Sugar()
-	if err := l.sugarLogger.Sync(); err != nil {
-		l.sugarLogger.Error(err)
+}
+
+// SetLevel changes the level of the logger while it runs, to one of the levels of
+// the logger config.
+func (l *ApiLogger) SetLevel(level string) error {
+	lvl, ok := loggerLevelMap[level]
+	if !ok {
+		return fmt.Errorf("unknown log level %q", level)
 	}
+	l.level.SetLevel(lvl)
+
+	return nil
+}
+
+// With returns a child logger adding the key-value pairs args to every message.
+func (l *ApiLogger) With(args ...interface{}) Logger {
+	child := *l
+	child.sugarLogger = l.sugarLogger.With(args...)
+
+	return &child
+}
+
+// Sampled returns a child logger writing, of the messages it repeats within a second,
+// the first logger.sampling.initial and then every logger.sampling.thereafter-th.
+// Without sampling, it is the logger itself.
+func (l *ApiLogger) Sampled() Logger {
+	if l.sample == nil {
+		return l
+	}
+
+	child := *l
+	child.sugarLogger = l.sugarLogger.WithOptions(zap.WrapCore(l.sample))
+	child.sample = nil
+
+	return &child
+}
+
+// Sync flushes the buffered messages. Terminals and pipes, which cannot be synced,
+// are not an error.
+func (l *ApiLogger) Sync() error {
+	err := l.sugarLogger.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) {
+		return nil
+	}
+
+	return err
 }
 
 func (l *ApiLogger) Debug(args ...interface{}) {
@@ -1,21 +1,45 @@
+// Package mailer renders the shop emails from their templates and sends them over
+// SMTP.
+//
+// Every template has an HTML and a plain text body in templates/, in English. A
+// locale translates a template with its own files in templates/<locale>/; templates
+// it does not translate are sent in English. Templates can translate English texts
+// with {{t "text"}} and write an amount such as "250.00 USD" the way their locale
+// does with {{money .Total}}. {{range lines .Items}} ranges over the lines of a
+// newline separated list.
 package mailer
 
 import (
 	"bytes"
 	"embed"
+	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	"github.com/jofosuware/go/shopit/pkg/money"
 	mail "github.com/xhit/go-simple-mail/v2"
 )
 
 //go:embed "templates"
 var emailTemplateFS embed.FS
 
+// DefaultFrom is the sender of the shop emails.
+const DefaultFrom = "DePeridot <postmaster@sandboxa7a6fd0db7744e4f8917325ae3ce1a04.mailgun.org>"
+
+// ErrUnknownTemplate is returned when rendering a template that does not exist.
+var ErrUnknownTemplate = errors.New("unknown email template")
+
 type Mailer interface {
-	SendMail(from, to, subject, tmpl string, data interface{}) error
+	// SendMail renders the template tmpl with data in the locale code, falling back to the shop
+	// default, and emails it to to
+	SendMail(from, to, subject, tmpl, code string, data interface{}) error
 }
 
 type Mail struct {
@@ -28,32 +52,94 @@ func NewMail(cfg *config.Config) *Mail {
 	}
 }
 
-func (m *Mail) SendMail(from, to, subject, tmpl string, data interface{}) error {
-	templateToRender := fmt.Sprintf("templates/%s.html.tmpl", tmpl)
+// Templates returns the names of the email templates, sorted.
+func Templates() []string {
+	files, _ := fs.Glob(emailTemplateFS, "templates/*.html.tmpl")
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, strings.TrimSuffix(path.Base(f), ".html.tmpl"))
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Render renders the HTML and plain text bodies of the template tmpl with data, in
+// the locale code when it translates tmpl and in English otherwise. An unsupported
+// or empty code falls back to the shop default.
+func Render(tmpl, code string, data interface{}) (html, plain string, err error) {
+	if !knownTemplate(tmpl) {
+		return "", "", fmt.Errorf("%w: %s", ErrUnknownTemplate, tmpl)
+	}
+
+	code = i18n.Match(code)
+	dir := "templates"
+	if _, err := fs.Stat(emailTemplateFS, fmt.Sprintf("templates/%s/%s.html.tmpl", code, tmpl)); err == nil {
+		dir = "templates/" + code
+	}
 
-	t, err := template.New("email-html").ParseFS(emailTemplateFS, templateToRender)
+	html, err = render(fmt.Sprintf("%s/%s.html.tmpl", dir, tmpl), code, data)
 	if err != nil {
-		return err
+		return "", "", err
 	}
 
-	var tpl bytes.Buffer
-	if err = t.ExecuteTemplate(&tpl, "body", data); err != nil {
-		return err
+	plain, err = render(fmt.Sprintf("%s/%s.plain.tmpl", dir, tmpl), code, data)
+	if err != nil {
+		return "", "", err
 	}
 
-	formattedMessage := tpl.String()
+	return html, plain, nil
+}
 
-	templateToRender = fmt.Sprintf("templates/%s.plain.tmpl", tmpl)
-	t, err = template.New("email-plain").ParseFS(emailTemplateFS, templateToRender)
+func render(file, code string, data interface{}) (string, error) {
+	t, err := template.New("email").Funcs(template.FuncMap{
+		"t": func(msg string) string { return i18n.T(code, msg) },
+		"money": func(amount string) string {
+			decimal, currency, ok := strings.Cut(amount, " ")
+			m, err := money.Parse(decimal, currency)
+			if !ok || err != nil {
+				return amount
+			}
+			return i18n.FormatMoney(code, m)
+		},
+		"lines": func(list string) []string {
+			var lines []string
+			for _, l := range strings.Split(list, "\n") {
+				if l != "" {
+					lines = append(lines, l)
+				}
+			}
+			return lines
+		},
+	}).ParseFS(emailTemplateFS, file)
 	if err != nil {
-		return err
+		return "", err
 	}
 
+	var tpl bytes.Buffer
 	if err = t.ExecuteTemplate(&tpl, "body", data); err != nil {
-		return err
+		return "", err
+	}
+
+	return tpl.String(), nil
+}
+
+func knownTemplate(tmpl string) bool {
+	for _, t := range Templates() {
+		if t == tmpl {
+			return true
+		}
 	}
 
-	plainMessage := tpl.String()
+	return false
+}
+
+func (m *Mail) SendMail(from, to, subject, tmpl, code string, data interface{}) error {
+	formattedMessage, plainMessage, err := Render(tmpl, code, data)
+	if err != nil {
+		return err
+	}
 
 	// send the mail
 	server := mail.NewSMTPClient()
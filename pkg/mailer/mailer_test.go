@@ -0,0 +1,65 @@
+package mailer_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	"github.com/jofosuware/go/shopit/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	for _, code := range i18n.Locales() {
+		for _, tmpl := range mailer.Templates() {
+			t.Run(code+"/"+tmpl, func(t *testing.T) {
+				html, plain, err := mailer.Render(tmpl, code, mailer.SampleData(tmpl))
+				require.NoError(t, err)
+
+				assert.NotEmpty(t, mailer.SampleData(tmpl), "templates need sample data for previews")
+				assert.Contains(t, html, "<html")
+				assert.NotContains(t, plain, "<html")
+				assert.NotContains(t, plain, "<no value>")
+			})
+		}
+	}
+
+	_, _, err := mailer.Render("../mailer", "", nil)
+	assert.ErrorIs(t, err, mailer.ErrUnknownTemplate)
+}
+
+func TestRenderLocale(t *testing.T) {
+	data := map[string]string{"OrderID": "1", "Total": "1250.00 EUR", "Status": "Shipped"}
+
+	_, plain, err := mailer.Render("order-placed", i18n.English, data)
+	require.NoError(t, err)
+	assert.Contains(t, plain, "Thank you for your order")
+	assert.Contains(t, plain, "Total: 1,250.00 EUR")
+
+	_, plain, err = mailer.Render("order-placed", "fr-FR", data)
+	require.NoError(t, err)
+	assert.Contains(t, plain, "Merci pour votre commande")
+	assert.Contains(t, plain, "Total : 1 250,00 EUR")
+
+	html, _, err := mailer.Render("order-status", i18n.French, data)
+	require.NoError(t, err)
+	assert.Contains(t, html, "est désormais : Expédiée.")
+
+	_, plain, err = mailer.Render("order-status", "de", data)
+	require.NoError(t, err)
+	assert.Contains(t, plain, "is now Shipped.")
+}
+
+func TestTranslatedTemplates(t *testing.T) {
+	files, err := filepath.Glob("templates/*/*.tmpl")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, f := range files {
+		code, name := filepath.Base(filepath.Dir(f)), strings.SplitN(filepath.Base(f), ".", 2)[0]
+		assert.True(t, i18n.Supported(code), "%s translates to an unsupported locale", f)
+		assert.Contains(t, mailer.Templates(), name, "%s translates a template that does not exist", f)
+	}
+}
@@ -9,17 +9,17 @@ type Mailer struct {
 	mock.Mock
 }
 
-// SendMail provides a mock function with given fields: from, to, subject, tmpl, data
-func (_m *Mailer) SendMail(from string, to string, subject string, tmpl string, data interface{}) error {
-	ret := _m.Called(from, to, subject, tmpl, data)
+// SendMail provides a mock function with given fields: from, to, subject, tmpl, code, data
+func (_m *Mailer) SendMail(from string, to string, subject string, tmpl string, code string, data interface{}) error {
+	ret := _m.Called(from, to, subject, tmpl, code, data)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendMail")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string, string, interface{}) error); ok {
-		r0 = rf(from, to, subject, tmpl, data)
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string, interface{}) error); ok {
+		r0 = rf(from, to, subject, tmpl, code, data)
 	} else {
 		r0 = ret.Error(0)
 	}
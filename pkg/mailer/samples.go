@@ -0,0 +1,81 @@
+package mailer
+
+// samples holds example data for each template, shaped like the data the flows
+// sending the template pass, for previews and test sends.
+var samples = map[string]map[string]string{
+	"account-created": {
+		"Name":     "Ama Mensah",
+		"Email":    "ama@example.com",
+		"Password": "Tmp-Pa55word",
+	},
+	"account-deletion": {
+		"Name":        "Ama Mensah",
+		"Link":        "https://shop.example.com/account/restore/SAMPLETOKEN",
+		"DeleteAfter": "January 2, 2006",
+	},
+	"admin-digest": {
+		"From":           "January 1, 2006",
+		"To":             "January 2, 2006",
+		"NewOrders":      "12",
+		"Revenue":        "1250.00 USD",
+		"FailedPayments": "1",
+		"NewReviews":     "4",
+		"LowStock":       "Kente Scarf (KS-RED-M): 2\nShea Butter Soap: 5",
+	},
+	"low-stock": {
+		"ProductID": "3c9e2b1a-8f7d-4e6c-b5a4-2d1e0f9a8b7c",
+		"Name":      "Kente Scarf (KS-RED-M)",
+		"Stock":     "4",
+		"Threshold": "5",
+	},
+	"magic-link": {
+		"Name":    "Ama Mensah",
+		"Link":    "https://shop.example.com/api/v1/auth/magic-link?token=SAMPLETOKEN&hash=.SAMPLE.SIGNATURE",
+		"Expires": "15 minutes",
+		"Device":  "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X)",
+		"IP":      "203.0.113.7",
+	},
+	"order-placed": {
+		"OrderID":     "7d5f0a4e-1c2b-4f3a-9e8d-6b5a4c3d2e1f",
+		"Total":       "250.00 USD",
+		"Gift":        "true",
+		"GiftMessage": "Happy birthday, Kofi!",
+		"HidePrices":  "true",
+	},
+	"order-status": {
+		"OrderID": "7d5f0a4e-1c2b-4f3a-9e8d-6b5a4c3d2e1f",
+		"Status":  "Shipped",
+	},
+	"password-reset": {
+		"Link":    "https://shop.example.com/password/reset/SAMPLETOKEN",
+		"Expires": "60 minutes",
+	},
+	"price-drop": {
+		"ProductID": "3c9e2b1a-8f7d-4e6c-b5a4-2d1e0f9a8b7c",
+		"Name":      "Kente Scarf",
+		"OldPrice":  "45.00 USD",
+		"NewPrice":  "36.00 USD",
+	},
+	"ticket-message": {
+		"TicketID": "5b2e8c1d-9a7f-4c3e-8d6b-1f0a2e3c4d5b",
+		"Subject":  "Missing scarf",
+		"Status":   "open",
+		"Message":  "My parcel arrived without the Kente Scarf I ordered.",
+	},
+	"ticket-update": {
+		"TicketID": "5b2e8c1d-9a7f-4c3e-8d6b-1f0a2e3c4d5b",
+		"Subject":  "Missing scarf",
+		"Status":   "answered",
+		"Message":  "Sorry about that, the scarf ships today.",
+	},
+}
+
+// SampleData returns example data to render the template tmpl with. Templates
+// without samples get an empty map, so they still render.
+func SampleData(tmpl string) map[string]string {
+	if data, ok := samples[tmpl]; ok {
+		return data
+	}
+
+	return map[string]string{}
+}
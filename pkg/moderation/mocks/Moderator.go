@@ -0,0 +1,59 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	moderation "github.com/jofosuware/go/shopit/pkg/moderation"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Moderator is an autogenerated mock type for the Moderator type
+type Moderator struct {
+	mock.Mock
+}
+
+// Review provides a mock function with given fields: ctx, image, contentType
+func (_m *Moderator) Review(ctx context.Context, image []byte, contentType string) (*moderation.Verdict, error) {
+	ret := _m.Called(ctx, image, contentType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Review")
+	}
+
+	var r0 *moderation.Verdict
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, string) (*moderation.Verdict, error)); ok {
+		return rf(ctx, image, contentType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, string) *moderation.Verdict); ok {
+		r0 = rf(ctx, image, contentType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*moderation.Verdict)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte, string) error); ok {
+		r1 = rf(ctx, image, contentType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewModerator creates a new instance of Moderator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewModerator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Moderator {
+	mock := &Moderator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,101 @@
+// Package moderation reviews uploaded images before they are stored.
+//
+// A Moderator decides whether an image may be published, for instance by running
+// face detection or an NSFW classifier. The bundled Webhook moderator delegates the
+// decision to an external service; Nop accepts everything.
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jofosuware/go/shopit/config"
+)
+
+// ErrUnavailable is returned when the moderation service cannot be reached or
+// answers with something other than a verdict.
+var ErrUnavailable = errors.New("image moderation unavailable")
+
+// DefaultTimeout bounds a moderation request when no timeout is configured.
+const DefaultTimeout = 5 * time.Second
+
+// maxResponseSize caps the verdict read from the moderation service.
+const maxResponseSize = 64 << 10
+
+// Verdict is the outcome of reviewing an image. Reason explains a rejection and is
+// shown to the client.
+type Verdict struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+// Moderator reviews an image before it is stored.
+type Moderator interface {
+	Review(ctx context.Context, image []byte, contentType string) (*Verdict, error)
+}
+
+// Nop is a Moderator that allows every image.
+type Nop struct{}
+
+// Review allows the image.
+func (Nop) Review(context.Context, []byte, string) (*Verdict, error) {
+	return &Verdict{Allowed: true}, nil
+}
+
+// Webhook is a Moderator that posts the image to an external service. The service
+// receives the raw image with its Content-Type and answers 200 with a JSON Verdict.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook returns a Webhook posting to url. A non-positive timeout falls back to
+// DefaultTimeout.
+func NewWebhook(url string, timeout time.Duration) *Webhook {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Webhook{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// New returns the Moderator configured by cfg: a Webhook when a moderation URL is
+// set, Nop otherwise.
+func New(cfg config.Avatar) Moderator {
+	if cfg.ModerationURL == "" {
+		return Nop{}
+	}
+	return NewWebhook(cfg.ModerationURL, cfg.ModerationTimeout)
+}
+
+// Review posts the image to the moderation service and returns its verdict.
+func (w *Webhook) Review(ctx context.Context, image []byte, contentType string) (*Verdict, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(image))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: service responded %s", ErrUnavailable, res.Status)
+	}
+
+	var v Verdict
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxResponseSize)).Decode(&v); err != nil {
+		return nil, fmt.Errorf("%w: decoding verdict: %v", ErrUnavailable, err)
+	}
+
+	return &v, nil
+}
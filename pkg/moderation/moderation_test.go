@@ -0,0 +1,67 @@
+package moderation_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/pkg/moderation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.IsType(t, moderation.Nop{}, moderation.New(config.Avatar{}))
+	assert.IsType(t, &moderation.Webhook{}, moderation.New(config.Avatar{ModerationURL: "http://moderation"}))
+}
+
+func TestWebhookReview(t *testing.T) {
+	t.Run("Verdict", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "image/png", r.Header.Get("Content-Type"))
+			assert.Equal(t, "img", string(body))
+			_, _ = w.Write([]byte(`{"allowed": false, "reason": "face not detected"}`))
+		}))
+		defer srv.Close()
+
+		v, err := moderation.NewWebhook(srv.URL, 0).Review(context.Background(), []byte("img"), "image/png")
+		require.NoError(t, err)
+		assert.Equal(t, &moderation.Verdict{Allowed: false, Reason: "face not detected"}, v)
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		_, err := moderation.NewWebhook(srv.URL, 0).Review(context.Background(), []byte("img"), "image/png")
+		assert.ErrorIs(t, err, moderation.ErrUnavailable)
+	})
+
+	t.Run("Malformed verdict", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer srv.Close()
+
+		_, err := moderation.NewWebhook(srv.URL, 0).Review(context.Background(), []byte("img"), "image/png")
+		assert.ErrorIs(t, err, moderation.ErrUnavailable)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+		}))
+		defer srv.Close()
+
+		_, err := moderation.NewWebhook(srv.URL, 10*time.Millisecond).Review(context.Background(), []byte("img"), "image/png")
+		assert.ErrorIs(t, err, moderation.ErrUnavailable)
+	})
+}
@@ -0,0 +1,340 @@
+// Package money represents amounts of money as integer minor units of a currency,
+// such as cents of USD, so prices add up without floating point rounding.
+//
+// The shop prices in DefaultCurrency and may accept other currencies too, both set
+// from the config at start. The database stores minor units, with the currency in a
+// separate column where amounts can be in several currencies; amounts read from it
+// are in DefaultCurrency until labelled with WithCurrency. In JSON an amount is
+// {"amount": <minor units>, "currency": <ISO 4217 code>} in an accepted currency;
+// requests may also give a decimal string of major units, such as "49.99", in
+// DefaultCurrency.
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of the shop.
+var DefaultCurrency = "USD"
+
+// ErrInvalidAmount is returned when parsing an amount that is not a decimal number
+// with at most the minor digits of its currency.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ErrInvalidCurrency is returned when a currency is not a three letter code.
+var ErrInvalidCurrency = errors.New("currency must be a three letter ISO 4217 code")
+
+// ErrUnsupportedCurrency is returned when decoding an amount in a currency the shop
+// does not accept.
+var ErrUnsupportedCurrency = errors.New("currency is not accepted by the shop")
+
+// accepted lists the currencies accepted besides DefaultCurrency.
+var accepted = map[string]bool{}
+
+// minorDigits lists the currencies without two minor digits.
+var minorDigits = map[string]int{
+	"BHD": 3, "CLP": 0, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3,
+	"LYD": 3, "OMR": 3, "PYG": 0, "TND": 3, "UGX": 0, "VND": 0, "XAF": 0, "XOF": 0,
+}
+
+// Money is an amount in minor units of Currency. The zero value is zero in no
+// currency yet; adding it to an amount takes that amount's currency.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// SetCurrency makes code, such as "USD", the currency of the shop.
+func SetCurrency(code string) error {
+	code, err := parseCode(code)
+	if err != nil {
+		return err
+	}
+	DefaultCurrency = code
+
+	return nil
+}
+
+// Accept makes the shop accept amounts in codes besides DefaultCurrency, replacing
+// the currencies accepted before.
+func Accept(codes ...string) error {
+	set := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		code, err := parseCode(c)
+		if err != nil {
+			return err
+		}
+		set[code] = true
+	}
+	accepted = set
+
+	return nil
+}
+
+// Supported reports whether the shop accepts amounts in currency.
+func Supported(currency string) bool {
+	currency = strings.ToUpper(currency)
+	return currency == DefaultCurrency || accepted[currency]
+}
+
+// Currencies returns the accepted currencies, DefaultCurrency first.
+func Currencies() []string {
+	codes := make([]string, 0, len(accepted))
+	for c := range accepted {
+		if c != DefaultCurrency {
+			codes = append(codes, c)
+		}
+	}
+	sort.Strings(codes)
+
+	return append([]string{DefaultCurrency}, codes...)
+}
+
+// parseCode returns code upper case when it is a three letter currency code.
+func parseCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, code)
+	}
+
+	return code, nil
+}
+
+// New returns amount minor units of currency.
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// Of returns amount minor units of DefaultCurrency.
+func Of(amount int64) Money {
+	return New(amount, DefaultCurrency)
+}
+
+// Parse parses a decimal amount of major units of currency, such as "49.99".
+func Parse(s, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	digits := Digits(currency)
+
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	whole, frac, _ := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if whole == "" && frac == "" || len(frac) > digits || strings.ContainsAny(whole+frac, "+-") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	frac += strings.Repeat("0", digits-len(frac))
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if neg {
+		n = -n
+	}
+
+	return New(n, currency), nil
+}
+
+// Digits returns the number of minor digits of currency.
+func Digits(currency string) int {
+	if d, ok := minorDigits[strings.ToUpper(currency)]; ok {
+		return d
+	}
+
+	return 2
+}
+
+// WithCurrency returns the minor units of m in currency. It does not convert m; it
+// labels amounts read from the database with the currency stored next to them.
+func (m Money) WithCurrency(currency string) Money {
+	return New(m.Amount, currency)
+}
+
+// Decimal formats m in major units, such as "49.99".
+func (m Money) Decimal() string {
+	digits := Digits(m.currency())
+
+	sign, n := "", m.Amount
+	if n < 0 {
+		sign, n = "-", -n
+	}
+
+	s := strconv.FormatInt(n, 10)
+	if digits == 0 {
+		return sign + s
+	}
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
+
+// String formats m with its currency, such as "49.99 USD".
+func (m Money) String() string {
+	return m.Decimal() + " " + m.currency()
+}
+
+// Add returns m + o. It panics when both have a currency and they differ.
+func (m Money) Add(o Money) Money {
+	return Money{Amount: m.Amount + o.Amount, Currency: m.common(o)}
+}
+
+// Sub returns m - o. It panics when both have a currency and they differ.
+func (m Money) Sub(o Money) Money {
+	return Money{Amount: m.Amount - o.Amount, Currency: m.common(o)}
+}
+
+// Mul returns m times n.
+func (m Money) Mul(n int) Money {
+	return Money{Amount: m.Amount * int64(n), Currency: m.Currency}
+}
+
+// Percent returns percent percent of m, rounded down to a minor unit.
+func (m Money) Percent(percent int) Money {
+	return Money{Amount: m.Amount * int64(percent) / 100, Currency: m.Currency}
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// IsZero reports whether m is zero.
+func (m Money) IsZero() bool { return m.Amount == 0 }
+
+// IsPositive reports whether m is more than zero.
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+
+// IsNegative reports whether m is less than zero.
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+// Less reports whether m is less than o.
+func (m Money) Less(o Money) bool {
+	m.common(o)
+	return m.Amount < o.Amount
+}
+
+// Min returns the smaller of a and b.
+func Min(a, b Money) Money {
+	if b.Less(a) {
+		return b
+	}
+	return a
+}
+
+// Max returns the larger of a and b.
+func Max(a, b Money) Money {
+	if a.Less(b) {
+		return b
+	}
+	return a
+}
+
+// common returns the currency of m and o, taking it from whichever has one.
+func (m Money) common(o Money) string {
+	switch {
+	case m.Currency == "":
+		return o.Currency
+	case o.Currency == "" || o.Currency == m.Currency:
+		return m.Currency
+	}
+
+	panic(fmt.Sprintf("money: %s and %s amounts cannot be combined", m.Currency, o.Currency))
+}
+
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+type jsonMoney struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes m as {"amount": <minor units>, "currency": <code>}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMoney{Amount: m.Amount, Currency: m.currency()})
+}
+
+// UnmarshalJSON decodes {"amount": <minor units>, "currency": <code>}, or a decimal
+// string of major units of DefaultCurrency. The currency must be accepted by the shop.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+
+		v, err := Parse(s, DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		*m = v
+
+		return nil
+	}
+
+	if len(b) == 0 || b[0] != '{' {
+		return fmt.Errorf("%w: expected an object with the amount in minor units or a decimal string", ErrInvalidAmount)
+	}
+
+	var v jsonMoney
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.Currency == "" {
+		v.Currency = DefaultCurrency
+	}
+	if !Supported(v.Currency) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCurrency, v.Currency)
+	}
+	*m = New(v.Amount, v.Currency)
+
+	return nil
+}
+
+// Scan reads an amount of minor units stored in the database, in DefaultCurrency.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		*m = Of(v)
+	case int:
+		*m = Of(int64(v))
+	case []byte:
+		n, err := strconv.ParseInt(string(v), 10, 64)
+		if err != nil {
+			return fmt.Errorf("money: scanning %q: %w", v, err)
+		}
+		*m = Of(n)
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("money: scanning %q: %w", v, err)
+		}
+		*m = Of(n)
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+
+	return nil
+}
+
+// Value stores m as its minor units.
+func (m Money) Value() (driver.Value, error) {
+	return m.Amount, nil
+}
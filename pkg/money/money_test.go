@@ -0,0 +1,127 @@
+package money_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCurrency(t *testing.T) {
+	defer func(c string) { money.DefaultCurrency = c }(money.DefaultCurrency)
+
+	require.NoError(t, money.SetCurrency("eur"))
+	assert.Equal(t, "EUR", money.DefaultCurrency)
+
+	assert.ErrorIs(t, money.SetCurrency("euro"), money.ErrInvalidCurrency)
+	assert.ErrorIs(t, money.SetCurrency("E1R"), money.ErrInvalidCurrency)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     int64
+	}{
+		{"49.99", "USD", 4999},
+		{"49.9", "usd", 4990},
+		{"49", "USD", 4900},
+		{".5", "USD", 50},
+		{"-1.25", "USD", -125},
+		{"0.1", "USD", 10},
+		{"1500", "JPY", 1500},
+		{"1.005", "KWD", 1005},
+	}
+
+	for _, tt := range tests {
+		m, err := money.Parse(tt.in, tt.currency)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, m.Amount, tt.in)
+	}
+
+	for _, in := range []string{"", "-", "1.999", "1.5", "abc", "1,00", "--1", "1.-5"} {
+		currency := "USD"
+		if in == "1.5" {
+			currency = "JPY"
+		}
+		_, err := money.Parse(in, currency)
+		assert.ErrorIs(t, err, money.ErrInvalidAmount, in)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	assert.Equal(t, "49.99", money.New(4999, "USD").Decimal())
+	assert.Equal(t, "0.05", money.New(5, "USD").Decimal())
+	assert.Equal(t, "-0.50", money.New(-50, "USD").Decimal())
+	assert.Equal(t, "1500", money.New(1500, "JPY").Decimal())
+	assert.Equal(t, "12.00 USD", money.New(1200, "USD").String())
+}
+
+func TestArithmetic(t *testing.T) {
+	a := money.New(1999, "USD")
+
+	assert.Equal(t, money.New(3998, "USD"), a.Add(a))
+	assert.Equal(t, money.New(1999, "USD"), money.Money{}.Add(a))
+	assert.Equal(t, money.New(0, "USD"), a.Sub(a))
+	assert.Equal(t, money.New(5997, "USD"), a.Mul(3))
+	// 10% of 19.99 is rounded down to 1.99
+	assert.Equal(t, money.New(199, "USD"), a.Percent(10))
+	assert.Equal(t, a, money.Min(a, a.Mul(2)))
+	assert.Equal(t, a.Mul(2), money.Max(a, a.Mul(2)))
+	assert.True(t, a.Neg().IsNegative())
+
+	assert.Panics(t, func() { a.Add(money.New(1, "EUR")) })
+}
+
+func TestJSON(t *testing.T) {
+	b, err := json.Marshal(money.New(4999, "USD"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":4999,"currency":"USD"}`, string(b))
+
+	var m money.Money
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":4999,"currency":"usd"}`), &m))
+	assert.Equal(t, money.New(4999, "USD"), m)
+
+	require.NoError(t, json.Unmarshal([]byte(`"49.99"`), &m))
+	assert.Equal(t, money.New(4999, "USD"), m)
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`49.99`), &m), money.ErrInvalidAmount)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount":1,"currency":"EUR"}`), &m), money.ErrUnsupportedCurrency)
+
+	require.NoError(t, money.Accept("eur"))
+	defer func() { _ = money.Accept() }()
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":1,"currency":"EUR"}`), &m))
+	assert.Equal(t, money.New(1, "EUR"), m)
+}
+
+func TestAccept(t *testing.T) {
+	defer func() { _ = money.Accept() }()
+
+	require.NoError(t, money.Accept("gbp", "EUR"))
+	assert.True(t, money.Supported("eur"))
+	assert.True(t, money.Supported(money.DefaultCurrency))
+	assert.False(t, money.Supported("JPY"))
+	assert.Equal(t, []string{money.DefaultCurrency, "EUR", "GBP"}, money.Currencies())
+
+	assert.ErrorIs(t, money.Accept("euro"), money.ErrInvalidCurrency)
+}
+
+func TestScanValue(t *testing.T) {
+	var m money.Money
+	require.NoError(t, m.Scan(int64(4999)))
+	assert.Equal(t, money.New(4999, money.DefaultCurrency), m)
+
+	require.NoError(t, m.Scan([]byte("12")))
+	assert.Equal(t, int64(12), m.Amount)
+
+	assert.Error(t, m.Scan(4.5))
+
+	assert.Equal(t, money.New(12, "EUR"), m.WithCurrency("eur"))
+
+	v, err := money.New(4999, "USD").Value()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4999), v)
+}
@@ -0,0 +1,290 @@
+// Package outbox delivers domain events reliably with a transactional outbox. An
+// event is written to the outbox table in the same transaction as the change it
+// describes, so it is neither lost when the process stops before handling it nor
+// handled for a change that was rolled back. A Dispatcher then hands the pending
+// events to their handler, retries those that fail and marks them processed.
+//
+// Delivery is at least once: an event is handled again when the process stops, or
+// marking it processed fails, after its handler succeeded.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+)
+
+// Defaults of the Options of a Dispatcher.
+const (
+	DefaultBatchSize   = 50
+	DefaultMaxAttempts = 10
+	DefaultRetention   = 7 * 24 * time.Hour
+)
+
+// lease is how long a claimed event is left to its dispatcher before it can be
+// claimed again, in case the process stopped while handling it.
+const lease = 5 * time.Minute
+
+// firstBackoff is how long a failed event waits for its next attempt; the wait
+// doubles with every attempt, up to maxBackoff.
+const (
+	firstBackoff = 30 * time.Second
+	maxBackoff   = time.Hour
+)
+
+// Execer runs statements, as *sql.DB and *sql.Tx do.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Write records the event named name carrying data, as JSON, through tx. It is
+// dispatched once tx commits.
+func Write(ctx context.Context, tx Execer, name string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error encoding %s event: %v", name, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `insert into outbox (name, payload) values ($1, $2)`, name, payload)
+	if err != nil {
+		return fmt.Errorf("error writing %s event to the outbox: %v", name, err)
+	}
+
+	return nil
+}
+
+// Options of a Dispatcher. BatchSize is how many events a Dispatch handles at most,
+// MaxAttempts how many times an event is handled before it is given up on, and
+// Retention how long processed events are kept. Zero values fall back to the
+// defaults.
+type Options struct {
+	BatchSize   int
+	MaxAttempts int
+	Retention   time.Duration
+}
+
+// message is an event read back from the outbox.
+type message struct {
+	id        uuid.UUID
+	name      string
+	payload   []byte
+	attempts  int
+	createdAt time.Time
+}
+
+type route struct {
+	handler events.Handler
+	decode  func(payload []byte) (interface{}, error)
+}
+
+// Dispatcher hands the events of the outbox to their handler.
+type Dispatcher struct {
+	db     *sql.DB
+	logger logger.Logger
+	opts   Options
+	routes map[string]route
+	now    func() time.Time
+}
+
+// NewDispatcher returns a Dispatcher of the outbox of db, logging the events it gives
+// up on with logger.
+func NewDispatcher(db *sql.DB, logger logger.Logger, opts Options) *Dispatcher {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultRetention
+	}
+
+	return &Dispatcher{
+		db:     db,
+		logger: logger,
+		opts:   opts,
+		routes: make(map[string]route),
+		now:    time.Now,
+	}
+}
+
+// Handle calls handler with the events named names, their data decoded into a T. An
+// event has one handler, the last one registered for its name; handlers are
+// registered before dispatching starts. Events without a handler are marked
+// processed.
+func Handle[T any](d *Dispatcher, handler events.Handler, names ...string) {
+	decode := func(payload []byte) (interface{}, error) {
+		var data T
+		if err := json.Unmarshal(payload, &data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	for _, name := range names {
+		d.routes[name] = route{handler: handler, decode: decode}
+	}
+}
+
+// Dispatch hands a batch of pending events, oldest first, to their handler and
+// returns how many were processed. An event whose handler fails is retried after a
+// wait doubling with its attempts; after MaxAttempts it is left in the outbox with
+// its last error. Processed events older than Retention are deleted. Once ctx is done
+// no other event is handed over; those left claimed are retried when their lease ends.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	if err := d.purge(ctx); err != nil {
+		return 0, fmt.Errorf("error purging processed events: %v", err)
+	}
+
+	msgs, err := d.claim(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming events: %v", err)
+	}
+
+	var processed int
+	var errs []error
+	for _, m := range msgs {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := d.deliver(m); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		processed++
+	}
+
+	return processed, errors.Join(errs...)
+}
+
+// claim leases a batch of the events due, counting the attempt.
+func (d *Dispatcher) claim(ctx context.Context) ([]message, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	now := d.now()
+	query := `update outbox set attempts = attempts + 1, next_attempt_at = $1
+			where event_id in (
+				select event_id from outbox
+				where processed_at is null and next_attempt_at <= $2 and attempts < $3
+				order by created_at
+				limit $4
+				for update skip locked)
+			returning event_id, name, payload, attempts, created_at`
+
+	rows, err := d.db.QueryContext(ctx, query, now.Add(lease), now, d.opts.MaxAttempts, d.opts.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var msgs []message
+	for rows.Next() {
+		var m message
+		if err := rows.Scan(&m.id, &m.name, &m.payload, &m.attempts, &m.createdAt); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].createdAt.Before(msgs[j].createdAt) })
+
+	return msgs, nil
+}
+
+// deliver hands m to its handler and records the outcome. The outcome is recorded on a
+// context of its own, for a handled event not to be handed over again.
+func (d *Dispatcher) deliver(m message) error {
+	err := d.handle(m)
+	if err == nil {
+		if err := d.markProcessed(m); err != nil {
+			return fmt.Errorf("error marking %s event %s processed: %v", m.name, m.id, err)
+		}
+		return nil
+	}
+
+	err = fmt.Errorf("error handling %s event %s (attempt %d): %v", m.name, m.id, m.attempts, err)
+	if m.attempts >= d.opts.MaxAttempts {
+		d.logger.Errorf("giving up on %s event %s after %d attempts", m.name, m.id, m.attempts)
+	}
+
+	if ferr := d.markFailed(m, err); ferr != nil {
+		return errors.Join(err, fmt.Errorf("error recording failure of %s event %s: %v", m.name, m.id, ferr))
+	}
+
+	return err
+}
+
+// handle calls the handler of m with its decoded data, turning a panic into an error.
+func (d *Dispatcher) handle(m message) (err error) {
+	r, ok := d.routes[m.name]
+	if !ok {
+		return nil
+	}
+
+	data, err := r.decode(m.payload)
+	if err != nil {
+		return fmt.Errorf("error decoding event: %v", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return r.handler(events.Event{Name: m.name, OccurredAt: m.createdAt, Data: data})
+}
+
+func (d *Dispatcher) markProcessed(m message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := d.db.ExecContext(ctx, `update outbox set processed_at = $1, last_error = '' where event_id = $2`,
+		d.now(), m.id)
+
+	return err
+}
+
+func (d *Dispatcher) markFailed(m message, cause error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := d.db.ExecContext(ctx, `update outbox set last_error = $1, next_attempt_at = $2 where event_id = $3`,
+		cause.Error(), d.now().Add(backoff(m.attempts)), m.id)
+
+	return err
+}
+
+// purge deletes the events processed more than Retention ago.
+func (d *Dispatcher) purge(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := d.db.ExecContext(ctx, `delete from outbox where processed_at < $1`, d.now().Add(-d.opts.Retention))
+
+	return err
+}
+
+// backoff is how long an event waits after its attempts failed.
+func backoff(attempts int) time.Duration {
+	wait := firstBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+
+	return min(wait, maxBackoff)
+}
@@ -0,0 +1,148 @@
+package outbox_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/events"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/outbox"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type order struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+const (
+	purgeQuery  = `delete from outbox where processed_at < \$1`
+	claimQuery  = `update outbox set attempts = attempts \+ 1, next_attempt_at = \$1\s+where event_id in \(.+for update skip locked\)\s+returning event_id, name, payload, attempts, created_at`
+	doneQuery   = `update outbox set processed_at = \$1, last_error = '' where event_id = \$2`
+	failedQuery = `update outbox set last_error = \$1, next_attempt_at = \$2 where event_id = \$3`
+)
+
+func claimed(events ...[]driver.Value) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"event_id", "name", "payload", "attempts", "created_at"})
+	for _, e := range events {
+		rows.AddRow(e...)
+	}
+	return rows
+}
+
+func TestWrite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	payload, _ := json.Marshal(order{ID: "1", Status: "Shipped"})
+	mock.ExpectExec(`insert into outbox \(name, payload\) values \(\$1, \$2\)`).
+		WithArgs(events.OrderStatusChanged, payload).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, outbox.Write(context.Background(), db, events.OrderStatusChanged, order{ID: "1", Status: "Shipped"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatcher(t *testing.T) {
+	newDispatcher := func(t *testing.T) (*outbox.Dispatcher, sqlmock.Sqlmock, *mockLogger.Logger) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		logger := mockLogger.NewLogger(t)
+		return outbox.NewDispatcher(db, logger, outbox.Options{BatchSize: 10, MaxAttempts: 3}), mock, logger
+	}
+
+	t.Run("Events are handled oldest first and marked processed", func(t *testing.T) {
+		d, mock, _ := newDispatcher(t)
+		var handled []order
+		outbox.Handle[order](d, func(e events.Event) error {
+			handled = append(handled, e.Data.(order))
+			return nil
+		}, events.OrderCreated, events.OrderStatusChanged)
+
+		first, second := uuid.New(), uuid.New()
+		mock.ExpectExec(purgeQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(claimQuery).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 3, 10).WillReturnRows(claimed(
+			[]driver.Value{second, events.OrderStatusChanged, []byte(`{"id":"1","status":"Shipped"}`), 1, time.Now()},
+			[]driver.Value{first, events.OrderCreated, []byte(`{"id":"1","status":"Processing"}`), 1, time.Now().Add(-time.Minute)},
+		))
+		mock.ExpectExec(doneQuery).WithArgs(sqlmock.AnyArg(), first).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(doneQuery).WithArgs(sqlmock.AnyArg(), second).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		n, err := d.Dispatch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []order{{ID: "1", Status: "Processing"}, {ID: "1", Status: "Shipped"}}, handled)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed event is retried later", func(t *testing.T) {
+		d, mock, _ := newDispatcher(t)
+		outbox.Handle[order](d, func(e events.Event) error { return errors.New("smtp down") }, events.OrderCreated)
+
+		id := uuid.New()
+		mock.ExpectExec(purgeQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(claimQuery).WillReturnRows(claimed(
+			[]driver.Value{id, events.OrderCreated, []byte(`{"id":"1"}`), 1, time.Now()},
+		))
+		mock.ExpectExec(failedQuery).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), id).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		n, err := d.Dispatch(context.Background())
+		assert.ErrorContains(t, err, "smtp down")
+		assert.Equal(t, 0, n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Event is given up on after its last attempt", func(t *testing.T) {
+		d, mock, logger := newDispatcher(t)
+		outbox.Handle[order](d, func(e events.Event) error { panic("boom") }, events.OrderCreated)
+
+		id := uuid.New()
+		mock.ExpectExec(purgeQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(claimQuery).WillReturnRows(claimed(
+			[]driver.Value{id, events.OrderCreated, []byte(`{"id":"1"}`), 3, time.Now()},
+		))
+		mock.ExpectExec(failedQuery).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), id).WillReturnResult(sqlmock.NewResult(0, 1))
+		logger.On("Errorf", testifymock.Anything, events.OrderCreated, id, 3).Once()
+
+		_, err := d.Dispatch(context.Background())
+		assert.ErrorContains(t, err, "panic: boom")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Event without a handler is marked processed", func(t *testing.T) {
+		d, mock, _ := newDispatcher(t)
+
+		id := uuid.New()
+		mock.ExpectExec(purgeQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(claimQuery).WillReturnRows(claimed(
+			[]driver.Value{id, events.UserRegistered, []byte(`{}`), 1, time.Now()},
+		))
+		mock.ExpectExec(doneQuery).WithArgs(sqlmock.AnyArg(), id).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		n, err := d.Dispatch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Claim error", func(t *testing.T) {
+		d, mock, _ := newDispatcher(t)
+
+		mock.ExpectExec(purgeQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(claimQuery).WillReturnError(errors.New("db down"))
+
+		_, err := d.Dispatch(context.Background())
+		assert.ErrorContains(t, err, "db down")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,98 @@
+// Package payments abstracts the providers orders are paid with.
+//
+// A Provider creates a payment for an amount, confirms it once the customer has
+// approved it, captures or voids the amount it authorized and verifies the webhook
+// notifications it sends. Payment statuses are normalized to those of models.Payment,
+// so the payments of every provider share the payments table.
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/card"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+var (
+	// ErrUnknownProvider is returned for a provider that is not configured.
+	ErrUnknownProvider = errors.New("unknown payment provider")
+
+	// ErrInvalidWebhook is returned for a webhook request that cannot be verified.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+)
+
+// maxWebhookSize caps the webhook payload read from a provider.
+const maxWebhookSize = 64 << 10
+
+// Payment is a payment created with a provider.
+type Payment struct {
+	ID       string
+	Provider string
+	Status   string
+	// ClientSecret confirms a Stripe payment in the browser
+	ClientSecret string
+	// ApprovalURL is where the customer approves a PayPal payment
+	ApprovalURL string
+}
+
+// Event is a change of payment status reported by a provider webhook.
+type Event struct {
+	PaymentID string
+	Status    string
+}
+
+// Provider is the interface to a payment provider.
+type Provider interface {
+	// Name returns the name payments of the provider are recorded with
+	Name() string
+
+	// CreatePayment creates a payment of amount in its currency. The message explains a declined payment
+	// to the customer
+	CreatePayment(amount money.Money) (*Payment, string, error)
+
+	// ConfirmPayment completes a payment the customer approved and returns its status
+	ConfirmPayment(id string) (string, error)
+
+	// CapturePayment captures the amount authorized by a payment
+	CapturePayment(id string) error
+
+	// VoidPayment cancels a payment, releasing the amount it authorized
+	VoidPayment(id string) error
+
+	// ParseWebhook verifies a webhook request and returns the payment status change it reports, nil for
+	// notifications that do not change a payment
+	ParseWebhook(r *http.Request) (*Event, error)
+}
+
+// Providers are the configured providers by name.
+type Providers map[string]Provider
+
+// New returns the providers configured by cfg: Stripe, charging through card, and
+// PayPal when its credentials are set.
+func New(cfg *config.Config, card card.Carder) Providers {
+	p := Providers{models.PaymentStripe: NewStripe(card, cfg.Stripe.WebhookSecret)}
+	if cfg.PayPal.ClientID != "" {
+		p[models.PaymentPayPal] = NewPayPal(cfg.PayPal)
+	}
+
+	return p
+}
+
+// Get returns the provider named name. Payments recorded without a provider were
+// made with Stripe.
+func (p Providers) Get(name string) (Provider, error) {
+	if name == "" {
+		name = models.PaymentStripe
+	}
+
+	provider, ok := p[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,262 @@
+package payments_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/models"
+	mockCard "github.com/jofosuware/go/shopit/pkg/card/mocks"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/payments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/webhook"
+)
+
+func TestProviders(t *testing.T) {
+	t.Run("PayPal is configured by its credentials", func(t *testing.T) {
+		cfg := config.Config{}
+		providers := payments.New(&cfg, mockCard.NewCarder(t))
+
+		p, err := providers.Get("")
+		require.NoError(t, err)
+		assert.Equal(t, models.PaymentStripe, p.Name())
+
+		_, err = providers.Get(models.PaymentPayPal)
+		assert.ErrorIs(t, err, payments.ErrUnknownProvider)
+
+		cfg.PayPal.ClientID = "client"
+		p, err = payments.New(&cfg, mockCard.NewCarder(t)).Get(models.PaymentPayPal)
+		require.NoError(t, err)
+		assert.Equal(t, models.PaymentPayPal, p.Name())
+	})
+}
+
+func TestStripe(t *testing.T) {
+	t.Run("Payment intent is created", func(t *testing.T) {
+		carder := mockCard.NewCarder(t)
+		carder.On("CreatePaymentIntent", money.Of(500)).Return(&stripe.PaymentIntent{
+			ID: "pi_1", ClientSecret: "secret", Status: stripe.PaymentIntentStatusRequiresPaymentMethod,
+		}, "", nil).Once()
+
+		p, _, err := payments.NewStripe(carder, "").CreatePayment(money.Of(500))
+		require.NoError(t, err)
+		assert.Equal(t, &payments.Payment{
+			ID: "pi_1", Provider: models.PaymentStripe, Status: "requires_payment_method", ClientSecret: "secret",
+		}, p)
+	})
+
+	newWebhook := func(secret, eventType string) *http.Request {
+		payload := fmt.Sprintf(`{"id": "evt_1", "type": %q, "data": {"object": {"id": "pi_1", "object": "payment_intent"}}}`, eventType)
+		now := time.Now()
+		signature := hex.EncodeToString(webhook.ComputeSignature(now, []byte(payload), secret))
+
+		r := httptest.NewRequest(http.MethodPost, "/payment/webhook/stripe", strings.NewReader(payload))
+		r.Header.Set("Stripe-Signature", "t="+strconv.FormatInt(now.Unix(), 10)+",v1="+signature)
+		return r
+	}
+
+	t.Run("Webhook reports the payment status", func(t *testing.T) {
+		e, err := payments.NewStripe(mockCard.NewCarder(t), "whsec").ParseWebhook(newWebhook("whsec", "payment_intent.succeeded"))
+		require.NoError(t, err)
+		assert.Equal(t, &payments.Event{PaymentID: "pi_1", Status: models.PaymentSucceeded}, e)
+	})
+
+	t.Run("Other events are ignored", func(t *testing.T) {
+		e, err := payments.NewStripe(mockCard.NewCarder(t), "whsec").ParseWebhook(newWebhook("whsec", "charge.refunded"))
+		require.NoError(t, err)
+		assert.Nil(t, e)
+	})
+
+	t.Run("Invalid signature", func(t *testing.T) {
+		_, err := payments.NewStripe(mockCard.NewCarder(t), "whsec").ParseWebhook(newWebhook("other", "payment_intent.succeeded"))
+		assert.ErrorIs(t, err, payments.ErrInvalidWebhook)
+	})
+
+	t.Run("No webhook secret", func(t *testing.T) {
+		_, err := payments.NewStripe(mockCard.NewCarder(t), "").ParseWebhook(newWebhook("", "payment_intent.succeeded"))
+		assert.ErrorIs(t, err, payments.ErrInvalidWebhook)
+	})
+}
+
+// paypalServer fakes the PayPal API for a single order in status, recording the
+// requests made to it.
+type paypalServer struct {
+	*httptest.Server
+	status   string
+	verified string
+	tokens   int
+	requests []string
+}
+
+func newPayPalServer(t *testing.T, status string) *paypalServer {
+	s := &paypalServer{status: status, verified: "SUCCESS"}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/oauth2/token" {
+			user, pass, _ := r.BasicAuth()
+			assert.Equal(t, "client:secret", user+":"+pass)
+			s.tokens++
+			fmt.Fprint(w, `{"access_token": "token", "expires_in": 3600}`)
+			return
+		}
+
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+		switch r.URL.Path {
+		case "/v2/checkout/orders":
+			var body struct {
+				Intent string `json:"intent"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "CAPTURE", body.Intent)
+			fmt.Fprint(w, `{"id": "ORDER-1", "status": "CREATED", "links": [
+				{"href": "https://api.paypal.test/v2/checkout/orders/ORDER-1", "rel": "self"},
+				{"href": "https://paypal.test/checkoutnow?token=ORDER-1", "rel": "approve"}]}`)
+		case "/v2/checkout/orders/ORDER-1":
+			fmt.Fprint(w, s.order())
+		case "/v2/checkout/orders/ORDER-1/capture":
+			fmt.Fprint(w, `{"id": "ORDER-1", "status": "COMPLETED", "purchase_units": [
+				{"payments": {"captures": [{"id": "CAP-1", "status": "COMPLETED"}]}}]}`)
+		case "/v2/checkout/orders/ORDER-1/authorize":
+			fmt.Fprint(w, `{"id": "ORDER-1", "status": "COMPLETED", "purchase_units": [
+				{"payments": {"authorizations": [{"id": "AUTH-1", "status": "CREATED"}]}}]}`)
+		case "/v2/payments/authorizations/AUTH-1/capture", "/v2/payments/authorizations/AUTH-1/void":
+			fmt.Fprint(w, `{}`)
+		case "/v1/notifications/verify-webhook-signature":
+			fmt.Fprintf(w, `{"verification_status": %q}`, s.verified)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"name": "RESOURCE_NOT_FOUND", "message": "not found"}`)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// order returns the order as it is in status.
+func (s *paypalServer) order() string {
+	if s.status == "AUTHORIZED" {
+		return `{"id": "ORDER-1", "status": "COMPLETED", "purchase_units": [
+			{"payments": {"authorizations": [{"id": "AUTH-1", "status": "CREATED"}]}}]}`
+	}
+	return fmt.Sprintf(`{"id": "ORDER-1", "status": %q}`, s.status)
+}
+
+func newPayPal(s *paypalServer, manualCapture bool) *payments.PayPal {
+	return payments.NewPayPal(config.PayPal{
+		ClientID: "client", Secret: "secret", URL: s.URL, WebhookID: "WH-1", ManualCapture: manualCapture,
+	})
+}
+
+func TestPayPal(t *testing.T) {
+	t.Run("Order is created for approval", func(t *testing.T) {
+		s := newPayPalServer(t, "CREATED")
+		p := newPayPal(s, false)
+
+		payment, _, err := p.CreatePayment(money.Of(1999))
+		require.NoError(t, err)
+		assert.Equal(t, &payments.Payment{
+			ID: "ORDER-1", Provider: models.PaymentPayPal, Status: models.PaymentRequiresAction,
+			ApprovalURL: "https://paypal.test/checkoutnow?token=ORDER-1",
+		}, payment)
+
+		_, _, err = p.CreatePayment(money.Of(500))
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.tokens, "the access token is reused")
+	})
+
+	t.Run("Approved order is captured", func(t *testing.T) {
+		s := newPayPalServer(t, "APPROVED")
+
+		status, err := newPayPal(s, false).ConfirmPayment("ORDER-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.PaymentSucceeded, status)
+		assert.Contains(t, s.requests, "POST /v2/checkout/orders/ORDER-1/capture")
+	})
+
+	t.Run("Approved order is authorized with manual capture", func(t *testing.T) {
+		s := newPayPalServer(t, "APPROVED")
+
+		status, err := newPayPal(s, true).ConfirmPayment("ORDER-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.PaymentRequiresCapture, status)
+		assert.Contains(t, s.requests, "POST /v2/checkout/orders/ORDER-1/authorize")
+	})
+
+	t.Run("Unapproved order is not captured", func(t *testing.T) {
+		s := newPayPalServer(t, "CREATED")
+
+		status, err := newPayPal(s, false).ConfirmPayment("ORDER-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.PaymentRequiresAction, status)
+		assert.Equal(t, []string{"GET /v2/checkout/orders/ORDER-1"}, s.requests)
+	})
+
+	t.Run("Authorization is captured and voided", func(t *testing.T) {
+		s := newPayPalServer(t, "AUTHORIZED")
+		p := newPayPal(s, true)
+
+		require.NoError(t, p.CapturePayment("ORDER-1"))
+		require.NoError(t, p.VoidPayment("ORDER-1"))
+		assert.Contains(t, s.requests, "POST /v2/payments/authorizations/AUTH-1/capture")
+		assert.Contains(t, s.requests, "POST /v2/payments/authorizations/AUTH-1/void")
+	})
+
+	t.Run("Order without authorization", func(t *testing.T) {
+		s := newPayPalServer(t, "CREATED")
+		p := newPayPal(s, true)
+
+		assert.Error(t, p.CapturePayment("ORDER-1"))
+		assert.NoError(t, p.VoidPayment("ORDER-1"))
+	})
+
+	t.Run("API error", func(t *testing.T) {
+		s := newPayPalServer(t, "CREATED")
+
+		_, err := newPayPal(s, false).ConfirmPayment("ORDER-2")
+		assert.ErrorContains(t, err, "RESOURCE_NOT_FOUND")
+	})
+
+	newWebhook := func(eventType string) *http.Request {
+		payload := fmt.Sprintf(`{"event_type": %q, "resource": {"id": "CAP-1",
+			"supplementary_data": {"related_ids": {"order_id": "ORDER-1"}}}}`, eventType)
+		r := httptest.NewRequest(http.MethodPost, "/payment/webhook/paypal", strings.NewReader(payload))
+		r.Header.Set("PAYPAL-TRANSMISSION-ID", "tx-1")
+		return r
+	}
+
+	t.Run("Webhook reports the payment status", func(t *testing.T) {
+		s := newPayPalServer(t, "CREATED")
+
+		e, err := newPayPal(s, false).ParseWebhook(newWebhook("PAYMENT.CAPTURE.COMPLETED"))
+		require.NoError(t, err)
+		assert.Equal(t, &payments.Event{PaymentID: "ORDER-1", Status: models.PaymentSucceeded}, e)
+	})
+
+	t.Run("Other events are ignored", func(t *testing.T) {
+		s := newPayPalServer(t, "CREATED")
+
+		e, err := newPayPal(s, false).ParseWebhook(newWebhook("CHECKOUT.ORDER.APPROVED"))
+		require.NoError(t, err)
+		assert.Nil(t, e)
+	})
+
+	t.Run("Unverified webhook", func(t *testing.T) {
+		s := newPayPalServer(t, "CREATED")
+		s.verified = "FAILURE"
+
+		_, err := newPayPal(s, false).ParseWebhook(newWebhook("PAYMENT.CAPTURE.COMPLETED"))
+		assert.ErrorIs(t, err, payments.ErrInvalidWebhook)
+	})
+}
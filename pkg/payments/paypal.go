@@ -0,0 +1,394 @@
+package payments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/money"
+)
+
+// DefaultPayPalURL is the PayPal sandbox API, used when no URL is configured.
+const DefaultPayPalURL = "https://api-m.sandbox.paypal.com"
+
+// paypalTimeout bounds a request to the PayPal API.
+const paypalTimeout = 10 * time.Second
+
+// maxErrorSize caps the error read from a failed PayPal API response.
+const maxErrorSize = 64 << 10
+
+// paypalEvents gives the payment status each PayPal webhook event reports.
+var paypalEvents = map[string]string{
+	"PAYMENT.AUTHORIZATION.CREATED": models.PaymentRequiresCapture,
+	"PAYMENT.AUTHORIZATION.VOIDED":  models.PaymentCanceled,
+	"PAYMENT.CAPTURE.COMPLETED":     models.PaymentSucceeded,
+	"PAYMENT.CAPTURE.DENIED":        models.PaymentFailed,
+}
+
+// PayPal is a Provider charging PayPal orders through the Orders v2 API. The customer
+// approves the order at its approval URL, then ConfirmPayment captures it, or only
+// authorizes it with manual capture. Payments are identified by the PayPal order id.
+type PayPal struct {
+	url           string
+	clientID      string
+	secret        string
+	webhookID     string
+	manualCapture bool
+	client        *http.Client
+	now           func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewPayPal returns the PayPal provider configured by cfg.
+func NewPayPal(cfg config.PayPal) *PayPal {
+	base := strings.TrimSuffix(cfg.URL, "/")
+	if base == "" {
+		base = DefaultPayPalURL
+	}
+
+	return &PayPal{
+		url:           base,
+		clientID:      cfg.ClientID,
+		secret:        cfg.Secret,
+		webhookID:     cfg.WebhookID,
+		manualCapture: cfg.ManualCapture,
+		client:        &http.Client{Timeout: paypalTimeout},
+		now:           time.Now,
+	}
+}
+
+// paypalPayment is a capture or an authorization of a PayPal order.
+type paypalPayment struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// paypalOrder is a PayPal order, with the payments made for it.
+type paypalOrder struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Links  []struct {
+		Href string `json:"href"`
+		Rel  string `json:"rel"`
+	} `json:"links"`
+	PurchaseUnits []struct {
+		Payments struct {
+			Authorizations []paypalPayment `json:"authorizations"`
+			Captures       []paypalPayment `json:"captures"`
+		} `json:"payments"`
+	} `json:"purchase_units"`
+}
+
+// authorization returns the authorization of the order, nil when it has none.
+func (o *paypalOrder) authorization() *paypalPayment {
+	for _, u := range o.PurchaseUnits {
+		if n := len(u.Payments.Authorizations); n > 0 {
+			return &u.Payments.Authorizations[n-1]
+		}
+	}
+
+	return nil
+}
+
+// status maps the state of the order and its payments to a payment status.
+func (o *paypalOrder) status() string {
+	for _, u := range o.PurchaseUnits {
+		if n := len(u.Payments.Captures); n > 0 {
+			switch u.Payments.Captures[n-1].Status {
+			case "COMPLETED", "REFUNDED", "PARTIALLY_REFUNDED":
+				return models.PaymentSucceeded
+			case "DECLINED", "FAILED":
+				return models.PaymentFailed
+			default:
+				return models.PaymentProcessing
+			}
+		}
+	}
+
+	if a := o.authorization(); a != nil {
+		switch a.Status {
+		case "CREATED", "PARTIALLY_CAPTURED":
+			return models.PaymentRequiresCapture
+		case "CAPTURED":
+			return models.PaymentSucceeded
+		case "DENIED":
+			return models.PaymentFailed
+		case "VOIDED", "EXPIRED":
+			return models.PaymentCanceled
+		default:
+			return models.PaymentProcessing
+		}
+	}
+
+	if o.Status == "VOIDED" {
+		return models.PaymentCanceled
+	}
+
+	return models.PaymentRequiresAction
+}
+
+// Name returns "paypal".
+func (p *PayPal) Name() string {
+	return models.PaymentPayPal
+}
+
+// CreatePayment creates a PayPal order for amount; the customer approves it at the
+// ApprovalURL of the payment.
+func (p *PayPal) CreatePayment(amount money.Money) (*Payment, string, error) {
+	intent := "CAPTURE"
+	if p.manualCapture {
+		intent = "AUTHORIZE"
+	}
+
+	currency := amount.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	body := map[string]any{
+		"intent": intent,
+		"purchase_units": []map[string]any{{
+			"amount": map[string]string{"currency_code": currency, "value": amount.Decimal()},
+		}},
+	}
+
+	var order paypalOrder
+	if err := p.do(http.MethodPost, "/v2/checkout/orders", body, &order); err != nil {
+		return nil, "Your PayPal payment could not be started", err
+	}
+
+	payment := Payment{ID: order.ID, Provider: models.PaymentPayPal, Status: order.status()}
+	for _, l := range order.Links {
+		if l.Rel == "approve" || l.Rel == "payer-action" {
+			payment.ApprovalURL = l.Href
+		}
+	}
+
+	return &payment, "", nil
+}
+
+// ConfirmPayment captures the approved order id, or authorizes it with manual
+// capture, and returns its status. An order already captured or authorized is left
+// as it is.
+func (p *PayPal) ConfirmPayment(id string) (string, error) {
+	order, err := p.order(id)
+	if err != nil {
+		return "", err
+	}
+
+	if order.Status != "APPROVED" {
+		return order.status(), nil
+	}
+
+	action := "capture"
+	if p.manualCapture {
+		action = "authorize"
+	}
+
+	if err := p.do(http.MethodPost, "/v2/checkout/orders/"+url.PathEscape(id)+"/"+action, struct{}{}, order); err != nil {
+		return "", err
+	}
+
+	return order.status(), nil
+}
+
+// CapturePayment captures the amount authorized for the order id.
+func (p *PayPal) CapturePayment(id string) error {
+	order, err := p.order(id)
+	if err != nil {
+		return err
+	}
+
+	a := order.authorization()
+	if a == nil {
+		return fmt.Errorf("paypal order %s has no authorization to capture", id)
+	}
+
+	return p.do(http.MethodPost, "/v2/payments/authorizations/"+url.PathEscape(a.ID)+"/capture", struct{}{}, nil)
+}
+
+// VoidPayment voids the authorization of the order id. An order the customer never
+// approved has nothing to void and expires on its own.
+func (p *PayPal) VoidPayment(id string) error {
+	order, err := p.order(id)
+	if err != nil {
+		return err
+	}
+
+	a := order.authorization()
+	if a == nil {
+		return nil
+	}
+
+	return p.do(http.MethodPost, "/v2/payments/authorizations/"+url.PathEscape(a.ID)+"/void", struct{}{}, nil)
+}
+
+// ParseWebhook has PayPal verify the signature of a webhook request and returns the
+// status change of the order it reports.
+func (p *PayPal) ParseWebhook(r *http.Request) (*Event, error) {
+	if p.webhookID == "" {
+		return nil, fmt.Errorf("%w: no paypal webhook id is configured", ErrInvalidWebhook)
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookSize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading webhook: %v", err)
+	}
+
+	if !json.Valid(payload) {
+		return nil, fmt.Errorf("%w: payload is not json", ErrInvalidWebhook)
+	}
+
+	verification := map[string]any{
+		"auth_algo":         r.Header.Get("PAYPAL-AUTH-ALGO"),
+		"cert_url":          r.Header.Get("PAYPAL-CERT-URL"),
+		"transmission_id":   r.Header.Get("PAYPAL-TRANSMISSION-ID"),
+		"transmission_sig":  r.Header.Get("PAYPAL-TRANSMISSION-SIG"),
+		"transmission_time": r.Header.Get("PAYPAL-TRANSMISSION-TIME"),
+		"webhook_id":        p.webhookID,
+		"webhook_event":     json.RawMessage(payload),
+	}
+	var verified struct {
+		Status string `json:"verification_status"`
+	}
+	if err := p.do(http.MethodPost, "/v1/notifications/verify-webhook-signature", verification, &verified); err != nil {
+		return nil, fmt.Errorf("error verifying webhook: %v", err)
+	}
+	if verified.Status != "SUCCESS" {
+		return nil, fmt.Errorf("%w: signature verification %s", ErrInvalidWebhook, strings.ToLower(verified.Status))
+	}
+
+	var event struct {
+		Type     string `json:"event_type"`
+		Resource struct {
+			SupplementaryData struct {
+				RelatedIDs struct {
+					OrderID string `json:"order_id"`
+				} `json:"related_ids"`
+			} `json:"supplementary_data"`
+		} `json:"resource"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	status, ok := paypalEvents[event.Type]
+	if !ok || event.Resource.SupplementaryData.RelatedIDs.OrderID == "" {
+		return nil, nil
+	}
+
+	return &Event{PaymentID: event.Resource.SupplementaryData.RelatedIDs.OrderID, Status: status}, nil
+}
+
+// order fetches the PayPal order id.
+func (p *PayPal) order(id string) (*paypalOrder, error) {
+	var order paypalOrder
+	if err := p.do(http.MethodGet, "/v2/checkout/orders/"+url.PathEscape(id), nil, &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// do sends a JSON request to the PayPal API and decodes the response into out,
+// unless it is nil.
+func (p *PayPal) do(method, path string, in, out any) error {
+	token, err := p.accessToken()
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, p.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("paypal: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var e struct {
+			Name    string `json:"name"`
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(io.LimitReader(res.Body, maxErrorSize)).Decode(&e)
+		return fmt.Errorf("paypal: %s %s responded %s: %s %s", method, path, res.Status, e.Name, e.Message)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("paypal: decoding %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// accessToken returns an OAuth access token for the API, requesting a new one a
+// minute before the current one expires.
+func (p *PayPal) accessToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && p.now().Before(p.expires) {
+		return p.token, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.url+"/v1/oauth2/token",
+		strings.NewReader(url.Values{"grant_type": {"client_credentials"}}.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(p.clientID, p.secret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("paypal: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("paypal: requesting an access token responded %s", res.Status)
+	}
+
+	var t struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&t); err != nil {
+		return "", fmt.Errorf("paypal: decoding access token: %v", err)
+	}
+
+	p.token = t.AccessToken
+	p.expires = p.now().Add(time.Duration(t.ExpiresIn)*time.Second - time.Minute)
+
+	return p.token, nil
+}
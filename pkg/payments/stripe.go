@@ -0,0 +1,108 @@
+package payments
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/card"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/webhook"
+)
+
+// stripeEvents gives the payment status each Stripe webhook event reports.
+var stripeEvents = map[string]string{
+	"payment_intent.amount_capturable_updated": models.PaymentRequiresCapture,
+	"payment_intent.succeeded":                 models.PaymentSucceeded,
+	"payment_intent.canceled":                  models.PaymentCanceled,
+	"payment_intent.payment_failed":            models.PaymentFailed,
+}
+
+// Stripe is a Provider charging cards with Stripe payment intents. The customer
+// confirms the intent in the browser with its client secret.
+type Stripe struct {
+	card          card.Carder
+	webhookSecret string
+}
+
+// NewStripe returns a Stripe provider charging through card. Webhooks are verified
+// with webhookSecret; without one they are rejected.
+func NewStripe(card card.Carder, webhookSecret string) *Stripe {
+	return &Stripe{card: card, webhookSecret: webhookSecret}
+}
+
+// Name returns "stripe".
+func (s *Stripe) Name() string {
+	return models.PaymentStripe
+}
+
+// CreatePayment creates a payment intent for amount.
+func (s *Stripe) CreatePayment(amount money.Money) (*Payment, string, error) {
+	pi, msg, err := s.card.CreatePaymentIntent(amount)
+	if err != nil {
+		return nil, msg, err
+	}
+
+	return &Payment{
+		ID:           pi.ID,
+		Provider:     models.PaymentStripe,
+		Status:       string(pi.Status),
+		ClientSecret: pi.ClientSecret,
+	}, "", nil
+}
+
+// ConfirmPayment returns the status of the payment intent id, which the customer
+// confirmed in the browser.
+func (s *Stripe) ConfirmPayment(id string) (string, error) {
+	pi, err := s.card.GetPaymentIntent(id)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pi.Status), nil
+}
+
+// CapturePayment captures the payment intent id.
+func (s *Stripe) CapturePayment(id string) error {
+	_, err := s.card.CapturePayment(id)
+	return err
+}
+
+// VoidPayment cancels the payment intent id.
+func (s *Stripe) VoidPayment(id string) error {
+	_, err := s.card.VoidPayment(id)
+	return err
+}
+
+// ParseWebhook verifies the Stripe-Signature of a webhook request and returns the
+// status change of the payment intent it reports.
+func (s *Stripe) ParseWebhook(r *http.Request) (*Event, error) {
+	if s.webhookSecret == "" {
+		return nil, fmt.Errorf("%w: no stripe webhook secret is configured", ErrInvalidWebhook)
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookSize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading webhook: %v", err)
+	}
+
+	event, err := webhook.ConstructEvent(payload, r.Header.Get("Stripe-Signature"), s.webhookSecret)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	status, ok := stripeEvents[event.Type]
+	if !ok {
+		return nil, nil
+	}
+
+	var pi stripe.PaymentIntent
+	if err := json.Unmarshal(event.Data.Raw, &pi); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	return &Event{PaymentID: pi.ID, Status: status}, nil
+}
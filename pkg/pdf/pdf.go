@@ -0,0 +1,158 @@
+// Package pdf writes simple text documents as PDF, enough for printable
+// paperwork such as packing slips without pulling in a layout engine.
+//
+// Headings are set in Helvetica Bold and lines in Courier, so columns padded
+// with spaces stay aligned. Pages are A4 and break automatically.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	pageWidth   = 595
+	pageHeight  = 842
+	margin      = 50
+	headingSize = 14
+	lineSize    = 10
+	leading     = 1.4
+)
+
+type line struct {
+	text    string
+	heading bool
+}
+
+func (l line) size() float64 {
+	if l.heading {
+		return headingSize
+	}
+	return lineSize
+}
+
+// Document is a PDF being built line by line.
+type Document struct {
+	title string
+	pages [][]line
+	y     float64
+}
+
+// New returns an empty document with the given title.
+func New(title string) *Document {
+	d := &Document{title: title}
+	d.PageBreak()
+	return d
+}
+
+// Heading adds a bold line.
+func (d *Document) Heading(s string) {
+	d.add(line{text: s, heading: true})
+}
+
+// Line adds a line of fixed-width text.
+func (d *Document) Line(s string) {
+	d.add(line{text: s})
+}
+
+// Blank adds an empty line.
+func (d *Document) Blank() {
+	d.add(line{})
+}
+
+// PageBreak starts a new page.
+func (d *Document) PageBreak() {
+	d.pages = append(d.pages, nil)
+	d.y = pageHeight - margin
+}
+
+func (d *Document) add(l line) {
+	step := l.size() * leading
+	if d.y-step < margin {
+		d.PageBreak()
+	}
+	d.y -= step
+	d.pages[len(d.pages)-1] = append(d.pages[len(d.pages)-1], l)
+}
+
+// WriteTo writes the document as PDF to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// objects 1-5 are fixed, each page then takes a page and a content object
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	obj(fmt.Sprintf("<< /Title (%s) /Producer (ShopIT) >>", escape(d.title)))
+
+	for i, page := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 7+2*i))
+
+		content := pageContent(page)
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// pageContent returns the content stream drawing the lines of one page.
+func pageContent(page []line) string {
+	var b strings.Builder
+	y := float64(pageHeight - margin)
+	for _, l := range page {
+		y -= l.size() * leading
+		if l.text == "" {
+			continue
+		}
+		font := "F2"
+		if l.heading {
+			font = "F1"
+		}
+		fmt.Fprintf(&b, "BT /%s %.0f Tf %d %.1f Td (%s) Tj ET\n", font, l.size(), margin, y, escape(l.text))
+	}
+	return b.String()
+}
+
+// escape makes s safe inside a PDF string literal. Characters outside Latin-1
+// cannot be drawn with the standard fonts and are replaced.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0xff:
+			b.WriteByte('?')
+		case r > 0x7e:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/pkg/pdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentWriteTo(t *testing.T) {
+	d := pdf.New("Packing slip")
+	d.Heading("Packing slip (order 1)")
+	d.Line(`C:\path`)
+	for i := 0; i < 80; i++ {
+		d.Line(fmt.Sprintf("line %d", i))
+	}
+
+	var buf bytes.Buffer
+	_, err := d.WriteTo(&buf)
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(out, "%%EOF\n"))
+	assert.Contains(t, out, `(Packing slip \(order 1\)) Tj`)
+	assert.Contains(t, out, `(C:\\path) Tj`)
+	assert.Contains(t, out, "/Count 2")
+}
@@ -0,0 +1,41 @@
+// Package pseudonym replaces identifiers with stable pseudonyms, so data can be shared
+// without the identifiers it was recorded with.
+//
+// A pseudonym is a keyed hash (HMAC-SHA256) of the identifier: the same identifier
+// always gets the same pseudonym under a key, so records stay linkable, while the
+// identifier cannot be recovered or guessed without the key.
+package pseudonym
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// size is how many bytes of the hash a pseudonym keeps.
+const size = 16
+
+// Hasher derives pseudonyms with a secret key.
+type Hasher struct {
+	key []byte
+}
+
+// New returns a Hasher keyed with key, or nil when key is empty.
+func New(key string) *Hasher {
+	if key == "" {
+		return nil
+	}
+
+	return &Hasher{key: []byte(key)}
+}
+
+// Of returns the pseudonym of the identifier id of kind, such as "user" or "order".
+// The kind keeps identifiers of different kinds from sharing a pseudonym.
+func (h *Hasher) Of(kind, id string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(id))
+
+	return hex.EncodeToString(mac.Sum(nil)[:size])
+}
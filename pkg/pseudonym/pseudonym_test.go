@@ -0,0 +1,21 @@
+package pseudonym_test
+
+import (
+	"testing"
+
+	"github.com/jofosuware/go/shopit/pkg/pseudonym"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasher(t *testing.T) {
+	assert.Nil(t, pseudonym.New(""))
+
+	h := pseudonym.New("secret")
+	id := "0b7c3c5e-2f4f-4f5e-9a59-3f1d2c1e8a10"
+
+	assert.Len(t, h.Of("user", id), 32)
+	assert.Equal(t, h.Of("user", id), pseudonym.New("secret").Of("user", id))
+	assert.NotEqual(t, h.Of("user", id), h.Of("order", id))
+	assert.NotEqual(t, h.Of("user", id), pseudonym.New("other").Of("user", id))
+	assert.NotContains(t, h.Of("user", id), id)
+}
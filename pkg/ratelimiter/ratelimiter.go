@@ -2,36 +2,69 @@ package ratelimiter
 
 import (
 	"fmt"
-	"github.com/jofosuware/go/shopit/pkg/utils"
 	"net/http"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/jofosuware/go/shopit/pkg/realip"
+	"github.com/jofosuware/go/shopit/pkg/utils"
 
 	"golang.org/x/time/rate"
 )
 
 type RateLimiter struct {
-	visitors map[string]*rate.Limiter
+	visitors map[string]*visitor
 	mu       sync.Mutex
 	rate     rate.Limit
 	burst    int
 }
 
+// visitor holds the limiter for a single client along with bookkeeping
+// exposed to admins through Snapshot.
+type visitor struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+	blocked  int
+}
+
+// VisitorState is a point-in-time view of a visitor's limiter.
+type VisitorState struct {
+	Key      string    `json:"key"`
+	Tokens   float64   `json:"tokens"`
+	Blocked  int       `json:"blocked"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
 // NewRateLimiter creates a new RateLimiter instance
 func NewRateLimiter(r rate.Limit, b int) *RateLimiter {
 	return &RateLimiter{
-		visitors: make(map[string]*rate.Limiter),
+		visitors: make(map[string]*visitor),
 		rate:     r,
 		burst:    b,
 	}
 }
 
+// SetLimit changes the rate and burst of every visitor, current and future.
+func (rl *RateLimiter) SetLimit(r rate.Limit, b int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.rate, rl.burst = r, b
+	for _, v := range rl.visitors {
+		v.limiter.SetLimit(r)
+		v.limiter.SetBurst(b)
+	}
+}
+
 // AddVisitor adds a new visitor with a rate limiter
 func (rl *RateLimiter) AddVisitor(ip string) *rate.Limiter {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
 	limiter := rate.NewLimiter(rl.rate, rl.burst)
-	rl.visitors[ip] = limiter
+	rl.visitors[ip] = &visitor{limiter: limiter, lastSeen: time.Now()}
+
 	return limiter
 }
 
@@ -40,22 +73,89 @@ func (rl *RateLimiter) GetLimiter(ip string) *rate.Limiter {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	limiter, exists := rl.visitors[ip]
+	return rl.visitor(ip).limiter
+}
+
+// visitor returns the visitor for key, creating it if needed. Callers must hold rl.mu.
+func (rl *RateLimiter) visitor(key string) *visitor {
+	v, exists := rl.visitors[key]
 	if !exists {
-		limiter = rate.NewLimiter(rl.rate, rl.burst)
-		rl.visitors[ip] = limiter
+		v = &visitor{limiter: rate.NewLimiter(rl.rate, rl.burst)}
+		rl.visitors[key] = v
 	}
-	return limiter
+
+	v.lastSeen = time.Now()
+
+	return v
+}
+
+// allow reports whether a request from key may proceed, recording blocked requests.
+func (rl *RateLimiter) allow(key string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	v := rl.visitor(key)
+	if v.limiter.Allow() {
+		return true
+	}
+
+	v.blocked++
+
+	return false
+}
+
+// Snapshot returns the state of every tracked visitor, most recently seen first.
+func (rl *RateLimiter) Snapshot() []VisitorState {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	states := make([]VisitorState, 0, len(rl.visitors))
+
+	for key, v := range rl.visitors {
+		states = append(states, VisitorState{
+			Key:      key,
+			Tokens:   v.limiter.TokensAt(now),
+			Blocked:  v.blocked,
+			LastSeen: v.lastSeen,
+		})
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].LastSeen.After(states[j].LastSeen)
+	})
+
+	return states
+}
+
+// Clear forgets the visitor identified by key, resetting its limit.
+// It reports whether the visitor was being tracked.
+func (rl *RateLimiter) Clear(key string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	_, exists := rl.visitors[key]
+	delete(rl.visitors, key)
+
+	return exists
+}
+
+// ClearAll forgets every visitor and returns how many were cleared.
+func (rl *RateLimiter) ClearAll() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	n := len(rl.visitors)
+	rl.visitors = make(map[string]*visitor)
+
+	return n
 }
 
 // Middleware for rate limiting
 func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := r.RemoteAddr
-		limiter := rl.GetLimiter(ip)
-
-		if !limiter.Allow() {
-			_ = utils.TooManyRequests(w)
+		if !rl.allow(realip.FromRequest(r)) {
+			_ = utils.TooManyRequests(w, r)
 			fmt.Println("Too many requests")
 			return
 		}
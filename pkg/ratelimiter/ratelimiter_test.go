@@ -97,3 +97,76 @@ func TestRateLimiterMiddleware(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rr.Code)
 	})
 }
+
+func TestSnapshot(t *testing.T) {
+	rl := ratelimiter.NewRateLimiter(1, 1)
+
+	handler := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Same client on another port shares the limiter and is blocked
+	req.RemoteAddr = "10.0.0.1:5678"
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	states := rl.Snapshot()
+	require.Len(t, states, 1)
+	assert.Equal(t, "10.0.0.1", states[0].Key)
+	assert.Equal(t, 1, states[0].Blocked)
+	assert.False(t, states[0].LastSeen.IsZero())
+}
+
+func TestClear(t *testing.T) {
+	t.Run("clears a single visitor", func(t *testing.T) {
+		rl := ratelimiter.NewRateLimiter(1, 1)
+		rl.AddVisitor("192.168.1.1")
+		rl.AddVisitor("192.168.1.2")
+
+		assert.True(t, rl.Clear("192.168.1.1"))
+		assert.False(t, rl.Clear("192.168.1.1"))
+		assert.Len(t, rl.Snapshot(), 1)
+	})
+
+	t.Run("cleared visitor is allowed again", func(t *testing.T) {
+		rl := ratelimiter.NewRateLimiter(1, 1)
+		handler := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		rl.Clear("192.0.2.1")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("clears all visitors", func(t *testing.T) {
+		rl := ratelimiter.NewRateLimiter(1, 1)
+		rl.AddVisitor("192.168.1.1")
+		rl.AddVisitor("192.168.1.2")
+
+		assert.Equal(t, 2, rl.ClearAll())
+		assert.Empty(t, rl.Snapshot())
+	})
+}
+
+func TestSetLimit(t *testing.T) {
+	rl := ratelimiter.NewRateLimiter(1, 1)
+	existing := rl.AddVisitor("192.168.1.1")
+
+	rl.SetLimit(10, 20)
+
+	assert.Equal(t, 20, existing.Burst())
+	assert.EqualValues(t, 10, existing.Limit())
+	assert.Equal(t, 20, rl.GetLimiter("192.168.1.2").Burst())
+}
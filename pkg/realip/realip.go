@@ -0,0 +1,176 @@
+// Package realip resolves the IP address of the client a request comes from when the
+// API runs behind proxies, such as the Render or Heroku router or NGINX, which pass
+// the address of the client in the X-Forwarded-For or X-Real-IP header.
+//
+// Anyone can send those headers, so they are only believed when the peer of the
+// connection is a trusted proxy; otherwise the peer is the client. X-Forwarded-For is
+// read from the right, each proxy appending the address it got the request from, and
+// the first address that is not a trusted proxy is the client. X-Real-IP is used when
+// there is no X-Forwarded-For. Likewise, the X-Forwarded-Proto header of a trusted
+// proxy tells whether the client connected over https.
+//
+// Middleware resolves the address once per request; the rate limiter, the logs and
+// whatever else records where a request came from read it with FromRequest, and
+// cookies are marked secure as Secure tells.
+package realip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type contextKey string
+
+const (
+	ipContextKey     contextKey = "realip"
+	secureContextKey contextKey = "realip_secure"
+)
+
+// Resolver resolves client addresses, believing the headers of its trusted proxies.
+type Resolver struct {
+	trusted []*net.IPNet
+}
+
+// New returns a Resolver trusting proxies, each an IP address such as "10.0.0.1" or a
+// CIDR range such as "10.0.0.0/8". With no proxies the headers are never believed.
+func New(proxies []string) (*Resolver, error) {
+	rs := &Resolver{}
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("trusted proxy %q must be an IP address or a CIDR range (server.trustedProxies)", p)
+			}
+			bits := 8 * len(ip.To4())
+			if bits == 0 {
+				bits = 8 * net.IPv6len
+			}
+			p = fmt.Sprintf("%s/%d", p, bits)
+		}
+
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q must be an IP address or a CIDR range (server.trustedProxies)", p)
+		}
+		rs.trusted = append(rs.trusted, n)
+	}
+
+	return rs, nil
+}
+
+// Resolve returns the address of the client r comes from.
+func (rs *Resolver) Resolve(r *http.Request) string {
+	peer := host(r.RemoteAddr)
+	if !rs.trusts(net.ParseIP(peer)) {
+		return peer
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				// a malformed hop was not added by a trusted proxy, so the last trusted
+				// address is the furthest that can be believed
+				break
+			}
+			client = ip.String()
+			if !rs.trusts(ip) {
+				break
+			}
+		}
+
+		return client
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+
+	return peer
+}
+
+// ResolveSecure reports whether the client of r connected over https: to the API, or
+// to the trusted proxy r comes from, as its X-Forwarded-Proto header tells.
+func (rs *Resolver) ResolveSecure(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	if !rs.trusts(net.ParseIP(host(r.RemoteAddr))) {
+		return false
+	}
+
+	// a proxy that appends to the header of the client adds the scheme it saw last
+	values := r.Header.Values("X-Forwarded-Proto")
+	if len(values) == 0 {
+		return false
+	}
+	protos := strings.Split(values[len(values)-1], ",")
+	proto := protos[len(protos)-1]
+
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
+
+// trusts reports whether ip is one of the trusted proxies.
+func (rs *Resolver) trusts(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range rs.trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Middleware resolves the client address of the request for FromRequest, and whether
+// it is secure for Secure.
+func (rs *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), ipContextKey, rs.Resolve(r))
+		ctx = context.WithValue(ctx, secureContextKey, rs.ResolveSecure(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// FromRequest returns the client address resolved by Middleware, or the peer of the
+// connection for a request that did not go through it.
+func FromRequest(r *http.Request) string {
+	if ip, ok := r.Context().Value(ipContextKey).(string); ok {
+		return ip
+	}
+
+	return host(r.RemoteAddr)
+}
+
+// Secure reports whether the client of r connected over https, as resolved by
+// Middleware, or whether the connection is TLS for a request that did not go through it.
+func Secure(r *http.Request) bool {
+	if secure, ok := r.Context().Value(secureContextKey).(bool); ok {
+		return secure
+	}
+
+	return r.TLS != nil
+}
+
+// host returns the host part of addr, or addr when it has no port.
+func host(addr string) string {
+	h, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return h
+}
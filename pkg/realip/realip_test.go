@@ -0,0 +1,119 @@
+package realip_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/pkg/realip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	_, err := realip.New([]string{"10.0.0.0/8", "203.0.113.7", "2001:db8::1", " "})
+	assert.NoError(t, err)
+
+	_, err = realip.New([]string{"render"})
+	assert.Error(t, err)
+
+	_, err = realip.New([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	rs, err := realip.New([]string{"10.0.0.0/8", "192.0.2.10"})
+	require.NoError(t, err)
+
+	request := func(peer string, headers map[string]string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = peer
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	tests := []struct {
+		name    string
+		peer    string
+		headers map[string]string
+		want    string
+	}{
+		{"no proxy", "198.51.100.4:5000", nil, "198.51.100.4"},
+		{"headers of an untrusted peer are ignored", "198.51.100.4:5000",
+			map[string]string{"X-Forwarded-For": "203.0.113.1", "X-Real-IP": "203.0.113.2"}, "198.51.100.4"},
+		{"forwarded by a trusted proxy", "10.1.2.3:5000", map[string]string{"X-Forwarded-For": "203.0.113.1"}, "203.0.113.1"},
+		{"trusted proxies are skipped", "10.1.2.3:5000",
+			map[string]string{"X-Forwarded-For": "203.0.113.1, 192.0.2.10, 10.4.5.6"}, "203.0.113.1"},
+		{"addresses spoofed by the client are ignored", "10.1.2.3:5000",
+			map[string]string{"X-Forwarded-For": "1.1.1.1, 203.0.113.1"}, "203.0.113.1"},
+		{"malformed hop", "10.1.2.3:5000", map[string]string{"X-Forwarded-For": "203.0.113.1, unknown, 10.4.5.6"}, "10.4.5.6"},
+		{"real ip header", "10.1.2.3:5000", map[string]string{"X-Real-IP": "203.0.113.1"}, "203.0.113.1"},
+		{"trusted proxy without headers", "10.1.2.3:5000", nil, "10.1.2.3"},
+		{"peer without port", "198.51.100.4", nil, "198.51.100.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rs.Resolve(request(tt.peer, tt.headers)))
+		})
+	}
+}
+
+func TestResolveSecure(t *testing.T) {
+	rs, err := realip.New([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		peer  string
+		proto string
+		want  bool
+	}{
+		{"plain http", "198.51.100.4:5000", "", false},
+		{"header of an untrusted peer is ignored", "198.51.100.4:5000", "https", false},
+		{"https at a trusted proxy", "10.1.2.3:5000", "https", true},
+		{"http at a trusted proxy", "10.1.2.3:5000", "http", false},
+		{"scheme spoofed by the client is ignored", "10.1.2.3:5000", "https, http", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.peer
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			assert.Equal(t, tt.want, rs.ResolveSecure(req))
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	rs, err := realip.New([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	var (
+		got    string
+		secure bool
+	)
+	handler := rs.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = realip.FromRequest(r)
+		secure = realip.Secure(r)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.1.2.3:5000"
+	req.Header.Set("X-Forwarded-For", "203.0.113.1")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "203.0.113.1", got)
+	assert.True(t, secure)
+
+	t.Run("Without the middleware the peer is the client", func(t *testing.T) {
+		assert.Equal(t, "10.1.2.3", realip.FromRequest(req))
+		assert.False(t, realip.Secure(req))
+	})
+}
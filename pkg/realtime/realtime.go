@@ -0,0 +1,178 @@
+// Package realtime pushes events to clients as they happen, over server-sent events.
+//
+// A Hub fans the events published on a topic out to its subscribers, in process:
+// subscribers only receive the events published by the instance they are connected
+// to. A Stream writes events to a client as a text/event-stream response.
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// KeepAlive is how often a Stream without events is written a comment, so proxies do
+// not close it as idle.
+const KeepAlive = 15 * time.Second
+
+// subscriptionBuffer is how many events a subscriber can fall behind before the next
+// ones are dropped.
+const subscriptionBuffer = 16
+
+// Event is a named event with its data, written to clients as JSON.
+type Event struct {
+	Name string
+	Data interface{}
+}
+
+// Subscription receives the events of a topic on C until it is closed.
+type Subscription struct {
+	C <-chan Event
+
+	c     chan Event
+	topic string
+	hub   *Hub
+}
+
+// Close stops the subscription and closes C. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+// Hub fans events out to the subscribers of their topic.
+type Hub struct {
+	mu     sync.Mutex
+	topics map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+// NewHub returns a Hub without subscribers.
+func NewHub() *Hub {
+	return &Hub{topics: make(map[string]map[*Subscription]struct{})}
+}
+
+// Subscribe returns a subscription to the events published on topic. Once the hub
+// is closed, the subscription is returned closed.
+func (h *Hub) Subscribe(topic string) *Subscription {
+	c := make(chan Event, subscriptionBuffer)
+	s := &Subscription{C: c, c: c, topic: topic, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(c)
+		return s
+	}
+
+	if h.topics[topic] == nil {
+		h.topics[topic] = make(map[*Subscription]struct{})
+	}
+	h.topics[topic][s] = struct{}{}
+
+	return s
+}
+
+// Publish sends e to the subscribers of topic without waiting for them; a subscriber
+// that has fallen subscriptionBuffer events behind misses e.
+func (h *Hub) Publish(topic string, e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.topics[topic] {
+		select {
+		case s.c <- e:
+		default:
+		}
+	}
+}
+
+// Close closes every subscription, so streams end and the server can shut down.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for topic, subs := range h.topics {
+		for s := range subs {
+			close(s.c)
+		}
+		delete(h.topics, topic)
+	}
+}
+
+// remove closes s when it is still subscribed.
+func (h *Hub) remove(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs := h.topics[s.topic]
+	if _, ok := subs[s]; !ok {
+		return
+	}
+
+	close(s.c)
+	delete(subs, s)
+	if len(subs) == 0 {
+		delete(h.topics, s.topic)
+	}
+}
+
+// Stream writes events to a client as server-sent events.
+type Stream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// NewStream starts a text/event-stream response on w. The write deadline of the
+// server is lifted, so the stream lasts until the client or the handler ends it.
+func NewStream(w http.ResponseWriter) (*Stream, error) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	s := &Stream{w: w, rc: rc}
+
+	return s, s.flush()
+}
+
+// Send writes e to the client.
+func (s *Stream) Send(e Event) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return fmt.Errorf("error encoding event: %v", err)
+	}
+
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", e.Name, data); err != nil {
+		return err
+	}
+
+	return s.flush()
+}
+
+// Ping writes a comment to the client, which clients ignore.
+func (s *Stream) Ping() error {
+	if _, err := fmt.Fprint(s.w, ": ping\n\n"); err != nil {
+		return err
+	}
+
+	return s.flush()
+}
+
+func (s *Stream) flush() error {
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
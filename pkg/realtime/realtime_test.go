@@ -0,0 +1,75 @@
+package realtime_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/pkg/realtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub(t *testing.T) {
+	t.Run("Events reach the subscribers of their topic", func(t *testing.T) {
+		hub := realtime.NewHub()
+		a, b, other := hub.Subscribe("order-1"), hub.Subscribe("order-1"), hub.Subscribe("order-2")
+
+		hub.Publish("order-1", realtime.Event{Name: "status", Data: "Shipped"})
+
+		assert.Equal(t, realtime.Event{Name: "status", Data: "Shipped"}, <-a.C)
+		assert.Equal(t, realtime.Event{Name: "status", Data: "Shipped"}, <-b.C)
+		assert.Empty(t, other.C)
+	})
+
+	t.Run("Closed subscription gets no more events", func(t *testing.T) {
+		hub := realtime.NewHub()
+		sub := hub.Subscribe("order-1")
+		sub.Close()
+		sub.Close()
+
+		hub.Publish("order-1", realtime.Event{Name: "status"})
+
+		_, ok := <-sub.C
+		assert.False(t, ok)
+	})
+
+	t.Run("Slow subscriber misses events instead of blocking", func(t *testing.T) {
+		hub := realtime.NewHub()
+		sub := hub.Subscribe("order-1")
+
+		for i := 0; i < 100; i++ {
+			hub.Publish("order-1", realtime.Event{Name: "status", Data: i})
+		}
+
+		assert.Equal(t, 0, (<-sub.C).Data)
+		assert.Less(t, len(sub.C), 100)
+	})
+
+	t.Run("Closing the hub ends every subscription", func(t *testing.T) {
+		hub := realtime.NewHub()
+		sub := hub.Subscribe("order-1")
+
+		hub.Close()
+		_, ok := <-sub.C
+		assert.False(t, ok)
+
+		_, ok = <-hub.Subscribe("order-2").C
+		assert.False(t, ok)
+		sub.Close()
+	})
+}
+
+func TestStream(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	stream, err := realtime.NewStream(rr)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(realtime.Event{Name: "status", Data: map[string]string{"status": "Shipped"}}))
+	require.NoError(t, stream.Ping())
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.True(t, rr.Flushed)
+	assert.Equal(t, "event: status\ndata: {\"status\":\"Shipped\"}\n\n: ping\n\n", rr.Body.String())
+}
@@ -0,0 +1,62 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jofosuware/go/shopit/pkg/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	// a Friday
+	from := time.Date(2026, 10, 16, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 10, 16, 10, 15, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)},
+		{"30 8-18 * * 1-5", time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		// either day matches when both are set
+		{"0 0 1 * 6", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, 10, 16, 10, 25, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 16, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := scheduler.Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.next, s.Next(from))
+		})
+	}
+
+	t.Run("Never matching spec", func(t *testing.T) {
+		s, err := scheduler.Parse("0 0 30 2 *")
+		require.NoError(t, err)
+		assert.True(t, s.Next(from).IsZero())
+	})
+
+	t.Run("Location of the time is kept", func(t *testing.T) {
+		kolkata := time.FixedZone("Kolkata", 5*3600+1800)
+		s, err := scheduler.Parse("0 * * * *")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 10, 16, 11, 0, 0, 0, kolkata), s.Next(time.Date(2026, 10, 16, 10, 7, 0, 0, kolkata)))
+	})
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "* * * 13 *",
+		"*/0 * * * *", "5-1 * * * *", "MON * * * *", "@fortnightly", "@every soon", "@every -1m"} {
+		t.Run("Invalid "+spec, func(t *testing.T) {
+			_, err := scheduler.Parse(spec)
+			assert.Error(t, err)
+		})
+	}
+}
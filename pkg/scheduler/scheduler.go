@@ -0,0 +1,227 @@
+// Package scheduler runs the periodic background jobs of the server, such as token
+// cleanup and outbox dispatch, so that they are not ad-hoc goroutines.
+//
+// A job is registered with a Schedule, an interval (Every) or a cron spec (Parse).
+// Every job runs on a goroutine of its own, one run at a time: a run that overruns
+// its schedule delays the next instead of overlapping it. Runs are given a context
+// cancelled after their timeout, their error is logged, and a panic is recovered
+// and logged without stopping the job or the others. A random jitter can be added to
+// the runs so that the instances of the server do not all run a job at once.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/jofosuware/go/shopit/pkg/logger"
+)
+
+// DefaultTimeout is how long a run may take when its job sets no timeout.
+const DefaultTimeout = 10 * time.Minute
+
+// CancelGrace is how long Shutdown waits for the runs it cancelled to return before it
+// gives up on them.
+const CancelGrace = time.Second
+
+// Func is the work of a job. It should return once ctx is done.
+type Func func(ctx context.Context) error
+
+// Option configures a job.
+type Option func(j *job)
+
+// Timeout cancels the context of a run after d. A non-positive d falls back to
+// DefaultTimeout.
+func Timeout(d time.Duration) Option {
+	return func(j *job) {
+		if d > 0 {
+			j.timeout = d
+		}
+	}
+}
+
+// Jitter delays every run by a random duration up to d.
+func Jitter(d time.Duration) Option {
+	return func(j *job) {
+		if d > 0 {
+			j.jitter = d
+		}
+	}
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	fn       Func
+	timeout  time.Duration
+	jitter   time.Duration
+}
+
+// Scheduler runs jobs on their schedule between Start and Shutdown.
+type Scheduler struct {
+	logger logger.Logger
+	jobs   []*job
+
+	mu      sync.Mutex
+	started bool
+	wg      sync.WaitGroup
+	// stop is closed by Shutdown, for no run to start after it
+	stop     chan struct{}
+	stopOnce sync.Once
+	// runs is the parent of the contexts of the runs, cancelled when Shutdown gives up
+	// waiting for them.
+	runs       context.Context
+	cancelRuns context.CancelFunc
+}
+
+// New returns a Scheduler logging the runs of its jobs with logger.
+func New(logger logger.Logger) *Scheduler {
+	runs, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		logger:     logger,
+		stop:       make(chan struct{}),
+		runs:       runs,
+		cancelRuns: cancel,
+	}
+}
+
+// Add registers fn under name, to run on schedule once the scheduler is started. It
+// panics when called after Start.
+func (s *Scheduler) Add(name string, schedule Schedule, fn Func, opts ...Option) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		panic("scheduler: job " + name + " added after Start")
+	}
+
+	j := &job{name: name, schedule: schedule, fn: fn, timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(j)
+	}
+	s.jobs = append(s.jobs, j)
+}
+
+// Every registers fn under name to run every interval, as Add with Every(interval).
+// A non-positive interval disables the job.
+func (s *Scheduler) Every(name string, interval time.Duration, fn Func, opts ...Option) {
+	if interval <= 0 {
+		return
+	}
+
+	s.Add(name, Every(interval), fn, opts...)
+}
+
+// Cron registers fn under name to run on the cron spec, as Add with Parse(spec).
+func (s *Scheduler) Cron(name, spec string, fn Func, opts ...Option) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+
+	s.Add(name, schedule, fn, opts...)
+	return nil
+}
+
+// Start runs the jobs on their schedule until ctx is done or Shutdown is called. No
+// run starts after that; Shutdown waits for those running.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Shutdown stops starting runs and waits for the running jobs to return. When ctx is
+// done first, it cancels the contexts of the runs, waits up to CancelGrace for the jobs
+// to return and returns ctx.Err(). A job that ignores its context is left running.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelRuns()
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.cancelRuns()
+
+	grace := time.NewTimer(CancelGrace)
+	defer grace.Stop()
+
+	select {
+	case <-done:
+	case <-grace.C:
+		s.logger.Warnf("jobs still running %s after they were cancelled, giving up on them", CancelGrace)
+	}
+	return ctx.Err()
+}
+
+// loop runs j on its schedule until ctx is done or the scheduler is shut down.
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Warnf("job %s is never scheduled again", j.name)
+			return
+		}
+		if j.jitter > 0 {
+			next = next.Add(time.Duration(rand.Int63n(int64(j.jitter))))
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(j)
+	}
+}
+
+// run runs j once, logging its error, panic or timeout.
+func (s *Scheduler) run(j *job) {
+	ctx, cancel := context.WithTimeout(s.runs, j.timeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		if p := recover(); p != nil {
+			s.logger.Errorf("job %s panicked: %v\n%s", j.name, p, debug.Stack())
+		}
+	}()
+
+	if err := j.fn(ctx); err != nil {
+		s.logger.Errorf("job %s failed after %s: %v", j.name, time.Since(start).Round(time.Millisecond), err)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		s.logger.Warnf("job %s ran past its timeout of %s", j.name, j.timeout)
+	}
+}
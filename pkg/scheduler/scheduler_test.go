@@ -0,0 +1,157 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// waitFor waits up to a second for n to reach want.
+func waitFor(t *testing.T, n *atomic.Int32, want int32) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool { return n.Load() >= want }, time.Second, time.Millisecond)
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("Jobs run on their schedule until shut down", func(t *testing.T) {
+		s := scheduler.New(mockLogger.NewLogger(t))
+
+		var runs atomic.Int32
+		s.Every("count", 5*time.Millisecond, func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+		s.Every("disabled", 0, func(ctx context.Context) error {
+			t.Error("disabled job ran")
+			return nil
+		})
+
+		s.Start(context.Background())
+		waitFor(t, &runs, 3)
+		require.NoError(t, s.Shutdown(context.Background()))
+
+		n := runs.Load()
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, n, runs.Load())
+	})
+
+	t.Run("Errors and panics are logged and the job keeps running", func(t *testing.T) {
+		l := mockLogger.NewLogger(t)
+		l.On("Errorf", "job %s failed after %s: %v", "failing", mock.Anything, mock.Anything).Return()
+		l.On("Errorf", "job %s panicked: %v\n%s", "panicking", "boom", mock.Anything).Return()
+		s := scheduler.New(l)
+
+		var failures, panics atomic.Int32
+		s.Every("failing", time.Millisecond, func(ctx context.Context) error {
+			failures.Add(1)
+			return errors.New("db down")
+		})
+		s.Every("panicking", time.Millisecond, func(ctx context.Context) error {
+			panics.Add(1)
+			panic("boom")
+		})
+
+		s.Start(context.Background())
+		waitFor(t, &failures, 2)
+		waitFor(t, &panics, 2)
+		require.NoError(t, s.Shutdown(context.Background()))
+	})
+
+	t.Run("Runs are cancelled at their timeout", func(t *testing.T) {
+		l := mockLogger.NewLogger(t)
+		l.On("Errorf", "job %s failed after %s: %v", "slow", mock.Anything, context.DeadlineExceeded).Return()
+		l.On("Warnf", "job %s ran past its timeout of %s", "slow", 5*time.Millisecond).Return()
+		s := scheduler.New(l)
+
+		var timeouts atomic.Int32
+		s.Every("slow", time.Millisecond, func(ctx context.Context) error {
+			<-ctx.Done()
+			timeouts.Add(1)
+			return ctx.Err()
+		}, scheduler.Timeout(5*time.Millisecond))
+
+		s.Start(context.Background())
+		waitFor(t, &timeouts, 1)
+		require.NoError(t, s.Shutdown(context.Background()))
+	})
+
+	t.Run("Shutdown waits for running jobs", func(t *testing.T) {
+		s := scheduler.New(mockLogger.NewLogger(t))
+
+		var started, finished atomic.Int32
+		s.Every("steady", time.Millisecond, func(ctx context.Context) error {
+			started.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			finished.Add(1)
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.Start(ctx)
+		waitFor(t, &started, 1)
+		cancel()
+
+		require.NoError(t, s.Shutdown(context.Background()))
+		assert.Equal(t, started.Load(), finished.Load())
+	})
+
+	t.Run("Shutdown cancels the runs at its deadline", func(t *testing.T) {
+		l := mockLogger.NewLogger(t)
+		l.On("Errorf", "job %s failed after %s: %v", "stuck", mock.Anything, context.Canceled).Return().Once()
+		s := scheduler.New(l)
+
+		var started atomic.Int32
+		s.Every("stuck", time.Millisecond, func(ctx context.Context) error {
+			started.Add(1)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		s.Start(context.Background())
+		waitFor(t, &started, 1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, s.Shutdown(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("Shutdown gives up on runs that ignore their context", func(t *testing.T) {
+		l := mockLogger.NewLogger(t)
+		l.On("Warnf", "jobs still running %s after they were cancelled, giving up on them", scheduler.CancelGrace).Return().Once()
+		s := scheduler.New(l)
+
+		var started atomic.Int32
+		release := make(chan struct{})
+		defer close(release)
+		s.Every("deaf", time.Millisecond, func(ctx context.Context) error {
+			started.Add(1)
+			<-release
+			return nil
+		})
+
+		s.Start(context.Background())
+		waitFor(t, &started, 1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, s.Shutdown(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("Cron jobs must have a valid spec", func(t *testing.T) {
+		s := scheduler.New(mockLogger.NewLogger(t))
+
+		assert.NoError(t, s.Cron("nightly", "0 3 * * *", func(ctx context.Context) error { return nil },
+			scheduler.Jitter(time.Minute)))
+		assert.ErrorContains(t, s.Cron("broken", "0 25 * * *", func(ctx context.Context) error { return nil }),
+			"job broken")
+	})
+}
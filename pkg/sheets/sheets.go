@@ -0,0 +1,105 @@
+// Package sheets reads Google Sheets as CSV.
+//
+// A sheet is read through its CSV export, so it has to be shared with anyone who has
+// the link, or published to the web; no Google credentials are needed. Google answers
+// the export of a private sheet with its sign-in page, which is reported as
+// ErrNotShared.
+package sheets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// MaxSize is the largest CSV export read from a sheet, in bytes.
+const MaxSize = 10 << 20
+
+// ErrNotShared is returned when a sheet is not shared by link.
+var ErrNotShared = errors.New("google sheet is not shared by link")
+
+// ErrTooLarge is returned when the CSV export of a sheet is larger than MaxSize.
+var ErrTooLarge = fmt.Errorf("google sheet export is larger than %d MB", MaxSize>>20)
+
+// Sheet is a tab of a Google Sheet, read as CSV from URL.
+type Sheet struct {
+	URL    string
+	Client *http.Client
+}
+
+// New returns the tab gid of the spreadsheet id, the first tab when gid is empty,
+// whose export times out after timeout.
+func New(id, gid string, timeout time.Duration) *Sheet {
+	u := "https://docs.google.com/spreadsheets/d/" + url.PathEscape(id) + "/export?format=csv"
+	if gid != "" {
+		u += "&gid=" + url.QueryEscape(gid)
+	}
+
+	return &Sheet{URL: u, Client: &http.Client{Timeout: timeout}}
+}
+
+// Open fetches the sheet and returns its CSV, which the caller closes. Reading more
+// than MaxSize bytes of it fails with ErrTooLarge. The request is cancelled with ctx.
+func (s *Sheet) Open(ctx context.Context) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+			return nil, ErrNotShared
+		}
+		return nil, fmt.Errorf("google sheet: unexpected status %s", res.Status)
+	}
+
+	// private sheets redirect to the sign-in page
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType == "text/html" {
+		res.Body.Close()
+		return nil, ErrNotShared
+	}
+
+	return &limitedBody{body: res.Body, left: MaxSize}, nil
+}
+
+// limitedBody reads a response body until left bytes are read, then fails.
+type limitedBody struct {
+	body io.ReadCloser
+	left int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.left <= 0 {
+		// one more byte tells a body of exactly MaxSize from a larger one
+		var b [1]byte
+		n, err := l.body.Read(b[:])
+		if n > 0 {
+			return 0, ErrTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > l.left {
+		p = p[:l.left]
+	}
+	n, err := l.body.Read(p)
+	l.left -= int64(n)
+
+	return n, err
+}
+
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
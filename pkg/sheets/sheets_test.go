@@ -0,0 +1,71 @@
+package sheets_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jofosuware/go/shopit/pkg/sheets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.Equal(t, "https://docs.google.com/spreadsheets/d/1AbC/export?format=csv&gid=42",
+		sheets.New("1AbC", "42", time.Second).URL)
+	assert.Equal(t, "https://docs.google.com/spreadsheets/d/1AbC/export?format=csv",
+		sheets.New("1AbC", "", time.Second).URL)
+}
+
+func TestOpen(t *testing.T) {
+	open := func(h http.HandlerFunc) (string, error) {
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+
+		body, err := (&sheets.Sheet{URL: srv.URL, Client: srv.Client()}).Open(context.Background())
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+
+		b, err := io.ReadAll(body)
+		return string(b), err
+	}
+
+	t.Run("CSV is read", func(t *testing.T) {
+		csv, err := open(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			fmt.Fprint(w, "name,price\nMug,9.99\n")
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "name,price\nMug,9.99\n", csv)
+	})
+
+	t.Run("Sheet not shared", func(t *testing.T) {
+		_, err := open(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<html>Sign in</html>")
+		})
+		assert.ErrorIs(t, err, sheets.ErrNotShared)
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		_, err := open(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("Export too large", func(t *testing.T) {
+		_, err := open(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			fmt.Fprint(w, strings.Repeat("a", sheets.MaxSize+1))
+		})
+		assert.ErrorIs(t, err, sheets.ErrTooLarge)
+	})
+}
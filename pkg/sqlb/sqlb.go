@@ -0,0 +1,191 @@
+// Package sqlb builds select queries whose conditions, ordering and paging depend on
+// the request, such as filtered listings, so repositories do not concatenate SQL
+// branches by hand.
+//
+// Conditions mark each of their values with ?, which Build numbers as the Postgres
+// parameters $1, $2, ... in the order they appear in the query, so values are always
+// bound and never written into the SQL. Any ? of a query is a parameter, so the
+// jsonb ? operators are written with their functions, such as jsonb_exists. Column names and expressions are written by
+// the repository; a sort asked for in a request is looked up in a Sort rather than
+// used as it is.
+package sqlb
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Cond is a condition of a where clause with the values of its ? parameters.
+// The zero Cond is no condition and is left out of a query.
+type Cond struct {
+	sql  string
+	args []interface{}
+}
+
+// Expr returns the condition sql, with a ? for each of args.
+func Expr(sql string, args ...interface{}) Cond {
+	return Cond{sql: sql, args: args}
+}
+
+// In returns the condition that column is one of values. With no values it is false,
+// as "in ()" is not valid SQL.
+func In[T any](column string, values []T) Cond {
+	if len(values) == 0 {
+		return Cond{sql: "false"}
+	}
+
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+
+	return Cond{sql: column + " in (" + strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ") + ")", args: args}
+}
+
+// Or returns the condition that one of conds holds, in parentheses. Zero conds are
+// left out; with none left it is no condition.
+func Or(conds ...Cond) Cond {
+	return join(" or ", conds)
+}
+
+// And returns the condition that all of conds hold, in parentheses. Zero conds are
+// left out; with none left it is no condition.
+func And(conds ...Cond) Cond {
+	return join(" and ", conds)
+}
+
+func join(sep string, conds []Cond) Cond {
+	var sqls []string
+	var args []interface{}
+	for _, c := range conds {
+		if c.sql == "" {
+			continue
+		}
+		sqls = append(sqls, c.sql)
+		args = append(args, c.args...)
+	}
+
+	if len(sqls) == 0 {
+		return Cond{}
+	}
+
+	return Cond{sql: "(" + strings.Join(sqls, sep) + ")", args: args}
+}
+
+// Sort maps the sort keys a request may ask for to the expressions they order by,
+// e.g. {"price": "price", "newest": "created_at desc"}.
+type Sort map[string]string
+
+// Order returns the expression of key, descending when key starts with "-" such as
+// "-price". It returns false for a key that is not in s.
+func (s Sort) Order(key string) (string, bool) {
+	desc := strings.HasPrefix(key, "-")
+	expr, ok := s[strings.TrimPrefix(key, "-")]
+	if !ok {
+		return "", false
+	}
+	if desc {
+		expr += " desc"
+	}
+
+	return expr, true
+}
+
+// SelectBuilder builds a select query. Its methods add to the query and return it,
+// so they can be chained.
+type SelectBuilder struct {
+	columns string
+	colArgs []interface{}
+	from    string
+	where   []Cond
+	groupBy string
+	orderBy []string
+	limit   int
+	offset  int
+	paged   bool
+}
+
+// Select starts a query of columns, e.g. "product_id, name", with a ? for each of
+// args, such as in a subquery of the columns.
+func Select(columns string, args ...interface{}) *SelectBuilder {
+	return &SelectBuilder{columns: columns, colArgs: args}
+}
+
+// From sets the tables the query selects from, with their joins.
+func (b *SelectBuilder) From(from string) *SelectBuilder {
+	b.from = from
+	return b
+}
+
+// Where adds conds to the conditions the rows must all meet. Zero conds are left out.
+func (b *SelectBuilder) Where(conds ...Cond) *SelectBuilder {
+	for _, c := range conds {
+		if c.sql != "" {
+			b.where = append(b.where, c)
+		}
+	}
+	return b
+}
+
+// GroupBy sets the expressions rows are grouped by.
+func (b *SelectBuilder) GroupBy(exprs string) *SelectBuilder {
+	b.groupBy = exprs
+	return b
+}
+
+// OrderBy adds exprs to the ordering of the rows, e.g. "created_at desc".
+func (b *SelectBuilder) OrderBy(exprs ...string) *SelectBuilder {
+	b.orderBy = append(b.orderBy, exprs...)
+	return b
+}
+
+// Page limits the query to limit rows after skipping offset, both bound as parameters.
+func (b *SelectBuilder) Page(limit, offset int) *SelectBuilder {
+	b.limit, b.offset, b.paged = limit, offset, true
+	return b
+}
+
+// Build returns the query with its parameters numbered, and their values.
+func (b *SelectBuilder) Build() (string, []interface{}) {
+	var sb strings.Builder
+	args := append([]interface{}{}, b.colArgs...)
+
+	sb.WriteString("select " + b.columns + " from " + b.from)
+
+	if len(b.where) > 0 {
+		sqls := make([]string, len(b.where))
+		for i, c := range b.where {
+			sqls[i] = c.sql
+			args = append(args, c.args...)
+		}
+		sb.WriteString(" where " + strings.Join(sqls, " and "))
+	}
+	if b.groupBy != "" {
+		sb.WriteString(" group by " + b.groupBy)
+	}
+	if len(b.orderBy) > 0 {
+		sb.WriteString(" order by " + strings.Join(b.orderBy, ", "))
+	}
+	if b.paged {
+		sb.WriteString(" limit ? offset ?")
+		args = append(args, b.limit, b.offset)
+	}
+
+	return number(sb.String()), args
+}
+
+// number replaces each ? of query with the next numbered parameter.
+func number(query string) string {
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			sb.WriteRune(r)
+			continue
+		}
+		n++
+		sb.WriteString("$" + strconv.Itoa(n))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,91 @@
+package sqlb_test
+
+import (
+	"testing"
+
+	"github.com/jofosuware/go/shopit/pkg/sqlb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelect(t *testing.T) {
+	t.Run("Without conditions", func(t *testing.T) {
+		query, args := sqlb.Select("product_id, name").From("products").Build()
+
+		assert.Equal(t, "select product_id, name from products", query)
+		assert.Empty(t, args)
+	})
+
+	t.Run("Parameters are numbered in order", func(t *testing.T) {
+		query, args := sqlb.Select("product_id").From("products").
+			Where(sqlb.Expr("not hidden"), sqlb.Or(sqlb.Expr("name ILIKE ?", "%lens%"), sqlb.Expr("sku = ?", "LENS-1"))).
+			Where(sqlb.Expr("price between ? and ?", 100, 500)).
+			OrderBy("price desc", "created_at").
+			Page(12, 24).
+			Build()
+
+		assert.Equal(t, "select product_id from products where not hidden and (name ILIKE $1 or sku = $2)"+
+			" and price between $3 and $4 order by price desc, created_at limit $5 offset $6", query)
+		assert.Equal(t, []interface{}{"%lens%", "LENS-1", 100, 500, 12, 24}, args)
+	})
+
+	t.Run("Empty conditions are left out", func(t *testing.T) {
+		query, args := sqlb.Select("order_id").From("orders").
+			Where(sqlb.Or(), sqlb.And(sqlb.Cond{}), sqlb.Cond{}).
+			Build()
+
+		assert.Equal(t, "select order_id from orders", query)
+		assert.Empty(t, args)
+	})
+
+	t.Run("Parameters of the columns come first", func(t *testing.T) {
+		query, args := sqlb.Select("name, exists (select 1 from orders where status = ?)", "Delivered").From("products").
+			Where(sqlb.Expr("product_id = ?", 7)).
+			Build()
+
+		assert.Equal(t, "select name, exists (select 1 from orders where status = $1) from products where product_id = $2", query)
+		assert.Equal(t, []interface{}{"Delivered", 7}, args)
+	})
+
+	t.Run("Group by", func(t *testing.T) {
+		query, args := sqlb.Select("product_id, count(*)").From("order_items").
+			Where(sqlb.In("order_id", []string{"a", "b"})).
+			GroupBy("product_id").
+			Build()
+
+		assert.Equal(t, "select product_id, count(*) from order_items where order_id in ($1, $2) group by product_id", query)
+		assert.Equal(t, []interface{}{"a", "b"}, args)
+	})
+}
+
+func TestIn(t *testing.T) {
+	t.Run("Values", func(t *testing.T) {
+		query, args := sqlb.Select("name").From("products").
+			Where(sqlb.Expr("stock > ?", 0), sqlb.In("product_id", []int{1, 2, 3})).
+			Build()
+
+		assert.Equal(t, "select name from products where stock > $1 and product_id in ($2, $3, $4)", query)
+		assert.Equal(t, []interface{}{0, 1, 2, 3}, args)
+	})
+
+	t.Run("No values match nothing", func(t *testing.T) {
+		query, args := sqlb.Select("name").From("products").Where(sqlb.In("product_id", []int{})).Build()
+
+		assert.Equal(t, "select name from products where false", query)
+		assert.Empty(t, args)
+	})
+}
+
+func TestSortOrder(t *testing.T) {
+	sort := sqlb.Sort{"price": "price", "stock": "stock"}
+
+	expr, ok := sort.Order("price")
+	assert.True(t, ok)
+	assert.Equal(t, "price", expr)
+
+	expr, ok = sort.Order("-stock")
+	assert.True(t, ok)
+	assert.Equal(t, "stock desc", expr)
+
+	_, ok = sort.Order("name; drop table products")
+	assert.False(t, ok)
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudinary/cloudinary-go/api/uploader"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+)
+
+// StaticPrefix is the route under which files stored by Local are served.
+const StaticPrefix = "/static"
+
+// StaticHandler serves the files stored by Local in dir under StaticPrefix.
+// Directories are not listed, and browsers are told not to sniff content types. Only
+// the images of imageExtensions are shown inline; any other file, such as an uploaded
+// HTML or SVG file, is sent as an attachment so that it does not run in the site.
+func StaticHandler(dir string) http.Handler {
+	files := http.StripPrefix(StaticPrefix, http.FileServer(fileSystem{http.Dir(dir)}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if !inlineImage(r.URL.Path) {
+			w.Header().Set("Content-Disposition", "attachment")
+		}
+
+		files.ServeHTTP(w, r)
+	})
+}
+
+// inlineImage reports whether the file at name is an image shown inline.
+func inlineImage(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for _, e := range imageExtensions {
+		if ext == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fileSystem opens the files of fs and none of its directories, so that they are not
+// listed.
+type fileSystem struct {
+	fs http.FileSystem
+}
+
+func (f fileSystem) Open(name string) (http.File, error) {
+	file, err := f.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+
+	return file, nil
+}
+
+// Local stores uploads on the local disk. Files are served by StaticHandler. Disk
+// writes are not cancelled, so only ListAssets honours its context.
+type Local struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocal returns a Local uploader rooted at cfg.Storage.Local.Dir, creating the directory if needed.
+func NewLocal(cfg *config.Config) (*Local, error) {
+	dir := cfg.Storage.Local.Dir
+	if dir == "" {
+		return nil, errors.New("local storage directory is not configured")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating storage directory: %v", err)
+	}
+
+	baseURL := strings.TrimSuffix(cfg.Storage.Local.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = StaticPrefix
+	}
+
+	return &Local{
+		dir:     dir,
+		baseURL: baseURL,
+	}, nil
+}
+
+// UploadToCloud writes data to <dir>/<folder>/<uuid><ext> and returns its public URL.
+func (l *Local) UploadToCloud(_ context.Context, folder string, data interface{}) (*uploader.UploadResult, error) {
+	content, err := readData(data)
+	if err != nil {
+		return &uploader.UploadResult{}, err
+	}
+
+	key, _ := objectKey(folder, content)
+
+	p, err := l.path(key)
+	if err != nil {
+		return &uploader.UploadResult{}, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return &uploader.UploadResult{}, err
+	}
+
+	if err = os.WriteFile(p, content, 0o644); err != nil {
+		return &uploader.UploadResult{}, err
+	}
+
+	url := l.baseURL + "/" + key
+
+	return &uploader.UploadResult{
+		PublicID:  key,
+		URL:       url,
+		SecureURL: url,
+		Bytes:     len(content),
+	}, nil
+}
+
+// Destroy removes the file identified by id. Missing files are reported as "not found".
+func (l *Local) Destroy(_ context.Context, id string) (*uploader.DestroyResult, error) {
+	p, err := l.path(id)
+	if err != nil {
+		return &uploader.DestroyResult{}, err
+	}
+
+	err = os.Remove(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return &uploader.DestroyResult{Result: "not found"}, nil
+	}
+	if err != nil {
+		return &uploader.DestroyResult{}, err
+	}
+
+	return &uploader.DestroyResult{Result: "ok"}, nil
+}
+
+// Dir returns the root directory of the stored files.
+func (l *Local) Dir() string {
+	return l.dir
+}
+
+// path resolves key inside the storage directory, rejecting keys that escape it.
+func (l *Local) path(key string) (string, error) {
+	p := filepath.Join(l.dir, filepath.FromSlash(key))
+
+	rel, err := filepath.Rel(l.dir, p)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+
+	return p, nil
+}
+
+// ListAssets returns every file stored under <dir>/<folder>, keyed like UploadToCloud's public ids.
+func (l *Local) ListAssets(ctx context.Context, folder string) ([]cloudinary.Asset, error) {
+	root, err := l.path(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []cloudinary.Asset
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(l.dir, p)
+		if err != nil {
+			return err
+		}
+
+		assets = append(assets, cloudinary.Asset{PublicID: filepath.ToSlash(rel), CreatedAt: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return assets, nil
+}
@@ -0,0 +1,169 @@
+package storage_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/jofosuware/go/shopit/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader is enough of a PNG for content sniffing.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newLocal(t *testing.T) (*storage.Local, string) {
+	dir := t.TempDir()
+	cfg := &config.Config{Storage: config.Storage{
+		Provider: storage.ProviderLocal,
+		Local:    config.LocalStorage{Dir: dir, BaseURL: "http://localhost:5000/static/"},
+	}}
+
+	l, err := storage.NewLocal(cfg)
+	require.NoError(t, err)
+
+	return l, dir
+}
+
+func TestLocalUploadToCloud(t *testing.T) {
+	l, dir := newLocal(t)
+
+	t.Run("data uri is stored under the folder", func(t *testing.T) {
+		uri := "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngHeader)
+
+		res, err := l.UploadToCloud(context.Background(), cloudinary.FolderAvatars, uri)
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(res.PublicID, cloudinary.FolderAvatars+"/"))
+		assert.True(t, strings.HasSuffix(res.PublicID, ".png"))
+		assert.Equal(t, "http://localhost:5000/static/"+res.PublicID, res.URL)
+
+		content, err := os.ReadFile(filepath.Join(dir, res.PublicID))
+		require.NoError(t, err)
+		assert.Equal(t, pngHeader, content)
+	})
+
+	t.Run("reader is stored", func(t *testing.T) {
+		res, err := l.UploadToCloud(context.Background(), cloudinary.FolderProducts, strings.NewReader("plain text"))
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(res.PublicID, cloudinary.FolderProducts+"/"))
+	})
+
+	t.Run("unsupported string is rejected", func(t *testing.T) {
+		_, err := l.UploadToCloud(context.Background(), cloudinary.FolderAvatars, "not-an-image")
+		assert.Error(t, err)
+	})
+
+	t.Run("urls are not fetched", func(t *testing.T) {
+		var fetched bool
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fetched = true
+			_, _ = w.Write(pngHeader)
+		}))
+		defer srv.Close()
+
+		_, err := l.UploadToCloud(context.Background(), cloudinary.FolderAvatars, srv.URL+"/avatar.png")
+		assert.Error(t, err)
+		assert.False(t, fetched)
+	})
+}
+
+func TestStaticHandler(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "products"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "products", "a.png"), pngHeader, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "products", "b.html"), []byte("<script>alert(1)</script>"), 0o644))
+
+	h := storage.StaticHandler(dir)
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, storage.StaticPrefix+path, nil))
+		return rr
+	}
+
+	t.Run("images are shown inline", func(t *testing.T) {
+		rr := get("/products/a.png")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+		assert.Empty(t, rr.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("other files are attachments", func(t *testing.T) {
+		rr := get("/products/b.html")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, "attachment", rr.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("directories are not listed", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/products/").Code)
+		assert.Equal(t, http.StatusNotFound, get("/").Code)
+	})
+}
+
+func TestLocalDestroy(t *testing.T) {
+	l, dir := newLocal(t)
+
+	res, err := l.UploadToCloud(context.Background(), cloudinary.FolderProducts, pngHeader)
+	require.NoError(t, err)
+
+	t.Run("existing file is removed", func(t *testing.T) {
+		d, err := l.Destroy(context.Background(), res.PublicID)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", d.Result)
+
+		_, err = os.Stat(filepath.Join(dir, res.PublicID))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("missing file is reported", func(t *testing.T) {
+		d, err := l.Destroy(context.Background(), res.PublicID)
+		require.NoError(t, err)
+		assert.Equal(t, "not found", d.Result)
+	})
+
+	t.Run("keys escaping the directory are rejected", func(t *testing.T) {
+		_, err := l.Destroy(context.Background(), "../../etc/passwd")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewUploader(t *testing.T) {
+	_, err := storage.NewUploader(&config.Config{Storage: config.Storage{Provider: "ftp"}})
+	assert.Error(t, err)
+
+	u, err := storage.NewUploader(&config.Config{Storage: config.Storage{
+		Provider: storage.ProviderLocal,
+		Local:    config.LocalStorage{Dir: t.TempDir()},
+	}})
+	require.NoError(t, err)
+	assert.IsType(t, &storage.Local{}, u)
+}
+
+func TestLocalListAssets(t *testing.T) {
+	l, _ := newLocal(t)
+
+	res, err := l.UploadToCloud(context.Background(), cloudinary.FolderProducts, pngHeader)
+	require.NoError(t, err)
+
+	assets, err := l.ListAssets(context.Background(), cloudinary.FolderProducts)
+	require.NoError(t, err)
+	require.Len(t, assets, 1)
+	assert.Equal(t, res.PublicID, assets[0].PublicID)
+
+	assets, err = l.ListAssets(context.Background(), cloudinary.FolderAvatars)
+	require.NoError(t, err)
+	assert.Empty(t, assets)
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/api/uploader"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3 stores uploads in a bucket of any S3-compatible object store (AWS S3, MinIO, R2, ...).
+type S3 struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// NewS3 returns an S3 uploader configured from cfg.Storage.S3.
+func NewS3(cfg *config.Config) (*S3, error) {
+	c := cfg.Storage.S3
+	if c.Endpoint == "" || c.Bucket == "" {
+		return nil, errors.New("s3 endpoint and bucket must be configured")
+	}
+
+	client, err := minio.New(c.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(c.AccessKey, c.SecretKey, ""),
+		Secure: c.UseSSL,
+		Region: c.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating s3 client: %v", err)
+	}
+
+	publicURL := strings.TrimSuffix(c.PublicURL, "/")
+	if publicURL == "" {
+		scheme := "http"
+		if c.UseSSL {
+			scheme = "https"
+		}
+		publicURL = fmt.Sprintf("%s://%s/%s", scheme, c.Endpoint, c.Bucket)
+	}
+
+	return &S3{
+		client:    client,
+		bucket:    c.Bucket,
+		publicURL: publicURL,
+	}, nil
+}
+
+// UploadToCloud puts data under folder/<uuid><ext> in the bucket and returns its public URL.
+func (s *S3) UploadToCloud(ctx context.Context, folder string, data interface{}) (*uploader.UploadResult, error) {
+	content, err := readData(data)
+	if err != nil {
+		return &uploader.UploadResult{}, err
+	}
+
+	key, contentType := objectKey(folder, content)
+
+	_, err = s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return &uploader.UploadResult{}, s3Error(err)
+	}
+
+	url := s.publicURL + "/" + key
+
+	return &uploader.UploadResult{
+		PublicID:  key,
+		URL:       url,
+		SecureURL: url,
+		Bytes:     len(content),
+	}, nil
+}
+
+// Destroy deletes the object identified by id from the bucket.
+func (s *S3) Destroy(ctx context.Context, id string) (*uploader.DestroyResult, error) {
+	err := s.client.RemoveObject(ctx, s.bucket, id, minio.RemoveObjectOptions{})
+	if err != nil {
+		return &uploader.DestroyResult{}, s3Error(err)
+	}
+
+	return &uploader.DestroyResult{Result: "ok"}, nil
+}
+
+// Presign issues a POST policy for one file of at most maxSize bytes, stored under
+// folder/<uuid> in the bucket, valid for expiry.
+func (s *S3) Presign(ctx context.Context, folder string, maxSize int64, expiry time.Duration) (*cloudinary.Presigned, error) {
+	key := path.Join(folder, uuid.New().String())
+	expiresAt := time.Now().Add(expiry)
+
+	policy := minio.NewPostPolicy()
+	for _, err := range []error{
+		policy.SetBucket(s.bucket),
+		policy.SetKey(key),
+		policy.SetExpires(expiresAt),
+		policy.SetContentLengthRange(1, maxSize),
+	} {
+		if err != nil {
+			return nil, fmt.Errorf("error building upload policy: %v", err)
+		}
+	}
+
+	u, fields, err := s.client.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return nil, s3Error(err)
+	}
+
+	return &cloudinary.Presigned{
+		URL:       u.String(),
+		Fields:    fields,
+		PublicID:  key,
+		AssetURL:  s.publicURL + "/" + key,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ListAssets returns every object stored under folder/ in the bucket.
+func (s *S3) ListAssets(ctx context.Context, folder string) ([]cloudinary.Asset, error) {
+	var assets []cloudinary.Asset
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: folder + "/", Recursive: true}) {
+		if obj.Err != nil {
+			return nil, s3Error(obj.Err)
+		}
+		assets = append(assets, cloudinary.Asset{PublicID: obj.Key, CreatedAt: obj.LastModified})
+	}
+
+	return assets, nil
+}
+
+// s3Error marks an error response of the server with a 5xx status transient, so that
+// the operation is tried again.
+func s3Error(err error) error {
+	if minio.ToErrorResponse(err).StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %v", cloudinary.ErrTransient, err)
+	}
+
+	return err
+}
@@ -0,0 +1,119 @@
+// Package storage provides alternative CloudUploader implementations so the API
+// can run in environments without Cloudinary, and selects the one to use from config.
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/jofosuware/go/shopit/pkg/cloudinary"
+)
+
+// Supported storage providers.
+const (
+	ProviderCloudinary = "cloudinary"
+	ProviderS3         = "s3"
+	ProviderLocal      = "local"
+)
+
+// maxUploadBytes caps how much data is read for a single upload.
+const maxUploadBytes = 10 << 20
+
+// NewUploader returns the CloudUploader selected by cfg.Storage.Provider,
+// defaulting to Cloudinary when no provider is configured.
+func NewUploader(cfg *config.Config) (cloudinary.CloudUploader, error) {
+	switch strings.ToLower(cfg.Storage.Provider) {
+	case "", ProviderCloudinary:
+		return cloudinary.NewCloudinary(cfg)
+	case ProviderS3:
+		return NewS3(cfg)
+	case ProviderLocal:
+		return NewLocal(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage provider %q", cfg.Storage.Provider)
+	}
+}
+
+// readData converts the payloads accepted by CloudUploader.UploadToCloud into raw
+// bytes: base64 data URIs, byte slices, multipart files and readers.
+func readData(data interface{}) ([]byte, error) {
+	switch d := data.(type) {
+	case string:
+		return readString(d)
+	case []byte:
+		return d, nil
+	case *multipart.File:
+		if d == nil || *d == nil {
+			return nil, errors.New("nil multipart file")
+		}
+		return readAll(*d)
+	case io.Reader:
+		return readAll(d)
+	default:
+		return nil, fmt.Errorf("unsupported upload data type %T", data)
+	}
+}
+
+// readString decodes a data URI. URLs are not fetched: the server would download
+// whatever a client points it at, internal hosts included.
+func readString(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "data:") {
+		return nil, errors.New("upload string must be a data uri")
+	}
+
+	i := strings.Index(s, ",")
+	if i < 0 || !strings.HasSuffix(s[:i], ";base64") {
+		return nil, errors.New("data uri must be base64 encoded")
+	}
+	if base64.StdEncoding.DecodedLen(len(s)-i-1) > maxUploadBytes+2 {
+		return nil, fmt.Errorf("upload exceeds %d bytes", maxUploadBytes)
+	}
+
+	return base64.StdEncoding.DecodeString(s[i+1:])
+}
+
+func readAll(r io.Reader) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, maxUploadBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > maxUploadBytes {
+		return nil, fmt.Errorf("upload exceeds %d bytes", maxUploadBytes)
+	}
+
+	return b, nil
+}
+
+// imageExtensions pins the extension used for common image types, since
+// mime.ExtensionsByType ordering depends on the host's mime tables.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// objectKey builds a provider-agnostic key of the form folder/<uuid><ext>
+// and returns it with the detected content type.
+func objectKey(folder string, content []byte) (string, string) {
+	contentType := http.DetectContentType(content)
+
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+
+	return path.Join(folder, uuid.New().String()+ext), contentType
+}
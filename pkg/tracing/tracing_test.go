@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jofosuware/go/shopit/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// record installs a tracer provider recording the spans ended during the test.
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	return rec
+}
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), &config.Config{})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestMiddleware(t *testing.T) {
+	rec := record(t)
+
+	useCase := func(ctx context.Context) (err error) {
+		_, span := Start(ctx, "CheckoutUC.GetSession")
+		defer End(span, &err)
+		return errors.New("session not found")
+	}
+
+	mux := chi.NewRouter()
+	mux.Use(Middleware)
+	mux.Get("/api/v1/checkout/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_ = useCase(r.Context())
+	})
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/checkout/42", nil))
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+
+	uc, req := spans[0], spans[1]
+	assert.Equal(t, "CheckoutUC.GetSession", uc.Name())
+	assert.Equal(t, codes.Error, uc.Status().Code)
+	assert.Equal(t, req.SpanContext().SpanID(), uc.Parent().SpanID())
+
+	assert.Equal(t, "GET /api/v1/checkout/{id}", req.Name())
+	assert.Contains(t, req.Attributes(), semconv.HTTPRoute("/api/v1/checkout/{id}"))
+}
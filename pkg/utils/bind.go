@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/gorilla/schema"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+)
+
+// formDecoder decodes form values into request structs by the JSON names of their
+// fields, so a struct reads the same from a form as from JSON. Fields implementing
+// encoding.TextUnmarshaler, such as ids, decode themselves.
+var formDecoder = newFormDecoder()
+
+func newFormDecoder() *schema.Decoder {
+	d := schema.NewDecoder()
+	d.SetAliasTag("json")
+	d.IgnoreUnknownKeys(true)
+	return d
+}
+
+// Bind reads the body of r into dst, a pointer to a request struct, and validates it
+// against its `validate` tags and, if dst is validator.Validatable, its own rules.
+// JSON bodies are read with ReadJSON; multipart and URL encoded forms are read by the
+// JSON names of the fields, their files left in r.MultipartForm. A body that cannot be
+// read is answered with 400, or 413 when it is too large, and an invalid one with 422
+// and the error of each field; Bind then returns false and the handler stops.
+func Bind(w http.ResponseWriter, r *http.Request, l logger.Logger, dst interface{}) bool {
+	if err := decode(w, r, dst); err != nil {
+		_ = ReadError(w, r, err)
+		l.Errorf("reading request body error: %v", err)
+		return false
+	}
+
+	v := validator.New()
+	v.Struct(dst)
+
+	if !v.Valid() {
+		FailedValidation(w, r, v)
+		l.Errorf("Failed validation: %v", v.Errors)
+		return false
+	}
+
+	return true
+}
+
+// decode reads the body of r into dst by its content type.
+func decode(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "multipart/form-data":
+		if err := ParseMultipartForm(w, r); err != nil {
+			return err
+		}
+	case "application/x-www-form-urlencoded":
+		r.Body = http.MaxBytesReader(w, r.Body, jsonLimit(r))
+		if err := r.ParseForm(); err != nil {
+			return err
+		}
+	default:
+		return ReadJSON(w, r, dst)
+	}
+
+	return formDecoder.Decode(dst, r.Form)
+}
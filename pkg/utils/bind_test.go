@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type bindItem struct {
+	Product  string `json:"product" validate:"required,uuid"`
+	Quantity int    `json:"quantity" validate:"min=1"`
+}
+
+type bindRequest struct {
+	Name   string     `json:"name" validate:"notblank"`
+	Email  string     `json:"email" validate:"omitempty,email"`
+	Kind   string     `json:"kind" validate:"omitempty,oneof=gift sale"`
+	Owner  uuid.UUID  `json:"owner"`
+	Items  []bindItem `json:"items" validate:"omitempty,min=1,dive"`
+	Labels []string   `json:"labels" validate:"max=2"`
+}
+
+func (req *bindRequest) Validate(v *validator.Validator) {
+	v.Check(req.Name != "reserved", "name", "name is reserved")
+}
+
+func TestBind(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+
+	bind := func(r *http.Request) (*httptest.ResponseRecorder, bindRequest, bool) {
+		var req bindRequest
+		w := httptest.NewRecorder()
+		ok := Bind(w, r, logger, &req)
+		return w, req, ok
+	}
+
+	errorsOf := func(t *testing.T, w *httptest.ResponseRecorder) (map[string]string, map[string]string) {
+		var body struct {
+			Errors map[string]string `json:"errors"`
+			Codes  map[string]string `json:"codes"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+		return body.Errors, body.Codes
+	}
+
+	t.Run("JSON body", func(t *testing.T) {
+		owner := uuid.New()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(
+			`{"name": "Ama", "owner": "`+owner.String()+`", "items": [{"product": "`+owner.String()+`", "quantity": 2}]}`))
+
+		w, req, ok := bind(r)
+
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Ama", req.Name)
+		assert.Equal(t, owner, req.Owner)
+		assert.Len(t, req.Items, 1)
+	})
+
+	t.Run("Form body", func(t *testing.T) {
+		owner := uuid.New()
+		payload, ct, err := CreateMultipartForm(url.Values{
+			"name":   {"Ama"},
+			"owner":  {owner.String()},
+			"labels": {"new", "sale"},
+		})
+		require.NoError(t, err)
+		r := httptest.NewRequest(http.MethodPost, "/", payload)
+		r.Header.Set("Content-Type", ct)
+
+		_, req, ok := bind(r)
+
+		assert.True(t, ok)
+		assert.Equal(t, "Ama", req.Name)
+		assert.Equal(t, owner, req.Owner)
+		assert.Equal(t, []string{"new", "sale"}, req.Labels)
+	})
+
+	t.Run("Unreadable body", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": `))
+
+		w, _, ok := bind(r)
+
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Malformed form value", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=Ama&owner=1"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		w, _, ok := bind(r)
+
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Invalid fields", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(
+			`{"name": " ", "email": "ama", "kind": "loan", "items": [{"product": "1", "quantity": 0}], "labels": ["a", "b", "c"]}`))
+
+		w, _, ok := bind(r)
+
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		errs, codes := errorsOf(t, w)
+		assert.Equal(t, map[string]string{
+			"name":              "name must be provided",
+			"email":             "email must be a valid email address",
+			"kind":              "kind must be one of: gift, sale",
+			"items[0].product":  "product must be a valid id",
+			"items[0].quantity": "quantity must be at least 1",
+			"labels":            "labels must have at most 2 items",
+		}, errs)
+		assert.Equal(t, map[string]string{
+			"name":              validator.CodeRequired,
+			"email":             validator.CodeEmail,
+			"kind":              validator.CodeOneOf,
+			"items[0].product":  validator.CodeID,
+			"items[0].quantity": validator.CodeTooSmall,
+			"labels":            validator.CodeTooMany,
+		}, codes)
+	})
+
+	t.Run("Own rules", func(t *testing.T) {
+		logger.On("Errorf", mock.Anything, mock.Anything).Once()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": "reserved"}`))
+
+		w, _, ok := bind(r)
+
+		assert.False(t, ok)
+		errs, codes := errorsOf(t, w)
+		assert.Equal(t, map[string]string{"name": "name is reserved"}, errs)
+		assert.Equal(t, map[string]string{"name": validator.CodeInvalid}, codes)
+	})
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// The limits of request bodies, in bytes, as they are set by the bodylimit config.
+var (
+	// MaxJSONBody is the largest JSON or URL encoded body read.
+	MaxJSONBody int64 = 1 << 20
+	// MaxMultipartBody is the largest multipart form read, on routes without a limit
+	// of their own.
+	MaxMultipartBody int64 = 10 << 20
+	// MaxUploadBody is the largest multipart form read on the routes wrapped with
+	// Uploads.
+	MaxUploadBody int64 = 32 << 20
+	// MaxFormMemory is how much of a multipart form is kept in memory; the rest of its
+	// files are stored in temporary files while the request is served.
+	MaxFormMemory int64 = 1 << 20
+)
+
+// bodyLimitsKey is the context key of the body limits of a route.
+type bodyLimitsKey struct{}
+
+// bodyLimits are the largest JSON body and multipart form of a route, 0 for the
+// default.
+type bodyLimits struct {
+	json, multipart int64
+}
+
+// LimitBody sets the largest JSON body and multipart form, in bytes, read on the
+// routes it wraps. A limit of 0 keeps the default.
+func LimitBody(json, multipart int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, withBodyLimits(r, bodyLimits{json: json, multipart: multipart}))
+		})
+	}
+}
+
+// Uploads lets the routes it wraps, which upload product images, read multipart forms
+// of up to MaxUploadBody.
+func Uploads(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, withBodyLimits(r, bodyLimits{multipart: MaxUploadBody}))
+	})
+}
+
+func withBodyLimits(r *http.Request, l bodyLimits) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), bodyLimitsKey{}, l))
+}
+
+// jsonLimit returns the largest JSON body read for r.
+func jsonLimit(r *http.Request) int64 {
+	if l, ok := r.Context().Value(bodyLimitsKey{}).(bodyLimits); ok && l.json > 0 {
+		return l.json
+	}
+	return MaxJSONBody
+}
+
+// multipartLimit returns the largest multipart form read for r.
+func multipartLimit(r *http.Request) int64 {
+	if l, ok := r.Context().Value(bodyLimitsKey{}).(bodyLimits); ok && l.multipart > 0 {
+		return l.multipart
+	}
+	return MaxMultipartBody
+}
+
+// ParseMultipartForm parses the multipart form of r up to the limit of its route,
+// keeping MaxFormMemory of it in memory and its other files in temporary files, which
+// the server removes once the request is served.
+func ParseMultipartForm(w http.ResponseWriter, r *http.Request) error {
+	r.Body = http.MaxBytesReader(w, r.Body, multipartLimit(r))
+	return r.ParseMultipartForm(MaxFormMemory)
+}
+
+// ReadError answers a request whose body could not be read: with 413 when the body is
+// larger than its limit, or else with 400 describing err.
+func ReadError(w http.ResponseWriter, r *http.Request, err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return TooLarge(w, r, maxBytesErr.Limit)
+	}
+
+	return BadRequest(w, r, err)
+}
+
+// TooLarge sends a JSON response with status http.StatusRequestEntityTooLarge, for a
+// body larger than limit bytes
+func TooLarge(w http.ResponseWriter, r *http.Request, limit int64) error {
+	var payload struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+	}
+
+	payload.Success = false
+	payload.Message = localize(r, fmt.Sprintf("request body must not be larger than %s", formatBytes(limit)))
+
+	return WriteJSON(w, http.StatusRequestEntityTooLarge, payload)
+}
+
+// formatBytes formats n bytes in the largest unit that counts them whole.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%d MB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%d KB", n>>10)
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
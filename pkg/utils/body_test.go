@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// multipartBody returns a form with a file of size bytes and its content type.
+func multipartBody(t *testing.T, size int) (*bytes.Buffer, string) {
+	t.Helper()
+
+	var b bytes.Buffer
+	mw := multipart.NewWriter(&b)
+	require.NoError(t, mw.WriteField("name", "Ama"))
+	fw, err := mw.CreateFormFile("images", "photo.jpg")
+	require.NoError(t, err)
+	_, err = fw.Write(bytes.Repeat([]byte{'x'}, size))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	return &b, mw.FormDataContentType()
+}
+
+func TestBodyLimits(t *testing.T) {
+	defer func(json, multipart, upload, memory int64) {
+		MaxJSONBody, MaxMultipartBody, MaxUploadBody, MaxFormMemory = json, multipart, upload, memory
+	}(MaxJSONBody, MaxMultipartBody, MaxUploadBody, MaxFormMemory)
+	MaxJSONBody, MaxMultipartBody, MaxUploadBody, MaxFormMemory = 64, 2<<10, 8<<10, 1<<10
+
+	logger := mockLogger.NewLogger(t)
+	logger.On("Errorf", mock.Anything, mock.Anything).Maybe()
+
+	bind := func(h func(http.Handler) http.Handler, r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		var next http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req bindRequest
+			Bind(w, r, logger, &req)
+		})
+		if h != nil {
+			next = h(next)
+		}
+		next.ServeHTTP(w, r)
+		return w
+	}
+
+	messageOf := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		var body struct {
+			Success bool   `json:"success"`
+			Message string `json:"message"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+		assert.False(t, body.Success)
+		return body.Message
+	}
+
+	t.Run("JSON body over the limit", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": "`+strings.Repeat("a", 100)+`"}`))
+		r.Header.Set("Content-Type", "application/json")
+
+		w := bind(nil, r)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, "request body must not be larger than 64 bytes", messageOf(t, w))
+	})
+
+	t.Run("JSON limit of the route", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": "`+strings.Repeat("a", 100)+`"}`))
+		r.Header.Set("Content-Type", "application/json")
+
+		assert.Equal(t, http.StatusOK, bind(LimitBody(1<<10, 0), r).Code)
+	})
+
+	t.Run("Form over the limit", func(t *testing.T) {
+		body, contentType := multipartBody(t, 4<<10)
+		r := httptest.NewRequest(http.MethodPost, "/", body)
+		r.Header.Set("Content-Type", contentType)
+
+		w := bind(nil, r)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, "request body must not be larger than 2 KB", messageOf(t, w))
+	})
+
+	t.Run("Upload route reads larger forms to temporary files", func(t *testing.T) {
+		body, contentType := multipartBody(t, 4<<10)
+		r := httptest.NewRequest(http.MethodPost, "/", body)
+		r.Header.Set("Content-Type", contentType)
+
+		var stored bool
+		w := httptest.NewRecorder()
+		Uploads(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, ParseMultipartForm(w, r))
+			defer r.MultipartForm.RemoveAll()
+
+			f, err := r.MultipartForm.File["images"][0].Open()
+			require.NoError(t, err)
+			defer f.Close()
+			_, stored = f.(*os.File)
+		})).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, stored)
+	})
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "10 MB", formatBytes(10<<20))
+	assert.Equal(t, "512 KB", formatBytes(512<<10))
+	assert.Equal(t, "1000 bytes", formatBytes(1000))
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// IndentJSON makes WriteJSON indent every response, as it is set by server.PrettyJSON.
+// Otherwise only the responses to requests with a pretty query parameter are indented,
+// by PrettyJSON.
+var IndentJSON bool
+
+// maxPooledBuffer is the capacity above which an encoding buffer is dropped instead of
+// being pooled, so one large listing does not keep its memory for good.
+const maxPooledBuffer = 1 << 20
+
+// bufferPool holds the buffers responses are encoded into before they are written.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// encodeJSON encodes data into a pooled buffer, indented when indent is set. The
+// buffer goes back to the pool with putBuffer once it is written.
+func encodeJSON(data interface{}, indent bool) (*bytes.Buffer, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	enc := json.NewEncoder(buf)
+	if indent {
+		enc.SetIndent("", "\t")
+	}
+	if err := enc.Encode(data); err != nil {
+		putBuffer(buf)
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// PrettyJSON indents the JSON responses to requests with a pretty query parameter,
+// such as ?pretty or ?pretty=true, for reading them in a browser or terminal.
+func PrettyJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("pretty") && r.URL.Query().Get("pretty") != "false" {
+			w = &prettyWriter{w}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// prettyWriter marks a response WriteJSON indents.
+type prettyWriter struct {
+	http.ResponseWriter
+}
+
+func (w *prettyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// indented reports whether the response w is to be indented, looking through the
+// writers wrapping it on the way from PrettyJSON.
+func indented(w http.ResponseWriter) bool {
+	if IndentJSON {
+		return true
+	}
+
+	for w != nil {
+		if _, ok := w.(*prettyWriter); ok {
+			return true
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return false
+		}
+		w = u.Unwrap()
+	}
+
+	return false
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/realip"
+)
+
+// requestIDPattern matches the X-Request-Id of callers that is kept, such as the id
+// of a proxy or of the request of another service.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestLogger gives every request a request id, from its X-Request-Id header or a
+// new one, which is answered in X-Request-Id and added to the fields of the loggers
+// returned by logger.FromContext. Once a request is served, it is logged with its
+// status and duration. The log is sampled by route, so that busy routes do not drown
+// the others; responses with a server error are always logged.
+func RequestLogger(l logger.Logger) func(http.Handler) http.Handler {
+	sampled := l.Sampled()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get("X-Request-Id")
+			if !requestIDPattern.MatchString(requestID) {
+				requestID = uuid.New().String()
+			}
+			w.Header().Set("X-Request-Id", requestID)
+
+			r = r.WithContext(logger.WithFields(r.Context(), "request_id", requestID))
+
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			// The sampler counts messages, which are named by route rather than by path
+			route := "unmatched"
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			lg := sampled
+			if status >= http.StatusInternalServerError {
+				lg = l
+			}
+			logger.FromContext(r.Context(), lg).With(
+				"status", status,
+				"path", r.URL.Path,
+				"bytes", ww.BytesWritten(),
+				"duration_ms", time.Since(start).Milliseconds(),
+				"remote_ip", realip.FromRequest(r),
+			).Info(r.Method + " " + route)
+		})
+	}
+}
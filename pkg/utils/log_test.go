@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRequestLogger(t *testing.T) {
+	newRouter := func(l *mockLogger.Logger) *chi.Mux {
+		mux := chi.NewRouter()
+		mux.Use(RequestLogger(l))
+		mux.Get("/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		mux.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+			_ = ServerError(w, r, l, errors.New("db down"))
+		})
+		return mux
+	}
+
+	// expectLog expects l to log the request with the id requestID under message
+	expectLog := func(t *testing.T, l *mockLogger.Logger, requestID, message string, status int) {
+		withID, withStatus := mockLogger.NewLogger(t), mockLogger.NewLogger(t)
+		l.On("With", "request_id", requestID).Return(withID).Once()
+		withID.On("With", "status", status, "path", mock.Anything, "bytes", mock.Anything,
+			"duration_ms", mock.Anything, "remote_ip", mock.Anything).Return(withStatus).Once()
+		withStatus.On("Info", message).Once()
+	}
+
+	t.Run("Request id of the caller is kept", func(t *testing.T) {
+		l, sampled := mockLogger.NewLogger(t), mockLogger.NewLogger(t)
+		l.On("Sampled").Return(sampled).Once()
+		expectLog(t, sampled, "req-42", "GET /products/{id}", http.StatusNoContent)
+
+		r := httptest.NewRequest(http.MethodGet, "/products/7", nil)
+		r.Header.Set("X-Request-Id", "req-42")
+		w := httptest.NewRecorder()
+		newRouter(l).ServeHTTP(w, r)
+
+		assert.Equal(t, "req-42", w.Header().Get("X-Request-Id"))
+	})
+
+	t.Run("Invalid request id is replaced", func(t *testing.T) {
+		l, sampled := mockLogger.NewLogger(t), mockLogger.NewLogger(t)
+		l.On("Sampled").Return(sampled).Once()
+		sampled.On("With", "request_id", mock.MatchedBy(func(id string) bool {
+			return id != "bad id\n"
+		})).Return(sampled).Once()
+		sampled.On("With", "status", http.StatusNotFound, "path", "/missing", "bytes", mock.Anything,
+			"duration_ms", mock.Anything, "remote_ip", mock.Anything).Return(sampled).Once()
+		sampled.On("Info", "GET unmatched").Once()
+
+		r := httptest.NewRequest(http.MethodGet, "/missing", nil)
+		r.Header.Set("X-Request-Id", "bad id\n")
+		w := httptest.NewRecorder()
+		newRouter(l).ServeHTTP(w, r)
+
+		assert.Len(t, w.Header().Get("X-Request-Id"), 36)
+	})
+
+	t.Run("Server errors carry the request id and are not sampled", func(t *testing.T) {
+		l, sampled, withID := mockLogger.NewLogger(t), mockLogger.NewLogger(t), mockLogger.NewLogger(t)
+		l.On("Sampled").Return(sampled).Once()
+		l.On("With", "request_id", "req-43").Return(withID).Once()
+		withID.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Once()
+		expectLog(t, l, "req-43", "GET /fail", http.StatusInternalServerError)
+
+		r := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		r.Header.Set("X-Request-Id", "req-43")
+		newRouter(l).ServeHTTP(httptest.NewRecorder(), r)
+	})
+}
@@ -13,9 +13,18 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"runtime/debug"
+	"slices"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/jofosuware/go/shopit/internal/auth/repository"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/errreport"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	"github.com/jofosuware/go/shopit/pkg/logger"
+	"github.com/jofosuware/go/shopit/pkg/realip"
+	"github.com/jofosuware/go/shopit/pkg/validator"
 	"github.com/nfnt/resize"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -26,14 +35,25 @@ type contextKey string
 // UserContextKey is the key used to store/retrieve the user from context.
 const UserContextKey contextKey = "user"
 
+// TokenContextKey is the key used to store/retrieve the authentication token of a
+// request from context, which tells its session.
+const TokenContextKey contextKey = "token"
+
+// ServiceContextKey is the key used to store/retrieve the service principal of an
+// API key from context.
+const ServiceContextKey contextKey = "service"
+
 var Repo *repository.AuthRepository
 
-// WriteJSON writes arbitrary data out as JSON
+// WriteJSON writes arbitrary data out as JSON, compact unless IndentJSON is set or the
+// request asked for it through PrettyJSON. The data is encoded into a pooled buffer
+// first, so an error is returned before anything is written.
 func WriteJSON(w http.ResponseWriter, status int, data interface{}, headers ...http.Header) error {
-	out, err := json.MarshalIndent(data, "", "\t")
+	buf, err := encodeJSON(data, indented(w))
 	if err != nil {
 		return err
 	}
+	defer putBuffer(buf)
 
 	if len(headers) > 0 {
 		for k, v := range headers[0] {
@@ -43,15 +63,15 @@ func WriteJSON(w http.ResponseWriter, status int, data interface{}, headers ...h
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	w.Write(out)
+	w.Write(buf.Bytes())
 
 	return nil
 }
 
-// ReadJSON reads json from request body into data. We only accept a single json value in the body
+// ReadJSON reads json from request body into data, up to the JSON body limit of the route.
+// We only accept a single json value in the body
 func ReadJSON(w http.ResponseWriter, r *http.Request, data interface{}) error {
-	maxBytes := 1048576 // max one megabyte in request body
-	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
+	r.Body = http.MaxBytesReader(w, r.Body, jsonLimit(r))
 
 	dec := json.NewDecoder(r.Body)
 	err := dec.Decode(data)
@@ -76,27 +96,107 @@ func BadRequest(w http.ResponseWriter, r *http.Request, err error) error {
 	}
 
 	payload.Success = true
-	payload.Message = err.Error()
+	payload.Message = localize(r, err.Error())
 
-	out, err := json.MarshalIndent(payload, "", "\t")
-	if err != nil {
-		return err
+	return WriteJSON(w, http.StatusBadRequest, payload)
+}
+
+// NotFound sends a JSON response with status http.StatusNotFound, describing the error
+func NotFound(w http.ResponseWriter, r *http.Request, err error) error {
+	var payload struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusBadRequest)
-	w.Write(out)
-	return nil
+	payload.Success = false
+	payload.Message = localize(r, err.Error())
+
+	return WriteJSON(w, http.StatusNotFound, payload)
+}
+
+// ServerError logs err together with a newly generated error ID and the current stack trace,
+// then sends a JSON response with status http.StatusInternalServerError carrying only that ID
+// and a generic message, so internals are never leaked to the client.
+func ServerError(w http.ResponseWriter, r *http.Request, l logger.Logger, err error) error {
+	return serverError(w, r, l, uuid.New().String(), err)
+}
+
+// serverError is ServerError with the error ID errorID.
+func serverError(w http.ResponseWriter, r *http.Request, l logger.Logger, errorID string, err error) error {
+	logger.FromContext(r.Context(), l).Errorf("error id %s: %s %s from %s: %v\n%s", errorID, r.Method, r.URL.Path, realip.FromRequest(r), err, debug.Stack())
+
+	var payload struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		ErrorID string `json:"errorId"`
+	}
+
+	payload.Success = false
+	payload.Message = localize(r, "internal server error, contact support with the error id")
+	payload.ErrorID = errorID
+
+	headers := http.Header{}
+	headers.Set("X-Error-Id", errorID)
+
+	return WriteJSON(w, http.StatusInternalServerError, payload, headers)
+}
+
+// ServiceUnavailable sends a JSON response with status http.StatusServiceUnavailable and a
+// Retry-After header, for failures of a dependency that are expected to be temporary.
+func ServiceUnavailable(w http.ResponseWriter, r *http.Request, err error) error {
+	var payload struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+	}
+
+	payload.Success = false
+	payload.Message = localize(r, err.Error())
+
+	headers := http.Header{}
+	headers.Set("Retry-After", "30")
+
+	return WriteJSON(w, http.StatusServiceUnavailable, payload, headers)
+}
+
+// RecoverPanic recovers from panics in downstream handlers and reports them through
+// ServerError so that every 500 response carries a traceable error ID. The panics are
+// then sent to reporters, in the background, under the same ID.
+func RecoverPanic(l logger.Logger, reporters ...errreport.Reporter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if rvr := recover(); rvr != nil {
+					if rvr == http.ErrAbortHandler {
+						panic(rvr)
+					}
+
+					errorID := uuid.New().String()
+					e := errreport.NewEvent(errorID, rvr, debug.Stack(), r)
+					_ = serverError(w, r, l, errorID, fmt.Errorf("panic: %v", rvr))
+
+					for _, rep := range reporters {
+						go func(rep errreport.Reporter) {
+							if err := rep.Report(context.Background(), e); err != nil {
+								l.Errorf("error reporting panic %s: %v", errorID, err)
+							}
+						}(rep)
+					}
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
-func InvalidCredentials(w http.ResponseWriter) error {
+func InvalidCredentials(w http.ResponseWriter, r *http.Request) error {
 	var payload struct {
 		Success   bool   `json:"success"`
 		Message string `json:"message"`
 	}
 
 	payload.Success = true
-	payload.Message = "invalid authentication credentials"
+	payload.Message = localize(r, "invalid authentication credentials")
 
 	err := WriteJSON(w, http.StatusUnauthorized, payload)
 	if err != nil {
@@ -105,14 +205,32 @@ func InvalidCredentials(w http.ResponseWriter) error {
 	return nil
 }
 
-func TooManyRequests(w http.ResponseWriter) error {
+// Forbidden sends a JSON response with status http.StatusForbidden
+func Forbidden(w http.ResponseWriter, r *http.Request) error {
+	var payload struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+	}
+
+	payload.Success = false
+	payload.Message = localize(r, "you are not allowed to access this resource")
+
+	err := WriteJSON(w, http.StatusForbidden, payload)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func TooManyRequests(w http.ResponseWriter, r *http.Request) error {
 	var payload struct {
 		Success   bool   `json:"success"`
 		Message string `json:"message"`
 	}
 
 	payload.Success = true
-	payload.Message = "Too many requests"
+	payload.Message = localize(r, "Too many requests")
 
 	err := WriteJSON(w, http.StatusTooManyRequests, payload)
 	if err != nil {
@@ -121,6 +239,11 @@ func TooManyRequests(w http.ResponseWriter) error {
 	return nil
 }
 
+// localize translates msg to the locale r is served in.
+func localize(r *http.Request, msg string) string {
+	return i18n.Localize(i18n.FromContext(r.Context()), msg)
+}
+
 func PasswordMatches(hash, password string) (bool, error) {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	if err != nil {
@@ -135,16 +258,27 @@ func PasswordMatches(hash, password string) (bool, error) {
 	return true, nil
 }
 
-func FailedValidation(w http.ResponseWriter, r *http.Request, errors map[string]string) {
+// FailedValidation writes the errors of v with a 422: a message per field, in the
+// language of the request, and its code (validator.CodeRequired...), keyed alike.
+func FailedValidation(w http.ResponseWriter, r *http.Request, v *validator.Validator) {
 	var payload struct {
 		Success   bool              `json:"success"`
 		Message string            `json:"message"`
 		Errors  map[string]string `json:"errors"`
+		Codes   map[string]string `json:"codes"`
 	}
 
 	payload.Success = true
-	payload.Message = "failed validation"
-	payload.Errors = errors
+	payload.Message = localize(r, "failed validation")
+	payload.Errors = make(map[string]string, len(v.Errors))
+	payload.Codes = make(map[string]string, len(v.Errors))
+	for key, msg := range v.Errors {
+		payload.Errors[key] = localize(r, msg)
+		payload.Codes[key] = v.Codes[key]
+		if payload.Codes[key] == "" {
+			payload.Codes[key] = validator.CodeInvalid
+		}
+	}
 	WriteJSON(w, http.StatusUnprocessableEntity, payload)
 }
 
@@ -173,14 +307,14 @@ func IsAuthenticated(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authorizationHeader := r.Header.Get("Authorization")
 		if authorizationHeader == "" {
-			_ = InvalidCredentials(w)
+			_ = InvalidCredentials(w, r)
 			fmt.Println("no authorization header received")
 			return
 		}
 
 		headerParts := strings.Split(authorizationHeader, " ")
 		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
-			_ = InvalidCredentials(w)
+			_ = InvalidCredentials(w, r)
 			fmt.Println("no authorization header received")
 			return
 		}
@@ -188,25 +322,69 @@ func IsAuthenticated(next http.Handler) http.Handler {
 		token := headerParts[1]
 
 		if len(token) != 26 {
-			_ = InvalidCredentials(w)
+			_ = InvalidCredentials(w, r)
 			fmt.Println("error verifying token length")
 			return
 		}
 
 		user, err := Repo.FetchUserByToken(token)
 		if err != nil {
-			_ = InvalidCredentials(w)
+			_ = InvalidCredentials(w, r)
 			fmt.Println("error retrieving token from database: ", err)
 			return
 		}
 
 		ctx := context.WithValue(r.Context(), UserContextKey, user)
+		ctx = context.WithValue(ctx, TokenContextKey, token)
+		ctx = logger.WithFields(ctx, "user_id", user.ID.String())
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
 	})
 }
 
+// MayAuthenticate authenticates the requests with an Authorization header like
+// IsAuthenticated, and lets the others through anonymously.
+func MayAuthenticate(next http.Handler) http.Handler {
+	authenticated := IsAuthenticated(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
+// IsAdmin only lets through users with the admin role. It must be chained after IsAuthenticated.
+func IsAdmin(next http.Handler) http.Handler {
+	return HasRole(models.RoleAdmin)(next)
+}
+
+// HasRole returns a middleware that only lets through users with one of roles. It must
+// be chained after IsAuthenticated.
+func HasRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := r.Context().Value(UserContextKey).(*models.User)
+			if !ok {
+				_ = InvalidCredentials(w, r)
+				fmt.Println("no authenticated user in context")
+				return
+			}
+
+			if !slices.Contains(roles, user.Role) {
+				_ = Forbidden(w, r)
+				fmt.Printf("user is not one of %s\n", strings.Join(roles, ", "))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // CreateMultipartForm takes url.Values map and returns the multipart form data and the content type
 func CreateMultipartForm(fields url.Values) (*bytes.Buffer, string, error) {
 	var b bytes.Buffer
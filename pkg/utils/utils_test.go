@@ -2,7 +2,10 @@ package utils
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"image/png"
@@ -10,9 +13,19 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/textproto"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
+	"github.com/jofosuware/go/shopit/internal/models"
+	"github.com/jofosuware/go/shopit/pkg/errreport"
+	"github.com/jofosuware/go/shopit/pkg/i18n"
+	mockLogger "github.com/jofosuware/go/shopit/pkg/logger/mock"
+	"github.com/jofosuware/go/shopit/pkg/money"
+	"github.com/jofosuware/go/shopit/pkg/validator"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -27,6 +40,71 @@ func TestWriteJSON(t *testing.T) {
 	assert.Equal(t, w.Code, http.StatusOK)
 }
 
+func TestWriteJSONPretty(t *testing.T) {
+	data := map[string]string{"message": "Hello, World!"}
+
+	w := httptest.NewRecorder()
+	require.NoError(t, WriteJSON(w, http.StatusOK, data))
+	assert.Equal(t, "{\"message\":\"Hello, World!\"}\n", w.Body.String())
+
+	h := PrettyJSON(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = WriteJSON(w, http.StatusOK, data)
+	}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api?pretty", nil))
+	assert.Equal(t, "{\n\t\"message\": \"Hello, World!\"\n}\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api?pretty=false", nil))
+	assert.Equal(t, "{\"message\":\"Hello, World!\"}\n", w.Body.String())
+}
+
+func TestWriteJSONError(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := WriteJSON(w, http.StatusOK, map[string]interface{}{"bad": make(chan int)})
+
+	assert.Error(t, err)
+	assert.Zero(t, w.Body.Len())
+}
+
+// BenchmarkWriteJSON writes a page of the product listing.
+func BenchmarkWriteJSON(b *testing.B) {
+	page := models.GetProd{Success: true, ProductCount: 100, ResPerPage: 100, FilteredProductsCount: 100}
+	for i := 0; i < 100; i++ {
+		page.Products = append(page.Products, models.Product{
+			ProductId:   uuid.New(),
+			Name:        fmt.Sprintf("Product %d", i),
+			Price:       money.Of(4999),
+			Description: strings.Repeat("A fine product. ", 20),
+			Ratings:     4,
+			Images:      []models.Images{{PublicId: "products/p", Url: "https://example.com/p.jpg"}},
+			Category:    "Cameras",
+			Status:      models.ProductPublished,
+			Seller:      "Shopit",
+			Stock:       10,
+		})
+	}
+
+	for _, bc := range []struct {
+		name   string
+		indent bool
+	}{{"compact", false}, {"indent", true}} {
+		b.Run(bc.name, func(b *testing.B) {
+			IndentJSON = bc.indent
+			defer func() { IndentJSON = false }()
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				w := httptest.NewRecorder()
+				if err := WriteJSON(w, http.StatusOK, page); err != nil {
+					b.Fatal(err)
+				}
+				b.SetBytes(int64(w.Body.Len()))
+			}
+		})
+	}
+}
+
 func TestReadJSON(t *testing.T) {
 	// Create a mock HTTP request with JSON body
 	r, err := http.NewRequest(http.MethodPost, "/api", bytes.NewBuffer([]byte(`{"name":"John Doe"}`)))
@@ -66,12 +144,105 @@ func TestBadRequest(t *testing.T) {
 	assert.Equal(t, w.Code, http.StatusBadRequest)
 }
 
+func TestNotFound(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api", nil)
+
+	err := NotFound(w, r, errors.New("product not found"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), `"message":"product not found"`)
+}
+
+func TestServerError(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api", nil)
+
+	var loggedID, loggedIP string
+	logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			loggedID = args.String(1)
+			loggedIP = args.String(4)
+		}).Once()
+
+	err := ServerError(w, r, logger, errors.New("pq: relation does not exist"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var payload struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		ErrorID string `json:"errorId"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &payload))
+
+	assert.False(t, payload.Success)
+	assert.NotEmpty(t, payload.ErrorID)
+	assert.Equal(t, loggedID, payload.ErrorID)
+	assert.Equal(t, "192.0.2.1", loggedIP)
+	assert.Equal(t, payload.ErrorID, w.Header().Get("X-Error-Id"))
+	assert.NotContains(t, w.Body.String(), "relation does not exist")
+}
+
+func TestRecoverPanic(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+	handler := RecoverPanic(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api", nil)
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotEmpty(t, w.Header().Get("X-Error-Id"))
+}
+
+// reporterFunc is an errreport.Reporter calling itself.
+type reporterFunc func(ctx context.Context, e errreport.Event) error
+
+func (f reporterFunc) Report(ctx context.Context, e errreport.Event) error {
+	return f(ctx, e)
+}
+
+func TestRecoverPanicReport(t *testing.T) {
+	logger := mockLogger.NewLogger(t)
+	logger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Once()
+
+	reported := make(chan errreport.Event, 1)
+	handler := RecoverPanic(logger, reporterFunc(func(ctx context.Context, e errreport.Event) error {
+		reported <- e
+		return nil
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api", nil))
+
+	e := <-reported
+	assert.Equal(t, w.Header().Get("X-Error-Id"), e.ID)
+	assert.Equal(t, "panic: boom", e.Message)
+	assert.Contains(t, string(e.Stack), "TestRecoverPanicReport")
+	assert.Equal(t, "/api", e.URL)
+}
+
 func TestInvalidCredentials(t *testing.T) {
 	// Create a mock HTTP response writer
 	w := httptest.NewRecorder()
 
+	// Create a mock HTTP request
+	r := httptest.NewRequest(http.MethodGet, "/api", nil)
+
 	// Call the InvalidCredentials function
-	err := InvalidCredentials(w)
+	err := InvalidCredentials(w, r)
 
 	// Check if there was an error
 	assert.NoError(t, err)
@@ -80,6 +251,68 @@ func TestInvalidCredentials(t *testing.T) {
 	assert.Equal(t, w.Code, http.StatusUnauthorized)
 }
 
+func TestIsAdmin(t *testing.T) {
+	handler := IsAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name     string
+		user     *models.User
+		wantCode int
+	}{
+		{name: "no user in context", user: nil, wantCode: http.StatusUnauthorized},
+		{name: "regular user", user: &models.User{Role: models.RoleUser}, wantCode: http.StatusForbidden},
+		{name: "admin user", user: &models.User{Role: models.RoleAdmin}, wantCode: http.StatusOK},
+		{name: "seller", user: &models.User{Role: models.RoleSeller}, wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api", nil)
+			if tt.user != nil {
+				r = r.WithContext(context.WithValue(r.Context(), UserContextKey, tt.user))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	handler := HasRole(models.RoleAdmin, models.RoleSeller)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name     string
+		user     *models.User
+		wantCode int
+	}{
+		{name: "no user in context", user: nil, wantCode: http.StatusUnauthorized},
+		{name: "regular user", user: &models.User{Role: models.RoleUser}, wantCode: http.StatusForbidden},
+		{name: "admin user", user: &models.User{Role: models.RoleAdmin}, wantCode: http.StatusOK},
+		{name: "seller", user: &models.User{Role: models.RoleSeller}, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api", nil)
+			if tt.user != nil {
+				r = r.WithContext(context.WithValue(r.Context(), UserContextKey, tt.user))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
 func TestPasswordMatches(t *testing.T) {
 	// Create a mock password hash
 	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
@@ -103,12 +336,35 @@ func TestFailedValidation(t *testing.T) {
 	r := httptest.NewRequest(http.MethodGet, "/api", nil)
 
 	// Call the FailedValidation function
-	FailedValidation(w, r, map[string]string{"name": "Name is required"})
+	v := validator.New()
+	v.AddErrorCode("name", validator.CodeRequired, "Name is required")
+	FailedValidation(w, r, v)
 
 	// Check the response status code
 	assert.Equal(t, w.Code, http.StatusUnprocessableEntity)
 }
 
+func TestFailedValidationLocalized(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api", nil)
+	r = r.WithContext(i18n.NewContext(r.Context(), i18n.French))
+
+	v := validator.New()
+	v.AddErrorCode("email", validator.CodeRequired, "email must be provided")
+	FailedValidation(w, r, v)
+
+	var payload struct {
+		Message string            `json:"message"`
+		Errors  map[string]string `json:"errors"`
+		Codes   map[string]string `json:"codes"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&payload))
+	assert.Equal(t, "échec de la validation", payload.Message)
+	assert.Equal(t, "email doit être renseigné", payload.Errors["email"])
+	assert.Equal(t, validator.CodeRequired, payload.Codes["email"], "codes are not localized")
+	assert.Equal(t, "email must be provided", v.Errors["email"], "the errors of the caller are left in English")
+}
+
 func TestProcessImage(t *testing.T) {
 	// Create a mock image of 100x100
 	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
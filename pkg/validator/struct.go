@@ -0,0 +1,199 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	playground "github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/non-standard/validators"
+)
+
+// Validatable is implemented by request structs with rules their `validate` tags
+// cannot express, such as values checked against the shop's settings. Struct calls
+// Validate once the tags are checked.
+type Validatable interface {
+	Validate(v *Validator)
+}
+
+// engine checks the `validate` tags of request structs. It caches what it learns
+// of each struct type, so it is shared.
+var engine = newEngine()
+
+func newEngine() *playground.Validate {
+	e := playground.New()
+
+	// errors are keyed by the JSON name of a field, as clients send it
+	e.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+
+	// notblank rejects strings of only white space, which required lets through
+	_ = e.RegisterValidation("notblank", validators.NotBlank)
+
+	return e
+}
+
+// Struct checks s, a pointer to a struct, against its `validate` tags and adds an
+// error for each field breaking one, keyed by its JSON path (e.g. "items[0].quantity").
+// If s is Validatable, its own rules are checked as well.
+//
+// Errors other than broken rules, such as a tag naming an unknown rule, panic: they
+// are mistakes in the request struct, not in the request.
+func (v *Validator) Struct(s interface{}) {
+	if isStruct(s) {
+		var fieldErrs playground.ValidationErrors
+		if errors.As(engine.Struct(s), &fieldErrs) {
+			for _, fe := range fieldErrs {
+				v.AddErrorCode(fieldKey(fe), code(fe), message(fe))
+			}
+		}
+	}
+
+	if c, ok := s.(Validatable); ok {
+		c.Validate(v)
+	}
+}
+
+// isStruct reports whether s is a struct or a pointer to one; other bodies, such as
+// maps, have no tags to check.
+func isStruct(s interface{}) bool {
+	t := reflect.TypeOf(s)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t != nil && t.Kind() == reflect.Struct
+}
+
+// fieldKey returns the JSON path of the field of fe, without the name of the struct.
+func fieldKey(fe playground.FieldError) string {
+	_, key, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return key
+}
+
+// code returns the code of the rule of fe that the field broke.
+func code(fe playground.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_with", "required_without", "required_without_all", "notblank":
+		return CodeRequired
+	case "email":
+		return CodeEmail
+	case "url", "http_url":
+		return CodeURL
+	case "uuid", "uuid4":
+		return CodeID
+	case "oneof":
+		return CodeOneOf
+	case "eqfield":
+		return CodeMismatch
+	case "nefield":
+		return CodeSame
+	case "unique":
+		return CodeDuplicate
+	case "min", "gte", "gt":
+		return boundCode(fe, CodeTooShort, CodeTooFew, CodeTooSmall)
+	case "max", "lte", "lt":
+		return boundCode(fe, CodeTooLong, CodeTooMany, CodeTooLarge)
+	case "len":
+		return CodeLength
+	default:
+		return CodeInvalid
+	}
+}
+
+// boundCode returns the code of a limit broken by fe: of a length for strings, of a
+// number of items for lists, of a value for numbers.
+func boundCode(fe playground.FieldError, length, items, value string) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return length
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return items
+	default:
+		return value
+	}
+}
+
+// message describes the rule of fe that the field broke, in the words of the other
+// validation errors of the API.
+func message(fe playground.FieldError) string {
+	field := fe.Field()
+	param := fe.Param()
+
+	var rule string
+	switch fe.Tag() {
+	case "required", "required_with", "notblank":
+		rule = "must be provided"
+	case "required_without", "required_without_all":
+		others := strings.Fields(param)
+		for i := range others {
+			others[i] = lowerFirst(others[i])
+		}
+		rule = "or " + strings.Join(others, " or ") + " must be provided"
+	case "email":
+		rule = "must be a valid email address"
+	case "url", "http_url":
+		rule = "must be a valid URL"
+	case "uuid", "uuid4":
+		rule = "must be a valid id"
+	case "oneof":
+		rule = "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "eqfield":
+		rule = "must match " + lowerFirst(param)
+	case "nefield":
+		rule = "must differ from " + lowerFirst(param)
+	case "unique":
+		rule = "must not have duplicates"
+	case "min", "gte":
+		rule = bound("at least", fe)
+	case "max", "lte":
+		rule = bound("at most", fe)
+	case "len":
+		rule = bound("exactly", fe)
+	case "gt":
+		rule = bound("more than", fe)
+	case "lt":
+		rule = bound("less than", fe)
+	default:
+		rule = "is invalid"
+	}
+
+	return field + " " + rule
+}
+
+// bound describes a size or numeric limit of fe: a length for strings and lists, a
+// value for numbers.
+func bound(limit string, fe playground.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("must be %s %s characters", limit, fe.Param())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if fe.Param() == "1" {
+			return fmt.Sprintf("must have %s 1 item", limit)
+		}
+		return fmt.Sprintf("must have %s %s items", limit, fe.Param())
+	default:
+		if limit == "at least" && fe.Param() == "0" {
+			return "must not be negative"
+		}
+		return fmt.Sprintf("must be %s %s", limit, fe.Param())
+	}
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
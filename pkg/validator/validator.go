@@ -2,21 +2,54 @@ package validator
 
 import "regexp"
 
+// Codes of validation errors. They are stable, so that clients can map an error to
+// a form field and word it themselves, whatever the language of the message.
+const (
+	CodeRequired  = "ERR_REQUIRED"
+	CodeInvalid   = "ERR_INVALID"
+	CodeEmail     = "ERR_EMAIL"
+	CodeURL       = "ERR_URL"
+	CodeID        = "ERR_ID"
+	CodeOneOf     = "ERR_ONE_OF"
+	CodeMismatch  = "ERR_MISMATCH"
+	CodeSame      = "ERR_SAME"
+	CodeDuplicate = "ERR_DUPLICATE"
+	CodeTaken     = "ERR_TAKEN"
+	CodeNotFound  = "ERR_NOT_FOUND"
+	CodeTooShort  = "ERR_TOO_SHORT"
+	CodeTooLong   = "ERR_TOO_LONG"
+	CodeTooFew    = "ERR_TOO_FEW"
+	CodeTooMany   = "ERR_TOO_MANY"
+	CodeTooSmall  = "ERR_TOO_SMALL"
+	CodeTooLarge  = "ERR_TOO_LARGE"
+	CodeLength    = "ERR_LENGTH"
+)
+
+// Validator collects what is wrong with a request: a message and a code per field,
+// keyed alike.
 type Validator struct {
 	Errors map[string]string
+	Codes  map[string]string
 }
 
 func New() *Validator {
-	return &Validator{Errors: make(map[string]string)}
+	return &Validator{Errors: make(map[string]string), Codes: make(map[string]string)}
 }
 
 func (v *Validator) Valid() bool {
 	return len(v.Errors) == 0
 }
 
+// AddError adds the message of key, coded CodeInvalid, unless key already has one.
 func (v *Validator) AddError(key, message string) {
+	v.AddErrorCode(key, CodeInvalid, message)
+}
+
+// AddErrorCode adds the message and code of key, unless key already has an error.
+func (v *Validator) AddErrorCode(key, code, message string) {
 	if _, exists := v.Errors[key]; !exists {
 		v.Errors[key] = message
+		v.Codes[key] = code
 	}
 }
 
@@ -26,6 +59,13 @@ func (v *Validator) Check(ok bool, key, message string) {
 	}
 }
 
+// CheckCode adds the message and code of key when ok is false.
+func (v *Validator) CheckCode(ok bool, key, code, message string) {
+	if !ok {
+		v.AddErrorCode(key, code, message)
+	}
+}
+
 // IsEmailValid checks if the provided email string is a valid email address format.
 func (v *Validator) IsEmailValid(email, key, message string) {
 	// Simple regex for email validation (RFC 5322 official standard is more complex)
@@ -36,6 +76,6 @@ func (v *Validator) IsEmailValid(email, key, message string) {
 		matched = regexp.MustCompile(emailRegex).MatchString(email)
 	}
 	if !matched {
-		v.AddError(key, message)
+		v.AddErrorCode(key, CodeEmail, message)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,111 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/jofosuware/go/shopit/pkg/xlsx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sheet is the part of a worksheet the tests read back.
+type sheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// read returns the parts of the workbook in b.
+func read(t *testing.T, b []byte) map[string]string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		parts[f.Name] = string(content)
+	}
+
+	return parts
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("Rows are written as cells", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := xlsx.NewWriter(&buf, "Sales")
+		require.NoError(t, w.Write([]any{"period", "orders", "revenue"}))
+		require.NoError(t, w.Write([]any{"2026-10-01", 12, xlsx.Number("1049.90"), nil, int64(3), 0.5}))
+		require.NoError(t, w.Close())
+
+		parts := read(t, buf.Bytes())
+		for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+			assert.Contains(t, parts, name)
+		}
+		assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Sales"`)
+
+		var s sheet
+		require.NoError(t, xml.Unmarshal([]byte(parts["xl/worksheets/sheet1.xml"]), &s))
+		require.Len(t, s.Rows, 2)
+		assert.Equal(t, 1, s.Rows[0].R)
+		assert.Equal(t, "inlineStr", s.Rows[0].Cells[0].Type)
+		assert.Equal(t, "period", s.Rows[0].Cells[0].Inline)
+
+		cells := s.Rows[1].Cells
+		require.Len(t, cells, 5)
+		assert.Equal(t, "A2", cells[0].Ref)
+		assert.Equal(t, "12", cells[1].Value)
+		assert.Equal(t, "1049.90", cells[2].Value)
+		assert.Equal(t, "E2", cells[3].Ref)
+		assert.Equal(t, "3", cells[3].Value)
+		assert.Equal(t, "0.5", cells[4].Value)
+	})
+
+	t.Run("Text is escaped", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := xlsx.NewWriter(&buf, "Q3: <report>")
+		require.NoError(t, w.Write([]any{`Tom & "Jerry" <ltd>`}))
+		require.NoError(t, w.Close())
+
+		parts := read(t, buf.Bytes())
+		assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Q3_ &lt;report&gt;"`)
+
+		var s sheet
+		require.NoError(t, xml.Unmarshal([]byte(parts["xl/worksheets/sheet1.xml"]), &s))
+		assert.Equal(t, `Tom & "Jerry" <ltd>`, s.Rows[0].Cells[0].Inline)
+	})
+
+	t.Run("Empty workbook is valid", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, xlsx.NewWriter(&buf, "").Close())
+
+		parts := read(t, buf.Bytes())
+		assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Sheet1"`)
+		var s sheet
+		require.NoError(t, xml.Unmarshal([]byte(parts["xl/worksheets/sheet1.xml"]), &s))
+		assert.Empty(t, s.Rows)
+	})
+
+	t.Run("Invalid cells", func(t *testing.T) {
+		w := xlsx.NewWriter(io.Discard, "Sales")
+		assert.ErrorContains(t, w.Write([]any{xlsx.Number("12,5")}), "not a number")
+		assert.ErrorContains(t, w.Write([]any{true}), "unsupported type bool")
+		require.NoError(t, w.Close())
+		assert.ErrorIs(t, w.Write([]any{"late"}), xlsx.ErrClosed)
+	})
+}